@@ -0,0 +1,148 @@
+/**
+ * Audit Anomalies — flag audit events that look out of character for the
+ * actor who made them.
+ *
+ * Each heuristic judges an event against the same actor's own last 30
+ * days, not a global rule, so a night-shift admin isn't flagged for
+ * working nights:
+ *
+ *   unusual_hour       nothing from the actor within an hour either side of
+ *                      this time of day (UTC), once they have some history
+ *   new_ip             a public IP the actor hasn't used in 30 days, once
+ *                      they have some history with IPs
+ *   destructive_burst  5 or more deletes, revokes, archives... by the actor
+ *                      within 10 minutes
+ *
+ * Flags are worked out when rows are read and never stored, so they follow
+ * the history as it grows and there is nothing to migrate. Automated actors
+ * (actorType 'system') are skipped; running at odd hours is their job.
+ */
+
+import type { Hono } from 'hono';
+import type { AuditEvent, DatabaseAdapter } from '../db/adapter.js';
+import { isLocalIp } from '../middleware/index.js';
+import { requireCapability } from '../middleware/role-permissions.js';
+
+export type AuditAnomalyType = 'unusual_hour' | 'new_ip' | 'destructive_burst';
+
+export interface AuditAnomaly {
+  type: AuditAnomalyType;
+  message: string;
+}
+
+const BASELINE_MS = 30 * 86_400_000;
+/** Most history read per actor; past this the baseline is their newest events */
+const BASELINE_MAX_EVENTS = 2000;
+/** Earlier events an actor needs before an hour or an IP counts as unusual */
+const MIN_HOUR_HISTORY = 20;
+const MIN_IP_HISTORY = 5;
+const BURST_WINDOW_MS = 10 * 60_000;
+const BURST_MIN_ACTIONS = 5;
+/** Most recent events the dashboard card scans */
+const SCAN_MAX = 1000;
+
+const DESTRUCTIVE = /delete|remove|revok|archiv|purge|deactivat|destroy|kill|wipe/i;
+
+export function isDestructiveAction(action: string | undefined): boolean {
+  return !!action && DESTRUCTIVE.test(action);
+}
+
+const timeOf = (e: AuditEvent) => new Date(e.timestamp).getTime();
+
+/** The flags for one event, given the actor's history around it */
+function anomaliesFor(event: AuditEvent, history: AuditEvent[]): AuditAnomaly[] {
+  const at = timeOf(event);
+  const others = history.filter(e => e.id !== event.id);
+  const prior = others.filter(e => timeOf(e) < at && timeOf(e) >= at - BASELINE_MS);
+  const found: AuditAnomaly[] = [];
+
+  if (prior.length >= MIN_HOUR_HISTORY) {
+    const hour = new Date(at).getUTCHours();
+    const near = new Set([(hour + 23) % 24, hour, (hour + 1) % 24]);
+    if (!prior.some(e => near.has(new Date(timeOf(e)).getUTCHours()))) {
+      found.push({ type: 'unusual_hour', message: `First activity around ${String(hour).padStart(2, '0')}:00 UTC in 30 days` });
+    }
+  }
+
+  if (event.ip && !isLocalIp(event.ip)) {
+    const withIp = prior.filter(e => e.ip);
+    if (withIp.length >= MIN_IP_HISTORY && !withIp.some(e => e.ip === event.ip)) {
+      found.push({ type: 'new_ip', message: `First use of ${event.ip} in 30 days` });
+    }
+  }
+
+  if (isDestructiveAction(event.action)) {
+    // Largest number of destructive actions in any 10-minute window that includes this one
+    const times = others
+      .filter(e => isDestructiveAction(e.action) && Math.abs(timeOf(e) - at) <= BURST_WINDOW_MS)
+      .map(timeOf)
+      .concat(at)
+      .sort((a, b) => a - b);
+    let most = 0;
+    for (let i = 0, j = 0; j < times.length; j++) {
+      while (times[j] - times[i] > BURST_WINDOW_MS) i++;
+      if (times[i] <= at && at <= times[j]) most = Math.max(most, j - i + 1);
+    }
+    if (most >= BURST_MIN_ACTIONS) {
+      found.push({ type: 'destructive_burst', message: `${most} destructive actions within 10 minutes` });
+    }
+  }
+
+  return found;
+}
+
+/**
+ * Add `anomalies` to each event that has any. Reads the history once per
+ * distinct actor; if that read fails the actor's events go unflagged.
+ */
+export async function flagAuditAnomalies<T extends AuditEvent>(db: DatabaseAdapter, events: T[]): Promise<Array<T & { anomalies?: AuditAnomaly[] }>> {
+  const byActor = new Map<string, T[]>();
+  for (const e of events) {
+    if (!e.actor || e.actorType === 'system') continue;
+    byActor.set(e.actor, [...(byActor.get(e.actor) || []), e]);
+  }
+
+  const flags = new Map<string, AuditAnomaly[]>();
+  await Promise.all([...byActor].map(async ([actor, mine]) => {
+    const times = mine.map(timeOf);
+    let history: AuditEvent[];
+    try {
+      history = (await db.queryAudit({
+        actor,
+        from: new Date(Math.min(...times) - BASELINE_MS),
+        to: new Date(Math.max(...times) + BURST_WINDOW_MS),
+        limit: BASELINE_MAX_EVENTS,
+        sort: { field: 'timestamp', dir: 'desc' },
+      })).events;
+    } catch {
+      return;
+    }
+    for (const e of mine) {
+      const found = anomaliesFor(e, history);
+      if (found.length) flags.set(e.id, found);
+    }
+  }));
+
+  return events.map(e => (flags.has(e.id) ? { ...e, anomalies: flags.get(e.id) } : e));
+}
+
+export function registerAuditAnomalyRoutes(api: Hono<any>, opts: { db: DatabaseAdapter }) {
+  const { db } = opts;
+
+  // Flagged events from the last ?hours=24 (up to a week), for the
+  // dashboard's Suspicious Activity card
+  api.get('/audit/anomalies', requireCapability('audit.view'), async (c) => {
+    const hours = Math.min(Math.max(parseInt(c.req.query('hours') || '24') || 24, 1), 168);
+    const orgId = c.req.query('orgId') || undefined;
+    const { events, total } = await db.queryAudit({
+      orgId,
+      from: new Date(Date.now() - hours * 3_600_000),
+      limit: SCAN_MAX,
+      sort: { field: 'timestamp', dir: 'desc' },
+    });
+    const flagged = (await flagAuditAnomalies(db, events)).filter(e => e.anomalies);
+    const counts: Record<AuditAnomalyType, number> = { unusual_hour: 0, new_ip: 0, destructive_burst: 0 };
+    for (const e of flagged) for (const a of e.anomalies!) counts[a.type]++;
+    return c.json({ hours, scanned: events.length, total, counts, events: flagged.slice(0, 50) });
+  });
+}
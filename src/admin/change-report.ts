@@ -0,0 +1,263 @@
+/**
+ * Change Report — what changed in the org between two points in time.
+ *
+ * Reads the audit log for the window and folds it into a per-entity diff
+ * for four areas: agents, users, rules (email, DLP and guardrail rules,
+ * policies) and settings. Each entity touched in the window comes out as
+ * added, removed, changed, or added-and-removed, with the human-readable
+ * lines that got it there ("alice@acme.com updated agent Sales Bot").
+ *
+ * Audit rows come in two shapes: the audit middleware's, whose resource is
+ * the request path ("/api/engine/dlp/rules/abc", method in details), and
+ * explicit logEvent calls, whose resource is "type:id" and whose action
+ * carries the verb ("user.deactivated"). Both are understood.
+ *
+ * Agents' own external actions come from the engine's action_journal and
+ * are summarised per type and agent alongside the diff.
+ */
+
+import type { Hono } from 'hono';
+import type { AuditEvent } from '../db/adapter.js';
+import { PdfDocument, pdfResponse } from '../lib/pdf.js';
+
+export type ChangeArea = 'agents' | 'users' | 'rules' | 'settings';
+export type ChangeStatus = 'added' | 'removed' | 'changed' | 'added-removed';
+
+export interface ChangeLine {
+  timestamp: string;
+  actor: string;
+  text: string;
+}
+
+export interface EntityChange {
+  /** Entity id, or the settings path for settings */
+  id: string;
+  name: string;
+  status: ChangeStatus;
+  actors: string[];
+  lines: ChangeLine[];
+}
+
+export interface ChangeSection {
+  id: ChangeArea;
+  label: string;
+  entities: EntityChange[];
+  counts: Record<ChangeStatus, number>;
+}
+
+export interface ChangeReport {
+  from: string;
+  to: string;
+  generatedAt: string;
+  /** Audit events read; more than MAX_EVENTS in the window means truncated */
+  events: number;
+  truncated: boolean;
+  sections: ChangeSection[];
+  journal: {
+    total: number;
+    reversed: number;
+    byType: Record<string, number>;
+    byAgent: Array<{ agentId: string; name: string; count: number }>;
+  };
+}
+
+const MAX_EVENTS = 5000;
+
+const AREAS: Array<{ id: ChangeArea; label: string; noun: string; paths: string[]; actions: string[] }> = [
+  { id: 'agents', label: 'Agents', noun: 'agent', paths: ['agents', 'agent-lifecycle'], actions: ['agent', 'agents'] },
+  { id: 'users', label: 'Users', noun: 'user', paths: ['users'], actions: ['user', 'users'] },
+  { id: 'rules', label: 'Rules', noun: 'rule', paths: ['rules', 'dlp', 'guardrails', 'policies'], actions: ['rule', 'rules', 'dlp', 'guardrail', 'policy'] },
+  { id: 'settings', label: 'Settings', noun: 'setting', paths: ['settings', 'admin', 'dashboard'], actions: ['settings', 'setting', 'dashboard', 'platform', 'setup'] },
+];
+
+const METHOD_VERBS: Record<string, string> = { POST: 'created', PUT: 'updated', PATCH: 'updated', DELETE: 'deleted' };
+
+// Path segments that name an action rather than an entity id
+const NOT_IDS = new Set(['rules', 'bulk', 'import', 'export', 'reorder', 'test', 'batch', 'config']);
+
+interface Classified {
+  area: typeof AREAS[number];
+  entityId: string;
+  /** Sub-kind within the area, e.g. "dlp" for /dlp/rules/... */
+  kind?: string;
+  verb: string;
+}
+
+/** Which area and entity an audit event touched, and what was done to it; null when it's none of the four areas */
+export function classifyEvent(e: Pick<AuditEvent, 'action' | 'resource' | 'details'>): Classified | null {
+  const resource = String(e.resource || '');
+  if (resource.startsWith('/')) {
+    const segs = resource.split('?')[0].split('/').filter(Boolean);
+    while (segs[0] === 'api' || segs[0] === 'engine') segs.shift();
+    const area = AREAS.find(a => a.paths.includes(segs[0]));
+    if (!area) return null;
+    const method = String((e.details as any)?.method || '').toUpperCase();
+    // /dlp/rules/:id, /guardrails/rules/:id — the entity sits one level deeper
+    const base = area.id === 'rules' && segs[1] === 'rules' ? 2 : 1;
+    const id = segs[base] && !NOT_IDS.has(segs[base]) ? segs[base] : '';
+    const sub = segs[base + 1];
+    let verb = METHOD_VERBS[method] || (method ? method.toLowerCase() : 'changed');
+    if (method === 'POST' && id && sub) verb = `ran ${sub.replace(/[-_]/g, ' ')} on`;
+    else if (method === 'POST' && id && area.id !== 'settings') verb = 'updated';
+    if (area.id === 'settings') {
+      return { area, entityId: segs.slice(0, 3).join('/'), verb: verb === 'deleted' ? 'cleared' : 'updated' };
+    }
+    return { area, entityId: id, kind: base === 2 ? segs[0] : undefined, verb };
+  }
+
+  const [prefix, ...rest] = String(e.action || '').split('.');
+  const area = AREAS.find(a => a.actions.includes(prefix)) || (resource.includes(':') ? AREAS.find(a => a.actions.includes(resource.split(':')[0])) : undefined);
+  if (!area) return null;
+  const suffix = rest.join('.') || 'changed';
+  if (suffix.includes('export')) return null;
+  const verb = suffix === 'create' ? 'created' : suffix === 'update' ? 'updated' : suffix === 'delete' ? 'deleted' : suffix.replace(/_/g, ' ');
+  const entityId = area.id === 'settings' ? (resource || e.action) : resource.includes(':') ? resource.slice(resource.indexOf(':') + 1) : resource;
+  return { area, entityId, verb };
+}
+
+function statusOf(verbs: string[]): ChangeStatus {
+  const created = verbs.some(v => v === 'created');
+  const deleted = verbs.some(v => v === 'deleted' || v === 'removed');
+  return created && deleted ? 'added-removed' : created ? 'added' : deleted ? 'removed' : 'changed';
+}
+
+/**
+ * Fold audit events (oldest first) into per-area entity diffs. `names`
+ * resolves entity and actor ids to display names; unknown ids fall back to
+ * a name or email in the event details, then the id.
+ *
+ * Creating an agent or user is a POST to the collection, so its audit row
+ * has no id; `created` lists the agents and users whose createdAt falls in
+ * the window so they still show as added.
+ */
+export function buildChangeSections(
+  events: AuditEvent[],
+  names: { agent: (id: string) => string | undefined; user: (id: string) => string | undefined },
+  created: { agents?: Array<{ id: string; name: string; createdAt: string }>; users?: Array<{ id: string; name: string; createdAt: string }> } = {},
+): ChangeSection[] {
+  const byEntity = new Map<string, { area: ChangeArea; id: string; name: string; verbs: string[]; actors: Set<string>; lines: ChangeLine[] }>();
+  for (const e of events) {
+    const cl = classifyEvent(e);
+    if (!cl) continue;
+    // Rules have no created list to fall back on — one entry per create
+    if (!cl.entityId && cl.area.id === 'rules' && cl.verb === 'created') cl.entityId = 'new:' + e.id;
+    if (!cl.entityId) continue;
+    const details = (e.details || {}) as Record<string, any>;
+    const actor = e.actorType === 'system' ? 'System' : names.user(e.actor) || details.email || e.actor;
+    const name = cl.area.id === 'agents' ? names.agent(cl.entityId) || details.name || cl.entityId
+      : cl.area.id === 'users' ? names.user(cl.entityId) || details.email || details.name || cl.entityId
+      : cl.area.id === 'rules' ? details.name || (cl.entityId.startsWith('new:') ? '(unnamed)' : cl.entityId)
+      : cl.entityId;
+    const key = cl.area.id + '\u0000' + cl.entityId;
+    let entry = byEntity.get(key);
+    if (!entry) byEntity.set(key, entry = { area: cl.area.id, id: cl.entityId, name, verbs: [], actors: new Set(), lines: [] });
+    if (entry.name === entry.id && name !== cl.entityId) entry.name = name;
+    entry.verbs.push(cl.verb);
+    entry.actors.add(actor);
+    const noun = cl.kind ? `${cl.kind} ${cl.area.noun}` : cl.area.noun;
+    entry.lines.push({
+      timestamp: new Date(e.timestamp).toISOString(),
+      actor,
+      text: cl.area.id === 'settings' ? `${actor} ${cl.verb} ${cl.entityId}` : `${actor} ${cl.verb} ${noun} ${name}`,
+    });
+  }
+
+  for (const [area, list] of [['agents', created.agents], ['users', created.users]] as const) {
+    for (const x of list || []) {
+      const key = area + '\u0000' + x.id;
+      let entry = byEntity.get(key);
+      if (!entry) byEntity.set(key, entry = { area, id: x.id, name: x.name, verbs: [], actors: new Set(), lines: [] });
+      if (entry.verbs.includes('created')) continue;
+      entry.verbs.unshift('created');
+      entry.lines.unshift({ timestamp: new Date(x.createdAt).toISOString(), actor: '', text: `${area === 'agents' ? 'Agent' : 'User'} ${x.name} was created` });
+    }
+  }
+
+  return AREAS.map(area => {
+    const entities: EntityChange[] = [...byEntity.values()]
+      .filter(x => x.area === area.id)
+      .map(x => ({ id: x.id, name: x.name, status: statusOf(x.verbs), actors: [...x.actors], lines: x.lines.sort((a, b) => a.timestamp.localeCompare(b.timestamp)) }))
+      .sort((a, b) => a.name.localeCompare(b.name));
+    const counts: Record<ChangeStatus, number> = { added: 0, removed: 0, changed: 0, 'added-removed': 0 };
+    for (const en of entities) counts[en.status]++;
+    return { id: area.id, label: area.label, entities, counts };
+  });
+}
+
+export function registerChangeReportRoutes(
+  api: Hono<any>,
+  opts: { getAdminDb: () => any; requireRole: (role: any) => any },
+) {
+  const { getAdminDb, requireRole } = opts;
+
+  const buildReport = async (from: Date, to: Date, orgId?: string): Promise<ChangeReport> => {
+    const db = getAdminDb();
+    const [{ events, total }, agents, users] = await Promise.all([
+      db.queryAudit({ from, to, orgId, limit: MAX_EVENTS, offset: 0, sort: { field: 'timestamp', dir: 'asc' } }),
+      db.listAgents({ limit: 10_000 }).catch(() => []),
+      db.listUsers({ limit: 10_000 }).catch(() => []),
+    ]);
+    const agentNames = new Map<string, string>(agents.map((a: any) => [a.id, a.name]));
+    const userNames = new Map<string, string>(users.map((u: any) => [u.id, u.email || u.name]));
+    const inWindow = (d: any) => { const t = new Date(d).getTime(); return t >= from.getTime() && t < to.getTime(); };
+    const sections = buildChangeSections(events, { agent: id => agentNames.get(id), user: id => userNames.get(id) }, {
+      agents: agents.filter((a: any) => inWindow(a.createdAt)).map((a: any) => ({ id: a.id, name: a.name, createdAt: a.createdAt })),
+      users: users.filter((u: any) => inWindow(u.createdAt)).map((u: any) => ({ id: u.id, name: u.email || u.name, createdAt: u.createdAt })),
+    });
+
+    const journal: ChangeReport['journal'] = { total: 0, reversed: 0, byType: {}, byAgent: [] };
+    const edb = db.getEngineDB?.();
+    if (edb) {
+      const rows = await edb.all(
+        'SELECT agent_id, action_type, reversed_at, created_at FROM action_journal WHERE org_id = ? AND ((created_at >= ? AND created_at < ?) OR (reversed_at >= ? AND reversed_at < ?))',
+        [orgId || 'default', from.toISOString(), to.toISOString(), from.toISOString(), to.toISOString()],
+      ).catch(() => []);
+      const perAgent = new Map<string, number>();
+      for (const r of rows) {
+        const created = String(r.created_at);
+        if (created >= from.toISOString() && created < to.toISOString()) {
+          journal.total++;
+          journal.byType[r.action_type] = (journal.byType[r.action_type] || 0) + 1;
+          perAgent.set(r.agent_id, (perAgent.get(r.agent_id) || 0) + 1);
+        }
+        if (r.reversed_at && String(r.reversed_at) >= from.toISOString() && String(r.reversed_at) < to.toISOString()) journal.reversed++;
+      }
+      journal.byAgent = [...perAgent.entries()]
+        .map(([agentId, count]) => ({ agentId, name: agentNames.get(agentId) || agentId, count }))
+        .sort((a, b) => b.count - a.count);
+    }
+
+    return { from: from.toISOString(), to: to.toISOString(), generatedAt: new Date().toISOString(), events: events.length, truncated: total > events.length, sections, journal };
+  };
+
+  // ?from=&to=&orgId=&format=pdf — `to` defaults to now
+  api.get('/admin/changes', requireRole('admin'), async (c) => {
+    const from = new Date(c.req.query('from') || '');
+    const to = c.req.query('to') ? new Date(c.req.query('to')!) : new Date();
+    if (isNaN(from.getTime()) || isNaN(to.getTime())) return c.json({ error: '"from" and "to" must be dates' }, 400);
+    if (from >= to) return c.json({ error: '"from" must be before "to"' }, 400);
+    const report = await buildReport(from, to, c.req.query('orgId') || undefined);
+
+    if (c.req.query('format') === 'pdf') {
+      const fmt = (d: string) => d.replace('T', ' ').slice(0, 16) + ' UTC';
+      const doc = new PdfDocument({ title: 'Change Report' });
+      doc.heading('Change Report', 1);
+      doc.paragraph(`${fmt(report.from)} to ${fmt(report.to)}` + (report.truncated ? ` • first ${report.events.toLocaleString()} audit events only; narrow the window for the rest` : ''), { gray: 0.4 });
+      doc.keyValues(report.sections.map(s => [s.label, `${s.counts.added} added, ${s.counts.removed} removed, ${s.counts.changed} changed`] as [string, string]));
+      for (const s of report.sections) {
+        if (!s.entities.length) continue;
+        doc.heading(s.label, 2);
+        doc.table(['Status', 'Name', 'Changes', 'By'], s.entities.map(en => [
+          en.status.replace('-', ' and '), en.name, en.lines.map(l => l.timestamp.slice(0, 16).replace('T', ' ') + '  ' + l.text).join('\n'), en.actors.join(', '),
+        ]), { weights: [50, 90, 260, 100], maxLines: 8 });
+      }
+      if (report.journal.total) {
+        doc.heading('Agent actions', 2);
+        doc.paragraph(`${report.journal.total.toLocaleString()} journaled actions, ${report.journal.reversed.toLocaleString()} rolled back`);
+        doc.keyValues(Object.entries(report.journal.byType).sort((a, b) => b[1] - a[1]).map(([t, n]) => [t.replace(/_/g, ' '), n] as [string, number]));
+      }
+      return pdfResponse('change-report', doc);
+    }
+    return c.json(report);
+  });
+}
@@ -0,0 +1,373 @@
+/**
+ * Cost Overview — month-to-date LLM spend, projection and budget warning.
+ *
+ * Combines each agent's usage counters (managed_agents.usage, written by
+ * the agent processes) with the model pricing settings, so the dashboard
+ * can show what each agent's model costs per million tokens next to what
+ * it has spent. Agents whose usage carries tokens but no recorded cost
+ * (older runtimes) get an estimate at their model's input rate.
+ *
+ * The projection extrapolates month-to-date spend linearly over the month
+ * (UTC). The org-wide monthly budget and the percentage at which the
+ * dashboard warns live in the engine's engine_settings table under
+ * 'cost_budget'.
+ *
+ * The per-agent usage report reads the `llm_call` activity events each LLM
+ * turn records, so it can break tokens and cost down by day and by
+ * conversation (session). Turns recorded without a cost are priced the same
+ * way, at the agent's current model rates.
+ *
+ * The team report rolls the same events up across a team's agents (see
+ * engine/teams.ts): cost and activity per day, and per member.
+ */
+
+import type { Hono } from 'hono';
+
+export interface CostBudget {
+  /** Monthly budget in USD; 0 = none */
+  monthlyUsd: number;
+  /** Warn once spend or projection reaches this share of the budget */
+  warnAtPercent: number;
+}
+
+export interface AgentCost {
+  id: string;
+  name: string;
+  provider: string | null;
+  modelId: string | null;
+  inputCostPerMillion: number | null;
+  outputCostPerMillion: number | null;
+  tokensThisMonth: number;
+  costToday: number;
+  costThisMonth: number;
+  /** costThisMonth was derived from tokens × pricing rather than recorded */
+  estimated: boolean;
+}
+
+export interface AgentUsageDay {
+  day: string;
+  inputTokens: number;
+  outputTokens: number;
+  costUsd: number;
+}
+
+export interface AgentUsageConversation {
+  sessionId: string;
+  /** Start of the first user message, when the session transcript is stored */
+  preview: string | null;
+  calls: number;
+  inputTokens: number;
+  outputTokens: number;
+  costUsd: number;
+  firstAt: string;
+  lastAt: string;
+}
+
+export interface TeamReportMember {
+  id: string;
+  name: string;
+  /** Activity events other than LLM calls: tool calls, messages, lifecycle */
+  events: number;
+  calls: number;
+  inputTokens: number;
+  outputTokens: number;
+  costUsd: number;
+  lastActiveAt: string | null;
+}
+
+export interface TeamReportDay {
+  day: string;
+  events: number;
+  costUsd: number;
+}
+
+const SETTINGS_KEY = 'cost_budget';
+const TOP_CONVERSATIONS = 10;
+const DEFAULT_BUDGET: CostBudget = { monthlyUsd: 0, warnAtPercent: 80 };
+
+export function normalizeBudget(raw: any): CostBudget {
+  const monthlyUsd = Number(raw?.monthlyUsd ?? 0);
+  const warnAtPercent = Number(raw?.warnAtPercent ?? DEFAULT_BUDGET.warnAtPercent);
+  if (!Number.isFinite(monthlyUsd) || monthlyUsd < 0) throw new Error('monthlyUsd must be a non-negative number');
+  if (!Number.isFinite(warnAtPercent) || warnAtPercent < 1 || warnAtPercent > 100) throw new Error('warnAtPercent must be 1-100');
+  return { monthlyUsd: Math.round(monthlyUsd * 100) / 100, warnAtPercent: Math.round(warnAtPercent) };
+}
+
+/** Share of the current UTC month that has passed, at least one day's worth. */
+export function monthElapsed(now: Date): { fraction: number; daysInMonth: number } {
+  const start = Date.UTC(now.getUTCFullYear(), now.getUTCMonth(), 1);
+  const end = Date.UTC(now.getUTCFullYear(), now.getUTCMonth() + 1, 1);
+  const daysInMonth = Math.round((end - start) / 86_400_000);
+  return { fraction: Math.max((now.getTime() - start) / (end - start), 1 / daysInMonth), daysInMonth };
+}
+
+const parse = (v: any) => {
+  if (typeof v !== 'string') return v || {};
+  try { return JSON.parse(v); } catch { return {}; }
+};
+const round = (n: number) => Math.round(n * 10_000) / 10_000;
+const isoOf = (v: any) => (v instanceof Date ? v.toISOString() : String(v));
+
+/** Plain text of a stored session message, whether a string or content blocks */
+function messageText(raw: any): string {
+  let content = raw;
+  if (typeof raw === 'string') { try { content = JSON.parse(raw); } catch { return raw; } }
+  if (typeof content === 'string') return content;
+  if (Array.isArray(content)) return content.map((b: any) => (typeof b === 'string' ? b : b?.text || '')).join(' ');
+  return '';
+}
+
+export function registerCostOverviewRoutes(
+  api: Hono<any>,
+  opts: { getAdminDb: () => any; requireRole: (role: any) => any; defaultPricing: () => any[] },
+) {
+  const { getAdminDb, requireRole } = opts;
+  const engineDb = () => getAdminDb()?.getEngineDB?.() || null;
+
+  const loadPricing = async () => {
+    const settings = await getAdminDb().getSettings().catch(() => null);
+    const pricingConfig = settings?.modelPricingConfig || {};
+    const models: any[] = pricingConfig.models?.length ? pricingConfig.models : opts.defaultPricing();
+    const priceOf = (provider?: string, modelId?: string) =>
+      models.find(m => m.modelId === modelId && (!provider || m.provider === provider)) || models.find(m => m.modelId === modelId) || null;
+    return { currency: pricingConfig.currency || 'USD', priceOf };
+  };
+
+  const loadBudget = async (): Promise<CostBudget> => {
+    const row = await engineDb()?.get('SELECT value FROM engine_settings WHERE key = ?', [SETTINGS_KEY]).catch(() => undefined);
+    try { return row?.value ? normalizeBudget(JSON.parse(row.value)) : { ...DEFAULT_BUDGET }; } catch { return { ...DEFAULT_BUDGET }; }
+  };
+
+  // ?orgId= — spend for one engine org's agents
+  api.get('/dashboard/cost-overview', async (c) => {
+    const edb = engineDb();
+    if (!edb) return c.json({ error: 'Cost overview needs a SQL database' }, 501);
+    const orgId = c.req.query('orgId') || 'default';
+
+    const { currency, priceOf } = await loadPricing();
+
+    const rows = await edb.all('SELECT id, display_name, config, usage FROM managed_agents WHERE org_id = ?', [orgId]).catch(() => []);
+    const agents: AgentCost[] = rows.map((r: any) => {
+      const config = parse(r.config);
+      const usage = parse(r.usage);
+      const provider = config.model?.provider || null;
+      const modelId = config.model?.modelId || null;
+      const price = modelId ? priceOf(provider, modelId) : null;
+      const tokens = Number(usage.tokensThisMonth) || 0;
+      let cost = Number(usage.costThisMonth) || 0;
+      const estimated = cost === 0 && tokens > 0 && !!price;
+      if (estimated) cost = tokens * price.inputCostPerMillion / 1_000_000;
+      return {
+        id: r.id,
+        name: config.displayName || r.display_name || r.id,
+        provider, modelId,
+        inputCostPerMillion: price ? price.inputCostPerMillion : null,
+        outputCostPerMillion: price ? price.outputCostPerMillion : null,
+        tokensThisMonth: tokens,
+        costToday: round(Number(usage.costToday) || 0),
+        costThisMonth: round(cost),
+        estimated,
+      };
+    }).sort((a: AgentCost, b: AgentCost) => b.costThisMonth - a.costThisMonth);
+
+    const now = new Date();
+    const { fraction, daysInMonth } = monthElapsed(now);
+    const monthToDate = agents.reduce((s, a) => s + a.costThisMonth, 0);
+    const projected = monthToDate / fraction;
+    const budget = await loadBudget();
+    let status: 'none' | 'ok' | 'warning' | 'exceeded' = 'none';
+    if (budget.monthlyUsd > 0) {
+      const warnAt = budget.monthlyUsd * budget.warnAtPercent / 100;
+      status = monthToDate >= budget.monthlyUsd ? 'exceeded' : (monthToDate >= warnAt || projected >= warnAt) ? 'warning' : 'ok';
+    }
+
+    return c.json({
+      currency,
+      monthToDate: round(monthToDate),
+      today: round(agents.reduce((s, a) => s + a.costToday, 0)),
+      projected: round(projected),
+      daysElapsed: Math.round(fraction * daysInMonth * 10) / 10,
+      daysInMonth,
+      budget, status,
+      agents,
+    });
+  });
+
+  // ?days= — one agent's tokens and cost per day, plus its most expensive conversations
+  api.get('/agents/:id/usage-report', async (c) => {
+    const edb = engineDb();
+    if (!edb) return c.json({ error: 'Usage reports need a SQL database' }, 501);
+    const agentId = c.req.param('id');
+    const days = Math.min(Math.max(parseInt(c.req.query('days') || '30') || 30, 1), 90);
+
+    const agentRow = await edb.get('SELECT config FROM managed_agents WHERE id = ?', [agentId]).catch(() => undefined);
+    const config = parse(agentRow?.config);
+    const provider = config.model?.provider || null;
+    const modelId = config.model?.modelId || null;
+    const { currency, priceOf } = await loadPricing();
+    const price = modelId ? priceOf(provider, modelId) : null;
+
+    const series: AgentUsageDay[] = Array.from({ length: days }, (_, i) => ({
+      day: new Date(Date.now() - (days - 1 - i) * 86_400_000).toISOString().slice(0, 10),
+      inputTokens: 0, outputTokens: 0, costUsd: 0,
+    }));
+    const byDay = new Map(series.map(d => [d.day, d]));
+    const bySession = new Map<string, AgentUsageConversation>();
+    let estimated = false;
+
+    const rows = await edb.all(
+      "SELECT session_id, data, created_at FROM activity_events WHERE agent_id = ? AND type = 'llm_call' AND created_at >= ? LIMIT 200000",
+      [agentId, series[0].day],
+    ).catch(() => []);
+    for (const r of rows) {
+      const data = parse(r.data);
+      const input = Number(data.inputTokens) || 0;
+      const output = Number(data.outputTokens) || 0;
+      let cost = Number(data.costUsd) || 0;
+      if (cost === 0 && input + output > 0 && price) {
+        cost = (input * price.inputCostPerMillion + output * price.outputCostPerMillion) / 1_000_000;
+        estimated = true;
+      }
+      const at = isoOf(r.created_at);
+      const d = byDay.get(at.slice(0, 10));
+      if (d) { d.inputTokens += input; d.outputTokens += output; d.costUsd += cost; }
+      if (!r.session_id) continue;
+      let s = bySession.get(r.session_id);
+      if (!s) bySession.set(r.session_id, s = { sessionId: r.session_id, preview: null, calls: 0, inputTokens: 0, outputTokens: 0, costUsd: 0, firstAt: at, lastAt: at });
+      s.calls++; s.inputTokens += input; s.outputTokens += output; s.costUsd += cost;
+      if (at < s.firstAt) s.firstAt = at;
+      if (at > s.lastAt) s.lastAt = at;
+    }
+    for (const d of series) d.costUsd = round(d.costUsd);
+
+    const conversations = Array.from(bySession.values())
+      .sort((a, b) => b.costUsd - a.costUsd || (b.inputTokens + b.outputTokens) - (a.inputTokens + a.outputTokens))
+      .slice(0, TOP_CONVERSATIONS);
+    await Promise.all(conversations.map(async (conv) => {
+      conv.costUsd = round(conv.costUsd);
+      const msg = await edb.get(
+        "SELECT content FROM agent_session_messages WHERE session_id = ? AND role = 'user' ORDER BY created_at LIMIT 1",
+        [conv.sessionId],
+      ).catch(() => undefined);
+      const text = msg ? messageText(msg.content).replace(/\s+/g, ' ').trim() : '';
+      conv.preview = text ? text.slice(0, 140) : null;
+    }));
+
+    const sum = (k: 'inputTokens' | 'outputTokens' | 'costUsd') => series.reduce((n, d) => n + d[k], 0);
+    return c.json({
+      agentId, days, currency, provider, modelId,
+      inputCostPerMillion: price ? price.inputCostPerMillion : null,
+      outputCostPerMillion: price ? price.outputCostPerMillion : null,
+      estimated,
+      totals: { inputTokens: sum('inputTokens'), outputTokens: sum('outputTokens'), costUsd: round(sum('costUsd')) },
+      series, conversations,
+    });
+  });
+
+  // ?days= — a team's activity and cost per day and per member
+  api.get('/teams/:id/report', async (c) => {
+    const edb = engineDb();
+    if (!edb) return c.json({ error: 'Team reports need a SQL database' }, 501);
+    const teamId = c.req.param('id');
+    const days = Math.min(Math.max(parseInt(c.req.query('days') || '30') || 30, 1), 90);
+
+    const team = await edb.get('SELECT id, name, description, color FROM agent_teams WHERE id = ?', [teamId]).catch(() => undefined);
+    if (!team) return c.json({ error: 'Team not found' }, 404);
+    const memberIds: string[] = (await edb.all('SELECT agent_id FROM agent_team_members WHERE team_id = ?', [teamId]).catch(() => [])).map((r: any) => r.agent_id);
+
+    const series: TeamReportDay[] = Array.from({ length: days }, (_, i) => ({
+      day: new Date(Date.now() - (days - 1 - i) * 86_400_000).toISOString().slice(0, 10),
+      events: 0, costUsd: 0,
+    }));
+    const byDay = new Map(series.map(d => [d.day, d]));
+    const eventTypes = new Map<string, number>();
+    let estimated = false;
+
+    const { currency, priceOf } = await loadPricing();
+    const placeholders = memberIds.map(() => '?').join(',');
+    const agentRows = memberIds.length
+      ? await edb.all(`SELECT id, display_name, config FROM managed_agents WHERE id IN (${placeholders})`, memberIds).catch(() => [])
+      : [];
+    const members = new Map<string, TeamReportMember & { price: any }>();
+    for (const id of memberIds) {
+      const row = agentRows.find((r: any) => r.id === id);
+      const config = parse(row?.config);
+      const modelId = config.model?.modelId || null;
+      members.set(id, {
+        id, name: config.displayName || row?.display_name || id,
+        events: 0, calls: 0, inputTokens: 0, outputTokens: 0, costUsd: 0, lastActiveAt: null,
+        price: modelId ? priceOf(config.model?.provider, modelId) : null,
+      });
+    }
+
+    const rows = memberIds.length
+      ? await edb.all(
+          `SELECT agent_id, type, data, created_at FROM activity_events WHERE agent_id IN (${placeholders}) AND created_at >= ? LIMIT 500000`,
+          [...memberIds, series[0].day],
+        ).catch(() => [])
+      : [];
+    for (const r of rows) {
+      const m = members.get(r.agent_id);
+      if (!m) continue;
+      const at = isoOf(r.created_at);
+      const d = byDay.get(at.slice(0, 10));
+      if (!m.lastActiveAt || at > m.lastActiveAt) m.lastActiveAt = at;
+      if (r.type !== 'llm_call') {
+        m.events++;
+        if (d) d.events++;
+        eventTypes.set(r.type, (eventTypes.get(r.type) || 0) + 1);
+        continue;
+      }
+      const data = parse(r.data);
+      const input = Number(data.inputTokens) || 0;
+      const output = Number(data.outputTokens) || 0;
+      let cost = Number(data.costUsd) || 0;
+      if (cost === 0 && input + output > 0 && m.price) {
+        cost = (input * m.price.inputCostPerMillion + output * m.price.outputCostPerMillion) / 1_000_000;
+        estimated = true;
+      }
+      m.calls++; m.inputTokens += input; m.outputTokens += output; m.costUsd += cost;
+      if (d) d.costUsd += cost;
+    }
+    for (const d of series) d.costUsd = round(d.costUsd);
+
+    const memberList: TeamReportMember[] = Array.from(members.values())
+      .map(({ price: _price, ...m }) => ({ ...m, costUsd: round(m.costUsd) }))
+      .sort((a, b) => b.costUsd - a.costUsd || b.events - a.events);
+    return c.json({
+      team: { id: team.id, name: team.name, description: team.description || null, color: team.color || null },
+      days, currency, estimated,
+      totals: {
+        agents: memberList.length,
+        events: memberList.reduce((n, m) => n + m.events, 0),
+        calls: memberList.reduce((n, m) => n + m.calls, 0),
+        tokens: memberList.reduce((n, m) => n + m.inputTokens + m.outputTokens, 0),
+        costUsd: round(memberList.reduce((n, m) => n + m.costUsd, 0)),
+      },
+      series,
+      eventTypes: Array.from(eventTypes, ([type, count]) => ({ type, count })).sort((a, b) => b.count - a.count).slice(0, 10),
+      members: memberList,
+    });
+  });
+
+  // { monthlyUsd, warnAtPercent }
+  api.put('/admin/cost-budget', requireRole('admin'), async (c) => {
+    const edb = engineDb();
+    if (!edb) return c.json({ error: 'Cost budgets need a SQL database' }, 501);
+    const body = await c.req.json().catch(() => ({}));
+    let budget: CostBudget;
+    try { budget = normalizeBudget(body); } catch (err: any) { return c.json({ error: err.message }, 400); }
+    const before = await loadBudget();
+    await edb.run('DELETE FROM engine_settings WHERE key = ?', [SETTINGS_KEY]);
+    await edb.run('INSERT INTO engine_settings (key, value) VALUES (?, ?)', [SETTINGS_KEY, JSON.stringify(budget)]);
+    getAdminDb()?.logEvent({
+      actor: c.get('userId') || 'unknown',
+      actorType: 'user',
+      action: 'dashboard.cost_budget_update',
+      resource: 'cost_budget',
+      details: { ...budget, previous: before },
+      ip: c.req.header('x-forwarded-for')?.split(',')[0]?.trim() || c.req.header('x-real-ip'),
+    }).catch(() => {});
+    return c.json({ success: true, budget });
+  });
+}
@@ -0,0 +1,88 @@
+/**
+ * Custom Dashboard Metrics — admin-defined stat cards for the home dashboard.
+ *
+ * Each metric names a read-only API endpoint and a JSONPath expression into
+ * its response ("/engine/approvals/pending" + "$.total" → "Pending approvals").
+ * The dashboard fetches the endpoints as the signed-in user, so a card shows
+ * only what that user could read anyway, and a card whose endpoint they
+ * can't read is left out. Definitions are org-wide and live in the engine's
+ * engine_settings table under 'dashboard_metrics'.
+ */
+
+import type { Hono } from 'hono';
+
+export type MetricFormat = 'number' | 'percent' | 'currency' | 'duration' | 'bytes' | 'text';
+
+export interface DashboardMetric {
+  id: string;
+  label: string;
+  /** GET path relative to /api; "{orgId}" is replaced with the selected organization */
+  endpoint: string;
+  /** JSONPath into the response: $.total, $.agents[?(@.status=='active')].length */
+  field: string;
+  format: MetricFormat;
+  description?: string;
+}
+
+const SETTINGS_KEY = 'dashboard_metrics';
+const MAX_METRICS = 24;
+const FORMATS: MetricFormat[] = ['number', 'percent', 'currency', 'duration', 'bytes', 'text'];
+
+export function normalizeMetrics(raw: any): DashboardMetric[] {
+  if (!Array.isArray(raw)) throw new Error('metrics must be an array');
+  if (raw.length > MAX_METRICS) throw new Error(`At most ${MAX_METRICS} custom metrics`);
+  const ids = new Set<string>();
+  return raw.map((m: any, i: number): DashboardMetric => {
+    const label = String(m?.label || '').trim().slice(0, 60);
+    const endpoint = String(m?.endpoint || '').trim();
+    const field = String(m?.field || '').trim();
+    if (!label) throw new Error(`Metric ${i + 1}: label is required`);
+    if (!endpoint.startsWith('/') || endpoint.startsWith('//') || endpoint.length > 500 || /^\/(batch|admin\/snapshot)\b/.test(endpoint)) {
+      throw new Error(`"${label}": endpoint must be an API path starting with "/"`);
+    }
+    if (!field.startsWith('$') || field.length > 200) throw new Error(`"${label}": field must be a JSONPath expression starting with "$"`);
+    const format = (m.format || 'number') as MetricFormat;
+    if (!FORMATS.includes(format)) throw new Error(`"${label}": format must be one of ${FORMATS.join(', ')}`);
+    let id = String(m.id || '').replace(/[^a-z0-9-]/gi, '').slice(0, 40) || label.toLowerCase().replace(/[^a-z0-9]+/g, '-').replace(/^-|-$/g, '').slice(0, 40) || `metric-${i + 1}`;
+    for (let n = 2; ids.has(id); n++) id = `${id.replace(/-\d+$/, '')}-${n}`;
+    ids.add(id);
+    return { id, label, endpoint, field, format, description: m.description ? String(m.description).slice(0, 300) : undefined };
+  });
+}
+
+export function registerDashboardMetricRoutes(
+  api: Hono<any>,
+  opts: { getAdminDb: () => any; requireRole: (role: any) => any },
+) {
+  const { getAdminDb, requireRole } = opts;
+  const engineDb = () => getAdminDb()?.getEngineDB?.() || null;
+
+  const load = async (): Promise<DashboardMetric[]> => {
+    const row = await engineDb()?.get('SELECT value FROM engine_settings WHERE key = ?', [SETTINGS_KEY]).catch(() => undefined);
+    try { return row?.value ? normalizeMetrics(JSON.parse(row.value)) : []; } catch { return []; }
+  };
+
+  // Every signed-in user's dashboard reads the definitions
+  api.get('/dashboard/metrics', async (c) => c.json({ metrics: await load() }));
+
+  // Replaces the whole list: { metrics: [...] }
+  api.put('/admin/dashboard-metrics', requireRole('admin'), async (c) => {
+    const edb = engineDb();
+    if (!edb) return c.json({ error: 'Custom metrics need a SQL database' }, 501);
+    const body = await c.req.json().catch(() => ({}));
+    let metrics: DashboardMetric[];
+    try { metrics = normalizeMetrics(body.metrics); } catch (err: any) { return c.json({ error: err.message }, 400); }
+    const before = await load();
+    await edb.run('DELETE FROM engine_settings WHERE key = ?', [SETTINGS_KEY]);
+    await edb.run('INSERT INTO engine_settings (key, value) VALUES (?, ?)', [SETTINGS_KEY, JSON.stringify(metrics)]);
+    getAdminDb()?.logEvent({
+      actor: c.get('userId') || 'unknown',
+      actorType: 'user',
+      action: 'dashboard.metrics_update',
+      resource: 'dashboard_metrics',
+      details: { metrics: metrics.map(m => m.label), previous: before.map(m => m.label) },
+      ip: c.req.header('x-forwarded-for')?.split(',')[0]?.trim() || c.req.header('x-real-ip'),
+    }).catch(() => {});
+    return c.json({ success: true, metrics });
+  });
+}
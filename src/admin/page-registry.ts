@@ -40,16 +40,22 @@ export const PAGE_REGISTRY: Record<string, PageDef> = {
       tools: 'Tools',
       skills: 'Skills',
       permissions: 'Permissions',
+      credentials: 'Credentials',
+      webhooks: 'Webhooks',
       activity: 'Activity',
+      conversations: 'Conversations',
+      logs: 'Logs',
       communication: 'Communication',
       workforce: 'Workforce',
       memory: 'Memory',
       guardrails: 'Guardrails',
       autonomy: 'Autonomy',
       budget: 'Budget',
+      usage: 'Usage',
       security: 'Security',
       'tool-security': 'Tool Security',
       deployment: 'Deployment',
+      lifecycle: 'Lifecycle',
     },
   },
   skills: {
@@ -153,6 +159,7 @@ export const PAGE_REGISTRY: Record<string, PageDef> = {
       tasks: 'Task Queue',
       budgets: 'Budgets',
       history: 'Clock History',
+      maintenance: 'Maintenance',
     },
   },
   messages: {
@@ -164,6 +171,21 @@ export const PAGE_REGISTRY: Record<string, PageDef> = {
       topology: 'Topology',
     },
   },
+  qa: {
+    label: 'Quality Review',
+    section: 'management',
+    description: 'Human grading of sampled agent replies and quality trends',
+    tabs: {
+      queue: 'Review Queue',
+      trends: 'Agent Trends',
+      settings: 'Settings',
+    },
+  },
+  'work-queue': {
+    label: 'Work Queue',
+    section: 'management',
+    description: 'Approvals, escalations, quarantined content and reviews awaiting a human, with SLA timers',
+  },
   guardrails: {
     label: 'Guardrails',
     section: 'management',
@@ -196,6 +218,36 @@ export const PAGE_REGISTRY: Record<string, PageDef> = {
       assessments: 'Assessments',
     },
   },
+  'trust-center': {
+    label: 'Trust Center',
+    section: 'administration',
+    description: 'Shareable summary of the organization\'s security controls, built from live configuration',
+  },
+  ediscovery: {
+    label: 'e-Discovery',
+    section: 'administration',
+    description: 'Legal cases bundling saved searches, holds, transcripts and reviewer notes, restricted to custodians',
+  },
+  'change-calendar': {
+    label: 'Change Calendar',
+    section: 'administration',
+    description: 'Upcoming maintenance, rule changes, compliance reports and retention purges, with iCal export',
+  },
+  storage: {
+    label: 'Storage',
+    section: 'administration',
+    description: 'Storage used by messages, attachments, journal, knowledge and audit, per agent, with trends',
+  },
+  attachments: {
+    label: 'Attachments',
+    section: 'administration',
+    description: 'Large and old attachments across agents, with bulk delete and archive',
+  },
+  housekeeping: {
+    label: 'Housekeeping',
+    section: 'administration',
+    description: 'Duplicate agents, orphaned API keys, unused skills and unread vault secrets, with cleanup',
+  },
   'domain-status': {
     label: 'Domain',
     section: 'administration',
@@ -206,6 +258,11 @@ export const PAGE_REGISTRY: Record<string, PageDef> = {
     section: 'administration',
     description: 'Manage dashboard users, roles, and permissions',
   },
+  teams: {
+    label: 'Teams',
+    section: 'management',
+    description: 'Group agents into teams and see each team\'s activity and cost',
+  },
   roles: {
     label: 'Roles',
     section: 'management',
@@ -234,6 +291,21 @@ export const PAGE_REGISTRY: Record<string, PageDef> = {
     section: 'administration',
     description: 'Full audit trail of all system actions',
   },
+  changes: {
+    label: 'What Changed',
+    section: 'administration',
+    description: 'Readable report of agent, user, rule and settings changes between two points in time',
+  },
+  'call-log': {
+    label: 'Call Log',
+    section: 'administration',
+    description: 'Backend API calls made for each dashboard action (owner only)',
+  },
+  performance: {
+    label: 'Performance',
+    section: 'administration',
+    description: 'Dashboard render times against their budgets and the slowest backend calls behind them',
+  },
   settings: {
     label: 'Settings',
     section: 'administration',
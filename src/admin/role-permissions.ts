@@ -0,0 +1,62 @@
+/**
+ * Role Permission Matrix — which roles may use each dashboard capability.
+ *
+ * Admins can read the matrix; only owners can change it, since it decides
+ * what admins themselves may do. The matrix is org-wide and lives in the
+ * engine's engine_settings table under 'role_permissions'.
+ */
+
+import type { Hono } from 'hono';
+import {
+  CAPABILITIES, EDITABLE_ROLES, DEFAULT_ROLE_MATRIX,
+  normalizeRoleMatrix, setRolePermissionsSource, invalidateRolePermissions, getRolePermissions,
+  type RoleMatrix,
+} from '../middleware/role-permissions.js';
+
+const SETTINGS_KEY = 'role_permissions';
+
+export function registerRolePermissionRoutes(
+  api: Hono<any>,
+  opts: { getAdminDb: () => any; requireRole: (role: any) => any },
+) {
+  const { getAdminDb, requireRole } = opts;
+  const engineDb = () => getAdminDb()?.getEngineDB?.() || null;
+
+  // Read errors propagate so the cache keeps the last good matrix
+  setRolePermissionsSource(async () => {
+    const row = await engineDb()?.get('SELECT value FROM engine_settings WHERE key = ?', [SETTINGS_KEY]);
+    return row?.value ? JSON.parse(row.value) : null;
+  });
+
+  api.get('/admin/role-permissions', requireRole('admin'), async (c) => {
+    return c.json({ capabilities: CAPABILITIES, roles: EDITABLE_ROLES, matrix: await getRolePermissions(), defaults: DEFAULT_ROLE_MATRIX });
+  });
+
+  // Replaces the whole matrix: { matrix: { 'vault.view': ['admin', 'member'], ... } }
+  api.put('/admin/role-permissions', requireRole('owner'), async (c) => {
+    const edb = engineDb();
+    if (!edb) return c.json({ error: 'The permission matrix needs a SQL database' }, 501);
+    const body = await c.req.json().catch(() => ({}));
+    let matrix: RoleMatrix;
+    try { matrix = normalizeRoleMatrix(body.matrix); } catch (err: any) { return c.json({ error: err.message }, 400); }
+    const before = await getRolePermissions();
+    await edb.run('DELETE FROM engine_settings WHERE key = ?', [SETTINGS_KEY]);
+    await edb.run('INSERT INTO engine_settings (key, value) VALUES (?, ?)', [SETTINGS_KEY, JSON.stringify(matrix)]);
+    await invalidateRolePermissions();
+
+    const changes = CAPABILITIES.flatMap(cap => {
+      const granted = matrix[cap.id].filter(r => !before[cap.id]?.includes(r));
+      const revoked = (before[cap.id] || []).filter(r => !matrix[cap.id].includes(r));
+      return granted.length || revoked.length ? [{ capability: cap.id, granted, revoked }] : [];
+    });
+    getAdminDb()?.logEvent({
+      actor: c.get('userId') || 'unknown',
+      actorType: 'user',
+      action: 'settings.role_permissions_update',
+      resource: 'role_permissions',
+      details: { changes },
+      ip: c.req.header('x-forwarded-for')?.split(',')[0]?.trim() || c.req.header('x-real-ip'),
+    }).catch(() => {});
+    return c.json({ success: true, matrix });
+  });
+}
@@ -9,12 +9,57 @@
 import { Hono } from 'hono';
 import { configBus } from '../engine/config-bus.js';
 import type { AppEnv } from '../types/hono-env.js';
-import type { DatabaseAdapter } from '../db/adapter.js';
-import { validate, requireRole, ValidationError, transportEncryptionMiddleware } from '../middleware/index.js';
+import { AUDIT_SORT_FIELDS, type DatabaseAdapter, type User } from '../db/adapter.js';
+import type { PasswordResetService } from '../auth/password-reset.js';
+import { parseSort, sortRows } from '../lib/sort.js';
+import { filterByQuery } from '../lib/filter.js';
+import { CLASSIFICATION_LEVELS, isClassificationLevel } from '../lib/classification.js';
+import { loadAuditRetention, saveAuditRetention, normalizeAuditRetention, previewAuditPurge, MIN_AUDIT_RETAIN_DAYS, MAX_AUDIT_RETAIN_DAYS } from '../engine/audit-retention.js';
+import { wantsCsv, wantsJsonl, csvResponse, jsonlResponse, pagesOf, type CsvColumn } from '../lib/csv.js';
+import { PdfDocument, pdfResponse } from '../lib/pdf.js';
+import { validate, requireRole, ValidationError, transportEncryptionMiddleware, API_KEY_SCOPES, API_KEY_RESOURCES, isValidApiKeyScope, lookupIpLocation } from '../middleware/index.js';
+import { normalizeIpAllowlist, setApiKeyIpSource, invalidateApiKeyIpAllowlists, getApiKeyIpAllowlists, type ApiKeyIpAllowlists } from '../middleware/api-key-ip.js';
+import { setApiKeyAgentSource, invalidateApiKeyAgentBindings, getApiKeyAgentBindings, revokeAgentApiKeys, type ApiKeyAgentBindings } from '../middleware/api-key-agents.js';
+import { requireCapability, hasCapability, capabilitiesFor } from '../middleware/role-permissions.js';
 import { registerDuplicateRoutes } from './agent-duplicate.js';
+import { registerDashboardMetricRoutes } from './dashboard-metrics.js';
+import { registerCostOverviewRoutes } from './cost-overview.js';
+import { registerChangeReportRoutes } from './change-report.js';
+import { registerRolePermissionRoutes } from './role-permissions.js';
+import { registerUserOffboardingRoutes } from './user-offboarding.js';
+import { registerAuditAnomalyRoutes, flagAuditAnomalies } from './audit-anomalies.js';
+import { SessionTracker } from '../auth/sessions.js';
+import { teamVisibleAgentIds } from '../engine/teams.js';
+import { DEFAULT_PASSWORD_POLICY, normalizePasswordPolicy, getPasswordPolicy, checkPassword, setUserPassword, setMustChangePassword } from '../auth/password-policy.js';
 import { PROVIDER_REGISTRY, type ProviderDef } from '../runtime/providers.js';
 import { USDC_ADDRESS as USDC_E_SHARED } from '../polymarket-engines/shared.js';
 
+/**
+ * Fragment endpoints (/agents/table, /audit/rows) return just the rows for
+ * one page of a dashboard table, so the table can refresh, paginate, sort
+ * (?sort=&dir=) and filter (?q= plus per-field params) in place without
+ * reloading the rest of the page.
+ */
+const FRAGMENT_MAX_PAGE_SIZE = 200;
+
+function fragmentPage(c: any, defaultSize = 25): { page: number; pageSize: number; offset: number } {
+  const page = Math.max(parseInt(c.req.query('p') || '1', 10) || 1, 1);
+  const pageSize = Math.min(Math.max(parseInt(c.req.query('pageSize') || String(defaultSize), 10) || defaultSize, 1), FRAGMENT_MAX_PAGE_SIZE);
+  return { page, pageSize, offset: (page - 1) * pageSize };
+}
+
+function fragment<T>(rows: T[], page: number, pageSize: number, total: number) {
+  return { rows, page, pageSize, total, pages: Math.max(1, Math.ceil(total / pageSize)), hasMore: page * pageSize < total };
+}
+
+const AGENT_SORT_FIELDS = ['name', 'email', 'role', 'status', 'model', 'createdAt'] as const;
+const USER_SORT_FIELDS = ['name', 'email', 'role', 'isActive', 'totpEnabled', 'createdAt', 'lastLoginAt', 'lastLoginIp', 'lastDevice', 'activeSessions'] as const;
+/** Rows in an audit log PDF; past this a printed log stops being useful and CSV is the better export */
+const AUDIT_PDF_MAX = 5000;
+/** How long a rotated-out API key keeps working by default, and at most */
+const API_KEY_ROTATION_GRACE_HOURS = 24;
+const API_KEY_ROTATION_MAX_GRACE_HOURS = 30 * 24;
+
 /**
  * Validate an API key by making a lightweight request to the provider.
  * Each provider has a different validation endpoint.
@@ -200,7 +245,7 @@ import { executeOrder } from '../agent-tools/tools/polymarket.js';
 // Shared vault instance for encrypting/decrypting provider API keys
 const vault = new SecureVault();
 
-export function createAdminRoutes(db: DatabaseAdapter) {
+export function createAdminRoutes(db: DatabaseAdapter, opts: { passwordReset?: PasswordResetService } = {}) {
   const api = new Hono<AppEnv>();
 
   // Transport encryption middleware — decrypts incoming, encrypts outgoing
@@ -254,41 +299,151 @@ export function createAdminRoutes(db: DatabaseAdapter) {
 
   // ─── Agents ─────────────────────────────────────────
 
+  /**
+   * Merge engine config into admin agent records: identity.role (source of
+   * truth for role), the model provider and id, and the ids of the agent's teams.
+   */
+  async function mergeEngineConfig(agents: any[]): Promise<any[]> {
+    try {
+      const edb = db.getEngineDB?.();
+      if (!edb) return agents;
+      const managed = await edb.all(`SELECT id, config FROM managed_agents`) || [];
+      const configMap = new Map<string, { role?: string; provider?: string; model?: string; avatar?: string }>();
+      for (const m of managed as any[]) {
+        const cfg = typeof m.config === 'string' ? JSON.parse(m.config) : m.config;
+        // Legacy inline data-URL avatars are left out so lists stay small
+        const avatar = typeof cfg?.identity?.avatar === 'string' && !cfg.identity.avatar.startsWith('data:') ? cfg.identity.avatar : undefined;
+        configMap.set(m.id, { role: cfg?.identity?.role, provider: cfg?.model?.provider, model: cfg?.model?.modelId, avatar });
+      }
+      const teamMap = new Map<string, string[]>();
+      for (const m of (await edb.all(`SELECT team_id, agent_id FROM agent_team_members`).catch(() => [])) as any[]) {
+        teamMap.set(m.agent_id, [...(teamMap.get(m.agent_id) || []), m.team_id]);
+      }
+      return agents.map((a: any) => {
+        const engine = configMap.get(a.id);
+        const teams = teamMap.get(a.id) || [];
+        if (!engine) return { ...a, teams };
+        return { ...a, role: engine.role || a.role, provider: engine.provider || null, model: engine.model || null, avatar: engine.avatar || null, teams };
+      });
+    } catch {
+      return agents;
+    }
+  }
+
+  /** Agents the caller may see, or null when they aren't limited to their teams' agents */
+  async function visibleAgentIds(c: any): Promise<Set<string> | null> {
+    if (c.get('authType' as any) === 'api-key') return null;
+    const edb = db.getEngineDB?.();
+    // With no engine database the lookup throws, so scoped users see nothing
+    return teamVisibleAgentIds(async (sql, params) => {
+      if (!edb) throw new Error('Engine database not available');
+      return edb.all(sql, params);
+    }, { id: c.get('userId'), role: c.get('userRole' as any) });
+  }
+
+  // Nor can they reach other agents' sub-routes (usage report, security, deploy...)
+  api.use('/agents/:id/*', async (c, next) => {
+    const visible = await visibleAgentIds(c);
+    if (visible && !visible.has(c.req.param('id'))) return c.json({ error: 'Agent not found' }, 404);
+    return next();
+  });
+
+  /** ?role=&provider=&model=&team= — exact matches on the merged engine config; team is a team id */
+  function agentConfigFilters(c: any) {
+    const filters = { role: c.req.query('role') || '', provider: c.req.query('provider') || '', model: c.req.query('model') || '', team: c.req.query('team') || '' };
+    const active = !!(filters.role || filters.provider || filters.model || filters.team);
+    const apply = (agents: any[]) => !active ? agents : agents.filter((a: any) =>
+      (!filters.role || a.role === filters.role)
+      && (!filters.provider || a.provider === filters.provider)
+      && (!filters.model || a.model === filters.model)
+      && (!filters.team || (a.teams || []).includes(filters.team)));
+    return { active, apply };
+  }
+
+  const AGENT_CSV: CsvColumn[] = [
+    { header: 'id' }, { header: 'name' }, { header: 'email' }, { header: 'role' }, { header: 'status' },
+    { header: 'provider' }, { header: 'model' }, { header: 'clientOrgId', value: a => a.client_org_id }, { header: 'createdBy' }, { header: 'createdAt' }, { header: 'updatedAt' },
+  ];
+
+  // ?format=csv exports every matching agent rather than one page
   api.get('/agents', async (c) => {
     const status = c.req.query('status') as any;
     const clientOrgId = c.req.query('clientOrgId') || '';
+    const configFilters = agentConfigFilters(c);
+    if (wantsCsv(c)) {
+      const visible = await visibleAgentIds(c);
+      // Same search, filters and order as the Agents table
+      if (visible || clientOrgId || c.req.query('q') || c.req.query('sort') || configFilters.active) {
+        let all: any[] = await mergeEngineConfig(await db.listAgents({ status }));
+        if (visible) all = all.filter((a: any) => visible.has(a.id));
+        if (clientOrgId) all = all.filter((a: any) => a.client_org_id === clientOrgId);
+        all = configFilters.apply(filterByQuery(all, c.req.query('q'), (a: any) => [a.name, a.email, a.role]));
+        sortRows(all, parseSort(c.req.query('sort'), c.req.query('dir'), AGENT_SORT_FIELDS, { field: 'createdAt', dir: 'desc' }));
+        return csvResponse('agents', AGENT_CSV, pagesOf(all));
+      }
+      return csvResponse('agents', AGENT_CSV, async (offset, limit) => mergeEngineConfig(await db.listAgents({ status, limit, offset })));
+    }
     const limit = Math.min(parseInt(c.req.query('limit') || '50'), 200);
     const offset = Math.max(parseInt(c.req.query('offset') || '0'), 0);
-    let agents = await db.listAgents({ status, limit, offset });
-    let total = await db.countAgents(status);
-    // Filter by client org if requested
-    if (clientOrgId) {
-      agents = agents.filter((a: any) => a.client_org_id === clientOrgId);
-      total = agents.length;
-    }
-    // Merge engine identity.role (source of truth) into admin agent records
-    try {
-      const edb = db.getEngineDB?.();
-      if (edb) {
-        const managed = await edb.all(`SELECT id, config FROM managed_agents`) || [];
-        const roleMap = new Map<string, string>();
-        for (const m of managed as any[]) {
-          const cfg = typeof m.config === 'string' ? JSON.parse(m.config) : m.config;
-          const identityRole = cfg?.identity?.role;
-          if (identityRole) roleMap.set(m.id, identityRole);
-        }
-        agents = agents.map((a: any) => {
-          const engineRole = roleMap.get(a.id);
-          return engineRole ? { ...a, role: engineRole } : a;
-        });
-      }
-    } catch {}
+    const { agents, total } = await queryAgents(c, limit, offset);
     return c.json({ agents, total, limit, offset });
   });
 
+  /**
+   * One page of agents for the list endpoints, with search (?q=), filters
+   * (?status=&clientOrgId=&role=&provider=&model=&team=) and ?sort=&dir= applied
+   * before paging so `total` counts every match. Members and viewers in a team
+   * only get that team's agents.
+   */
+  async function queryAgents(c: any, limit: number, offset: number): Promise<{ agents: any[]; total: number }> {
+    const status = c.req.query('status') as any;
+    const clientOrgId = c.req.query('clientOrgId') || '';
+    const q = c.req.query('q') || '';
+    const configFilters = agentConfigFilters(c);
+    const visible = await visibleAgentIds(c);
+    if (visible || clientOrgId || q || c.req.query('sort') || configFilters.active) {
+      // Team scope, client-org membership, search, engine config and non-default orders aren't indexed by the adapters — filter and sort, then slice
+      let all: any[] = await mergeEngineConfig(await db.listAgents({ status }));
+      if (visible) all = all.filter((a: any) => visible.has(a.id));
+      if (clientOrgId) all = all.filter((a: any) => a.client_org_id === clientOrgId);
+      all = configFilters.apply(filterByQuery(all, q, (a: any) => [a.name, a.email, a.role]));
+      sortRows(all, parseSort(c.req.query('sort'), c.req.query('dir'), AGENT_SORT_FIELDS, { field: 'createdAt', dir: 'desc' }));
+      return { agents: all.slice(offset, offset + limit), total: all.length };
+    }
+    const [agents, total] = await Promise.all([db.listAgents({ status, limit, offset }), db.countAgents(status)]);
+    return { agents: await mergeEngineConfig(agents), total };
+  }
+
+  // Table fragment: only the columns the Agents list renders
+  api.get('/agents/table', async (c) => {
+    const { page, pageSize, offset } = fragmentPage(c);
+    const { agents, total } = await queryAgents(c, pageSize, offset);
+    const rows = agents.map((a: any) => ({
+      id: a.id, name: a.name, email: a.email, role: a.role, status: a.status,
+      provider: a.provider || null, model: a.model || null, teams: a.teams || [], avatar: a.avatar || null,
+      createdAt: a.createdAt, client_org_id: a.client_org_id,
+    }));
+    return c.json(fragment(rows, page, pageSize, total));
+  });
+
+  // Values the Agents list can filter on, from the agents that exist
+  api.get('/agents/facets', async (c) => {
+    const clientOrgId = c.req.query('clientOrgId') || '';
+    const visible = await visibleAgentIds(c);
+    let all: any[] = await mergeEngineConfig(await db.listAgents({}));
+    if (visible) all = all.filter((a: any) => visible.has(a.id));
+    if (clientOrgId) all = all.filter((a: any) => a.client_org_id === clientOrgId);
+    const distinct = (key: string) => Array.from(new Set(all.map((a: any) => a[key]).filter(Boolean))).sort();
+    const teamIds = new Set(all.flatMap((a: any) => a.teams || []));
+    const teamRows = await db.getEngineDB?.()?.all('SELECT id, name FROM agent_teams ORDER BY name').catch(() => []) || [];
+    const teams = (teamRows as any[]).filter(t => teamIds.has(t.id)).map(t => ({ id: t.id, name: t.name }));
+    return c.json({ roles: distinct('role'), providers: distinct('provider'), models: distinct('model'), teams });
+  });
+
   api.get('/agents/:id', async (c) => {
     const agent = await db.getAgent(c.req.param('id'));
-    if (!agent) return c.json({ error: 'Agent not found' }, 404);
+    const visible = agent ? await visibleAgentIds(c) : null;
+    if (!agent || (visible && !visible.has(agent.id))) return c.json({ error: 'Agent not found' }, 404);
     return c.json(agent);
   });
 
@@ -323,6 +478,10 @@ export function createAdminRoutes(db: DatabaseAdapter) {
       { field: 'role', type: 'string', maxLength: 32 },
       { field: 'status', type: 'string', pattern: /^(active|archived|suspended)$/ },
     ]);
+    // Offboarding and retirement only move through the engine's lifecycle flow
+    if (body.status && body.status !== existing.status && ['offboarding', 'retired'].includes(existing.status)) {
+      return c.json({ error: `Agent is ${existing.status}; use the lifecycle flow to change its state` }, 400);
+    }
 
     // If renaming, check for conflicts
     if (body.name && body.name !== existing.name) {
@@ -346,13 +505,28 @@ export function createAdminRoutes(db: DatabaseAdapter) {
     return c.json(agent);
   });
 
+  // Service-account keys bound to an agent stop working once the agent is gone
+  const revokeBoundApiKeys = async (c: any, agent: { id: string; name: string }, reason: string) => {
+    const revoked = await revokeAgentApiKeys(db, agent.id).catch(() => []);
+    for (const key of revoked) {
+      await db.logEvent({
+        actor: c.get('userId') || 'system', actorType: 'user', action: 'api_key.revoked',
+        resource: `api_key:${key.id}`, details: { name: key.name, agentId: agent.id, agentName: agent.name, reason },
+        ip: c.req.header('x-forwarded-for')?.split(',')[0]?.trim(),
+        orgId: c.get('userOrgId' as any) || undefined,
+      }).catch(() => {});
+    }
+    return revoked;
+  };
+
   api.post('/agents/:id/archive', async (c) => {
     const existing = await db.getAgent(c.req.param('id'));
     if (!existing) return c.json({ error: 'Agent not found' }, 404);
     if (existing.status === 'archived') return c.json({ error: 'Agent already archived' }, 400);
 
     await db.archiveAgent(c.req.param('id'));
-    return c.json({ ok: true, status: 'archived' });
+    const revokedKeys = await revokeBoundApiKeys(c, existing, 'agent archived');
+    return c.json({ ok: true, status: 'archived', revokedKeys: revokedKeys.length });
   });
 
   api.post('/agents/:id/restore', async (c) => {
@@ -370,6 +544,7 @@ export function createAdminRoutes(db: DatabaseAdapter) {
     if (!existing) return c.json({ error: 'Agent not found' }, 404);
 
     await db.deleteAgent(c.req.param('id'));
+    await revokeBoundApiKeys(c, existing, 'agent deleted');
     return c.json({ ok: true });
   });
 
@@ -532,10 +707,42 @@ export function createAdminRoutes(db: DatabaseAdapter) {
 
   // ─── Users ──────────────────────────────────────────
 
+  // ?format=csv exports every matching user rather than one page
+  const sessions = new SessionTracker(db);
+
   api.get('/users', requireRole('admin'), async (c) => {
     const limit = Math.min(parseInt(c.req.query('limit') || '50'), 200);
     const offset = Math.max(parseInt(c.req.query('offset') || '0'), 0);
-    const users = await db.listUsers({ limit, offset });
+    const role = c.req.query('role') || '';
+    const status = c.req.query('status') || ''; // 'active' | 'deactivated'
+    const csv = wantsCsv(c);
+    // Last sign-in IP/device and live session count, from the sign-in session log
+    const sessionInfo = await sessions.summaries();
+    const withSessions = (u: any) => {
+      const info = sessionInfo.get(u.id);
+      return { ...u, lastLoginIp: info?.lastLoginIp, lastDevice: info?.lastDevice, activeSessions: info?.activeSessions || 0 };
+    };
+    let users: any[];
+    if (csv || c.req.query('sort') || c.req.query('q') || role || status) {
+      // Adapters only order by created_at and can't search — filter and sort the full list, then slice
+      let all: any[] = (await db.listUsers()).map(withSessions);
+      if (role) all = all.filter(u => u.role === role);
+      if (status) all = all.filter(u => (status === 'deactivated') === (u.isActive === false));
+      all = filterByQuery(all, c.req.query('q'), u => [u.name, u.email]);
+      sortRows(all, parseSort(c.req.query('sort'), c.req.query('dir'), USER_SORT_FIELDS, { field: 'createdAt', dir: 'desc' }));
+      users = csv ? all : all.slice(offset, offset + limit);
+    } else {
+      users = (await db.listUsers({ limit, offset })).map(withSessions);
+    }
+    if (csv) {
+      return csvResponse('users', [
+        { header: 'id' }, { header: 'name' }, { header: 'email' }, { header: 'role' },
+        { header: 'status', value: u => u.isActive === false ? 'deactivated' : 'active' },
+        { header: 'twoFactor', value: u => !!u.totpEnabled }, { header: 'sso', value: u => u.ssoProvider || '' },
+        { header: 'clientOrgId' }, { header: 'createdAt' }, { header: 'lastLoginAt' },
+        { header: 'lastLoginIp' }, { header: 'lastDevice' }, { header: 'activeSessions' },
+      ], pagesOf(users));
+    }
     // Strip sensitive fields
     const safe = users.map(({ passwordHash, totpSecret, totpBackupCodes, ...u }) => u);
     return c.json({ users: safe, limit, offset });
@@ -550,6 +757,14 @@ export function createAdminRoutes(db: DatabaseAdapter) {
       { field: 'password', type: 'string', minLength: 8, maxLength: 128 },
     ]);
 
+    if (body.role === 'owner' && c.get('userRole' as any) !== 'owner') {
+      return c.json({ error: 'Only owners can create owner accounts' }, 403);
+    }
+    if (body.password !== undefined) {
+      const passwordError = await checkPassword(db, body.password);
+      if (passwordError) return c.json({ error: passwordError }, 400);
+    }
+
     // Check duplicate email
     const existing = await db.getUserByEmail(body.email);
     if (existing) return c.json({ error: 'Email already registered' }, 409);
@@ -557,17 +772,7 @@ export function createAdminRoutes(db: DatabaseAdapter) {
     const user = await db.createUser(body);
 
     // Mark as must-reset-password (admin-created accounts)
-    try {
-      await (db as any).pool.query(
-        'UPDATE users SET must_reset_password = TRUE WHERE id = $1',
-        [user.id]
-      );
-    } catch {
-      try {
-        const edb = (db as any).db;
-        if (edb?.prepare) edb.prepare('UPDATE users SET must_reset_password = 1 WHERE id = ?').run(user.id);
-      } catch { /* ignore */ }
-    }
+    await db.setMustResetPassword(user.id, true);
 
     // Set client org if provided
     if (body.clientOrgId) {
@@ -607,6 +812,9 @@ export function createAdminRoutes(db: DatabaseAdapter) {
       { field: 'name', type: 'string', minLength: 1, maxLength: 128 },
       { field: 'role', type: 'string', pattern: /^(owner|admin|member|viewer)$/ },
     ]);
+    if (body.role !== undefined && body.role !== existing.role) {
+      return c.json({ error: 'Change roles with POST /users/:id/role' }, 400);
+    }
 
     const user = await db.updateUser(c.req.param('id'), body);
 
@@ -624,6 +832,88 @@ export function createAdminRoutes(db: DatabaseAdapter) {
     return c.json(safe);
   });
 
+  // ─── Role Changes ──────────────────────────────────
+  //
+  // Roles change only through this endpoint so the guardrails always apply:
+  // only owners can grant or take away the owner role, the last active owner
+  // can't be demoted, and promoting to owner needs step-up verification
+  // (the acting owner's password, plus a 2FA code if they have 2FA on).
+
+  // Active owners; role changes, deactivation and deletes may not leave none
+  const countActiveOwners = async () => (await db.listUsers()).filter(u => u.role === 'owner' && u.isActive !== false).length;
+
+  const verifyStepUp = async (userId: string, body: any): Promise<{ method: string } | { error: string; status: 400 | 401 }> => {
+    const actor = await db.getUser(userId);
+    if (!actor) return { error: 'Step-up verification failed', status: 401 };
+    const methods: string[] = [];
+    if (actor.passwordHash) {
+      if (!body.password) return { error: 'Enter your password to confirm', status: 400 };
+      const { default: bcrypt } = await import('bcryptjs');
+      if (!(await bcrypt.compare(String(body.password), actor.passwordHash))) return { error: 'Incorrect password', status: 401 };
+      methods.push('password');
+    }
+    if (actor.totpEnabled && actor.totpSecret) {
+      if (!body.totpCode) return { error: 'Enter your 2FA code to confirm', status: 400 };
+      const { verifyTotp } = await import('../lib/totp.js');
+      if (!(await verifyTotp(actor.totpSecret, String(body.totpCode).replace(/\s/g, '')))) return { error: 'Invalid 2FA code', status: 401 };
+      methods.push('totp');
+    }
+    // SSO-only accounts have nothing to re-check without 2FA
+    if (methods.length === 0) return { error: 'Turn on two-factor authentication to promote users to owner', status: 400 };
+    return { method: methods.join('+') };
+  };
+
+  // { role, password?, totpCode? } — password/totpCode are required when promoting to owner
+  api.post('/users/:id/role', requireRole('admin'), async (c) => {
+    const existing = await db.getUser(c.req.param('id'));
+    if (!existing) return c.json({ error: 'User not found' }, 404);
+    const body = await c.req.json().catch(() => ({}));
+    try {
+      validate(body, [{ field: 'role', type: 'string', required: true, pattern: /^(owner|admin|member|viewer)$/ }]);
+    } catch (e: any) { return c.json({ error: e.message }, 400); }
+
+    const previousRole = existing.role;
+    const newRole = body.role as typeof existing.role;
+    if (previousRole === newRole) return c.json({ error: `${existing.name || existing.email} is already ${newRole === 'admin' ? 'an' : 'a'} ${newRole}` }, 400);
+
+    const requesterId = c.get('userId');
+    const ownerChange = previousRole === 'owner' || newRole === 'owner';
+    if (ownerChange && (c.get('authType' as any) === 'api-key' || c.get('userRole' as any) !== 'owner')) {
+      return c.json({ error: newRole === 'owner' ? 'Only owners can promote users to owner' : 'Only owners can change an owner\'s role' }, 403);
+    }
+    if (previousRole === 'owner' && existing.isActive !== false && await countActiveOwners() <= 1) {
+      return c.json({ error: 'This is the last owner. Promote another user to owner first.' }, 409);
+    }
+
+    let stepUp: string | undefined;
+    if (newRole === 'owner') {
+      const result = await verifyStepUp(requesterId, body);
+      if ('error' in result) {
+        await db.logEvent({
+          actor: requesterId || 'system', actorType: 'user', action: 'user.role_change_denied',
+          resource: `user:${existing.id}`, details: { targetEmail: existing.email, previousRole, newRole, reason: result.error },
+          ip: c.req.header('x-forwarded-for')?.split(',')[0]?.trim() || c.req.header('x-real-ip'),
+          orgId: c.get('userOrgId' as any) || undefined,
+        }).catch(() => {});
+        return c.json({ error: result.error, stepUpRequired: true }, result.status);
+      }
+      stepUp = result.method;
+    }
+
+    const user = await db.updateUser(existing.id, { role: newRole });
+    // Their tokens still carry the old role; signing them out makes the change take effect now
+    await sessions.endAllForUser(existing.id);
+    await db.logEvent({
+      actor: requesterId || 'system', actorType: 'user', action: 'user.role_changed',
+      resource: `user:${existing.id}`, details: { targetEmail: existing.email, previousRole, newRole, stepUp, self: requesterId === existing.id },
+      ip: c.req.header('x-forwarded-for')?.split(',')[0]?.trim() || c.req.header('x-real-ip'),
+      orgId: c.get('userOrgId' as any) || undefined,
+    }).catch(() => {});
+
+    const { passwordHash, totpSecret, totpBackupCodes, ...safe } = user;
+    return c.json({ user: safe, previousRole, newRole });
+  });
+
   // ─── Reset Password (admin/owner can reset any user's password) ──
 
   api.post('/users/:id/reset-password', requireRole('admin'), async (c) => {
@@ -632,26 +922,19 @@ export function createAdminRoutes(db: DatabaseAdapter) {
 
     const body = await c.req.json();
     const newPassword = body.password;
+    const passwordError = await checkPassword(db, newPassword);
+    if (passwordError) return c.json({ error: passwordError }, 400);
 
-    if (!newPassword || typeof newPassword !== 'string' || newPassword.length < 8) {
-      return c.json({ error: 'Password must be at least 8 characters' }, 400);
-    }
-
-    const { default: bcrypt } = await import('bcryptjs');
-    const passwordHash = await bcrypt.hash(newPassword, 12);
-
-    // Use raw SQL via the pool — updateUser doesn't handle password_hash
-    await (db as any).pool.query(
-      'UPDATE users SET password_hash = $1, updated_at = NOW() WHERE id = $2',
-      [passwordHash, c.req.param('id')]
-    );
+    // { mustChange: false } keeps the password; otherwise it's temporary and must be changed at next sign-in
+    const mustChange = body.mustChange !== false;
+    await setUserPassword(db, c.req.param('id'), newPassword, { mustChange });
 
     await db.logEvent({
       actor: c.get('userId') || 'system',
       actorType: 'user',
       action: 'user.password_reset',
       resource: `user:${c.req.param('id')}`,
-      details: { targetEmail: existing.email, resetBy: 'admin' },
+      details: { targetEmail: existing.email, resetBy: 'admin', mustChange },
       ip: c.req.header('x-forwarded-for')?.split(',')[0]?.trim() || c.req.header('x-real-ip'),
       orgId: c.get('userOrgId' as any) || undefined,
     }).catch(() => {});
@@ -659,20 +942,99 @@ export function createAdminRoutes(db: DatabaseAdapter) {
     return c.json({ ok: true, message: 'Password reset successfully' });
   });
 
-  // ─── Deactivate / Reactivate User ──────────────────
+  // ─── Force Password Rotation ───────────────────────
+  // { required?: boolean } — sends the user to the change-password screen at next sign-in
 
-  api.post('/users/:id/deactivate', requireRole('admin'), async (c) => {
+  api.post('/users/:id/require-password-change', requireRole('admin'), async (c) => {
     const existing = await db.getUser(c.req.param('id'));
     if (!existing) return c.json({ error: 'User not found' }, 404);
-    const requesterId = c.get('userId');
-    if (requesterId === c.req.param('id')) return c.json({ error: 'Cannot deactivate your own account' }, 400);
+    if (existing.role === 'owner' && c.get('userRole' as any) !== 'owner') {
+      return c.json({ error: 'Only owners can force an owner to change their password' }, 403);
+    }
+    if (!existing.passwordHash) return c.json({ error: 'This user signs in with SSO and has no password to change' }, 400);
+    const body = await c.req.json().catch(() => ({}));
+    const required = body.required !== false;
+    await setMustChangePassword(db, existing.id, required);
+
+    await db.logEvent({
+      actor: c.get('userId') || 'system', actorType: 'user',
+      action: required ? 'user.password_rotation_required' : 'user.password_rotation_cleared',
+      resource: `user:${existing.id}`, details: { targetEmail: existing.email },
+      ip: c.req.header('x-forwarded-for')?.split(',')[0]?.trim() || c.req.header('x-real-ip'),
+      orgId: c.get('userOrgId' as any) || undefined,
+    }).catch(() => {});
+
+    return c.json({ ok: true, mustResetPassword: required });
+  });
+
+  // ─── Send Reset Link ───────────────────────────────
+  // Emails the user a one-time link. Without SMTP the link is returned so
+  // the admin can pass it on another way. The link is only ever built from
+  // ENTERPRISE_URL, never the request's Host header.
+
+  api.post('/users/:id/send-reset', requireRole('admin'), async (c) => {
+    const reset = opts.passwordReset;
+    if (!reset) return c.json({ error: 'Password reset links are not available' }, 501);
+    const existing = await db.getUser(c.req.param('id'));
+    if (!existing) return c.json({ error: 'User not found' }, 404);
+    if (existing.isActive === false) return c.json({ error: 'Reactivate this user before sending a reset link' }, 400);
+    if (existing.role === 'owner' && c.get('userRole' as any) !== 'owner') {
+      return c.json({ error: 'Only owners can reset an owner\'s password' }, 403);
+    }
+
+    const baseUrl = reset.trustedBaseUrl();
+    if (!baseUrl) return c.json({ error: 'Set the dashboard URL (ENTERPRISE_URL) before sending reset links' }, 400);
+    const { link, expiresAt } = await reset.createLink(existing, baseUrl);
+    let sent = false;
+    if (await reset.smtpConfigured()) {
+      try {
+        await reset.sendEmail(existing, link, 'admin');
+        sent = true;
+      } catch (err: any) {
+        return c.json({ error: `Could not send the reset email: ${err.message}` }, 502);
+      }
+    }
+
+    await db.logEvent({
+      actor: c.get('userId') || 'system',
+      actorType: 'user',
+      action: 'user.password_reset_sent',
+      resource: `user:${existing.id}`,
+      details: { targetEmail: existing.email, delivery: sent ? 'email' : 'link' },
+      ip: c.req.header('x-forwarded-for')?.split(',')[0]?.trim() || c.req.header('x-real-ip'),
+      orgId: c.get('userOrgId' as any) || undefined,
+    }).catch(() => {});
+
+    return c.json(sent ? { sent, email: existing.email, expiresAt } : { sent, link, expiresAt });
+  });
+
+  // ─── Deactivate / Reactivate User ──────────────────
+
+  const canDeactivate = async (target: User, requesterId: string | undefined): Promise<{ error: string; status: 400 | 409 } | null> => {
+    if (requesterId === target.id) return { error: 'Cannot deactivate your own account', status: 400 };
+    if (target.role === 'owner' && target.isActive !== false && await countActiveOwners() <= 1) {
+      return { error: 'Cannot deactivate the last owner', status: 409 };
+    }
+    return null;
+  };
 
+  const setUserActive = async (userId: string, active: boolean) => {
+    if (!active) await sessions.endAllForUser(userId);
     try {
-      await (db as any).pool.query('UPDATE users SET is_active = FALSE, updated_at = NOW() WHERE id = $1', [c.req.param('id')]);
+      await (db as any).pool.query('UPDATE users SET is_active = $1, updated_at = NOW() WHERE id = $2', [active, userId]);
     } catch {
       const edb = (db as any).db;
-      if (edb?.prepare) edb.prepare('UPDATE users SET is_active = 0, updated_at = CURRENT_TIMESTAMP WHERE id = ?').run(c.req.param('id'));
+      if (edb?.prepare) edb.prepare('UPDATE users SET is_active = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?').run(active ? 1 : 0, userId);
     }
+  };
+
+  api.post('/users/:id/deactivate', requireRole('admin'), async (c) => {
+    const existing = await db.getUser(c.req.param('id'));
+    if (!existing) return c.json({ error: 'User not found' }, 404);
+    const denied = await canDeactivate(existing, c.get('userId'));
+    if (denied) return c.json({ error: denied.error }, denied.status);
+
+    await setUserActive(existing.id, false);
 
     await db.logEvent({
       actor: c.get('userId') || 'system', actorType: 'user', action: 'user.deactivated',
@@ -688,12 +1050,7 @@ export function createAdminRoutes(db: DatabaseAdapter) {
     const existing = await db.getUser(c.req.param('id'));
     if (!existing) return c.json({ error: 'User not found' }, 404);
 
-    try {
-      await (db as any).pool.query('UPDATE users SET is_active = TRUE, updated_at = NOW() WHERE id = $1', [c.req.param('id')]);
-    } catch {
-      const edb = (db as any).db;
-      if (edb?.prepare) edb.prepare('UPDATE users SET is_active = 1, updated_at = CURRENT_TIMESTAMP WHERE id = ?').run(c.req.param('id'));
-    }
+    await setUserActive(existing.id, true);
 
     await db.logEvent({
       actor: c.get('userId') || 'system', actorType: 'user', action: 'user.reactivated',
@@ -705,6 +1062,9 @@ export function createAdminRoutes(db: DatabaseAdapter) {
     return c.json({ ok: true, message: 'User reactivated' });
   });
 
+  // Reassign a user's agents, API keys and report schedules, then deactivate
+  registerUserOffboardingRoutes(api, { db, requireRole, canDeactivate, setUserActive });
+
   // ─── Delete User (owner only, requires confirmation token) ──
 
   api.delete('/users/:id', requireRole('owner'), async (c) => {
@@ -713,6 +1073,9 @@ export function createAdminRoutes(db: DatabaseAdapter) {
 
     const requesterId = c.get('userId');
     if (requesterId === c.req.param('id')) return c.json({ error: 'Cannot delete your own account' }, 400);
+    if (existing.role === 'owner' && existing.isActive !== false && await countActiveOwners() <= 1) {
+      return c.json({ error: 'Cannot delete the last owner' }, 409);
+    }
 
     // Require confirmation token from frontend (5-step modal flow)
     const body = await c.req.json().catch(() => ({}));
@@ -721,6 +1084,7 @@ export function createAdminRoutes(db: DatabaseAdapter) {
     }
 
     await db.deleteUser(c.req.param('id'));
+    await db.getEngineDB?.()?.run('DELETE FROM agent_team_users WHERE user_id = ?', [c.req.param('id')]).catch(() => {});
 
     await db.logEvent({
       actor: c.get('userId') || 'system', actorType: 'user', action: 'user.deleted',
@@ -812,11 +1176,13 @@ export function createAdminRoutes(db: DatabaseAdapter) {
     if (!userId) return c.json({ error: 'Not authenticated' }, 401);
 
     const user = await db.getUser(userId);
+    // Capabilities from the role permission matrix, for hiding actions in the UI
+    const can = await capabilitiesFor(userRole);
     const clientOrgId = user?.clientOrgId || c.get('clientOrgId' as any) || null;
 
     // Owner and admin always get full access
     if (userRole === 'owner' || userRole === 'admin') {
-      return c.json({ permissions: '*', role: userRole, clientOrgId });
+      return c.json({ permissions: '*', role: userRole, clientOrgId, can });
     }
 
     // Client org users get restricted page access by default
@@ -851,12 +1217,12 @@ export function createAdminRoutes(db: DatabaseAdapter) {
       } catch {}
 
       if (userPerms && userPerms !== '*') {
-        return c.json({ permissions: userPerms, role: userRole, clientOrgId });
+        return c.json({ permissions: userPerms, role: userRole, clientOrgId, can });
       }
-      return c.json({ permissions: clientPages, role: userRole, clientOrgId });
+      return c.json({ permissions: clientPages, role: userRole, clientOrgId, can });
     }
 
-    return c.json({ permissions: user?.permissions ?? '*', role: userRole, clientOrgId });
+    return c.json({ permissions: user?.permissions ?? '*', role: userRole, clientOrgId, can });
   });
 
   // ─── Platform Capabilities ──────────────────────────
@@ -920,7 +1286,9 @@ export function createAdminRoutes(db: DatabaseAdapter) {
 
   // ─── Audit Log ──────────────────────────────────────
 
-  api.get('/audit', requireRole('admin'), async (c) => {
+  // ?format=csv and ?format=jsonl export every matching event rather than one page;
+  // ?format=pdf renders the newest AUDIT_PDF_MAX of them as a printable report
+  api.get('/audit', requireCapability('audit.view'), async (c) => {
     const filters = {
       actor: c.req.query('actor') || undefined,
       action: c.req.query('action') || undefined,
@@ -940,19 +1308,306 @@ export function createAdminRoutes(db: DatabaseAdapter) {
       return c.json({ error: 'Invalid "to" date' }, 400);
     }
 
+    if ((wantsCsv(c) || wantsJsonl(c) || c.req.query('format') === 'pdf') && !(await hasCapability(c, 'audit.export'))) {
+      return c.json({ error: 'Insufficient permissions', required: 'audit.export' }, 403);
+    }
+
+    if (wantsCsv(c) || wantsJsonl(c)) {
+      // Pin the end of the range so events logged mid-export don't shift the pages
+      const exportFilters = { ...filters, to: filters.to || new Date() };
+      const fetchPage = async (offset: number, limit: number) => (await db.queryAudit({ ...exportFilters, limit, offset })).events;
+      await db.logEvent({
+        actor: c.get('userId') || 'system', actorType: 'user', action: 'audit.exported',
+        resource: 'audit', details: { format: c.req.query('format'), actor: filters.actor, action: filters.action, resource: filters.resource, orgId: filters.orgId, from: filters.from?.toISOString(), to: exportFilters.to.toISOString() },
+        ip: c.req.header('x-forwarded-for')?.split(',')[0]?.trim(),
+        orgId: c.get('userOrgId' as any) || undefined,
+      }).catch(() => {});
+      if (wantsJsonl(c)) return jsonlResponse('audit-log', fetchPage);
+      return csvResponse('audit-log', [
+        { header: 'id' }, { header: 'timestamp' }, { header: 'actor' }, { header: 'actorType' },
+        { header: 'action' }, { header: 'resource' }, { header: 'orgId' }, { header: 'ip' }, { header: 'details' },
+      ], fetchPage);
+    }
+
+    if (c.req.query('format') === 'pdf') {
+      const { events, total } = await db.queryAudit({ ...filters, limit: AUDIT_PDF_MAX, offset: 0 });
+      const doc = new PdfDocument({ title: 'Audit Log', landscape: true });
+      doc.heading('Audit Log', 1);
+      doc.paragraph(`Generated ${new Date().toLocaleString()}` + (total > events.length ? ` \u2022 newest ${events.length.toLocaleString()} of ${total.toLocaleString()} events; export CSV for the full log` : ` \u2022 ${total.toLocaleString()} events`), { gray: 0.4 });
+      const applied = Object.entries({ Actor: filters.actor, Action: filters.action, Resource: filters.resource, Org: filters.orgId, From: filters.from?.toISOString(), To: filters.to?.toISOString() })
+        .filter(([, v]) => v) as Array<[string, string]>;
+      if (applied.length) doc.keyValues(applied);
+      doc.table(['Time', 'Actor', 'Action', 'Resource', 'IP', 'Details'], events.map((e: any) => [
+        new Date(e.timestamp).toISOString().replace('T', ' ').slice(0, 19), e.actor, e.action, e.resource, e.ip || '',
+        e.details && Object.keys(e.details).length ? JSON.stringify(e.details) : '',
+      ]), { weights: [70, 80, 90, 100, 50, 180], maxLines: 4 });
+      return pdfResponse('audit-log', doc);
+    }
+
     const result = await db.queryAudit(filters);
-    return c.json(result);
+    return c.json({ ...result, events: await flagAuditAnomalies(db, result.events) });
   });
 
+  // Rows fragment for the Audit Log table (?p=2&pageSize=50&action=...)
+  api.get('/audit/rows', requireCapability('audit.view'), async (c) => {
+    const { page, pageSize, offset } = fragmentPage(c, 50);
+    const from = c.req.query('from') ? new Date(c.req.query('from')!) : undefined;
+    const to = c.req.query('to') ? new Date(c.req.query('to')!) : undefined;
+    if ((from && isNaN(from.getTime())) || (to && isNaN(to.getTime()))) {
+      return c.json({ error: 'Invalid date range' }, 400);
+    }
+    const result = await db.queryAudit({
+      actor: c.req.query('actor') || undefined,
+      action: c.req.query('action') || undefined,
+      resource: c.req.query('resource') || undefined,
+      orgId: c.req.query('orgId') || undefined,
+      from, to, limit: pageSize, offset,
+      sort: parseSort(c.req.query('sort'), c.req.query('dir'), AUDIT_SORT_FIELDS, { field: 'timestamp', dir: 'desc' }),
+    });
+    return c.json(fragment(await flagAuditAnomalies(db, result.events), page, pageSize, result.total));
+  });
+
+  // Unusual hours, new IPs and bursts of destructive actions
+  registerAuditAnomalyRoutes(api, { db });
+
+  // The agent, user or API key an audit resource points at ("agent:<id>",
+  // "/api/users/<id>/role", ...), if it still exists
+  const auditRelated = async (resource: string | undefined) => {
+    if (!resource) return null;
+    const tagged = /^(agent|user|api_key):([^/\s]+)$/.exec(resource);
+    const m = tagged || /\/(agents|users|api-keys)\/([^/?]+)/.exec(resource);
+    if (!m) return null;
+    const type = ({ agents: 'agent', users: 'user', 'api-keys': 'api_key' } as Record<string, string>)[m[1]] || m[1];
+    const id = m[2];
+    // A path segment that matches nothing is a sub-route (/users/me), not a deleted record
+    const missing = tagged ? { type, id, missing: true } : null;
+    try {
+      if (type === 'agent') { const a = await db.getAgent(id); return a ? { type, id, name: a.name, status: a.status } : missing; }
+      if (type === 'user') { const u = await db.getUser(id); return u ? { type, id, name: u.name, email: u.email } : missing; }
+      const k = await db.getApiKey(id); return k ? { type, id, name: k.name, revoked: k.revoked } : missing;
+    } catch {
+      return null;
+    }
+  };
+
+  // Context for the audit detail drawer: the same actor's events either side
+  // of one event, what its resource points at, and where its IP is.
+  // ?actor=&at=<timestamp>&id=<event id>&resource=&ip=&orgId=&n=5
+  api.get('/audit/context', requireCapability('audit.view'), async (c) => {
+    const actor = c.req.query('actor');
+    const at = new Date(c.req.query('at') || '');
+    if (!actor || isNaN(at.getTime())) return c.json({ error: 'actor and at are required' }, 400);
+    const id = c.req.query('id');
+    const orgId = c.req.query('orgId') || undefined;
+    const n = Math.min(Math.max(parseInt(c.req.query('n') || '5') || 5, 1), 20);
+    const ip = c.req.query('ip');
+
+    const [older, newer, location, related] = await Promise.all([
+      db.queryAudit({ actor, orgId, to: at, limit: n + 1, sort: { field: 'timestamp', dir: 'desc' } }),
+      db.queryAudit({ actor, orgId, from: at, limit: n + 1, sort: { field: 'timestamp', dir: 'asc' } }),
+      ip ? lookupIpLocation(ip) : Promise.resolve(null),
+      auditRelated(c.req.query('resource')),
+    ]);
+    // Events sharing the timestamp come back from both queries
+    const before = older.events.filter(e => e.id !== id).slice(0, n);
+    const seen = new Set(before.map(e => e.id));
+    const after = newer.events.filter(e => e.id !== id && !seen.has(e.id)).slice(0, n);
+    // before is newest first, after oldest first — both step away from the event
+    return c.json({ before, after, location, related });
+  });
+
+  // ─── Dashboard Call Log ─────────────────────────────
+
+  api.get('/admin/call-log', requireRole('owner'), async (c) => {
+    const { listActions } = await import('../lib/call-log.js');
+    const actions = listActions({
+      userId: c.req.query('userId') || undefined,
+      search: c.req.query('q') || undefined,
+      failedOnly: c.req.query('failed') === 'true',
+      limit: parseInt(c.req.query('limit') || '100'),
+    });
+    // Resolve user emails for display
+    const emails: Record<string, string> = {};
+    for (const id of new Set(actions.map(a => a.userId).filter(Boolean) as string[])) {
+      try { const u = await db.getUser(id); if (u) emails[id] = u.email; } catch { /* deleted user */ }
+    }
+    return c.json({ actions: actions.map(a => ({ ...a, userEmail: a.userId ? emails[a.userId] : undefined })) });
+  });
+
+  api.delete('/admin/call-log', requireRole('owner'), async (c) => {
+    const { clearCallLog } = await import('../lib/call-log.js');
+    clearCallLog();
+    return c.json({ ok: true });
+  });
+
+  // ─── Dashboard Performance ──────────────────────────
+
+  // Render times are reported by every signed-in user's browser; reading them is admin-only
+  api.post('/admin/performance/renders', async (c) => {
+    const { recordRender } = await import('../lib/performance.js');
+    const body = await c.req.json().catch(() => ({}));
+    recordRender(String(body.page || ''), Number(body.durationMs), c.get('userId' as any) || undefined);
+    return c.json({ ok: true });
+  });
+
+  api.get('/admin/performance', requireRole('admin'), async (c) => {
+    const { summarizePerformance, listBudgets, listAlerts } = await import('../lib/performance.js');
+    const windowMin = Math.min(Math.max(parseInt(c.req.query('window') || '15') || 15, 1), 24 * 60);
+    return c.json({ windowMinutes: windowMin, pages: summarizePerformance(windowMin * 60_000), budgets: listBudgets(), alerts: listAlerts().slice(0, 50) });
+  });
+
+  api.get('/admin/performance/alerts', requireRole('admin'), async (c) => {
+    const { listAlerts } = await import('../lib/performance.js');
+    return c.json({ alerts: listAlerts({ open: c.req.query('open') === 'true' }) });
+  });
+
+  api.post('/admin/performance/alerts/:id/acknowledge', requireRole('admin'), async (c) => {
+    const { acknowledgeAlert } = await import('../lib/performance.js');
+    const alert = acknowledgeAlert(c.req.param('id'), c.get('userId' as any) || 'dashboard');
+    if (!alert) return c.json({ error: 'Alert not found' }, 404);
+    return c.json({ ok: true, alert });
+  });
+
+  api.delete('/admin/performance', requireRole('owner'), async (c) => {
+    const { clearPerformance } = await import('../lib/performance.js');
+    clearPerformance();
+    return c.json({ ok: true });
+  });
+
+  // ─── Dashboard Plugins ──────────────────────────────
+
+  // Pages the caller's role may open; the dashboard imports each entry module
+  api.get('/dashboard/plugins', async (c) => {
+    const { pluginsForRole } = await import('../lib/dashboard-plugins.js');
+    return c.json({ plugins: pluginsForRole(c.get('userRole' as any), c.get('authType' as any) === 'api-key') });
+  });
+
+  api.get('/admin/dashboard-plugins', requireRole('admin'), async (c) => {
+    const { listPlugins, pluginErrors, pluginsDir } = await import('../lib/dashboard-plugins.js');
+    return c.json({ dir: pluginsDir(), plugins: listPlugins().map(({ dir, ...p }) => p), errors: pluginErrors() });
+  });
+
+  // Rescan the plugins directory after adding or updating a plugin
+  api.post('/admin/dashboard-plugins/reload', requireRole('owner'), async (c) => {
+    const { reloadPlugins, pluginsDir } = await import('../lib/dashboard-plugins.js');
+    const { plugins, errors } = reloadPlugins();
+    return c.json({ dir: pluginsDir(), plugins: plugins.map(({ dir, ...p }) => p), errors });
+  });
+
+  // ─── Custom Dashboard Metrics ───────────────────────
+
+  registerDashboardMetricRoutes(api, { getAdminDb: () => db, requireRole });
+
+  // ─── Cost Overview ──────────────────────────────────
+
+  registerCostOverviewRoutes(api, { getAdminDb: () => db, requireRole, defaultPricing: getDefaultModelPricing });
+
+  // ─── Change Report ──────────────────────────────────
+
+  registerChangeReportRoutes(api, { getAdminDb: () => db, requireRole });
+
+  // ─── Role Permission Matrix ─────────────────────────
+
+  registerRolePermissionRoutes(api, { getAdminDb: () => db, requireRole });
+
   // ─── API Keys ───────────────────────────────────────
 
+  const checkApiKeyScopes = (scopes: unknown[]): string | null => {
+    if (scopes.length === 0) return 'Choose at least one scope';
+    const bad = scopes.find(s => !isValidApiKeyScope(s));
+    return bad === undefined ? null : `Unknown scope: ${String(bad)}`;
+  };
+
+  // Per-key IP allowlists live in engine_settings; the auth middleware reads them through a cache
+  const API_KEY_IP_SETTINGS_KEY = 'api_key_ip_allowlists';
+  setApiKeyIpSource(async () => {
+    const row = await db.getEngineDB()?.get('SELECT value FROM engine_settings WHERE key = ?', [API_KEY_IP_SETTINGS_KEY]);
+    return row?.value ? JSON.parse(row.value) : null;
+  });
+  const setApiKeyIpAllowlist = async (keyId: string, list: string[]) => {
+    const edb = db.getEngineDB();
+    if (!edb) throw new Error('IP restrictions on API keys need a SQL database');
+    const row = await edb.get('SELECT value FROM engine_settings WHERE key = ?', [API_KEY_IP_SETTINGS_KEY]);
+    const all: ApiKeyIpAllowlists = row?.value ? JSON.parse(row.value) : {};
+    if (list.length) all[keyId] = list; else delete all[keyId];
+    await edb.run('DELETE FROM engine_settings WHERE key = ?', [API_KEY_IP_SETTINGS_KEY]);
+    await edb.run('INSERT INTO engine_settings (key, value) VALUES (?, ?)', [API_KEY_IP_SETTINGS_KEY, JSON.stringify(all)]);
+    await invalidateApiKeyIpAllowlists();
+  };
+
+  // Service-account bindings ({ keyId: agentId }) are stored and cached the same way
+  const API_KEY_AGENT_SETTINGS_KEY = 'api_key_agents';
+  setApiKeyAgentSource(async () => {
+    const row = await db.getEngineDB()?.get('SELECT value FROM engine_settings WHERE key = ?', [API_KEY_AGENT_SETTINGS_KEY]);
+    return row?.value ? JSON.parse(row.value) : null;
+  });
+  const setApiKeyAgent = async (keyId: string, agentId: string) => {
+    const edb = db.getEngineDB();
+    if (!edb) throw new Error('Agent-bound API keys need a SQL database');
+    const row = await edb.get('SELECT value FROM engine_settings WHERE key = ?', [API_KEY_AGENT_SETTINGS_KEY]);
+    const all: ApiKeyAgentBindings = row?.value ? JSON.parse(row.value) : {};
+    all[keyId] = agentId;
+    await edb.run('DELETE FROM engine_settings WHERE key = ?', [API_KEY_AGENT_SETTINGS_KEY]);
+    await edb.run('INSERT INTO engine_settings (key, value) VALUES (?, ?)', [API_KEY_AGENT_SETTINGS_KEY, JSON.stringify(all)]);
+    await invalidateApiKeyAgentBindings();
+  };
+
+  // ?agentId= lists only the keys bound to that agent
   api.get('/api-keys', requireRole('admin'), async (c) => {
-    const keys = await db.listApiKeys();
+    const [keys, ipLists, agentBindings] = await Promise.all([db.listApiKeys(), getApiKeyIpAllowlists(), getApiKeyAgentBindings()]);
+    const agentId = c.req.query('agentId');
     // Never expose key hashes
-    const safe = keys.map(({ keyHash, ...k }) => k);
+    const safe = keys
+      .filter(k => !agentId || agentBindings[k.id] === agentId)
+      .map(({ keyHash, ...k }) => ({ ...k, ipAllowlist: ipLists[k.id] || [], agentId: agentBindings[k.id] || null }));
     return c.json({ keys: safe });
   });
 
+  // Replaces the key's IP allowlist: { ipAllowlist: ['10.0.0.0/8', '203.0.113.7'] }; empty allows any IP
+  api.put('/api-keys/:id/ip-allowlist', requireRole('admin'), async (c) => {
+    const existing = await db.getApiKey(c.req.param('id'));
+    if (!existing) return c.json({ error: 'API key not found' }, 404);
+    const body = await c.req.json().catch(() => ({}));
+    let list: string[];
+    try { list = normalizeIpAllowlist(body.ipAllowlist); } catch (err: any) { return c.json({ error: err.message }, 400); }
+    const before = (await getApiKeyIpAllowlists())[existing.id] || [];
+    try { await setApiKeyIpAllowlist(existing.id, list); } catch (err: any) { return c.json({ error: err.message }, 501); }
+    await db.logEvent({
+      actor: c.get('userId') || 'system', actorType: 'user', action: 'api_key.ip_allowlist_updated',
+      resource: `api_key:${existing.id}`, details: { name: existing.name, before, after: list },
+      ip: c.req.header('x-forwarded-for')?.split(',')[0]?.trim(),
+      orgId: c.get('userOrgId' as any) || undefined,
+    }).catch(() => {});
+    return c.json({ ok: true, ipAllowlist: list });
+  });
+
+  // Scopes a key can be given, for the dashboard's scope editor
+  api.get('/api-keys/scopes', requireRole('admin'), (c) => {
+    return c.json({ scopes: API_KEY_SCOPES, resources: API_KEY_RESOURCES.map(({ id, label, access }) => ({ id, label, access })) });
+  });
+
+  // Replaces the key's scopes: { scopes: ['agents:write', 'audit:read'] }
+  api.put('/api-keys/:id/scopes', requireRole('admin'), async (c) => {
+    const existing = await db.getApiKey(c.req.param('id'));
+    if (!existing) return c.json({ error: 'API key not found' }, 404);
+    if (existing.revoked) return c.json({ error: 'This key has been revoked' }, 409);
+    const body = await c.req.json().catch(() => ({}));
+    if (!Array.isArray(body.scopes)) return c.json({ error: 'scopes must be an array' }, 400);
+    const scopes = [...new Set<string>(body.scopes)];
+    const scopeError = checkApiKeyScopes(scopes);
+    if (scopeError) return c.json({ error: scopeError }, 400);
+
+    await db.setApiKeyScopes(existing.id, scopes);
+    await db.logEvent({
+      actor: c.get('userId') || 'system', actorType: 'user', action: 'api_key.scopes_updated',
+      resource: `api_key:${existing.id}`,
+      details: { name: existing.name, added: scopes.filter(s => !existing.scopes.includes(s)), removed: existing.scopes.filter(s => !scopes.includes(s)) },
+      ip: c.req.header('x-forwarded-for')?.split(',')[0]?.trim(),
+      orgId: c.get('userOrgId' as any) || undefined,
+    }).catch(() => {});
+    return c.json({ ok: true, scopes });
+  });
+
   api.post('/api-keys', requireRole('admin'), async (c) => {
     const body = await c.req.json();
     validate(body, [
@@ -960,8 +1615,23 @@ export function createAdminRoutes(db: DatabaseAdapter) {
     ]);
 
     const userId = c.get('userId') || 'system';
-    const scopes = Array.isArray(body.scopes) ? body.scopes : ['*'];
+    const scopes = Array.isArray(body.scopes) ? [...new Set<string>(body.scopes)] : ['*'];
+    const scopeError = checkApiKeyScopes(scopes);
+    if (scopeError) return c.json({ error: scopeError }, 400);
     const expiresAt = body.expiresAt ? new Date(body.expiresAt) : undefined;
+    if (expiresAt && (isNaN(expiresAt.getTime()) || expiresAt.getTime() <= Date.now())) {
+      return c.json({ error: 'expiresAt must be a date in the future' }, 400);
+    }
+    let ipAllowlist: string[];
+    try { ipAllowlist = normalizeIpAllowlist(body.ipAllowlist); } catch (err: any) { return c.json({ error: err.message }, 400); }
+    if (ipAllowlist.length && !db.getEngineDB()) return c.json({ error: 'IP restrictions on API keys need a SQL database' }, 501);
+    const agentId = body.agentId ? String(body.agentId) : null;
+    if (agentId) {
+      const agent = await db.getAgent(agentId);
+      if (!agent) return c.json({ error: 'Agent not found' }, 400);
+      if (agent.status === 'archived') return c.json({ error: 'Keys cannot be bound to an archived agent' }, 400);
+      if (!db.getEngineDB()) return c.json({ error: 'Agent-bound API keys need a SQL database' }, 501);
+    }
 
     const { key, plaintext } = await db.createApiKey({
       name: body.name,
@@ -970,11 +1640,94 @@ export function createAdminRoutes(db: DatabaseAdapter) {
       expiresAt,
     });
 
+    if (ipAllowlist.length) {
+      // A key meant for one network must not go out without its allowlist
+      try { await setApiKeyIpAllowlist(key.id, ipAllowlist); } catch (err: any) {
+        await db.revokeApiKey(key.id).catch(() => {});
+        return c.json({ error: 'Could not save the key\'s IP allowlist: ' + err.message }, 500);
+      }
+    }
+    if (agentId) {
+      // An unbound copy of a bound key must never go out
+      try { await setApiKeyAgent(key.id, agentId); } catch (err: any) {
+        await db.revokeApiKey(key.id).catch(() => {});
+        return c.json({ error: 'Could not bind the key to the agent: ' + err.message }, 500);
+      }
+    }
+
+    await db.logEvent({
+      actor: userId, actorType: 'user', action: 'api_key.created',
+      resource: `api_key:${key.id}`, details: { name: key.name, scopes, expiresAt: expiresAt?.toISOString(), ipAllowlist, agentId },
+      ip: c.req.header('x-forwarded-for')?.split(',')[0]?.trim(),
+      orgId: c.get('userOrgId' as any) || undefined,
+    }).catch(() => {});
+
     // Only time the plaintext key is returned — emphasize this
     const { keyHash, ...safeKey } = key;
     return c.json({
-      key: safeKey,
+      key: { ...safeKey, ipAllowlist, agentId },
+      plaintext,
+      warning: 'Store this key securely. It will not be shown again.',
+    }, 201);
+  });
+
+  // Replace a key with a new one that has the same name, scopes and owner.
+  // The old key keeps working for `graceHours` (0 to stop it now) so callers
+  // can switch over; the new plaintext is returned once, like a create.
+  api.post('/api-keys/:id/rotate', requireRole('admin'), async (c) => {
+    const existing = await db.getApiKey(c.req.param('id'));
+    if (!existing) return c.json({ error: 'API key not found' }, 404);
+    if (existing.revoked) return c.json({ error: 'This key has been revoked' }, 409);
+    if (existing.expiresAt && existing.expiresAt.getTime() <= Date.now()) return c.json({ error: 'This key has already expired' }, 409);
+
+    const body = await c.req.json().catch(() => ({}));
+    const graceHours = body.graceHours === undefined ? API_KEY_ROTATION_GRACE_HOURS : Number(body.graceHours);
+    if (!Number.isFinite(graceHours) || graceHours < 0 || graceHours > API_KEY_ROTATION_MAX_GRACE_HOURS) {
+      return c.json({ error: `graceHours must be from 0 to ${API_KEY_ROTATION_MAX_GRACE_HOURS}` }, 400);
+    }
+
+    // The replacement gets the same lifetime the old key was created with
+    const lifetime = existing.expiresAt ? existing.expiresAt.getTime() - new Date(existing.createdAt).getTime() : 0;
+    const { key, plaintext } = await db.createApiKey({
+      name: existing.name,
+      scopes: existing.scopes,
+      createdBy: existing.createdBy,
+      expiresAt: lifetime > 0 ? new Date(Date.now() + lifetime) : undefined,
+    });
+
+    // The replacement is limited to the same networks and the same agent
+    const ipAllowlist = (await getApiKeyIpAllowlists())[existing.id] || [];
+    if (ipAllowlist.length) {
+      try { await setApiKeyIpAllowlist(key.id, ipAllowlist); } catch (err: any) {
+        await db.revokeApiKey(key.id).catch(() => {});
+        return c.json({ error: 'Could not copy the IP allowlist to the replacement key: ' + err.message }, 500);
+      }
+    }
+    const agentId = (await getApiKeyAgentBindings())[existing.id] || null;
+    if (agentId) {
+      try { await setApiKeyAgent(key.id, agentId); } catch (err: any) {
+        await db.revokeApiKey(key.id).catch(() => {});
+        return c.json({ error: 'Could not bind the replacement key to the agent: ' + err.message }, 500);
+      }
+    }
+
+    const oldExpiresAt = new Date(Date.now() + graceHours * 3_600_000);
+    if (graceHours === 0) await db.revokeApiKey(existing.id);
+    else if (!existing.expiresAt || existing.expiresAt > oldExpiresAt) await db.expireApiKey(existing.id, oldExpiresAt);
+
+    await db.logEvent({
+      actor: c.get('userId') || 'system', actorType: 'user', action: 'api_key.rotated',
+      resource: `api_key:${existing.id}`,
+      details: { name: existing.name, replacementId: key.id, graceHours, oldKeyExpiresAt: graceHours === 0 ? null : oldExpiresAt.toISOString() },
+      ip: c.req.header('x-forwarded-for')?.split(',')[0]?.trim(),
+      orgId: c.get('userOrgId' as any) || undefined,
+    }).catch(() => {});
+
+    const { keyHash, ...safeKey } = key;
+    return c.json({
+      key: { ...safeKey, ipAllowlist, agentId },
       plaintext,
+      previous: { id: existing.id, expiresAt: graceHours === 0 ? null : oldExpiresAt.toISOString(), revoked: graceHours === 0 },
       warning: 'Store this key securely. It will not be shown again.',
     }, 201);
   });
@@ -984,6 +1737,12 @@ export function createAdminRoutes(db: DatabaseAdapter) {
     if (!existing) return c.json({ error: 'API key not found' }, 404);
 
     await db.revokeApiKey(c.req.param('id'));
+    await db.logEvent({
+      actor: c.get('userId') || 'system', actorType: 'user', action: 'api_key.revoked',
+      resource: `api_key:${existing.id}`, details: { name: existing.name },
+      ip: c.req.header('x-forwarded-for')?.split(',')[0]?.trim(),
+      orgId: c.get('userOrgId' as any) || undefined,
+    }).catch(() => {});
     return c.json({ ok: true, revoked: true });
   });
 
@@ -1359,6 +2118,9 @@ export function createAdminRoutes(db: DatabaseAdapter) {
     if (body.egress?.mode && !['allowlist', 'blocklist'].includes(body.egress.mode)) {
       return c.json({ error: 'egress.mode must be "allowlist" or "blocklist"' }, 400);
     }
+    if (body.egress?.classifiedMinLevel && !isClassificationLevel(body.egress.classifiedMinLevel)) {
+      return c.json({ error: 'egress.classifiedMinLevel must be one of ' + CLASSIFICATION_LEVELS.join(', ') }, 400);
+    }
     // Validate CIDR entries
     const { isValidIpOrCidr } = await import('../lib/cidr.js');
     for (const entry of (body.ipAccess?.allowlist || [])) {
@@ -1370,6 +2132,19 @@ export function createAdminRoutes(db: DatabaseAdapter) {
     for (const entry of (body.trustedProxies?.ips || [])) {
       if (!isValidIpOrCidr(entry)) return c.json({ error: 'Invalid IP/CIDR in trusted proxies: ' + entry }, 400);
     }
+    for (const url of (body.network?.failover?.backends || [])) {
+      if (!/^https?:\/\/[a-z0-9.-]+(:\d{1,5})?\/?$/i.test(url)) return c.json({ error: 'Invalid failover backend (expected http(s)://host[:port]): ' + url }, 400);
+    }
+    const embedding = body.network?.securityHeaders?.embedding;
+    if (embedding) {
+      const { isValidFrameAncestor } = await import('../middleware/index.js');
+      for (const origin of (embedding.frameAncestors || [])) {
+        if (!isValidFrameAncestor(origin)) return c.json({ error: 'Invalid frame ancestor (expected https://host[:port]): ' + origin }, 400);
+      }
+      for (const route of (embedding.routes || [])) {
+        if (!/^[a-z0-9-]+$/.test(route)) return c.json({ error: 'Invalid embeddable page ID: ' + route }, 400);
+      }
+    }
     // Self-lockout protection for allowlist mode
     if (body.ipAccess?.enabled && body.ipAccess?.mode === 'allowlist' && body.ipAccess?.allowlist?.length > 0) {
       const clientIp = c.req.header('x-forwarded-for')?.split(',')[0]?.trim() || c.req.header('x-real-ip') || '';
@@ -1656,6 +2431,50 @@ export function createAdminRoutes(db: DatabaseAdapter) {
     return c.json({ ok: true });
   });
 
+  // ─── Audit Log Retention ─────────────────────────────
+  // How long audit events are kept, and whether they go to S3 first.
+  // The server's scheduler applies the policy daily (engine/audit-retention.ts).
+
+  api.get('/audit/retention', requireRole('admin'), async (c) => {
+    const edb = db.getEngineDB();
+    if (!edb) return c.json({ error: 'Audit retention needs a SQL database' }, 501);
+    const policy = await loadAuditRetention(edb);
+    return c.json({ policy, limits: { minDays: MIN_AUDIT_RETAIN_DAYS, maxDays: MAX_AUDIT_RETAIN_DAYS } });
+  });
+
+  // { enabled, retainDays, archiveToS3, s3: { bucket, region, prefix, endpoint } }
+  api.put('/audit/retention', requireRole('owner'), async (c) => {
+    const edb = db.getEngineDB();
+    if (!edb) return c.json({ error: 'Audit retention needs a SQL database' }, 501);
+    const body = await c.req.json().catch(() => ({}));
+    let next;
+    try { next = normalizeAuditRetention(body); } catch (err: any) { return c.json({ error: err.message }, 400); }
+    const before = await loadAuditRetention(edb);
+    const policy = { ...before, ...next, updatedAt: new Date().toISOString(), updatedBy: c.get('userId') || undefined };
+    await saveAuditRetention(edb, policy);
+    await db.logEvent({
+      actor: c.get('userId') || 'system', actorType: 'user', action: 'audit.retention_updated',
+      resource: 'audit_retention',
+      details: {
+        before: { enabled: before.enabled, retainDays: before.retainDays, archiveToS3: before.archiveToS3, bucket: before.s3?.bucket },
+        after: { enabled: policy.enabled, retainDays: policy.retainDays, archiveToS3: policy.archiveToS3, bucket: policy.s3?.bucket },
+      },
+      ip: c.req.header('x-forwarded-for')?.split(',')[0]?.trim(),
+      orgId: c.get('userOrgId' as any) || undefined,
+    }).catch(() => {});
+    return c.json({ ok: true, policy });
+  });
+
+  // How many events a retention period would purge now: ?retainDays=365
+  api.get('/audit/retention/preview', requireRole('admin'), async (c) => {
+    const retainDays = parseInt(c.req.query('retainDays') || '');
+    if (!Number.isInteger(retainDays) || retainDays < MIN_AUDIT_RETAIN_DAYS || retainDays > MAX_AUDIT_RETAIN_DAYS) {
+      return c.json({ error: `retainDays must be from ${MIN_AUDIT_RETAIN_DAYS} to ${MAX_AUDIT_RETAIN_DAYS}` }, 400);
+    }
+    const [preview, stats] = await Promise.all([previewAuditPurge(db, retainDays), db.queryAudit({ limit: 1 })]);
+    return c.json({ ...preview, retainDays, totalEvents: stats.total });
+  });
+
   // ─── Security ────────────────────────────────────────
 
   api.get('/settings/security', requireRole('admin'), async (c) => {
@@ -1676,6 +2495,14 @@ export function createAdminRoutes(db: DatabaseAdapter) {
       if (!securityConfig || typeof securityConfig !== 'object') {
         return c.json({ error: 'securityConfig is required and must be an object' }, 400);
       }
+      if (securityConfig.encryptionPolicy) {
+        const { normalizeEncryptionPolicy } = await import('../engine/data-encryption.js');
+        securityConfig.encryptionPolicy = normalizeEncryptionPolicy(securityConfig.encryptionPolicy);
+      }
+      // The password policy has its own endpoint; keep whatever is stored
+      const current: any = await db.getSettings();
+      if (current?.securityConfig?.passwordPolicy) securityConfig.passwordPolicy = current.securityConfig.passwordPolicy;
+      else delete securityConfig.passwordPolicy;
 
       await updateSettingsAndEmit({ securityConfig } as any);
 
@@ -1693,6 +2520,33 @@ export function createAdminRoutes(db: DatabaseAdapter) {
     }
   });
 
+  // ─── Password Policy ─────────────────────────────────
+  // Stored in securityConfig.passwordPolicy; edited on its own so the
+  // security page's other sections aren't overwritten.
+
+  api.get('/settings/password-policy', requireRole('admin'), async (c) => {
+    return c.json({ policy: await getPasswordPolicy(db), defaults: DEFAULT_PASSWORD_POLICY });
+  });
+
+  api.put('/settings/password-policy', requireRole('admin'), async (c) => {
+    const body = await c.req.json().catch(() => ({}));
+    let policy;
+    try { policy = normalizePasswordPolicy(body.policy); } catch (err: any) { return c.json({ error: err.message }, 400); }
+    const settings: any = await db.getSettings();
+    const before = await getPasswordPolicy(db);
+    await updateSettingsAndEmit({ securityConfig: { ...(settings?.securityConfig || {}), passwordPolicy: policy } });
+
+    await db.logEvent({
+      actor: c.get('userId') || 'system', actorType: 'user', action: 'settings.password_policy_update',
+      resource: 'password_policy',
+      details: { changes: Object.fromEntries(Object.keys(policy).filter(k => (policy as any)[k] !== (before as any)[k]).map(k => [k, { from: (before as any)[k], to: (policy as any)[k] }])) },
+      ip: c.req.header('x-forwarded-for')?.split(',')[0]?.trim() || c.req.header('x-real-ip'),
+      orgId: c.get('userOrgId' as any) || undefined,
+    }).catch(() => {});
+
+    return c.json({ ok: true, policy });
+  });
+
   api.get('/settings/security/events', requireRole('admin'), async (c) => {
     try {
       const query = c.req.query();
@@ -0,0 +1,128 @@
+/**
+ * User Offboarding — hand a departing user's resources to someone else
+ * before their account is deactivated.
+ *
+ * Agents and API keys record the user who created them, and scheduled
+ * reports fetch their pages as their owner, so deactivating a user on its
+ * own leaves keys acting as a disabled account and reports that fail on
+ * every run. The dashboard shows what the user owns, the admin picks who
+ * takes it over (or revokes keys / pauses reports), and one request moves
+ * everything and then deactivates the account.
+ */
+
+import type { Hono } from 'hono';
+import type { DatabaseAdapter, User } from '../db/adapter.js';
+import { REPORT_SETTINGS_KEY, type ReportSchedule } from '../lib/report-schedules.js';
+
+export function registerUserOffboardingRoutes(
+  api: Hono<any>,
+  opts: {
+    db: DatabaseAdapter;
+    requireRole: (role: any) => any;
+    /** Runs the same checks as a plain deactivation; returns an error to stop */
+    canDeactivate: (target: User, requesterId: string | undefined) => Promise<{ error: string; status: 400 | 409 } | null>;
+    setUserActive: (userId: string, active: boolean) => Promise<void>;
+  },
+) {
+  const { db, requireRole, canDeactivate, setUserActive } = opts;
+  const engineDb = () => db.getEngineDB?.() || null;
+
+  const loadSchedules = async (): Promise<ReportSchedule[]> => {
+    const row = await engineDb()?.get('SELECT value FROM engine_settings WHERE key = ?', [REPORT_SETTINGS_KEY]).catch(() => undefined);
+    try { return row?.value ? JSON.parse(row.value) : []; } catch { return []; }
+  };
+
+  const owned = async (userId: string) => {
+    const [agents, apiKeys, schedules] = await Promise.all([
+      db.listAgents(),
+      db.listApiKeys({ createdBy: userId }),
+      loadSchedules(),
+    ]);
+    return {
+      agents: agents.filter(a => a.createdBy === userId && a.status !== 'archived'),
+      apiKeys: apiKeys.filter(k => !k.revoked),
+      schedules: schedules.filter(s => s.owner === userId),
+    };
+  };
+
+  // What the user owns, for the offboarding wizard
+  api.get('/users/:id/offboarding', requireRole('admin'), async (c) => {
+    const user = await db.getUser(c.req.param('id'));
+    if (!user) return c.json({ error: 'User not found' }, 404);
+    const { agents, apiKeys, schedules } = await owned(user.id);
+    return c.json({
+      agents: agents.map(a => ({ id: a.id, name: a.name, email: a.email, status: a.status })),
+      apiKeys: apiKeys.map(k => ({ id: k.id, name: k.name, keyPrefix: k.keyPrefix, lastUsedAt: k.lastUsedAt })),
+      reportSchedules: schedules.map(s => ({ id: s.id, name: s.name, enabled: s.enabled, frequency: s.frequency })),
+    });
+  });
+
+  // { reassignTo, apiKeys: 'reassign' | 'revoke', reportSchedules: 'reassign' | 'disable' }
+  api.post('/users/:id/offboard', requireRole('admin'), async (c) => {
+    const user = await db.getUser(c.req.param('id'));
+    if (!user) return c.json({ error: 'User not found' }, 404);
+    const denied = await canDeactivate(user, c.get('userId'));
+    if (denied) return c.json({ error: denied.error }, denied.status);
+
+    const body = await c.req.json().catch(() => ({}));
+    const keyAction = body.apiKeys || 'reassign';
+    const reportAction = body.reportSchedules || 'reassign';
+    if (!['reassign', 'revoke'].includes(keyAction)) return c.json({ error: "apiKeys must be 'reassign' or 'revoke'" }, 400);
+    if (!['reassign', 'disable'].includes(reportAction)) return c.json({ error: "reportSchedules must be 'reassign' or 'disable'" }, 400);
+
+    const { agents, apiKeys, schedules } = await owned(user.id);
+    const needsTarget = agents.length > 0 || (apiKeys.length > 0 && keyAction === 'reassign') || (schedules.length > 0 && reportAction === 'reassign');
+    let target: User | null = null;
+    if (needsTarget) {
+      if (!body.reassignTo) return c.json({ error: 'Choose a user to take over these resources' }, 400);
+      target = await db.getUser(body.reassignTo);
+      if (!target || target.isActive === false) return c.json({ error: 'The new owner must be an active user' }, 400);
+      if (target.id === user.id) return c.json({ error: 'Choose a different user to take over these resources' }, 400);
+    }
+
+    for (const agent of agents) await db.updateAgent(agent.id, { createdBy: target!.id });
+    for (const key of apiKeys) {
+      if (keyAction === 'revoke') await db.revokeApiKey(key.id);
+      else await db.transferApiKey(key.id, target!.id);
+    }
+    if (schedules.length > 0) {
+      const edb = engineDb();
+      if (!edb) return c.json({ error: 'Scheduled reports need a SQL database' }, 501);
+      const all = (await loadSchedules()).map(s => {
+        if (s.owner !== user.id) return s;
+        return reportAction === 'disable' ? { ...s, enabled: false } : { ...s, owner: target!.id };
+      });
+      await edb.run('DELETE FROM engine_settings WHERE key = ?', [REPORT_SETTINGS_KEY]);
+      await edb.run('INSERT INTO engine_settings (key, value) VALUES (?, ?)', [REPORT_SETTINGS_KEY, JSON.stringify(all)]);
+    }
+
+    await setUserActive(user.id, false);
+
+    const ip = c.req.header('x-forwarded-for')?.split(',')[0]?.trim();
+    const orgId = c.get('userOrgId' as any) || undefined;
+    await db.logEvent({
+      actor: c.get('userId') || 'system', actorType: 'user', action: 'user.offboarded',
+      resource: `user:${user.id}`,
+      details: {
+        targetEmail: user.email,
+        reassignedTo: target?.email,
+        agents: agents.map(a => a.name),
+        apiKeys: { action: keyAction, names: apiKeys.map(k => k.name) },
+        reportSchedules: { action: reportAction, names: schedules.map(s => s.name) },
+      },
+      ip, orgId,
+    }).catch(() => {});
+    await db.logEvent({
+      actor: c.get('userId') || 'system', actorType: 'user', action: 'user.deactivated',
+      resource: `user:${user.id}`, details: { targetEmail: user.email }, ip, orgId,
+    }).catch(() => {});
+
+    return c.json({
+      ok: true,
+      message: 'User deactivated',
+      agents: agents.length,
+      apiKeys: apiKeys.length,
+      reportSchedules: schedules.length,
+    });
+  });
+}
@@ -145,7 +145,7 @@ export function createEnterpriseHttpTools(options?: ToolCreationOptions): AnyAge
           }
 
           // Egress filter (DB-backed, hot-reloaded)
-          try { validateEgress(url); } catch (egressErr: any) {
+          try { validateEgress(url, options?.agentId); } catch (egressErr: any) {
             return errorResult(egressErr.message);
           }
 
@@ -232,7 +232,7 @@ export function createEnterpriseHttpTools(options?: ToolCreationOptions): AnyAge
           if (isPrivateUrl(url)) {
             return errorResult('SSRF protection: requests to private/internal addresses are blocked.');
           }
-          try { validateEgress(url); } catch (egressErr: any) { return errorResult(egressErr.message); }
+          try { validateEgress(url, options?.agentId); } catch (egressErr: any) { return errorResult(egressErr.message); }
 
           var variables: Record<string, unknown> | undefined;
           if (variablesRaw) {
@@ -322,7 +322,7 @@ export function createEnterpriseHttpTools(options?: ToolCreationOptions): AnyAge
                 throw new Error('SSRF protection: private/internal URL blocked');
               }
               // Egress filter
-              validateEgress(req.url);
+              validateEgress(req.url, options?.agentId);
               var fetchOpts: RequestInit = {
                 method: (req.method || 'GET').toUpperCase(),
                 headers: req.headers || {},
@@ -394,7 +394,7 @@ export function createEnterpriseHttpTools(options?: ToolCreationOptions): AnyAge
           if (isPrivateUrl(url)) {
             return errorResult('SSRF protection: requests to private/internal addresses are blocked.');
           }
-          try { validateEgress(url); } catch (egressErr: any) { return errorResult(egressErr.message); }
+          try { validateEgress(url, options?.agentId); } catch (egressErr: any) { return errorResult(egressErr.message); }
 
           // Resolve relative paths
           if (!path.isAbsolute(outputPath) && options?.workspaceDir) {
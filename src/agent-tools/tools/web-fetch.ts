@@ -198,6 +198,8 @@ type WebFetchRuntimeParams = {
   firecrawlMaxAgeMs: number;
   firecrawlTimeoutSeconds: number;
   ssrfGuard?: SsrfGuard;
+  /** Calling agent, for classification-keyed egress rules */
+  agentId?: string;
 };
 
 async function tryFirecrawlFallback(
@@ -261,7 +263,7 @@ async function runWebFetch(params: WebFetchRuntimeParams): Promise<Record<string
   // Egress filter (DB-backed, hot-reloaded)
   try {
     const { validateEgress } = await import('../../middleware/egress-filter.js');
-    validateEgress(params.url);
+    validateEgress(params.url, params.agentId);
   } catch (egressErr: any) {
     if (egressErr.message?.includes('egress policy')) throw egressErr;
   }
@@ -453,6 +455,7 @@ export function createWebFetchTool(options?: ToolCreationOptions & {
           firecrawlMaxAgeMs,
           firecrawlTimeoutSeconds,
           ssrfGuard: options?.ssrfGuard,
+          agentId: options?.agentId,
         });
         return jsonResult(result);
       } catch (err: any) {
@@ -0,0 +1,112 @@
+/**
+ * Password Policy
+ *
+ * Org-wide rules for new passwords (length and character classes) and an
+ * optional maximum age. The policy lives in company settings under
+ * securityConfig.passwordPolicy and is checked everywhere a password is
+ * set: user creation, admin resets, reset links and forced changes.
+ *
+ * A password past its maximum age is treated like the must_reset_password
+ * flag: the user can sign in but is sent to the change-password screen
+ * before the dashboard loads.
+ */
+
+import type { DatabaseAdapter, User } from '../db/adapter.js';
+
+export interface PasswordPolicy {
+  minLength: number;
+  requireUppercase: boolean;
+  requireLowercase: boolean;
+  requireNumber: boolean;
+  requireSymbol: boolean;
+  /** Days before a password must be changed; 0 means passwords never expire */
+  maxAgeDays: number;
+}
+
+export const DEFAULT_PASSWORD_POLICY: PasswordPolicy = {
+  minLength: 8,
+  requireUppercase: false,
+  requireLowercase: false,
+  requireNumber: false,
+  requireSymbol: false,
+  maxAgeDays: 0,
+};
+
+const MIN_LENGTH_FLOOR = 8;
+const MAX_LENGTH = 128;
+const MAX_AGE_DAYS = 3650;
+
+/** Fill in defaults and reject values that would weaken passwords below the floor. */
+export function normalizePasswordPolicy(raw: any): PasswordPolicy {
+  if (raw === undefined || raw === null) return { ...DEFAULT_PASSWORD_POLICY };
+  if (typeof raw !== 'object' || Array.isArray(raw)) throw new Error('passwordPolicy must be an object');
+  const minLength = raw.minLength === undefined ? DEFAULT_PASSWORD_POLICY.minLength : Number(raw.minLength);
+  if (!Number.isInteger(minLength) || minLength < MIN_LENGTH_FLOOR || minLength > 64) {
+    throw new Error(`minLength must be a whole number from ${MIN_LENGTH_FLOOR} to 64`);
+  }
+  const maxAgeDays = raw.maxAgeDays === undefined ? 0 : Number(raw.maxAgeDays);
+  if (!Number.isInteger(maxAgeDays) || maxAgeDays < 0 || maxAgeDays > MAX_AGE_DAYS) {
+    throw new Error(`maxAgeDays must be a whole number from 0 to ${MAX_AGE_DAYS}`);
+  }
+  return {
+    minLength,
+    requireUppercase: !!raw.requireUppercase,
+    requireLowercase: !!raw.requireLowercase,
+    requireNumber: !!raw.requireNumber,
+    requireSymbol: !!raw.requireSymbol,
+    maxAgeDays,
+  };
+}
+
+export async function getPasswordPolicy(db: DatabaseAdapter): Promise<PasswordPolicy> {
+  const settings: any = await db.getSettings().catch(() => null);
+  try {
+    return normalizePasswordPolicy(settings?.securityConfig?.passwordPolicy);
+  } catch {
+    return { ...DEFAULT_PASSWORD_POLICY };
+  }
+}
+
+/** Everything wrong with a password under the policy; empty when it passes. */
+export function passwordProblems(policy: PasswordPolicy, password: unknown): string[] {
+  if (typeof password !== 'string' || !password) return ['A password is required'];
+  const problems: string[] = [];
+  if (password.length < policy.minLength) problems.push(`at least ${policy.minLength} characters`);
+  if (password.length > MAX_LENGTH) problems.push(`at most ${MAX_LENGTH} characters`);
+  if (policy.requireUppercase && !/[A-Z]/.test(password)) problems.push('an uppercase letter');
+  if (policy.requireLowercase && !/[a-z]/.test(password)) problems.push('a lowercase letter');
+  if (policy.requireNumber && !/[0-9]/.test(password)) problems.push('a number');
+  if (policy.requireSymbol && !/[^A-Za-z0-9]/.test(password)) problems.push('a symbol');
+  return problems;
+}
+
+/** A single error message for the password, or null if it meets the policy. */
+export async function checkPassword(db: DatabaseAdapter, password: unknown): Promise<string | null> {
+  const problems = passwordProblems(await getPasswordPolicy(db), password);
+  if (problems.length === 0) return null;
+  if (problems[0] === 'A password is required') return problems[0];
+  return 'Password must have ' + problems.join(', ');
+}
+
+/** True when the user's password is older than the policy allows. SSO-only accounts never expire. */
+export function passwordExpired(policy: PasswordPolicy, user: User): boolean {
+  if (!policy.maxAgeDays || !user.passwordHash) return false;
+  const changedAt = user.passwordChangedAt || user.createdAt;
+  if (!changedAt) return false;
+  return Date.now() - new Date(changedAt).getTime() > policy.maxAgeDays * 86_400_000;
+}
+
+/**
+ * Hash and store a new password. Clears the must-change flag unless
+ * `mustChange` is set (an admin handing out a temporary password).
+ */
+export async function setUserPassword(db: DatabaseAdapter, userId: string, newPassword: string, opts: { mustChange?: boolean } = {}): Promise<void> {
+  const { default: bcrypt } = await import('bcryptjs');
+  const passwordHash = await bcrypt.hash(newPassword, 12);
+  await db.updatePassword(userId, passwordHash, !!opts.mustChange);
+}
+
+/** Set or clear the flag that sends a user to the change-password screen at next sign-in. */
+export async function setMustChangePassword(db: DatabaseAdapter, userId: string, required: boolean): Promise<void> {
+  await db.setMustResetPassword(userId, required);
+}
@@ -0,0 +1,130 @@
+/**
+ * Password Reset Links
+ *
+ * Reset tokens are short-lived JWTs signed with a key derived from the
+ * session secret, so they can never be replayed as a session. Each token
+ * carries a fingerprint of the user's current password hash: once the
+ * password changes, every outstanding link for that user stops working.
+ *
+ * Self-service emails are only sent when ENTERPRISE_URL is set. Building
+ * the link from the request's Host header would let anyone point a reset
+ * email at a domain they control.
+ */
+
+import { createHash } from 'node:crypto';
+import type { DatabaseAdapter, User } from '../db/adapter.js';
+import { getBranding } from '../lib/branding.js';
+import { setUserPassword } from './password-policy.js';
+
+const RESET_TTL_SECONDS = 60 * 60;
+const RESEND_INTERVAL_MS = 60_000;
+
+export class PasswordResetService {
+  /** Last self-service request per email, to stop the form being used to flood an inbox */
+  private lastRequested = new Map<string, number>();
+
+  constructor(private db: DatabaseAdapter, private jwtSecret: string) {}
+
+  private key(): Uint8Array {
+    return new TextEncoder().encode(`${this.jwtSecret}:password-reset`);
+  }
+
+  private fingerprint(user: User): string {
+    return createHash('sha256').update(`${user.id}:${user.passwordHash || ''}`).digest('hex').slice(0, 32);
+  }
+
+  /** The trusted public URL of the dashboard, if one is configured */
+  trustedBaseUrl(): string | null {
+    const url = process.env.ENTERPRISE_URL?.trim();
+    return url ? url.replace(/\/+$/, '') : null;
+  }
+
+  async smtpConfigured(): Promise<boolean> {
+    const settings: any = await this.db.getSettings().catch(() => null);
+    return !!(settings?.smtpHost && settings?.smtpUser);
+  }
+
+  /** Whether the login page can offer "email me a reset link" */
+  async selfServiceAvailable(): Promise<boolean> {
+    return !!this.trustedBaseUrl() && await this.smtpConfigured();
+  }
+
+  async createLink(user: User, baseUrl: string): Promise<{ link: string; expiresAt: string }> {
+    const { SignJWT } = await import('jose');
+    const token = await new SignJWT({ sub: user.id, purpose: 'password-reset', fp: this.fingerprint(user) })
+      .setProtectedHeader({ alg: 'HS256' })
+      .setIssuedAt()
+      .setExpirationTime(`${RESET_TTL_SECONDS}s`)
+      .sign(this.key());
+    return {
+      link: `${baseUrl.replace(/\/+$/, '')}/dashboard/reset-password?token=${encodeURIComponent(token)}`,
+      expiresAt: new Date(Date.now() + RESET_TTL_SECONDS * 1000).toISOString(),
+    };
+  }
+
+  /** Returns the user a token was issued for, or null if it is invalid, expired or already used */
+  async verify(token: string): Promise<User | null> {
+    if (!token) return null;
+    try {
+      const { jwtVerify } = await import('jose');
+      const { payload } = await jwtVerify(token, this.key());
+      if (payload.purpose !== 'password-reset' || typeof payload.sub !== 'string') return null;
+      const user = await this.db.getUser(payload.sub);
+      if (!user || user.isActive === false) return null;
+      return payload.fp === this.fingerprint(user) ? user : null;
+    } catch {
+      return null;
+    }
+  }
+
+  async sendEmail(user: User, link: string, requestedBy: 'self' | 'admin'): Promise<void> {
+    const settings: any = await this.db.getSettings();
+    if (!settings?.smtpHost || !settings?.smtpUser) throw new Error('Set up an SMTP relay in Settings → Email & Domain to send reset emails');
+    const branding = await getBranding();
+    const nodemailer = await import('nodemailer');
+    const port = settings.smtpPort || 587;
+    const transport = nodemailer.createTransport({ host: settings.smtpHost, port, secure: port === 465, auth: { user: settings.smtpUser, pass: settings.smtpPass } });
+    const reason = requestedBy === 'admin'
+      ? `An administrator of ${branding.companyName} asked for your password to be reset.`
+      : `Someone asked to reset the password for ${user.email} on ${branding.companyName}. If it wasn't you, ignore this email; your password won't change.`;
+    try {
+      await transport.sendMail({
+        from: `"${branding.companyName.replace(/"/g, '')}" <${settings.smtpUser}>`,
+        to: user.email,
+        subject: `Reset your ${branding.companyName} password`,
+        text: `Hi ${user.name || user.email},\n\n${reason}\n\nChoose a new password here (the link works once and expires in 1 hour):\n${link}\n`,
+      });
+    } finally {
+      transport.close();
+    }
+  }
+
+  /**
+   * Handle a "forgot password" request. Never reports whether the email
+   * exists to the caller; SSO-only and deactivated accounts are silently
+   * skipped. Returns the user an email went to, for the audit log.
+   */
+  async requestSelfService(email: string): Promise<User | null> {
+    const baseUrl = this.trustedBaseUrl();
+    if (!baseUrl) return null;
+    const normalized = email.trim().toLowerCase();
+    const last = this.lastRequested.get(normalized);
+    if (last && Date.now() - last < RESEND_INTERVAL_MS) return null;
+    this.lastRequested.set(normalized, Date.now());
+    for (const [k, t] of this.lastRequested) if (Date.now() - t > RESEND_INTERVAL_MS) this.lastRequested.delete(k);
+
+    const user = await this.db.getUserByEmail(email.trim());
+    if (!user || user.isActive === false || (user.ssoProvider && !user.passwordHash)) return null;
+    const { link } = await this.createLink(user, baseUrl);
+    await this.sendEmail(user, link, 'self');
+    return user;
+  }
+
+  /** Set a new password from a reset token; returns the user, or null if the token is no good */
+  async resetPassword(token: string, newPassword: string): Promise<User | null> {
+    const user = await this.verify(token);
+    if (!user) return null;
+    await setUserPassword(this.db, user.id, newPassword);
+    return user;
+  }
+}
@@ -11,12 +11,19 @@ import { setCookie, getCookie, deleteCookie } from 'hono/cookie';
 import { createVerify } from 'node:crypto';
 import type { DatabaseAdapter, SsoConfig } from '../db/adapter.js';
 import { transportEncryptionMiddleware } from '../middleware/index.js';
+import { renderPage } from '../lib/templates.js';
+import { redirectWithFlash } from '../lib/flash.js';
+import { verifyTotp } from '../lib/totp.js';
+import type { PasswordResetService } from './password-reset.js';
+import { getPasswordPolicy, checkPassword, passwordExpired, setUserPassword } from './password-policy.js';
+import { SessionTracker } from './sessions.js';
 
 const COOKIE_NAME = 'em_session';
 const REFRESH_COOKIE = 'em_refresh';
 const CSRF_COOKIE = 'em_csrf';
 const TOKEN_TTL = '24h';
 const REFRESH_TTL = '7d';
+const REFRESH_TTL_MS = 7 * 86_400_000;
 
 function cookieOpts(maxAge: number, isSecure: boolean) {
   return {
@@ -62,6 +69,7 @@ export function createAuthRoutes(
   opts?: {
     onBootstrap?: () => void;
     onDbConfigure?: (newAdapter: DatabaseAdapter) => DatabaseAdapter;
+    passwordReset?: PasswordResetService;
   },
 ) {
   const auth = new Hono();
@@ -73,12 +81,15 @@ export function createAuthRoutes(
     return process.env.NODE_ENV === 'production' || process.env.SECURE_COOKIES === '1';
   };
 
-  async function issueTokens(userId: string, email: string, role: string, clientOrgId?: string | null) {
+  const sessions = new SessionTracker(db);
+
+  async function issueTokens(userId: string, email: string, role: string, clientOrgId?: string | null, sid?: string | null) {
     const { SignJWT } = await import('jose');
     const secret = new TextEncoder().encode(jwtSecret);
 
     const payload: Record<string, any> = { sub: userId, email, role };
     if (clientOrgId) payload.clientOrgId = clientOrgId;
+    if (sid) payload.sid = sid;
 
     const token = await new SignJWT(payload)
       .setProtectedHeader({ alg: 'HS256' })
@@ -86,7 +97,7 @@ export function createAuthRoutes(
       .setExpirationTime(TOKEN_TTL)
       .sign(secret);
 
-    const refreshToken = await new SignJWT({ sub: userId, type: 'refresh' })
+    const refreshToken = await new SignJWT({ sub: userId, type: 'refresh', ...(sid ? { sid } : {}) })
       .setProtectedHeader({ alg: 'HS256' })
       .setIssuedAt()
       .setExpirationTime(REFRESH_TTL)
@@ -95,9 +106,31 @@ export function createAuthRoutes(
     return { token, refreshToken };
   }
 
+  /** Session id from the browser's refresh cookie, if it has one */
+  async function currentSessionId(c: any): Promise<string | null> {
+    const refreshJwt = getCookie(c, REFRESH_COOKIE);
+    if (!refreshJwt) return null;
+    try {
+      const { jwtVerify } = await import('jose');
+      const { payload } = await jwtVerify(refreshJwt, new TextEncoder().encode(jwtSecret));
+      return typeof payload.sid === 'string' ? payload.sid : null;
+    } catch {
+      return null;
+    }
+  }
+
   /** Set session cookies and return token info */
   async function setSessionCookies(c: any, userId: string, email: string, role: string, method: string, clientOrgId?: string | null) {
-    const { token, refreshToken } = await issueTokens(userId, email, role, clientOrgId);
+    // Signing in again from the same browser replaces its previous session
+    const previous = await currentSessionId(c);
+    if (previous) await sessions.end(previous);
+    const sid = await sessions.start(userId, {
+      ip: c.req.header('x-forwarded-for')?.split(',')[0]?.trim() || c.req.header('x-real-ip'),
+      userAgent: c.req.header('user-agent'),
+      method,
+      ttlMs: REFRESH_TTL_MS,
+    });
+    const { token, refreshToken } = await issueTokens(userId, email, role, clientOrgId, sid);
     const csrf = generateCsrf();
     const secure = isSecure();
 
@@ -115,6 +148,17 @@ export function createAuthRoutes(
     return { token, refreshToken, csrf };
   }
 
+  /** Record a rejected sign-in; repeated ones surface in the dashboard's Needs attention feed */
+  function logFailedLogin(c: any, email: string, reason: string, user?: { id: string; clientOrgId?: string | null } | null) {
+    db.logEvent({
+      actor: user?.id || 'anonymous', actorType: 'user', action: 'auth.login_failed',
+      resource: user ? `user:${user.id}` : 'auth:login',
+      details: { email: String(email).trim().toLowerCase().slice(0, 254), reason },
+      ip: c.req.header('x-forwarded-for')?.split(',')[0]?.trim() || c.req.header('x-real-ip'),
+      orgId: user?.clientOrgId || undefined,
+    }).catch(() => {});
+  }
+
   /** Find or auto-provision an SSO user */
   async function findOrProvisionSsoUser(
     provider: string,
@@ -204,50 +248,6 @@ export function createAuthRoutes(
     return result;
   }
 
-  function base32Decode(input: string): Uint8Array {
-    const alphabet = 'ABCDEFGHIJKLMNOPQRSTUVWXYZ234567';
-    const cleaned = input.replace(/[=\s]/g, '').toUpperCase();
-    const bytes: number[] = [];
-    let bits = 0;
-    let value = 0;
-    for (const char of cleaned) {
-      const idx = alphabet.indexOf(char);
-      if (idx === -1) continue;
-      value = (value << 5) | idx;
-      bits += 5;
-      if (bits >= 8) {
-        bytes.push((value >>> (bits - 8)) & 0xff);
-        bits -= 8;
-      }
-    }
-    return new Uint8Array(bytes);
-  }
-
-  async function generateTotp(secret: string, timeStep = 30, digits = 6, offsetSteps = 0): Promise<string> {
-    const keyBytes = base32Decode(secret);
-    const time = Math.floor(Date.now() / 1000 / timeStep) + offsetSteps;
-    const timeBytes = new Uint8Array(8);
-    let t = time;
-    for (let i = 7; i >= 0; i--) {
-      timeBytes[i] = t & 0xff;
-      t = Math.floor(t / 256);
-    }
-    const key = await crypto.subtle.importKey('raw', keyBytes.buffer as ArrayBuffer, { name: 'HMAC', hash: 'SHA-1' }, false, ['sign']);
-    const sig = new Uint8Array(await crypto.subtle.sign('HMAC', key, timeBytes));
-    const offset = sig[sig.length - 1] & 0x0f;
-    const code = ((sig[offset] & 0x7f) << 24 | sig[offset + 1] << 16 | sig[offset + 2] << 8 | sig[offset + 3]) % (10 ** digits);
-    return String(code).padStart(digits, '0');
-  }
-
-  async function verifyTotp(secret: string, token: string): Promise<boolean> {
-    // Allow 1 step drift in either direction
-    for (const offset of [0, -1, 1]) {
-      const expected = await generateTotp(secret, 30, 6, offset);
-      if (expected === token) return true;
-    }
-    return false;
-  }
-
   function generateBackupCodes(count = 8): string[] {
     const codes: string[] = [];
     for (let i = 0; i < count; i++) {
@@ -279,6 +279,7 @@ export function createAuthRoutes(
 
     const user = await db.getUserByEmail(email);
     if (!user || !user.passwordHash) {
+      logFailedLogin(c, email, user ? 'no_password' : 'unknown_user', user);
       return c.json({ error: 'Invalid credentials' }, 401);
     }
 
@@ -290,6 +291,7 @@ export function createAuthRoutes(
     const { default: bcrypt } = await import('bcryptjs');
     const valid = await bcrypt.compare(password, user.passwordHash);
     if (!valid) {
+      logFailedLogin(c, email, 'bad_password', user);
       return c.json({ error: 'Invalid credentials' }, 401);
     }
 
@@ -305,13 +307,15 @@ export function createAuthRoutes(
     }
 
     const { token, refreshToken, csrf } = await setSessionCookies(c, user.id, user.email, user.role, 'password', user.clientOrgId);
+    const expired = passwordExpired(await getPasswordPolicy(db), user);
 
     return c.json({
       token,
       refreshToken,
       csrf,
       user: { id: user.id, email: user.email, name: user.name, role: user.role, totpEnabled: !!user.totpEnabled, clientOrgId: user.clientOrgId || null },
-      mustResetPassword: !!user.mustResetPassword,
+      mustResetPassword: !!user.mustResetPassword || expired,
+      passwordExpired: expired,
     });
   });
 
@@ -358,19 +362,22 @@ export function createAuthRoutes(
     }
 
     if (!totpValid && !backupUsed) {
+      logFailedLogin(c, user.email, 'bad_2fa_code', user);
       return c.json({ error: 'Invalid 2FA code' }, 401);
     }
 
     pending2fa.delete(challengeToken);
 
     const { token, refreshToken, csrf } = await setSessionCookies(c, user.id, user.email, user.role, 'password+2fa', user.clientOrgId);
+    const expired = passwordExpired(await getPasswordPolicy(db), user);
 
     return c.json({
       token,
       refreshToken,
       csrf,
       user: { id: user.id, email: user.email, name: user.name, role: user.role, totpEnabled: true, clientOrgId: user.clientOrgId || null },
-      mustResetPassword: !!user.mustResetPassword,
+      mustResetPassword: !!user.mustResetPassword || expired,
+      passwordExpired: expired,
       ...(backupUsed ? { warning: 'Backup code used. You have fewer backup codes remaining.' } : {}),
     });
   });
@@ -382,9 +389,8 @@ export function createAuthRoutes(
     if (!email || !newPassword) {
       return c.json({ error: 'Email and new password are required' }, 400);
     }
-    if (typeof newPassword !== 'string' || newPassword.length < 8) {
-      return c.json({ error: 'Password must be at least 8 characters' }, 400);
-    }
+    const passwordError = await checkPassword(db, newPassword);
+    if (passwordError) return c.json({ error: passwordError }, 400);
 
     const user = await db.getUserByEmail(email);
     if (!user) {
@@ -424,19 +430,60 @@ export function createAuthRoutes(
       return c.json({ no2fa: true, error: 'Two-factor authentication is not enabled on this account. Please contact your organization administrator to reset your password.' }, 403);
     }
 
-    // Reset password
-    const { default: bcrypt } = await import('bcryptjs');
-    const passwordHash = await bcrypt.hash(newPassword, 12);
-    try {
-      await (db as any).pool.query(
-        'UPDATE users SET password_hash = $1, must_reset_password = FALSE, updated_at = NOW() WHERE id = $2',
-        [passwordHash, user.id]
-      );
-    } catch {
-      const edb = (db as any).db;
-      if (edb?.prepare) edb.prepare('UPDATE users SET password_hash = ?, must_reset_password = 0, updated_at = CURRENT_TIMESTAMP WHERE id = ?').run(passwordHash, user.id);
-    }
+    await setUserPassword(db, user.id, newPassword);
+
+    return c.json({ ok: true, message: 'Password reset successfully. You can now sign in.' });
+  });
+
+  // ─── Password Reset by Email Link ──────────────────
+
+  // Public so the sign-in, reset and forced-change screens can show the rules
+  auth.get('/password-policy', async (c) => {
+    const { maxAgeDays, ...rules } = await getPasswordPolicy(db);
+    return c.json({ policy: rules });
+  });
+
+  auth.get('/password-reset/config', async (c) => {
+    return c.json({ emailReset: !!opts?.passwordReset && await opts.passwordReset.selfServiceAvailable() });
+  });
+
+  // Always answers the same way, whether or not the email belongs to anyone
+  auth.post('/forgot-password', async (c) => {
+    const { email } = await c.req.json().catch(() => ({} as any));
+    if (!email || typeof email !== 'string') return c.json({ error: 'Email is required' }, 400);
+    const reset = opts?.passwordReset;
+    if (!reset || !await reset.selfServiceAvailable()) {
+      return c.json({ error: 'Email password reset is not available. Contact your administrator.' }, 503);
+    }
+    const ip = c.req.header('x-forwarded-for')?.split(',')[0]?.trim() || c.req.header('x-real-ip');
+    // Sent in the background so response time doesn't reveal whether the account exists
+    reset.requestSelfService(email).then((user) => {
+      if (!user) return;
+      db.logEvent({
+        actor: user.id, actorType: 'user', action: 'user.password_reset_requested',
+        resource: `user:${user.id}`, details: { method: 'email' }, ip,
+      }).catch(() => {});
+    }).catch((err) => console.warn(`[auth] Password reset email failed: ${err.message}`));
+    return c.json({ ok: true, message: 'If an account exists for that email, a reset link is on its way.' });
+  });
+
+  auth.get('/reset-password/verify', async (c) => {
+    const user = await opts?.passwordReset?.verify(c.req.query('token') || '');
+    if (!user) return c.json({ valid: false, error: 'This reset link is invalid or has expired' }, 400);
+    return c.json({ valid: true, email: user.email });
+  });
 
+  auth.post('/reset-password', async (c) => {
+    const { token, newPassword } = await c.req.json().catch(() => ({} as any));
+    const passwordError = await checkPassword(db, newPassword);
+    if (passwordError) return c.json({ error: passwordError }, 400);
+    const user = await opts?.passwordReset?.resetPassword(token || '', newPassword);
+    if (!user) return c.json({ error: 'This reset link is invalid or has expired' }, 400);
+    await db.logEvent({
+      actor: user.id, actorType: 'user', action: 'user.password_reset',
+      resource: `user:${user.id}`, details: { targetEmail: user.email, resetBy: 'link' },
+      ip: c.req.header('x-forwarded-for')?.split(',')[0]?.trim() || c.req.header('x-real-ip'),
+    }).catch(() => {});
     return c.json({ ok: true, message: 'Password reset successfully. You can now sign in.' });
   });
 
@@ -457,22 +504,26 @@ export function createAuthRoutes(
     }
 
     const { newPassword } = await c.req.json();
-    if (!newPassword || typeof newPassword !== 'string' || newPassword.length < 8) {
-      return c.json({ error: 'Password must be at least 8 characters' }, 400);
-    }
+    const passwordError = await checkPassword(db, newPassword);
+    if (passwordError) return c.json({ error: passwordError }, 400);
 
-    const { default: bcrypt } = await import('bcryptjs');
-    const passwordHash = await bcrypt.hash(newPassword, 12);
-    try {
-      await (db as any).pool.query(
-        'UPDATE users SET password_hash = $1, must_reset_password = FALSE, updated_at = NOW() WHERE id = $2',
-        [passwordHash, userId]
-      );
-    } catch {
-      const edb = (db as any).db;
-      if (edb?.prepare) edb.prepare('UPDATE users SET password_hash = ?, must_reset_password = 0, updated_at = CURRENT_TIMESTAMP WHERE id = ?').run(passwordHash, userId);
+    // A rotation has to actually change the password
+    const user = await db.getUser(userId);
+    if (!user) return c.json({ error: 'User not found' }, 404);
+    if (user.passwordHash) {
+      const { default: bcrypt } = await import('bcryptjs');
+      if (await bcrypt.compare(newPassword, user.passwordHash)) {
+        return c.json({ error: 'Choose a password different from your current one' }, 400);
+      }
     }
 
+    await setUserPassword(db, userId, newPassword);
+    await db.logEvent({
+      actor: userId, actorType: 'user', action: 'user.password_changed',
+      resource: `user:${userId}`, details: { reason: user.mustResetPassword ? 'required' : 'expired' },
+      ip: c.req.header('x-forwarded-for')?.split(',')[0]?.trim() || c.req.header('x-real-ip'),
+    }).catch(() => {});
+
     return c.json({ ok: true });
   });
 
@@ -645,6 +696,8 @@ export function createAuthRoutes(
 
       const user = await db.getUser(payload.sub as string);
       if (!user) return c.json({ error: 'User not found' }, 401);
+      const sid = typeof payload.sid === 'string' ? payload.sid : null;
+      if (sid && !(await sessions.touch(sid, REFRESH_TTL_MS))) return c.json({ error: 'Session has ended' }, 401);
 
       // Check if current session is impersonated — preserve the claim in the new token
       const currentSessionJwt = getCookie(c, COOKIE_NAME);
@@ -672,7 +725,7 @@ export function createAuthRoutes(
         return c.json({ token: impersonateToken, csrf });
       }
 
-      const { token, refreshToken } = await issueTokens(user.id, user.email, user.role, user.clientOrgId);
+      const { token, refreshToken } = await issueTokens(user.id, user.email, user.role, user.clientOrgId, sid);
       const csrf = generateCsrf();
       const secure = isSecure();
 
@@ -698,8 +751,9 @@ export function createAuthRoutes(
       const { payload } = await jwtVerify(token, secret);
       const user = await db.getUser(payload.sub as string);
       if (!user) return c.json({ error: 'User not found' }, 404);
+      const expired = passwordExpired(await getPasswordPolicy(db), user);
       const { passwordHash, ...safe } = user;
-      return c.json(safe);
+      return c.json({ ...safe, mustResetPassword: !!user.mustResetPassword || expired, passwordExpired: expired });
     } catch {
       return c.json({ error: 'Invalid or expired token' }, 401);
     }
@@ -757,7 +811,9 @@ export function createAuthRoutes(
 
   // ─── Logout ─────────────────────────────────────────────
 
-  auth.post('/logout', (c) => {
+  auth.post('/logout', async (c) => {
+    const sid = await currentSessionId(c);
+    if (sid) await sessions.end(sid);
     deleteCookie(c, COOKIE_NAME, { path: '/' });
     deleteCookie(c, REFRESH_COOKIE, { path: '/' });
     deleteCookie(c, CSRF_COOKIE, { path: '/' });
@@ -1262,7 +1318,7 @@ export function createAuthRoutes(
     await setSessionCookies(c, result.user.id, result.user.email, result.user.role, 'oidc', result.user.clientOrgId);
 
     // Redirect to dashboard
-    return c.redirect('/dashboard');
+    return redirectWithFlash(c, '/dashboard', 'success', `Signed in as ${result.user.email} via SSO`);
   });
 
   // ─── SAML 2.0 ────────────────────────────────────────────
@@ -1411,7 +1467,7 @@ export function createAuthRoutes(
     await setSessionCookies(c, result.user.id, result.user.email, result.user.role, 'saml', result.user.clientOrgId);
 
     // Redirect to dashboard (or RelayState)
-    return c.redirect('/dashboard');
+    return redirectWithFlash(c, '/dashboard', 'success', `Signed in as ${result.user.email} via SSO`);
   });
 
   return auth;
@@ -1568,22 +1624,5 @@ function verifySamlSignature(xml: string, certPem: string): boolean {
 // ─── SSO Error Page ──────────────────────────────────────
 
 function ssoErrorPage(title: string, message: string): string {
-  return `<!DOCTYPE html>
-<html>
-<head><title>${title}</title>
-<style>
-  body { font-family: system-ui, -apple-system, sans-serif; display: flex; align-items: center; justify-content: center; min-height: 100vh; margin: 0; background: #f8f9fa; }
-  .card { background: white; border-radius: 12px; padding: 40px; max-width: 480px; box-shadow: 0 2px 8px rgba(0,0,0,0.1); text-align: center; }
-  h1 { color: #dc2626; font-size: 1.5rem; margin: 0 0 16px; }
-  p { color: #4b5563; margin: 0 0 24px; line-height: 1.5; }
-  a { display: inline-block; padding: 10px 24px; background: #6366f1; color: white; border-radius: 8px; text-decoration: none; }
-  a:hover { background: #4f46e5; }
-</style></head>
-<body>
-<div class="card">
-  <h1>${title}</h1>
-  <p>${message}</p>
-  <a href="/dashboard">Back to Dashboard</a>
-</div>
-</body></html>`;
+  return renderPage('sso-error', { title, message });
 }
@@ -0,0 +1,143 @@
+/**
+ * Sign-in Sessions
+ *
+ * One row per sign-in in the engine's user_sessions table, keyed by a
+ * session id that rides in both the session and refresh JWTs ("sid").
+ * The row records where and on what the user signed in, is kept alive by
+ * token refreshes, and is ended on logout, when the same browser signs
+ * in again, or when the user's role changes. Tokens for an ended session
+ * are refused even before they expire. The users page reads it for each user's last sign-in IP and
+ * device and the number of sessions still live.
+ *
+ * Tracking is best effort: without a SQL engine database sign-in works as
+ * before and nothing is recorded.
+ */
+
+import type { DatabaseAdapter } from '../db/adapter.js';
+
+export interface SessionSummary {
+  lastLoginIp?: string;
+  lastDevice?: string;
+  activeSessions: number;
+}
+
+/** Sessions that ended or expired this long ago are pruned */
+const RETAIN_MS = 90 * 86_400_000;
+
+/** "Chrome on macOS" from a User-Agent header; good enough to recognize a device, not to fingerprint it. */
+export function describeDevice(userAgent: string | null | undefined): string {
+  const ua = userAgent || '';
+  if (!ua) return 'Unknown device';
+  const browser =
+    /Edg\//.test(ua) ? 'Edge' :
+    /OPR\/|Opera/.test(ua) ? 'Opera' :
+    /Firefox\//.test(ua) ? 'Firefox' :
+    /Chrome\//.test(ua) ? 'Chrome' :
+    /Safari\//.test(ua) ? 'Safari' :
+    /curl\//i.test(ua) ? 'curl' :
+    null;
+  const os =
+    /iPhone|iPad|iPod/.test(ua) ? 'iOS' :
+    /Android/.test(ua) ? 'Android' :
+    /Mac OS X|Macintosh/.test(ua) ? 'macOS' :
+    /Windows/.test(ua) ? 'Windows' :
+    /CrOS/.test(ua) ? 'ChromeOS' :
+    /Linux/.test(ua) ? 'Linux' :
+    null;
+  if (browser && os) return `${browser} on ${os}`;
+  return browser || os || ua.slice(0, 40);
+}
+
+export class SessionTracker {
+  constructor(private db: DatabaseAdapter) {}
+
+  private edb() {
+    return this.db.getEngineDB?.() || null;
+  }
+
+  /** Record a sign-in; returns the new session id, or null when sessions aren't tracked */
+  async start(userId: string, info: { ip?: string; userAgent?: string; method: string; ttlMs: number }): Promise<string | null> {
+    const edb = this.edb();
+    if (!edb) return null;
+    const id = crypto.randomUUID();
+    const now = new Date();
+    try {
+      await edb.run(
+        'INSERT INTO user_sessions (id, user_id, ip, user_agent, method, created_at, last_seen_at, expires_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)',
+        [id, userId, info.ip?.slice(0, 64) || null, info.userAgent?.slice(0, 512) || null, info.method, now.toISOString(), now.toISOString(), new Date(now.getTime() + info.ttlMs).toISOString()],
+      );
+    } catch {
+      return null;
+    }
+    const cutoff = new Date(now.getTime() - RETAIN_MS).toISOString();
+    edb.run('DELETE FROM user_sessions WHERE expires_at < ? OR ended_at < ?', [cutoff, cutoff]).catch(() => {});
+    return id;
+  }
+
+  /** Extend a session on token refresh; false if it was ended (the refresh should be refused) */
+  async touch(id: string, ttlMs: number): Promise<boolean> {
+    const edb = this.edb();
+    if (!edb) return true;
+    try {
+      const row = await edb.get<{ ended_at: string | null }>('SELECT ended_at FROM user_sessions WHERE id = ?', [id]);
+      if (row?.ended_at) return false;
+      const now = new Date();
+      await edb.run('UPDATE user_sessions SET last_seen_at = ?, expires_at = ? WHERE id = ?', [now.toISOString(), new Date(now.getTime() + ttlMs).toISOString(), id]);
+    } catch {
+      // Table not migrated yet — don't lock anyone out over bookkeeping
+    }
+    return true;
+  }
+
+  /** False once a session has been ended; unknown sessions and untracked setups count as live */
+  async isLive(id: string): Promise<boolean> {
+    const edb = this.edb();
+    if (!edb) return true;
+    try {
+      const row = await edb.get<{ ended_at: string | null }>('SELECT ended_at FROM user_sessions WHERE id = ?', [id]);
+      return !row?.ended_at;
+    } catch {
+      return true;
+    }
+  }
+
+  async end(id: string): Promise<void> {
+    await this.edb()?.run('UPDATE user_sessions SET ended_at = ? WHERE id = ? AND ended_at IS NULL', [new Date().toISOString(), id]).catch(() => {});
+  }
+
+  /** End every live session a user has, e.g. when their account is deactivated or their role changes */
+  async endAllForUser(userId: string): Promise<void> {
+    await this.edb()?.run('UPDATE user_sessions SET ended_at = ? WHERE user_id = ? AND ended_at IS NULL', [new Date().toISOString(), userId]).catch(() => {});
+  }
+
+  /** Last sign-in and live session count per user id */
+  async summaries(): Promise<Map<string, SessionSummary>> {
+    const out = new Map<string, SessionSummary>();
+    const edb = this.edb();
+    if (!edb) return out;
+    try {
+      const [latest, active] = await Promise.all([
+        edb.all<{ user_id: string; ip: string | null; user_agent: string | null }>(
+          `SELECT s.user_id, s.ip, s.user_agent FROM user_sessions s
+           JOIN (SELECT user_id, MAX(created_at) AS latest FROM user_sessions GROUP BY user_id) m
+             ON m.user_id = s.user_id AND m.latest = s.created_at`,
+        ),
+        edb.all<{ user_id: string; n: number | string }>(
+          'SELECT user_id, COUNT(*) AS n FROM user_sessions WHERE ended_at IS NULL AND expires_at > ? GROUP BY user_id',
+          [new Date().toISOString()],
+        ),
+      ]);
+      for (const r of latest) {
+        out.set(r.user_id, { lastLoginIp: r.ip || undefined, lastDevice: r.user_agent ? describeDevice(r.user_agent) : undefined, activeSessions: 0 });
+      }
+      for (const r of active) {
+        const s = out.get(r.user_id) || { activeSessions: 0 };
+        s.activeSessions = Number(r.n) || 0;
+        out.set(r.user_id, s);
+      }
+    } catch {
+      // No sessions table yet; users show without session details
+    }
+    return out;
+  }
+}
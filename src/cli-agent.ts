@@ -2212,10 +2212,10 @@ export async function runAgent(_args: string[]) {
         console.log(`[onboarding] ✅ Onboarding complete — ${policyNames.length} policies acknowledged`);
       }
 
-      // 11. Auto-setup Gmail signature from org template (BEFORE welcome email so it's included)
+      // 11. Auto-setup Gmail signature from the agent's own signature or the org template (BEFORE welcome email so it's included)
       try {
         const orgSettings = await db.getSettings();
-        const sigTemplate = (orgSettings as any)?.signatureTemplate;
+        const sigTemplate = config.identity?.signature || (orgSettings as any)?.signatureTemplate;
         const sigEmailConfig = config.emailConfig || {};
         let sigToken = sigEmailConfig.oauthAccessToken;
         if (sigEmailConfig.oauthRefreshToken && sigEmailConfig.oauthClientId) {
@@ -1,10 +1,11 @@
 // ─── Imports ─────────────────────────────────────────────
-import { h, useState, useEffect, useCallback, useRef, Fragment, AppContext, useApp, apiCall, authCall, engineCall, applyBrandColor, setOrgId } from './components/utils.js';
+import { h, useState, useEffect, useCallback, useRef, Fragment, AppContext, useApp, apiCall, authCall, engineCall, applyBrandColor, setOrgId, startPageTrace, flash, takeFlash } from './components/utils.js';
 import { I } from './components/icons.js?v=2';
 import { ErrorBoundary } from './components/error-boundary.js';
-import { Modal } from './components/modal.js';
+import { Modal, useDialogFocus } from './components/modal.js';
+import { resetPreferences } from './components/preferences.js';
 import { setConfig as setTransportEncConfig, installFetchInterceptor } from './components/transport-encryption.js';
-import { LoginPage, OnboardingWizard } from './pages/login.js';
+import { LoginPage, OnboardingWizard, ResetPasswordPage } from './pages/login.js';
 import { DashboardPage, SetupChecklist } from './pages/dashboard.js';
 import { AgentsPage, AgentDetailPage, CreateAgentWizard, DeployModal } from './pages/agents.js?v=5';
 import { SkillsPage } from './pages/skills.js';
@@ -13,12 +14,23 @@ import { ApprovalsPage } from './pages/approvals.js';
 import { ActivityPage } from './pages/activity.js';
 import { UsersPage } from './pages/users.js';
 import { AuditPage } from './pages/audit.js';
+import { ChangesPage } from './pages/changes.js';
 import { SettingsPage } from './pages/settings.js';
 import { DLPPage } from './pages/dlp.js';
 import { GuardrailsPage } from './pages/guardrails.js';
 import { JournalPage } from './pages/journal.js';
 import { MessagesPage } from './pages/messages.js';
+import { QAPage } from './pages/qa.js';
+import { WorkQueuePage } from './pages/work-queue.js';
+import { ChangeCalendarPage } from './pages/change-calendar.js';
+import { StoragePage } from './pages/storage.js';
+import { AttachmentsPage } from './pages/attachments.js';
+import { HousekeepingPage } from './pages/housekeeping.js';
+import { TeamsPage } from './pages/teams.js';
+import { AgentComparePage } from './pages/agent-compare.js';
 import { CompliancePage } from './pages/compliance.js';
+import { TrustCenterPage } from './pages/trust-center.js';
+import { EDiscoveryPage } from './pages/ediscovery.js';
 import { CommunitySkillsPage } from './pages/community-skills.js';
 import { DomainStatusPage } from './pages/domain-status.js';
 import { WorkforcePage } from './pages/workforce.js';
@@ -33,35 +45,57 @@ import { RolesPage } from './pages/roles.js';
 import { PolymarketPage } from './pages/polymarket.js';
 import { MemoryTransferPage } from './pages/memory-transfer.js';
 import { ClusterPage } from './pages/cluster.js';
+import { CallLogPage } from './pages/call-log.js';
+import { usePluginPages, withPluginNav, PluginPagePlaceholder } from './components/plugins.js';
+import { PerformancePage } from './pages/performance.js';
+import { failoverEnabled, startBackendHealthChecks, getBackendStatus, onBackendChange } from './components/backends.js';
+import { NotificationBell } from './components/notifications.js';
+import { GlobalSearch } from './components/global-search.js';
+import { useTheme, ThemeSwitcher } from './components/theme.js';
+import { TimezonePicker } from './components/time.js';
+import { usePasswordPolicy, PasswordRules, passwordOk, passwordError } from './components/password-rules.js';
 
 // ─── Toast System ────────────────────────────────────────
 let toastId = 0;
 function ToastContainer() {
   const { toasts } = useApp();
-  return h('div', { className: 'toast-container' }, toasts.map(t => h('div', { key: t.id, className: 'toast toast-' + t.type }, t.message)));
+  return h('div', { className: 'toast-container', role: 'status', 'aria-live': 'polite' }, toasts.map(t => h('div', { key: t.id, className: 'toast toast-' + t.type }, t.message)));
 }
 
 // ─── Shared Components ───────────────────────────────────
 
+// Every destructive action goes through here via showConfirm(). Options:
+//   { title, message, warning?, danger?, confirmText?, requireText? }
+// requireText makes the user type that word before the confirm button
+// enables, for actions that can't be taken back (kill, purge). Danger
+// prompts focus Cancel so a stray Enter never destroys anything.
 let confirmResolve = null;
 export function ConfirmDialog() {
   const [state, setState] = useState(null);
-  useEffect(() => { window.__showConfirm = (opts) => new Promise(resolve => { confirmResolve = resolve; setState(opts); }); return () => { window.__showConfirm = null; }; }, []);
-  if (!state) return null;
+  const [typed, setTyped] = useState('');
+  const dialogRef = useRef(null);
+  useEffect(() => { window.__showConfirm = (opts) => new Promise(resolve => { if (confirmResolve) confirmResolve(false); confirmResolve = resolve; setTyped(''); setState(opts); }); return () => { window.__showConfirm = null; }; }, []);
   const close = (val) => { setState(null); if (confirmResolve) { confirmResolve(val); confirmResolve = null; } };
+  useDialogFocus(dialogRef, () => close(false), !!state);
+  if (!state) return null;
+  const locked = !!state.requireText && typed.trim() !== state.requireText;
   return h('div', { className: 'modal-overlay', onClick: e => { if (e.target === e.currentTarget) close(false); } },
-    h('div', { className: 'modal', style: { width: 420 } },
+    h('div', { ref: dialogRef, className: 'modal', role: 'alertdialog', 'aria-modal': true, 'aria-labelledby': 'confirm-title', tabIndex: -1, style: { width: 420 } },
       h('div', { className: 'modal-header' },
-        h('h2', null, state.title || 'Confirm'),
-        h('button', { className: 'btn btn-ghost btn-icon', onClick: () => close(false) }, I.x())
+        h('h2', { id: 'confirm-title' }, state.title || 'Confirm'),
+        h('button', { className: 'btn btn-ghost btn-icon', 'aria-label': 'Close', onClick: () => close(false) }, I.x())
       ),
       h('div', { className: 'modal-body' },
         h('p', { style: { fontSize: 14, color: 'var(--text-secondary)', lineHeight: 1.6, whiteSpace: 'pre-wrap' } }, state.message),
-        state.warning && h('div', { style: { marginTop: 12, padding: 12, background: 'var(--danger-soft)', borderRadius: 'var(--radius)', fontSize: 13, color: 'var(--danger)' } }, state.warning)
+        state.warning && h('div', { style: { marginTop: 12, padding: 12, background: 'var(--danger-soft)', borderRadius: 'var(--radius)', fontSize: 13, color: 'var(--danger)' } }, state.warning),
+        state.requireText && h('div', { className: 'form-group', style: { marginTop: 12, marginBottom: 0 } },
+          h('label', { className: 'form-label' }, 'Type ', h('strong', null, state.requireText), ' to confirm'),
+          h('input', { className: 'input', value: typed, autoFocus: true, autoComplete: 'off', onChange: e => setTyped(e.target.value), onKeyDown: e => { if (e.key === 'Enter' && !locked) close(true); } })
+        )
       ),
       h('div', { className: 'modal-footer' },
-        h('button', { className: 'btn btn-secondary', onClick: () => close(false) }, 'Cancel'),
-        h('button', { className: 'btn ' + (state.danger ? 'btn-danger' : 'btn-primary'), onClick: () => close(true), autoFocus: true }, state.confirmText || 'Confirm')
+        h('button', { className: 'btn btn-secondary', onClick: () => close(false), autoFocus: !!state.danger && !state.requireText }, 'Cancel'),
+        h('button', { className: 'btn ' + (state.danger ? 'btn-danger' : 'btn-primary'), disabled: locked, onClick: () => close(true), autoFocus: !state.danger }, state.confirmText || 'Confirm')
       )
     )
   );
@@ -71,6 +105,36 @@ export async function showConfirm(opts) { return window.__showConfirm ? window._
 // Modal imported from ./components/modal.js
 export { Modal } from './components/modal.js';
 
+// ─── Active Backend Indicator ────────────────────────────
+function BackendStatus() {
+  const [status, setStatus] = useState(getBackendStatus());
+  useEffect(() => onBackendChange(setStatus), []);
+  const active = status.backends.find(b => b.url === status.active) || status.backends[0];
+  const down = status.backends.filter(b => !b.healthy).length;
+  return h('div', { className: 'sidebar-backend', title: status.backends.map(b => (b.healthy ? '● ' : '○ ') + b.label + (b.latencyMs != null ? ' (' + b.latencyMs + ' ms)' : '')).join('\n') },
+    h('span', { className: 'sidebar-backend-dot' + (status.isPrimary ? '' : ' failover') }),
+    h('span', { className: 'nav-label' }, (status.isPrimary ? 'Primary: ' : 'Failover: ') + active.label + (down ? ' · ' + down + ' down' : ''))
+  );
+}
+
+// ─── Embedded Mode ───────────────────────────────────────
+// When framed by an allowed intranet portal, only the configured read-only
+// pages render in-frame; anything else opens in a new top-level tab.
+const _embedded = window.self !== window.top && Array.isArray(window.__EM_EMBED_ROUTES__);
+const _canEmbed = (p) => !_embedded || window.__EM_EMBED_ROUTES__.indexOf(p) !== -1;
+
+// ─── Print Mode ──────────────────────────────────────────
+// ?print=1 renders just the page content, without the sidebar and top bar,
+// for printing or saving as PDF from the browser.
+const _printMode = new URLSearchParams(window.location.search).get('print') === '1';
+
+// ─── Focus ───────────────────────────────────────────────
+// After navigating, keyboard and screen reader users land on the new page
+// instead of staying in the sidebar.
+function focusMain() {
+  requestAnimationFrame(() => { const el = document.getElementById('main-content'); if (el) el.focus({ preventScroll: true }); });
+}
+
 // ─── Main App ────────────────────────────────────────────
 function App() {
   const [authed, setAuthed] = useState(false);
@@ -79,7 +143,11 @@ function App() {
   function parseRoute() {
     const p = window.location.pathname.replace(/^\/dashboard\/?/, '') || '';
     const parts = p.split('/').filter(Boolean);
+    if (parts[0] === 'agents' && parts[1] === 'compare') return { page: 'agents/compare', agentId: null };
+    // Agent templates are the role templates managed on the Roles page
+    if (parts[0] === 'agent-templates') return { page: 'roles', agentId: null };
     if (parts[0] === 'agents' && parts[1]) return { page: 'agents', agentId: parts[1] };
+    if (parts[0] === 'plugins' && parts[2]) return { page: parts.slice(0, 3).join('/'), agentId: null };
     if (parts[0]) return { page: parts[0], agentId: null };
     return { page: 'dashboard', agentId: null };
   }
@@ -104,7 +172,9 @@ function App() {
   };
 
   function setPage(p) {
+    if (!_canEmbed(p)) { window.open('/dashboard/' + (p === 'dashboard' ? '' : p), '_blank', 'noopener'); return; }
     _saveScroll();
+    startPageTrace(p);
     _setPage(p); _setSelectedAgentId(null);
     history.pushState(null, '', '/dashboard/' + (p === 'dashboard' ? '' : p));
     // Scroll to top for new pages, restored for revisited ones
@@ -114,7 +184,9 @@ function App() {
     });
   }
   function setSelectedAgentId(id) {
+    if (id && _embedded) { window.open('/dashboard/agents/' + id, '_blank', 'noopener'); return; }
     _saveScroll();
+    if (id) startPageTrace('agents/detail');
     _setSelectedAgentId(id);
     if (id) history.pushState(null, '', '/dashboard/agents/' + id);
   }
@@ -123,18 +195,22 @@ function App() {
     const onPop = () => {
       _saveScroll();
       const r = parseRoute();
+      startPageTrace(r.agentId ? 'agents/detail' : r.page);
       _setPage(r.page); _setSelectedAgentId(r.agentId);
       _restoreScroll(r.page + (r.agentId ? '/' + r.agentId : ''));
     };
     window.addEventListener('popstate', onPop);
     return () => window.removeEventListener('popstate', onPop);
   }, []);
-  const [theme, setTheme] = useState(localStorage.getItem('em_theme') || 'dark');
+  const { theme, preference: themePreference, setPreference: setThemePreference } = useTheme();
   const [toasts, setToasts] = useState([]);
   const [user, setUser] = useState(null);
   const [pendingCount, setPendingCount] = useState(0);
+  const [workQueueCount, setWorkQueueCount] = useState(0);
   const [permissions, setPermissions] = useState('*'); // '*' = full access, or { pageId: true | ['tab1','tab2'] }
-  const [mustResetPassword, setMustResetPassword] = useState(false);
+  const [capabilities, setCapabilities] = useState([]); // from the role permission matrix, e.g. 'vault.manage'
+  const pluginPages = usePluginPages(authed);
+  const [mustResetPassword, setMustResetPassword] = useState(false); // false, 'required' (flagged by an admin) or 'expired'
   const [show2faReminder, setShow2faReminder] = useState(false);
   const [updateInfo, setUpdateInfo] = useState(null);
   const [updating, setUpdating] = useState(false);
@@ -153,6 +229,7 @@ function App() {
   const [forceResetPw2, setForceResetPw2] = useState('');
   const [forceResetLoading, setForceResetLoading] = useState(false);
   const [forceResetError, setForceResetError] = useState('');
+  const passwordPolicy = usePasswordPolicy();
   const [needsSetup, setNeedsSetup] = useState(null);
   const [sidebarPinned, setSidebarPinned] = useState(() => localStorage.getItem('em_sidebar_pinned') === 'true');
   const [sidebarHovered, setSidebarHovered] = useState(false);
@@ -160,6 +237,14 @@ function App() {
   const [selectedOrgId, setSelectedOrgId] = useState('');
   const [selectedOrg, setSelectedOrg] = useState(null);
   const [orgVersion, setOrgVersion] = useState(0);
+  // Bumped to remount the current page when a search result points back at it with new URL filters,
+  // or when the timezone changes so absolute times re-render
+  const [navNonce, setNavNonce] = useState(0);
+  useEffect(() => {
+    const onTz = () => setNavNonce(n => n + 1);
+    window.addEventListener('em:timezone', onTz);
+    return () => window.removeEventListener('em:timezone', onTz);
+  }, []);
   const [companyName, setCompanyName] = useState((window.__EM_BRANDING__ && window.__EM_BRANDING__.companyName) || '');
   const onOrgChange = useCallback((id, org) => { setSelectedOrgId(id); setSelectedOrg(org); setOrgVersion(v => v + 1); if (org && org.name) setCompanyName(org.name); }, []);
 
@@ -180,6 +265,7 @@ function App() {
         setUser(d.user || d);
         // If user is org-bound, lock org context immediately
         var u = d.user || d;
+        if (u.mustResetPassword) setMustResetPassword(u.passwordExpired ? 'expired' : 'required');
         if (u.clientOrgId) {
           setSelectedOrgId(u.clientOrgId);
           localStorage.setItem('em_client_org_id', u.clientOrgId);
@@ -221,21 +307,23 @@ function App() {
     setTimeout(() => setToasts(t => t.filter(x => x.id !== id)), 3500);
   }, []);
 
-  useEffect(() => {
-    document.documentElement.setAttribute('data-theme', theme);
-    localStorage.setItem('em_theme', theme);
-  }, [theme]);
-
   useEffect(() => {
     localStorage.setItem('em_sidebar_pinned', sidebarPinned ? 'true' : 'false');
   }, [sidebarPinned]);
 
   useEffect(() => {
     if (!authed) return;
+    // Messages queued before a reload or SSO redirect
+    takeFlash().forEach(f => toast(f.message, f.type));
+    startBackendHealthChecks();
     engineCall('/approvals/pending').then(d => setPendingCount((d.requests || []).length)).catch(() => {});
-    apiCall('/settings').then(d => { const s = d.settings || d || {}; if (s.primaryColor) applyBrandColor(s.primaryColor); if (s.orgId) setOrgId(s.orgId); }).catch(() => {});
+    apiCall('/settings').then(d => {
+      const s = d.settings || d || {}; if (s.primaryColor) applyBrandColor(s.primaryColor); if (s.orgId) setOrgId(s.orgId);
+      if (s.orgId) engineCall('/work-queue/counts?orgId=' + encodeURIComponent(s.orgId)).then(c => setWorkQueueCount((c.counts && c.counts.overdue) || 0)).catch(() => {});
+    }).catch(() => {});
     apiCall('/me/permissions').then(d => {
       if (d && d.permissions) setPermissions(d.permissions);
+      if (d && d.can) setCapabilities(d.can);
       // If user is assigned to a client org, auto-set org context and lock switcher
       if (d && d.clientOrgId) {
         localStorage.setItem('em_client_org_id', d.clientOrgId);
@@ -253,6 +341,9 @@ function App() {
   const onSidebarEnter = useCallback(() => { if (!sidebarPinned) setSidebarHovered(true); }, [sidebarPinned]);
   const onSidebarLeave = useCallback(() => setSidebarHovered(false), []);
 
+  // Preferences are per user: sign-in, sign-out and impersonation all switch whose are loaded
+  useEffect(() => { resetPreferences(); }, [user && user.id]);
+
   // Register global logout so apiCall can trigger it on 401
   useEffect(() => { window.__emLogout = logout; return () => { window.__emLogout = null; }; }, [logout]);
 
@@ -269,7 +360,7 @@ function App() {
           setPermissions({ dashboard: true, agents: true, roles: true, polymarket: true, skills: true, 'community-skills': true, 'skill-connections': true, 'database-access': true, knowledge: true, 'knowledge-contributions': true, 'memory-transfer': true, approvals: true, 'org-chart': true, 'task-pipeline': true, workforce: true, messages: true, guardrails: true, journal: true, activity: true, dlp: true, compliance: true, vault: true, audit: true, settings: true });
         }
         // Then fetch computed permissions for the definitive set
-        apiCall('/me/permissions').then(function(p) { if (p && p.permissions) setPermissions(p.permissions); }).catch(function() {});
+        apiCall('/me/permissions').then(function(p) { if (p && p.permissions) setPermissions(p.permissions); if (p && p.can) setCapabilities(p.can); }).catch(function() {});
         if (d.user.clientOrgId) {
           localStorage.setItem('em_client_org_id', d.user.clientOrgId);
           // Auto-select the client org so all pages filter by it
@@ -311,11 +402,12 @@ function App() {
       }).then(function(d) {
         setUser(d.user || d);
         window.__suppressLogout = false;
-        apiCall('/me/permissions').then(function(p) { if (p && p.permissions) setPermissions(p.permissions); }).catch(function() {});
+        apiCall('/me/permissions').then(function(p) { if (p && p.permissions) setPermissions(p.permissions); if (p && p.can) setCapabilities(p.can); }).catch(function() {});
         toast('Stopped impersonation', 'success');
         setPage('users');
       }).catch(function() {
         // Last resort: full page reload to clear all state
+        flash('Stopped impersonation', 'success');
         window.location.reload();
       });
     };
@@ -329,7 +421,7 @@ function App() {
       setTimeout(function() {
         window.__suppressLogout = false;
         authCall('/me').then(function(d) { setUser(d.user || d); }).catch(function() {});
-        apiCall('/me/permissions').then(function(p) { if (p && p.permissions) setPermissions(p.permissions); }).catch(function() {});
+        apiCall('/me/permissions').then(function(p) { if (p && p.permissions) setPermissions(p.permissions); if (p && p.can) setCapabilities(p.can); }).catch(function() {});
       }, 100);
       toast('Stopped impersonation', 'success');
       setPage('users');
@@ -339,6 +431,8 @@ function App() {
     });
   }, []);
 
+  // Reset links from emails work whether or not someone is signed in
+  if (page === 'reset-password') return h(ResetPasswordPage, { token: new URLSearchParams(window.location.search).get('token') || '' });
   if (!authChecked) return h('div', { style: { minHeight: '100vh', display: 'flex', alignItems: 'center', justifyContent: 'center', background: 'var(--bg-primary)', color: 'var(--text-muted)' } }, 'Loading...');
   if (needsSetup === true && !authed) return h(OnboardingWizard, { onComplete: () => { setNeedsSetup(false); setAuthed(true); authCall('/me').then(d => { setUser(d.user || d); }).catch(() => {}); } });
   if (!authed) return h(LoginPage, { onLogin: async (d) => {
@@ -346,7 +440,7 @@ function App() {
       setUser(d.user);
       if (!d.user.totpEnabled) setShow2faReminder(true);
     }
-    if (d?.mustResetPassword) setMustResetPassword(true);
+    if (d?.mustResetPassword) setMustResetPassword(d.passwordExpired ? 'expired' : 'required');
     // Init encryption before enabling dashboard
     try {
       var sec = await apiCall('/settings/security');
@@ -366,7 +460,7 @@ function App() {
   // Force password reset modal
   const doForceReset = async () => {
     if (forceResetPw !== forceResetPw2) { setForceResetError('Passwords do not match'); return; }
-    if (forceResetPw.length < 8) { setForceResetError('Password must be at least 8 characters'); return; }
+    if (!passwordOk(passwordPolicy, forceResetPw)) { setForceResetError(passwordError(passwordPolicy, forceResetPw)); return; }
     setForceResetLoading(true); setForceResetError('');
     try {
       await authCall('/force-reset-password', { method: 'POST', body: JSON.stringify({ newPassword: forceResetPw }) });
@@ -387,12 +481,15 @@ function App() {
             )
           ),
           h('h2', { style: { fontSize: 18, fontWeight: 700 } }, 'Password Reset Required'),
-          h('p', { style: { color: 'var(--text-muted)', fontSize: 13, marginTop: 4 } }, 'Your administrator created this account with a temporary password. Please set a new password to continue.')
+          h('p', { style: { color: 'var(--text-muted)', fontSize: 13, marginTop: 4 } }, mustResetPassword === 'expired'
+            ? 'Your password has expired under your organization\'s password policy. Please choose a new one to continue.'
+            : 'Your administrator requires you to set a new password before continuing.')
         ),
         h('div', { style: { display: 'flex', flexDirection: 'column', gap: 12 } },
           h('div', null,
             h('label', { style: { fontSize: 12, fontWeight: 600, color: 'var(--text-secondary)', display: 'block', marginBottom: 4 } }, 'New Password'),
-            h('input', { className: 'input', type: 'password', value: forceResetPw, onChange: (e) => setForceResetPw(e.target.value), placeholder: 'Min 8 characters', autoFocus: true })
+            h('input', { className: 'input', type: 'password', value: forceResetPw, onChange: (e) => setForceResetPw(e.target.value), placeholder: 'Min ' + passwordPolicy.minLength + ' characters', autoFocus: true }),
+            h(PasswordRules, { policy: passwordPolicy, password: forceResetPw })
           ),
           h('div', null,
             h('label', { style: { fontSize: 12, fontWeight: 600, color: 'var(--text-secondary)', display: 'block', marginBottom: 4 } }, 'Confirm Password'),
@@ -407,10 +504,11 @@ function App() {
     );
   }
 
-  const nav = [
+  const nav = withPluginNav([
     { section: 'Overview', items: [{ id: 'dashboard', icon: I.dashboard, label: 'Dashboard' }] },
     { section: 'Management', items: [
       { id: 'agents', icon: I.agents, label: 'Agents' },
+      { id: 'teams', icon: I.users, label: 'Teams' },
       { id: 'roles', icon: I.agents, label: 'Roles' },
       { id: 'polymarket', icon: I.activity, label: 'Polymarket' },
       { id: 'organizations', icon: I.building, label: 'Organizations' },
@@ -429,35 +527,57 @@ function App() {
       { id: 'cluster', icon: I.server, label: 'Cluster' },
       { id: 'workforce', icon: I.clock, label: 'Workforce' },
       { id: 'messages', icon: I.messages, label: 'Messages' },
+      { id: 'qa', icon: I.check, label: 'Quality Review' },
+      { id: 'work-queue', icon: I.clock, label: 'Work Queue', badge: workQueueCount || null },
       { id: 'guardrails', icon: I.guardrails, label: 'Guardrails' },
       { id: 'journal', icon: I.journal, label: 'Journal' },
     ]},
     { section: 'Administration', items: [
       { id: 'dlp', icon: I.dlp, label: 'DLP' },
       { id: 'compliance', icon: I.compliance, label: 'Compliance' },
+      { id: 'trust-center', icon: I.shield, label: 'Trust Center' },
+      { id: 'ediscovery', icon: I.briefcase, label: 'e-Discovery' },
+      { id: 'change-calendar', icon: I.calendar, label: 'Change Calendar' },
+      { id: 'storage', icon: I.database, label: 'Storage' },
+      { id: 'attachments', icon: I.folder, label: 'Attachments' },
+      { id: 'housekeeping', icon: I.trash, label: 'Housekeeping' },
       { id: 'domain-status', icon: I.shield, label: 'Domain' },
       { id: 'users', icon: I.users, label: 'Users' },
       { id: 'vault', icon: I.lock, label: 'Vault' },
       { id: 'audit', icon: I.audit, label: 'Audit Log' },
+      { id: 'changes', icon: I.calendar, label: 'What Changed' },
+      ...(user?.role === 'owner' ? [{ id: 'call-log', icon: I.terminal, label: 'Call Log' }] : []),
+      ...(user?.role === 'owner' || user?.role === 'admin' ? [{ id: 'performance', icon: I.activity, label: 'Performance' }] : []),
       { id: 'settings', icon: I.settings, label: 'Settings' },
     ]}
-  ];
+  ], pluginPages);
 
   const pages = {
     dashboard: DashboardPage,
     agents: AgentsPage,
+    'agents/compare': AgentComparePage,
+    teams: TeamsPage,
     skills: SkillsPage,
     knowledge: KnowledgeBasePage,
     approvals: ApprovalsPage,
     activity: ActivityPage,
     users: UsersPage,
     audit: AuditPage,
+    changes: ChangesPage,
     settings: SettingsPage,
     dlp: DLPPage,
     guardrails: GuardrailsPage,
     journal: JournalPage,
     messages: MessagesPage,
+    qa: QAPage,
+    'work-queue': WorkQueuePage,
     compliance: CompliancePage,
+    'trust-center': TrustCenterPage,
+    ediscovery: EDiscoveryPage,
+    'change-calendar': ChangeCalendarPage,
+    storage: StoragePage,
+    attachments: AttachmentsPage,
+    housekeeping: HousekeepingPage,
     'community-skills': CommunitySkillsPage,
     'domain-status': DomainStatusPage,
     workforce: WorkforcePage,
@@ -472,12 +592,29 @@ function App() {
     polymarket: PolymarketPage,
     'memory-transfer': MemoryTransferPage,
     cluster: ClusterPage,
+    'call-log': CallLogPage,
+    performance: PerformancePage,
   };
+  Object.keys(pluginPages).forEach(id => { pages[id] = pluginPages[id].component; });
 
-  const navigateToAgent = (agentId) => { _setSelectedAgentId(agentId); history.pushState(null, '', '/dashboard/agents/' + agentId); };
+  // Notifications → open a message on the Messages page (?message=<id>)
+  const openMessage = (messageId) => {
+    setPage('messages');
+    history.replaceState(null, '', '/dashboard/messages?message=' + encodeURIComponent(messageId));
+    window.dispatchEvent(new CustomEvent('em:open-message', { detail: messageId }));
+  };
+  const navigateToAgent = (agentId) => { if (_embedded) { window.open('/dashboard/agents/' + agentId, '_blank', 'noopener'); return; } startPageTrace('agents/detail'); _setSelectedAgentId(agentId); history.pushState(null, '', '/dashboard/agents/' + agentId); };
+  const openSearchResult = (r) => {
+    if (r.page === 'agents') return navigateToAgent(r.id);
+    setPage(r.page);
+    // Pages with a FilterBar pick the query up from the URL when they mount
+    if (r.query) history.replaceState(null, '', '/dashboard/' + r.page + '?' + r.query);
+    setNavNonce(n => n + 1);
+  };
 
-  // Filter nav based on permissions
-  const hasAccess = (pageId) => permissions === '*' || (permissions && pageId in permissions);
+  // Filter nav based on permissions. Plugin pages are already filtered by role on the server.
+  // Sub-pages such as agents/compare go with their parent page's permission
+  const hasAccess = (pageId) => permissions === '*' || pageId.startsWith('plugins/') || (permissions && pageId.split('/')[0] in permissions);
   const filteredNav = nav.map(section => ({
     ...section,
     items: section.items.filter(item => hasAccess(item.id))
@@ -485,14 +622,23 @@ function App() {
 
   // Block access to pages user can't see — show unauthorized page
   const canAccessPage = hasAccess(page);
-  const PageComponent = canAccessPage ? (pages[page] || DashboardPage) : null;
+  const PageComponent = canAccessPage ? (pages[page] || (page.startsWith('plugins/') ? PluginPagePlaceholder : DashboardPage)) : null;
   const sidebarClass = 'sidebar' + (sidebarPinned ? ' expanded' : sidebarHovered ? ' hover-expanded' : '') + (mobileMenuOpen ? ' mobile-open' : '');
 
-  return h(AppContext.Provider, { value: { toast, toasts, user, theme, setPage, permissions, impersonating, startImpersonation, stopImpersonation, selectedOrgId, selectedOrg, onOrgChange, companyName, setCompanyName } },
-    h('div', { className: 'app-layout' },
+  // Owners can do everything; everyone else per the role permission matrix
+  const can = (capability) => user?.role === 'owner' || capabilities.indexOf(capability) >= 0;
+
+  return h(AppContext.Provider, { value: { toast, toasts, user, theme, setPage, permissions, can, impersonating, startImpersonation, stopImpersonation, selectedOrgId, selectedOrg, onOrgChange, companyName, setCompanyName } },
+    h('div', { className: 'app-layout' + (_embedded ? ' embedded' : '') + (_printMode ? ' print-mode' : '') },
+      h('a', { className: 'skip-link', href: '#main-content', onClick: (e) => { e.preventDefault(); focusMain(); } }, 'Skip to main content'),
+      _printMode && h('div', { className: 'print-toolbar' },
+        h('span', null, 'Print view'),
+        h('button', { className: 'btn btn-primary btn-sm', onClick: () => window.print() }, 'Print'),
+        h('button', { className: 'btn btn-secondary btn-sm', onClick: () => window.close() }, 'Close')
+      ),
       // Mobile hamburger
-      h('button', { className: 'mobile-hamburger', onClick: () => setMobileMenuOpen(true) },
-        h('svg', { viewBox: '0 0 24 24', fill: 'none', stroke: 'currentColor', strokeWidth: 2, strokeLinecap: 'round' },
+      !_embedded && h('button', { className: 'mobile-hamburger', 'aria-label': 'Open menu', 'aria-expanded': mobileMenuOpen, onClick: () => setMobileMenuOpen(true) },
+        h('svg', { viewBox: '0 0 24 24', fill: 'none', stroke: 'currentColor', strokeWidth: 2, strokeLinecap: 'round', 'aria-hidden': true },
           h('line', { x1: 3, y1: 6, x2: 21, y2: 6 }),
           h('line', { x1: 3, y1: 12, x2: 21, y2: 12 }),
           h('line', { x1: 3, y1: 18, x2: 21, y2: 18 })
@@ -501,23 +647,35 @@ function App() {
       // Mobile backdrop
       mobileMenuOpen && h('div', { className: 'mobile-backdrop visible', onClick: () => setMobileMenuOpen(false) }),
       // Sidebar
-      h('div', { className: sidebarClass, onMouseEnter: onSidebarEnter, onMouseLeave: onSidebarLeave },
+      !_embedded && h('aside', { className: sidebarClass, 'aria-label': 'Sidebar', onMouseEnter: onSidebarEnter, onMouseLeave: onSidebarLeave },
         h('div', { className: 'sidebar-brand' },
-          h('img', { src: (window.__EM_BRANDING__ && window.__EM_BRANDING__.logo) || '/dashboard/assets/logo.png', alt: 'AgenticMail', style: { width: 28, height: 28, objectFit: 'contain' } }),
+          h('img', { src: (window.__EM_BRANDING__ && window.__EM_BRANDING__.logo) || '/dashboard/assets/logo.png', alt: companyName || 'AgenticMail', style: { width: 28, height: 28, objectFit: 'contain' } }),
           h('div', { className: 'sidebar-brand-text' }, h('h2', null, companyName || 'AgenticMail'), h('span', null, 'Enterprise')),
-          h('button', { className: 'sidebar-toggle' + (sidebarPinned ? ' pinned' : ''), onClick: toggleSidebarPin, title: sidebarPinned ? 'Unpin sidebar' : 'Pin sidebar' }, sidebarPinned ? I.chevronLeft() : I.panelLeft())
+          h('button', { className: 'sidebar-toggle' + (sidebarPinned ? ' pinned' : ''), onClick: toggleSidebarPin, title: sidebarPinned ? 'Unpin sidebar' : 'Pin sidebar', 'aria-label': sidebarPinned ? 'Unpin sidebar' : 'Pin sidebar', 'aria-pressed': sidebarPinned }, sidebarPinned ? I.chevronLeft() : I.panelLeft())
         ),
-        h('div', { className: 'sidebar-nav' },
+        h('nav', { className: 'sidebar-nav', 'aria-label': 'Main navigation' },
           filteredNav.map((section, si) =>
-            h('div', { key: section.section + si, className: 'sidebar-section' },
-              h('div', { className: 'sidebar-section-title' }, section.section),
-              section.items.map(item =>
-                h('div', { key: item.id, className: 'nav-item' + (page === item.id && !selectedAgentId ? ' active' : ''), onClick: () => { setPage(item.id); setSelectedAgentId(null); setMobileMenuOpen(false); }, 'data-tooltip': item.label },
+            h('div', { key: section.section + si, className: 'sidebar-section', role: 'group', 'aria-labelledby': 'nav-section-' + si },
+              h('div', { className: 'sidebar-section-title', id: 'nav-section-' + si }, section.section),
+              section.items.map(item => {
+                const current = (page === item.id || page.startsWith(item.id + '/')) && !selectedAgentId;
+                // Real links so they're focusable and open in a new tab on ctrl/middle click; the collapsed
+                // sidebar shows only the icon, so the label is also given as aria-label
+                return h('a', {
+                  key: item.id, href: '/dashboard/' + (item.id === 'dashboard' ? '' : item.id), className: 'nav-item' + (current ? ' active' : ''), 'data-tooltip': item.label,
+                  'aria-label': item.label + (item.badge ? ' (' + item.badge + ')' : ''), 'aria-current': current ? 'page' : undefined,
+                  onClick: (e) => {
+                    if (e.metaKey || e.ctrlKey || e.shiftKey || e.button === 1) return;
+                    e.preventDefault();
+                    setPage(item.id); setSelectedAgentId(null); setMobileMenuOpen(false);
+                    focusMain();
+                  }
+                },
                   item.icon(),
                   h('span', { className: 'nav-label' }, item.label),
                   item.badge && h('span', { className: 'badge' }, item.badge)
-                )
-              )
+                );
+              })
             )
           )
         ),
@@ -528,22 +686,27 @@ function App() {
               h('div', { className: 'user-name' }, user?.name || user?.email || 'Admin'),
               h('div', { className: 'user-role' }, user?.role || 'admin')
             )
-          )
+          ),
+          h(ThemeSwitcher, { preference: themePreference, onChange: setThemePreference }),
+          h(TimezonePicker),
+          failoverEnabled() && h(BackendStatus)
         )
       ),
 
       // Main
       h('div', { className: 'main-content' },
-        h('div', { className: 'topbar' },
+        h('header', { className: 'topbar' },
           h('div', { className: 'topbar-left' },
             h('span', { className: 'topbar-title' }, (nav.flatMap(s => s.items).find(i => i.id === page)?.label || 'Dashboard'))
           ),
           h('div', { className: 'topbar-right' },
-            h('button', { className: 'btn btn-ghost btn-icon', onClick: () => setTheme(theme === 'dark' ? 'light' : 'dark'), title: 'Toggle theme', style: { width: 36, height: 36 } }, theme === 'dark' ? I.sun({ size: 22 }) : I.moon({ size: 22 })),
-            h('button', { className: 'btn btn-ghost btn-icon', onClick: logout, title: 'Sign out', style: { width: 36, height: 36 } }, I.logout({ size: 22 }))
+            h('button', { className: 'btn btn-ghost btn-icon', onClick: () => window.dispatchEvent(new CustomEvent('em:open-search')), title: 'Search (' + (navigator.platform && navigator.platform.indexOf('Mac') === 0 ? '\u2318K' : 'Ctrl+K') + ')', 'aria-label': 'Search', style: { width: 36, height: 36 } }, I.search({ size: 20 })),
+            h(NotificationBell, { onOpenMessage: openMessage, showPerformance: user?.role === 'owner' || user?.role === 'admin', onOpenPerformance: () => setPage('performance') }),
+            h('button', { className: 'btn btn-ghost btn-icon', onClick: () => setThemePreference(theme === 'dark' ? 'light' : 'dark'), title: 'Toggle theme', 'aria-label': theme === 'dark' ? 'Switch to light theme' : 'Switch to dark theme', style: { width: 36, height: 36 } }, theme === 'dark' ? I.sun({ size: 22 }) : I.moon({ size: 22 })),
+            h('button', { className: 'btn btn-ghost btn-icon', onClick: logout, title: 'Sign out', 'aria-label': 'Sign out', style: { width: 36, height: 36 } }, I.logout({ size: 22 }))
           )
         ),
-        h('div', { className: 'page-content' },
+        h('main', { className: 'page-content', id: 'main-content', tabIndex: -1 },
           // Impersonation banner
           impersonating && h('div', { style: { display: 'flex', alignItems: 'center', gap: 12, padding: '10px 16px', margin: '0 0 16px', background: 'rgba(99,102,241,0.12)', border: '2px solid var(--primary, #6366f1)', borderRadius: 8, fontSize: 13 } },
             I.agents(),
@@ -564,7 +727,7 @@ function App() {
               h('span', { style: { color: 'var(--text-secondary)', marginLeft: 6 } }, 'Protect your account and enable self-service password reset.')
             ),
             h('button', { className: 'btn btn-warning btn-sm', onClick: () => { setPage('settings'); setShow2faReminder(false); history.pushState(null, '', '/dashboard/settings'); } }, 'Set Up 2FA'),
-            h('button', { className: 'btn btn-ghost btn-sm', onClick: () => setShow2faReminder(false), style: { padding: '2px 6px', minWidth: 0 } }, '\u00d7')
+            h('button', { className: 'btn btn-ghost btn-sm', 'aria-label': 'Dismiss', onClick: () => setShow2faReminder(false), style: { padding: '2px 6px', minWidth: 0 } }, '\u00d7')
           ),
           // Update available banner
           updateInfo && h('div', { style: { padding: '12px 16px', margin: '0 0 16px', background: 'rgba(16,185,129,0.08)', border: '1px solid rgba(16,185,129,0.3)', borderRadius: 10, fontSize: 13 } },
@@ -596,7 +759,7 @@ function App() {
                   setUpdating(false);
                 }
               }, updating ? 'Updating...' : 'Update Now'),
-              h('button', { className: 'btn btn-ghost btn-sm', onClick: () => setUpdateInfo(null), style: { padding: '2px 6px', minWidth: 0 } }, '\u00d7')
+              h('button', { className: 'btn btn-ghost btn-sm', 'aria-label': 'Dismiss', onClick: () => setUpdateInfo(null), style: { padding: '2px 6px', minWidth: 0 } }, '\u00d7')
             ),
             updateInfo.releaseNotes && h('div', { style: { marginTop: 10, paddingTop: 10, borderTop: '1px solid rgba(16,185,129,0.2)', color: 'var(--text-secondary)', fontSize: 12, lineHeight: 1.6, maxHeight: 200, overflowY: 'auto' } },
               h('div', { dangerouslySetInnerHTML: { __html: updateInfo.releaseNotes
@@ -616,7 +779,7 @@ function App() {
             ? h(AgentDetailPage, { agentId: selectedAgentId, onBack: () => { _setSelectedAgentId(null); _setPage('agents'); history.pushState(null, '', '/dashboard/agents'); } })
             : page === 'agents'
               ? h(AgentsPage, { key: 'agents-' + orgVersion, onSelectAgent: navigateToAgent })
-              : PageComponent ? h(PageComponent, { key: page + '-' + orgVersion })
+              : PageComponent ? h(PageComponent, { key: page + '-' + orgVersion + '-' + navNonce })
               : h('div', { style: { display: 'flex', flexDirection: 'column', alignItems: 'center', justifyContent: 'center', minHeight: '60vh', textAlign: 'center', padding: 40 } },
                   h('div', { style: { width: 64, height: 64, borderRadius: '50%', background: 'var(--danger-soft, rgba(220,38,38,0.1))', display: 'flex', alignItems: 'center', justifyContent: 'center', marginBottom: 20 } },
                     h('svg', { width: 32, height: 32, viewBox: '0 0 24 24', fill: 'none', stroke: 'var(--danger, #dc2626)', strokeWidth: 2, strokeLinecap: 'round', strokeLinejoin: 'round' },
@@ -638,6 +801,7 @@ function App() {
         )
       )
     ),
+    !_embedded && h(GlobalSearch, { orgId: selectedOrgId, onOpen: openSearchResult }),
     h(ToastContainer),
     h(ConfirmDialog)
   );
@@ -0,0 +1,15 @@
+// OAuth popup result page (templates/oauth-result.html). The result travels in
+// a data-attribute rather than inline script so the page runs under the strict
+// CSP: post it to the window that opened the popup, then close.
+(function() {
+  var el = document.getElementById('oauth-result');
+  if (!el) return;
+  var result;
+  try { result = JSON.parse(el.getAttribute('data-result') || '{}'); } catch (e) { return; }
+  if (window.opener) {
+    window.opener.postMessage(result, '*');
+    setTimeout(function() { window.close(); }, 1500);
+  } else if (window.parent !== window) {
+    window.parent.postMessage(result, '*');
+  }
+})();
@@ -0,0 +1,76 @@
+import { h, useState, useEffect, Fragment, formatUptime } from './utils.js';
+
+// ─── Live Agent Status ───────────────────────────────────
+// What an agent's process is doing right now, from the engine's status
+// stream, rather than the stored status field. Shared by the Agents list and
+// the agent detail header so both say the same thing.
+//
+//   var live = useLiveAgentStatus();            // every agent, keyed by id
+//   var one = useLiveAgentStatus(agentId)[agentId];
+//   h(LiveStatusBadge, { live: one, fallback: agent.status })
+
+var LABELS = { online: 'running', idle: 'idle', offline: 'stopped', error: 'crashed' };
+var COLORS = { running: 'success', idle: 'info', stopped: 'neutral', crashed: 'danger', active: 'success', archived: 'neutral', draft: 'neutral', retired: 'neutral', offboarding: 'danger' };
+var REASONS = { heartbeat_lost: 'Stopped sending heartbeats without being stopped', degraded: 'Health checks failing', crashed: 'Process crashed', error: 'Process reported an error' };
+
+/**
+ * Live status snapshots keyed by agent id, kept current over SSE. With
+ * `agentId` only that agent is streamed. Re-renders every 30s so uptime
+ * stays fresh between updates.
+ */
+export function useLiveAgentStatus(agentId) {
+  var [statuses, setStatuses] = useState({});
+  var [, setTick] = useState(0);
+
+  useEffect(function() {
+    var es = new EventSource('/api/engine/agent-status-stream' + (agentId ? '?agentId=' + encodeURIComponent(agentId) : ''));
+    es.onmessage = function(ev) {
+      try {
+        var d = JSON.parse(ev.data);
+        if (d.type === 'status' && d.agentId) {
+          setStatuses(function(prev) {
+            var next = Object.assign({}, prev);
+            next[d.agentId] = d;
+            return next;
+          });
+        }
+      } catch (e) { /* heartbeat or malformed */ }
+    };
+    return function() { es.close(); };
+  }, [agentId]);
+
+  useEffect(function() {
+    var t = setInterval(function() { setTick(function(n) { return n + 1; }); }, 30000);
+    return function() { clearInterval(t); };
+  }, []);
+
+  return statuses;
+}
+
+/** 'running' | 'idle' | 'stopped' | 'crashed', or `fallback` with no live status */
+export function liveStatusLabel(live, fallback) {
+  return live ? LABELS[live.status] || live.status : (fallback || 'active');
+}
+
+/** Seconds since the agent came online, or null */
+export function liveUptime(live) {
+  return live && live.onlineSince ? Math.max(0, Math.floor((Date.now() - new Date(live.onlineSince).getTime()) / 1000)) : null;
+}
+
+/**
+ * Status badge plus uptime (while running or idle) or the crash reason.
+ * Optional: showActivity adds what the agent is doing.
+ */
+export function LiveStatusBadge(props) {
+  var live = props.live;
+  var label = liveStatusLabel(live, props.fallback);
+  var uptime = liveUptime(live);
+  var reason = live && live.status === 'error' ? REASONS[live.statusReason] || live.statusReason : null;
+  var activity = props.showActivity && live && live.currentActivity ? live.currentActivity.detail || live.currentActivity.type : null;
+  var _muted = { fontSize: 11, color: 'var(--text-muted)', marginLeft: 6 };
+  return h(Fragment, null,
+    h('span', { className: 'badge badge-' + (COLORS[label] || 'warning'), style: { textTransform: 'capitalize' }, title: reason || undefined }, label),
+    uptime != null && h('span', { style: _muted, title: 'Online since ' + new Date(live.onlineSince).toLocaleString() }, 'up ' + (uptime < 60 ? '<1m' : formatUptime(uptime))),
+    activity && h('span', { style: Object.assign({ fontStyle: 'italic' }, _muted) }, activity)
+  );
+}
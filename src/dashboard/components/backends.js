@@ -0,0 +1,102 @@
+// ─── Multi-Backend Failover ──────────────────────────────
+// The server injects window.__EM_BACKENDS__ (primary first, then replicas)
+// when failover is enabled in Settings > Network. API calls go to the active
+// backend; when it stops answering the client moves to the next healthy one,
+// and a background health check moves back to the primary once it recovers.
+
+var DEFAULT_HEALTH_INTERVAL_MS = 15000;
+var HEALTH_TIMEOUT_MS = 3000;
+
+function normalize(url) {
+  var base = String(url || '').trim().replace(/\/+$/, '');
+  return base === location.origin ? '' : base; // '' = same origin
+}
+
+var _cfg = window.__EM_BACKENDS__ || null;
+var _backends = _cfg && Array.isArray(_cfg.urls) && _cfg.urls.length > 1 ? _cfg.urls.map(normalize) : [''];
+var _health = {};
+var _active = 0;
+var _timer = null;
+var _listeners = [];
+
+function notify() {
+  _listeners.forEach(function(fn) { try { fn(getBackendStatus()); } catch (e) { /* listener errors are not ours */ } });
+}
+
+function setActive(idx) {
+  if (idx === _active) return;
+  var from = _backends[_active], to = _backends[idx];
+  _active = idx;
+  console.warn('[failover] Switched API backend ' + backendLabel(from) + ' → ' + backendLabel(to));
+  notify();
+}
+
+/** True when more than one backend is configured. */
+export function failoverEnabled() { return _backends.length > 1; }
+
+/** Base URL of the backend API calls should use ('' = same origin). */
+export function activeBackend() { return _backends[_active]; }
+
+export function backendLabel(base) {
+  if (!base) return location.host;
+  try { return new URL(base).host; } catch (e) { return base; }
+}
+
+export function getBackendStatus() {
+  return {
+    active: _backends[_active],
+    isPrimary: _active === 0,
+    backends: _backends.map(function(b) { return Object.assign({ url: b, label: backendLabel(b) }, _health[b] || { healthy: true }); }),
+  };
+}
+
+/** Subscribe to backend switches and health updates. Returns an unsubscribe fn. */
+export function onBackendChange(fn) {
+  _listeners.push(fn);
+  return function() { _listeners = _listeners.filter(function(l) { return l !== fn; }); };
+}
+
+/**
+ * Mark a backend unreachable and switch to the next healthy one.
+ * Returns true if another backend is available to retry against.
+ */
+export function markBackendDown(base) {
+  _health[base] = { healthy: false, checkedAt: Date.now() };
+  for (var i = 1; i <= _backends.length; i++) {
+    var idx = (_backends.indexOf(base) + i) % _backends.length;
+    var st = _health[_backends[idx]];
+    if (_backends[idx] !== base && (!st || st.healthy)) { setActive(idx); return true; }
+  }
+  notify();
+  return false;
+}
+
+function checkBackend(base) {
+  var ctrl = new AbortController();
+  var t = setTimeout(function() { ctrl.abort(); }, HEALTH_TIMEOUT_MS);
+  var started = performance.now();
+  return fetch(base + '/ready', { cache: 'no-store', credentials: 'omit', signal: ctrl.signal })
+    .then(function(r) { return r.ok; })
+    .catch(function() { return false; })
+    .then(function(ok) {
+      clearTimeout(t);
+      _health[base] = { healthy: ok, checkedAt: Date.now(), latencyMs: Math.round(performance.now() - started) };
+    });
+}
+
+function runHealthChecks() {
+  return Promise.all(_backends.map(checkBackend)).then(function() {
+    // Prefer the lowest-index healthy backend — returns to the primary when it recovers
+    var preferred = _backends.findIndex(function(b) { return _health[b] && _health[b].healthy; });
+    if (preferred !== -1) setActive(preferred);
+    notify();
+  });
+}
+
+/** Start periodic health checks (no-op unless failover is configured). */
+export function startBackendHealthChecks() {
+  if (!failoverEnabled() || _timer) return;
+  runHealthChecks();
+  var interval = (_cfg && _cfg.healthCheckIntervalSec ? _cfg.healthCheckIntervalSec * 1000 : DEFAULT_HEALTH_INTERVAL_MS);
+  _timer = setInterval(runHealthChecks, interval);
+}
@@ -0,0 +1,67 @@
+import { h } from './utils.js';
+
+// ─── Charts ──────────────────────────────────────────────
+// Small inline SVG charts for showing a trend next to a number. No
+// dependencies and no axes — hover a bar or the line for the values.
+//
+//   h(Sparkline, { values: [3, 5, 2, 8], color: 'var(--accent)' })
+//   h(BarChart, { data: trend.map(d => ({ label: d.day, value: d.toolCalls })), height: 80 })
+
+/** Min and max with a non-zero span so a flat series still draws. */
+function extent(values, min, max) {
+  var lo = min != null ? min : Math.min.apply(null, values.concat([0]));
+  var hi = max != null ? max : Math.max.apply(null, values);
+  if (!(hi > lo)) hi = lo + 1;
+  return [lo, hi];
+}
+
+/**
+ * Line of `values`, oldest first. Optional: width, height, color, fill
+ * (shade under the line), min/max (fixed scale), title (tooltip).
+ */
+export function Sparkline(props) {
+  var values = (props.values || []).map(Number).filter(function(v) { return !isNaN(v); });
+  if (values.length < 2) return null;
+  var W = props.width || 80, H = props.height || 24, pad = 2;
+  var ext = extent(values, props.min, props.max);
+  var step = W / (values.length - 1);
+  var y = function(v) { return pad + (H - pad * 2) * (1 - (v - ext[0]) / (ext[1] - ext[0])); };
+  var line = values.map(function(v, i) { return (i ? 'L' : 'M') + (i * step).toFixed(1) + ' ' + y(v).toFixed(1); }).join(' ');
+  var color = props.color || 'var(--accent)';
+  return h('svg', { width: W, height: H, viewBox: '0 0 ' + W + ' ' + H, role: 'img', 'aria-label': props.title || 'Trend', style: Object.assign({ verticalAlign: 'middle', overflow: 'visible' }, props.style) },
+    props.title && h('title', null, props.title),
+    props.fill && h('path', { d: line + ' L' + W + ' ' + H + ' L0 ' + H + ' Z', fill: color, opacity: 0.12 }),
+    h('path', { d: line, fill: 'none', stroke: color, strokeWidth: 1.5, strokeLinejoin: 'round', strokeLinecap: 'round' }),
+    h('circle', { cx: W, cy: y(values[values.length - 1]), r: 2, fill: color })
+  );
+}
+
+/**
+ * Vertical bars for `data` ([{ label, value, color? }], oldest first).
+ * Optional: width (defaults to filling the container), height, color,
+ * formatValue for the tooltips.
+ */
+export function BarChart(props) {
+  var data = props.data || [];
+  if (data.length === 0) return null;
+  var H = props.height || 80;
+  var W = props.width || data.length * 12;
+  var gap = data.length > 40 ? 1 : 2;
+  var bw = Math.max(1, W / data.length - gap);
+  var max = Math.max.apply(null, data.map(function(d) { return Number(d.value) || 0; }).concat([1]));
+  var fmt = props.formatValue || function(v) { return Number(v).toLocaleString(); };
+  return h('svg', {
+    width: props.width || '100%', height: H, viewBox: '0 0 ' + W + ' ' + H, preserveAspectRatio: 'none',
+    role: 'img', 'aria-label': props.title || 'Bar chart', style: Object.assign({ display: 'block' }, props.style),
+  },
+    data.map(function(d, i) {
+      var v = Number(d.value) || 0;
+      var bh = v > 0 ? Math.max(1, v / max * H) : 0;
+      return h('g', { key: d.label != null ? d.label : i },
+        // Full-height transparent hit area so short bars are still easy to hover
+        h('rect', { x: i * (bw + gap), y: 0, width: bw, height: H, fill: 'transparent' }, h('title', null, (d.label != null ? d.label + ': ' : '') + fmt(v))),
+        h('rect', { x: i * (bw + gap), y: H - bh, width: bw, height: bh, rx: Math.min(2, bw / 2), fill: d.color || props.color || 'var(--accent)', style: { pointerEvents: 'none' } })
+      );
+    })
+  );
+}
@@ -0,0 +1,93 @@
+import { h, useState, useEffect, useCallback, engineCall, getOrgId } from './utils.js';
+
+// ─── Data Classification ────────────────────────────────
+// Labels on knowledge bases, role templates and mailboxes. The engine keeps
+// them in /engine/classifications; DLP rules and the egress filter key off
+// an agent's highest label.
+
+export var CLASSIFICATION_LEVELS = [
+  { value: 'public', label: 'Public' },
+  { value: 'internal', label: 'Internal' },
+  { value: 'confidential', label: 'Confidential' },
+  { value: 'restricted', label: 'Restricted' },
+];
+
+function levelLabel(level) {
+  var l = CLASSIFICATION_LEVELS.find(function(x) { return x.value === level; });
+  return l ? l.label : level;
+}
+
+/** Coloured chip; renders nothing for unclassified unless showNone */
+export function ClassificationChip(props) {
+  if (!props.level) {
+    return props.showNone ? h('span', { className: 'classification-chip classification-none', title: 'Not classified' }, 'Unclassified') : null;
+  }
+  return h('span', { className: 'classification-chip classification-' + props.level, title: 'Data classification: ' + levelLabel(props.level), style: props.style }, levelLabel(props.level));
+}
+
+/** Level picker; value '' means unclassified (or "any" for policy minimums, via noneLabel) */
+export function ClassificationSelect(props) {
+  return h('select', {
+    className: 'input', style: Object.assign({ width: 'auto' }, props.style || {}), value: props.value || '', disabled: props.disabled,
+    onChange: function(e) { props.onChange(e.target.value || null); },
+  },
+    h('option', { value: '' }, props.noneLabel || 'Unclassified'),
+    CLASSIFICATION_LEVELS.map(function(l) { return h('option', { key: l.value, value: l.value }, props.atOrAbove ? l.label + ' and above' : l.label); })
+  );
+}
+
+/**
+ * Labels of one resource type for an org (default: the current one):
+ *   var cls = useClassifications('knowledge_base');
+ *   cls.get(kb.id)  → 'confidential' | null
+ *   cls.set(kb.id, 'restricted')  (null clears)
+ */
+export function useClassifications(type, orgId) {
+  orgId = orgId || getOrgId();
+  var [labels, setLabels] = useState({});
+
+  useEffect(function() {
+    engineCall('/classifications?type=' + type + '&orgId=' + encodeURIComponent(orgId)).then(function(d) {
+      var map = {};
+      (d.classifications || []).forEach(function(c) { map[c.resourceId] = c.level; });
+      setLabels(map);
+    }).catch(function() { setLabels({}); });
+  }, [type, orgId]);
+
+  var set = useCallback(function(id, level) {
+    return engineCall('/classifications/' + type + '/' + encodeURIComponent(id), {
+      method: 'PUT', body: JSON.stringify({ orgId: orgId, level: level || null }),
+    }).then(function() {
+      setLabels(function(prev) { var next = Object.assign({}, prev); if (level) next[id] = level; else delete next[id]; return next; });
+    });
+  }, [type, orgId]);
+
+  return { labels: labels, get: function(id) { return labels[id] || null; }, set: set };
+}
+
+/**
+ * Mailbox label picker for one agent, with the effective level it handles
+ * (mailbox plus knowledge bases) shown alongside when that's higher.
+ */
+export function AgentClassification(props) {
+  var [state, setState] = useState({ mailbox: null, effective: null });
+
+  var load = useCallback(function() {
+    engineCall('/classifications/agents/' + encodeURIComponent(props.agentId))
+      .then(function(d) { setState({ mailbox: d.mailbox || null, effective: d.effective || null }); })
+      .catch(function() {});
+  }, [props.agentId]);
+  useEffect(load, [load]);
+
+  var change = function(level) {
+    engineCall('/classifications/mailbox/' + encodeURIComponent(props.agentId), {
+      method: 'PUT', body: JSON.stringify({ orgId: props.orgId || getOrgId(), level: level }),
+    }).then(load).catch(function(err) { if (props.onError) props.onError(err); });
+  };
+
+  return h('span', { style: { display: 'inline-flex', alignItems: 'center', gap: 6 } },
+    h(ClassificationSelect, { value: state.mailbox, noneLabel: 'Mailbox unclassified', style: { fontSize: 12, padding: '2px 6px' }, onChange: change }),
+    state.effective && state.effective !== state.mailbox && h('span', { title: 'Highest classification across this agent\'s mailbox and knowledge bases', style: { display: 'inline-flex', alignItems: 'center', gap: 4, fontSize: 11, color: 'var(--text-muted)' } },
+      'handles', h(ClassificationChip, { level: state.effective }))
+  );
+}
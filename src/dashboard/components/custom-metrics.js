@@ -0,0 +1,138 @@
+import { h, useState, useEffect, useCallback, apiCall, batchGet, useApp } from './utils.js';
+import { I } from './icons.js';
+import { Modal } from './modal.js';
+import { evaluatePath } from './jsonpath.js';
+
+// ─── Custom Metrics ─────────────────────────────────────
+// Admin-defined stat cards on the home dashboard (admin/dashboard-metrics.ts).
+// Each endpoint is fetched as the signed-in user — batched into one request —
+// and the JSONPath picks the number out. A metric whose endpoint the user
+// can't read is dropped rather than shown as an error.
+
+export var METRIC_FORMATS = [
+  { value: 'number', label: 'Number' },
+  { value: 'percent', label: 'Percent (0-1 or 0-100)' },
+  { value: 'currency', label: 'Currency (USD)' },
+  { value: 'duration', label: 'Duration (ms)' },
+  { value: 'bytes', label: 'Bytes' },
+  { value: 'text', label: 'Text' },
+];
+
+export function formatMetric(value, format) {
+  if (value === undefined || value === null) return '-';
+  if (Array.isArray(value)) return value.length > 3 ? value.slice(0, 3).join(', ') + ', …' : value.join(', ');
+  if (typeof value === 'object') return JSON.stringify(value);
+  var n = Number(value);
+  if (format === 'text' || isNaN(n)) return String(value);
+  switch (format) {
+    case 'percent': return (n <= 1 && n >= -1 ? n * 100 : n).toFixed(1).replace(/\.0$/, '') + '%';
+    case 'currency': return '$' + (Math.abs(n) >= 100 ? Math.round(n).toLocaleString() : n.toFixed(2));
+    case 'duration': return n >= 60000 ? (n / 60000).toFixed(1) + ' min' : n >= 1000 ? (n / 1000).toFixed(1) + ' s' : Math.round(n) + ' ms';
+    case 'bytes': {
+      var units = ['B', 'KB', 'MB', 'GB', 'TB'], u = 0;
+      while (Math.abs(n) >= 1024 && u < units.length - 1) { n /= 1024; u++; }
+      return (u ? n.toFixed(1) : n) + ' ' + units[u];
+    }
+    default: return n.toLocaleString(undefined, { maximumFractionDigits: 2 });
+  }
+}
+
+function resolveEndpoint(endpoint, orgId) {
+  return endpoint.replace(/\{orgId\}/g, encodeURIComponent(orgId || ''));
+}
+
+/** Fetch and evaluate one metric; resolves { value } or { error }. */
+export function evaluateMetric(metric, orgId, fetcher) {
+  return (fetcher || batchGet)(resolveEndpoint(metric.endpoint, orgId))
+    .then(function(body) {
+      try { return { value: evaluatePath(body, metric.field) }; } catch (e) { return { error: e.message }; }
+    })
+    .catch(function(e) { return { error: e.message, denied: /permission|forbidden|unauthorized/i.test(e.message) }; });
+}
+
+/** Definitions plus current values: { metrics, values: { [id]: { value } | { error } }, reload } */
+export function useCustomMetrics(orgId) {
+  var [metrics, setMetrics] = useState([]);
+  var [values, setValues] = useState({});
+  var load = useCallback(function() {
+    apiCall('/dashboard/metrics').then(function(d) {
+      var list = d.metrics || [];
+      setMetrics(list);
+      return Promise.all(list.map(function(m) { return evaluateMetric(m, orgId); })).then(function(results) {
+        var next = {};
+        list.forEach(function(m, i) { next[m.id] = results[i]; });
+        setValues(next);
+      });
+    }).catch(function() {});
+  }, [orgId]);
+  useEffect(function() { load(); }, [load]);
+  return { metrics: metrics, values: values, reload: load };
+}
+
+/** Modal for admins to add, edit, test and remove the org's custom metrics. */
+export function CustomMetricsEditor(props) {
+  var { toast } = useApp();
+  var [rows, setRows] = useState(function() { return (props.metrics || []).map(function(m) { return Object.assign({}, m); }); });
+  var [previews, setPreviews] = useState({});
+  var [saving, setSaving] = useState(false);
+
+  var update = function(i, key, value) {
+    setRows(function(prev) { var next = prev.slice(); next[i] = Object.assign({}, next[i], { [key]: value }); return next; });
+  };
+  var add = function() { setRows(rows.concat([{ label: '', endpoint: '', field: '$.total', format: 'number' }])); };
+  var remove = function(i) { setRows(rows.filter(function(_, j) { return j !== i; })); };
+  var test = function(i) {
+    var m = rows[i];
+    setPreviews(function(p) { return Object.assign({}, p, { [i]: { loading: true } }); });
+    evaluateMetric(m, props.orgId, apiCall).then(function(r) {
+      setPreviews(function(p) { return Object.assign({}, p, { [i]: r }); });
+    });
+  };
+  var save = function() {
+    setSaving(true);
+    apiCall('/admin/dashboard-metrics', { method: 'PUT', body: JSON.stringify({ metrics: rows }) })
+      .then(function() { toast('Custom metrics saved', 'success'); props.onSaved(); })
+      .catch(function(e) { toast(e.message, 'error'); })
+      .finally(function() { setSaving(false); });
+  };
+
+  var _label = { fontSize: 11, color: 'var(--text-muted)', display: 'block', marginBottom: 2 };
+  return h(Modal, {
+    title: 'Custom Metrics', onClose: props.onClose, large: true,
+    footer: h('div', { style: { display: 'flex', gap: 8, justifyContent: 'flex-end' } },
+      h('button', { className: 'btn btn-secondary', onClick: props.onClose }, 'Cancel'),
+      h('button', { className: 'btn btn-primary', disabled: saving, onClick: save }, saving ? 'Saving...' : 'Save')
+    ),
+  },
+    h('p', { style: { fontSize: 13, color: 'var(--text-muted)', marginTop: 0 } },
+      'Each card reads an API endpoint (relative to /api, e.g. /engine/approvals/pending) and picks a value with a JSONPath expression such as $.total or ',
+      h('code', null, "$.agents[?(@.status == 'active')].length"),
+      '. Use {orgId} in the endpoint for the selected organization. Users only see cards whose endpoint they can read.'
+    ),
+    rows.length === 0 && h('div', { style: { padding: 16, textAlign: 'center', color: 'var(--text-muted)', fontSize: 13 } }, 'No custom metrics yet'),
+    rows.map(function(m, i) {
+      var preview = previews[i];
+      return h('div', { key: i, style: { border: '1px solid var(--border)', borderRadius: 8, padding: 12, marginBottom: 10 } },
+        h('div', { style: { display: 'grid', gridTemplateColumns: '1fr 2fr', gap: 8 } },
+          h('label', null, h('span', { style: _label }, 'Label'), h('input', { className: 'input', value: m.label, placeholder: 'Pending approvals', onChange: function(e) { update(i, 'label', e.target.value); } })),
+          h('label', null, h('span', { style: _label }, 'Endpoint'), h('input', { className: 'input', value: m.endpoint, placeholder: '/engine/approvals/pending', style: { fontFamily: 'var(--font-mono, monospace)' }, onChange: function(e) { update(i, 'endpoint', e.target.value); } })),
+          h('label', null, h('span', { style: _label }, 'Format'), h('select', { className: 'input', value: m.format || 'number', onChange: function(e) { update(i, 'format', e.target.value); } },
+            METRIC_FORMATS.map(function(f) { return h('option', { key: f.value, value: f.value }, f.label); })
+          )),
+          h('label', null, h('span', { style: _label }, 'JSONPath'), h('input', { className: 'input', value: m.field, placeholder: '$.total', style: { fontFamily: 'var(--font-mono, monospace)' }, onChange: function(e) { update(i, 'field', e.target.value); } }))
+        ),
+        h('div', { style: { display: 'flex', alignItems: 'center', gap: 8, marginTop: 8 } },
+          h('button', { className: 'btn btn-secondary btn-sm', disabled: !m.endpoint || !m.field, onClick: function() { test(i); } }, I.play(), ' Test'),
+          preview && (preview.loading
+            ? h('span', { style: { fontSize: 12, color: 'var(--text-muted)' } }, 'Fetching...')
+            : preview.error
+              ? h('span', { style: { fontSize: 12, color: 'var(--danger)' } }, preview.error)
+              : h('span', { style: { fontSize: 12 } }, 'Value: ', h('strong', null, formatMetric(preview.value, m.format)))),
+          h('span', { style: { flex: 1 } }),
+          h('button', { className: 'btn btn-ghost btn-sm', title: 'Remove metric', 'aria-label': 'Remove metric', onClick: function() { remove(i); } }, I.trash())
+        )
+      );
+    }),
+    h('button', { className: 'btn btn-secondary btn-sm', onClick: add }, I.plus(), ' Add metric')
+  );
+}
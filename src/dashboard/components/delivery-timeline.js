@@ -0,0 +1,62 @@
+import { h, useState, useEffect, engineCall } from './utils.js';
+
+// ─── Delivery timeline ───────────────────────────────────
+// queued → sent → delivered → opened, or → bounced/failed with the reason.
+// Steps that haven't happened yet are drawn as pending; a failed message
+// gets a Resend action that sends a linked copy.
+
+var STEPS = ['queued', 'sent', 'delivered', 'opened'];
+var LABELS = { queued: 'Queued', sent: 'Sent', delivered: 'Delivered', opened: 'Opened', bounced: 'Bounced', failed: 'Failed', resent: 'Resent' };
+
+export function DeliveryTimeline(props) {
+  var [data, setData] = useState(null);
+  var [resending, setResending] = useState(false);
+
+  var load = function() {
+    engineCall('/messages/' + encodeURIComponent(props.messageId) + '/timeline')
+      .then(function(d) { setData(d); })
+      .catch(function() { setData({ events: [], status: null }); });
+  };
+  useEffect(load, [props.messageId]);
+
+  if (!data) return h('div', { style: { fontSize: 12, color: 'var(--text-muted)' } }, 'Loading delivery status...');
+
+  var events = data.events || [];
+  var byType = {};
+  events.forEach(function(e) { if (!byType[e.event]) byType[e.event] = e; });
+  var failure = events.filter(function(e) { return e.event === 'bounced' || e.event === 'failed'; }).pop();
+  var resent = events.filter(function(e) { return e.event === 'resent'; });
+
+  var resend = function() {
+    setResending(true);
+    engineCall('/messages/' + encodeURIComponent(props.messageId) + '/resend', { method: 'POST' })
+      .then(function(d) { if (props.onResent) props.onResent(d.message); load(); })
+      .catch(function(e) { if (props.toast) props.toast(e.message, 'error'); })
+      .finally(function() { setResending(false); });
+  };
+
+  // Stop the happy path at the failure point
+  var steps = STEPS.filter(function(s) { return !failure || byType[s]; });
+  if (failure) steps.push(failure.event);
+
+  return h('div', null,
+    h('ol', { className: 'delivery-timeline' },
+      steps.map(function(s) {
+        var e = s === failure?.event ? failure : byType[s];
+        var cls = 'delivery-step' + (e ? ' done' : '') + (e && (s === 'bounced' || s === 'failed') ? ' error' : '');
+        return h('li', { key: s, className: cls, title: e && e.inferred ? 'Inferred from the message record' : undefined },
+          h('span', { className: 'delivery-dot' }),
+          h('span', { className: 'delivery-label' }, LABELS[s]),
+          h('span', { className: 'delivery-time' }, e ? new Date(e.createdAt).toLocaleString() : 'Pending')
+        );
+      })
+    ),
+    failure && h('div', { className: 'delivery-error' },
+      h('strong', null, LABELS[failure.event] + ': '), failure.reason || 'No reason reported',
+      data.status === 'failed' && h('button', { className: 'btn btn-secondary btn-sm', style: { marginLeft: 'auto' }, disabled: resending, onClick: resend }, resending ? 'Resending...' : 'Resend')
+    ),
+    resent.length > 0 && h('div', { style: { fontSize: 12, color: 'var(--text-muted)', marginTop: 6 } },
+      'Resent ' + resent.length + ' time' + (resent.length === 1 ? '' : 's') + ', last ' + new Date(resent[resent.length - 1].createdAt).toLocaleString()
+    )
+  );
+}
@@ -0,0 +1,73 @@
+import { h, useState, useEffect, useRef, useCallback, engineCall } from './utils.js';
+
+// ─── Server-side Form Drafts ─────────────────────────────
+// Long forms persist their state per user via /engine/drafts/:key, so an
+// accidental navigation doesn't lose work. Drafts expire after 7 days idle.
+
+var AUTOSAVE_DELAY_MS = 1500;
+
+/**
+ * useFormDraft(key, value, { enabled })
+ *   pending   — a saved draft found on mount (offer to resume it), or null
+ *   resume()  — returns the pending draft's data and dismisses the prompt
+ *   discard() — deletes the saved draft
+ *   clear()   — deletes the draft after a successful submit
+ *   savedAt   — timestamp of the last autosave
+ * Autosaves `value` (debounced) while `enabled` is true and no prompt is open.
+ */
+export function useFormDraft(key, value, opts) {
+  var enabled = !opts || opts.enabled !== false;
+  var [pending, setPending] = useState(null);
+  var [checked, setChecked] = useState(false);
+  var [savedAt, setSavedAt] = useState(null);
+  var timer = useRef(null);
+  var cleared = useRef(null); // value snapshot at clear() — not re-saved until it changes
+
+  useEffect(function() {
+    engineCall('/drafts/' + encodeURIComponent(key))
+      .then(function(d) { if (d && d.draft) setPending(d.draft); })
+      .catch(function() { /* no draft */ })
+      .finally(function() { setChecked(true); });
+  }, [key]);
+
+  var snapshot = JSON.stringify(value);
+  useEffect(function() {
+    if (!checked || pending || !enabled || cleared.current === snapshot) return;
+    cleared.current = null;
+    clearTimeout(timer.current);
+    timer.current = setTimeout(function() {
+      engineCall('/drafts/' + encodeURIComponent(key), { method: 'PUT', body: JSON.stringify({ data: value }) })
+        .then(function(d) { if (d && d.draft) setSavedAt(d.draft.updatedAt); })
+        .catch(function() { /* autosave is best-effort */ });
+    }, AUTOSAVE_DELAY_MS);
+    return function() { clearTimeout(timer.current); };
+  }, [key, checked, pending, enabled, snapshot]);
+
+  var remove = useCallback(function() {
+    clearTimeout(timer.current);
+    setPending(null);
+    return engineCall('/drafts/' + encodeURIComponent(key), { method: 'DELETE' }).catch(function() {});
+  }, [key]);
+
+  return {
+    pending: pending,
+    savedAt: savedAt,
+    resume: function() { var data = pending ? pending.data : null; setPending(null); return data; },
+    discard: remove,
+    clear: function() { cleared.current = snapshot; return remove(); },
+  };
+}
+
+/** "Resume draft?" banner shown above a form when a saved draft exists. */
+export function DraftPrompt(props) {
+  var draft = props.draft;
+  if (!draft) return null;
+  return h('div', { style: { display: 'flex', alignItems: 'center', gap: 12, padding: '10px 14px', marginBottom: 16, background: 'var(--accent-soft, rgba(99,102,241,0.1))', border: '1px solid var(--accent, #6366f1)', borderRadius: 'var(--radius, 8px)', fontSize: 13 } },
+    h('div', { style: { flex: 1 } },
+      h('strong', null, 'Unsaved draft found'),
+      ' — last edited ' + new Date(draft.updatedAt).toLocaleString() + '.'
+    ),
+    h('button', { className: 'btn btn-primary btn-sm', onClick: props.onResume }, 'Resume draft'),
+    h('button', { className: 'btn btn-ghost btn-sm', onClick: props.onDiscard }, 'Discard')
+  );
+}
@@ -0,0 +1,117 @@
+import { h, useState, useEffect, useRef } from './utils.js';
+import { I } from './icons.js';
+import { SavedViews } from './saved-views.js';
+
+// ─── Filter Bar ──────────────────────────────────────────
+// Text search plus field dropdowns for list pages. Filtering happens on the
+// server: useFilters keeps the values in the page URL (?q=&status=...) so a
+// filtered view survives reloads and can be shared, and its `params` / `query`
+// are passed straight through to the list endpoint.
+//
+//   var filters = useFilters({ q: '', status: '' });
+//   useFragment('/agents/table', Object.assign({ ... }, filters.params), ...)
+//   h(FilterBar, { filters, placeholder: 'Search agents...', fields: [
+//     { key: 'status', label: 'All statuses', options: [{ value: 'active', label: 'Active' }] }
+//   ] })
+//
+// With `viewsKey` the bar also offers the user's saved views for that page.
+
+function readUrl(keys) {
+  var out = {};
+  try {
+    var sp = new URLSearchParams(location.search);
+    keys.forEach(function(k) { if (sp.has(k)) out[k] = sp.get(k); });
+  } catch (e) { /* no URL access */ }
+  return out;
+}
+
+function writeUrl(values, defaults) {
+  try {
+    var sp = new URLSearchParams(location.search);
+    Object.keys(defaults).forEach(function(k) {
+      if (values[k] && values[k] !== defaults[k]) sp.set(k, values[k]); else sp.delete(k);
+    });
+    var qs = sp.toString();
+    history.replaceState(history.state, '', location.pathname + (qs ? '?' + qs : '') + location.hash);
+  } catch (e) { /* no URL access */ }
+}
+
+/**
+ * useFilters(defaults) → { values, set(key, value), replace(values), clear(), params, query, active }
+ * `params` holds only non-empty values; `active` counts filters that differ
+ * from their default.
+ */
+export function useFilters(defaults) {
+  var keys = Object.keys(defaults);
+  var [values, setValues] = useState(function() { return Object.assign({}, defaults, readUrl(keys)); });
+
+  useEffect(function() { writeUrl(values, defaults); }, [JSON.stringify(values)]);
+
+  var params = {};
+  keys.forEach(function(k) { if (values[k]) params[k] = values[k]; });
+  return {
+    values: values,
+    set: function(key, value) { setValues(function(v) { var n = Object.assign({}, v); n[key] = value; return n; }); },
+    replace: function(next) {
+      var out = Object.assign({}, defaults);
+      keys.forEach(function(k) { if (next && next[k] != null) out[k] = next[k]; });
+      setValues(out);
+    },
+    clear: function() { setValues(Object.assign({}, defaults)); },
+    params: params,
+    query: Object.keys(params).map(function(k) { return encodeURIComponent(k) + '=' + encodeURIComponent(params[k]); }).join('&'),
+    active: keys.filter(function(k) { return (values[k] || '') !== (defaults[k] || ''); }).length,
+  };
+}
+
+/**
+ * FilterBar({ filters, fields, placeholder, queryKey, viewsKey, summary, style, children })
+ * The text box commits after a short pause; dropdowns apply immediately.
+ * Field: { key, label, options: [{ value, label }] | string[], width }
+ */
+export function FilterBar(props) {
+  var filters = props.filters;
+  var queryKey = props.queryKey || 'q';
+  var committed = filters.values[queryKey] || '';
+  var [text, setText] = useState(committed);
+  var timer = useRef(null);
+
+  // Follow external changes (Clear, saved views)
+  useEffect(function() { setText(committed); }, [committed]);
+  useEffect(function() { return function() { clearTimeout(timer.current); }; }, []);
+
+  var onText = function(v) {
+    setText(v);
+    clearTimeout(timer.current);
+    timer.current = setTimeout(function() { filters.set(queryKey, v.trim()); }, 300);
+  };
+
+  return h('div', { className: 'filter-bar', style: props.style },
+    h('div', { className: 'filter-bar-search' },
+      I.search(),
+      h('input', {
+        className: 'input', type: 'search', placeholder: props.placeholder || 'Search...', value: text,
+        'aria-label': props.placeholder || 'Search',
+        onChange: function(e) { onText(e.target.value); },
+        onKeyDown: function(e) { if (e.key === 'Enter') { clearTimeout(timer.current); filters.set(queryKey, text.trim()); } }
+      })
+    ),
+    (props.fields || []).map(function(f) {
+      return h('select', {
+        key: f.key, className: 'input', style: { width: f.width || 160 }, 'aria-label': f.label,
+        value: filters.values[f.key] || '',
+        onChange: function(e) { filters.set(f.key, e.target.value); }
+      },
+        h('option', { value: '' }, f.label),
+        f.options.map(function(o) {
+          var opt = typeof o === 'string' ? { value: o, label: o.charAt(0).toUpperCase() + o.slice(1) } : o;
+          return h('option', { key: opt.value, value: opt.value }, opt.label);
+        })
+      );
+    }),
+    filters.active > 0 && h('button', { className: 'btn btn-ghost btn-sm', onClick: filters.clear }, 'Clear'),
+    props.viewsKey && h(SavedViews, { viewsKey: props.viewsKey, values: filters.values, onApply: filters.replace }),
+    props.summary && h('span', { className: 'filter-bar-summary' }, props.summary),
+    props.children
+  );
+}
@@ -0,0 +1,117 @@
+import { h, useState, useEffect, useRef, useCallback, apiCall } from './utils.js';
+
+// ─── Table Fragments ─────────────────────────────────────
+// Fragment endpoints (/agents/table, /audit/rows) return one page of rows:
+//   { rows, page, pageSize, total, pages, hasMore }
+// useFragment refreshes only that data. The current rows stay on screen while
+// the next page or filter loads, so paginating never blanks the table.
+
+function buildQuery(params) {
+  var parts = [];
+  Object.keys(params || {}).forEach(function(k) {
+    var v = params[k];
+    if (v !== undefined && v !== null && v !== '') parts.push(encodeURIComponent(k) + '=' + encodeURIComponent(v));
+  });
+  return parts.length ? '?' + parts.join('&') : '';
+}
+
+/**
+ * useFragment(path, params, { pageSize })
+ *   rows, total, page, pages, hasMore
+ *   loading    — true only until the first page arrives
+ *   refreshing — true while a newer page/filter is loading over existing rows
+ *   setPage(n), reload()
+ * Changing `params` resets to page 1.
+ */
+export function useFragment(path, params, opts) {
+  var pageSize = (opts && opts.pageSize) || 25;
+  var [page, setPage] = useState(1);
+  var [data, setData] = useState(null);
+  var [refreshing, setRefreshing] = useState(false);
+  var [error, setError] = useState(null);
+  var seq = useRef(0);
+  var paramKey = JSON.stringify(params || {});
+
+  var fetchPage = useCallback(function(p) {
+    var id = ++seq.current;
+    setRefreshing(true);
+    return apiCall(path + buildQuery(Object.assign({}, params, { p: p, pageSize: pageSize })))
+      .then(function(d) { if (id === seq.current) { setData(d); setError(null); } })
+      .catch(function(e) { if (id === seq.current) setError(e.message); })
+      .finally(function() { if (id === seq.current) setRefreshing(false); });
+  }, [path, paramKey, pageSize]);
+
+  useEffect(function() { setPage(1); fetchPage(1); }, [fetchPage]);
+
+  return {
+    rows: data ? data.rows : [],
+    total: data ? data.total : 0,
+    pages: data ? data.pages : 1,
+    hasMore: data ? data.hasMore : false,
+    page: page,
+    pageSize: pageSize,
+    loading: !data && !error,
+    refreshing: refreshing && !!data,
+    error: error,
+    setPage: function(p) { setPage(p); fetchPage(p); },
+    reload: function() { return fetchPage(page); },
+  };
+}
+
+// ─── Deferred Fragments ──────────────────────────────────
+// Slow sections load on their own after the page shell renders, showing a
+// skeleton meanwhile, so one slow endpoint doesn't hold up the whole page:
+//
+//   var events = useDeferred(function() { return engineCall('/activity/events?agentId=' + id); }, [id]);
+//   events.loading ? h(SkeletonTable, { columns: 3 }) : renderEvents(events.data)
+
+/**
+ * useDeferred(load, deps)
+ *   data       — last resolved value (kept while reloading)
+ *   loading    — true only until the first value arrives
+ *   error, reload()
+ * Responses from superseded loads (deps changed meanwhile) are dropped.
+ */
+export function useDeferred(load, deps) {
+  var [data, setData] = useState(null);
+  var [loaded, setLoaded] = useState(false);
+  var [error, setError] = useState(null);
+  var seq = useRef(0);
+
+  var run = useCallback(function() {
+    var id = ++seq.current;
+    return Promise.resolve().then(load)
+      .then(function(d) { if (id === seq.current) { setData(d); setError(null); } })
+      .catch(function(e) { if (id === seq.current) setError(e.message); })
+      .finally(function() { if (id === seq.current) setLoaded(true); });
+  }, deps || []);
+
+  useEffect(function() { setLoaded(false); run(); }, [run]);
+
+  return { data: data, loading: !loaded, error: error, reload: run };
+}
+
+/** Skeleton({ lines, width, height }) — shimmering placeholder text lines. */
+export function Skeleton(props) {
+  var lines = props.lines || 3;
+  var out = [];
+  for (var i = 0; i < lines; i++) {
+    out.push(h('span', { key: i, className: 'skeleton', style: { width: i === lines - 1 && lines > 1 ? '60%' : (props.width || '100%'), height: props.height || 12 } }));
+  }
+  return h('div', { className: 'skeleton-block', 'aria-busy': true, 'aria-label': props.label || 'Loading' }, out);
+}
+
+/** SkeletonTable({ rows, columns }) — placeholder rows sized like a data table. */
+export function SkeletonTable(props) {
+  var rows = props.rows || 5;
+  var columns = props.columns || 3;
+  var body = [];
+  for (var r = 0; r < rows; r++) {
+    var cells = [];
+    for (var c = 0; c < columns; c++) {
+      cells.push(h('td', { key: c }, h('span', { className: 'skeleton', style: { width: c === 0 ? '70%' : (40 + ((r * 7 + c * 13) % 50)) + '%' } })));
+    }
+    body.push(h('tr', { key: r }, cells));
+  }
+  return h('table', { className: 'skeleton-table', 'aria-busy': true, 'aria-label': props.label || 'Loading' }, h('tbody', null, body));
+}
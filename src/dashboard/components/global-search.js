@@ -0,0 +1,127 @@
+import { h, useState, useEffect, useRef, apiCall, getOrgId } from './utils.js';
+import { I } from './icons.js';
+
+// ─── Global Search ───────────────────────────────────────
+// Cmd-K / Ctrl-K overlay over GET /api/search, which queries agents, users,
+// vault secrets and the audit log as the signed-in user. Results come back
+// grouped; arrow keys move, Enter opens, Escape closes.
+//
+//   h(GlobalSearch, { orgId, onOpen: (result) => ... })
+// Result: { id, title, subtitle, page, query? }
+
+var DEBOUNCE_MS = 200;
+
+export function GlobalSearch(props) {
+  var [open, setOpen] = useState(false);
+  var [q, setQ] = useState('');
+  var [groups, setGroups] = useState([]);
+  var [loading, setLoading] = useState(false);
+  var [active, setActive] = useState(0);
+  var inputRef = useRef(null);
+  var seq = useRef(0);
+
+  useEffect(function() {
+    function onKey(e) {
+      if ((e.metaKey || e.ctrlKey) && (e.key === 'k' || e.key === 'K')) {
+        e.preventDefault();
+        setOpen(function(o) { return !o; });
+      }
+    }
+    // Other components (the topbar button) open it without owning its state
+    function onOpenEvent() { setOpen(true); }
+    document.addEventListener('keydown', onKey);
+    window.addEventListener('em:open-search', onOpenEvent);
+    return function() {
+      document.removeEventListener('keydown', onKey);
+      window.removeEventListener('em:open-search', onOpenEvent);
+    };
+  }, []);
+
+  useEffect(function() {
+    if (!open) { setQ(''); setGroups([]); setActive(0); return; }
+    var t = setTimeout(function() { if (inputRef.current) inputRef.current.focus(); }, 0);
+    return function() { clearTimeout(t); };
+  }, [open]);
+
+  useEffect(function() {
+    var term = q.trim();
+    if (term.length < 2) { setGroups([]); setLoading(false); return; }
+    setLoading(true);
+    var mine = ++seq.current;
+    var t = setTimeout(function() {
+      var orgId = props.orgId || getOrgId();
+      apiCall('/search?q=' + encodeURIComponent(term) + (orgId ? '&orgId=' + encodeURIComponent(orgId) : ''))
+        .then(function(d) { if (mine === seq.current) { setGroups(d.groups || []); setActive(0); } })
+        .catch(function() { if (mine === seq.current) setGroups([]); })
+        .finally(function() { if (mine === seq.current) setLoading(false); });
+    }, DEBOUNCE_MS);
+    return function() { clearTimeout(t); };
+  }, [q]);
+
+  if (!open) return null;
+
+  var flat = [];
+  groups.forEach(function(g) { g.results.forEach(function(r) { flat.push(r); }); });
+
+  var choose = function(r) {
+    setOpen(false);
+    props.onOpen(r);
+  };
+
+  var onKeyDown = function(e) {
+    if (e.key === 'Escape') { e.preventDefault(); setOpen(false); }
+    else if (e.key === 'ArrowDown') { e.preventDefault(); setActive(function(i) { return Math.min(i + 1, flat.length - 1); }); }
+    else if (e.key === 'ArrowUp') { e.preventDefault(); setActive(function(i) { return Math.max(i - 1, 0); }); }
+    else if (e.key === 'Enter' && flat[active]) { e.preventDefault(); choose(flat[active]); }
+  };
+
+  var index = 0;
+  var term = q.trim();
+
+  return h('div', { className: 'modal-overlay global-search-overlay', onClick: function() { setOpen(false); } },
+    h('div', { className: 'global-search', role: 'dialog', 'aria-label': 'Search', onClick: function(e) { e.stopPropagation(); } },
+      h('div', { className: 'global-search-input' },
+        I.search(),
+        h('input', {
+          ref: inputRef, type: 'text', value: q, placeholder: 'Search agents, users, secrets and audit events...',
+          'aria-label': 'Search', role: 'combobox', 'aria-expanded': flat.length > 0, 'aria-controls': 'global-search-results',
+          'aria-activedescendant': flat[active] ? 'gs-' + active : undefined,
+          onChange: function(e) { setQ(e.target.value); }, onKeyDown: onKeyDown,
+        }),
+        h('kbd', null, 'Esc')
+      ),
+      h('div', { className: 'global-search-results', id: 'global-search-results', role: 'listbox' },
+        term.length < 2
+          ? h('div', { className: 'global-search-empty' }, 'Type at least 2 characters')
+          : loading && flat.length === 0
+            ? h('div', { className: 'global-search-empty' }, 'Searching...')
+            : groups.length === 0 || (flat.length === 0 && !groups.some(function(g) { return g.error; }))
+              ? h('div', { className: 'global-search-empty' }, 'No results for "' + term + '"')
+              : groups.map(function(g) {
+                  if (g.results.length === 0 && !g.error) return null;
+                  return h('div', { key: g.key, className: 'global-search-group' },
+                    h('div', { className: 'global-search-group-label' }, g.label),
+                    g.error && h('div', { className: 'global-search-empty', style: { color: 'var(--danger)' } }, 'Search failed: ' + g.error),
+                    g.results.map(function(r) {
+                      var i = index++;
+                      return h('div', {
+                        key: g.key + ':' + r.id, id: 'gs-' + i, role: 'option', 'aria-selected': i === active,
+                        className: 'global-search-item' + (i === active ? ' active' : ''),
+                        onMouseEnter: function() { setActive(i); },
+                        onClick: function() { choose(r); },
+                      },
+                        h('div', { className: 'global-search-title' }, r.title),
+                        r.subtitle && h('div', { className: 'global-search-subtitle' }, r.subtitle)
+                      );
+                    })
+                  );
+                })
+      ),
+      h('div', { className: 'global-search-footer' },
+        h('span', null, h('kbd', null, '↑'), h('kbd', null, '↓'), ' to move'),
+        h('span', null, h('kbd', null, 'Enter'), ' to open'),
+        h('span', null, h('kbd', null, navigator.platform && navigator.platform.indexOf('Mac') === 0 ? '⌘K' : 'Ctrl K'), ' to toggle')
+      )
+    )
+  );
+}
@@ -17,6 +17,8 @@ export function HelpButton(props) {
     h('button', {
       onClick: function(e) { e.stopPropagation(); e.preventDefault(); setOpen(!isOpen); },
       title: 'Learn more about ' + (props.label || 'this section'),
+      'aria-label': 'Help: ' + (props.label || 'this section'),
+      'aria-expanded': isOpen,
       style: {
         display: 'inline-flex', alignItems: 'center', justifyContent: 'center',
         width: 22, height: 22, borderRadius: '50%',
@@ -1,6 +1,7 @@
 import { h } from './utils.js';
 
-var S = { viewBox: '0 0 24 24', width: 20, height: 20, fill: 'none', stroke: 'currentColor', strokeWidth: 2, strokeLinecap: 'round', strokeLinejoin: 'round' };
+// Icons are decorative: buttons and links that show only an icon carry their own aria-label/title
+var S = { viewBox: '0 0 24 24', width: 20, height: 20, fill: 'none', stroke: 'currentColor', strokeWidth: 2, strokeLinecap: 'round', strokeLinejoin: 'round', 'aria-hidden': true, focusable: 'false' };
 
 export const I = {
   dashboard: () => h('svg', S, h('rect', { x: 3, y: 3, width: 7, height: 7 }), h('rect', { x: 14, y: 3, width: 7, height: 7 }), h('rect', { x: 14, y: 14, width: 7, height: 7 }), h('rect', { x: 3, y: 14, width: 7, height: 7 })),
@@ -19,7 +20,7 @@ export const I = {
   sun: (o) => h('svg', Object.assign({}, S, o && o.size ? { width: o.size, height: o.size } : {}), h('path', { d: 'M12 2l2.1 4.1L18.5 4.5 17 9l4.5 2-4.5 2 1.5 4.5-4.4-1.6L12 20l-2.1-4.1L5.5 17.5 7 13l-4.5-2L7 9 5.5 4.5l4.4 1.6L12 2z' }), h('circle', { cx: 12, cy: 11, r: 3.5, fill: 'none' })),
   moon: (o) => h('svg', Object.assign({}, S, o && o.size ? { width: o.size, height: o.size } : {}), h('path', { d: 'M12 2l2.1 4.1L18.5 4.5 17 9l4.5 2-4.5 2 1.5 4.5-4.4-1.6L12 20l-2.1-4.1L5.5 17.5 7 13l-4.5-2L7 9 5.5 4.5l4.4 1.6L12 2z' }), h('path', { d: 'M15 11a4 4 0 11-7 3.5A3.2 3.2 0 0015 11z' })),
   logout: (o) => h('svg', Object.assign({}, S, o && o.size ? { width: o.size, height: o.size } : {}), h('circle', { cx: 12, cy: 8, r: 4 }), h('path', { d: 'M5 20c0-3.5 3.1-6 7-6' }), h('path', { d: 'M16 16l4 4m0-4l-4 4' })),
-  search: () => h('svg', S, h('circle', { cx: 11, cy: 11, r: 8 }), h('line', { x1: 21, y1: 21, x2: 16.65, y2: 16.65 })),
+  search: (o) => h('svg', Object.assign({}, S, o && o.size ? { width: o.size, height: o.size } : {}), h('circle', { cx: 11, cy: 11, r: 8 }), h('line', { x1: 21, y1: 21, x2: 16.65, y2: 16.65 })),
   shield: () => h('svg', S, h('path', { d: 'M12 22s8-4 8-10V5l-8-3-8 3v7c0 6 8 10 8 10z' })),
   copy: () => h('svg', S, h('rect', { x: 9, y: 9, width: 13, height: 13, rx: 2, ry: 2 }), h('path', { d: 'M5 15H4a2 2 0 01-2-2V4a2 2 0 012-2h9a2 2 0 012 2v1' })),
   play: () => h('svg', S, h('polygon', { points: '5 3 19 12 5 21 5 3' })),
@@ -39,8 +40,11 @@ export const I = {
   download: () => h('svg', S, h('path', { d: 'M21 15v4a2 2 0 01-2 2H5a2 2 0 01-2-2v-4' }), h('polyline', { points: '7 10 12 15 17 10' }), h('line', { x1: 12, y1: 15, x2: 12, y2: 3 })),
   marketplace: () => h('svg', S, h('path', { d: 'M6 2L3 6v14a2 2 0 002 2h14a2 2 0 002-2V6l-3-4z' }), h('line', { x1: 3, y1: 6, x2: 21, y2: 6 }), h('path', { d: 'M16 10a4 4 0 01-8 0' })),
   clock: () => h('svg', S, h('circle', { cx: 12, cy: 12, r: 10 }), h('polyline', { points: '12 6 12 12 16 14' })),
+  bell: (o) => h('svg', Object.assign({}, S, o && o.size ? { width: o.size, height: o.size } : {}), h('path', { d: 'M18 8A6 6 0 006 8c0 7-3 9-3 9h18s-3-2-3-9' }), h('path', { d: 'M13.73 21a2 2 0 01-3.46 0' })),
+  monitor: (o) => h('svg', Object.assign({}, S, o && o.size ? { width: o.size, height: o.size } : {}), h('rect', { x: 2, y: 3, width: 20, height: 14, rx: 2 }), h('line', { x1: 8, y1: 21, x2: 16, y2: 21 }), h('line', { x1: 12, y1: 17, x2: 12, y2: 21 })),
   calendar: () => h('svg', S, h('rect', { x: 3, y: 4, width: 18, height: 18, rx: 2, ry: 2 }), h('line', { x1: 16, y1: 2, x2: 16, y2: 6 }), h('line', { x1: 8, y1: 2, x2: 8, y2: 6 }), h('line', { x1: 3, y1: 10, x2: 21, y2: 10 })),
   link: () => h('svg', S, h('path', { d: 'M10 13a5 5 0 007.54.54l3-3a5 5 0 00-7.07-7.07l-1.72 1.71' }), h('path', { d: 'M14 11a5 5 0 00-7.54-.54l-3 3a5 5 0 007.07 7.07l1.71-1.71' })),
+  briefcase: () => h('svg', S, h('rect', { x: 2, y: 7, width: 20, height: 14, rx: 2, ry: 2 }), h('path', { d: 'M16 21V5a2 2 0 00-2-2h-4a2 2 0 00-2 2v16' })),
   folder: () => h('svg', S, h('path', { d: 'M22 19a2 2 0 01-2 2H4a2 2 0 01-2-2V5a2 2 0 012-2h5l2 3h9a2 2 0 012 2z' })),
   globe: () => h('svg', S, h('circle', { cx: 12, cy: 12, r: 10 }), h('line', { x1: 2, y1: 12, x2: 22, y2: 12 }), h('path', { d: 'M12 2a15.3 15.3 0 014 10 15.3 15.3 0 01-4 10 15.3 15.3 0 01-4-10 15.3 15.3 0 014-10z' })),
   workflow: () => h('svg', Object.assign({}, S, { width: 16, height: 16 }), h('circle', { cx: 4, cy: 12, r: 2.5, fill: 'currentColor', stroke: 'none' }), h('circle', { cx: 12, cy: 7, r: 2.5, fill: 'currentColor', stroke: 'none' }), h('circle', { cx: 12, cy: 17, r: 2.5, fill: 'currentColor', stroke: 'none' }), h('circle', { cx: 20, cy: 12, r: 2.5, fill: 'currentColor', stroke: 'none' }), h('path', { d: 'M6.5 11L9.5 8M6.5 13L9.5 16M14.5 8L17.5 11M14.5 16L17.5 13', stroke: 'currentColor', strokeWidth: 1.5, fill: 'none' })),
@@ -49,6 +53,7 @@ export const I = {
   chart: () => h('svg', S, h('line', { x1: 18, y1: 20, x2: 18, y2: 10 }), h('line', { x1: 12, y1: 20, x2: 12, y2: 4 }), h('line', { x1: 6, y1: 20, x2: 6, y2: 14 })),
   help: () => h('svg', S, h('circle', { cx: 12, cy: 12, r: 10 }), h('path', { d: 'M9.09 9a3 3 0 015.83 1c0 2-3 3-3 3' }), h('line', { x1: 12, y1: 17, x2: 12.01, y2: 17 })),
   lock: () => h('svg', S, h('rect', { x: 3, y: 11, width: 18, height: 11, rx: 2, ry: 2 }), h('path', { d: 'M7 11V7a5 5 0 0110 0v4' })),
+  unlock: () => h('svg', S, h('rect', { x: 3, y: 11, width: 18, height: 11, rx: 2, ry: 2 }), h('path', { d: 'M7 11V7a5 5 0 019.9-1' })),
   database: (sz) => h('svg', Object.assign({}, S, sz ? { width: sz, height: sz } : {}), h('ellipse', { cx: 12, cy: 5, rx: 9, ry: 3 }), h('path', { d: 'M21 12c0 1.66-4 3-9 3s-9-1.34-9-3' }), h('path', { d: 'M3 5v14c0 1.66 4 3 9 3s9-1.34 9-3V5' })),
   eye: () => h('svg', S, h('path', { d: 'M1 12s4-8 11-8 11 8 11 8-4 8-11 8-11-8-11-8z' }), h('circle', { cx: 12, cy: 12, r: 3 })),
   eyeOff: () => h('svg', S, h('path', { d: 'M17.94 17.94A10.07 10.07 0 0112 20c-7 0-11-8-11-8a18.45 18.45 0 015.06-5.94M9.9 4.24A9.12 9.12 0 0112 4c7 0 11 8 11 8a18.5 18.5 0 01-2.16 3.19m-6.72-1.07a3 3 0 11-4.24-4.24' }), h('line', { x1: 1, y1: 1, x2: 23, y2: 23 })),
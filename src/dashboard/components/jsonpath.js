@@ -0,0 +1,89 @@
+// ─── JSONPath ───────────────────────────────────────────
+// The subset custom dashboard metrics need:
+//
+//   $.total                                   a field
+//   $.agents[0].name  $['odd key']  $.a[-1]   index / quoted key
+//   $.agents[*].status                        every element
+//   $.agents[?(@.status == 'active')]         filter (== != > < >= <=)
+//   ....length  .count()  .sum()  .avg()  .min()  .max()   aggregate the matches
+//
+// evaluatePath() returns the single value, an array when a wildcard or filter
+// matched several, or undefined. Throws on a malformed expression.
+
+var AGGREGATES = {
+  count: function(v) { return v.length; },
+  sum: function(v) { return v.reduce(function(s, x) { return s + (Number(x) || 0); }, 0); },
+  avg: function(v) { return v.length ? AGGREGATES.sum(v) / v.length : 0; },
+  min: function(v) { return v.length ? Math.min.apply(null, v.map(Number)) : undefined; },
+  max: function(v) { return v.length ? Math.max.apply(null, v.map(Number)) : undefined; },
+};
+
+function parseLiteral(s) {
+  s = s.trim();
+  if (/^'.*'$|^".*"$/.test(s)) return s.slice(1, -1);
+  if (s === 'true') return true;
+  if (s === 'false') return false;
+  if (s === 'null') return null;
+  if (s !== '' && !isNaN(Number(s))) return Number(s);
+  throw new Error('Unsupported value in filter: ' + s);
+}
+
+function compileFilter(expr) {
+  var m = expr.match(/^@((?:\.[A-Za-z_$][\w$-]*)*)\s*(==|!=|>=|<=|>|<)\s*(.+)$/);
+  if (!m) {
+    var exists = expr.match(/^@((?:\.[A-Za-z_$][\w$-]*)+)$/);
+    if (!exists) throw new Error('Unsupported filter: ' + expr);
+    var keys = exists[1].split('.').slice(1);
+    return function(item) { return !!keys.reduce(function(v, k) { return v == null ? undefined : v[k]; }, item); };
+  }
+  var path = m[1].split('.').slice(1), op = m[2], want = parseLiteral(m[3]);
+  return function(item) {
+    var v = path.reduce(function(acc, k) { return acc == null ? undefined : acc[k]; }, item);
+    switch (op) {
+      case '==': return v === want || (v != null && want != null && String(v) === String(want));
+      case '!=': return !(v === want || (v != null && want != null && String(v) === String(want)));
+      case '>': return v > want;
+      case '<': return v < want;
+      case '>=': return v >= want;
+      default: return v <= want;
+    }
+  };
+}
+
+export function evaluatePath(data, path) {
+  var src = String(path || '').trim();
+  if (src[0] !== '$') throw new Error('JSONPath must start with $');
+  var nodes = [data];
+  var multi = false;
+  var i = 1;
+  while (i < src.length) {
+    var rest = src.slice(i);
+    var m;
+    if ((m = rest.match(/^\.(count|sum|avg|min|max)\(\)$/))) {
+      return AGGREGATES[m[1]](multi ? nodes : (Array.isArray(nodes[0]) ? nodes[0] : nodes.filter(function(n) { return n !== undefined; })));
+    }
+    if (multi && (m = rest.match(/^\.length$/))) return nodes.length;
+    if ((m = rest.match(/^\[\?\((.+?)\)\]/))) {
+      var test = compileFilter(m[1].trim());
+      nodes = nodes.reduce(function(out, n) { return out.concat(Array.isArray(n) ? n.filter(test) : []); }, []);
+      multi = true;
+    } else if ((m = rest.match(/^\[\*\]|^\.\*/))) {
+      nodes = nodes.reduce(function(out, n) { return out.concat(Array.isArray(n) ? n : n && typeof n === 'object' ? Object.values(n) : []); }, []);
+      multi = true;
+    } else if ((m = rest.match(/^\[(-?\d+)\]/))) {
+      var idx = parseInt(m[1], 10);
+      nodes = nodes.map(function(n) { return Array.isArray(n) ? n[idx < 0 ? n.length + idx : idx] : undefined; });
+    } else if ((m = rest.match(/^\[(['"])(.*?)\1\]/))) {
+      var qk = m[2];
+      nodes = nodes.map(function(n) { return n == null ? undefined : n[qk]; });
+    } else if ((m = rest.match(/^\.([A-Za-z_$][\w$-]*)/))) {
+      var key = m[1];
+      nodes = nodes.map(function(n) { return n == null ? undefined : n[key]; });
+    } else {
+      throw new Error('Unexpected "' + rest.slice(0, 12) + '" in JSONPath');
+    }
+    i += m[0].length;
+  }
+  if (!multi) return nodes[0];
+  return nodes.filter(function(n) { return n !== undefined; });
+}
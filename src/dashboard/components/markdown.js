@@ -0,0 +1,139 @@
+/**
+ * Markdown — compose editor + safe renderer
+ *
+ * MarkdownEditor: textarea with a small formatting toolbar and Write/Preview tabs.
+ * MarkdownView:   renders markdown as HTML. Source is HTML-escaped before any
+ *                 markup is added, and links are limited to http(s)/mailto, so
+ *                 message bodies can never inject markup or scripts.
+ *
+ * Keep the syntax in sync with src/lib/markdown.ts (used for email delivery).
+ */
+import { h, useState, useRef } from './utils.js';
+
+var SAFE_URL_RE = /^(https?:|mailto:)/i;
+
+function escapeHtml(s) {
+  return String(s || '').replace(/&/g, '&amp;').replace(/</g, '&lt;').replace(/>/g, '&gt;').replace(/"/g, '&quot;').replace(/'/g, '&#039;');
+}
+
+function inline(text) {
+  return text
+    .replace(/`([^`]+)`/g, '<code>$1</code>')
+    .replace(/\*\*(.+?)\*\*/g, '<strong>$1</strong>')
+    .replace(/(^|[^*])\*([^*\s][^*]*?)\*/g, '$1<em>$2</em>')
+    .replace(/~~(.+?)~~/g, '<del>$1</del>')
+    .replace(/\[([^\]]+)\]\(([^)\s]+)\)/g, function(m, label, url) {
+      return SAFE_URL_RE.test(url) ? '<a href="' + url + '" target="_blank" rel="noopener noreferrer">' + label + '</a>' : m;
+    });
+}
+
+export function renderMarkdown(md) {
+  var lines = escapeHtml(md).replace(/\r\n?/g, '\n').split('\n');
+  var out = [], para = [], list = null, code = null;
+  var flushPara = function() { if (para.length) { out.push('<p>' + inline(para.join('<br>')) + '</p>'); para = []; } };
+  var flushList = function() {
+    if (!list) return;
+    out.push('<' + list.tag + '>' + list.items.map(function(i) { return '<li>' + inline(i) + '</li>'; }).join('') + '</' + list.tag + '>');
+    list = null;
+  };
+
+  lines.forEach(function(line) {
+    if (code) {
+      if (/^```/.test(line)) { out.push('<pre><code>' + code.join('\n') + '</code></pre>'); code = null; }
+      else code.push(line);
+      return;
+    }
+    if (/^```/.test(line)) { flushPara(); flushList(); code = []; return; }
+
+    var heading = line.match(/^(#{1,3})\s+(.+)$/);
+    var item = line.match(/^\s*[-*]\s+(.+)$/) || line.match(/^\s*\d+[.)]\s+(.+)$/);
+    var quote = line.match(/^&gt;\s?(.*)$/);
+
+    if (heading) { flushPara(); flushList(); out.push('<h' + heading[1].length + '>' + inline(heading[2]) + '</h' + heading[1].length + '>'); }
+    else if (item) {
+      flushPara();
+      var tag = /^\s*[-*]\s/.test(line) ? 'ul' : 'ol';
+      if (list && list.tag !== tag) flushList();
+      if (!list) list = { tag: tag, items: [] };
+      list.items.push(item[1]);
+    }
+    else if (quote) { flushPara(); flushList(); out.push('<blockquote>' + inline(quote[1]) + '</blockquote>'); }
+    else if (/^(-{3,}|\*{3,})$/.test(line.trim())) { flushPara(); flushList(); out.push('<hr>'); }
+    else if (!line.trim()) { flushPara(); flushList(); }
+    else { flushList(); para.push(line); }
+  });
+  if (code) out.push('<pre><code>' + code.join('\n') + '</code></pre>');
+  flushPara();
+  flushList();
+  return out.join('\n');
+}
+
+/** Rendered markdown body. Pass `markdown: false` to show plain text as-is. */
+export function MarkdownView(props) {
+  if (props.markdown === false) {
+    return h('div', { className: 'markdown-body', style: Object.assign({ whiteSpace: 'pre-wrap' }, props.style) }, props.source || '');
+  }
+  return h('div', { className: 'markdown-body', style: props.style, dangerouslySetInnerHTML: { __html: renderMarkdown(props.source) } });
+}
+
+var TOOLBAR = [
+  { label: 'B', title: 'Bold', wrap: ['**', '**'], style: { fontWeight: 700 } },
+  { label: 'I', title: 'Italic', wrap: ['*', '*'], style: { fontStyle: 'italic' } },
+  { label: '</>', title: 'Code', wrap: ['`', '`'] },
+  { label: 'Link', title: 'Link', wrap: ['[', '](https://)'] },
+  { label: '•', title: 'Bulleted list', prefix: '- ' },
+  { label: '1.', title: 'Numbered list', prefix: '1. ' },
+  { label: '❝', title: 'Quote', prefix: '> ' },
+];
+
+/**
+ * Props:
+ *   value: string          — markdown source
+ *   onChange: fn(string)
+ *   placeholder: string
+ *   minHeight: number      — textarea height (default 140)
+ */
+export function MarkdownEditor(props) {
+  var [tab, setTab] = useState('write');
+  var ref = useRef(null);
+  var value = props.value || '';
+
+  var apply = function(tool) {
+    var el = ref.current;
+    if (!el) return;
+    var start = el.selectionStart, end = el.selectionEnd;
+    var selected = value.slice(start, end);
+    var next, cursor;
+    if (tool.wrap) {
+      next = value.slice(0, start) + tool.wrap[0] + selected + tool.wrap[1] + value.slice(end);
+      cursor = start + tool.wrap[0].length + selected.length;
+    } else {
+      var lineStart = value.lastIndexOf('\n', start - 1) + 1;
+      next = value.slice(0, lineStart) + tool.prefix + value.slice(lineStart);
+      cursor = end + tool.prefix.length;
+    }
+    props.onChange(next);
+    setTimeout(function() { el.focus(); el.setSelectionRange(cursor, cursor); }, 0);
+  };
+
+  var tabBtn = function(id, label) {
+    return h('button', { type: 'button', className: 'tab' + (tab === id ? ' active' : ''), onClick: function() { setTab(id); } }, label);
+  };
+
+  return h('div', { className: 'markdown-editor' },
+    h('div', { style: { display: 'flex', alignItems: 'center', justifyContent: 'space-between', marginBottom: 6 } },
+      h('div', { className: 'tabs', style: { marginBottom: 0 } }, tabBtn('write', 'Write'), tabBtn('preview', 'Preview')),
+      tab === 'write' && h('div', { style: { display: 'flex', gap: 2 } },
+        TOOLBAR.map(function(t) {
+          return h('button', { key: t.title, type: 'button', className: 'btn btn-ghost btn-sm', title: t.title, style: Object.assign({ minWidth: 28, padding: '2px 6px' }, t.style), onClick: function() { apply(t); } }, t.label);
+        })
+      )
+    ),
+    tab === 'write'
+      ? h('textarea', { ref: ref, className: 'input', style: { minHeight: props.minHeight || 140, fontFamily: 'var(--font-mono, monospace)', fontSize: 13 }, placeholder: props.placeholder, value: value, onChange: function(e) { props.onChange(e.target.value); } })
+      : h('div', { className: 'input', style: { minHeight: props.minHeight || 140, overflowY: 'auto', height: 'auto' } },
+          value.trim() ? h(MarkdownView, { source: value }) : h('span', { style: { color: 'var(--text-muted)' } }, 'Nothing to preview')
+        ),
+    h('div', { style: { fontSize: 11, color: 'var(--text-muted)', marginTop: 4 } }, 'Markdown supported — **bold**, *italic*, `code`, [links](https://…), lists and > quotes. Email recipients also get a plain-text version.')
+  );
+}
@@ -0,0 +1,58 @@
+/**
+ * Mail-merge variables — {{contact.name}}, {{agent.name}}, ...
+ *
+ * VariableChips: clickable list of variables that calls onInsert('{{var}}').
+ * unknownVariables(texts, known): variables not in the known list, used to
+ * block sending before the server would reject it.
+ *
+ * Keep MERGE_VARIABLES in sync with src/lib/merge-vars.ts.
+ */
+import { h } from './utils.js';
+
+export var MERGE_VARIABLES = [
+  { key: 'contact.name', label: 'Recipient name' },
+  { key: 'contact.firstName', label: 'Recipient first name' },
+  { key: 'contact.email', label: 'Recipient email' },
+  { key: 'contact.role', label: 'Recipient role' },
+  { key: 'agent.name', label: 'Sender name' },
+  { key: 'agent.email', label: 'Sender email' },
+  { key: 'agent.role', label: 'Sender role' },
+  { key: 'org.name', label: 'Organization name' },
+  { key: 'date', label: 'Today\'s date' },
+];
+
+var VAR_RE = /\{\{\s*([\w.]+)\s*\}\}/g;
+
+export function unknownVariables(texts, known) {
+  var allowed = {};
+  (known || MERGE_VARIABLES.map(function(v) { return v.key; })).forEach(function(k) { allowed[k] = true; });
+  var found = {};
+  texts.forEach(function(t) {
+    String(t || '').replace(VAR_RE, function(_m, key) { if (!allowed[key]) found[key] = true; return _m; });
+  });
+  return Object.keys(found);
+}
+
+export function VariableChips(props) {
+  var vars = props.variables || MERGE_VARIABLES;
+  return h('div', { style: { display: 'flex', gap: 6, flexWrap: 'wrap', margin: '6px 0' } },
+    vars.map(function(v) {
+      var key = typeof v === 'string' ? v : v.key;
+      return h('button', {
+        key: key, type: 'button', className: 'badge', title: v.label || key,
+        style: { fontSize: 11, cursor: props.onInsert ? 'pointer' : 'default', border: 'none', fontFamily: 'var(--font-mono)' },
+        onClick: function() { if (props.onInsert) props.onInsert('{{' + key + '}}'); }
+      }, '{{' + key + '}}');
+    })
+  );
+}
+
+/** Inline warning listing unknown variables; renders nothing when all are known. */
+export function UnknownVariablesWarning(props) {
+  if (!props.unknown || props.unknown.length === 0) return null;
+  return h('div', { style: { fontSize: 12, color: 'var(--danger)', margin: '4px 0 8px' } },
+    'Unknown variable' + (props.unknown.length > 1 ? 's' : '') + ': ',
+    props.unknown.map(function(v) { return '{{' + v + '}}'; }).join(', '),
+    props.hint ? ' — ' + props.hint : ''
+  );
+}
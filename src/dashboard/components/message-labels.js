@@ -0,0 +1,150 @@
+import { h, useState, engineCall } from './utils.js';
+import { I } from './icons.js';
+
+// ─── Message Labels ──────────────────────────────────────
+// Chips, a per-message picker, and the label manager (name, colour, routing
+// rules). Labels are org-wide and stored by the engine (/message-labels);
+// saved filters are per browser (localStorage).
+
+export var LABEL_COLORS = ['#6366f1', '#3b82f6', '#10b981', '#f59e0b', '#ef4444', '#ec4899', '#8b5cf6', '#64748b'];
+
+var RULE_FIELDS = [
+  { id: 'subject', label: 'Subject' },
+  { id: 'content', label: 'Body' },
+  { id: 'fromAgentId', label: 'From agent' },
+  { id: 'toAgentId', label: 'To agent' },
+  { id: 'type', label: 'Type' },
+  { id: 'direction', label: 'Direction' },
+  { id: 'channel', label: 'Channel' },
+  { id: 'priority', label: 'Priority' },
+];
+
+export function LabelChip(props) {
+  var l = props.label;
+  return h('span', {
+    className: 'label-chip', title: l.name,
+    style: { background: l.color + '26', color: l.color, borderColor: l.color + '66' }
+  }, l.name, props.onRemove && h('button', { type: 'button', onClick: function(e) { e.stopPropagation(); props.onRemove(l); }, 'aria-label': 'Remove label ' + l.name }, '×'));
+}
+
+export function LabelChips(props) {
+  var ids = props.ids || [];
+  if (!ids.length) return null;
+  return h('span', { style: { display: 'inline-flex', gap: 4, flexWrap: 'wrap', marginLeft: 6, verticalAlign: 'middle' } },
+    ids.map(function(id) {
+      var l = props.labels.find(function(x) { return x.id === id; });
+      return l ? h(LabelChip, { key: id, label: l, onRemove: props.onRemove }) : null;
+    })
+  );
+}
+
+/** Toggle labels on one message. onChange receives the full new labelId list. */
+export function LabelPicker(props) {
+  var selected = props.value || [];
+  return h('div', { style: { display: 'flex', flexWrap: 'wrap', alignItems: 'center', gap: 6, fontSize: 13 } },
+    h('span', { style: { color: 'var(--text-muted)', marginRight: 4 } }, 'Labels:'),
+    props.labels.length === 0 && h('span', { style: { color: 'var(--text-muted)' } }, 'No labels yet'),
+    props.labels.map(function(l) {
+      var on = selected.indexOf(l.id) !== -1;
+      return h('button', {
+        key: l.id, type: 'button', className: 'label-chip' + (on ? '' : ' label-chip-off'),
+        style: on ? { background: l.color + '26', color: l.color, borderColor: l.color + '66' } : undefined,
+        onClick: function() { props.onChange(on ? selected.filter(function(x) { return x !== l.id; }) : selected.concat(l.id)); }
+      }, on ? '✓ ' : '+ ', l.name);
+    }),
+    props.onManage && h('button', { type: 'button', className: 'btn btn-ghost btn-sm', onClick: props.onManage }, 'Manage')
+  );
+}
+
+function emptyLabel() { return { name: '', color: LABEL_COLORS[0], rules: [], applyToExisting: true }; }
+
+/** Modal for creating/editing labels and their routing rules. */
+export function LabelManager(props) {
+  var [editing, setEditing] = useState(null); // label being edited (id null = new)
+  var [saving, setSaving] = useState(false);
+
+  var save = function() {
+    setSaving(true);
+    var body = JSON.stringify({ orgId: props.orgId, name: editing.name, color: editing.color, rules: editing.rules, applyToExisting: editing.applyToExisting });
+    var req = editing.id
+      ? engineCall('/message-labels/' + editing.id, { method: 'PATCH', body: body })
+      : engineCall('/message-labels', { method: 'POST', body: body });
+    req.then(function(d) {
+      props.toast((editing.id ? 'Label updated' : 'Label created') + (d.applied ? ' — applied to ' + d.applied + ' message' + (d.applied === 1 ? '' : 's') : ''), 'success');
+      setEditing(null); props.onChanged();
+    }).catch(function(e) { props.toast(e.message, 'error'); }).finally(function() { setSaving(false); });
+  };
+  var remove = function(l) {
+    if (!confirm('Delete label "' + l.name + '"? It will be removed from all messages.')) return;
+    engineCall('/message-labels/' + l.id + '?orgId=' + encodeURIComponent(props.orgId), { method: 'DELETE' })
+      .then(function() { props.toast('Label deleted', 'success'); props.onChanged(); })
+      .catch(function(e) { props.toast(e.message, 'error'); });
+  };
+  var setRule = function(i, patch) {
+    setEditing(Object.assign({}, editing, { rules: editing.rules.map(function(r, j) { return j === i ? Object.assign({}, r, patch) : r; }) }));
+  };
+
+  return h('div', { className: 'modal-overlay', onClick: props.onClose },
+    h('div', { className: 'modal', style: { maxWidth: 640 }, onClick: function(e) { e.stopPropagation(); } },
+      h('div', { className: 'modal-header' }, h('h2', null, 'Message Labels'), h('button', { className: 'btn btn-ghost btn-icon', onClick: props.onClose }, I.x())),
+      h('div', { className: 'modal-body' },
+        !editing && h('div', null,
+          props.labels.length === 0 && h('div', { style: { color: 'var(--text-muted)', fontSize: 13, padding: '12px 0' } }, 'No labels yet. Create one to tag messages by hand or automatically with rules.'),
+          props.labels.map(function(l) {
+            return h('div', { key: l.id, style: { display: 'flex', alignItems: 'center', gap: 8, padding: '8px 0', borderBottom: '1px solid var(--border)' } },
+              h(LabelChip, { label: l }),
+              h('span', { style: { flex: 1, fontSize: 12, color: 'var(--text-muted)' } }, l.rules.length ? l.rules.length + ' rule condition' + (l.rules.length === 1 ? '' : 's') : 'Manual only'),
+              h('button', { className: 'btn btn-ghost btn-sm', onClick: function() { setEditing(Object.assign({}, l, { applyToExisting: false })); } }, 'Edit'),
+              h('button', { className: 'btn btn-ghost btn-sm', style: { color: 'var(--danger)' }, onClick: function() { remove(l); } }, 'Delete')
+            );
+          }),
+          h('button', { className: 'btn btn-secondary btn-sm', style: { marginTop: 12 }, onClick: function() { setEditing(emptyLabel()); } }, I.plus(), ' New label')
+        ),
+        editing && h('div', null,
+          h('label', { className: 'field-label' }, 'Name'),
+          h('input', { className: 'input', maxLength: 64, value: editing.name, onChange: function(e) { setEditing(Object.assign({}, editing, { name: e.target.value })); } }),
+          h('label', { className: 'field-label' }, 'Colour'),
+          h('div', { style: { display: 'flex', gap: 6 } }, LABEL_COLORS.map(function(c) {
+            return h('button', { key: c, type: 'button', 'aria-label': c, onClick: function() { setEditing(Object.assign({}, editing, { color: c })); },
+              style: { width: 24, height: 24, borderRadius: '50%', background: c, border: editing.color === c ? '2px solid var(--text-primary)' : '2px solid transparent', cursor: 'pointer' } });
+          })),
+          h('label', { className: 'field-label' }, 'Routing rules'),
+          h('p', { className: 'form-help', style: { marginBottom: 8 } }, 'New messages matching every condition get this label automatically. Leave empty for a manual-only label.'),
+          editing.rules.map(function(r, i) {
+            return h('div', { key: i, style: { display: 'flex', gap: 6, marginBottom: 6 } },
+              h('select', { className: 'input', style: { width: 140 }, value: r.field, onChange: function(e) { setRule(i, { field: e.target.value }); } },
+                RULE_FIELDS.map(function(f) { return h('option', { key: f.id, value: f.id }, f.label); })),
+              h('select', { className: 'input', style: { width: 110 }, value: r.op, onChange: function(e) { setRule(i, { op: e.target.value }); } },
+                h('option', { value: 'contains' }, 'contains'), h('option', { value: 'equals' }, 'equals')),
+              h('input', { className: 'input', style: { flex: 1 }, value: r.value, onChange: function(e) { setRule(i, { value: e.target.value }); } }),
+              h('button', { type: 'button', className: 'btn btn-ghost btn-icon', onClick: function() { setEditing(Object.assign({}, editing, { rules: editing.rules.filter(function(_, j) { return j !== i; }) })); } }, I.x())
+            );
+          }),
+          h('button', { type: 'button', className: 'btn btn-ghost btn-sm', disabled: editing.rules.length >= 10, onClick: function() { setEditing(Object.assign({}, editing, { rules: editing.rules.concat({ field: 'subject', op: 'contains', value: '' }) })); } }, I.plus(), ' Add condition'),
+          editing.rules.length > 0 && h('label', { style: { display: 'flex', alignItems: 'center', gap: 6, fontSize: 13, marginTop: 12 } },
+            h('input', { type: 'checkbox', checked: !!editing.applyToExisting, onChange: function(e) { setEditing(Object.assign({}, editing, { applyToExisting: e.target.checked })); } }),
+            'Also apply to existing messages'
+          )
+        )
+      ),
+      h('div', { className: 'modal-footer' },
+        editing
+          ? [h('button', { key: 'b', className: 'btn btn-ghost', onClick: function() { setEditing(null); } }, 'Back'),
+             h('button', { key: 's', className: 'btn btn-primary', disabled: saving || !editing.name.trim() || editing.rules.some(function(r) { return !r.value.trim(); }), onClick: save }, saving ? 'Saving...' : 'Save')]
+          : h('button', { className: 'btn btn-ghost', onClick: props.onClose }, 'Close')
+      )
+    )
+  );
+}
+
+// ─── Saved filters (per browser) ────────────────────────
+
+var FILTERS_KEY = 'em_message_filters';
+
+export function loadSavedFilters() {
+  try { return JSON.parse(localStorage.getItem(FILTERS_KEY) || '[]'); } catch (e) { return []; }
+}
+
+export function storeSavedFilters(list) {
+  try { localStorage.setItem(FILTERS_KEY, JSON.stringify(list.slice(0, 20))); } catch (e) { /* storage full or disabled */ }
+}
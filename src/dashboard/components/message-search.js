@@ -0,0 +1,126 @@
+import { h, useState, useEffect, Fragment, engineCall, downloadCsv } from './utils.js';
+import { I } from './icons.js';
+import { Pagination, usePageSize } from './pagination.js';
+import { formatTime } from './time.js';
+
+// ─── Message Search ──────────────────────────────────────
+// Full-text search over stored message bodies, internal and external, for
+// the Messages page. Words must all appear; "quote" a phrase to match it
+// exactly. Sender, month and source facets narrow the results, and the
+// export button downloads every match, not just the page.
+//
+//   h(MessageSearchPanel, { orgId, agentData, toast })
+
+var SOURCE_LABELS = { internal: 'Internal', whatsapp: 'WhatsApp', telegram: 'Telegram' };
+
+/** Snippet text with the server's highlight ranges wrapped in <mark>. */
+function Highlighted(props) {
+  var parts = [], at = 0;
+  (props.highlights || []).forEach(function(r, i) {
+    if (r[0] > at) parts.push(props.text.slice(at, r[0]));
+    parts.push(h('mark', { key: i }, props.text.slice(r[0], r[1])));
+    at = r[1];
+  });
+  parts.push(props.text.slice(at));
+  return h(Fragment, null, parts);
+}
+
+function monthLabel(ym) {
+  var d = new Date(ym + '-01T00:00:00Z');
+  return isNaN(d.getTime()) ? ym : d.toLocaleDateString(undefined, { month: 'short', year: 'numeric', timeZone: 'UTC' });
+}
+
+export function MessageSearchPanel(props) {
+  var orgId = props.orgId;
+  var agentData = props.agentData || {};
+  var [input, setInput] = useState('');
+  var [query, setQuery] = useState({ q: '', sender: '', source: '', from: '', to: '' });
+  var [result, setResult] = useState(null);
+  var [loading, setLoading] = useState(false);
+  var [page, setPage] = useState(1);
+  var [pageSize, setPageSize] = usePageSize('message-search', 25);
+
+  var params = function() {
+    var sp = new URLSearchParams({ orgId: orgId });
+    Object.keys(query).forEach(function(k) { if (query[k]) sp.set(k, query[k]); });
+    return sp;
+  };
+
+  useEffect(function() {
+    if (!query.q) { setResult(null); return; }
+    setLoading(true);
+    var sp = params();
+    sp.set('limit', String(pageSize));
+    sp.set('offset', String((page - 1) * pageSize));
+    engineCall('/message-search?' + sp).then(setResult)
+      .catch(function(err) { props.toast && props.toast('Search failed: ' + err.message, 'error'); })
+      .finally(function() { setLoading(false); });
+  }, [orgId, query, page, pageSize]);
+
+  var update = function(patch) { setQuery(function(q) { return Object.assign({}, q, patch); }); setPage(1); };
+  var nameOf = function(id) { return agentData[id] && agentData[id].name || id; };
+  var facet = function(title, items, key, label) {
+    if (!items || items.length === 0) return null;
+    return h('div', { style: { marginBottom: 16 } },
+      h('div', { style: { fontSize: 11, fontWeight: 600, textTransform: 'uppercase', color: 'var(--text-muted)', marginBottom: 6 } }, title),
+      items.map(function(f) {
+        var active = key && query[key] === f.value;
+        return h('button', {
+          key: f.value, className: 'btn btn-ghost btn-sm', disabled: !key,
+          style: { display: 'flex', width: '100%', justifyContent: 'space-between', fontWeight: active ? 600 : 400, background: active ? 'var(--accent-soft)' : undefined, cursor: key ? 'pointer' : 'default' },
+          onClick: key ? function() { update({ [key]: active ? '' : f.value }); } : undefined,
+        }, h('span', { style: { overflow: 'hidden', textOverflow: 'ellipsis', whiteSpace: 'nowrap' } }, label(f.value)), h('span', { style: { color: 'var(--text-muted)' } }, f.count));
+      })
+    );
+  };
+
+  return h('div', null,
+    h('form', {
+      style: { display: 'flex', gap: 8, flexWrap: 'wrap', alignItems: 'center', marginBottom: 16 },
+      onSubmit: function(e) { e.preventDefault(); update({ q: input.trim(), sender: '' }); }
+    },
+      h('input', { className: 'input', style: { flex: 1, minWidth: 240 }, placeholder: 'Search message text — all words must match, "quote" an exact phrase', value: input, onChange: function(e) { setInput(e.target.value); }, autoFocus: true }),
+      h('input', { className: 'input', type: 'date', style: { width: 'auto' }, title: 'From', value: query.from, onChange: function(e) { update({ from: e.target.value }); } }),
+      h('input', { className: 'input', type: 'date', style: { width: 'auto' }, title: 'To', value: query.to, onChange: function(e) { update({ to: e.target.value }); } }),
+      h('button', { className: 'btn btn-primary', type: 'submit', disabled: !input.trim() }, I.search(), ' Search'),
+      result && result.total > 0 && h('button', {
+        className: 'btn btn-secondary', type: 'button', title: 'Download every match as CSV',
+        onClick: function() { downloadCsv('/engine/message-search?' + params()).catch(function(err) { props.toast && props.toast(err.message, 'error'); }); }
+      }, I.download(), ' Export')
+    ),
+
+    result && !result.supported && h('div', { className: 'card', style: { padding: 16, color: 'var(--text-muted)' } }, 'Full-text search needs a SQL database (SQLite, Postgres or MySQL). This deployment\'s backend does not support it.'),
+
+    result && result.supported && h('div', { style: { display: 'grid', gridTemplateColumns: '220px 1fr', gap: 16, alignItems: 'start' } },
+      h('div', null,
+        facet('Sender', result.facets.senders, 'sender', nameOf),
+        facet('Source', result.facets.sources, 'source', function(v) { return SOURCE_LABELS[v] || v; }),
+        facet('Month', result.facets.months, null, monthLabel)
+      ),
+      h('div', null,
+        h('div', { style: { fontSize: 13, color: 'var(--text-muted)', marginBottom: 8 } },
+          loading ? 'Searching...' : result.total.toLocaleString() + ' match' + (result.total === 1 ? '' : 'es'),
+          result.truncated && ' — showing the newest; narrow the search or dates to see older ones'
+        ),
+        result.hits.length === 0 && !loading
+          ? h('div', { className: 'card', style: { padding: 24, textAlign: 'center', color: 'var(--text-muted)' } }, 'No messages match')
+          : h('div', { className: 'card', style: { opacity: loading ? 0.5 : 1 } }, result.hits.map(function(hit) {
+              var internal = hit.source === 'internal';
+              return h('div', {
+                key: hit.id, className: 'search-hit',
+                style: { padding: '10px 14px', borderBottom: '1px solid var(--border)', cursor: internal ? 'pointer' : 'default' },
+                onClick: internal ? function() { window.dispatchEvent(new CustomEvent('em:open-message', { detail: hit.id })); } : undefined,
+              },
+                h('div', { style: { display: 'flex', gap: 8, alignItems: 'center', fontSize: 12, color: 'var(--text-muted)', marginBottom: 4 } },
+                  h('span', { className: 'badge badge-neutral' }, SOURCE_LABELS[hit.source] || hit.source),
+                  h('strong', { style: { color: 'var(--text-primary)' } }, nameOf(hit.sender)), ' → ', nameOf(hit.recipient),
+                  h('span', { style: { marginLeft: 'auto' }, title: formatTime(hit.createdAt, 'full') }, formatTime(hit.createdAt))
+                ),
+                h('div', { style: { fontSize: 13, lineHeight: 1.5 } }, h(Highlighted, { text: hit.snippet, highlights: hit.highlights }))
+              );
+            })),
+        h(Pagination, { page: page, pageSize: pageSize, total: result.total, onPage: setPage, onPageSize: function(n) { setPageSize(n); setPage(1); } })
+      )
+    )
+  );
+}
@@ -1,13 +1,64 @@
-import { h, Fragment } from './utils.js';
+import { h, Fragment, useState, useEffect, useRef } from './utils.js';
 import { I } from './icons.js';
 
+// ─── Dialog Focus ────────────────────────────────────────
+// While a dialog is open, focus moves into it, Tab/Shift+Tab cycle inside it
+// and Escape closes it. On close, focus goes back to whatever opened it (the
+// table row, the button) so keyboard users don't restart from the top.
+
+var FOCUSABLE = 'a[href], button:not([disabled]), input:not([disabled]):not([type="hidden"]), select:not([disabled]), textarea:not([disabled]), [tabindex]:not([tabindex="-1"])';
+
+/** useDialogFocus(ref, onClose, active = true) — ref is the dialog element */
+export function useDialogFocus(ref, onClose, active) {
+  var closeRef = useRef(onClose);
+  closeRef.current = onClose;
+  var open = active !== false;
+  // Who had focus before the dialog (or an autoFocus field inside it) took it
+  var openerRef = useRef(null);
+  if (open && !openerRef.current) openerRef.current = document.activeElement;
+
+  useEffect(function() {
+    if (!open) return;
+    var el = ref.current;
+    if (!el) return;
+    // autoFocus children have already taken focus; otherwise focus the dialog so its title is read out
+    if (!el.contains(document.activeElement)) el.focus();
+
+    var onKey = function(e) {
+      if (e.key === 'Escape') {
+        e.stopPropagation();
+        if (closeRef.current) closeRef.current();
+        return;
+      }
+      if (e.key !== 'Tab') return;
+      var items = Array.prototype.filter.call(el.querySelectorAll(FOCUSABLE), function(n) { return n.offsetParent !== null; });
+      if (!items.length) { e.preventDefault(); return; }
+      var first = items[0], last = items[items.length - 1];
+      if (e.shiftKey && (document.activeElement === first || document.activeElement === el)) { e.preventDefault(); last.focus(); }
+      else if (!e.shiftKey && document.activeElement === last) { e.preventDefault(); first.focus(); }
+    };
+    el.addEventListener('keydown', onKey);
+    return function() {
+      el.removeEventListener('keydown', onKey);
+      var opener = openerRef.current;
+      openerRef.current = null;
+      if (opener && opener.focus && document.body.contains(opener)) opener.focus();
+    };
+  }, [open]);
+}
+
+var _titleSeq = 0;
+
 export function Modal({ title, onClose, children, footer, large, width }) {
   var extraStyle = width ? { width: typeof width === 'number' ? width + 'px' : width, maxWidth: '95vw' } : undefined;
+  var ref = useRef(null);
+  var [titleId] = useState(function() { return 'modal-title-' + (++_titleSeq); });
+  useDialogFocus(ref, onClose);
   return h('div', { className: 'modal-overlay', onClick: e => { if (e.target === e.currentTarget) onClose(); } },
-    h('div', { className: 'modal' + (large ? ' modal-lg' : ''), style: extraStyle },
+    h('div', { ref: ref, className: 'modal' + (large ? ' modal-lg' : ''), style: extraStyle, role: 'dialog', 'aria-modal': true, 'aria-labelledby': titleId, tabIndex: -1 },
       h('div', { className: 'modal-header' },
-        h('h2', null, title),
-        h('button', { className: 'btn btn-ghost btn-icon', onClick: onClose }, I.x())
+        h('h2', { id: titleId }, title),
+        h('button', { className: 'btn btn-ghost btn-icon', 'aria-label': 'Close', onClick: onClose }, I.x())
       ),
       h('div', { className: 'modal-body' }, children),
       footer && h('div', { className: 'modal-footer' }, footer)
@@ -15,6 +66,26 @@ export function Modal({ title, onClose, children, footer, large, width }) {
   );
 }
 
+// ─── Drawer ─────────────────────────────────────────────
+// A panel that slides in from the right, for detail views that sit beside
+// the list they came from. Same props and keyboard handling as Modal.
+
+export function Drawer({ title, onClose, children, footer, width }) {
+  var ref = useRef(null);
+  var [titleId] = useState(function() { return 'drawer-title-' + (++_titleSeq); });
+  useDialogFocus(ref, onClose);
+  return h('div', { className: 'modal-overlay drawer-overlay', onClick: e => { if (e.target === e.currentTarget) onClose(); } },
+    h('div', { ref: ref, className: 'drawer', style: width ? { width: typeof width === 'number' ? width + 'px' : width } : undefined, role: 'dialog', 'aria-modal': true, 'aria-labelledby': titleId, tabIndex: -1 },
+      h('div', { className: 'modal-header' },
+        h('h2', { id: titleId }, title),
+        h('button', { className: 'btn btn-ghost btn-icon', 'aria-label': 'Close', onClick: onClose }, I.x())
+      ),
+      h('div', { className: 'modal-body drawer-body' }, children),
+      footer && h('div', { className: 'modal-footer' }, footer)
+    )
+  );
+}
+
 // ─── Reusable Detail Modal ──────────────────────────────
 //
 // Renders any data object as a formatted detail view inside a Modal.
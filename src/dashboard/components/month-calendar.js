@@ -0,0 +1,84 @@
+import { h } from './utils.js';
+import { I } from './icons.js';
+
+// ─── Month Calendar ──────────────────────────────────────
+// Six-week month grid that draws timed events on every day they overlap.
+// Events need { id, title, startsAt, endsAt? }; the page decides colours.
+//
+//   var [month, setMonth] = useState(startOfMonth(new Date()));
+//   var range = calendarRange(month);   // fetch events for range.start..range.end
+//   h(MonthCalendar, { month, onMonthChange: setMonth, events, onSelect,
+//     colorFor: (e) => 'var(--info)', legend: [{ label: 'Org-wide', color: 'var(--warning)' }] })
+
+export function startOfMonth(d) {
+  return new Date(d.getFullYear(), d.getMonth(), 1);
+}
+
+/** First and last day shown in the grid (whole weeks, Sunday first) */
+export function calendarRange(month) {
+  var first = startOfMonth(month);
+  var start = new Date(first); start.setDate(1 - first.getDay());
+  var end = new Date(start); end.setDate(start.getDate() + 42);
+  return { start: start, end: end };
+}
+
+/** 'YYYY-MM-DDTHH:mm' in local time, for <input type="datetime-local"> */
+export function toLocalInput(d) {
+  var pad = function(n) { return String(n).padStart(2, '0'); };
+  return d.getFullYear() + '-' + pad(d.getMonth() + 1) + '-' + pad(d.getDate()) + 'T' + pad(d.getHours()) + ':' + pad(d.getMinutes());
+}
+
+export function MonthCalendar(props) {
+  var month = props.month;
+  var range = calendarRange(month);
+  var today = new Date().toDateString();
+  var colorFor = props.colorFor || function() { return 'var(--accent)'; };
+  var days = [];
+  for (var d = new Date(range.start); d < range.end; d.setDate(d.getDate() + 1)) days.push(new Date(d));
+
+  var eventsOn = function(day) {
+    var dayStart = day.getTime(), dayEnd = dayStart + 86400000;
+    return (props.events || []).filter(function(e) {
+      var start = new Date(e.startsAt).getTime();
+      var end = e.endsAt ? new Date(e.endsAt).getTime() : start + 1;
+      return start < dayEnd && end > dayStart;
+    });
+  };
+  var shift = function(n) { props.onMonthChange(new Date(month.getFullYear(), month.getMonth() + n, 1)); };
+
+  return h('div', { className: 'card', style: { padding: 16 } },
+    h('div', { style: { display: 'flex', alignItems: 'center', gap: 8, marginBottom: 12 } },
+      h('button', { className: 'btn btn-ghost btn-sm', 'aria-label': 'Previous month', onClick: function() { shift(-1); } }, I.chevronLeft()),
+      h('strong', { style: { minWidth: 140, textAlign: 'center' } }, month.toLocaleString(undefined, { month: 'long', year: 'numeric' })),
+      h('button', { className: 'btn btn-ghost btn-sm', 'aria-label': 'Next month', onClick: function() { shift(1); } }, I.chevronRight()),
+      h('button', { className: 'btn btn-ghost btn-sm', onClick: function() { props.onMonthChange(startOfMonth(new Date())); } }, 'Today')
+    ),
+    h('div', { style: { display: 'grid', gridTemplateColumns: 'repeat(7, 1fr)', gap: 1, background: 'var(--border)', border: '1px solid var(--border)', borderRadius: 6, overflow: 'hidden' } },
+      ['Sun', 'Mon', 'Tue', 'Wed', 'Thu', 'Fri', 'Sat'].map(function(n) {
+        return h('div', { key: n, style: { background: 'var(--bg-secondary)', padding: '4px 6px', fontSize: 11, fontWeight: 600, color: 'var(--text-muted)' } }, n);
+      }),
+      days.map(function(day) {
+        var inMonth = day.getMonth() === month.getMonth();
+        var isToday = day.toDateString() === today;
+        var list = eventsOn(day);
+        return h('div', { key: day.toISOString(), style: { background: 'var(--bg-primary)', minHeight: 72, padding: 4, opacity: inMonth ? 1 : 0.45 } },
+          h('div', { style: { fontSize: 11, fontWeight: isToday ? 700 : 400, color: isToday ? 'var(--accent)' : 'var(--text-muted)', marginBottom: 2 } }, day.getDate()),
+          list.slice(0, 3).map(function(e) {
+            return h('div', {
+              key: e.id,
+              title: e.title + ' — ' + new Date(e.startsAt).toLocaleString() + (e.endsAt ? ' to ' + new Date(e.endsAt).toLocaleString() : ''),
+              onClick: props.onSelect ? function() { props.onSelect(e); } : undefined,
+              style: { fontSize: 11, padding: '1px 4px', marginBottom: 2, borderRadius: 3, cursor: props.onSelect ? 'pointer' : 'default', whiteSpace: 'nowrap', overflow: 'hidden', textOverflow: 'ellipsis', color: '#fff', background: colorFor(e) }
+            }, e.title);
+          }),
+          list.length > 3 && h('div', { style: { fontSize: 10, color: 'var(--text-muted)' } }, '+' + (list.length - 3) + ' more')
+        );
+      })
+    ),
+    props.legend && h('div', { style: { display: 'flex', gap: 16, marginTop: 8, fontSize: 12, color: 'var(--text-muted)', flexWrap: 'wrap' } },
+      props.legend.map(function(l) {
+        return h('span', { key: l.label }, h('span', { style: { display: 'inline-block', width: 10, height: 10, borderRadius: 2, background: l.color, marginRight: 4 } }), l.label);
+      })
+    )
+  );
+}
@@ -0,0 +1,105 @@
+import { h, useState, useEffect, useRef, apiCall, engineCall } from './utils.js';
+import { I } from './icons.js';
+
+// ─── Notifications Center ────────────────────────────────
+// Topbar bell listing items that need the user's attention. Fed by due
+// message follow-ups (/engine/follow-ups?due=true) and, for admins, pages over
+// their render budget (/admin/performance/alerts?open=true). Pages that change
+// either call notifyFollowUpsChanged() / notifyPerformanceAlertsChanged() so
+// the badge updates immediately.
+
+var POLL_INTERVAL_MS = 60000;
+var CHANGE_EVENT = 'em:followups-changed';
+
+export function notifyFollowUpsChanged() {
+  window.dispatchEvent(new Event(CHANGE_EVENT));
+}
+export var notifyPerformanceAlertsChanged = notifyFollowUpsChanged;
+
+/** Common "remind me" presets, resolved relative to now. */
+export var REMIND_PRESETS = [
+  { id: '1h', label: 'In 1 hour', at: function() { return new Date(Date.now() + 3600000); } },
+  { id: '4h', label: 'In 4 hours', at: function() { return new Date(Date.now() + 4 * 3600000); } },
+  { id: 'tomorrow', label: 'Tomorrow 9:00', at: function() { var d = new Date(); d.setDate(d.getDate() + 1); d.setHours(9, 0, 0, 0); return d; } },
+  { id: 'week', label: 'Next week', at: function() { var d = new Date(); d.setDate(d.getDate() + 7); d.setHours(9, 0, 0, 0); return d; } },
+];
+
+export function NotificationBell(props) {
+  var [items, setItems] = useState([]);
+  var [alerts, setAlerts] = useState([]);
+  var [open, setOpen] = useState(false);
+  var ref = useRef(null);
+
+  var load = function() {
+    engineCall('/follow-ups?due=true')
+      .then(function(d) { setItems(d.followUps || []); })
+      .catch(function() { /* follow-ups unavailable — keep last state */ });
+    if (props.showPerformance) {
+      apiCall('/admin/performance/alerts?open=true')
+        .then(function(d) { setAlerts(d.alerts || []); })
+        .catch(function() { /* keep last state */ });
+    }
+  };
+
+  useEffect(function() {
+    load();
+    var t = setInterval(load, POLL_INTERVAL_MS);
+    window.addEventListener(CHANGE_EVENT, load);
+    return function() { clearInterval(t); window.removeEventListener(CHANGE_EVENT, load); };
+  }, [props.showPerformance]);
+
+  useEffect(function() {
+    if (!open) return;
+    var onDoc = function(e) { if (ref.current && !ref.current.contains(e.target)) setOpen(false); };
+    document.addEventListener('mousedown', onDoc);
+    return function() { document.removeEventListener('mousedown', onDoc); };
+  }, [open]);
+
+  var done = function(f) {
+    engineCall('/follow-ups/' + f.id + '/done', { method: 'POST' }).then(notifyFollowUpsChanged).catch(function() {});
+  };
+  var acknowledge = function(a) {
+    apiCall('/admin/performance/alerts/' + a.id + '/acknowledge', { method: 'POST' }).then(notifyPerformanceAlertsChanged).catch(function() {});
+  };
+  var later = function(f) {
+    engineCall('/follow-ups/' + f.id, { method: 'PATCH', body: JSON.stringify({ remindAt: REMIND_PRESETS[0].at().toISOString() }) }).then(notifyFollowUpsChanged).catch(function() {});
+  };
+
+  var count = items.length + alerts.length;
+
+  return h('div', { ref: ref, style: { position: 'relative' } },
+    h('button', { className: 'btn btn-ghost btn-icon', title: 'Notifications', onClick: function() { setOpen(!open); }, style: { width: 36, height: 36, position: 'relative' } },
+      I.bell({ size: 22 }),
+      count > 0 && h('span', { className: 'notification-badge' }, count > 99 ? '99+' : count)
+    ),
+    open && h('div', { className: 'notification-panel' },
+      h('div', { className: 'notification-panel-header' }, 'Notifications'),
+      count === 0
+        ? h('div', { style: { padding: 24, textAlign: 'center', color: 'var(--text-muted)', fontSize: 13 } }, 'You\'re all caught up')
+        : alerts.map(function(a) {
+            return h('div', { key: 'perf-' + a.id, className: 'notification-item' },
+              h('div', { style: { flex: 1, minWidth: 0, cursor: 'pointer' }, onClick: function() { setOpen(false); if (props.onOpenPerformance) props.onOpenPerformance(); } },
+                h('div', { style: { fontSize: 11, color: 'var(--danger)', textTransform: 'uppercase', letterSpacing: 0.4 } }, 'Page over render budget'),
+                h('div', { style: { fontSize: 13, fontWeight: 600 } }, a.page),
+                h('div', { style: { fontSize: 12, color: 'var(--text-secondary)' } }, 'p95 ' + a.p95Ms + ' ms, budget ' + a.budgetMs + ' ms'),
+                h('div', { style: { fontSize: 11, color: 'var(--text-muted)' } }, 'Since ' + new Date(a.breachingSince).toLocaleString())
+              ),
+              h('button', { className: 'btn btn-ghost btn-sm', title: 'Acknowledge', 'aria-label': 'Acknowledge', onClick: function() { acknowledge(a); } }, I.check())
+            );
+          }).concat(items.map(function(f) {
+            return h('div', { key: f.id, className: 'notification-item' },
+              h('div', { style: { flex: 1, minWidth: 0, cursor: 'pointer' }, onClick: function() { setOpen(false); if (props.onOpenMessage) props.onOpenMessage(f.messageId); } },
+                h('div', { style: { fontSize: 11, color: 'var(--text-muted)', textTransform: 'uppercase', letterSpacing: 0.4 } }, f.kind === 'snooze' ? 'Snoozed message is back' : 'Follow-up due'),
+                h('div', { style: { fontSize: 13, fontWeight: 600, overflow: 'hidden', textOverflow: 'ellipsis', whiteSpace: 'nowrap' } }, f.message ? f.message.subject || '(no subject)' : 'Message no longer available'),
+                f.note && h('div', { style: { fontSize: 12, color: 'var(--text-secondary)' } }, f.note),
+                h('div', { style: { fontSize: 11, color: 'var(--text-muted)' } }, 'Due ' + new Date(f.remindAt).toLocaleString())
+              ),
+              h('div', { style: { display: 'flex', flexDirection: 'column', gap: 4 } },
+                h('button', { className: 'btn btn-ghost btn-sm', title: 'Mark done', onClick: function() { done(f); } }, I.check()),
+                h('button', { className: 'btn btn-ghost btn-sm', title: 'Remind me in 1 hour', onClick: function() { later(f); } }, I.clock())
+              )
+            );
+          }))
+    )
+  );
+}
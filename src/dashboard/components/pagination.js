@@ -0,0 +1,84 @@
+import { h, useState } from './utils.js';
+
+// ─── Pagination ──────────────────────────────────────────
+// Footer for paged tables: "Showing 51–100 of 340", prev/next, numbered
+// pages and a page-size select. Pages are 1-based. When the endpoint can't
+// count (total unknown) pass `hasMore` instead and the numbers are skipped.
+//
+//   var [pageSize, setPageSize] = usePageSize('audit', 50);
+//   var audit = useFragment('/audit/rows', params, { pageSize: pageSize });
+//   h(Pagination, { page: audit.page, pageSize, total: audit.total, hasMore: audit.hasMore,
+//     onPage: audit.setPage, onPageSize: setPageSize })
+
+export var PAGE_SIZES = [25, 50, 100];
+
+/** Page size remembered per table in localStorage. */
+export function usePageSize(key, fallback) {
+  var storageKey = 'em_page_size_' + key;
+  var [size, setSize] = useState(function() {
+    try {
+      var saved = parseInt(localStorage.getItem(storageKey) || '', 10);
+      return PAGE_SIZES.indexOf(saved) >= 0 ? saved : fallback;
+    } catch (e) { return fallback; }
+  });
+  var set = function(n) {
+    setSize(n);
+    try { localStorage.setItem(storageKey, String(n)); } catch (e) { /* storage unavailable */ }
+  };
+  return [size, set];
+}
+
+/** Page numbers to show around `page`, with null marking a gap: 1 … 4 5 6 … 20 */
+export function pageWindow(page, pages) {
+  if (pages <= 7) return Array.from({ length: pages }, function(_, i) { return i + 1; });
+  var out = [1];
+  var start = Math.max(2, page - 1), end = Math.min(pages - 1, page + 1);
+  if (page <= 3) end = 4;
+  if (page >= pages - 2) start = pages - 3;
+  if (start > 2) out.push(null);
+  for (var i = start; i <= end; i++) out.push(i);
+  if (end < pages - 1) out.push(null);
+  out.push(pages);
+  return out;
+}
+
+export function Pagination(props) {
+  var page = props.page;
+  var pageSize = props.pageSize;
+  var hasTotal = typeof props.total === 'number' && props.total > 0;
+  var pages = hasTotal ? Math.max(1, Math.ceil(props.total / pageSize)) : null;
+  var hasNext = pages ? page < pages : !!props.hasMore;
+  var sizes = props.pageSizes || PAGE_SIZES;
+
+  // Nothing to page through and nothing smaller to switch to
+  if (page <= 1 && !hasNext && (!hasTotal || props.total <= sizes[0])) return null;
+
+  var first = (page - 1) * pageSize + 1;
+  var last = hasTotal ? Math.min(page * pageSize, props.total) : (page - 1) * pageSize + (props.count != null ? props.count : pageSize);
+  var btn = { className: 'btn btn-secondary btn-sm' };
+
+  return h('div', { className: 'pagination', style: props.style },
+    h('span', { className: 'pagination-summary' },
+      'Showing ' + first + '–' + last + (hasTotal ? ' of ' + props.total : '') + (props.noun ? ' ' + props.noun : '')
+    ),
+    h('div', { className: 'pagination-controls' },
+      h('button', Object.assign({}, btn, { disabled: page <= 1, onClick: function() { props.onPage(page - 1); }, 'aria-label': 'Previous page' }), '← Previous'),
+      pages
+        ? pageWindow(page, pages).map(function(n, i) {
+            return n === null
+              ? h('span', { key: 'gap' + i, className: 'pagination-gap' }, '…')
+              : h('button', {
+                  key: n, className: 'btn btn-sm ' + (n === page ? 'btn-primary' : 'btn-ghost'),
+                  'aria-current': n === page ? 'page' : undefined, 'aria-label': 'Page ' + n,
+                  onClick: function() { if (n !== page) props.onPage(n); }
+                }, n);
+          })
+        : h('span', { className: 'pagination-gap' }, 'Page ' + page),
+      h('button', Object.assign({}, btn, { disabled: !hasNext, onClick: function() { props.onPage(page + 1); }, 'aria-label': 'Next page' }), 'Next →'),
+      props.onPageSize && h('select', {
+        className: 'input', style: { width: 'auto', marginLeft: 8 }, 'aria-label': 'Rows per page', value: pageSize,
+        onChange: function(e) { props.onPageSize(parseInt(e.target.value, 10)); }
+      }, sizes.map(function(n) { return h('option', { key: n, value: n }, n + ' / page'); }))
+    )
+  );
+}
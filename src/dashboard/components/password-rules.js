@@ -0,0 +1,66 @@
+import { h, useState, useEffect, authCall } from './utils.js';
+
+// ─── Password rules ──────────────────────────────────────
+// The org's password policy (Settings → Authentication), shown as a
+// checklist under any "new password" field. The server enforces the same
+// rules; this just tells people what is missing before they submit.
+//
+//   var policy = usePasswordPolicy();
+//   h(PasswordRules, { policy: policy, password: pw })
+//   disabled: !passwordOk(policy, pw)
+
+var DEFAULT_POLICY = { minLength: 8, requireUppercase: false, requireLowercase: false, requireNumber: false, requireSymbol: false };
+
+var _cached = null;
+
+export function usePasswordPolicy() {
+  var [policy, setPolicy] = useState(_cached || DEFAULT_POLICY);
+  useEffect(function() {
+    authCall('/password-policy').then(function(d) {
+      if (d && d.policy) { _cached = d.policy; setPolicy(d.policy); }
+    }).catch(function() {});
+  }, []);
+  return policy;
+}
+
+/** Each rule with whether the password meets it */
+export function passwordChecks(policy, password) {
+  var pw = password || '';
+  var checks = [{ label: 'At least ' + policy.minLength + ' characters', ok: pw.length >= policy.minLength }];
+  if (policy.requireUppercase) checks.push({ label: 'An uppercase letter', ok: /[A-Z]/.test(pw) });
+  if (policy.requireLowercase) checks.push({ label: 'A lowercase letter', ok: /[a-z]/.test(pw) });
+  if (policy.requireNumber) checks.push({ label: 'A number', ok: /[0-9]/.test(pw) });
+  if (policy.requireSymbol) checks.push({ label: 'A symbol', ok: /[^A-Za-z0-9]/.test(pw) });
+  return checks;
+}
+
+export function passwordOk(policy, password) {
+  return passwordChecks(policy, password).every(function(c) { return c.ok; });
+}
+
+/** First unmet rule as an error message, or '' */
+export function passwordError(policy, password) {
+  var miss = passwordChecks(policy, password).filter(function(c) { return !c.ok; });
+  return miss.length ? 'Password needs: ' + miss.map(function(c) { return c.label.toLowerCase(); }).join(', ') : '';
+}
+
+/** A random password that meets the policy, for admins setting a temporary one */
+export function generatePassword(policy) {
+  var chars = 'ABCDEFGHJKLMNPQRSTUVWXYZabcdefghjkmnpqrstuvwxyz23456789!@#$%';
+  var pw;
+  do {
+    var bytes = new Uint8Array(Math.max(16, policy.minLength));
+    crypto.getRandomValues(bytes);
+    pw = Array.from(bytes).map(function(b) { return chars[b % chars.length]; }).join('');
+  } while (!passwordOk(policy, pw));
+  return pw;
+}
+
+export function PasswordRules(props) {
+  var checks = passwordChecks(props.policy, props.password);
+  return h('ul', { style: { listStyle: 'none', margin: '6px 0 0', padding: 0, fontSize: 12, display: 'flex', flexWrap: 'wrap', gap: '2px 12px' } },
+    checks.map(function(c) {
+      return h('li', { key: c.label, style: { color: c.ok ? 'var(--success)' : 'var(--text-muted)' } }, (c.ok ? '✓ ' : '• ') + c.label);
+    })
+  );
+}
@@ -5,7 +5,7 @@
  * used by both the Create-Agent wizard and the Agent-Detail "Personal" tab.
  */
 
-import { h, useState, useRef, Fragment } from './utils.js';
+import { h, useState, useRef, Fragment, engineCall } from './utils.js';
 
 // ─── Constants ───────────────────────────────────────────
 
@@ -333,6 +333,20 @@ export function computeAge(dateOfBirth) {
   return age;
 }
 
+// ─── Avatar Save ────────────────────────────────────────
+//
+// The image is stored by the backend (/avatars), which also sets
+// identity.avatar, so callers leave the avatar out of config updates.
+// `next` is a data URL from an upload, an image URL to fetch, or null.
+// Resolves to the avatar URL now in the config.
+
+export function saveAvatar(agentId, next, prev) {
+  if ((next || null) === (prev || null)) return Promise.resolve(prev || null);
+  if (!next) return engineCall('/avatars/' + agentId, { method: 'DELETE' }).then(function() { return null; });
+  var body = next.indexOf('data:') === 0 ? { dataUrl: next } : { url: next };
+  return engineCall('/avatars/' + agentId, { method: 'PUT', body: JSON.stringify(body) }).then(function(d) { return d.avatar; });
+}
+
 // ─── PersonaForm Component ──────────────────────────────
 //
 // Renders the full persona editing UI — avatar upload, DOB,
@@ -351,6 +365,15 @@ export function PersonaForm(props) {
   var set = props.set;
   var toast = props.toast;
   var fileInputRef = useRef(null);
+  var _urlInput = useState('');
+  var urlInput = _urlInput[0]; var setUrlInput = _urlInput[1];
+
+  var applyAvatarUrl = function() {
+    var url = urlInput.trim();
+    if (!/^https?:\/\/\S+$/i.test(url)) { toast('Enter an http(s) image URL', 'error'); return; }
+    set('avatar', url);
+    setUrlInput('');
+  };
 
   var onAvatarFile = function(file) {
     handleAvatarFile(file, function(dataUrl) { set('avatar', dataUrl); }, toast);
@@ -383,7 +406,11 @@ export function PersonaForm(props) {
           h('input', { ref: fileInputRef, type: 'file', accept: 'image/jpeg,image/png,image/webp', style: { display: 'none' }, onChange: function(e) { onAvatarFile(e.target.files[0]); } })
         ),
         form.avatar && h('button', { className: 'btn btn-ghost btn-sm', style: { marginTop: 6, fontSize: 11 }, onClick: function() { set('avatar', null); } }, 'Remove'),
-        h('p', { className: 'form-help', style: { marginTop: 4 } }, 'JPG, PNG, WebP. Large images auto-resized.')
+        h('p', { className: 'form-help', style: { marginTop: 4 } }, 'JPG, PNG, WebP. Large images auto-resized.'),
+        h('div', { style: { display: 'flex', gap: 4, marginTop: 6, width: 160 } },
+          h('input', { className: 'input', type: 'url', value: urlInput, placeholder: 'or image URL', style: { fontSize: 11, padding: '4px 6px' }, onChange: function(e) { setUrlInput(e.target.value); }, onKeyDown: function(e) { if (e.key === 'Enter') { e.preventDefault(); applyAvatarUrl(); } } }),
+          h('button', { type: 'button', className: 'btn btn-secondary btn-sm', style: { fontSize: 11 }, disabled: !urlInput.trim(), onClick: applyAvatarUrl }, 'Use')
+        )
       ),
 
       // DOB + Gender + Marital
@@ -0,0 +1,119 @@
+import { h, useState, useEffect, useCallback, useRef, Fragment, useApp, apiCall, engineCall, batchGet, downloadExport, showConfirm } from './utils.js';
+import { I } from './icons.js';
+import { Modal, DetailModal } from './modal.js';
+import { HelpButton } from './help-button.js';
+import { RelativeTime, formatTime, timeAgo } from './time.js';
+import { useDeferred, Skeleton, SkeletonTable } from './fragments.js';
+import { FilterBar, useFilters } from './filter-bar.js';
+import { Sparkline, BarChart } from './charts.js';
+import { usePreference } from './preferences.js';
+
+// ─── Dashboard Plugins ──────────────────────────────────
+// Custom pages installed by self-hosters (see lib/dashboard-plugins.ts for
+// the manifest). The server lists the pages the user's role may open; each
+// plugin's entry module is imported and its default export called with the
+// API below, which is the only contract plugins should rely on:
+//
+//   export default function register(api) {
+//     api.registerPage({ id: 'weekly', component: function() {
+//       var { toast } = api.useApp();
+//       return api.h('div', null, 'Hello');
+//     } });
+//   }
+//
+// Page ids in the dashboard are 'plugins/<plugin>/<page>'. A plugin that
+// fails to load is logged and left out; it never takes the dashboard down.
+
+var _pages = {};
+var _loading = null;
+var _loaded = false;
+var _listeners = [];
+
+function pluginApi(plugin) {
+  return {
+    plugin: { id: plugin.id, name: plugin.name, version: plugin.version },
+    // Rendering
+    h: h, Fragment: Fragment, useState: useState, useEffect: useEffect, useCallback: useCallback, useRef: useRef,
+    // Services client — same auth, CSRF and org scoping as the built-in pages
+    useApp: useApp, apiCall: apiCall, engineCall: engineCall, batchGet: batchGet, downloadExport: downloadExport, showConfirm: showConfirm,
+    usePreference: function(key, fallback) { return usePreference('plugins.' + plugin.id + '.' + key, fallback); },
+    icons: I,
+    components: {
+      Modal: Modal, DetailModal: DetailModal, HelpButton: HelpButton, RelativeTime: RelativeTime,
+      Skeleton: Skeleton, SkeletonTable: SkeletonTable, FilterBar: FilterBar, Sparkline: Sparkline, BarChart: BarChart,
+    },
+    hooks: { useDeferred: useDeferred, useFilters: useFilters },
+    format: { time: formatTime, timeAgo: timeAgo },
+    registerPage: function(def) {
+      var page = def && plugin.pages.find(function(p) { return p.id === def.id; });
+      if (!page) { console.warn('[plugins] ' + plugin.id + ': page "' + (def && def.id) + '" is not in plugin.json or not visible to this user'); return; }
+      if (typeof def.component !== 'function') { console.warn('[plugins] ' + plugin.id + ': page "' + def.id + '" has no component'); return; }
+      _pages['plugins/' + plugin.id + '/' + page.id] = {
+        id: 'plugins/' + plugin.id + '/' + page.id,
+        label: page.label,
+        section: page.section,
+        icon: I[page.icon] || I.link,
+        component: def.component,
+        plugin: plugin.id,
+      };
+    },
+  };
+}
+
+function notify() {
+  _listeners.forEach(function(fn) { try { fn(); } catch (e) { /* listener errors are not ours */ } });
+}
+
+/** Fetch the plugin list and import every entry module, once per session. */
+export function loadPlugins() {
+  if (_loading) return _loading;
+  _loading = apiCall('/dashboard/plugins', { _untimed: true })
+    .then(function(d) {
+      return Promise.all((d.plugins || []).map(function(plugin) {
+        return import(plugin.entry)
+          .then(function(mod) {
+            var register = mod.default || mod.register;
+            if (typeof register !== 'function') throw new Error('entry module has no default export');
+            return register(pluginApi(plugin));
+          })
+          .catch(function(err) { console.warn('[plugins] Failed to load ' + plugin.id + ':', err); });
+      }));
+    })
+    .catch(function() { /* no plugins endpoint or not signed in */ })
+    .then(function() { _loaded = true; notify(); });
+  return _loading;
+}
+
+/** Registered plugin pages keyed by page id; re-renders once plugins have loaded. */
+export function usePluginPages(enabled) {
+  var [, setTick] = useState(0);
+  useEffect(function() {
+    if (!enabled) return;
+    var fn = function() { setTick(function(n) { return n + 1; }); };
+    _listeners.push(fn);
+    loadPlugins();
+    return function() { _listeners = _listeners.filter(function(l) { return l !== fn; }); };
+  }, [enabled]);
+  return _pages;
+}
+
+/** Add plugin pages to the sidebar sections, creating any section that doesn't exist yet. */
+export function withPluginNav(nav, pages) {
+  var ids = Object.keys(pages);
+  if (!ids.length) return nav;
+  var result = nav.map(function(s) { return Object.assign({}, s, { items: s.items.slice() }); });
+  ids.forEach(function(id) {
+    var p = pages[id];
+    var section = result.find(function(s) { return s.section === p.section; });
+    if (!section) { section = { section: p.section, items: [] }; result.push(section); }
+    section.items.push({ id: p.id, icon: p.icon, label: p.label });
+  });
+  return result;
+}
+
+/** Stands in for a plugin page until plugins have loaded, or when it doesn't exist. */
+export function PluginPagePlaceholder() {
+  return h('div', { style: { padding: 40, textAlign: 'center', color: 'var(--text-muted)' } },
+    _loaded ? 'This plugin page is not installed, or you don\'t have access to it.' : 'Loading...'
+  );
+}
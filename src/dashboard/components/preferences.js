@@ -0,0 +1,105 @@
+import { h, useState, useEffect, engineCall } from './utils.js';
+import { I } from './icons.js';
+
+// ─── Per-User Preferences ───────────────────────────────
+// Stored on the server (/engine/preferences) so they follow the user to any
+// browser. Everything is loaded once per session and cached here; writes
+// update the cache straight away and are saved in the background.
+//
+//   var [hidden, setHidden] = usePreference('columns.audit', []);
+
+var _prefs = null;
+var _loading = null;
+var _listeners = [];
+var _timers = {};
+
+function notify() {
+  _listeners.forEach(function(fn) { try { fn(); } catch (e) { /* listener errors are not ours */ } });
+}
+
+export function loadPreferences() {
+  if (_prefs) return Promise.resolve(_prefs);
+  if (!_loading) {
+    _loading = engineCall('/preferences')
+      .then(function(d) { _prefs = d.preferences || {}; })
+      // Not signed in or the engine is down: work from defaults for this session
+      .catch(function() { _prefs = {}; })
+      .then(function() { _loading = null; notify(); return _prefs; });
+  }
+  return _loading;
+}
+
+/** Drop the cache (sign-out, impersonation) so the next read loads the new user's */
+export function resetPreferences() { _prefs = null; notify(); }
+
+export function getPreference(key, fallback) {
+  return _prefs && Object.prototype.hasOwnProperty.call(_prefs, key) ? _prefs[key] : fallback;
+}
+
+export function setPreference(key, value) {
+  if (!_prefs) _prefs = {};
+  if (value === undefined) delete _prefs[key]; else _prefs[key] = value;
+  notify();
+  // Toggling several columns in a row saves once
+  clearTimeout(_timers[key]);
+  _timers[key] = setTimeout(function() {
+    var path = '/preferences/' + encodeURIComponent(key);
+    (value === undefined ? engineCall(path, { method: 'DELETE' }) : engineCall(path, { method: 'PUT', body: JSON.stringify({ value: value }) }))
+      .catch(function(err) { console.warn('[preferences] Failed to save ' + key + ': ' + err.message); });
+  }, 400);
+}
+
+/** [value, setValue] for one preference; setValue(undefined) resets it to the default */
+export function usePreference(key, fallback) {
+  var [, setTick] = useState(0);
+  useEffect(function() {
+    var fn = function() { setTick(function(t) { return t + 1; }); };
+    _listeners.push(fn);
+    loadPreferences();
+    return function() { _listeners = _listeners.filter(function(l) { return l !== fn; }); };
+  }, []);
+  return [getPreference(key, fallback), function(value) { setPreference(key, value); }];
+}
+
+// ─── Column Picker ──────────────────────────────────────
+// Checkbox menu for showing/hiding table columns. Columns with `required`
+// (or no label) are always shown and not listed.
+
+export function ColumnPicker(props) {
+  // Where the menu goes, or null when closed. Fixed positioning so cards with overflow: hidden don't clip it.
+  var [open, setOpen] = useState(null);
+  var hidden = props.hidden || [];
+  var options = props.columns.filter(function(c) { return c && c.label && !c.required; });
+
+  useEffect(function() {
+    if (!open) return;
+    var close = function(e) { if (!e.target.closest || !e.target.closest('.column-picker')) setOpen(null); };
+    var onScroll = function() { setOpen(null); };
+    document.addEventListener('mousedown', close);
+    window.addEventListener('scroll', onScroll, true);
+    return function() { document.removeEventListener('mousedown', close); window.removeEventListener('scroll', onScroll, true); };
+  }, [open]);
+
+  if (!options.length) return null;
+
+  var toggle = function(key) {
+    props.onChange(hidden.indexOf(key) === -1 ? hidden.concat([key]) : hidden.filter(function(k) { return k !== key; }));
+  };
+
+  return h('div', { className: 'column-picker' },
+    h('button', { type: 'button', className: 'btn btn-ghost btn-icon btn-sm', title: 'Show or hide columns', 'aria-expanded': !!open, onClick: function(e) {
+      var r = e.currentTarget.getBoundingClientRect();
+      setOpen(open ? null : { top: r.bottom + 4, right: Math.max(8, window.innerWidth - r.right) });
+    } }, I.settings()),
+    open && h('div', { className: 'column-picker-menu', role: 'menu', style: { top: open.top, right: open.right } },
+      h('div', { className: 'column-picker-title' }, 'Columns'),
+      options.map(function(col) {
+        return h('label', { key: col.key, className: 'column-picker-item' },
+          h('input', { type: 'checkbox', checked: hidden.indexOf(col.key) === -1, onChange: function() { toggle(col.key); } }),
+          col.label
+        );
+      }),
+      hidden.length > 0 && h('button', { type: 'button', className: 'btn btn-ghost btn-sm', style: { width: '100%', marginTop: 4 }, onClick: function() { props.onChange([]); setOpen(null); } }, 'Show all')
+    )
+  );
+}
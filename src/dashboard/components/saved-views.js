@@ -0,0 +1,58 @@
+import { h, useState } from './utils.js';
+import { usePreference } from './preferences.js';
+
+// ─── Saved Views ────────────────────────────────────────
+// Named filter combinations ("Suspended users", "Failed tool calls last 24h")
+// saved per user as the `views.<viewsKey>` preference and offered in a
+// dropdown next to the filters. A view is just a copy of the filter values;
+// relative ranges like '24h' stay relative, so a view always means "now".
+//
+//   h(SavedViews, { viewsKey: 'users', values: filters.values, onApply: filters.replace })
+
+var MAX_VIEWS = 30;
+
+function sameValues(a, b) {
+  var keys = Object.keys(Object.assign({}, a, b));
+  return keys.every(function(k) { return (a[k] || '') === (b[k] || ''); });
+}
+
+/** SavedViews({ viewsKey, values, onApply }) */
+export function SavedViews(props) {
+  var [views, setViews] = usePreference('views.' + props.viewsKey, []);
+  var [picked, setPicked] = useState('');
+  var list = Array.isArray(views) ? views : [];
+  // Keep the dropdown on the view that's showing, and drop it once the filters change
+  var current = list.find(function(v) { return v.name === picked && sameValues(v.values, props.values); });
+
+  var save = function() {
+    var name = (prompt('Name this view', current ? current.name : '') || '').trim();
+    if (!name) return;
+    var next = list.filter(function(v) { return v.name !== name; });
+    next.push({ name: name, values: Object.assign({}, props.values) });
+    setViews(next.slice(-MAX_VIEWS));
+    setPicked(name);
+  };
+
+  var remove = function() {
+    if (!current || !confirm('Delete the saved view "' + current.name + '"?')) return;
+    setViews(list.filter(function(v) { return v.name !== current.name; }));
+    setPicked('');
+  };
+
+  return h('div', { className: 'saved-views' },
+    h('select', {
+      className: 'input', style: { width: 180 }, 'aria-label': 'Saved views',
+      value: current ? current.name : '',
+      onChange: function(e) {
+        var view = list.find(function(v) { return v.name === e.target.value; });
+        setPicked(view ? view.name : '');
+        if (view) props.onApply(view.values);
+      }
+    },
+      h('option', { value: '' }, list.length ? 'Saved views' : 'No saved views'),
+      list.map(function(v) { return h('option', { key: v.name, value: v.name }, v.name); })
+    ),
+    h('button', { type: 'button', className: 'btn btn-ghost btn-sm', title: 'Save the current filters as a named view', onClick: save }, 'Save view'),
+    current && h('button', { type: 'button', className: 'btn btn-ghost btn-sm', title: 'Delete this saved view', onClick: remove }, 'Delete')
+  );
+}
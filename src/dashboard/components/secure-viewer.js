@@ -0,0 +1,68 @@
+import { h } from './utils.js';
+import { I } from './icons.js';
+
+// ─── Secure Viewer ──────────────────────────────────────
+//
+// Shows sensitive content (compliance reports, quarantined DLP matches,
+// case transcripts) with the viewer's identity tiled across it, instead of
+// handing out a raw download. The route that serves the content logs the
+// access and returns the `viewer` stamp this renders.
+//
+// Props:
+//   title     — modal title
+//   subtitle  — optional line under the title
+//   viewer    — stamp from the server: { label, email, viewedAt, requestId }
+//   html      — optional full HTML document, shown in a sandboxed iframe
+//   children  — content to show when there's no `html`
+//   actions   — optional buttons for the header (e.g. a download fallback)
+//   onClose   — close handler
+
+function escapeXml(s) {
+  return String(s).replace(/&/g, '&amp;').replace(/</g, '&lt;').replace(/>/g, '&gt;').replace(/"/g, '&quot;');
+}
+
+/** A tiled, rotated SVG of the label, as a CSS background-image value */
+export function watermarkBackground(label) {
+  var svg = '<svg xmlns="http://www.w3.org/2000/svg" width="420" height="220">' +
+    '<text x="210" y="110" text-anchor="middle" transform="rotate(-24 210 110)" ' +
+    'font-family="Helvetica, Arial, sans-serif" font-size="14" fill="#888" fill-opacity="0.22">' +
+    escapeXml(label) + '</text></svg>';
+  return 'url("data:image/svg+xml;charset=utf-8,' + encodeURIComponent(svg) + '")';
+}
+
+/** Wraps content so the watermark sits over it without catching clicks */
+export function Watermarked({ label, children, style }) {
+  return h('div', { className: 'secure-viewer-content', style: Object.assign({ position: 'relative' }, style) },
+    children,
+    label && h('div', { className: 'secure-viewer-watermark', 'aria-hidden': 'true', style: { backgroundImage: watermarkBackground(label) } })
+  );
+}
+
+export function SecureViewer({ title, subtitle, viewer, html, children, actions, onClose }) {
+  var label = viewer && viewer.label;
+  return h('div', { className: 'modal-overlay', onClick: function(e) { if (e.target === e.currentTarget) onClose(); } },
+    h('div', { className: 'modal secure-viewer', style: { maxWidth: 1100, width: '95vw', height: '90vh', display: 'flex', flexDirection: 'column' } },
+      h('div', { className: 'modal-header' },
+        h('div', null,
+          h('h2', { style: { margin: 0 } }, title),
+          subtitle && h('div', { style: { fontSize: 12, color: 'var(--text-muted)', marginTop: 4 } }, subtitle)
+        ),
+        h('div', { style: { display: 'flex', gap: 8, alignItems: 'center' } },
+          actions,
+          h('button', { className: 'btn btn-ghost btn-icon', onClick: onClose }, I.x())
+        )
+      ),
+      h('div', { className: 'secure-viewer-notice' },
+        I.lock(), ' Confidential. This view is logged',
+        viewer ? ' and watermarked for ' + (viewer.email || label) : '',
+        viewer && viewer.requestId ? ' · Ref ' + viewer.requestId : ''
+      ),
+      html
+        ? h(Watermarked, { label: label, style: { flex: 1, minHeight: 0 } },
+            // No scripts, same-origin or forms: the report is static markup
+            h('iframe', { title: title, srcDoc: html, sandbox: '', style: { width: '100%', height: '100%', border: 0, background: '#fff' } }))
+        : h('div', { className: 'modal-body', style: { overflow: 'auto', flex: 1 } },
+            h(Watermarked, { label: label }, children))
+    )
+  );
+}
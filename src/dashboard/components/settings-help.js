@@ -46,7 +46,14 @@ export var SETTINGS_HELP = {
         h('ul', { style: _ul },
           h('li', null, h('strong', null, 'Key Name'), ' \u2014 A label to help you remember what each key is used for (e.g., "Production Backend" or "CI/CD Pipeline").'),
           h('li', null, h('strong', null, 'Key Prefix'), ' \u2014 The visible portion of the key shown in the table for identification. The full key is only shown once when created.'),
-          h('li', null, h('strong', null, 'Scopes'), ' \u2014 What the key can do: read (view data), write (create and update), or admin (full access including deleting resources).'),
+          h('li', null, h('strong', null, 'Scopes'), ' \u2014 What the key can do: read (GET requests only), write (create, change and delete), or admin (admin-only routes such as users and settings, plus read and write). A request outside the key\'s scopes is refused with 403.'),
+          h('li', null, h('strong', null, 'Area scopes'), ' \u2014 Limit a key to one part of the API, e.g. agents:write or audit:read. Area scopes never open admin-only routes such as managing users; those need the admin scope. Edit a key\'s scopes at any time with the pencil icon.'),
+          h('li', null, h('strong', null, 'Allowed IPs'), ' \u2014 Optional IPv4 addresses or CIDR ranges the key may be used from. Requests from anywhere else are refused, so a leaked key is useless outside your network. Set them when creating a key or later with the globe icon.'),
+          h('li', null, h('strong', null, 'Agent'), ' \u2014 A service-account key bound to one agent, for that agent\'s external integrations. It only works on requests about that agent, and it is revoked automatically when the agent is archived. Use the agent filter above the table to see one agent\'s keys.'),
+          h('li', null, h('strong', null, 'Expires'), ' \u2014 After this date the key stops working. Pick the shortest lifetime that suits the integration.'),
+          h('li', null, h('strong', null, 'Acts as'), ' \u2014 A key acts as the user who created it, with that user\'s access. Changes made with it are logged under the key itself (api_key:<id>), with the user noted as actingAs.'),
+          h('li', null, h('strong', null, 'Audit Trail'), ' \u2014 Click a key\'s name or the clock icon to see everything done with it and to it: creation, the calls made with it, scope and IP changes, rotation and revocation.'),
+          h('li', null, h('strong', null, 'Rotate'), ' \u2014 Creates a replacement key with the same name and scopes and shows it once. The old key keeps working for the grace period you choose, then expires, so integrations can switch over without downtime.'),
           h('li', null, h('strong', null, 'Revoke'), ' \u2014 Permanently disables a key. Any application using that key will immediately lose access. This cannot be undone.')
         ),
         h('div', { style: _tip }, 'Important: Copy your API key immediately after creation. For security, the full key is never shown again.')
@@ -170,6 +177,22 @@ export var SETTINGS_HELP = {
     }
   },
 
+  residency: {
+    label: 'Data Residency',
+    content: function() {
+      return h('div', null,
+        h('p', null, 'Data Residency shows where each part of AgenticMail stores or processes your data: the database, file storage, the server itself, agent hosting, model inference and email.'),
+        h('p', null, 'Locations are detected from the deployment where possible (cloud region variables, database hostnames, storage bucket region). Anything that can\'t be detected can be declared by hand.'),
+        h('h4', { style: _h4 }, 'Multi-region deployments'),
+        h('ul', { style: _ul },
+          h('li', null, h('strong', null, 'Regions'), ' \u2014 Define the regions data may live in (e.g. EU, US) and the cloud regions inside each.'),
+          h('li', null, h('strong', null, 'Pinning'), ' \u2014 New agents and their mailboxes are pinned to the default region, or the one picked when creating them. Pins can be changed per agent.'),
+          h('li', null, h('strong', null, 'Enforce'), ' \u2014 Deploys that would put a pinned agent in a cloud region outside its residency region fail.')
+        )
+      );
+    }
+  },
+
   integrations: {
     label: 'Integrations',
     content: function() {
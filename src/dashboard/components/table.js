@@ -0,0 +1,96 @@
+import { h, useState } from './utils.js';
+import { usePreference, ColumnPicker } from './preferences.js';
+
+// ─── Sortable Table ──────────────────────────────────────
+// Column-driven <table> for the list pages. Sorting happens on the server:
+// clicking a header only updates the sort state, the page sends it with its
+// list request (?sort=<key>&dir=asc|desc) and renders the rows it gets back.
+//
+//   var sort = useSort('createdAt', 'desc');
+//   useFragment('/agents/table', Object.assign({ ... }, sort.params), ...)
+//   h(Table, { columns, rows, sort: sort.sort, onSort: sort.toggle, ... })
+//
+// Column: { key, label, sortable, defaultDir, width, align, style, required, render(row, i) }
+//
+// With `prefsKey` the last header gets a column picker; hidden columns are
+// saved per user as the `columns.<prefsKey>` preference. `required` columns
+// can't be hidden.
+
+/** { sort: { key, dir }, toggle(key, defaultDir), params: { sort, dir }, query } */
+export function useSort(initialKey, initialDir) {
+  var [sort, setSort] = useState({ key: initialKey, dir: initialDir || 'asc' });
+  var toggle = function(key, defaultDir) {
+    setSort(function(s) {
+      if (s.key === key) return { key: key, dir: s.dir === 'asc' ? 'desc' : 'asc' };
+      return { key: key, dir: defaultDir || 'asc' };
+    });
+  };
+  return {
+    sort: sort,
+    toggle: toggle,
+    params: { sort: sort.key, dir: sort.dir },
+    query: 'sort=' + encodeURIComponent(sort.key) + '&dir=' + sort.dir,
+  };
+}
+
+function SortHeader(props) {
+  var col = props.column;
+  var active = props.sort && props.sort.key === col.key;
+  var dir = active ? props.sort.dir : null;
+  var style = Object.assign({}, col.width ? { width: col.width } : null, col.align ? { textAlign: col.align } : null, col.headerStyle);
+  var extra = props.picker ? { className: 'th-with-picker' } : null;
+  if (!col.sortable || !props.onSort) return h('th', Object.assign({ style: style }, extra), col.label, props.picker);
+  return h('th', Object.assign({ style: style, 'aria-sort': dir === 'asc' ? 'ascending' : dir === 'desc' ? 'descending' : 'none' }, extra),
+    h('button', {
+      type: 'button',
+      className: 'th-sort' + (active ? ' active' : ''),
+      title: 'Sort by ' + col.label,
+      onClick: function() { props.onSort(col.key, col.defaultDir); }
+    },
+      col.label,
+      h('span', { className: 'th-sort-icon', 'aria-hidden': true }, dir === 'asc' ? '▲' : dir === 'desc' ? '▼' : '⇅')
+    ),
+    props.picker
+  );
+}
+
+/**
+ * Table({ columns, rows, rowKey, sort, onSort, onRowClick, rowTitle, rowStyle,
+ *         empty, refreshing, className, prefsKey })
+ * rowKey is a property name or function (default 'id').
+ */
+export function Table(props) {
+  var all = props.columns.filter(Boolean);
+  var [hidden, setHidden] = usePreference(props.prefsKey ? 'columns.' + props.prefsKey : null, []);
+  var columns = all.filter(function(col) { return col.required || hidden.indexOf(col.key) === -1; });
+  // Hiding everything hideable still leaves something to click
+  if (!columns.length) columns = all;
+  var picker = props.prefsKey ? h(ColumnPicker, { columns: all, hidden: hidden, onChange: setHidden }) : null;
+  var rows = props.rows || [];
+  var keyOf = typeof props.rowKey === 'function' ? props.rowKey : function(r, i) { return r[props.rowKey || 'id'] || i; };
+
+  return h('table', { className: props.className || null },
+    h('thead', null, h('tr', null, columns.map(function(col, i) {
+      return h(SortHeader, { key: col.key, column: col, sort: props.sort, onSort: props.onSort, picker: i === columns.length - 1 ? picker : null });
+    }))),
+    h('tbody', { style: props.refreshing ? { opacity: 0.5, transition: 'opacity 150ms' } : { transition: 'opacity 150ms' } },
+      rows.length === 0 && props.empty
+        ? h('tr', null, h('td', { colSpan: columns.length, style: { textAlign: 'center', color: 'var(--text-muted)', padding: 40 } }, props.empty))
+        : rows.map(function(row, i) {
+            var style = Object.assign({}, props.onRowClick ? { cursor: 'pointer' } : null, props.rowStyle ? props.rowStyle(row) : null);
+            return h('tr', {
+              key: keyOf(row, i),
+              style: style,
+              title: props.rowTitle || undefined,
+              onClick: props.onRowClick ? function() { props.onRowClick(row); } : undefined,
+              // Clickable rows are reachable and openable from the keyboard too
+              tabIndex: props.onRowClick ? 0 : undefined,
+              onKeyDown: props.onRowClick ? function(e) { if ((e.key === 'Enter' || e.key === ' ') && e.target === e.currentTarget) { e.preventDefault(); props.onRowClick(row); } } : undefined
+            }, columns.map(function(col) {
+              var cellStyle = Object.assign({}, col.align ? { textAlign: col.align } : null, col.style);
+              return h('td', { key: col.key, style: cellStyle }, col.render ? col.render(row, i) : (row[col.key] ?? '-'));
+            }));
+          })
+    )
+  );
+}
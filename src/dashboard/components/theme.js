@@ -0,0 +1,69 @@
+import { h, useState, useEffect } from './utils.js';
+import { I } from './icons.js';
+
+// ─── Theme preference ────────────────────────────────────
+// 'system' follows prefers-color-scheme; 'light'/'dark' override it. The
+// choice lives in the em_theme cookie so the server can stamp data-theme on
+// <html> before first paint (see serveDashboard in server.ts).
+
+var COOKIE = 'em_theme';
+var PREFERENCES = ['system', 'light', 'dark'];
+var MEDIA = '(prefers-color-scheme: light)';
+
+export function getThemePreference() {
+  var m = document.cookie.match(/(?:^|;\s*)em_theme=(\w+)/);
+  var v = m ? m[1] : localStorage.getItem('em_theme'); // localStorage: pre-cookie installs
+  return PREFERENCES.indexOf(v) !== -1 ? v : 'system';
+}
+
+function saveThemePreference(pref) {
+  document.cookie = COOKIE + '=' + pref + '; path=/; max-age=31536000; SameSite=Lax' + (location.protocol === 'https:' ? '; Secure' : '');
+  localStorage.removeItem('em_theme');
+}
+
+function systemTheme() {
+  return window.matchMedia && window.matchMedia(MEDIA).matches ? 'light' : 'dark';
+}
+
+/** { preference, theme, setPreference } — theme is the resolved 'light' | 'dark'. */
+export function useTheme() {
+  var [preference, setPref] = useState(getThemePreference);
+  var [system, setSystem] = useState(systemTheme);
+  var theme = preference === 'system' ? system : preference;
+
+  useEffect(function() {
+    if (!window.matchMedia) return;
+    var mq = window.matchMedia(MEDIA);
+    var onChange = function() { setSystem(mq.matches ? 'light' : 'dark'); };
+    mq.addEventListener('change', onChange);
+    return function() { mq.removeEventListener('change', onChange); };
+  }, []);
+
+  useEffect(function() { document.documentElement.setAttribute('data-theme', theme); }, [theme]);
+
+  var setPreference = function(pref) {
+    if (PREFERENCES.indexOf(pref) === -1) return;
+    saveThemePreference(pref);
+    setPref(pref);
+  };
+  return { preference: preference, theme: theme, setPreference: setPreference };
+}
+
+/** Segmented System / Light / Dark control for the sidebar footer. */
+export function ThemeSwitcher(props) {
+  var options = [
+    { id: 'system', label: 'System', icon: I.monitor },
+    { id: 'light', label: 'Light', icon: I.sun },
+    { id: 'dark', label: 'Dark', icon: I.moon },
+  ];
+  return h('div', { className: 'theme-switcher', role: 'radiogroup', 'aria-label': 'Theme' },
+    options.map(function(o) {
+      var active = props.preference === o.id;
+      return h('button', {
+        key: o.id, type: 'button', role: 'radio', 'aria-checked': active, title: o.label + ' theme',
+        className: 'theme-switcher-option' + (active ? ' active' : ''),
+        onClick: function() { props.onChange(o.id); }
+      }, o.icon ? o.icon({ size: 14 }) : null, h('span', { className: 'theme-switcher-label' }, o.label));
+    })
+  );
+}
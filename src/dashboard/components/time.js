@@ -0,0 +1,116 @@
+import { h, useState, useEffect } from './utils.js';
+import { TIMEZONE_GROUPS, getTimezoneLabel } from './timezones.js';
+
+// ─── Time rendering ──────────────────────────────────────
+// Timestamps render as "3m ago" with the full date in a tooltip, or as a
+// localized absolute time — both in the user's chosen timezone. 'auto'
+// follows the browser. Like the theme, the choice lives in a cookie
+// (em_tz) so it sticks per browser session across reloads and sign-ins.
+//
+//   h(RelativeTime, { value: row.createdAt })    // <time title="Mar 3, 2026, 14:05 UTC">3m ago</time>
+//   formatTime(row.createdAt)                     // "Mar 3, 2026, 14:05"
+//   timeAgo(row.createdAt)                        // "3m ago" / "in 2h"
+
+var COOKIE = 'em_tz';
+
+function browserTimezone() {
+  try { return Intl.DateTimeFormat().resolvedOptions().timeZone || 'UTC'; } catch (e) { return 'UTC'; }
+}
+
+function isValidTimezone(tz) {
+  try { new Intl.DateTimeFormat(undefined, { timeZone: tz }); return true; } catch (e) { return false; }
+}
+
+/** 'auto' or an IANA zone name */
+export function getTimezonePreference() {
+  var m = document.cookie.match(/(?:^|;\s*)em_tz=([^;]+)/);
+  var v = m ? decodeURIComponent(m[1]) : 'auto';
+  return v === 'auto' || isValidTimezone(v) ? v : 'auto';
+}
+
+/** The IANA zone times are shown in */
+export function getTimezone() {
+  var pref = getTimezonePreference();
+  return pref === 'auto' ? browserTimezone() : pref;
+}
+
+export function setTimezonePreference(tz) {
+  if (tz !== 'auto' && !isValidTimezone(tz)) return;
+  document.cookie = COOKIE + '=' + encodeURIComponent(tz) + '; path=/; max-age=31536000; SameSite=Lax' + (location.protocol === 'https:' ? '; Secure' : '');
+  window.dispatchEvent(new CustomEvent('em:timezone', { detail: tz }));
+}
+
+function toDate(ts) {
+  if (ts == null || ts === '') return null;
+  // SQL timestamps without a zone ("2026-03-03 14:05:00") are UTC
+  if (typeof ts === 'string' && /^\d{4}-\d\d-\d\d[ T]\d\d:\d\d(:\d\d(\.\d+)?)?$/.test(ts)) ts = ts.replace(' ', 'T') + 'Z';
+  var d = ts instanceof Date ? ts : new Date(ts);
+  return isNaN(d.getTime()) ? null : d;
+}
+
+/**
+ * Localized absolute time in the preferred zone. style: 'datetime' (default),
+ * 'date', 'time', or 'full' (with seconds and zone name).
+ */
+export function formatTime(ts, style) {
+  var d = toDate(ts);
+  if (!d) return '-';
+  var opts = { timeZone: getTimezone() };
+  if (style === 'date') { opts.dateStyle = 'medium'; }
+  else if (style === 'time') { opts.timeStyle = 'short'; }
+  else if (style === 'full') { Object.assign(opts, { year: 'numeric', month: 'short', day: 'numeric', hour: '2-digit', minute: '2-digit', second: '2-digit', timeZoneName: 'short' }); }
+  else { opts.dateStyle = 'medium'; opts.timeStyle = 'short'; }
+  try { return new Intl.DateTimeFormat(undefined, opts).format(d); } catch (e) { return d.toLocaleString(); }
+}
+
+/** "just now", "3m ago", "in 2h"; past a week, the date. */
+export function timeAgo(ts) {
+  var d = toDate(ts);
+  if (!d) return '-';
+  var diff = Date.now() - d.getTime();
+  var abs = Math.abs(diff);
+  if (abs < 45000) return 'just now';
+  var n, unit;
+  if (abs < 3600000) { n = Math.round(abs / 60000); unit = 'm'; }
+  else if (abs < 86400000) { n = Math.round(abs / 3600000); unit = 'h'; }
+  else if (abs < 7 * 86400000) { n = Math.round(abs / 86400000); unit = 'd'; }
+  else return formatTime(d, 'date');
+  return diff >= 0 ? n + unit + ' ago' : 'in ' + n + unit;
+}
+
+// One ticker for every RelativeTime on the page
+var listeners = new Set();
+var ticker = null;
+function subscribe(fn) {
+  listeners.add(fn);
+  if (!ticker) ticker = setInterval(function() { listeners.forEach(function(l) { l(); }); }, 30000);
+  return function() {
+    listeners.delete(fn);
+    if (listeners.size === 0) { clearInterval(ticker); ticker = null; }
+  };
+}
+
+/** <time> showing "3m ago" that keeps itself current; hover for the absolute time. */
+export function RelativeTime(props) {
+  var [, setTick] = useState(0);
+  useEffect(function() { return subscribe(function() { setTick(function(t) { return t + 1; }); }); }, []);
+  var d = toDate(props.value);
+  if (!d) return h('span', { style: props.style }, '-');
+  return h('time', { dateTime: d.toISOString(), title: formatTime(d, 'full'), style: props.style }, timeAgo(d));
+}
+
+/** Compact timezone preference picker for the sidebar footer. */
+export function TimezonePicker() {
+  var [pref, setPref] = useState(getTimezonePreference);
+  return h('select', {
+    className: 'input timezone-select', 'aria-label': 'Timezone', title: 'Times are shown in this timezone', value: pref,
+    onChange: function(e) { setPref(e.target.value); setTimezonePreference(e.target.value); }
+  },
+    h('option', { value: 'auto' }, 'Browser time (' + browserTimezone() + ')'),
+    Object.entries(TIMEZONE_GROUPS).map(function(entry) {
+      return h('optgroup', { key: entry[0], label: entry[0] },
+        entry[1].map(function(tz) { return h('option', { key: tz, value: tz }, getTimezoneLabel(tz)); })
+      );
+    })
+  );
+}
@@ -1,3 +1,5 @@
+import { activeBackend, failoverEnabled, markBackendDown } from './backends.js';
+
 const h = React.createElement;
 const { useState, useEffect, useCallback, useRef, Fragment, createContext, useContext } = React;
 const AppContext = createContext();
@@ -45,20 +47,117 @@ export function getCsrf() {
   return m ? m[1] : '';
 }
 
+// W3C trace context — one trace per page navigation, so every API call behind
+// a slow page load shows up under a single trace in the OTel backend
+function _randomHex(bytes) {
+  var a = new Uint8Array(bytes);
+  crypto.getRandomValues(a);
+  return Array.from(a, function(b) { return b.toString(16).padStart(2, '0'); }).join('');
+}
+var _pageTraceId = _randomHex(16);
+var _tracePage = (location.pathname.replace(/^\/dashboard\/?/, '').split('/')[0]) || 'dashboard';
+export function startPageTrace(page) {
+  _pageTraceId = _randomHex(16);
+  _tracePage = page || '';
+  _beginRender(_tracePage);
+}
+function traceHeaders() {
+  return { traceparent: '00-' + _pageTraceId + '-' + _randomHex(8) + '-01', 'X-Dashboard-Page': _tracePage };
+}
+
+// Render timing — a page counts as rendered once the API calls it started in
+// its first seconds have all settled and stayed quiet for a moment. The time
+// from navigation to that point is reported once per navigation, feeding the
+// admin performance page and its per-page render budgets
+var RENDER_QUIET_MS = 300;
+var RENDER_WINDOW_MS = 10000;
+var RENDER_MAX_MS = 120000;
+var _render = { page: _tracePage, start: 0, inflight: 0, last: 0, timer: null, done: false };
+function _beginRender(page) {
+  if (_render.timer) clearTimeout(_render.timer);
+  _render = { page: page, start: performance.now(), inflight: 0, last: 0, timer: null, done: false };
+}
+function _trackRender() {
+  var r = _render;
+  if (r.done || performance.now() - r.start > RENDER_WINDOW_MS) return null;
+  r.inflight++;
+  if (r.timer) { clearTimeout(r.timer); r.timer = null; }
+  return function() {
+    r.inflight--;
+    r.last = performance.now();
+    if (r.inflight > 0 || r.done) return;
+    r.timer = setTimeout(function() {
+      if (r.inflight > 0 || r.done) return;
+      r.done = true;
+      var durationMs = Math.round(r.last - r.start);
+      if (r.page && durationMs <= RENDER_MAX_MS) {
+        apiCall('/admin/performance/renders', { method: 'POST', body: JSON.stringify({ page: r.page, durationMs: durationMs }), _untimed: true }).catch(function() {});
+      }
+    }, RENDER_QUIET_MS);
+  };
+}
+
+// User-action correlation — a click or form submit opens a short window during
+// which every API call carries the same action ID, so the owner-only call log
+// can show exactly which backend calls a button press produced
+var ACTION_WINDOW_MS = 5000;
+var _action = null;
+function _beginAction(el, kind) {
+  var label = (el.getAttribute('aria-label') || el.getAttribute('title') || el.textContent || el.value || kind).replace(/\s+/g, ' ').trim().slice(0, 80);
+  _action = { id: _randomHex(8), label: label || kind, at: Date.now() };
+}
+if (typeof document !== 'undefined') {
+  document.addEventListener('click', function(e) {
+    var el = e.target && e.target.closest && e.target.closest('button, a, [role="button"], .nav-item, input[type="checkbox"], select');
+    if (el) _beginAction(el, 'click');
+  }, true);
+  document.addEventListener('submit', function(e) { _beginAction(e.target, 'submit'); }, true);
+}
+function actionHeaders() {
+  if (!_action || Date.now() - _action.at > ACTION_WINDOW_MS) return {};
+  return { 'X-Action-Id': _action.id, 'X-Action-Label': encodeURIComponent(_action.label) };
+}
+
+// ─── Compressed Responses ────────────────────────────────
+// Large responses from remote backends (audit exports, message lists) are
+// asked for gzip/deflate-encoded. Browsers send their own Accept-Encoding and
+// decode Content-Encoding themselves, ignoring the header set here; other
+// fetch runtimes honour it. A body that still arrives compressed (a proxy
+// served it as application/gzip or dropped Content-Encoding) is decompressed
+// before it is parsed.
+var ACCEPT_ENCODING = 'gzip, deflate';
+
+function _compressedFormat(b) {
+  if (b[0] === 0x1f && b[1] === 0x8b) return 'gzip';
+  if (b[0] === 0x78 && (b[1] === 0x01 || b[1] === 0x9c || b[1] === 0xda)) return 'deflate';
+  return null;
+}
+
+async function decodedResponse(r) {
+  if (!r.body || r.status === 204 || r.status === 304 || typeof DecompressionStream === 'undefined') return r;
+  var bytes = new Uint8Array(await r.arrayBuffer());
+  var format = _compressedFormat(bytes);
+  var body = format ? new Blob([bytes]).stream().pipeThrough(new DecompressionStream(format)) : bytes;
+  return new Response(body, { status: r.status, statusText: r.statusText, headers: r.headers });
+}
+
 let _refreshing = null;
 export async function tryRefreshToken() {
   if (_refreshing) return _refreshing;
-  _refreshing = fetch('/auth/refresh', { method: 'POST', credentials: 'same-origin', headers: { 'Content-Type': 'application/json', 'X-CSRF-Token': getCsrf() } })
+  const base = activeBackend();
+  _refreshing = fetch(base + '/auth/refresh', { method: 'POST', credentials: base ? 'include' : 'same-origin', headers: { 'Content-Type': 'application/json', 'X-CSRF-Token': getCsrf() } })
     .then(async r => { _refreshing = null; if (!r.ok) throw new Error('refresh failed'); return r.json(); })
     .catch(e => { _refreshing = null; throw e; });
   return _refreshing;
 }
 
 export function apiCall(path, opts = {}) {
-  const headers = { 'Content-Type': 'application/json', 'X-CSRF-Token': getCsrf() };
+  const headers = { 'Content-Type': 'application/json', 'Accept-Encoding': ACCEPT_ENCODING, 'X-CSRF-Token': getCsrf(), ...traceHeaders(), ...actionHeaders() };
   const apiKey = localStorage.getItem('em_api_key');
   if (apiKey) headers['X-API-Key'] = apiKey;
-  const url = '/api' + (path.startsWith('/') ? '' : '/') + path;
+  const base = activeBackend();
+  const url = base + '/api' + (path.startsWith('/') ? '' : '/') + path;
+  const method = (opts.method || 'GET').toUpperCase();
 
   const doFetch = async () => {
     // Wait for transport encryption to be ready if enabled
@@ -68,7 +167,7 @@ export function apiCall(path, opts = {}) {
     const fetchHeaders = { ...headers, ...opts.headers };
     if (sensitive) fetchHeaders['x-transport-encryption'] = '1';
 
-    let fetchOpts = { ...opts, credentials: 'same-origin', headers: fetchHeaders };
+    let fetchOpts = { ...opts, credentials: base ? 'include' : 'same-origin', headers: fetchHeaders };
 
     // Encrypt request body for sensitive endpoints
     if (sensitive && fetchOpts.body && (fetchOpts.method === 'PUT' || fetchOpts.method === 'POST' || fetchOpts.method === 'PATCH')) {
@@ -79,13 +178,28 @@ export function apiCall(path, opts = {}) {
       } catch {}
     }
 
-    const r = await fetch(url, fetchOpts);
+    // Failover: on an unreachable backend, switch to the next healthy one. Only
+    // idempotent reads are replayed — a write may have landed before the failure.
+    let r;
+    try {
+      r = await fetch(url, fetchOpts);
+    } catch (e) {
+      if (failoverEnabled() && markBackendDown(base) && method === 'GET' && (opts._failovers || 0) < 2) {
+        return apiCall(path, { ...opts, _failovers: (opts._failovers || 0) + 1 });
+      }
+      throw e;
+    }
+    if (failoverEnabled() && (r.status === 502 || r.status === 503 || r.status === 504)) {
+      if (markBackendDown(base) && method === 'GET' && (opts._failovers || 0) < 2) {
+        return apiCall(path, { ...opts, _failovers: (opts._failovers || 0) + 1 });
+      }
+    }
     if (r.status === 401 && !opts._retried) {
       try { await tryRefreshToken(); return apiCall(path, { ...opts, _retried: true }); }
       catch { if (window.__emLogout && !window.__suppressLogout) window.__emLogout(); throw new Error('Session expired'); }
     }
 
-    const d = await r.json().catch(() => ({}));
+    const d = await (await decodedResponse(r)).json().catch(() => ({}));
 
     // Decrypt response if it contains encrypted payload
     if (d && d._enc && typeof d._enc === 'string') {
@@ -106,15 +220,96 @@ export function apiCall(path, opts = {}) {
     return d;
   };
 
-  return doFetch();
+  const settled = opts._untimed ? null : _trackRender();
+  const result = doFetch();
+  if (settled) result.then(settled, settled);
+  return result;
+}
+// ─── Batched Reads ───────────────────────────────────────
+// batchGet(path) behaves like apiCall(path) for GETs, but calls issued in the
+// same tick (e.g. one per table row) are coalesced into a single POST /batch.
+// Falls back to parallel single calls if the batch request fails.
+var BATCH_MAX = 25;
+var _batchQueue = [];
+var _batchScheduled = false;
+var _batchSupported = true;
+
+export function batchGet(path) {
+  if (!_batchSupported) return apiCall(path);
+  return new Promise(function(resolve, reject) {
+    _batchQueue.push({ path: path, resolve: resolve, reject: reject });
+    if (!_batchScheduled) { _batchScheduled = true; setTimeout(_flushBatch, 0); }
+  });
 }
+export function batchEngineGet(path) { return batchGet('/engine' + (path.startsWith('/') ? '' : '/') + path); }
+
+function _runSingle(q) { apiCall(q.path).then(q.resolve, q.reject); }
+
+function _flushBatch() {
+  _batchScheduled = false;
+  var queue = _batchQueue.splice(0);
+  for (var i = 0; i < queue.length; i += BATCH_MAX) {
+    var chunk = queue.slice(i, i + BATCH_MAX);
+    if (chunk.length === 1) { _runSingle(chunk[0]); continue; }
+    (function(chunk) {
+      apiCall('/batch', { method: 'POST', body: JSON.stringify({ requests: chunk.map(function(q, idx) { return { id: String(idx), path: q.path }; }) }) })
+        .then(function(d) {
+          (d.responses || []).forEach(function(r) {
+            var q = chunk[parseInt(r.id, 10)];
+            if (!q) return;
+            if (r.status >= 200 && r.status < 300) q.resolve(r.body);
+            else q.reject(new Error((r.body && r.body.error) || 'HTTP ' + r.status));
+          });
+        })
+        .catch(function(e) {
+          if (e.message === 'Not found') _batchSupported = false; // older server without /batch
+          chunk.forEach(_runSingle);
+        });
+    })(chunk);
+  }
+}
+
 export function authCall(path, opts = {}) {
   const headers = { 'Content-Type': 'application/json', 'X-CSRF-Token': getCsrf() };
-  return fetch('/auth' + (path.startsWith('/') ? '' : '/') + path, { ...opts, credentials: 'same-origin', headers: { ...headers, ...opts.headers } })
+  const base = activeBackend();
+  return fetch(base + '/auth' + (path.startsWith('/') ? '' : '/') + path, { ...opts, credentials: base ? 'include' : 'same-origin', headers: { ...headers, ...opts.headers } })
     .then(async r => { const d = await r.json().catch(() => ({})); if (!r.ok) throw new Error(d.error || r.statusText); return d; });
 }
 export function engineCall(path, opts = {}) { return apiCall('/engine' + (path.startsWith('/') ? '' : '/') + path, opts); }
 
+/**
+ * Download a list endpoint's ?format=csv export. `path` is relative to /api,
+ * with the same filters the table is showing; the server streams every page.
+ */
+export function downloadCsv(path) { return downloadExport(path, 'csv'); }
+
+/** Download an endpoint's export in `format` (csv, pdf, ...) under the server's file name. */
+export async function downloadExport(path, format) {
+  const headers = { 'Accept-Encoding': ACCEPT_ENCODING, 'X-CSRF-Token': getCsrf(), ...traceHeaders() };
+  const apiKey = localStorage.getItem('em_api_key');
+  if (apiKey) headers['X-API-Key'] = apiKey;
+  const base = activeBackend();
+  const url = base + '/api' + (path.startsWith('/') ? '' : '/') + path + (format ? (path.includes('?') ? '&' : '?') + 'format=' + format : '');
+  const r = await fetch(url, { credentials: base ? 'include' : 'same-origin', headers });
+  if (!r.ok) { const d = await r.json().catch(() => ({})); throw new Error(d.error || r.statusText); }
+  const name = (r.headers.get('Content-Disposition') || '').match(/filename="([^"]+)"/);
+  const a = document.createElement('a');
+  // Text exports only; a PDF or zip is handed over exactly as sent
+  a.href = URL.createObjectURL(await (['csv', 'jsonl', 'json'].includes(format) ? await decodedResponse(r) : r).blob());
+  a.download = name ? name[1] : 'export.' + (format || 'dat');
+  document.body.appendChild(a);
+  a.click();
+  a.remove();
+  setTimeout(() => URL.revokeObjectURL(a.href), 1000);
+}
+
+/** Open the current page in print view (?print=1): no sidebar or chrome, tables laid out for paper. */
+export function openPrintView() {
+  const url = new URL(window.location.href);
+  url.searchParams.set('print', '1');
+  window.open(url.toString(), '_blank', 'noopener');
+}
+
 export function formatUptime(seconds) {
   if (!seconds || seconds < 0) return '-';
   var d = Math.floor(seconds / 86400);
@@ -130,6 +325,33 @@ export var DEPLOY_PHASE_LABELS = { validate: 'Validate Config', provision: 'Prov
 
 export async function showConfirm(opts) { var o = typeof opts === 'string' ? { message: opts } : opts; return window.__showConfirm ? window.__showConfirm(o) : confirm(o.message || o); }
 
+// Flash messages — a toast that survives a reload or redirect. Call flash()
+// instead of toast() right before window.location.reload(); the layout shows
+// queued messages on its next load. The server queues its own (SSO sign-in)
+// in the em_flash cookie, see src/lib/flash.ts.
+export function flash(message, type) {
+  try {
+    var queued = JSON.parse(sessionStorage.getItem('em_flash') || '[]');
+    queued.push({ message: String(message), type: type || 'info' });
+    sessionStorage.setItem('em_flash', JSON.stringify(queued.slice(-5)));
+  } catch (e) { /* storage unavailable */ }
+}
+
+/** Queued flash messages from this tab and the server, cleared once read. */
+export function takeFlash() {
+  var out = [];
+  try {
+    out = JSON.parse(sessionStorage.getItem('em_flash') || '[]');
+    sessionStorage.removeItem('em_flash');
+  } catch (e) { /* storage unavailable */ }
+  var m = document.cookie.match(/(?:^|;\s*)em_flash=([^;]+)/);
+  if (m) {
+    try { out = out.concat(JSON.parse(decodeURIComponent(m[1]))); } catch (e) { /* malformed */ }
+    document.cookie = 'em_flash=; Max-Age=0; Path=/; SameSite=Lax';
+  }
+  return out.filter(function(f) { return f && f.message; });
+}
+
 var _uuidRe = /^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$/i;
 
 export function buildAgentEmailMap(agents) {
@@ -153,7 +375,8 @@ export function buildAgentDataMap(agents) {
     const name = identity.name || a.config?.displayName || a.config?.name || a.name || null;
     const rawEmail = identity.email || a.config?.email?.address || a.config?.email || a.email || null;
     const email = (rawEmail && !_uuidRe.test(rawEmail)) ? rawEmail : null;
-    const avatar = identity.avatar || null;
+    // Admin rows carry the avatar URL at the top level
+    const avatar = identity.avatar || a.avatar || null;
     map[a.id] = { name, email, avatar };
   });
   return map;
@@ -194,8 +417,8 @@ export function renderAgentBadge(id, agentDataMap) {
   var displayName = data.name || 'Agent';
   var displayEmail = data.email || '';
   return h('div', { style: { display: 'flex', alignItems: 'center', gap: 8 } },
-    data.avatar
-      ? h('img', { src: data.avatar, style: _badgeAvatarStyle })
+    data.avatar && data.avatar.length > 2
+      ? h('img', { src: data.avatar, alt: '', style: _badgeAvatarStyle })
       : h('div', { style: _badgeInitialStyle }, displayName.charAt(0).toUpperCase()),
     h('div', { style: { lineHeight: 1.3 } },
       h('div', { style: { fontWeight: 500, fontSize: 13 } }, displayName),
@@ -3,9 +3,18 @@ import { DetailModal } from '../components/modal.js';
 import { HelpButton } from '../components/help-button.js';
 import { KnowledgeLink } from '../components/knowledge-link.js';
 import { useOrgContext } from '../components/org-switcher.js';
+import { SavedViews } from '../components/saved-views.js';
 
 const PAGE_SIZE = 25;
 
+// Relative so a saved "last 24h" view always means the last 24 hours
+const RANGES = [{ value: '1h', label: 'Last hour', ms: 3600000 }, { value: '24h', label: 'Last 24 hours', ms: 86400000 }, { value: '7d', label: 'Last 7 days', ms: 7 * 86400000 }, { value: '30d', label: 'Last 30 days', ms: 30 * 86400000 }];
+
+function sinceFor(range) {
+  const r = RANGES.find(x => x.value === range);
+  return r ? new Date(Date.now() - r.ms).toISOString() : '';
+}
+
 export function ActivityPage() {
   const orgCtx = useOrgContext();
   const effectiveOrgId = orgCtx.selectedOrgId || getOrgId();
@@ -19,6 +28,7 @@ export function ActivityPage() {
   const [eventsSearch, setEventsSearch] = useState('');
   const [eventsAgent, setEventsAgent] = useState('');
   const [eventsType, setEventsType] = useState('');
+  const [eventsRange, setEventsRange] = useState('');
   const [eventsLoading, setEventsLoading] = useState(false);
 
   // Tool calls state
@@ -27,6 +37,8 @@ export function ActivityPage() {
   const [toolsPage, setToolsPage] = useState(0);
   const [toolsSearch, setToolsSearch] = useState('');
   const [toolsAgent, setToolsAgent] = useState('');
+  const [toolsStatus, setToolsStatus] = useState('');
+  const [toolsRange, setToolsRange] = useState('');
   const [toolsLoading, setToolsLoading] = useState(false);
 
   // Event types for filter
@@ -51,16 +63,17 @@ export function ActivityPage() {
     if (eventsSearch) params.set('search', eventsSearch);
     if (eventsAgent) params.set('agentId', eventsAgent);
     if (eventsType) params.set('type', eventsType);
+    if (eventsRange) params.set('since', sinceFor(eventsRange));
     engineCall('/activity/events?' + params).then(d => {
       setEvents(d.events || []);
       setEventsTotal(d.total || 0);
       // Collect unique types for filter dropdown
-      if (!eventsType && eventsPage === 0 && !eventsSearch) {
+      if (!eventsType && eventsPage === 0 && !eventsSearch && !eventsRange) {
         const types = [...new Set((d.events || []).map(e => e.type).filter(Boolean))];
         if (types.length > eventTypes.length) setEventTypes(types);
       }
     }).catch(() => {}).finally(() => setEventsLoading(false));
-  }, [eventsPage, eventsSearch, eventsAgent, eventsType, effectiveOrgId]);
+  }, [eventsPage, eventsSearch, eventsAgent, eventsType, eventsRange, effectiveOrgId]);
 
   // Fetch tool calls
   const fetchTools = useCallback(() => {
@@ -72,11 +85,13 @@ export function ActivityPage() {
     });
     if (toolsSearch) params.set('search', toolsSearch);
     if (toolsAgent) params.set('agentId', toolsAgent);
+    if (toolsStatus) params.set('status', toolsStatus);
+    if (toolsRange) params.set('since', sinceFor(toolsRange));
     engineCall('/activity/tool-calls?' + params).then(d => {
       setToolCalls(d.toolCalls || []);
       setToolsTotal(d.total || 0);
     }).catch(() => {}).finally(() => setToolsLoading(false));
-  }, [toolsPage, toolsSearch, toolsAgent, effectiveOrgId]);
+  }, [toolsPage, toolsSearch, toolsAgent, toolsStatus, toolsRange, effectiveOrgId]);
 
   useEffect(() => { fetchEvents(); }, [fetchEvents]);
   useEffect(() => { fetchTools(); }, [fetchTools]);
@@ -111,9 +126,9 @@ export function ActivityPage() {
       h('p', { style: { color: 'var(--text-muted)', fontSize: 13 } }, 'Real-time activity and tool usage across all agents')
     ),
 
-    h('div', { className: 'tabs' },
-      h('div', { className: 'tab' + (tab === 'events' ? ' active' : ''), onClick: () => setTab('events') }, 'Events'),
-      h('div', { className: 'tab' + (tab === 'tools' ? ' active' : ''), onClick: () => setTab('tools') }, 'Tool Calls')
+    h('div', { className: 'tabs', role: 'tablist', 'aria-label': 'Activity views' },
+      h('button', { type: 'button', role: 'tab', 'aria-selected': tab === 'events', className: 'tab' + (tab === 'events' ? ' active' : ''), onClick: () => setTab('events') }, 'Events'),
+      h('button', { type: 'button', role: 'tab', 'aria-selected': tab === 'tools', className: 'tab' + (tab === 'tools' ? ' active' : ''), onClick: () => setTab('tools') }, 'Tool Calls')
     ),
 
     // ─── Events Tab ───
@@ -133,6 +148,13 @@ export function ActivityPage() {
           h('option', { value: '' }, 'All types'),
           ...eventTypes.map(t => h('option', { key: t, value: t }, t))
         ),
+        range: eventsRange,
+        onRangeChange: v => { setEventsRange(v); setEventsPage(0); },
+        views: h(SavedViews, {
+          viewsKey: 'activity.events',
+          values: { search: eventsSearch, agentId: eventsAgent, type: eventsType, range: eventsRange },
+          onApply: v => { setEventsSearch(v.search || ''); setEventsAgent(v.agentId || ''); setEventsType(v.type || ''); setEventsRange(v.range || ''); setEventsPage(0); },
+        }),
       }),
 
       h('div', { className: 'card', style: { position: 'relative' } },
@@ -148,7 +170,7 @@ export function ActivityPage() {
                   h('th', null, 'Details'),
                 )),
                 h('tbody', null, events.map((ev, i) =>
-                  h('tr', { key: i, onClick: () => setSelected({ kind: 'event', item: ev }), style: { cursor: 'pointer' }, title: 'Click to view details' },
+                  h('tr', Object.assign({ key: i, title: 'Click to view details' }, rowOpener(() => setSelected({ kind: 'event', item: ev }))),
                     h('td', { style: cellTime() }, formatTime(ev.timestamp)),
                     h('td', null, h('span', { className: 'badge badge-info' }, ev.type)),
                     h('td', null, renderAgentBadge(ev.agentId, agentData)),
@@ -170,6 +192,22 @@ export function ActivityPage() {
         agents, agentData,
         selectedAgent: toolsAgent,
         onAgentChange: v => { setToolsAgent(v); setToolsPage(0); },
+        extraFilter: h('select', {
+          value: toolsStatus,
+          onChange: e => { setToolsStatus(e.target.value); setToolsPage(0); },
+          style: selectStyle(),
+        },
+          h('option', { value: '' }, 'Any status'),
+          h('option', { value: 'success' }, 'Succeeded'),
+          h('option', { value: 'failed' }, 'Failed')
+        ),
+        range: toolsRange,
+        onRangeChange: v => { setToolsRange(v); setToolsPage(0); },
+        views: h(SavedViews, {
+          viewsKey: 'activity.tools',
+          values: { search: toolsSearch, agentId: toolsAgent, status: toolsStatus, range: toolsRange },
+          onApply: v => { setToolsSearch(v.search || ''); setToolsAgent(v.agentId || ''); setToolsStatus(v.status || ''); setToolsRange(v.range || ''); setToolsPage(0); },
+        }),
       }),
 
       h('div', { className: 'card', style: { position: 'relative' } },
@@ -186,7 +224,7 @@ export function ActivityPage() {
                   h('th', null, 'Status'),
                 )),
                 h('tbody', null, toolCalls.map((tc, i) =>
-                  h('tr', { key: i, onClick: () => setSelected({ kind: 'tool', item: tc }), style: { cursor: 'pointer' }, title: 'Click to view details' },
+                  h('tr', Object.assign({ key: i, title: 'Click to view details' }, rowOpener(() => setSelected({ kind: 'tool', item: tc }))),
                     h('td', { style: cellTime() }, formatTime(tc.timestamp || tc.timing?.startedAt)),
                     h('td', null, h('span', { style: { fontFamily: 'var(--font-mono)', fontSize: 12 } }, tc.tool || tc.toolId)),
                     h('td', null, renderAgentBadge(tc.agentId, agentData)),
@@ -240,10 +278,21 @@ export function ActivityPage() {
 
 // ─── Sub-components ───
 
-function FilterBar({ search, onSearch, searchPlaceholder, agents, agentData, selectedAgent, onAgentChange, extraFilter }) {
+/** Row props that open the detail modal on click, Enter or Space; focus returns to the row when it closes */
+function rowOpener(open) {
+  return {
+    onClick: open, tabIndex: 0, style: { cursor: 'pointer' },
+    onKeyDown: e => { if (e.key === 'Enter' || e.key === ' ') { e.preventDefault(); open(); } },
+  };
+}
+
+function FilterBar({ search, onSearch, searchPlaceholder, agents, agentData, selectedAgent, onAgentChange, extraFilter, range, onRangeChange, views }) {
   const [searchInput, setSearchInput] = useState(search);
   const debounceRef = { current: null };
 
+  // Follow saved views being applied
+  useEffect(() => { setSearchInput(search); }, [search]);
+
   const handleSearch = (v) => {
     setSearchInput(v);
     clearTimeout(debounceRef.current);
@@ -254,6 +303,7 @@ function FilterBar({ search, onSearch, searchPlaceholder, agents, agentData, sel
     h('input', {
       type: 'text',
       placeholder: searchPlaceholder,
+      'aria-label': searchPlaceholder,
       value: searchInput,
       onInput: e => handleSearch(e.target.value),
       style: {
@@ -263,6 +313,7 @@ function FilterBar({ search, onSearch, searchPlaceholder, agents, agentData, sel
       },
     }),
     h('select', {
+      'aria-label': 'Agent',
       value: selectedAgent,
       onChange: e => onAgentChange(e.target.value),
       style: selectStyle(),
@@ -271,6 +322,16 @@ function FilterBar({ search, onSearch, searchPlaceholder, agents, agentData, sel
       ...agents.map(a => h('option', { key: a.id, value: a.id }, a.config?.identity?.name || a.config?.displayName || a.config?.name || a.name || a.id))
     ),
     extraFilter || null,
+    onRangeChange && h('select', {
+      'aria-label': 'Time range',
+      value: range || '',
+      onChange: e => onRangeChange(e.target.value),
+      style: selectStyle(),
+    },
+      h('option', { value: '' }, 'Any time'),
+      ...RANGES.map(r => h('option', { key: r.value, value: r.value }, r.label))
+    ),
+    views || null,
   );
 }
 
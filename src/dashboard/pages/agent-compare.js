@@ -0,0 +1,221 @@
+import { h, useState, useEffect, Fragment, useApp, engineCall, apiCall } from '../components/utils.js';
+import { I } from '../components/icons.js';
+import { HelpButton } from '../components/help-button.js';
+import { useOrgContext } from '../components/org-switcher.js';
+import { formatNumber, formatCost } from './agent-detail/shared.js?v=5';
+
+// ─── Agent Compare ───────────────────────────────────────
+// /dashboard/agents/compare?ids=a,b,c — agents side by side, one column each,
+// so drift between agents that should be configured alike stands out.
+
+var MAX_AGENTS = 6;
+var USAGE_DAYS = 30;
+
+// Settings that are meant to differ per agent, so they'd only be noise
+var SKIP_CONFIG = /^(id|name|displayName|description|createdAt|updatedAt|roleTransition|email\.address|identity\.(name|avatar|dateOfBirth|age|ageRange|gender|maritalStatus|culturalBackground|description))(\.|$)/;
+var SKIP_COMMON = /(^|\.)(id|agentId|orgId|createdAt|updatedAt)$/;
+var SECRET = /(password|secret|token|apikey|privatekey)$/i;
+
+function parseIds() {
+  var raw = new URLSearchParams(window.location.search).get('ids') || '';
+  return Array.from(new Set(raw.split(',').map(s => s.trim()).filter(Boolean))).slice(0, MAX_AGENTS);
+}
+
+function openAgent(id) {
+  history.pushState(null, '', '/dashboard/agents/' + id);
+  window.dispatchEvent(new PopStateEvent('popstate'));
+}
+
+// Nested objects become dotted paths; arrays and anything deeper stay whole
+function flatten(obj, skip, prefix, out, depth) {
+  out = out || {};
+  if (!obj || typeof obj !== 'object') return out;
+  Object.keys(obj).forEach(k => {
+    var path = prefix ? prefix + '.' + k : k;
+    if (SKIP_COMMON.test(path) || (skip && skip.test(path))) return;
+    var v = obj[k];
+    if (v && typeof v === 'object' && !Array.isArray(v) && (depth || 0) < 4 && Object.keys(v).length) flatten(v, skip, path, out, (depth || 0) + 1);
+    else out[path] = v;
+  });
+  return out;
+}
+
+// Order-insensitive for lists of plain values, so ['a','b'] matches ['b','a']
+function canonical(v) {
+  if (v === undefined || v === null || v === '') return '';
+  if (Array.isArray(v)) return JSON.stringify(v.every(x => typeof x !== 'object') ? v.slice().sort() : v);
+  return typeof v === 'object' ? JSON.stringify(v) : String(v);
+}
+
+function display(path, v) {
+  if (v === undefined || v === null || v === '') return h('span', { style: { color: 'var(--text-muted)' } }, '—');
+  if (SECRET.test(path)) return h('span', { style: { color: 'var(--text-muted)' } }, '(set)');
+  if (typeof v === 'boolean') return v ? 'Yes' : 'No';
+  var text = Array.isArray(v) && v.every(x => typeof x !== 'object') ? v.slice().sort().join(', ') || '(none)' : typeof v === 'object' ? JSON.stringify(v) : String(v);
+  return text.length > 120 ? h('span', { title: text }, text.slice(0, 120) + '…') : text;
+}
+
+function loadAgent(id) {
+  var soft = p => p.catch(() => null);
+  return Promise.all([
+    soft(engineCall('/bridge/agents/' + id + '/full')),
+    soft(apiCall('/agents/' + id)),
+    soft(engineCall('/agents/' + id + '/tool-security')),
+    soft(apiCall('/agents/' + id + '/usage-report?days=' + USAGE_DAYS)),
+    soft(engineCall('/teams/agent/' + id)),
+  ]).then(([full, admin, toolSec, usage, teams]) => {
+    if (!full && !admin) return { id, missing: true };
+    var engineAgent = full && full.agent || {};
+    var config = engineAgent.config || {};
+    return {
+      id,
+      name: (admin && admin.name) || config.displayName || config.name || id,
+      email: (admin && admin.email) || (config.email && config.email.address) || '',
+      overview: {
+        Status: admin && admin.status,
+        State: engineAgent.state,
+        Role: (config.identity && config.identity.role) || (admin && admin.role),
+        Teams: teams && teams.teams ? teams.teams.map(t => t.name) : undefined,
+        'Available tools': full && full.availableTools,
+      },
+      config: flatten(config, SKIP_CONFIG),
+      permissions: flatten(full && full.permissions),
+      toolSecurity: flatten(toolSec && toolSec.toolSecurity),
+      usage: usage,
+    };
+  });
+}
+
+export function AgentComparePage() {
+  var { toast, setPage } = useApp();
+  var orgCtx = useOrgContext();
+  const [ids, setIds] = useState(parseIds);
+  const [data, setData] = useState({});
+  const [allAgents, setAllAgents] = useState([]);
+  const [onlyDiffs, setOnlyDiffs] = useState(true);
+  const [adding, setAdding] = useState('');
+
+  useEffect(() => {
+    apiCall('/agents?limit=200' + (orgCtx.selectedOrgId ? '&clientOrgId=' + orgCtx.selectedOrgId : '')).then(d => setAllAgents(d.agents || [])).catch(() => {});
+  }, [orgCtx.selectedOrgId]);
+
+  useEffect(() => {
+    history.replaceState(null, '', '/dashboard/agents/compare' + (ids.length ? '?ids=' + ids.map(encodeURIComponent).join(',') : ''));
+    var missing = ids.filter(id => !data[id]);
+    if (!missing.length) return;
+    Promise.all(missing.map(loadAgent))
+      .then(rows => setData(d => { var next = Object.assign({}, d); rows.forEach(r => { next[r.id] = r; }); return next; }))
+      .catch(err => toast('Failed to load agents: ' + err.message, 'error'));
+  }, [ids.join(',')]);
+
+  var reload = () => {
+    setData({});
+    Promise.all(ids.map(loadAgent))
+      .then(rows => { var next = {}; rows.forEach(r => { next[r.id] = r; }); setData(next); })
+      .catch(err => toast('Failed to load agents: ' + err.message, 'error'));
+  };
+
+  var cols = ids.map(id => data[id]).filter(Boolean);
+  var loading = cols.length < ids.length;
+
+  // One section per source; a row is a setting and differs when any two agents disagree
+  var section = (key, title) => {
+    var paths = Array.from(new Set([].concat.apply([], cols.filter(c => !c.missing).map(c => Object.keys(c[key] || {}))))).sort();
+    var rows = paths.map(path => {
+      var values = cols.map(c => c.missing ? undefined : c[key][path]);
+      return { path, values, differs: new Set(values.filter((v, i) => !cols[i].missing).map(canonical)).size > 1 };
+    });
+    return { key, title, rows, diffs: rows.filter(r => r.differs).length };
+  };
+  var sections = cols.length ? [
+    section('overview', 'Overview'),
+    section('config', 'Configuration'),
+    section('permissions', 'Permissions'),
+    section('toolSecurity', 'Tool Security'),
+  ] : [];
+  var totalDiffs = sections.reduce((n, s) => n + s.diffs, 0);
+
+  // Usage is measured, not configured, so it's always shown and never counted as drift
+  var usageRows = [
+    { label: 'Input tokens (' + USAGE_DAYS + 'd)', value: u => formatNumber(u.totals.inputTokens) },
+    { label: 'Output tokens (' + USAGE_DAYS + 'd)', value: u => formatNumber(u.totals.outputTokens) },
+    { label: 'Cost (' + USAGE_DAYS + 'd)', value: u => formatCost(u.totals.costUsd) + (u.estimated ? ' (est.)' : '') },
+    { label: 'Active days', value: u => u.series.filter(d => d.inputTokens + d.outputTokens > 0).length + ' / ' + u.days },
+  ];
+
+  var remove = id => setIds(ids.filter(x => x !== id));
+  var add = id => { if (id && !ids.includes(id)) setIds(ids.concat([id])); setAdding(''); };
+
+  var _cell = { padding: '6px 10px', borderBottom: '1px solid var(--border)', fontSize: 12, verticalAlign: 'top', wordBreak: 'break-word' };
+  var _label = Object.assign({}, _cell, { fontFamily: 'var(--font-mono)', color: 'var(--text-secondary)', position: 'sticky', left: 0, background: 'var(--bg-primary)', minWidth: 220 });
+  var _diff = { background: 'var(--warning-soft, rgba(217,119,6,0.1))' };
+
+  return h(Fragment, null,
+    h(orgCtx.Switcher),
+    h('div', { style: { display: 'flex', justifyContent: 'space-between', alignItems: 'center', marginBottom: 20, gap: 12, flexWrap: 'wrap' } },
+      h('div', null,
+        h('h1', { style: { fontSize: 20, fontWeight: 700, display: 'flex', alignItems: 'center' } }, 'Compare Agents', h(HelpButton, { label: 'Compare Agents' },
+          h('p', null, 'Agents side by side: configuration, permission profile, tool security and the last ' + USAGE_DAYS + ' days of usage.'),
+          h('p', null, 'Rows where the agents disagree are highlighted. Keep "Only differences" on to see just the drift between agents that should be set up the same way.'),
+          h('p', null, 'Names, email addresses, personal details and ids are left out because they are expected to differ. Secrets show only whether they are set.')
+        )),
+        h('p', { style: { color: 'var(--text-muted)', fontSize: 13 } },
+          h('a', { href: '/dashboard/agents', onClick: e => { e.preventDefault(); setPage('agents'); } }, 'Agents'), ' / Compare',
+          cols.length > 1 && !loading && (' · ' + (totalDiffs === 0 ? 'no differences' : totalDiffs + ' difference' + (totalDiffs === 1 ? '' : 's'))))
+      ),
+      h('div', { style: { display: 'flex', gap: 8, alignItems: 'center' } },
+        h('label', { style: { display: 'flex', alignItems: 'center', gap: 6, fontSize: 13 } },
+          h('input', { type: 'checkbox', checked: onlyDiffs, onChange: e => setOnlyDiffs(e.target.checked) }), 'Only differences'),
+        ids.length < MAX_AGENTS && h('select', { className: 'input', value: adding, onChange: e => add(e.target.value), style: { width: 200 } },
+          h('option', { value: '' }, 'Add agent...'),
+          allAgents.filter(a => !ids.includes(a.id)).map(a => h('option', { key: a.id, value: a.id }, a.name))),
+        h('button', { className: 'btn btn-secondary btn-sm', onClick: reload, disabled: loading || !ids.length }, I.refresh(), ' Refresh')
+      )
+    ),
+
+    ids.length < 2
+      ? h('div', { className: 'card' }, h('div', { className: 'card-body' },
+          h('div', { className: 'empty-state' },
+            I.agents(),
+            h('h3', null, 'Pick agents to compare'),
+            h('p', null, 'Add at least two agents with the picker above, or select them on the Agents list and choose Compare. Up to ' + MAX_AGENTS + ' at a time.'))))
+    : loading
+      ? h('div', { className: 'card' }, h('div', { style: { padding: 24, textAlign: 'center', color: 'var(--text-muted)' } }, 'Loading...'))
+    : h('div', { className: 'card' },
+        h('div', { className: 'card-body-flush', style: { overflowX: 'auto' } },
+          h('table', { style: { width: '100%', borderCollapse: 'collapse' } },
+            h('thead', null, h('tr', null,
+              h('th', { style: Object.assign({}, _label, { fontFamily: 'inherit', textAlign: 'left' }) }, 'Setting'),
+              cols.map(c => h('th', { key: c.id, style: Object.assign({}, _cell, { textAlign: 'left', minWidth: 180 }) },
+                h('div', { style: { display: 'flex', alignItems: 'flex-start', gap: 6 } },
+                  h('div', { style: { flex: 1, minWidth: 0 } },
+                    c.missing
+                      ? h('span', { style: { color: 'var(--danger)' } }, c.id.slice(0, 12) + ' (not found)')
+                      : h('a', { href: '/dashboard/agents/' + c.id, onClick: e => { if (e.metaKey || e.ctrlKey) return; e.preventDefault(); openAgent(c.id); }, style: { fontWeight: 600, fontSize: 13 } }, c.name),
+                    c.email && h('div', { style: { fontSize: 11, color: 'var(--text-muted)', fontWeight: 400 } }, c.email)),
+                  h('button', { className: 'btn btn-ghost btn-sm', 'aria-label': 'Remove ' + (c.name || c.id), title: 'Remove from comparison', onClick: () => remove(c.id), style: { padding: '0 6px', minWidth: 0 } }, '×'))
+              ))
+            )),
+            h('tbody', null,
+              sections.map(s => {
+                var shown = onlyDiffs ? s.rows.filter(r => r.differs) : s.rows;
+                return h(Fragment, { key: s.key },
+                  h('tr', null, h('td', { colSpan: cols.length + 1, style: Object.assign({}, _cell, { fontWeight: 600, fontSize: 13, background: 'var(--bg-tertiary)' }) },
+                    s.title, ' ', h('span', { className: 'badge badge-' + (s.diffs ? 'warning' : 'success') }, s.diffs ? s.diffs + ' differ' : 'identical'))),
+                  shown.length === 0
+                    ? h('tr', null, h('td', { colSpan: cols.length + 1, style: Object.assign({}, _cell, { color: 'var(--text-muted)' }) }, s.rows.length ? 'All settings match' : 'Nothing to compare'))
+                    : shown.map(r => h('tr', { key: r.path, style: r.differs ? _diff : null },
+                        h('td', { style: Object.assign({}, _label, r.differs ? _diff : {}) }, r.path),
+                        r.values.map((v, i) => h('td', { key: cols[i].id, style: _cell }, display(r.path, v)))))
+                );
+              }),
+              h('tr', null, h('td', { colSpan: cols.length + 1, style: Object.assign({}, _cell, { fontWeight: 600, fontSize: 13, background: 'var(--bg-tertiary)' }) }, 'Usage')),
+              usageRows.map(row => h('tr', { key: row.label },
+                h('td', { style: Object.assign({}, _label, { fontFamily: 'inherit' }) }, row.label),
+                cols.map(c => h('td', { key: c.id, style: _cell }, c.usage && c.usage.totals ? row.value(c.usage) : h('span', { style: { color: 'var(--text-muted)' } }, '—')))))
+            )
+          )
+        )
+      )
+  );
+}
@@ -3,6 +3,7 @@ import { I } from '../../components/icons.js';
 import { E } from '../../assets/icons/emoji-icons.js';
 import { DetailModal } from '../../components/modal.js';
 import { HelpButton } from '../../components/help-button.js';
+import { useDeferred, SkeletonTable } from '../../components/fragments.js';
 
 // --- ActivitySection ------------------------------------------------
 
@@ -14,14 +15,13 @@ export function ActivitySection(props) {
   var _tab = useState('events');
   var activeTab = _tab[0]; var setActiveTab = _tab[1];
 
-  var _events = useState([]);
-  var events = _events[0]; var setEvents = _events[1];
-  var _toolCalls = useState([]);
-  var toolCalls = _toolCalls[0]; var setToolCalls = _toolCalls[1];
-  var _journal = useState([]);
-  var journalEntries = _journal[0]; var setJournalEntries = _journal[1];
-  var _loading = useState(false);
-  var loading = _loading[0]; var setLoading = _loading[1];
+  // Each table loads on its own, so a slow journal query doesn't hold up events
+  var eventsFrag = useDeferred(function() { return engineCall('/activity/events?agentId=' + agentId + '&limit=200'); }, [agentId]);
+  var toolCallsFrag = useDeferred(function() { return engineCall('/activity/tool-calls?agentId=' + agentId + '&limit=200'); }, [agentId]);
+  var journalFrag = useDeferred(function() { return engineCall('/journal?agentId=' + agentId + '&orgId=' + getOrgId() + '&limit=200'); }, [agentId]);
+  var events = (eventsFrag.data && eventsFrag.data.events) || [];
+  var toolCalls = (toolCallsFrag.data && toolCallsFrag.data.toolCalls) || [];
+  var journalEntries = (journalFrag.data && journalFrag.data.entries) || [];
   var _selectedItem = useState(null);
   var selectedItem = _selectedItem[0]; var setSelectedItem = _selectedItem[1];
 
@@ -40,50 +40,22 @@ export function ActivitySection(props) {
   var _page = useState(1);
   var page = _page[0]; var setPage = _page[1];
 
-  var loadEvents = function() {
-    engineCall('/activity/events?agentId=' + agentId + '&limit=200')
-      .then(function(d) { setEvents(d.events || []); })
-      .catch(function() {});
-  };
-  var loadToolCalls = function() {
-    engineCall('/activity/tool-calls?agentId=' + agentId + '&limit=200')
-      .then(function(d) { setToolCalls(d.toolCalls || []); })
-      .catch(function() {});
-  };
-  var loadJournal = function() {
-    engineCall('/journal?agentId=' + agentId + '&orgId=' + getOrgId() + '&limit=200')
-      .then(function(d) { setJournalEntries(d.entries || []); })
-      .catch(function() {});
-  };
-
-  var loadAll = function() {
-    setLoading(true);
-    Promise.all([
-      engineCall('/activity/events?agentId=' + agentId + '&limit=200').then(function(d) { setEvents(d.events || []); }).catch(function() {}),
-      engineCall('/activity/tool-calls?agentId=' + agentId + '&limit=200').then(function(d) { setToolCalls(d.toolCalls || []); }).catch(function() {}),
-      engineCall('/journal?agentId=' + agentId + '&orgId=' + getOrgId() + '&limit=200').then(function(d) { setJournalEntries(d.entries || []); }).catch(function() {}),
-    ]).then(function() { setLoading(false); }).catch(function() { setLoading(false); });
-  };
-
-  useEffect(loadAll, []);
-
   // Reset page when filters change
   useEffect(function() { setPage(1); }, [typeFilter, searchFilter, dateFrom, dateTo, activeTab]);
 
   var rollback = function(id) {
-    showConfirm({ title: 'Rollback Action', message: 'Reverse this journal entry?', warning: true, confirmText: 'Rollback' }).then(function(ok) {
+    showConfirm({ title: 'Rollback Action', message: 'Reverse this journal entry?', warning: 'The original action is undone against the live system. This can\'t be rolled forward again.', danger: true, confirmText: 'Rollback' }).then(function(ok) {
       if (!ok) return;
       engineCall('/journal/' + id + '/rollback', { method: 'POST', body: JSON.stringify({}) })
-        .then(function(r) { if (r.success) { toast('Rolled back', 'success'); loadJournal(); } else toast('Failed: ' + (r.error || ''), 'error'); })
+        .then(function(r) { if (r.success) { toast('Rolled back', 'success'); journalFrag.reload(); } else toast('Failed: ' + (r.error || ''), 'error'); })
         .catch(function(e) { toast(e.message, 'error'); });
     });
   };
 
-  var refreshCurrent = function() {
-    if (activeTab === 'events') loadEvents();
-    else if (activeTab === 'tools') loadToolCalls();
-    else if (activeTab === 'journal') loadJournal();
-  };
+  var currentFrag = activeTab === 'events' ? eventsFrag : activeTab === 'tools' ? toolCallsFrag : journalFrag;
+  var refreshCurrent = function() { currentFrag.reload(); };
+  var tabCount = function(frag, items) { return frag.loading ? '…' : items.length; };
+  var ready = !currentFrag.loading && !currentFrag.error;
 
   // Filter helper
   var filterItems = function(items) {
@@ -161,9 +133,9 @@ export function ActivitySection(props) {
     ),
     h('div', { style: { borderBottom: '1px solid var(--border)' } },
       h('div', { className: 'tabs', style: { padding: '0 16px' } },
-        h('div', { className: 'tab' + (activeTab === 'events' ? ' active' : ''), onClick: function() { setActiveTab('events'); } }, 'Events (' + events.length + ')'),
-        h('div', { className: 'tab' + (activeTab === 'tools' ? ' active' : ''), onClick: function() { setActiveTab('tools'); } }, 'Tool Calls (' + toolCalls.length + ')'),
-        h('div', { className: 'tab' + (activeTab === 'journal' ? ' active' : ''), onClick: function() { setActiveTab('journal'); } }, 'Journal (' + journalEntries.length + ')')
+        h('div', { className: 'tab' + (activeTab === 'events' ? ' active' : ''), onClick: function() { setActiveTab('events'); } }, 'Events (' + tabCount(eventsFrag, events) + ')'),
+        h('div', { className: 'tab' + (activeTab === 'tools' ? ' active' : ''), onClick: function() { setActiveTab('tools'); } }, 'Tool Calls (' + tabCount(toolCallsFrag, toolCalls) + ')'),
+        h('div', { className: 'tab' + (activeTab === 'journal' ? ' active' : ''), onClick: function() { setActiveTab('journal'); } }, 'Journal (' + tabCount(journalFrag, journalEntries) + ')')
       )
     ),
 
@@ -182,8 +154,11 @@ export function ActivitySection(props) {
 
     h('div', { className: 'card-body-flush' },
 
+      currentFrag.loading && h(SkeletonTable, { rows: 8, columns: activeTab === 'journal' ? 6 : activeTab === 'tools' ? 4 : 3, label: 'Loading activity' }),
+      !currentFrag.loading && currentFrag.error && h('div', { style: { padding: 24, textAlign: 'center', color: 'var(--danger)' } }, currentFrag.error),
+
       // Events Tab
-      activeTab === 'events' && (
+      ready && activeTab === 'events' && (
         paged.length === 0
           ? h('div', { style: { padding: 40, textAlign: 'center', color: 'var(--text-muted)' } }, filtered.length === 0 && events.length > 0 ? 'No events match filters' : 'No events recorded')
           : h('table', { className: 'data-table' },
@@ -204,7 +179,7 @@ export function ActivitySection(props) {
       ),
 
       // Tool Calls Tab
-      activeTab === 'tools' && (
+      ready && activeTab === 'tools' && (
         paged.length === 0
           ? h('div', { style: { padding: 40, textAlign: 'center', color: 'var(--text-muted)' } }, filtered.length === 0 && toolCalls.length > 0 ? 'No tool calls match filters' : 'No tool calls recorded')
           : h('table', { className: 'data-table' },
@@ -227,7 +202,7 @@ export function ActivitySection(props) {
       ),
 
       // Journal Tab
-      activeTab === 'journal' && (
+      ready && activeTab === 'journal' && (
         paged.length === 0
           ? h('div', { style: { padding: 40, textAlign: 'center', color: 'var(--text-muted)' } }, filtered.length === 0 && journalEntries.length > 0 ? 'No journal entries match filters' : 'No journal entries')
           : h('table', { className: 'data-table' },
@@ -1,4 +1,4 @@
-import { h, useState, useEffect, useCallback, Fragment, useApp, apiCall, engineCall, formatUptime, buildAgentDataMap, renderAgentBadge, showConfirm, getOrgId } from '../../components/utils.js';
+import { h, useState, useEffect, useCallback, Fragment, useApp, apiCall, batchGet, engineCall, formatUptime, buildAgentDataMap, renderAgentBadge, showConfirm, getOrgId } from '../../components/utils.js';
 import { I } from '../../components/icons.js';
 import { E } from '../../assets/icons/emoji-icons.js';
 import { HelpButton } from '../../components/help-button.js';
@@ -291,7 +291,7 @@ function ModelFallbackCard(props) {
   useEffect(function() {
     if (!configuredProviders.length) return;
     Promise.all(configuredProviders.map(function(p) {
-      return apiCall('/providers/' + p.id + '/models').then(function(d) {
+      return batchGet('/providers/' + p.id + '/models').then(function(d) {
         return (d.models || []).map(function(m) { return { id: p.id + '/' + (m.id || m.name), label: p.name + ' / ' + (m.name || m.id) }; });
       }).catch(function() { return []; });
     })).then(function(results) {
@@ -0,0 +1,149 @@
+import { h, useState, useEffect, Fragment, useApp, engineCall, downloadExport } from '../../components/utils.js';
+import { I } from '../../components/icons.js';
+import { EmptyState, formatNumber } from './shared.js?v=5';
+import { Pagination } from '../../components/pagination.js';
+import { RelativeTime } from '../../components/time.js';
+import { HelpButton } from '../../components/help-button.js';
+
+// ════════════════════════════════════════════════════════════
+// CONVERSATIONS SECTION
+// ════════════════════════════════════════════════════════════
+
+var PAGE_SIZE = 25;
+var STATUS_COLOR = { active: 'success', completed: 'neutral', failed: 'danger', paused: 'warning', resuming: 'info' };
+var ROLE_LABEL = { user: 'User', assistant: 'Agent', system: 'System' };
+
+export function ConversationsSection(props) {
+  var agentId = props.agentId;
+  var toast = useApp().toast;
+
+  var _list = useState({ conversations: [], total: 0 });
+  var list = _list[0]; var setList = _list[1];
+  var _page = useState(1);
+  var page = _page[0]; var setPage = _page[1];
+  var _loading = useState(true);
+  var loading = _loading[0]; var setLoading = _loading[1];
+  var _selected = useState(null);
+  var selected = _selected[0]; var setSelected = _selected[1];
+  var _transcript = useState(null);
+  var transcript = _transcript[0]; var setTranscript = _transcript[1];
+  var _loadingTranscript = useState(false);
+  var loadingTranscript = _loadingTranscript[0]; var setLoadingTranscript = _loadingTranscript[1];
+
+  var loadList = function() {
+    setLoading(true);
+    engineCall('/transcripts/agent/' + agentId + '?limit=' + PAGE_SIZE + '&offset=' + (page - 1) * PAGE_SIZE)
+      .then(function(d) {
+        setList(d);
+        if (!selected && d.conversations.length) setSelected(d.conversations[0].sessionId);
+      })
+      .catch(function(err) { toast('Failed to load conversations: ' + err.message, 'error'); })
+      .finally(function() { setLoading(false); });
+  };
+
+  useEffect(function() { setSelected(null); setPage(1); }, [agentId]);
+  useEffect(loadList, [agentId, page]);
+
+  useEffect(function() {
+    if (!selected) { setTranscript(null); return; }
+    setLoadingTranscript(true);
+    engineCall('/transcripts/' + selected)
+      .then(function(d) { setTranscript(d.transcript); })
+      .catch(function(err) { setTranscript(null); toast('Failed to load transcript: ' + err.message, 'error'); })
+      .finally(function() { setLoadingTranscript(false); });
+  }, [selected]);
+
+  var exportAs = function(format) {
+    downloadExport('/engine/transcripts/' + selected + '/export', format)
+      .catch(function(err) { toast('Export failed: ' + err.message, 'error'); });
+  };
+
+  var _muted = { fontSize: 12, color: 'var(--text-muted)' };
+
+  return h(Fragment, null,
+    h('div', { style: { display: 'flex', justifyContent: 'space-between', alignItems: 'center', marginBottom: 16 } },
+      h('h3', { style: { margin: 0, fontSize: 16, display: 'flex', alignItems: 'center' } }, 'Conversations', h(HelpButton, { label: 'Conversations' },
+        h('p', null, 'Every conversation this agent has had through the runtime, most recently active first. Select one to read the transcript.'),
+        h('p', null, 'Tool calls appear inline where the agent made them, with their input and the result the tool returned. Expand a call to see the details; failed calls are marked.'),
+        h('p', null, 'Export downloads the transcript as JSON (for tooling) or Markdown (for a ticket). Exports are recorded in the audit log.')
+      )),
+      h('button', { className: 'btn btn-secondary btn-sm', onClick: loadList, disabled: loading }, I.refresh(), ' Refresh')
+    ),
+
+    h('div', { style: { display: 'grid', gridTemplateColumns: 'minmax(260px, 1fr) 2fr', gap: 16, alignItems: 'start' } },
+      h('div', { className: 'card' },
+        h('div', { className: 'card-header' }, h('h3', null, formatNumber(list.total) + ' conversation' + (list.total === 1 ? '' : 's'))),
+        list.conversations.length === 0
+          ? h(EmptyState, { message: loading ? 'Loading...' : 'No conversations yet' })
+          : h('div', { style: { maxHeight: 640, overflowY: 'auto' } },
+              list.conversations.map(function(conv) {
+                var active = conv.sessionId === selected;
+                return h('div', {
+                  key: conv.sessionId, role: 'button', tabIndex: 0,
+                  onClick: function() { setSelected(conv.sessionId); },
+                  onKeyDown: function(e) { if (e.key === 'Enter') setSelected(conv.sessionId); },
+                  style: { padding: '10px 14px', cursor: 'pointer', borderBottom: '1px solid var(--border)', background: active ? 'var(--bg-tertiary)' : 'transparent', borderLeft: '3px solid ' + (active ? 'var(--primary)' : 'transparent') }
+                },
+                  h('div', { style: { fontSize: 13, overflow: 'hidden', textOverflow: 'ellipsis', whiteSpace: 'nowrap' }, title: conv.preview || '' }, conv.preview || h('span', { style: _muted }, 'No user message')),
+                  h('div', { style: Object.assign({ display: 'flex', gap: 8, alignItems: 'center', marginTop: 4 }, _muted) },
+                    h('span', { className: 'badge badge-' + (STATUS_COLOR[conv.status] || 'neutral') }, conv.status),
+                    h('span', null, conv.turnCount + ' turns'),
+                    h('span', { style: { marginLeft: 'auto' } }, h(RelativeTime, { value: conv.updatedAt }))));
+              })),
+        h(Pagination, { page: page, pageSize: PAGE_SIZE, total: list.total, onPage: setPage, style: { padding: '8px 12px' } })
+      ),
+
+      h('div', { className: 'card' },
+        !transcript
+          ? h(EmptyState, { message: loadingTranscript ? 'Loading transcript...' : 'Select a conversation' })
+          : h(Fragment, null,
+              h('div', { className: 'card-header', style: { display: 'flex', alignItems: 'center', gap: 8 } },
+                h('div', { style: { marginRight: 'auto', minWidth: 0 } },
+                  h('h3', { style: { margin: 0 } }, 'Transcript'),
+                  h('div', { style: Object.assign({ fontFamily: 'var(--font-mono)' }, _muted) }, transcript.sessionId),
+                  h('div', { style: _muted },
+                    new Date(transcript.createdAt).toLocaleString(), ' · ', transcript.turns.length, ' turns · ', formatNumber(transcript.tokenCount), ' tokens',
+                    transcript.parentSessionId && h('span', null, ' · continued from ', h('a', { href: '#', onClick: function(e) { e.preventDefault(); setSelected(transcript.parentSessionId); } }, transcript.parentSessionId.slice(0, 12))))),
+                h('button', { className: 'btn btn-secondary btn-sm', onClick: function() { exportAs('json'); } }, I.download(), ' JSON'),
+                h('button', { className: 'btn btn-secondary btn-sm', onClick: function() { exportAs('md'); } }, I.download(), ' Markdown')
+              ),
+              h('div', { className: 'card-body', style: { maxHeight: 640, overflowY: 'auto', opacity: loadingTranscript ? 0.6 : 1 } },
+                transcript.turns.length === 0
+                  ? h('div', { style: _muted }, 'This conversation has no messages')
+                  : transcript.turns.map(function(turn, i) { return h(TranscriptTurn, { key: i, turn: turn }); }))
+            )
+      )
+    )
+  );
+}
+
+function TranscriptTurn(props) {
+  var turn = props.turn;
+  var isAgent = turn.role === 'assistant';
+  return h('div', { style: { marginBottom: 16, paddingLeft: 12, borderLeft: '3px solid ' + (isAgent ? 'var(--primary)' : turn.role === 'system' ? 'var(--warning)' : 'var(--border)') } },
+    h('div', { style: { display: 'flex', gap: 8, alignItems: 'baseline', marginBottom: 4 } },
+      h('strong', { style: { fontSize: 13 } }, ROLE_LABEL[turn.role] || turn.role),
+      h('span', { style: { fontSize: 11, color: 'var(--text-muted)' } }, new Date(turn.timestamp).toLocaleTimeString())),
+    turn.thinking && h('details', { style: { marginBottom: 6 } },
+      h('summary', { style: { fontSize: 12, color: 'var(--text-muted)', cursor: 'pointer' } }, 'Reasoning'),
+      h('div', { style: { fontSize: 12, color: 'var(--text-muted)', whiteSpace: 'pre-wrap', fontStyle: 'italic', marginTop: 4 } }, turn.thinking)),
+    turn.text && h('div', { style: { fontSize: 13, whiteSpace: 'pre-wrap', wordBreak: 'break-word' } }, turn.text),
+    turn.toolCalls.map(function(call) { return h(ToolCallView, { key: call.id, call: call }); })
+  );
+}
+
+function ToolCallView(props) {
+  var call = props.call;
+  var _pre = { margin: '4px 0 0', padding: 8, background: 'var(--bg-tertiary)', borderRadius: 4, fontSize: 11, whiteSpace: 'pre-wrap', wordBreak: 'break-word', maxHeight: 300, overflow: 'auto' };
+  var state = call.result === undefined ? { label: 'no result', color: 'warning' } : call.isError ? { label: 'error', color: 'danger' } : { label: 'ok', color: 'success' };
+  return h('details', { style: { margin: '6px 0', border: '1px solid var(--border)', borderRadius: 6, padding: '6px 10px' } },
+    h('summary', { style: { cursor: 'pointer', fontSize: 12, display: 'flex', gap: 8, alignItems: 'center' } },
+      h('span', { style: { fontFamily: 'var(--font-mono)', fontWeight: 600 } }, call.name),
+      h('span', { className: 'badge badge-' + state.color }, state.label)),
+    h('div', { style: { fontSize: 11, color: 'var(--text-muted)', marginTop: 6 } }, 'Input'),
+    h('pre', { style: _pre }, JSON.stringify(call.input, null, 2)),
+    call.result !== undefined && h(Fragment, null,
+      h('div', { style: { fontSize: 11, color: 'var(--text-muted)', marginTop: 6 } }, 'Result'),
+      h('pre', { style: Object.assign({}, _pre, call.isError ? { color: 'var(--danger)' } : {}) }, call.result || '(empty)'))
+  );
+}
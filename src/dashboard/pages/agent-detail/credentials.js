@@ -0,0 +1,141 @@
+import { h, useState, useEffect, Fragment, useApp, engineCall } from '../../components/utils.js';
+import { I } from '../../components/icons.js';
+import { HelpButton } from '../../components/help-button.js';
+import { Table } from '../../components/table.js';
+import { RelativeTime } from '../../components/time.js';
+import { EmptyState } from './shared.js?v=5';
+
+// ════════════════════════════════════════════════════════════
+// CREDENTIALS SECTION
+// ════════════════════════════════════════════════════════════
+
+var CATEGORY_LABEL = { skill_credential: 'Integration', cloud_storage: 'Cloud storage', deploy: 'Deploy', api_key: 'API key', oauth_app: 'OAuth app', database_credential: 'Database', custom: 'Custom' };
+
+export function CredentialsSection(props) {
+  var agentId = props.agentId;
+  var app = useApp();
+  var toast = app.toast;
+  var canManage = app.can('vault.manage');
+
+  var _data = useState({ secrets: [], hasProfile: true });
+  var data = _data[0]; var setData = _data[1];
+  var _loading = useState(true);
+  var loading = _loading[0]; var setLoading = _loading[1];
+  var _saving = useState(false);
+  var saving = _saving[0]; var setSaving = _saving[1];
+  // Working copy of the granted ids; saved as a whole
+  var _granted = useState([]);
+  var granted = _granted[0]; var setGranted = _granted[1];
+  var _q = useState('');
+  var q = _q[0]; var setQ = _q[1];
+  var _category = useState('');
+  var category = _category[0]; var setCategory = _category[1];
+  var _onlyAccess = useState(false);
+  var onlyAccess = _onlyAccess[0]; var setOnlyAccess = _onlyAccess[1];
+
+  var load = function() {
+    setLoading(true);
+    engineCall('/vault/agents/' + agentId + '/access')
+      .then(function(d) {
+        setData(d);
+        setGranted(d.secrets.filter(function(s) { return s.access === 'granted'; }).map(function(s) { return s.id; }));
+      })
+      .catch(function(err) { toast('Failed to load credentials: ' + err.message, 'error'); })
+      .finally(function() { setLoading(false); });
+  };
+
+  useEffect(load, [agentId]);
+
+  var saved = data.secrets.filter(function(s) { return s.access === 'granted'; }).map(function(s) { return s.id; });
+  var dirty = saved.length !== granted.length || saved.some(function(id) { return granted.indexOf(id) < 0; });
+
+  var toggle = function(id) {
+    setGranted(granted.indexOf(id) >= 0 ? granted.filter(function(x) { return x !== id; }) : granted.concat([id]));
+  };
+
+  var save = function() {
+    setSaving(true);
+    engineCall('/vault/agents/' + agentId + '/access', { method: 'PUT', body: JSON.stringify({ secretIds: granted }) })
+      .then(function(r) {
+        toast(r.added || r.removed ? 'Granted ' + r.added + ', revoked ' + r.removed : 'No changes', 'success');
+        load();
+      })
+      .catch(function(err) { toast('Failed to save: ' + err.message, 'error'); })
+      .finally(function() { setSaving(false); });
+  };
+
+  var hasAccess = function(s) { return s.access === 'owned' || granted.indexOf(s.id) >= 0; };
+  var needle = q.trim().toLowerCase();
+  var categories = Array.from(new Set(data.secrets.map(function(s) { return s.category; }))).sort();
+  var rows = data.secrets.filter(function(s) {
+    return (!needle || s.name.toLowerCase().indexOf(needle) >= 0)
+      && (!category || s.category === category)
+      && (!onlyAccess || hasAccess(s));
+  });
+  var accessCount = data.secrets.filter(hasAccess).length;
+  var canEdit = canManage && data.hasProfile;
+
+  return h(Fragment, null,
+    h('div', { style: { display: 'flex', justifyContent: 'space-between', alignItems: 'center', marginBottom: 16 } },
+      h('h3', { style: { margin: 0, fontSize: 16, display: 'flex', alignItems: 'center' } }, 'Credentials', h(HelpButton, { label: 'Credentials' },
+        h('p', null, 'Choose which vault secrets this agent may read. Values are never shown here; manage the secrets themselves on the Vault page.'),
+        h('ul', { style: { paddingLeft: 20, margin: '4px 0 8px' } },
+          h('li', null, h('strong', null, 'Granted'), ' — an admin gave this agent access. Stored with the agent\'s permission profile.'),
+          h('li', null, h('strong', null, 'Owned'), ' — created for this agent (its own email or integration logins). Always readable and can\'t be revoked here.')
+        ),
+        h('p', null, 'Every grant, revoke and read is written to the vault audit log. "Last read" shows when this agent last used the secret.')
+      )),
+      h('div', { style: { display: 'flex', gap: 8 } },
+        h('button', { className: 'btn btn-ghost btn-sm', onClick: function() { app.setPage('vault'); } }, I.lock(), ' Open Vault'),
+        h('button', { className: 'btn btn-secondary btn-sm', onClick: load, disabled: loading }, I.refresh(), ' Refresh'),
+        canEdit && h('button', { className: 'btn btn-ghost btn-sm', disabled: !dirty || saving, onClick: function() { setGranted(saved); } }, 'Reset'),
+        canEdit && h('button', { className: 'btn btn-primary btn-sm', disabled: !dirty || saving, onClick: save }, saving ? 'Saving...' : 'Save Access')
+      )
+    ),
+
+    !data.hasProfile && h('div', { className: 'card', style: { marginBottom: 12, padding: 12, fontSize: 13, borderLeft: '3px solid var(--warning)' } },
+      'This agent has no permission profile, so secrets can\'t be granted yet. Assign one on the Permissions tab first.'),
+    data.hasProfile && !canManage && h('div', { style: { fontSize: 12, color: 'var(--text-muted)', marginBottom: 8 } }, 'You don\'t have permission to grant or revoke access.'),
+
+    h('div', { style: { display: 'flex', gap: 8, alignItems: 'center', marginBottom: 12, flexWrap: 'wrap' } },
+      h('input', { className: 'input', placeholder: 'Search secrets...', value: q, onInput: function(e) { setQ(e.target.value); }, style: { width: 240 } }),
+      h('select', { className: 'input', value: category, onChange: function(e) { setCategory(e.target.value); }, style: { width: 180 } },
+        h('option', { value: '' }, 'All categories'),
+        categories.map(function(c) { return h('option', { key: c, value: c }, CATEGORY_LABEL[c] || c); })),
+      h('label', { style: { display: 'flex', alignItems: 'center', gap: 6, fontSize: 13 } },
+        h('input', { type: 'checkbox', checked: onlyAccess, onChange: function(e) { setOnlyAccess(e.target.checked); } }), 'Only readable'),
+      h('span', { style: { marginLeft: 'auto', fontSize: 12, color: 'var(--text-muted)' } }, accessCount + ' of ' + data.secrets.length + ' readable' + (dirty ? ' (unsaved)' : ''))
+    ),
+
+    h('div', { className: 'card' },
+      data.secrets.length === 0
+        ? h(EmptyState, { icon: I.key(), message: loading ? 'Loading...' : 'No secrets in the vault for this organization' })
+        : h('div', { className: 'card-body-flush' },
+            h(Table, {
+              rows: rows, refreshing: loading,
+              empty: 'No secrets match',
+              rowStyle: function(s) { return granted.indexOf(s.id) >= 0 !== (s.access === 'granted') ? { background: 'var(--accent-soft)' } : null; },
+              columns: [
+                { key: 'access', label: 'Access', width: 64, render: function(s) {
+                  return h('input', {
+                    type: 'checkbox', checked: hasAccess(s), disabled: !canEdit || s.access === 'owned',
+                    'aria-label': (hasAccess(s) ? 'Revoke ' : 'Grant ') + s.name,
+                    title: s.access === 'owned' ? 'Owned by this agent' : canEdit ? '' : 'You don\'t have permission to change access',
+                    onChange: function() { toggle(s.id); }
+                  });
+                } },
+                { key: 'name', label: 'Secret', render: function(s) { return h('span', { style: { fontFamily: 'var(--font-mono)', fontSize: 12 } }, s.name); } },
+                { key: 'category', label: 'Category', render: function(s) { return h('span', { className: 'badge badge-neutral' }, CATEGORY_LABEL[s.category] || s.category); } },
+                { key: 'status', label: 'Status', render: function(s) {
+                  return s.access === 'owned' ? h('span', { className: 'badge badge-info' }, 'Owned')
+                    : granted.indexOf(s.id) >= 0 ? h('span', { className: 'badge badge-success' }, 'Granted')
+                    : h('span', { style: { color: 'var(--text-muted)' } }, '—');
+                } },
+                { key: 'lastReadAt', label: 'Last read', render: function(s) { return s.lastReadAt ? h(RelativeTime, { value: s.lastReadAt }) : h('span', { style: { color: 'var(--text-muted)' } }, 'Never'); } },
+                { key: 'createdBy', label: 'Created by', render: function(s) { return s.createdBy || '-'; } },
+              ]
+            })
+          )
+    )
+  );
+}
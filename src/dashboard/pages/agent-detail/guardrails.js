@@ -104,7 +104,7 @@ export function GuardrailsSection(props) {
       .catch(function(err) { toast(err.message, 'error'); });
   };
   var killAgent = function() {
-    showConfirm({ title: 'Kill Agent', message: 'Immediately terminate all running processes?', danger: true, confirmText: 'Kill Agent' }).then(function(ok) {
+    showConfirm({ title: 'Kill Agent', message: 'Immediately terminate all running processes? In-flight work is lost and the agent stays stopped until resumed.', danger: true, confirmText: 'Kill Agent', requireText: 'KILL' }).then(function(ok) {
       if (!ok) return;
       engineCall('/guardrails/kill/' + agentId, { method: 'POST', body: JSON.stringify({ reason: 'Manual kill from dashboard' }) })
         .then(function() { toast('Agent killed', 'success'); loadAll(); })
@@ -3,27 +3,36 @@ import { I } from '../../components/icons.js';
 import { E } from '../../assets/icons/emoji-icons.js';
 import { Badge, StatCard, ProgressBar, EmptyState, formatNumber, formatCost, riskBadgeClass, formatTime, MEMORY_CATEGORIES, memCatColor, memCatLabel, importanceBadgeColor } from './shared.js?v=5';
 import { OverviewSection } from './overview.js?v=6';
-import { PersonalDetailsSection } from './personal-details.js?v=5';
+import { PersonalDetailsSection } from './personal-details.js?v=6';
 import { PermissionsSection } from './permissions.js?v=5';
 import { BudgetSection } from './budget.js?v=5';
-import { ActivitySection } from './activity.js?v=5';
+import { UsageSection } from './usage.js?v=1';
+import { LogsSection } from './logs.js?v=1';
+import { ConversationsSection } from './conversations.js?v=1';
+import { CredentialsSection } from './credentials.js?v=1';
+import { WebhooksSection } from './webhooks.js?v=1';
+import { ActivitySection } from './activity.js?v=6';
 import { CommunicationSection } from './communication.js?v=5';
 import { MemorySection } from './memory.js?v=5';
-import { WorkforceSection } from './workforce.js?v=5';
+import { WorkforceSection, NextWindowLabel } from './workforce.js?v=6';
 import { GuardrailsSection } from './guardrails.js?v=5';
 import { ConfigurationSection } from './configuration.js?v=5';
 import { ManagerCatchUpSection } from './manager.js?v=5';
 import { SkillsSection } from './skills-section.js?v=5';
 import { DeploymentSection } from './deployment.js?v=5';
+import { LifecycleSection, IDENTITY_STATE_LABELS, IDENTITY_STATE_COLORS } from './lifecycle.js?v=1';
 import { ToolsSection } from './tools.js?v=5';
 import { MeetingCapabilitiesSection, BrowserConfigCard, ToolRestrictionsCard } from './meeting-browser.js?v=5';
 import { EmailSection } from './email.js?v=5';
-import { ToolSecuritySection } from './tool-security.js?v=5';
+import { ToolSecuritySection } from './tool-security.js?v=6';
 import { AgentSecurityTab } from './security.js?v=5';
 import { AutonomySection } from './autonomy.js?v=5';
 import { ChannelsSection } from './channels.js?v=5';
 import { WhatsAppSection } from './whatsapp.js?v=5';
 import { KnowledgeLink, AGENT_TAB_DOCS } from '../../components/knowledge-link.js';
+import { AgentClassification } from '../../components/classification.js';
+import { Skeleton } from '../../components/fragments.js';
+import { liveUptime } from '../../components/agent-status.js';
 
 export function AgentDetailPage(props) {
   var agentId = props.agentId;
@@ -44,8 +53,11 @@ export function AgentDetailPage(props) {
   var loading = _loading[0]; var setLoading = _loading[1];
   var _agents = useState([]);
   var agents = _agents[0]; var setAgents = _agents[1];
+  // { schedule, nextWindow } for the header's on/off duty note
+  var _workSchedule = useState(null);
+  var workSchedule = _workSchedule[0]; var setWorkSchedule = _workSchedule[1];
 
-  var ALL_TABS = ['overview', 'personal', 'email', 'whatsapp', 'channels', 'configuration', 'manager', 'tools', 'skills', 'permissions', 'activity', 'communication', 'workforce', 'memory', 'guardrails', 'autonomy', 'budget', 'security', 'tool-security', 'deployment'];
+  var ALL_TABS = ['overview', 'personal', 'email', 'whatsapp', 'channels', 'configuration', 'manager', 'tools', 'skills', 'permissions', 'credentials', 'webhooks', 'activity', 'conversations', 'logs', 'communication', 'workforce', 'memory', 'guardrails', 'autonomy', 'budget', 'usage', 'security', 'tool-security', 'deployment', 'lifecycle'];
   var TAB_LABELS = { 'security': 'Security', 'tool-security': 'Tool Security', 'manager': 'Manager', 'email': 'Email', 'whatsapp': 'WhatsApp', 'channels': 'Channels', 'tools': 'Tools', 'autonomy': 'Autonomy' };
 
   // Filter tabs based on user permissions
@@ -78,27 +90,27 @@ export function AgentDetailPage(props) {
     );
   }
 
+  // The shell renders straight away; each request fills in its part as it
+  // lands, so a slow agent list never holds up the header or the tabs
   var load = function() {
     setLoading(true);
-    Promise.all([
-      engineCall('/bridge/agents/' + agentId + '/full').catch(function() { return null; }),
-      apiCall('/agents/' + agentId).catch(function() { return null; }),
-      engineCall('/agents?orgId=' + getOrgId()).catch(function() { return { agents: [] }; })
-    ]).then(function(results) {
-      var fullData = results[0];
-      var adminData = results[1];
-      var allAgents = results[2]?.agents || results[2] || [];
-
-      if (fullData) {
+    var full = engineCall('/bridge/agents/' + agentId + '/full')
+      .then(function(fullData) {
+        if (!fullData) return;
         setEngineAgent(fullData.agent || fullData);
         setProfile(fullData.permissions || null);
-      }
-      if (adminData) {
-        setAgent(adminData);
-      }
-      setAgents(allAgents);
-      setLoading(false);
-    });
+      })
+      .catch(function() {});
+    var admin = apiCall('/agents/' + agentId)
+      .then(function(adminData) { if (adminData) setAgent(adminData); })
+      .catch(function() {});
+    engineCall('/agents?orgId=' + getOrgId())
+      .then(function(d) { setAgents(d?.agents || d || []); })
+      .catch(function() {});
+    engineCall('/workforce/schedules/' + agentId)
+      .then(function(d) { setWorkSchedule(d && d.schedule ? d : null); })
+      .catch(function() {});
+    Promise.all([full, admin]).then(function() { setLoading(false); });
   };
 
   useEffect(function() { load(); }, [agentId]);
@@ -134,7 +146,9 @@ export function AgentDetailPage(props) {
   if (state === 'online') state = 'running';
   if (state === 'idle') state = 'idle';
   if (state === 'offline') state = 'stopped';
-  var stateColor = { running: 'success', active: 'success', idle: 'info', deploying: 'info', starting: 'info', provisioning: 'info', degraded: 'warning', error: 'danger', stopped: 'neutral', draft: 'neutral', ready: 'primary' }[state] || 'neutral';
+  if (liveState === 'error') state = 'crashed';
+  var uptime = liveUptime(liveStatus);
+  var stateColor = { crashed: 'danger', running: 'success', active: 'success', idle: 'info', deploying: 'info', starting: 'info', provisioning: 'info', degraded: 'warning', error: 'danger', stopped: 'neutral', draft: 'neutral', ready: 'primary' }[state] || 'neutral';
   var displayName = identity.name || config.name || config.displayName || a.name || 'Unnamed Agent';
   var displayEmail = identity.email || config.email || a.email || '';
   var avatarUrl = identity.avatar && identity.avatar.length > 2 ? identity.avatar : null;
@@ -162,9 +176,10 @@ export function AgentDetailPage(props) {
       .catch(function(err) { toast(err.message, 'error'); });
   };
 
-  if (loading && !agent && !engineAgent) {
-    return h('div', { style: { padding: 40, textAlign: 'center', color: 'var(--text-muted)' } }, 'Loading agent...');
-  }
+  // Until the agent itself has loaded, only tabs that fetch their own data render
+  var shellOnly = loading && !agent && !engineAgent;
+  var SELF_LOADING_TABS = ['credentials', 'webhooks', 'activity', 'conversations', 'logs', 'communication', 'guardrails', 'usage', 'tool-security'];
+  var showTab = function(t) { return tab === t && (!shellOnly || SELF_LOADING_TABS.indexOf(t) >= 0); };
 
   return h(Fragment, null,
 
@@ -192,26 +207,32 @@ export function AgentDetailPage(props) {
       } },
         avatarUrl
           ? h('img', { src: avatarUrl, style: { width: '100%', height: '100%', objectFit: 'cover' } })
-          : avatarInitial
+          : shellOnly ? '' : avatarInitial
       ),
 
       // Name + Info
       h('div', { style: { flex: 1, minWidth: 0 } },
         h('div', { style: { display: 'flex', alignItems: 'center', gap: 8, flexWrap: 'wrap' } },
-          h('h1', { style: { fontSize: 20, fontWeight: 700, margin: 0 } }, displayName),
-          h('span', { className: 'badge badge-' + stateColor, style: { textTransform: 'capitalize' } }, state),
+          shellOnly
+            ? h('span', { className: 'skeleton', style: { width: 180, height: 20 }, 'aria-label': 'Loading agent' })
+            : h('h1', { style: { fontSize: 20, fontWeight: 700, margin: 0 } }, displayName),
+          !shellOnly && h('span', { className: 'badge badge-' + stateColor, style: { textTransform: 'capitalize' }, title: state === 'crashed' && liveStatus.statusReason === 'heartbeat_lost' ? 'Stopped sending heartbeats without being stopped' : undefined }, state),
+          !shellOnly && uptime != null && h('span', { style: { fontSize: 11, color: 'var(--text-muted)' }, title: 'Online since ' + new Date(liveStatus.onlineSince).toLocaleString() }, 'up ' + (uptime < 60 ? '<1m' : formatUptime(uptime))),
+          !shellOnly && workSchedule && h('span', { style: { fontSize: 11, color: 'var(--text-muted)', cursor: 'pointer' }, onClick: function() { setTab('workforce'); } }, h(NextWindowLabel, { window: workSchedule.nextWindow, enabled: workSchedule.schedule.enabled, compact: true })),
+          a.status && a.status !== 'active' && h('span', { className: 'badge badge-' + (IDENTITY_STATE_COLORS[a.status] || 'neutral'), title: 'Identity state', style: { cursor: 'pointer' }, onClick: function() { setTab('lifecycle'); } }, IDENTITY_STATE_LABELS[a.status] || a.status),
           liveStatus && liveStatus.currentActivity && h('span', { style: { fontSize: 11, color: 'var(--text-muted)', fontStyle: 'italic' } }, liveStatus.currentActivity.detail || liveStatus.currentActivity.type)
         ),
         h('div', { style: { display: 'flex', alignItems: 'center', gap: 12, marginTop: 4 } },
           displayEmail && h('span', { style: { fontFamily: 'var(--font-mono, monospace)', fontSize: 12, color: 'var(--text-muted)' } }, displayEmail),
-          h('span', { className: 'badge badge-neutral', style: { textTransform: 'capitalize' } }, role)
+          !shellOnly && h('span', { className: 'badge badge-neutral', style: { textTransform: 'capitalize' } }, role),
+          h(AgentClassification, { agentId: agentId, onError: function(err) { toast(err.message, 'error'); } })
         )
       ),
 
       // Action Buttons
-      h('div', { style: { display: 'flex', gap: 6, flexShrink: 0 } },
+      !shellOnly && h('div', { style: { display: 'flex', gap: 6, flexShrink: 0 } },
         (state !== 'running' && state !== 'active' && state !== 'deploying') && h('button', { className: 'btn btn-primary btn-sm', onClick: function() { doAction('deploy'); } }, I.play(), ' Deploy'),
-        (state === 'running' || state === 'active' || state === 'degraded' || state === 'stopped') && h('button', { className: 'btn btn-secondary btn-sm', onClick: function() { doAction('restart'); } }, I.refresh(), ' Restart'),
+        (state === 'running' || state === 'active' || state === 'degraded' || state === 'stopped' || state === 'crashed') && h('button', { className: 'btn btn-secondary btn-sm', onClick: function() { doAction('restart'); } }, I.refresh(), ' Restart'),
         (state === 'running' || state === 'active' || state === 'degraded') && h('button', { className: 'btn btn-danger btn-sm', onClick: function() { doAction('stop'); } }, I.stop(), ' Stop'),
         !isPaused && (state === 'running' || state === 'active') && h('button', { className: 'btn btn-secondary btn-sm', onClick: doPause }, I.pause(), ' Pause'),
         isPaused && h('button', { className: 'btn btn-secondary btn-sm', onClick: doResume }, I.play(), ' Resume')
@@ -244,26 +265,33 @@ export function AgentDetailPage(props) {
     ),
 
     // ─── Tab Content ────────────────────────────────────
-    tab === 'overview' && h(OverviewSection, { agentId: agentId, agent: agent, engineAgent: engineAgent, profile: profile, reload: load, agents: agents, onBack: onBack }),
-    tab === 'personal' && h(PersonalDetailsSection, { agentId: agentId, agent: agent, engineAgent: engineAgent, reload: load }),
-    tab === 'email' && h(EmailSection, { agentId: agentId, engineAgent: engineAgent, reload: load }),
-    tab === 'whatsapp' && h(WhatsAppSection, { agentId: agentId, engineAgent: engineAgent, reload: load, setTab: setTab }),
-    tab === 'channels' && h(ChannelsSection, { agentId: agentId, engineAgent: engineAgent, reload: load }),
-    tab === 'configuration' && h(ConfigurationSection, { agentId: agentId, engineAgent: engineAgent, reload: load }),
-    tab === 'manager' && h(ManagerCatchUpSection, { agentId: agentId, engineAgent: engineAgent, agents: agents, reload: load }),
-    tab === 'tools' && h(ToolsSection, { agentId: agentId, engineAgent: engineAgent, reload: load }),
-    tab === 'skills' && h(SkillsSection, { agentId: agentId, engineAgent: engineAgent, reload: load }),
-    tab === 'permissions' && h(PermissionsSection, { agentId: agentId, engineAgent: engineAgent, profile: profile, reload: load }),
-    tab === 'activity' && h(ActivitySection, { agentId: agentId }),
-    tab === 'communication' && h(CommunicationSection, { agentId: agentId, agents: agents }),
-    tab === 'workforce' && h(WorkforceSection, { agentId: agentId, engineAgent: engineAgent, reload: load }),
-    tab === 'memory' && h(MemorySection, { agentId: agentId, engineAgent: engineAgent, reload: load }),
-    tab === 'guardrails' && h(GuardrailsSection, { agentId: agentId, agents: agents }),
-    tab === 'autonomy' && h(AutonomySection, { agentId: agentId, engineAgent: engineAgent, reload: load }),
-    tab === 'budget' && h(BudgetSection, { agentId: agentId, engineAgent: engineAgent, reload: load }),
-    tab === 'security' && h(AgentSecurityTab, { agentId: agentId, engineAgent: engineAgent, reload: load }),
-    tab === 'tool-security' && h(ToolSecuritySection, { agentId: agentId }),
-    tab === 'deployment' && h(DeploymentSection, { agentId: agentId, engineAgent: engineAgent, agent: agent, reload: load, onBack: onBack })
+    shellOnly && SELF_LOADING_TABS.indexOf(tab) < 0 && h('div', { className: 'card' }, h(Skeleton, { lines: 6, label: 'Loading agent' })),
+    showTab('overview') && h(OverviewSection, { agentId: agentId, agent: agent, engineAgent: engineAgent, profile: profile, reload: load, agents: agents, onBack: onBack }),
+    showTab('personal') && h(PersonalDetailsSection, { agentId: agentId, agent: agent, engineAgent: engineAgent, reload: load }),
+    showTab('email') && h(EmailSection, { agentId: agentId, engineAgent: engineAgent, reload: load }),
+    showTab('whatsapp') && h(WhatsAppSection, { agentId: agentId, engineAgent: engineAgent, reload: load, setTab: setTab }),
+    showTab('channels') && h(ChannelsSection, { agentId: agentId, engineAgent: engineAgent, reload: load }),
+    showTab('configuration') && h(ConfigurationSection, { agentId: agentId, engineAgent: engineAgent, reload: load }),
+    showTab('manager') && h(ManagerCatchUpSection, { agentId: agentId, engineAgent: engineAgent, agents: agents, reload: load }),
+    showTab('tools') && h(ToolsSection, { agentId: agentId, engineAgent: engineAgent, reload: load }),
+    showTab('skills') && h(SkillsSection, { agentId: agentId, engineAgent: engineAgent, reload: load }),
+    showTab('permissions') && h(PermissionsSection, { agentId: agentId, engineAgent: engineAgent, profile: profile, reload: load }),
+    showTab('credentials') && h(CredentialsSection, { agentId: agentId }),
+    showTab('webhooks') && h(WebhooksSection, { agentId: agentId }),
+    showTab('activity') && h(ActivitySection, { agentId: agentId }),
+    showTab('conversations') && h(ConversationsSection, { agentId: agentId }),
+    showTab('logs') && h(LogsSection, { agentId: agentId }),
+    showTab('communication') && h(CommunicationSection, { agentId: agentId, agents: agents }),
+    showTab('workforce') && h(WorkforceSection, { agentId: agentId, engineAgent: engineAgent, reload: load }),
+    showTab('memory') && h(MemorySection, { agentId: agentId, engineAgent: engineAgent, reload: load }),
+    showTab('guardrails') && h(GuardrailsSection, { agentId: agentId, agents: agents }),
+    showTab('autonomy') && h(AutonomySection, { agentId: agentId, engineAgent: engineAgent, reload: load }),
+    showTab('budget') && h(BudgetSection, { agentId: agentId, engineAgent: engineAgent, reload: load }),
+    showTab('usage') && h(UsageSection, { agentId: agentId }),
+    showTab('security') && h(AgentSecurityTab, { agentId: agentId, engineAgent: engineAgent, reload: load }),
+    showTab('tool-security') && h(ToolSecuritySection, { agentId: agentId }),
+    showTab('deployment') && h(DeploymentSection, { agentId: agentId, engineAgent: engineAgent, agent: agent, reload: load, onBack: onBack }),
+    showTab('lifecycle') && h(LifecycleSection, { agentId: agentId, agents: agents, reload: load })
   );
 }
 
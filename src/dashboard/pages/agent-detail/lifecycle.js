@@ -0,0 +1,206 @@
+import { h, useState, useEffect, Fragment, useApp, engineCall, downloadExport, showConfirm } from '../../components/utils.js';
+import { I } from '../../components/icons.js';
+
+// ════════════════════════════════════════════════════════════
+// LIFECYCLE SECTION
+// ════════════════════════════════════════════════════════════
+// Identity state (draft → pending approval → active ⇄ suspended →
+// offboarding → retired) and the guided offboarding flow.
+
+export var IDENTITY_STATE_LABELS = {
+  'draft': 'Draft', 'pending-approval': 'Pending Approval', 'active': 'Active', 'suspended': 'Suspended',
+  'offboarding': 'Offboarding', 'retired': 'Retired', 'archived': 'Archived',
+};
+export var IDENTITY_STATE_COLORS = {
+  'draft': 'neutral', 'pending-approval': 'warning', 'active': 'success', 'suspended': 'warning',
+  'offboarding': 'danger', 'retired': 'neutral', 'archived': 'neutral',
+};
+
+var TRANSITION_ACTIONS = {
+  'pending-approval': { label: 'Submit for Approval', className: 'btn-primary' },
+  'active': { label: 'Activate', className: 'btn-primary' },
+  'draft': { label: 'Return to Draft', className: 'btn-secondary' },
+  'suspended': { label: 'Suspend', className: 'btn-secondary', confirm: 'The agent is stopped and stays unavailable until it is reactivated.' },
+  'retired': { label: 'Retire', className: 'btn-danger', confirm: 'A retired identity cannot be reactivated.' },
+};
+
+var STEP_HINTS = {
+  reassign: function(p, successorName) { return p.openSessions + ' open session(s) and ' + p.openTasks + ' task(s) ' + (successorName ? 'move to ' + successorName : 'are closed — no successor chosen'); },
+  revoke: function(p) { return p.vaultSecrets + ' vault secret(s), ' + p.databaseGrants + ' database grant(s), mailbox credentials and any API keys bound to the agent'; },
+  export: function(p) { return p.journalEntries + ' journal entries saved as JSON to storage'; },
+  retire: function(p) { return (p.mailbox ? 'Detaches ' + p.mailbox + ', stops' : 'Stops') + ' the agent and marks it retired'; },
+};
+
+export function LifecycleSection(props) {
+  var agentId = props.agentId;
+  var agents = props.agents || [];
+  var app = useApp();
+  var toast = app.toast;
+
+  var _data = useState(null);
+  var data = _data[0]; var setData = _data[1];
+  var _steps = useState([]);
+  var stepDefs = _steps[0]; var setStepDefs = _steps[1];
+  var _busy = useState(false);
+  var busy = _busy[0]; var setBusy = _busy[1];
+  var _successor = useState('');
+  var successor = _successor[0]; var setSuccessor = _successor[1];
+  var _reason = useState('');
+  var reason = _reason[0]; var setReason = _reason[1];
+
+  var load = function() {
+    engineCall('/agent-lifecycle/' + agentId).then(setData).catch(function(err) { toast(err.message, 'error'); });
+  };
+  useEffect(function() {
+    load();
+    engineCall('/agent-lifecycle/states').then(function(d) { setStepDefs(d.steps || []); }).catch(function() {});
+  }, [agentId]);
+
+  if (!data) return h('div', { className: 'card' }, h('div', { className: 'card-body', style: { color: 'var(--text-muted)' } }, 'Loading...'));
+
+  var record = data.offboarding;
+  var preview = data.preview || {};
+  // The server checks the successor is an active identity in the same org
+  var successors = agents.filter(function(a) { return a.id !== agentId; });
+  var agentName = function(id) { var a = agents.find(function(x) { return x.id === id; }); return a ? (a.config && (a.config.displayName || a.config.name)) || a.name || id : id; };
+
+  var done = function(msg) { toast(msg, 'success'); load(); if (props.reload) props.reload(); };
+  var fail = function(err) { toast(err.message, 'error'); };
+
+  var transition = async function(to) {
+    var action = TRANSITION_ACTIONS[to] || { label: IDENTITY_STATE_LABELS[to] };
+    if (action.confirm && !(await showConfirm({ title: action.label + ' agent', message: action.confirm, confirmText: action.label, danger: to === 'retired' }))) return;
+    setBusy(true);
+    engineCall('/agent-lifecycle/' + agentId + '/transition', { method: 'POST', body: JSON.stringify({ to: to, reason: reason || undefined }) })
+      .then(function(r) { setReason(''); done('Agent is now ' + (IDENTITY_STATE_LABELS[r.to] || r.to).toLowerCase()); })
+      .catch(fail).finally(function() { setBusy(false); });
+  };
+
+  var startOffboarding = async function() {
+    var ok = await showConfirm({
+      title: 'Start offboarding',
+      message: 'The agent moves to offboarding. Nothing is changed until you run the steps below, and you can cancel until the first one has run.',
+      confirmText: 'Start Offboarding', danger: true,
+    });
+    if (!ok) return;
+    setBusy(true);
+    engineCall('/agent-lifecycle/' + agentId + '/offboarding', { method: 'POST', body: JSON.stringify({ successorId: successor || null, reason: reason || undefined }) })
+      .then(function() { setReason(''); done('Offboarding started'); })
+      .catch(fail).finally(function() { setBusy(false); });
+  };
+
+  var changeSuccessor = function(id) {
+    engineCall('/agent-lifecycle/' + agentId + '/offboarding', { method: 'PUT', body: JSON.stringify({ successorId: id || null }) })
+      .then(function() { load(); }).catch(fail);
+  };
+
+  var runSteps = async function(all) {
+    if (all && !(await showConfirm({ title: 'Run remaining steps', message: 'Every remaining offboarding step runs now, ending with the agent retired.', confirmText: 'Run All', danger: true }))) return;
+    setBusy(true);
+    engineCall('/agent-lifecycle/' + agentId + '/offboarding/run', { method: 'POST', body: JSON.stringify({ all: all }) })
+      .then(function(r) {
+        var failed = r.offboarding.steps.find(function(s) { return s.status === 'failed'; });
+        if (failed) { toast('Step failed: ' + failed.error, 'error'); load(); }
+        else done(r.offboarding.completedAt ? 'Agent retired' : 'Step completed');
+      })
+      .catch(fail).finally(function() { setBusy(false); });
+  };
+
+  var state = data.state;
+  var manual = (data.transitions || []).filter(function(t) { return t !== 'offboarding' && !(state === 'offboarding' && t === 'active'); });
+  var canOffboard = (data.transitions || []).indexOf('offboarding') >= 0;
+  var started = record && record.steps.some(function(s) { return s.status === 'done'; });
+  var nextStep = record && record.steps.find(function(s) { return s.status !== 'done'; });
+  var stepLabel = function(id) { var d = stepDefs.find(function(s) { return s.id === id; }); return d ? d.label : id; };
+
+  return h(Fragment, null,
+    // ─── Identity State ───────────────────────────────
+    h('div', { className: 'card', style: { marginBottom: 16 } },
+      h('div', { className: 'card-header' }, h('h3', null, 'Identity State')),
+      h('div', { className: 'card-body' },
+        h('div', { style: { display: 'flex', alignItems: 'center', gap: 12, marginBottom: 12 } },
+          h('span', { className: 'badge badge-' + (IDENTITY_STATE_COLORS[state] || 'neutral'), style: { fontSize: 13 } }, IDENTITY_STATE_LABELS[state] || state),
+          h('span', { style: { fontSize: 12, color: 'var(--text-muted)' } },
+            state === 'retired' ? 'This identity has been retired and can no longer be changed.'
+              : state === 'offboarding' ? 'Offboarding is in progress — see the steps below.'
+              : 'Moves are limited to the next states in the agent\'s lifecycle.')
+        ),
+        (manual.length > 0 || canOffboard) && h('div', { style: { display: 'flex', gap: 8, alignItems: 'flex-end', flexWrap: 'wrap' } },
+          h('div', { style: { flex: 1, minWidth: 220 } },
+            h('label', { className: 'field-label' }, 'Reason (recorded in the audit log)'),
+            h('input', { className: 'input', value: reason, placeholder: 'Optional', onInput: function(e) { setReason(e.target.value); } })
+          ),
+          manual.map(function(to) {
+            var action = TRANSITION_ACTIONS[to] || { label: IDENTITY_STATE_LABELS[to], className: 'btn-secondary' };
+            return h('button', { key: to, className: 'btn btn-sm ' + action.className, disabled: busy, onClick: function() { transition(to); } }, action.label);
+          })
+        )
+      )
+    ),
+
+    // ─── Start Offboarding ────────────────────────────
+    canOffboard && h('div', { className: 'card', style: { marginBottom: 16 } },
+      h('div', { className: 'card-header' }, h('h3', null, 'Offboard Agent')),
+      h('div', { className: 'card-body' },
+        h('p', { style: { fontSize: 13, color: 'var(--text-muted)', marginTop: 0 } },
+          'Offboarding reassigns open conversations, revokes the agent\'s credentials, exports its action journal and then retires its mailbox. Each step runs when you say so.'),
+        h('div', { style: { display: 'grid', gridTemplateColumns: 'repeat(auto-fit, minmax(140px, 1fr))', gap: 8, marginBottom: 12, fontSize: 13 } },
+          h('div', null, h('strong', null, preview.openSessions || 0), ' open sessions'),
+          h('div', null, h('strong', null, preview.openTasks || 0), ' open tasks'),
+          h('div', null, h('strong', null, (preview.vaultSecrets || 0) + (preview.databaseGrants || 0)), ' credentials'),
+          h('div', null, h('strong', null, preview.journalEntries || 0), ' journal entries')
+        ),
+        h('div', { style: { display: 'flex', gap: 8, alignItems: 'flex-end', flexWrap: 'wrap' } },
+          h('div', { style: { flex: 1, minWidth: 220 } },
+            h('label', { className: 'field-label' }, 'Successor agent'),
+            h('select', { className: 'input', value: successor, onChange: function(e) { setSuccessor(e.target.value); } },
+              h('option', { value: '' }, 'None — close open conversations'),
+              successors.map(function(a) { return h('option', { key: a.id, value: a.id }, agentName(a.id)); })
+            )
+          ),
+          h('button', { className: 'btn btn-danger btn-sm', disabled: busy, onClick: startOffboarding }, 'Start Offboarding')
+        )
+      )
+    ),
+
+    // ─── Offboarding Steps ────────────────────────────
+    record && h('div', { className: 'card' },
+      h('div', { className: 'card-header', style: { display: 'flex', justifyContent: 'space-between', alignItems: 'center' } },
+        h('h3', null, 'Offboarding'),
+        h('span', { style: { fontSize: 12, color: 'var(--text-muted)' } }, 'Started ' + new Date(record.startedAt).toLocaleString() + (record.reason ? ' — ' + record.reason : ''))
+      ),
+      h('div', { className: 'card-body' },
+        h('div', { style: { display: 'flex', gap: 8, alignItems: 'flex-end', marginBottom: 16 } },
+          h('div', { style: { flex: 1 } },
+            h('label', { className: 'field-label' }, 'Successor agent'),
+            h('select', { className: 'input', value: record.successorId || '', disabled: started, onChange: function(e) { changeSuccessor(e.target.value); } },
+              h('option', { value: '' }, 'None — close open conversations'),
+              record.successorId && !successors.some(function(a) { return a.id === record.successorId; }) && h('option', { value: record.successorId }, agentName(record.successorId)),
+              successors.map(function(a) { return h('option', { key: a.id, value: a.id }, agentName(a.id)); })
+            )
+          ),
+          state === 'offboarding' && !started && h('button', { className: 'btn btn-secondary btn-sm', disabled: busy, onClick: function() { transition('active'); } }, 'Cancel Offboarding')
+        ),
+        record.steps.map(function(step, i) {
+          var color = step.status === 'done' ? 'var(--success)' : step.status === 'failed' ? 'var(--danger)' : 'var(--text-muted)';
+          var detail = step.status === 'failed' ? step.error
+            : step.status === 'done' ? 'Done ' + new Date(step.at).toLocaleString() + (step.by ? ' by ' + step.by : '')
+            : data.preview && STEP_HINTS[step.id] ? STEP_HINTS[step.id](preview, record.successorId && agentName(record.successorId)) : '';
+          return h('div', { key: step.id, style: { display: 'flex', alignItems: 'center', gap: 12, padding: '10px 0', borderTop: i ? '1px solid var(--border)' : 'none' } },
+            h('span', { style: { width: 24, height: 24, borderRadius: '50%', border: '2px solid ' + color, color: color, display: 'flex', alignItems: 'center', justifyContent: 'center', fontSize: 12, fontWeight: 700, flexShrink: 0 } }, step.status === 'done' ? '✓' : step.status === 'failed' ? '!' : i + 1),
+            h('div', { style: { flex: 1, minWidth: 0 } },
+              h('div', { style: { fontWeight: 600, fontSize: 13 } }, stepLabel(step.id)),
+              detail && h('div', { style: { fontSize: 12, color: step.status === 'failed' ? 'var(--danger)' : 'var(--text-muted)' } }, detail)
+            ),
+            step.id === 'export' && step.status === 'done' && h('button', { className: 'btn btn-ghost btn-sm', onClick: function() { downloadExport('/engine/agent-lifecycle/' + agentId + '/offboarding/journal').catch(fail); } }, I.download(), ' Journal')
+          );
+        }),
+        nextStep && h('div', { style: { display: 'flex', gap: 8, justifyContent: 'flex-end', marginTop: 12 } },
+          h('button', { className: 'btn btn-secondary btn-sm', disabled: busy, onClick: function() { runSteps(false); } }, I.play(), ' ', nextStep.status === 'failed' ? 'Retry' : 'Run', ': ', stepLabel(nextStep.id)),
+          h('button', { className: 'btn btn-danger btn-sm', disabled: busy, onClick: function() { runSteps(true); } }, 'Run All Remaining')
+        ),
+        record.completedAt && h('div', { style: { marginTop: 12, fontSize: 13, color: 'var(--success)' } }, 'Offboarding completed ' + new Date(record.completedAt).toLocaleString())
+      )
+    )
+  );
+}
@@ -0,0 +1,114 @@
+import { h, useState, useEffect, useRef, Fragment, useApp } from '../../components/utils.js';
+import { I } from '../../components/icons.js';
+import { HelpButton } from '../../components/help-button.js';
+
+// ════════════════════════════════════════════════════════════
+// LOGS SECTION
+// ════════════════════════════════════════════════════════════
+
+var LEVELS = ['debug', 'info', 'warn', 'error'];
+var LEVEL_COLOR = { debug: 'var(--text-muted)', info: 'var(--text)', warn: 'var(--warning)', error: 'var(--danger)' };
+/** Lines kept in the browser; older ones drop off the top */
+var MAX_LINES = 2000;
+
+export function LogsSection(props) {
+  var agentId = props.agentId;
+  var user = useApp().user;
+  var isAdmin = user && (user.role === 'owner' || user.role === 'admin');
+
+  var _lines = useState([]);
+  var lines = _lines[0]; var setLines = _lines[1];
+  var _level = useState('info');
+  var level = _level[0]; var setLevel = _level[1];
+  var _paused = useState(false);
+  var paused = _paused[0]; var setPaused = _paused[1];
+  var _pending = useState(0);
+  var pending = _pending[0]; var setPending = _pending[1];
+  var _status = useState('connecting');
+  var status = _status[0]; var setStatus = _status[1];
+  var _filter = useState('');
+  var filter = _filter[0]; var setFilter = _filter[1];
+  var _follow = useState(true);
+  var follow = _follow[0]; var setFollow = _follow[1];
+
+  // While paused, new lines wait here instead of moving the view
+  var held = useRef([]);
+  var pausedRef = useRef(false);
+  var boxRef = useRef(null);
+
+  var append = function(incoming) {
+    setLines(function(prev) {
+      var next = prev.concat(incoming);
+      return next.length > MAX_LINES ? next.slice(next.length - MAX_LINES) : next;
+    });
+  };
+
+  useEffect(function() {
+    if (!isAdmin) return;
+    setStatus('connecting');
+    held.current = []; setPending(0);
+    var es = new EventSource('/api/engine/logs/agent/' + encodeURIComponent(agentId) + '/stream?level=' + level);
+    es.onopen = function() { setStatus('live'); };
+    es.onmessage = function(ev) {
+      var d;
+      try { d = JSON.parse(ev.data); } catch (e) { return; }
+      if (d.type === 'backlog') { setLines(d.lines || []); return; }
+      if (d.type !== 'line') return;
+      if (pausedRef.current) { held.current.push(d.line); setPending(held.current.length); }
+      else append([d.line]);
+    };
+    es.onerror = function() { setStatus(es.readyState === 2 ? 'closed' : 'reconnecting'); };
+    return function() { es.close(); };
+  }, [agentId, level, isAdmin]);
+
+  useEffect(function() {
+    if (follow && !paused && boxRef.current) boxRef.current.scrollTop = boxRef.current.scrollHeight;
+  }, [lines, follow, paused]);
+
+  var togglePause = function() {
+    var next = !paused;
+    pausedRef.current = next;
+    setPaused(next);
+    if (!next && held.current.length) {
+      append(held.current);
+      held.current = [];
+      setPending(0);
+    }
+  };
+
+  if (!isAdmin) {
+    return h('div', { className: 'card' }, h('div', { className: 'card-body', style: { color: 'var(--text-muted)', fontSize: 13 } }, 'Engine logs can contain message contents and tool input, so only admins can view them.'));
+  }
+
+  var needle = filter.trim().toLowerCase();
+  var shown = needle ? lines.filter(function(l) { return l.message.toLowerCase().indexOf(needle) >= 0; }) : lines;
+  var statusBadge = { live: 'success', connecting: 'neutral', reconnecting: 'warning', closed: 'danger' }[status];
+
+  return h('div', { className: 'card' },
+    h('div', { className: 'card-header', style: { display: 'flex', alignItems: 'center', gap: 8, flexWrap: 'wrap' } },
+      h('h3', { style: { display: 'flex', alignItems: 'center', gap: 8, marginRight: 'auto' } }, 'Engine Logs',
+        h('span', { className: 'badge badge-' + statusBadge }, paused ? 'paused' : status),
+        h(HelpButton, { label: 'Engine Logs' },
+          h('p', null, 'A live tail of the engine\'s log output that mentions this agent by id or name, starting with the most recent lines. Nothing is stored beyond the engine\'s in-memory buffer.'),
+          h('p', null, 'Pause freezes the view while new lines keep arriving; Resume adds them. Agents deployed to their own machine or container log there, not here.')
+        )),
+      h('input', { className: 'input', style: { width: 200, height: 30, fontSize: 12 }, placeholder: 'Filter lines...', value: filter, onInput: function(e) { setFilter(e.target.value); } }),
+      h('select', { className: 'input', style: { width: 120, height: 30, fontSize: 12 }, 'aria-label': 'Minimum level', value: level, onChange: function(e) { setLevel(e.target.value); } },
+        LEVELS.map(function(l) { return h('option', { key: l, value: l }, l === 'debug' ? 'All levels' : l.charAt(0).toUpperCase() + l.slice(1) + (l === 'error' ? ' only' : ' and up')); })),
+      h('label', { style: { display: 'flex', alignItems: 'center', gap: 4, fontSize: 12 } },
+        h('input', { type: 'checkbox', checked: follow, onChange: function(e) { setFollow(e.target.checked); } }), 'Follow'),
+      h('button', { className: 'btn btn-secondary btn-sm', onClick: togglePause }, paused ? I.play() : I.pause(), paused ? ' Resume' + (pending ? ' (' + pending + ' new)' : '') : ' Pause'),
+      h('button', { className: 'btn btn-ghost btn-sm', onClick: function() { setLines([]); } }, 'Clear')
+    ),
+    h('div', { ref: boxRef, style: { height: 480, overflow: 'auto', background: 'var(--bg-tertiary)', padding: '8px 12px', fontFamily: 'var(--font-mono, monospace)', fontSize: 12, lineHeight: 1.5 } },
+      shown.length === 0
+        ? h('div', { style: { color: 'var(--text-muted)' } }, status === 'connecting' ? 'Connecting...' : needle ? 'No lines match the filter' : 'No log lines for this agent yet. New ones appear here as they are written.')
+        : shown.map(function(l) {
+            return h('div', { key: l.seq, style: { display: 'flex', gap: 10, whiteSpace: 'pre-wrap', wordBreak: 'break-word' } },
+              h('span', { style: { color: 'var(--text-muted)', flexShrink: 0 } }, new Date(l.timestamp).toLocaleTimeString()),
+              h('span', { style: { color: LEVEL_COLOR[l.level], width: 40, flexShrink: 0, textTransform: 'uppercase', fontWeight: 600 } }, l.level),
+              h('span', { style: { color: l.level === 'debug' ? 'var(--text-muted)' : 'var(--text)' } }, l.message));
+          })
+    )
+  );
+}
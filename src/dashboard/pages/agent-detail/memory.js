@@ -102,15 +102,16 @@ export function MemorySection(props) {
       .catch(function(e) { toast(e.message, 'error'); });
   };
 
-  var deleteMemory = function(id) {
+  var deleteMemory = function(m) {
     showConfirm({
       title: 'Delete Memory',
-      message: 'Are you sure you want to delete this memory entry? This action cannot be undone.',
-      warning: true,
+      message: 'Delete "' + (m.title || 'Untitled') + '"? The agent will no longer recall it. The deletion and the removed text are recorded in the audit log.',
+      warning: 'This action cannot be undone.',
+      danger: true,
       confirmText: 'Delete'
     }).then(function(confirmed) {
       if (!confirmed) return;
-      engineCall('/memory/' + id, { method: 'DELETE' })
+      engineCall('/memory/' + m.id, { method: 'DELETE' })
         .then(function() { toast('Memory deleted', 'success'); loadAll(); })
         .catch(function(e) { toast(e.message, 'error'); });
     });
@@ -125,7 +126,7 @@ export function MemorySection(props) {
     }).then(function(confirmed) {
       if (!confirmed) return;
       engineCall('/memory/agent/' + agentId + '/prune', { method: 'POST' })
-        .then(function(d) { toast('Pruned ' + (d.deleted || 0) + ' entries', 'success'); loadAll(); })
+        .then(function(d) { toast('Pruned ' + (d.pruned || 0) + ' entries', 'success'); loadAll(); })
         .catch(function(e) { toast(e.message, 'error'); });
     });
   };
@@ -303,7 +304,7 @@ export function MemorySection(props) {
                   m.lastAccessedAt && h('span', null, 'Last accessed: ', h('strong', null, fmtDate(m.lastAccessedAt))),
                   m.tags && m.tags.length > 0 && h('span', null, 'Tags: ', m.tags.join(', ')),
                   h('div', { style: { flex: 1 } }),
-                  h('button', { className: 'btn btn-ghost btn-sm', style: { color: 'var(--danger)', height: 24, fontSize: 11 }, onClick: function(e) { e.stopPropagation(); deleteMemory(m.id); } }, I.trash(), ' Delete')
+                  h('button', { className: 'btn btn-ghost btn-sm', style: { color: 'var(--danger)', height: 24, fontSize: 11 }, onClick: function(e) { e.stopPropagation(); deleteMemory(m); } }, I.trash(), ' Delete')
                 )
               )
             );
@@ -4,6 +4,8 @@ import { E } from '../../assets/icons/emoji-icons.js';
 import { Badge, StatCard, EmptyState, formatNumber, formatCost, formatTime, MEMORY_CATEGORIES, memCatColor, memCatLabel, importanceBadgeColor } from './shared.js?v=4';
 import { resolveManager } from './manager.js?v=4';
 import { HelpButton } from '../../components/help-button.js';
+import { timeAgo } from '../../components/time.js';
+import { BarChart } from '../../components/charts.js';
 
 var CATEGORY_COLORS = {
   code_of_conduct: '#6366f1', communication: '#0ea5e9', data_handling: '#991b1b',
@@ -34,10 +36,14 @@ export function OverviewSection(props) {
   var guardrailStatus = _guard[0]; var setGuardrailStatus = _guard[1];
   var _work = useState(null);
   var workforceStatus = _work[0]; var setWorkforceStatus = _work[1];
+  var _qa = useState(null);
+  var qaSummary = _qa[0]; var setQaSummary = _qa[1];
   var _loading = useState(true);
   var loading = _loading[0]; var setLoading = _loading[1];
   var _acting = useState('');
   var acting = _acting[0]; var setActing = _acting[1];
+  var _trend = useState([]);
+  var trend = _trend[0]; var setTrend = _trend[1];
 
   useEffect(function() {
     setLoading(true);
@@ -45,12 +51,16 @@ export function OverviewSection(props) {
       engineCall('/agents/' + agentId + '/usage').catch(function() { return null; }),
       engineCall('/onboarding/status/' + agentId).catch(function() { return null; }),
       engineCall('/guardrails/status/' + agentId).catch(function() { return null; }),
-      engineCall('/workforce/status/' + agentId).catch(function() { return null; })
+      engineCall('/workforce/status/' + agentId).catch(function() { return null; }),
+      engineCall('/qa/summary?orgId=' + getOrgId() + '&agentId=' + agentId).catch(function() { return null; }),
+      engineCall('/activity/trend?days=14&agentId=' + agentId).catch(function() { return null; })
     ]).then(function(results) {
       setUsageData(results[0]);
       setOnboardingStatus(results[1]);
       setGuardrailStatus(results[2]);
       setWorkforceStatus(results[3]);
+      setQaSummary(results[4] && results[4].agents ? results[4].agents[0] || null : null);
+      setTrend(results[5] ? results[5].trend : []);
       setLoading(false);
     });
   }, [agentId]);
@@ -240,7 +250,8 @@ export function OverviewSection(props) {
           h('li', null, h('strong', null, 'Cost Today'), ' — Estimated API cost based on token usage and the agent\'s configured model pricing.'),
           h('li', null, h('strong', null, 'Uptime'), ' — How long the agent has been running since last restart. Resets when the engine restarts.'),
           h('li', null, h('strong', null, 'Error Rate'), ' — Percentage of requests that resulted in errors (timeouts, API failures, guardrail blocks). Above 5% is flagged in red.'),
-          h('li', null, h('strong', null, 'Active Sessions'), ' — Currently open conversation sessions. Each chat, email thread, or task is a separate session.')
+          h('li', null, h('strong', null, 'Active Sessions'), ' — Currently open conversation sessions. Each chat, email thread, or task is a separate session.'),
+          h('li', null, h('strong', null, 'QA Score'), ' — Average rubric grade (1–5) from the Quality Review queue over the last 90 days, with the change since the previous graded week.')
         ),
         h('div', { style: _tip }, h('strong', null, 'Tip: '), 'If cost is climbing faster than expected, check the Budget tab to set daily/monthly spending limits.')
       )
@@ -249,8 +260,25 @@ export function OverviewSection(props) {
       h(StatCard, { label: 'Tokens Today', value: formatNumber(tokensToday) }),
       h(StatCard, { label: 'Cost Today', value: formatCost(costToday) }),
       h(StatCard, { label: 'Uptime', value: formatUptime(uptime) }),
-      h(StatCard, { label: 'Error Rate', value: (errorRate * 100).toFixed(1) + '%', color: errorRate > 0.05 ? 'var(--danger)' : undefined }),
-      h(StatCard, { label: 'Active Sessions', value: String(activeSessions) })
+      h(StatCard, { label: 'Error Rate', value: (errorRate * 100).toFixed(1) + '%', color: errorRate > 0.05 ? 'var(--danger)' : undefined,
+        trend: trend.length ? trend.map(function(d) { return d.errors; }) : null, trendTitle: 'Errors per day, last 14 days' }),
+      h(StatCard, { label: 'Active Sessions', value: String(activeSessions) }),
+      h(StatCard, {
+        label: 'QA Score',
+        value: qaSummary && qaSummary.average != null ? qaSummary.average.toFixed(2) : '—',
+        color: qaSummary && qaSummary.average != null ? (qaSummary.average >= 4 ? 'var(--success)' : qaSummary.average >= 3 ? 'var(--warning)' : 'var(--danger)') : undefined,
+        sub: qaSummary && qaSummary.graded ? qaSummary.graded + ' graded' + (qaSummary.delta != null ? ' · ' + (qaSummary.delta >= 0 ? '+' : '') + qaSummary.delta.toFixed(2) + ' vs last week' : '') : 'No graded responses'
+      })
+    ),
+
+    // ─── 14-Day Trend ───────────────────────────────────
+    trend.some(function(d) { return d.toolCalls > 0; }) && h('div', { className: 'card', style: { marginBottom: 20 } },
+      h('div', { className: 'card-header' }, h('span', null, 'Tool Calls — Last 14 Days'),
+        h('span', { style: { fontSize: 12, color: 'var(--text-muted)' } }, formatNumber(trend.reduce(function(s, d) { return s + d.toolCalls; }, 0)) + ' total')
+      ),
+      h('div', { className: 'card-body' },
+        h(BarChart, { height: 64, data: trend.map(function(d) { return { label: d.day, value: d.toolCalls, color: d.errors > 0 && d.errors >= d.toolCalls / 2 ? 'var(--danger)' : undefined }; }) })
+      )
     ),
 
     // ─── Status Indicators ──────────────────────────────
@@ -412,7 +440,7 @@ export function OverviewSection(props) {
               h('div', { style: { display: 'flex', gap: 20, fontSize: 12, color: 'var(--text-muted)', borderTop: '1px solid var(--border)', paddingTop: 10 } },
                 h('span', null, 'Sessions: ', h('strong', null, rtStatus.activeSessions || 0)),
                 rtStatus.onlineSince && h('span', null, 'Uptime: ', h('strong', null, _formatUptime(rtStatus.uptimeMs))),
-                rtStatus.lastHeartbeat && h('span', null, 'Last heartbeat: ', h('strong', null, _timeAgo(rtStatus.lastHeartbeat)))
+                rtStatus.lastHeartbeat && h('span', null, 'Last heartbeat: ', h('strong', null, timeAgo(rtStatus.lastHeartbeat)))
               )
             )
       )
@@ -688,15 +716,6 @@ function _formatUptime(ms) {
   return Math.floor(hr / 24) + 'd ' + (hr % 24) + 'h';
 }
 
-function _timeAgo(ts) {
-  if (!ts) return '-';
-  var diff = Date.now() - new Date(ts).getTime();
-  if (diff < 5000) return 'just now';
-  if (diff < 60000) return Math.floor(diff / 1000) + 's ago';
-  if (diff < 3600000) return Math.floor(diff / 60000) + 'm ago';
-  return Math.floor(diff / 3600000) + 'h ago';
-}
-
 // ─── Organization & Knowledge Access Cards ────────────────
 function OrgAndKnowledgeCards(props) {
   var agentId = props.agentId;
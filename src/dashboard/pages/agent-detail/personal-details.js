@@ -3,7 +3,7 @@ import { I } from '../../components/icons.js';
 import { E } from '../../assets/icons/emoji-icons.js';
 import { TimezoneSelect } from '../../components/timezones.js';
 import { HelpButton } from '../../components/help-button.js';
-import { CULTURES, LANGUAGES, DEFAULT_TRAITS, computeAge, PersonaForm, getLanguageName } from '../../components/persona-fields.js';
+import { CULTURES, LANGUAGES, DEFAULT_TRAITS, computeAge, PersonaForm, getLanguageName, saveAvatar } from '../../components/persona-fields.js';
 import { TagInput } from '../../components/tag-input.js';
 import { Badge, StatCard, formatTime } from './shared.js?v=4';
 
@@ -46,6 +46,7 @@ export function PersonalDetailsSection(props) {
       culturalBackground: identity.culturalBackground || '',
       language: identity.language || 'en-us',
       description: identity.description || config.description || '',
+      signature: identity.signature || '',
       traits: identity.traits && typeof identity.traits === 'object' && !Array.isArray(identity.traits)
         ? Object.assign({}, DEFAULT_TRAITS, identity.traits)
         : Object.assign({}, DEFAULT_TRAITS),
@@ -71,13 +72,13 @@ export function PersonalDetailsSection(props) {
       description: form.description,
       identity: Object.assign({}, identity, {
         role: form.role,
-        avatar: form.avatar,
         gender: form.gender,
         dateOfBirth: form.dateOfBirth,
         maritalStatus: form.maritalStatus,
         culturalBackground: form.culturalBackground,
         language: form.language,
         description: form.description,
+        signature: form.signature.trim() || undefined,
         traits: form.traits,
         name: form.name,
         email: form.email,
@@ -92,9 +93,14 @@ export function PersonalDetailsSection(props) {
       : '/agents/' + agentId + '/config';
     var method = isRunning ? 'POST' : 'PATCH';
 
+    // The avatar is saved separately, after the rest, so a rejected image doesn't lose the other edits
     engineCall(endpoint, {
       method: method,
       body: JSON.stringify({ updates: updates, updatedBy: 'dashboard' })
+    }).then(function() {
+      return saveAvatar(agentId, form.avatar, identity.avatar).catch(function(err) {
+        toast('Details saved, but the avatar was not: ' + err.message, 'warning');
+      });
     }).then(function() {
       toast('Personal details saved', 'success');
       setEditing(false);
@@ -152,7 +158,8 @@ export function PersonalDetailsSection(props) {
               h('li', null, h('strong', null, 'Name'), ' — The agent\'s display name. Used in emails, chat messages, and team interactions.'),
               h('li', null, h('strong', null, 'Role'), ' — Job title or function (e.g., "Sales Rep", "Support Agent"). Helps the agent understand its responsibilities.'),
               h('li', null, h('strong', null, 'Timezone'), ' — Used for scheduling, work hours, and time-aware responses.'),
-              h('li', null, h('strong', null, 'Avatar'), ' — Profile image shown in chat, emails, and the dashboard.'),
+              h('li', null, h('strong', null, 'Avatar'), ' — Profile image shown in the agent list, messages and the dashboard. Upload a file or paste an image URL; either way the image is stored on the server.'),
+              h('li', null, h('strong', null, 'Email Signature'), ' — Optional HTML signature for this agent. When empty, the organization template from Settings is used.'),
               h('li', null, h('strong', null, 'Personality Traits'), ' — Adjectives that shape the agent\'s tone and style (e.g., "professional", "friendly", "concise").'),
               h('li', null, h('strong', null, 'Communication Style'), ' — How the agent writes: formal, casual, technical, etc.'),
               h('li', null, h('strong', null, 'Backstory'), ' — Optional narrative context that enriches the agent\'s persona.')
@@ -209,6 +216,14 @@ export function PersonalDetailsSection(props) {
         fieldView('Description', displayDesc)
       ),
 
+      // Email signature
+      h('div', { className: 'card', style: { padding: 20, marginBottom: 20 } },
+        h('h4', { style: { margin: '0 0 16px', fontSize: 14, fontWeight: 600 } }, 'Email Signature'),
+        identity.signature
+          ? h('pre', { style: { margin: 0, fontSize: 12, whiteSpace: 'pre-wrap', wordBreak: 'break-word', fontFamily: 'var(--font-mono)' } }, identity.signature)
+          : h('span', { style: { fontSize: 13, color: 'var(--text-muted)' } }, 'Uses the organization signature template')
+      ),
+
       // Personality Traits
       h('div', { className: 'card', style: { padding: 20 } },
         h('h4', { style: { margin: '0 0 16px', fontSize: 14, fontWeight: 600 } }, 'Personality Traits'),
@@ -271,6 +286,12 @@ export function PersonalDetailsSection(props) {
       h('div', { style: fieldGroupStyle },
         h('label', { style: labelStyle }, 'Description'),
         h('textarea', { className: 'input', style: { minHeight: 80, resize: 'vertical' }, value: form.description, placeholder: 'Describe what this agent does...', onChange: function(e) { set('description', e.target.value); } })
+      ),
+
+      h('div', { style: fieldGroupStyle },
+        h('label', { style: labelStyle }, 'Email Signature'),
+        h('textarea', { className: 'input', style: { minHeight: 80, resize: 'vertical', fontFamily: 'var(--font-mono)', fontSize: 12 }, value: form.signature, placeholder: 'Leave empty to use the organization template', onChange: function(e) { set('signature', e.target.value); } }),
+        h('p', { className: 'form-help' }, 'HTML. Replaces the organization signature template for this agent; {{name}}, {{role}}, {{email}}, {{company}} and {{logo}} work here too. Applied to the mailbox the next time the agent starts.')
       )
     ),
 
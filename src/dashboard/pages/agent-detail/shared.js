@@ -1,6 +1,7 @@
 import { h, useState, useEffect, useCallback, Fragment, useApp, apiCall, engineCall, formatUptime, buildAgentDataMap, renderAgentBadge, showConfirm, getOrgId } from '../../components/utils.js';
 import { I } from '../../components/icons.js';
 import { E } from '../../assets/icons/emoji-icons.js';
+import { Sparkline } from '../../components/charts.js';
 
 // ════════════════════════════════════════════════════════════
 // HELPERS
@@ -16,7 +17,8 @@ export function StatCard(props) {
   return h('div', { className: 'stat-card' },
     h('div', { className: 'stat-label' }, props.label),
     h('div', { className: 'stat-value', style: props.color ? { color: props.color } : null }, props.value),
-    props.sub && h('div', { style: { fontSize: 11, color: 'var(--text-muted)', marginTop: 2 } }, props.sub)
+    props.sub && h('div', { style: { fontSize: 11, color: 'var(--text-muted)', marginTop: 2 } }, props.sub),
+    props.trend && h(Sparkline, { values: props.trend, width: 96, height: 20, color: props.color, fill: true, title: props.trendTitle, style: { marginTop: 6 } })
   );
 }
 
@@ -4,6 +4,7 @@ import { E } from '../../assets/icons/emoji-icons.js';
 import { TagInput } from '../../components/tag-input.js';
 import { Badge, EmptyState } from './shared.js?v=4';
 import { HelpButton } from '../../components/help-button.js';
+import { Skeleton } from '../../components/fragments.js';
 
 var _tsCardStyle = { border: '1px solid var(--border)', borderRadius: 'var(--radius)', padding: 20, marginBottom: 16 };
 var _tsCardTitle = { fontSize: 15, fontWeight: 600, marginBottom: 4, display: 'flex', alignItems: 'center', gap: 8 };
@@ -215,8 +216,22 @@ export function ToolSecuritySection(props) {
     });
   };
 
+  // Shell with placeholder cards while the (sometimes slow) config request runs
   if (loading) {
-    return h('div', { style: { textAlign: 'center', padding: 40, color: 'var(--text-muted)' } }, 'Loading tool security config...');
+    return h(Fragment, null,
+      h('div', { style: { marginBottom: 20 } },
+        h('h3', { style: { margin: 0, fontSize: 18, fontWeight: 600 } }, 'Tool Security'),
+        h('p', { style: { margin: '4px 0 0', fontSize: 13, color: 'var(--text-muted)' } }, 'Loading tool security config...')
+      ),
+      h('div', { style: _tsGrid },
+        ['Path Sandbox', 'SSRF Protection', 'Command Sanitizer', 'Audit Logging'].map(function(title) {
+          return h('div', { key: title, style: _tsCardStyle },
+            h('div', { style: _tsCardTitle }, title),
+            h(Skeleton, { lines: 4, label: 'Loading ' + title })
+          );
+        })
+      )
+    );
   }
 
   var ps = sec.pathSandbox || {};
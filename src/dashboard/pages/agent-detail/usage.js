@@ -0,0 +1,94 @@
+import { h, useState, useEffect, Fragment, useApp, apiCall } from '../../components/utils.js';
+import { StatCard, EmptyState, formatNumber, formatCost } from './shared.js?v=5';
+import { BarChart } from '../../components/charts.js';
+import { RelativeTime } from '../../components/time.js';
+import { HelpButton } from '../../components/help-button.js';
+
+// ════════════════════════════════════════════════════════════
+// USAGE SECTION
+// ════════════════════════════════════════════════════════════
+
+var RANGES = [7, 30, 90];
+
+export function UsageSection(props) {
+  var agentId = props.agentId;
+  var toast = useApp().toast;
+
+  var _days = useState(30);
+  var days = _days[0]; var setDays = _days[1];
+  var _data = useState(null);
+  var data = _data[0]; var setData = _data[1];
+  var _loading = useState(true);
+  var loading = _loading[0]; var setLoading = _loading[1];
+
+  useEffect(function() {
+    setLoading(true);
+    apiCall('/agents/' + agentId + '/usage-report?days=' + days)
+      .then(setData)
+      .catch(function(err) { toast('Failed to load usage: ' + err.message, 'error'); })
+      .finally(function() { setLoading(false); });
+  }, [agentId, days]);
+
+  var _muted = { fontSize: 12, color: 'var(--text-muted)' };
+  var rate = function(v) { return v == null ? '-' : '$' + Number(v).toFixed(2); };
+
+  return h(Fragment, null,
+    h('div', { style: { display: 'flex', justifyContent: 'space-between', alignItems: 'center', marginBottom: 16 } },
+      h('h3', { style: { margin: 0, fontSize: 16, display: 'flex', alignItems: 'center' } }, 'Usage', h(HelpButton, { label: 'Usage' },
+        h('p', null, 'Tokens and LLM cost for this agent, from the usage each model call records, broken down by day and by conversation.'),
+        h('p', null, 'Calls recorded without a cost are priced at the rates for the agent\'s current model in Settings → Model Pricing and marked as estimated.'),
+        h('p', null, 'Spending limits are set on the Budget tab.')
+      )),
+      h('div', { style: { display: 'flex', gap: 4 } },
+        RANGES.map(function(n) {
+          return h('button', { key: n, className: 'btn btn-sm ' + (days === n ? 'btn-primary' : 'btn-secondary'), onClick: function() { setDays(n); } }, n + 'd');
+        })
+      )
+    ),
+
+    !data
+      ? h('div', { className: 'card' }, h(EmptyState, { message: loading ? 'Loading...' : 'Usage unavailable' }))
+      : h(Fragment, null,
+          h('div', { className: 'stat-grid', style: { marginBottom: 16, opacity: loading ? 0.6 : 1 } },
+            h(StatCard, { label: 'Cost', value: formatCost(data.totals.costUsd), sub: data.estimated ? 'Includes estimates' : 'Last ' + data.days + ' days' }),
+            h(StatCard, { label: 'Input Tokens', value: formatNumber(data.totals.inputTokens), trend: data.series.map(function(d) { return d.inputTokens; }) }),
+            h(StatCard, { label: 'Output Tokens', value: formatNumber(data.totals.outputTokens), trend: data.series.map(function(d) { return d.outputTokens; }) }),
+            h(StatCard, { label: 'Model', value: data.modelId || '-', sub: 'In ' + rate(data.inputCostPerMillion) + ' · Out ' + rate(data.outputCostPerMillion) + ' per 1M' })
+          ),
+
+          h('div', { className: 'card', style: { marginBottom: 16 } },
+            h('div', { className: 'card-header' }, h('h3', null, 'Cost per day')),
+            h('div', { className: 'card-body' },
+              data.totals.costUsd === 0
+                ? h('div', { style: _muted }, 'No LLM usage in this period')
+                : h(Fragment, null,
+                    h(BarChart, { data: data.series.map(function(d) { return { label: d.day, value: d.costUsd }; }), height: 100, formatValue: formatCost, title: 'Cost per day' }),
+                    h('div', { style: Object.assign({ display: 'flex', justifyContent: 'space-between', marginTop: 4 }, _muted) },
+                      h('span', null, data.series[0].day), h('span', null, data.series[data.series.length - 1].day)))
+            )
+          ),
+
+          h('div', { className: 'card' },
+            h('div', { className: 'card-header' }, h('h3', null, 'Most expensive conversations')),
+            data.conversations.length === 0
+              ? h('div', { className: 'card-body', style: _muted }, 'No conversations with recorded usage in this period')
+              : h('div', { className: 'card-body-flush' },
+                  h('table', null,
+                    h('thead', null, h('tr', null,
+                      h('th', null, 'Conversation'), h('th', null, 'Calls'), h('th', null, 'Tokens in / out'), h('th', null, 'Cost'), h('th', null, 'Last activity'))),
+                    h('tbody', null, data.conversations.map(function(conv) {
+                      return h('tr', { key: conv.sessionId },
+                        h('td', { style: { maxWidth: 360 } },
+                          h('div', { style: { overflow: 'hidden', textOverflow: 'ellipsis', whiteSpace: 'nowrap' }, title: conv.preview || '' }, conv.preview || h('span', { style: _muted }, 'No transcript')),
+                          h('div', { style: { fontFamily: 'var(--font-mono)', fontSize: 11, color: 'var(--text-muted)' } }, conv.sessionId)),
+                        h('td', null, formatNumber(conv.calls)),
+                        h('td', null, formatNumber(conv.inputTokens) + ' / ' + formatNumber(conv.outputTokens)),
+                        h('td', null, h('strong', null, formatCost(conv.costUsd))),
+                        h('td', { style: _muted }, h(RelativeTime, { value: conv.lastAt })));
+                    }))
+                  )
+                )
+          )
+        )
+  );
+}
@@ -0,0 +1,325 @@
+import { h, useState, useEffect, Fragment, useApp, engineCall, showConfirm } from '../../components/utils.js';
+import { I } from '../../components/icons.js';
+import { HelpButton } from '../../components/help-button.js';
+import { Table } from '../../components/table.js';
+import { Modal } from '../../components/modal.js';
+import { Pagination } from '../../components/pagination.js';
+import { RelativeTime } from '../../components/time.js';
+import { EmptyState, formatNumber } from './shared.js?v=5';
+
+// ════════════════════════════════════════════════════════════
+// WEBHOOKS SECTION
+// ════════════════════════════════════════════════════════════
+
+var PAGE_SIZE = 25;
+var EVENT_LABEL = { 'message.received': 'Message received', 'agent.error': 'Error', 'agent.intervention': 'Intervention', ping: 'Test ping' };
+var STATUS_COLOR = { success: 'success', failed: 'danger', pending: 'warning' };
+
+export function WebhooksSection(props) {
+  var agentId = props.agentId;
+  var app = useApp();
+  var toast = app.toast;
+  var isAdmin = app.user && (app.user.role === 'owner' || app.user.role === 'admin');
+
+  var _data = useState({ webhooks: [], events: [] });
+  var data = _data[0]; var setData = _data[1];
+  var _loading = useState(true);
+  var loading = _loading[0]; var setLoading = _loading[1];
+  var _editing = useState(null);
+  var editing = _editing[0]; var setEditing = _editing[1];
+  // { webhook, secret } right after create or rotate — the only time it's shown
+  var _revealed = useState(null);
+  var revealed = _revealed[0]; var setRevealed = _revealed[1];
+  var _busy = useState(null);
+  var busy = _busy[0]; var setBusy = _busy[1];
+
+  // Delivery log
+  var _log = useState({ deliveries: [], total: 0 });
+  var log = _log[0]; var setLog = _log[1];
+  var _logLoading = useState(true);
+  var logLoading = _logLoading[0]; var setLogLoading = _logLoading[1];
+  var _page = useState(1);
+  var page = _page[0]; var setPage = _page[1];
+  var _hookFilter = useState('');
+  var hookFilter = _hookFilter[0]; var setHookFilter = _hookFilter[1];
+  var _statusFilter = useState('');
+  var statusFilter = _statusFilter[0]; var setStatusFilter = _statusFilter[1];
+  var _selected = useState(null);
+  var selected = _selected[0]; var setSelected = _selected[1];
+
+  var load = function() {
+    setLoading(true);
+    engineCall('/agent-webhooks/agents/' + agentId)
+      .then(setData)
+      .catch(function(err) { toast('Failed to load webhooks: ' + err.message, 'error'); })
+      .finally(function() { setLoading(false); });
+  };
+
+  var loadLog = function() {
+    setLogLoading(true);
+    var qs = '?limit=' + PAGE_SIZE + '&offset=' + (page - 1) * PAGE_SIZE
+      + (hookFilter ? '&webhookId=' + encodeURIComponent(hookFilter) : '')
+      + (statusFilter ? '&status=' + statusFilter : '');
+    engineCall('/agent-webhooks/agents/' + agentId + '/deliveries' + qs)
+      .then(setLog)
+      .catch(function(err) { toast('Failed to load deliveries: ' + err.message, 'error'); })
+      .finally(function() { setLogLoading(false); });
+  };
+
+  var refresh = function() { load(); loadLog(); };
+
+  useEffect(function() { setPage(1); setHookFilter(''); setStatusFilter(''); setRevealed(null); }, [agentId]);
+  useEffect(load, [agentId]);
+  useEffect(loadLog, [agentId, page, hookFilter, statusFilter]);
+
+  var hookUrl = function(id) {
+    var w = data.webhooks.find(function(x) { return x.id === id; });
+    return w ? w.url : 'Deleted webhook';
+  };
+
+  var save = function(form) {
+    var isNew = !form.id;
+    var body = { url: form.url, description: form.description, events: form.events };
+    if (!isNew) body.enabled = form.enabled;
+    return engineCall(isNew ? '/agent-webhooks/agents/' + agentId : '/agent-webhooks/' + form.id, { method: isNew ? 'POST' : 'PATCH', body: JSON.stringify(body) })
+      .then(function(r) {
+        toast(isNew ? 'Webhook added' : 'Webhook saved', 'success');
+        if (r.secret) setRevealed({ webhook: r.webhook, secret: r.secret });
+        setEditing(null);
+        load();
+      });
+  };
+
+  var toggleEnabled = function(w) {
+    engineCall('/agent-webhooks/' + w.id, { method: 'PATCH', body: JSON.stringify({ enabled: !w.enabled }) })
+      .then(function() { toast(w.enabled ? 'Webhook disabled' : 'Webhook enabled', 'success'); load(); })
+      .catch(function(err) { toast(err.message, 'error'); });
+  };
+
+  var test = function(w) {
+    setBusy(w.id);
+    engineCall('/agent-webhooks/' + w.id + '/test', { method: 'POST' })
+      .then(function(r) {
+        var d = r.delivery;
+        toast(d.status === 'success' ? 'Ping delivered (HTTP ' + d.responseCode + ')' : 'Ping failed: ' + (d.error || 'HTTP ' + d.responseCode), d.status === 'success' ? 'success' : 'error');
+        loadLog();
+      })
+      .catch(function(err) { toast(err.message, 'error'); })
+      .finally(function() { setBusy(null); });
+  };
+
+  var rotate = function(w) {
+    showConfirm({ title: 'Rotate Signing Secret', message: 'Generate a new secret for ' + w.url + '? The current secret stops working immediately, so update the receiver right away.', warning: true, confirmText: 'Rotate' }).then(function(ok) {
+      if (!ok) return;
+      engineCall('/agent-webhooks/' + w.id + '/rotate-secret', { method: 'POST' })
+        .then(function(r) { setRevealed({ webhook: w, secret: r.secret }); toast('Secret rotated', 'success'); })
+        .catch(function(err) { toast(err.message, 'error'); });
+    });
+  };
+
+  var remove = function(w) {
+    showConfirm({ title: 'Delete Webhook', message: 'Delete the webhook for ' + w.url + '? Its delivery log is deleted too.', warning: true, confirmText: 'Delete' }).then(function(ok) {
+      if (!ok) return;
+      engineCall('/agent-webhooks/' + w.id, { method: 'DELETE' })
+        .then(function() {
+          toast('Webhook deleted', 'success');
+          if (hookFilter === w.id) setHookFilter('');
+          if (revealed && revealed.webhook.id === w.id) setRevealed(null);
+          refresh();
+        })
+        .catch(function(err) { toast(err.message, 'error'); });
+    });
+  };
+
+  var redeliver = function(d) {
+    engineCall('/agent-webhooks/deliveries/' + d.id + '/redeliver', { method: 'POST' })
+      .then(function(r) {
+        var n = r.delivery;
+        toast(n.status === 'success' ? 'Redelivered (HTTP ' + n.responseCode + ')' : 'Redelivery failed: ' + (n.error || 'HTTP ' + n.responseCode), n.status === 'success' ? 'success' : 'error');
+        setSelected(n);
+        loadLog();
+        load();
+      })
+      .catch(function(err) { toast(err.message, 'error'); });
+  };
+
+  var _muted = { fontSize: 12, color: 'var(--text-muted)' };
+
+  return h(Fragment, null,
+    h('div', { style: { display: 'flex', justifyContent: 'space-between', alignItems: 'center', marginBottom: 16 } },
+      h('h3', { style: { margin: 0, fontSize: 16, display: 'flex', alignItems: 'center' } }, 'Webhooks', h(HelpButton, { label: 'Webhooks' },
+        h('p', null, 'Send an HTTP POST to your own service when something happens to this agent:'),
+        h('ul', { style: { paddingLeft: 20, margin: '4px 0 8px' } },
+          h('li', null, h('strong', null, 'Message received'), ' — a new email, chat or messaging message reached the agent. The payload has a preview of the text.'),
+          h('li', null, h('strong', null, 'Error'), ' — the agent crashed or reported an error.'),
+          h('li', null, h('strong', null, 'Intervention'), ' — the agent was paused, resumed or killed, or a guardrail flagged it.')
+        ),
+        h('p', null, 'Each request carries an X-AgenticMail-Signature header: ', h('code', null, 't=<timestamp>,v1=<HMAC-SHA256>'), ', computed over "<timestamp>.<body>" with the webhook\'s secret. The secret is shown only when the webhook is created or the secret is rotated.'),
+        h('p', null, 'Failed deliveries are retried twice. A webhook that fails 20 deliveries in a row is disabled. The delivery log keeps 30 days.')
+      )),
+      h('div', { style: { display: 'flex', gap: 8 } },
+        h('button', { className: 'btn btn-secondary btn-sm', onClick: refresh, disabled: loading }, I.refresh(), ' Refresh'),
+        isAdmin && h('button', { className: 'btn btn-primary btn-sm', onClick: function() { setEditing({ url: '', description: '', events: ['message.received'], enabled: true }); } }, I.plus(), ' Add Webhook')
+      )
+    ),
+
+    revealed && h('div', { className: 'card', style: { marginBottom: 16, padding: 16, borderLeft: '3px solid var(--warning)' } },
+      h('div', { style: { fontWeight: 600, marginBottom: 4 } }, 'Signing secret for ', revealed.webhook.url),
+      h('div', { style: Object.assign({ marginBottom: 8 }, _muted) }, 'Copy it now — it won\'t be shown again.'),
+      h('div', { style: { display: 'flex', gap: 8, alignItems: 'center' } },
+        h('code', { style: { flex: 1, padding: '6px 10px', background: 'var(--bg-tertiary)', borderRadius: 6, fontSize: 12, wordBreak: 'break-all' } }, revealed.secret),
+        h('button', { className: 'btn btn-secondary btn-sm', onClick: function() { navigator.clipboard.writeText(revealed.secret); toast('Secret copied', 'success'); } }, I.copy(), ' Copy'),
+        h('button', { className: 'btn btn-ghost btn-sm', onClick: function() { setRevealed(null); } }, 'Done')
+      )
+    ),
+
+    h('div', { className: 'card', style: { marginBottom: 16 } },
+      data.webhooks.length === 0
+        ? h(EmptyState, { icon: I.link(), message: loading ? 'Loading...' : 'No webhooks for this agent' + (isAdmin ? '' : '. Ask an admin to add one.') })
+        : h('div', { className: 'card-body-flush' },
+            h(Table, {
+              rows: data.webhooks, refreshing: loading,
+              rowStyle: function(w) { return w.enabled ? null : { opacity: 0.6 }; },
+              columns: [
+                { key: 'url', label: 'Endpoint', render: function(w) {
+                  return h('div', { style: { minWidth: 0 } },
+                    h('div', { style: { fontFamily: 'var(--font-mono)', fontSize: 12, wordBreak: 'break-all' } }, w.url),
+                    w.description && h('div', { style: _muted }, w.description));
+                } },
+                { key: 'events', label: 'Events', render: function(w) {
+                  return h('div', { style: { display: 'flex', gap: 4, flexWrap: 'wrap' } },
+                    w.events.map(function(e) { return h('span', { key: e, className: 'badge badge-neutral' }, EVENT_LABEL[e] || e); }));
+                } },
+                { key: 'enabled', label: 'Status', render: function(w) {
+                  if (w.enabled) return h('span', { className: 'badge badge-success' }, 'Enabled');
+                  return h('span', { className: 'badge badge-warning', title: w.consecutiveFailures ? w.consecutiveFailures + ' failed deliveries in a row' : '' }, 'Disabled');
+                } },
+                { key: 'lastDeliveryAt', label: 'Last delivery', render: function(w) {
+                  if (!w.lastDeliveryAt) return h('span', { style: _muted }, 'Never');
+                  return h('span', { style: { display: 'flex', gap: 6, alignItems: 'center' } },
+                    h('span', { className: 'badge badge-' + (STATUS_COLOR[w.lastStatus] || 'neutral') }, w.lastStatus),
+                    h(RelativeTime, { value: w.lastDeliveryAt }));
+                } },
+                { key: 'actions', label: '', align: 'right', render: function(w) {
+                  return h('div', { style: { display: 'flex', gap: 4, justifyContent: 'flex-end' } },
+                    h('button', { className: 'btn btn-ghost btn-sm', title: 'Show this webhook\'s deliveries', onClick: function() { setHookFilter(w.id); setPage(1); } }, 'Log'),
+                    isAdmin && h('button', { className: 'btn btn-ghost btn-sm', disabled: busy === w.id, onClick: function() { test(w); } }, busy === w.id ? 'Sending...' : 'Test'),
+                    isAdmin && h('button', { className: 'btn btn-ghost btn-sm', onClick: function() { toggleEnabled(w); } }, w.enabled ? 'Disable' : 'Enable'),
+                    isAdmin && h('button', { className: 'btn btn-ghost btn-sm', onClick: function() { setEditing(Object.assign({}, w)); } }, I.edit()),
+                    isAdmin && h('button', { className: 'btn btn-ghost btn-sm', title: 'Rotate signing secret', onClick: function() { rotate(w); } }, I.key()),
+                    isAdmin && h('button', { className: 'btn btn-ghost btn-sm', title: 'Delete', onClick: function() { remove(w); } }, I.trash()));
+                } },
+              ]
+            })
+          )
+    ),
+
+    h('div', { className: 'card' },
+      h('div', { className: 'card-header', style: { display: 'flex', alignItems: 'center', gap: 8, flexWrap: 'wrap' } },
+        h('h3', { style: { marginRight: 'auto' } }, 'Delivery Log', h('span', { style: Object.assign({ marginLeft: 8, fontWeight: 400 }, _muted) }, formatNumber(log.total))),
+        h('select', { className: 'input', value: hookFilter, onChange: function(e) { setHookFilter(e.target.value); setPage(1); }, style: { width: 240 } },
+          h('option', { value: '' }, 'All webhooks'),
+          data.webhooks.map(function(w) { return h('option', { key: w.id, value: w.id }, w.url); })),
+        h('select', { className: 'input', value: statusFilter, onChange: function(e) { setStatusFilter(e.target.value); setPage(1); }, style: { width: 140 } },
+          h('option', { value: '' }, 'Any status'),
+          h('option', { value: 'success' }, 'Succeeded'),
+          h('option', { value: 'failed' }, 'Failed'),
+          h('option', { value: 'pending' }, 'Retrying'))
+      ),
+      log.deliveries.length === 0
+        ? h(EmptyState, { message: logLoading ? 'Loading...' : 'No deliveries' + (hookFilter || statusFilter ? ' match these filters' : ' yet') })
+        : h('div', { className: 'card-body-flush' },
+            h(Table, {
+              rows: log.deliveries, refreshing: logLoading,
+              onRowClick: function(d) { setSelected(d); },
+              columns: [
+                { key: 'createdAt', label: 'Time', render: function(d) { return h(RelativeTime, { value: d.createdAt }); } },
+                { key: 'event', label: 'Event', render: function(d) { return EVENT_LABEL[d.event] || d.event; } },
+                { key: 'webhookId', label: 'Endpoint', render: function(d) { return h('span', { style: { fontFamily: 'var(--font-mono)', fontSize: 12 } }, hookUrl(d.webhookId)); } },
+                { key: 'status', label: 'Status', render: function(d) { return h('span', { className: 'badge badge-' + (STATUS_COLOR[d.status] || 'neutral') }, d.status === 'pending' ? 'retrying' : d.status); } },
+                { key: 'responseCode', label: 'Response', render: function(d) { return d.responseCode ? 'HTTP ' + d.responseCode : h('span', { style: _muted, title: d.error || '' }, d.error ? 'No response' : '-'); } },
+                { key: 'attempts', label: 'Attempts', align: 'right', render: function(d) { return d.attempts; } },
+                { key: 'durationMs', label: 'Duration', align: 'right', render: function(d) { return d.durationMs != null ? d.durationMs + ' ms' : '-'; } },
+              ]
+            })
+          ),
+      h(Pagination, { page: page, pageSize: PAGE_SIZE, total: log.total, onPage: setPage, style: { padding: '8px 12px' } })
+    ),
+
+    editing && h(WebhookForm, { initial: editing, events: data.events, onSave: save, onClose: function() { setEditing(null); } }),
+
+    selected && h(Modal, {
+      title: 'Delivery', large: true, onClose: function() { setSelected(null); },
+      footer: h(Fragment, null,
+        h('button', { className: 'btn btn-secondary', onClick: function() { setSelected(null); } }, 'Close'),
+        isAdmin && data.webhooks.some(function(w) { return w.id === selected.webhookId; }) && h('button', { className: 'btn btn-primary', onClick: function() { redeliver(selected); } }, I.refresh(), ' Redeliver'))
+    },
+      h('div', { style: { display: 'grid', gridTemplateColumns: '140px 1fr', gap: '6px 12px', fontSize: 13, marginBottom: 16 } },
+        h('span', { style: _muted }, 'Event'), h('span', null, EVENT_LABEL[selected.event] || selected.event),
+        h('span', { style: _muted }, 'Endpoint'), h('span', { style: { fontFamily: 'var(--font-mono)', fontSize: 12, wordBreak: 'break-all' } }, hookUrl(selected.webhookId)),
+        h('span', { style: _muted }, 'Status'), h('span', null, h('span', { className: 'badge badge-' + (STATUS_COLOR[selected.status] || 'neutral') }, selected.status === 'pending' ? 'retrying' : selected.status)),
+        h('span', { style: _muted }, 'Response'), h('span', null, selected.responseCode ? 'HTTP ' + selected.responseCode : '-', selected.error && h('span', { style: { color: 'var(--danger)', marginLeft: 8 } }, selected.error)),
+        h('span', { style: _muted }, 'Attempts'), h('span', null, selected.attempts, selected.durationMs != null ? ' · last took ' + selected.durationMs + ' ms' : ''),
+        h('span', { style: _muted }, 'Sent'), h('span', null, new Date(selected.createdAt).toLocaleString()),
+        h('span', { style: _muted }, 'Delivery ID'), h('span', { style: { fontFamily: 'var(--font-mono)', fontSize: 12 } }, selected.id)
+      ),
+      h('div', { style: { fontWeight: 600, fontSize: 13, marginBottom: 4 } }, 'Payload'),
+      h('pre', { style: { background: 'var(--bg-tertiary)', padding: 12, borderRadius: 6, fontSize: 12, maxHeight: 280, overflow: 'auto', whiteSpace: 'pre-wrap', wordBreak: 'break-word' } }, JSON.stringify(selected.payload, null, 2)),
+      selected.responseBody && h(Fragment, null,
+        h('div', { style: { fontWeight: 600, fontSize: 13, margin: '12px 0 4px' } }, 'Response body'),
+        h('pre', { style: { background: 'var(--bg-tertiary)', padding: 12, borderRadius: 6, fontSize: 12, maxHeight: 200, overflow: 'auto', whiteSpace: 'pre-wrap', wordBreak: 'break-word' } }, selected.responseBody))
+    )
+  );
+}
+
+function WebhookForm(props) {
+  var toast = useApp().toast;
+  var _form = useState(props.initial);
+  var form = _form[0]; var setForm = _form[1];
+  var _saving = useState(false);
+  var saving = _saving[0]; var setSaving = _saving[1];
+  var isNew = !form.id;
+  var events = props.events.length ? props.events : ['message.received', 'agent.error', 'agent.intervention'];
+
+  var set = function(key, value) { var next = Object.assign({}, form); next[key] = value; setForm(next); };
+  var toggleEvent = function(e) {
+    set('events', form.events.indexOf(e) >= 0 ? form.events.filter(function(x) { return x !== e; }) : form.events.concat([e]));
+  };
+
+  var submit = function() {
+    if (!form.url.trim()) { toast('Enter the URL to send events to', 'error'); return; }
+    if (form.events.length === 0) { toast('Choose at least one event', 'error'); return; }
+    setSaving(true);
+    props.onSave(form)
+      .catch(function(err) { toast(err.message, 'error'); })
+      .finally(function() { setSaving(false); });
+  };
+
+  return h(Modal, {
+    title: isNew ? 'Add Webhook' : 'Edit Webhook', onClose: props.onClose,
+    footer: h(Fragment, null,
+      h('button', { className: 'btn btn-secondary', onClick: props.onClose }, 'Cancel'),
+      h('button', { className: 'btn btn-primary', disabled: saving, onClick: submit }, saving ? 'Saving...' : isNew ? 'Add Webhook' : 'Save'))
+  },
+    h('div', { className: 'form-group' },
+      h('label', { className: 'form-label' }, 'Payload URL'),
+      h('input', { className: 'input', type: 'url', placeholder: 'https://example.com/hooks/agent', value: form.url, onInput: function(e) { set('url', e.target.value); }, autoFocus: true })
+    ),
+    h('div', { className: 'form-group' },
+      h('label', { className: 'form-label' }, 'Description'),
+      h('input', { className: 'input', placeholder: 'Optional — what receives these events', value: form.description || '', onInput: function(e) { set('description', e.target.value); } })
+    ),
+    h('div', { className: 'form-group' },
+      h('label', { className: 'form-label' }, 'Events'),
+      events.map(function(e) {
+        return h('label', { key: e, style: { display: 'flex', alignItems: 'center', gap: 8, fontSize: 13, marginBottom: 6 } },
+          h('input', { type: 'checkbox', checked: form.events.indexOf(e) >= 0, onChange: function() { toggleEvent(e); } }),
+          EVENT_LABEL[e] || e,
+          h('code', { style: { fontSize: 11, color: 'var(--text-muted)' } }, e));
+      })
+    ),
+    !isNew && h('label', { style: { display: 'flex', alignItems: 'center', gap: 8, fontSize: 13 } },
+      h('input', { type: 'checkbox', checked: form.enabled, onChange: function(e) { set('enabled', e.target.checked); } }), 'Enabled'),
+    isNew && h('div', { style: { fontSize: 12, color: 'var(--text-muted)' } }, 'A signing secret is generated when you add the webhook.')
+  );
+}
@@ -1,7 +1,8 @@
-import { h, useState, useEffect, Fragment, useApp, engineCall, apiCall } from '../../components/utils.js';
+import { h, useState, useEffect, Fragment, useApp, engineCall, apiCall, showConfirm, getOrgId } from '../../components/utils.js';
 import { I } from '../../components/icons.js';
 import { E } from '../../assets/icons/emoji-icons.js';
 import { HelpButton } from '../../components/help-button.js';
+import { timeAgo } from '../../components/time.js';
 
 // ─── Styles ───
 var card = { background: 'var(--bg-secondary)', borderRadius: '12px', padding: '24px', marginBottom: '16px', border: '1px solid var(--border)' };
@@ -660,6 +661,57 @@ function ConversationDetail(props) {
     if (messages.length > 0) loadMessages(messages[0].timestamp);
   };
 
+  // ─── Human takeover ───
+  var toast = useApp().toast;
+  var _takeover = useState(null); var takeover = _takeover[0]; var setTakeover = _takeover[1];
+  var _reply = useState(''); var reply = _reply[0]; var setReply = _reply[1];
+  var _busy = useState(false); var busy = _busy[0]; var setBusy = _busy[1];
+
+  var loadTakeover = function() {
+    engineCall('/takeovers/thread?agentId=' + agentId + '&platform=whatsapp&contactId=' + encodeURIComponent(contact.contactId))
+      .then(function(r) { setTakeover(r.active || null); }).catch(function() {});
+  };
+  useEffect(loadTakeover, [agentId, contact.contactId]);
+
+  // While a human holds the thread, keep the transcript live
+  useEffect(function() {
+    if (!takeover) return;
+    var t = setInterval(function() { loadMessages(); }, 5000);
+    return function() { clearInterval(t); };
+  }, [takeover && takeover.id]);
+
+  var startTakeover = async function() {
+    var ok = await showConfirm({
+      title: 'Take over conversation',
+      message: 'The agent will stop replying to ' + (contact.name || formatPhone(contact.contactId)) + ' until you hand the conversation back. Your replies are sent from the agent\'s number and recorded in the audit log.',
+      confirmText: 'Take over'
+    });
+    if (!ok) return;
+    setBusy(true);
+    engineCall('/takeovers', { method: 'POST', body: JSON.stringify({ agentId: agentId, platform: 'whatsapp', contactId: contact.contactId, orgId: getOrgId() }) })
+      .then(function(r) { setTakeover(r.takeover); toast('You are now handling this conversation', 'success'); })
+      .catch(function(e) { toast(e.message, 'error'); loadTakeover(); })
+      .finally(function() { setBusy(false); });
+  };
+
+  var handBack = function() {
+    setBusy(true);
+    engineCall('/takeovers/' + takeover.id + '/release', { method: 'POST' })
+      .then(function() { setTakeover(null); toast('Conversation handed back to the agent', 'success'); })
+      .catch(function(e) { toast(e.message, 'error'); })
+      .finally(function() { setBusy(false); });
+  };
+
+  var sendReply = function() {
+    var text = reply.trim();
+    if (!text) return;
+    setBusy(true);
+    engineCall('/takeovers/' + takeover.id + '/reply', { method: 'POST', body: JSON.stringify({ text: text }) })
+      .then(function() { setReply(''); loadMessages(); })
+      .catch(function(e) { toast(e.message, 'error'); })
+      .finally(function() { setBusy(false); });
+  };
+
   return h('div', { style: card },
     // Header with back button
     h('div', { style: { display: 'flex', alignItems: 'center', gap: '12px', marginBottom: '16px', paddingBottom: '12px', borderBottom: '1px solid var(--border)' } },
@@ -674,9 +726,20 @@ function ConversationDetail(props) {
           contact.firstAt && (' \u2022 Since ' + new Date(contact.firstAt).toLocaleDateString())
         )
       ),
-      h('div', { style: { display: 'flex', gap: '4px' } },
+      h('div', { style: { display: 'flex', gap: '4px', alignItems: 'center' } },
         contact.isTrusted && h('span', { style: Object.assign({}, tag, { background: '#28a74520', color: '#28a745' }) }, 'Trusted'),
-        contact.isCustomer && h('span', { style: Object.assign({}, tag, { background: '#007bff20', color: '#007bff' }) }, 'Customer')
+        contact.isCustomer && h('span', { style: Object.assign({}, tag, { background: '#007bff20', color: '#007bff' }) }, 'Customer'),
+        takeover
+          ? h('button', { style: Object.assign({}, btnSuccess, { marginLeft: '8px' }), disabled: busy, onClick: handBack }, 'Hand back to agent')
+          : h('button', { style: Object.assign({}, btnP, { marginLeft: '8px' }), disabled: busy, onClick: startTakeover }, 'Take over')
+      )
+    ),
+
+    takeover && h('div', { style: Object.assign({}, infoBox(), { borderColor: 'var(--warning)', display: 'flex', gap: '8px', alignItems: 'center' }) },
+      E.warning(16),
+      h('span', null,
+        h('strong', null, 'Human takeover active. '),
+        'The agent is paused for this conversation since ' + new Date(takeover.takenAt).toLocaleString() + '. Replies below go out from the agent\'s number and are audited.'
       )
     ),
 
@@ -694,6 +757,8 @@ function ConversationDetail(props) {
     !loading && messages.length > 0 && h('div', { style: { display: 'flex', flexDirection: 'column', gap: '4px', maxHeight: '500px', overflow: 'auto', padding: '8px 0' } },
       messages.map(function(msg, i) {
         var isAgent = msg.direction === 'outbound';
+        var meta = typeof msg.metadata === 'string' ? safeJson(msg.metadata) : (msg.metadata || {});
+        var byHuman = isAgent && meta.humanTakeover;
         var showDate = i === 0 || !sameDay(msg.timestamp, messages[i - 1].timestamp);
         return h(Fragment, { key: msg.id || i },
           showDate && h('div', { style: { textAlign: 'center', margin: '12px 0 8px', fontSize: '11px', color: 'var(--text-tertiary)' } },
@@ -702,28 +767,36 @@ function ConversationDetail(props) {
           h('div', { style: { display: 'flex', justifyContent: isAgent ? 'flex-end' : 'flex-start' } },
             h('div', { style: {
               maxWidth: '75%', padding: '8px 12px', borderRadius: isAgent ? '12px 12px 4px 12px' : '12px 12px 12px 4px',
-              background: isAgent ? 'var(--accent)' : 'var(--bg-tertiary)',
+              background: byHuman ? '#b45309' : isAgent ? 'var(--accent)' : 'var(--bg-tertiary)',
               color: isAgent ? 'white' : 'var(--text-primary)',
               fontSize: '13px', lineHeight: '1.5', wordBreak: 'break-word'
             } },
               h('div', null, msg.text),
-              h('div', { style: { fontSize: '10px', opacity: 0.7, marginTop: '4px', textAlign: 'right' } }, formatTime(msg.timestamp))
+              h('div', { style: { fontSize: '10px', opacity: 0.7, marginTop: '4px', textAlign: 'right' } },
+                byHuman ? 'Human reply \u2022 ' : meta.heldForHuman ? 'Held for human \u2022 ' : '', formatTime(msg.timestamp))
             )
           )
         );
       })
+    ),
+
+    // Reply composer (only while a human holds the thread)
+    takeover && h('div', { style: { display: 'flex', gap: '8px', marginTop: '12px', alignItems: 'flex-end' } },
+      h('textarea', {
+        style: Object.assign({}, textareaStyle, { flex: 1 }), rows: 2, value: reply, placeholder: 'Reply as the agent\u2026',
+        onChange: function(e) { setReply(e.target.value); },
+        onKeyDown: function(e) { if (e.key === 'Enter' && (e.metaKey || e.ctrlKey)) sendReply(); }
+      }),
+      h('button', { style: btnP, disabled: busy || !reply.trim(), onClick: sendReply }, busy ? 'Sending...' : 'Send')
     )
   );
 }
 
-// ─── Helpers ───
-function timeAgo(ts) {
-  var d = Date.now() - new Date(ts).getTime();
-  if (d < 60000) return 'just now';
-  if (d < 3600000) return Math.floor(d / 60000) + 'm ago';
-  if (d < 86400000) return Math.floor(d / 3600000) + 'h ago';
-  return Math.floor(d / 86400000) + 'd ago';
+function safeJson(s) {
+  try { return JSON.parse(s) || {}; } catch (e) { return {}; }
 }
+
+// ─── Helpers ───
 function formatPhone(id) {
   if (!id) return '';
   var num = id.replace(/@.*$/, '').replace(/[^0-9+]/g, '');
@@ -18,6 +18,8 @@ export function WorkforceSection(props) {
 
   var _schedule = useState(null);
   var schedule = _schedule[0]; var setSchedule = _schedule[1];
+  var _nextWindow = useState(null);
+  var nextWindow = _nextWindow[0]; var setNextWindow = _nextWindow[1];
   var _status = useState(null);
   var status = _status[0]; var setStatus = _status[1];
   var _tasks = useState([]);
@@ -94,6 +96,7 @@ export function WorkforceSection(props) {
     ]).then(function(results) {
       var sched = results[0]?.schedule || results[0];
       setSchedule(sched);
+      setNextWindow(results[0]?.nextWindow || null);
       setStatus(results[1]);
       setTasks(results[2]?.tasks || results[2] || []);
       setClockRecords(results[3]?.records || results[3] || []);
@@ -216,6 +219,11 @@ export function WorkforceSection(props) {
 
   var formatDays = function(days) { return days?.map(function(d) { return dayNames[d]; }).join(', ') || '-'; };
 
+  var ooo = schedForm.config?.outOfOffice || { enabled: true };
+  var setOoo = function(patch) {
+    setSchedForm(Object.assign({}, schedForm, { config: Object.assign({}, schedForm.config, { outOfOffice: Object.assign({}, ooo, patch) }) }));
+  };
+
   if (loading) {
     return h('div', { style: { padding: 40, textAlign: 'center', color: 'var(--text-muted)' } }, 'Loading workforce data...');
   }
@@ -366,6 +374,24 @@ export function WorkforceSection(props) {
                   h('input', { className: 'input', type: 'number', value: schedForm.gracePeriodMinutes, onChange: function(e) { setSchedForm(Object.assign({}, schedForm, { gracePeriodMinutes: parseInt(e.target.value) || 0 })); } })
                 )
               ),
+              // Out-of-office auto-reply
+              h('div', { style: { marginTop: 12, paddingTop: 12, borderTop: '1px solid var(--border)' } },
+                h('label', { style: { display: 'flex', alignItems: 'center', gap: 6, cursor: 'pointer', fontSize: 13, marginBottom: 8 } },
+                  h('input', { type: 'checkbox', checked: ooo.enabled !== false, onChange: function(e) { setOoo({ enabled: e.target.checked }); } }),
+                  'Out-of-office auto-reply while off duty'
+                ),
+                ooo.enabled !== false && h(Fragment, null,
+                  h('div', { className: 'form-group' },
+                    h('label', { className: 'form-label' }, 'Subject'),
+                    h('input', { className: 'input', placeholder: 'Out of Office - ' + (engineAgent?.config?.displayName || engineAgent?.config?.name || 'Agent'), value: ooo.subject || '', onChange: function(e) { setOoo({ subject: e.target.value }); } })
+                  ),
+                  h('div', { className: 'form-group' },
+                    h('label', { className: 'form-label' }, 'Message'),
+                    h('textarea', { className: 'input', rows: 4, placeholder: 'Leave blank for the default message, which lists the working hours and when the agent is back.', value: ooo.message || '', onChange: function(e) { setOoo({ message: e.target.value }); } }),
+                    h('div', { style: { fontSize: 11, color: 'var(--text-muted)', marginTop: 4 } }, '{nextStart} and {hours} are replaced with when the agent is back and its working hours. The agent\'s name is added as a sign-off.')
+                  )
+                )
+              ),
               // Actions
               h('div', { style: { display: 'flex', gap: 8, marginTop: 16, justifyContent: 'space-between' } },
                 h('div', { style: { display: 'flex', gap: 8 } },
@@ -410,6 +436,16 @@ export function WorkforceSection(props) {
                     h('span', { className: schedule.enabled ? 'badge badge-success' : 'badge badge-neutral' }, schedule.enabled ? 'Enabled' : 'Disabled'),
                     schedule.autoWakeEnabled && h('span', { className: 'badge badge-info' }, 'Auto-Wake')
                   )
+                ),
+                h('div', null,
+                  h('div', { style: { fontSize: 12, color: 'var(--text-muted)', marginBottom: 4 } }, 'Next Active Window'),
+                  h('div', { style: { fontSize: 14, fontWeight: 600 } }, h(NextWindowLabel, { window: nextWindow, enabled: schedule.enabled }))
+                ),
+                h('div', null,
+                  h('div', { style: { fontSize: 12, color: 'var(--text-muted)', marginBottom: 4 } }, 'Out of Office'),
+                  schedule.config?.outOfOffice?.enabled === false
+                    ? h('span', { className: 'badge badge-neutral' }, 'No auto-reply')
+                    : h('div', { style: { fontSize: 13 }, title: schedule.config?.outOfOffice?.message || 'Default message' }, schedule.config?.outOfOffice?.subject || (schedule.config?.outOfOffice?.message ? 'Custom message' : 'Default message'))
                 )
               )
             : h('div', { style: { textAlign: 'center', padding: 20, color: 'var(--text-muted)', fontSize: 13 } },
@@ -681,3 +717,17 @@ export function WorkforceSection(props) {
   );
 }
 
+
+/**
+ * "Active now until Fri 17:05" / "Next active Mon 08:55 – 17:05" from the
+ * engine's nextWindow, in the viewer's locale.
+ */
+export function NextWindowLabel(props) {
+  var w = props.window;
+  if (props.enabled === false) return h('span', { style: { color: 'var(--text-muted)' } }, 'Schedule disabled');
+  if (!w) return h('span', { style: { color: 'var(--text-muted)' } }, 'No working time in the next week');
+  var fmt = function(iso) { return new Date(iso).toLocaleString(undefined, { weekday: 'short', hour: '2-digit', minute: '2-digit' }); };
+  var title = 'Schedule timezone: ' + w.timezone + (props.compact ? '' : '. Times include the grace period.');
+  if (w.activeNow) return h('span', { title: title }, (props.compact ? 'on duty' : 'Active now') + (w.end ? ' until ' + fmt(w.end) : ''));
+  return h('span', { title: title }, (props.compact ? 'off duty, back ' : 'Next active ') + fmt(w.start) + (!props.compact && w.end ? ' – ' + fmt(w.end) : ''));
+}
@@ -1,11 +1,18 @@
-import { h, useState, useEffect, useCallback, Fragment, useApp, apiCall, engineCall, DEPLOY_PHASES, DEPLOY_PHASE_LABELS, showConfirm, getOrgId } from '../components/utils.js';
+import { h, useState, useEffect, useCallback, Fragment, useApp, apiCall, engineCall, DEPLOY_PHASES, DEPLOY_PHASE_LABELS, showConfirm, getOrgId, downloadCsv } from '../components/utils.js';
 import { I } from '../components/icons.js';
 import { E } from '../assets/icons/emoji-icons.js';
-import { CULTURES, LANGUAGES, PersonaForm, LanguageSelect, getLanguageName } from '../components/persona-fields.js';
+import { CULTURES, LANGUAGES, PersonaForm, LanguageSelect, getLanguageName, saveAvatar } from '../components/persona-fields.js';
 import { HelpButton } from '../components/help-button.js';
 import { DuplicateAgentModal } from '../components/duplicate-agent.js';
 import { useOrgContext } from '../components/org-switcher.js';
 import { KnowledgeLink } from '../components/knowledge-link.js';
+import { useFormDraft, DraftPrompt } from '../components/drafts.js';
+import { useFragment } from '../components/fragments.js';
+import { Table, useSort } from '../components/table.js';
+import { Pagination, usePageSize } from '../components/pagination.js';
+import { FilterBar, useFilters } from '../components/filter-bar.js';
+import { ClassificationChip, useClassifications } from '../components/classification.js';
+import { useLiveAgentStatus, LiveStatusBadge } from '../components/agent-status.js';
 
 // ════════════════════════════════════════════════════════════
 // DEPLOY MODAL
@@ -236,6 +243,8 @@ export function DeploymentProgress({ agentId, onComplete }) {
 // ════════════════════════════════════════════════════════════
 
 export function CreateAgentWizard({ onClose, onCreated, toast }) {
+  const app = useApp();
+  const canManageTemplates = app.permissions === '*' || (app.permissions && 'roles' in app.permissions);
   const [step, setStep] = useState(0);
   const steps = ['Role', 'Basics', 'Persona', 'Skills', 'Permissions', 'Deployment', 'Review'];
   const [form, setForm] = useState({ name: '', email: '', role: 'assistant', description: '', personality: '', skills: [], preset: null, customTools: { allowed: [], blocked: [] }, deployTarget: 'fly', knowledgeBases: [], provider: '', model: '', approvalRequired: true, soulId: null, avatar: null, gender: '', dateOfBirth: '', maritalStatus: '', culturalBackground: '', language: 'en-us', autoOnboard: true, maxRiskLevel: 'medium', blockedSideEffects: ['runs-code', 'deletes-data', 'financial', 'controls-device'], approvalForRiskLevels: ['high', 'critical'], approvalForSideEffects: ['sends-email', 'sends-message'], rateLimits: { toolCallsPerMinute: 30, toolCallsPerHour: 500, toolCallsPerDay: 5000, externalActionsPerHour: 50 }, constraints: { maxConcurrentTasks: 5, maxSessionDurationMinutes: 480, sandboxMode: false }, traits: { communication: 'direct', detail: 'detail-oriented', energy: 'calm', humor: 'warm', formality: 'adaptive', empathy: 'moderate', patience: 'patient', creativity: 'creative' } });
@@ -252,39 +261,32 @@ export function CreateAgentWizard({ onClose, onCreated, toast }) {
   const [showSetupGuide, setShowSetupGuide] = useState(false);
   const [draftSaved, setDraftSaved] = useState(false);
 
-  // Load draft from localStorage on mount
-  useEffect(function() {
-    try {
-      var draft = localStorage.getItem('em_agent_draft');
-      if (draft) {
-        var parsed = JSON.parse(draft);
-        setForm(function(f) { return Object.assign({}, f, parsed); });
-        if (parsed._step) setStep(parsed._step);
-      }
-    } catch {}
-  }, []);
+  // Server-side draft (per user) — autosaves once the agent has a name
+  var draft = useFormDraft('create-agent', Object.assign({}, form, { _step: step }), { enabled: !!form.name });
+  var resumeDraft = function() {
+    var data = draft.resume();
+    if (!data) return;
+    setForm(function(f) { return Object.assign({}, f, data, { _step: undefined }); });
+    if (data._step) setStep(data._step);
+  };
 
-  // Save draft function
+  // Save draft now (autosave also runs in the background)
   var saveDraft = useCallback(function() {
-    try {
-      localStorage.setItem('em_agent_draft', JSON.stringify(Object.assign({}, form, { _step: step })));
-      setDraftSaved(true);
-      setTimeout(function() { setDraftSaved(false); }, 2000);
-    } catch {}
+    engineCall('/drafts/create-agent', { method: 'PUT', body: JSON.stringify({ data: Object.assign({}, form, { _step: step }) }) })
+      .then(function() {
+        setDraftSaved(true);
+        setTimeout(function() { setDraftSaved(false); }, 2000);
+      })
+      .catch(function(e) { toast('Could not save draft: ' + e.message, 'error'); });
   }, [form, step]);
 
-  // Auto-save draft on step change
-  useEffect(function() {
-    if (form.name) {
-      try { localStorage.setItem('em_agent_draft', JSON.stringify(Object.assign({}, form, { _step: step }))); } catch {}
-    }
-  }, [step]);
-
   // Clear draft after successful creation
-  var clearDraft = function() { try { localStorage.removeItem('em_agent_draft'); } catch {} };
+  var clearDraft = function() { draft.clear(); try { localStorage.removeItem('em_agent_draft'); } catch {} };
   const [setupChecked, setSetupChecked] = useState(false);
+  const [residency, setResidency] = useState(null);
 
   useEffect(() => {
+    engineCall('/residency/config').then(d => setResidency(d.config || null)).catch(() => {});
     engineCall('/skills/by-category').then(d => setAllSkills(d.categories || {})).catch(() => {});
     engineCall('/profiles/presets').then(d => setPresets(d.presets || [])).catch(() => {});
     engineCall('/souls/by-category?orgId=' + (getOrgId() || '')).then(d => { setSoulCategories(d.categories || {}); setSoulMeta(d.categoryMeta || {}); }).catch(() => {});
@@ -439,7 +441,7 @@ export function CreateAgentWizard({ onClose, onCreated, toast }) {
   for (const [cat, templates] of Object.entries(soulCategories)) {
     if (!soulSearch) { filteredCategories[cat] = templates; continue; }
     const q = soulSearch.toLowerCase();
-    const filtered = templates.filter(t => t.name.toLowerCase().includes(q) || t.description.toLowerCase().includes(q) || (t.tags || []).some(tag => tag.includes(q)));
+    const filtered = templates.filter(t => t.name.toLowerCase().includes(q) || (t.description || '').toLowerCase().includes(q) || (t.tags || []).some(tag => tag.includes(q)));
     if (filtered.length > 0) filteredCategories[cat] = filtered;
   }
 
@@ -457,6 +459,7 @@ export function CreateAgentWizard({ onClose, onCreated, toast }) {
         model: { provider: form.provider || 'anthropic', modelId: form.model === 'custom' ? (form.customModelId || form.model) : form.model },
         deployment: { target: form.deployTarget },
         deployTarget: form.deployTarget,
+        dataRegion: form.dataRegion || undefined,
         skills: form.skills || [],
         knowledgeBases: form.knowledgeBases || [],
         presetName: form.preset || undefined,
@@ -474,7 +477,6 @@ export function CreateAgentWizard({ onClose, onCreated, toast }) {
           constraints: form.constraints,
         },
         persona: {
-          avatar: form.avatar || undefined,
           gender: form.gender || undefined,
           dateOfBirth: form.dateOfBirth || undefined,
           maritalStatus: form.maritalStatus || undefined,
@@ -488,6 +490,11 @@ export function CreateAgentWizard({ onClose, onCreated, toast }) {
 
       const agentId = result?.agentId || result?.agent?.id;
 
+      // The avatar goes to the backend once the agent exists
+      if (form.avatar && agentId) {
+        await saveAvatar(agentId, form.avatar, null).catch(e => toast('Agent created but the avatar was not saved: ' + e.message, 'warning'));
+      }
+
       if (form.autoOnboard && agentId) {
         try {
           await engineCall('/onboarding/initiate/' + agentId, { method: 'POST', body: JSON.stringify({ orgId: getOrgId() }) });
@@ -595,6 +602,7 @@ export function CreateAgentWizard({ onClose, onCreated, toast }) {
           ),
           // ─── Step content ───
           h('div', { className: 'wizard-content' },
+            h(DraftPrompt, { draft: draft.pending, onResume: resumeDraft, onDiscard: draft.discard }),
 
             // Step 0: Role (Soul Template Selector)
             step === 0 && h(Fragment, null,
@@ -718,9 +726,11 @@ export function CreateAgentWizard({ onClose, onCreated, toast }) {
                 );
               })(),
 
-              h('div', { style: { marginBottom: 14 } },
-                h('input', { className: 'input', value: soulSearch, onChange: e => setSoulSearch(e.target.value), placeholder: 'Search roles (e.g., support, engineer, analyst...)' })
+              h('div', { style: { marginBottom: 14, display: 'flex', gap: 8 } },
+                h('input', { className: 'input', value: soulSearch, onChange: e => setSoulSearch(e.target.value), placeholder: 'Search roles (e.g., support, engineer, analyst...)', style: { flex: 1 } }),
+                canManageTemplates && h('button', { className: 'btn btn-ghost btn-sm', title: 'Create and edit custom role templates', onClick: () => { onClose(); app.setPage('roles'); } }, 'Manage templates')
               ),
+              Object.values(filteredCategories).every(t => t.length === 0) && h('div', { style: { padding: 24, textAlign: 'center', color: 'var(--text-muted)', fontSize: 13 } }, soulSearch ? 'No role templates match "' + soulSearch + '"' : 'No role templates available. Configure the agent manually instead.'),
               h('div', null,
                 Object.entries(filteredCategories).map(([cat, templates]) =>
                   h('div', { key: cat, style: { marginBottom: 20 } },
@@ -1033,6 +1043,15 @@ export function CreateAgentWizard({ onClose, onCreated, toast }) {
                     h('p', null, { fly: 'Deploy to Fly.io with auto-scaling, TLS, and health checks. Recommended for production.', docker: 'Run in an isolated Docker container with resource limits.', railway: 'Deploy to Railway with zero-config builds and auto-deploys.', vps: 'Deploy to a VPS or dedicated server via SSH. Full control.', local: 'Run on the current machine. Best for development and testing.' }[t])
                   )
                 )
+              ),
+              // Multi-region deployments: pin the agent and its mailbox to a residency region
+              residency && residency.regions.length > 0 && h('div', { style: { marginTop: 20 } },
+                h('label', { className: 'form-label' }, 'Data residency region'),
+                h('select', { className: 'input', style: { maxWidth: 320 }, value: form.dataRegion || '', onChange: e => set('dataRegion', e.target.value) },
+                  h('option', { value: '' }, residency.defaultRegion ? 'Default (' + ((residency.regions.find(r => r.id === residency.defaultRegion) || {}).label || residency.defaultRegion) + ')' : 'Not pinned'),
+                  residency.regions.map(r => h('option', { key: r.id, value: r.id }, r.label + (r.jurisdiction ? ' \u2014 ' + r.jurisdiction : '')))
+                ),
+                h('div', { className: 'form-help' }, 'The agent\'s mailbox, memory and messages stay in this region. Cloud deploys use one of its regions.')
               )
             ),
 
@@ -1088,6 +1107,9 @@ export function CreateAgentWizard({ onClose, onCreated, toast }) {
                     h('span', { style: { color: 'var(--text-muted)' } }, 'Rate Limits'), h('span', null, form.rateLimits.toolCallsPerMinute + '/min, ' + form.rateLimits.toolCallsPerHour + '/hr, ' + form.rateLimits.toolCallsPerDay + '/day'),
                     h('span', { style: { color: 'var(--text-muted)' } }, 'Constraints'), h('span', null, form.constraints.maxConcurrentTasks + ' tasks, ' + form.constraints.maxSessionDurationMinutes + 'min max' + (form.constraints.sandboxMode ? ', sandbox' : '')),
                     h('span', { style: { color: 'var(--text-muted)' } }, 'Deployment'), h('span', null, { fly: 'Fly.io', docker: 'Docker Container', railway: 'Railway', vps: 'VPS / Dedicated Server', local: 'Local Machine' }[form.deployTarget] || form.deployTarget),
+                    residency && residency.regions.length > 0 && h(Fragment, null,
+                      h('span', { style: { color: 'var(--text-muted)' } }, 'Data Region'), h('span', null, (residency.regions.find(r => r.id === (form.dataRegion || residency.defaultRegion)) || {}).label || 'Not pinned')
+                    ),
                     h('span', { style: { color: 'var(--text-muted)' } }, 'Onboarding'), h('span', null, form.autoOnboard ? h('span', { className: 'badge badge-success' }, 'Auto-start') : h('span', { className: 'badge badge-neutral' }, 'Manual'))
                   )
                 )
@@ -1131,45 +1153,78 @@ export function AgentsPage({ onSelectAgent }) {
   const app = useApp();
   const toast = app.toast;
   var orgCtx = useOrgContext();
-  const [agents, setAgents] = useState([]);
   const [creating, setCreating] = useState(false);
-  const [liveStatuses, setLiveStatuses] = useState({});
   const [duplicatingAgent, setDuplicatingAgent] = useState(null);
+  const mailboxClassifications = useClassifications('mailbox');
 
-  // Subscribe to real-time agent status
-  useEffect(function() {
-    var es = new EventSource('/api/engine/agent-status-stream');
-    es.onmessage = function(ev) {
-      try {
-        var d = JSON.parse(ev.data);
-        if (d.type === 'status' && d.agentId) {
-          setLiveStatuses(function(prev) {
-            var next = Object.assign({}, prev);
-            next[d.agentId] = d;
-            return next;
-          });
-        }
-      } catch(e) {}
-    };
-    return function() { es.close(); };
-  }, []);
+  // What each agent's process is doing now, rather than the stored status
+  var liveStatuses = useLiveAgentStatus();
 
   const perms = app.permissions || '*';
   const allowedAgents = perms === '*' ? '*' : (perms._allowedAgents || '*');
 
-  const load = () => apiCall('/agents').then(d => {
-    console.log('[agents] response type:', typeof d, 'keys:', d ? Object.keys(d) : 'null', 'agents type:', typeof d?.agents);
-    var all = Array.isArray(d?.agents) ? d.agents : Array.isArray(d) ? d : [];
-    if (allowedAgents !== '*' && Array.isArray(allowedAgents)) {
-      all = all.filter(a => allowedAgents.indexOf(a.id) >= 0);
-    }
-    // Filter by selected org context
-    if (orgCtx.selectedOrgId) {
-      all = all.filter(a => a.client_org_id === orgCtx.selectedOrgId);
-    }
-    setAgents(all);
-  }).catch(() => {});
-  useEffect(() => { load(); }, [orgCtx.selectedOrgId]);
+  // Table rows come from the /agents/table fragment so paging and refreshes only swap the rows
+  var sort = useSort('createdAt', 'desc');
+  var filters = useFilters({ q: '', status: '', role: '', provider: '', model: '', team: '' });
+  var [facets, setFacets] = useState({ roles: [], providers: [], models: [], teams: [] });
+  useEffect(() => {
+    apiCall('/agents/facets' + (orgCtx.selectedOrgId ? '?clientOrgId=' + encodeURIComponent(orgCtx.selectedOrgId) : ''))
+      .then(setFacets).catch(() => {});
+  }, [orgCtx.selectedOrgId]);
+  var [pageSize, setPageSize] = usePageSize('agents', 50);
+  var table = useFragment('/agents/table', Object.assign({ clientOrgId: orgCtx.selectedOrgId || undefined }, filters.params, sort.params), { pageSize: pageSize });
+  var agents = table.rows;
+  if (allowedAgents !== '*' && Array.isArray(allowedAgents)) {
+    agents = agents.filter(a => allowedAgents.indexOf(a.id) >= 0);
+  }
+  const load = table.reload;
+
+  // ─── Bulk operations ─────────────────────────────────
+  // Each agent is a separate call to the same endpoint as its own button, so
+  // one failure never holds up the rest and every agent gets its own result.
+  const [selected, setSelected] = useState([]);
+  const [bulkBusy, setBulkBusy] = useState(null);
+  const [bulkResult, setBulkResult] = useState(null);
+  useEffect(() => { setSelected([]); }, [filters.query, table.page, orgCtx.selectedOrgId]);
+
+  var allSelected = agents.length > 0 && agents.every(a => selected.includes(a.id));
+  const toggleSelected = (id) => setSelected(sel => sel.includes(id) ? sel.filter(x => x !== id) : sel.concat([id]));
+
+  const BULK_ACTIONS = {
+    archive: { label: 'Archive', busy: 'Archiving...', done: 'archived', danger: true, call: id => apiCall('/agents/' + id + '/archive', { method: 'POST' }),
+      message: 'Archived agents stop working and are hidden from the default list.' },
+    deploy: { label: 'Deploy', busy: 'Deploying...', done: 'deployed', call: id => engineCall('/agents/' + id + '/deploy', { method: 'POST', body: JSON.stringify({ deployedBy: 'dashboard' }) }),
+      message: 'Each agent is deployed to its configured target.' },
+    stop: { label: 'Stop', busy: 'Stopping...', done: 'stopped', danger: true, call: id => engineCall('/agents/' + id + '/stop', { method: 'POST', body: JSON.stringify({ stoppedBy: 'dashboard', reason: 'Bulk stop' }) }),
+      message: 'Agents stop processing email and tasks until they are started again.' },
+    restart: { label: 'Restart', busy: 'Restarting...', done: 'restarted', call: id => engineCall('/agents/' + id + '/restart', { method: 'POST', body: JSON.stringify({ restartedBy: 'dashboard' }) }),
+      message: 'In-flight work on each agent is interrupted while it restarts.' },
+  };
+
+  const runBulk = async (action) => {
+    var op = BULK_ACTIONS[action];
+    var targets = agents.filter(a => selected.includes(a.id));
+    var n = targets.length;
+    var ok = await showConfirm({
+      title: op.label + ' ' + n + ' agent' + (n === 1 ? '' : 's') + '?',
+      message: op.message,
+      danger: !!op.danger,
+      confirmText: op.label,
+    });
+    if (!ok) return;
+    setBulkBusy(action);
+    setBulkResult(null);
+    var settled = await Promise.allSettled(targets.map(a => op.call(a.id)));
+    var results = targets.map((a, i) => ({ id: a.id, name: a.name, ok: settled[i].status === 'fulfilled', error: settled[i].reason ? settled[i].reason.message : null }));
+    var failed = results.filter(r => !r.ok);
+    toast(failed.length === 0
+      ? (n === 1 ? '1 agent ' : n + ' agents ') + op.done
+      : (n - failed.length) + ' ' + op.done + ' · ' + failed.length + ' failed', failed.length === 0 ? 'success' : n === failed.length ? 'error' : 'warning');
+    setBulkResult({ action, results });
+    setSelected(failed.map(r => r.id));
+    setBulkBusy(null);
+    load();
+  };
 
   // Delete moved to agent detail overview tab with triple confirmation
 
@@ -1190,10 +1245,50 @@ export function AgentsPage({ onSelectAgent }) {
         ),
         h('div', { style: _tip }, h('strong', null, 'Tip: '), 'Click an agent\'s name to access their full detail page with logs, email, workforce schedule, and more.')
       )), h('p', { style: { color: 'var(--text-muted)', fontSize: 13 } }, 'Manage your AI agents — create, configure, deploy, and monitor')),
-      h('button', { className: 'btn btn-primary', onClick: () => setCreating(true) }, I.plus(), ' Create Agent')
+      h('div', { style: { display: 'flex', gap: 8, alignItems: 'center' } },
+        !table.loading && table.total > 0 && h('span', { style: { fontSize: 12, color: 'var(--text-muted)' } }, table.total + ' total'),
+        h('button', { className: 'btn btn-secondary', title: 'Download every agent matching the current filters', onClick: () => downloadCsv('/agents?' + [orgCtx.selectedOrgId ? 'clientOrgId=' + encodeURIComponent(orgCtx.selectedOrgId) : '', filters.query, sort.query].filter(Boolean).join('&')).catch(err => toast(err.message, 'error')) }, I.download(), ' Export CSV'),
+        h('button', { className: 'btn btn-primary', onClick: () => setCreating(true) }, I.plus(), ' Create Agent')
+      )
     ),
     creating && h(CreateAgentWizard, { onClose: () => setCreating(false), onCreated: load, toast }),
-    agents.length === 0
+    h(FilterBar, {
+      filters, placeholder: 'Search name or email...', viewsKey: 'agents',
+      fields: [
+        { key: 'status', label: 'All statuses', options: ['draft', 'pending-approval', 'active', 'suspended', 'offboarding', 'retired', 'archived'] },
+        { key: 'role', label: 'All roles', options: facets.roles },
+        { key: 'provider', label: 'All providers', options: facets.providers },
+        { key: 'model', label: 'All models', width: 220, options: facets.models.map(m => ({ value: m, label: m })) },
+        { key: 'team', label: 'All teams', options: (facets.teams || []).map(t => ({ value: t.id, label: t.name })) },
+      ],
+    }),
+    selected.length > 0 && h('div', { className: 'card', style: { marginBottom: 12, padding: '8px 12px', display: 'flex', alignItems: 'center', gap: 8 } },
+      h('span', { style: { fontSize: 13, flex: 1 } }, selected.length + ' selected'),
+      h('button', { className: 'btn btn-ghost btn-sm', disabled: !!bulkBusy, onClick: () => setSelected([]) }, 'Clear'),
+      h('button', {
+        className: 'btn btn-secondary btn-sm', disabled: !!bulkBusy || selected.length < 2 || selected.length > 6,
+        title: selected.length < 2 ? 'Select at least two agents to compare' : selected.length > 6 ? 'Compare up to 6 agents at a time' : 'Compare the selected agents side by side',
+        onClick: () => { history.pushState(null, '', '/dashboard/agents/compare?ids=' + selected.map(encodeURIComponent).join(',')); window.dispatchEvent(new PopStateEvent('popstate')); }
+      }, 'Compare'),
+      ['deploy', 'restart', 'stop', 'archive'].map(action => h('button', {
+        key: action, className: 'btn btn-' + (BULK_ACTIONS[action].danger ? 'danger' : 'secondary') + ' btn-sm', disabled: !!bulkBusy, onClick: () => runBulk(action)
+      }, bulkBusy === action ? BULK_ACTIONS[action].busy : BULK_ACTIONS[action].label))
+    ),
+    bulkResult && h('div', { className: 'card', style: { marginBottom: 12 } },
+      h('div', { className: 'card-header', style: { display: 'flex', justifyContent: 'space-between', alignItems: 'center' } },
+        h('h3', null, BULK_ACTIONS[bulkResult.action].label + ': ' + bulkResult.results.filter(r => r.ok).length + ' of ' + bulkResult.results.length + ' succeeded'),
+        h('button', { className: 'btn btn-ghost btn-sm', onClick: () => setBulkResult(null) }, 'Dismiss')),
+      h('div', { className: 'card-body', style: { display: 'grid', gap: 4, maxHeight: 240, overflowY: 'auto' } },
+        bulkResult.results.map(r => h('div', { key: r.id, style: { display: 'flex', alignItems: 'baseline', gap: 8, fontSize: 13 } },
+          h('span', { className: 'badge badge-' + (r.ok ? 'success' : 'danger'), style: { minWidth: 56, textAlign: 'center' } }, r.ok ? 'done' : 'failed'),
+          h('strong', null, r.name),
+          r.error && h('span', { style: { color: 'var(--text-muted)' } }, r.error))))
+    ),
+    table.loading
+      ? h('div', { className: 'card' }, h('div', { style: { padding: 24, textAlign: 'center', color: 'var(--text-muted)' } }, 'Loading...'))
+    : agents.length === 0 && table.page === 1 && filters.active > 0
+      ? h('div', { className: 'card' }, h('div', { style: { padding: 24, textAlign: 'center', color: 'var(--text-muted)' } }, 'No agents match these filters'))
+    : agents.length === 0 && table.page === 1
       ? h('div', { className: 'card' }, h('div', { className: 'card-body' },
           h('div', { className: 'empty-state' },
             I.agents(),
@@ -1204,36 +1299,40 @@ export function AgentsPage({ onSelectAgent }) {
         ))
       : h('div', { className: 'card' },
           h('div', { className: 'card-body-flush' },
-            h('table', null,
-              h('thead', null, h('tr', null, h('th', null, 'Name'), h('th', null, 'Email'), h('th', null, 'Role'), h('th', null, 'Status'), h('th', null, 'Created'), h('th', { style: { width: 180 } }, 'Actions'))),
-              h('tbody', null, agents.map(a =>
-                h('tr', { key: a.id },
-                  h('td', null, h('strong', { style: { cursor: 'pointer', color: 'var(--accent-text)' }, onClick: () => onSelectAgent && onSelectAgent(a.id) }, a.name)),
-                  h('td', null, h('span', { style: { fontFamily: 'var(--font-mono)', fontSize: 12 } }, a.email || '-')),
-                  h('td', null, h('span', { className: 'badge badge-neutral' }, a.role || 'agent')),
-                  h('td', null, (function() {
-                    var live = liveStatuses[a.id];
-                    var st = live ? live.status : null;
-                    var label = st === 'online' ? 'running' : st === 'idle' ? 'idle' : st === 'offline' ? 'stopped' : st === 'error' ? 'error' : (a.status || 'active');
-                    var color = { running: 'success', idle: 'info', stopped: 'neutral', error: 'danger', active: 'success', archived: 'neutral' }[label] || 'warning';
-                    var activity = live && live.currentActivity ? live.currentActivity.detail || live.currentActivity.type : null;
-                    return h(Fragment, null,
-                      h('span', { className: 'badge badge-' + color, style: { textTransform: 'capitalize' } }, label),
-                      activity && h('span', { style: { fontSize: 10, color: 'var(--text-muted)', marginLeft: 6, fontStyle: 'italic' } }, activity)
-                    );
-                  })()),
-                  h('td', { style: { fontSize: 12, color: 'var(--text-muted)' } }, a.createdAt ? new Date(a.createdAt).toLocaleDateString() : '-'),
-                  h('td', null,
-                    h('div', { style: { display: 'flex', gap: 4 } },
-                      h('button', { className: 'btn btn-primary btn-sm', onClick: () => onSelectAgent && onSelectAgent(a.id) }, 'View Details'),
-                      h('button', { className: 'btn btn-ghost btn-sm', title: 'Duplicate Agent', onClick: (e) => { e.stopPropagation(); setDuplicatingAgent(a); } }, I.copy()),
-                      h('button', { className: 'btn btn-ghost btn-sm', title: 'Restart', onClick: () => engineCall('/agents/' + a.id + '/restart', { method: 'POST', body: JSON.stringify({ restartedBy: 'dashboard' }) }).then(() => toast('Restarting...', 'info')).catch(e => toast(e.message, 'error')) }, I.refresh())
-                    )
-                  )
-                )
-              ))
-            )
-          )
+            h(Table, {
+              prefsKey: 'agents',
+              sort: sort.sort, onSort: sort.toggle, refreshing: table.refreshing, rows: agents,
+              columns: [
+                { key: 'select', label: h('input', { type: 'checkbox', checked: allSelected, 'aria-label': 'Select all', onChange: () => setSelected(allSelected ? [] : agents.map(a => a.id)) }), width: 32, required: true,
+                  render: a => h('input', { type: 'checkbox', checked: selected.includes(a.id), 'aria-label': 'Select ' + a.name, onClick: e => e.stopPropagation(), onChange: () => toggleSelected(a.id) }) },
+                { key: 'name', label: 'Name', sortable: true, required: true, render: a => h('div', { style: { display: 'flex', alignItems: 'center', gap: 8 } },
+                  a.avatar
+                    ? h('img', { src: a.avatar, alt: '', loading: 'lazy', style: { width: 24, height: 24, borderRadius: '50%', objectFit: 'cover', flexShrink: 0 } })
+                    : h('div', { style: { width: 24, height: 24, borderRadius: '50%', background: 'var(--accent-soft)', color: 'var(--accent-text)', display: 'flex', alignItems: 'center', justifyContent: 'center', fontSize: 11, fontWeight: 600, flexShrink: 0 } }, (a.name || '?').charAt(0).toUpperCase()),
+                  h('strong', { style: { cursor: 'pointer', color: 'var(--accent-text)' }, onClick: () => onSelectAgent && onSelectAgent(a.id) }, a.name)) },
+                { key: 'email', label: 'Email', sortable: true, render: a => h('span', { style: { fontFamily: 'var(--font-mono)', fontSize: 12 } }, a.email || '-') },
+                { key: 'role', label: 'Role', sortable: true, render: a => h('span', { className: 'badge badge-neutral' }, a.role || 'agent') },
+                { key: 'model', label: 'Model', sortable: true, render: a => a.model
+                  ? h('span', { style: { fontSize: 12 } }, a.model, a.provider && h('span', { style: { color: 'var(--text-muted)', marginLeft: 4 } }, a.provider))
+                  : h('span', { style: { color: 'var(--text-muted)' } }, '-') },
+                { key: 'teams', label: 'Teams', render: a => (a.teams || []).length === 0
+                  ? h('span', { style: { color: 'var(--text-muted)' } }, '-')
+                  : h('div', { style: { display: 'flex', gap: 4, flexWrap: 'wrap' } }, a.teams.map(id => h('span', { key: id, className: 'badge badge-neutral', style: { cursor: 'pointer' }, onClick: e => { e.stopPropagation(); filters.set('team', id); } }, (facets.teams || []).find(t => t.id === id)?.name || 'Team'))) },
+                { key: 'classification', label: 'Classification', render: a => h(ClassificationChip, { level: mailboxClassifications.get(a.id), showNone: true }) },
+                // Archived and retired agents have no process; their identity state is what matters
+                { key: 'status', label: 'Status', sortable: true, render: a => h(LiveStatusBadge, {
+                  live: a.status === 'archived' || a.status === 'retired' ? null : liveStatuses[a.id], fallback: a.status, showActivity: true,
+                }) },
+                { key: 'createdAt', label: 'Created', sortable: true, defaultDir: 'desc', style: { fontSize: 12, color: 'var(--text-muted)' }, render: a => a.createdAt ? new Date(a.createdAt).toLocaleDateString() : '-' },
+                { key: 'actions', label: 'Actions', width: 180, render: a => h('div', { style: { display: 'flex', gap: 4 } },
+                  h('button', { className: 'btn btn-primary btn-sm', onClick: () => onSelectAgent && onSelectAgent(a.id) }, 'View Details'),
+                  h('button', { className: 'btn btn-ghost btn-sm', title: 'Duplicate Agent', onClick: (e) => { e.stopPropagation(); setDuplicatingAgent(a); } }, I.copy()),
+                  h('button', { className: 'btn btn-ghost btn-sm', title: 'Restart', onClick: () => engineCall('/agents/' + a.id + '/restart', { method: 'POST', body: JSON.stringify({ restartedBy: 'dashboard' }) }).then(() => toast('Restarting...', 'info')).catch(e => toast(e.message, 'error')) }, I.refresh())
+                ) }
+              ]
+            })
+          ),
+          h(Pagination, { page: table.page, pageSize: pageSize, total: table.total, hasMore: table.hasMore, noun: 'agents', onPage: table.setPage, onPageSize: setPageSize })
         ),
     // Duplicate Agent Modal
     duplicatingAgent && h(DuplicateAgentModal, {
@@ -1249,4 +1348,4 @@ export function AgentsPage({ onSelectAgent }) {
 // AGENT DETAIL PAGE — Re-exported from agent-detail.js
 // ════════════════════════════════════════════════════════════
 
-export { AgentDetailPage } from './agent-detail/index.js?v=6';
+export { AgentDetailPage } from './agent-detail/index.js?v=7';
@@ -0,0 +1,164 @@
+import { h, useState, useEffect, useApp, engineCall, apiCall, getOrgId, showConfirm, buildAgentDataMap, renderAgentBadge } from '../components/utils.js';
+import { I } from '../components/icons.js';
+import { HelpButton } from '../components/help-button.js';
+import { useOrgContext } from '../components/org-switcher.js';
+import { Table } from '../components/table.js';
+import { Pagination, usePageSize } from '../components/pagination.js';
+import { RelativeTime } from '../components/time.js';
+import { formatBytes } from './storage.js';
+
+const SIZE_OPTIONS = [
+  { value: '', label: 'Any size' },
+  { value: String(1024 * 1024), label: '≥ 1 MB' },
+  { value: String(10 * 1024 * 1024), label: '≥ 10 MB' },
+  { value: String(100 * 1024 * 1024), label: '≥ 100 MB' },
+];
+
+const AGE_OPTIONS = [
+  { value: '', label: 'Any age' },
+  { value: '30', label: 'Older than 30 days' },
+  { value: '90', label: 'Older than 90 days' },
+  { value: '365', label: 'Older than a year' },
+];
+
+export function AttachmentsPage() {
+  var orgCtx = useOrgContext();
+  var effectiveOrgId = orgCtx.selectedOrgId || getOrgId();
+  const { toast } = useApp();
+  const [data, setData] = useState(null);
+  const [loading, setLoading] = useState(true);
+  const [agents, setAgents] = useState([]);
+  const [filters, setFilters] = useState({ agentId: '', sender: '', minSize: '', olderThanDays: '', sort: 'size', includeArchived: false });
+  const [page, setPage] = useState(1);
+  const [pageSize, setPageSize] = usePageSize('attachments', 50);
+  const [selected, setSelected] = useState([]);
+  const [busy, setBusy] = useState(false);
+
+  const load = () => {
+    setLoading(true);
+    var params = new URLSearchParams({ orgId: effectiveOrgId, sort: filters.sort, limit: String(pageSize), offset: String((page - 1) * pageSize) });
+    ['agentId', 'sender', 'minSize', 'olderThanDays'].forEach(k => { if (filters[k]) params.set(k, filters[k]); });
+    if (filters.includeArchived) params.set('includeArchived', 'true');
+    engineCall('/storage/attachments?' + params)
+      .then(d => { setData(d); setSelected(sel => sel.filter(id => d.attachments.some(a => a.id === id))); })
+      .catch(err => toast('Failed to load attachments: ' + err.message, 'error'))
+      .finally(() => setLoading(false));
+  };
+
+  useEffect(() => {
+    apiCall('/agents' + (orgCtx.selectedOrgId ? '?clientOrgId=' + orgCtx.selectedOrgId : '')).then(d => setAgents(d.agents || [])).catch(() => {});
+  }, [orgCtx.selectedOrgId]);
+  useEffect(() => { load(); }, [effectiveOrgId, filters, page, pageSize]);
+
+  const setFilter = (k, v) => { setFilters(f => Object.assign({}, f, { [k]: v })); setPage(1); };
+  var agentData = buildAgentDataMap(agents);
+  var rows = data ? data.attachments : [];
+  var selectable = rows.filter(a => !a.held);
+  var selectedRows = rows.filter(a => selected.includes(a.id));
+  var selectedBytes = selectedRows.reduce((s, a) => s + a.size, 0);
+  var allSelected = selectable.length > 0 && selectable.every(a => selected.includes(a.id));
+
+  const toggle = (id) => setSelected(sel => sel.includes(id) ? sel.filter(x => x !== id) : sel.concat([id]));
+
+  const runBulk = async (action) => {
+    var n = selected.length;
+    var ok = await showConfirm({
+      title: action === 'delete' ? 'Delete ' + n + ' attachment' + (n === 1 ? '' : 's') + '?' : 'Archive ' + n + ' attachment' + (n === 1 ? '' : 's') + '?',
+      message: action === 'delete'
+        ? formatBytes(selectedBytes) + ' will be removed from storage.'
+        : 'Files are compressed into the archive folder and the originals removed. They stay downloadable as .gz files.',
+      warning: action === 'delete' ? 'Deleted files cannot be recovered.' : undefined,
+      danger: action === 'delete',
+      confirmText: action === 'delete' ? 'Delete' : 'Archive',
+    });
+    if (!ok) return;
+    setBusy(true);
+    try {
+      var res = await engineCall('/storage/attachments/bulk', { method: 'POST', body: JSON.stringify({ orgId: effectiveOrgId, ids: selected, action }) });
+      var msg = (action === 'delete' ? 'Deleted ' : 'Archived ') + res.done.length + ', reclaimed ' + formatBytes(res.reclaimedBytes);
+      if (res.skipped.length) msg += ' · ' + res.skipped.length + ' skipped (' + [...new Set(res.skipped.map(s => s.reason))].join(', ') + ')';
+      toast(msg, res.skipped.length ? 'warning' : 'success');
+      setSelected([]);
+      load();
+    } catch (err) { toast(err.message, 'error'); }
+    setBusy(false);
+  };
+
+  const columns = [
+    { key: 'select', label: h('input', { type: 'checkbox', checked: allSelected, disabled: selectable.length === 0, 'aria-label': 'Select all', onChange: () => setSelected(allSelected ? [] : selectable.map(a => a.id)) }),
+      render: (a) => a.held
+        ? h('span', { title: 'Agent is on legal hold', style: { color: 'var(--warning)', display: 'inline-flex' } }, I.lock())
+        : h('input', { type: 'checkbox', checked: selected.includes(a.id), 'aria-label': 'Select ' + a.originalName, onClick: e => e.stopPropagation(), onChange: () => toggle(a.id) }) },
+    { key: 'name', label: 'File', render: (a) => h('div', null,
+      h('div', { style: { fontWeight: 500, wordBreak: 'break-all' } }, a.originalName, a.archived && h('span', { className: 'badge badge-neutral', style: { marginLeft: 6 } }, 'archived')),
+      h('div', { style: { fontSize: 11, color: 'var(--text-muted)' } }, a.contentType)
+    ) },
+    { key: 'agent', label: 'Agent', render: (a) => a.agentId ? renderAgentBadge(a.agentId, agentData) : h('span', { style: { color: 'var(--text-muted)' } }, a.relatedType || '-') },
+    { key: 'sender', label: 'Sender', render: (a) => h('span', { style: { fontSize: 12 } }, agentData[a.createdBy]?.name || a.createdBy) },
+    { key: 'size', label: 'Size', render: (a) => formatBytes(a.size) },
+    { key: 'age', label: 'Uploaded', render: (a) => h(RelativeTime, { value: a.createdAt }) },
+  ];
+
+  var _h4 = { marginTop: 16, marginBottom: 8, fontSize: 14 };
+
+  return h('div', { className: 'page-inner' },
+    h(orgCtx.Switcher),
+    h('div', { className: 'page-header' },
+      h('h1', { style: { display: 'flex', alignItems: 'center' } }, 'Attachments', h(HelpButton, { label: 'Attachments' },
+        h('p', null, 'Every stored file across agents, largest or oldest first, so you can find what is taking up space and clear it in bulk.'),
+        h('h4', { style: _h4 }, 'Delete or archive'),
+        h('p', null, 'Delete removes the file for good. Archive compresses it into the archive folder and removes the original — it still downloads, as a .gz file. Archived files are hidden unless you include them.'),
+        h('h4', { style: _h4 }, 'Legal holds'),
+        h('p', null, 'Files belonging to an agent on legal hold show a lock and cannot be selected. The server skips them too. Holds are managed under Settings → Data Retention.'),
+        h('p', null, 'Every bulk action is recorded in the audit log.')
+      )),
+      h('button', { className: 'btn btn-secondary', onClick: load, disabled: loading }, I.refresh(), ' Refresh')
+    ),
+
+    h('div', { className: 'stat-grid', style: { marginBottom: 16 } },
+      h('div', { className: 'stat-card' }, h('div', { className: 'stat-value' }, data ? data.total.toLocaleString() : '...'), h('div', { className: 'stat-label' }, 'Matching files')),
+      h('div', { className: 'stat-card' }, h('div', { className: 'stat-value' }, data ? formatBytes(data.totalBytes) : '...'), h('div', { className: 'stat-label' }, 'Matching size'))
+    ),
+
+    h('div', { style: { display: 'flex', gap: 8, flexWrap: 'wrap', alignItems: 'center', marginBottom: 12 } },
+      h('select', { className: 'input', style: { width: 'auto' }, value: filters.agentId, onChange: e => setFilter('agentId', e.target.value) },
+        h('option', { value: '' }, 'All agents'),
+        agents.map(a => h('option', { key: a.id, value: a.id }, agentData[a.id]?.name || a.id))
+      ),
+      h('input', { className: 'input', style: { width: 180 }, placeholder: 'Sender', value: filters.sender, onChange: e => setFilter('sender', e.target.value.trim()) }),
+      h('select', { className: 'input', style: { width: 'auto' }, value: filters.minSize, onChange: e => setFilter('minSize', e.target.value) },
+        SIZE_OPTIONS.map(o => h('option', { key: o.value, value: o.value }, o.label))
+      ),
+      h('select', { className: 'input', style: { width: 'auto' }, value: filters.olderThanDays, onChange: e => setFilter('olderThanDays', e.target.value) },
+        AGE_OPTIONS.map(o => h('option', { key: o.value, value: o.value }, o.label))
+      ),
+      h('select', { className: 'input', style: { width: 'auto' }, value: filters.sort, onChange: e => setFilter('sort', e.target.value) },
+        h('option', { value: 'size' }, 'Largest first'),
+        h('option', { value: 'age' }, 'Oldest first')
+      ),
+      h('label', { style: { display: 'flex', alignItems: 'center', gap: 6, fontSize: 13 } },
+        h('input', { type: 'checkbox', checked: filters.includeArchived, onChange: e => setFilter('includeArchived', e.target.checked) }), 'Include archived')
+    ),
+
+    selected.length > 0 && h('div', { className: 'card', style: { marginBottom: 12, padding: '8px 12px', display: 'flex', alignItems: 'center', gap: 8 } },
+      h('span', { style: { fontSize: 13, flex: 1 } }, selected.length + ' selected · ' + formatBytes(selectedBytes)),
+      h('button', { className: 'btn btn-ghost btn-sm', onClick: () => setSelected([]) }, 'Clear'),
+      h('button', { className: 'btn btn-secondary btn-sm', disabled: busy, onClick: () => runBulk('archive') }, 'Archive'),
+      h('button', { className: 'btn btn-danger btn-sm', disabled: busy, onClick: () => runBulk('delete') }, I.trash(), ' Delete')
+    ),
+
+    h('div', { className: 'card' },
+      h(Table, {
+        prefsKey: 'attachments',
+        className: 'data-table',
+        columns,
+        rows,
+        refreshing: loading && !!data,
+        rowStyle: (a) => a.held ? { opacity: 0.6 } : null,
+        onRowClick: (a) => { if (!a.held) toggle(a.id); },
+        empty: loading ? 'Loading...' : 'No attachments match these filters',
+      }),
+      h(Pagination, { page, pageSize, total: data ? data.total : 0, onPage: setPage, onPageSize: (n) => { setPageSize(n); setPage(1); } })
+    )
+  );
+}
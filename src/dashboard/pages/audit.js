@@ -1,41 +1,154 @@
-import { h, useState, useEffect, useCallback, Fragment, useApp, apiCall, getOrgId } from '../components/utils.js';
+import { h, useState, useEffect, Fragment, getOrgId, useApp, apiCall, downloadExport, openPrintView } from '../components/utils.js';
+import { useFragment } from '../components/fragments.js';
+import { Table, useSort } from '../components/table.js';
+import { Pagination, usePageSize } from '../components/pagination.js';
 import { I } from '../components/icons.js';
-import { DetailModal } from '../components/modal.js';
+import { Drawer } from '../components/modal.js';
 import { HelpButton } from '../components/help-button.js';
 import { KnowledgeLink } from '../components/knowledge-link.js';
 import { useOrgContext } from '../components/org-switcher.js';
+import { useFilters } from '../components/filter-bar.js';
+import { RelativeTime } from '../components/time.js';
 
-var PAGE_SIZE = 50;
+var RELATED_LABELS = { agent: 'Agent', user: 'User', api_key: 'API key' };
+
+// Flags the server puts on events that look out of character for their actor
+export var AUDIT_ANOMALY_LABELS = { unusual_hour: 'Unusual hour', new_ip: 'New IP', destructive_burst: 'Destructive burst' };
+
+export function AuditAnomalyBadge({ anomalies }) {
+  if (!anomalies || !anomalies.length) return null;
+  return h('span', {
+    className: 'badge badge-warning',
+    style: { display: 'inline-flex', alignItems: 'center', gap: 4, fontSize: 10, marginLeft: 6 },
+    title: anomalies.map(function(a) { return a.message; }).join('\n'),
+  }, I.warning(), anomalies.length === 1 ? AUDIT_ANOMALY_LABELS[anomalies[0].type] || 'Unusual' : anomalies.length + ' flags');
+}
+
+function openLink(path) { history.pushState(null, '', path); window.dispatchEvent(new PopStateEvent('popstate')); }
+
+function relatedPath(r) {
+  if (r.type === 'agent') return '/dashboard/agents/' + r.id;
+  if (r.type === 'user') return '/dashboard/users?q=' + encodeURIComponent(r.email || r.name || '');
+  return '/dashboard/settings?tab=api-keys';
+}
+
+function locationLabel(loc) {
+  if (!loc) return null;
+  if (loc.local) return 'Private or local network';
+  var place = [loc.city, loc.region, loc.country].filter(Boolean).join(', ');
+  return (place || 'Unknown location') + (loc.isp ? ' \u00b7 ' + loc.isp : '');
+}
+
+// ─── Event detail drawer ─────────────────────────────────
+// The full event as JSON, what its resource points at, where the IP is,
+// and the same actor's events just before and after it.
+
+function AuditEventDrawer({ event, orgId, actorDisplay, actionColor, onSelect, onFilterActor, onClose }) {
+  var { toast } = useApp();
+  var [ctx, setCtx] = useState(null);
+
+  useEffect(function() {
+    setCtx(null);
+    if (!event.actor) { setCtx({ before: [], after: [] }); return; }
+    var q = { actor: event.actor, at: new Date(event.timestamp).toISOString(), id: event.id || '', resource: event.resource || '', ip: event.ip || '', orgId: orgId || '' };
+    apiCall('/audit/context?' + Object.keys(q).filter(function(k) { return q[k]; }).map(function(k) { return k + '=' + encodeURIComponent(q[k]); }).join('&'))
+      .then(setCtx)
+      .catch(function() { setCtx({ before: [], after: [], failed: true }); });
+  }, [event.id]);
+
+  var raw = JSON.stringify(event, null, 2);
+  var copyRaw = function() {
+    navigator.clipboard.writeText(raw).then(function() { toast('Event copied', 'success'); }).catch(function() { toast('Copy failed', 'error'); });
+  };
+
+  var _label = { fontSize: 11, fontWeight: 600, color: 'var(--text-muted)', textTransform: 'uppercase', letterSpacing: '0.05em' };
+  var _section = { fontSize: 13, fontWeight: 600, margin: '20px 0 8px' };
+  var related = ctx && ctx.related;
+  var location = ctx && locationLabel(ctx.location);
+
+  var eventRow = function(e, current) {
+    return h('div', {
+      key: e.id, onClick: current ? null : function() { onSelect(e); },
+      style: { display: 'flex', gap: 8, alignItems: 'center', padding: '6px 8px', borderRadius: 6, cursor: current ? 'default' : 'pointer', background: current ? 'var(--bg-secondary)' : 'transparent', fontSize: 12 },
+      title: current ? 'This event' : 'Open this event',
+    },
+      h('span', { style: { color: 'var(--text-muted)', width: 110, flexShrink: 0 } }, new Date(e.timestamp).toLocaleTimeString()),
+      h('span', { className: 'badge ' + actionColor(e.action), style: { fontSize: 10 } }, e.action || '-'),
+      h('span', { style: { fontFamily: 'var(--font-mono, monospace)', color: 'var(--text-secondary)', overflow: 'hidden', textOverflow: 'ellipsis', whiteSpace: 'nowrap' } }, (e.resource || '').replace(/^\/api\//, ''))
+    );
+  };
+
+  return h(Drawer, { title: 'Audit Event', onClose: onClose, width: 600 },
+    event.anomalies && event.anomalies.length > 0 && h('div', { style: { padding: '10px 12px', marginBottom: 16, borderRadius: 6, background: 'var(--warning-soft)', border: '1px solid var(--warning)', fontSize: 13 } },
+      h('div', { style: { fontWeight: 600, color: 'var(--warning)', display: 'flex', alignItems: 'center', gap: 6, marginBottom: 4 } }, I.warning(), ' Out of character for this actor'),
+      event.anomalies.map(function(a) { return h('div', { key: a.type }, h('strong', null, AUDIT_ANOMALY_LABELS[a.type] || a.type), ' \u2014 ', a.message); })
+    ),
+    h('div', { style: { display: 'grid', gridTemplateColumns: '110px 1fr', gap: '10px 12px', alignItems: 'start', fontSize: 13 } },
+      h('span', { style: _label }, 'Time'),
+      h('span', null, new Date(event.timestamp).toLocaleString(), ' ', h('span', { style: { color: 'var(--text-muted)', fontSize: 12 } }, '(', h(RelativeTime, { value: event.timestamp }), ')')),
+      h('span', { style: _label }, 'Action'),
+      h('span', null, h('span', { className: 'badge ' + actionColor(event.action) }, event.action || '-')),
+      h('span', { style: _label }, 'Actor'),
+      h('span', null, actorDisplay(event), event.actor && h('button', { className: 'btn btn-ghost btn-sm', style: { marginLeft: 6 }, onClick: function() { onFilterActor(event.actor); } }, 'All events by this actor')),
+      h('span', { style: _label }, 'Resource'),
+      h('span', { style: { fontFamily: 'var(--font-mono, monospace)', fontSize: 12, wordBreak: 'break-all' } }, event.resource || '-'),
+      related && h(Fragment, null,
+        h('span', { style: _label }, 'Related'),
+        related.missing
+          ? h('span', { style: { color: 'var(--text-muted)' } }, RELATED_LABELS[related.type] + ' ' + related.id + ' no longer exists')
+          : h('span', null,
+              h('a', { href: relatedPath(related), onClick: function(e) { e.preventDefault(); onClose(); openLink(relatedPath(related)); } }, RELATED_LABELS[related.type] + ': ' + (related.name || related.id)),
+              related.email && h('span', { style: { color: 'var(--text-muted)', marginLeft: 6 } }, related.email),
+              (related.status === 'archived' || related.revoked) && h('span', { className: 'badge badge-neutral', style: { marginLeft: 6, fontSize: 10 } }, related.revoked ? 'revoked' : 'archived')
+            )
+      ),
+      h('span', { style: _label }, 'IP'),
+      h('span', null, event.ip || '-', event.ip && h('div', { style: { fontSize: 12, color: 'var(--text-muted)', marginTop: 2 } }, !ctx ? 'Looking up location...' : location || 'Location unavailable'))
+    ),
+
+    h('div', { style: _section }, 'Same actor, around this time'),
+    !ctx ? h('div', { style: { fontSize: 12, color: 'var(--text-muted)' } }, 'Loading...')
+    : ctx.failed ? h('div', { style: { fontSize: 12, color: 'var(--danger)' } }, 'Could not load nearby events.')
+    : h('div', null,
+        ctx.after.slice().reverse().map(function(e) { return eventRow(e, false); }),
+        eventRow(event, true),
+        ctx.before.map(function(e) { return eventRow(e, false); }),
+        ctx.before.length === 0 && ctx.after.length === 0 && h('div', { style: { fontSize: 12, color: 'var(--text-muted)', padding: '4px 8px' } }, 'No other events from this actor nearby.')
+      ),
+
+    h('div', { style: Object.assign({}, _section, { display: 'flex', alignItems: 'center', justifyContent: 'space-between' }) },
+      'Raw event',
+      h('button', { className: 'btn btn-ghost btn-sm', onClick: copyRaw }, I.copy(), ' Copy JSON')
+    ),
+    h('pre', { style: { fontSize: 11, fontFamily: 'var(--font-mono, monospace)', color: 'var(--text-secondary)', background: 'var(--bg-secondary)', padding: '10px 12px', borderRadius: 6, whiteSpace: 'pre-wrap', wordBreak: 'break-all', margin: 0 } }, raw)
+  );
+}
 
 export function AuditPage() {
   var orgCtx = useOrgContext();
   var effectiveOrgId = orgCtx.selectedOrgId || getOrgId();
-  var [logs, setLogs] = useState([]);
-  var [loading, setLoading] = useState(true);
+  var { toast, can } = useApp();
+  var canExport = can('audit.export');
   var [selected, setSelected] = useState(null);
-  var [filter, setFilter] = useState('');
-  var [page, setPage] = useState(0);
-  var [total, setTotal] = useState(0);
-  var [hasMore, setHasMore] = useState(false);
-
-  var loadPage = useCallback(function(p) {
-    setLoading(true);
-    var offset = p * PAGE_SIZE;
-    apiCall('/audit?limit=' + PAGE_SIZE + '&offset=' + offset + '&orgId=' + effectiveOrgId)
-      .then(function(d) {
-        var arr = d.events || d.entries || d.logs || d;
-        arr = Array.isArray(arr) ? arr : [];
-        setLogs(arr);
-        setTotal(d.total || arr.length);
-        setHasMore(arr.length >= PAGE_SIZE);
-        setLoading(false);
-      })
-      .catch(function() { setLoading(false); });
-  }, [effectiveOrgId]);
+  // Global search links here with ?q=<resource>; drill-downs add exact-match
+  // action/actor/resource and a from/to range, which are applied on the server
+  var filters = useFilters({ q: '', action: '', actor: '', resource: '', from: '', to: '' });
+  var filter = filters.values.q;
+  var setFilter = function(v) { filters.set('q', v); };
+  var serverFilters = Object.assign({}, filters.params);
+  delete serverFilters.q;
+  var serverQuery = Object.keys(serverFilters).map(function(k) { return k + '=' + encodeURIComponent(serverFilters[k]); }).join('&');
+  var sort = useSort('timestamp', 'desc');
+  var [pageSize, setPageSize] = usePageSize('audit', 50);
+  var audit = useFragment('/audit/rows', Object.assign({ orgId: effectiveOrgId }, serverFilters, sort.params), { pageSize: pageSize });
+  var logs = audit.rows;
+  var loading = audit.loading;
+  var total = audit.total;
 
-  useEffect(function() { setPage(0); loadPage(0); }, [effectiveOrgId]);
-
-  var goPage = function(p) { setPage(p); loadPage(p); };
+  // Exports cover the whole filtered history, not just the loaded page
+  var exportAs = function(format) {
+    downloadExport('/audit?orgId=' + encodeURIComponent(effectiveOrgId) + (serverQuery ? '&' + serverQuery : ''), format).catch(function(err) { toast(err.message, 'error'); });
+  };
 
   var actorDisplay = function(l) {
     if (l.details && l.details.email) return l.details.email;
@@ -81,8 +194,6 @@ export function AuditPage() {
     return r.replace(/^\/api\//, '').replace(/^\//, '');
   };
 
-  var totalPages = Math.max(1, Math.ceil((total || (hasMore ? (page + 2) * PAGE_SIZE : (page + 1) * PAGE_SIZE)) / PAGE_SIZE));
-
   var _h4 = { marginTop: 16, marginBottom: 8, fontSize: 14 };
   var _ul = { paddingLeft: 20, margin: '4px 0 8px' };
   var _tip = { marginTop: 12, padding: 12, background: 'var(--bg-secondary, #1e293b)', borderRadius: 'var(--radius, 8px)', fontSize: 13 };
@@ -107,7 +218,22 @@ export function AuditPage() {
             h('li', null, h('strong', null, 'Yellow'), ' — Update/edit actions.'),
             h('li', null, h('strong', null, 'Blue'), ' — Login/auth actions.')
           ),
-          h('div', { style: _tip }, h('strong', null, 'Tip: '), 'Use the filter box to search across actions, users, and targets. Click any row to see full details including IP address and metadata.')
+          h('h4', { style: _h4 }, 'Suspicious activity'),
+          h('p', null, 'Events that look out of character for their actor carry a yellow warning badge. Each check compares the event with the same actor\'s own last 30 days:'),
+          h('ul', { style: _ul },
+            h('li', null, h('strong', null, 'Unusual hour'), ' — nothing from this actor within an hour of this time of day (UTC) in 30 days.'),
+            h('li', null, h('strong', null, 'New IP'), ' — a public IP the actor has not used in 30 days.'),
+            h('li', null, h('strong', null, 'Destructive burst'), ' — 5 or more deletes, revokes or archives within 10 minutes.')
+          ),
+          h('p', null, 'New actors are not judged on hours or IPs until they have some history, and automated system events are never flagged. The same flags feed the Suspicious Activity card on the dashboard.'),
+          h('h4', { style: _h4 }, 'Exports'),
+          h('ul', { style: _ul },
+            h('li', null, h('strong', null, 'CSV'), ' — every event matching the active filters, for spreadsheets.'),
+            h('li', null, h('strong', null, 'JSONL'), ' — the same events as JSON Lines with details kept intact; suited to SOC 2 evidence and SIEM import.'),
+            h('li', null, h('strong', null, 'PDF'), ' — the newest 5,000 matching events as a printable report.')
+          ),
+          h('p', null, 'Exports stream the full history page by page, stop at the moment you click so the file is a consistent snapshot, and are themselves recorded as audit.exported.'),
+          h('div', { style: _tip }, h('strong', null, 'Tip: '), 'Use the filter box to search across actions, users, and targets. Click any row to open its detail drawer: the raw event JSON, a link to the agent or user it concerns, where the IP is located, and the same actor\'s events just before and after.')
         )),
         h('p', { style: { color: 'var(--text-muted)', fontSize: 13 } }, 'Complete record of all administrative actions and changes')
       ),
@@ -117,82 +243,55 @@ export function AuditPage() {
           className: 'input', placeholder: 'Filter by action, user, target...',
           style: { width: 260, fontSize: 13 },
           value: filter, onChange: function(e) { setFilter(e.target.value); }
-        })
+        }),
+        canExport && h('button', { className: 'btn btn-secondary', title: 'Download every matching event for this organization as a spreadsheet', onClick: function() { exportAs('csv'); } }, I.download(), ' Export CSV'),
+        canExport && h('button', { className: 'btn btn-secondary', title: 'Download every matching event as JSON Lines, one full record per line, for evidence collection and SIEM import', onClick: function() { exportAs('jsonl'); } }, I.download(), ' JSONL'),
+        canExport && h('button', { className: 'btn btn-secondary', title: 'Download the newest 5,000 events as a PDF report', onClick: function() { exportAs('pdf'); } }, I.download(), ' PDF'),
+        h('button', { className: 'btn btn-secondary', title: 'Open a printable view of this page', onClick: openPrintView }, 'Print view')
       )
     ),
+    serverQuery && h('div', { style: { display: 'flex', gap: 8, alignItems: 'center', flexWrap: 'wrap', marginBottom: 12, fontSize: 13 } },
+      h('span', { style: { color: 'var(--text-muted)' } }, 'Showing only'),
+      Object.keys(serverFilters).map(function(k) {
+        var v = k === 'from' || k === 'to' ? new Date(serverFilters[k]).toLocaleString() : serverFilters[k];
+        return h('span', { key: k, className: 'badge badge-neutral', style: { display: 'inline-flex', alignItems: 'center', gap: 4 } }, k + ': ' + v,
+          h('button', { className: 'btn btn-ghost btn-icon', style: { padding: 0, fontSize: 12, lineHeight: 1 }, title: 'Remove filter', onClick: function() { filters.set(k, ''); } }, '\u00d7'));
+      }),
+      h('button', { className: 'btn btn-ghost btn-sm', onClick: filters.clear }, 'Clear')
+    ),
     h('div', { className: 'card' },
       h('div', { className: 'card-body-flush' },
         loading ? h('div', { style: { padding: 24, textAlign: 'center', color: 'var(--text-muted)' } }, 'Loading...')
-        : filtered.length === 0 ? h('div', { style: { padding: 24, textAlign: 'center', color: 'var(--text-muted)' } }, filter ? 'No matching entries' : 'No audit entries')
-        : h('table', null,
-            h('thead', null, h('tr', null,
-              h('th', null, 'Time'),
-              h('th', null, 'Action'),
-              h('th', null, 'User'),
-              h('th', null, 'Role'),
-              h('th', null, 'Resource'),
-              h('th', null, 'IP'),
-              h('th', { style: { width: 40 } })
-            )),
-            h('tbody', null, filtered.map(function(l, i) {
-              return h('tr', {
-                key: i,
-                style: { cursor: 'pointer' },
-                onClick: function() { setSelected(l); },
-                title: 'Click to view details'
-              },
-                h('td', { style: { fontSize: 12, color: 'var(--text-muted)', whiteSpace: 'nowrap' } },
-                  l.timestamp ? new Date(l.timestamp).toLocaleString() : '-'
-                ),
-                h('td', null, h('span', { className: 'badge ' + actionColor(l.action) }, l.action || '-')),
-                h('td', { style: { fontSize: 13 } }, actorDisplay(l)),
-                h('td', null, actorRole(l) ? h('span', { className: 'badge ' + roleColor(actorRole(l)), style: { fontSize: 10 } }, actorRole(l)) : '-'),
-                h('td', { style: { fontSize: 12, fontFamily: 'var(--font-mono, monospace)', color: 'var(--text-secondary)', maxWidth: 280, overflow: 'hidden', textOverflow: 'ellipsis', whiteSpace: 'nowrap' } },
-                  resourceDisplay(l.resource)
-                ),
-                h('td', { style: { fontSize: 12, color: 'var(--text-muted)' } }, l.ip || '-'),
-                h('td', null, h('button', { className: 'btn btn-ghost btn-icon', style: { padding: 4, fontSize: 14, color: 'var(--text-muted)' }, onClick: function(e) { e.stopPropagation(); setSelected(l); } }, '\u203A'))
-              );
-            }))
-          )
+        : audit.error && logs.length === 0 ? h('div', { style: { padding: 24, textAlign: 'center', color: 'var(--danger)' } }, audit.error)
+        : filtered.length === 0 ? h('div', { style: { padding: 24, textAlign: 'center', color: 'var(--text-muted)' } }, filter || serverQuery ? 'No matching entries' : 'No audit entries')
+        : h(Table, {
+            prefsKey: 'audit',
+            sort: sort.sort, onSort: sort.toggle, refreshing: audit.refreshing, rows: filtered,
+            rowKey: function(l, i) { return l.id || i; },
+            onRowClick: setSelected, rowTitle: 'Click to view details',
+            columns: [
+              { key: 'timestamp', label: 'Time', sortable: true, defaultDir: 'desc', style: { fontSize: 12, color: 'var(--text-muted)', whiteSpace: 'nowrap' }, render: function(l) { return h(RelativeTime, { value: l.timestamp }); } },
+              { key: 'action', label: 'Action', sortable: true, render: function(l) { return h('span', { style: { whiteSpace: 'nowrap' } }, h('span', { className: 'badge ' + actionColor(l.action) }, l.action || '-'), h(AuditAnomalyBadge, { anomalies: l.anomalies })); } },
+              { key: 'actor', label: 'User', sortable: true, style: { fontSize: 13 }, render: actorDisplay },
+              { key: 'role', label: 'Role', render: function(l) { return actorRole(l) ? h('span', { className: 'badge ' + roleColor(actorRole(l)), style: { fontSize: 10 } }, actorRole(l)) : '-'; } },
+              { key: 'resource', label: 'Resource', sortable: true, style: { fontSize: 12, fontFamily: 'var(--font-mono, monospace)', color: 'var(--text-secondary)', maxWidth: 280, overflow: 'hidden', textOverflow: 'ellipsis', whiteSpace: 'nowrap' }, render: function(l) { return resourceDisplay(l.resource); } },
+              { key: 'ip', label: 'IP', sortable: true, style: { fontSize: 12, color: 'var(--text-muted)' }, render: function(l) { return l.ip || '-'; } },
+              { key: 'open', label: '', width: 40, render: function(l) { return h('button', { className: 'btn btn-ghost btn-icon', style: { padding: 4, fontSize: 14, color: 'var(--text-muted)' }, onClick: function(e) { e.stopPropagation(); setSelected(l); } }, '\u203A'); } }
+            ]
+          })
       ),
 
-      // Pagination
-      (hasMore || page > 0) && h('div', { style: { display: 'flex', justifyContent: 'space-between', alignItems: 'center', padding: '12px 16px', borderTop: '1px solid var(--border)', fontSize: 13 } },
-        h('span', { style: { color: 'var(--text-muted)' } },
-          'Showing ' + (page * PAGE_SIZE + 1) + '–' + (page * PAGE_SIZE + filtered.length) + (total ? ' of ' + total : '')
-        ),
-        h('div', { style: { display: 'flex', gap: 4 } },
-          h('button', {
-            className: 'btn btn-secondary btn-sm',
-            disabled: page === 0,
-            onClick: function() { goPage(page - 1); }
-          }, '\u2190 Previous'),
-          h('span', { style: { padding: '4px 12px', fontSize: 12, color: 'var(--text-secondary)' } }, 'Page ' + (page + 1)),
-          h('button', {
-            className: 'btn btn-secondary btn-sm',
-            disabled: !hasMore,
-            onClick: function() { goPage(page + 1); }
-          }, 'Next \u2192')
-        )
-      )
+      h(Pagination, { page: audit.page, pageSize: pageSize, total: total, hasMore: audit.hasMore, count: logs.length, onPage: audit.setPage, onPageSize: setPageSize })
     ),
 
-    selected && h(DetailModal, {
-      title: 'Audit Entry',
+    selected && h(AuditEventDrawer, {
+      event: selected,
+      orgId: effectiveOrgId,
+      actorDisplay: actorDisplay,
+      actionColor: actionColor,
+      onSelect: setSelected,
+      onFilterActor: function(actor) { setSelected(null); filters.set('actor', actor); },
       onClose: function() { setSelected(null); },
-      data: {
-        timestamp: selected.timestamp,
-        action: selected.action,
-        user: actorDisplay(selected),
-        role: actorRole(selected),
-        actorType: selected.actorType,
-        resource: selected.resource,
-        ip: selected.ip,
-        details: selected.details,
-        id: selected.id,
-      },
-      badge: { label: selected.action, color: selected.action && (selected.action.toLowerCase().includes('delete') || selected.action.toLowerCase().includes('remove')) ? 'var(--danger)' : 'var(--accent)' },
     })
   );
 }
@@ -0,0 +1,125 @@
+import { h, useState, useEffect, useCallback, Fragment, useApp, apiCall, showConfirm } from '../components/utils.js';
+import { I } from '../components/icons.js';
+import { HelpButton } from '../components/help-button.js';
+
+export function CallLogPage() {
+  var { toast } = useApp();
+  var [actions, setActions] = useState([]);
+  var [loading, setLoading] = useState(true);
+  var [error, setError] = useState(null);
+  var [search, setSearch] = useState('');
+  var [failedOnly, setFailedOnly] = useState(false);
+  var [expanded, setExpanded] = useState({});
+
+  var load = useCallback(function() {
+    setLoading(true);
+    apiCall('/admin/call-log?limit=200' + (failedOnly ? '&failed=true' : '') + (search ? '&q=' + encodeURIComponent(search) : ''))
+      .then(function(d) { setActions(d.actions || []); setError(null); })
+      .catch(function(e) { setError(e.message); })
+      .finally(function() { setLoading(false); });
+  }, [search, failedOnly]);
+
+  useEffect(function() { load(); }, [failedOnly]);
+
+  var clearLog = async function() {
+    var ok = await showConfirm({ title: 'Clear Call Log', message: 'Remove all recorded dashboard calls? New actions will continue to be recorded.', danger: true, confirmText: 'Clear' });
+    if (!ok) return;
+    apiCall('/admin/call-log', { method: 'DELETE' })
+      .then(function() { setActions([]); toast('Call log cleared', 'success'); })
+      .catch(function(e) { toast(e.message, 'error'); });
+  };
+
+  var toggle = function(id) {
+    setExpanded(function(prev) { var next = Object.assign({}, prev); next[id] = !prev[id]; return next; });
+  };
+
+  var statusColor = function(status) {
+    if (status >= 500) return 'badge-danger';
+    if (status >= 400) return 'badge-warning';
+    return 'badge-success';
+  };
+
+  var _h4 = { marginTop: 16, marginBottom: 8, fontSize: 14 };
+  var _ul = { paddingLeft: 20, margin: '4px 0 8px' };
+  var _mono = { fontFamily: 'var(--font-mono, monospace)', fontSize: 12 };
+
+  return h(Fragment, null,
+    h('div', { style: { display: 'flex', justifyContent: 'space-between', alignItems: 'center', marginBottom: 20 } },
+      h('div', null,
+        h('h1', { style: { fontSize: 20, fontWeight: 700, display: 'flex', alignItems: 'center' } }, 'Call Log', h(HelpButton, { label: 'Call Log' },
+          h('p', null, 'Shows the exact backend API calls made for each dashboard action — every click or form submit is grouped with the requests it triggered.'),
+          h('h4', { style: _h4 }, 'When to use it'),
+          h('ul', { style: _ul },
+            h('li', null, 'A user reports "the UI said saved but nothing changed" — find their action and check each call\'s status and error.'),
+            h('li', null, 'Use the Request ID or Trace ID to locate the request in server logs or your tracing backend.')
+          ),
+          h('p', null, 'The log is kept in memory (most recent 5,000 calls) and resets on restart. Only owners can view it.')
+        )),
+        h('p', { style: { color: 'var(--text-muted)', fontSize: 13 } }, 'Backend calls made for each user action in the dashboard')
+      ),
+      h('div', { style: { display: 'flex', gap: 8, alignItems: 'center' } },
+        h('label', { style: { display: 'flex', alignItems: 'center', gap: 6, fontSize: 13, color: 'var(--text-secondary)' } },
+          h('input', { type: 'checkbox', checked: failedOnly, onChange: function(e) { setFailedOnly(e.target.checked); } }),
+          'Failed only'
+        ),
+        h('input', {
+          className: 'input', placeholder: 'Filter by action, page, path or error...',
+          style: { width: 260, fontSize: 13 },
+          value: search, onChange: function(e) { setSearch(e.target.value); },
+          onKeyDown: function(e) { if (e.key === 'Enter') load(); }
+        }),
+        h('button', { className: 'btn btn-secondary btn-sm', onClick: load }, I.refresh(), ' Refresh'),
+        h('button', { className: 'btn btn-ghost btn-sm', onClick: clearLog, title: 'Clear call log' }, I.trash())
+      )
+    ),
+    h('div', { className: 'card' },
+      h('div', { className: 'card-body-flush' },
+        loading ? h('div', { style: { padding: 24, textAlign: 'center', color: 'var(--text-muted)' } }, 'Loading...')
+        : error ? h('div', { style: { padding: 24, textAlign: 'center', color: 'var(--danger)' } }, error)
+        : actions.length === 0 ? h('div', { style: { padding: 24, textAlign: 'center', color: 'var(--text-muted)' } }, search || failedOnly ? 'No matching actions' : 'No actions recorded yet')
+        : h('table', null,
+            h('thead', null, h('tr', null,
+              h('th', { style: { width: 24 } }),
+              h('th', null, 'Time'),
+              h('th', null, 'Action'),
+              h('th', null, 'Page'),
+              h('th', null, 'User'),
+              h('th', null, 'Calls')
+            )),
+            h('tbody', null, actions.map(function(a) {
+              var open = !!expanded[a.actionId];
+              return h(Fragment, { key: a.actionId },
+                h('tr', { style: { cursor: 'pointer' }, onClick: function() { toggle(a.actionId); } },
+                  h('td', { style: { color: 'var(--text-muted)' } }, open ? '▾' : '▸'),
+                  h('td', { style: { fontSize: 12, color: 'var(--text-muted)', whiteSpace: 'nowrap' } }, new Date(a.startedAt).toLocaleString()),
+                  h('td', { style: { fontSize: 13, fontWeight: 500 } }, a.label),
+                  h('td', { style: _mono }, a.page || '-'),
+                  h('td', { style: { fontSize: 13 } }, a.userEmail || a.userId || '-'),
+                  h('td', null,
+                    h('span', { className: 'badge badge-neutral' }, a.calls.length),
+                    a.failed > 0 && h('span', { className: 'badge badge-danger', style: { marginLeft: 6 } }, a.failed + ' failed')
+                  )
+                ),
+                open && h('tr', null,
+                  h('td', { colSpan: 6, style: { background: 'var(--bg-secondary)', padding: '8px 16px' } },
+                    h('table', { style: { width: '100%' } },
+                      h('tbody', null, a.calls.map(function(call, i) {
+                        return h('tr', { key: i },
+                          h('td', { style: Object.assign({ width: 70, fontWeight: 600 }, _mono) }, call.method),
+                          h('td', { style: _mono }, call.path),
+                          h('td', { style: { width: 70 } }, h('span', { className: 'badge ' + statusColor(call.status) }, call.status)),
+                          h('td', { style: { width: 80, fontSize: 12, color: 'var(--text-muted)' } }, call.durationMs + ' ms'),
+                          h('td', { style: { fontSize: 12, color: 'var(--danger)' } }, call.error || ''),
+                          h('td', { style: Object.assign({ color: 'var(--text-muted)' }, _mono), title: call.traceId ? 'Trace ' + call.traceId : '' }, call.requestId || '')
+                        );
+                      }))
+                    )
+                  )
+                )
+              );
+            }))
+          )
+      )
+    )
+  );
+}
@@ -0,0 +1,306 @@
+import { h, useState, useEffect, useApp, engineCall, getOrgId, showConfirm } from '../components/utils.js';
+import { I } from '../components/icons.js';
+import { HelpButton } from '../components/help-button.js';
+import { useOrgContext } from '../components/org-switcher.js';
+import { Table } from '../components/table.js';
+import { FilterBar, useFilters } from '../components/filter-bar.js';
+import { MonthCalendar, calendarRange, startOfMonth, toLocalInput } from '../components/month-calendar.js';
+
+const KINDS = {
+  maintenance: { label: 'Maintenance', color: 'var(--warning)', badge: 'warning' },
+  compliance_report: { label: 'Compliance report', color: 'var(--info)', badge: 'info' },
+  task: { label: 'Task deadline', color: 'var(--success)', badge: 'success' },
+  rule_change: { label: 'Rule change', color: 'var(--accent)', badge: 'primary' },
+  retention_purge: { label: 'Retention purge', color: 'var(--danger)', badge: 'danger' },
+  compliance_deadline: { label: 'Compliance deadline', color: '#8b5cf6', badge: 'primary' },
+  other: { label: 'Other', color: 'var(--text-muted)', badge: 'neutral' },
+};
+// Only these are entered here; maintenance, reports and tasks come from their own pages
+const PLANNED_KINDS = ['rule_change', 'retention_purge', 'compliance_deadline', 'other'];
+
+function statusBadge(status) {
+  var cls = { planned: 'info', scheduled: 'info', active: 'warning', generating: 'warning', done: 'success', completed: 'success', failed: 'danger', cancelled: 'neutral' }[status] || 'neutral';
+  return h('span', { className: 'badge badge-' + cls }, status);
+}
+
+export function ChangeCalendarPage() {
+  var orgCtx = useOrgContext();
+  var effectiveOrgId = orgCtx.selectedOrgId || getOrgId();
+  const { toast, setPage } = useApp();
+  const filters = useFilters({ q: '', kind: '' });
+  const [month, setMonth] = useState(() => startOfMonth(new Date()));
+  const [events, setEvents] = useState([]);
+  const [loading, setLoading] = useState(true);
+  const [form, setForm] = useState(null); // { id?, kind, title, description, resource, startsAt, endsAt, status? }
+  const [feed, setFeed] = useState(null); // { feed, url? } while the Subscribe modal is open
+
+  const rangeQuery = () => {
+    var range = calendarRange(month);
+    // Always include what's coming up, even when browsing past months
+    var to = new Date(Math.max(range.end.getTime(), Date.now() + 90 * 86400000));
+    return 'orgId=' + encodeURIComponent(effectiveOrgId) + '&from=' + range.start.toISOString() + '&to=' + to.toISOString() + (filters.query ? '&' + filters.query : '');
+  };
+
+  const load = () => {
+    return engineCall('/change-calendar?' + rangeQuery())
+      .then(d => setEvents(d.events || []))
+      .catch(err => toast('Failed to load change calendar: ' + err.message, 'error'))
+      .finally(() => setLoading(false));
+  };
+
+  useEffect(() => { load(); }, [effectiveOrgId, month, filters.query]);
+
+  const exportICal = () => {
+    window.open('/api/engine/change-calendar/ical?' + rangeQuery(), '_blank');
+  };
+
+  const openSubscribe = () => {
+    engineCall('/change-calendar/feed?orgId=' + encodeURIComponent(effectiveOrgId))
+      .then(d => setFeed({ feed: d.feed }))
+      .catch(err => toast('Failed to load calendar feed: ' + err.message, 'error'));
+  };
+
+  const issueFeed = async () => {
+    if (feed.feed) {
+      var ok = await showConfirm({
+        title: 'Generate new feed link',
+        message: 'The current link stops working immediately. Calendars subscribed with it will stop updating until you subscribe again with the new one.',
+        confirmText: 'Generate New Link',
+        danger: true,
+      });
+      if (!ok) return;
+    }
+    try {
+      var d = await engineCall('/change-calendar/feed', { method: 'POST', body: JSON.stringify({ orgId: effectiveOrgId }) });
+      setFeed({ feed: d.feed, url: d.url });
+    } catch (err) { toast(err.message, 'error'); }
+  };
+
+  const revokeFeed = async () => {
+    var ok = await showConfirm({
+      title: 'Revoke calendar feed',
+      message: 'Subscribed calendars will stop receiving updates. Events already synced stay in those calendars until removed there.',
+      confirmText: 'Revoke',
+      danger: true,
+    });
+    if (!ok) return;
+    try {
+      await engineCall('/change-calendar/feed?orgId=' + encodeURIComponent(effectiveOrgId), { method: 'DELETE' });
+      toast('Calendar feed revoked', 'success');
+      setFeed({ feed: null });
+    } catch (err) { toast(err.message, 'error'); }
+  };
+
+  const openNew = () => {
+    var start = new Date(Date.now() + 86400000); start.setHours(9, 0, 0, 0);
+    setForm({ kind: 'rule_change', title: '', description: '', resource: '', startsAt: toLocalInput(start), endsAt: '' });
+  };
+
+  const openEvent = (e) => {
+    if (e.source === 'maintenance') return setPage('workforce');
+    if (e.source === 'compliance') return setPage('compliance');
+    if (e.source === 'task') return setPage('messages');
+    setForm({
+      id: e.sourceId, kind: e.kind, title: e.title, description: e.description || '', resource: e.resource || '', status: e.status,
+      startsAt: toLocalInput(new Date(e.startsAt)), endsAt: e.endsAt ? toLocalInput(new Date(e.endsAt)) : '',
+    });
+  };
+
+  const save = async (extra) => {
+    var body = Object.assign({
+      orgId: effectiveOrgId, kind: form.kind, title: form.title, description: form.description, resource: form.resource,
+      startsAt: new Date(form.startsAt).toISOString(), endsAt: form.endsAt ? new Date(form.endsAt).toISOString() : null,
+    }, extra || {});
+    try {
+      if (form.id) {
+        await engineCall('/change-calendar/planned/' + form.id, { method: 'PUT', body: JSON.stringify(body) });
+        toast('Change updated', 'success');
+      } else {
+        await engineCall('/change-calendar/planned', { method: 'POST', body: JSON.stringify(body) });
+        toast('Change added to the calendar', 'success');
+      }
+      setForm(null);
+      load();
+    } catch (err) { toast(err.message, 'error'); }
+  };
+
+  const remove = async () => {
+    var ok = await showConfirm({
+      title: 'Delete planned change',
+      message: 'Remove "' + form.title + '" from the change calendar? To keep a record that it was called off, mark it cancelled instead.',
+      confirmText: 'Delete',
+      danger: true,
+    });
+    if (!ok) return;
+    try {
+      await engineCall('/change-calendar/planned/' + form.id, { method: 'DELETE' });
+      toast('Planned change deleted', 'success');
+      setForm(null);
+      load();
+    } catch (err) { toast(err.message, 'error'); }
+  };
+
+  var now = Date.now();
+  var upcoming = events.filter(e => e.status !== 'cancelled' && new Date(e.endsAt || e.startsAt).getTime() >= now);
+  var next7 = upcoming.filter(e => new Date(e.startsAt).getTime() < now + 7 * 86400000).length;
+  var inProgress = upcoming.filter(e => new Date(e.startsAt).getTime() <= now).length;
+
+  var _h4 = { marginTop: 16, marginBottom: 8, fontSize: 14 };
+  var _ul = { paddingLeft: 20, margin: '4px 0 8px' };
+
+  const columns = [
+    { key: 'when', label: 'When', render: (e) => h('div', { style: { whiteSpace: 'nowrap' } },
+      new Date(e.startsAt).toLocaleString(),
+      e.endsAt && h('div', { style: { fontSize: 12, color: 'var(--text-muted)' } }, 'until ' + new Date(e.endsAt).toLocaleString())
+    ) },
+    { key: 'kind', label: 'Type', render: (e) => h('span', { className: 'badge badge-' + KINDS[e.kind].badge }, KINDS[e.kind].label) },
+    { key: 'title', label: 'Change', render: (e) => h('div', null,
+      h('div', { style: { fontWeight: 500 } }, e.title),
+      e.description && h('div', { style: { fontSize: 12, color: 'var(--text-muted)', maxWidth: 420, overflow: 'hidden', textOverflow: 'ellipsis', whiteSpace: 'nowrap' }, title: e.description }, e.description)
+    ) },
+    { key: 'resource', label: 'Affects', render: (e) => e.resource || '-' },
+    { key: 'owner', label: 'Owner', render: (e) => e.createdBy || '-' },
+    { key: 'status', label: 'Status', render: (e) => statusBadge(e.status) },
+  ];
+
+  return h('div', { className: 'page-inner' },
+    h(orgCtx.Switcher),
+    h('div', { className: 'page-header' },
+      h('h1', { style: { display: 'flex', alignItems: 'center' } }, 'Change Calendar', h(HelpButton, { label: 'Change Calendar' },
+        h('p', null, 'Every scheduled change in one timeline, so a change advisory board can review what is coming up and when.'),
+        h('h4', { style: _h4 }, 'What appears here'),
+        h('ul', { style: _ul },
+          h('li', null, h('strong', null, 'Maintenance'), ' — Agent and org-wide maintenance windows, scheduled from the Workforce page.'),
+          h('li', null, h('strong', null, 'Compliance reports'), ' — Reports generated from the Compliance page.'),
+          h('li', null, h('strong', null, 'Task deadlines'), ' — Open agent tasks that have a deadline. They drop off once completed.'),
+          h('li', null, h('strong', null, 'Rule changes, retention purges, compliance deadlines, other'), ' — Planned changes entered here. Mark them done or cancelled once they happen.')
+        ),
+        h('h4', { style: _h4 }, 'Export and subscribe'),
+        h('p', null, 'Export iCal downloads the current view as an .ics file for Outlook, Google Calendar or Apple Calendar. Changes keep the same ID across exports, so importing again updates them.'),
+        h('p', null, 'Subscribe gives you a private feed link that calendar apps poll on their own, covering the past 30 days and the next year. The link is tied to your account: anyone with it can read the calendar, so generate a new one or revoke it if it leaks. It stops working if your account is deactivated.')
+      )),
+      h('div', { style: { display: 'flex', gap: 8 } },
+        h('button', { className: 'btn btn-secondary', onClick: openSubscribe }, I.link(), ' Subscribe'),
+        h('button', { className: 'btn btn-secondary', onClick: exportICal }, I.download(), ' Export iCal'),
+        h('button', { className: 'btn btn-primary', onClick: openNew }, I.plus(), ' Plan Change')
+      )
+    ),
+
+    h('div', { className: 'stat-grid', style: { marginBottom: 16 } },
+      h('div', { className: 'stat-card' }, h('div', { className: 'stat-value' }, upcoming.length), h('div', { className: 'stat-label' }, 'Upcoming')),
+      h('div', { className: 'stat-card' }, h('div', { className: 'stat-value' }, next7), h('div', { className: 'stat-label' }, 'Next 7 days')),
+      h('div', { className: 'stat-card' }, h('div', { className: 'stat-value', style: { color: inProgress ? 'var(--warning)' : undefined } }, inProgress), h('div', { className: 'stat-label' }, 'In progress'))
+    ),
+
+    h(FilterBar, {
+      filters: filters, placeholder: 'Search changes...', viewsKey: 'change-calendar',
+      fields: [{ key: 'kind', label: 'All types', options: Object.keys(KINDS).map(k => ({ value: k, label: KINDS[k].label })) }],
+    }),
+
+    h(MonthCalendar, {
+      month: month, onMonthChange: setMonth, onSelect: openEvent,
+      events: events.filter(e => e.status !== 'cancelled'),
+      colorFor: (e) => KINDS[e.kind].color,
+      legend: Object.keys(KINDS).map(k => ({ label: KINDS[k].label, color: KINDS[k].color })),
+    }),
+
+    h('div', { className: 'card', style: { marginTop: 16 } },
+      h('div', { className: 'card-header' }, h('h3', null, 'Upcoming changes')),
+      h(Table, {
+        prefsKey: 'change-calendar',
+        className: 'data-table',
+        columns: columns,
+        rows: upcoming,
+        onRowClick: openEvent,
+        empty: loading ? 'Loading...' : filters.active ? 'No changes match these filters' : 'Nothing scheduled',
+      })
+    ),
+
+    feed && h('div', { className: 'modal-overlay', onClick: () => setFeed(null) },
+      h('div', { className: 'modal', style: { maxWidth: 560 }, onClick: e => e.stopPropagation() },
+        h('div', { className: 'modal-header' },
+          h('h2', null, 'Subscribe to Change Calendar'),
+          h('button', { className: 'btn btn-ghost btn-icon', onClick: () => setFeed(null) }, I.x())
+        ),
+        h('div', { className: 'modal-body' },
+          feed.url
+            ? h('div', null,
+                h('p', { style: { fontSize: 13, marginTop: 0 } }, 'Copy this link now — it won\'t be shown again.'),
+                h('div', { style: { display: 'flex', gap: 8 } },
+                  h('input', { className: 'input', readOnly: true, value: feed.url, style: { fontFamily: 'var(--font-mono)', fontSize: 12 }, onFocus: e => e.target.select() }),
+                  h('button', { className: 'btn btn-secondary', onClick: () => { navigator.clipboard.writeText(feed.url); toast('Feed link copied', 'success'); } }, I.copy(), ' Copy')
+                )
+              )
+            : feed.feed
+              ? h('p', { style: { fontSize: 13, marginTop: 0 } },
+                  'You have an active feed, created ' + new Date(feed.feed.createdAt).toLocaleString() + '. ',
+                  feed.feed.lastUsedAt ? 'Last fetched ' + new Date(feed.feed.lastUsedAt).toLocaleString() + '.' : 'No calendar has fetched it yet.',
+                  ' The link is only shown when generated; generate a new one if you need it again.'
+                )
+              : h('p', { style: { fontSize: 13, marginTop: 0 } }, 'Generate a private link that Outlook, Google Calendar or Apple Calendar can subscribe to. It includes maintenance windows, compliance reports and deadlines, task deadlines and planned changes.'),
+          h('h4', { style: _h4 }, 'Adding it to your calendar'),
+          h('ul', { style: _ul },
+            h('li', null, h('strong', null, 'Outlook'), ' — Add calendar → Subscribe from web, then paste the link.'),
+            h('li', null, h('strong', null, 'Google Calendar'), ' — Other calendars → + → From URL, then paste the link. Google refreshes subscribed calendars every few hours.'),
+            h('li', null, h('strong', null, 'Apple Calendar'), ' — File → New Calendar Subscription, then paste the link.')
+          )
+        ),
+        h('div', { className: 'modal-footer' },
+          feed.feed && h('button', { className: 'btn btn-danger', style: { marginRight: 'auto' }, onClick: revokeFeed }, 'Revoke'),
+          h('button', { className: 'btn btn-ghost', onClick: () => setFeed(null) }, 'Close'),
+          h('button', { className: 'btn btn-primary', onClick: issueFeed }, feed.feed ? 'Generate New Link' : 'Generate Link')
+        )
+      )
+    ),
+
+    form && h('div', { className: 'modal-overlay', onClick: () => setForm(null) },
+      h('div', { className: 'modal', style: { maxWidth: 560 }, onClick: e => e.stopPropagation() },
+        h('div', { className: 'modal-header' },
+          h('h2', null, form.id ? 'Edit Planned Change' : 'Plan Change'),
+          h('button', { className: 'btn btn-ghost btn-icon', onClick: () => setForm(null) }, I.x())
+        ),
+        h('div', { className: 'modal-body' },
+          h('div', { className: 'form-group' },
+            h('label', { className: 'form-label' }, 'Type'),
+            h('select', { className: 'input', value: form.kind, onChange: e => setForm({ ...form, kind: e.target.value }) },
+              PLANNED_KINDS.map(k => h('option', { key: k, value: k }, KINDS[k].label))
+            )
+          ),
+          h('div', { className: 'form-group' },
+            h('label', { className: 'form-label' }, 'Title'),
+            h('input', { className: 'input', value: form.title, maxLength: 200, placeholder: 'e.g. Tighten outbound PII rule', onChange: e => setForm({ ...form, title: e.target.value }) })
+          ),
+          h('div', { className: 'form-group' },
+            h('label', { className: 'form-label' }, 'Affects'),
+            h('input', { className: 'input', value: form.resource, maxLength: 255, placeholder: 'e.g. DLP rule "PII outbound", all agents', onChange: e => setForm({ ...form, resource: e.target.value }) })
+          ),
+          h('div', { style: { display: 'flex', gap: 12 } },
+            h('div', { className: 'form-group', style: { flex: 1 } },
+              h('label', { className: 'form-label' }, 'Starts'),
+              h('input', { className: 'input', type: 'datetime-local', value: form.startsAt, onChange: e => setForm({ ...form, startsAt: e.target.value }) })
+            ),
+            h('div', { className: 'form-group', style: { flex: 1 } },
+              h('label', { className: 'form-label' }, 'Ends (optional)'),
+              h('input', { className: 'input', type: 'datetime-local', value: form.endsAt, onChange: e => setForm({ ...form, endsAt: e.target.value }) })
+            )
+          ),
+          h('div', { className: 'form-group' },
+            h('label', { className: 'form-label' }, 'Description'),
+            h('textarea', { className: 'input', rows: 4, maxLength: 4000, placeholder: 'What changes, why, and how to roll back', value: form.description, onChange: e => setForm({ ...form, description: e.target.value }) })
+          )
+        ),
+        h('div', { className: 'modal-footer' },
+          form.id && h('button', { className: 'btn btn-danger', style: { marginRight: 'auto' }, onClick: remove }, 'Delete'),
+          form.id && form.status === 'planned' && h('button', { className: 'btn btn-ghost', onClick: () => save({ status: 'cancelled' }) }, 'Mark Cancelled'),
+          form.id && form.status === 'planned' && h('button', { className: 'btn btn-secondary', onClick: () => save({ status: 'done' }) }, I.check(), ' Mark Done'),
+          h('button', { className: 'btn btn-ghost', onClick: () => setForm(null) }, 'Close'),
+          h('button', {
+            className: 'btn btn-primary',
+            disabled: !form.title.trim() || !form.startsAt || (form.endsAt && new Date(form.endsAt) <= new Date(form.startsAt)),
+            onClick: () => save(),
+          }, form.id ? 'Save Changes' : 'Add to Calendar')
+        )
+      )
+    )
+  );
+}
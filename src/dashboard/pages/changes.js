@@ -0,0 +1,172 @@
+import { h, useState, useEffect, Fragment, useApp, apiCall, getOrgId, downloadExport } from '../components/utils.js';
+import { I } from '../components/icons.js';
+import { HelpButton } from '../components/help-button.js';
+import { useOrgContext } from '../components/org-switcher.js';
+import { RelativeTime } from '../components/time.js';
+
+var STATUS_BADGE = { added: 'success', removed: 'danger', changed: 'warning', 'added-removed': 'neutral' };
+var STATUS_LABEL = { added: 'added', removed: 'removed', changed: 'changed', 'added-removed': 'added & removed' };
+
+// datetime-local wants local time without a zone
+function toLocalInput(d) {
+  var off = d.getTimezoneOffset() * 60000;
+  return new Date(d.getTime() - off).toISOString().slice(0, 16);
+}
+
+function lastMonday() {
+  var d = new Date();
+  d.setHours(0, 0, 0, 0);
+  d.setDate(d.getDate() - ((d.getDay() + 6) % 7));
+  return d;
+}
+
+var PRESETS = [
+  { id: '24h', label: 'Last 24 hours', from: function() { return new Date(Date.now() - 86400000); } },
+  { id: 'monday', label: 'Since Monday', from: lastMonday },
+  { id: '7d', label: 'Last 7 days', from: function() { return new Date(Date.now() - 7 * 86400000); } },
+  { id: '30d', label: 'Last 30 days', from: function() { return new Date(Date.now() - 30 * 86400000); } },
+];
+
+export function ChangesPage() {
+  var orgCtx = useOrgContext();
+  var effectiveOrgId = orgCtx.selectedOrgId || getOrgId();
+  var { toast } = useApp();
+  var [range, setRange] = useState(function() { return { from: toLocalInput(PRESETS[2].from()), to: '' }; });
+  var [report, setReport] = useState(null);
+  var [loading, setLoading] = useState(false);
+  var [expanded, setExpanded] = useState({});
+  var [exporting, setExporting] = useState(false);
+
+  var query = function() {
+    var qs = 'from=' + encodeURIComponent(new Date(range.from).toISOString()) + '&orgId=' + encodeURIComponent(effectiveOrgId);
+    if (range.to) qs += '&to=' + encodeURIComponent(new Date(range.to).toISOString());
+    return '/admin/changes?' + qs;
+  };
+
+  var load = function() {
+    if (!range.from) return;
+    setLoading(true);
+    apiCall(query())
+      .then(function(d) { setReport(d); setExpanded({}); })
+      .catch(function(err) { toast(err.message, 'error'); })
+      .finally(function() { setLoading(false); });
+  };
+  useEffect(load, [effectiveOrgId, range.from, range.to]);
+
+  var exportPdf = function() {
+    setExporting(true);
+    downloadExport(query(), 'pdf')
+      .catch(function(err) { toast(err.message, 'error'); })
+      .finally(function() { setExporting(false); });
+  };
+
+  var toggle = function(key) { setExpanded(Object.assign({}, expanded, { [key]: !expanded[key] })); };
+  var totalChanged = report ? report.sections.reduce(function(n, s) { return n + s.entities.length; }, 0) : 0;
+  var _muted = { fontSize: 12, color: 'var(--text-muted)' };
+  var _h4 = { marginTop: 16, marginBottom: 8, fontSize: 14 };
+
+  return h('div', { className: 'page-inner' },
+    h(orgCtx.Switcher),
+    h('div', { className: 'page-header' },
+      h('h1', { style: { display: 'flex', alignItems: 'center' } }, 'What Changed', h(HelpButton, { label: 'What Changed' },
+        h('p', null, 'A readable summary of what changed in the organization between two points in time, built from the audit log — handy for weekly ops reviews.'),
+        h('h4', { style: _h4 }, 'How to read it'),
+        h('ul', { style: { paddingLeft: 20 } },
+          h('li', null, h('strong', null, 'Added'), ' — created in the window and still there at the end of it.'),
+          h('li', null, h('strong', null, 'Removed'), ' — existed before the window and was deleted in it.'),
+          h('li', null, h('strong', null, 'Changed'), ' — existed throughout and was edited or acted on.'),
+          h('li', null, h('strong', null, 'Added & removed'), ' — came and went within the window.')
+        ),
+        h('p', null, 'Rules covers email rules, DLP and guardrail rules, and policies. Agent actions are the external actions agents took themselves, from the action journal.'),
+        h('p', null, 'Download PDF for a copy to attach to a review.')
+      )),
+      h('div', { style: { display: 'flex', gap: 8 } },
+        h('button', { className: 'btn btn-secondary', onClick: load, disabled: loading }, I.refresh(), ' Refresh'),
+        h('button', { className: 'btn btn-secondary', onClick: exportPdf, disabled: exporting || !report }, I.download(), exporting ? ' Exporting...' : ' Download PDF')
+      )
+    ),
+
+    h('div', { className: 'card', style: { marginBottom: 16 } },
+      h('div', { className: 'card-body', style: { display: 'flex', gap: 12, alignItems: 'flex-end', flexWrap: 'wrap' } },
+        h('label', null, h('div', { className: 'stat-label' }, 'Changes since'),
+          h('input', { className: 'input', type: 'datetime-local', value: range.from, max: range.to || undefined, onChange: function(e) { setRange(Object.assign({}, range, { from: e.target.value })); } })),
+        h('label', null, h('div', { className: 'stat-label' }, 'Until'),
+          h('input', { className: 'input', type: 'datetime-local', value: range.to, min: range.from, placeholder: 'now', onChange: function(e) { setRange(Object.assign({}, range, { to: e.target.value })); } })),
+        range.to && h('button', { className: 'btn btn-ghost btn-sm', onClick: function() { setRange(Object.assign({}, range, { to: '' })); } }, 'Until now'),
+        h('span', { style: { flex: 1 } }),
+        PRESETS.map(function(p) {
+          return h('button', { key: p.id, className: 'btn btn-ghost btn-sm', onClick: function() { setRange({ from: toLocalInput(p.from()), to: '' }); } }, p.label);
+        })
+      )
+    ),
+
+    !report ? h('div', { style: { padding: 40, textAlign: 'center', color: 'var(--text-muted)' } }, loading ? 'Loading...' : 'Pick a start time')
+    : h(Fragment, null,
+        report.truncated && h('div', { style: { padding: '8px 12px', marginBottom: 16, borderRadius: 8, fontSize: 13, background: 'var(--warning-soft, rgba(245,158,11,0.12))', color: 'var(--warning)' } },
+          I.warning(), ' Only the first ' + report.events.toLocaleString() + ' audit events in this window were read. Narrow the window to see the rest.'),
+
+        h('div', { className: 'stat-grid', style: { marginBottom: 16 } },
+          report.sections.map(function(s) {
+            return h('div', { key: s.id, className: 'stat-card' },
+              h('div', { className: 'stat-value' }, s.entities.length),
+              h('div', { className: 'stat-label' }, s.label),
+              h('div', { style: _muted }, s.counts.added + ' added · ' + s.counts.removed + ' removed · ' + s.counts.changed + ' changed'));
+          }),
+          h('div', { className: 'stat-card' },
+            h('div', { className: 'stat-value' }, report.journal.total.toLocaleString()),
+            h('div', { className: 'stat-label' }, 'Agent actions'),
+            h('div', { style: _muted }, report.journal.reversed + ' rolled back'))
+        ),
+
+        totalChanged === 0 && h('div', { className: 'card', style: { padding: 40, textAlign: 'center', color: 'var(--text-muted)', marginBottom: 16 } }, 'Nothing changed in this window'),
+
+        report.sections.filter(function(s) { return s.entities.length > 0; }).map(function(s) {
+          return h('div', { key: s.id, className: 'card', style: { marginBottom: 16 } },
+            h('div', { className: 'card-header' }, h('h3', null, s.label)),
+            h('div', { className: 'card-body-flush' },
+              s.entities.map(function(en) {
+                var key = s.id + ':' + en.id;
+                var open = !!expanded[key];
+                return h('div', { key: key, style: { borderTop: '1px solid var(--border)' } },
+                  h('div', { onClick: function() { toggle(key); }, style: { display: 'flex', alignItems: 'center', gap: 10, padding: '8px 16px', cursor: 'pointer', fontSize: 13 } },
+                    h('span', { style: { width: 12, color: 'var(--text-muted)' } }, open ? '▾' : '▸'),
+                    h('span', { className: 'badge badge-' + STATUS_BADGE[en.status], style: { minWidth: 70, textAlign: 'center' } }, STATUS_LABEL[en.status]),
+                    h('strong', { style: { flex: 1, minWidth: 0, overflow: 'hidden', textOverflow: 'ellipsis', whiteSpace: 'nowrap' } }, en.name),
+                    h('span', { style: _muted }, en.lines.length + (en.lines.length === 1 ? ' change' : ' changes') + (en.actors.length ? ' by ' + en.actors.join(', ') : ''))
+                  ),
+                  open && h('div', { style: { padding: '0 16px 10px 38px' } },
+                    en.lines.map(function(l, i) {
+                      return h('div', { key: i, style: { display: 'flex', gap: 12, fontSize: 12, padding: '2px 0' } },
+                        h('span', { style: { color: 'var(--text-muted)', minWidth: 140 }, title: l.timestamp }, new Date(l.timestamp).toLocaleString()),
+                        h('span', null, l.text));
+                    }))
+                );
+              })
+            )
+          );
+        }),
+
+        report.journal.total > 0 && h('div', { className: 'card', style: { marginBottom: 16 } },
+          h('div', { className: 'card-header' }, h('h3', null, 'Agent Actions')),
+          h('div', { className: 'card-body', style: { display: 'grid', gridTemplateColumns: 'repeat(auto-fit, minmax(260px, 1fr))', gap: 20 } },
+            h('div', null,
+              h('div', { className: 'stat-label', style: { marginBottom: 6 } }, 'By type'),
+              Object.keys(report.journal.byType).sort(function(a, b) { return report.journal.byType[b] - report.journal.byType[a]; }).map(function(t) {
+                return h('div', { key: t, style: { display: 'flex', justifyContent: 'space-between', fontSize: 13, padding: '2px 0' } },
+                  h('span', null, t.replace(/_/g, ' ')), h('strong', null, report.journal.byType[t].toLocaleString()));
+              })
+            ),
+            h('div', null,
+              h('div', { className: 'stat-label', style: { marginBottom: 6 } }, 'By agent'),
+              report.journal.byAgent.slice(0, 10).map(function(a) {
+                return h('div', { key: a.agentId, style: { display: 'flex', justifyContent: 'space-between', fontSize: 13, padding: '2px 0' } },
+                  h('span', null, a.name), h('strong', null, a.count.toLocaleString()));
+              })
+            )
+          )
+        ),
+
+        h('div', { style: Object.assign({ textAlign: 'right' }, _muted) }, 'Generated ', h(RelativeTime, { value: report.generatedAt }))
+      )
+  );
+}
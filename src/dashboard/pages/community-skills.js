@@ -1,4 +1,4 @@
-import { h, useState, useEffect, useCallback, Fragment, useApp, engineCall, getOrgId } from '../components/utils.js';
+import { h, useState, useEffect, useCallback, Fragment, useApp, engineCall, getOrgId, showConfirm } from '../components/utils.js';
 import { I } from '../components/icons.js';
 import { E } from '../assets/icons/emoji-icons.js';
 import { mapEmojiToIcon } from './agent-detail/tools.js';
@@ -305,6 +305,11 @@ export function CommunitySkillsPage() {
   };
 
   const uninstallSkill = async (skillId) => {
+    var ok = await showConfirm({
+      title: 'Uninstall Skill', danger: true, confirmText: 'Uninstall',
+      message: 'Remove this skill? Agents lose its tools and any active connections are dropped.',
+    });
+    if (!ok) return false;
     try {
       await engineCall('/community/skills/' + skillId + '/uninstall', {
         method: 'DELETE',
@@ -312,7 +317,8 @@ export function CommunitySkillsPage() {
       });
       toast('Skill uninstalled', 'success');
       load();
-    } catch (e) { toast(e.message || 'Uninstall failed', 'error'); }
+      return true;
+    } catch (e) { toast(e.message || 'Uninstall failed', 'error'); return false; }
   };
 
   const toggleSkill = async (skillId, enable) => {
@@ -498,13 +504,16 @@ export function CommunitySkillsPage() {
   };
 
   var handleDisconnect = function(skill) {
-    if (!confirm('Disconnect ' + skill.name + '? Agents using this integration will lose access.')) return;
-    engineCall('/oauth/disconnect/' + skill.id + '?orgId=' + effectiveOrgId, { method: 'DELETE' })
-      .then(function() {
-        toast(skill.name + ' disconnected', 'success');
-        setCredStatuses(function(s) { var n = Object.assign({}, s); n[skill.id] = false; return n; });
-      })
-      .catch(function(e) { toast('Failed: ' + e.message, 'error'); });
+    showConfirm({ title: 'Disconnect ' + skill.name, message: 'This will remove stored credentials for ' + skill.name + '. Agents using this integration will lose access.', danger: true, confirmText: 'Disconnect' })
+      .then(function(ok) {
+        if (!ok) return;
+        engineCall('/oauth/disconnect/' + skill.id + '?orgId=' + effectiveOrgId, { method: 'DELETE' })
+          .then(function() {
+            toast(skill.name + ' disconnected', 'success');
+            setCredStatuses(function(s) { var n = Object.assign({}, s); n[skill.id] = false; return n; });
+          })
+          .catch(function(e) { toast('Failed: ' + e.message, 'error'); });
+      });
   };
 
   const SkillCard = (s) => {
@@ -935,7 +944,7 @@ export function CommunitySkillsPage() {
                   )
                 : h(Fragment, null,
                     h('button', { className: 'btn btn-primary', onClick: function() { setDetail(null); openCredSetup(detail); } }, 'Connect'),
-                    h('button', { className: 'btn btn-ghost btn-sm', style: { color: 'var(--danger)' }, onClick: function() { uninstallSkill(detail.id); setDetail(null); } }, 'Uninstall')
+                    h('button', { className: 'btn btn-ghost btn-sm', style: { color: 'var(--danger)' }, onClick: function() { uninstallSkill(detail.id).then(function(done) { if (done) setDetail(null); }); } }, 'Uninstall')
                   )
           )
         )
@@ -1,8 +1,9 @@
-import { h, useState, useEffect, Fragment, useApp, engineCall, buildAgentDataMap, renderAgentBadge, getOrgId } from '../components/utils.js';
+import { h, useState, useEffect, Fragment, useApp, engineCall, buildAgentDataMap, renderAgentBadge, getOrgId, openPrintView } from '../components/utils.js';
 import { I } from '../components/icons.js';
 import { HelpButton } from '../components/help-button.js';
 import { KnowledgeLink } from '../components/knowledge-link.js';
 import { useOrgContext } from '../components/org-switcher.js';
+import { SecureViewer } from '../components/secure-viewer.js';
 
 export function CompliancePage() {
   const { toast } = useApp();
@@ -17,6 +18,7 @@ export function CompliancePage() {
   const [detail, setDetail] = useState(null);
   const [detailLoading, setDetailLoading] = useState(false);
   const [detailTab, setDetailTab] = useState('summary');
+  const [viewing, setViewing] = useState(null);
 
   const load = () => {
     engineCall('/compliance/reports?orgId=' + effectiveOrgId).then(d => setReports(d.reports || [])).catch(() => {});
@@ -63,6 +65,11 @@ export function CompliancePage() {
     window.open('/api/engine/compliance/reports/' + id + '/download?format=' + format, '_blank');
   };
 
+  // Reviewers read reports in the watermarked viewer; downloads stay available but secondary
+  const viewReport = async (id) => {
+    try { setViewing(await engineCall('/compliance/reports/' + id + '/view')); } catch (e) { toast(e.message, 'error'); }
+  };
+
   const deleteReport = async (id) => {
     try { await engineCall('/compliance/reports/' + id, { method: 'DELETE' }); toast('Report deleted', 'success'); load(); setDetail(null); } catch (e) { toast(e.message, 'error'); }
   };
@@ -269,9 +276,10 @@ export function CompliancePage() {
           h('li', null, h('strong', null, 'Incident Report'), ' — Security incident summary: DLP blocks, denied approvals, escalations, reversed actions.'),
           h('li', null, h('strong', null, 'Access Review'), ' — Periodic access review: agent permissions, vault secrets, expired credentials, recommendations.')
         ),
-        h('div', { style: _tip }, h('strong', null, 'SOC 2 Tip: '), 'Generate monthly SOC 2 reports and keep the JSON exports. Auditors will want to see continuous monitoring evidence across the audit period.')
+        h('div', { style: _tip }, h('strong', null, 'SOC 2 Tip: '), 'Generate monthly SOC 2 reports and keep the JSON exports. Auditors will want to see continuous monitoring evidence across the audit period. The PDF download is a paginated copy of the report tables for sharing or filing.')
       )),
       h('div', { style: { display: 'flex', alignItems: 'center', gap: 12 } },
+        h('button', { className: 'btn btn-secondary btn-sm', onClick: openPrintView, title: 'Open a printable view of this page' }, 'Print view'),
         h(orgCtx.Switcher)
       )
     ),
@@ -322,9 +330,11 @@ export function CompliancePage() {
             h('td', null, new Date(r.createdAt).toLocaleString()),
             h('td', null, r.data?._generatedByName || r.generatedBy),
             h('td', null, h('div', { style: { display: 'flex', gap: 4 }, onClick: e => e.stopPropagation() },
+              r.status === 'completed' && h('button', { className: 'btn btn-primary btn-sm', onClick: () => viewReport(r.id), title: 'Open the full report in the watermarked viewer' }, I.eye(), ' View'),
               r.status === 'completed' && h('button', { className: 'btn btn-ghost btn-sm', onClick: () => download(r.id, 'json'), title: 'Download JSON' }, I.download(), ' JSON'),
               r.status === 'completed' && h('button', { className: 'btn btn-ghost btn-sm', onClick: () => download(r.id, 'csv'), title: 'Download CSV' }, I.download(), ' CSV'),
               r.status === 'completed' && h('button', { className: 'btn btn-ghost btn-sm', onClick: () => download(r.id, 'html'), title: 'Download HTML (full printable report)' }, I.download(), ' HTML'),
+              r.status === 'completed' && h('button', { className: 'btn btn-ghost btn-sm', onClick: () => download(r.id, 'pdf'), title: 'Download PDF' }, I.download(), ' PDF'),
               h('button', { className: 'btn btn-ghost btn-sm', onClick: () => deleteReport(r.id), title: 'Delete' }, I.trash())
             ))
           ))
@@ -332,6 +342,14 @@ export function CompliancePage() {
       )
     ),
 
+    viewing && h(SecureViewer, {
+      title: viewing.report.title,
+      subtitle: typeLabel(viewing.report.type) + ' \u2022 ' + new Date(viewing.report.createdAt).toLocaleString(),
+      viewer: viewing.viewer,
+      html: viewing.html,
+      onClose: () => setViewing(null),
+    }),
+
     // ─── Report Detail Modal ────────────────────────
     detail && h('div', { className: 'modal-overlay', onClick: () => setDetail(null) },
       h('div', { className: 'modal', style: { maxWidth: 1000, maxHeight: '90vh', display: 'flex', flexDirection: 'column' }, onClick: e => e.stopPropagation() },
@@ -345,7 +363,9 @@ export function CompliancePage() {
           h('div', { style: { display: 'flex', gap: 8, alignItems: 'center' } },
             h('button', { className: 'btn btn-ghost btn-sm', onClick: () => download(detail.id, 'json') }, I.download(), ' JSON'),
             h('button', { className: 'btn btn-ghost btn-sm', onClick: () => download(detail.id, 'csv') }, I.download(), ' CSV'),
-            h('button', { className: 'btn btn-primary btn-sm', onClick: () => download(detail.id, 'html') }, I.download(), ' Full Report (HTML)'),
+            h('button', { className: 'btn btn-ghost btn-sm', onClick: () => download(detail.id, 'pdf') }, I.download(), ' PDF'),
+            h('button', { className: 'btn btn-ghost btn-sm', onClick: () => download(detail.id, 'html') }, I.download(), ' HTML'),
+            h('button', { className: 'btn btn-primary btn-sm', onClick: () => viewReport(detail.id) }, I.eye(), ' View Full Report'),
             h('button', { className: 'btn btn-ghost btn-icon', onClick: () => setDetail(null) }, I.x())
           )
         ),
@@ -1,9 +1,57 @@
-import { h, useState, useEffect, Fragment, buildAgentEmailMap, buildAgentDataMap, resolveAgentEmail, renderAgentBadge, getOrgId, useApp, apiCall, engineCall } from '../components/utils.js';
+import { h, useState, useEffect, Fragment, buildAgentEmailMap, buildAgentDataMap, resolveAgentEmail, renderAgentBadge, getOrgId, useApp, apiCall, engineCall, downloadExport } from '../components/utils.js';
 import { I } from '../components/icons.js';
 import { DetailModal } from '../components/modal.js';
 import { HelpButton } from '../components/help-button.js';
 import { useOrgContext } from '../components/org-switcher.js';
 import { KnowledgeLink } from '../components/knowledge-link.js';
+import { Sparkline, BarChart } from '../components/charts.js';
+import { usePreference } from '../components/preferences.js';
+import { useCustomMetrics, formatMetric, CustomMetricsEditor } from '../components/custom-metrics.js';
+import { RelativeTime } from '../components/time.js';
+import { QuickActions } from './quick-actions.js';
+import { AUDIT_ANOMALY_LABELS, AuditAnomalyBadge } from './audit.js';
+
+// Home page cards in their default order. `wide` cards span both columns.
+var TREND_RANGES = [7, 14, 30, 90];
+var LEADERBOARD_RANGES = [1, 7, 30, 90];
+var LEADERBOARD_METRICS = [
+  { key: 'messages', label: 'Messages' },
+  { key: 'toolCalls', label: 'Tool calls' },
+  { key: 'errorRate', label: 'Error rate' },
+  { key: 'costUsd', label: 'Cost' },
+];
+
+function fmtUsd(v) { return '$' + (v >= 100 ? Math.round(v).toLocaleString() : v.toFixed(2)); }
+
+var ATTENTION_PRIORITY_BADGE = { critical: 'danger', high: 'warning', medium: 'info', low: 'neutral' };
+var ATTENTION_KIND_LABEL = { guardrail: 'Guardrail', dlp: 'DLP', login: 'Sign-in', approval: 'Approval' };
+
+var DASHBOARD_CARDS = [
+  { id: 'attention', label: 'Needs Attention', wide: true },
+  { id: 'quick-actions', label: 'Quick Actions', wide: true },
+  { id: 'stats', label: 'Summary', wide: true },
+  { id: 'metrics', label: 'Custom Metrics', wide: true },
+  { id: 'trend', label: 'Trends', wide: true },
+  { id: 'leaderboard', label: 'Agent Leaderboard', page: 'agents', wide: true },
+  { id: 'agents', label: 'Agents' },
+  { id: 'activity', label: 'Recent Activity' },
+  { id: 'cost', label: 'Cost & Budget', page: 'agents', wide: true },
+  { id: 'approvals', label: 'Pending Approvals', page: 'approvals' },
+  { id: 'dlp', label: 'DLP Violations', page: 'dlp' },
+  { id: 'suspicious', label: 'Suspicious Activity', page: 'audit' },
+  { id: 'api-keys', label: 'Expiring API Keys', adminOnly: true },
+];
+
+/** Keys that stop working within this many days show on the Expiring API Keys card */
+var API_KEY_EXPIRY_WARN_DAYS = 14;
+
+/** Saved order first (skipping cards that no longer exist), then any new cards */
+function orderCards(layout) {
+  var ids = DASHBOARD_CARDS.map(function(c) { return c.id; });
+  var order = (layout.order || []).filter(function(id) { return ids.indexOf(id) !== -1; });
+  ids.forEach(function(id) { if (order.indexOf(id) === -1) order.push(id); });
+  return order.map(function(id) { return DASHBOARD_CARDS.find(function(c) { return c.id === id; }); });
+}
 
 export function SetupChecklist({ onNavigate }) {
   const [status, setStatus] = useState(null);
@@ -53,13 +101,15 @@ export function DashboardPage() {
   const [stats, setStats] = useState(null);
   const [agents, setAgents] = useState([]);
   const [events, setEvents] = useState([]);
+  const [trend, setTrend] = useState([]);
+  var [trendDays, setTrendDays] = usePreference('dashboard.trendDays', 14);
 
   var _engineAgents = useState([]);
   var engineAgents = _engineAgents[0]; var setEngineAgents = _engineAgents[1];
   var _selectedEvent = useState(null);
   var selectedEvent = _selectedEvent[0]; var setSelectedEvent = _selectedEvent[1];
 
-  useEffect(() => {
+  var loadSummary = function() {
     var agentUrl = clientOrgFilter ? '/agents?clientOrgId=' + clientOrgFilter : '/agents';
     var statsUrl = clientOrgFilter ? '/stats?clientOrgId=' + clientOrgFilter : '/stats';
     var engineOrgId = clientOrgFilter || getOrgId();
@@ -67,42 +117,298 @@ export function DashboardPage() {
     apiCall(agentUrl).then(d => { var a = d?.agents || d; setAgents(Array.isArray(a) ? a : []); }).catch(() => {});
     engineCall('/agents?orgId=' + engineOrgId).then(d => setEngineAgents(d.agents || [])).catch(() => {});
     engineCall('/activity/events?limit=10&orgId=' + engineOrgId).then(d => setEvents(d.events || [])).catch(() => {});
+  };
+  useEffect(loadSummary, [clientOrgFilter]);
+
+  // Live updates for the stat cards and activity feed; the initial load above still
+  // fills them, so a browser or proxy without SSE just shows the snapshot
+  useEffect(() => {
+    if (typeof EventSource === 'undefined') return;
+    var qs = clientOrgFilter ? 'clientOrgId=' + encodeURIComponent(clientOrgFilter) : 'orgId=' + encodeURIComponent(getOrgId());
+    var es = new EventSource('/api/dashboard/stream?' + qs);
+    es.addEventListener('stats', function(e) { try { setStats(JSON.parse(e.data)); } catch (err) { /* ignore malformed frame */ } });
+    es.addEventListener('activity', function(e) { try { setEvents(JSON.parse(e.data).events || []); } catch (err) { /* ignore malformed frame */ } });
+    es.addEventListener('end', function() { es.close(); });
+    return function() { es.close(); };
   }, [clientOrgFilter]);
 
+  useEffect(() => {
+    engineCall('/activity/trend?days=' + trendDays + '&orgId=' + (clientOrgFilter || getOrgId())).then(d => setTrend(d.trend || [])).catch(() => {});
+  }, [clientOrgFilter, trendDays]);
+
   // Merge admin + engine agents; engine agents (appended last) win in the data map
   var mergedForMap = [].concat(agents, engineAgents);
   const emailMap = buildAgentEmailMap(mergedForMap);
   const agentData = buildAgentDataMap(mergedForMap);
-  const { setPage: navTo } = useApp();
+  const { setPage: navTo, user, toast, permissions } = useApp();
+  var [snapshotting, setSnapshotting] = useState(false);
+  var isAdmin = user && (user.role === 'owner' || user.role === 'admin');
+  var customMetrics = useCustomMetrics(clientOrgFilter || getOrgId());
+  var [editingMetrics, setEditingMetrics] = useState(false);
+  var exportSnapshot = function() {
+    setSnapshotting(true);
+    downloadExport('/admin/snapshot' + (clientOrgFilter ? '?orgId=' + encodeURIComponent(clientOrgFilter) : ''))
+      .catch(function(err) { toast(err.message, 'error'); })
+      .finally(function() { setSnapshotting(false); });
+  };
+
+  var [layout, setLayout] = usePreference('dashboard.layout', { order: [], hidden: [] });
+  var [customizing, setCustomizing] = useState(false);
+  // Widgets tied to a page are offered only to users who can open that page
+  var cards = orderCards(layout).filter(function(c) {
+    if (c.adminOnly && !isAdmin) return false;
+    return !c.page || permissions === '*' || (permissions && c.page in permissions);
+  });
+  var hidden = layout.hidden || [];
+  var showing = function(id) { return hidden.indexOf(id) === -1 && cards.some(function(c) { return c.id === id; }); };
+
+  var [pendingApprovals, setPendingApprovals] = useState(null);
+  var [violations, setViolations] = useState(null);
+  var [costOverview, setCostOverview] = useState(null);
+  var [budgetForm, setBudgetForm] = useState(null);
+  var wantApprovals = showing('approvals'), wantDlp = showing('dlp'), wantCost = showing('cost');
+  var loadCost = function() {
+    apiCall('/dashboard/cost-overview?orgId=' + encodeURIComponent(clientOrgFilter || getOrgId())).then(setCostOverview).catch(() => setCostOverview(null));
+  };
+  useEffect(() => { if (wantCost) loadCost(); }, [wantCost, clientOrgFilter]);
+  var saveBudget = function() {
+    apiCall('/admin/cost-budget', { method: 'PUT', body: JSON.stringify({ monthlyUsd: Number(budgetForm.monthlyUsd) || 0, warnAtPercent: Number(budgetForm.warnAtPercent) || 80 }) })
+      .then(function() { toast('Budget saved', 'success'); setBudgetForm(null); loadCost(); })
+      .catch(function(err) { toast(err.message, 'error'); });
+  };
+  var [leaderboard, setLeaderboard] = useState(null);
+  var [leaderboardDays, setLeaderboardDays] = usePreference('dashboard.leaderboardDays', 7);
+  var [leaderboardBy, setLeaderboardBy] = usePreference('dashboard.leaderboardBy', 'messages');
+  var wantLeaderboard = showing('leaderboard');
+  useEffect(() => {
+    if (wantLeaderboard) engineCall('/activity/leaderboard?days=' + leaderboardDays + '&orgId=' + (clientOrgFilter || getOrgId())).then(d => setLeaderboard(d.agents || [])).catch(() => setLeaderboard([]));
+  }, [wantLeaderboard, leaderboardDays, clientOrgFilter]);
+  var openAgent = function(id) { history.pushState(null, '', '/dashboard/agents/' + id); window.dispatchEvent(new PopStateEvent('popstate')); };
+  var [attention, setAttention] = useState(null);
+  var [dismissedAlerts, setDismissedAlerts] = usePreference('dashboard.dismissedAlerts', {});
+  var [showDismissed, setShowDismissed] = useState(false);
+  var wantAttention = showing('attention');
+  useEffect(() => {
+    if (wantAttention) engineCall('/attention?orgId=' + (clientOrgFilter || getOrgId())).then(d => setAttention(d.items || [])).catch(() => setAttention([]));
+  }, [wantAttention, clientOrgFilter]);
+  // Only ids still in the feed are kept, so the preference doesn't grow forever
+  var dismissAlerts = function(ids, dismiss) {
+    var next = {};
+    (attention || []).forEach(function(a) { if (dismissedAlerts[a.id]) next[a.id] = dismissedAlerts[a.id]; });
+    ids.forEach(function(id) { if (dismiss) next[id] = new Date().toISOString(); else delete next[id]; });
+    setDismissedAlerts(next);
+  };
+  var openLink = function(path) { history.pushState(null, '', path); window.dispatchEvent(new PopStateEvent('popstate')); };
+  // Stat card props that open `path` (a pre-filtered list), if the user can open that page
+  var drill = function(page, path, title) {
+    if (!(permissions === '*' || (permissions && page in permissions))) return {};
+    return {
+      // The card's help dialog renders inside it; clicks there shouldn't navigate
+      onClick: function(e) { if (!e.target.closest('#help-overlay')) openLink(path); },
+      title: title, role: 'link', style: { cursor: 'pointer' },
+    };
+  };
+  useEffect(() => {
+    if (wantApprovals) engineCall('/approvals/pending').then(d => setPendingApprovals(d.requests || [])).catch(() => setPendingApprovals([]));
+  }, [wantApprovals, clientOrgFilter]);
+  useEffect(() => {
+    if (wantDlp) engineCall('/dlp/violations?limit=50&orgId=' + (clientOrgFilter || getOrgId())).then(d => setViolations(d.violations || [])).catch(() => setViolations([]));
+  }, [wantDlp, clientOrgFilter]);
+  var [anomalies, setAnomalies] = useState(null);
+  var wantAnomalies = showing('suspicious');
+  useEffect(() => {
+    if (wantAnomalies) apiCall('/audit/anomalies?hours=24&orgId=' + encodeURIComponent(clientOrgFilter || getOrgId())).then(setAnomalies).catch(() => setAnomalies({ events: [], counts: {} }));
+  }, [wantAnomalies, clientOrgFilter]);
+  var [apiKeys, setApiKeys] = useState(null);
+  var wantApiKeys = showing('api-keys');
+  useEffect(() => {
+    if (wantApiKeys) apiCall('/api-keys').then(d => setApiKeys(d.keys || [])).catch(() => setApiKeys([]));
+  }, [wantApiKeys]);
+  var moveCard = function(id, delta) {
+    var order = cards.map(function(c) { return c.id; });
+    var i = order.indexOf(id), j = i + delta;
+    if (j < 0 || j >= order.length) return;
+    order.splice(i, 1); order.splice(j, 0, id);
+    setLayout({ order: order, hidden: hidden });
+  };
+  var setHidden = function(id, hide) {
+    setLayout({ order: cards.map(function(c) { return c.id; }), hidden: hide ? hidden.concat([id]) : hidden.filter(function(x) { return x !== id; }) });
+  };
 
   var _h4 = { marginTop: 16, marginBottom: 8, fontSize: 14 };
   var _ul = { paddingLeft: 20, margin: '4px 0 8px' };
   var _tip = { marginTop: 12, padding: 12, background: 'var(--bg-secondary, #1e293b)', borderRadius: 'var(--radius, 8px)', fontSize: 13 };
 
-  return h(Fragment, null,
-    h(orgCtx.Switcher),
-    h(SetupChecklist, { onNavigate: function(pg) { if (navTo) navTo(pg); } }),
-    h('div', { style: { display: 'flex', alignItems: 'center', gap: 8, marginBottom: 16 } },
-      h('h1', { style: { fontSize: 20, fontWeight: 700, margin: 0 } }, 'Dashboard'),
-      h(KnowledgeLink, { page: 'dashboard' })
-    ),
-    h('div', { className: 'stat-grid' },
-      h('div', { className: 'stat-card' }, h('div', { className: 'stat-label', style: { display: 'flex', alignItems: 'center' } }, 'Total Agents', h(HelpButton, { label: 'Total Agents' },
-        h('p', null, 'The total number of agents created in your organization, including active, paused, and archived agents.')
-      )), h('div', { className: 'stat-value' }, clientOrgFilter ? agents.length : (stats?.totalAgents ?? agents.length ?? '-'))),
-      h('div', { className: 'stat-card' }, h('div', { className: 'stat-label', style: { display: 'flex', alignItems: 'center' } }, 'Active Agents', h(HelpButton, { label: 'Active Agents' },
-        h('p', null, 'Agents currently running and available to process tasks. If this is lower than Total Agents, some agents may be paused or archived.')
-      )), h('div', { className: 'stat-value', style: { color: 'var(--success)' } }, (stats?.activeAgents ?? agents.filter(function(a) { return a.status === 'active'; }).length) || '-')),
-      h('div', { className: 'stat-card' }, h('div', { className: 'stat-label', style: { display: 'flex', alignItems: 'center' } }, 'Users', h(HelpButton, { label: 'Users' },
-        h('p', null, 'Human team members with access to this dashboard. Manage users and invite team members from the Users page.')
-      )), h('div', { className: 'stat-value' }, stats?.totalUsers ?? '-')),
-      h('div', { className: 'stat-card' }, h('div', { className: 'stat-label', style: { display: 'flex', alignItems: 'center' } }, 'Audit Events', h(HelpButton, { label: 'Audit Events' },
-        h('p', null, 'Total logged events across all agents — tool calls, deployments, errors, etc. Visit the Activity page for full details.')
-      )), h('div', { className: 'stat-value' }, stats?.totalAuditEvents ?? '-'))
-    ),
-
-    h('div', { style: { display: 'grid', gridTemplateColumns: '1fr 1fr', gap: 16 } },
-      h('div', { className: 'card' },
+  var renderCard = {
+    attention: function() {
+      var all = attention || [];
+      var open = all.filter(function(a) { return !dismissedAlerts[a.id]; });
+      var list = showDismissed ? all : open;
+      var dismissedCount = all.length - open.length;
+      var urgent = open.filter(function(a) { return a.priority === 'critical' || a.priority === 'high'; }).length;
+      return h('div', { className: 'card' },
+        h('div', { className: 'card-header' },
+          h('h3', { style: { display: 'flex', alignItems: 'center' } }, 'Needs Attention',
+            open.length > 0 && h('span', { className: 'badge badge-' + (urgent ? 'danger' : 'warning'), style: { marginLeft: 8 } }, open.length),
+            h(HelpButton, { label: 'Needs Attention' },
+              h('p', null, 'Everything from the last 7 days that someone should look at, most urgent first:'),
+              h('ul', { style: _ul },
+                h('li', null, h('strong', null, 'Guardrail'), ' — agents stopped, paused or flagged by anomaly rules.'),
+                h('li', null, h('strong', null, 'DLP'), ' — outbound content that was blocked, redacted or flagged.'),
+                h('li', null, h('strong', null, 'Sign-in'), ' — three or more failed sign-ins for one email. Shown to admins only.'),
+                h('li', null, h('strong', null, 'Approval'), ' — tool calls waiting on a human, whatever their age.')
+              ),
+              h('p', null, 'Click an item to open it. Dismissing hides it for you only; a new failed sign-in brings its email back.')
+            )),
+          h('div', { style: { display: 'flex', gap: 8 } },
+            dismissedCount > 0 && h('button', { className: 'btn btn-sm btn-ghost', onClick: function() { setShowDismissed(!showDismissed); } }, showDismissed ? 'Hide dismissed' : 'Show ' + dismissedCount + ' dismissed'),
+            open.length > 1 && h('button', { className: 'btn btn-sm btn-secondary', onClick: function() { dismissAlerts(open.map(function(a) { return a.id; }), true); } }, 'Dismiss all'))
+        ),
+        h('div', { className: 'card-body' },
+          attention === null ? h('div', { style: { color: 'var(--text-muted)', fontSize: 13 } }, 'Loading...')
+          : list.length === 0 ? h('div', { style: { textAlign: 'center', padding: 16, color: 'var(--text-muted)', fontSize: 13 } }, 'Nothing needs attention')
+          : h(Fragment, null,
+              list.slice(0, 10).map(function(a) {
+                var isDismissed = !!dismissedAlerts[a.id];
+                return h('div', { key: a.id, onClick: function() { openLink(a.link); }, style: { display: 'flex', alignItems: 'center', gap: 10, padding: '6px 0', borderTop: '1px solid var(--border)', fontSize: 13, cursor: 'pointer', opacity: isDismissed ? 0.5 : 1 } },
+                  h('span', { className: 'badge badge-' + ATTENTION_PRIORITY_BADGE[a.priority], style: { minWidth: 60, textAlign: 'center' } }, a.priority),
+                  h('span', { style: { color: 'var(--text-muted)', fontSize: 11, minWidth: 60 } }, ATTENTION_KIND_LABEL[a.kind]),
+                  h('span', { style: { flex: 1, minWidth: 0, overflow: 'hidden', textOverflow: 'ellipsis', whiteSpace: 'nowrap' } },
+                    h('strong', null, a.title),
+                    a.detail && h('span', { style: { color: 'var(--text-muted)' } }, ' — ' + a.detail)),
+                  h('span', { style: { color: 'var(--text-muted)', fontSize: 11, flexShrink: 0 } }, h(RelativeTime, { value: a.createdAt })),
+                  h('button', { className: 'btn btn-ghost btn-sm', title: isDismissed ? 'Restore' : 'Dismiss', onClick: function(e) { e.stopPropagation(); dismissAlerts([a.id], !isDismissed); } }, isDismissed ? 'Restore' : '\u00d7')
+                );
+              }),
+              list.length > 10 && h('div', { style: { fontSize: 12, color: 'var(--text-muted)', marginTop: 6 } }, '+' + (list.length - 10) + ' more')
+            )
+        )
+      );
+    },
+    'quick-actions': function() {
+      return h(QuickActions, {
+        orgId: clientOrgFilter || getOrgId(), toast: toast,
+        // Creating users is admin-only on the server
+        can: function(page) { return (page !== 'users' || isAdmin) && (permissions === '*' || !!(permissions && page in permissions)); },
+        onDone: function(page) { if (page === 'agents' || page === 'users') loadSummary(); },
+      });
+    },
+    stats: function() {
+      return h('div', { className: 'stat-grid' },
+        h('div', Object.assign({ className: 'stat-card' }, drill('agents', '/dashboard/agents', 'View all agents')), h('div', { className: 'stat-label', style: { display: 'flex', alignItems: 'center' } }, 'Total Agents', h(HelpButton, { label: 'Total Agents' },
+          h('p', null, 'The total number of agents created in your organization, including active, paused, and archived agents.')
+        )), h('div', { className: 'stat-value' }, clientOrgFilter ? agents.length : (stats?.totalAgents ?? agents.length ?? '-'))),
+        h('div', Object.assign({ className: 'stat-card' }, drill('agents', '/dashboard/agents?status=active', 'View active agents')), h('div', { className: 'stat-label', style: { display: 'flex', alignItems: 'center' } }, 'Active Agents', h(HelpButton, { label: 'Active Agents' },
+          h('p', null, 'Agents currently running and available to process tasks. If this is lower than Total Agents, some agents may be paused or archived.')
+        )), h('div', { className: 'stat-value', style: { color: 'var(--success)' } }, (stats?.activeAgents ?? agents.filter(function(a) { return a.status === 'active'; }).length) || '-')),
+        h('div', Object.assign({ className: 'stat-card' }, drill('users', '/dashboard/users', 'View users')), h('div', { className: 'stat-label', style: { display: 'flex', alignItems: 'center' } }, 'Users', h(HelpButton, { label: 'Users' },
+          h('p', null, 'Human team members with access to this dashboard. Manage users and invite team members from the Users page.')
+        )), h('div', { className: 'stat-value' }, stats?.totalUsers ?? '-')),
+        h('div', Object.assign({ className: 'stat-card' }, drill('audit', '/dashboard/audit', 'Open the audit log')), h('div', { className: 'stat-label', style: { display: 'flex', alignItems: 'center' } }, 'Audit Events', h(HelpButton, { label: 'Audit Events' },
+          h('p', null, 'Total logged events across all agents — tool calls, deployments, errors, etc. Visit the Activity page for full details.')
+        )), h('div', { className: 'stat-value' }, stats?.totalAuditEvents ?? '-'))
+      );
+    },
+    // Admin-defined cards; ones the user can't read are left out
+    metrics: function() {
+      var shown = customMetrics.metrics.filter(function(m) { var r = customMetrics.values[m.id]; return !(r && r.denied); });
+      if (shown.length === 0) return null;
+      return h('div', { className: 'stat-grid' }, shown.map(function(m) {
+        var r = customMetrics.values[m.id];
+        return h('div', { key: m.id, className: 'stat-card', title: r && r.error ? r.error : m.description || undefined },
+          h('div', { className: 'stat-label' }, m.label),
+          h('div', { className: 'stat-value', style: r && r.error ? { color: 'var(--text-muted)' } : undefined }, !r ? '…' : r.error ? '-' : formatMetric(r.value, m.format))
+        );
+      }));
+    },
+    // Only once there's something to chart
+    trend: function() {
+      if (!trend.some(d => d.toolCalls > 0 || d.errors > 0 || d.messages > 0 || d.costUsd > 0)) return null;
+      var total = function(key) { return trend.reduce((s, d) => s + (d[key] || 0), 0); };
+      var series = [
+        { key: 'messages', label: 'Messages', color: 'var(--accent)', total: total('messages').toLocaleString(), format: v => v.toLocaleString() + ' messages' },
+        { key: 'toolCalls', label: 'Tool Calls', color: 'var(--info)', total: total('toolCalls').toLocaleString(), format: v => v.toLocaleString() + ' tool calls' },
+        { key: 'costUsd', label: 'LLM Cost', color: 'var(--warning)', total: fmtUsd(total('costUsd')), format: fmtUsd },
+      ];
+      return h('div', { className: 'card' },
+        h('div', { className: 'card-header' }, h('h3', { style: { display: 'flex', alignItems: 'center' } }, 'Last ' + trendDays + ' Days', h(HelpButton, { label: 'Trends' },
+          h('p', null, 'Daily totals across all agents: messages and emails sent or received, tool calls, and estimated LLM spend. Hover a bar for the exact figure.'),
+          h('p', null, 'Errors per day are the red line next to the range picker. Days are in UTC.')
+        )),
+          h('div', { style: { display: 'flex', alignItems: 'center', gap: 8, fontSize: 12, color: 'var(--text-muted)' } },
+            total('errors').toLocaleString() + ' errors',
+            h(Sparkline, { values: trend.map(d => d.errors), width: 80, height: 20, color: 'var(--danger)', title: 'Errors per day' }),
+            h('select', { className: 'input', style: { width: 110, fontSize: 12, padding: '2px 6px' }, 'aria-label': 'Trend range', value: trendDays, onChange: function(e) { setTrendDays(parseInt(e.target.value, 10)); } },
+              TREND_RANGES.map(function(n) { return h('option', { key: n, value: n }, 'Last ' + n + ' days'); })
+            )
+          )
+        ),
+        h('div', { className: 'card-body', style: { display: 'grid', gridTemplateColumns: 'repeat(auto-fit, minmax(220px, 1fr))', gap: 20 } },
+          series.map(function(s) {
+            return h('div', { key: s.key },
+              h('div', { style: { display: 'flex', justifyContent: 'space-between', alignItems: 'baseline', marginBottom: 6 } },
+                h('span', { style: { fontSize: 12, color: 'var(--text-muted)' } }, s.label),
+                h('strong', { style: { fontSize: 16 } }, s.total)
+              ),
+              h(BarChart, { height: 64, color: s.color, title: s.label + ' per day', data: trend.map(d => ({ label: d.day, value: d[s.key] || 0 })), formatValue: s.format })
+            );
+          })
+        )
+      );
+    },
+    leaderboard: function() {
+      var ranked = (leaderboard || []).slice().sort(function(a, b) { return (b[leaderboardBy] || 0) - (a[leaderboardBy] || 0); }).slice(0, 10);
+      var top = ranked.length ? ranked[0][leaderboardBy] || 0 : 0;
+      var _right = { textAlign: 'right' };
+      var cell = function(key, text) {
+        return h('td', { style: Object.assign({}, _right, key === leaderboardBy ? { fontWeight: 600 } : { color: 'var(--text-muted)' }) }, text);
+      };
+      return h('div', { className: 'card' },
+        h('div', { className: 'card-header' }, h('h3', { style: { display: 'flex', alignItems: 'center' } }, 'Agent Leaderboard', h(HelpButton, { label: 'Agent Leaderboard' },
+          h('p', null, 'The ten agents ranking highest on the chosen measure over the selected window.'),
+          h('ul', { style: _ul },
+            h('li', null, h('strong', null, 'Messages'), ' — messages and emails sent or received.'),
+            h('li', null, h('strong', null, 'Error rate'), ' — share of tool calls that failed. The Errors column also counts general errors.'),
+            h('li', null, h('strong', null, 'Cost'), ' — estimated LLM spend.')
+          ),
+          h('div', { style: _tip }, h('strong', null, 'Tip: '), 'Click an agent to open its detail page.')
+        )),
+          h('div', { style: { display: 'flex', gap: 8 } },
+            h('select', { className: 'input', style: { width: 120, fontSize: 12, padding: '2px 6px' }, 'aria-label': 'Rank by', value: leaderboardBy, onChange: function(e) { setLeaderboardBy(e.target.value); } },
+              LEADERBOARD_METRICS.map(function(m) { return h('option', { key: m.key, value: m.key }, 'By ' + m.label.toLowerCase()); })
+            ),
+            h('select', { className: 'input', style: { width: 130, fontSize: 12, padding: '2px 6px' }, 'aria-label': 'Leaderboard window', value: leaderboardDays, onChange: function(e) { setLeaderboardDays(parseInt(e.target.value, 10)); } },
+              LEADERBOARD_RANGES.map(function(n) { return h('option', { key: n, value: n }, n === 1 ? 'Last 24 hours' : 'Last ' + n + ' days'); })
+            )
+          )
+        ),
+        h('div', { className: 'card-body' },
+          leaderboard === null ? h('div', { style: { color: 'var(--text-muted)', fontSize: 13 } }, 'Loading...')
+          : ranked.length === 0 ? h('div', { style: { color: 'var(--text-muted)', fontSize: 13 } }, 'No agent activity in this window')
+          : h('table', { className: 'data-table', style: { width: '100%', fontSize: 13 } },
+              h('thead', null, h('tr', null, h('th', { style: { width: 32 } }, '#'), h('th', null, 'Agent'), h('th', { style: { width: '25%' } }),
+                h('th', { style: _right }, 'Messages'), h('th', { style: _right }, 'Tool calls'), h('th', { style: _right }, 'Errors'), h('th', { style: _right }, 'Error rate'), h('th', { style: _right }, 'Cost'))),
+              h('tbody', null, ranked.map(function(a, i) {
+                var info = agentData[a.agentId];
+                return h('tr', { key: a.agentId, style: { cursor: 'pointer' }, onClick: function() { openAgent(a.agentId); } },
+                  h('td', { style: { color: 'var(--text-muted)' } }, i + 1),
+                  h('td', null, h('strong', { style: { color: 'var(--accent-text)' } }, info ? info.name || 'Agent' : a.agentId)),
+                  h('td', null, h('div', { style: { height: 6, borderRadius: 3, background: 'var(--bg-tertiary, var(--border))', overflow: 'hidden' } },
+                    h('div', { style: { height: '100%', width: (top > 0 ? (a[leaderboardBy] || 0) / top * 100 : 0) + '%', background: leaderboardBy === 'errorRate' ? 'var(--danger)' : 'var(--accent)' } }))),
+                  cell('messages', a.messages.toLocaleString()),
+                  cell('toolCalls', a.toolCalls.toLocaleString()),
+                  h('td', { style: Object.assign({}, _right, { color: a.errors ? 'var(--danger)' : 'var(--text-muted)' }) }, a.errors.toLocaleString()),
+                  cell('errorRate', a.toolCalls ? (a.errorRate * 100).toFixed(1) + '%' : '-'),
+                  cell('costUsd', fmtUsd(a.costUsd))
+                );
+              }))
+            ),
+          leaderboard && leaderboard.length > 10 && h('div', { style: { fontSize: 12, color: 'var(--text-muted)', marginTop: 6 } }, '+' + (leaderboard.length - 10) + ' more agents with activity')
+        )
+      );
+    },
+    agents: function() {
+      return h('div', { className: 'card' },
         h('div', { className: 'card-header' }, h('h3', { style: { display: 'flex', alignItems: 'center' } }, 'Agents', h(HelpButton, { label: 'Agents' },
           h('p', null, 'A quick overview of your agents. Click "View all" to manage them — create new agents, configure skills, deploy, and monitor.'),
           h('div', { style: _tip }, h('strong', null, 'Tip: '), 'Click an agent\'s name to see their full detail page with logs, email, sessions, and more.')
@@ -121,9 +427,187 @@ export function DashboardPage() {
                 ))
               )
         )
-      ),
-
-      h('div', { className: 'card' },
+      );
+    },
+    cost: function() {
+      var co = costOverview;
+      var budget = co && co.budget;
+      var statusColor = { ok: 'var(--success)', warning: 'var(--warning)', exceeded: 'var(--danger)' }[co && co.status] || 'var(--text-muted)';
+      var pct = budget && budget.monthlyUsd > 0 ? Math.min(100, co.monthToDate / budget.monthlyUsd * 100) : 0;
+      var projPct = budget && budget.monthlyUsd > 0 ? Math.min(100, co.projected / budget.monthlyUsd * 100) : 0;
+      var spending = co ? co.agents.filter(function(a) { return a.costThisMonth > 0; }) : [];
+      var _num = { fontSize: 20 };
+      return h('div', { className: 'card' },
+        h('div', { className: 'card-header' }, h('h3', { style: { display: 'flex', alignItems: 'center' } }, 'Cost & Budget', h(HelpButton, { label: 'Cost & Budget' },
+          h('p', null, 'Model spend across the organization\'s agents this month (UTC), from each agent\'s usage counters priced with Settings → Model Pricing.'),
+          h('ul', { style: _ul },
+            h('li', null, h('strong', null, 'Projected'), ' — month-to-date spend extended linearly to the end of the month.'),
+            h('li', null, h('strong', null, 'Budget'), ' — an org-wide monthly limit. The card warns once spend or the projection reaches the warning threshold.'),
+            h('li', null, 'Costs marked "est." were priced from token counts at the model\'s input rate because the agent didn\'t record a cost.')
+          )
+        )),
+          isAdmin && co && h('button', { className: 'btn btn-sm btn-secondary', onClick: function() { setBudgetForm(budgetForm ? null : { monthlyUsd: budget.monthlyUsd || '', warnAtPercent: budget.warnAtPercent }); } }, 'Set budget')),
+        h('div', { className: 'card-body' },
+          !co ? h('div', { style: { color: 'var(--text-muted)', fontSize: 13 } }, 'Loading...') : h(Fragment, null,
+            budgetForm && h('div', { style: { display: 'flex', gap: 8, alignItems: 'flex-end', marginBottom: 12, padding: 12, background: 'var(--bg-secondary)', borderRadius: 8 } },
+              h('label', { style: { flex: 1 } }, h('div', { className: 'stat-label' }, 'Monthly budget (USD, 0 = none)'),
+                h('input', { className: 'input', type: 'number', min: 0, step: 'any', value: budgetForm.monthlyUsd, onInput: function(e) { setBudgetForm(Object.assign({}, budgetForm, { monthlyUsd: e.target.value })); } })),
+              h('label', { style: { width: 140 } }, h('div', { className: 'stat-label' }, 'Warn at (%)'),
+                h('input', { className: 'input', type: 'number', min: 1, max: 100, value: budgetForm.warnAtPercent, onInput: function(e) { setBudgetForm(Object.assign({}, budgetForm, { warnAtPercent: e.target.value })); } })),
+              h('button', { className: 'btn btn-sm btn-primary', onClick: saveBudget }, 'Save')
+            ),
+            co.status === 'warning' && h('div', { style: { padding: '8px 12px', marginBottom: 12, borderRadius: 8, fontSize: 13, background: 'var(--warning-soft, rgba(245,158,11,0.12))', color: 'var(--warning)' } },
+              I.warning(), ' Spend is on track to reach ' + Math.round(projPct) + '% of the ' + fmtUsd(budget.monthlyUsd) + ' monthly budget'),
+            co.status === 'exceeded' && h('div', { style: { padding: '8px 12px', marginBottom: 12, borderRadius: 8, fontSize: 13, background: 'var(--danger-soft, rgba(220,38,38,0.1))', color: 'var(--danger)' } },
+              'This month\'s ' + fmtUsd(budget.monthlyUsd) + ' budget has been exceeded'),
+            h('div', { style: { display: 'flex', gap: 24, flexWrap: 'wrap', marginBottom: 12 } },
+              h('div', null, h('div', { className: 'stat-label' }, 'Today'), h('div', { className: 'stat-value', style: _num }, fmtUsd(co.today))),
+              h('div', null, h('div', { className: 'stat-label' }, 'Month to date'), h('div', { className: 'stat-value', style: _num }, fmtUsd(co.monthToDate))),
+              h('div', null, h('div', { className: 'stat-label' }, 'Projected (' + co.daysInMonth + ' days)'), h('div', { className: 'stat-value', style: Object.assign({}, _num, { color: statusColor }) }, fmtUsd(co.projected))),
+              budget.monthlyUsd > 0 && h('div', null, h('div', { className: 'stat-label' }, 'Budget'), h('div', { className: 'stat-value', style: _num }, fmtUsd(budget.monthlyUsd)))
+            ),
+            budget.monthlyUsd > 0 && h('div', { title: Math.round(pct) + '% spent, ' + Math.round(projPct) + '% projected', style: { position: 'relative', height: 8, borderRadius: 4, background: 'var(--bg-tertiary, var(--border))', marginBottom: 16, overflow: 'hidden' } },
+              h('div', { style: { position: 'absolute', left: 0, top: 0, bottom: 0, width: projPct + '%', background: statusColor, opacity: 0.3 } }),
+              h('div', { style: { position: 'absolute', left: 0, top: 0, bottom: 0, width: pct + '%', background: statusColor } }),
+              h('div', { style: { position: 'absolute', left: budget.warnAtPercent + '%', top: 0, bottom: 0, width: 2, background: 'var(--text-muted)' } })
+            ),
+            spending.length === 0
+              ? h('div', { style: { color: 'var(--text-muted)', fontSize: 13 } }, 'No spend recorded this month')
+              : h('table', { className: 'data-table', style: { width: '100%', fontSize: 13 } },
+                  h('thead', null, h('tr', null, h('th', null, 'Agent'), h('th', null, 'Model'), h('th', { style: { textAlign: 'right' } }, 'Price / 1M in·out'), h('th', { style: { textAlign: 'right' } }, 'Tokens'), h('th', { style: { textAlign: 'right' } }, 'Month'), h('th', { style: { textAlign: 'right' } }, 'Share'))),
+                  h('tbody', null, spending.slice(0, 10).map(function(a) {
+                    return h('tr', { key: a.id },
+                      h('td', null, a.name),
+                      h('td', { style: { fontFamily: 'var(--font-mono, monospace)', fontSize: 12 } }, a.modelId || '-'),
+                      h('td', { style: { textAlign: 'right' } }, a.inputCostPerMillion === null ? '-' : '$' + a.inputCostPerMillion + ' · $' + a.outputCostPerMillion),
+                      h('td', { style: { textAlign: 'right' } }, a.tokensThisMonth.toLocaleString()),
+                      h('td', { style: { textAlign: 'right', fontWeight: 600 } }, fmtUsd(a.costThisMonth), a.estimated && h('span', { style: { fontSize: 10, color: 'var(--text-muted)', marginLeft: 4 } }, 'est.')),
+                      h('td', { style: { textAlign: 'right', color: 'var(--text-muted)' } }, co.monthToDate > 0 ? Math.round(a.costThisMonth / co.monthToDate * 100) + '%' : '-')
+                    );
+                  }))
+                ),
+            spending.length > 10 && h('div', { style: { fontSize: 12, color: 'var(--text-muted)', marginTop: 6 } }, '+' + (spending.length - 10) + ' more agents')
+          )
+        )
+      );
+    },
+    approvals: function() {
+      var list = pendingApprovals || [];
+      return h('div', { className: 'card' },
+        h('div', { className: 'card-header' }, h('h3', null, 'Pending Approvals', list.length > 0 && h('span', { className: 'badge badge-warning', style: { marginLeft: 8 } }, list.length)),
+          h('button', { className: 'btn btn-sm btn-secondary', onClick: function() { navTo('approvals'); } }, 'View all')),
+        h('div', { className: 'card-body' },
+          pendingApprovals === null ? h('div', { style: { color: 'var(--text-muted)', fontSize: 13 } }, 'Loading...')
+          : list.length === 0 ? h('div', { style: { textAlign: 'center', padding: 16, color: 'var(--text-muted)', fontSize: 13 } }, 'Nothing waiting for approval')
+          : list.slice(0, 5).map(function(r) {
+              return h('div', { key: r.id, style: { display: 'flex', alignItems: 'center', gap: 8, padding: '6px 0', borderTop: '1px solid var(--border)', fontSize: 13 } },
+                h('strong', null, r.agentName || r.agentId),
+                h('span', { style: { flex: 1, minWidth: 0, overflow: 'hidden', textOverflow: 'ellipsis', whiteSpace: 'nowrap', color: 'var(--text-muted)' } }, r.toolName),
+                h('span', { className: 'badge badge-' + (r.riskLevel === 'high' || r.riskLevel === 'critical' ? 'danger' : 'neutral') }, r.riskLevel)
+              );
+            })
+        )
+      );
+    },
+    dlp: function() {
+      var list = violations || [];
+      var dayAgo = new Date(Date.now() - 86400000).toISOString();
+      var recent = list.filter(function(v) { return v.createdAt >= dayAgo; });
+      var blocked = recent.filter(function(v) { return v.actionTaken === 'blocked'; }).length;
+      return h('div', { className: 'card' },
+        h('div', { className: 'card-header' }, h('h3', null, 'DLP Violations'),
+          h('button', { className: 'btn btn-sm btn-secondary', onClick: function() { navTo('dlp'); } }, 'View all')),
+        h('div', { className: 'card-body' },
+          violations === null ? h('div', { style: { color: 'var(--text-muted)', fontSize: 13 } }, 'Loading...')
+          : h(Fragment, null,
+              h('div', { style: { display: 'flex', gap: 24, marginBottom: list.length ? 12 : 0 } },
+                h('div', drill('dlp', '/dashboard/dlp?since=24h', 'View violations from the last 24 hours'), h('div', { className: 'stat-label' }, 'Last 24h'), h('div', { className: 'stat-value', style: { fontSize: 20 } }, recent.length)),
+                h('div', drill('dlp', '/dashboard/dlp?action=blocked&since=24h', 'View blocked violations from the last 24 hours'), h('div', { className: 'stat-label' }, 'Blocked'), h('div', { className: 'stat-value', style: { fontSize: 20, color: blocked ? 'var(--danger)' : undefined } }, blocked))
+              ),
+              list.length === 0
+                ? h('div', { style: { color: 'var(--text-muted)', fontSize: 13 } }, 'No violations recorded')
+                : list.slice(0, 5).map(function(v) {
+                    var a = agentData[v.agentId];
+                    return h('div', { key: v.id, style: { display: 'flex', alignItems: 'center', gap: 8, padding: '6px 0', borderTop: '1px solid var(--border)', fontSize: 13 } },
+                      h('span', { style: { color: 'var(--text-muted)', fontSize: 11, minWidth: 70 } }, new Date(v.createdAt).toLocaleTimeString()),
+                      h('strong', null, a ? a.name : v.agentId),
+                      h('span', { style: { flex: 1, color: 'var(--text-muted)' } }, v.toolId),
+                      h('span', { className: 'badge badge-' + (v.actionTaken === 'blocked' ? 'danger' : v.actionTaken === 'redacted' ? 'warning' : 'neutral') }, v.actionTaken)
+                    );
+                  })
+            )
+        )
+      );
+    },
+    suspicious: function() {
+      var list = (anomalies && anomalies.events) || [];
+      var counts = (anomalies && anomalies.counts) || {};
+      return h('div', { className: 'card', style: list.length ? { borderLeft: '3px solid var(--warning)' } : undefined },
+        h('div', { className: 'card-header' }, h('h3', { style: { display: 'flex', alignItems: 'center' } }, 'Suspicious Activity',
+          list.length > 0 && h('span', { className: 'badge badge-warning', style: { marginLeft: 8 } }, list.length),
+          h(HelpButton, { label: 'Suspicious Activity' },
+            h('p', null, 'Audit events from the last 24 hours that look out of character for the person or API key that made them: activity at an hour they are never active, a public IP they have not used in 30 days, or a burst of 5 or more destructive actions within 10 minutes.'),
+            h('div', { style: _tip }, h('strong', null, 'Tip: '), 'Click an event to open the audit log filtered to that actor. Flagged rows carry the same warning badge there.')
+          )),
+          h('button', { className: 'btn btn-sm btn-secondary', onClick: function() { navTo('audit'); } }, 'View audit log')),
+        h('div', { className: 'card-body' },
+          anomalies === null ? h('div', { style: { color: 'var(--text-muted)', fontSize: 13 } }, 'Loading...')
+          : h(Fragment, null,
+              h('div', { style: { display: 'flex', gap: 24, marginBottom: list.length ? 12 : 0 } },
+                Object.keys(AUDIT_ANOMALY_LABELS).map(function(type) {
+                  return h('div', { key: type }, h('div', { className: 'stat-label' }, AUDIT_ANOMALY_LABELS[type]), h('div', { className: 'stat-value', style: { fontSize: 20, color: counts[type] ? 'var(--warning)' : undefined } }, counts[type] || 0));
+                })
+              ),
+              list.length === 0
+                ? h('div', { style: { color: 'var(--text-muted)', fontSize: 13 } }, 'Nothing unusual in the last 24 hours')
+                : list.slice(0, 5).map(function(e) {
+                    return h('div', {
+                      key: e.id, title: e.anomalies.map(function(a) { return a.message; }).join('\n'),
+                      onClick: function() { openLink('/dashboard/audit?actor=' + encodeURIComponent(e.actor)); },
+                      style: { display: 'flex', alignItems: 'center', gap: 8, padding: '6px 0', borderTop: '1px solid var(--border)', fontSize: 13, cursor: 'pointer' },
+                    },
+                      h('span', { style: { color: 'var(--text-muted)', fontSize: 11, minWidth: 70 } }, new Date(e.timestamp).toLocaleTimeString()),
+                      h('strong', { style: { overflow: 'hidden', textOverflow: 'ellipsis', whiteSpace: 'nowrap', maxWidth: 160 } }, (e.details && e.details.email) || e.actor),
+                      h('span', { style: { flex: 1, color: 'var(--text-muted)', overflow: 'hidden', textOverflow: 'ellipsis', whiteSpace: 'nowrap' } }, e.action),
+                      h(AuditAnomalyBadge, { anomalies: e.anomalies })
+                    );
+                  }),
+              list.length > 5 && h('div', { style: { fontSize: 12, color: 'var(--text-muted)', marginTop: 6 } }, '+' + (list.length - 5) + ' more')
+            )
+        )
+      );
+    },
+    'api-keys': function() {
+      var now = Date.now(), horizon = now + API_KEY_EXPIRY_WARN_DAYS * 86400000;
+      var expiring = (apiKeys || []).filter(function(k) {
+        var at = k.expiresAt ? new Date(k.expiresAt).getTime() : 0;
+        return !k.revoked && at > now && at <= horizon;
+      }).sort(function(a, b) { return new Date(a.expiresAt) - new Date(b.expiresAt); });
+      // Only takes up space when something is about to break
+      if (expiring.length === 0) return null;
+      return h('div', { className: 'card', style: { borderLeft: '3px solid var(--warning)' } },
+        h('div', { className: 'card-header' }, h('h3', { style: { display: 'flex', alignItems: 'center' } }, 'Expiring API Keys',
+          h('span', { className: 'badge badge-warning', style: { marginLeft: 8 } }, expiring.length),
+          h(HelpButton, { label: 'Expiring API Keys' },
+            h('p', null, 'API keys that stop working in the next ' + API_KEY_EXPIRY_WARN_DAYS + ' days. Integrations using them will get 401 errors once they expire.'),
+            h('div', { style: _tip }, h('strong', null, 'Tip: '), 'Rotate a key in Settings → API Keys to get a replacement with the same scopes while the old one keeps working for a grace period.')
+          )),
+          h('button', { className: 'btn btn-sm btn-secondary', onClick: function() { openLink('/dashboard/settings?tab=api-keys'); } }, 'Manage keys')),
+        h('div', { className: 'card-body' },
+          expiring.slice(0, 5).map(function(k) {
+            var days = Math.ceil((new Date(k.expiresAt).getTime() - now) / 86400000);
+            return h('div', { key: k.id, style: { display: 'flex', alignItems: 'center', gap: 8, padding: '6px 0', borderTop: '1px solid var(--border)', fontSize: 13 } },
+              h('strong', null, k.name),
+              h('span', { style: { flex: 1, fontFamily: 'var(--font-mono)', fontSize: 11, color: 'var(--text-muted)' } }, (k.keyPrefix || '') + '...'),
+              h('span', { className: 'badge badge-' + (days <= 3 ? 'danger' : 'warning'), title: new Date(k.expiresAt).toLocaleString() }, days <= 1 ? 'Within 24h' : 'In ' + days + ' days')
+            );
+          }),
+          expiring.length > 5 && h('div', { style: { fontSize: 12, color: 'var(--text-muted)', marginTop: 6 } }, '+' + (expiring.length - 5) + ' more')
+        )
+      );
+    },
+    activity: function() {
+      return h('div', { className: 'card' },
         h('div', { className: 'card-header' }, h('h3', { style: { display: 'flex', alignItems: 'center' } }, 'Recent Activity', h(HelpButton, { label: 'Recent Activity' },
           h('p', null, 'The latest events across all agents — deployments, tool calls, errors, and status changes. Shows the 8 most recent events.'),
           h('div', { style: _tip }, h('strong', null, 'Tip: '), 'Click any event to see its full details. Red badges indicate errors that may need attention.')
@@ -150,9 +634,47 @@ export function DashboardPage() {
                 );
               })
         )
-      )
+      );
+    },
+  };
+
+  return h(Fragment, null,
+    h(orgCtx.Switcher),
+    h(SetupChecklist, { onNavigate: function(pg) { if (navTo) navTo(pg); } }),
+    h('div', { style: { display: 'flex', alignItems: 'center', gap: 8, marginBottom: 16 } },
+      h('h1', { style: { fontSize: 20, fontWeight: 700, margin: 0 } }, 'Dashboard'),
+      h(KnowledgeLink, { page: 'dashboard' }),
+      h('span', { style: { flex: 1 } }),
+      isAdmin && customizing && h('button', { className: 'btn btn-ghost btn-sm', onClick: function() { setEditingMetrics(true); } }, 'Custom metrics'),
+      isAdmin && !customizing && h('button', { className: 'btn btn-ghost btn-sm', disabled: snapshotting, title: 'Download a read-only HTML copy of the agents, users, activity, approvals, journal and audit pages', onClick: exportSnapshot }, I.download(), snapshotting ? ' Exporting...' : ' Export snapshot'),
+      customizing && h('button', { className: 'btn btn-ghost btn-sm', onClick: function() { setLayout(undefined); } }, 'Reset layout'),
+      h('button', { className: 'btn btn-sm ' + (customizing ? 'btn-primary' : 'btn-secondary'), onClick: function() { setCustomizing(!customizing); } }, customizing ? 'Done' : 'Customize')
+    ),
+    h('div', { className: 'dashboard-cards' + (customizing ? ' customizing' : '') },
+      cards.filter(function(card) { return customizing || hidden.indexOf(card.id) === -1; }).map(function(card, i, shown) {
+        var isHidden = hidden.indexOf(card.id) !== -1;
+        var content = isHidden ? null : renderCard[card.id]();
+        if (!content && !customizing) return null;
+        return h('div', { key: card.id, className: 'dashboard-card-slot' + (card.wide ? ' wide' : '') + (isHidden ? ' is-hidden' : '') },
+          customizing && h('div', { className: 'dashboard-card-toolbar' },
+            h('strong', null, card.label),
+            isHidden && h('span', { style: { color: 'var(--text-muted)' } }, '(hidden)'),
+            h('span', { style: { flex: 1 } }),
+            h('button', { className: 'btn btn-ghost btn-sm', disabled: i === 0, title: 'Move up', onClick: function() { moveCard(card.id, -1); } }, '\u2191'),
+            h('button', { className: 'btn btn-ghost btn-sm', disabled: i === shown.length - 1, title: 'Move down', onClick: function() { moveCard(card.id, 1); } }, '\u2193'),
+            h('button', { className: 'btn btn-ghost btn-sm', onClick: function() { setHidden(card.id, !isHidden); } }, isHidden ? I.eye() : I.eyeOff(), isHidden ? ' Show' : ' Hide')
+          ),
+          content || (customizing && !isHidden ? h('div', { className: 'card', style: { padding: 16, fontSize: 13, color: 'var(--text-muted)' } }, 'Nothing to show yet') : null)
+        );
+      })
     ),
 
+    editingMetrics && h(CustomMetricsEditor, {
+      metrics: customMetrics.metrics, orgId: clientOrgFilter || getOrgId(),
+      onClose: function() { setEditingMetrics(false); },
+      onSaved: function() { setEditingMetrics(false); customMetrics.reload(); }
+    }),
+
     // ─── Event Detail Modal ──────────────────────────────
     selectedEvent && (function() {
       var ev = selectedEvent;
@@ -1,21 +1,30 @@
-import { h, useState, useEffect, Fragment, useApp, engineCall, buildAgentEmailMap, buildAgentDataMap, resolveAgentEmail, renderAgentBadge, getOrgId } from '../components/utils.js';
+import { h, useState, useEffect, Fragment, useApp, engineCall, buildAgentEmailMap, buildAgentDataMap, resolveAgentEmail, renderAgentBadge, getOrgId, downloadCsv } from '../components/utils.js';
 import { I } from '../components/icons.js';
 import { HelpButton } from '../components/help-button.js';
 import { KnowledgeLink } from '../components/knowledge-link.js';
 import { useOrgContext } from '../components/org-switcher.js';
+import { SecureViewer } from '../components/secure-viewer.js';
+import { ClassificationChip, ClassificationSelect } from '../components/classification.js';
+import { useFilters } from '../components/filter-bar.js';
+
+// Violation time filters; the dashboard's DLP card links here with ?since=24h
+const SINCE_OPTIONS = [{ value: '24h', label: 'Last 24 hours', ms: 86400000 }, { value: '7d', label: 'Last 7 days', ms: 7 * 86400000 }, { value: '30d', label: 'Last 30 days', ms: 30 * 86400000 }];
 
 export function DLPPage() {
-  const { toast } = useApp();
+  const { toast, can } = useApp();
+  const canManage = can('dlp.manage');
   var orgCtx = useOrgContext();
   var effectiveOrgId = orgCtx.selectedOrgId || getOrgId();
 
   const [rules, setRules] = useState([]);
   const [violations, setViolations] = useState([]);
-  const [tab, setTab] = useState('rules');
+  var violationFilters = useFilters({ action: '', since: '' });
+  // Arriving with violation filters in the URL opens that tab
+  const [tab, setTab] = useState(violationFilters.active > 0 ? 'violations' : 'rules');
   const [showModal, setShowModal] = useState(false);
   const [editingRule, setEditingRule] = useState(null);
   const [viewRule, setViewRule] = useState(null);
-  const defaultForm = { name: '', orgId: effectiveOrgId, patternType: 'regex', pattern: '', action: 'block', appliesTo: 'both', severity: 'high', enabled: true };
+  const defaultForm = { name: '', orgId: effectiveOrgId, patternType: 'regex', pattern: '', action: 'block', appliesTo: 'both', severity: 'high', minClassification: null, enabled: true };
   const [form, setForm] = useState(defaultForm);
   const [testContent, setTestContent] = useState('');
   const [testResults, setTestResults] = useState(null);
@@ -26,20 +35,38 @@ export function DLPPage() {
   const [packOverwrite, setPackOverwrite] = useState(false);
   const [expandedPack, setExpandedPack] = useState(null);
   const [packDetails, setPackDetails] = useState({});
+  const [viewing, setViewing] = useState(null);
+
+  // Quarantined content isn't in the list; fetching it here is what gets logged
+  const viewViolation = async (v) => {
+    try { setViewing(await engineCall('/dlp/violations/' + v.id + '/view')); } catch (e) { toast(e.message, 'error'); }
+  };
 
   const load = () => {
     engineCall('/dlp/rules?orgId=' + effectiveOrgId).then(d => setRules(d.rules || [])).catch(() => {});
-    engineCall('/dlp/violations?orgId=' + effectiveOrgId + '&limit=100').then(d => setViolations(d.violations || [])).catch(() => {});
     engineCall('/agents?orgId=' + effectiveOrgId).then(d => setAgents(d.agents || [])).catch(() => {});
     engineCall('/dlp/rule-packs').then(d => setPacks(d.packs || {})).catch(() => {});
   };
   useEffect(load, [effectiveOrgId]);
 
+  var violationQuery = function() {
+    var qs = 'orgId=' + effectiveOrgId;
+    var f = violationFilters.values;
+    if (f.action) qs += '&action=' + encodeURIComponent(f.action);
+    var since = SINCE_OPTIONS.find(o => o.value === f.since);
+    if (since) qs += '&since=' + encodeURIComponent(new Date(Date.now() - since.ms).toISOString());
+    return qs;
+  };
+  function loadViolations() {
+    engineCall('/dlp/violations?' + violationQuery() + '&limit=100').then(d => setViolations(d.violations || [])).catch(() => {});
+  }
+  useEffect(loadViolations, [effectiveOrgId, violationFilters.query]);
+
   const emailMap = buildAgentEmailMap(agents);
   const agentData = buildAgentDataMap(agents);
 
   const openCreate = () => { setEditingRule(null); setForm({ ...defaultForm, orgId: effectiveOrgId }); setShowModal(true); };
-  const openEdit = (r) => { setEditingRule(r); setForm({ name: r.name, orgId: r.orgId || effectiveOrgId, patternType: r.patternType, pattern: r.pattern, action: r.action, appliesTo: r.appliesTo || 'both', severity: r.severity, enabled: r.enabled !== false }); setShowModal(true); };
+  const openEdit = (r) => { setEditingRule(r); setForm({ name: r.name, orgId: r.orgId || effectiveOrgId, patternType: r.patternType, pattern: r.pattern, action: r.action, appliesTo: r.appliesTo || 'both', severity: r.severity, minClassification: r.minClassification || null, enabled: r.enabled !== false }); setShowModal(true); };
   const closeModal = () => { setShowModal(false); setEditingRule(null); };
 
   const saveRule = async () => {
@@ -113,7 +140,7 @@ export function DLPPage() {
     )),
       h('div', { style: { display: 'flex', alignItems: 'center', gap: 12 } },
         h(orgCtx.Switcher),
-        h('button', { className: 'btn btn-primary', onClick: openCreate }, I.plus(), ' Add Rule')
+        canManage && h('button', { className: 'btn btn-primary', onClick: openCreate }, I.plus(), ' Add Rule')
       )
     ),
     h('div', { className: 'tabs', style: { marginBottom: 16 } },
@@ -125,18 +152,18 @@ export function DLPPage() {
         h('tbody', null, rules.length === 0
           ? h('tr', null, h('td', { colSpan: 7, style: { textAlign: 'center', color: 'var(--text-muted)', padding: 40 } }, 'No DLP rules configured'))
           : rules.map(r => h('tr', { key: r.id, style: { cursor: 'pointer' }, onClick: () => setViewRule(r) },
-            h('td', null, h('strong', null, r.name)),
+            h('td', null, h('strong', null, r.name), r.minClassification && h(ClassificationChip, { level: r.minClassification, style: { marginLeft: 6 } })),
             h('td', null, h('span', { className: 'badge-tag' }, r.patternType)),
             h('td', null, h('code', { style: { fontSize: 11 } }, r.pattern.substring(0, 40) + (r.pattern.length > 40 ? '...' : ''))),
             h('td', null, h('span', { className: 'status-badge status-' + (r.action === 'block' ? 'error' : r.action === 'redact' ? 'warning' : 'info') }, r.action)),
             h('td', null, h('span', { style: { color: severityColor(r.severity), fontWeight: 600 } }, r.severity)),
-            h('td', { onClick: e => e.stopPropagation() }, h('button', { className: 'btn btn-ghost btn-sm', onClick: () => toggleRule(r), title: r.enabled !== false ? 'Disable' : 'Enable' },
+            h('td', { onClick: e => e.stopPropagation() }, h('button', { className: 'btn btn-ghost btn-sm', onClick: () => toggleRule(r), disabled: !canManage, title: r.enabled !== false ? 'Disable' : 'Enable' },
               h('span', { className: 'status-badge ' + (r.enabled !== false ? 'status-success' : 'status-neutral') }, r.enabled !== false ? 'On' : 'Off')
             )),
             h('td', { onClick: e => e.stopPropagation() },
               h('div', { style: { display: 'flex', gap: 4 } },
-                h('button', { className: 'btn btn-ghost btn-sm', onClick: () => openEdit(r), title: 'Edit' }, I.edit()),
-                h('button', { className: 'btn btn-ghost btn-sm', onClick: () => deleteRule(r.id), title: 'Delete' }, I.trash())
+                canManage && h('button', { className: 'btn btn-ghost btn-sm', onClick: () => openEdit(r), title: 'Edit' }, I.edit()),
+                canManage && h('button', { className: 'btn btn-ghost btn-sm', onClick: () => deleteRule(r.id), title: 'Delete' }, I.trash())
               )
             )
           ))
@@ -155,7 +182,7 @@ export function DLPPage() {
               h('input', { type: 'checkbox', checked: packOverwrite, onChange: e => setPackOverwrite(e.target.checked) }),
               'Overwrite existing rules with same name'
             ),
-            h('button', { className: 'btn btn-primary', onClick: applySelectedPacks, disabled: applyingPacks }, applyingPacks ? 'Applying...' : 'Apply Selected Packs')
+            h('button', { className: 'btn btn-primary', onClick: applySelectedPacks, disabled: applyingPacks || !canManage }, applyingPacks ? 'Applying...' : 'Apply Selected Packs')
           ),
           h('div', { style: { display: 'grid', gap: 12 } },
             Object.entries(packs).map(([id, pack]) => h('div', { key: id, style: { border: '1px solid ' + (selectedPacks[id] ? 'var(--accent)' : 'var(--border)'), borderRadius: 'var(--radius, 8px)', padding: 16, background: selectedPacks[id] ? 'var(--accent-soft, rgba(59,130,246,0.08))' : 'var(--bg-secondary)', transition: 'all 0.15s ease', cursor: 'pointer' }, onClick: () => togglePack(id) },
@@ -200,17 +227,31 @@ export function DLPPage() {
       )
     ),
     tab === 'violations' && h('div', { className: 'card' },
+      h('div', { className: 'card-header' }, h('h3', null, 'Violations'),
+        h('div', { style: { display: 'flex', gap: 8, alignItems: 'center' } },
+          h('select', { className: 'input', style: { width: 140 }, 'aria-label': 'Action', value: violationFilters.values.action, onChange: e => violationFilters.set('action', e.target.value) },
+            h('option', { value: '' }, 'All actions'),
+            ['blocked', 'redacted', 'warned', 'logged'].map(a => h('option', { key: a, value: a }, a.charAt(0).toUpperCase() + a.slice(1)))),
+          h('select', { className: 'input', style: { width: 150 }, 'aria-label': 'Time', value: violationFilters.values.since, onChange: e => violationFilters.set('since', e.target.value) },
+            h('option', { value: '' }, 'Any time'),
+            SINCE_OPTIONS.map(o => h('option', { key: o.value, value: o.value }, o.label))),
+          violationFilters.active > 0 && h('button', { className: 'btn btn-ghost btn-sm', onClick: violationFilters.clear }, 'Clear'),
+          h('button', { className: 'btn btn-secondary btn-sm', title: 'Download every matching violation, not just the latest 100', onClick: () => downloadCsv('/engine/dlp/violations?' + violationQuery()).catch(err => toast(err.message, 'error')) }, I.download(), ' Export CSV')
+        )
+      ),
       h('table', { className: 'data-table' },
         h('thead', null, h('tr', null, h('th', null, 'Time'), h('th', null, 'Agent'), h('th', null, 'Tool'), h('th', null, 'Action'), h('th', null, 'Direction'), h('th', null, 'Match'))),
         h('tbody', null, violations.length === 0
-          ? h('tr', null, h('td', { colSpan: 6, style: { textAlign: 'center', color: 'var(--text-muted)', padding: 40 } }, 'No violations recorded'))
+          ? h('tr', null, h('td', { colSpan: 6, style: { textAlign: 'center', color: 'var(--text-muted)', padding: 40 } }, violationFilters.active > 0 ? 'No violations match these filters' : 'No violations recorded'))
           : violations.map(v => h('tr', { key: v.id },
             h('td', null, new Date(v.createdAt).toLocaleString()),
             h('td', null, renderAgentBadge(v.agentId, agentData)),
             h('td', null, v.toolId),
             h('td', null, h('span', { className: 'status-badge status-' + (v.actionTaken === 'blocked' ? 'error' : v.actionTaken === 'redacted' ? 'warning' : 'info') }, v.actionTaken)),
             h('td', null, v.direction),
-            h('td', null, h('code', { style: { fontSize: 11 } }, v.matchContext || '-'))
+            h('td', null, v.quarantined
+              ? h('button', { className: 'btn btn-ghost btn-sm', onClick: () => viewViolation(v), title: 'Quarantined content — opens a watermarked view and records the access' }, I.lock(), ' View')
+              : h('code', { style: { fontSize: 11 } }, v.matchContext || '-'))
           ))
         )
       )
@@ -231,6 +272,19 @@ export function DLPPage() {
         )
       )
     ),
+    viewing && h(SecureViewer, {
+      title: 'Quarantined Content',
+      subtitle: [viewing.rule?.name || viewing.violation.ruleId, viewing.violation.toolId, new Date(viewing.violation.createdAt).toLocaleString()].join(' \u2022 '),
+      viewer: viewing.viewer,
+      onClose: () => setViewing(null),
+    },
+      h('div', { style: { display: 'grid', gridTemplateColumns: '140px 1fr', gap: '8px 16px', fontSize: 13, marginBottom: 16 } },
+        h('strong', null, 'Agent'), h('div', null, renderAgentBadge(viewing.violation.agentId, agentData)),
+        h('strong', null, 'Action'), h('div', null, viewing.violation.actionTaken + ' (' + viewing.violation.direction + ')'),
+        h('strong', null, 'Rule'), h('div', null, viewing.rule ? viewing.rule.name + ' \u2014 ' + viewing.rule.severity : viewing.violation.ruleId)
+      ),
+      h('pre', { style: { fontSize: 12, fontFamily: 'var(--font-mono, monospace)', background: 'var(--bg-secondary)', padding: 12, borderRadius: 6, whiteSpace: 'pre-wrap', wordBreak: 'break-all', minHeight: 120, margin: 0 } }, viewing.violation.matchContext || '(no content captured)')
+    ),
     showModal && h('div', { className: 'modal-overlay', onClick: closeModal },
       h('div', { className: 'modal', onClick: e => e.stopPropagation() },
         h('div', { className: 'modal-header' }, h('h2', null, editingRule ? 'Edit DLP Rule' : 'Create DLP Rule'), h('button', { className: 'btn btn-ghost btn-icon', onClick: closeModal }, I.x())),
@@ -246,6 +300,9 @@ export function DLPPage() {
           h('select', { className: 'input', value: form.severity, onChange: e => setForm({ ...form, severity: e.target.value }) }, h('option', { value: 'critical' }, 'Critical'), h('option', { value: 'high' }, 'High'), h('option', { value: 'medium' }, 'Medium'), h('option', { value: 'low' }, 'Low')),
           h('label', { className: 'field-label' }, 'Applies To'),
           h('select', { className: 'input', value: form.appliesTo, onChange: e => setForm({ ...form, appliesTo: e.target.value }) }, h('option', { value: 'both' }, 'Both'), h('option', { value: 'inbound' }, 'Inbound'), h('option', { value: 'outbound' }, 'Outbound')),
+          h('label', { className: 'field-label' }, 'Applies to agents handling'),
+          h(ClassificationSelect, { value: form.minClassification, noneLabel: 'Any data (all agents)', atOrAbove: true, style: { width: '100%' }, onChange: v => setForm({ ...form, minClassification: v }) }),
+          h('div', { style: { fontSize: 11, color: 'var(--text-muted)', marginTop: 4 } }, 'An agent handles the highest classification of its mailbox and the knowledge bases it can read.'),
           h('label', { style: { display: 'flex', alignItems: 'center', gap: 8, marginTop: 12, cursor: 'pointer' } },
             h('input', { type: 'checkbox', checked: form.enabled, onChange: e => setForm({ ...form, enabled: e.target.checked }) }),
             'Enabled'
@@ -271,6 +328,7 @@ export function DLPPage() {
             h('div', null, h('div', { style: { color: 'var(--text-muted)', fontSize: 11, marginBottom: 2 } }, 'Action'), h('span', { className: 'status-badge status-' + (viewRule.action === 'block' ? 'error' : viewRule.action === 'redact' ? 'warning' : 'info') }, viewRule.action)),
             h('div', null, h('div', { style: { color: 'var(--text-muted)', fontSize: 11, marginBottom: 2 } }, 'Severity'), h('span', { style: { color: severityColor(viewRule.severity), fontWeight: 600 } }, viewRule.severity)),
             h('div', null, h('div', { style: { color: 'var(--text-muted)', fontSize: 11, marginBottom: 2 } }, 'Applies To'), viewRule.appliesTo || 'both'),
+            h('div', null, h('div', { style: { color: 'var(--text-muted)', fontSize: 11, marginBottom: 2 } }, 'Agents Handling'), viewRule.minClassification ? h(Fragment, null, h(ClassificationChip, { level: viewRule.minClassification }), ' and above') : 'Any data'),
             h('div', null, h('div', { style: { color: 'var(--text-muted)', fontSize: 11, marginBottom: 2 } }, 'Status'), h('span', { className: 'status-badge ' + (viewRule.enabled !== false ? 'status-success' : 'status-neutral') }, viewRule.enabled !== false ? 'Enabled' : 'Disabled')),
             h('div', null, h('div', { style: { color: 'var(--text-muted)', fontSize: 11, marginBottom: 2 } }, 'Organization'), h('code', { style: { fontSize: 11 } }, viewRule.orgId || '-'))
           ),
@@ -0,0 +1,319 @@
+import { h, useState, useEffect, Fragment, useApp, engineCall, apiCall, getOrgId, showConfirm, buildAgentDataMap, renderAgentBadge, downloadExport } from '../components/utils.js';
+import { I } from '../components/icons.js';
+import { HelpButton } from '../components/help-button.js';
+import { useOrgContext } from '../components/org-switcher.js';
+import { Table } from '../components/table.js';
+import { Modal } from '../components/modal.js';
+import { SecureViewer } from '../components/secure-viewer.js';
+import { RelativeTime, formatTime } from '../components/time.js';
+
+// ─── e-Discovery Cases ───────────────────────────────────
+// Named legal matters bundling saved searches, legal holds, frozen
+// transcripts and reviewer notes. Only custodians see a case.
+
+var SOURCES = [['', 'All sources'], ['internal', 'Internal'], ['whatsapp', 'WhatsApp'], ['telegram', 'Telegram']];
+var TABS = [['search', 'Saved Searches'], ['transcript', 'Transcripts'], ['hold', 'Legal Holds'], ['note', 'Notes']];
+
+function queryLabel(q) {
+  if (!q) return '';
+  var parts = [q.q];
+  if (q.source) parts.push('source: ' + q.source);
+  if (q.sender) parts.push('sender: ' + q.sender);
+  if (q.from || q.to) parts.push((q.from || '…') + ' – ' + (q.to || '…'));
+  return parts.join(' · ');
+}
+
+function CustodianPicker(props) {
+  var users = props.users;
+  if (users.length === 0) return h('div', { style: { fontSize: 12, color: 'var(--text-muted)' } }, 'User list unavailable — you will be the only custodian.');
+  return h('div', { style: { maxHeight: 180, overflowY: 'auto', border: '1px solid var(--border)', borderRadius: 6, padding: 8 } },
+    users.map(u => h('label', { key: u.id, style: { display: 'flex', alignItems: 'center', gap: 8, fontSize: 13, padding: '3px 0' } },
+      h('input', { type: 'checkbox', checked: props.value.includes(u.id), disabled: u.id === props.lockedId, onChange: () => props.onChange(props.value.includes(u.id) ? props.value.filter(x => x !== u.id) : props.value.concat([u.id])) }),
+      u.name || u.email, h('span', { style: { color: 'var(--text-muted)', fontSize: 11 } }, u.email)
+    ))
+  );
+}
+
+function CaseForm(props) {
+  var initial = props.initial || {};
+  const [form, setForm] = useState({ name: initial.name || '', matter: initial.matter || '', description: initial.description || '', custodians: initial.custodians || (props.userId ? [props.userId] : []) });
+  const [saving, setSaving] = useState(false);
+  var set = (k, v) => setForm(f => Object.assign({}, f, { [k]: v }));
+  var submit = async () => {
+    setSaving(true);
+    try { await props.onSave(form); } finally { setSaving(false); }
+  };
+  return h(Modal, {
+    title: props.initial ? 'Edit Case' : 'New Case', onClose: props.onClose,
+    footer: h(Fragment, null,
+      h('button', { className: 'btn btn-secondary', onClick: props.onClose }, 'Cancel'),
+      h('button', { className: 'btn btn-primary', disabled: saving || !form.name.trim() || form.custodians.length === 0, onClick: submit }, props.initial ? 'Save' : 'Create Case')
+    )
+  },
+    h('label', { className: 'field-label' }, 'Case name'),
+    h('input', { className: 'input', value: form.name, onChange: e => set('name', e.target.value), placeholder: 'e.g. Acme v. Example Corp', autoFocus: true }),
+    h('label', { className: 'field-label', style: { marginTop: 12 } }, 'Matter / docket number'),
+    h('input', { className: 'input', value: form.matter, onChange: e => set('matter', e.target.value), placeholder: 'Optional' }),
+    h('label', { className: 'field-label', style: { marginTop: 12 } }, 'Description'),
+    h('textarea', { className: 'input', rows: 3, value: form.description, onChange: e => set('description', e.target.value) }),
+    h('label', { className: 'field-label', style: { marginTop: 12 } }, 'Custodians'),
+    h('div', { style: { fontSize: 12, color: 'var(--text-muted)', marginBottom: 6 } }, 'Only these users can open the case. Owners can always open it.'),
+    h(CustodianPicker, { users: props.users, value: form.custodians, onChange: v => set('custodians', v), lockedId: props.initial ? null : props.userId })
+  );
+}
+
+function TranscriptModal(props) {
+  const [data, setData] = useState(null);
+  useEffect(() => {
+    engineCall('/ediscovery/cases/' + props.caseId + '/items/' + props.itemId).then(setData).catch(err => props.toast(err.message, 'error'));
+  }, [props.itemId]);
+  var item = data && data.item;
+  var messages = item ? item.data.messages || [] : [];
+  return h(SecureViewer, {
+    title: item ? item.title : 'Transcript',
+    subtitle: item && messages.length.toLocaleString() + ' messages · frozen ' + formatTime(item.createdAt, 'full') + ' · ' + queryLabel(item.data.query),
+    viewer: data && data.viewer,
+    onClose: props.onClose,
+  },
+    !item ? h('div', { style: { padding: 24, textAlign: 'center', color: 'var(--text-muted)' } }, 'Loading...') : h(Fragment, null,
+      messages.slice(0, 500).map(m => h('div', { key: m.id, style: { padding: '8px 0', borderBottom: '1px solid var(--border)' } },
+        h('div', { style: { fontSize: 12, color: 'var(--text-muted)', display: 'flex', gap: 8 } },
+          h('strong', { style: { color: 'var(--text-primary)' } }, props.nameOf(m.sender)), '→', props.nameOf(m.recipient),
+          h('span', { style: { marginLeft: 'auto' } }, formatTime(m.createdAt, 'full'))
+        ),
+        m.subject && h('div', { style: { fontSize: 13, fontWeight: 500 } }, m.subject),
+        h('div', { style: { fontSize: 13, whiteSpace: 'pre-wrap' } }, m.text)
+      )),
+      messages.length > 500 && h('div', { style: { fontSize: 12, color: 'var(--text-muted)', marginTop: 8 } }, 'Showing the first 500. The export package has every message.')
+    )
+  );
+}
+
+function CaseDetail(props) {
+  const { toast } = useApp();
+  const [data, setData] = useState(null);
+  const [tab, setTab] = useState('search');
+  const [editing, setEditing] = useState(false);
+  const [viewing, setViewing] = useState(null);
+  const [busy, setBusy] = useState(false);
+  const [searchForm, setSearchForm] = useState({ title: '', q: '', source: '', from: '', to: '' });
+  const [holdForm, setHoldForm] = useState({ agentId: '', reason: '' });
+  const [note, setNote] = useState('');
+  var base = '/ediscovery/cases/' + props.caseId;
+
+  var load = () => engineCall(base).then(setData).catch(err => { toast(err.message, 'error'); props.onBack(); });
+  useEffect(() => { load(); }, [props.caseId]);
+
+  var run = async (fn, ok) => {
+    setBusy(true);
+    try { await fn(); if (ok) toast(ok, 'success'); load(); } catch (err) { toast(err.message, 'error'); }
+    setBusy(false);
+  };
+  var post = (path, body) => engineCall(base + path, { method: 'POST', body: JSON.stringify(body) });
+
+  if (!data) return h('div', { style: { padding: 40, textAlign: 'center', color: 'var(--text-muted)' } }, 'Loading case...');
+  var c = data.case;
+  var closed = c.status === 'closed';
+  var items = data.items.filter(i => i.kind === tab);
+  var counts = {};
+  data.items.forEach(i => { counts[i.kind] = (counts[i.kind] || 0) + 1; });
+  var nameOfUser = (id) => { var u = props.users.find(x => x.id === id); return u ? u.name || u.email : id || 'unknown'; };
+  var nameOfAgent = (id) => props.agentData[id] && props.agentData[id].name || id;
+  var searchQuery = () => { var q = { q: searchForm.q.trim() }; ['source', 'from', 'to'].forEach(k => { if (searchForm[k]) q[k] = searchForm[k]; }); return q; };
+
+  var toggleStatus = async () => {
+    if (!closed) {
+      var ok = await showConfirm({ title: 'Close this case?', message: 'A closed case is read-only until it is reopened. Legal holds stay in place — release them under Settings → Data Retention when the matter ends.', confirmText: 'Close Case' });
+      if (!ok) return;
+    }
+    run(() => engineCall(base, { method: 'PATCH', body: JSON.stringify({ status: closed ? 'open' : 'closed' }) }), closed ? 'Case reopened' : 'Case closed');
+  };
+
+  var unlink = async (item) => {
+    var ok = await showConfirm({
+      title: 'Remove from case?',
+      message: item.kind === 'hold' ? 'The hold is unlinked from this case but stays in place. Release it under Settings → Data Retention.' : 'The saved search is removed from the case. Transcripts already exported from it are kept.',
+      confirmText: 'Remove',
+    });
+    if (ok) run(() => engineCall(base + '/items/' + item.id, { method: 'DELETE' }), 'Removed');
+  };
+
+  var itemMeta = (i) => h('div', { style: { fontSize: 11, color: 'var(--text-muted)' } }, nameOfUser(i.createdBy), ' · ', h(RelativeTime, { value: i.createdAt }));
+
+  var tabBody = {
+    search: h(Fragment, null,
+      !closed && h('form', {
+        className: 'card', style: { padding: 12, marginBottom: 12, display: 'flex', gap: 8, flexWrap: 'wrap', alignItems: 'center' },
+        onSubmit: e => { e.preventDefault(); run(() => post('/searches', { title: searchForm.title, query: searchQuery() }).then(() => setSearchForm({ title: '', q: '', source: '', from: '', to: '' })), 'Search saved'); }
+      },
+        h('input', { className: 'input', style: { flex: 2, minWidth: 200 }, placeholder: 'Search terms — "quote" exact phrases', value: searchForm.q, onChange: e => setSearchForm(Object.assign({}, searchForm, { q: e.target.value })) }),
+        h('input', { className: 'input', style: { flex: 1, minWidth: 140 }, placeholder: 'Label (optional)', value: searchForm.title, onChange: e => setSearchForm(Object.assign({}, searchForm, { title: e.target.value })) }),
+        h('select', { className: 'input', style: { width: 'auto' }, value: searchForm.source, onChange: e => setSearchForm(Object.assign({}, searchForm, { source: e.target.value })) }, SOURCES.map(s => h('option', { key: s[0], value: s[0] }, s[1]))),
+        h('input', { className: 'input', type: 'date', style: { width: 'auto' }, title: 'From', value: searchForm.from, onChange: e => setSearchForm(Object.assign({}, searchForm, { from: e.target.value })) }),
+        h('input', { className: 'input', type: 'date', style: { width: 'auto' }, title: 'To', value: searchForm.to, onChange: e => setSearchForm(Object.assign({}, searchForm, { to: e.target.value })) }),
+        h('button', { className: 'btn btn-primary', type: 'submit', disabled: busy || !searchForm.q.trim() }, I.plus(), ' Save Search')
+      ),
+      h('div', { className: 'card' }, h(Table, {
+        className: 'data-table', rows: items, empty: 'No saved searches',
+        columns: [
+          { key: 'title', label: 'Search', render: i => h('div', null, h('div', { style: { fontWeight: 500 } }, i.title), h('div', { style: { fontSize: 12, color: 'var(--text-muted)' } }, queryLabel(i.data.query))) },
+          { key: 'by', label: 'Saved', render: itemMeta },
+          { key: 'actions', label: '', align: 'right', render: i => !closed && h('div', { style: { display: 'flex', gap: 6, justifyContent: 'flex-end' } },
+            h('button', { className: 'btn btn-secondary btn-sm', disabled: busy, title: 'Freeze the messages this search matches now into a transcript', onClick: () => run(() => post('/transcripts', { title: i.title, query: i.data.query }).then(() => setTab('transcript')), 'Transcript exported') }, I.download(), ' Export Transcript'),
+            h('button', { className: 'btn btn-ghost btn-sm', disabled: busy, title: 'Remove from case', onClick: () => unlink(i) }, I.trash())
+          ) },
+        ],
+      }))
+    ),
+    transcript: h('div', { className: 'card' }, h(Table, {
+      className: 'data-table', rows: items, empty: 'No transcripts — export one from a saved search',
+      onRowClick: i => setViewing(i.id),
+      columns: [
+        { key: 'title', label: 'Transcript', render: i => h('div', null, h('div', { style: { fontWeight: 500 } }, i.title), h('div', { style: { fontSize: 12, color: 'var(--text-muted)' } }, queryLabel(i.data.query))) },
+        { key: 'count', label: 'Messages', render: i => (i.data.count || 0).toLocaleString() },
+        { key: 'by', label: 'Exported', render: itemMeta },
+      ],
+    })),
+    hold: h(Fragment, null,
+      !closed && h('form', {
+        className: 'card', style: { padding: 12, marginBottom: 12, display: 'flex', gap: 8, flexWrap: 'wrap', alignItems: 'center' },
+        onSubmit: e => { e.preventDefault(); run(() => post('/holds', holdForm).then(() => setHoldForm({ agentId: '', reason: '' })), 'Legal hold placed'); }
+      },
+        h('select', { className: 'input', style: { width: 'auto' }, value: holdForm.agentId, onChange: e => setHoldForm(Object.assign({}, holdForm, { agentId: e.target.value })) },
+          h('option', { value: '' }, 'Select agent...'),
+          props.agents.map(a => h('option', { key: a.id, value: a.id }, nameOfAgent(a.id)))
+        ),
+        h('input', { className: 'input', style: { flex: 1, minWidth: 200 }, placeholder: 'Reason (defaults to the case name)', value: holdForm.reason, onChange: e => setHoldForm(Object.assign({}, holdForm, { reason: e.target.value })) }),
+        h('button', { className: 'btn btn-primary', type: 'submit', disabled: busy || !holdForm.agentId }, I.lock(), ' Place Hold')
+      ),
+      h('div', { className: 'card' }, h(Table, {
+        className: 'data-table', rows: items, empty: 'No legal holds linked to this case',
+        columns: [
+          { key: 'agent', label: 'Agent', render: i => renderAgentBadge(i.data.agentId, props.agentData) },
+          { key: 'reason', label: 'Reason', render: i => i.data.reason },
+          { key: 'state', label: 'Status', render: i => i.data.active ? h('span', { className: 'badge badge-warning' }, 'On hold') : h('span', { className: 'badge badge-neutral' }, 'Released') },
+          { key: 'by', label: 'Linked', render: itemMeta },
+          { key: 'actions', label: '', align: 'right', render: i => !closed && h('button', { className: 'btn btn-ghost btn-sm', disabled: busy, title: 'Unlink from case (the hold stays)', onClick: () => unlink(i) }, I.trash()) },
+        ],
+      }))
+    ),
+    note: h(Fragment, null,
+      !closed && h('div', { className: 'card', style: { padding: 12, marginBottom: 12 } },
+        h('textarea', { className: 'input', rows: 3, placeholder: 'Add a reviewer note. Notes are permanent and attributed to you.', value: note, onChange: e => setNote(e.target.value) }),
+        h('div', { style: { display: 'flex', justifyContent: 'flex-end', marginTop: 8 } },
+          h('button', { className: 'btn btn-primary btn-sm', disabled: busy || !note.trim(), onClick: () => run(() => post('/notes', { text: note }).then(() => setNote('')), 'Note added') }, 'Add Note')
+        )
+      ),
+      items.length === 0
+        ? h('div', { className: 'card', style: { padding: 24, textAlign: 'center', color: 'var(--text-muted)' } }, 'No notes yet')
+        : items.map(i => h('div', { key: i.id, className: 'card', style: { padding: 12, marginBottom: 8 } },
+            itemMeta(i),
+            h('div', { style: { fontSize: 13, whiteSpace: 'pre-wrap', marginTop: 6 } }, i.data.text)
+          ))
+    ),
+  };
+
+  return h(Fragment, null,
+    h('button', { className: 'btn btn-ghost btn-sm', onClick: props.onBack, style: { marginBottom: 12 } }, '← All cases'),
+    h('div', { className: 'card', style: { padding: 16, marginBottom: 16 } },
+      h('div', { style: { display: 'flex', alignItems: 'flex-start', gap: 12, flexWrap: 'wrap' } },
+        h('div', { style: { flex: 1, minWidth: 240 } },
+          h('h2', { style: { margin: 0, fontSize: 18, display: 'flex', alignItems: 'center', gap: 8 } }, c.name,
+            h('span', { className: 'badge ' + (closed ? 'badge-neutral' : 'badge-success') }, closed ? 'Closed' : 'Open')),
+          c.matter && h('div', { style: { fontSize: 13, color: 'var(--text-muted)', marginTop: 2 } }, 'Matter ' + c.matter),
+          c.description && h('p', { style: { fontSize: 13, marginTop: 8, whiteSpace: 'pre-wrap' } }, c.description),
+          h('div', { style: { fontSize: 12, color: 'var(--text-muted)', marginTop: 8 } }, 'Custodians: ', c.custodians.map(nameOfUser).join(', '))
+        ),
+        h('div', { style: { display: 'flex', gap: 8 } },
+          h('button', { className: 'btn btn-primary btn-sm', disabled: busy, title: 'ZIP of the case record, notes, holds, transcripts and saved search results, with a SHA-256 manifest', onClick: () => { setBusy(true); downloadExport('/engine' + base + '/export').catch(err => toast(err.message, 'error')).finally(() => setBusy(false)); } }, I.download(), ' Export Package'),
+          !closed && h('button', { className: 'btn btn-secondary btn-sm', onClick: () => setEditing(true) }, 'Edit'),
+          h('button', { className: 'btn btn-secondary btn-sm', disabled: busy, onClick: toggleStatus }, closed ? 'Reopen' : 'Close Case')
+        )
+      )
+    ),
+    h('div', { className: 'tabs', style: { marginBottom: 12 } },
+      TABS.map(t => h('button', { key: t[0], className: 'tab' + (tab === t[0] ? ' active' : ''), onClick: () => setTab(t[0]) }, t[1], counts[t[0]] ? ' (' + counts[t[0]] + ')' : ''))
+    ),
+    tabBody[tab],
+    editing && h(CaseForm, {
+      initial: c, users: props.users, onClose: () => setEditing(false),
+      onSave: (form) => run(() => engineCall(base, { method: 'PATCH', body: JSON.stringify(form) }).then(() => setEditing(false)), 'Case updated'),
+    }),
+    viewing && h(TranscriptModal, { caseId: c.id, itemId: viewing, onClose: () => setViewing(null), nameOf: nameOfAgent, toast })
+  );
+}
+
+export function EDiscoveryPage() {
+  var orgCtx = useOrgContext();
+  var effectiveOrgId = orgCtx.selectedOrgId || getOrgId();
+  const { toast, user } = useApp();
+  const [cases, setCases] = useState(null);
+  const [users, setUsers] = useState([]);
+  const [agents, setAgents] = useState([]);
+  const [creating, setCreating] = useState(false);
+  const [openId, setOpenId] = useState(null);
+  const [showClosed, setShowClosed] = useState(false);
+
+  var load = () => engineCall('/ediscovery/cases?orgId=' + encodeURIComponent(effectiveOrgId))
+    .then(d => setCases(d.cases || []))
+    .catch(err => { toast('Failed to load cases: ' + err.message, 'error'); setCases([]); });
+
+  useEffect(() => { load(); }, [effectiveOrgId]);
+  useEffect(() => {
+    // Listing users needs admin; custodians who aren't see IDs instead of names
+    apiCall('/users?limit=200').then(d => setUsers(d.users || d || [])).catch(() => {});
+    apiCall('/agents' + (orgCtx.selectedOrgId ? '?clientOrgId=' + orgCtx.selectedOrgId : '')).then(d => setAgents(d.agents || [])).catch(() => {});
+  }, [orgCtx.selectedOrgId]);
+
+  var agentData = buildAgentDataMap(agents);
+  var rows = (cases || []).filter(c => showClosed || c.status === 'open');
+  var _h4 = { marginTop: 16, marginBottom: 8, fontSize: 14 };
+
+  var create = async (form) => {
+    try {
+      var res = await engineCall('/ediscovery/cases', { method: 'POST', body: JSON.stringify(Object.assign({ orgId: effectiveOrgId }, form)) });
+      toast('Case created', 'success');
+      setCreating(false);
+      load();
+      setOpenId(res.case.id);
+    } catch (err) { toast(err.message, 'error'); }
+  };
+
+  return h('div', { className: 'page-inner' },
+    h(orgCtx.Switcher),
+    h('div', { className: 'page-header' },
+      h('h1', { style: { display: 'flex', alignItems: 'center' } }, 'e-Discovery', h(HelpButton, { label: 'e-Discovery' },
+        h('p', null, 'A case gathers everything for one legal matter: the searches that define its scope, the legal holds that preserve the data, transcripts of the matching messages, and reviewer notes.'),
+        h('h4', { style: _h4 }, 'Custodians'),
+        h('p', null, 'Only a case\'s custodians can see it. Owners can open any case so a matter is never orphaned. Every view, change and export is written to the audit log.'),
+        h('h4', { style: _h4 }, 'Transcripts'),
+        h('p', null, 'Exporting a transcript freezes the messages a saved search matches at that moment. Later edits or deletions do not change it. Notes and transcripts cannot be removed from a case.'),
+        h('h4', { style: _h4 }, 'Export package'),
+        h('p', null, 'A ZIP with the case record, notes, holds, every transcript, and a fresh run of every saved search. manifest.json lists a SHA-256 for each file so recipients can verify nothing changed.')
+      )),
+      !openId && h('div', { style: { display: 'flex', gap: 8, alignItems: 'center' } },
+        h('label', { style: { display: 'flex', alignItems: 'center', gap: 6, fontSize: 13 } },
+          h('input', { type: 'checkbox', checked: showClosed, onChange: e => setShowClosed(e.target.checked) }), 'Show closed'),
+        h('button', { className: 'btn btn-primary', onClick: () => setCreating(true) }, I.plus(), ' New Case')
+      )
+    ),
+
+    openId
+      ? h(CaseDetail, { caseId: openId, users, agents, agentData, onBack: () => { setOpenId(null); load(); } })
+      : h('div', { className: 'card' }, h(Table, {
+          className: 'data-table', rows,
+          onRowClick: c => setOpenId(c.id),
+          empty: cases === null ? 'Loading...' : 'No cases you are a custodian of',
+          columns: [
+            { key: 'name', label: 'Case', render: c => h('div', null, h('div', { style: { fontWeight: 500 } }, c.name), c.matter && h('div', { style: { fontSize: 12, color: 'var(--text-muted)' } }, c.matter)) },
+            { key: 'status', label: 'Status', render: c => h('span', { className: 'badge ' + (c.status === 'closed' ? 'badge-neutral' : 'badge-success') }, c.status === 'closed' ? 'Closed' : 'Open') },
+            { key: 'contents', label: 'Contents', render: c => h('span', { style: { fontSize: 12, color: 'var(--text-muted)' } },
+              [['search', 'searches'], ['hold', 'holds'], ['transcript', 'transcripts'], ['note', 'notes']].map(k => (c.counts[k[0]] || 0) + ' ' + k[1]).join(' · ')) },
+            { key: 'custodians', label: 'Custodians', render: c => c.custodians.length },
+            { key: 'updatedAt', label: 'Updated', render: c => h(RelativeTime, { value: c.updatedAt }) },
+          ],
+        })),
+
+    creating && h(CaseForm, { users, userId: user && user.id, onClose: () => setCreating(false), onSave: create })
+  );
+}
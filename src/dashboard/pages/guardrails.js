@@ -1,4 +1,4 @@
-import { h, useState, useEffect, useCallback, Fragment, useApp, engineCall, buildAgentEmailMap, resolveAgentEmail, buildAgentDataMap, renderAgentBadge, getOrgId , apiCall } from '../components/utils.js';
+import { h, useState, useEffect, useCallback, Fragment, useApp, engineCall, buildAgentEmailMap, resolveAgentEmail, buildAgentDataMap, renderAgentBadge, getOrgId , apiCall, showConfirm } from '../components/utils.js';
 import { I } from '../components/icons.js';
 import { HelpButton } from '../components/help-button.js';
 import { useOrgContext } from '../components/org-switcher.js';
@@ -153,6 +153,7 @@ function OverviewTab(props) {
   var orgCtx = useOrgContext();
   var effectiveOrgId = orgCtx.selectedOrgId || getOrgId();
   var toast = app.toast;
+  var canControl = app.can('agents.control');
   var _int = useState([]);
   var interventions = _int[0]; var setInterventions = _int[1];
   var _stat = useState(null);
@@ -200,9 +201,17 @@ function OverviewTab(props) {
       .catch(function(e) { toast(e.message, 'error'); });
   };
   var killAgent = function(id) {
-    engineCall('/guardrails/kill/' + (id || agentIdInput), { method: 'POST', body: JSON.stringify({ reason: 'Emergency kill from dashboard' }) })
-      .then(function() { toast('Agent killed', 'warning'); load(); })
-      .catch(function(e) { toast(e.message, 'error'); });
+    var agent = agents.find(function(a) { return a.id === id; });
+    var name = (agent && ((agent.config && (agent.config.displayName || agent.config.name)) || agent.name)) || id;
+    showConfirm({
+      title: 'Kill Agent', danger: true, confirmText: 'Kill Agent', requireText: 'KILL',
+      message: 'Immediately terminate every running process for ' + name + '? In-flight work is lost and the agent stays stopped until resumed.',
+    }).then(function(ok) {
+      if (!ok) return;
+      engineCall('/guardrails/kill/' + id, { method: 'POST', body: JSON.stringify({ reason: 'Emergency kill from dashboard' }) })
+        .then(function() { toast('Agent killed', 'warning'); load(); })
+        .catch(function(e) { toast(e.message, 'error'); });
+    });
   };
 
   var typeColor = function(t) { return t === 'kill' ? '#ef4444' : t === 'pause' ? '#991b1b' : t === 'resume' ? '#15803d' : '#0ea5e9'; };
@@ -216,9 +225,9 @@ function OverviewTab(props) {
           h('option', { value: '' }, '-- Select Agent --'),
           agents.map(function(a) { var name = (a.config && a.config.displayName) || (a.config && a.config.name) || a.name || 'Agent'; var email = a.config && a.config.email && a.config.email.address; return h('option', { key: a.id, value: a.id }, name + (email ? ' (' + email + ')' : '')); })
         ),
-        h('button', { className: 'btn btn-warning', onClick: pauseAgent }, I.pause(), ' Pause'),
-        h('button', { className: 'btn btn-primary', onClick: function() { if (agentIdInput) resumeAgent(agentIdInput); } }, I.play(), ' Resume'),
-        h('button', { className: 'btn btn-danger', onClick: function() { if (agentIdInput) killAgent(agentIdInput); } }, I.stop(), ' Kill')
+        h('button', { className: 'btn btn-warning', onClick: pauseAgent, disabled: !canControl }, I.pause(), ' Pause'),
+        h('button', { className: 'btn btn-primary', onClick: function() { if (agentIdInput) resumeAgent(agentIdInput); }, disabled: !canControl }, I.play(), ' Resume'),
+        h('button', { className: 'btn btn-danger', onClick: function() { if (agentIdInput) killAgent(agentIdInput); }, disabled: !canControl }, I.stop(), ' Kill')
       )
     ),
     // Stat cards
@@ -267,7 +276,7 @@ function OverviewTab(props) {
                 h('td', null, h(Badge, { color: typeColor(r.type) }, r.type)),
                 h('td', { style: { maxWidth: 250, overflow: 'hidden', textOverflow: 'ellipsis', whiteSpace: 'nowrap' } }, r.reason || '-'),
                 h('td', null, r.triggeredBy || '-'),
-                h('td', null, r.type === 'pause' && h('button', { className: 'btn btn-ghost btn-sm', onClick: function() { resumeAgent(r.agentId); } }, 'Resume'))
+                h('td', null, r.type === 'pause' && canControl && h('button', { className: 'btn btn-ghost btn-sm', onClick: function() { resumeAgent(r.agentId); } }, 'Resume'))
               );
             }))
           )
@@ -763,6 +772,7 @@ function RulesTab(props) {
   var agentData = buildAgentDataMap(agents);
   var app = useApp();
   var toast = app.toast;
+  var canManage = app.can('guardrails.manage');
   var _rules = useState([]);
   var rules = _rules[0]; var setRules = _rules[1];
   var _anomaly = useState([]);
@@ -857,7 +867,7 @@ function RulesTab(props) {
     // ── Guardrail Rules sub-tab ──
     subTab === 'rules' && h(Fragment, null,
       h('div', { style: { marginBottom: 12 } },
-        h('button', { className: 'btn btn-primary', onClick: openCreateRule }, I.plus(), ' Create Rule')
+        canManage && h('button', { className: 'btn btn-primary', onClick: openCreateRule }, I.plus(), ' Create Rule')
       ),
       rules.length === 0
         ? h(EmptyState, { message: 'No guardrail rules configured' })
@@ -876,8 +886,8 @@ function RulesTab(props) {
                   h('td', { style: { textAlign: 'center' } }, r.triggerCount || 0),
                   h('td', null, r.enabled !== false ? h('span', { style: { color: '#15803d' } }, 'Yes') : h('span', { style: { color: '#ef4444' } }, 'No')),
                   h('td', { style: { whiteSpace: 'nowrap' } },
-                    h('button', { className: 'btn btn-ghost btn-sm', onClick: function() { openEditRule(r); } }, I.settings()),
-                    h('button', { className: 'btn btn-ghost btn-sm', onClick: function() { deleteRule(r.id); } }, I.trash())
+                    canManage && h('button', { className: 'btn btn-ghost btn-sm', onClick: function() { openEditRule(r); } }, I.settings()),
+                    canManage && h('button', { className: 'btn btn-ghost btn-sm', onClick: function() { deleteRule(r.id); } }, I.trash())
                   )
                 );
               }))
@@ -888,7 +898,7 @@ function RulesTab(props) {
     // ── Anomaly Rules sub-tab ──
     subTab === 'anomaly' && h(Fragment, null,
       h('div', { style: { marginBottom: 12 } },
-        h('button', { className: 'btn btn-primary', onClick: function() { setShowAnomalyModal(true); } }, I.plus(), ' Add Anomaly Rule')
+        canManage && h('button', { className: 'btn btn-primary', onClick: function() { setShowAnomalyModal(true); } }, I.plus(), ' Add Anomaly Rule')
       ),
       anomalyRules.length === 0
         ? h(EmptyState, { message: 'No anomaly rules configured' })
@@ -901,7 +911,7 @@ function RulesTab(props) {
                   h('td', null, h('span', { className: 'badge-tag' }, r.ruleType)),
                   h('td', null, h(Badge, { color: r.action === 'kill' ? '#ef4444' : r.action === 'pause' ? '#991b1b' : '#0ea5e9' }, r.action)),
                   h('td', null, r.enabled ? 'Yes' : 'No'),
-                  h('td', null, h('button', { className: 'btn btn-ghost btn-sm', onClick: function() { deleteAnomalyRule(r.id); } }, I.trash()))
+                  h('td', null, canManage && h('button', { className: 'btn btn-ghost btn-sm', onClick: function() { deleteAnomalyRule(r.id); } }, I.trash()))
                 );
               }))
             )
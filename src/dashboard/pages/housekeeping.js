@@ -0,0 +1,178 @@
+import { h, useState, useEffect, Fragment, useApp, engineCall, getOrgId, showConfirm } from '../components/utils.js';
+import { I } from '../components/icons.js';
+import { HelpButton } from '../components/help-button.js';
+import { useOrgContext } from '../components/org-switcher.js';
+import { Table } from '../components/table.js';
+import { RelativeTime } from '../components/time.js';
+
+const STALE_OPTIONS = [
+  { value: 7, label: '7 days' },
+  { value: 30, label: '30 days' },
+  { value: 90, label: '90 days' },
+  { value: 365, label: 'a year' },
+];
+
+// What each report section's cleanup does, for the buttons and confirm dialogs
+const KINDS = {
+  'duplicate-agents': { title: 'Duplicate Agents', verb: 'Archive', noun: 'agent', detail: 'Archived agents stop appearing in lists and can be restored from the Agents page.' },
+  'orphaned-api-keys': { title: 'Orphaned API Keys', verb: 'Revoke', noun: 'API key', detail: 'Requests using a revoked key are rejected immediately.', warning: 'Revoking cannot be undone.' },
+  'unused-skills': { title: 'Unused Skills', verb: 'Uninstall', noun: 'skill', detail: 'The skill can be installed again from Community Skills.' },
+  'unreferenced-secrets': { title: 'Unreferenced Secrets', verb: 'Delete', noun: 'secret', detail: 'The encrypted value is removed from the vault.', warning: 'Deleted secrets cannot be recovered.' },
+};
+
+export function HousekeepingPage() {
+  var orgCtx = useOrgContext();
+  var effectiveOrgId = orgCtx.selectedOrgId || getOrgId();
+  const { toast } = useApp();
+  const [report, setReport] = useState(null);
+  const [loading, setLoading] = useState(true);
+  const [staleDays, setStaleDays] = useState(30);
+  const [busy, setBusy] = useState(false);
+
+  const load = () => {
+    setLoading(true);
+    engineCall('/housekeeping?orgId=' + encodeURIComponent(effectiveOrgId) + '&staleDays=' + staleDays)
+      .then(setReport)
+      .catch(err => toast('Failed to load housekeeping report: ' + err.message, 'error'))
+      .finally(() => setLoading(false));
+  };
+  useEffect(() => { load(); }, [effectiveOrgId, staleDays]);
+
+  const cleanup = async (kind, ids, label) => {
+    var k = KINDS[kind];
+    var n = ids.length;
+    var ok = await showConfirm({
+      title: k.verb + ' ' + (label || n + ' ' + k.noun + (n === 1 ? '' : 's')) + '?',
+      message: k.detail,
+      warning: k.warning,
+      danger: kind !== 'duplicate-agents',
+      confirmText: k.verb,
+    });
+    if (!ok) return;
+    setBusy(true);
+    try {
+      var res = await engineCall('/housekeeping/cleanup', { method: 'POST', body: JSON.stringify({ orgId: effectiveOrgId, kind, ids, staleDays }) });
+      var msg = k.verb.replace(/e$/, '') + 'ed ' + res.done.length + ' ' + k.noun + (res.done.length === 1 ? '' : 's');
+      if (res.skipped.length) msg += ' · ' + res.skipped.length + ' skipped (' + [...new Set(res.skipped.map(s => s.reason))].join(', ') + ')';
+      toast(msg, res.skipped.length ? 'warning' : 'success');
+      load();
+    } catch (err) { toast(err.message, 'error'); }
+    setBusy(false);
+  };
+
+  var r = report || { duplicateAgents: [], orphanedApiKeys: [], unusedSkills: [], unreferencedSecrets: [] };
+  var duplicateIds = r.duplicateAgents.flatMap(g => g.agents.filter(a => a.id !== g.keepId).map(a => a.id))
+    .filter(id => !r.duplicateAgents.some(g => g.keepId === id));
+  var counts = {
+    'duplicate-agents': duplicateIds.length,
+    'orphaned-api-keys': r.orphanedApiKeys.length,
+    'unused-skills': r.unusedSkills.length,
+    'unreferenced-secrets': r.unreferencedSecrets.length,
+  };
+
+  var actionButton = (kind, ids, label) => h('button', { className: 'btn btn-ghost btn-sm', disabled: busy, onClick: (e) => { e.stopPropagation(); cleanup(kind, ids, label); } },
+    kind === 'duplicate-agents' ? null : I.trash(), ' ', KINDS[kind].verb);
+
+  var section = (kind, help, body) => h('div', { className: 'card', style: { marginBottom: 16 } },
+    h('div', { className: 'card-header', style: { display: 'flex', justifyContent: 'space-between', alignItems: 'center' } },
+      h('h3', { style: { display: 'flex', alignItems: 'center', gap: 8 } }, KINDS[kind].title,
+        h('span', { className: 'badge badge-' + (counts[kind] ? 'warning' : 'success') }, counts[kind]),
+        h('span', { style: { fontSize: 12, fontWeight: 400, color: 'var(--text-muted)' } }, help)),
+      counts[kind] > 0 && h('button', { className: 'btn btn-secondary btn-sm', disabled: busy, onClick: () => cleanup(kind, kind === 'duplicate-agents' ? duplicateIds : r[{ 'orphaned-api-keys': 'orphanedApiKeys', 'unused-skills': 'unusedSkills', 'unreferenced-secrets': 'unreferencedSecrets' }[kind]].map(x => x.id || x.skillId)) },
+        KINDS[kind].verb + ' all')
+    ),
+    body
+  );
+
+  var _muted = { fontSize: 11, color: 'var(--text-muted)' };
+  var _h4 = { marginTop: 16, marginBottom: 8, fontSize: 14 };
+
+  return h('div', { className: 'page-inner' },
+    h(orgCtx.Switcher),
+    h('div', { className: 'page-header' },
+      h('h1', { style: { display: 'flex', alignItems: 'center' } }, 'Housekeeping', h(HelpButton, { label: 'Housekeeping' },
+        h('p', null, 'Things that pile up and are easy to forget, each with a one-click cleanup.'),
+        h('h4', { style: _h4 }, 'What is flagged'),
+        h('ul', { style: { paddingLeft: 20 } },
+          h('li', null, h('strong', null, 'Duplicate agents'), ' share an email address, or the same name and role. The oldest in each group is kept; the others can be archived.'),
+          h('li', null, h('strong', null, 'Orphaned API keys'), ' are still active but the user who created them has been removed.'),
+          h('li', null, h('strong', null, 'Unused skills'), ' are installed but no agent in the organization is allowed to use them.'),
+          h('li', null, h('strong', null, 'Unreferenced secrets'), ' have never been read from the vault since they were stored, and are older than the grace period.')
+        ),
+        h('p', null, 'Cleanup only touches items the report still flags when you click, and every cleanup is recorded in the audit log.')
+      )),
+      h('div', { style: { display: 'flex', gap: 8, alignItems: 'center' } },
+        h('label', { style: { fontSize: 13, color: 'var(--text-muted)' } }, 'Secrets unread for'),
+        h('select', { className: 'input', style: { width: 'auto' }, value: staleDays, onChange: e => setStaleDays(Number(e.target.value)) },
+          STALE_OPTIONS.map(o => h('option', { key: o.value, value: o.value }, o.label))
+        ),
+        h('button', { className: 'btn btn-secondary', onClick: load, disabled: loading }, I.refresh(), ' Refresh')
+      )
+    ),
+
+    h('div', { className: 'stat-grid', style: { marginBottom: 16 } },
+      Object.keys(KINDS).map(kind => h('div', { key: kind, className: 'stat-card' },
+        h('div', { className: 'stat-value', style: { color: counts[kind] ? 'var(--warning)' : undefined } }, report ? counts[kind] : '...'),
+        h('div', { className: 'stat-label' }, KINDS[kind].title)))
+    ),
+
+    section('duplicate-agents', 'same email, or same name and role',
+      r.duplicateAgents.length === 0
+        ? h('div', { className: 'card-body', style: { color: 'var(--text-muted)', fontSize: 13 } }, loading ? 'Loading...' : 'No duplicate agents')
+        : h('div', { className: 'card-body' }, r.duplicateAgents.map(g => h('div', { key: g.reason + g.key, style: { marginBottom: 12 } },
+            h('div', { style: { fontSize: 12, fontWeight: 600, marginBottom: 4 } }, g.reason === 'email' ? 'Email: ' : 'Name / role: ', h('span', { style: { fontFamily: 'var(--font-mono, monospace)' } }, g.key)),
+            g.agents.map(a => h('div', { key: a.id, style: { display: 'flex', alignItems: 'center', gap: 8, padding: '4px 0', borderTop: '1px solid var(--border)', fontSize: 13 } },
+              h('span', { style: { flex: 1 } }, h('strong', null, a.name), ' ', h('span', { style: _muted }, a.email + ' · ' + a.role + ' · ' + a.status)),
+              h('span', { style: _muted }, 'created ', h(RelativeTime, { value: a.createdAt })),
+              a.id === g.keepId || r.duplicateAgents.some(x => x.keepId === a.id)
+                ? h('span', { className: 'badge badge-success' }, 'keep')
+                : actionButton('duplicate-agents', [a.id], a.name)
+            ))
+          )))
+    ),
+
+    section('orphaned-api-keys', 'creator no longer exists',
+      h(Table, {
+        className: 'data-table',
+        columns: [
+          { key: 'name', label: 'Key', render: k => h('div', null, h('div', { style: { fontWeight: 500 } }, k.name), h('div', { style: Object.assign({ fontFamily: 'var(--font-mono, monospace)' }, _muted) }, k.keyPrefix + '…')) },
+          { key: 'createdBy', label: 'Created by', render: k => h('span', { style: { fontFamily: 'var(--font-mono, monospace)', fontSize: 12 } }, k.createdBy) },
+          { key: 'createdAt', label: 'Created', render: k => h(RelativeTime, { value: k.createdAt }) },
+          { key: 'lastUsedAt', label: 'Last used', render: k => k.lastUsedAt ? h(RelativeTime, { value: k.lastUsedAt }) : h('span', { style: _muted }, 'never') },
+          { key: 'action', label: '', render: k => actionButton('orphaned-api-keys', [k.id], k.name) },
+        ],
+        rows: r.orphanedApiKeys,
+        empty: loading ? 'Loading...' : 'No orphaned API keys',
+      })
+    ),
+
+    section('unused-skills', 'no agent can use them',
+      h(Table, {
+        className: 'data-table',
+        columns: [
+          { key: 'name', label: 'Skill', render: s => h('div', null, h('div', { style: { fontWeight: 500 } }, s.name), h('div', { style: _muted }, s.skillId + ' · v' + s.version)) },
+          { key: 'enabled', label: 'Status', render: s => h('span', { className: 'badge badge-' + (s.enabled ? 'info' : 'neutral') }, s.enabled ? 'enabled' : 'disabled') },
+          { key: 'installedAt', label: 'Installed', render: s => h(Fragment, null, h(RelativeTime, { value: s.installedAt }), h('div', { style: _muted }, 'by ' + s.installedBy)) },
+          { key: 'action', label: '', render: s => actionButton('unused-skills', [s.skillId], s.name) },
+        ],
+        rows: r.unusedSkills.map(s => Object.assign({ id: s.skillId }, s)),
+        empty: loading ? 'Loading...' : 'Every installed skill is used by at least one agent',
+      })
+    ),
+
+    section('unreferenced-secrets', 'never read, older than ' + (STALE_OPTIONS.find(o => o.value === staleDays) || { label: staleDays + ' days' }).label,
+      h(Table, {
+        className: 'data-table',
+        columns: [
+          { key: 'name', label: 'Secret', render: s => h('span', { style: { fontFamily: 'var(--font-mono, monospace)', fontSize: 12 } }, s.name) },
+          { key: 'category', label: 'Category', render: s => h('span', { className: 'badge badge-neutral' }, s.category) },
+          { key: 'createdBy', label: 'Stored by', render: s => s.createdBy || '-' },
+          { key: 'createdAt', label: 'Stored', render: s => h(RelativeTime, { value: s.createdAt }) },
+          { key: 'action', label: '', render: s => actionButton('unreferenced-secrets', [s.id], s.name) },
+        ],
+        rows: r.unreferencedSecrets,
+        empty: loading ? 'Loading...' : 'No unreferenced secrets',
+      })
+    )
+  );
+}
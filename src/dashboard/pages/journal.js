@@ -1,9 +1,12 @@
-import { h, useState, useEffect, Fragment, useApp, engineCall, buildAgentEmailMap, buildAgentDataMap, resolveAgentEmail, renderAgentBadge, getOrgId , apiCall } from '../components/utils.js';
+import { h, useState, useEffect, Fragment, useApp, engineCall, buildAgentEmailMap, buildAgentDataMap, resolveAgentEmail, renderAgentBadge, getOrgId , apiCall, showConfirm } from '../components/utils.js';
 import { I } from '../components/icons.js';
 import { E } from '../assets/icons/emoji-icons.js';
 import { HelpButton } from '../components/help-button.js';
 import { useOrgContext } from '../components/org-switcher.js';
 import { KnowledgeLink } from '../components/knowledge-link.js';
+import { Pagination, usePageSize } from '../components/pagination.js';
+import { RelativeTime, formatTime } from '../components/time.js';
+import { Sparkline } from '../components/charts.js';
 
 export function JournalPage() {
   var orgCtx = useOrgContext();
@@ -15,13 +18,13 @@ export function JournalPage() {
   const [agents, setAgents] = useState([]);
 
   // Pagination, search, filter
-  const [page, setPage] = useState(0);
+  const [page, setPage] = useState(1);
   const [searchQ, setSearchQ] = useState('');
   const [filterAgent, setFilterAgent] = useState('');
   const [filterType, setFilterType] = useState('');
   const [filterStatus, setFilterStatus] = useState('');
   const [selectedEntry, setSelectedEntry] = useState(null);
-  const PAGE_SIZE = 20;
+  const [pageSize, setPageSize] = usePageSize('journal', 25);
 
   const load = () => {
     engineCall('/journal?orgId=' + effectiveOrgId + '&limit=500').then(d => { setEntries(d.entries || []); setTotal(d.total || 0); }).catch(() => {});
@@ -34,6 +37,13 @@ export function JournalPage() {
   const agentData = buildAgentDataMap(agents);
 
   const rollback = async (id) => {
+    var entry = entries.find(e => e.id === id);
+    var ok = await showConfirm({
+      title: 'Rollback Action', danger: true, confirmText: 'Rollback',
+      message: 'Reverse ' + (entry && entry.actionType ? '"' + entry.actionType + '"' : 'this action') + '?',
+      warning: 'The original action is undone against the live system. This can\'t be rolled forward again.',
+    });
+    if (!ok) return;
     try { const r = await engineCall('/journal/' + id + '/rollback', { method: 'POST', body: JSON.stringify({}) }); if (r.success) { toast('Action rolled back', 'success'); load(); } else toast('Rollback failed: ' + (r.error || 'Unknown'), 'error'); } catch (e) { toast(e.message, 'error'); }
   };
 
@@ -55,38 +65,38 @@ export function JournalPage() {
     ))),
     stats && h('div', { className: 'stat-grid', style: { marginBottom: 16 } },
       h('div', { className: 'stat-card' }, h('div', { className: 'stat-value' }, stats.total), h('div', { className: 'stat-label', style: { display: 'flex', alignItems: 'center' } }, 'Total Actions', h(HelpButton, { label: 'Total Actions' },
-        h('p', null, 'The total number of tool calls and side effects recorded across all agents.')
-      ))),
+        h('p', null, 'The total number of tool calls and side effects recorded across all agents. The line shows actions per day over the last 14 days.')
+      )), stats.daily && h(Sparkline, { values: stats.daily.map(d => d.actions), width: 120, height: 24, fill: true, title: 'Actions per day, last 14 days', style: { marginTop: 6 } })),
       h('div', { className: 'stat-card' }, h('div', { className: 'stat-value' }, stats.reversible), h('div', { className: 'stat-label', style: { display: 'flex', alignItems: 'center' } }, 'Reversible', h(HelpButton, { label: 'Reversible' },
         h('p', null, 'Actions that can be undone (rolled back). Not all actions are reversible — for example, sent emails cannot be unsent.')
       ))),
       h('div', { className: 'stat-card' }, h('div', { className: 'stat-value' }, stats.reversed), h('div', { className: 'stat-label', style: { display: 'flex', alignItems: 'center' } }, 'Rolled Back', h(HelpButton, { label: 'Rolled Back' },
-        h('p', null, 'Actions that have been reversed by an admin. A high number may indicate agents need tighter permissions or better instructions.')
-      )))
+        h('p', null, 'Actions that have been reversed by an admin. A high number may indicate agents need tighter permissions or better instructions. The line shows rollbacks per day, by the day the action was taken.')
+      )), stats.daily && h(Sparkline, { values: stats.daily.map(d => d.reversed), width: 120, height: 24, color: 'var(--warning)', fill: true, title: 'Rolled back per day, last 14 days', style: { marginTop: 6 } }))
     ),
     // Filter bar
     h('div', { style: { display: 'flex', gap: 10, marginBottom: 14, flexWrap: 'wrap', alignItems: 'center' } },
       h('input', {
         type: 'text', placeholder: 'Search tool name...',
-        value: searchQ, onInput: e => { setSearchQ(e.target.value); setPage(0); },
+        value: searchQ, onInput: e => { setSearchQ(e.target.value); setPage(1); },
         style: { flex: '1 1 200px', padding: '8px 12px', borderRadius: 8, border: '1px solid var(--border)', background: 'var(--bg-card)', color: 'var(--text)', fontSize: 13, minWidth: 180, outline: 'none' }
       }),
       h('select', {
-        value: filterAgent, onChange: e => { setFilterAgent(e.target.value); setPage(0); },
+        value: filterAgent, onChange: e => { setFilterAgent(e.target.value); setPage(1); },
         style: { padding: '8px 12px', borderRadius: 8, border: '1px solid var(--border)', background: 'var(--bg-card)', color: 'var(--text)', fontSize: 13, cursor: 'pointer', outline: 'none' }
       },
         h('option', { value: '' }, 'All Agents'),
         agents.map(a => h('option', { key: a.id, value: a.id }, a.config && a.config.identity && a.config.identity.name || a.config && a.config.displayName || a.name || a.id))
       ),
       h('select', {
-        value: filterType, onChange: e => { setFilterType(e.target.value); setPage(0); },
+        value: filterType, onChange: e => { setFilterType(e.target.value); setPage(1); },
         style: { padding: '8px 12px', borderRadius: 8, border: '1px solid var(--border)', background: 'var(--bg-card)', color: 'var(--text)', fontSize: 13, cursor: 'pointer', outline: 'none' }
       },
         h('option', { value: '' }, 'All Types'),
         [...new Set(entries.map(e => e.actionType).filter(Boolean))].sort().map(t => h('option', { key: t, value: t }, t))
       ),
       h('select', {
-        value: filterStatus, onChange: e => { setFilterStatus(e.target.value); setPage(0); },
+        value: filterStatus, onChange: e => { setFilterStatus(e.target.value); setPage(1); },
         style: { padding: '8px 12px', borderRadius: 8, border: '1px solid var(--border)', background: 'var(--bg-card)', color: 'var(--text)', fontSize: 13, cursor: 'pointer', outline: 'none' }
       },
         h('option', { value: '' }, 'All Statuses'),
@@ -103,8 +113,7 @@ export function JournalPage() {
       if (filterStatus === 'active') filtered = filtered.filter(e => !e.reversed);
       if (filterStatus === 'rolled_back') filtered = filtered.filter(e => e.reversed);
       var totalFiltered = filtered.length;
-      var totalPages = Math.ceil(totalFiltered / PAGE_SIZE);
-      var paged = filtered.slice(page * PAGE_SIZE, (page + 1) * PAGE_SIZE);
+      var paged = filtered.slice((page - 1) * pageSize, page * pageSize);
 
       return h(Fragment, null,
         h('div', { style: { fontSize: 13, color: 'var(--text-muted)', marginBottom: 8 } }, totalFiltered + ' entr' + (totalFiltered !== 1 ? 'ies' : 'y')),
@@ -114,7 +123,7 @@ export function JournalPage() {
             h('tbody', null, paged.length === 0
               ? h('tr', null, h('td', { colSpan: 7, style: { textAlign: 'center', color: 'var(--text-muted)', padding: 40 } }, searchQ || filterAgent || filterType || filterStatus ? 'No matching entries' : 'No journal entries'))
               : paged.map(e => h('tr', { key: e.id, onClick: () => setSelectedEntry(e), style: { cursor: 'pointer' } },
-                h('td', null, new Date(e.createdAt).toLocaleString()),
+                h('td', null, h(RelativeTime, { value: e.createdAt })),
                 h('td', null, renderAgentBadge(e.agentId, agentData)),
                 h('td', null, e.toolName || e.toolId),
                 h('td', null, h('span', { className: 'badge-tag' }, e.actionType)),
@@ -134,7 +143,7 @@ export function JournalPage() {
             h('div', { className: 'modal-body' },
               h('div', { style: { display: 'grid', gridTemplateColumns: '1fr 1fr', gap: '12px 24px', fontSize: 13, marginBottom: 16 } },
                 h('div', null, h('div', { style: { color: 'var(--text-muted)', fontSize: 11, marginBottom: 2 } }, 'ID'), h('code', { style: { fontSize: 12 } }, selectedEntry.id)),
-                h('div', null, h('div', { style: { color: 'var(--text-muted)', fontSize: 11, marginBottom: 2 } }, 'Time'), selectedEntry.createdAt ? new Date(selectedEntry.createdAt).toLocaleString() : '-'),
+                h('div', null, h('div', { style: { color: 'var(--text-muted)', fontSize: 11, marginBottom: 2 } }, 'Time'), formatTime(selectedEntry.createdAt, 'full')),
                 h('div', null, h('div', { style: { color: 'var(--text-muted)', fontSize: 11, marginBottom: 2 } }, 'Agent'), renderAgentBadge(selectedEntry.agentId, agentData)),
                 h('div', null, h('div', { style: { color: 'var(--text-muted)', fontSize: 11, marginBottom: 2 } }, 'Tool'), selectedEntry.toolName || selectedEntry.toolId || '-'),
                 h('div', null, h('div', { style: { color: 'var(--text-muted)', fontSize: 11, marginBottom: 2 } }, 'Action Type'), h('span', { className: 'badge-tag' }, selectedEntry.actionType || '-')),
@@ -143,7 +152,7 @@ export function JournalPage() {
                 h('div', null, h('div', { style: { color: 'var(--text-muted)', fontSize: 11, marginBottom: 2 } }, 'Session'), h('code', { style: { fontSize: 12 } }, selectedEntry.sessionId || '-'))
               ),
               selectedEntry.reversed && h('div', { style: { marginBottom: 16, padding: 10, background: 'rgba(234,179,8,0.1)', borderRadius: 8, fontSize: 13 } },
-                h('strong', null, 'Rolled back'), ' at ', selectedEntry.reversedAt ? new Date(selectedEntry.reversedAt).toLocaleString() : 'unknown', ' by ', selectedEntry.reversedBy || 'unknown'
+                h('strong', null, 'Rolled back'), ' at ', selectedEntry.reversedAt ? formatTime(selectedEntry.reversedAt, 'full') : 'unknown', ' by ', selectedEntry.reversedBy || 'unknown'
               ),
               selectedEntry.forwardData && Object.keys(selectedEntry.forwardData).length > 0 && h('div', { style: { marginBottom: 12 } },
                 h('div', { style: { color: 'var(--text-muted)', fontSize: 11, marginBottom: 4 } }, 'Parameters'),
@@ -156,16 +165,10 @@ export function JournalPage() {
             )
           )
         ),
-        totalPages > 1 && h('div', {
-          style: { display: 'flex', justifyContent: 'space-between', alignItems: 'center', padding: '12px 0', fontSize: 13, color: 'var(--text-muted)' }
-        },
-          h('span', null, 'Showing ' + (page * PAGE_SIZE + 1) + '-' + Math.min((page + 1) * PAGE_SIZE, totalFiltered) + ' of ' + totalFiltered),
-          h('div', { style: { display: 'flex', gap: 4 } },
-            h('button', { onClick: () => setPage(p => Math.max(0, p - 1)), disabled: page === 0, className: 'btn btn-ghost btn-sm' }, '\u2039 Prev'),
-            h('span', { style: { padding: '4px 8px', fontSize: 12 } }, (page + 1) + ' / ' + totalPages),
-            h('button', { onClick: () => setPage(p => Math.min(totalPages - 1, p + 1)), disabled: page >= totalPages - 1, className: 'btn btn-ghost btn-sm' }, 'Next \u203A')
-          )
-        )
+        h(Pagination, {
+          page: page, pageSize: pageSize, total: totalFiltered, noun: 'entries', style: { borderTop: 'none', padding: '12px 0' },
+          onPage: setPage, onPageSize: (n) => { setPageSize(n); setPage(1); },
+        })
       );
     })()
   );
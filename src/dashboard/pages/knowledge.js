@@ -5,6 +5,7 @@ import { KnowledgeImportWizard, ImportJobsList } from './knowledge-import.js';
 import { HelpButton } from '../components/help-button.js';
 import { useOrgContext } from '../components/org-switcher.js';
 import { KnowledgeLink } from '../components/knowledge-link.js';
+import { ClassificationChip, ClassificationSelect, useClassifications } from '../components/classification.js';
 
 export function KnowledgeBasePage() {
   const { toast } = useApp();
@@ -13,6 +14,7 @@ export function KnowledgeBasePage() {
   const [form, setForm] = useState({ name: '', description: '', orgId: '' });
   const [clientOrgs, setClientOrgs] = useState([]);
   const orgCtx = useOrgContext();
+  const classifications = useClassifications('knowledge_base');
   const [selected, setSelected] = useState(null); // full KB detail
   const [docs, setDocs] = useState([]);
   const [chunks, setChunks] = useState([]);
@@ -165,7 +167,7 @@ export function KnowledgeBasePage() {
           h('button', { className: 'btn btn-secondary btn-sm', onClick: () => setSelected(null) }, '\u2190 Back'),
           editing
             ? h('input', { className: 'input', value: editForm.name, onChange: e => setEditForm(f => ({ ...f, name: e.target.value })), style: { fontSize: 18, fontWeight: 700, padding: '4px 8px' } })
-            : h('h1', { style: { fontSize: 20, fontWeight: 700, margin: 0, display: 'flex', alignItems: 'center' } }, selected.name, h(HelpButton, { label: 'Knowledge Base Detail' },
+            : h('h1', { style: { fontSize: 20, fontWeight: 700, margin: 0, display: 'flex', alignItems: 'center', gap: 8 } }, selected.name, h(ClassificationChip, { level: classifications.get(selected.id) }), h(HelpButton, { label: 'Knowledge Base Detail' },
                 h('p', null, 'This is the detail view for a single knowledge base. Here you can manage documents, view chunks, and import new content.'),
                 h('h4', { style: _h4 }, 'Key Actions'),
                 h('ul', { style: _ul },
@@ -210,6 +212,13 @@ export function KnowledgeBasePage() {
             selected.createdAt && h('span', null, 'Created: ', new Date(selected.createdAt).toLocaleDateString()),
             h('span', null, docs.length + ' document(s)'),
             selected.agentIds && h('span', null, (selected.agentIds.length || 0) + ' agent(s)')
+          ),
+          h('div', { style: { display: 'flex', alignItems: 'center', gap: 8, marginTop: 12 } },
+            h('label', { style: { fontSize: 12, fontWeight: 600, whiteSpace: 'nowrap' } }, 'Classification:'),
+            h(ClassificationSelect, { value: classifications.get(selected.id), style: { fontSize: 12 }, onChange: level => {
+              classifications.set(selected.id, level).then(() => toast('Classification updated', 'success')).catch(e => toast(e.message, 'error'));
+            } }),
+            h('span', { style: { fontSize: 11, color: 'var(--text-muted)' } }, 'Agents with access handle data at this level; DLP and egress policies can key off it.')
           )
         )
       ),
@@ -434,6 +443,7 @@ export function KnowledgeBasePage() {
               h('h3', { style: { fontSize: 15, fontWeight: 600, marginBottom: 4 } }, kb.name),
               h('p', { style: { fontSize: 12, color: 'var(--text-muted)', marginBottom: 12, minHeight: 32 } }, kb.description || 'No description'),
               h('div', { style: { display: 'flex', gap: 8, flexWrap: 'wrap' } },
+                h(ClassificationChip, { level: classifications.get(kb.id) }),
                 h('span', { className: 'badge badge-info' }, (kb.stats?.documentCount || kb.stats?.documents || kb.stats?.totalDocuments || kb.documents?.length || 0) + ' docs'),
                 h('span', { className: 'badge badge-neutral' }, (kb.stats?.chunkCount || kb.stats?.chunks || kb.stats?.totalChunks || 0) + ' chunks'),
                 kb.agentIds && kb.agentIds.length > 0 && h('span', { className: 'badge badge-success' }, kb.agentIds.length + ' agent(s)'),
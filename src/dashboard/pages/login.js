@@ -3,6 +3,7 @@ import { apiCall, authCall, engineCall } from '../components/utils.js';
 import { I } from '../components/icons.js';
 import { E } from '../assets/icons/emoji-icons.js';
 import { LoginAnimation } from '../components/login-animations.js';
+import { usePasswordPolicy, PasswordRules, passwordOk, passwordError } from '../components/password-rules.js';
 
 var _b = typeof window !== 'undefined' && window.__EM_BRANDING__ || {};
 var _brandLogo = _b.login_logo || _b.logo || _brandLogo;
@@ -28,14 +29,17 @@ export function LoginPage({ onLogin }) {
   var [forgotCode, setForgotCode] = useState('');
   var [forgotNewPw, setForgotNewPw] = useState('');
   var [forgotNewPw2, setForgotNewPw2] = useState('');
-  var [forgotStep, setForgotStep] = useState('email');  // 'email' | 'code' | 'no2fa' | 'done'
+  var passwordPolicy = usePasswordPolicy();
+  var [forgotStep, setForgotStep] = useState('email');  // 'email' | 'sent' | 'code' | 'no2fa' | 'done'
   var [forgotLoading, setForgotLoading] = useState(false);
   var [forgotError, setForgotError] = useState('');
+  var [emailReset, setEmailReset] = useState(false);   // server can email reset links
 
   useEffect(function() {
     fetch('/auth/sso/providers').then(function(r) { return r.ok ? r.json() : null; }).then(function(d) {
       if (d && d.providers && d.providers.length > 0) setSsoProviders(d.providers);
     }).catch(function() {});
+    authCall('/password-reset/config').then(function(d) { setEmailReset(!!d.emailReset); }).catch(function() {});
   }, []);
 
   var submitPassword = async function(e) {
@@ -71,6 +75,15 @@ export function LoginPage({ onLogin }) {
     setLoading(false);
   };
 
+  var submitForgotLink = async function() {
+    setForgotLoading(true); setForgotError('');
+    try {
+      await authCall('/forgot-password', { method: 'POST', body: JSON.stringify({ email: forgotEmail }) });
+      setForgotStep('sent');
+    } catch (err) { setForgotError(err.message); }
+    setForgotLoading(false);
+  };
+
   var submitForgotEmail = async function() {
     setForgotLoading(true); setForgotError('');
     try {
@@ -92,7 +105,7 @@ export function LoginPage({ onLogin }) {
 
   var submitForgotReset = async function() {
     if (forgotNewPw !== forgotNewPw2) { setForgotError('Passwords do not match'); return; }
-    if (forgotNewPw.length < 8) { setForgotError('Password must be at least 8 characters'); return; }
+    if (!passwordOk(passwordPolicy, forgotNewPw)) { setForgotError(passwordError(passwordPolicy, forgotNewPw)); return; }
     setForgotLoading(true); setForgotError('');
     try {
       var d = await authCall('/reset-password-self', { method: 'POST', body: JSON.stringify({ email: forgotEmail, totpCode: forgotCode, newPassword: forgotNewPw }) });
@@ -169,7 +182,7 @@ export function LoginPage({ onLogin }) {
         h('div', { className: 'login-logo' },
           h('img', { src: _brandLogo, alt: 'AgenticMail', style: { width: 48, height: 48, objectFit: 'contain' } }),
           h('h1', null, 'Reset Password'),
-          h('p', null, forgotStep === 'email' ? 'Enter your email address' : forgotStep === 'code' ? 'Verify with your authenticator app' : forgotStep === 'done' ? 'Password updated' : 'Contact your administrator')
+          h('p', null, forgotStep === 'email' ? 'Enter your email address' : forgotStep === 'sent' ? 'Check your inbox' : forgotStep === 'code' ? 'Verify with your authenticator app' : forgotStep === 'done' ? 'Password updated' : 'Contact your administrator')
         ),
 
         // Step: enter email
@@ -179,12 +192,26 @@ export function LoginPage({ onLogin }) {
             h('input', { className: 'input', type: 'email', value: forgotEmail, onChange: function(e) { setForgotEmail(e.target.value); }, placeholder: 'you@company.com', autoFocus: true })
           ),
           forgotError && h('div', { style: { color: 'var(--danger)', fontSize: 13, marginBottom: 12 } }, forgotError),
-          h('button', { className: 'btn btn-primary', onClick: submitForgotEmail, disabled: forgotLoading || !forgotEmail, style: { width: '100%', justifyContent: 'center', padding: '8px' } }, forgotLoading ? 'Checking...' : 'Continue'),
+          emailReset
+            ? h(Fragment, null,
+                h('button', { className: 'btn btn-primary', onClick: submitForgotLink, disabled: forgotLoading || !forgotEmail, style: { width: '100%', justifyContent: 'center', padding: '8px' } }, forgotLoading ? 'Sending...' : 'Email Me a Reset Link'),
+                h('button', { type: 'button', className: 'btn btn-secondary', onClick: submitForgotEmail, disabled: forgotLoading || !forgotEmail, style: { width: '100%', justifyContent: 'center', padding: '8px', marginTop: 8 } }, 'Use My Authenticator App Instead')
+              )
+            : h('button', { className: 'btn btn-primary', onClick: submitForgotEmail, disabled: forgotLoading || !forgotEmail, style: { width: '100%', justifyContent: 'center', padding: '8px' } }, forgotLoading ? 'Checking...' : 'Continue'),
           h('div', { style: { textAlign: 'center', marginTop: 16 } },
             h('button', { type: 'button', className: 'btn btn-ghost btn-sm', onClick: cancelForgot }, 'Back to login')
           )
         ),
 
+        // Step: reset link sent
+        forgotStep === 'sent' && h('div', null,
+          h('div', { style: { background: 'var(--info-soft, rgba(59,130,246,0.1))', borderRadius: 8, padding: 12, marginBottom: 16, fontSize: 13, color: 'var(--text-secondary)', lineHeight: 1.6 } },
+            'If an account exists for ', h('strong', null, forgotEmail), ', we\'ve sent it a link to choose a new password. The link expires in 1 hour and works once.'
+          ),
+          h('p', { style: { fontSize: 12, color: 'var(--text-muted)', marginBottom: 16 } }, 'No email? Check your spam folder, wait a minute and try again, or ask your administrator to send you a reset link.'),
+          h('button', { type: 'button', className: 'btn btn-primary', onClick: cancelForgot, style: { width: '100%', justifyContent: 'center' } }, 'Back to Login')
+        ),
+
         // Step: enter 2FA code + new password
         forgotStep === 'code' && h('div', null,
           h('div', { style: { background: 'var(--info-soft, rgba(59,130,246,0.1))', borderRadius: 8, padding: 12, marginBottom: 16, fontSize: 12, color: 'var(--text-secondary)' } },
@@ -201,7 +228,8 @@ export function LoginPage({ onLogin }) {
           ),
           h('div', { className: 'form-group' },
             h('label', { className: 'form-label' }, 'New Password'),
-            h('input', { className: 'input', type: 'password', value: forgotNewPw, onChange: function(e) { setForgotNewPw(e.target.value); }, placeholder: 'Min 8 characters' })
+            h('input', { className: 'input', type: 'password', value: forgotNewPw, onChange: function(e) { setForgotNewPw(e.target.value); }, placeholder: 'Min ' + passwordPolicy.minLength + ' characters' }),
+            h(PasswordRules, { policy: passwordPolicy, password: forgotNewPw })
           ),
           h('div', { className: 'form-group' },
             h('label', { className: 'form-label' }, 'Confirm Password'),
@@ -336,6 +364,75 @@ export function LoginPage({ onLogin }) {
   );
 }
 
+// ─── Reset Password (from an emailed link) ──────────────
+
+export function ResetPasswordPage({ token }) {
+  var [status, setStatus] = useState('checking'); // 'checking' | 'ready' | 'invalid' | 'done'
+  var [email, setEmail] = useState('');
+  var [pw, setPw] = useState('');
+  var [pw2, setPw2] = useState('');
+  var [error, setError] = useState('');
+  var [loading, setLoading] = useState(false);
+  var passwordPolicy = usePasswordPolicy();
+
+  useEffect(function() {
+    if (!token) { setStatus('invalid'); return; }
+    authCall('/reset-password/verify?token=' + encodeURIComponent(token))
+      .then(function(d) { setEmail(d.email); setStatus('ready'); })
+      .catch(function() { setStatus('invalid'); });
+  }, [token]);
+
+  var submit = async function(e) {
+    e.preventDefault();
+    if (pw !== pw2) { setError('Passwords do not match'); return; }
+    if (!passwordOk(passwordPolicy, pw)) { setError(passwordError(passwordPolicy, pw)); return; }
+    setLoading(true); setError('');
+    try {
+      await authCall('/reset-password', { method: 'POST', body: JSON.stringify({ token: token, newPassword: pw }) });
+      setStatus('done');
+    } catch (err) { setError(err.message); }
+    setLoading(false);
+  };
+
+  var toLogin = function() { window.location.href = '/dashboard/'; };
+
+  return h('div', { className: 'login-page', style: Object.assign({ position: 'relative', overflow: 'hidden' }, _brandBg ? { backgroundImage: 'url(' + _brandBg + ')', backgroundSize: 'cover', backgroundPosition: 'center' } : {}) },
+    !_brandBg && h(LoginAnimation),
+    h('div', { className: 'login-card', style: { position: 'relative', zIndex: 1 } },
+      h('div', { className: 'login-logo' },
+        h('img', { src: _brandLogo, alt: 'AgenticMail', style: { width: 48, height: 48, objectFit: 'contain' } }),
+        h('h1', null, 'Choose a New Password'),
+        h('p', null, status === 'ready' ? email : status === 'done' ? 'Password updated' : status === 'invalid' ? 'Link not valid' : 'Checking your link...')
+      ),
+
+      status === 'ready' && h('form', { onSubmit: submit },
+        h('div', { className: 'form-group' },
+          h('label', { className: 'form-label' }, 'New Password'),
+          h('input', { className: 'input', type: 'password', autoComplete: 'new-password', value: pw, onChange: function(e) { setPw(e.target.value); }, placeholder: 'Min ' + passwordPolicy.minLength + ' characters', autoFocus: true }),
+          h(PasswordRules, { policy: passwordPolicy, password: pw })
+        ),
+        h('div', { className: 'form-group' },
+          h('label', { className: 'form-label' }, 'Confirm Password'),
+          h('input', { className: 'input', type: 'password', autoComplete: 'new-password', value: pw2, onChange: function(e) { setPw2(e.target.value); }, placeholder: 'Confirm new password' })
+        ),
+        error && h('div', { style: { color: 'var(--danger)', fontSize: 13, marginBottom: 12 } }, error),
+        h('button', { className: 'btn btn-primary', type: 'submit', disabled: loading || !pw || !pw2, style: { width: '100%', justifyContent: 'center', padding: '8px' } }, loading ? 'Saving...' : 'Set New Password')
+      ),
+
+      status === 'invalid' && h('div', { style: { textAlign: 'center' } },
+        h('p', { style: { fontSize: 13, color: 'var(--text-muted)', lineHeight: 1.6, marginBottom: 16 } },
+          'This reset link is invalid, has expired, or has already been used. Request a new one from the sign-in page or ask your administrator.'),
+        h('button', { type: 'button', className: 'btn btn-primary', onClick: toLogin, style: { width: '100%', justifyContent: 'center' } }, 'Back to Login')
+      ),
+
+      status === 'done' && h('div', { style: { textAlign: 'center' } },
+        h('p', { style: { fontSize: 13, color: 'var(--text-muted)', marginBottom: 16 } }, 'Your password has been reset. Sign in with your new password.'),
+        h('button', { type: 'button', className: 'btn btn-primary', onClick: toLogin, style: { width: '100%', justifyContent: 'center' } }, 'Sign In')
+      )
+    )
+  );
+}
+
 // ─── Database Type Metadata ──────────────────────────────
 
 var DB_TYPES = [
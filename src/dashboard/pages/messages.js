@@ -1,37 +1,163 @@
+import { h, useState, useEffect, useRef, Fragment, useApp, apiCall, engineCall, getOrgId, buildAgentEmailMap, buildAgentDataMap, renderAgentBadge, downloadCsv } from '../components/utils.js';
 import { I } from '../components/icons.js';
 import { E } from '../assets/icons/emoji-icons.js';
 import { HelpButton } from '../components/help-button.js';
 import { useOrgContext } from '../components/org-switcher.js';
 import { KnowledgeLink } from '../components/knowledge-link.js';
+import { useFormDraft, DraftPrompt } from '../components/drafts.js';
+import { MarkdownEditor, MarkdownView } from '../components/markdown.js';
+import { VariableChips, UnknownVariablesWarning, unknownVariables } from '../components/merge-vars.js';
+import { REMIND_PRESETS, notifyFollowUpsChanged } from '../components/notifications.js';
+import { DeliveryTimeline } from '../components/delivery-timeline.js';
+import { MessageSearchPanel } from '../components/message-search.js';
+import { LabelChips, LabelPicker, LabelManager, loadSavedFilters, storeSavedFilters } from '../components/message-labels.js';
+import { Table, useSort } from '../components/table.js';
+import { Pagination, usePageSize } from '../components/pagination.js';
+import { FilterBar, useFilters } from '../components/filter-bar.js';
+import { RelativeTime, formatTime } from '../components/time.js';
 
 export function MessagesPage() {
   var orgCtx = useOrgContext();
   var effectiveOrgId = orgCtx.selectedOrgId || getOrgId();
   const { toast } = useApp();
   const [messages, setMessages] = useState([]);
+  const [messageTotal, setMessageTotal] = useState(0);
+  const [page, setPage] = useState(1);
+  const [pageSize, setPageSize] = usePageSize('messages', 50);
   const [agents, setAgents] = useState([]);
   const [topology, setTopology] = useState(null);
   const [mainTab, setMainTab] = useState('messages');
   const [subTab, setSubTab] = useState('all');
   const [showModal, setShowModal] = useState(false);
-  const [form, setForm] = useState({ orgId: effectiveOrgId, fromAgentId: '', toAgentId: '', subject: '', content: '', priority: 'normal' });
+  const [form, setForm] = useState({ orgId: effectiveOrgId, fromAgentId: '', toAgentId: '', toAgentIds: [], merge: false, subject: '', content: '', priority: 'normal' });
+  const [mergePreview, setMergePreview] = useState(null);
   const [selectedNode, setSelectedNode] = useState(null);
+  const [viewMessage, setViewMessage] = useState(null);
+  const [followUps, setFollowUps] = useState({}); // messageId → pending follow-up
+  const [customRemind, setCustomRemind] = useState('');
+  const [labels, setLabels] = useState([]);
+  const [labelMap, setLabelMap] = useState({}); // messageId → labelIds
+  const [labelFilter, setLabelFilter] = useState('');
+  const [showLabels, setShowLabels] = useState(false);
+  const [savedFilters, setSavedFilters] = useState(loadSavedFilters);
   const [nodePositions, setNodePositions] = useState([]);
   const svgRef = useRef(null);
 
-  const loadMessages = () => {
-    engineCall('/messages?orgId=' + effectiveOrgId + '&limit=100').then(d => setMessages(d.messages || [])).catch(() => {});
+  const sort = useSort('createdAt', 'desc');
+  const filters = useFilters({ q: '', channel: '', status: '' });
+  const loadMessages = (p) => {
+    var pg = p || page;
+    engineCall('/messages?orgId=' + effectiveOrgId + '&limit=' + pageSize + '&offset=' + (pg - 1) * pageSize + '&' + sort.query + (filters.query ? '&' + filters.query : ''))
+      .then(d => { setMessages(d.messages || []); setMessageTotal(d.total || 0); }).catch(() => {});
   };
+  const goPage = (p) => { setPage(p); loadMessages(p); };
   const loadAgents = () => {
     apiCall('/agents' + (orgCtx.selectedOrgId ? '?clientOrgId=' + orgCtx.selectedOrgId : '')).then(d => setAgents(d.agents || [])).catch(() => {});
   };
   const loadTopology = () => {
     engineCall('/messages/topology?orgId=' + effectiveOrgId).then(d => setTopology(d.topology || null)).catch(() => {});
   };
-  useEffect(() => { loadMessages(); loadAgents(); loadTopology(); }, []);
+  const loadFollowUps = () => {
+    engineCall('/follow-ups?orgId=' + effectiveOrgId).then(d => {
+      const map = {};
+      (d.followUps || []).forEach(f => { map[f.messageId] = f; });
+      setFollowUps(map);
+    }).catch(() => {});
+  };
+  const loadLabels = () => {
+    engineCall('/message-labels?orgId=' + effectiveOrgId).then(d => setLabels(d.labels || [])).catch(() => {});
+    engineCall('/message-labels/assignments?orgId=' + effectiveOrgId).then(d => setLabelMap(d.assignments || {})).catch(() => {});
+  };
+  useEffect(() => { loadAgents(); loadTopology(); loadFollowUps(); loadLabels(); }, []);
+  // A new sort, filter or page size starts again from the first page
+  useEffect(() => { setPage(1); loadMessages(1); }, [sort.query, filters.query, pageSize]);
+
+  // Deep link from the notifications center: /dashboard/messages?message=<id>
+  useEffect(() => {
+    const open = (id) => {
+      if (!id) return;
+      engineCall('/messages/' + encodeURIComponent(id)).then(d => { if (d.message) setViewMessage(d.message); }).catch(() => toast('Message no longer available', 'error'));
+    };
+    open(new URLSearchParams(location.search).get('message'));
+    const onOpen = (e) => { loadFollowUps(); open(e.detail); };
+    window.addEventListener('em:open-message', onOpen);
+    window.addEventListener('em:followups-changed', loadFollowUps);
+    return () => { window.removeEventListener('em:open-message', onOpen); window.removeEventListener('em:followups-changed', loadFollowUps); };
+  }, []);
+
+  // ── Snooze / follow-up ──
+  const scheduleFollowUp = async (msg, kind, when) => {
+    try {
+      await engineCall('/follow-ups', { method: 'POST', body: JSON.stringify({ messageId: msg.id, orgId: effectiveOrgId, kind, remindAt: when.toISOString() }) });
+      toast(kind === 'snooze' ? 'Snoozed until ' + when.toLocaleString() : 'Follow-up set for ' + when.toLocaleString(), 'success');
+      if (kind === 'snooze') setViewMessage(null);
+      setCustomRemind('');
+      loadFollowUps(); notifyFollowUpsChanged();
+    } catch (e) { toast(e.message, 'error'); }
+  };
+  const finishFollowUp = async (f, cancel) => {
+    try {
+      await engineCall('/follow-ups/' + f.id + (cancel ? '' : '/done'), { method: cancel ? 'DELETE' : 'POST' });
+      loadFollowUps(); notifyFollowUpsChanged();
+    } catch (e) { toast(e.message, 'error'); }
+  };
+  const isDue = (f) => f && new Date(f.remindAt).getTime() <= Date.now();
+
+  // ── Labels ──
+  const setMessageLabels = async (msg, labelIds) => {
+    const prev = labelMap[msg.id] || [];
+    setLabelMap({ ...labelMap, [msg.id]: labelIds });
+    try {
+      await engineCall('/message-labels/assignments/' + encodeURIComponent(msg.id), { method: 'PUT', body: JSON.stringify({ orgId: effectiveOrgId, labelIds }) });
+    } catch (e) { setLabelMap({ ...labelMap, [msg.id]: prev }); toast(e.message, 'error'); }
+  };
+
+  // ── QA ──
+  const sendToQA = async (msg) => {
+    try {
+      await engineCall('/qa/queue', { method: 'POST', body: JSON.stringify({ messageId: msg.id }) });
+      toast('Added to the QA review queue', 'success');
+    } catch (e) { toast(e.message, 'error'); }
+  };
+
+  const saveFilter = () => {
+    const name = prompt('Name this filter');
+    if (!name || !name.trim()) return;
+    const next = savedFilters.filter(f => f.name !== name.trim()).concat({ name: name.trim(), subTab, labelId: labelFilter });
+    setSavedFilters(next); storeSavedFilters(next);
+  };
+  const removeFilter = (name) => { const next = savedFilters.filter(f => f.name !== name); setSavedFilters(next); storeSavedFilters(next); };
+  const applyFilter = (f) => { setSubTab(f.subTab || 'all'); setLabelFilter(f.labelId || ''); };
+
+  // Compose draft persists server-side so closing the modal or navigating away keeps it
+  const draft = useFormDraft('compose-message', form, { enabled: showModal && !!(form.subject || form.content) });
+  const resumeDraft = () => { const data = draft.resume(); if (data) setForm({ ...form, ...data, orgId: effectiveOrgId }); };
+
+  // Mail-merge: {{variables}} are resolved per recipient on the server
+  const MERGE_MAX = 25;
+  const unknownVars = form.merge ? unknownVariables([form.subject, form.content]) : [];
+  const mergeBody = (extra) => JSON.stringify({ orgId: form.orgId, fromAgentId: form.fromAgentId, toAgentIds: form.toAgentIds, subject: form.subject, content: form.content, priority: form.priority, format: 'markdown', ...extra });
+  const toggleRecipient = (id) => {
+    const ids = form.toAgentIds || [];
+    setForm({ ...form, toAgentIds: ids.includes(id) ? ids.filter(x => x !== id) : ids.concat(id).slice(0, MERGE_MAX) });
+    setMergePreview(null);
+  };
+  const previewMerge = async () => {
+    try { const d = await engineCall('/messages/merge', { method: 'POST', body: mergeBody({ dryRun: true }) }); setMergePreview({ items: d.preview || [], index: 0 }); } catch (e) { toast(e.message, 'error'); }
+  };
 
   const send = async () => {
-    try { await engineCall('/messages', { method: 'POST', body: JSON.stringify(form) }); toast('Message sent', 'success'); setShowModal(false); loadMessages(); loadTopology(); } catch (e) { toast(e.message, 'error'); }
+    if (unknownVars.length) { toast('Fix unknown variables before sending', 'error'); return; }
+    try {
+      if (form.merge) {
+        const d = await engineCall('/messages/merge', { method: 'POST', body: mergeBody() });
+        toast(d.total + ' personalised message' + (d.total === 1 ? '' : 's') + ' sent', 'success');
+      } else {
+        await engineCall('/messages', { method: 'POST', body: JSON.stringify({ orgId: form.orgId, fromAgentId: form.fromAgentId, toAgentId: form.toAgentId, subject: form.subject, content: form.content, priority: form.priority, format: 'markdown' }) });
+        toast('Message sent', 'success');
+      }
+      draft.clear(); setShowModal(false); setMergePreview(null); setForm({ ...form, subject: '', content: '' }); loadMessages(); loadTopology();
+    } catch (e) { toast(e.message, 'error'); }
   };
 
   // Agent name resolution
@@ -40,10 +166,15 @@ export function MessagesPage() {
   const resolveAgent = (id) => renderAgentBadge(id, agentData);
 
   // Filtering logic
-  const filtered = subTab === 'all' ? messages
-    : subTab === 'internal' ? messages.filter(m => m.direction === 'internal')
-    : subTab === 'external' ? messages.filter(m => m.direction === 'external_outbound' || m.direction === 'external_inbound')
-    : messages.filter(m => m.type === subTab);
+  // Snoozed messages stay hidden until their reminder is due
+  const visible = messages.filter(m => { const f = followUps[m.id]; return !(f && f.kind === 'snooze' && !isDue(f)); });
+  const byTab = subTab === 'all' ? visible
+    : subTab === 'followups' ? messages.filter(m => followUps[m.id]).sort((a, b) => followUps[a.id].remindAt.localeCompare(followUps[b.id].remindAt))
+    : subTab === 'internal' ? visible.filter(m => m.direction === 'internal')
+    : subTab === 'external' ? visible.filter(m => m.direction === 'external_outbound' || m.direction === 'external_inbound')
+    : visible.filter(m => m.type === subTab);
+  const filtered = labelFilter ? byTab.filter(m => (labelMap[m.id] || []).includes(labelFilter)) : byTab;
+  const dueCount = Object.values(followUps).filter(isDue).length;
 
   const typeIcon = (t) => t === 'task' ? E.clipboard(14) : t === 'handoff' ? E.sync(14) : t === 'broadcast' ? E.bell(14) : E.chat(14);
   const channelIcon = (ch) => ch === 'email' ? E.email(14) : ch === 'task' ? E.clipboard(14) : E.chat(14);
@@ -215,8 +346,13 @@ export function MessagesPage() {
         h('li', null, h('strong', null, 'Tasks/Handoffs'), ' — Structured work delegation between agents.'),
         h('li', null, h('strong', null, 'Broadcasts'), ' — One-to-many announcements.')
       ),
+      h('h4', { style: _h4 }, 'Search'),
+      h('p', null, 'The Search tab looks through the text of every stored message — internal, WhatsApp and Telegram — not just the recent ones listed here. Searches and exports are recorded in the audit log.'),
       h('div', { style: _tip }, h('strong', null, 'Tip: '), 'Switch to the Topology tab to visualize which agents communicate most. Click nodes to see communication details.')
-    )), h('button', { className: 'btn btn-primary', onClick: () => setShowModal(true) }, I.plus(), ' New Message')),
+    )), h('div', { style: { display: 'flex', gap: 8 } },
+      h('button', { className: 'btn btn-secondary', title: 'Download every message matching the current filters', onClick: () => downloadCsv('/engine/messages?orgId=' + effectiveOrgId + '&' + sort.query + (filters.query ? '&' + filters.query : '')).catch(err => toast(err.message, 'error')) }, I.download(), ' Export CSV'),
+      h('button', { className: 'btn btn-primary', onClick: () => setShowModal(true) }, I.plus(), ' New Message')
+    )),
 
     // Stats cards
     h('div', { style: { display: 'grid', gridTemplateColumns: 'repeat(auto-fit, minmax(160px, 1fr))', gap: 12, marginBottom: 20 } },
@@ -234,68 +370,170 @@ export function MessagesPage() {
       )))
     ),
 
-    // Main tabs: Messages | Topology
+    // Main tabs: Messages | Topology | Search
     h('div', { className: 'tabs', style: { marginBottom: 16 } },
       h('button', { className: 'tab' + (mainTab === 'messages' ? ' active' : ''), onClick: () => setMainTab('messages'), style: { display: 'flex', alignItems: 'center', gap: 6 } }, I.messages(), 'Messages'),
-      h('button', { className: 'tab' + (mainTab === 'topology' ? ' active' : ''), onClick: () => setMainTab('topology'), style: { display: 'flex', alignItems: 'center', gap: 6 } }, I.orgChart(), 'Topology')
+      h('button', { className: 'tab' + (mainTab === 'topology' ? ' active' : ''), onClick: () => setMainTab('topology'), style: { display: 'flex', alignItems: 'center', gap: 6 } }, I.orgChart(), 'Topology'),
+      h('button', { className: 'tab' + (mainTab === 'search' ? ' active' : ''), onClick: () => setMainTab('search'), style: { display: 'flex', alignItems: 'center', gap: 6 } }, I.search(), 'Search')
     ),
 
     // Messages tab content
     mainTab === 'messages' && h('div', null,
       // Sub-tabs
       h('div', { className: 'tabs', style: { marginBottom: 12 } },
-        ['all', 'internal', 'external', 'message', 'task', 'handoff', 'broadcast'].map(t =>
+        ['all', 'internal', 'external', 'message', 'task', 'handoff', 'broadcast', 'followups'].map(t =>
           h('button', { key: t, className: 'tab' + (subTab === t ? ' active' : ''), onClick: () => setSubTab(t) },
-            t === 'all' ? 'All' : t === 'internal' ? 'Internal' : t === 'external' ? 'External' : t.charAt(0).toUpperCase() + t.slice(1) + 's')
+            t === 'all' ? 'All' : t === 'internal' ? 'Internal' : t === 'external' ? 'External' : t === 'followups' ? 'Follow-ups' : t.charAt(0).toUpperCase() + t.slice(1) + 's',
+            t === 'followups' && dueCount > 0 && h('span', { className: 'badge badge-danger', style: { marginLeft: 6, fontSize: 10 } }, dueCount)
+          )
         )
       ),
+      h(FilterBar, {
+        filters, placeholder: 'Search subject, content or agent...', viewsKey: 'messages',
+        fields: [
+          { key: 'channel', label: 'All channels', options: ['direct', 'email', 'task'] },
+          { key: 'status', label: 'Any status', options: ['pending', 'delivered', 'read', 'completed', 'failed'] }
+        ]
+      }),
+      // Label filter + saved filters
+      h('div', { style: { display: 'flex', flexWrap: 'wrap', alignItems: 'center', gap: 8, marginBottom: 12, fontSize: 13 } },
+        h('select', { className: 'input', style: { width: 180 }, value: labelFilter, onChange: e => setLabelFilter(e.target.value) },
+          h('option', { value: '' }, 'All labels'),
+          labels.map(l => h('option', { key: l.id, value: l.id }, l.name))
+        ),
+        h('button', { className: 'btn btn-ghost btn-sm', onClick: () => setShowLabels(true) }, 'Manage labels'),
+        h('button', { className: 'btn btn-ghost btn-sm', disabled: subTab === 'all' && !labelFilter, onClick: saveFilter }, 'Save filter'),
+        savedFilters.length > 0 && h('span', { style: { color: 'var(--text-muted)', marginLeft: 8 } }, 'Saved:'),
+        savedFilters.map(f => h('span', { key: f.name, className: 'badge', style: { display: 'inline-flex', alignItems: 'center', gap: 4, cursor: 'pointer', fontWeight: f.subTab === subTab && (f.labelId || '') === labelFilter ? 700 : 400 }, onClick: () => applyFilter(f) },
+          f.name,
+          h('button', { className: 'btn btn-ghost', style: { padding: 0, minHeight: 0, fontSize: 12, lineHeight: 1 }, title: 'Delete saved filter', onClick: e => { e.stopPropagation(); removeFilter(f.name); } }, '×')
+        ))
+      ),
       h('div', { className: 'card' },
-        h('table', { className: 'data-table' },
-          h('thead', null, h('tr', null,
-            h('th', null, 'Type'), h('th', null, 'Direction'), h('th', null, 'Channel'), h('th', null, 'From'), h('th', null, 'To'), h('th', null, 'Subject'), h('th', null, 'Status'), h('th', null, 'Priority'), h('th', null, 'Time')
-          )),
-          h('tbody', null, filtered.length === 0
-            ? h('tr', null, h('td', { colSpan: 9, style: { textAlign: 'center', color: 'var(--text-muted)', padding: 40 } }, 'No messages'))
-            : filtered.map(m => h('tr', { key: m.id },
-              h('td', null, typeIcon(m.type), ' ', m.type),
-              h('td', null, dirBadge(m.direction)),
-              h('td', null, channelIcon(m.channel), ' ', m.channel || 'direct'),
-              h('td', null, resolveAgent(m.fromAgentId)),
-              h('td', null, resolveAgent(m.toAgentId)),
-              h('td', null, h('strong', null, m.subject)),
-              h('td', null, h('span', { className: 'status-badge status-' + (m.status === 'completed' ? 'success' : m.status === 'failed' ? 'error' : m.status === 'read' ? 'info' : 'warning') }, m.status)),
-              h('td', null, m.priority),
-              h('td', null, new Date(m.createdAt).toLocaleString())
-            ))
-          )
-        )
+        h(Table, {
+          prefsKey: 'messages',
+          className: 'data-table', sort: sort.sort, onSort: sort.toggle, rows: filtered, empty: 'No messages',
+          onRowClick: setViewMessage,
+          columns: [
+            { key: 'type', label: 'Type', sortable: true, render: m => h(Fragment, null, typeIcon(m.type), ' ', m.type) },
+            { key: 'direction', label: 'Direction', sortable: true, render: m => dirBadge(m.direction) },
+            { key: 'channel', label: 'Channel', sortable: true, render: m => h(Fragment, null, channelIcon(m.channel), ' ', m.channel || 'direct') },
+            { key: 'fromAgentId', label: 'From', sortable: true, render: m => resolveAgent(m.fromAgentId) },
+            { key: 'toAgentId', label: 'To', sortable: true, render: m => resolveAgent(m.toAgentId) },
+            { key: 'subject', label: 'Subject', sortable: true, render: m => h(Fragment, null, h('strong', null, m.subject), h(LabelChips, { ids: labelMap[m.id], labels }), followUps[m.id] && h('span', { title: (followUps[m.id].kind === 'snooze' ? 'Snoozed until ' : 'Follow up ') + formatTime(followUps[m.id].remindAt), style: { marginLeft: 6, verticalAlign: 'middle', color: isDue(followUps[m.id]) ? 'var(--danger)' : 'var(--text-muted)', display: 'inline-flex' } }, I.clock())) },
+            { key: 'status', label: 'Status', sortable: true, render: m => h('span', { title: m.metadata?.deliveryError?.reason, className: 'status-badge status-' + (m.status === 'completed' ? 'success' : m.status === 'failed' ? 'error' : m.status === 'read' ? 'info' : 'warning') }, m.status) },
+            { key: 'priority', label: 'Priority', sortable: true },
+            { key: 'createdAt', label: 'Time', sortable: true, defaultDir: 'desc', render: m => h(RelativeTime, { value: m.createdAt }) }
+          ]
+        }),
+        h(Pagination, { page, pageSize, total: messageTotal, noun: 'messages', onPage: goPage, onPageSize: setPageSize })
       )
     ),
 
     // Topology tab content
     mainTab === 'topology' && renderTopology(),
 
-    // New Message modal (unchanged)
+    // Full-text search over every stored message body
+    mainTab === 'search' && h(MessageSearchPanel, { orgId: effectiveOrgId, agentData, toast }),
+
+    // Message detail modal
+    viewMessage && h('div', { className: 'modal-overlay', onClick: () => setViewMessage(null) },
+      h('div', { className: 'modal', style: { maxWidth: 720 }, onClick: e => e.stopPropagation() },
+        h('div', { className: 'modal-header' }, h('h2', null, viewMessage.subject || '(no subject)'), h('button', { className: 'btn btn-ghost btn-icon', onClick: () => setViewMessage(null) }, I.x())),
+        h('div', { className: 'modal-body' },
+          h('div', { style: { display: 'flex', flexWrap: 'wrap', gap: 12, alignItems: 'center', fontSize: 13, color: 'var(--text-muted)', marginBottom: 16 } },
+            h('span', null, 'From ', resolveAgent(viewMessage.fromAgentId)),
+            h('span', null, 'To ', resolveAgent(viewMessage.toAgentId)),
+            dirBadge(viewMessage.direction),
+            h('span', null, formatTime(viewMessage.createdAt, 'full'))
+          ),
+          // Only admin-composed markdown is rendered; observed email bodies may hold raw HTML and are shown as text
+          h(MarkdownView, { source: viewMessage.content, markdown: viewMessage.metadata?.format === 'markdown' }),
+          // Delivery timeline / read receipts
+          h('div', { style: { marginTop: 20, paddingTop: 12, borderTop: '1px solid var(--border)' } },
+            h(DeliveryTimeline, { key: viewMessage.id, messageId: viewMessage.id, toast, onResent: () => { toast('Message resent', 'success'); loadMessages(); } })
+          ),
+          h('div', { style: { marginTop: 20, paddingTop: 12, borderTop: '1px solid var(--border)' } },
+            h(LabelPicker, { labels, value: labelMap[viewMessage.id], onChange: ids => setMessageLabels(viewMessage, ids), onManage: () => setShowLabels(true) })
+          ),
+          // Snooze / follow-up
+          h('div', { style: { marginTop: 20, paddingTop: 12, borderTop: '1px solid var(--border)' } },
+            followUps[viewMessage.id]
+              ? h('div', { style: { display: 'flex', alignItems: 'center', gap: 8, fontSize: 13 } },
+                  I.clock(),
+                  h('span', { style: { flex: 1, color: isDue(followUps[viewMessage.id]) ? 'var(--danger)' : 'var(--text-secondary)' } },
+                    (followUps[viewMessage.id].kind === 'snooze' ? 'Snoozed until ' : 'Follow-up ') + (isDue(followUps[viewMessage.id]) ? 'due since ' : '') + formatTime(followUps[viewMessage.id].remindAt)),
+                  h('button', { className: 'btn btn-secondary btn-sm', onClick: () => finishFollowUp(followUps[viewMessage.id], false) }, 'Mark done'),
+                  h('button', { className: 'btn btn-ghost btn-sm', onClick: () => finishFollowUp(followUps[viewMessage.id], true) }, 'Cancel reminder')
+                )
+              : h('div', { style: { display: 'flex', flexWrap: 'wrap', alignItems: 'center', gap: 6, fontSize: 13 } },
+                  h('span', { style: { color: 'var(--text-muted)', marginRight: 4 } }, 'Remind me:'),
+                  REMIND_PRESETS.map(p => h('button', { key: p.id, className: 'btn btn-ghost btn-sm', onClick: () => scheduleFollowUp(viewMessage, 'followup', p.at()) }, p.label)),
+                  h('input', { type: 'datetime-local', className: 'input', style: { width: 200, fontSize: 12 }, value: customRemind, onChange: e => setCustomRemind(e.target.value) }),
+                  h('button', { className: 'btn btn-ghost btn-sm', disabled: !customRemind, onClick: () => scheduleFollowUp(viewMessage, 'followup', new Date(customRemind)) }, 'Set'),
+                  h('button', { className: 'btn btn-secondary btn-sm', title: 'Hide until tomorrow 9:00', onClick: () => scheduleFollowUp(viewMessage, 'snooze', REMIND_PRESETS[2].at()) }, 'Snooze until tomorrow')
+                )
+          )
+        ),
+        h('div', { className: 'modal-footer' },
+          viewMessage.fromAgentId && h('button', { className: 'btn btn-secondary', style: { marginRight: 'auto' }, onClick: () => sendToQA(viewMessage) }, 'Send to QA'),
+          h('button', { className: 'btn btn-ghost', onClick: () => setViewMessage(null) }, 'Close'))
+      )
+    ),
+
+    showLabels && h(LabelManager, { orgId: effectiveOrgId, labels, toast, onClose: () => setShowLabels(false), onChanged: loadLabels }),
+
+    // New Message modal
     showModal && h('div', { className: 'modal-overlay', onClick: () => setShowModal(false) },
       h('div', { className: 'modal', onClick: e => e.stopPropagation() },
         h('div', { className: 'modal-header' }, h('h2', null, 'Send Message'), h('button', { className: 'btn btn-ghost btn-icon', onClick: () => setShowModal(false) }, I.x())),
         h('div', { className: 'modal-body' },
+          h(DraftPrompt, { draft: draft.pending, onResume: resumeDraft, onDiscard: draft.discard }),
           h('label', { className: 'field-label' }, 'From Agent'),
           h('select', { className: 'input', value: form.fromAgentId, onChange: e => setForm({ ...form, fromAgentId: e.target.value }) },
             h('option', { value: '' }, '-- Select Agent --'),
             agents.map(a => h('option', { key: a.id, value: a.id }, (a.config?.displayName || a.config?.name || a.name || 'Agent') + (a.config?.email?.address ? ' (' + a.config.email.address + ')' : '')))
           ),
-          h('label', { className: 'field-label' }, 'To Agent'),
-          h('select', { className: 'input', value: form.toAgentId, onChange: e => setForm({ ...form, toAgentId: e.target.value }) },
-            h('option', { value: '' }, '-- Select Agent --'),
-            agents.map(a => h('option', { key: a.id, value: a.id }, (a.config?.displayName || a.config?.name || a.name || 'Agent') + (a.config?.email?.address ? ' (' + a.config.email.address + ')' : '')))
+          h('label', { style: { display: 'flex', alignItems: 'center', gap: 6, fontSize: 13, margin: '12px 0 4px' } },
+            h('input', { type: 'checkbox', checked: !!form.merge, onChange: e => { setForm({ ...form, merge: e.target.checked }); setMergePreview(null); } }),
+            'Mail merge — send a personalised copy to several agents'
+          ),
+          !form.merge && h(Fragment, null,
+            h('label', { className: 'field-label' }, 'To Agent'),
+            h('select', { className: 'input', value: form.toAgentId, onChange: e => setForm({ ...form, toAgentId: e.target.value }) },
+              h('option', { value: '' }, '-- Select Agent --'),
+              agents.map(a => h('option', { key: a.id, value: a.id }, (a.config?.displayName || a.config?.name || a.name || 'Agent') + (a.config?.email?.address ? ' (' + a.config.email.address + ')' : '')))
+            )
+          ),
+          form.merge && h(Fragment, null,
+            h('label', { className: 'field-label' }, 'To Agents (' + (form.toAgentIds || []).length + '/' + MERGE_MAX + ')'),
+            h('div', { className: 'input', style: { maxHeight: 140, overflowY: 'auto', height: 'auto', padding: 6 } },
+              agents.filter(a => a.id !== form.fromAgentId).map(a => h('label', { key: a.id, style: { display: 'flex', alignItems: 'center', gap: 6, fontSize: 13, padding: '2px 0' } },
+                h('input', { type: 'checkbox', checked: (form.toAgentIds || []).includes(a.id), onChange: () => toggleRecipient(a.id) }),
+                (a.config?.displayName || a.config?.name || a.name || 'Agent') + (a.config?.email?.address ? ' (' + a.config.email.address + ')' : '')
+              ))
+            )
+          ),
+          h('label', { className: 'field-label' }, 'Subject'), h('input', { className: 'input', value: form.subject, onChange: e => { setForm({ ...form, subject: e.target.value }); setMergePreview(null); } }),
+          h('label', { className: 'field-label' }, 'Content'), h(MarkdownEditor, { value: form.content, onChange: v => { setForm({ ...form, content: v }); setMergePreview(null); }, placeholder: form.merge ? 'Hi {{contact.firstName}}, ...' : 'Write your message...' }),
+          form.merge && h(VariableChips, { onInsert: v => setForm({ ...form, content: (form.content || '') + v }) }),
+          h(UnknownVariablesWarning, { unknown: unknownVars, hint: 'remove or correct them before sending' }),
+          form.merge && h('div', { style: { margin: '8px 0' } },
+            h('button', { type: 'button', className: 'btn btn-secondary btn-sm', disabled: !form.fromAgentId || !(form.toAgentIds || []).length || unknownVars.length > 0, onClick: previewMerge }, 'Preview per recipient'),
+            mergePreview && mergePreview.items.length > 0 && h('div', { style: { marginTop: 8, border: '1px solid var(--border)', borderRadius: 'var(--radius)', padding: 12 } },
+              h('div', { style: { display: 'flex', alignItems: 'center', gap: 8, marginBottom: 8, fontSize: 12, color: 'var(--text-muted)' } },
+                h('button', { type: 'button', className: 'btn btn-ghost btn-sm', disabled: mergePreview.index === 0, onClick: () => setMergePreview({ ...mergePreview, index: mergePreview.index - 1 }) }, '\u2190'),
+                'Recipient ' + (mergePreview.index + 1) + ' of ' + mergePreview.items.length + ': ', resolveAgent(mergePreview.items[mergePreview.index].toAgentId),
+                h('button', { type: 'button', className: 'btn btn-ghost btn-sm', disabled: mergePreview.index >= mergePreview.items.length - 1, onClick: () => setMergePreview({ ...mergePreview, index: mergePreview.index + 1 }) }, '\u2192')
+              ),
+              h('div', { style: { fontWeight: 600, marginBottom: 6 } }, mergePreview.items[mergePreview.index].subject),
+              h(MarkdownView, { source: mergePreview.items[mergePreview.index].content })
+            )
           ),
-          h('label', { className: 'field-label' }, 'Subject'), h('input', { className: 'input', value: form.subject, onChange: e => setForm({ ...form, subject: e.target.value }) }),
-          h('label', { className: 'field-label' }, 'Content'), h('textarea', { className: 'input', style: { minHeight: 80 }, value: form.content, onChange: e => setForm({ ...form, content: e.target.value }) }),
           h('label', { className: 'field-label' }, 'Priority'),
           h('select', { className: 'input', value: form.priority, onChange: e => setForm({ ...form, priority: e.target.value }) }, h('option', { value: 'low' }, 'Low'), h('option', { value: 'normal' }, 'Normal'), h('option', { value: 'high' }, 'High'), h('option', { value: 'urgent' }, 'Urgent'))
         ),
-        h('div', { className: 'modal-footer' }, h('button', { className: 'btn btn-ghost', onClick: () => setShowModal(false) }, 'Cancel'), h('button', { className: 'btn btn-primary', onClick: send }, 'Send'))
+        h('div', { className: 'modal-footer' }, h('button', { className: 'btn btn-ghost', onClick: () => setShowModal(false) }, 'Cancel'), h('button', { className: 'btn btn-primary', disabled: unknownVars.length > 0, onClick: send }, form.merge ? 'Send ' + (form.toAgentIds || []).length + ' Messages' : 'Send'))
       )
     )
   );
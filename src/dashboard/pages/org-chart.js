@@ -3,6 +3,7 @@ import { I } from '../components/icons.js';
 import { HelpButton } from '../components/help-button.js';
 import { useOrgContext } from '../components/org-switcher.js';
 import { KnowledgeLink } from '../components/knowledge-link.js';
+import { timeAgo } from '../components/time.js';
 
 // ─── Inject theme CSS once ───────────────────────────────
 var _injected = false;
@@ -90,14 +91,6 @@ function legendDot(color, label) {
     h('div', { style: { width: 8, height: 8, borderRadius: '50%', background: color } }),
     h('span', { style: { color: 'var(--oc-dim)', fontSize: 12 } }, label));
 }
-function timeAgo(iso) {
-  var diff = Date.now() - new Date(iso).getTime();
-  var mins = Math.floor(diff / 60000);
-  if (mins < 1) return 'Just now'; if (mins < 60) return mins + 'm ago';
-  var hrs = Math.floor(mins / 60); if (hrs < 24) return hrs + 'h ago';
-  return Math.floor(hrs / 24) + 'd ago';
-}
-
 // ─── Summary Metrics ─────────────────────────────────────
 function OrgSummary(props) {
   var nodes = props.nodes;
@@ -308,7 +308,7 @@ export function OrganizationsPage() {
 
   var doDelete = function(org) {
     if (!window.__showConfirm) return;
-    window.__showConfirm({ title: 'Delete Organization', message: 'Are you sure you want to delete "' + org.name + '"? This cannot be undone.' }).then(function(confirmed) {
+    window.__showConfirm({ title: 'Delete Organization', message: 'Are you sure you want to delete "' + org.name + '"? This cannot be undone.', danger: true, confirmText: 'Delete', requireText: org.name }).then(function(confirmed) {
       if (!confirmed) return;
       setActing('delete-' + org.id);
       apiCall('/organizations/' + org.id, { method: 'DELETE' })
@@ -0,0 +1,171 @@
+import { h, useState, useEffect, useCallback, Fragment, useApp, apiCall, showConfirm } from '../components/utils.js';
+import { I } from '../components/icons.js';
+import { HelpButton } from '../components/help-button.js';
+import { RelativeTime } from '../components/time.js';
+import { notifyPerformanceAlertsChanged } from '../components/notifications.js';
+
+var WINDOWS = [
+  { value: '15', label: 'Last 15 minutes' },
+  { value: '60', label: 'Last hour' },
+  { value: '360', label: 'Last 6 hours' },
+  { value: '1440', label: 'Last 24 hours' },
+];
+
+function fmtMs(ms) {
+  return ms >= 1000 ? (ms / 1000).toFixed(ms >= 10000 ? 0 : 1) + ' s' : ms + ' ms';
+}
+
+export function PerformancePage() {
+  var { toast, user } = useApp();
+  var [data, setData] = useState(null);
+  var [loading, setLoading] = useState(true);
+  var [error, setError] = useState(null);
+  var [windowMin, setWindowMin] = useState('15');
+  var [expanded, setExpanded] = useState({});
+
+  var load = useCallback(function() {
+    setLoading(true);
+    apiCall('/admin/performance?window=' + windowMin)
+      .then(function(d) { setData(d); setError(null); })
+      .catch(function(e) { setError(e.message); })
+      .finally(function() { setLoading(false); });
+  }, [windowMin]);
+
+  useEffect(function() { load(); }, [load]);
+
+  var acknowledge = function(a) {
+    apiCall('/admin/performance/alerts/' + a.id + '/acknowledge', { method: 'POST' })
+      .then(function() { notifyPerformanceAlertsChanged(); load(); })
+      .catch(function(e) { toast(e.message, 'error'); });
+  };
+
+  var clearAll = async function() {
+    var ok = await showConfirm({ title: 'Reset Performance Data', message: 'Discard all recorded render times, backend call timings and alerts? New page loads will continue to be recorded.', danger: true, confirmText: 'Reset' });
+    if (!ok) return;
+    apiCall('/admin/performance', { method: 'DELETE' })
+      .then(function() { notifyPerformanceAlertsChanged(); load(); toast('Performance data reset', 'success'); })
+      .catch(function(e) { toast(e.message, 'error'); });
+  };
+
+  var toggle = function(page) {
+    setExpanded(function(prev) { var next = Object.assign({}, prev); next[page] = !prev[page]; return next; });
+  };
+
+  var _h4 = { marginTop: 16, marginBottom: 8, fontSize: 14 };
+  var _ul = { paddingLeft: 20, margin: '4px 0 8px' };
+  var _mono = { fontFamily: 'var(--font-mono, monospace)', fontSize: 12 };
+  var pages = (data && data.pages) || [];
+  var alerts = (data && data.alerts) || [];
+  var openAlerts = alerts.filter(function(a) { return !a.resolvedAt; });
+
+  return h(Fragment, null,
+    h('div', { style: { display: 'flex', justifyContent: 'space-between', alignItems: 'center', marginBottom: 20 } },
+      h('div', null,
+        h('h1', { style: { fontSize: 20, fontWeight: 700, display: 'flex', alignItems: 'center' } }, 'Performance', h(HelpButton, { label: 'Performance' },
+          h('p', null, 'Shows how long each dashboard page takes to render for your users, measured in their browsers from navigation until the page\'s initial API calls finish.'),
+          h('h4', { style: _h4 }, 'Budgets'),
+          h('ul', { style: _ul },
+            h('li', null, 'Every page has a render budget for its 95th percentile (p95) — the time 95% of loads finish within.'),
+            h('li', null, 'The default budget is set with DASHBOARD_RENDER_BUDGET_MS; per-page budgets with DASHBOARD_RENDER_BUDGETS (e.g. "messages=5000").'),
+            h('li', null, 'A page that stays over budget for five minutes raises an alert in the notifications bell. The alert clears itself once the page is back under budget.')
+          ),
+          h('h4', { style: _h4 }, 'Finding the cause'),
+          h('p', null, 'Expand a page to see its slowest backend calls. The Call Log and your tracing backend have the individual requests.'),
+          h('p', null, 'Timings are kept in memory and reset on restart. Only admins can view them.')
+        )),
+        h('p', { style: { color: 'var(--text-muted)', fontSize: 13 } }, 'Dashboard render times against their budgets, worst offenders first')
+      ),
+      h('div', { style: { display: 'flex', gap: 8, alignItems: 'center' } },
+        h('select', { className: 'input', style: { width: 170, fontSize: 13 }, 'aria-label': 'Time window', value: windowMin, onChange: function(e) { setWindowMin(e.target.value); } },
+          WINDOWS.map(function(w) { return h('option', { key: w.value, value: w.value }, w.label); })
+        ),
+        h('button', { className: 'btn btn-secondary btn-sm', onClick: load }, I.refresh(), ' Refresh'),
+        user && user.role === 'owner' && h('button', { className: 'btn btn-ghost btn-sm', onClick: clearAll, title: 'Reset performance data', 'aria-label': 'Reset performance data' }, I.trash())
+      )
+    ),
+
+    openAlerts.length > 0 && h('div', { className: 'card', style: { marginBottom: 16, borderLeft: '3px solid var(--danger)' } },
+      h('div', { className: 'card-header' }, h('h3', null, 'Over budget')),
+      h('div', { className: 'card-body-flush' },
+        h('table', null,
+          h('thead', null, h('tr', null, h('th', null, 'Page'), h('th', null, 'p95'), h('th', null, 'Budget'), h('th', null, 'Over budget since'), h('th', null, ''))),
+          h('tbody', null, openAlerts.map(function(a) {
+            return h('tr', { key: a.id },
+              h('td', { style: _mono }, a.page),
+              h('td', { style: { color: 'var(--danger)', fontWeight: 600 } }, fmtMs(a.p95Ms)),
+              h('td', null, fmtMs(a.budgetMs)),
+              h('td', null, h(RelativeTime, { value: a.breachingSince })),
+              h('td', { style: { textAlign: 'right' } },
+                a.acknowledgedBy
+                  ? h('span', { className: 'badge badge-neutral' }, 'Acknowledged')
+                  : h('button', { className: 'btn btn-ghost btn-sm', onClick: function() { acknowledge(a); } }, 'Acknowledge')
+              )
+            );
+          }))
+        )
+      )
+    ),
+
+    h('div', { className: 'card' },
+      h('div', { className: 'card-body-flush' },
+        loading && !data ? h('div', { style: { padding: 24, textAlign: 'center', color: 'var(--text-muted)' } }, 'Loading...')
+        : error ? h('div', { style: { padding: 24, textAlign: 'center', color: 'var(--danger)' } }, error)
+        : pages.length === 0 ? h('div', { style: { padding: 24, textAlign: 'center', color: 'var(--text-muted)' } }, 'No page loads recorded in this window')
+        : h('table', null,
+            h('thead', null, h('tr', null,
+              h('th', { style: { width: 24 } }),
+              h('th', null, 'Page'),
+              h('th', null, 'Loads'),
+              h('th', null, 'p50'),
+              h('th', null, 'p95'),
+              h('th', null, 'Max'),
+              h('th', null, 'Budget'),
+              h('th', null, 'Status')
+            )),
+            h('tbody', null, pages.map(function(p) {
+              var open = !!expanded[p.page];
+              return h(Fragment, { key: p.page },
+                h('tr', { style: { cursor: 'pointer' }, tabIndex: 0, 'aria-expanded': open, onClick: function() { toggle(p.page); }, onKeyDown: function(e) { if (e.key === 'Enter' || e.key === ' ') { e.preventDefault(); toggle(p.page); } } },
+                  h('td', { style: { color: 'var(--text-muted)' } }, open ? '▾' : '▸'),
+                  h('td', { style: _mono }, p.page),
+                  h('td', null, p.samples),
+                  h('td', null, fmtMs(p.p50Ms)),
+                  h('td', { style: { fontWeight: 600, color: p.overBudget ? 'var(--danger)' : undefined } }, fmtMs(p.p95Ms)),
+                  h('td', { style: { color: 'var(--text-muted)' } }, fmtMs(p.maxMs)),
+                  h('td', null, fmtMs(p.budgetMs)),
+                  h('td', null,
+                    p.overBudget
+                      ? h('span', { className: 'badge badge-danger' }, 'Over budget')
+                      : h('span', { className: 'badge badge-success' }, 'Within budget'),
+                    p.breachingSince && h('span', { style: { fontSize: 11, color: 'var(--text-muted)', marginLeft: 6 } }, 'since ', h(RelativeTime, { value: p.breachingSince }))
+                  )
+                ),
+                open && h('tr', null,
+                  h('td', { colSpan: 8, style: { background: 'var(--bg-secondary)', padding: '8px 16px' } },
+                    p.slowestCalls.length === 0
+                      ? h('div', { style: { fontSize: 12, color: 'var(--text-muted)' } }, 'No backend calls recorded for this page')
+                      : h('table', { style: { width: '100%' } },
+                          h('thead', null, h('tr', null, h('th', null, 'Slowest backend calls'), h('th', null, 'Calls'), h('th', null, 'p95'), h('th', null, 'Max'), h('th', null, 'Errors'))),
+                          h('tbody', null, p.slowestCalls.map(function(call) {
+                            return h('tr', { key: call.method + ' ' + call.route },
+                              h('td', { style: _mono }, h('strong', null, call.method), ' ', call.route),
+                              h('td', null, call.count),
+                              h('td', null, fmtMs(call.p95Ms)),
+                              h('td', { style: { color: 'var(--text-muted)' } }, fmtMs(call.maxMs)),
+                              h('td', null, call.errors > 0 ? h('span', { className: 'badge badge-danger' }, call.errors) : '0')
+                            );
+                          }))
+                        )
+                  )
+                )
+              );
+            }))
+          )
+      )
+    ),
+    data && h('div', { style: { marginTop: 8, fontSize: 12, color: 'var(--text-muted)' } },
+      'Default budget ' + fmtMs(data.budgets.defaultMs) +
+      (Object.keys(data.budgets.pages).length ? ' · ' + Object.keys(data.budgets.pages).map(function(k) { return k + ' ' + fmtMs(data.budgets.pages[k]); }).join(', ') : '')
+    )
+  );
+}
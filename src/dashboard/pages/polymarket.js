@@ -1,4 +1,4 @@
-import { h, useState, useEffect, Fragment, useApp, apiCall, engineCall, showConfirm, getOrgId } from '../components/utils.js';
+import { h, useState, useEffect, Fragment, useApp, apiCall, batchGet, engineCall, showConfirm, getOrgId } from '../components/utils.js';
 import { I as Icons } from '../components/icons.js';
 var iconMap = { 'trending-up': 'activity', 'refresh-cw': 'refresh', 'play': 'play', 'pause': 'pause', 'check': 'check', 'x': 'x', 'edit': 'settings', 'link': 'link', 'message-circle': 'messages', 'calendar': 'calendar', 'trash-2': 'trash', 'zap': 'warning', 'git-branch': 'link', 'shuffle': 'refresh', 'activity': 'activity', 'crosshair': 'search', 'layers': 'folder', 'shield': 'shield', 'log-out': 'logout', 'pie-chart': 'dashboard', 'trending-down': 'activity', 'brain': 'brain', 'key': 'key', 'globe': 'globe', 'eye': 'eye', 'chart': 'chart', 'clock': 'clock', 'database': 'database' };
 function I(name) { var k = iconMap[name] || name; var fn = Icons[k]; return fn ? fn() : ''; }
@@ -756,9 +756,9 @@ export function PolymarketPage() {
       // Load P&L timeline for ALL poly agents (trades + paper positions)
       var allTimelines = await Promise.all(agents.map(function(ag) {
         return Promise.all([
-          apiCall('/polymarket/' + ag.id + '/trades').catch(function() { return { trades: [] }; }),
-          apiCall('/polymarket/' + ag.id + '/paper').catch(function() { return { positions: [] }; }),
-          apiCall('/polymarket/' + ag.id + '/live-positions').catch(function() { return { positions: [] }; })
+          batchGet('/polymarket/' + ag.id + '/trades').catch(function() { return { trades: [] }; }),
+          batchGet('/polymarket/' + ag.id + '/paper').catch(function() { return { positions: [] }; }),
+          batchGet('/polymarket/' + ag.id + '/live-positions').catch(function() { return { positions: [] }; })
         ]).then(function(results) {
           var trades = results[0].trades || [];
           var papers = results[1].positions || [];
@@ -0,0 +1,232 @@
+import { h, useState, useEffect, Fragment, useApp, apiCall, engineCall, getOrgId, buildAgentDataMap, renderAgentBadge } from '../components/utils.js';
+import { I } from '../components/icons.js';
+import { HelpButton } from '../components/help-button.js';
+import { useOrgContext } from '../components/org-switcher.js';
+import { MarkdownView } from '../components/markdown.js';
+import { Sparkline } from '../components/charts.js';
+
+// Score colour: 1–2 red, 3 amber, 4–5 green
+function scoreColor(v) {
+  if (v == null) return 'var(--text-muted)';
+  return v >= 4 ? 'var(--success)' : v >= 3 ? 'var(--warning)' : 'var(--danger)';
+}
+
+export function QualityScore(props) {
+  var v = props.value;
+  return h('span', { style: { fontWeight: 700, color: scoreColor(v), fontVariantNumeric: 'tabular-nums' } }, v == null ? '—' : Number(v).toFixed(2));
+}
+
+/** Tiny inline sparkline of weekly averages (1–5 scale). */
+export function QualitySparkline(props) {
+  var pts = props.trend || [];
+  if (pts.length < 2) return null;
+  return h(Sparkline, {
+    values: pts.map(function(p) { return p.average; }), min: 1, max: 5,
+    width: props.width || 80, height: props.height || 20, color: scoreColor(pts[pts.length - 1].average),
+    title: 'Weekly average: ' + pts.map(function(p) { return p.average.toFixed(1); }).join(' → '),
+  });
+}
+
+export function QAPage() {
+  var orgCtx = useOrgContext();
+  var effectiveOrgId = orgCtx.selectedOrgId || getOrgId();
+  const { toast } = useApp();
+  const [tab, setTab] = useState('queue');
+  const [agents, setAgents] = useState([]);
+  const [settings, setSettings] = useState(null);
+  const [queue, setQueue] = useState({ reviews: [], total: 0 });
+  const [status, setStatus] = useState('pending');
+  const [filterAgent, setFilterAgent] = useState('');
+  const [summary, setSummary] = useState([]);
+  const [days, setDays] = useState(90);
+  const [grading, setGrading] = useState(null); // { review, scores, comment }
+  const [draftSettings, setDraftSettings] = useState(null);
+
+  const agentData = buildAgentDataMap(agents);
+  const qs = (extra) => '?orgId=' + encodeURIComponent(effectiveOrgId) + (filterAgent ? '&agentId=' + encodeURIComponent(filterAgent) : '') + (extra || '');
+
+  const loadQueue = () => engineCall('/qa/queue' + qs('&status=' + status)).then(d => setQueue({ reviews: d.reviews || [], total: d.total || 0 })).catch(() => {});
+  const loadSummary = () => engineCall('/qa/summary' + qs('&days=' + days)).then(d => setSummary(d.agents || [])).catch(() => {});
+  const loadSettings = () => engineCall('/qa/settings?orgId=' + encodeURIComponent(effectiveOrgId)).then(d => { setSettings(d.settings); setDraftSettings(d.settings); }).catch(() => {});
+
+  useEffect(() => {
+    apiCall('/agents' + (orgCtx.selectedOrgId ? '?clientOrgId=' + orgCtx.selectedOrgId : '')).then(d => setAgents(d.agents || [])).catch(() => {});
+    loadSettings();
+  }, [effectiveOrgId]);
+  useEffect(() => { loadQueue(); }, [effectiveOrgId, status, filterAgent]);
+  useEffect(() => { loadSummary(); }, [effectiveOrgId, filterAgent, days]);
+
+  const startGrading = (review) => setGrading({ review, scores: {}, comment: '' });
+  const submitGrade = async () => {
+    try {
+      await engineCall('/qa/queue/' + grading.review.id + '/grade', { method: 'POST', body: JSON.stringify({ orgId: effectiveOrgId, scores: grading.scores, comment: grading.comment }) });
+      toast('Review saved', 'success');
+      setGrading(null); loadQueue(); loadSummary();
+    } catch (e) { toast(e.message, 'error'); }
+  };
+  const skip = async (review) => {
+    try {
+      await engineCall('/qa/queue/' + review.id + '/skip', { method: 'POST', body: JSON.stringify({ orgId: effectiveOrgId }) });
+      if (grading && grading.review.id === review.id) setGrading(null);
+      loadQueue();
+    } catch (e) { toast(e.message, 'error'); }
+  };
+  const saveSettings = async () => {
+    try {
+      const d = await engineCall('/qa/settings', { method: 'PUT', body: JSON.stringify({ orgId: effectiveOrgId, sampleRate: draftSettings.sampleRate, rubric: draftSettings.rubric }) });
+      setSettings(d.settings); setDraftSettings(d.settings);
+      toast('QA settings saved', 'success');
+    } catch (e) { toast(e.message, 'error'); }
+  };
+  const exportReport = () => {
+    window.open('/api/engine/qa/export' + qs('&days=' + days), '_blank');
+  };
+
+  const rubric = (settings && settings.rubric) || [];
+  const allScored = grading && rubric.every(c => grading.scores[c.id]);
+  const totals = summary.reduce((acc, a) => ({ graded: acc.graded + a.graded, pending: acc.pending + a.pending, sum: acc.sum + (a.average || 0) * a.graded }), { graded: 0, pending: 0, sum: 0 });
+
+  var _h4 = { marginTop: 16, marginBottom: 8, fontSize: 14 };
+  var _ul = { paddingLeft: 20, margin: '4px 0 8px' };
+
+  const setCriterion = (i, patch) => setDraftSettings({ ...draftSettings, rubric: draftSettings.rubric.map((c, j) => j === i ? { ...c, ...patch } : c) });
+
+  return h('div', { className: 'page-inner' },
+    h(orgCtx.Switcher),
+    h('div', { className: 'page-header' },
+      h('h1', { style: { display: 'flex', alignItems: 'center' } }, 'Quality Review', h(HelpButton, { label: 'Quality Review' },
+        h('p', null, 'A sample of real agent replies is queued here for human grading. Scores roll up into per-agent quality trends on each agent\'s overview.'),
+        h('h4', { style: _h4 }, 'How it works'),
+        h('ul', { style: _ul },
+          h('li', null, h('strong', null, 'Sampling'), ' — A share of agent replies (set in Settings) is queued automatically. You can also queue any message by hand.'),
+          h('li', null, h('strong', null, 'Rubric'), ' — Each reply is scored 1–5 per criterion; the overall score is the weighted average.'),
+          h('li', null, h('strong', null, 'Reports'), ' — Export graded reviews as CSV for audits or coaching.')
+        )
+      )),
+      h('button', { className: 'btn btn-secondary', onClick: exportReport }, I.download(), ' Export QA Report')
+    ),
+
+    h('div', { className: 'stat-grid', style: { marginBottom: 16 } },
+      h('div', { className: 'stat-card' }, h('div', { className: 'stat-value' }, totals.pending), h('div', { className: 'stat-label' }, 'Awaiting review')),
+      h('div', { className: 'stat-card' }, h('div', { className: 'stat-value' }, totals.graded), h('div', { className: 'stat-label' }, 'Graded (' + days + 'd)')),
+      h('div', { className: 'stat-card' }, h('div', { className: 'stat-value' }, h(QualityScore, { value: totals.graded ? totals.sum / totals.graded : null })), h('div', { className: 'stat-label' }, 'Average score')),
+      h('div', { className: 'stat-card' }, h('div', { className: 'stat-value' }, settings ? Math.round(settings.sampleRate * 100) + '%' : '—'), h('div', { className: 'stat-label' }, 'Sample rate'))
+    ),
+
+    h('div', { className: 'tabs', style: { marginBottom: 16 } },
+      ['queue', 'trends', 'settings'].map(t => h('button', { key: t, className: 'tab' + (tab === t ? ' active' : ''), onClick: () => setTab(t) }, t === 'queue' ? 'Review Queue' : t === 'trends' ? 'Agent Trends' : 'Settings'))
+    ),
+
+    tab !== 'settings' && h('div', { style: { display: 'flex', gap: 8, marginBottom: 12 } },
+      h('select', { className: 'input', style: { width: 220 }, value: filterAgent, onChange: e => setFilterAgent(e.target.value) },
+        h('option', { value: '' }, 'All agents'),
+        agents.map(a => h('option', { key: a.id, value: a.id }, a.config?.displayName || a.config?.name || a.name || a.id))
+      ),
+      tab === 'queue' && h('select', { className: 'input', style: { width: 160 }, value: status, onChange: e => setStatus(e.target.value) },
+        h('option', { value: 'pending' }, 'Pending'), h('option', { value: 'graded' }, 'Graded'), h('option', { value: 'skipped' }, 'Skipped')
+      ),
+      tab === 'trends' && h('select', { className: 'input', style: { width: 160 }, value: days, onChange: e => setDays(parseInt(e.target.value)) },
+        h('option', { value: 30 }, 'Last 30 days'), h('option', { value: 90 }, 'Last 90 days'), h('option', { value: 180 }, 'Last 180 days')
+      )
+    ),
+
+    // ─── Queue ───────────────────────────────────────
+    tab === 'queue' && h('div', { className: 'card' },
+      h('table', { className: 'data-table' },
+        h('thead', null, h('tr', null, h('th', null, 'Agent'), h('th', null, 'To'), h('th', null, 'Subject'), h('th', null, 'Queued'), h('th', null, status === 'graded' ? 'Score' : 'Source'), h('th', null, ''))),
+        h('tbody', null, queue.reviews.length === 0
+          ? h('tr', null, h('td', { colSpan: 6, style: { textAlign: 'center', color: 'var(--text-muted)', padding: 40 } }, status === 'pending' ? 'Nothing to review' : 'No reviews'))
+          : queue.reviews.map(r => h('tr', { key: r.id },
+              h('td', null, renderAgentBadge(r.agentId, agentData)),
+              h('td', null, r.message ? renderAgentBadge(r.message.toAgentId, agentData) : '—'),
+              h('td', null, r.message ? r.message.subject || '(no subject)' : h('span', { style: { color: 'var(--text-muted)' } }, 'Message no longer available')),
+              h('td', null, new Date(r.queuedAt).toLocaleString()),
+              h('td', null, status === 'graded' ? h(QualityScore, { value: r.overall }) : h('span', { className: 'badge' }, r.source === 'manual' ? 'Manual' : 'Sample')),
+              h('td', { style: { textAlign: 'right', whiteSpace: 'nowrap' } },
+                status === 'pending' && h(Fragment, null,
+                  h('button', { className: 'btn btn-primary btn-sm', disabled: !r.message, onClick: () => startGrading(r) }, 'Grade'),
+                  h('button', { className: 'btn btn-ghost btn-sm', onClick: () => skip(r) }, 'Skip')
+                ),
+                status === 'graded' && r.comment && h('span', { title: r.comment, style: { color: 'var(--text-muted)', fontSize: 12 } }, 'Comment')
+              )
+            ))
+        )
+      )
+    ),
+
+    // ─── Trends ──────────────────────────────────────
+    tab === 'trends' && h('div', { className: 'card' },
+      h('table', { className: 'data-table' },
+        h('thead', null, h('tr', null, h('th', null, 'Agent'), h('th', null, 'Average'), h('th', null, 'Weekly trend'), h('th', null, 'Change'), h('th', null, 'Graded'), h('th', null, 'Pending'))),
+        h('tbody', null, summary.length === 0
+          ? h('tr', null, h('td', { colSpan: 6, style: { textAlign: 'center', color: 'var(--text-muted)', padding: 40 } }, 'No graded reviews in this period'))
+          : summary.map(a => h('tr', { key: a.agentId },
+              h('td', null, renderAgentBadge(a.agentId, agentData)),
+              h('td', null, h(QualityScore, { value: a.average })),
+              h('td', null, h(QualitySparkline, { trend: a.trend, width: 120 })),
+              h('td', { style: { color: a.delta == null ? 'var(--text-muted)' : a.delta >= 0 ? 'var(--success)' : 'var(--danger)' } }, a.delta == null ? '—' : (a.delta >= 0 ? '+' : '') + a.delta.toFixed(2)),
+              h('td', null, a.graded),
+              h('td', null, a.pending)
+            ))
+        )
+      )
+    ),
+
+    // ─── Settings ────────────────────────────────────
+    tab === 'settings' && draftSettings && h('div', { className: 'card' },
+      h('div', { className: 'card-body' },
+        h('label', { className: 'field-label' }, 'Sample rate — share of agent replies queued for review'),
+        h('div', { style: { display: 'flex', alignItems: 'center', gap: 12, marginBottom: 16 } },
+          h('input', { type: 'range', min: 0, max: 100, step: 1, value: Math.round(draftSettings.sampleRate * 100), onChange: e => setDraftSettings({ ...draftSettings, sampleRate: parseInt(e.target.value) / 100 }), style: { flex: 1, maxWidth: 320 } }),
+          h('strong', null, Math.round(draftSettings.sampleRate * 100) + '%')
+        ),
+        h('label', { className: 'field-label' }, 'Rubric'),
+        h('p', { className: 'form-help', style: { marginBottom: 8 } }, 'Each criterion is scored 1–5. Weights control how much each counts toward the overall score.'),
+        draftSettings.rubric.map((c, i) => h('div', { key: i, style: { display: 'flex', gap: 6, marginBottom: 6 } },
+          h('input', { className: 'input', style: { width: 140, fontFamily: 'var(--font-mono)' }, placeholder: 'id', value: c.id, onChange: e => setCriterion(i, { id: e.target.value.toLowerCase() }) }),
+          h('input', { className: 'input', style: { width: 180 }, placeholder: 'Label', value: c.label, onChange: e => setCriterion(i, { label: e.target.value }) }),
+          h('input', { className: 'input', style: { flex: 1 }, placeholder: 'Description', value: c.description || '', onChange: e => setCriterion(i, { description: e.target.value }) }),
+          h('input', { className: 'input', type: 'number', min: 0.5, max: 10, step: 0.5, style: { width: 80 }, title: 'Weight', value: c.weight, onChange: e => setCriterion(i, { weight: parseFloat(e.target.value) || 1 }) }),
+          h('button', { className: 'btn btn-ghost btn-icon', disabled: draftSettings.rubric.length <= 1, onClick: () => setDraftSettings({ ...draftSettings, rubric: draftSettings.rubric.filter((_, j) => j !== i) }) }, I.x())
+        )),
+        h('button', { className: 'btn btn-ghost btn-sm', disabled: draftSettings.rubric.length >= 10, onClick: () => setDraftSettings({ ...draftSettings, rubric: draftSettings.rubric.concat({ id: '', label: '', weight: 1 }) }) }, I.plus(), ' Add criterion'),
+        h('div', { style: { marginTop: 16, display: 'flex', gap: 8 } },
+          h('button', { className: 'btn btn-primary', onClick: saveSettings }, 'Save'),
+          h('button', { className: 'btn btn-ghost', onClick: () => setDraftSettings(settings) }, 'Reset')
+        )
+      )
+    ),
+
+    // ─── Grading modal ───────────────────────────────
+    grading && h('div', { className: 'modal-overlay', onClick: () => setGrading(null) },
+      h('div', { className: 'modal', style: { maxWidth: 760 }, onClick: e => e.stopPropagation() },
+        h('div', { className: 'modal-header' }, h('h2', null, 'Grade reply'), h('button', { className: 'btn btn-ghost btn-icon', onClick: () => setGrading(null) }, I.x())),
+        h('div', { className: 'modal-body' },
+          h('div', { style: { display: 'flex', gap: 12, alignItems: 'center', fontSize: 13, color: 'var(--text-muted)', marginBottom: 8 } },
+            h('span', null, 'From ', renderAgentBadge(grading.review.agentId, agentData)),
+            h('span', null, 'To ', renderAgentBadge(grading.review.message.toAgentId, agentData)),
+            h('span', null, new Date(grading.review.message.createdAt).toLocaleString())
+          ),
+          h('div', { style: { fontWeight: 600, marginBottom: 8 } }, grading.review.message.subject || '(no subject)'),
+          h('div', { style: { maxHeight: 260, overflowY: 'auto', padding: 12, background: 'var(--bg-secondary)', borderRadius: 'var(--radius)', marginBottom: 16 } },
+            h(MarkdownView, { source: grading.review.message.content, markdown: grading.review.message.metadata?.format === 'markdown' })
+          ),
+          rubric.map(c => h('div', { key: c.id, style: { display: 'flex', alignItems: 'center', gap: 12, marginBottom: 8 } },
+            h('div', { style: { flex: 1 } }, h('div', { style: { fontWeight: 600, fontSize: 13 } }, c.label), c.description && h('div', { style: { fontSize: 12, color: 'var(--text-muted)' } }, c.description)),
+            h('div', { role: 'radiogroup', 'aria-label': c.label, style: { display: 'flex', gap: 4 } }, [1, 2, 3, 4, 5].map(v =>
+              h('button', { key: v, type: 'button', role: 'radio', 'aria-checked': grading.scores[c.id] === v,
+                className: 'btn btn-sm ' + (grading.scores[c.id] === v ? 'btn-primary' : 'btn-ghost'), style: { width: 32 },
+                onClick: () => setGrading({ ...grading, scores: { ...grading.scores, [c.id]: v } }) }, v)
+            ))
+          )),
+          h('label', { className: 'field-label' }, 'Comment'),
+          h('textarea', { className: 'input', rows: 3, value: grading.comment, placeholder: 'What was good, what should change?', onChange: e => setGrading({ ...grading, comment: e.target.value }) })
+        ),
+        h('div', { className: 'modal-footer' },
+          h('button', { className: 'btn btn-ghost', onClick: () => skip(grading.review) }, 'Skip'),
+          h('button', { className: 'btn btn-primary', disabled: !allScored, onClick: submitGrade }, 'Save grade')
+        )
+      )
+    )
+  );
+}
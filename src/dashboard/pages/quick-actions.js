@@ -0,0 +1,142 @@
+import { h, useState, Fragment, apiCall, engineCall } from '../components/utils.js';
+import { I } from '../components/icons.js';
+import { Modal } from '../components/modal.js';
+import { CreateAgentWizard } from './agents.js';
+import { usePasswordPolicy, PasswordRules, passwordOk, generatePassword } from '../components/password-rules.js';
+
+// ─── Quick Actions ──────────────────────────────────────
+// The dashboard's shortcuts for the most common admin tasks. Each opens a
+// short form in a modal and posts to the same endpoint as the full page;
+// the full pages stay the place for anything beyond the basics.
+
+function InviteUserModal({ onClose, onDone, toast }) {
+  var policy = usePasswordPolicy();
+  var [form, setForm] = useState(function() { return { name: '', email: '', role: 'member', password: generatePassword(policy) }; });
+  var [saving, setSaving] = useState(false);
+  var set = function(k) { return function(e) { var v = e.target.value; setForm(function(f) { return Object.assign({}, f, { [k]: v }); }); }; };
+
+  var submit = async function() {
+    setSaving(true);
+    try {
+      await apiCall('/users', { method: 'POST', body: JSON.stringify({ name: form.name || form.email.split('@')[0], email: form.email, role: form.role, password: form.password }) });
+      toast('User created. They will be prompted to set a new password on first login.', 'success');
+      onDone('users');
+      onClose();
+    } catch (e) { toast(e.message, 'error'); }
+    setSaving(false);
+  };
+
+  return h(Modal, {
+    title: 'Invite User', onClose: onClose, width: 480,
+    footer: h(Fragment, null,
+      h('button', { className: 'btn btn-secondary', onClick: onClose }, 'Cancel'),
+      h('button', { className: 'btn btn-primary', onClick: submit, disabled: saving || !form.email || !passwordOk(policy, form.password) }, saving ? 'Creating...' : 'Create User'))
+  },
+    h('div', { className: 'form-group' }, h('label', { className: 'form-label' }, 'Email *'), h('input', { className: 'input', type: 'email', value: form.email, onInput: set('email'), autoFocus: true })),
+    h('div', { className: 'form-group' }, h('label', { className: 'form-label' }, 'Name'), h('input', { className: 'input', value: form.name, placeholder: 'Defaults to the start of the email', onInput: set('name') })),
+    h('div', { className: 'form-group' }, h('label', { className: 'form-label' }, 'Role'),
+      h('select', { className: 'input', value: form.role, onChange: set('role') },
+        h('option', { value: 'viewer' }, 'Viewer'), h('option', { value: 'member' }, 'Member'), h('option', { value: 'admin' }, 'Admin'))),
+    h('div', { className: 'form-group' },
+      h('label', { className: 'form-label' }, 'Initial Password *'),
+      h('div', { style: { display: 'flex', gap: 8 } },
+        h('input', { className: 'input', type: 'text', value: form.password, onInput: set('password'), style: { flex: 1, fontFamily: 'var(--font-mono)', fontSize: 13 } }),
+        h('button', { type: 'button', className: 'btn btn-secondary btn-sm', title: 'Generate random password', onClick: function() { setForm(Object.assign({}, form, { password: generatePassword(policy) })); } }, I.refresh())),
+      h(PasswordRules, { policy: policy, password: form.password }),
+      h('div', { style: { fontSize: 11, color: 'var(--text-muted)', marginTop: 4 } }, 'They change it on first sign-in. Share it securely. Page permissions and client organizations can be set on the Users page.'))
+  );
+}
+
+function AddSecretModal({ orgId, onClose, onDone, toast }) {
+  var [form, setForm] = useState({ name: '', value: '' });
+  var [saving, setSaving] = useState(false);
+
+  var submit = async function() {
+    setSaving(true);
+    try {
+      await engineCall('/vault/secrets', { method: 'POST', body: JSON.stringify({ orgId: orgId, name: form.name.trim(), value: form.value, category: 'custom' }) });
+      toast('Secret stored securely', 'success');
+      onDone('vault');
+      onClose();
+    } catch (e) { toast(e.message || 'Failed to store secret', 'error'); }
+    setSaving(false);
+  };
+
+  return h(Modal, {
+    title: 'Add Secret', onClose: onClose, width: 480,
+    footer: h(Fragment, null,
+      h('button', { className: 'btn btn-secondary', onClick: onClose }, 'Cancel'),
+      h('button', { className: 'btn btn-primary', onClick: submit, disabled: saving || !form.name.trim() || !form.value }, saving ? 'Saving...' : 'Store Secret'))
+  },
+    h('div', { className: 'form-group' }, h('label', { className: 'form-label' }, 'Secret Name *'),
+      h('input', { className: 'input', placeholder: 'e.g., MY_API_KEY', value: form.name, autoFocus: true, onInput: function(e) { setForm(Object.assign({}, form, { name: e.target.value })); } })),
+    h('div', { className: 'form-group' }, h('label', { className: 'form-label' }, 'Value *'),
+      h('input', { className: 'input', type: 'password', autoComplete: 'off', value: form.value, onInput: function(e) { setForm(Object.assign({}, form, { value: e.target.value })); } })),
+    h('div', { style: { fontSize: 11, color: 'var(--text-muted)' } }, 'Stored encrypted as a custom secret. Use the Vault page for platform credentials that agent tools pick up automatically.')
+  );
+}
+
+function DlpScanModal({ orgId, onClose, toast }) {
+  var [content, setContent] = useState('');
+  var [result, setResult] = useState(null);
+  var [scanning, setScanning] = useState(false);
+
+  var scan = async function() {
+    setScanning(true);
+    try { setResult(await engineCall('/dlp/scan', { method: 'POST', body: JSON.stringify({ orgId: orgId, content: content }) })); }
+    catch (e) { toast(e.message, 'error'); }
+    setScanning(false);
+  };
+
+  var matches = result ? result.matches || [] : [];
+  return h(Modal, {
+    title: 'Run DLP Scan', onClose: onClose, width: 560,
+    footer: h(Fragment, null,
+      h('button', { className: 'btn btn-secondary', onClick: onClose }, 'Close'),
+      h('button', { className: 'btn btn-primary', onClick: scan, disabled: scanning || !content.trim() }, scanning ? 'Scanning...' : 'Run Scan'))
+  },
+    h('textarea', { className: 'input', style: { minHeight: 120, width: '100%' }, autoFocus: true, placeholder: 'Paste content to test against the organization\'s DLP rules...', value: content, onInput: function(e) { setContent(e.target.value); setResult(null); } }),
+    result && h('div', { style: { marginTop: 12, fontSize: 13 } },
+      matches.length === 0
+        ? h('div', { style: { color: 'var(--success)' } }, 'No rules matched')
+        : h(Fragment, null,
+            h('strong', null, matches.length + (matches.length === 1 ? ' rule matched' : ' rules matched')),
+            matches.map(function(m) {
+              return h('div', { key: m.ruleId, style: { display: 'flex', justifyContent: 'space-between', padding: '6px 8px', background: 'var(--bg-tertiary)', borderRadius: 6, marginTop: 6 } },
+                h('span', null, m.ruleName), h('span', { style: { color: 'var(--text-muted)' } }, m.matchCount + (m.matchCount === 1 ? ' match' : ' matches')));
+            }))
+    )
+  );
+}
+
+/**
+ * QuickActions({ orgId, can, toast, onDone })
+ * `can(page)` says whether the user may use that page's action; `onDone(page)`
+ * runs after something was created so the dashboard can refresh.
+ */
+export function QuickActions({ orgId, can, toast, onDone }) {
+  var [open, setOpen] = useState(null);
+  var close = function() { setOpen(null); };
+  var actions = [
+    { id: 'agent', page: 'agents', icon: I.agents, label: 'Create agent', hint: 'Role, skills and deployment' },
+    { id: 'user', page: 'users', icon: I.users, label: 'Invite user', hint: 'Dashboard access with a starter password' },
+    { id: 'secret', page: 'vault', icon: I.lock, label: 'Add secret', hint: 'Encrypted in the vault' },
+    { id: 'dlp', page: 'dlp', icon: I.dlp, label: 'Run DLP scan', hint: 'Test content against your rules' },
+  ].filter(function(a) { return can(a.page); });
+  if (actions.length === 0) return null;
+
+  return h('div', { className: 'card' },
+    h('div', { className: 'card-header' }, h('h3', null, 'Quick Actions')),
+    h('div', { className: 'card-body', style: { display: 'grid', gridTemplateColumns: 'repeat(auto-fit, minmax(200px, 1fr))', gap: 12 } },
+      actions.map(function(a) {
+        return h('button', { key: a.id, className: 'btn btn-secondary', style: { display: 'flex', alignItems: 'center', gap: 10, padding: '12px 14px', textAlign: 'left', height: 'auto' }, onClick: function() { setOpen(a.id); } },
+          a.icon(),
+          h('span', null, h('div', { style: { fontWeight: 600 } }, a.label), h('div', { style: { fontSize: 11, color: 'var(--text-muted)', fontWeight: 400 } }, a.hint)));
+      })
+    ),
+    open === 'agent' && h(CreateAgentWizard, { onClose: close, onCreated: function() { onDone('agents'); }, toast: toast }),
+    open === 'user' && h(InviteUserModal, { onClose: close, onDone: onDone, toast: toast }),
+    open === 'secret' && h(AddSecretModal, { orgId: orgId, onClose: close, onDone: onDone, toast: toast }),
+    open === 'dlp' && h(DlpScanModal, { orgId: orgId, onClose: close, toast: toast })
+  );
+}
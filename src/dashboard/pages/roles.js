@@ -8,6 +8,7 @@ import { LanguageSelect, TagPicker } from '../components/persona-fields.js';
 var LANGUAGE_NAMES = { 'en-us': 'English (US)', 'en-gb': 'English (UK)', 'es': 'Spanish', 'fr': 'French', 'de': 'German', 'pt': 'Portuguese', 'it': 'Italian', 'nl': 'Dutch', 'ja': 'Japanese', 'ko': 'Korean', 'zh': 'Chinese', 'ar': 'Arabic', 'hi': 'Hindi', 'ru': 'Russian', 'tr': 'Turkish', 'pl': 'Polish', 'sv': 'Swedish', 'da': 'Danish', 'no': 'Norwegian', 'fi': 'Finnish' };
 function getLanguageName(code) { return LANGUAGE_NAMES[code] || code; }
 import { useOrgContext } from '../components/org-switcher.js';
+import { ClassificationChip, ClassificationSelect, useClassifications } from '../components/classification.js';
 
 var engineCall = function(path, opts) { return apiCall('/engine' + path, opts); };
 
@@ -413,7 +414,7 @@ function RoleFormModal({ role, allSkills, skillsLoaded, orgId, onSave, onClose }
 
 // ─── Role Card ─────────────────────────────────────
 
-function RoleCard({ role, onEdit, onDuplicate, onDelete, onView }) {
+function RoleCard({ role, classification, onEdit, onDuplicate, onDelete, onView }) {
   var isBuiltIn = !role.isCustom;
   var skills = role.suggestedSkills || [];
   var tags = role.tags || [];
@@ -432,6 +433,7 @@ function RoleCard({ role, onEdit, onDuplicate, onDelete, onView }) {
         h('div', { style: { display: 'flex', alignItems: 'center', gap: 6 } },
           h('strong', { style: { fontSize: 14 } }, role.name),
           isBuiltIn && h('span', { className: 'badge badge-neutral', style: { fontSize: 9 } }, 'Built-in'),
+          h(ClassificationChip, { level: classification }),
           role.orgId && h('span', { style: { fontSize: 9, padding: '1px 6px', borderRadius: 8, background: 'var(--info-soft)', color: 'var(--info)', display: 'inline-flex', alignItems: 'center', gap: 3 } }, I.building(), 'Org'),
         ),
         h('div', { style: { fontSize: 12, color: 'var(--text-muted)', marginTop: 1, whiteSpace: 'nowrap', overflow: 'hidden', textOverflow: 'ellipsis' } }, role.description || 'No description')
@@ -464,6 +466,7 @@ export function RolesPage() {
   var toast = app.toast;
   var orgCtx = useOrgContext();
   var effectiveOrgId = orgCtx.selectedOrgId;
+  var classifications = useClassifications('template');
 
   var [builtInRoles, setBuiltInRoles] = useState([]);
   var [builtInMeta, setBuiltInMeta] = useState({});
@@ -674,6 +677,7 @@ export function RolesPage() {
                   return h(RoleCard, {
                     key: role.id || role.slug,
                     role: role,
+                    classification: classifications.get(role.id || role.slug),
                     onView: function() { setPreviewRole(role); },
                     onEdit: function() { setEditRole(role); },
                     onDuplicate: function() { handleDuplicate(role); },
@@ -690,6 +694,12 @@ export function RolesPage() {
       onClose: function() { setPreviewRole(null); },
       width: 1100,
       footer: h(Fragment, null,
+        h('div', { style: { display: 'flex', alignItems: 'center', gap: 8, marginRight: 'auto' } },
+          h('label', { style: { fontSize: 12, fontWeight: 600 } }, 'Classification'),
+          h(ClassificationSelect, { value: classifications.get(previewRole.id || previewRole.slug), style: { fontSize: 12 }, onChange: function(level) {
+            classifications.set(previewRole.id || previewRole.slug, level).then(function() { toast('Classification updated', 'success'); }).catch(function(e) { toast(e.message, 'error'); });
+          } })
+        ),
         h('button', { className: 'btn btn-secondary', onClick: function() { setPreviewRole(null); } }, 'Close'),
         !previewRole.isCustom && h('button', { className: 'btn btn-primary', onClick: function() { setPreviewRole(null); handleDuplicate(previewRole); } }, I.copy(), ' Duplicate as Custom'),
         previewRole.isCustom && h('button', { className: 'btn btn-primary', onClick: function() { setPreviewRole(null); setEditRole(previewRole); } }, I.edit(), ' Edit')
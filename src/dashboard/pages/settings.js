@@ -1,25 +1,28 @@
-import { h, useState, useEffect, useCallback, useRef, Fragment, useApp, apiCall, engineCall, applyBrandColor, showConfirm, setOrgId, getOrgId } from '../components/utils.js';
+import { h, useState, useEffect, useCallback, useRef, Fragment, useApp, apiCall, engineCall, downloadExport, applyBrandColor, showConfirm, setOrgId, getOrgId, flash, buildAgentDataMap, renderAgentBadge } from '../components/utils.js';
 import { I } from '../components/icons.js';
 import { E } from '../assets/icons/emoji-icons.js';
 import { Modal } from '../components/modal.js';
 import { TagInput } from '../components/tag-input.js';
+import { ClassificationSelect } from '../components/classification.js';
 import { COUNTRIES } from '../data/countries.js?v=6';
 import { HelpButton } from '../components/help-button.js';
 import { SETTINGS_HELP } from '../components/settings-help.js';
 import { KnowledgeLink, SETTINGS_TAB_DOCS } from '../components/knowledge-link.js';
 import { ProviderLogo } from '../assets/provider-logos.js';
 import { useOrgContext } from '../components/org-switcher.js';
+import { UnknownVariablesWarning, unknownVariables } from '../components/merge-vars.js';
+import { RelativeTime } from '../components/time.js';
+import { Pagination } from '../components/pagination.js';
+
+var SIGNATURE_VARIABLES = ['name', 'role', 'email', 'phone', 'company', 'logo'];
 
 export function SettingsPage() {
   const { toast, setCompanyName } = useApp();
   var orgCtx = useOrgContext();
   var effectiveOrgId = orgCtx.selectedOrgId || '';
-  const [tab, setTab] = useState('general');
+  // ?tab=api-keys opens a tab directly (the dashboard's expiring-keys card links here)
+  const [tab, setTab] = useState(function() { return new URLSearchParams(location.search).get('tab') || 'general'; });
   const [settings, setSettings] = useState({});
-  const [apiKeys, setApiKeys] = useState([]);
-  const [keyName, setKeyName] = useState('');
-  const [newKeyPlaintext, setNewKeyPlaintext] = useState(null);
-  const [keyCopied, setKeyCopied] = useState(false);
   const [ssoConfig, setSsoConfig] = useState({});
   const [deployCreds, setDeployCreds] = useState([]);
   const [showDeployModal, setShowDeployModal] = useState(false);
@@ -90,9 +93,9 @@ export function SettingsPage() {
 
   // Org-scoped tabs vs system tabs
   var ORG_TABS = ['models', 'email', 'integrations', 'authentication'];
-  var SYSTEM_TABS = ['general', 'models', 'api-keys', 'authentication', 'platform', 'email', 'deployments', 'security-system', 'tool-security', 'network'];
-  var TAB_LABELS = { general: 'General', models: 'Models & API Keys', 'api-keys': 'API Keys', authentication: 'Authentication', platform: 'Platform', email: 'Email & Domain', deployments: 'Deployments', 'security-system': 'Security', 'tool-security': 'Tool Security', network: 'Network & Firewall', integrations: 'Integrations' };
-  var TAB_ICONS = { general: I.settings, models: I.key, 'api-keys': I.key, authentication: I.shield, platform: I.globe, email: I.messages, deployments: I.upload, 'security-system': I.lock, 'tool-security': I.guardrails, network: I.globe, integrations: I.link };
+  var SYSTEM_TABS = ['general', 'models', 'api-keys', 'authentication', 'permissions', 'platform', 'email', 'deployments', 'security-system', 'tool-security', 'network', 'residency', 'disclosure', 'reports'];
+  var TAB_LABELS = { general: 'General', models: 'Models & API Keys', 'api-keys': 'API Keys', authentication: 'Authentication', permissions: 'Role Permissions', platform: 'Platform', email: 'Email & Domain', deployments: 'Deployments', 'security-system': 'Security', 'tool-security': 'Tool Security', network: 'Network & Firewall', residency: 'Data Residency', disclosure: 'AI Disclosure', reports: 'Scheduled Reports', integrations: 'Integrations' };
+  var TAB_ICONS = { general: I.settings, models: I.key, 'api-keys': I.key, authentication: I.shield, permissions: I.users, platform: I.globe, email: I.messages, deployments: I.upload, 'security-system': I.lock, 'tool-security': I.guardrails, network: I.globe, residency: I.database, disclosure: I.messages, reports: I.calendar, integrations: I.link };
   var activeTabs = effectiveOrgId ? ORG_TABS : SYSTEM_TABS;
 
   // Reset tab when switching between org/system view
@@ -120,7 +123,6 @@ export function SettingsPage() {
 
   useEffect(() => {
     apiCall('/settings').then(d => { const s = d.settings || d || {}; setSettings(s); if (s.primaryColor) applyBrandColor(s.primaryColor); if (s.orgId) setOrgId(s.orgId); }).catch(() => {});
-    apiCall('/api-keys').then(d => setApiKeys(d.keys || [])).catch(() => {});
     apiCall('/settings/sso').then(d => {
       const sso = d.ssoConfig || {};
       setSsoConfig(sso);
@@ -156,26 +158,6 @@ export function SettingsPage() {
     }).catch(function() {});
   }, []);
 
-  const createKey = async () => {
-    try {
-      const d = await apiCall('/api-keys', { method: 'POST', body: JSON.stringify({ name: keyName || 'New Key', scopes: ['read', 'write', 'admin'] }) });
-      if (d.plaintext) { setNewKeyPlaintext(d.plaintext); setKeyCopied(false); }
-      else toast('API Key created', 'success');
-      setKeyName('');
-      apiCall('/api-keys').then(d => setApiKeys(d.keys || [])).catch(() => {});
-    } catch (e) { toast(e.message, 'error'); }
-  };
-
-  const copyKey = () => {
-    if (newKeyPlaintext) { navigator.clipboard.writeText(newKeyPlaintext).then(() => { setKeyCopied(true); toast('Copied to clipboard', 'success'); }).catch(() => toast('Copy failed', 'error')); }
-  };
-
-  const revokeKey = async (id) => {
-    const ok = await showConfirm({ title: 'Revoke API Key', message: 'Are you sure you want to revoke this API key? Any applications using this key will immediately lose access.', warning: 'This action cannot be undone. You will need to create a new key.', danger: true, confirmText: 'Revoke Key' });
-    if (!ok) return;
-    try { await apiCall('/api-keys/' + id, { method: 'DELETE' }); toast('Key revoked', 'success'); apiCall('/api-keys').then(d => setApiKeys(d.keys || [])); } catch (e) { toast(e.message, 'error'); }
-  };
-
   const saveSetting = async (key, value) => {
     try { await apiCall('/settings', { method: 'PATCH', body: JSON.stringify({ [key]: value }) }); toast('Settings saved', 'success'); } catch (e) { toast(e.message, 'error'); }
   };
@@ -480,6 +462,7 @@ export function SettingsPage() {
             h('span', { className: 'badge', style: { fontSize: 11 } }, '{{company}}'),
             h('span', { className: 'badge', style: { fontSize: 11 } }, '{{logo}}')
           ),
+          h(UnknownVariablesWarning, { unknown: unknownVariables([settings.signatureTemplate], SIGNATURE_VARIABLES), hint: 'these will be left as-is in agent signatures' }),
           settings.signatureTemplate && h('div', { style: { marginBottom: 16 } },
             h('label', { className: 'form-label' }, 'Preview'),
             h('div', {
@@ -498,6 +481,8 @@ export function SettingsPage() {
           h('button', {
             className: 'btn btn-primary',
             onClick: function() {
+              var unknown = unknownVariables([settings.signatureTemplate], SIGNATURE_VARIABLES);
+              if (unknown.length) { toast('Unknown variables: ' + unknown.map(function(v) { return '{{' + v + '}}'; }).join(', '), 'error'); return; }
               apiCall('/settings', {
                 method: 'PATCH',
                 body: JSON.stringify({ signatureTemplate: settings.signatureTemplate })
@@ -585,6 +570,8 @@ export function SettingsPage() {
           )
         )
       ),
+      h(DataRetentionCard, { toast: toast, orgId: effectiveOrgId || getOrgId(), clientOrgId: effectiveOrgId }),
+      h(AuditRetentionCard, { toast: toast }),
       h('div', { className: 'card', style: { marginTop: 16 } },
         h('div', { className: 'card-header' }, h('h3', null, 'Info')),
         h('div', { className: 'card-body' },
@@ -664,48 +651,11 @@ export function SettingsPage() {
       })
     ),
 
-    tab === 'api-keys' && h(Fragment, null,
-      newKeyPlaintext && h(Modal, { title: 'API Key Created', onClose: () => setNewKeyPlaintext(null) },
-        h('div', { style: { marginBottom: 16 } },
-          h('div', { style: { padding: 16, background: 'var(--warning-soft)', borderRadius: 'var(--radius)', fontSize: 13, color: 'var(--warning)', marginBottom: 16 } }, 'Copy this key now. It will not be shown again.'),
-          h('div', { style: { display: 'flex', gap: 8, alignItems: 'center' } },
-            h('input', { className: 'input', value: newKeyPlaintext, readOnly: true, style: { fontFamily: 'var(--font-mono)', fontSize: 12, flex: 1 }, onClick: e => e.target.select() }),
-            h('button', { className: 'btn ' + (keyCopied ? 'btn-secondary' : 'btn-primary'), onClick: copyKey }, keyCopied ? [I.check(), ' Copied'] : [I.copy(), ' Copy'])
-          )
-        ),
-        h('div', { style: { textAlign: 'right' } },
-          h('button', { className: 'btn btn-secondary', onClick: () => setNewKeyPlaintext(null) }, 'Done')
-        )
-      ),
-      h('div', { className: 'card', style: { marginBottom: 16 } },
-        h('div', { className: 'card-body' },
-          h('div', { style: { display: 'flex', gap: 8, alignItems: 'center' } },
-            h('input', { className: 'input', value: keyName, onChange: e => setKeyName(e.target.value), placeholder: 'Key name (e.g., production)', style: { maxWidth: 300 } }),
-            h('button', { className: 'btn btn-primary', onClick: createKey }, I.plus(), ' Create Key')
-          )
-        )
-      ),
-      h('div', { className: 'card' },
-        h('div', { className: 'card-body-flush' },
-          apiKeys.length === 0 ? h('div', { style: { padding: 24, textAlign: 'center', color: 'var(--text-muted)' } }, 'No API keys')
-          : h('table', null,
-              h('thead', null, h('tr', null, h('th', null, 'Name'), h('th', null, 'Key Prefix'), h('th', null, 'Scopes'), h('th', null, 'Created'), h('th', null, 'Actions'))),
-              h('tbody', null, apiKeys.map(k =>
-                h('tr', { key: k.id },
-                  h('td', null, h('strong', null, k.name)),
-                  h('td', null, h('span', { style: { fontFamily: 'var(--font-mono)', fontSize: 12 } }, (k.keyPrefix || '???') + '...')),
-                  h('td', null, (k.scopes || []).map(s => h('span', { key: s, className: 'badge badge-neutral', style: { marginRight: 4 } }, s))),
-                  h('td', { style: { fontSize: 12, color: 'var(--text-muted)' } }, k.createdAt ? new Date(k.createdAt).toLocaleDateString() : '-'),
-                  h('td', null, h('button', { className: 'btn btn-danger btn-sm', onClick: () => revokeKey(k.id) }, 'Revoke'))
-                )
-              ))
-            )
-        )
-      )
-    ),
+    tab === 'api-keys' && h(ApiKeysCard, { toast: toast }),
 
     tab === 'authentication' && h('div', null,
       h(TwoFactorCard, { toast: toast }),
+      !effectiveOrgId && h(PasswordPolicyCard, { toast: toast }),
       !effectiveOrgId && h('div', { className: 'card', style: { marginBottom: 16 } },
         h('div', { className: 'card-header' }, h('h3', { style: { display: 'flex', alignItems: 'center' } }, 'Single Sign-On (SSO)', h(HelpButton, { label: 'Single Sign-On (SSO)' },
           h('p', null, 'Let your team sign into AgenticMail using their existing corporate identity provider (Okta, Google Workspace, Azure AD, etc.).'),
@@ -789,8 +739,16 @@ export function SettingsPage() {
       )
     ),
 
+    tab === 'permissions' && h(RolePermissionsTab, { toast: toast }),
+
     tab === 'platform' && h(PlatformCapabilitiesTab, { toast: toast }),
 
+    tab === 'residency' && h(DataResidencyTab, { toast: toast }),
+
+    tab === 'disclosure' && h(AIDisclosureTab, { toast: toast }),
+
+    tab === 'reports' && h(ScheduledReportsTab, { toast: toast }),
+
     tab === 'email' && effectiveOrgId && h('div', null,
       h('div', { className: 'card' },
         h('div', { className: 'card-header' }, h('h3', null, 'Organization Email Configuration')),
@@ -1735,6 +1693,34 @@ function ComprehensiveSecurityTab(props) {
     return h('div', { style: isEditing ? {} : { opacity: 0.7, pointerEvents: 'none' } }, content);
   }
 
+  // ── Posture + encryption at rest (reported by the engine, refreshed after each save) ──
+  var _posture = useState(null);
+  var posture = _posture[0]; var setPosture = _posture[1];
+  var _encryption = useState(null);
+  var encryption = _encryption[0]; var setEncryption = _encryption[1];
+  useEffect(function() {
+    if (saving) return;
+    engineCall('/security/posture').then(function(d) { setPosture(d.posture); }).catch(function() {});
+    engineCall('/security/encryption').then(setEncryption).catch(function() {});
+  }, [saving]);
+
+  var encryptionPolicy = securityConfig.encryptionPolicy || { requiredClasses: [] };
+  function toggleRequired(key, on) {
+    var next = (encryptionPolicy.requiredClasses || []).filter(function(k) { return k !== key; });
+    if (on) next.push(key);
+    updateSection('encryptionPolicy', { requiredClasses: next });
+  }
+  var ENC_STATUS = {
+    encrypted: { label: 'Encrypted', color: 'var(--success, #16a34a)' },
+    partial: { label: 'Partially encrypted', color: 'var(--warning, #d97706)' },
+    plaintext: { label: 'Not encrypted', color: 'var(--text-muted)' },
+  };
+  var POSTURE_STATUS = {
+    pass: { label: 'Passing', color: 'var(--success, #16a34a)' },
+    warn: { label: 'Needs attention', color: 'var(--warning, #d97706)' },
+    fail: { label: 'Failing', color: 'var(--danger, #dc2626)' },
+  };
+
   return h('div', null,
     h('div', { style: { display: 'flex', alignItems: 'center', justifyContent: 'space-between', marginBottom: 24 } },
       h('div', null,
@@ -1743,6 +1729,53 @@ function ComprehensiveSecurityTab(props) {
       )
     ),
 
+    // Security Posture
+    posture && h('div', { style: Object.assign({}, _cardStyle, { display: 'flex', gap: 24, alignItems: 'flex-start' }) },
+      h('div', { style: { textAlign: 'center', minWidth: 110 } },
+        h('div', { style: { fontSize: 40, fontWeight: 700, color: POSTURE_STATUS[posture.status].color, lineHeight: 1 } }, posture.score),
+        h('div', { style: { fontSize: 12, color: 'var(--text-muted)', marginTop: 4 } }, 'Posture score'),
+        h('div', { style: { fontSize: 12, fontWeight: 600, marginTop: 6, color: POSTURE_STATUS[posture.status].color } }, POSTURE_STATUS[posture.status].label)
+      ),
+      h('div', { style: { flex: 1 } },
+        h('div', { style: _cardTitleStyle }, I.shield(), 'Security Posture'),
+        posture.checks.every(function(c) { return c.passed; })
+          ? h('p', { style: _cardDescStyle }, 'All checks pass.')
+          : h('div', { style: { display: 'grid', gap: 6 } }, posture.checks.filter(function(c) { return !c.passed; }).map(function(c) {
+              return h('div', { key: c.id, style: { fontSize: 13, display: 'flex', gap: 8 } },
+                h('span', { style: { color: c.critical ? 'var(--danger, #dc2626)' : 'var(--warning, #d97706)', fontWeight: 600, minWidth: 56 } }, c.critical ? 'Critical' : '-' + c.weight),
+                h('span', null, h('strong', null, c.label), c.detail ? ' \u2014 ' + c.detail : '')
+              );
+            }))
+      )
+    ),
+
+    // Encryption at Rest
+    h('div', { style: _cardStyle },
+      sectionHeader(I.lock(), 'Encryption at Rest', 'encryptionPolicy'),
+      h('p', { style: _cardDescStyle }, 'Which stored fields the application encrypts (AES-256-GCM via the vault). Disk or database-level encryption isn\u2019t visible here. Requiring a data class fails the security posture until every field in it is encrypted.'),
+      encryption && !encryption.keyConfigured && h('div', { style: { fontSize: 13, padding: '8px 12px', borderRadius: 6, background: 'var(--bg-secondary)', color: 'var(--warning, #d97706)', marginBottom: 12 } },
+        'The vault is using the built-in development key. Set AGENTICMAIL_VAULT_KEY so encrypted data can\u2019t be read with a public key.'),
+      !encryption ? h('div', { style: { fontSize: 13, color: 'var(--text-muted)' } }, 'Loading encryption report...')
+        : h('table', { className: 'data-table' },
+            h('thead', null, h('tr', null, h('th', null, 'Data'), h('th', null, 'Stored fields'), h('th', null, 'Status'), h('th', { style: { textAlign: 'right' } }, 'Require encryption'))),
+            h('tbody', null, encryption.classes.map(function(cls) {
+              var st = ENC_STATUS[cls.status];
+              var required = (encryptionPolicy.requiredClasses || []).indexOf(cls.key) !== -1;
+              return h('tr', { key: cls.key },
+                h('td', null, h('div', { style: { fontWeight: 500 } }, cls.label), h('div', { style: { fontSize: 12, color: 'var(--text-muted)' } }, cls.description)),
+                h('td', { style: { fontSize: 12 } }, cls.fields.map(function(f) {
+                  return h('div', { key: f.table + '.' + f.column, title: f.table + '.' + f.column },
+                    f.label, ' ', h('span', { style: { color: 'var(--text-muted)' } }, f.protection === 'none' ? '(' + f.rows.toLocaleString() + ' rows)' : '(' + f.encryptedRows.toLocaleString() + '/' + f.rows.toLocaleString() + ' encrypted)'));
+                })),
+                h('td', null, h('span', { style: { display: 'inline-flex', alignItems: 'center', gap: 4, fontSize: 12, fontWeight: 600, color: st.color } }, cls.status === 'plaintext' ? I.unlock() : I.lock(), st.label)),
+                h('td', { style: { textAlign: 'right' } }, sectionBody('encryptionPolicy', h(ToggleSwitch, { checked: required, onChange: function(v) { toggleRequired(cls.key, v); } })))
+              );
+            }))
+          ),
+      encryption && !encryption.evaluation.met && h('div', { style: { fontSize: 13, color: 'var(--danger, #dc2626)', marginTop: 12 } },
+        'Policy not met: ', encryption.evaluation.unmet.map(function(u) { return u.label + ' (' + u.reason + ')'; }).join('; '))
+    ),
+
     // Prompt Injection Defense
     h('div', { style: _cardStyle },
       sectionHeader(I.shield(), 'Prompt Injection Defense', 'promptInjection'),
@@ -2550,6 +2583,12 @@ function NetworkFirewallTab(props) {
         h('div', { style: _gridStyle },
           h(TagInput, { label: 'Allowed Ports', value: (egress.allowedPorts || []).map(String), onChange: function(v) { patchEgress('allowedPorts', v.map(Number).filter(function(n) { return !isNaN(n); })); }, placeholder: '443' }),
           h(TagInput, { label: 'Blocked Ports', value: (egress.blockedPorts || []).map(String), onChange: function(v) { patchEgress('blockedPorts', v.map(Number).filter(function(n) { return !isNaN(n); })); }, placeholder: '25' })
+        ),
+        h('div', { style: { borderTop: '1px solid var(--border)', paddingTop: 12, marginTop: 4 } },
+          h('label', { style: { display: 'block', fontSize: 12, fontWeight: 600, color: 'var(--text-secondary)', marginBottom: 4 } }, 'Classified agents'),
+          h('div', { style: { fontSize: 12, color: 'var(--text-muted)', marginBottom: 8 } }, 'Agents whose mailbox or knowledge bases carry this classification or higher can only reach the hosts below, whatever the mode above.'),
+          h(ClassificationSelect, { value: egress.classifiedMinLevel || '', noneLabel: 'No extra restriction', atOrAbove: true, style: { width: 280, marginBottom: 8 }, onChange: function(v) { patchEgress('classifiedMinLevel', v || undefined); } }),
+          egress.classifiedMinLevel && h(TagInput, { label: 'Allowed Hosts for classified agents', value: egress.classifiedAllowedHosts || [], onChange: function(v) { patchEgress('classifiedAllowedHosts', v); }, placeholder: '*.internal.example.com', mono: true })
         )
       )
     ),
@@ -2615,7 +2654,7 @@ function NetworkFirewallTab(props) {
 
       // Security Headers
       h('div', { style: _cardStyle },
-        h('div', { style: _cardTitleStyle }, I.shield(), ' Security Headers', h(HelpButton, { label: 'Security Headers' }, h('div', null, h('p', null, 'HTTP headers added to every response for defense-in-depth browser security.'), h('p', null, h('strong', null, 'HSTS:'), ' Forces browsers to use HTTPS for future visits.'), h('p', null, h('strong', null, 'X-Frame-Options:'), ' Prevents clickjacking by controlling iframe embedding.'), h('p', null, h('strong', null, 'Referrer-Policy:'), ' Controls how much referrer info is sent with requests.'), h('p', null, h('strong', null, 'Permissions-Policy:'), ' Disables browser features like camera/microphone access.')))),
+        h('div', { style: _cardTitleStyle }, I.shield(), ' Security Headers', h(HelpButton, { label: 'Security Headers' }, h('div', null, h('p', null, 'HTTP headers added to every response for defense-in-depth browser security.'), h('p', null, h('strong', null, 'HSTS:'), ' Forces browsers to use HTTPS for future visits.'), h('p', null, h('strong', null, 'X-Frame-Options:'), ' Prevents clickjacking by controlling iframe embedding.'), h('p', null, h('strong', null, 'Referrer-Policy:'), ' Controls how much referrer info is sent with requests.'), h('p', null, h('strong', null, 'Permissions-Policy:'), ' Disables browser features like camera/microphone access.'), h('p', null, h('strong', null, 'Content-Security-Policy:'), ' Pages only run scripts served by this server or inline blocks carrying a per-request nonce, so injected markup can\'t execute. Turn off only if a proxy or extension you rely on injects its own scripts.')))),
         h('div', { style: _cardDescStyle }, 'HTTP security headers applied to all responses. Protects against clickjacking, MIME sniffing, and other browser-level attacks.'),
         h(ToggleSwitch, { label: 'Strict-Transport-Security (HSTS)', checked: sh.hsts !== false, onChange: function(v) { patchSh('hsts', v); } }),
         sh.hsts !== false && h('div', { className: 'form-group', style: { marginBottom: 12 } },
@@ -2623,6 +2662,7 @@ function NetworkFirewallTab(props) {
           h('input', { className: 'input', type: 'number', min: 0, style: { width: 160, fontSize: 13 }, value: sh.hstsMaxAge || 31536000, onChange: function(e) { patchSh('hstsMaxAge', parseInt(e.target.value) || 31536000); } })
         ),
         h(ToggleSwitch, { label: 'X-Content-Type-Options: nosniff', checked: sh.xContentTypeOptions !== false, onChange: function(v) { patchSh('xContentTypeOptions', v); } }),
+        h(ToggleSwitch, { label: 'Content-Security-Policy (nonce-based scripts)', checked: sh.contentSecurityPolicy !== false, onChange: function(v) { patchSh('contentSecurityPolicy', v); } }),
         h('div', { style: { marginBottom: 12 } },
           h('label', { style: { display: 'block', fontSize: 12, fontWeight: 600, color: 'var(--text-secondary)', marginBottom: 4 } }, 'X-Frame-Options'),
           h('select', { className: 'input', style: { width: 200 }, value: sh.xFrameOptions || 'DENY', onChange: function(e) { patchSh('xFrameOptions', e.target.value); } },
@@ -2644,6 +2684,17 @@ function NetworkFirewallTab(props) {
           h('label', { style: { display: 'block', fontSize: 12, fontWeight: 600, color: 'var(--text-secondary)', marginBottom: 4 } }, 'Permissions-Policy'),
           h('input', { className: 'input', style: { fontSize: 13 }, value: sh.permissionsPolicy || 'camera=(), microphone=(), geolocation=()', onChange: function(e) { patchSh('permissionsPolicy', e.target.value); } })
         )
+      ),
+
+      // Embedding (frame-ancestors allowlist)
+      h('div', { style: _cardStyle },
+        h('div', { style: _cardTitleStyle }, I.globe(), ' Dashboard Embedding', h(HelpButton, { label: 'Dashboard Embedding' }, h('div', null, h('p', null, 'Allows specific read-only dashboard pages to be embedded in an iframe on trusted sites such as your intranet portal.'), h('p', null, 'Listed pages are served with a CSP ', h('code', null, 'frame-ancestors'), ' allowlist instead of X-Frame-Options. Every other page keeps the X-Frame-Options policy above.'), h('p', null, 'Administrative pages (Settings, Users, Vault, Roles, Database Access, Organizations) can never be embedded. Inside a frame, links to non-embeddable pages open in a new tab.')))),
+        h('div', { style: _cardDescStyle }, 'Selectively allow trusted origins to frame chosen read-only pages. All other pages remain protected against clickjacking.'),
+        h(ToggleSwitch, { label: 'Allow embedding of selected pages', checked: (sh.embedding || {}).enabled === true, onChange: function(v) { patchSh('embedding', Object.assign({}, sh.embedding, { enabled: v })); } }),
+        (sh.embedding || {}).enabled && h(Fragment, null,
+          h(TagInput, { label: 'Allowed Frame Ancestors', value: (sh.embedding || {}).frameAncestors || [], onChange: function(v) { patchSh('embedding', Object.assign({}, sh.embedding, { frameAncestors: v })); }, placeholder: 'https://intranet.example.com', mono: true }),
+          h(TagInput, { label: 'Embeddable Pages', value: (sh.embedding || {}).routes || [], onChange: function(v) { patchSh('embedding', Object.assign({}, sh.embedding, { routes: v })); }, placeholder: 'activity', mono: true })
+        )
       )
     ),
 
@@ -2670,6 +2721,20 @@ function NetworkFirewallTab(props) {
         h('div', { style: { fontSize: 11, color: 'var(--text-muted)' } }, 'Default: 10240 KB (10 MB). Set higher for file upload APIs.')
       ),
 
+      // API Failover
+      h('div', { style: _cardStyle },
+        h('div', { style: _cardTitleStyle }, I.server(), ' API Failover', h(HelpButton, { label: 'API Failover' }, h('div', null, h('p', null, 'Lists the enterprise servers the dashboard may talk to: the primary first, then replicas. The dashboard health-checks each one (', h('code', null, 'GET /ready'), ') and automatically switches to the next healthy server when the active one stops responding, returning to the primary once it recovers.'), h('p', null, 'Replicas must share the same database and JWT_SECRET, list this dashboard\'s origin under CORS Origins, and receive the session cookie (same host, or a shared cookie domain).'), h('p', null, 'Only read requests are retried automatically; a failed save shows an error and the next attempt goes to the replica. The active server is shown at the bottom of the sidebar.')))),
+        h('div', { style: _cardDescStyle }, 'Primary and replica API servers. The dashboard fails over automatically when the active server is unreachable.'),
+        h(ToggleSwitch, { label: 'Enable failover', checked: (net.failover || {}).enabled === true, onChange: function(v) { patchNet('failover', Object.assign({}, net.failover, { enabled: v })); } }),
+        (net.failover || {}).enabled && h(Fragment, null,
+          h(TagInput, { label: 'Backends (primary first)', value: (net.failover || {}).backends || [window.location.origin], onChange: function(v) { patchNet('failover', Object.assign({}, net.failover, { backends: v })); }, placeholder: 'https://api-2.example.com', mono: true }),
+          h('div', { className: 'form-group', style: { marginBottom: 12 } },
+            h('label', { style: { display: 'block', fontSize: 12, fontWeight: 600, color: 'var(--text-secondary)', marginBottom: 4 } }, 'Health Check Interval (seconds)'),
+            h('input', { className: 'input', type: 'number', min: 5, max: 300, style: { width: 120, fontSize: 13 }, value: (net.failover || {}).healthCheckIntervalSec || 15, onChange: function(e) { patchNet('failover', Object.assign({}, net.failover, { healthCheckIntervalSec: parseInt(e.target.value) || 15 })); } })
+          )
+        )
+      ),
+
       // Geo-IP Restrictions
       h('div', { style: _cardStyle },
         h('div', { style: _cardTitleStyle }, I.globe(), ' Geo-IP Restrictions', h(HelpButton, { label: 'Geo-IP Restrictions' }, h('div', null, h('p', null, 'Restricts access based on the geographic location of the client IP address.'), h('p', null, h('strong', null, 'Allowlist:'), ' Only selected countries can access.'), h('p', null, h('strong', null, 'Blocklist:'), ' Selected countries are blocked, everyone else allowed.'), h('p', null, 'Uses built-in IP geolocation — works without Cloudflare or any reverse proxy.')))),
@@ -3045,7 +3110,7 @@ function ProvidersSection(props) {
             if (e.key === 'Enter' && props.apiKeyInput.trim()) {
               var m = props.apiKeyModal;
               apiCall('/providers/' + m.providerId + '/api-key', { method: 'POST', body: JSON.stringify({ apiKey: props.apiKeyInput.trim() }) })
-                .then(function() { toast((m.isUpdate ? 'API key updated' : 'API key saved') + ' for ' + m.providerName + '!', 'success'); props.setApiKeyModal(null); props.setApiKeyInput(''); window.location.reload(); })
+                .then(function() { flash((m.isUpdate ? 'API key updated' : 'API key saved') + ' for ' + m.providerName, 'success'); props.setApiKeyModal(null); props.setApiKeyInput(''); window.location.reload(); })
                 .catch(function(e) { toast(e.message || 'Failed to save', 'error'); });
             }
           },
@@ -3061,7 +3126,7 @@ function ProvidersSection(props) {
           onClick: function() {
             var m = props.apiKeyModal;
             apiCall('/providers/' + m.providerId + '/api-key', { method: 'POST', body: JSON.stringify({ apiKey: props.apiKeyInput.trim() }) })
-              .then(function() { toast((m.isUpdate ? 'API key updated' : 'API key saved') + ' for ' + m.providerName + '!', 'success'); props.setApiKeyModal(null); props.setApiKeyInput(''); window.location.reload(); })
+              .then(function() { flash((m.isUpdate ? 'API key updated' : 'API key saved') + ' for ' + m.providerName, 'success'); props.setApiKeyModal(null); props.setApiKeyInput(''); window.location.reload(); })
               .catch(function(e) { toast(e.message || 'Failed to save', 'error'); });
           }
         }, props.apiKeyModal.isUpdate ? 'Update Key' : 'Save Key')
@@ -3228,6 +3293,323 @@ function ModelPricingTab(props) {
 
 // ─── Two-Factor Authentication Card ─────────────────────
 
+// ─── Data Retention ──────────────────────────────────────
+// Purges never run straight from the policy: Preview produces a dry-run
+// report (what would go, oldest/newest, which agents, what legal holds keep)
+// and only that report can be approved.
+
+function DataRetentionCard({ toast, orgId, clientOrgId }) {
+  var { user } = useApp();
+  var isOwner = user && user.role === 'owner';
+  var [policy, setPolicy] = useState(null);
+  var [runs, setRuns] = useState([]);
+  var [holds, setHolds] = useState([]);
+  var [agents, setAgents] = useState([]);
+  var [busy, setBusy] = useState('');
+  var [holdForm, setHoldForm] = useState(null); // { agentId, reason }
+
+  var load = function() {
+    apiCall('/retention').then(setPolicy).catch(function() { setPolicy({ enabled: false, retainDays: 365, archiveFirst: true, excludeTags: [] }); });
+    engineCall('/retention/runs?orgId=' + encodeURIComponent(orgId)).then(function(d) { setRuns(d.runs || []); }).catch(function() {});
+    engineCall('/retention/holds?orgId=' + encodeURIComponent(orgId)).then(function(d) { setHolds(d.holds || []); }).catch(function() {});
+  };
+  useEffect(function() {
+    load();
+    apiCall('/agents' + (clientOrgId ? '?clientOrgId=' + clientOrgId : '')).then(function(d) { setAgents(d.agents || []); }).catch(function() {});
+  }, [orgId]);
+
+  var savePolicy = async function() {
+    setBusy('policy');
+    try {
+      await apiCall('/retention', { method: 'PUT', body: JSON.stringify(policy) });
+      toast('Retention policy saved', 'success');
+    } catch (e) { toast(e.message, 'error'); }
+    setBusy('');
+  };
+
+  var preview = async function() {
+    setBusy('preview');
+    try {
+      await engineCall('/retention/preview', { method: 'POST', body: JSON.stringify({ orgId: orgId, retainDays: policy.retainDays }) });
+      load();
+    } catch (e) { toast(e.message, 'error'); }
+    setBusy('');
+  };
+
+  var approve = async function(run) {
+    var ok = await showConfirm({
+      title: 'Approve Purge', danger: true, confirmText: 'Purge', requireText: 'PURGE',
+      message: 'Permanently delete ' + run.report.total.toLocaleString() + ' records created before ' + new Date(run.cutoff).toLocaleString() + '?',
+      warning: 'This cannot be undone. Agents on legal hold are skipped.',
+    });
+    if (!ok) return;
+    setBusy('approve');
+    try {
+      var d = await engineCall('/retention/runs/' + run.id + '/approve', { method: 'POST' });
+      var deleted = Object.values(d.run.result || {}).reduce(function(a, b) { return a + b; }, 0);
+      toast('Purged ' + deleted.toLocaleString() + ' records', 'success');
+    } catch (e) { toast(e.message, 'error'); }
+    setBusy('');
+    load();
+  };
+
+  var discard = function(run) {
+    engineCall('/retention/runs/' + run.id + '/discard', { method: 'POST' })
+      .then(function() { toast('Report discarded', 'success'); load(); })
+      .catch(function(e) { toast(e.message, 'error'); });
+  };
+
+  var placeHold = async function() {
+    try {
+      await engineCall('/retention/holds', { method: 'POST', body: JSON.stringify({ orgId: orgId, agentId: holdForm.agentId, reason: holdForm.reason }) });
+      toast('Legal hold placed', 'success');
+      setHoldForm(null);
+      load();
+    } catch (e) { toast(e.message, 'error'); }
+  };
+
+  var releaseHold = async function(hold) {
+    var ok = await showConfirm({
+      title: 'Release Legal Hold', danger: true, confirmText: 'Release',
+      message: 'Release the hold on ' + agentName(hold.agentId) + '? Its data becomes eligible for the next approved purge.',
+    });
+    if (!ok) return;
+    try {
+      await engineCall('/retention/holds/' + hold.id, { method: 'DELETE' });
+      toast('Legal hold released', 'success');
+      load();
+    } catch (e) { toast(e.message, 'error'); }
+  };
+
+  var agentName = function(id) {
+    var a = agents.find(function(x) { return x.id === id; });
+    return a ? ((a.config && (a.config.displayName || a.config.name)) || a.name || id) : id;
+  };
+  var fmt = function(d) { return d ? new Date(d).toLocaleDateString() : '-'; };
+
+  if (!policy) return null;
+  var pending = runs.find(function(r) { return r.status === 'pending'; });
+  var ownPreview = !!(pending && user && pending.createdBy === user.id);
+  var history = runs.filter(function(r) { return r.status !== 'pending'; }).slice(0, 5);
+  var _label = { fontSize: 12, fontWeight: 600, color: 'var(--text-muted)', textTransform: 'uppercase', margin: '16px 0 6px' };
+
+  return h('div', { className: 'card', style: { marginTop: 16 } },
+    h('div', { className: 'card-header' }, h('h3', { style: { display: 'flex', alignItems: 'center' } }, 'Data Retention', h(HelpButton, { label: 'Data Retention' },
+      h('p', null, 'Old tool calls, activity events and conversation messages can be purged once they pass the retention period.'),
+      h('p', null, 'Nothing is deleted automatically. Preview Purge builds a dry-run report of exactly what would be removed; a different admin then approves or discards it within 24 hours. The purge never reaches past the cutoff in the report.'),
+      h('p', null, h('strong', null, 'Legal holds'), ' keep every record for an agent, regardless of age, until released. Only the owner can place or release a hold. Holds placed after a preview still apply when it runs.')
+    ))),
+    h('div', { className: 'card-body' },
+      h('div', { style: { display: 'flex', gap: 16, alignItems: 'flex-end', flexWrap: 'wrap' } },
+        h('div', { className: 'form-group', style: { marginBottom: 0 } },
+          h('label', { className: 'form-label' }, 'Keep data for (days)'),
+          h('input', { className: 'input', type: 'number', min: 1, max: 3650, style: { width: 140 }, value: policy.retainDays, onChange: function(e) { setPolicy(Object.assign({}, policy, { retainDays: parseInt(e.target.value) || 1 })); } })
+        ),
+        h('label', { style: { display: 'flex', alignItems: 'center', gap: 6, fontSize: 13, paddingBottom: 8 } },
+          h('input', { type: 'checkbox', checked: !!policy.enabled, onChange: function(e) { setPolicy(Object.assign({}, policy, { enabled: e.target.checked })); } }),
+          'Retention enabled'
+        ),
+        h('button', { className: 'btn btn-secondary', disabled: busy === 'policy', onClick: savePolicy }, 'Save Policy'),
+        h('button', { className: 'btn btn-primary', disabled: !!busy, onClick: preview }, busy === 'preview' ? 'Generating...' : 'Preview Purge')
+      ),
+
+      pending && h('div', { style: { marginTop: 16, padding: 16, border: '1px solid var(--warning)', borderRadius: 'var(--radius)' } },
+        h('div', { style: { display: 'flex', alignItems: 'center', gap: 8, flexWrap: 'wrap' } },
+          h('strong', null, 'Dry run: ' + pending.report.total.toLocaleString() + ' records would be purged'),
+          h('span', { style: { fontSize: 12, color: 'var(--text-muted)' } }, 'older than ' + new Date(pending.cutoff).toLocaleString() + ' (' + pending.retainDays + ' days) · generated ' + new Date(pending.createdAt).toLocaleString()),
+          h('div', { style: { marginLeft: 'auto', display: 'flex', gap: 8, alignItems: 'center' } },
+            ownPreview && h('span', { style: { fontSize: 12, color: 'var(--text-muted)' } }, 'Another admin must approve or discard this purge'),
+            h('button', { className: 'btn btn-ghost btn-sm', disabled: ownPreview, title: ownPreview ? 'You generated this preview; a second admin discards it' : undefined, onClick: function() { discard(pending); } }, 'Discard'),
+            h('button', { className: 'btn btn-danger btn-sm', disabled: !!busy || pending.report.total === 0 || ownPreview, title: ownPreview ? 'You generated this preview; a second admin approves it' : undefined, onClick: function() { approve(pending); } }, busy === 'approve' ? 'Purging...' : 'Approve & Purge')
+          )
+        ),
+        h('table', { className: 'data-table', style: { marginTop: 12 } },
+          h('thead', null, h('tr', null, h('th', null, 'Type'), h('th', null, 'Records'), h('th', null, 'Oldest'), h('th', null, 'Newest'))),
+          h('tbody', null, pending.report.types.map(function(t) {
+            return h('tr', { key: t.type }, h('td', null, t.label), h('td', null, t.count.toLocaleString()), h('td', null, fmt(t.oldest)), h('td', null, fmt(t.newest)));
+          }))
+        ),
+        pending.report.agents.length > 0 && h(Fragment, null,
+          h('div', { style: _label }, 'Affected agents (' + pending.report.agents.length + ')'),
+          h('div', { style: { display: 'flex', flexWrap: 'wrap', gap: 6 } },
+            pending.report.agents.slice(0, 20).map(function(a) {
+              return h('span', { key: a.agentId, className: 'badge badge-neutral' }, a.name + ': ' + a.count.toLocaleString());
+            }),
+            pending.report.agents.length > 20 && h('span', { style: { fontSize: 12, color: 'var(--text-muted)' } }, '+' + (pending.report.agents.length - 20) + ' more')
+          )
+        ),
+        pending.report.holds.length > 0 && h(Fragment, null,
+          h('div', { style: _label }, 'Excluded by legal hold'),
+          pending.report.holds.map(function(hd) {
+            return h('div', { key: hd.agentId, style: { fontSize: 13 } }, h('strong', null, hd.name), ' — ' + hd.excluded.toLocaleString() + ' records kept (' + hd.reason + ')');
+          })
+        )
+      ),
+
+      h('div', { style: Object.assign({}, _label, { display: 'flex', alignItems: 'center' }) }, 'Legal holds',
+        isOwner && h('button', { className: 'btn btn-ghost btn-sm', style: { marginLeft: 'auto', textTransform: 'none' }, onClick: function() { setHoldForm({ agentId: '', reason: '' }); } }, I.plus(), ' Place Hold')
+      ),
+      holds.length === 0
+        ? h('div', { style: { fontSize: 13, color: 'var(--text-muted)' } }, 'No agents are on legal hold.')
+        : holds.map(function(hd) {
+            return h('div', { key: hd.id, style: { display: 'flex', alignItems: 'center', gap: 8, fontSize: 13, padding: '6px 0', borderBottom: '1px solid var(--border)' } },
+              I.lock(), h('strong', null, agentName(hd.agentId)),
+              h('span', { style: { color: 'var(--text-muted)' } }, hd.reason + ' · since ' + fmt(hd.createdAt)),
+              isOwner && h('button', { className: 'btn btn-ghost btn-sm', style: { marginLeft: 'auto' }, onClick: function() { releaseHold(hd); } }, 'Release')
+            );
+          }),
+      holdForm && h('div', { style: { display: 'flex', gap: 8, marginTop: 8, flexWrap: 'wrap' } },
+        h('select', { className: 'input', style: { maxWidth: 240 }, value: holdForm.agentId, onChange: function(e) { setHoldForm(Object.assign({}, holdForm, { agentId: e.target.value })); } },
+          h('option', { value: '' }, '-- Select Agent --'),
+          agents.filter(function(a) { return !holds.some(function(hd) { return hd.agentId === a.id; }); }).map(function(a) { return h('option', { key: a.id, value: a.id }, agentName(a.id)); })
+        ),
+        h('input', { className: 'input', style: { flex: 1, minWidth: 200 }, placeholder: 'Reason, e.g. matter number or request', maxLength: 1000, value: holdForm.reason, onChange: function(e) { setHoldForm(Object.assign({}, holdForm, { reason: e.target.value })); } }),
+        h('button', { className: 'btn btn-ghost', onClick: function() { setHoldForm(null); } }, 'Cancel'),
+        h('button', { className: 'btn btn-primary', disabled: !holdForm.agentId || !holdForm.reason.trim(), onClick: placeHold }, 'Place Hold')
+      ),
+
+      history.length > 0 && h(Fragment, null,
+        h('div', { style: _label }, 'Recent runs'),
+        history.map(function(r) {
+          var deleted = r.result ? Object.values(r.result).reduce(function(a, b) { return a + b; }, 0) : null;
+          var cls = { completed: 'success', failed: 'danger', expired: 'neutral', discarded: 'neutral' }[r.status] || 'neutral';
+          return h('div', { key: r.id, style: { display: 'flex', gap: 8, alignItems: 'center', fontSize: 13, padding: '4px 0' } },
+            h('span', { className: 'badge badge-' + cls }, r.status),
+            h('span', null, new Date(r.createdAt).toLocaleString()),
+            h('span', { style: { color: 'var(--text-muted)' } },
+              r.status === 'completed' ? deleted.toLocaleString() + ' of ' + r.report.total.toLocaleString() + ' records purged' : r.report.total.toLocaleString() + ' records previewed',
+              r.error ? ' — ' + r.error : ''
+            )
+          );
+        })
+      )
+    )
+  );
+}
+
+// ─── Audit Log Retention ─────────────────────────────────
+// Unlike Data Retention this runs on its own once a day, so the preview
+// shows what the next run would remove at the period being edited.
+
+function AuditRetentionCard({ toast }) {
+  var app = useApp();
+  var isOwner = app.user && app.user.role === 'owner';
+  var [policy, setPolicy] = useState(null);
+  var [limits, setLimits] = useState({ minDays: 30, maxDays: 3650 });
+  var [unavailable, setUnavailable] = useState('');
+  var [preview, setPreview] = useState(null);
+  var [saving, setSaving] = useState(false);
+  var [dirty, setDirty] = useState(false);
+
+  useEffect(function() {
+    apiCall('/audit/retention')
+      .then(function(d) { setPolicy(Object.assign({ s3: {} }, d.policy, { s3: d.policy.s3 || {} })); setLimits(d.limits); })
+      .catch(function(err) { setUnavailable(err.message); });
+  }, []);
+
+  var days = policy && policy.retainDays;
+  var daysOk = days >= limits.minDays && days <= limits.maxDays;
+  useEffect(function() {
+    if (!policy || !daysOk) { setPreview(null); return; }
+    var t = setTimeout(function() {
+      apiCall('/audit/retention/preview?retainDays=' + days).then(setPreview).catch(function() { setPreview(null); });
+    }, 400);
+    return function() { clearTimeout(t); };
+  }, [days]);
+
+  var set = function(patch) { setPolicy(Object.assign({}, policy, patch)); setDirty(true); };
+  var setS3 = function(k, v) { set({ s3: Object.assign({}, policy.s3, { [k]: v }) }); };
+
+  var save = async function() {
+    if (policy.enabled && preview && preview.count > 0) {
+      var ok = await showConfirm({
+        title: 'Save Audit Retention', danger: !policy.archiveToS3, confirmText: 'Save Policy',
+        message: preview.count.toLocaleString() + ' audit events older than ' + new Date(preview.cutoff).toLocaleDateString() + ' will be ' + (policy.archiveToS3 ? 'archived to S3 and then deleted' : 'permanently deleted') + ' on the next daily run.',
+        warning: policy.archiveToS3 ? null : 'Without archiving, purged events cannot be recovered. Export them first if you need them as evidence.',
+      });
+      if (!ok) return;
+    }
+    setSaving(true);
+    try {
+      var d = await apiCall('/audit/retention', { method: 'PUT', body: JSON.stringify(policy) });
+      setPolicy(Object.assign({}, d.policy, { s3: d.policy.s3 || {} }));
+      setDirty(false);
+      toast('Audit retention saved', 'success');
+    } catch (e) { toast(e.message, 'error'); }
+    setSaving(false);
+  };
+
+  if (unavailable) return null;
+  if (!policy) return null;
+  var last = policy.lastRun;
+  var canSave = isOwner && dirty && daysOk && (!policy.archiveToS3 || (policy.s3.bucket || '').trim());
+
+  return h('div', { className: 'card', style: { marginTop: 16 } },
+    h('div', { className: 'card-header' }, h('h3', { style: { display: 'flex', alignItems: 'center' } }, 'Audit Log Retention', h(HelpButton, { label: 'Audit Log Retention' },
+      h('p', null, 'How long events in the Audit Log are kept. While enabled, events older than the retention period are removed once a day.'),
+      h('p', null, h('strong', null, 'Archive to S3'), ' writes the expiring events to your bucket as gzipped JSON Lines before anything is deleted; if an upload fails, nothing is purged and the run is retried later. The server uses its own AWS credentials (environment variables, shared config or instance role) — none are stored here.'),
+      h('p', null, 'The preview counts the events the next run would remove at the period you enter, before you save. Only the owner can change the policy, and every change is itself audited.')
+    ))),
+    h('div', { className: 'card-body' },
+      h('div', { style: { display: 'flex', gap: 16, alignItems: 'flex-end', flexWrap: 'wrap' } },
+        h('div', { className: 'form-group', style: { marginBottom: 0 } },
+          h('label', { className: 'form-label' }, 'Keep audit events for (days)'),
+          h('input', { className: 'input', type: 'number', min: limits.minDays, max: limits.maxDays, style: { width: 160 }, value: policy.retainDays, disabled: !isOwner, onChange: function(e) { set({ retainDays: parseInt(e.target.value) || 0 }); } })
+        ),
+        h('label', { style: { display: 'flex', alignItems: 'center', gap: 6, fontSize: 13, paddingBottom: 8 } },
+          h('input', { type: 'checkbox', checked: !!policy.enabled, disabled: !isOwner, onChange: function(e) { set({ enabled: e.target.checked }); } }),
+          'Purge automatically'
+        ),
+        h('label', { style: { display: 'flex', alignItems: 'center', gap: 6, fontSize: 13, paddingBottom: 8 } },
+          h('input', { type: 'checkbox', checked: !!policy.archiveToS3, disabled: !isOwner, onChange: function(e) { set({ archiveToS3: e.target.checked }); } }),
+          'Archive to S3 first'
+        ),
+        isOwner && h('button', { className: 'btn btn-primary', disabled: saving || !canSave, onClick: save }, saving ? 'Saving...' : 'Save Policy')
+      ),
+      !daysOk && h('div', { style: { fontSize: 12, color: 'var(--danger)', marginTop: 6 } }, 'Choose between ' + limits.minDays + ' and ' + limits.maxDays + ' days.'),
+      !isOwner && h('div', { style: { fontSize: 12, color: 'var(--text-muted)', marginTop: 6 } }, 'Only the owner can change audit retention.'),
+
+      policy.archiveToS3 && h('div', { style: { display: 'grid', gridTemplateColumns: 'repeat(auto-fit, minmax(180px, 1fr))', gap: 12, marginTop: 16 } },
+        h('div', { className: 'form-group', style: { marginBottom: 0 } },
+          h('label', { className: 'form-label' }, 'Bucket'),
+          h('input', { className: 'input', value: policy.s3.bucket || '', placeholder: 'acme-audit-archive', disabled: !isOwner, onChange: function(e) { setS3('bucket', e.target.value); } })
+        ),
+        h('div', { className: 'form-group', style: { marginBottom: 0 } },
+          h('label', { className: 'form-label' }, 'Region'),
+          h('input', { className: 'input', value: policy.s3.region || '', placeholder: 'us-east-1', disabled: !isOwner, onChange: function(e) { setS3('region', e.target.value); } })
+        ),
+        h('div', { className: 'form-group', style: { marginBottom: 0 } },
+          h('label', { className: 'form-label' }, 'Key prefix (optional)'),
+          h('input', { className: 'input', value: policy.s3.prefix || '', placeholder: 'compliance/', disabled: !isOwner, onChange: function(e) { setS3('prefix', e.target.value); } })
+        ),
+        h('div', { className: 'form-group', style: { marginBottom: 0 } },
+          h('label', { className: 'form-label' }, 'Endpoint (S3-compatible, optional)'),
+          h('input', { className: 'input', value: policy.s3.endpoint || '', placeholder: 'https://...', disabled: !isOwner, onChange: function(e) { setS3('endpoint', e.target.value); } })
+        )
+      ),
+
+      daysOk && preview && h('div', { style: { marginTop: 16, padding: 12, borderRadius: 'var(--radius)', background: preview.count > 0 ? 'var(--warning-soft)' : 'var(--bg-tertiary)', fontSize: 13 } },
+        preview.count > 0
+          ? h(Fragment, null,
+              h('strong', null, preview.count.toLocaleString() + ' of ' + preview.totalEvents.toLocaleString() + ' events'),
+              ' are older than ' + new Date(preview.cutoff).toLocaleDateString() + ' and would be ' + (policy.archiveToS3 ? 'archived and purged' : 'purged') + ' on the next run',
+              preview.oldest ? ' (oldest from ' + new Date(preview.oldest).toLocaleDateString() + ').' : '.',
+              !policy.enabled && h('span', { style: { color: 'var(--text-muted)' } }, ' Automatic purging is off, so nothing will be removed until you enable it.')
+            )
+          : 'No events are older than ' + preview.retainDays + ' days; nothing would be purged.'
+      ),
+
+      last && h('div', { style: { display: 'flex', gap: 8, alignItems: 'center', fontSize: 13, marginTop: 12 } },
+        h('span', { className: 'badge badge-' + (last.error ? 'danger' : 'success') }, last.error ? 'failed' : 'last run'),
+        h('span', null, new Date(last.at).toLocaleString()),
+        h('span', { style: { color: 'var(--text-muted)' } }, last.error
+          ? last.error
+          : last.deleted.toLocaleString() + ' events purged' + (last.archived ? ', ' + last.archived.toLocaleString() + ' archived to ' + (last.archiveKeys || []).length + ' object(s)' : ''))
+      )
+    )
+  );
+}
+
 function TwoFactorCard({ toast }) {
   var [status, setStatus] = useState(null); // null=loading, true=enabled, false=disabled
   var [setupData, setSetupData] = useState(null); // { secret, otpauthUrl }
@@ -3367,6 +3749,1036 @@ function TwoFactorCard({ toast }) {
   );
 }
 
+// ─── Password Policy Card ───────────────────────────────
+// Rules for new passwords and how long a password lasts. Checked whenever a
+// password is set; an expired one sends the user to the change-password
+// screen at their next sign-in.
+
+var PASSWORD_RULE_FIELDS = [
+  { key: 'requireUppercase', label: 'Uppercase letter' },
+  { key: 'requireLowercase', label: 'Lowercase letter' },
+  { key: 'requireNumber', label: 'Number' },
+  { key: 'requireSymbol', label: 'Symbol' },
+];
+
+// ─── API Keys ──────────────────────────────────────
+// The plaintext key is returned once, by the create call. It's held in
+// state only until the admin dismisses the panel; after that the server
+// only has its hash and prefix.
+
+var API_KEY_SCOPES = [
+  { id: 'read', label: 'Read everything', description: 'Any GET request: agents, messages, audit log, settings.' },
+  { id: 'write', label: 'Write everything', description: 'Create, change and delete anything a member can. Includes read.' },
+  { id: 'admin', label: 'Admin', description: 'Admin-only routes such as users, settings and API keys. Includes read and write.' },
+];
+
+// Broad scopes as checkboxes, then one access level per area for keys that
+// should only touch part of the API (agents:write, audit:read...).
+function ApiKeyScopeEditor({ value, resources, onChange }) {
+  var has = function(s) { return value.indexOf(s) !== -1; };
+  var toggle = function(id) { onChange(has(id) ? value.filter(function(s) { return s !== id; }) : value.concat(id)); };
+  var levelOf = function(r) { return has(r.id + ':write') ? 'write' : has(r.id + ':read') ? 'read' : ''; };
+  var setLevel = function(r, level) {
+    var rest = value.filter(function(s) { return s.split(':')[0] !== r.id; });
+    onChange(level ? rest.concat(r.id + ':' + level) : rest);
+  };
+  var broad = has('*') || has('admin') || has('write');
+  return h('div', null,
+    API_KEY_SCOPES.map(function(sc) {
+      return h('label', { key: sc.id, style: { display: 'flex', gap: 8, alignItems: 'flex-start', padding: '4px 0', cursor: 'pointer', fontSize: 13 } },
+        h('input', { type: 'checkbox', checked: has(sc.id), onChange: function() { toggle(sc.id); }, style: { marginTop: 3 } }),
+        h('div', null, h('div', null, sc.label), h('div', { style: { fontSize: 12, color: 'var(--text-muted)' } }, sc.description))
+      );
+    }),
+    resources.length > 0 && h('div', { style: { marginTop: 12, opacity: broad ? 0.5 : 1 } },
+      h('div', { style: { fontSize: 12, fontWeight: 600, marginBottom: 6 } }, 'Or limit to specific areas'),
+      broad && h('div', { style: { fontSize: 12, color: 'var(--text-muted)', marginBottom: 6 } }, 'A broad write or admin scope already covers every area.'),
+      h('div', { style: { display: 'grid', gridTemplateColumns: '1fr 140px', gap: '6px 12px', alignItems: 'center' } },
+        resources.map(function(r) {
+          return h(Fragment, { key: r.id },
+            h('span', { style: { fontSize: 13 } }, r.label),
+            h('select', { className: 'input', value: levelOf(r), onChange: function(e) { setLevel(r, e.target.value); }, style: { padding: '4px 8px', fontSize: 12 } },
+              h('option', { value: '' }, 'No access'),
+              r.access.indexOf('read') !== -1 && h('option', { value: 'read' }, 'Read'),
+              r.access.indexOf('write') !== -1 && h('option', { value: 'write' }, 'Read & write')
+            )
+          );
+        })
+      )
+    )
+  );
+}
+
+/** One IPv4 address or CIDR range per line (commas work too) */
+function parseIpList(text) {
+  return text.split(/[\s,]+/).map(function(x) { return x.trim(); }).filter(Boolean);
+}
+
+var IP_OR_CIDR = /^(\d{1,3}\.){3}\d{1,3}(\/\d{1,2})?$/;
+
+function ApiKeyIpField({ value, onChange }) {
+  var bad = parseIpList(value).filter(function(x) { return !IP_OR_CIDR.test(x); });
+  return h('div', null,
+    h('textarea', { className: 'input', rows: 3, value: value, placeholder: '10.0.0.0/8\n203.0.113.7', onChange: function(e) { onChange(e.target.value); }, style: { fontFamily: 'var(--font-mono)', fontSize: 12, resize: 'vertical' } }),
+    h('div', { style: { fontSize: 12, color: bad.length ? 'var(--danger)' : 'var(--text-muted)', marginTop: 4 } }, bad.length
+      ? 'Not an IPv4 address or CIDR range: ' + bad.join(', ')
+      : 'One IPv4 address or CIDR range per line. Leave empty to allow any IP.')
+  );
+}
+
+function ApiKeyIpChips({ list }) {
+  if (!list || list.length === 0) return h('span', { style: { fontSize: 12, color: 'var(--text-muted)' } }, 'Any IP');
+  return h('span', { style: { display: 'inline-flex', flexWrap: 'wrap', gap: 4 }, title: list.join('\n') },
+    list.slice(0, 2).map(function(ip) { return h('span', { key: ip, className: 'badge badge-info', style: { fontFamily: 'var(--font-mono)', fontSize: 11 } }, ip); }),
+    list.length > 2 && h('span', { className: 'badge badge-neutral', style: { fontSize: 11 } }, '+' + (list.length - 2))
+  );
+}
+
+function ApiKeyScopeChips({ scopes }) {
+  return h('span', { style: { display: 'inline-flex', flexWrap: 'wrap', gap: 4 } },
+    (scopes || []).map(function(sc) {
+      var broad = sc === '*' || sc === 'admin' || sc === 'write';
+      return h('span', { key: sc, className: 'badge ' + (broad ? 'badge-warning' : 'badge-neutral'), style: { fontFamily: sc.indexOf(':') !== -1 ? 'var(--font-mono)' : undefined, fontSize: 11 } }, sc === '*' ? 'all' : sc);
+    })
+  );
+}
+var API_KEY_EXPIRY = [
+  { value: '30', label: '30 days' },
+  { value: '90', label: '90 days' },
+  { value: '365', label: '1 year' },
+  { value: 'custom', label: 'Custom date' },
+  { value: 'never', label: 'Never' },
+];
+
+var API_KEY_GRACE = [
+  { value: 0, label: 'Immediately' },
+  { value: 1, label: 'After 1 hour' },
+  { value: 24, label: 'After 24 hours' },
+  { value: 168, label: 'After 7 days' },
+];
+
+// Same window as the dashboard's Expiring API Keys card
+var API_KEY_EXPIRY_WARN_DAYS = 14;
+
+function apiKeyStatus(k) {
+  if (k.revoked) return { label: 'Revoked', cls: 'badge-danger' };
+  var left = k.expiresAt ? new Date(k.expiresAt).getTime() - Date.now() : Infinity;
+  if (left <= 0) return { label: 'Expired', cls: 'badge-danger' };
+  if (left < API_KEY_EXPIRY_WARN_DAYS * 86400000) return { label: 'Expires in ' + Math.ceil(left / 86400000) + 'd', cls: 'badge-warning' };
+  return { label: 'Active', cls: 'badge-success' };
+}
+
+// Calls made with a key are audited with actor "api_key:<id>"; creation,
+// rotation, revocation and edits have the key as their resource
+function ApiKeyAuditTrail({ apiKey }) {
+  var [events, setEvents] = useState(null);
+  var [error, setError] = useState(null);
+  var actor = 'api_key:' + apiKey.id;
+
+  useEffect(function() {
+    Promise.all([
+      apiCall('/audit?actor=' + encodeURIComponent(actor) + '&limit=200'),
+      apiCall('/audit?resource=' + encodeURIComponent(actor) + '&limit=200'),
+    ]).then(function(res) {
+      var seen = {};
+      var all = res[0].events.concat(res[1].events).filter(function(e) { if (seen[e.id]) return false; seen[e.id] = true; return true; });
+      all.sort(function(a, b) { return new Date(b.timestamp) - new Date(a.timestamp); });
+      setEvents(all);
+    }).catch(function(err) { setError(err.message); });
+  }, [apiKey.id]);
+
+  if (error) return h('div', { style: { padding: 16, color: 'var(--danger)', fontSize: 13 } }, 'Could not load the audit trail: ' + error);
+  if (!events) return h('div', { style: { padding: 16, color: 'var(--text-muted)', fontSize: 13 } }, 'Loading...');
+  if (events.length === 0) return h('div', { style: { padding: 16, color: 'var(--text-muted)', fontSize: 13 } }, 'No audit events for this key yet.');
+  return h('div', { style: { maxHeight: 420, overflowY: 'auto' } },
+    h('table', null,
+      h('thead', null, h('tr', null, h('th', null, 'Time'), h('th', null, 'Action'), h('th', null, 'Resource'), h('th', null, 'By'), h('th', null, 'IP'))),
+      h('tbody', null, events.map(function(e) {
+        var byKey = e.actor === actor;
+        return h('tr', { key: e.id },
+          h('td', { style: { fontSize: 12, color: 'var(--text-muted)', whiteSpace: 'nowrap' } }, h(RelativeTime, { value: e.timestamp })),
+          h('td', null, h('span', { className: 'badge ' + (byKey ? 'badge-neutral' : 'badge-info'), style: { fontSize: 11 } }, e.action)),
+          h('td', { style: { fontFamily: 'var(--font-mono)', fontSize: 11, wordBreak: 'break-all' } }, e.resource),
+          h('td', { style: { fontSize: 12 } }, byKey ? 'This key' : (e.details && e.details.email) || e.actor),
+          h('td', { style: { fontSize: 12, color: 'var(--text-muted)' } }, e.ip || '-')
+        );
+      }))
+    )
+  );
+}
+
+function ApiKeysCard({ toast }) {
+  var [keys, setKeys] = useState([]);
+  var [creating, setCreating] = useState(false);
+  var [form, setForm] = useState({ name: '', scopes: ['read'], expiry: '90', expiresOn: '', ips: '', agentId: '' });
+  var [saving, setSaving] = useState(false);
+  var [secret, setSecret] = useState(null); // { name, plaintext, note }
+  var [copied, setCopied] = useState(false);
+  var [rotating, setRotating] = useState(null);
+  var [grace, setGrace] = useState(24);
+  var [resources, setResources] = useState([]);
+  var [editing, setEditing] = useState(null); // { key, scopes }
+  var [editingIps, setEditingIps] = useState(null); // { key, text }
+  var [viewing, setViewing] = useState(null); // { key, tab }
+  var [agents, setAgents] = useState([]);
+  var [agentFilter, setAgentFilter] = useState('');
+
+  useEffect(function() { apiCall('/api-keys/scopes').then(function(d) { setResources(d.resources || []); }).catch(function() {}); }, []);
+  useEffect(function() { apiCall('/agents?limit=200').then(function(d) { setAgents(d.agents || []); }).catch(function() {}); }, []);
+  var agentName = function(id) { var a = agents.find(function(x) { return x.id === id; }); return a ? a.name : id.slice(0, 8); };
+
+  var saveIps = function() {
+    setSaving(true);
+    apiCall('/api-keys/' + editingIps.key.id + '/ip-allowlist', { method: 'PUT', body: JSON.stringify({ ipAllowlist: parseIpList(editingIps.text) }) })
+      .then(function() { toast('IP restrictions updated', 'success'); setEditingIps(null); load(); })
+      .catch(function(err) { toast(err.message, 'error'); })
+      .finally(function() { setSaving(false); });
+  };
+
+  var saveScopes = function() {
+    setSaving(true);
+    apiCall('/api-keys/' + editing.key.id + '/scopes', { method: 'PUT', body: JSON.stringify({ scopes: editing.scopes }) })
+      .then(function() { toast('Scopes updated', 'success'); setEditing(null); load(); })
+      .catch(function(err) { toast(err.message, 'error'); })
+      .finally(function() { setSaving(false); });
+  };
+
+  var load = function() { apiCall('/api-keys' + (agentFilter ? '?agentId=' + encodeURIComponent(agentFilter) : '')).then(function(d) { setKeys(d.keys || []); }).catch(function() {}); };
+  useEffect(load, [agentFilter]);
+
+  var set = function(k, v) { setForm(function(f) { return Object.assign({}, f, { [k]: v }); }); };
+  var expiresAt = function() {
+    if (form.expiry === 'never') return undefined;
+    if (form.expiry === 'custom') return form.expiresOn ? new Date(form.expiresOn + 'T23:59:59').toISOString() : null;
+    return new Date(Date.now() + Number(form.expiry) * 86400000).toISOString();
+  };
+  var valid = form.name.trim() && form.scopes.length > 0 && expiresAt() !== null && parseIpList(form.ips).every(function(x) { return IP_OR_CIDR.test(x); });
+
+  var create = function() {
+    setSaving(true);
+    apiCall('/api-keys', { method: 'POST', body: JSON.stringify({ name: form.name.trim(), scopes: form.scopes, expiresAt: expiresAt(), ipAllowlist: parseIpList(form.ips), agentId: form.agentId || undefined }) })
+      .then(function(d) {
+        setSecret({ name: d.key.name, plaintext: d.plaintext });
+        setCopied(false);
+        setCreating(false);
+        setForm({ name: '', scopes: ['read'], expiry: '90', expiresOn: '', ips: '', agentId: '' });
+        load();
+      })
+      .catch(function(err) { toast(err.message, 'error'); })
+      .finally(function() { setSaving(false); });
+  };
+
+  var copy = function() {
+    navigator.clipboard.writeText(secret.plaintext)
+      .then(function() { setCopied(true); toast('Copied to clipboard', 'success'); })
+      .catch(function() { toast('Copy failed — select the key and copy it manually', 'error'); });
+  };
+
+  var dismissSecret = async function() {
+    if (!copied) {
+      var ok = await showConfirm({ title: 'Close without copying?', message: 'You haven\'t copied this key. Once you close this panel it can\'t be shown again — you would have to create a new key.', confirmText: 'Close Anyway', danger: true });
+      if (!ok) return;
+    }
+    setSecret(null);
+  };
+
+  var rotate = function() {
+    setSaving(true);
+    apiCall('/api-keys/' + rotating.id + '/rotate', { method: 'POST', body: JSON.stringify({ graceHours: grace }) })
+      .then(function(d) {
+        var note = d.previous.revoked
+          ? 'The old key has been revoked.'
+          : 'The old key keeps working until ' + new Date(d.previous.expiresAt).toLocaleString() + '. Switch your integrations over before then.';
+        setSecret({ name: d.key.name, plaintext: d.plaintext, note: note });
+        setCopied(false);
+        setRotating(null);
+        load();
+      })
+      .catch(function(err) { toast(err.message, 'error'); })
+      .finally(function() { setSaving(false); });
+  };
+
+  var revoke = async function(k) {
+    var ok = await showConfirm({ title: 'Revoke API Key', message: 'Revoke "' + k.name + '"? Any applications using this key will immediately lose access.', warning: 'This action cannot be undone. You will need to create a new key.', danger: true, confirmText: 'Revoke Key' });
+    if (!ok) return;
+    apiCall('/api-keys/' + k.id, { method: 'DELETE' })
+      .then(function() { toast('Key revoked', 'success'); load(); })
+      .catch(function(err) { toast(err.message, 'error'); });
+  };
+
+  var today = new Date().toISOString().slice(0, 10);
+
+  return h(Fragment, null,
+    secret && h('div', { className: 'card', style: { marginBottom: 16, border: '1px solid var(--warning)' } },
+      h('div', { className: 'card-body' },
+        h('div', { style: { display: 'flex', alignItems: 'center', gap: 8, marginBottom: 8 } }, I.key(), h('strong', null, 'New key: ' + secret.name)),
+        h('div', { style: { padding: 12, background: 'var(--warning-soft)', borderRadius: 'var(--radius)', fontSize: 13, color: 'var(--warning)', marginBottom: 12 } },
+          'Copy this key now and store it somewhere safe. This is the only time it will be shown.'),
+        h('div', { style: { display: 'flex', gap: 8, alignItems: 'center' } },
+          h('input', { className: 'input', value: secret.plaintext, readOnly: true, style: { fontFamily: 'var(--font-mono)', fontSize: 12, flex: 1 }, onFocus: function(e) { e.target.select(); } }),
+          h('button', { className: 'btn ' + (copied ? 'btn-secondary' : 'btn-primary'), onClick: copy }, copied ? [I.check(), ' Copied'] : [I.copy(), ' Copy']),
+          h('button', { className: 'btn btn-secondary', onClick: dismissSecret }, 'Done')
+        ),
+        h('div', { style: { fontSize: 12, color: 'var(--text-muted)', marginTop: 8 } }, secret.note ? secret.note + ' ' : '', 'Send it in the ', h('code', null, 'X-API-Key'), ' header.')
+      )
+    ),
+
+    creating && h(Modal, {
+      title: 'Create API Key',
+      onClose: function() { setCreating(false); },
+      width: 480,
+      footer: h(Fragment, null,
+        h('button', { className: 'btn btn-secondary', onClick: function() { setCreating(false); } }, 'Cancel'),
+        h('button', { className: 'btn btn-primary', onClick: create, disabled: saving || !valid }, saving ? 'Creating...' : 'Create Key')
+      )
+    },
+      h('div', { className: 'form-group' },
+        h('label', { className: 'form-label' }, 'Name'),
+        h('input', { className: 'input', value: form.name, maxLength: 64, autoFocus: true, placeholder: 'e.g. CI pipeline, Zapier', onChange: function(e) { set('name', e.target.value); } })
+      ),
+      h('div', { className: 'form-group' },
+        h('label', { className: 'form-label' }, 'Scopes'),
+        h(ApiKeyScopeEditor, { value: form.scopes, resources: resources, onChange: function(v) { set('scopes', v); } }),
+        form.scopes.length === 0 && h('div', { style: { fontSize: 12, color: 'var(--danger)', marginTop: 4 } }, 'Pick at least one scope.')
+      ),
+      h('div', { className: 'form-group' },
+        h('label', { className: 'form-label' }, 'Expires'),
+        h('div', { style: { display: 'flex', gap: 8 } },
+          h('select', { className: 'input', value: form.expiry, onChange: function(e) { set('expiry', e.target.value); }, style: { flex: 1 } },
+            API_KEY_EXPIRY.map(function(o) { return h('option', { key: o.value, value: o.value }, o.label); })
+          ),
+          form.expiry === 'custom' && h('input', { className: 'input', type: 'date', min: today, value: form.expiresOn, onChange: function(e) { set('expiresOn', e.target.value); }, style: { flex: 1 } })
+        ),
+        form.expiry === 'never' && h('div', { style: { fontSize: 12, color: 'var(--text-muted)', marginTop: 4 } }, 'Keys that never expire keep working until someone revokes them.')
+      ),
+      h('div', { className: 'form-group' },
+        h('label', { className: 'form-label' }, 'Allowed IPs (optional)'),
+        h(ApiKeyIpField, { value: form.ips, onChange: function(v) { set('ips', v); } })
+      ),
+      h('div', { className: 'form-group' },
+        h('label', { className: 'form-label' }, 'Bind to agent (optional)'),
+        h('select', { className: 'input', value: form.agentId, onChange: function(e) { set('agentId', e.target.value); } },
+          h('option', { value: '' }, 'Not bound — any agent'),
+          agents.filter(function(a) { return a.status !== 'archived'; }).map(function(a) { return h('option', { key: a.id, value: a.id }, a.name); })
+        ),
+        h('div', { style: { fontSize: 12, color: 'var(--text-muted)', marginTop: 4 } }, form.agentId
+          ? 'A service-account key for this agent\'s integrations. It only works on requests about this agent and is revoked if the agent is archived.'
+          : 'Bind the key to one agent when it\'s for that agent\'s external integrations.')
+      )
+    ),
+
+    viewing && h(Modal, {
+      title: 'API Key — ' + viewing.key.name,
+      onClose: function() { setViewing(null); },
+      width: 760,
+    },
+      h('div', { className: 'tabs', style: { marginBottom: 12 } },
+        [['details', 'Details'], ['audit', 'Audit Trail']].map(function(t) {
+          return h('div', { key: t[0], className: 'tab' + (viewing.tab === t[0] ? ' active' : ''), onClick: function() { setViewing(Object.assign({}, viewing, { tab: t[0] })); }, style: { cursor: 'pointer' } }, t[1]);
+        })
+      ),
+      viewing.tab === 'details' && h('div', { style: { display: 'grid', gridTemplateColumns: '140px 1fr', gap: '8px 12px', fontSize: 13 } },
+        h('span', { style: { color: 'var(--text-muted)' } }, 'Key prefix'), h('span', { style: { fontFamily: 'var(--font-mono)' } }, (viewing.key.keyPrefix || '???') + '...'),
+        h('span', { style: { color: 'var(--text-muted)' } }, 'Scopes'), h(ApiKeyScopeChips, { scopes: viewing.key.scopes }),
+        h('span', { style: { color: 'var(--text-muted)' } }, 'Allowed IPs'), h(ApiKeyIpChips, { list: viewing.key.ipAllowlist }),
+        h('span', { style: { color: 'var(--text-muted)' } }, 'Agent'), h('span', null, viewing.key.agentId ? agentName(viewing.key.agentId) : 'Any'),
+        h('span', { style: { color: 'var(--text-muted)' } }, 'Created'), h('span', null, viewing.key.createdAt ? new Date(viewing.key.createdAt).toLocaleString() : '-'),
+        h('span', { style: { color: 'var(--text-muted)' } }, 'Expires'), h('span', null, viewing.key.expiresAt ? new Date(viewing.key.expiresAt).toLocaleString() : 'Never'),
+        h('span', { style: { color: 'var(--text-muted)' } }, 'Last used'), h('span', null, viewing.key.lastUsedAt ? h(RelativeTime, { value: viewing.key.lastUsedAt }) : 'Never'),
+        h('span', { style: { color: 'var(--text-muted)' } }, 'Status'), h('span', null, h('span', { className: 'badge ' + apiKeyStatus(viewing.key).cls }, apiKeyStatus(viewing.key).label))
+      ),
+      viewing.tab === 'audit' && h(ApiKeyAuditTrail, { apiKey: viewing.key })
+    ),
+
+    editingIps && h(Modal, {
+      title: 'IP Restrictions — ' + editingIps.key.name,
+      onClose: function() { setEditingIps(null); },
+      width: 440,
+      footer: h(Fragment, null,
+        h('button', { className: 'btn btn-secondary', onClick: function() { setEditingIps(null); } }, 'Cancel'),
+        h('button', { className: 'btn btn-primary', onClick: saveIps, disabled: saving || parseIpList(editingIps.text).some(function(x) { return !IP_OR_CIDR.test(x); }) }, saving ? 'Saving...' : 'Save')
+      )
+    },
+      h('p', { style: { fontSize: 13, color: 'var(--text-secondary)', marginBottom: 12 } }, 'Requests with this key from any other address are refused, so a leaked key can\'t be used outside your network. If the server is behind a proxy, set up trusted proxies under Network & Firewall so the real client IP is used.'),
+      h(ApiKeyIpField, { value: editingIps.text, onChange: function(v) { setEditingIps(Object.assign({}, editingIps, { text: v })); } })
+    ),
+
+    editing && h(Modal, {
+      title: 'Edit Scopes — ' + editing.key.name,
+      onClose: function() { setEditing(null); },
+      width: 480,
+      footer: h(Fragment, null,
+        h('button', { className: 'btn btn-secondary', onClick: function() { setEditing(null); } }, 'Cancel'),
+        h('button', { className: 'btn btn-primary', onClick: saveScopes, disabled: saving || editing.scopes.length === 0 }, saving ? 'Saving...' : 'Save Scopes')
+      )
+    },
+      h('p', { style: { fontSize: 13, color: 'var(--text-secondary)', marginBottom: 12 } }, 'Changes apply to the next request made with this key.'),
+      h(ApiKeyScopeEditor, { value: editing.scopes, resources: resources, onChange: function(v) { setEditing(Object.assign({}, editing, { scopes: v })); } }),
+      editing.scopes.length === 0 && h('div', { style: { fontSize: 12, color: 'var(--danger)', marginTop: 4 } }, 'Pick at least one scope.')
+    ),
+
+    rotating && h(Modal, {
+      title: 'Rotate API Key',
+      onClose: function() { setRotating(null); },
+      width: 440,
+      footer: h(Fragment, null,
+        h('button', { className: 'btn btn-secondary', onClick: function() { setRotating(null); } }, 'Cancel'),
+        h('button', { className: 'btn btn-primary', onClick: rotate, disabled: saving }, saving ? 'Rotating...' : 'Rotate Key')
+      )
+    },
+      h('p', { style: { fontSize: 13, color: 'var(--text-secondary)', marginBottom: 12 } },
+        'Create a replacement for ', h('strong', null, rotating.name), ' with the same scopes. The new key is shown once.'),
+      h('div', { className: 'form-group' },
+        h('label', { className: 'form-label' }, 'Stop the old key'),
+        h('select', { className: 'input', value: grace, onChange: function(e) { setGrace(Number(e.target.value)); } },
+          API_KEY_GRACE.map(function(o) { return h('option', { key: o.value, value: o.value }, o.label); })
+        ),
+        h('div', { style: { fontSize: 12, color: 'var(--text-muted)', marginTop: 4 } }, grace === 0
+          ? 'Anything still using the old key stops working as soon as you rotate.'
+          : 'Both keys work during the grace period, so you can update integrations without downtime.')
+      )
+    ),
+
+    h('div', { className: 'card' },
+      h('div', { className: 'card-header', style: { display: 'flex', alignItems: 'center', justifyContent: 'space-between' } },
+        h('h3', null, 'API Keys'),
+        h('div', { style: { display: 'flex', gap: 8, alignItems: 'center' } },
+          agents.length > 0 && h('select', { className: 'input', value: agentFilter, onChange: function(e) { setAgentFilter(e.target.value); }, style: { width: 180, padding: '4px 8px', fontSize: 13 } },
+            h('option', { value: '' }, 'All keys'),
+            agents.map(function(a) { return h('option', { key: a.id, value: a.id }, 'Agent: ' + a.name); })
+          ),
+          h('button', { className: 'btn btn-primary btn-sm', onClick: function() { set('agentId', agentFilter); setCreating(true); } }, I.plus(), ' Create Key')
+        )
+      ),
+      h('div', { className: 'card-body-flush' },
+        keys.length === 0 ? h('div', { style: { padding: 24, textAlign: 'center', color: 'var(--text-muted)' } }, agentFilter ? 'No keys bound to ' + agentName(agentFilter) : 'No API keys yet')
+        : h('table', null,
+            h('thead', null, h('tr', null, h('th', null, 'Name'), h('th', null, 'Key Prefix'), h('th', null, 'Scopes'), h('th', null, 'Allowed IPs'), h('th', null, 'Agent'), h('th', null, 'Created'), h('th', null, 'Expires'), h('th', null, 'Last Used'), h('th', null, 'Status'), h('th', null, 'Actions'))),
+            h('tbody', null, keys.map(function(k) {
+              var status = apiKeyStatus(k);
+              return h('tr', { key: k.id, style: k.revoked ? { opacity: 0.6 } : null },
+                h('td', null, h('a', { href: '#', onClick: function(e) { e.preventDefault(); setViewing({ key: k, tab: 'details' }); } }, h('strong', null, k.name))),
+                h('td', null, h('span', { style: { fontFamily: 'var(--font-mono)', fontSize: 12 } }, (k.keyPrefix || '???') + '...')),
+                h('td', null, h(ApiKeyScopeChips, { scopes: k.scopes })),
+                h('td', null, h(ApiKeyIpChips, { list: k.ipAllowlist })),
+                h('td', null, k.agentId
+                  ? h('span', { className: 'badge badge-info', style: { fontSize: 11, cursor: 'pointer' }, title: 'Show only this agent\'s keys', onClick: function() { setAgentFilter(k.agentId); } }, agentName(k.agentId))
+                  : h('span', { style: { fontSize: 12, color: 'var(--text-muted)' } }, 'Any')),
+                h('td', { style: { fontSize: 12, color: 'var(--text-muted)' } }, k.createdAt ? h(RelativeTime, { value: k.createdAt }) : '-'),
+                h('td', { style: { fontSize: 12, color: 'var(--text-muted)' } }, k.expiresAt ? new Date(k.expiresAt).toLocaleDateString() : 'Never'),
+                h('td', { style: { fontSize: 12, color: 'var(--text-muted)' } }, k.lastUsedAt ? h(RelativeTime, { value: k.lastUsedAt }) : 'Never'),
+                h('td', null, h('span', { className: 'badge ' + status.cls }, status.label)),
+                h('td', null, h('div', { style: { display: 'flex', gap: 4 } },
+                  h('button', { className: 'btn btn-ghost btn-sm', title: 'Audit trail', onClick: function() { setViewing({ key: k, tab: 'audit' }); } }, I.clock()),
+                  !k.revoked && status.label !== 'Expired' && h(Fragment, null,
+                  h('button', { className: 'btn btn-ghost btn-sm', title: 'Edit scopes', onClick: function() { setEditing({ key: k, scopes: (k.scopes || []).slice() }); } }, I.edit()),
+                  h('button', { className: 'btn btn-ghost btn-sm', title: 'Restrict by IP', onClick: function() { setEditingIps({ key: k, text: (k.ipAllowlist || []).join('\n') }); } }, I.globe()),
+                  h('button', { className: 'btn btn-secondary btn-sm', title: 'Replace with a new key', onClick: function() { setGrace(24); setRotating(k); } }, I.refresh(), ' Rotate'),
+                  h('button', { className: 'btn btn-danger btn-sm', onClick: function() { revoke(k); } }, 'Revoke')
+                  )
+                ))
+              );
+            }))
+          )
+      )
+    )
+  );
+}
+
+function PasswordPolicyCard({ toast }) {
+  var [data, setData] = useState(null);
+  var [policy, setPolicy] = useState(null);
+  var [saving, setSaving] = useState(false);
+
+  var load = function() {
+    apiCall('/settings/password-policy').then(function(d) { setData(d); setPolicy(d.policy); })
+      .catch(function(err) { toast('Failed to load password policy: ' + err.message, 'error'); });
+  };
+  useEffect(load, []);
+
+  if (!policy) return null;
+  var set = function(k, v) { setPolicy(Object.assign({}, policy, { [k]: v })); };
+  var dirty = JSON.stringify(policy) !== JSON.stringify(data.policy);
+  var save = function() {
+    setSaving(true);
+    apiCall('/settings/password-policy', { method: 'PUT', body: JSON.stringify({ policy: policy }) })
+      .then(function() { toast('Password policy saved', 'success'); load(); })
+      .catch(function(err) { toast(err.message, 'error'); })
+      .finally(function() { setSaving(false); });
+  };
+
+  return h('div', { className: 'card', style: { marginBottom: 16 } },
+    h('div', { className: 'card-header' }, h('h3', { style: { display: 'flex', alignItems: 'center' } }, I.lock(), ' Password Policy', h(HelpButton, { label: 'Password Policy' },
+      h('p', null, 'These rules apply whenever a password is set: when an admin adds a user or resets a password, and when a user resets or changes their own.'),
+      h('p', null, 'Existing passwords are not checked against new rules. To make everyone pick a compliant password, set a maximum age or use "Require Password Change" on the Users page.'),
+      h('p', null, 'With a maximum age, users whose password is older than that are asked for a new one the next time they sign in. Accounts that only use SSO are not affected.')
+    ))),
+    h('div', { className: 'card-body' },
+      h('div', { style: { display: 'grid', gridTemplateColumns: '1fr 1fr', gap: 16 } },
+        h('div', { className: 'form-group' },
+          h('label', { className: 'form-label' }, 'Minimum length'),
+          h('input', { className: 'input', type: 'number', min: 8, max: 64, value: policy.minLength, onChange: function(e) { set('minLength', parseInt(e.target.value) || 8); } })
+        ),
+        h('div', { className: 'form-group' },
+          h('label', { className: 'form-label' }, 'Maximum age (days)'),
+          h('input', { className: 'input', type: 'number', min: 0, max: 3650, value: policy.maxAgeDays, onChange: function(e) { set('maxAgeDays', parseInt(e.target.value) || 0); } }),
+          h('div', { style: { fontSize: 11, color: 'var(--text-muted)', marginTop: 4 } }, policy.maxAgeDays ? 'Passwords must be changed every ' + policy.maxAgeDays + ' days' : '0 = passwords never expire')
+        )
+      ),
+      h('label', { className: 'form-label' }, 'Must contain'),
+      h('div', { style: { display: 'flex', gap: 16, flexWrap: 'wrap', marginBottom: 16 } },
+        PASSWORD_RULE_FIELDS.map(function(f) {
+          return h('label', { key: f.key, style: { display: 'flex', alignItems: 'center', gap: 6, fontSize: 13 } },
+            h('input', { type: 'checkbox', checked: !!policy[f.key], onChange: function(e) { set(f.key, e.target.checked); } }), f.label);
+        })
+      ),
+      h('div', { style: { display: 'flex', justifyContent: 'flex-end', gap: 8 } },
+        JSON.stringify(policy) !== JSON.stringify(data.defaults) && h('button', { className: 'btn btn-ghost btn-sm', onClick: function() { setPolicy(data.defaults); } }, 'Restore Defaults'),
+        dirty && h('button', { className: 'btn btn-ghost btn-sm', onClick: function() { setPolicy(data.policy); } }, 'Discard'),
+        h('button', { className: 'btn btn-primary btn-sm', disabled: !dirty || saving || policy.minLength < 8, onClick: save }, saving ? 'Saving...' : 'Save Policy')
+      )
+    )
+  );
+}
+
+// ─── Data Residency ─────────────────────────────────────
+// Where each subsystem stores/processes data, and for multi-region
+// deployments the residency regions agents (and their mailboxes) are pinned to.
+
+var RESIDENCY_STATUS = {
+  ok: { label: 'In region', cls: 'badge-success' },
+  outside: { label: 'Outside region', cls: 'badge-danger' },
+  unverified: { label: 'Not verified', cls: 'badge-warning' },
+  unpinned: { label: 'Not pinned', cls: 'badge-neutral' },
+};
+
+function DataResidencyTab({ toast }) {
+  var [report, setReport] = useState(null);
+  var [config, setConfig] = useState(null);
+  var [dirty, setDirty] = useState(false);
+  var [saving, setSaving] = useState(false);
+
+  var load = function() {
+    engineCall('/residency?orgId=' + getOrgId()).then(function(d) {
+      setReport(d);
+      setConfig(d.config);
+      setDirty(false);
+    }).catch(function(err) { toast('Failed to load data residency: ' + err.message, 'error'); });
+  };
+  useEffect(load, []);
+
+  if (!report || !config) return h('div', { style: { padding: 40, textAlign: 'center', color: 'var(--text-muted)' } }, 'Loading...');
+
+  var patch = function(changes) { setConfig(Object.assign({}, config, changes)); setDirty(true); };
+  var patchRegion = function(i, changes) {
+    patch({ regions: config.regions.map(function(r, j) { return j === i ? Object.assign({}, r, changes) : r; }) });
+  };
+  var declare = function(key, value) { patch({ declared: Object.assign({}, config.declared, { [key]: value || undefined }) }); };
+
+  var save = function() {
+    setSaving(true);
+    engineCall('/residency/config', { method: 'PUT', body: JSON.stringify(config) })
+      .then(function() { toast('Data residency saved', 'success'); load(); })
+      .catch(function(err) { toast(err.message, 'error'); })
+      .finally(function() { setSaving(false); });
+  };
+
+  var pin = function(agentId, region) {
+    engineCall('/residency/agents/' + agentId, { method: 'PUT', body: JSON.stringify({ region: region || null }) })
+      .then(function() { toast(region ? 'Agent pinned to ' + region : 'Agent unpinned', 'success'); load(); })
+      .catch(function(err) { toast(err.message, 'error'); });
+  };
+
+  var regionLabel = function(id) {
+    var r = config.regions.find(function(x) { return x.id === id; });
+    return r ? r.label : id;
+  };
+
+  return h('div', null,
+    // ── WHERE DATA LIVES ──
+    h('div', { style: _sectionTitleStyle }, 'Where your data lives'),
+    h('div', { style: _cardStyle },
+      h('div', { style: _cardTitleStyle }, I.database(), ' Storage and processing locations'),
+      h('div', { style: _cardDescStyle }, 'Regions are detected from the deployment where possible. Declare the rest so the table is complete for customer questionnaires.'),
+      h('table', { className: 'data-table' },
+        h('thead', null, h('tr', null, h('th', null, 'Subsystem'), h('th', null, 'Provider'), h('th', null, 'Location'), h('th', null, 'Detail'))),
+        h('tbody', null, report.subsystems.map(function(sub) {
+          return h('tr', { key: sub.key },
+            h('td', null, h('strong', null, sub.label)),
+            h('td', null, h('code', { style: { fontSize: 12 } }, sub.provider)),
+            h('td', null, sub.source === 'detected'
+              ? h('span', { style: { display: 'inline-flex', alignItems: 'center', gap: 6 } }, h('code', null, sub.location), h('span', { className: 'badge badge-success', style: { fontSize: 10 } }, 'Detected'))
+              : h('span', { style: { display: 'inline-flex', alignItems: 'center', gap: 6 } },
+                  h('input', { className: 'input', style: { width: 180, fontSize: 12, padding: '3px 8px' }, placeholder: 'e.g. eu-central-1', value: (config.declared || {})[sub.key] || '', onChange: function(e) { declare(sub.key, e.target.value); } }),
+                  h('span', { className: 'badge ' + (sub.source === 'declared' ? 'badge-info' : 'badge-warning'), style: { fontSize: 10 } }, sub.source === 'declared' ? 'Declared' : 'Unknown')
+                )
+            ),
+            h('td', { style: { fontSize: 12, color: 'var(--text-muted)' } }, sub.detail || '-')
+          );
+        }))
+      )
+    ),
+
+    // ── RESIDENCY REGIONS ──
+    h('div', { style: _sectionTitleStyle }, 'Residency regions'),
+    h('div', { style: _cardStyle },
+      h('div', { style: _cardTitleStyle }, I.globe(), ' Regions', h(HelpButton, { label: 'Residency Regions' },
+        h('p', null, 'For multi-region deployments, define the regions customer data may live in (e.g. EU and US) and the cloud regions that count as inside each.'),
+        h('p', null, 'New agents and their mailboxes are pinned to the default region unless another is chosen when creating them. When a pinned agent is deployed to the cloud without a region, the first cloud region of its residency region is used.'),
+        h('p', null, h('strong', null, 'Enforce'), ' makes deploys to a cloud region outside the agent\'s residency region fail instead of only being reported.')
+      )),
+      h('div', { style: _cardDescStyle }, 'Pin agents and mailboxes to a region so EU customer data stays in the EU.'),
+      config.regions.length === 0 && h('div', { style: { fontSize: 13, color: 'var(--text-muted)', marginBottom: 12 } }, 'No regions defined. This deployment is treated as single-region.'),
+      config.regions.map(function(r, i) {
+        return h('div', { key: i, style: { border: '1px solid var(--border)', borderRadius: 'var(--radius)', padding: 12, marginBottom: 10 } },
+          h('div', { style: { display: 'grid', gridTemplateColumns: '120px 1fr 1fr auto', gap: 8, alignItems: 'end', marginBottom: 8 } },
+            h('div', null, h('label', { className: 'field-label' }, 'ID'), h('input', { className: 'input', value: r.id, placeholder: 'eu', onChange: function(e) { patchRegion(i, { id: e.target.value.toLowerCase() }); } })),
+            h('div', null, h('label', { className: 'field-label' }, 'Label'), h('input', { className: 'input', value: r.label, placeholder: 'European Union', onChange: function(e) { patchRegion(i, { label: e.target.value }); } })),
+            h('div', null, h('label', { className: 'field-label' }, 'Jurisdiction'), h('input', { className: 'input', value: r.jurisdiction || '', placeholder: 'EU (GDPR)', onChange: function(e) { patchRegion(i, { jurisdiction: e.target.value }); } })),
+            h('button', { className: 'btn btn-ghost btn-icon', title: 'Remove region', onClick: function() { patch({ regions: config.regions.filter(function(_, j) { return j !== i; }), defaultRegion: config.defaultRegion === r.id ? undefined : config.defaultRegion }); } }, I.trash())
+          ),
+          h(TagInput, { label: 'Cloud regions inside it', value: r.deployRegions || [], onChange: function(v) { patchRegion(i, { deployRegions: v }); }, placeholder: 'fra, ams, eu-central-1', mono: true })
+        );
+      }),
+      h('button', { className: 'btn btn-secondary btn-sm', onClick: function() { patch({ regions: config.regions.concat([{ id: '', label: '', deployRegions: [] }]) }); } }, I.plus(), ' Add region'),
+      config.regions.length > 0 && h('div', { style: { marginTop: 16 } },
+        h('label', { style: { display: 'block', fontSize: 12, fontWeight: 600, color: 'var(--text-secondary)', marginBottom: 4 } }, 'Default region for new agents'),
+        h('select', { className: 'input', style: { width: 280, marginBottom: 8 }, value: config.defaultRegion || '', onChange: function(e) { patch({ defaultRegion: e.target.value || undefined }); } },
+          h('option', { value: '' }, 'None (leave unpinned)'),
+          config.regions.filter(function(r) { return r.id; }).map(function(r) { return h('option', { key: r.id, value: r.id }, r.label || r.id); })
+        ),
+        h(ToggleSwitch, { label: 'Enforce: fail deploys outside the agent\'s region', checked: config.enforce === true, onChange: function(v) { patch({ enforce: v }); } })
+      )
+    ),
+
+    // ── AGENTS ──
+    config.regions.length > 0 && h(Fragment, null,
+      h('div', { style: _sectionTitleStyle }, 'Agents and mailboxes'),
+      h('div', { style: _cardStyle },
+        h('div', { style: _cardDescStyle }, 'Each agent\'s mailbox, memory and messages follow its pinned region. Changes apply from the next deploy.'),
+        report.agents.length === 0
+          ? h('div', { style: { fontSize: 13, color: 'var(--text-muted)' } }, 'No agents yet.')
+          : h('table', { className: 'data-table' },
+              h('thead', null, h('tr', null, h('th', null, 'Agent'), h('th', null, 'Pinned region'), h('th', null, 'Hosted in'), h('th', null, 'Status'))),
+              h('tbody', null, report.agents.map(function(a) {
+                var st = RESIDENCY_STATUS[a.status] || RESIDENCY_STATUS.unpinned;
+                return h('tr', { key: a.agentId },
+                  h('td', null, h('strong', null, a.name)),
+                  h('td', null, h('select', { className: 'input', style: { width: 'auto', fontSize: 12 }, value: a.dataRegion || '', disabled: dirty, title: dirty ? 'Save region changes first' : undefined, onChange: function(e) { pin(a.agentId, e.target.value); } },
+                    h('option', { value: '' }, 'Not pinned'),
+                    report.config.regions.map(function(r) { return h('option', { key: r.id, value: r.id }, regionLabel(r.id)); })
+                  )),
+                  h('td', null, a.hostRegion ? h('code', { style: { fontSize: 12 } }, a.hostRegion) : h('span', { style: { color: 'var(--text-muted)', fontSize: 12 } }, a.target || '-')),
+                  h('td', null, h('span', { className: 'badge ' + st.cls }, st.label))
+                );
+              }))
+            )
+      )
+    ),
+
+    h('div', { style: { display: 'flex', justifyContent: 'flex-end', gap: 8 } },
+      dirty && h('button', { className: 'btn btn-ghost', onClick: load }, 'Discard'),
+      h('button', { className: 'btn btn-primary', disabled: !dirty || saving, onClick: save }, saving ? 'Saving...' : 'Save')
+    )
+  );
+}
+
+// ─── AI Disclosure ──────────────────────────────────────
+// Disclosure footers on agent email and consent capture for tracked
+// conversations, with per-jurisdiction wording and a log of what was applied.
+
+var CONSENT_STATUS = {
+  requested: { label: 'Requested', cls: 'badge-warning' },
+  granted: { label: 'Granted', cls: 'badge-success' },
+  declined: { label: 'Declined', cls: 'badge-danger' },
+  withdrawn: { label: 'Withdrawn', cls: 'badge-neutral' },
+};
+
+function AIDisclosureTab({ toast }) {
+  var [config, setConfig] = useState(null);
+  var [dirty, setDirty] = useState(false);
+  var [saving, setSaving] = useState(false);
+  var [consents, setConsents] = useState([]);
+  var [consentFilter, setConsentFilter] = useState('');
+  var [log, setLog] = useState({ entries: [], total: 0 });
+  var [logPage, setLogPage] = useState(1);
+  var [newConsent, setNewConsent] = useState({ contact: '', status: 'granted' });
+  var [agents, setAgents] = useState([]);
+  var LOG_PAGE_SIZE = 25;
+
+  var load = function() {
+    engineCall('/disclosure/config').then(function(d) { setConfig(d.config); setDirty(false); })
+      .catch(function(err) { toast('Failed to load AI disclosure settings: ' + err.message, 'error'); });
+  };
+  var loadConsents = function() {
+    engineCall('/disclosure/consents?orgId=' + getOrgId() + (consentFilter ? '&status=' + consentFilter : ''))
+      .then(function(d) { setConsents(d.consents || []); }).catch(function() {});
+  };
+  var loadLog = function() {
+    engineCall('/disclosure/log?orgId=' + getOrgId() + '&limit=' + LOG_PAGE_SIZE + '&offset=' + (logPage - 1) * LOG_PAGE_SIZE)
+      .then(function(d) { setLog(d); }).catch(function() {});
+  };
+  useEffect(load, []);
+  useEffect(function() { engineCall('/agents?orgId=' + getOrgId()).then(function(d) { setAgents(d.agents || []); }).catch(function() {}); }, []);
+  useEffect(loadConsents, [consentFilter]);
+  useEffect(loadLog, [logPage]);
+
+  if (!config) return h('div', { style: { padding: 40, textAlign: 'center', color: 'var(--text-muted)' } }, 'Loading...');
+
+  var patch = function(changes) { setConfig(Object.assign({}, config, changes)); setDirty(true); };
+  var patchTemplate = function(i, changes) {
+    patch({ templates: config.templates.map(function(t, j) { return j === i ? Object.assign({}, t, changes) : t; }) });
+  };
+
+  var save = function() {
+    setSaving(true);
+    engineCall('/disclosure/config', { method: 'PUT', body: JSON.stringify(config) })
+      .then(function() { toast('AI disclosure settings saved', 'success'); load(); })
+      .catch(function(err) { toast(err.message, 'error'); })
+      .finally(function() { setSaving(false); });
+  };
+
+  var setConsent = function(contact, status) {
+    engineCall('/disclosure/consents', { method: 'PUT', body: JSON.stringify({ orgId: getOrgId(), contact: contact, status: status }) })
+      .then(function() { toast('Consent recorded for ' + contact, 'success'); setNewConsent({ contact: '', status: 'granted' }); loadConsents(); })
+      .catch(function(err) { toast(err.message, 'error'); });
+  };
+
+  var agentData = buildAgentDataMap(agents);
+  var templateLabel = function(id) {
+    var t = config.templates.find(function(x) { return x.id === id; });
+    return t ? t.label : id;
+  };
+
+  return h('div', null,
+    // ── SETTINGS ──
+    h('div', { style: _sectionTitleStyle }, 'Disclosure and consent'),
+    h('div', { style: _cardStyle },
+      h('div', { style: _cardTitleStyle }, I.messages(), ' Outbound agent email', h(HelpButton, { label: 'AI Disclosure' },
+        h('p', null, 'Adds a footer to every email an agent sends saying it was written by an AI agent. The wording is picked per recipient from the jurisdiction templates below.'),
+        h('p', null, h('strong', null, 'Consent capture'), ' adds the template\'s consent notice to the first email sent to a contact without a consent record, and lists them below as requested. Record their answer here once they reply.'),
+        h('p', null, 'Mail to internal domains gets neither.')
+      )),
+      h('div', { style: _cardDescStyle }, 'Applies to AgenticMail, Gmail and Outlook sends, replies and forwards.'),
+      h(ToggleSwitch, { label: 'Add an AI-disclosure footer to agent emails', checked: config.footerEnabled === true, onChange: function(v) { patch({ footerEnabled: v }); } }),
+      h(ToggleSwitch, { label: 'Ask new contacts for consent to tracked conversations', checked: config.consentEnabled === true, onChange: function(v) { patch({ consentEnabled: v }); } }),
+      h('div', { style: { marginTop: 12 } },
+        h(TagInput, { label: 'Internal domains (no footer)', value: config.internalDomains || [], onChange: function(v) { patch({ internalDomains: v }); }, placeholder: 'example.com', mono: true })
+      )
+    ),
+
+    // ── TEMPLATES ──
+    h('div', { style: _sectionTitleStyle }, 'Jurisdiction templates'),
+    h('div', { style: _cardStyle },
+      h('div', { style: _cardDescStyle }, 'The first template whose domains match the recipient is used; everyone else gets Default. Use {{agentName}} and {{agentEmail}} in the text.'),
+      config.templates.map(function(t, i) {
+        var isDefault = t.id === 'default';
+        return h('div', { key: i, style: { border: '1px solid var(--border)', borderRadius: 'var(--radius)', padding: 12, marginBottom: 10 } },
+          h('div', { style: { display: 'grid', gridTemplateColumns: '120px 1fr auto', gap: 8, alignItems: 'end', marginBottom: 8 } },
+            h('div', null, h('label', { className: 'field-label' }, 'ID'), h('input', { className: 'input', value: t.id, disabled: isDefault, placeholder: 'uk', onChange: function(e) { patchTemplate(i, { id: e.target.value.toLowerCase() }); } })),
+            h('div', null, h('label', { className: 'field-label' }, 'Label'), h('input', { className: 'input', value: t.label, placeholder: 'United Kingdom', onChange: function(e) { patchTemplate(i, { label: e.target.value }); } })),
+            !isDefault && h('button', { className: 'btn btn-ghost btn-icon', title: 'Remove template', onClick: function() { patch({ templates: config.templates.filter(function(_, j) { return j !== i; }) }); } }, I.trash())
+          ),
+          !isDefault && h(TagInput, { label: 'Recipient domains', value: t.domains || [], onChange: function(v) { patchTemplate(i, { domains: v }); }, placeholder: '.de, .fr, example.co.uk', mono: true }),
+          h('label', { className: 'field-label', style: { marginTop: 8 } }, 'Footer'),
+          h('textarea', { className: 'input', rows: 2, value: t.footer, onChange: function(e) { patchTemplate(i, { footer: e.target.value }); } }),
+          h('label', { className: 'field-label', style: { marginTop: 8 } }, 'Consent notice'),
+          h('textarea', { className: 'input', rows: 2, value: t.consent, onChange: function(e) { patchTemplate(i, { consent: e.target.value }); } })
+        );
+      }),
+      h('button', { className: 'btn btn-secondary btn-sm', onClick: function() { patch({ templates: config.templates.concat([{ id: '', label: '', domains: [], footer: '', consent: '' }]) }); } }, I.plus(), ' Add template')
+    ),
+
+    h('div', { style: { display: 'flex', justifyContent: 'flex-end', gap: 8, marginBottom: 16 } },
+      dirty && h('button', { className: 'btn btn-ghost', onClick: load }, 'Discard'),
+      h('button', { className: 'btn btn-primary', disabled: !dirty || saving, onClick: save }, saving ? 'Saving...' : 'Save')
+    ),
+
+    // ── CONSENTS ──
+    h('div', { style: _sectionTitleStyle }, 'Conversation consent'),
+    h('div', { style: _cardStyle },
+      h('div', { style: { display: 'flex', gap: 8, alignItems: 'center', marginBottom: 12, flexWrap: 'wrap' } },
+        h('select', { className: 'input', style: { width: 160 }, value: consentFilter, onChange: function(e) { setConsentFilter(e.target.value); } },
+          h('option', { value: '' }, 'Any status'),
+          Object.keys(CONSENT_STATUS).map(function(k) { return h('option', { key: k, value: k }, CONSENT_STATUS[k].label); })
+        ),
+        h('div', { style: { flex: 1 } }),
+        h('input', { className: 'input', style: { width: 220 }, placeholder: 'contact@example.com', value: newConsent.contact, onChange: function(e) { setNewConsent(Object.assign({}, newConsent, { contact: e.target.value })); } }),
+        h('select', { className: 'input', style: { width: 130 }, value: newConsent.status, onChange: function(e) { setNewConsent(Object.assign({}, newConsent, { status: e.target.value })); } },
+          ['granted', 'declined', 'withdrawn'].map(function(k) { return h('option', { key: k, value: k }, CONSENT_STATUS[k].label); })
+        ),
+        h('button', { className: 'btn btn-secondary btn-sm', disabled: !newConsent.contact.trim(), onClick: function() { setConsent(newConsent.contact.trim(), newConsent.status); } }, 'Record')
+      ),
+      consents.length === 0
+        ? h('div', { style: { fontSize: 13, color: 'var(--text-muted)' } }, consentFilter ? 'No contacts with this status.' : 'No consent records yet.')
+        : h('table', { className: 'data-table' },
+            h('thead', null, h('tr', null, h('th', null, 'Contact'), h('th', null, 'Jurisdiction'), h('th', null, 'Status'), h('th', null, 'Requested'), h('th', null, 'Decided'), h('th', null, ''))),
+            h('tbody', null, consents.map(function(c) {
+              var st = CONSENT_STATUS[c.status] || CONSENT_STATUS.requested;
+              return h('tr', { key: c.id },
+                h('td', null, h('code', { style: { fontSize: 12 } }, c.contact)),
+                h('td', null, templateLabel(c.jurisdiction)),
+                h('td', null, h('span', { className: 'badge ' + st.cls }, st.label)),
+                h('td', null, h(RelativeTime, { value: c.requestedAt })),
+                h('td', null, c.decidedAt ? h(RelativeTime, { value: c.decidedAt }) : '-', c.decidedBy ? h('div', { style: { fontSize: 11, color: 'var(--text-muted)' } }, c.decidedBy) : null),
+                h('td', { style: { textAlign: 'right', whiteSpace: 'nowrap' } },
+                  c.status !== 'granted' && h('button', { className: 'btn btn-ghost btn-sm', onClick: function() { setConsent(c.contact, 'granted'); } }, 'Granted'),
+                  c.status !== 'declined' && c.status !== 'withdrawn' && h('button', { className: 'btn btn-ghost btn-sm', onClick: function() { setConsent(c.contact, c.status === 'granted' ? 'withdrawn' : 'declined'); } }, c.status === 'granted' ? 'Withdrawn' : 'Declined')
+                )
+              );
+            }))
+          )
+    ),
+
+    // ── LOG ──
+    h('div', { style: _sectionTitleStyle }, 'Disclosure log'),
+    h('div', { style: _cardStyle },
+      h('div', { style: _cardDescStyle }, 'Every footer and consent notice added to an agent email.'),
+      log.entries.length === 0
+        ? h('div', { style: { fontSize: 13, color: 'var(--text-muted)' } }, 'No disclosures applied yet.')
+        : h('table', { className: 'data-table' },
+            h('thead', null, h('tr', null, h('th', null, 'When'), h('th', null, 'Agent'), h('th', null, 'Recipient'), h('th', null, 'Tool'), h('th', null, 'Template'), h('th', null, 'Type'))),
+            h('tbody', null, log.entries.map(function(e) {
+              return h('tr', { key: e.id },
+                h('td', null, h(RelativeTime, { value: e.appliedAt })),
+                h('td', null, renderAgentBadge(e.agentId, agentData)),
+                h('td', { style: { fontSize: 12 } }, e.recipient || h('span', { style: { color: 'var(--text-muted)' } }, 'reply')),
+                h('td', null, h('code', { style: { fontSize: 12 } }, e.tool)),
+                h('td', null, templateLabel(e.jurisdiction)),
+                h('td', null, h('span', { className: 'badge ' + (e.kind === 'consent' ? 'badge-info' : 'badge-neutral') }, e.kind === 'consent' ? 'Consent notice' : 'Footer'))
+              );
+            }))
+          ),
+      h(Pagination, { page: logPage, pageSize: LOG_PAGE_SIZE, pageSizes: [LOG_PAGE_SIZE], total: log.total, onPage: setLogPage })
+    )
+  );
+}
+
+// ─── Scheduled Reports ──────────────────────────────────
+// PDF renders of the dashboard, costs and compliance pages emailed on a
+// schedule to people who never sign in. Each recipient picks their pages.
+
+var REPORT_PAGES = [
+  { id: 'dashboard', label: 'Dashboard' },
+  { id: 'costs', label: 'Costs' },
+  { id: 'compliance', label: 'Compliance' },
+];
+var WEEKDAYS = ['Sunday', 'Monday', 'Tuesday', 'Wednesday', 'Thursday', 'Friday', 'Saturday'];
+var REPORT_STATUS = {
+  sent: { label: 'Sent', cls: 'badge-success' },
+  partial: { label: 'Partly sent', cls: 'badge-warning' },
+  failed: { label: 'Failed', cls: 'badge-danger' },
+};
+
+function ScheduledReportsTab({ toast }) {
+  var [data, setData] = useState(null);
+  var [schedules, setSchedules] = useState([]);
+  var [orgs, setOrgs] = useState([]);
+  var [dirty, setDirty] = useState(false);
+  var [saving, setSaving] = useState(false);
+  var [sending, setSending] = useState(null);
+
+  var load = function() {
+    apiCall('/admin/report-schedules').then(function(d) {
+      setData(d);
+      setSchedules(d.schedules || []);
+      setDirty(false);
+    }).catch(function(err) { toast('Failed to load report schedules: ' + err.message, 'error'); });
+  };
+  useEffect(load, []);
+  useEffect(function() { apiCall('/organizations').then(function(d) { setOrgs(d.organizations || []); }).catch(function() {}); }, []);
+
+  if (!data) return h('div', { style: { padding: 40, textAlign: 'center', color: 'var(--text-muted)' } }, 'Loading...');
+
+  var patch = function(i, changes) {
+    setSchedules(schedules.map(function(s, j) { return j === i ? Object.assign({}, s, changes) : s; }));
+    setDirty(true);
+  };
+  var patchRecipient = function(i, k, changes) {
+    patch(i, { recipients: schedules[i].recipients.map(function(r, j) { return j === k ? Object.assign({}, r, changes) : r; }) });
+  };
+  var togglePage = function(i, k, page) {
+    var pages = schedules[i].recipients[k].pages;
+    patchRecipient(i, k, { pages: pages.indexOf(page) === -1 ? pages.concat([page]) : pages.filter(function(p) { return p !== page; }) });
+  };
+  var add = function() {
+    setSchedules(schedules.concat([{ name: '', enabled: true, frequency: 'weekly', hour: 8, weekday: 1, dayOfMonth: 1, orgId: '', recipients: [{ email: '', pages: ['dashboard'] }] }]));
+    setDirty(true);
+  };
+
+  var save = function() {
+    setSaving(true);
+    apiCall('/admin/report-schedules', { method: 'PUT', body: JSON.stringify({ schedules: schedules }) })
+      .then(function() { toast('Report schedules saved', 'success'); load(); })
+      .catch(function(err) { toast(err.message, 'error'); })
+      .finally(function() { setSaving(false); });
+  };
+  var sendNow = async function(s) {
+    var ok = await showConfirm({ title: 'Send Report Now', message: 'Email "' + s.name + '" to its ' + s.recipients.length + ' recipient' + (s.recipients.length === 1 ? '' : 's') + ' now? The regular schedule is not affected.', confirmText: 'Send' });
+    if (!ok) return;
+    setSending(s.id);
+    apiCall('/admin/report-schedules/' + s.id + '/send', { method: 'POST' })
+      .then(function(r) { toast(r.status === 'sent' ? 'Report sent to ' + r.sent.length + ' recipient' + (r.sent.length === 1 ? '' : 's') : 'Sent to ' + r.sent.length + ', failed for ' + r.failed.length, r.status === 'sent' ? 'success' : 'warning'); load(); })
+      .catch(function(err) { toast(err.message, 'error'); load(); })
+      .finally(function() { setSending(null); });
+  };
+  var preview = function(s, pages) {
+    downloadExport('/admin/report-schedules/' + s.id + '/preview' + (pages ? '?pages=' + pages.join(',') : ''))
+      .catch(function(err) { toast(err.message, 'error'); });
+  };
+
+  var _row = { display: 'grid', gap: 8, alignItems: 'end', marginBottom: 8 };
+  return h('div', null,
+    !data.available && h('div', { className: 'badge badge-warning', style: { display: 'block', padding: 12, marginBottom: 16, fontSize: 13 } }, 'Scheduled reports need a SQL database.'),
+    data.available && !data.smtpConfigured && h('div', { style: { padding: 12, marginBottom: 16, fontSize: 13, borderRadius: 'var(--radius)', border: '1px solid var(--warning)', color: 'var(--warning)' } },
+      'Reports are sent through the SMTP relay. Set one up in the Email & Domain tab before the first run.'
+    ),
+
+    h('div', { style: _sectionTitleStyle }, 'Schedules'),
+    schedules.length === 0 && h('div', { style: Object.assign({}, _cardStyle, { textAlign: 'center', color: 'var(--text-muted)', fontSize: 13 }) }, 'No scheduled reports yet.'),
+    schedules.map(function(s, i) {
+      var st = s.lastStatus && REPORT_STATUS[s.lastStatus];
+      var saved = !dirty && s.id;
+      return h('div', { key: s.id || 'new-' + i, style: _cardStyle },
+        h('div', { style: Object.assign({}, _row, { gridTemplateColumns: '2fr 1fr 1fr 1fr 1.5fr' }) },
+          h('div', null, h('label', { className: 'field-label' }, 'Name'), h('input', { className: 'input', value: s.name, placeholder: 'Weekly executive summary', onChange: function(e) { patch(i, { name: e.target.value }); } })),
+          h('div', null, h('label', { className: 'field-label' }, 'Frequency'), h('select', { className: 'input', value: s.frequency, onChange: function(e) { patch(i, { frequency: e.target.value }); } },
+            h('option', { value: 'daily' }, 'Daily'), h('option', { value: 'weekly' }, 'Weekly'), h('option', { value: 'monthly' }, 'Monthly')
+          )),
+          s.frequency === 'weekly' && h('div', null, h('label', { className: 'field-label' }, 'On'), h('select', { className: 'input', value: s.weekday == null ? 1 : s.weekday, onChange: function(e) { patch(i, { weekday: parseInt(e.target.value, 10) }); } },
+            WEEKDAYS.map(function(d, n) { return h('option', { key: n, value: n }, d); })
+          )),
+          s.frequency === 'monthly' && h('div', null, h('label', { className: 'field-label' }, 'Day of month'), h('select', { className: 'input', value: s.dayOfMonth || 1, onChange: function(e) { patch(i, { dayOfMonth: parseInt(e.target.value, 10) }); } },
+            Array.from({ length: 28 }, function(_, n) { return h('option', { key: n, value: n + 1 }, n + 1); })
+          )),
+          s.frequency === 'daily' && h('div', null),
+          h('div', null, h('label', { className: 'field-label' }, 'At (UTC)'), h('select', { className: 'input', value: s.hour, onChange: function(e) { patch(i, { hour: parseInt(e.target.value, 10) }); } },
+            Array.from({ length: 24 }, function(_, n) { return h('option', { key: n, value: n }, (n < 10 ? '0' : '') + n + ':00'); })
+          )),
+          h('div', null, h('label', { className: 'field-label' }, 'Organization'), h('select', { className: 'input', value: s.orgId || '', onChange: function(e) { patch(i, { orgId: e.target.value }); } },
+            h('option', { value: '' }, 'All organizations'),
+            orgs.map(function(o) { return h('option', { key: o.id, value: o.id }, o.name); })
+          ))
+        ),
+
+        h('label', { className: 'field-label', style: { marginTop: 8 } }, 'Recipients'),
+        s.recipients.map(function(r, k) {
+          return h('div', { key: k, style: { display: 'flex', alignItems: 'center', gap: 12, marginBottom: 6 } },
+            h('input', { className: 'input', type: 'email', style: { width: 260 }, value: r.email, placeholder: 'cfo@example.com', onChange: function(e) { patchRecipient(i, k, { email: e.target.value }); } }),
+            REPORT_PAGES.map(function(p) {
+              return h('label', { key: p.id, style: { display: 'inline-flex', alignItems: 'center', gap: 4, fontSize: 13, cursor: 'pointer' } },
+                h('input', { type: 'checkbox', checked: r.pages.indexOf(p.id) !== -1, onChange: function() { togglePage(i, k, p.id); } }), p.label
+              );
+            }),
+            h('span', { style: { flex: 1 } }),
+            saved && h('button', { className: 'btn btn-ghost btn-sm', title: 'Download the PDF this recipient gets', onClick: function() { preview(s, r.pages); } }, I.download()),
+            h('button', { className: 'btn btn-ghost btn-sm', title: 'Remove recipient', 'aria-label': 'Remove recipient', disabled: s.recipients.length === 1, onClick: function() { patch(i, { recipients: s.recipients.filter(function(_, j) { return j !== k; }) }); } }, I.trash())
+          );
+        }),
+        h('button', { className: 'btn btn-secondary btn-sm', onClick: function() { patch(i, { recipients: s.recipients.concat([{ email: '', pages: ['dashboard'] }]) }); } }, I.plus(), ' Add recipient'),
+
+        h('div', { style: { display: 'flex', alignItems: 'center', gap: 12, marginTop: 16, paddingTop: 12, borderTop: '1px solid var(--border)', fontSize: 12, color: 'var(--text-muted)' } },
+          h('label', { style: { display: 'inline-flex', alignItems: 'center', gap: 6, fontSize: 13, color: 'var(--text)', cursor: 'pointer' } }, h('input', { type: 'checkbox', checked: s.enabled !== false, onChange: function(e) { patch(i, { enabled: e.target.checked }); } }), 'Enabled'),
+          s.nextRunAt && h('span', null, 'Next: ', new Date(s.nextRunAt).toLocaleString()),
+          s.lastRunAt && h('span', { style: { display: 'inline-flex', alignItems: 'center', gap: 6 } }, 'Last: ', h(RelativeTime, { value: s.lastRunAt }), st && h('span', { className: 'badge ' + st.cls, title: s.lastError || undefined }, st.label)),
+          h('span', { style: { flex: 1 } }),
+          saved && h('button', { className: 'btn btn-secondary btn-sm', disabled: sending === s.id, onClick: function() { sendNow(s); } }, sending === s.id ? 'Sending...' : 'Send now'),
+          h('button', { className: 'btn btn-ghost btn-sm', title: 'Delete schedule', 'aria-label': 'Delete schedule', onClick: function() { setSchedules(schedules.filter(function(_, j) { return j !== i; })); setDirty(true); } }, I.trash())
+        ),
+        s.lastError && h('div', { style: { fontSize: 12, color: 'var(--danger)', marginTop: 6 } }, s.lastError)
+      );
+    }),
+    h('button', { className: 'btn btn-secondary btn-sm', disabled: !data.available, onClick: add }, I.plus(), ' Add schedule'),
+
+    h('div', { style: { display: 'flex', justifyContent: 'flex-end', gap: 8, marginTop: 16 } },
+      dirty && h('button', { className: 'btn btn-ghost', onClick: load }, 'Discard'),
+      h('button', { className: 'btn btn-primary', disabled: !dirty || saving, onClick: save }, saving ? 'Saving...' : 'Save')
+    )
+  );
+}
+
+// ─── Role Permissions Tab ───────────────────────────────
+
+var ROLE_LABELS = { admin: 'Admin', member: 'Member', viewer: 'Viewer' };
+
+function RolePermissionsTab({ toast }) {
+  var app = useApp();
+  var isOwner = app.user && app.user.role === 'owner';
+  var [data, setData] = useState(null);
+  var [matrix, setMatrix] = useState({});
+  var [saving, setSaving] = useState(false);
+
+  var load = function() {
+    apiCall('/admin/role-permissions').then(function(d) {
+      setData(d);
+      setMatrix(d.matrix);
+    }).catch(function(err) { toast('Failed to load role permissions: ' + err.message, 'error'); });
+  };
+  useEffect(load, []);
+
+  if (!data) return h('div', { style: { padding: 40, textAlign: 'center', color: 'var(--text-muted)' } }, 'Loading...');
+
+  var dirty = JSON.stringify(matrix) !== JSON.stringify(data.matrix);
+  var isDefault = JSON.stringify(matrix) === JSON.stringify(data.defaults);
+  var toggle = function(capId, role) {
+    var roles = matrix[capId] || [];
+    var next = roles.indexOf(role) >= 0 ? roles.filter(function(r) { return r !== role; }) : roles.concat([role]);
+    setMatrix(Object.assign({}, matrix, { [capId]: data.roles.filter(function(r) { return next.indexOf(r) >= 0; }) }));
+  };
+  var save = function() {
+    setSaving(true);
+    apiCall('/admin/role-permissions', { method: 'PUT', body: JSON.stringify({ matrix: matrix }) })
+      .then(function() { toast('Role permissions saved. They apply within 15 seconds.', 'success'); load(); })
+      .catch(function(err) { toast(err.message, 'error'); })
+      .finally(function() { setSaving(false); });
+  };
+
+  var groups = [];
+  data.capabilities.forEach(function(cap) { if (groups.indexOf(cap.group) < 0) groups.push(cap.group); });
+  var _cell = { padding: '8px 12px', borderBottom: '1px solid var(--border)', textAlign: 'center', width: 90 };
+
+  return h('div', null,
+    h('div', { style: { fontSize: 13, color: 'var(--text-secondary)', marginBottom: 16 } },
+      'Choose what each role can do across the dashboard. Owners can always do everything. Page access for individual members and viewers is still set on the Users page.',
+      !isOwner && h('div', { style: { marginTop: 8, color: 'var(--text-muted)' } }, 'Only owners can change these permissions.')
+    ),
+    h('div', { style: _cardStyle },
+      h('table', { style: { width: '100%', borderCollapse: 'collapse', fontSize: 13 } },
+        h('thead', null, h('tr', null,
+          h('th', { style: { textAlign: 'left', padding: '8px 12px', borderBottom: '1px solid var(--border)' } }, 'Capability'),
+          h('th', { style: _cell }, 'Owner'),
+          data.roles.map(function(r) { return h('th', { key: r, style: _cell }, ROLE_LABELS[r] || r); })
+        )),
+        groups.map(function(g) {
+          return h(Fragment, { key: g },
+            h('tr', null, h('td', { colSpan: data.roles.length + 2, style: { padding: '12px 12px 4px', fontSize: 11, fontWeight: 600, textTransform: 'uppercase', color: 'var(--text-muted)' } }, g)),
+            data.capabilities.filter(function(cap) { return cap.group === g; }).map(function(cap) {
+              var changed = JSON.stringify(matrix[cap.id]) !== JSON.stringify(data.defaults[cap.id]);
+              return h('tr', { key: cap.id },
+                h('td', { style: { padding: '8px 12px', borderBottom: '1px solid var(--border)' } },
+                  h('div', { style: { fontWeight: 500 } }, cap.label, changed && h('span', { className: 'badge badge-info', style: { marginLeft: 8, fontSize: 10 } }, 'Customized')),
+                  h('div', { style: { fontSize: 12, color: 'var(--text-muted)' } }, cap.description)
+                ),
+                h('td', { style: _cell }, h('input', { type: 'checkbox', checked: true, disabled: true, title: 'Owners can always do everything' })),
+                data.roles.map(function(r) {
+                  return h('td', { key: r, style: _cell },
+                    h('input', { type: 'checkbox', checked: (matrix[cap.id] || []).indexOf(r) >= 0, disabled: !isOwner, 'aria-label': (ROLE_LABELS[r] || r) + ': ' + cap.label, onChange: function() { toggle(cap.id, r); } })
+                  );
+                })
+              );
+            })
+          );
+        })
+      )
+    ),
+    isOwner && h('div', { style: { display: 'flex', justifyContent: 'flex-end', gap: 8 } },
+      !isDefault && h('button', { className: 'btn btn-ghost', onClick: function() { setMatrix(data.defaults); } }, 'Restore Defaults'),
+      dirty && h('button', { className: 'btn btn-ghost', onClick: function() { setMatrix(data.matrix); } }, 'Discard'),
+      h('button', { className: 'btn btn-primary', disabled: !dirty || saving, onClick: save }, saving ? 'Saving...' : 'Save')
+    )
+  );
+}
+
 // ─── Platform Capabilities Tab ──────────────────────────
 
 function PlatformCapabilitiesTab({ toast }) {
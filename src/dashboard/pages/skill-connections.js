@@ -1,4 +1,4 @@
-import { h, useState, useEffect, useCallback, Fragment, useApp, engineCall, apiCall, getOrgId } from '../components/utils.js';
+import { h, useState, useEffect, useCallback, Fragment, useApp, engineCall, apiCall, getOrgId, showConfirm } from '../components/utils.js';
 import { I } from '../components/icons.js';
 import { E } from '../assets/icons/emoji-icons.js';
 import { Modal } from '../components/modal.js';
@@ -890,9 +890,13 @@ function CommunitySkillsSection(props) {
                   ? h(Fragment, null,
                       h('span', { className: 'badge', style: { background: 'var(--success)', color: '#fff', fontSize: 10 } }, 'Connected'),
                       h('button', { className: 'btn btn-ghost btn-sm', onClick: function() {
-                        engineCall('/oauth/disconnect/' + skill.skillId + (orgId ? '?orgId=' + orgId : ''), { method: 'DELETE' })
-                          .then(function() { toast('Disconnected', 'success'); load(); })
-                          .catch(function(e) { toast(e.message || 'Failed', 'error'); });
+                        showConfirm({ title: 'Disconnect ' + (meta.name || skill.skillId), message: 'This will remove stored credentials. Agents using this skill will lose access.', danger: true, confirmText: 'Disconnect' })
+                          .then(function(ok) {
+                            if (!ok) return;
+                            engineCall('/oauth/disconnect/' + skill.skillId + (orgId ? '?orgId=' + orgId : ''), { method: 'DELETE' })
+                              .then(function() { toast('Disconnected', 'success'); load(); })
+                              .catch(function(e) { toast(e.message || 'Failed', 'error'); });
+                          });
                       } }, 'Disconnect')
                     )
                   : h('button', { className: 'btn btn-primary btn-sm', onClick: function() { openConfig(skill); } }, 'Connect'),
@@ -1,4 +1,4 @@
-import { h, useState, useEffect, useCallback, Fragment, useApp, apiCall, engineCall, getOrgId } from '../components/utils.js';
+import { h, useState, useEffect, useCallback, Fragment, useApp, apiCall, engineCall, getOrgId, showConfirm } from '../components/utils.js';
 import { I } from '../components/icons.js';
 import { Modal } from '../components/modal.js';
 import { HelpButton } from '../components/help-button.js';
@@ -197,6 +197,11 @@ export function SkillsPage() {
 
   // Disconnect
   var disconnectSkill = async function(skillId) {
+    var ok = await showConfirm({
+      title: 'Disconnect Skill', danger: true, confirmText: 'Disconnect',
+      message: 'This will remove stored credentials for this skill. Agents using it will lose access.',
+    });
+    if (!ok) return;
     try {
       await engineCall('/oauth/disconnect/' + skillId, { method: 'DELETE' });
       toast('Disconnected', 'success');
@@ -251,7 +256,7 @@ export function SkillsPage() {
 
   // Uninstall
   var uninstallSkill = async function(skillId) {
-    var ok = await window.__showConfirm({
+    var ok = await showConfirm({
       title: 'Uninstall Skill',
       message: 'Remove this skill? Any active connections will be lost.',
       danger: true, confirmText: 'Uninstall'
@@ -411,10 +416,13 @@ export function SkillsPage() {
   };
 
   var disconnectIntegration = function(int) {
-    if (!confirm('Disconnect ' + int.name + '? Agents will lose access to its tools.')) return;
-    engineCall('/oauth/disconnect/' + int.skillId + '?orgId=' + effectiveOrgId, { method: 'DELETE' })
-      .then(function() { toast(int.name + ' disconnected', 'success'); loadIntegrations(); })
-      .catch(function(e) { toast('Failed: ' + e.message, 'error'); });
+    showConfirm({ title: 'Disconnect ' + int.name, message: 'Agents will lose access to ' + int.name + ' tools until it is connected again.', danger: true, confirmText: 'Disconnect' })
+      .then(function(ok) {
+        if (!ok) return;
+        engineCall('/oauth/disconnect/' + int.skillId + '?orgId=' + effectiveOrgId, { method: 'DELETE' })
+          .then(function() { toast(int.name + ' disconnected', 'success'); loadIntegrations(); })
+          .catch(function(e) { toast('Failed: ' + e.message, 'error'); });
+      });
   };
 
   // Note: saveToken already defined above; after saving we also refresh integrations
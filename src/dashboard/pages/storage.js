@@ -0,0 +1,136 @@
+import { h, useState, useEffect, useApp, engineCall, getOrgId } from '../components/utils.js';
+import { I } from '../components/icons.js';
+import { HelpButton } from '../components/help-button.js';
+import { useOrgContext } from '../components/org-switcher.js';
+import { Table } from '../components/table.js';
+
+const COLORS = {
+  messages: 'var(--info)',
+  attachments: 'var(--warning)',
+  journal: 'var(--accent)',
+  knowledge: 'var(--success)',
+  activity: '#8b5cf6',
+  audit: 'var(--text-muted)',
+};
+
+export function formatBytes(n) {
+  if (n == null) return '-';
+  if (n < 1024) return n + ' B';
+  var units = ['KB', 'MB', 'GB', 'TB'];
+  var v = n, i = -1;
+  do { v /= 1024; i++; } while (v >= 1024 && i < units.length - 1);
+  return (v >= 100 ? v.toFixed(0) : v.toFixed(1)) + ' ' + units[i];
+}
+
+export function StoragePage() {
+  var orgCtx = useOrgContext();
+  var effectiveOrgId = orgCtx.selectedOrgId || getOrgId();
+  const { toast, setPage } = useApp();
+  const [usage, setUsage] = useState(null);
+  const [loading, setLoading] = useState(true);
+
+  const load = () => {
+    setLoading(true);
+    engineCall('/storage/usage?orgId=' + encodeURIComponent(effectiveOrgId))
+      .then(setUsage)
+      .catch(err => toast('Failed to load storage usage: ' + err.message, 'error'))
+      .finally(() => setLoading(false));
+  };
+
+  useEffect(() => { load(); }, [effectiveOrgId]);
+
+  var _h4 = { marginTop: 16, marginBottom: 8, fontSize: 14 };
+  var _ul = { paddingLeft: 20, margin: '4px 0 8px' };
+
+  var categories = usage ? usage.categories : [];
+  var measured = categories.filter(c => c.bytes != null);
+  var total = usage ? usage.totalBytes : 0;
+  var trend = usage ? usage.trend : [];
+  var first = trend[0], last = trend[trend.length - 1];
+  var change = first && last && trend.length > 1 ? last.total - first.total : null;
+  var trendMax = Math.max.apply(null, trend.map(t => t.total).concat([1]));
+
+  const agentColumns = [
+    { key: 'name', label: 'Agent', render: (a) => h('strong', null, a.name) },
+    { key: 'total', label: 'Total', render: (a) => formatBytes(a.total) },
+  ].concat(measured.filter(c => c.key !== 'knowledge').map(c => ({
+    key: c.key, label: c.label, render: (a) => a.bytes[c.key] ? formatBytes(a.bytes[c.key]) : '-',
+  })));
+
+  return h('div', { className: 'page-inner' },
+    h(orgCtx.Switcher),
+    h('div', { className: 'page-header' },
+      h('h1', { style: { display: 'flex', alignItems: 'center' } }, 'Storage', h(HelpButton, { label: 'Storage' },
+        h('p', null, 'How much data the organization holds, by category and by agent, and how that has changed over the last 30 days.'),
+        h('h4', { style: _h4 }, 'How sizes are measured'),
+        h('ul', { style: _ul },
+          h('li', null, 'Sizes are the stored content — message bodies, attachment files, journal payloads, knowledge documents — not database indexes or overhead, so the disk footprint is larger.'),
+          h('li', null, 'Audit events live in the admin database and are counted but not sized.'),
+          h('li', null, 'Internal messages count toward the receiving agent. Knowledge bases are shared, so they are only shown org-wide.')
+        ),
+        h('h4', { style: _h4 }, 'Trend'),
+        h('p', null, 'A snapshot is taken each day this page is opened. Days nobody looked have no point.'),
+        h('h4', { style: _h4 }, 'Freeing space'),
+        h('p', null, 'Each category links to where its data is managed. Activity and conversations are purged through Settings → Data Retention, which previews what will go before anything is deleted.')
+      )),
+      h('button', { className: 'btn btn-secondary', onClick: load, disabled: loading }, I.refresh(), ' Refresh')
+    ),
+
+    h('div', { className: 'stat-grid', style: { marginBottom: 16 } },
+      h('div', { className: 'stat-card' }, h('div', { className: 'stat-value' }, loading && !usage ? '...' : formatBytes(total)), h('div', { className: 'stat-label' }, 'Total stored')),
+      h('div', { className: 'stat-card' },
+        h('div', { className: 'stat-value', style: { color: change > 0 ? 'var(--warning)' : change < 0 ? 'var(--success)' : undefined } },
+          change == null ? '-' : (change >= 0 ? '+' : '−') + formatBytes(Math.abs(change))
+        ),
+        h('div', { className: 'stat-label' }, first && trend.length > 1 ? 'Since ' + new Date(first.day).toLocaleDateString() : 'Change (not enough history)')
+      ),
+      h('div', { className: 'stat-card' }, h('div', { className: 'stat-value' }, usage ? usage.agents.length : '-'), h('div', { className: 'stat-label' }, 'Agents with data'))
+    ),
+
+    h('div', { className: 'card', style: { marginBottom: 16 } },
+      h('div', { className: 'card-header' }, h('h3', null, 'By category')),
+      h('div', { className: 'card-body' },
+        total > 0 && h('div', { style: { display: 'flex', height: 12, borderRadius: 6, overflow: 'hidden', marginBottom: 16, background: 'var(--bg-tertiary)' } },
+          measured.filter(c => c.bytes > 0).map(c => h('div', { key: c.key, title: c.label + ': ' + formatBytes(c.bytes), style: { width: (c.bytes / total * 100) + '%', background: COLORS[c.key] } }))
+        ),
+        h('table', { className: 'data-table' },
+          h('thead', null, h('tr', null, h('th', null, 'Category'), h('th', null, 'Size'), h('th', null, 'Share'), h('th', null, 'Records'), h('th', null, ''))),
+          h('tbody', null, categories.map(c => h('tr', { key: c.key },
+            h('td', null, h('span', { style: { display: 'inline-block', width: 10, height: 10, borderRadius: 2, background: COLORS[c.key], marginRight: 8 } }), c.label),
+            h('td', null, formatBytes(c.bytes)),
+            h('td', null, c.bytes != null && total > 0 ? (c.bytes / total * 100).toFixed(1) + '%' : '-'),
+            h('td', null, c.rows.toLocaleString()),
+            h('td', { style: { textAlign: 'right' } }, c.cleanupPage && h('button', { className: 'btn btn-ghost btn-sm', onClick: () => setPage(c.cleanupPage) }, c.key === 'activity' ? 'Retention settings' : 'Manage', ' →'))
+          )))
+        )
+      )
+    ),
+
+    h('div', { className: 'card', style: { marginBottom: 16 } },
+      h('div', { className: 'card-header' }, h('h3', null, 'Last 30 days')),
+      h('div', { className: 'card-body' },
+        trend.length < 2
+          ? h('div', { style: { fontSize: 13, color: 'var(--text-muted)' } }, 'Not enough history yet. A snapshot is recorded each day this page is viewed.')
+          : h('div', { style: { display: 'flex', alignItems: 'flex-end', gap: 4, height: 120 } },
+              trend.map(t => h('div', {
+                key: t.day, title: new Date(t.day).toLocaleDateString() + ': ' + formatBytes(t.total),
+                style: { flex: 1, display: 'flex', flexDirection: 'column-reverse', height: Math.max(2, t.total / trendMax * 120), borderRadius: '3px 3px 0 0', overflow: 'hidden' },
+              }, measured.map(c => t.bytes[c.key] ? h('div', { key: c.key, style: { flex: t.bytes[c.key], background: COLORS[c.key] } }) : null)))
+            )
+      )
+    ),
+
+    h('div', { className: 'card' },
+      h('div', { className: 'card-header' }, h('h3', null, 'By agent')),
+      h(Table, {
+        prefsKey: 'storage.agents',
+        className: 'data-table',
+        columns: agentColumns,
+        rows: usage ? usage.agents : [],
+        rowKey: 'agentId',
+        onRowClick: (a) => { history.pushState(null, '', '/dashboard/agents/' + a.agentId); window.dispatchEvent(new PopStateEvent('popstate')); },
+        empty: loading ? 'Loading...' : 'No agent data stored',
+      })
+    )
+  );
+}
@@ -4,6 +4,7 @@ import { E } from '../assets/icons/emoji-icons.js';
 import { HelpButton } from '../components/help-button.js';
 import { useOrgContext } from '../components/org-switcher.js';
 import { KnowledgeLink } from '../components/knowledge-link.js';
+import { timeAgo } from '../components/time.js';
 
 // ─── Constants ───────────────────────────────────────────
 var PAGE_SIZES = [25, 50, 100];
@@ -24,16 +25,6 @@ function tag(color, text) {
   return h('span', { style: { display: 'inline-block', fontSize: 9, padding: '1px 5px', borderRadius: 4, fontWeight: 600, letterSpacing: '0.02em', whiteSpace: 'nowrap', background: color + '22', color: color } }, text);
 }
 
-function timeAgo(ts) {
-  if (!ts) return '-';
-  var diff = Date.now() - new Date(ts).getTime();
-  if (diff < 5000) return 'just now';
-  if (diff < 60000) return Math.floor(diff / 1000) + 's ago';
-  if (diff < 3600000) return Math.floor(diff / 60000) + 'm ago';
-  if (diff < 86400000) return Math.floor(diff / 3600000) + 'h ago';
-  return Math.floor(diff / 86400000) + 'd ago';
-}
-
 function formatDuration(ms) {
   if (!ms) return '-';
   var s = Math.floor(ms / 1000);
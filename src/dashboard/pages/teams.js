@@ -0,0 +1,255 @@
+import { h, useState, useEffect, Fragment, useApp, engineCall, apiCall, getOrgId, showConfirm, buildAgentDataMap, renderAgentBadge } from '../components/utils.js';
+import { I } from '../components/icons.js';
+import { HelpButton } from '../components/help-button.js';
+import { useOrgContext } from '../components/org-switcher.js';
+import { Table } from '../components/table.js';
+import { Modal } from '../components/modal.js';
+import { BarChart } from '../components/charts.js';
+import { RelativeTime } from '../components/time.js';
+import { formatNumber, formatCost } from './agent-detail/shared.js?v=5';
+
+// ─── Teams ───────────────────────────────────────────────
+// Named groups of agents (departments, pods). A team doesn't change how its
+// agents run; it filters the Agents list and rolls up activity and cost.
+// Members and viewers added to a team only see that team's agents.
+
+var RANGES = [7, 30, 90];
+var COLORS = ['#6366f1', '#0ea5e9', '#15803d', '#d97706', '#dc2626', '#9333ea', '#64748b'];
+
+// Checkbox list with a filter box, for a team's agents or users
+function MemberPicker(props) {
+  var [q, setQ] = useState('');
+  var needle = q.trim().toLowerCase();
+  var shown = props.items.filter(a => !needle || (a.name || '').toLowerCase().includes(needle) || (a.email || '').toLowerCase().includes(needle));
+  var toggle = id => props.onChange(props.value.includes(id) ? props.value.filter(x => x !== id) : props.value.concat([id]));
+  return h(Fragment, null,
+    h('input', { className: 'input', placeholder: 'Filter ' + props.noun + 's...', value: q, onInput: e => setQ(e.target.value), style: { marginBottom: 6 } }),
+    h('div', { style: { maxHeight: 220, overflowY: 'auto', border: '1px solid var(--border)', borderRadius: 6, padding: 8 } },
+      shown.length === 0
+        ? h('div', { style: { fontSize: 12, color: 'var(--text-muted)' } }, props.items.length ? 'No ' + props.noun + 's match' : 'No ' + props.noun + 's in this organization')
+        : shown.map(a => h('label', { key: a.id, style: { display: 'flex', alignItems: 'center', gap: 8, fontSize: 13, padding: '3px 0' } },
+            h('input', { type: 'checkbox', checked: props.value.includes(a.id), onChange: () => toggle(a.id) }),
+            a.name || a.email, h('span', { style: { color: 'var(--text-muted)', fontSize: 11 } }, a.name ? a.email : ''), a.role && props.noun === 'user' && h('span', { className: 'badge badge-neutral', style: { fontSize: 10 } }, a.role)
+          ))
+    ),
+    h('div', { style: { fontSize: 12, color: 'var(--text-muted)', marginTop: 4 } }, props.value.length + ' selected')
+  );
+}
+
+function TeamForm(props) {
+  var initial = props.initial || {};
+  const [form, setForm] = useState({ name: initial.name || '', description: initial.description || '', color: initial.color || COLORS[0], agentIds: initial.agentIds || [], userIds: initial.userIds || [] });
+  const [saving, setSaving] = useState(false);
+  var set = (k, v) => setForm(f => Object.assign({}, f, { [k]: v }));
+  var submit = async () => {
+    setSaving(true);
+    try { await props.onSave(form); } finally { setSaving(false); }
+  };
+  return h(Modal, {
+    title: props.initial ? 'Edit Team' : 'New Team', onClose: props.onClose,
+    footer: h(Fragment, null,
+      h('button', { className: 'btn btn-secondary', onClick: props.onClose }, 'Cancel'),
+      h('button', { className: 'btn btn-primary', disabled: saving || !form.name.trim(), onClick: submit }, props.initial ? 'Save' : 'Create Team')
+    )
+  },
+    h('label', { className: 'field-label' }, 'Name'),
+    h('input', { className: 'input', value: form.name, onChange: e => set('name', e.target.value), placeholder: 'e.g. Customer Support', autoFocus: true }),
+    h('label', { className: 'field-label', style: { marginTop: 12 } }, 'Description'),
+    h('textarea', { className: 'input', rows: 2, value: form.description, onChange: e => set('description', e.target.value), placeholder: 'Optional' }),
+    h('label', { className: 'field-label', style: { marginTop: 12 } }, 'Color'),
+    h('div', { style: { display: 'flex', gap: 6 } },
+      COLORS.map(c => h('button', {
+        key: c, type: 'button', 'aria-label': 'Color ' + c, 'aria-pressed': form.color === c, onClick: () => set('color', c),
+        style: { width: 24, height: 24, borderRadius: '50%', background: c, border: form.color === c ? '2px solid var(--text)' : '2px solid transparent', cursor: 'pointer', padding: 0 }
+      }))),
+    h('label', { className: 'field-label', style: { marginTop: 12 } }, 'Agents'),
+    h(MemberPicker, { noun: 'agent', items: props.agents, value: form.agentIds, onChange: v => set('agentIds', v) }),
+    h('label', { className: 'field-label', style: { marginTop: 12 } }, 'Users'),
+    h('div', { style: { fontSize: 12, color: 'var(--text-muted)', marginBottom: 6 } }, 'Members and viewers you add here only see this team\'s agents and their messages. Admins and owners always see everything.'),
+    h(MemberPicker, { noun: 'user', items: props.users, value: form.userIds, onChange: v => set('userIds', v) })
+  );
+}
+
+function TeamDot(props) {
+  return h('span', { style: { display: 'inline-block', width: 10, height: 10, borderRadius: '50%', background: props.color || 'var(--text-muted)', flexShrink: 0 } });
+}
+
+function TeamDetail(props) {
+  const { toast } = useApp();
+  const [days, setDays] = useState(30);
+  const [report, setReport] = useState(null);
+  const [loading, setLoading] = useState(true);
+
+  useEffect(() => {
+    setLoading(true);
+    apiCall('/teams/' + props.team.id + '/report?days=' + days)
+      .then(setReport)
+      .catch(err => toast('Failed to load team report: ' + err.message, 'error'))
+      .finally(() => setLoading(false));
+  }, [props.team.id, props.team.updatedAt, days]);
+
+  var t = props.team;
+  var _muted = { fontSize: 12, color: 'var(--text-muted)' };
+
+  return h(Fragment, null,
+    h('button', { className: 'btn btn-ghost btn-sm', onClick: props.onBack, style: { marginBottom: 12 } }, '← All teams'),
+    h('div', { className: 'card', style: { padding: 16, marginBottom: 16 } },
+      h('div', { style: { display: 'flex', alignItems: 'flex-start', gap: 12, flexWrap: 'wrap' } },
+        h('div', { style: { flex: 1, minWidth: 240 } },
+          h('h2', { style: { margin: 0, fontSize: 18, display: 'flex', alignItems: 'center', gap: 8 } }, h(TeamDot, { color: t.color }), t.name),
+          t.description && h('p', { style: { fontSize: 13, marginTop: 8, whiteSpace: 'pre-wrap' } }, t.description),
+          h('div', { style: Object.assign({ marginTop: 8 }, _muted) },
+            t.agentIds.length + ' agent' + (t.agentIds.length === 1 ? '' : 's'),
+            ' · ', (t.userIds || []).length + ' user' + ((t.userIds || []).length === 1 ? '' : 's'),
+            (t.userIds || []).length > 0 && props.users.length > 0 && ': ' + t.userIds.map(id => { var u = props.users.find(x => x.id === id); return u ? (u.name || u.email) : id.slice(0, 8); }).join(', '))
+        ),
+        h('div', { style: { display: 'flex', gap: 8, alignItems: 'center' } },
+          RANGES.map(n => h('button', { key: n, className: 'btn btn-sm ' + (days === n ? 'btn-primary' : 'btn-secondary'), onClick: () => setDays(n) }, n + 'd')),
+          h('a', { className: 'btn btn-secondary btn-sm', href: '/dashboard/agents?team=' + encodeURIComponent(t.id) }, I.agents(), ' Open in Agents'),
+          props.canEdit && h('button', { className: 'btn btn-secondary btn-sm', onClick: props.onEdit }, I.edit(), ' Edit'),
+          props.canEdit && h('button', { className: 'btn btn-ghost btn-sm', style: { color: 'var(--danger)' }, onClick: props.onDelete }, I.trash(), ' Delete')
+        )
+      )
+    ),
+
+    !report
+      ? h('div', { className: 'card', style: { padding: 24, textAlign: 'center', color: 'var(--text-muted)' } }, loading ? 'Loading...' : 'Report unavailable')
+      : h(Fragment, null,
+          h('div', { className: 'stat-grid', style: { marginBottom: 16, opacity: loading ? 0.6 : 1 } },
+            h('div', { className: 'stat-card' }, h('div', { className: 'stat-label' }, 'Cost'), h('div', { className: 'stat-value' }, formatCost(report.totals.costUsd)),
+              h('div', { style: { fontSize: 11, color: 'var(--text-muted)', marginTop: 2 } }, report.estimated ? 'Includes estimates' : 'Last ' + report.days + ' days')),
+            h('div', { className: 'stat-card' }, h('div', { className: 'stat-label' }, 'Tokens'), h('div', { className: 'stat-value' }, formatNumber(report.totals.tokens))),
+            h('div', { className: 'stat-card' }, h('div', { className: 'stat-label' }, 'LLM Calls'), h('div', { className: 'stat-value' }, formatNumber(report.totals.calls))),
+            h('div', { className: 'stat-card' }, h('div', { className: 'stat-label' }, 'Activity Events'), h('div', { className: 'stat-value' }, formatNumber(report.totals.events)))
+          ),
+
+          h('div', { style: { display: 'grid', gridTemplateColumns: 'repeat(auto-fit, minmax(320px, 1fr))', gap: 16, marginBottom: 16 } },
+            h('div', { className: 'card' },
+              h('div', { className: 'card-header' }, h('h3', null, 'Cost per day')),
+              h('div', { className: 'card-body' },
+                report.totals.costUsd === 0
+                  ? h('div', { style: _muted }, 'No LLM usage in this period')
+                  : h(BarChart, { data: report.series.map(d => ({ label: d.day, value: d.costUsd })), height: 100, formatValue: formatCost, title: 'Cost per day' }))
+            ),
+            h('div', { className: 'card' },
+              h('div', { className: 'card-header' }, h('h3', null, 'Activity per day')),
+              h('div', { className: 'card-body' },
+                report.totals.events === 0
+                  ? h('div', { style: _muted }, 'No activity in this period')
+                  : h(Fragment, null,
+                      h(BarChart, { data: report.series.map(d => ({ label: d.day, value: d.events })), height: 100, formatValue: formatNumber, title: 'Activity events per day' }),
+                      h('div', { style: Object.assign({ display: 'flex', gap: 8, flexWrap: 'wrap', marginTop: 8 }, _muted) },
+                        report.eventTypes.map(e => h('span', { key: e.type, className: 'badge badge-neutral' }, e.type.replace(/_/g, ' ') + ' ' + formatNumber(e.count))))))
+            )
+          ),
+
+          h('div', { className: 'card' },
+            h('div', { className: 'card-header' }, h('h3', null, 'Members')),
+            h(Table, {
+              className: 'data-table', rows: report.members,
+              empty: 'No agents in this team yet',
+              columns: [
+                { key: 'name', label: 'Agent', render: m => renderAgentBadge(m.id, props.agentData) || m.name },
+                { key: 'costUsd', label: 'Cost', render: m => h('strong', null, formatCost(m.costUsd)) },
+                { key: 'tokens', label: 'Tokens in / out', render: m => formatNumber(m.inputTokens) + ' / ' + formatNumber(m.outputTokens) },
+                { key: 'calls', label: 'LLM Calls', render: m => formatNumber(m.calls) },
+                { key: 'events', label: 'Activity', render: m => formatNumber(m.events) },
+                { key: 'lastActiveAt', label: 'Last Active', render: m => m.lastActiveAt ? h(RelativeTime, { value: m.lastActiveAt }) : h('span', { style: _muted }, 'Not in this period') },
+              ],
+            })
+          )
+        )
+  );
+}
+
+export function TeamsPage() {
+  var orgCtx = useOrgContext();
+  var effectiveOrgId = orgCtx.selectedOrgId || getOrgId();
+  const { toast, user } = useApp();
+  const [teams, setTeams] = useState(null);
+  const [agents, setAgents] = useState([]);
+  const [users, setUsers] = useState([]);
+  const [editing, setEditing] = useState(null);
+  const [openId, setOpenId] = useState(null);
+  var canEdit = user && (user.role === 'owner' || user.role === 'admin');
+
+  var load = () => engineCall('/teams?orgId=' + encodeURIComponent(effectiveOrgId))
+    .then(d => setTeams(d.teams || []))
+    .catch(err => { toast('Failed to load teams: ' + err.message, 'error'); setTeams([]); });
+
+  useEffect(() => { load(); }, [effectiveOrgId]);
+  useEffect(() => {
+    apiCall('/agents?limit=200' + (orgCtx.selectedOrgId ? '&clientOrgId=' + orgCtx.selectedOrgId : '')).then(d => setAgents(d.agents || [])).catch(() => {});
+  }, [orgCtx.selectedOrgId]);
+  useEffect(() => {
+    if (canEdit) apiCall('/users?limit=200').then(d => setUsers(d.users || d || [])).catch(() => {});
+  }, [canEdit]);
+
+  var agentData = buildAgentDataMap(agents);
+  var open = (teams || []).find(t => t.id === openId);
+
+  var save = async (form) => {
+    try {
+      if (editing && editing.id) {
+        await engineCall('/teams/' + editing.id, { method: 'PATCH', body: JSON.stringify({ name: form.name, description: form.description, color: form.color }) });
+        await engineCall('/teams/' + editing.id + '/members', { method: 'PUT', body: JSON.stringify({ agentIds: form.agentIds }) });
+        await engineCall('/teams/' + editing.id + '/users', { method: 'PUT', body: JSON.stringify({ userIds: form.userIds }) });
+        toast('Team updated', 'success');
+      } else {
+        var res = await engineCall('/teams', { method: 'POST', body: JSON.stringify(Object.assign({ orgId: effectiveOrgId }, form)) });
+        toast('Team created', 'success');
+        setOpenId(res.team.id);
+      }
+      setEditing(null);
+      load();
+    } catch (err) { toast(err.message, 'error'); }
+  };
+
+  var remove = async (team) => {
+    var ok = await showConfirm({
+      title: 'Delete team "' + team.name + '"?',
+      message: 'The ' + team.agentIds.length + ' agent' + (team.agentIds.length === 1 ? '' : 's') + ' in it are not affected; they just stop being grouped under this team.',
+      danger: true, confirmText: 'Delete Team',
+    });
+    if (!ok) return;
+    try {
+      await engineCall('/teams/' + team.id, { method: 'DELETE' });
+      toast('Team deleted', 'success');
+      setOpenId(null);
+      load();
+    } catch (err) { toast(err.message, 'error'); }
+  };
+
+  return h('div', { className: 'page-inner' },
+    h(orgCtx.Switcher),
+    h('div', { className: 'page-header' },
+      h('h1', { style: { display: 'flex', alignItems: 'center' } }, 'Teams', h(HelpButton, { label: 'Teams' },
+        h('p', null, 'Group agents into teams such as departments or pods. An agent can be in more than one team.'),
+        h('p', null, 'Open a team to see its combined LLM cost and activity by day and by agent. On the Agents page, filter the list by team.'),
+        h('p', null, 'Teams do not change what an agent can do. Admins can create and edit teams. Every change is recorded in the audit log.'),
+        h('p', null, 'Add dashboard users to a team to limit what they see. A member or viewer in one or more teams only sees those teams\' agents, and the messages those agents sent or received. Users in no team, admins and owners see everything.')
+      )),
+      !openId && canEdit && h('button', { className: 'btn btn-primary', onClick: () => setEditing({}) }, I.plus(), ' New Team')
+    ),
+
+    open
+      ? h(TeamDetail, { team: open, agentData, users, canEdit, onBack: () => setOpenId(null), onEdit: () => setEditing(open), onDelete: () => remove(open) })
+      : h('div', { className: 'card' }, h(Table, {
+          className: 'data-table', rows: teams || [],
+          onRowClick: t => setOpenId(t.id),
+          empty: teams === null ? 'Loading...' : canEdit ? 'No teams yet. Create one to group agents.' : 'No teams yet',
+          columns: [
+            { key: 'name', label: 'Team', render: t => h('div', { style: { display: 'flex', alignItems: 'center', gap: 8 } }, h(TeamDot, { color: t.color }),
+              h('div', null, h('div', { style: { fontWeight: 500 } }, t.name), t.description && h('div', { style: { fontSize: 12, color: 'var(--text-muted)' } }, t.description))) },
+            { key: 'agents', label: 'Agents', render: t => t.agentIds.length === 0
+              ? h('span', { style: { color: 'var(--text-muted)' } }, 'None')
+              : h('span', { style: { fontSize: 13 } },
+                  t.agentIds.slice(0, 5).map(id => (agentData[id] && agentData[id].name) || id.slice(0, 8)).join(', '),
+                  t.agentIds.length > 5 && h('span', { style: { color: 'var(--text-muted)' } }, ' +' + (t.agentIds.length - 5) + ' more')) },
+            { key: 'updatedAt', label: 'Updated', render: t => h(RelativeTime, { value: t.updatedAt }) },
+          ],
+        })),
+
+    editing && h(TeamForm, { initial: editing.id ? editing : null, agents, users, onClose: () => setEditing(null), onSave: save })
+  );
+}
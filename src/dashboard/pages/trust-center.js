@@ -0,0 +1,167 @@
+import { h, useState, useEffect, Fragment, useApp, engineCall, getOrgId, showConfirm, downloadExport } from '../components/utils.js';
+import { I } from '../components/icons.js';
+import { HelpButton } from '../components/help-button.js';
+import { useOrgContext } from '../components/org-switcher.js';
+import { RelativeTime } from '../components/time.js';
+
+const STATUS_BADGE = { on: 'success', partial: 'warning', off: 'danger', info: 'neutral' };
+const STATUS_LABEL = { on: 'In place', partial: 'Partial', off: 'Not enabled', info: 'Info' };
+
+export function TrustCenterPage() {
+  var orgCtx = useOrgContext();
+  var effectiveOrgId = orgCtx.selectedOrgId || getOrgId();
+  const { toast, user } = useApp();
+  const [data, setData] = useState(null);
+  const [loading, setLoading] = useState(true);
+  const [form, setForm] = useState({ intro: '', contactEmail: '', hiddenSections: [] });
+  const [saving, setSaving] = useState(false);
+  const [exporting, setExporting] = useState(null);
+  const canEdit = user?.role === 'owner' || user?.role === 'admin';
+
+  const load = () => {
+    setLoading(true);
+    engineCall('/trust?orgId=' + encodeURIComponent(effectiveOrgId))
+      .then(d => {
+        setData(d);
+        setForm({ intro: d.settings.intro, contactEmail: d.settings.contactEmail, hiddenSections: d.settings.hiddenSections });
+      })
+      .catch(err => toast('Failed to load Trust page: ' + err.message, 'error'))
+      .finally(() => setLoading(false));
+  };
+  useEffect(() => { load(); }, [effectiveOrgId]);
+
+  const save = async () => {
+    setSaving(true);
+    try {
+      await engineCall('/trust/settings', { method: 'PUT', body: JSON.stringify(Object.assign({ orgId: effectiveOrgId }, form)) });
+      toast('Trust page saved', 'success');
+      load();
+    } catch (err) { toast(err.message, 'error'); }
+    setSaving(false);
+  };
+
+  const publish = async () => {
+    if (data.settings.token) {
+      var ok = await showConfirm({
+        title: 'Generate a new link?',
+        message: 'The Trust page will move to a new address.',
+        warning: 'Anyone holding the current link will no longer be able to open it.',
+        confirmText: 'New link',
+      });
+      if (!ok) return;
+    }
+    try {
+      await engineCall('/trust/publish', { method: 'POST', body: JSON.stringify({ orgId: effectiveOrgId }) });
+      toast('Trust page published', 'success');
+      load();
+    } catch (err) { toast(err.message, 'error'); }
+  };
+
+  const unpublish = async () => {
+    var ok = await showConfirm({
+      title: 'Unpublish the Trust page?',
+      message: 'The public link stops working immediately. You can still download the page and share it as a file.',
+      danger: true,
+      confirmText: 'Unpublish',
+    });
+    if (!ok) return;
+    try {
+      await engineCall('/trust/unpublish', { method: 'POST', body: JSON.stringify({ orgId: effectiveOrgId }) });
+      toast('Trust page unpublished', 'success');
+      load();
+    } catch (err) { toast(err.message, 'error'); }
+  };
+
+  const exportAs = (format) => {
+    setExporting(format);
+    downloadExport('/engine/trust/export?orgId=' + encodeURIComponent(effectiveOrgId), format)
+      .catch(err => toast(err.message, 'error'))
+      .finally(() => setExporting(null));
+  };
+
+  const toggleSection = (id) => {
+    var hidden = form.hiddenSections.includes(id) ? form.hiddenSections.filter(s => s !== id) : form.hiddenSections.concat([id]);
+    setForm(Object.assign({}, form, { hiddenSections: hidden }));
+  };
+
+  var publicUrl = data?.publicPath ? window.location.origin + data.publicPath : null;
+  var dirty = data && (form.intro !== data.settings.intro || form.contactEmail !== data.settings.contactEmail
+    || form.hiddenSections.slice().sort().join() !== data.settings.hiddenSections.slice().sort().join());
+  var _muted = { fontSize: 12, color: 'var(--text-muted)' };
+  var _h4 = { marginTop: 16, marginBottom: 8, fontSize: 14 };
+
+  return h('div', { className: 'page-inner' },
+    h(orgCtx.Switcher),
+    h('div', { className: 'page-header' },
+      h('h1', { style: { display: 'flex', alignItems: 'center' } }, 'Trust Center', h(HelpButton, { label: 'Trust Center' },
+        h('p', null, 'A one-page summary of the security controls in place for this organization, for customers\' security reviewers. It is assembled from live configuration every time it is viewed, so it never goes stale.'),
+        h('h4', { style: _h4 }, 'Sharing it'),
+        h('ul', { style: { paddingLeft: 20 } },
+          h('li', null, h('strong', null, 'Download'), ' an HTML or PDF copy to attach to a questionnaire.'),
+          h('li', null, h('strong', null, 'Publish'), ' to get a link anyone can open without signing in. Generating a new link or unpublishing revokes the old one.')
+        ),
+        h('p', null, 'Only the status of each control is shown — no keys, addresses, rule contents or other configuration values. Hide any section you would rather not share.')
+      )),
+      h('div', { style: { display: 'flex', gap: 8 } },
+        h('button', { className: 'btn btn-secondary', onClick: load, disabled: loading }, I.refresh(), ' Refresh'),
+        h('button', { className: 'btn btn-secondary', onClick: () => exportAs('html'), disabled: !!exporting || !data }, I.download(), exporting === 'html' ? ' Exporting...' : ' HTML'),
+        h('button', { className: 'btn btn-secondary', onClick: () => exportAs('pdf'), disabled: !!exporting || !data }, I.download(), exporting === 'pdf' ? ' Exporting...' : ' PDF')
+      )
+    ),
+
+    !data ? h('div', { style: { padding: 40, textAlign: 'center', color: 'var(--text-muted)' } }, loading ? 'Loading...' : 'Trust page unavailable')
+    : h(Fragment, null,
+        h('div', { className: 'card', style: { marginBottom: 16 } },
+          h('div', { className: 'card-header', style: { display: 'flex', justifyContent: 'space-between', alignItems: 'center' } },
+            h('h3', { style: { display: 'flex', alignItems: 'center', gap: 8 } }, 'Public link',
+              h('span', { className: 'badge badge-' + (publicUrl ? 'success' : 'neutral') }, publicUrl ? 'published' : 'not published')),
+            canEdit && h('div', { style: { display: 'flex', gap: 8 } },
+              publicUrl && h('button', { className: 'btn btn-ghost btn-sm', onClick: unpublish }, 'Unpublish'),
+              h('button', { className: 'btn btn-primary btn-sm', onClick: publish }, I.link(), publicUrl ? ' New link' : ' Publish'))
+          ),
+          h('div', { className: 'card-body' },
+            publicUrl
+              ? h(Fragment, null,
+                  h('div', { style: { display: 'flex', gap: 8 } },
+                    h('input', { className: 'input', readOnly: true, value: publicUrl, onFocus: e => e.target.select(), style: { fontFamily: 'var(--font-mono, monospace)', fontSize: 12 } }),
+                    h('button', { className: 'btn btn-secondary', onClick: () => { navigator.clipboard.writeText(publicUrl); toast('Link copied', 'success'); } }, I.copy(), ' Copy'),
+                    h('a', { className: 'btn btn-ghost', href: publicUrl, target: '_blank', rel: 'noopener' }, 'Open')),
+                  h('div', { style: Object.assign({ marginTop: 6 }, _muted) }, 'Published ', h(RelativeTime, { value: data.settings.publishedAt })))
+              : h('div', { style: _muted }, canEdit ? 'Publish to get a link you can hand to reviewers. Nothing is public until you do.' : 'Not published. An admin can publish a public link.')
+          )
+        ),
+
+        h('div', { className: 'card', style: { marginBottom: 16 } },
+          h('div', { className: 'card-header' }, h('h3', null, 'Page content')),
+          h('div', { className: 'card-body', style: { display: 'grid', gap: 12 } },
+            h('label', null, h('div', { className: 'stat-label' }, 'Introduction'),
+              h('textarea', { className: 'input', rows: 3, disabled: !canEdit, value: form.intro, placeholder: 'Shown at the top of the page', onInput: e => setForm(Object.assign({}, form, { intro: e.target.value })) })),
+            h('label', null, h('div', { className: 'stat-label' }, 'Security contact'),
+              h('input', { className: 'input', type: 'email', disabled: !canEdit, value: form.contactEmail, placeholder: 'security@example.com', onInput: e => setForm(Object.assign({}, form, { contactEmail: e.target.value })) })),
+            h('div', null, h('div', { className: 'stat-label', style: { marginBottom: 4 } }, 'Sections'),
+              h('div', { style: { display: 'flex', flexWrap: 'wrap', gap: 16 } },
+                data.sections.map(s => h('label', { key: s.id, style: { display: 'flex', alignItems: 'center', gap: 6, fontSize: 13 } },
+                  h('input', { type: 'checkbox', disabled: !canEdit, checked: !form.hiddenSections.includes(s.id), onChange: () => toggleSection(s.id) }), s.title)))),
+            canEdit && h('div', null, h('button', { className: 'btn btn-primary', onClick: save, disabled: saving || !dirty }, saving ? 'Saving...' : 'Save'))
+          )
+        ),
+
+        h('div', { className: 'card', style: { marginBottom: 16 } },
+          h('div', { className: 'card-header', style: { display: 'flex', justifyContent: 'space-between', alignItems: 'center' } },
+            h('h3', null, 'Preview'),
+            h('span', { style: _muted }, 'As of ', h(RelativeTime, { value: data.page.generatedAt }))),
+          h('div', { className: 'card-body' },
+            data.page.intro && h('p', { style: { marginTop: 0 } }, data.page.intro),
+            data.page.sections.length === 0 && h('div', { style: _muted }, 'Every section is hidden'),
+            data.page.sections.map(s => h('div', { key: s.id, style: { marginBottom: 16 } },
+              h('h4', { style: { margin: '0 0 6px', fontSize: 14 } }, s.title),
+              s.items.map((it, i) => h('div', { key: i, style: { display: 'flex', alignItems: 'baseline', gap: 10, padding: '4px 0', borderTop: '1px solid var(--border)', fontSize: 13 } },
+                h('span', { className: 'badge badge-' + STATUS_BADGE[it.status], style: { minWidth: 84, textAlign: 'center' } }, STATUS_LABEL[it.status]),
+                h('span', { style: { flex: 1 } }, h('strong', null, it.label), it.detail ? h('span', { style: _muted }, ' — ' + it.detail) : null)))
+            )),
+            data.page.contactEmail && h('div', { style: _muted }, 'Security questions: ' + data.page.contactEmail)
+          )
+        )
+      )
+  );
+}
@@ -1,8 +1,11 @@
-import { h, useState, useEffect, Fragment, useApp, apiCall, showConfirm } from '../components/utils.js';
+import { h, useState, useEffect, Fragment, useApp, apiCall, showConfirm, downloadCsv } from '../components/utils.js';
 import { I } from '../components/icons.js';
 import { Modal } from '../components/modal.js';
 import { HelpButton } from '../components/help-button.js';
 import { KnowledgeLink } from '../components/knowledge-link.js';
+import { Table, useSort } from '../components/table.js';
+import { FilterBar, useFilters } from '../components/filter-bar.js';
+import { usePasswordPolicy, PasswordRules, passwordOk, generatePassword as policyPassword } from '../components/password-rules.js';
 
 // ─── Permission Editor Component ───────────────────
 
@@ -440,6 +443,117 @@ function InlinePermissionPicker({ permissions, pageRegistry, onChange }) {
   );
 }
 
+// ─── Offboarding Wizard ────────────────────────────
+// Shown instead of the plain confirm when a user being deactivated still
+// owns agents, API keys or scheduled reports. Each step says what happens
+// to one kind of resource; the last step reviews and deactivates.
+
+function OffboardingWizard({ user, owned, onDone, onClose }) {
+  var app = useApp();
+  var toast = app.toast;
+  var steps = [];
+  if (owned.agents.length) steps.push('agents');
+  if (owned.apiKeys.length) steps.push('apiKeys');
+  if (owned.reportSchedules.length) steps.push('reports');
+  steps.push('review');
+
+  var [step, setStep] = useState(0);
+  var [candidates, setCandidates] = useState([]);
+  var [reassignTo, setReassignTo] = useState('');
+  var [keyAction, setKeyAction] = useState('reassign');
+  var [reportAction, setReportAction] = useState('reassign');
+  var [saving, setSaving] = useState(false);
+
+  useEffect(function() {
+    apiCall('/users?status=active&limit=200').then(function(d) {
+      setCandidates((d.users || d || []).filter(function(u) { return u.id !== user.id; }));
+    }).catch(function() {});
+  }, [user.id]);
+
+  var needsTarget = owned.agents.length > 0 || (owned.apiKeys.length > 0 && keyAction === 'reassign') || (owned.reportSchedules.length > 0 && reportAction === 'reassign');
+  var target = candidates.find(function(u) { return u.id === reassignTo; });
+  var targetLabel = target ? (target.name || target.email) : 'the new owner';
+  var current = steps[step];
+
+  var finish = async function() {
+    setSaving(true);
+    try {
+      await apiCall('/users/' + user.id + '/offboard', { method: 'POST', body: JSON.stringify({ reassignTo: reassignTo || undefined, apiKeys: keyAction, reportSchedules: reportAction }) });
+      toast('User offboarded and deactivated', 'success');
+      onDone();
+    } catch (e) { toast(e.message, 'error'); }
+    setSaving(false);
+  };
+
+  var list = function(items, render) {
+    return h('ul', { style: { margin: '8px 0 12px', paddingLeft: 18, fontSize: 13, maxHeight: 180, overflowY: 'auto' } },
+      items.map(function(item) { return h('li', { key: item.id, style: { marginBottom: 4 } }, render(item)); })
+    );
+  };
+  var choice = function(name, value, selected, onSelect, label, hint) {
+    return h('label', { style: { display: 'flex', gap: 8, alignItems: 'flex-start', padding: '6px 0', cursor: 'pointer', fontSize: 13 } },
+      h('input', { type: 'radio', name: name, checked: selected === value, onChange: function() { onSelect(value); }, style: { marginTop: 3 } }),
+      h('div', null, h('div', null, label), hint && h('div', { style: { fontSize: 12, color: 'var(--text-muted)' } }, hint))
+    );
+  };
+  var targetPicker = h('div', { className: 'form-group' },
+    h('label', { className: 'form-label' }, 'New owner'),
+    h('select', { className: 'input', value: reassignTo, onChange: function(e) { setReassignTo(e.target.value); } },
+      h('option', { value: '' }, 'Choose a user...'),
+      candidates.map(function(u) { return h('option', { key: u.id, value: u.id }, (u.name || u.email) + ' (' + u.role + ')'); })
+    )
+  );
+
+  var body;
+  if (current === 'agents') {
+    body = h(Fragment, null,
+      h('p', { style: { fontSize: 13, color: 'var(--text-secondary)' } }, h('strong', null, user.name || user.email), ' created ', owned.agents.length, ' agent', owned.agents.length === 1 ? '' : 's', '. They keep running; choose who owns them from now on.'),
+      list(owned.agents, function(a) { return h(Fragment, null, a.name, ' ', h('span', { style: { color: 'var(--text-muted)' } }, a.email + ' · ' + a.status)); }),
+      targetPicker
+    );
+  } else if (current === 'apiKeys') {
+    body = h(Fragment, null,
+      h('p', { style: { fontSize: 13, color: 'var(--text-secondary)' } }, 'These API keys act as ', h('strong', null, user.name || user.email), '. A key left with a deactivated owner keeps that owner\'s identity in the audit log.'),
+      list(owned.apiKeys, function(k) { return h(Fragment, null, k.name, ' ', h('code', { style: { fontSize: 11 } }, k.keyPrefix + '…'), k.lastUsedAt ? h('span', { style: { color: 'var(--text-muted)' } }, ' · last used ' + new Date(k.lastUsedAt).toLocaleDateString()) : null); }),
+      choice('offboard-keys', 'reassign', keyAction, setKeyAction, 'Reassign to ' + targetLabel, 'Integrations keep working with the same key.'),
+      choice('offboard-keys', 'revoke', keyAction, setKeyAction, 'Revoke', 'Anything using these keys stops working immediately.'),
+      keyAction === 'reassign' && !owned.agents.length && targetPicker
+    );
+  } else if (current === 'reports') {
+    body = h(Fragment, null,
+      h('p', { style: { fontSize: 13, color: 'var(--text-secondary)' } }, 'Scheduled reports fetch their pages as their owner, so these stop sending once ', h('strong', null, user.name || user.email), ' is deactivated.'),
+      list(owned.reportSchedules, function(s) { return h(Fragment, null, s.name, ' ', h('span', { style: { color: 'var(--text-muted)' } }, s.frequency + (s.enabled ? '' : ' · paused'))); }),
+      choice('offboard-reports', 'reassign', reportAction, setReportAction, 'Reassign to ' + targetLabel, 'Reports will show what the new owner can see.'),
+      choice('offboard-reports', 'disable', reportAction, setReportAction, 'Pause', 'Keep the schedules but stop sending them.'),
+      reportAction === 'reassign' && !owned.agents.length && !(owned.apiKeys.length && keyAction === 'reassign') && targetPicker
+    );
+  } else {
+    var lines = [];
+    if (owned.agents.length) lines.push(owned.agents.length + ' agent' + (owned.agents.length === 1 ? '' : 's') + ' move to ' + targetLabel);
+    if (owned.apiKeys.length) lines.push(owned.apiKeys.length + ' API key' + (owned.apiKeys.length === 1 ? '' : 's') + (keyAction === 'revoke' ? ' will be revoked' : ' move to ' + targetLabel));
+    if (owned.reportSchedules.length) lines.push(owned.reportSchedules.length + ' scheduled report' + (owned.reportSchedules.length === 1 ? '' : 's') + (reportAction === 'disable' ? ' will be paused' : ' move to ' + targetLabel));
+    lines.push((user.name || user.email) + ' will be deactivated and unable to sign in');
+    body = h(Fragment, null,
+      h('p', { style: { fontSize: 13, color: 'var(--text-secondary)' } }, 'Review the changes:'),
+      h('ul', { style: { margin: '8px 0 0', paddingLeft: 18, fontSize: 13 } }, lines.map(function(l) { return h('li', { key: l, style: { marginBottom: 4 } }, l); })),
+      needsTarget && !target && h('div', { style: { marginTop: 12, fontSize: 12, color: 'var(--danger)' } }, 'Go back and choose a new owner.')
+    );
+  }
+
+  var canContinue = current === 'review' || (current === 'agents' ? !!reassignTo : true);
+  return h(Modal, {
+    title: 'Offboard ' + (user.name || user.email) + ' — Step ' + (step + 1) + ' of ' + steps.length,
+    onClose: onClose,
+    width: 500,
+    footer: h(Fragment, null,
+      h('button', { className: 'btn btn-secondary', onClick: step === 0 ? onClose : function() { setStep(step - 1); } }, step === 0 ? 'Cancel' : 'Back'),
+      current === 'review'
+        ? h('button', { className: 'btn btn-danger', onClick: finish, disabled: saving || (needsTarget && !target) }, saving ? 'Deactivating...' : 'Deactivate User')
+        : h('button', { className: 'btn btn-primary', onClick: function() { setStep(step + 1); }, disabled: !canContinue }, 'Continue')
+    )
+  }, body);
+}
+
 // ─── Users Page ────────────────────────────────────
 
 export function UsersPage() {
@@ -451,22 +565,25 @@ export function UsersPage() {
   var [resetTarget, setResetTarget] = useState(null);
   var [clientOrgs, setClientOrgs] = useState([]);
   var [newPassword, setNewPassword] = useState('');
+  var [resetMustChange, setResetMustChange] = useState(true);
   var [resetting, setResetting] = useState(false);
+  var passwordPolicy = usePasswordPolicy();
+  var [resetLink, setResetLink] = useState(null);      // { user, link, expiresAt } when no SMTP is set up
   var [permTarget, setPermTarget] = useState(null);    // user object for permission editing
   var [permGrants, setPermGrants] = useState('*');      // current permissions for target
   var [pageRegistry, setPageRegistry] = useState(null); // page/tab registry from backend
 
-  var load = function() { apiCall('/users').then(function(d) { setUsers(d.users || d || []); }).catch(function() {}); };
+  var sort = useSort('createdAt', 'desc');
+  var filters = useFilters({ q: '', role: '', status: '' });
+  var load = function() { apiCall('/users?limit=200&' + sort.query + (filters.query ? '&' + filters.query : '')).then(function(d) { setUsers(d.users || d || []); }).catch(function() {}); };
+  useEffect(load, [sort.query, filters.query]);
   useEffect(function() {
-    load();
     apiCall('/page-registry').then(function(d) { setPageRegistry(d); }).catch(function() {});
     apiCall('/organizations').then(function(d) { setClientOrgs(d.organizations || []); }).catch(function() {});
   }, []);
 
   var generateCreatePassword = function() {
-    var chars = 'ABCDEFGHJKLMNPQRSTUVWXYZabcdefghjkmnpqrstuvwxyz23456789!@#$%';
-    var pw = '';
-    for (var i = 0; i < 16; i++) pw += chars[Math.floor(Math.random() * chars.length)];
+    var pw = policyPassword(passwordPolicy);
     setForm(function(f) { return Object.assign({}, f, { password: pw }); });
   };
 
@@ -487,31 +604,84 @@ export function UsersPage() {
     if (!resetTarget || !newPassword) return;
     setResetting(true);
     try {
-      await apiCall('/users/' + resetTarget.id + '/reset-password', { method: 'POST', body: JSON.stringify({ password: newPassword }) });
+      await apiCall('/users/' + resetTarget.id + '/reset-password', { method: 'POST', body: JSON.stringify({ password: newPassword, mustChange: resetMustChange }) });
       toast('Password reset for ' + (resetTarget.name || resetTarget.email), 'success');
       setResetTarget(null);
       setNewPassword('');
+      load();
     } catch (e) { toast(e.message, 'error'); }
     setResetting(false);
   };
 
+  var sendResetLink = async function(u) {
+    var ok = await showConfirm({
+      title: 'Send Reset Link',
+      message: 'Send "' + (u.name || u.email) + '" a link to choose a new password? The link works once and expires in 1 hour. Their current password keeps working until they use it.',
+      confirmText: 'Send Link'
+    });
+    if (!ok) return;
+    try {
+      var d = await apiCall('/users/' + u.id + '/send-reset', { method: 'POST' });
+      if (d.sent) toast('Reset link emailed to ' + d.email, 'success');
+      else setResetLink({ user: u, link: d.link, expiresAt: d.expiresAt });
+    } catch (e) { toast(e.message, 'error'); }
+  };
+
+  var toggleMustChange = async function(u) {
+    var required = !u.mustResetPassword;
+    var ok = await showConfirm({
+      title: required ? 'Require Password Change' : 'Cancel Password Change',
+      message: required
+        ? '"' + (u.name || u.email) + '" will have to choose a new password the next time they sign in, or the next time they load the dashboard if already signed in.'
+        : '"' + (u.name || u.email) + '" will no longer be asked to change their password. A password past the policy\'s maximum age still has to be changed.',
+      confirmText: required ? 'Require Change' : 'Cancel Requirement'
+    });
+    if (!ok) return;
+    try {
+      await apiCall('/users/' + u.id + '/require-password-change', { method: 'POST', body: JSON.stringify({ required: required }) });
+      toast(required ? 'Password change required for ' + (u.name || u.email) : 'Requirement cleared', 'success');
+      load();
+    } catch (e) { toast(e.message, 'error'); }
+  };
+
   var openEditUser = function(u) {
     setEditUser(u);
-    setEditForm({ name: u.name || '', role: u.role || 'viewer', clientOrgId: u.clientOrgId || '' });
+    setEditForm({ name: u.name || '', role: u.role || 'viewer', clientOrgId: u.clientOrgId || '', password: '', totpCode: '' });
   };
 
+  // Role changes go through their own endpoint, which guards the owner role
   var doEditUser = async function() {
     if (!editUser) return;
+    var roleChanged = editForm.role !== editUser.role;
     try {
-      await apiCall('/users/' + editUser.id, { method: 'PATCH', body: JSON.stringify({ name: editForm.name, role: editForm.role, clientOrgId: editForm.clientOrgId || null }) });
-      toast('User updated', 'success');
-      setEditUser(null);
-      load();
-    } catch (e) { toast(e.message || 'Update failed', 'error'); }
+      await apiCall('/users/' + editUser.id, { method: 'PATCH', body: JSON.stringify({ name: editForm.name, clientOrgId: editForm.clientOrgId || null }) });
+    } catch (e) { toast(e.message || 'Update failed', 'error'); return; }
+    if (roleChanged) {
+      try {
+        await apiCall('/users/' + editUser.id + '/role', { method: 'POST', body: JSON.stringify({ role: editForm.role, password: editForm.password || undefined, totpCode: editForm.totpCode || undefined }) });
+      } catch (e) {
+        toast('Role not changed: ' + (e.message || 'Update failed'), 'error');
+        load();
+        return;
+      }
+    }
+    toast(roleChanged ? 'User updated — role changed from ' + editUser.role + ' to ' + editForm.role : 'User updated', 'success');
+    setEditUser(null);
+    load();
   };
 
+  var [offboarding, setOffboarding] = useState(null);
+
   var toggleActive = async function(user) {
     var action = user.isActive === false ? 'reactivate' : 'deactivate';
+    if (action === 'deactivate') {
+      // Anything the user owns is handed over first, in the offboarding wizard
+      var owned = await apiCall('/users/' + user.id + '/offboarding').catch(function() { return null; });
+      if (owned && (owned.agents.length || owned.apiKeys.length || owned.reportSchedules.length)) {
+        setOffboarding({ user: user, owned: owned });
+        return;
+      }
+    }
     var ok = await showConfirm({
       title: action === 'deactivate' ? 'Deactivate User' : 'Reactivate User',
       message: action === 'deactivate'
@@ -532,7 +702,8 @@ export function UsersPage() {
   var [deleteTarget, setDeleteTarget] = useState(null);
   var [deleteTyped, setDeleteTyped] = useState('');
   var [editUser, setEditUser] = useState(null);
-  var [editForm, setEditForm] = useState({ name: '', role: '', clientOrgId: '' });
+  var [editForm, setEditForm] = useState({ name: '', role: '', clientOrgId: '', password: '', totpCode: '' });
+  var isOwner = app.user && app.user.role === 'owner';
 
   var startDelete = function(user) { setDeleteTarget(user); setDeleteStep(1); setDeleteTyped(''); };
   var cancelDelete = function() { setDeleteTarget(null); setDeleteStep(0); setDeleteTyped(''); };
@@ -563,10 +734,7 @@ export function UsersPage() {
   };
 
   var generatePassword = function() {
-    var chars = 'ABCDEFGHJKLMNPQRSTUVWXYZabcdefghjkmnpqrstuvwxyz23456789!@#$%';
-    var pw = '';
-    for (var i = 0; i < 16; i++) pw += chars[Math.floor(Math.random() * chars.length)];
-    setNewPassword(pw);
+    setNewPassword(policyPassword(passwordPolicy));
   };
 
   // Permission badge for display
@@ -598,26 +766,34 @@ export function UsersPage() {
         ),
         h('h4', { style: { marginTop: 16, marginBottom: 8, fontSize: 14 } }, 'Page Permissions'),
         h('p', null, 'Click the shield icon on a Member or Viewer to control which pages and tabs they can see. Pages with tabs (like Agents) allow tab-level control.'),
+        h('h4', { style: { marginTop: 16, marginBottom: 8, fontSize: 14 } }, 'Sign-in Activity'),
+        h('p', null, 'Last Login, Last IP and Device come from each user\'s most recent sign-in. Sessions counts the browsers and clients still signed in — a session ends on logout, after 7 days without use, or when the user is deactivated. Use the column picker to hide any of these.'),
+        h('h4', { style: { marginTop: 16, marginBottom: 8, fontSize: 14 } }, 'Offboarding'),
+        h('p', null, 'Deactivating someone who still owns agents, API keys or scheduled reports opens a short walkthrough: pick who takes over their agents, reassign or revoke their keys, and reassign or pause their reports. Everything is handed over before the account is deactivated.'),
         h('div', { style: { marginTop: 12, padding: 12, background: 'var(--bg-secondary, #1e293b)', borderRadius: 'var(--radius, 8px)', fontSize: 13 } }, h('strong', null, 'Tip: '), 'Owner and Admin users always have full access — permissions only apply to Member and Viewer roles.')
       )), h('p', { style: { color: 'var(--text-muted)', fontSize: 13 } }, 'Manage team members and their access')),
-      h('button', { className: 'btn btn-primary', onClick: function() { setCreating(true); } }, I.plus(), ' Add User')
+      h('div', { style: { display: 'flex', gap: 8 } },
+        h('button', { className: 'btn btn-secondary', title: 'Download every user matching the current filters', onClick: function() { downloadCsv('/users?' + sort.query + (filters.query ? '&' + filters.query : '')).catch(function(err) { toast(err.message, 'error'); }); } }, I.download(), ' Export CSV'),
+        h('button', { className: 'btn btn-primary', onClick: function() { setCreating(true); } }, I.plus(), ' Add User')
+      )
     ),
 
     // Create user modal
-    creating && h(Modal, { title: 'Add User', onClose: function() { setCreating(false); setShowCreatePerms(false); }, width: 520, footer: h(Fragment, null, h('button', { className: 'btn btn-secondary', onClick: function() { setCreating(false); setShowCreatePerms(false); } }, 'Cancel'), h('button', { className: 'btn btn-primary', onClick: create, disabled: !form.email || !form.password }, 'Create User')) },
+    creating && h(Modal, { title: 'Add User', onClose: function() { setCreating(false); setShowCreatePerms(false); }, width: 520, footer: h(Fragment, null, h('button', { className: 'btn btn-secondary', onClick: function() { setCreating(false); setShowCreatePerms(false); } }, 'Cancel'), h('button', { className: 'btn btn-primary', onClick: create, disabled: !form.email || !passwordOk(passwordPolicy, form.password) }, 'Create User')) },
       h('div', { className: 'form-group' }, h('label', { className: 'form-label' }, 'Name'), h('input', { className: 'input', value: form.name, onChange: function(e) { setForm(function(f) { return Object.assign({}, f, { name: e.target.value }); }); }, autoFocus: true })),
       h('div', { className: 'form-group' }, h('label', { className: 'form-label' }, 'Email *'), h('input', { className: 'input', type: 'email', value: form.email, onChange: function(e) { setForm(function(f) { return Object.assign({}, f, { email: e.target.value }); }); } })),
       h('div', { className: 'form-group' },
         h('label', { className: 'form-label' }, 'Initial Password *'),
         h('div', { style: { display: 'flex', gap: 8 } },
-          h('input', { className: 'input', type: 'text', value: form.password, onChange: function(e) { setForm(function(f) { return Object.assign({}, f, { password: e.target.value }); }); }, placeholder: 'Min 8 characters', style: { flex: 1, fontFamily: 'var(--font-mono)', fontSize: 13 } }),
+          h('input', { className: 'input', type: 'text', value: form.password, onChange: function(e) { setForm(function(f) { return Object.assign({}, f, { password: e.target.value }); }); }, placeholder: 'Min ' + passwordPolicy.minLength + ' characters', style: { flex: 1, fontFamily: 'var(--font-mono)', fontSize: 13 } }),
           h('button', { type: 'button', className: 'btn btn-secondary btn-sm', onClick: generateCreatePassword, title: 'Generate random password', style: { whiteSpace: 'nowrap' } }, I.refresh(), ' Generate')
         ),
+        h(PasswordRules, { policy: passwordPolicy, password: form.password }),
         form.password && h('div', { style: { marginTop: 6, padding: 8, background: 'var(--warning-soft, rgba(153,27,27,0.08))', borderRadius: 6, fontSize: 11, color: 'var(--text-secondary)' } },
           'The user will be required to change this password on their first login. Share it securely.'
         )
       ),
-      h('div', { className: 'form-group' }, h('label', { className: 'form-label' }, 'Role'), h('select', { className: 'input', value: form.role, onChange: function(e) { setForm(function(f) { return Object.assign({}, f, { role: e.target.value }); }); } }, h('option', { value: 'viewer' }, 'Viewer'), h('option', { value: 'member' }, 'Member'), h('option', { value: 'admin' }, 'Admin'), h('option', { value: 'owner' }, 'Owner'))),
+      h('div', { className: 'form-group' }, h('label', { className: 'form-label' }, 'Role'), h('select', { className: 'input', value: form.role, onChange: function(e) { setForm(function(f) { return Object.assign({}, f, { role: e.target.value }); }); } }, h('option', { value: 'viewer' }, 'Viewer'), h('option', { value: 'member' }, 'Member'), h('option', { value: 'admin' }, 'Admin'), isOwner && h('option', { value: 'owner' }, 'Owner'))),
       // Client organization assignment
       clientOrgs.length > 0 && h('div', { className: 'form-group' },
         h('label', { className: 'form-label' }, 'Client Organization'),
@@ -653,7 +829,7 @@ export function UsersPage() {
       onClose: function() { setResetTarget(null); setNewPassword(''); },
       footer: h(Fragment, null,
         h('button', { className: 'btn btn-secondary', onClick: function() { setResetTarget(null); setNewPassword(''); } }, 'Cancel'),
-        h('button', { className: 'btn btn-primary', onClick: resetPassword, disabled: resetting || newPassword.length < 8 }, resetting ? 'Resetting...' : 'Reset Password')
+        h('button', { className: 'btn btn-primary', onClick: resetPassword, disabled: resetting || !passwordOk(passwordPolicy, newPassword) }, resetting ? 'Resetting...' : 'Reset Password')
       )
     },
       h('div', { style: { marginBottom: 16 } },
@@ -661,17 +837,43 @@ export function UsersPage() {
         resetTarget.totpEnabled && h('div', { style: { marginTop: 8, padding: 8, background: 'var(--info-soft)', borderRadius: 'var(--radius)', fontSize: 12, color: 'var(--info)' } }, 'This user has 2FA enabled. Password reset will not affect their 2FA setup.')
       ),
       h('div', { className: 'form-group' },
-        h('label', { className: 'form-label' }, 'New Password (min 8 characters)'),
+        h('label', { className: 'form-label' }, 'New Password'),
         h('div', { style: { display: 'flex', gap: 8 } },
           h('input', { className: 'input', type: 'text', value: newPassword, onChange: function(e) { setNewPassword(e.target.value); }, placeholder: 'Enter new password', autoFocus: true, style: { flex: 1, fontFamily: 'var(--font-mono)', fontSize: 13 } }),
           h('button', { type: 'button', className: 'btn btn-secondary btn-sm', onClick: generatePassword, title: 'Generate random password' }, I.refresh(), ' Generate')
-        )
+        ),
+        h(PasswordRules, { policy: passwordPolicy, password: newPassword })
+      ),
+      h('label', { style: { display: 'flex', alignItems: 'center', gap: 8, fontSize: 13 } },
+        h('input', { type: 'checkbox', checked: resetMustChange, onChange: function(e) { setResetMustChange(e.target.checked); } }),
+        'Require them to choose a new password at next sign-in'
       ),
       newPassword && h('div', { style: { marginTop: 8, padding: 8, background: 'var(--bg-tertiary)', borderRadius: 'var(--radius)', fontSize: 12, color: 'var(--text-muted)' } },
         'Make sure to share this password securely with the user.'
       )
     ),
 
+    // Reset link (shown when email isn't configured)
+    offboarding && h(OffboardingWizard, {
+      user: offboarding.user,
+      owned: offboarding.owned,
+      onClose: function() { setOffboarding(null); },
+      onDone: function() { setOffboarding(null); load(); }
+    }),
+
+    resetLink && h(Modal, {
+      title: 'Reset Link',
+      onClose: function() { setResetLink(null); },
+      footer: h('button', { className: 'btn btn-primary', onClick: function() { setResetLink(null); } }, 'Done')
+    },
+      h('p', { style: { fontSize: 13, color: 'var(--text-secondary)', marginBottom: 12 } },
+        'Email isn\'t set up, so no message was sent. Share this link with ', h('strong', null, resetLink.user.name || resetLink.user.email), ' over a channel you trust. It expires ', new Date(resetLink.expiresAt).toLocaleTimeString(), '.'),
+      h('div', { style: { display: 'flex', gap: 8 } },
+        h('input', { className: 'input', readOnly: true, value: resetLink.link, onFocus: function(e) { e.target.select(); }, style: { flex: 1, fontFamily: 'var(--font-mono)', fontSize: 12 } }),
+        h('button', { className: 'btn btn-secondary btn-sm', onClick: function() { navigator.clipboard.writeText(resetLink.link).then(function() { toast('Link copied', 'success'); }); } }, I.copy(), ' Copy')
+      )
+    ),
+
     // Permission editor modal
     // Edit User modal
     editUser && h(Modal, {
@@ -689,18 +891,27 @@ export function UsersPage() {
       ),
       h('div', { className: 'form-group' },
         h('label', { className: 'form-label' }, 'Role'),
-        h('select', { className: 'input', value: editForm.role, onChange: function(e) { setEditForm(function(f) { return Object.assign({}, f, { role: e.target.value }); }); } },
+        h('select', { className: 'input', value: editForm.role, disabled: editUser.role === 'owner' && !isOwner, onChange: function(e) { setEditForm(function(f) { return Object.assign({}, f, { role: e.target.value }); }); } },
           h('option', { value: 'viewer' }, 'Viewer'),
           h('option', { value: 'member' }, 'Member'),
           h('option', { value: 'admin' }, 'Admin'),
-          h('option', { value: 'owner' }, 'Owner')
+          (isOwner || editUser.role === 'owner') && h('option', { value: 'owner' }, 'Owner')
         ),
         h('div', { style: { fontSize: 11, color: 'var(--text-muted)', marginTop: 4 } },
           editForm.role === 'owner' ? 'Full access to everything. Cannot be restricted.' :
           editForm.role === 'admin' ? 'Full access by default. Can be restricted via permissions.' :
           editForm.role === 'member' ? 'Access controlled by permissions. Can view and act on assigned pages.' :
           'Read-only access. Can view but not modify.'
-        )
+        ),
+        editUser.role === 'owner' && !isOwner && h('div', { style: { fontSize: 11, color: 'var(--text-muted)', marginTop: 4 } }, 'Only owners can change an owner\'s role.')
+      ),
+      editForm.role === 'owner' && editUser.role !== 'owner' && h('div', { className: 'form-group', style: { padding: 12, background: 'var(--warning-soft)', borderRadius: 'var(--radius)' } },
+        h('div', { style: { fontSize: 12, marginBottom: 8 } }, 'Owners have full, unrestricted control, including over other owners. Confirm it\'s you to continue.'),
+        h('label', { className: 'form-label' }, 'Your password'),
+        h('input', { className: 'input', type: 'password', autoComplete: 'current-password', value: editForm.password, onChange: function(e) { setEditForm(function(f) { return Object.assign({}, f, { password: e.target.value }); }); } }),
+        app.user && app.user.totpEnabled && h(Fragment, null,
+          h('label', { className: 'form-label', style: { marginTop: 8 } }, 'Your 2FA code'),
+          h('input', { className: 'input', inputMode: 'numeric', autoComplete: 'one-time-code', maxLength: 6, value: editForm.totpCode, onChange: function(e) { setEditForm(function(f) { return Object.assign({}, f, { totpCode: e.target.value }); }); } }))
       ),
       clientOrgs.length > 0 && h('div', { className: 'form-group' },
         h('label', { className: 'form-label' }, 'Client Organization'),
@@ -806,61 +1017,85 @@ export function UsersPage() {
     ),
 
     // Users table
+    h(FilterBar, {
+      filters: filters, placeholder: 'Search name or email...', viewsKey: 'users',
+      fields: [
+        { key: 'role', label: 'All roles', options: ['owner', 'admin', 'member', 'viewer'] },
+        { key: 'status', label: 'Any status', options: [{ value: 'active', label: 'Active' }, { value: 'deactivated', label: 'Deactivated' }] }
+      ]
+    }),
     h('div', { className: 'card' },
       h('div', { className: 'card-body-flush' },
-        users.length === 0 ? h('div', { style: { padding: 24, textAlign: 'center', color: 'var(--text-muted)' } }, 'No users')
-        : h('table', null,
-            h('thead', null, h('tr', null, h('th', null, 'Name'), h('th', null, 'Email'), h('th', null, 'Role'), h('th', null, 'Organization'), h('th', null, 'Status'), h('th', null, 'Access'), h('th', null, '2FA'), h('th', null, 'Created'), h('th', { style: { width: 240 } }, 'Actions'))),
-            h('tbody', null, users.map(function(u) {
-              var isRestricted = u.role === 'member' || u.role === 'viewer';
-              var isDeactivated = u.isActive === false;
-              var isSelf = u.id === ((app || {}).user || {}).id;
-              return h('tr', { key: u.id, style: isDeactivated ? { opacity: 0.6 } : {} },
-                h('td', null, h('strong', null, u.name || '-')),
-                h('td', null, h('span', { style: { fontFamily: 'var(--font-mono)', fontSize: 12 } }, u.email)),
-                h('td', null, h('span', { className: 'badge badge-' + (u.role === 'owner' ? 'warning' : u.role === 'admin' ? 'primary' : 'neutral') }, u.role)),
-                h('td', null, (function() {
-                  var org = u.clientOrgId && clientOrgs.find(function(o) { return o.id === u.clientOrgId; });
-                  return org ? h('span', { className: 'badge badge-info', style: { fontSize: 10 } }, org.name) : h('span', { style: { color: 'var(--text-muted)', fontSize: 11 } }, 'Internal');
-                })()),
-                h('td', null, isDeactivated
-                  ? h('span', { className: 'badge badge-danger', style: { fontSize: 10 } }, 'Deactivated')
-                  : h('span', { className: 'badge badge-success', style: { fontSize: 10 } }, 'Active')
-                ),
-                h('td', null, permBadge(u)),
-                h('td', null, u.totpEnabled ? h('span', { className: 'badge badge-success' }, 'On') : h('span', { className: 'badge badge-neutral' }, 'Off')),
-                h('td', { style: { fontSize: 12, color: 'var(--text-muted)' } }, u.createdAt ? new Date(u.createdAt).toLocaleDateString() : '-'),
-                h('td', null,
-                  h('div', { style: { display: 'flex', gap: 4 } },
-                    h('button', { className: 'btn btn-ghost btn-sm', title: 'Edit User', onClick: function() { openEditUser(u); } }, I.edit()),
-                    h('button', {
-                      className: 'btn btn-ghost btn-sm',
-                      title: isRestricted ? 'Edit Permissions' : 'Permissions (Owner/Admin have full access)',
-                      onClick: function() { openPermissions(u); },
-                      style: !isRestricted ? { opacity: 0.4 } : {}
-                    }, I.shield()),
-                    h('button', { className: 'btn btn-ghost btn-sm', title: 'Reset Password', onClick: function() { setResetTarget(u); setNewPassword(''); } }, I.lock()),
-                    // Impersonate (owner-only, not self)
-                    !isSelf && app.user && app.user.role === 'owner' && !isDeactivated && h('button', {
-                      className: 'btn btn-ghost btn-sm',
-                      title: 'View as ' + (u.name || u.email),
-                      onClick: function() { if (app.startImpersonation) app.startImpersonation(u.id); },
-                      style: { color: 'var(--primary)' }
-                    }, I.agents()),
-                    // Deactivate / Reactivate
-                    !isSelf && h('button', {
-                      className: 'btn btn-ghost btn-sm',
-                      title: isDeactivated ? 'Reactivate User' : 'Deactivate User',
-                      onClick: function() { toggleActive(u); },
-                      style: { color: isDeactivated ? 'var(--success, #15803d)' : 'var(--warning, #991b1b)' }
-                    }, isDeactivated ? I.check() : I.pause()),
-                    // Delete (owner only)
-                    !isSelf && h('button', { className: 'btn btn-ghost btn-sm', title: 'Delete User Permanently', onClick: function() { startDelete(u); }, style: { color: 'var(--danger)' } }, I.trash())
-                  )
-                )
-              );
-            }))
-          )
+        users.length === 0 ? h('div', { style: { padding: 24, textAlign: 'center', color: 'var(--text-muted)' } }, filters.active ? 'No users match these filters' : 'No users')
+        : h(Table, {
+            prefsKey: 'users',
+            sort: sort.sort, onSort: sort.toggle, rows: users,
+            rowStyle: function(u) { return u.isActive === false ? { opacity: 0.6 } : null; },
+            columns: [
+              { key: 'name', label: 'Name', sortable: true, required: true, render: function(u) { return h('strong', null, u.name || '-'); } },
+              { key: 'email', label: 'Email', sortable: true, render: function(u) { return h('span', { style: { fontFamily: 'var(--font-mono)', fontSize: 12 } }, u.email); } },
+              { key: 'role', label: 'Role', sortable: true, render: function(u) { return h('span', { className: 'badge badge-' + (u.role === 'owner' ? 'warning' : u.role === 'admin' ? 'primary' : 'neutral') }, u.role); } },
+              { key: 'organization', label: 'Organization', render: function(u) {
+                var org = u.clientOrgId && clientOrgs.find(function(o) { return o.id === u.clientOrgId; });
+                return org ? h('span', { className: 'badge badge-info', style: { fontSize: 10 } }, org.name) : h('span', { style: { color: 'var(--text-muted)', fontSize: 11 } }, 'Internal');
+              } },
+              { key: 'isActive', label: 'Status', sortable: true, render: function(u) {
+                if (u.isActive === false) return h('span', { className: 'badge badge-danger', style: { fontSize: 10 } }, 'Deactivated');
+                return h('span', { style: { display: 'inline-flex', gap: 4, flexWrap: 'wrap' } },
+                  h('span', { className: 'badge badge-success', style: { fontSize: 10 } }, 'Active'),
+                  u.mustResetPassword && h('span', { className: 'badge badge-warning', style: { fontSize: 10 }, title: 'Must choose a new password at next sign-in' }, 'Password change'));
+              } },
+              { key: 'access', label: 'Access', render: permBadge },
+              { key: 'totpEnabled', label: '2FA', sortable: true, render: function(u) { return u.totpEnabled ? h('span', { className: 'badge badge-success' }, 'On') : h('span', { className: 'badge badge-neutral' }, 'Off'); } },
+              { key: 'createdAt', label: 'Created', sortable: true, defaultDir: 'desc', style: { fontSize: 12, color: 'var(--text-muted)' }, render: function(u) { return u.createdAt ? new Date(u.createdAt).toLocaleDateString() : '-'; } },
+              { key: 'lastLoginAt', label: 'Last Login', sortable: true, defaultDir: 'desc', style: { fontSize: 12, color: 'var(--text-muted)' }, render: function(u) { return u.lastLoginAt ? new Date(u.lastLoginAt).toLocaleString() : 'Never'; } },
+              { key: 'lastLoginIp', label: 'Last IP', sortable: true, render: function(u) { return u.lastLoginIp ? h('span', { style: { fontFamily: 'var(--font-mono)', fontSize: 12 } }, u.lastLoginIp) : h('span', { style: { color: 'var(--text-muted)' } }, '-'); } },
+              { key: 'lastDevice', label: 'Device', sortable: true, style: { fontSize: 12 }, render: function(u) { return u.lastDevice || h('span', { style: { color: 'var(--text-muted)' } }, '-'); } },
+              { key: 'activeSessions', label: 'Sessions', sortable: true, defaultDir: 'desc', align: 'center', render: function(u) {
+                return u.activeSessions
+                  ? h('span', { className: 'badge badge-info', title: u.activeSessions + ' signed-in session' + (u.activeSessions === 1 ? '' : 's') + ' that haven\'t logged out or expired' }, u.activeSessions)
+                  : h('span', { style: { color: 'var(--text-muted)' } }, '0');
+              } },
+              { key: 'actions', label: 'Actions', width: 300, render: function(u) {
+                var isRestricted = u.role === 'member' || u.role === 'viewer';
+                var isDeactivated = u.isActive === false;
+                var isSelf = u.id === ((app || {}).user || {}).id;
+                return h('div', { style: { display: 'flex', gap: 4 } },
+                  h('button', { className: 'btn btn-ghost btn-sm', title: 'Edit User', onClick: function() { openEditUser(u); } }, I.edit()),
+                  h('button', {
+                    className: 'btn btn-ghost btn-sm',
+                    title: isRestricted ? 'Edit Permissions' : 'Permissions (Owner/Admin have full access)',
+                    onClick: function() { openPermissions(u); },
+                    style: !isRestricted ? { opacity: 0.4 } : {}
+                  }, I.shield()),
+                  h('button', { className: 'btn btn-ghost btn-sm', title: 'Reset Password', onClick: function() { setResetTarget(u); setNewPassword(''); setResetMustChange(true); } }, I.lock()),
+                  !isDeactivated && !u.ssoProvider && h('button', {
+                    className: 'btn btn-ghost btn-sm',
+                    title: u.mustResetPassword ? 'Cancel Required Password Change' : 'Require Password Change',
+                    onClick: function() { toggleMustChange(u); },
+                    style: u.mustResetPassword ? { color: 'var(--warning, #991b1b)' } : {}
+                  }, I.key()),
+                  !isDeactivated && h('button', { className: 'btn btn-ghost btn-sm', title: 'Send Reset Link', onClick: function() { sendResetLink(u); } }, I.messages()),
+                  // Impersonate (owner-only, not self)
+                  !isSelf && app.user && app.user.role === 'owner' && !isDeactivated && h('button', {
+                    className: 'btn btn-ghost btn-sm',
+                    title: 'View as ' + (u.name || u.email),
+                    onClick: function() { if (app.startImpersonation) app.startImpersonation(u.id); },
+                    style: { color: 'var(--primary)' }
+                  }, I.agents()),
+                  // Deactivate / Reactivate
+                  !isSelf && h('button', {
+                    className: 'btn btn-ghost btn-sm',
+                    title: isDeactivated ? 'Reactivate User' : 'Deactivate User',
+                    onClick: function() { toggleActive(u); },
+                    style: { color: isDeactivated ? 'var(--success, #15803d)' : 'var(--warning, #991b1b)' }
+                  }, isDeactivated ? I.check() : I.pause()),
+                  // Delete (owner only)
+                  !isSelf && h('button', { className: 'btn btn-ghost btn-sm', title: 'Delete User Permanently', onClick: function() { startDelete(u); }, style: { color: 'var(--danger)' } }, I.trash())
+                );
+              } }
+            ]
+          })
       )
     )
   );
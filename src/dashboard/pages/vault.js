@@ -4,6 +4,8 @@ import { I } from '../components/icons.js';
 import { Modal } from '../components/modal.js';
 import { HelpButton } from '../components/help-button.js';
 import { KnowledgeLink } from '../components/knowledge-link.js';
+import { Table, useSort } from '../components/table.js';
+import { FilterBar, useFilters } from '../components/filter-bar.js';
 
 var PAGE_SIZE = 25;
 
@@ -160,6 +162,8 @@ function SearchBar(props) {
 export function VaultPage() {
   var app = useApp();
   var toast = app.toast;
+  var canManage = app.can('vault.manage');
+  var canReveal = app.can('vault.reveal');
   var orgCtx = useOrgContext();
   var effectiveOrgId = orgCtx.selectedOrgId || getOrgId();
   var _tab = useState('secrets');
@@ -170,10 +174,6 @@ export function VaultPage() {
   var secrets = _secrets[0]; var setSecrets = _secrets[1];
   var _loading = useState(true);
   var loading = _loading[0]; var setLoading = _loading[1];
-  var _secretFilter = useState('');
-  var secretFilter = _secretFilter[0]; var setSecretFilter = _secretFilter[1];
-  var _secretSearch = useState('');
-  var secretSearch = _secretSearch[0]; var setSecretSearch = _secretSearch[1];
 
   // Add modal
   var _showAdd = useState(false);
@@ -215,14 +215,17 @@ export function VaultPage() {
   var _status = useState(null);
   var status = _status[0]; var setStatus = _status[1];
 
+  var sort = useSort('name', 'asc');
+  var filters = useFilters({ q: '', category: '' });
+
   // ── Load functions ──
   var loadSecrets = useCallback(function() {
     setLoading(true);
-    engineCall('/vault/secrets?orgId=' + effectiveOrgId)
+    engineCall('/vault/secrets?orgId=' + effectiveOrgId + '&' + sort.query + (filters.query ? '&' + filters.query : ''))
       .then(function(d) { setSecrets(d.secrets || d.entries || []); })
       .catch(function(e) { toast(e.message || 'Failed to load secrets', 'error'); })
       .finally(function() { setLoading(false); });
-  }, [toast]);
+  }, [toast, sort.query, filters.query]);
 
   var loadAudit = useCallback(function() {
     setAuditLoading(true);
@@ -338,35 +341,22 @@ export function VaultPage() {
     navigator.clipboard.writeText(viewValue).then(function() { toast('Copied to clipboard', 'success'); });
   };
 
-  // ── Filter secrets (client-side since list is small) ──
+  // Search and category are applied server-side
   var filtered = secrets;
-  if (secretFilter) filtered = filtered.filter(function(s) { return s.category === secretFilter; });
-  if (secretSearch) {
-    var q = secretSearch.toLowerCase();
-    filtered = filtered.filter(function(s) {
-      return (s.name || '').toLowerCase().includes(q) || (s.category || '').toLowerCase().includes(q) || (s.createdBy || '').toLowerCase().includes(q);
-    });
-  }
 
   // ═══ Secrets Tab ═══
   var renderSecrets = function() {
     return h(Fragment, null,
       // Toolbar
       h('div', { style: { display: 'flex', justifyContent: 'space-between', alignItems: 'center', marginBottom: 16, flexWrap: 'wrap', gap: 8 } },
-        h('div', { style: { display: 'flex', gap: 8, alignItems: 'center', flexWrap: 'wrap' } },
-          h(SearchBar, { placeholder: 'Search secrets...', value: secretSearch, onSearch: setSecretSearch }),
-          h('select', {
-            className: 'input', style: { width: 180 }, value: secretFilter,
-            onChange: function(e) { setSecretFilter(e.target.value); }
-          },
-            h('option', { value: '' }, 'All Categories'),
-            CATEGORIES.map(function(c) { return h('option', { key: c.value, value: c.value }, c.label); })
-          ),
-          h('span', { style: { fontSize: 13, color: 'var(--text-muted)' } }, filtered.length + ' secret' + (filtered.length !== 1 ? 's' : ''))
-        ),
+        h(FilterBar, {
+          filters: filters, placeholder: 'Search secrets...', viewsKey: 'vault', style: { marginBottom: 0 },
+          fields: [{ key: 'category', label: 'All Categories', width: 180, options: CATEGORIES }],
+          summary: filtered.length + ' secret' + (filtered.length !== 1 ? 's' : '')
+        }),
         h('div', { style: { display: 'flex', gap: 8 } },
-          secrets.length > 0 && h('button', { className: 'btn btn-secondary', onClick: rotateAll }, I.refresh(), ' Rotate All'),
-          h('button', { className: 'btn btn-primary', onClick: function() { setShowAdd(true); } }, I.plus(), ' Add Secret')
+          canManage && secrets.length > 0 && h('button', { className: 'btn btn-secondary', onClick: rotateAll }, I.refresh(), ' Rotate All'),
+          canManage && h('button', { className: 'btn btn-primary', onClick: function() { setShowAdd(true); } }, I.plus(), ' Add Secret')
         )
       ),
 
@@ -374,49 +364,34 @@ export function VaultPage() {
 
       !loading && filtered.length === 0 && h('div', { style: { textAlign: 'center', padding: 60, color: 'var(--text-muted)' } },
         h('div', { style: { marginBottom: 12 } }, I.lock()),
-        h('p', { style: { fontSize: 15, fontWeight: 500, marginBottom: 8 } }, secretSearch || secretFilter ? 'No matching secrets' : 'No secrets stored yet'),
+        h('p', { style: { fontSize: 15, fontWeight: 500, marginBottom: 8 } }, filters.active ? 'No matching secrets' : 'No secrets stored yet'),
         h('p', { style: { fontSize: 13 } }, 'Secrets are encrypted at rest with AES-256-GCM.')
       ),
 
       !loading && filtered.length > 0 && h('div', { className: 'card' },
-        h('table', { className: 'data-table' },
-          h('thead', null,
-            h('tr', null,
-              h('th', null, 'Name'),
-              h('th', null, 'Category'),
-              h('th', null, 'Created By'),
-              h('th', null, 'Created'),
-              h('th', null, 'Last Rotated'),
-              h('th', { style: { textAlign: 'right' } }, 'Actions')
-            )
-          ),
-          h('tbody', null,
-            filtered.map(function(s) {
-              return h('tr', {
-                key: s.id,
-                style: { cursor: 'pointer' },
-                onClick: function() { openViewSecret(s); }
-              },
-                h('td', null, h('span', { style: { color: 'var(--text-primary)', fontWeight: 500 } }, s.name)),
-                h('td', null,
-                  h('span', {
-                    style: { display: 'inline-block', padding: '2px 8px', borderRadius: 12, fontSize: 11, fontWeight: 600, color: '#fff', background: catColor(s.category) }
-                  }, (s.category || 'custom').replace(/_/g, ' '))
-                ),
-                h('td', { style: { color: 'var(--text-muted)', fontSize: 13 } }, s.createdBy || '-'),
-                h('td', { style: { color: 'var(--text-muted)', fontSize: 13 } }, s.createdAt ? new Date(s.createdAt).toLocaleDateString() : '-'),
-                h('td', { style: { color: 'var(--text-muted)', fontSize: 13 } }, s.rotatedAt ? new Date(s.rotatedAt).toLocaleDateString() : 'Never'),
-                h('td', { style: { textAlign: 'right' } },
-                  h('div', { style: { display: 'flex', gap: 4, justifyContent: 'flex-end' } },
-                    h('button', { className: 'btn btn-ghost btn-sm', onClick: function(e) { e.stopPropagation(); openViewSecret(s); }, title: 'View' }, I.eye()),
-                    h('button', { className: 'btn btn-ghost btn-sm', onClick: function(e) { e.stopPropagation(); rotateSecret(s); }, title: 'Rotate' }, I.refresh()),
-                    h('button', { className: 'btn btn-ghost btn-sm', style: { color: 'var(--danger)' }, onClick: function(e) { e.stopPropagation(); deleteSecret(s); }, title: 'Delete' }, I.trash())
-                  )
-                )
+        h(Table, {
+          prefsKey: 'vault',
+          className: 'data-table', sort: sort.sort, onSort: sort.toggle, rows: filtered,
+          onRowClick: canReveal ? openViewSecret : undefined,
+          columns: [
+            { key: 'name', label: 'Name', sortable: true, render: function(s) { return h('span', { style: { color: 'var(--text-primary)', fontWeight: 500 } }, s.name); } },
+            { key: 'category', label: 'Category', sortable: true, render: function(s) {
+              return h('span', {
+                style: { display: 'inline-block', padding: '2px 8px', borderRadius: 12, fontSize: 11, fontWeight: 600, color: '#fff', background: catColor(s.category) }
+              }, (s.category || 'custom').replace(/_/g, ' '));
+            } },
+            { key: 'createdBy', label: 'Created By', sortable: true, style: { color: 'var(--text-muted)', fontSize: 13 }, render: function(s) { return s.createdBy || '-'; } },
+            { key: 'createdAt', label: 'Created', sortable: true, defaultDir: 'desc', style: { color: 'var(--text-muted)', fontSize: 13 }, render: function(s) { return s.createdAt ? new Date(s.createdAt).toLocaleDateString() : '-'; } },
+            { key: 'rotatedAt', label: 'Last Rotated', sortable: true, defaultDir: 'desc', style: { color: 'var(--text-muted)', fontSize: 13 }, render: function(s) { return s.rotatedAt ? new Date(s.rotatedAt).toLocaleDateString() : 'Never'; } },
+            { key: 'actions', label: 'Actions', align: 'right', render: function(s) {
+              return h('div', { style: { display: 'flex', gap: 4, justifyContent: 'flex-end' } },
+                canReveal && h('button', { className: 'btn btn-ghost btn-sm', onClick: function(e) { e.stopPropagation(); openViewSecret(s); }, title: 'View' }, I.eye()),
+                canManage && h('button', { className: 'btn btn-ghost btn-sm', onClick: function(e) { e.stopPropagation(); rotateSecret(s); }, title: 'Rotate' }, I.refresh()),
+                canManage && h('button', { className: 'btn btn-ghost btn-sm', style: { color: 'var(--danger)' }, onClick: function(e) { e.stopPropagation(); deleteSecret(s); }, title: 'Delete' }, I.trash())
               );
-            })
-          )
-        )
+            } }
+          ]
+        })
       )
     );
   };
@@ -733,7 +708,7 @@ export function VaultPage() {
               }, viewRevealed ? viewValue : '\u2022'.repeat(Math.min(viewValue.length || 20, 40))),
               h('div', { style: { marginTop: 8, display: 'flex', gap: 8 } },
                 h('button', { className: 'btn btn-secondary btn-sm', onClick: copyValue }, I.copy(), ' Copy'),
-                h('button', { className: 'btn btn-ghost btn-sm', onClick: function() { rotateSecret(viewSecret); setViewSecret(null); } }, I.refresh(), ' Rotate')
+                canManage && h('button', { className: 'btn btn-ghost btn-sm', onClick: function() { rotateSecret(viewSecret); setViewSecret(null); } }, I.refresh(), ' Rotate')
               ),
               h('div', {
                 style: { marginTop: 12, padding: 10, background: 'rgba(245, 158, 11, 0.1)', borderRadius: 6, fontSize: 12, color: 'var(--warning)' }
@@ -0,0 +1,194 @@
+import { h, useState, useEffect, useApp, apiCall, engineCall, getOrgId, buildAgentDataMap, renderAgentBadge, showConfirm } from '../components/utils.js';
+import { I } from '../components/icons.js';
+import { HelpButton } from '../components/help-button.js';
+import { useOrgContext } from '../components/org-switcher.js';
+import { Table } from '../components/table.js';
+import { FilterBar, useFilters } from '../components/filter-bar.js';
+
+const KINDS = {
+  approval: { label: 'Approval', badge: 'warning', page: 'approvals' },
+  escalation: { label: 'Escalation', badge: 'danger', page: 'org-chart' },
+  conversation: { label: 'Conversation', badge: 'info', page: 'agents' },
+  quarantine: { label: 'Quarantined', badge: 'danger', page: 'dlp' },
+  qa: { label: 'QA review', badge: 'neutral', page: 'qa' },
+};
+
+/** "due in 25m" / "overdue 3h" against the SLA due time */
+export function slaLabel(dueAt, now) {
+  var ms = new Date(dueAt).getTime() - now;
+  var abs = Math.abs(ms);
+  var txt = abs < 3600000 ? Math.max(1, Math.round(abs / 60000)) + 'm' : abs < 172800000 ? Math.round(abs / 3600000) + 'h' : Math.round(abs / 86400000) + 'd';
+  return ms < 0 ? 'overdue ' + txt : 'due in ' + txt;
+}
+
+function slaColor(dueAt, createdAt, now) {
+  var due = new Date(dueAt).getTime();
+  if (due < now) return 'var(--danger)';
+  var span = due - new Date(createdAt).getTime();
+  // Amber once three quarters of the SLA has been used
+  return span > 0 && (now - new Date(createdAt).getTime()) / span >= 0.75 ? 'var(--warning)' : 'var(--text-muted)';
+}
+
+export function WorkQueuePage() {
+  var orgCtx = useOrgContext();
+  var effectiveOrgId = orgCtx.selectedOrgId || getOrgId();
+  const { toast, user, setPage } = useApp();
+  const filters = useFilters({ q: '', kind: '', assignee: '' });
+  const [items, setItems] = useState([]);
+  const [loading, setLoading] = useState(true);
+  const [agents, setAgents] = useState([]);
+  const [users, setUsers] = useState([]);
+  const [selected, setSelected] = useState({});
+  const [assignTo, setAssignTo] = useState('');
+  const [now, setNow] = useState(Date.now());
+  const [busy, setBusy] = useState(false);
+
+  const agentData = buildAgentDataMap(agents);
+  const userName = (id) => {
+    if (!id) return null;
+    if (user && id === user.id) return 'You';
+    var u = users.find(x => x.id === id);
+    return u ? (u.name || u.email) : id;
+  };
+
+  const load = () => {
+    var qs = 'orgId=' + encodeURIComponent(effectiveOrgId) + (filters.query ? '&' + filters.query : '');
+    return engineCall('/work-queue?' + qs)
+      .then(d => {
+        setItems(d.items || []);
+        // Drop selections that are no longer in the queue
+        setSelected(sel => { var next = {}; (d.items || []).forEach(i => { if (sel[i.id]) next[i.id] = true; }); return next; });
+      })
+      .catch(() => {})
+      .finally(() => setLoading(false));
+  };
+
+  useEffect(() => {
+    apiCall('/agents' + (orgCtx.selectedOrgId ? '?clientOrgId=' + orgCtx.selectedOrgId : '')).then(d => setAgents(d.agents || [])).catch(() => {});
+    apiCall('/users?limit=200').then(d => setUsers(d.users || [])).catch(() => {});
+  }, [effectiveOrgId]);
+  useEffect(() => { load(); }, [effectiveOrgId, filters.query]);
+  // Refresh the queue every 30s; SLA timers tick every 15s
+  useEffect(() => { var t = setInterval(load, 30000); return () => clearInterval(t); }, [effectiveOrgId, filters.query]);
+  useEffect(() => { var t = setInterval(() => setNow(Date.now()), 15000); return () => clearInterval(t); }, []);
+
+  const ids = Object.keys(selected).filter(k => selected[k]);
+  const selectedItems = items.filter(i => selected[i.id]);
+  const allApprovals = selectedItems.length > 0 && selectedItems.every(i => i.kind === 'approval');
+  const anyApprovals = selectedItems.some(i => i.kind === 'approval');
+  const overdue = items.filter(i => new Date(i.dueAt).getTime() < now).length;
+  const mine = user ? items.filter(i => i.assignedTo === user.id).length : 0;
+  const unassigned = items.filter(i => !i.assignedTo).length;
+
+  const toggle = (id) => setSelected(s => Object.assign({}, s, { [id]: !s[id] }));
+  const toggleAll = () => setSelected(ids.length === items.length ? {} : items.reduce((acc, i) => { acc[i.id] = true; return acc; }, {}));
+
+  const run = async (action, targetIds, extra) => {
+    setBusy(true);
+    try {
+      const d = await engineCall('/work-queue/bulk', { method: 'POST', body: JSON.stringify(Object.assign({ orgId: effectiveOrgId, ids: targetIds, action: action }, extra || {})) });
+      if (d.failed > 0) {
+        var first = (d.results || []).find(r => !r.ok);
+        toast(d.succeeded + ' updated, ' + d.failed + ' failed' + (first ? ': ' + first.error : ''), d.succeeded ? 'warning' : 'error');
+      } else {
+        toast(d.succeeded + (d.succeeded === 1 ? ' item' : ' items') + ' updated', 'success');
+      }
+      setSelected({});
+      load();
+    } catch (e) { toast(e.message, 'error'); }
+    setBusy(false);
+  };
+
+  const bulk = async (action) => {
+    if (action === 'resolve' || action === 'deny' || action === 'approve') {
+      var verb = action === 'resolve' ? 'Resolve' : action === 'approve' ? 'Approve' : 'Deny';
+      var ok = await showConfirm({
+        title: verb + ' ' + ids.length + (ids.length === 1 ? ' item' : ' items'),
+        message: action === 'resolve'
+          ? 'Resolving closes each item in its source: escalations are marked resolved, conversations are handed back to the agent, QA reviews are skipped and quarantined content is marked reviewed.'
+          : verb + ' the selected approval requests? Agents waiting on them will continue immediately.',
+        confirmText: verb,
+        danger: action !== 'approve',
+      });
+      if (!ok) return;
+    }
+    run(action, ids, action === 'assign' ? { assignee: assignTo || (user && user.id) } : null);
+  };
+
+  var _h4 = { marginTop: 16, marginBottom: 8, fontSize: 14 };
+  var _ul = { paddingLeft: 20, margin: '4px 0 8px' };
+
+  const columns = [
+    { key: 'select', label: h('input', { type: 'checkbox', 'aria-label': 'Select all', checked: items.length > 0 && ids.length === items.length, onChange: toggleAll }), width: 32,
+      render: (i) => h('input', { type: 'checkbox', 'aria-label': 'Select item', checked: !!selected[i.id], onClick: e => e.stopPropagation(), onChange: () => toggle(i.id) }) },
+    { key: 'kind', label: 'Type', render: (i) => h('span', { className: 'badge badge-' + KINDS[i.kind].badge }, KINDS[i.kind].label) },
+    { key: 'title', label: 'Item', render: (i) => h('div', null,
+      h('div', { style: { fontWeight: 500 } }, i.title),
+      i.detail && h('div', { style: { fontSize: 12, color: 'var(--text-muted)', maxWidth: 420, overflow: 'hidden', textOverflow: 'ellipsis', whiteSpace: 'nowrap' }, title: i.detail }, i.detail)
+    ) },
+    { key: 'agent', label: 'Agent', render: (i) => i.agentId ? renderAgentBadge(i.agentId, agentData) : '-' },
+    { key: 'sla', label: 'SLA', render: (i) => h('span', { style: { color: slaColor(i.dueAt, i.createdAt, now), fontWeight: 600, whiteSpace: 'nowrap' }, title: 'Due ' + new Date(i.dueAt).toLocaleString() }, I.clock(), ' ', slaLabel(i.dueAt, now)) },
+    { key: 'assignee', label: 'Assignee', render: (i) => i.assignedTo
+      ? h('span', null, userName(i.assignedTo))
+      : h('button', { className: 'btn btn-ghost btn-sm', disabled: busy, onClick: e => { e.stopPropagation(); run('assign', [i.id], { assignee: user && user.id }); } }, 'Take it') },
+    { key: 'open', label: '', align: 'right', render: (i) => h('button', { className: 'btn btn-ghost btn-sm', onClick: e => { e.stopPropagation(); setPage(KINDS[i.kind].page); } }, 'Open') },
+  ];
+
+  return h('div', { className: 'page-inner' },
+    h(orgCtx.Switcher),
+    h('div', { className: 'page-header' },
+      h('h1', { style: { display: 'flex', alignItems: 'center' } }, 'Work Queue', h(HelpButton, { label: 'Work Queue' },
+        h('p', null, 'Everything waiting on a person, across the platform: approvals, escalations, taken-over conversations, content blocked by DLP, and QA reviews.'),
+        h('h4', { style: _h4 }, 'How it works'),
+        h('ul', { style: _ul },
+          h('li', null, h('strong', null, 'SLA'), ' — Each item has a due time. Approvals are due when they expire; other items have a fixed target by type. The timer turns amber near the deadline and red once overdue.'),
+          h('li', null, h('strong', null, 'Assignment'), ' — Take an item yourself or assign a selection to a teammate so it has a clear owner.'),
+          h('li', null, h('strong', null, 'Bulk actions'), ' — Select items to assign, approve, deny or resolve them together. Each action is recorded in the audit log.')
+        )
+      )),
+      h('button', { className: 'btn btn-secondary', onClick: load }, I.refresh(), ' Refresh')
+    ),
+
+    h('div', { className: 'stat-grid', style: { marginBottom: 16 } },
+      h('div', { className: 'stat-card' }, h('div', { className: 'stat-value' }, items.length), h('div', { className: 'stat-label' }, 'Open items')),
+      h('div', { className: 'stat-card' }, h('div', { className: 'stat-value', style: { color: overdue ? 'var(--danger)' : undefined } }, overdue), h('div', { className: 'stat-label' }, 'Overdue')),
+      h('div', { className: 'stat-card' }, h('div', { className: 'stat-value' }, unassigned), h('div', { className: 'stat-label' }, 'Unassigned')),
+      h('div', { className: 'stat-card' }, h('div', { className: 'stat-value' }, mine), h('div', { className: 'stat-label' }, 'Assigned to you'))
+    ),
+
+    h(FilterBar, {
+      filters: filters, placeholder: 'Search items...', viewsKey: 'work-queue',
+      fields: [
+        { key: 'kind', label: 'All types', options: Object.keys(KINDS).map(k => ({ value: k, label: KINDS[k].label })) },
+        { key: 'assignee', label: 'Anyone', options: [{ value: 'me', label: 'Assigned to me' }, { value: 'unassigned', label: 'Unassigned' }] },
+      ],
+    }),
+
+    ids.length > 0 && h('div', { className: 'card', style: { display: 'flex', alignItems: 'center', gap: 8, padding: '8px 12px', marginBottom: 12, flexWrap: 'wrap' } },
+      h('strong', null, ids.length + ' selected'),
+      h('select', { className: 'input', style: { width: 200 }, value: assignTo, onChange: e => setAssignTo(e.target.value), 'aria-label': 'Assign to' },
+        h('option', { value: '' }, 'Me'),
+        users.filter(u => !user || u.id !== user.id).map(u => h('option', { key: u.id, value: u.id }, u.name || u.email))
+      ),
+      h('button', { className: 'btn btn-secondary btn-sm', disabled: busy, onClick: () => bulk('assign') }, 'Assign'),
+      h('button', { className: 'btn btn-ghost btn-sm', disabled: busy, onClick: () => bulk('unassign') }, 'Unassign'),
+      h('span', { style: { flex: 1 } }),
+      allApprovals && h('button', { className: 'btn btn-primary btn-sm', disabled: busy, onClick: () => bulk('approve') }, 'Approve'),
+      allApprovals && h('button', { className: 'btn btn-danger btn-sm', disabled: busy, onClick: () => bulk('deny') }, 'Deny'),
+      !anyApprovals && h('button', { className: 'btn btn-primary btn-sm', disabled: busy, onClick: () => bulk('resolve') }, I.check(), ' Resolve'),
+      h('button', { className: 'btn btn-ghost btn-sm', onClick: () => setSelected({}) }, 'Clear selection')
+    ),
+
+    h('div', { className: 'card' },
+      h(Table, {
+        prefsKey: 'work-queue',
+        className: 'data-table',
+        columns: columns,
+        rows: items,
+        rowStyle: (i) => selected[i.id] ? { background: 'var(--bg-tertiary)' } : null,
+        onRowClick: (i) => toggle(i.id),
+        empty: loading ? 'Loading...' : filters.active ? 'No items match these filters' : 'Nothing is waiting on a human',
+      })
+    )
+  );
+}
@@ -1,4 +1,6 @@
+import { h, useState, useEffect, Fragment, useApp, engineCall, getOrgId, showConfirm, buildAgentEmailMap, buildAgentDataMap, renderAgentBadge } from '../components/utils.js';
 import { I } from '../components/icons.js';
+import { MonthCalendar, calendarRange, startOfMonth, toLocalInput } from '../components/month-calendar.js';
 import { TimezoneSelect } from '../components/timezones.js';
 import { DetailModal } from '../components/modal.js';
 import { HelpButton } from '../components/help-button.js';
@@ -60,6 +62,11 @@ export function WorkforcePage() {
   const [budgetStatusFilter, setBudgetStatusFilter] = useState('');
   const [selectedRecord, setSelectedRecord] = useState(null);
 
+  // Maintenance tab
+  const [maintWindows, setMaintWindows] = useState([]);
+  const [calMonth, setCalMonth] = useState(() => startOfMonth(new Date()));
+  const [maintForm, setMaintForm] = useState(null); // { id?, agentId, title, startsAt, endsAt, notice }
+
   const formatTime = (iso) => iso ? new Date(iso).toLocaleString() : '-';
   const dayNames = ['Sun', 'Mon', 'Tue', 'Wed', 'Thu', 'Fri', 'Sat'];
   const formatDays = (days) => days?.map(d => dayNames[d]).join(', ') || '-';
@@ -193,6 +200,68 @@ export function WorkforcePage() {
     if (tab === 'tasks') loadTasks();
   }, [tab]);
 
+  // --- Maintenance windows ---
+  const loadMaintenance = async () => {
+    var range = calendarRange(calMonth);
+    var from = new Date(Math.min(range.start.getTime(), Date.now()));
+    try {
+      const res = await engineCall('/workforce/maintenance?orgId=' + effectiveOrgId + '&from=' + from.toISOString() + '&to=' + range.end.toISOString());
+      setMaintWindows(res.windows || []);
+    } catch (err) { toast('Failed to load maintenance windows', 'error'); }
+  };
+
+  useEffect(() => {
+    if (tab === 'maintenance') loadMaintenance();
+  }, [tab, calMonth]);
+
+  const openNewMaintenance = () => {
+    var start = new Date(Date.now() + 3600000); start.setMinutes(0, 0, 0);
+    setMaintForm({ agentId: '', title: 'Scheduled maintenance', startsAt: toLocalInput(start), endsAt: toLocalInput(new Date(start.getTime() + 2 * 3600000)), notice: '' });
+  };
+
+  const openEditMaintenance = (w) => {
+    if (w.status !== 'scheduled' && w.status !== 'active') return;
+    setMaintForm({ id: w.id, status: w.status, agentId: w.agentId || '', title: w.title, startsAt: toLocalInput(new Date(w.startsAt)), endsAt: toLocalInput(new Date(w.endsAt)), notice: w.notice || '' });
+  };
+
+  const saveMaintenance = async () => {
+    var body = {
+      orgId: effectiveOrgId, agentId: maintForm.agentId || undefined, title: maintForm.title, notice: maintForm.notice || undefined,
+      startsAt: new Date(maintForm.startsAt).toISOString(), endsAt: new Date(maintForm.endsAt).toISOString(),
+    };
+    try {
+      if (maintForm.id) {
+        await engineCall('/workforce/maintenance/' + maintForm.id, { method: 'PUT', body: JSON.stringify(body) });
+        toast('Maintenance window updated', 'success');
+      } else {
+        await engineCall('/workforce/maintenance', { method: 'POST', body: JSON.stringify(body) });
+        toast('Maintenance window scheduled', 'success');
+      }
+      setMaintForm(null);
+      loadMaintenance();
+      loadData();
+    } catch (err) { toast(err.message, 'error'); }
+  };
+
+  const cancelMaintenance = async (w) => {
+    var ok = await showConfirm({
+      title: 'Cancel maintenance window',
+      message: w.status === 'active'
+        ? 'This window is in progress. Cancelling ends it now and hands the affected agents back to their schedules.'
+        : 'Cancel "' + w.title + '"? Agents will not be paused for it.',
+      confirmText: 'Cancel Window',
+      danger: true,
+    });
+    if (!ok) return;
+    try {
+      await engineCall('/workforce/maintenance/' + w.id, { method: 'DELETE' });
+      toast('Maintenance window cancelled', 'success');
+      setMaintForm(null);
+      loadMaintenance();
+      loadData();
+    } catch (err) { toast(err.message, 'error'); }
+  };
+
   const toggleDay = (day) => {
     const days = schedForm.config?.standardHours?.daysOfWeek || [];
     const next = days.includes(day) ? days.filter(d => d !== day) : [...days, day].sort();
@@ -277,6 +346,7 @@ export function WorkforcePage() {
     { key: 'tasks', label: 'Task Queue', icon: I.workflow },
     { key: 'budgets', label: 'Budgets', icon: I.chart },
     { key: 'history', label: 'Clock History', icon: I.clock },
+    { key: 'maintenance', label: 'Maintenance', icon: I.calendar },
   ];
 
   var _h4 = { marginTop: 16, marginBottom: 8, fontSize: 14 };
@@ -294,7 +364,8 @@ export function WorkforcePage() {
           h('li', null, h('strong', null, 'Schedules'), ' — Define working hours, shifts, and auto-wake rules.'),
           h('li', null, h('strong', null, 'Task Queue'), ' — Assign and track tasks for specific agents.'),
           h('li', null, h('strong', null, 'Budgets'), ' — Set token caps to control agent spending.'),
-          h('li', null, h('strong', null, 'Clock History'), ' — Audit trail of all clock-in/out events.')
+          h('li', null, h('strong', null, 'Clock History'), ' — Audit trail of all clock-in/out events.'),
+          h('li', null, h('strong', null, 'Maintenance'), ' — Schedule downtime for one agent or the whole org. Agents pause automatically and reply with your notice until the window ends.')
         ),
         h('div', { style: _tip }, h('strong', null, 'Tip: '), 'Use schedules with auto-wake to have agents automatically start working at their scheduled time each day.')
       )),
@@ -326,7 +397,9 @@ export function WorkforcePage() {
               ? h('tr', { key: '_empty' }, h('td', { colSpan: 5, style: { textAlign: 'center', color: 'var(--text-muted)', padding: 40 } }, 'No agents found'))
               : status.agents.map(a => h('tr', { key: a.agentId },
                 h('td', null, renderAgentBadge(a.agentId || a.id, agentData)),
-                h('td', null, statusBadge(a.clockStatus || a.status)),
+                h('td', null, statusBadge(a.clockStatus || a.status),
+                  a.maintenance && h('span', { className: 'badge', title: 'Until ' + formatTime(a.maintenance.endsAt), style: { background: 'var(--danger)', color: '#fff', marginLeft: 4 } }, 'Maintenance')
+                ),
                 h('td', null, a.schedule
                   ? h(Fragment, null,
                       schedTypeBadge(a.schedule.scheduleType || a.schedule.type || 'standard'),
@@ -713,6 +786,83 @@ export function WorkforcePage() {
       );
     })(),
 
+    // ===== MAINTENANCE TAB =====
+    tab === 'maintenance' && h(Fragment, null,
+      h('div', { style: { display: 'flex', justifyContent: 'space-between', alignItems: 'center', marginBottom: 12 } },
+        h('span', { style: { fontSize: 13, color: 'var(--text-muted)' } }, 'Agents pause for the whole window and auto-reply with its notice on email, WhatsApp and Telegram.'),
+        h('button', { className: 'btn btn-primary btn-sm', onClick: openNewMaintenance }, I.plus(), ' Schedule Maintenance')
+      ),
+      h(MonthCalendar, {
+        events: maintWindows, month: calMonth, onMonthChange: setCalMonth, onSelect: openEditMaintenance,
+        colorFor: (w) => w.status === 'active' ? 'var(--danger)' : w.agentId ? 'var(--info)' : 'var(--warning)',
+        legend: [{ label: 'Org-wide', color: 'var(--warning)' }, { label: 'Single agent', color: 'var(--info)' }, { label: 'In progress', color: 'var(--danger)' }],
+      }),
+      h('div', { className: 'card', style: { marginTop: 16 } },
+        h('div', { className: 'card-header' }, h('h3', null, 'Upcoming')),
+        h('table', { className: 'data-table' },
+          h('thead', null, h('tr', null, h('th', null, 'Window'), h('th', null, 'Scope'), h('th', null, 'Starts'), h('th', null, 'Ends'), h('th', null, 'Status'), h('th', null, ''))),
+          h('tbody', null, (function() {
+            var upcoming = maintWindows.filter(w => (w.status === 'scheduled' || w.status === 'active') && new Date(w.endsAt).getTime() > Date.now());
+            if (upcoming.length === 0) return h('tr', null, h('td', { colSpan: 6, style: { textAlign: 'center', color: 'var(--text-muted)', padding: 40 } }, 'No maintenance scheduled'));
+            return upcoming.map(w => h('tr', { key: w.id },
+              h('td', null, h('strong', null, w.title)),
+              h('td', null, w.agentId ? renderAgentBadge(w.agentId, agentData) : h('span', { className: 'badge badge-warning' }, 'All agents')),
+              h('td', null, formatTime(w.startsAt)),
+              h('td', null, formatTime(w.endsAt)),
+              h('td', null, w.status === 'active' ? h('span', { className: 'badge badge-danger' }, 'In progress') : h('span', { className: 'badge badge-info' }, 'Scheduled')),
+              h('td', { style: { display: 'flex', gap: 4, justifyContent: 'flex-end' } },
+                h('button', { className: 'btn btn-ghost btn-sm', onClick: () => openEditMaintenance(w) }, I.edit(), ' Edit'),
+                h('button', { className: 'btn btn-ghost btn-sm', style: { color: 'var(--danger)' }, onClick: () => cancelMaintenance(w) }, w.status === 'active' ? 'End Now' : 'Cancel')
+              )
+            ));
+          })())
+        )
+      )
+    ),
+
+    // ===== MAINTENANCE MODAL =====
+    maintForm && h('div', { className: 'modal-overlay', onClick: () => setMaintForm(null) },
+      h('div', { className: 'modal', style: { maxWidth: 520 }, onClick: e => e.stopPropagation() },
+        h('div', { className: 'modal-header' },
+          h('h2', null, maintForm.id ? 'Edit Maintenance Window' : 'Schedule Maintenance'),
+          h('button', { className: 'btn btn-ghost btn-icon', onClick: () => setMaintForm(null) }, I.x())
+        ),
+        h('div', { className: 'modal-body' },
+          h('div', { className: 'form-group' },
+            h('label', { className: 'form-label' }, 'Title'),
+            h('input', { className: 'input', value: maintForm.title, maxLength: 200, onChange: e => setMaintForm({ ...maintForm, title: e.target.value }) })
+          ),
+          h('div', { className: 'form-group' },
+            h('label', { className: 'form-label' }, 'Applies to'),
+            h('select', { className: 'input', value: maintForm.agentId, disabled: !!maintForm.id, onChange: e => setMaintForm({ ...maintForm, agentId: e.target.value }) },
+              h('option', { value: '' }, 'All agents (org-wide)'),
+              agents.map(a => h('option', { key: a.id, value: a.id }, a.config?.displayName || a.config?.name || a.name || a.id))
+            )
+          ),
+          h('div', { style: { display: 'flex', gap: 12 } },
+            h('div', { className: 'form-group', style: { flex: 1 } },
+              h('label', { className: 'form-label' }, 'Starts'),
+              h('input', { className: 'input', type: 'datetime-local', value: maintForm.startsAt, disabled: maintForm.status === 'active', onChange: e => setMaintForm({ ...maintForm, startsAt: e.target.value }) })
+            ),
+            h('div', { className: 'form-group', style: { flex: 1 } },
+              h('label', { className: 'form-label' }, 'Ends'),
+              h('input', { className: 'input', type: 'datetime-local', value: maintForm.endsAt, onChange: e => setMaintForm({ ...maintForm, endsAt: e.target.value }) })
+            )
+          ),
+          h('div', { className: 'form-group' },
+            h('label', { className: 'form-label' }, 'Auto-reply notice'),
+            h('textarea', { className: 'input', rows: 4, maxLength: 2000, placeholder: "I'm currently unavailable due to scheduled maintenance. I'll pick up your message as soon as I'm back online.", value: maintForm.notice, onChange: e => setMaintForm({ ...maintForm, notice: e.target.value }) }),
+            h('p', { className: 'form-help' }, 'Sent once per contact while the window is open. Leave empty for the default notice.')
+          )
+        ),
+        h('div', { className: 'modal-footer' },
+          maintForm.id && h('button', { className: 'btn btn-danger', style: { marginRight: 'auto' }, onClick: () => cancelMaintenance(maintWindows.find(w => w.id === maintForm.id) || maintForm) }, maintForm.status === 'active' ? 'End Now' : 'Cancel Window'),
+          h('button', { className: 'btn btn-ghost', onClick: () => setMaintForm(null) }, 'Close'),
+          h('button', { className: 'btn btn-primary', disabled: !maintForm.startsAt || !maintForm.endsAt || new Date(maintForm.endsAt) <= new Date(maintForm.startsAt), onClick: saveMaintenance }, maintForm.id ? 'Save Changes' : 'Schedule')
+        )
+      )
+    ),
+
     // ===== SCHEDULE EDITOR MODAL =====
     // ===== CLOCK RECORD DETAIL MODAL =====
     selectedRecord && h(DetailModal, {
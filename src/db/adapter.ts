@@ -39,7 +39,7 @@ export interface Agent {
   name: string;
   email: string;
   role: string;
-  status: 'active' | 'archived' | 'suspended';
+  status: 'draft' | 'pending-approval' | 'active' | 'suspended' | 'offboarding' | 'retired' | 'archived';
   metadata: Record<string, unknown>;
   securityOverrides?: Partial<SecurityConfig>;
   client_org_id?: string;
@@ -70,6 +70,7 @@ export interface User {
   totpBackupCodes?: string;  // JSON array of hashed backup codes
   permissions?: any;         // '*' or { pageId: true | string[] }
   mustResetPassword?: boolean;
+  passwordChangedAt?: Date;
   isActive?: boolean;
   clientOrgId?: string | null;
   createdAt: Date;
@@ -107,8 +108,13 @@ export interface AuditFilters {
   to?: Date;
   limit?: number;
   offset?: number;
+  /** Defaults to newest first */
+  sort?: { field: AuditSortField; dir: 'asc' | 'desc' };
 }
 
+export const AUDIT_SORT_FIELDS = ['timestamp', 'actor', 'action', 'resource', 'ip'] as const;
+export type AuditSortField = typeof AUDIT_SORT_FIELDS[number];
+
 export interface ApiKey {
   id: string;
   name: string;
@@ -320,6 +326,17 @@ export interface SecurityConfig {
     logApiAccess: boolean;
     retentionDays: number; // how long to keep security logs (default 90)
   };
+  passwordPolicy?: {
+    minLength: number;
+    requireUppercase: boolean;
+    requireLowercase: boolean;
+    requireNumber: boolean;
+    requireSymbol: boolean;
+    maxAgeDays: number; // 0 = passwords never expire
+  };
+  encryptionPolicy?: {
+    requiredClasses: Array<'messages' | 'persona' | 'memory' | 'secrets' | 'credentials'>; // must be encrypted at rest or the posture fails
+  };
 }
 
 export interface FirewallConfig {
@@ -337,6 +354,9 @@ export interface FirewallConfig {
     blockedHosts?: string[];
     allowedPorts?: number[];
     blockedPorts?: number[];
+    /** Agents handling data at or above this classification may only reach classifiedAllowedHosts */
+    classifiedMinLevel?: 'public' | 'internal' | 'confidential' | 'restricted';
+    classifiedAllowedHosts?: string[];
   };
   proxy?: {
     httpProxy?: string;
@@ -365,11 +385,27 @@ export interface FirewallConfig {
       xContentTypeOptions?: boolean;
       referrerPolicy?: string;
       permissionsPolicy?: string;
+      /** Nonce-based Content-Security-Policy on HTML pages (default: enabled) */
+      contentSecurityPolicy?: boolean;
+      /** Selective iframe embedding of read-only dashboard pages */
+      embedding?: {
+        enabled?: boolean;
+        /** Origins allowed to frame embeddable pages (CSP frame-ancestors) */
+        frameAncestors?: string[];
+        /** Dashboard page IDs that may be framed (e.g. "activity", "journal") */
+        routes?: string[];
+      };
     };
     /** Maximum request body size in KB (default: 10240 = 10MB) */
     maxBodySizeKb?: number;
     /** Request timeout in seconds for API endpoints (default: 30) */
     requestTimeoutSec?: number;
+    /** Dashboard API failover — primary first, then replicas sharing the same DB and JWT secret */
+    failover?: {
+      enabled?: boolean;
+      backends?: string[];
+      healthCheckIntervalSec?: number;
+    };
   };
   /** DNS rebinding protection */
   dnsRebinding?: {
@@ -426,6 +462,14 @@ export interface ModelPricingConfig {
 export abstract class DatabaseAdapter {
   abstract readonly type: DatabaseType;
 
+  /** ORDER BY clause for queryAudit — the field is whitelisted, so it's safe to interpolate. */
+  protected auditOrderBy(filters: AuditFilters): string {
+    const s = filters.sort;
+    if (!s || !AUDIT_SORT_FIELDS.includes(s.field)) return 'timestamp DESC';
+    const dir = s.dir === 'asc' ? 'ASC' : 'DESC';
+    return s.field === 'timestamp' ? `timestamp ${dir}` : `${s.field} ${dir}, timestamp DESC`;
+  }
+
   // Connection lifecycle
   abstract connect(config: DatabaseConfig): Promise<void>;
   abstract disconnect(): Promise<void>;
@@ -454,10 +498,15 @@ export abstract class DatabaseAdapter {
   abstract listUsers(options?: { limit?: number; offset?: number }): Promise<User[]>;
   abstract updateUser(id: string, updates: Partial<User>): Promise<User>;
   abstract deleteUser(id: string): Promise<void>;
+  /** Store a new password hash and record when it changed */
+  abstract updatePassword(id: string, passwordHash: string, mustReset: boolean): Promise<void>;
+  abstract setMustResetPassword(id: string, required: boolean): Promise<void>;
 
   // Audit
   abstract logEvent(event: Omit<AuditEvent, 'id' | 'timestamp'>): Promise<void>;
   abstract queryAudit(filters: AuditFilters): Promise<{ events: AuditEvent[]; total: number }>;
+  /** Delete events logged before `cutoff`; returns how many were removed */
+  abstract purgeAuditBefore(cutoff: Date): Promise<number>;
 
   // API Keys
   abstract createApiKey(input: ApiKeyInput): Promise<{ key: ApiKey; plaintext: string }>;
@@ -465,6 +514,11 @@ export abstract class DatabaseAdapter {
   abstract validateApiKey(plaintext: string): Promise<ApiKey | null>;
   abstract listApiKeys(options?: { createdBy?: string }): Promise<ApiKey[]>;
   abstract revokeApiKey(id: string): Promise<void>;
+  /** Move a key to another user; requests made with it then act as that user */
+  abstract transferApiKey(id: string, createdBy: string): Promise<void>;
+  /** Set when a key stops working, e.g. the grace period after rotation */
+  abstract expireApiKey(id: string, expiresAt: Date): Promise<void>;
+  abstract setApiKeyScopes(id: string, scopes: string[]): Promise<void>;
 
   // Email Rules
   abstract createRule(rule: Omit<EmailRule, 'id' | 'createdAt' | 'updatedAt'>): Promise<EmailRule>;
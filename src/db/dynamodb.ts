@@ -13,6 +13,7 @@ import {
   AuditEvent, AuditFilters, ApiKey, ApiKeyInput,
   EmailRule, RetentionPolicy, CompanySettings,
 } from './adapter.js';
+import { sortRows } from '../lib/sort.js';
 
 let ddbLib: any;
 let ddbDocLib: any;
@@ -210,7 +211,7 @@ export class DynamoAdapter extends DatabaseAdapter {
     const current = await this.getItem(pk('AGENT'), id);
     if (!current) throw new Error('Agent not found');
     const merged = { ...current, updatedAt: new Date().toISOString() };
-    for (const key of ['name', 'email', 'role', 'status', 'metadata', 'securityOverrides']) {
+    for (const key of ['name', 'email', 'role', 'status', 'metadata', 'securityOverrides', 'createdBy']) {
       if ((updates as any)[key] !== undefined) merged[key] = (updates as any)[key];
     }
     if (updates.name) { merged.GSI1SK = updates.name; }
@@ -294,6 +295,19 @@ export class DynamoAdapter extends DatabaseAdapter {
     await this.deleteItem(pk('USER'), id);
   }
 
+  async updatePassword(id: string, passwordHash: string, mustReset: boolean): Promise<void> {
+    const current = await this.getItem(pk('USER'), id);
+    if (!current) throw new Error('User not found');
+    const now = new Date().toISOString();
+    await this.put({ ...current, passwordHash, mustResetPassword: mustReset, passwordChangedAt: now, updatedAt: now });
+  }
+
+  async setMustResetPassword(id: string, required: boolean): Promise<void> {
+    const current = await this.getItem(pk('USER'), id);
+    if (!current) throw new Error('User not found');
+    await this.put({ ...current, mustResetPassword: required, updatedAt: new Date().toISOString() });
+  }
+
   // ─── Audit ───────────────────────────────────────────────
 
   async logEvent(event: Omit<AuditEvent, 'id' | 'timestamp'>): Promise<void> {
@@ -319,6 +333,11 @@ export class DynamoAdapter extends DatabaseAdapter {
     if (filters.resource) items = items.filter(i => i.resource?.includes(filters.resource));
     if (filters.from) items = items.filter(i => new Date(i.timestamp) >= filters.from!);
     if (filters.to) items = items.filter(i => new Date(i.timestamp) <= filters.to!);
+    if (filters.sort) {
+      const { field, dir } = filters.sort;
+      sortRows(items, { field: 'timestamp', dir: 'desc' });
+      if (field !== 'timestamp' || dir === 'asc') sortRows(items, { field, dir });
+    }
     const total = items.length;
     if (filters.offset) items = items.slice(filters.offset);
     if (filters.limit) items = items.slice(0, filters.limit);
@@ -328,6 +347,14 @@ export class DynamoAdapter extends DatabaseAdapter {
     };
   }
 
+
+  async purgeAuditBefore(cutoff: Date): Promise<number> {
+    // Filtered client-side, like queryAudit
+    const items = (await this.query(pk('AUDIT'))).filter((i: any) => new Date(i.timestamp) < cutoff);
+    for (const item of items) await this.deleteItem(item.PK, item.SK);
+    return items.length;
+  }
+
   // ─── API Keys ────────────────────────────────────────────
 
   async createApiKey(input: ApiKeyInput): Promise<{ key: ApiKey; plaintext: string }> {
@@ -375,6 +402,21 @@ export class DynamoAdapter extends DatabaseAdapter {
     if (current) { current.revoked = true; await this.put(current); }
   }
 
+  async transferApiKey(id: string, createdBy: string): Promise<void> {
+    const current = await this.getItem(pk('APIKEY'), id);
+    if (current) { current.createdBy = createdBy; await this.put(current); }
+  }
+
+  async expireApiKey(id: string, expiresAt: Date): Promise<void> {
+    const current = await this.getItem(pk('APIKEY'), id);
+    if (current) { current.expiresAt = expiresAt.toISOString(); await this.put(current); }
+  }
+
+  async setApiKeyScopes(id: string, scopes: string[]): Promise<void> {
+    const current = await this.getItem(pk('APIKEY'), id);
+    if (current) { current.scopes = scopes; await this.put(current); }
+  }
+
   // ─── Rules ───────────────────────────────────────────────
 
   async createRule(rule: Omit<EmailRule, 'id' | 'createdAt' | 'updatedAt'>): Promise<EmailRule> {
@@ -441,7 +483,7 @@ export class DynamoAdapter extends DatabaseAdapter {
   }
 
   private itemToUser(r: any): User {
-    return { id: r.SK || r.id, email: r.email, name: r.name, role: r.role, passwordHash: r.passwordHash, ssoProvider: r.ssoProvider, ssoSubject: r.ssoSubject, createdAt: new Date(r.createdAt), updatedAt: new Date(r.updatedAt), lastLoginAt: r.lastLoginAt ? new Date(r.lastLoginAt) : undefined };
+    return { id: r.SK || r.id, email: r.email, name: r.name, role: r.role, passwordHash: r.passwordHash, ssoProvider: r.ssoProvider, ssoSubject: r.ssoSubject, mustResetPassword: !!r.mustResetPassword, passwordChangedAt: r.passwordChangedAt ? new Date(r.passwordChangedAt) : undefined, createdAt: new Date(r.createdAt), updatedAt: new Date(r.updatedAt), lastLoginAt: r.lastLoginAt ? new Date(r.lastLoginAt) : undefined };
   }
 
   private itemToApiKey(r: any): ApiKey {
@@ -129,7 +129,7 @@ export class MongoAdapter extends DatabaseAdapter {
 
   async updateAgent(id: string, updates: Partial<Agent>): Promise<Agent> {
     const set: any = { updatedAt: new Date() };
-    for (const key of ['name', 'email', 'role', 'status', 'metadata', 'securityOverrides']) {
+    for (const key of ['name', 'email', 'role', 'status', 'metadata', 'securityOverrides', 'createdBy']) {
       if ((updates as any)[key] !== undefined) set[key] = (updates as any)[key];
     }
     await this.col('agents').updateOne({ _id: id }, { $set: set });
@@ -209,6 +209,15 @@ export class MongoAdapter extends DatabaseAdapter {
     await this.col('users').deleteOne({ _id: id });
   }
 
+  async updatePassword(id: string, passwordHash: string, mustReset: boolean): Promise<void> {
+    const now = new Date();
+    await this.col('users').updateOne({ _id: id }, { $set: { passwordHash, mustResetPassword: mustReset, passwordChangedAt: now, updatedAt: now } });
+  }
+
+  async setMustResetPassword(id: string, required: boolean): Promise<void> {
+    await this.col('users').updateOne({ _id: id }, { $set: { mustResetPassword: required, updatedAt: new Date() } });
+  }
+
   // ─── Audit ───────────────────────────────────────────────
 
   async logEvent(event: Omit<AuditEvent, 'id' | 'timestamp'>): Promise<void> {
@@ -235,7 +244,10 @@ export class MongoAdapter extends DatabaseAdapter {
       if (filters.to) filter.timestamp.$lte = filters.to;
     }
     const total = await this.col('audit_log').countDocuments(filter);
-    const cursor = this.col('audit_log').find(filter).sort({ timestamp: -1 });
+    const sort: Record<string, 1 | -1> = {};
+    if (filters.sort && filters.sort.field !== 'timestamp') sort[filters.sort.field] = filters.sort.dir === 'asc' ? 1 : -1;
+    sort.timestamp = filters.sort?.field === 'timestamp' && filters.sort.dir === 'asc' ? 1 : -1;
+    const cursor = this.col('audit_log').find(filter).sort(sort);
     if (filters.offset) cursor.skip(filters.offset);
     if (filters.limit) cursor.limit(filters.limit);
     const rows = await cursor.toArray();
@@ -248,6 +260,12 @@ export class MongoAdapter extends DatabaseAdapter {
     };
   }
 
+
+  async purgeAuditBefore(cutoff: Date): Promise<number> {
+    const result = await this.col('audit_log').deleteMany({ timestamp: { $lt: cutoff } });
+    return result.deletedCount || 0;
+  }
+
   // ─── API Keys ────────────────────────────────────────────
 
   async createApiKey(input: ApiKeyInput): Promise<{ key: ApiKey; plaintext: string }> {
@@ -289,6 +307,18 @@ export class MongoAdapter extends DatabaseAdapter {
     await this.col('api_keys').updateOne({ _id: id }, { $set: { revoked: true } });
   }
 
+  async transferApiKey(id: string, createdBy: string): Promise<void> {
+    await this.col('api_keys').updateOne({ _id: id }, { $set: { createdBy } });
+  }
+
+  async expireApiKey(id: string, expiresAt: Date): Promise<void> {
+    await this.col('api_keys').updateOne({ _id: id }, { $set: { expiresAt } });
+  }
+
+  async setApiKeyScopes(id: string, scopes: string[]): Promise<void> {
+    await this.col('api_keys').updateOne({ _id: id }, { $set: { scopes } });
+  }
+
   // ─── Rules ───────────────────────────────────────────────
 
   async createRule(rule: Omit<EmailRule, 'id' | 'createdAt' | 'updatedAt'>): Promise<EmailRule> {
@@ -347,7 +377,7 @@ export class MongoAdapter extends DatabaseAdapter {
   }
 
   private docToUser(r: any): User {
-    return { id: r._id, email: r.email, name: r.name, role: r.role, passwordHash: r.passwordHash, ssoProvider: r.ssoProvider, ssoSubject: r.ssoSubject, createdAt: r.createdAt, updatedAt: r.updatedAt, lastLoginAt: r.lastLoginAt || undefined };
+    return { id: r._id, email: r.email, name: r.name, role: r.role, passwordHash: r.passwordHash, ssoProvider: r.ssoProvider, ssoSubject: r.ssoSubject, mustResetPassword: !!r.mustResetPassword, passwordChangedAt: r.passwordChangedAt || undefined, createdAt: r.createdAt, updatedAt: r.updatedAt, lastLoginAt: r.lastLoginAt || undefined };
   }
 
   private docToApiKey(r: any): ApiKey {
@@ -88,6 +88,9 @@ export class MysqlAdapter extends DatabaseAdapter {
       await conn.execute(
         `INSERT IGNORE INTO retention_policy (id) VALUES ('default')`
       );
+      // Password columns added after the base schema
+      try { await conn.execute('ALTER TABLE users ADD COLUMN must_reset_password BOOLEAN DEFAULT FALSE'); } catch { /* exists */ }
+      try { await conn.execute('ALTER TABLE users ADD COLUMN password_changed_at TIMESTAMP NULL'); } catch { /* exists */ }
     } finally {
       conn.release();
     }
@@ -222,7 +225,7 @@ export class MysqlAdapter extends DatabaseAdapter {
   async updateAgent(id: string, updates: Partial<Agent>): Promise<Agent> {
     const fields: string[] = [];
     const vals: any[] = [];
-    for (const [key, col] of Object.entries({ name: 'name', email: 'email', role: 'role', status: 'status' })) {
+    for (const [key, col] of Object.entries({ name: 'name', email: 'email', role: 'role', status: 'status', createdBy: 'created_by' })) {
       if ((updates as any)[key] !== undefined) { fields.push(`${col} = ?`); vals.push((updates as any)[key]); }
     }
     if (updates.metadata) { fields.push('metadata = ?'); vals.push(JSON.stringify(updates.metadata)); }
@@ -308,6 +311,17 @@ export class MysqlAdapter extends DatabaseAdapter {
     await this.execute('DELETE FROM users WHERE id = ?', [id]);
   }
 
+  async updatePassword(id: string, passwordHash: string, mustReset: boolean): Promise<void> {
+    await this.execute(
+      'UPDATE users SET password_hash = ?, must_reset_password = ?, password_changed_at = NOW(), updated_at = NOW() WHERE id = ?',
+      [passwordHash, mustReset, id]
+    );
+  }
+
+  async setMustResetPassword(id: string, required: boolean): Promise<void> {
+    await this.execute('UPDATE users SET must_reset_password = ?, updated_at = NOW() WHERE id = ?', [required, id]);
+  }
+
   // ─── Audit ───────────────────────────────────────────
 
   async logEvent(event: Omit<AuditEvent, 'id' | 'timestamp'>): Promise<void> {
@@ -331,7 +345,7 @@ export class MysqlAdapter extends DatabaseAdapter {
     const countRow = await this.queryOne(`SELECT COUNT(*) as c FROM audit_log ${wc}`, params);
     const total = Number(countRow?.c || 0);
 
-    let q = `SELECT * FROM audit_log ${wc} ORDER BY timestamp DESC`;
+    let q = `SELECT * FROM audit_log ${wc} ORDER BY ${this.auditOrderBy(filters)}`;
     const qParams = [...params];
     if (filters.limit) { q += ' LIMIT ?'; qParams.push(filters.limit); }
     if (filters.offset) { q += ' OFFSET ?'; qParams.push(filters.offset); }
@@ -348,6 +362,12 @@ export class MysqlAdapter extends DatabaseAdapter {
     };
   }
 
+
+  async purgeAuditBefore(cutoff: Date): Promise<number> {
+    const result: any = await this.query('DELETE FROM audit_log WHERE timestamp < ?', [cutoff]);
+    return Number(result?.affectedRows || 0);
+  }
+
   // ─── API Keys ────────────────────────────────────────
 
   async createApiKey(input: ApiKeyInput): Promise<{ key: ApiKey; plaintext: string }> {
@@ -390,6 +410,18 @@ export class MysqlAdapter extends DatabaseAdapter {
     await this.execute('UPDATE api_keys SET revoked = 1 WHERE id = ?', [id]);
   }
 
+  async transferApiKey(id: string, createdBy: string): Promise<void> {
+    await this.execute('UPDATE api_keys SET created_by = ? WHERE id = ?', [createdBy, id]);
+  }
+
+  async expireApiKey(id: string, expiresAt: Date): Promise<void> {
+    await this.execute('UPDATE api_keys SET expires_at = ? WHERE id = ?', [expiresAt, id]);
+  }
+
+  async setApiKeyScopes(id: string, scopes: string[]): Promise<void> {
+    await this.execute('UPDATE api_keys SET scopes = ? WHERE id = ?', [JSON.stringify(scopes), id]);
+  }
+
   // ─── Rules ───────────────────────────────────────────
 
   async createRule(rule: Omit<EmailRule, 'id' | 'createdAt' | 'updatedAt'>): Promise<EmailRule> {
@@ -485,6 +517,8 @@ export class MysqlAdapter extends DatabaseAdapter {
     return {
       id: r.id, email: r.email, name: r.name, role: r.role,
       passwordHash: r.password_hash, ssoProvider: r.sso_provider, ssoSubject: r.sso_subject,
+      mustResetPassword: !!r.must_reset_password,
+      passwordChangedAt: r.password_changed_at ? new Date(r.password_changed_at) : undefined,
       createdAt: new Date(r.created_at), updatedAt: new Date(r.updated_at),
       lastLoginAt: r.last_login_at ? new Date(r.last_login_at) : undefined,
     };
@@ -244,6 +244,7 @@ export class PostgresAdapter extends DatabaseAdapter {
         ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_backup_codes TEXT;
         ALTER TABLE users ADD COLUMN IF NOT EXISTS permissions JSONB DEFAULT '"*"';
         ALTER TABLE users ADD COLUMN IF NOT EXISTS must_reset_password BOOLEAN DEFAULT FALSE;
+        ALTER TABLE users ADD COLUMN IF NOT EXISTS password_changed_at TIMESTAMP;
         ALTER TABLE users ADD COLUMN IF NOT EXISTS is_active BOOLEAN DEFAULT TRUE;
         ALTER TABLE users ADD COLUMN IF NOT EXISTS client_org_id TEXT;
         ALTER TABLE agents ADD COLUMN IF NOT EXISTS billing_rate NUMERIC(10,2) DEFAULT 0;
@@ -440,7 +441,7 @@ export class PostgresAdapter extends DatabaseAdapter {
     const fields: string[] = [];
     const values: any[] = [];
     let i = 1;
-    for (const [key, col] of Object.entries({ name: 'name', email: 'email', role: 'role', status: 'status' })) {
+    for (const [key, col] of Object.entries({ name: 'name', email: 'email', role: 'role', status: 'status', createdBy: 'created_by' })) {
       if ((updates as any)[key] !== undefined) {
         fields.push(`${col} = $${i}`);
         values.push((updates as any)[key]);
@@ -549,6 +550,17 @@ export class PostgresAdapter extends DatabaseAdapter {
     await this.pool.query('DELETE FROM users WHERE id = $1', [id]);
   }
 
+  async updatePassword(id: string, passwordHash: string, mustReset: boolean): Promise<void> {
+    await this.pool.query(
+      'UPDATE users SET password_hash = $1, must_reset_password = $2, password_changed_at = NOW(), updated_at = NOW() WHERE id = $3',
+      [passwordHash, mustReset, id]
+    );
+  }
+
+  async setMustResetPassword(id: string, required: boolean): Promise<void> {
+    await this.pool.query('UPDATE users SET must_reset_password = $1, updated_at = NOW() WHERE id = $2', [required, id]);
+  }
+
   // ─── Audit ───────────────────────────────────────────────
 
   async logEvent(event: Omit<AuditEvent, 'id' | 'timestamp'>): Promise<void> {
@@ -575,7 +587,7 @@ export class PostgresAdapter extends DatabaseAdapter {
     const countResult = await this.pool.query(`SELECT COUNT(*) FROM audit_log ${whereClause}`, params);
     const total = parseInt(countResult.rows[0].count, 10);
 
-    let q = `SELECT * FROM audit_log ${whereClause} ORDER BY timestamp DESC`;
+    let q = `SELECT * FROM audit_log ${whereClause} ORDER BY ${this.auditOrderBy(filters)}`;
     if (filters.limit) q += ` LIMIT ${filters.limit}`;
     if (filters.offset) q += ` OFFSET ${filters.offset}`;
     const { rows } = await this.pool.query(q, params);
@@ -589,6 +601,12 @@ export class PostgresAdapter extends DatabaseAdapter {
     };
   }
 
+
+  async purgeAuditBefore(cutoff: Date): Promise<number> {
+    const result = await this.pool.query('DELETE FROM audit_log WHERE timestamp < $1', [cutoff]);
+    return result.rowCount || 0;
+  }
+
   // ─── API Keys ────────────────────────────────────────────
 
   async createApiKey(input: ApiKeyInput): Promise<{ key: ApiKey; plaintext: string }> {
@@ -636,6 +654,18 @@ export class PostgresAdapter extends DatabaseAdapter {
     await this.pool.query('UPDATE api_keys SET revoked = 1 WHERE id = $1', [id]);
   }
 
+  async transferApiKey(id: string, createdBy: string): Promise<void> {
+    await this.pool.query('UPDATE api_keys SET created_by = $1 WHERE id = $2', [createdBy, id]);
+  }
+
+  async expireApiKey(id: string, expiresAt: Date): Promise<void> {
+    await this.pool.query('UPDATE api_keys SET expires_at = $1 WHERE id = $2', [expiresAt, id]);
+  }
+
+  async setApiKeyScopes(id: string, scopes: string[]): Promise<void> {
+    await this.pool.query('UPDATE api_keys SET scopes = $1 WHERE id = $2', [JSON.stringify(scopes), id]);
+  }
+
   // ─── Rules ───────────────────────────────────────────────
 
   async createRule(rule: Omit<EmailRule, 'id' | 'createdAt' | 'updatedAt'>): Promise<EmailRule> {
@@ -821,6 +851,7 @@ export class PostgresAdapter extends DatabaseAdapter {
       totpSecret: r.totp_secret, totpEnabled: !!r.totp_enabled, totpBackupCodes: r.totp_backup_codes,
       permissions: r.permissions != null ? (typeof r.permissions === 'string' ? (() => { try { return JSON.parse(r.permissions); } catch { return '*'; } })() : r.permissions) : '*',
       mustResetPassword: !!r.must_reset_password,
+      passwordChangedAt: r.password_changed_at ? new Date(r.password_changed_at) : undefined,
       isActive: r.is_active !== false && r.is_active !== 0, // default true
       clientOrgId: r.client_org_id || null,
       createdAt: new Date(r.created_at), updatedAt: new Date(r.updated_at),
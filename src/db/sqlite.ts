@@ -62,6 +62,7 @@ export class SqliteAdapter extends DatabaseAdapter {
       // Add permissions column if missing
       try { this.db.exec(`ALTER TABLE users ADD COLUMN permissions TEXT DEFAULT '"*"'`); } catch { /* exists */ }
       try { this.db.exec(`ALTER TABLE users ADD COLUMN must_reset_password INTEGER DEFAULT 0`); } catch { /* exists */ }
+      try { this.db.exec(`ALTER TABLE users ADD COLUMN password_changed_at TEXT`); } catch { /* exists */ }
       try { this.db.exec(`ALTER TABLE users ADD COLUMN is_active INTEGER DEFAULT 1`); } catch { /* exists */ }
       try { this.db.exec(`ALTER TABLE users ADD COLUMN client_org_id TEXT`); } catch { /* exists */ }
       try { this.db.exec(`ALTER TABLE agents ADD COLUMN billing_rate REAL DEFAULT 0`); } catch { /* exists */ }
@@ -209,7 +210,7 @@ export class SqliteAdapter extends DatabaseAdapter {
   async updateAgent(id: string, updates: Partial<Agent>): Promise<Agent> {
     const fields: string[] = [];
     const vals: any[] = [];
-    for (const [key, col] of Object.entries({ name: 'name', email: 'email', role: 'role', status: 'status' })) {
+    for (const [key, col] of Object.entries({ name: 'name', email: 'email', role: 'role', status: 'status', createdBy: 'created_by' })) {
       if ((updates as any)[key] !== undefined) { fields.push(`${col} = ?`); vals.push((updates as any)[key]); }
     }
     if (updates.metadata) { fields.push('metadata = ?'); vals.push(JSON.stringify(updates.metadata)); }
@@ -289,6 +290,16 @@ export class SqliteAdapter extends DatabaseAdapter {
     this.db.prepare('DELETE FROM users WHERE id = ?').run(id);
   }
 
+  async updatePassword(id: string, passwordHash: string, mustReset: boolean): Promise<void> {
+    this.db.prepare(
+      "UPDATE users SET password_hash = ?, must_reset_password = ?, password_changed_at = datetime('now'), updated_at = datetime('now') WHERE id = ?"
+    ).run(passwordHash, mustReset ? 1 : 0, id);
+  }
+
+  async setMustResetPassword(id: string, required: boolean): Promise<void> {
+    this.db.prepare("UPDATE users SET must_reset_password = ?, updated_at = datetime('now') WHERE id = ?").run(required ? 1 : 0, id);
+  }
+
   // ─── Audit ───────────────────────────────────────────────
 
   async logEvent(event: Omit<AuditEvent, 'id' | 'timestamp'>): Promise<void> {
@@ -308,7 +319,7 @@ export class SqliteAdapter extends DatabaseAdapter {
     if (filters.to) { where.push('timestamp <= ?'); params.push(filters.to.toISOString()); }
     const wc = where.length > 0 ? `WHERE ${where.join(' AND ')}` : '';
     const total = this.db.prepare(`SELECT COUNT(*) as c FROM audit_log ${wc}`).get(...params).c;
-    let q = `SELECT * FROM audit_log ${wc} ORDER BY timestamp DESC`;
+    let q = `SELECT * FROM audit_log ${wc} ORDER BY ${this.auditOrderBy(filters)}`;
     if (filters.limit) q += ` LIMIT ${filters.limit}`;
     if (filters.offset) q += ` OFFSET ${filters.offset}`;
     const rows = this.db.prepare(q).all(...params);
@@ -321,6 +332,11 @@ export class SqliteAdapter extends DatabaseAdapter {
     };
   }
 
+
+  async purgeAuditBefore(cutoff: Date): Promise<number> {
+    return this.db.prepare('DELETE FROM audit_log WHERE timestamp < ?').run(cutoff.toISOString()).changes;
+  }
+
   // ─── API Keys ────────────────────────────────────────────
 
   async createApiKey(input: ApiKeyInput): Promise<{ key: ApiKey; plaintext: string }> {
@@ -362,6 +378,18 @@ export class SqliteAdapter extends DatabaseAdapter {
     this.db.prepare('UPDATE api_keys SET revoked = 1 WHERE id = ?').run(id);
   }
 
+  async transferApiKey(id: string, createdBy: string): Promise<void> {
+    this.db.prepare('UPDATE api_keys SET created_by = ? WHERE id = ?').run(createdBy, id);
+  }
+
+  async expireApiKey(id: string, expiresAt: Date): Promise<void> {
+    this.db.prepare('UPDATE api_keys SET expires_at = ? WHERE id = ?').run(expiresAt.toISOString(), id);
+  }
+
+  async setApiKeyScopes(id: string, scopes: string[]): Promise<void> {
+    this.db.prepare('UPDATE api_keys SET scopes = ? WHERE id = ?').run(JSON.stringify(scopes), id);
+  }
+
   // ─── Rules ───────────────────────────────────────────────
 
   async createRule(rule: Omit<EmailRule, 'id' | 'createdAt' | 'updatedAt'>): Promise<EmailRule> {
@@ -447,6 +475,7 @@ export class SqliteAdapter extends DatabaseAdapter {
       totpSecret: r.totp_secret, totpEnabled: !!r.totp_enabled, totpBackupCodes: r.totp_backup_codes,
       permissions: r.permissions != null ? (typeof r.permissions === 'string' ? (() => { try { return JSON.parse(r.permissions); } catch { return '*'; } })() : r.permissions) : '*',
       mustResetPassword: !!r.must_reset_password,
+      passwordChangedAt: r.password_changed_at ? new Date(r.password_changed_at) : undefined,
       isActive: r.is_active !== 0 && r.is_active !== false,
       clientOrgId: r.client_org_id || null,
       createdAt: new Date(r.created_at), updatedAt: new Date(r.updated_at),
@@ -93,6 +93,9 @@ export class TursoAdapter extends DatabaseAdapter {
       ]),
       'write',
     );
+    // Password columns added after the base schema
+    try { await this.run('ALTER TABLE users ADD COLUMN must_reset_password INTEGER DEFAULT 0'); } catch { /* exists */ }
+    try { await this.run('ALTER TABLE users ADD COLUMN password_changed_at TEXT'); } catch { /* exists */ }
   }
 
   // ─── Company ─────────────────────────────────────────────
@@ -184,7 +187,7 @@ export class TursoAdapter extends DatabaseAdapter {
   async updateAgent(id: string, updates: Partial<Agent>): Promise<Agent> {
     const fields: string[] = [];
     const vals: any[] = [];
-    for (const [key, col] of Object.entries({ name: 'name', email: 'email', role: 'role', status: 'status' })) {
+    for (const [key, col] of Object.entries({ name: 'name', email: 'email', role: 'role', status: 'status', createdBy: 'created_by' })) {
       if ((updates as any)[key] !== undefined) { fields.push(`${col} = ?`); vals.push((updates as any)[key]); }
     }
     if (updates.metadata) { fields.push('metadata = ?'); vals.push(JSON.stringify(updates.metadata)); }
@@ -264,6 +267,17 @@ export class TursoAdapter extends DatabaseAdapter {
     await this.run('DELETE FROM users WHERE id = ?', [id]);
   }
 
+  async updatePassword(id: string, passwordHash: string, mustReset: boolean): Promise<void> {
+    await this.run(
+      "UPDATE users SET password_hash = ?, must_reset_password = ?, password_changed_at = datetime('now'), updated_at = datetime('now') WHERE id = ?",
+      [passwordHash, mustReset ? 1 : 0, id]
+    );
+  }
+
+  async setMustResetPassword(id: string, required: boolean): Promise<void> {
+    await this.run("UPDATE users SET must_reset_password = ?, updated_at = datetime('now') WHERE id = ?", [required ? 1 : 0, id]);
+  }
+
   // ─── Audit ───────────────────────────────────────────────
 
   async logEvent(event: Omit<AuditEvent, 'id' | 'timestamp'>): Promise<void> {
@@ -283,7 +297,7 @@ export class TursoAdapter extends DatabaseAdapter {
     if (filters.to) { where.push('timestamp <= ?'); params.push(filters.to.toISOString()); }
     const wc = where.length > 0 ? `WHERE ${where.join(' AND ')}` : '';
     const countRow = await this.get(`SELECT COUNT(*) as c FROM audit_log ${wc}`, params);
-    let q = `SELECT * FROM audit_log ${wc} ORDER BY timestamp DESC`;
+    let q = `SELECT * FROM audit_log ${wc} ORDER BY ${this.auditOrderBy(filters)}`;
     if (filters.limit) q += ` LIMIT ${filters.limit}`;
     if (filters.offset) q += ` OFFSET ${filters.offset}`;
     const rows = await this.all(q, params);
@@ -296,6 +310,12 @@ export class TursoAdapter extends DatabaseAdapter {
     };
   }
 
+
+  async purgeAuditBefore(cutoff: Date): Promise<number> {
+    const result = await this.run('DELETE FROM audit_log WHERE timestamp < ?', [cutoff.toISOString()]);
+    return Number(result?.rowsAffected || 0);
+  }
+
   // ─── API Keys ────────────────────────────────────────────
 
   async createApiKey(input: ApiKeyInput): Promise<{ key: ApiKey; plaintext: string }> {
@@ -337,6 +357,18 @@ export class TursoAdapter extends DatabaseAdapter {
     await this.run('UPDATE api_keys SET revoked = 1 WHERE id = ?', [id]);
   }
 
+  async transferApiKey(id: string, createdBy: string): Promise<void> {
+    await this.run('UPDATE api_keys SET created_by = ? WHERE id = ?', [createdBy, id]);
+  }
+
+  async expireApiKey(id: string, expiresAt: Date): Promise<void> {
+    await this.run('UPDATE api_keys SET expires_at = ? WHERE id = ?', [expiresAt.toISOString(), id]);
+  }
+
+  async setApiKeyScopes(id: string, scopes: string[]): Promise<void> {
+    await this.run('UPDATE api_keys SET scopes = ? WHERE id = ?', [JSON.stringify(scopes), id]);
+  }
+
   // ─── Rules ───────────────────────────────────────────────
 
   async createRule(rule: Omit<EmailRule, 'id' | 'createdAt' | 'updatedAt'>): Promise<EmailRule> {
@@ -424,6 +456,8 @@ export class TursoAdapter extends DatabaseAdapter {
     return {
       id: r.id, email: r.email, name: r.name, role: r.role,
       passwordHash: r.password_hash, ssoProvider: r.sso_provider, ssoSubject: r.sso_subject,
+      mustResetPassword: !!r.must_reset_password,
+      passwordChangedAt: r.password_changed_at ? new Date(r.password_changed_at) : undefined,
       createdAt: new Date(r.created_at), updatedAt: new Date(r.updated_at),
       lastLoginAt: r.last_login_at ? new Date(r.last_login_at) : undefined,
     };
@@ -43,6 +43,8 @@ export function createActivityRoutes(opts: {
       agentId: c.req.query('agentId') || undefined,
       orgId: c.req.query('orgId') || undefined,
       toolId: c.req.query('toolId') || undefined,
+      since: c.req.query('since') || undefined,
+      status: (c.req.query('status') as 'success' | 'failed') || undefined,
       limit: 10000,
     });
     const search = (c.req.query('search') || '').toLowerCase();
@@ -67,6 +69,23 @@ export function createActivityRoutes(opts: {
     return c.json(activity.getStats(orgId || undefined));
   });
 
+  router.get('/activity/trend', async (c) => {
+    const trend = await activity.getDailyTrend({
+      orgId: c.req.query('orgId') || undefined,
+      agentId: c.req.query('agentId') || undefined,
+      days: parseInt(c.req.query('days') || '14'),
+    });
+    return c.json({ trend });
+  });
+
+  router.get('/activity/leaderboard', async (c) => {
+    const agents = await activity.getAgentLeaderboard({
+      orgId: c.req.query('orgId') || undefined,
+      days: parseInt(c.req.query('days') || '7'),
+    });
+    return c.json({ agents });
+  });
+
   // SSE endpoint for real-time events
   router.get('/activity/stream', (c) => {
     const orgId = c.req.query('orgId');
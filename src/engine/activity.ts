@@ -105,6 +105,29 @@ export interface AgentTimeline {
   };
 }
 
+export interface DailyTrendPoint {
+  day: string;                       // YYYY-MM-DD (UTC)
+  toolCalls: number;
+  errors: number;
+  /** Messages and emails sent or received */
+  messages: number;
+  /** Estimated LLM spend */
+  costUsd: number;
+}
+
+export interface AgentLeaderboardEntry {
+  agentId: string;
+  /** Messages and emails sent or received */
+  messages: number;
+  toolCalls: number;
+  /** Failed tool calls plus general errors */
+  errors: number;
+  /** Share of tool calls that failed, 0-1 */
+  errorRate: number;
+  /** Estimated LLM spend */
+  costUsd: number;
+}
+
 export interface TimelineEntry {
   timestamp: string;
   type: ActivityType;
@@ -385,12 +408,20 @@ export class ActivityTracker {
     agentId?: string;
     orgId?: string;
     toolId?: string;
+    since?: string;
+    status?: 'success' | 'failed';
     limit?: number;
   }): ToolCallRecord[] {
     let calls = Array.from(this.toolCalls.values());
     if (opts.agentId) calls = calls.filter(c => c.agentId === opts.agentId);
     if (opts.orgId) calls = calls.filter(c => c.orgId === opts.orgId);
     if (opts.toolId) calls = calls.filter(c => c.toolId === opts.toolId);
+    if (opts.since) {
+      const since = new Date(opts.since).getTime();
+      calls = calls.filter(c => new Date(c.timing.startedAt).getTime() >= since);
+    }
+    // Calls still running have no result yet and count as neither
+    if (opts.status) calls = calls.filter(c => c.result && (c.result.success === (opts.status === 'success')));
     calls.sort((a, b) => new Date(b.timing.startedAt).getTime() - new Date(a.timing.startedAt).getTime());
     return calls.slice(0, opts.limit || 50);
   }
@@ -503,6 +534,88 @@ export class ActivityTracker {
 
   // ─── Stats ──────────────────────────────────────────
 
+  /**
+   * Per-day tool calls, errors, messages and LLM cost over the last `days`
+   * days, oldest first. Read from the database so it reaches past the
+   * in-memory buffer.
+   */
+  async getDailyTrend(opts: { orgId?: string; agentId?: string; days?: number }): Promise<DailyTrendPoint[]> {
+    const days = Math.min(Math.max(opts.days || 14, 1), 90);
+    const trend: DailyTrendPoint[] = Array.from({ length: days }, (_, i) => ({
+      day: new Date(Date.now() - (days - 1 - i) * 86_400_000).toISOString().slice(0, 10),
+      toolCalls: 0, errors: 0, messages: 0, costUsd: 0,
+    }));
+    if (!this.engineDb) return trend;
+    const byDay = new Map(trend.map(d => [d.day, d]));
+    // Only llm_call payloads are needed (for the cost); skip reading the rest
+    let sql = "SELECT type, CASE WHEN type = 'llm_call' THEN data END AS data, created_at FROM activity_events"
+      + " WHERE type IN ('tool_call_end', 'tool_call_error', 'error', 'message_sent', 'message_received', 'email_sent', 'email_received', 'llm_call') AND created_at >= ?";
+    const params: any[] = [trend[0].day];
+    if (opts.orgId) { sql += ' AND org_id = ?'; params.push(opts.orgId); }
+    if (opts.agentId) { sql += ' AND agent_id = ?'; params.push(opts.agentId); }
+    const rows = await this.engineDb.query<any>(sql + ' LIMIT 200000', params).catch(() => []);
+    for (const r of rows) {
+      const d = byDay.get((r.created_at instanceof Date ? r.created_at.toISOString() : String(r.created_at)).slice(0, 10));
+      if (!d) continue;
+      switch (r.type) {
+        case 'tool_call_end': d.toolCalls++; break;
+        case 'tool_call_error': d.toolCalls++; d.errors++; break;
+        case 'error': d.errors++; break;
+        case 'llm_call': {
+          try {
+            const data = typeof r.data === 'string' ? JSON.parse(r.data) : r.data;
+            d.costUsd += Number(data?.costUsd) || 0;
+          } catch { /* malformed payload */ }
+          break;
+        }
+        default: d.messages++;
+      }
+    }
+    for (const d of trend) d.costUsd = Math.round(d.costUsd * 10_000) / 10_000;
+    return trend;
+  }
+
+  /**
+   * Per-agent messages, tool calls, errors and LLM cost over the last `days`
+   * days (rolling, not calendar days), busiest agents first. Same event
+   * types and cost source as getDailyTrend.
+   */
+  async getAgentLeaderboard(opts: { orgId?: string; days?: number }): Promise<AgentLeaderboardEntry[]> {
+    if (!this.engineDb) return [];
+    const days = Math.min(Math.max(opts.days || 7, 1), 90);
+    let sql = "SELECT agent_id, type, CASE WHEN type = 'llm_call' THEN data END AS data FROM activity_events"
+      + " WHERE type IN ('tool_call_end', 'tool_call_error', 'error', 'message_sent', 'message_received', 'email_sent', 'email_received', 'llm_call') AND created_at >= ?";
+    const params: any[] = [new Date(Date.now() - days * 86_400_000).toISOString()];
+    if (opts.orgId) { sql += ' AND org_id = ?'; params.push(opts.orgId); }
+    const rows = await this.engineDb.query<any>(sql + ' LIMIT 200000', params).catch(() => []);
+
+    const byAgent = new Map<string, AgentLeaderboardEntry & { toolErrors: number }>();
+    for (const r of rows) {
+      let a = byAgent.get(r.agent_id);
+      if (!a) byAgent.set(r.agent_id, a = { agentId: r.agent_id, messages: 0, toolCalls: 0, errors: 0, errorRate: 0, costUsd: 0, toolErrors: 0 });
+      switch (r.type) {
+        case 'tool_call_end': a.toolCalls++; break;
+        case 'tool_call_error': a.toolCalls++; a.toolErrors++; a.errors++; break;
+        case 'error': a.errors++; break;
+        case 'llm_call': {
+          try {
+            const data = typeof r.data === 'string' ? JSON.parse(r.data) : r.data;
+            a.costUsd += Number(data?.costUsd) || 0;
+          } catch { /* malformed payload */ }
+          break;
+        }
+        default: a.messages++;
+      }
+    }
+    return Array.from(byAgent.values())
+      .map(({ toolErrors, ...a }) => ({
+        ...a,
+        errorRate: a.toolCalls ? Math.round(toolErrors / a.toolCalls * 10_000) / 10_000 : 0,
+        costUsd: Math.round(a.costUsd * 10_000) / 10_000,
+      }))
+      .sort((x, y) => y.messages - x.messages || y.toolCalls - x.toolCalls);
+  }
+
   /**
    * Get real-time stats for dashboard
    */
@@ -24,7 +24,8 @@ export interface AgentConfig {
     tone: 'formal' | 'casual' | 'professional' | 'friendly' | 'custom';
     customTone?: string;
     language: string;                     // Primary language (e.g. "en", "es", "fr")
-    avatar?: string;                      // URL or base64
+    avatar?: string;                      // /api/engine/avatars/<id> URL; older configs may hold a data URL
+    signature?: string;                   // HTML email signature; overrides the org signature template
     gender?: string;                      // e.g. "male", "female", "non-binary"
     dateOfBirth?: string;                 // ISO date string (e.g. "1994-03-15")
     age?: number;                         // Derived from dateOfBirth at runtime
@@ -147,6 +148,9 @@ export interface AgentConfig {
   permissionProfileId: string;
 
   // Deployment
+  /** Data residency region this agent and its mailbox are pinned to (see data-residency.ts) */
+  dataRegion?: string;
+
   deployment: {
     target: DeploymentTarget;
     config: DeploymentConfig;
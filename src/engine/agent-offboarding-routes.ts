@@ -0,0 +1,104 @@
+/**
+ * Agent Lifecycle Routes
+ * Mounted at /agent-lifecycle/* on the engine sub-app.
+ */
+
+import { Hono } from 'hono';
+import { AgentOffboarding, IDENTITY_STATES, OFFBOARDING_STEPS, type IdentityState } from './agent-offboarding.js';
+import type { DatabaseAdapter } from '../db/adapter.js';
+import { auditFromEngine } from './route-audit.js';
+import { isAdminCaller } from './caller-role.js';
+
+export function createAgentOffboardingRoutes(offboarding: AgentOffboarding, deps: { getAdminDb?: () => DatabaseAdapter | null } = {}) {
+  const router = new Hono();
+
+  const audit = auditFromEngine(deps.getAdminDb);
+
+  const userOf = (c: any) => c.req.header('X-User-Id') || 'dashboard';
+
+  router.get('/states', (c) => c.json({ states: IDENTITY_STATES, steps: OFFBOARDING_STEPS }));
+
+  // Current state, allowed transitions, the offboarding record and what it would touch
+  router.get('/:agentId', async (c) => {
+    const agentId = c.req.param('agentId');
+    try {
+      const state = await offboarding.getState(agentId);
+      return c.json({
+        state,
+        transitions: offboarding.allowedTransitions(state),
+        offboarding: await offboarding.getRecord(agentId),
+        preview: state === 'retired' ? null : await offboarding.preview(agentId),
+      });
+    } catch (e: any) { return c.json({ error: e.message }, 404); }
+  });
+
+  // { to, reason }
+  router.post('/:agentId/transition', async (c) => {
+    if (!isAdminCaller(c)) return c.json({ error: 'Only admins can change an agent\'s lifecycle state' }, 403);
+    const agentId = c.req.param('agentId');
+    const body = await c.req.json().catch(() => ({}));
+    try {
+      const result = await offboarding.transition(agentId, body.to as IdentityState, userOf(c));
+      audit(c, 'agent.lifecycle_' + result.to, `agent:${agentId}`, { from: result.from, reason: body.reason || undefined, revokedApiKeys: result.revokedApiKeys?.length ? result.revokedApiKeys : undefined });
+      return c.json({ success: true, ...result });
+    } catch (e: any) { return c.json({ error: e.message }, 400); }
+  });
+
+  // { successorId, reason } — moves the agent to offboarding
+  router.post('/:agentId/offboarding', async (c) => {
+    if (!isAdminCaller(c)) return c.json({ error: 'Only admins can offboard agents' }, 403);
+    const agentId = c.req.param('agentId');
+    const body = await c.req.json().catch(() => ({}));
+    try {
+      const record = await offboarding.startOffboarding(agentId, { successorId: body.successorId || null, reason: body.reason }, userOf(c));
+      audit(c, 'agent.offboarding_start', `agent:${agentId}`, { successorId: record.successorId, reason: record.reason, previous: record.previousState }, record.orgId);
+      return c.json({ success: true, offboarding: record });
+    } catch (e: any) { return c.json({ error: e.message }, 400); }
+  });
+
+  // { successorId } — before conversations have been reassigned
+  router.put('/:agentId/offboarding', async (c) => {
+    if (!isAdminCaller(c)) return c.json({ error: 'Only admins can offboard agents' }, 403);
+    const agentId = c.req.param('agentId');
+    const body = await c.req.json().catch(() => ({}));
+    try {
+      const record = await offboarding.setSuccessor(agentId, body.successorId || null);
+      audit(c, 'agent.offboarding_update', `agent:${agentId}`, { successorId: record.successorId }, record.orgId);
+      return c.json({ success: true, offboarding: record });
+    } catch (e: any) { return c.json({ error: e.message }, 400); }
+  });
+
+  // { all } — runs the next step, or every remaining one
+  router.post('/:agentId/offboarding/run', async (c) => {
+    if (!isAdminCaller(c)) return c.json({ error: 'Only admins can offboard agents' }, 403);
+    const agentId = c.req.param('agentId');
+    const body = await c.req.json().catch(() => ({}));
+    try {
+      const before = await offboarding.getRecord(agentId);
+      const record = await offboarding.run(agentId, userOf(c), { all: !!body.all });
+      for (const step of record.steps) {
+        const prev = before?.steps.find(s => s.id === step.id);
+        if (step.at && step.at !== prev?.at) {
+          audit(c, `agent.offboarding_${step.id}${step.status === 'failed' ? '_failed' : ''}`, `agent:${agentId}`, { ...(step.result || {}), error: step.error }, record.orgId);
+        }
+      }
+      return c.json({ success: true, offboarding: record });
+    } catch (e: any) { return c.json({ error: e.message }, 400); }
+  });
+
+  // The exported action journal
+  router.get('/:agentId/offboarding/journal', async (c) => {
+    if (!isAdminCaller(c)) return c.json({ error: 'Only admins can download offboarding exports' }, 403);
+    try {
+      const file = await offboarding.downloadJournal(c.req.param('agentId'));
+      return new Response(file.data, {
+        headers: {
+          'Content-Type': 'application/json',
+          'Content-Disposition': `attachment; filename="${file.fileName}"`,
+        },
+      });
+    } catch (e: any) { return c.json({ error: e.message }, 404); }
+  });
+
+  return router;
+}
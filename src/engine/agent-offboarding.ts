@@ -0,0 +1,424 @@
+/**
+ * Agent Identity Lifecycle — states beyond active/archived, and offboarding
+ *
+ * An agent identity moves through draft → pending-approval → active, can be
+ * suspended and resumed, and leaves through offboarding → retired. Only the
+ * transitions in IDENTITY_TRANSITIONS are allowed; "archived" is the legacy
+ * soft-delete state and can still be restored or offboarded.
+ *
+ * Offboarding is a guided, resumable flow. Its steps run in order and stop
+ * at the first failure, so an admin can fix the cause and continue:
+ *
+ *   1. reassign — open sessions and unfinished pipeline tasks move to the
+ *      successor agent (or are closed/cancelled when there is none)
+ *   2. revoke   — the agent's vault secrets, database grants and mailbox
+ *      credentials are removed, and API keys bound to it revoked
+ *   3. export   — the action journal is exported as JSON to org storage
+ *   4. retire   — the agent is stopped, its mailbox detached, and the
+ *      identity marked retired
+ *
+ * The identity state lives on the admin agents table (status); offboarding
+ * records live in engine_settings under 'agent_offboarding:<agentId>'.
+ */
+
+import crypto from 'crypto';
+import type { EngineDatabase } from './db-adapter.js';
+import type { AgentLifecycleManager } from './lifecycle.js';
+import type { SecureVault } from './vault.js';
+import type { StorageManager } from './storage-manager.js';
+import type { DatabaseAdapter } from '../db/adapter.js';
+import { revokeAgentApiKeys } from '../middleware/api-key-agents.js';
+
+// ─── Types ──────────────────────────────────────────────
+
+export type IdentityState = 'draft' | 'pending-approval' | 'active' | 'suspended' | 'offboarding' | 'retired' | 'archived';
+
+export const IDENTITY_STATES: IdentityState[] = ['draft', 'pending-approval', 'active', 'suspended', 'offboarding', 'retired'];
+
+/**
+ * Allowed manual transitions. offboarding → retired only happens through the
+ * flow; offboarding → active cancels it and restores the previous state.
+ */
+export const IDENTITY_TRANSITIONS: Record<IdentityState, IdentityState[]> = {
+  'draft': ['pending-approval', 'retired'],
+  'pending-approval': ['active', 'draft'],
+  'active': ['suspended', 'offboarding'],
+  'suspended': ['active', 'offboarding'],
+  'offboarding': ['active'],
+  'retired': [],
+  'archived': ['active', 'offboarding'],
+};
+
+export type OffboardingStepId = 'reassign' | 'revoke' | 'export' | 'retire';
+
+export const OFFBOARDING_STEPS: Array<{ id: OffboardingStepId; label: string }> = [
+  { id: 'reassign', label: 'Reassign open conversations and tasks' },
+  { id: 'revoke', label: 'Revoke credentials' },
+  { id: 'export', label: 'Export action journal' },
+  { id: 'retire', label: 'Retire mailbox and identity' },
+];
+
+export interface OffboardingStep {
+  id: OffboardingStepId;
+  status: 'pending' | 'done' | 'failed';
+  at?: string;
+  by?: string;
+  result?: Record<string, any>;
+  error?: string;
+}
+
+export interface OffboardingRecord {
+  agentId: string;
+  orgId: string;
+  /** Agent that takes over open sessions and tasks; null closes them instead */
+  successorId: string | null;
+  /** State to return to if offboarding is cancelled before any step ran */
+  previousState: IdentityState;
+  reason?: string;
+  startedAt: string;
+  startedBy: string;
+  completedAt?: string;
+  steps: OffboardingStep[];
+  journalExport?: { storageKey: string; fileName: string; entries: number; sha256: string };
+}
+
+export interface OffboardingPreview {
+  openSessions: number;
+  openTasks: number;
+  vaultSecrets: number;
+  databaseGrants: number;
+  journalEntries: number;
+  mailbox: string | null;
+}
+
+const SETTINGS_PREFIX = 'agent_offboarding:';
+
+// ─── Manager ────────────────────────────────────────────
+
+export class AgentOffboarding {
+  private db: EngineDatabase | null = null;
+  private lifecycle: AgentLifecycleManager;
+  private vault: SecureVault;
+  private storage: StorageManager;
+  private databaseManager: { getAgentAccess(agentId: string): Array<{ connectionId: string }>; revokeAccess(agentId: string, connectionId: string): Promise<boolean> } | null;
+  private getAdminDb: () => DatabaseAdapter | null;
+
+  constructor(opts: {
+    lifecycle: AgentLifecycleManager;
+    vault: SecureVault;
+    storage: StorageManager;
+    databaseManager?: AgentOffboarding['databaseManager'];
+    getAdminDb: () => DatabaseAdapter | null;
+  }) {
+    this.lifecycle = opts.lifecycle;
+    this.vault = opts.vault;
+    this.storage = opts.storage;
+    this.databaseManager = opts.databaseManager || null;
+    this.getAdminDb = opts.getAdminDb;
+  }
+
+  async setDb(db: EngineDatabase): Promise<void> {
+    this.db = db;
+  }
+
+  // ─── Identity State ───────────────────────────────────
+
+  async getState(agentId: string): Promise<IdentityState> {
+    const agent = await this.getAdminDb()?.getAgent(agentId).catch(() => null);
+    if (!agent) throw new Error('Agent not found');
+    return ((agent.status as string) || 'active') as IdentityState;
+  }
+
+  allowedTransitions(state: IdentityState): IdentityState[] {
+    return IDENTITY_TRANSITIONS[state] || [];
+  }
+
+  /**
+   * Manual transition. Moving to offboarding goes through startOffboarding();
+   * leaving offboarding is only possible before any step has run.
+   */
+  async transition(agentId: string, to: IdentityState, by: string): Promise<{ from: IdentityState; to: IdentityState; revokedApiKeys?: string[] }> {
+    const from = await this.getState(agentId);
+    if (to === 'offboarding') throw new Error('Start offboarding to choose a successor');
+    if (!this.allowedTransitions(from).includes(to)) throw new Error(`Cannot move from "${from}" to "${to}"`);
+
+    if (from === 'offboarding') {
+      const record = await this.getRecord(agentId);
+      if (record?.steps.some(s => s.status === 'done')) throw new Error('Offboarding has already started changing this agent; finish it instead');
+      await this.deleteRecord(agentId);
+      // Cancelling returns the agent to where it was
+      if (record?.previousState) to = record.previousState;
+    }
+
+    // A suspended or retired identity shouldn't keep a running process
+    if (to === 'suspended' || to === 'retired') await this.stopAgent(agentId, by, `Identity ${to}`);
+
+    await this.setState(agentId, to);
+    // Service-account keys bound to a retired agent stop working with it
+    if (to === 'retired') return { from, to, revokedApiKeys: await this.revokeApiKeys(agentId) };
+    return { from, to };
+  }
+
+  // ─── Offboarding ──────────────────────────────────────
+
+  async getRecord(agentId: string): Promise<OffboardingRecord | null> {
+    const row = await this.db?.get<{ value: string }>('SELECT value FROM engine_settings WHERE key = ?', [SETTINGS_PREFIX + agentId]).catch(() => undefined);
+    try { return row?.value ? JSON.parse(row.value) : null; } catch { return null; }
+  }
+
+  async preview(agentId: string): Promise<OffboardingPreview> {
+    const managed = this.lifecycle.getAgent(agentId);
+    const count = async (sql: string, params: any[]) => {
+      const row = await this.db?.get<{ n: number }>(sql, params).catch(() => undefined);
+      return Number(row?.n || 0);
+    };
+    return {
+      openSessions: await count("SELECT COUNT(*) AS n FROM agent_sessions WHERE agent_id = ? AND status = 'active'", [agentId]),
+      openTasks: await count("SELECT COUNT(*) AS n FROM task_pipeline WHERE assigned_to = ? AND status IN ('created', 'assigned', 'in_progress')", [agentId]),
+      vaultSecrets: (await this.agentSecrets(agentId, managed?.orgId || 'default')).length,
+      databaseGrants: this.databaseManager?.getAgentAccess(agentId).length || 0,
+      journalEntries: await count('SELECT COUNT(*) AS n FROM action_journal WHERE agent_id = ?', [agentId]),
+      mailbox: managed?.config?.emailConfig?.email || managed?.config?.email?.address || null,
+    };
+  }
+
+  async startOffboarding(agentId: string, opts: { successorId?: string | null; reason?: string }, by: string): Promise<OffboardingRecord> {
+    const from = await this.getState(agentId);
+    if (!this.allowedTransitions(from).includes('offboarding')) throw new Error(`Cannot offboard an agent that is "${from}"`);
+    const managed = this.lifecycle.getAgent(agentId);
+    const orgId = managed?.orgId || 'default';
+
+    const successorId = opts.successorId || null;
+    if (successorId) await this.checkSuccessor(agentId, successorId, orgId);
+
+    const record: OffboardingRecord = {
+      agentId, orgId, successorId,
+      previousState: from,
+      reason: opts.reason ? String(opts.reason).slice(0, 500) : undefined,
+      startedAt: new Date().toISOString(),
+      startedBy: by,
+      steps: OFFBOARDING_STEPS.map(s => ({ id: s.id, status: 'pending' })),
+    };
+    await this.saveRecord(record);
+    await this.setState(agentId, 'offboarding');
+    return record;
+  }
+
+  /** Change the successor before the reassign step has run. */
+  async setSuccessor(agentId: string, successorId: string | null): Promise<OffboardingRecord> {
+    const record = await this.requireRecord(agentId);
+    if (record.steps[0].status === 'done') throw new Error('Conversations have already been reassigned');
+    if (successorId) await this.checkSuccessor(agentId, successorId, record.orgId);
+    record.successorId = successorId;
+    await this.saveRecord(record);
+    return record;
+  }
+
+  /**
+   * Run the next pending step — or, with `all`, every remaining step —
+   * stopping at the first failure. Steps can't be skipped or reordered.
+   */
+  async run(agentId: string, by: string, opts: { all?: boolean } = {}): Promise<OffboardingRecord> {
+    const record = await this.requireRecord(agentId);
+    for (const step of record.steps) {
+      if (step.status === 'done') continue;
+      try {
+        step.result = await this.runStep(step.id, record, by);
+        step.status = 'done';
+        step.error = undefined;
+      } catch (err: any) {
+        step.status = 'failed';
+        step.error = err.message;
+      }
+      step.at = new Date().toISOString();
+      step.by = by;
+      await this.saveRecord(record);
+      if (step.status === 'failed' || !opts.all) break;
+    }
+    if (record.steps.every(s => s.status === 'done') && !record.completedAt) {
+      record.completedAt = new Date().toISOString();
+      await this.saveRecord(record);
+    }
+    return record;
+  }
+
+  async downloadJournal(agentId: string): Promise<{ fileName: string; data: Buffer }> {
+    const record = await this.requireRecord(agentId);
+    if (!record.journalExport) throw new Error('The journal has not been exported yet');
+    return { fileName: record.journalExport.fileName, data: await this.storage.downloadDocument(record.orgId, record.journalExport.storageKey) };
+  }
+
+  // ─── Steps ────────────────────────────────────────────
+
+  private async runStep(id: OffboardingStepId, record: OffboardingRecord, by: string): Promise<Record<string, any>> {
+    switch (id) {
+      case 'reassign': return this.reassign(record);
+      case 'revoke': return this.revoke(record);
+      case 'export': return this.exportJournal(record, by);
+      case 'retire': return this.retire(record, by);
+    }
+  }
+
+  private async reassign(record: OffboardingRecord): Promise<Record<string, any>> {
+    const db = this.requireDb();
+    const { agentId, successorId } = record;
+    if (successorId) await this.checkSuccessor(agentId, successorId, record.orgId);
+    const preview = await this.preview(agentId);
+    const now = Date.now();
+    if (successorId) {
+      const successor = this.lifecycle.getAgent(successorId);
+      await db.execute("UPDATE agent_sessions SET agent_id = ?, updated_at = ? WHERE agent_id = ? AND status = 'active'", [successorId, now, agentId]);
+      // In-progress work restarts under the successor
+      await db.execute(
+        "UPDATE task_pipeline SET assigned_to = ?, assigned_to_name = ?, status = 'assigned', started_at = NULL WHERE assigned_to = ? AND status IN ('created', 'assigned', 'in_progress')",
+        [successorId, successor?.config?.displayName || successor?.config?.name || successorId, agentId],
+      );
+    } else {
+      await db.execute("UPDATE agent_sessions SET status = 'completed', updated_at = ? WHERE agent_id = ? AND status = 'active'", [now, agentId]);
+      await db.execute(
+        "UPDATE task_pipeline SET status = 'cancelled', error = ?, completed_at = ? WHERE assigned_to = ? AND status IN ('created', 'assigned', 'in_progress')",
+        ['Agent offboarded', new Date(now).toISOString(), agentId],
+      );
+    }
+    return { sessions: preview.openSessions, tasks: preview.openTasks, successorId };
+  }
+
+  private async revoke(record: OffboardingRecord): Promise<Record<string, any>> {
+    const { agentId } = record;
+    const secrets = await this.agentSecrets(agentId, record.orgId);
+    for (const s of secrets) await this.vault.deleteSecret(s.id);
+
+    let grants = 0;
+    for (const access of this.databaseManager?.getAgentAccess(agentId) || []) {
+      if (await this.databaseManager!.revokeAccess(agentId, access.connectionId)) grants++;
+    }
+
+    // Mailbox credentials go now; the address stays until the mailbox is retired
+    let mailbox = false;
+    const managed = this.lifecycle.getAgent(agentId);
+    const emailConfig = managed?.config?.emailConfig;
+    if (emailConfig) {
+      for (const key of ['password', 'smtpPass', 'imapPass', 'oauthAccessToken', 'oauthRefreshToken', 'oauthClientSecret', 'oauthTokenExpiry']) {
+        if (key in emailConfig) { delete emailConfig[key]; mailbox = true; }
+      }
+      emailConfig.status = 'disconnected';
+    }
+    if (managed?.config?.email?.relayConfig || managed?.config?.email?.domainConfig) {
+      delete managed.config.email.relayConfig;
+      delete managed.config.email.domainConfig;
+      mailbox = true;
+    }
+    if (managed) await this.lifecycle.saveAgent(agentId);
+    const apiKeys = await this.revokeApiKeys(agentId);
+    return { vaultSecrets: secrets.length, databaseGrants: grants, mailboxCredentials: mailbox, apiKeys };
+  }
+
+  private async exportJournal(record: OffboardingRecord, by: string): Promise<Record<string, any>> {
+    const db = this.requireDb();
+    const rows = await db.query<any>('SELECT * FROM action_journal WHERE agent_id = ? ORDER BY created_at', [record.agentId]);
+    const entries = rows.map((r: any) => ({
+      ...r,
+      forward_data: parseJson(r.forward_data),
+      reverse_data: parseJson(r.reverse_data),
+    }));
+    const managed = this.lifecycle.getAgent(record.agentId);
+    const body = JSON.stringify({
+      agentId: record.agentId,
+      agentName: managed?.config?.displayName || managed?.config?.name || record.agentId,
+      orgId: record.orgId,
+      exportedAt: new Date().toISOString(),
+      exportedBy: by,
+      entries,
+    }, null, 2);
+    const data = Buffer.from(body, 'utf8');
+    const fileName = `journal-${record.agentId}.json`;
+    const stored = await this.storage.uploadDocument(record.orgId, {
+      fileName, data, contentType: 'application/json',
+      relatedType: 'agent_offboarding', relatedId: record.agentId,
+      metadata: { entries: entries.length }, createdBy: by,
+    });
+    record.journalExport = {
+      storageKey: stored.storageKey, fileName, entries: entries.length,
+      sha256: crypto.createHash('sha256').update(data).digest('hex'),
+    };
+    return { entries: entries.length, size: data.length };
+  }
+
+  private async retire(record: OffboardingRecord, by: string): Promise<Record<string, any>> {
+    const { agentId } = record;
+    await this.stopAgent(agentId, by, 'Agent retired');
+    const managed = this.lifecycle.getAgent(agentId);
+    const mailbox = managed?.config?.emailConfig?.email || managed?.config?.email?.address || null;
+    if (managed) {
+      managed.config.emailConfig = null;
+      managed.config.email = { ...managed.config.email, enabled: false, provider: 'none', autoReply: undefined };
+      await this.lifecycle.saveAgent(agentId);
+    }
+    await this.setState(agentId, 'retired');
+    // Catches keys bound after the revoke step ran
+    const apiKeys = await this.revokeApiKeys(agentId);
+    return { mailbox, apiKeys };
+  }
+
+  // ─── Helpers ──────────────────────────────────────────
+
+  /** Revoke the agent's bound API keys; returns their names */
+  private async revokeApiKeys(agentId: string): Promise<string[]> {
+    const adminDb = this.getAdminDb();
+    if (!adminDb) return [];
+    return (await revokeAgentApiKeys(adminDb, agentId)).map(k => k.name);
+  }
+
+  private async agentSecrets(agentId: string, orgId: string) {
+    const all = await this.vault.getSecretsByOrg(orgId).catch(() => []);
+    return all.filter(e => e.metadata?.agentId === agentId || e.name.startsWith(`agent:${agentId}:`));
+  }
+
+  private async checkSuccessor(agentId: string, successorId: string, orgId: string): Promise<void> {
+    if (successorId === agentId) throw new Error('An agent cannot be its own successor');
+    const successor = await this.getAdminDb()?.getAgent(successorId).catch(() => null);
+    if (!successor) throw new Error('Successor agent not found');
+    if (successor.status !== 'active') throw new Error('Successor agent must be active');
+    const managed = this.lifecycle.getAgent(successorId);
+    if (managed && managed.orgId !== orgId) throw new Error('Successor agent belongs to another organization');
+  }
+
+  private async stopAgent(agentId: string, by: string, reason: string): Promise<void> {
+    const managed = this.lifecycle.getAgent(agentId);
+    if (managed && ['running', 'degraded', 'starting', 'error'].includes(managed.state)) {
+      await this.lifecycle.stop(agentId, by, reason);
+    }
+  }
+
+  private async setState(agentId: string, state: IdentityState): Promise<void> {
+    const adminDb = this.getAdminDb();
+    if (!adminDb) throw new Error('Admin database not available');
+    await adminDb.updateAgent(agentId, { status: state });
+  }
+
+  private async requireRecord(agentId: string): Promise<OffboardingRecord> {
+    const record = await this.getRecord(agentId);
+    if (!record) throw new Error('Agent is not being offboarded');
+    return record;
+  }
+
+  private requireDb(): EngineDatabase {
+    if (!this.db) throw new Error('Engine database not available');
+    return this.db;
+  }
+
+  private async saveRecord(record: OffboardingRecord): Promise<void> {
+    const db = this.requireDb();
+    await db.execute('DELETE FROM engine_settings WHERE key = ?', [SETTINGS_PREFIX + record.agentId]);
+    await db.execute('INSERT INTO engine_settings (key, value) VALUES (?, ?)', [SETTINGS_PREFIX + record.agentId, JSON.stringify(record)]);
+  }
+
+  private async deleteRecord(agentId: string): Promise<void> {
+    await this.db?.execute('DELETE FROM engine_settings WHERE key = ?', [SETTINGS_PREFIX + agentId]);
+  }
+}
+
+function parseJson(v: any) {
+  if (typeof v !== 'string') return v;
+  try { return JSON.parse(v); } catch { return v; }
+}
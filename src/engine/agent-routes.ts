@@ -601,7 +601,7 @@ export function createAgentRoutes(opts: {
    * Returns both IDs and the full agent object.
    */
   router.post('/bridge/agents', async (c) => {
-    const { orgId, name, email, displayName, role, model, deployment, permissionProfile, presetName, createdBy, persona, permissions: permissionsData, skills, knowledgeBases, description, soulId, deployTarget } = await c.req.json();
+    const { orgId, name, email, displayName, role, model, deployment, permissionProfile, presetName, createdBy, persona, permissions: permissionsData, skills, knowledgeBases, description, soulId, deployTarget, dataRegion } = await c.req.json();
 
     if (!name || !orgId) {
       return c.json({ error: 'name and orgId are required' }, 400);
@@ -650,6 +650,7 @@ export function createAgentRoutes(opts: {
         config: { docker: { image: 'agenticmail/agent', tag: 'latest', ports: [3000], env: {}, volumes: [], restart: 'unless-stopped' } },
       },
       permissionProfileId: permissionProfile || 'default',
+      dataRegion: dataRegion || undefined,
     };
 
     // Apply permissions: start from preset if specified, then overlay granular settings
@@ -2675,7 +2676,7 @@ export function createAgentRoutes(opts: {
       }
       const r = await opts.engineDb.query(
         `SELECT id, direction, sender_name as "senderName", message_text as text, 
-                message_id as "messageId", created_at as "timestamp"
+                message_id as "messageId", metadata, created_at as "timestamp"
          FROM messaging_history 
          WHERE agent_id = $1 AND platform = 'whatsapp' AND contact_id = $2${whereExtra}
          ORDER BY created_at DESC 
@@ -6,6 +6,8 @@
  *
  * Status flow:
  *   offline → idle → working (with activity detail) → idle → offline
+ *   An agent that stops heartbeating without being stopped goes to `error`
+ *   (crashed) with `statusReason` 'heartbeat_lost'.
  *
  * Activities tracked:
  *   - Session starts/ends (chat, email, meeting, task)
@@ -36,6 +38,7 @@ export interface AgentStatusSnapshot {
   lastActivity: string | null;    // Last time agent did anything
   uptimeMs: number | null;        // How long since agent came online
   onlineSince: string | null;
+  statusReason: string | null;    // Why it is in `error` (e.g. 'crashed', 'heartbeat_lost')
 }
 
 type StatusListener = (agentId: string, snapshot: AgentStatusSnapshot) => void;
@@ -184,6 +187,7 @@ export class AgentStatusTracker {
   markOffline(agentId: string, reason?: string): void {
     const snap = this.getOrCreate(agentId);
     snap.status = reason ? 'error' : 'offline';
+    snap.statusReason = reason || null;
     snap.currentActivity = null;
     snap.activeSessions = 0;
     snap.onlineSince = null;
@@ -232,6 +236,7 @@ export class AgentStatusTracker {
         lastActivity: null,
         uptimeMs: null,
         onlineSince: null,
+        statusReason: null,
       };
       this.statuses.set(agentId, snap);
     }
@@ -239,6 +244,7 @@ export class AgentStatusTracker {
   }
 
   private emit(agentId: string, snapshot: AgentStatusSnapshot): void {
+    if (snapshot.status !== 'error') snapshot.statusReason = null;
     this.dirtyAgents.add(agentId);
     for (const listener of this.listeners) {
       try { listener(agentId, snapshot); } catch { /* don't let listener errors break us */ }
@@ -294,8 +300,9 @@ export class AgentStatusTracker {
       if (snap.lastHeartbeat) {
         const elapsed = now - new Date(snap.lastHeartbeat).getTime();
         if (elapsed > this.staleThresholdMs) {
-          // Agent hasn't sent a heartbeat — mark as offline
-          snap.status = 'offline';
+          // Went quiet without being stopped — treat as crashed
+          snap.status = 'error';
+          snap.statusReason = 'heartbeat_lost';
           snap.currentActivity = null;
           snap.activeSessions = 0;
           snap.onlineSince = null;
@@ -0,0 +1,117 @@
+/**
+ * Agent Webhook Routes
+ * Mounted at /agent-webhooks/* on the engine sub-app.
+ *
+ * Anyone who can see an agent can see its webhooks and delivery log
+ * (members and viewers in a team only their teams' agents); registering,
+ * editing, testing and redelivering is for admins. Signing secrets are
+ * only returned when a webhook is created or its secret is rotated.
+ */
+
+import { Hono } from 'hono';
+import { WEBHOOK_EVENTS, type AgentWebhookManager } from './agent-webhooks.js';
+import type { AgentLifecycleManager } from './lifecycle.js';
+import type { DatabaseAdapter } from '../db/adapter.js';
+import { auditFromEngine } from './route-audit.js';
+import { isAdminCaller } from './caller-role.js';
+
+export function createAgentWebhookRoutes(webhooks: AgentWebhookManager, lifecycle: AgentLifecycleManager, deps: {
+  getAdminDb?: () => DatabaseAdapter | null;
+  /** Agents the caller may see, or null when they aren't limited to their teams */
+  visibleAgentIds?: (c: any) => Promise<Set<string> | null>;
+} = {}) {
+  const router = new Hono();
+
+  const audit = auditFromEngine(deps.getAdminDb);
+
+  router.get('/agents/:agentId', async (c) => {
+    try {
+      return c.json({ webhooks: await webhooks.list(c.req.param('agentId')), events: WEBHOOK_EVENTS });
+    } catch (e: any) { return c.json({ error: e.message }, 500); }
+  });
+
+  // { url, events, description? }
+  router.post('/agents/:agentId', async (c) => {
+    if (!isAdminCaller(c)) return c.json({ error: 'Only admins can add webhooks' }, 403);
+    const agent = lifecycle.getAgent(c.req.param('agentId'));
+    if (!agent) return c.json({ error: 'Agent not found' }, 404);
+    const body = await c.req.json().catch(() => ({}));
+    try {
+      const { webhook, secret } = await webhooks.create({ ...body, orgId: agent.orgId, agentId: agent.id, createdBy: c.req.header('X-User-Id') });
+      audit(c, 'agent.webhook.create', `agent:${agent.id}`, { webhookId: webhook.id, url: webhook.url, events: webhook.events }, agent.orgId);
+      return c.json({ webhook, secret }, 201);
+    } catch (e: any) { return c.json({ error: e.message }, 400); }
+  });
+
+  // ?webhookId=&status=&limit=&offset=
+  router.get('/agents/:agentId/deliveries', async (c) => {
+    try {
+      return c.json(await webhooks.listDeliveries({
+        agentId: c.req.param('agentId'),
+        webhookId: c.req.query('webhookId') || undefined,
+        status: c.req.query('status') || undefined,
+        limit: parseInt(c.req.query('limit') || '50'),
+        offset: parseInt(c.req.query('offset') || '0'),
+      }));
+    } catch (e: any) { return c.json({ error: e.message }, 500); }
+  });
+
+  router.get('/deliveries/:id', async (c) => {
+    const delivery = await webhooks.getDelivery(c.req.param('id'));
+    const visible = delivery && deps.visibleAgentIds ? await deps.visibleAgentIds(c) : null;
+    if (!delivery || (visible && !visible.has(delivery.agentId))) return c.json({ error: 'Delivery not found' }, 404);
+    return c.json({ delivery });
+  });
+
+  router.post('/deliveries/:id/redeliver', async (c) => {
+    if (!isAdminCaller(c)) return c.json({ error: 'Only admins can redeliver webhooks' }, 403);
+    try {
+      const delivery = await webhooks.redeliver(c.req.param('id'));
+      audit(c, 'agent.webhook.redeliver', `agent:${delivery.agentId}`, { webhookId: delivery.webhookId, deliveryId: c.req.param('id'), status: delivery.status });
+      return c.json({ delivery });
+    } catch (e: any) { return c.json({ error: e.message }, e.message.endsWith('not found') ? 404 : 400); }
+  });
+
+  // { url?, events?, description?, enabled? }
+  router.patch('/:id', async (c) => {
+    if (!isAdminCaller(c)) return c.json({ error: 'Only admins can edit webhooks' }, 403);
+    const body = await c.req.json().catch(() => ({}));
+    try {
+      const webhook = await webhooks.update(c.req.param('id'), body);
+      audit(c, 'agent.webhook.update', `agent:${webhook.agentId}`, { webhookId: webhook.id, fields: Object.keys(body), url: webhook.url, enabled: webhook.enabled }, webhook.orgId);
+      return c.json({ webhook });
+    } catch (e: any) { return c.json({ error: e.message }, e.message === 'Webhook not found' ? 404 : 400); }
+  });
+
+  router.post('/:id/rotate-secret', async (c) => {
+    if (!isAdminCaller(c)) return c.json({ error: 'Only admins can rotate webhook secrets' }, 403);
+    const existing = await webhooks.get(c.req.param('id'));
+    if (!existing) return c.json({ error: 'Webhook not found' }, 404);
+    try {
+      const secret = await webhooks.rotateSecret(existing.id);
+      audit(c, 'agent.webhook.rotate_secret', `agent:${existing.agentId}`, { webhookId: existing.id }, existing.orgId);
+      return c.json({ secret });
+    } catch (e: any) { return c.json({ error: e.message }, 500); }
+  });
+
+  // Sends a ping and waits for the first attempt
+  router.post('/:id/test', async (c) => {
+    if (!isAdminCaller(c)) return c.json({ error: 'Only admins can test webhooks' }, 403);
+    try {
+      return c.json({ delivery: await webhooks.test(c.req.param('id')) });
+    } catch (e: any) { return c.json({ error: e.message }, e.message === 'Webhook not found' ? 404 : 400); }
+  });
+
+  router.delete('/:id', async (c) => {
+    if (!isAdminCaller(c)) return c.json({ error: 'Only admins can delete webhooks' }, 403);
+    const existing = await webhooks.get(c.req.param('id'));
+    if (!existing) return c.json({ error: 'Webhook not found' }, 404);
+    try {
+      await webhooks.delete(existing.id);
+      audit(c, 'agent.webhook.delete', `agent:${existing.agentId}`, { webhookId: existing.id, url: existing.url }, existing.orgId);
+      return c.json({ success: true });
+    } catch (e: any) { return c.json({ error: e.message }, 500); }
+  });
+
+  return router;
+}
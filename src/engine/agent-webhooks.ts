@@ -0,0 +1,412 @@
+/**
+ * Agent Webhooks — per-agent HTTP callbacks for notable events
+ *
+ * Admins register URLs on an agent and choose which events they want:
+ *   - message.received  — a new inbound email, chat or messaging message
+ *   - agent.error       — the agent crashed or reported an error
+ *   - agent.intervention — the agent was paused, resumed, killed or flagged
+ *
+ * Every request is signed with the webhook's own secret so receivers can
+ * check it came from us: the X-AgenticMail-Signature header carries
+ * `t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">`. Failed attempts
+ * are retried a few times, and every delivery is kept in a log for 30 days
+ * so it can be inspected and redelivered from the dashboard.
+ *
+ * A webhook that keeps failing is switched off rather than retried forever.
+ * Target URLs go through the same SSRF guard as agent web tools.
+ */
+
+import { createHmac, randomBytes } from 'node:crypto';
+import type { EngineDatabase } from './db-adapter.js';
+import { createSsrfGuard } from '../agent-tools/security.js';
+
+// ─── Types ──────────────────────────────────────────────
+
+export const WEBHOOK_EVENTS = ['message.received', 'agent.error', 'agent.intervention'] as const;
+export type WebhookEvent = typeof WEBHOOK_EVENTS[number];
+
+export interface AgentWebhook {
+  id: string;
+  orgId: string;
+  agentId: string;
+  url: string;
+  description?: string;
+  events: WebhookEvent[];
+  enabled: boolean;
+  /** Deliveries that failed in a row; reset by any success */
+  consecutiveFailures: number;
+  lastDeliveryAt?: string;
+  lastStatus?: 'success' | 'failed';
+  createdBy?: string;
+  createdAt: string;
+  updatedAt: string;
+}
+
+export interface WebhookDelivery {
+  id: string;
+  webhookId: string;
+  agentId: string;
+  /** An event name, or 'ping' for test deliveries */
+  event: string;
+  payload: any;
+  status: 'pending' | 'success' | 'failed';
+  attempts: number;
+  responseCode?: number;
+  responseBody?: string;
+  error?: string;
+  durationMs?: number;
+  createdAt: string;
+  completedAt?: string;
+}
+
+// ─── Config ─────────────────────────────────────────────
+
+const MAX_WEBHOOKS_PER_AGENT = 10;
+const MAX_DESCRIPTION_CHARS = 500;
+const REQUEST_TIMEOUT_MS = 10_000;
+/** Delay before each retry; the first attempt is immediate */
+const RETRY_DELAYS_MS = [10_000, 60_000];
+const MAX_CONSECUTIVE_FAILURES = 20;
+const MAX_RESPONSE_BODY_CHARS = 1000;
+const DELIVERY_RETENTION_DAYS = 30;
+const PRUNE_INTERVAL_MS = 60 * 60 * 1000;
+
+const ssrf = createSsrfGuard();
+
+/** The value receivers compare against X-AgenticMail-Signature. */
+export function signPayload(secret: string, timestamp: number, body: string): string {
+  return `t=${timestamp},v1=${createHmac('sha256', secret).update(`${timestamp}.${body}`).digest('hex')}`;
+}
+
+// ─── Webhook Manager ────────────────────────────────────
+
+export class AgentWebhookManager {
+  private engineDb?: EngineDatabase;
+  private lastPruneAt = 0;
+
+  async setDb(db: EngineDatabase): Promise<void> {
+    this.engineDb = db;
+  }
+
+  private get db(): EngineDatabase {
+    if (!this.engineDb) throw new Error('Webhook database not initialized');
+    return this.engineDb;
+  }
+
+  async list(agentId: string): Promise<AgentWebhook[]> {
+    if (!this.engineDb) return [];
+    const rows = await this.engineDb.query<any>('SELECT * FROM agent_webhooks WHERE agent_id = ? ORDER BY created_at', [agentId]);
+    return rows.map((r: any) => this.rowToWebhook(r));
+  }
+
+  async get(id: string): Promise<AgentWebhook | undefined> {
+    if (!this.engineDb) return undefined;
+    const row = await this.engineDb.get<any>('SELECT * FROM agent_webhooks WHERE id = ?', [id]);
+    return row ? this.rowToWebhook(row) : undefined;
+  }
+
+  /** Returns the new webhook and its signing secret, which is only shown this once. */
+  async create(input: { orgId: string; agentId: string; url: string; description?: string; events: string[]; createdBy?: string }): Promise<{ webhook: AgentWebhook; secret: string }> {
+    const url = await validateTarget(input.url);
+    const events = normalizeEvents(input.events);
+    if ((await this.list(input.agentId)).length >= MAX_WEBHOOKS_PER_AGENT) throw new Error(`An agent can have at most ${MAX_WEBHOOKS_PER_AGENT} webhooks`);
+    const now = new Date().toISOString();
+    const secret = newSecret();
+    const webhook: AgentWebhook = {
+      id: crypto.randomUUID(),
+      orgId: input.orgId,
+      agentId: input.agentId,
+      url,
+      description: input.description?.trim().slice(0, MAX_DESCRIPTION_CHARS) || undefined,
+      events,
+      enabled: true,
+      consecutiveFailures: 0,
+      createdBy: input.createdBy,
+      createdAt: now,
+      updatedAt: now,
+    };
+    await this.db.execute(
+      'INSERT INTO agent_webhooks (id, org_id, agent_id, url, description, events, secret, enabled, consecutive_failures, created_by, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)',
+      [webhook.id, webhook.orgId, webhook.agentId, webhook.url, webhook.description || null, JSON.stringify(events), secret, 1, 0, webhook.createdBy || null, now, now]
+    );
+    return { webhook, secret };
+  }
+
+  /** Re-enabling a webhook also clears its failure count. */
+  async update(id: string, patch: { url?: string; description?: string; events?: string[]; enabled?: boolean }): Promise<AgentWebhook> {
+    const existing = await this.get(id);
+    if (!existing) throw new Error('Webhook not found');
+    const next: AgentWebhook = {
+      ...existing,
+      url: patch.url !== undefined ? await validateTarget(patch.url) : existing.url,
+      description: patch.description !== undefined ? patch.description.trim().slice(0, MAX_DESCRIPTION_CHARS) || undefined : existing.description,
+      events: patch.events !== undefined ? normalizeEvents(patch.events) : existing.events,
+      enabled: patch.enabled !== undefined ? !!patch.enabled : existing.enabled,
+      updatedAt: new Date().toISOString(),
+    };
+    if (next.enabled && !existing.enabled) next.consecutiveFailures = 0;
+    await this.db.execute(
+      'UPDATE agent_webhooks SET url = ?, description = ?, events = ?, enabled = ?, consecutive_failures = ?, updated_at = ? WHERE id = ?',
+      [next.url, next.description || null, JSON.stringify(next.events), next.enabled ? 1 : 0, next.consecutiveFailures, next.updatedAt, id]
+    );
+    return next;
+  }
+
+  /** Replace the signing secret. The old one stops working immediately. */
+  async rotateSecret(id: string): Promise<string> {
+    const secret = newSecret();
+    await this.db.execute('UPDATE agent_webhooks SET secret = ?, updated_at = ? WHERE id = ?', [secret, new Date().toISOString(), id]);
+    return secret;
+  }
+
+  async delete(id: string): Promise<void> {
+    await this.db.execute('DELETE FROM agent_webhook_deliveries WHERE webhook_id = ?', [id]);
+    await this.db.execute('DELETE FROM agent_webhooks WHERE id = ?', [id]);
+  }
+
+  /** Drop a deleted agent's webhooks and their log. */
+  async removeAgent(agentId: string): Promise<void> {
+    if (!this.engineDb) return;
+    await this.engineDb.execute('DELETE FROM agent_webhook_deliveries WHERE agent_id = ?', [agentId]);
+    await this.engineDb.execute('DELETE FROM agent_webhooks WHERE agent_id = ?', [agentId]);
+  }
+
+  // ─── Delivery ───────────────────────────────────────
+
+  /**
+   * Send an event to every enabled webhook on the agent that subscribes to it.
+   * Returns once the deliveries are queued; sending happens in the background.
+   */
+  async emit(agent: { id: string; orgId: string; name?: string }, event: WebhookEvent, data: Record<string, any>): Promise<void> {
+    if (!this.engineDb) return;
+    const hooks = (await this.list(agent.id)).filter(w => w.enabled && w.events.includes(event));
+    if (hooks.length === 0) return;
+    const payload = {
+      id: `evt_${crypto.randomUUID()}`,
+      event,
+      createdAt: new Date().toISOString(),
+      orgId: agent.orgId,
+      agent: { id: agent.id, name: agent.name },
+      data,
+    };
+    for (const hook of hooks) {
+      this.queue(hook, event, payload).catch((err) => console.error(`[webhooks] Failed to queue ${event} for ${hook.id}:`, err.message));
+    }
+  }
+
+  /** Send a ping to check the endpoint; waits for the first attempt and doesn't retry. */
+  async test(id: string): Promise<WebhookDelivery> {
+    const hook = await this.get(id);
+    if (!hook) throw new Error('Webhook not found');
+    const payload = {
+      id: `evt_${crypto.randomUUID()}`,
+      event: 'ping',
+      createdAt: new Date().toISOString(),
+      orgId: hook.orgId,
+      agent: { id: hook.agentId },
+      data: { message: 'Test delivery from AgenticMail' },
+    };
+    return this.queue(hook, 'ping', payload, { retry: false });
+  }
+
+  /** Send a logged delivery's payload again, as a new delivery with the same event id. */
+  async redeliver(deliveryId: string): Promise<WebhookDelivery> {
+    const original = await this.getDelivery(deliveryId);
+    if (!original) throw new Error('Delivery not found');
+    const hook = await this.get(original.webhookId);
+    if (!hook) throw new Error('Webhook not found');
+    return this.queue(hook, original.event, original.payload, { retry: false });
+  }
+
+  async listDeliveries(opts: { agentId: string; webhookId?: string; status?: string; limit?: number; offset?: number }): Promise<{ deliveries: WebhookDelivery[]; total: number }> {
+    if (!this.engineDb) return { deliveries: [], total: 0 };
+    const where = ['agent_id = ?'];
+    const params: any[] = [opts.agentId];
+    if (opts.webhookId) { where.push('webhook_id = ?'); params.push(opts.webhookId); }
+    if (opts.status) { where.push('status = ?'); params.push(opts.status); }
+    const limit = Math.min(Math.max(opts.limit || 50, 1), 200);
+    const offset = Math.max(opts.offset || 0, 0);
+    const rows = await this.engineDb.query<any>(
+      `SELECT * FROM agent_webhook_deliveries WHERE ${where.join(' AND ')} ORDER BY created_at DESC LIMIT ${limit} OFFSET ${offset}`,
+      params,
+    );
+    const count = await this.engineDb.get<any>(`SELECT COUNT(*) as n FROM agent_webhook_deliveries WHERE ${where.join(' AND ')}`, params);
+    return { deliveries: rows.map((r: any) => this.rowToDelivery(r)), total: Number(count?.n || 0) };
+  }
+
+  async getDelivery(id: string): Promise<WebhookDelivery | undefined> {
+    if (!this.engineDb) return undefined;
+    const row = await this.engineDb.get<any>('SELECT * FROM agent_webhook_deliveries WHERE id = ?', [id]);
+    return row ? this.rowToDelivery(row) : undefined;
+  }
+
+  // ─── Private ────────────────────────────────────────
+
+  // Logs the delivery, makes the first attempt, and schedules retries if it fails
+  private async queue(hook: AgentWebhook, event: string, payload: any, opts: { retry?: boolean } = {}): Promise<WebhookDelivery> {
+    const delivery: WebhookDelivery = {
+      id: crypto.randomUUID(),
+      webhookId: hook.id,
+      agentId: hook.agentId,
+      event,
+      payload,
+      status: 'pending',
+      attempts: 0,
+      createdAt: new Date().toISOString(),
+    };
+    await this.db.execute(
+      'INSERT INTO agent_webhook_deliveries (id, webhook_id, agent_id, event, payload, status, attempts, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)',
+      [delivery.id, delivery.webhookId, delivery.agentId, event, JSON.stringify(payload), 'pending', 0, delivery.createdAt]
+    );
+    this.prune();
+    await this.attempt(delivery, opts.retry !== false);
+    return delivery;
+  }
+
+  private async attempt(delivery: WebhookDelivery, retry: boolean): Promise<void> {
+    // Read fresh so a rotated secret, new URL or disable takes effect on retries
+    const row = await this.db.get<any>('SELECT * FROM agent_webhooks WHERE id = ?', [delivery.webhookId]);
+    if (!row) {
+      await this.finish(delivery, { status: 'failed', error: 'Webhook was deleted' });
+      return;
+    }
+    if (!row.enabled) {
+      await this.finish(delivery, { status: 'failed', error: 'Webhook is disabled' });
+      return;
+    }
+    delivery.attempts++;
+    const body = JSON.stringify(delivery.payload);
+    const timestamp = Math.floor(Date.now() / 1000);
+    const started = Date.now();
+    let result: Partial<WebhookDelivery>;
+    try {
+      await ssrf.validateUrl(row.url);
+      const res = await fetch(row.url, {
+        method: 'POST',
+        redirect: 'manual',
+        signal: AbortSignal.timeout(REQUEST_TIMEOUT_MS),
+        headers: {
+          'Content-Type': 'application/json',
+          'User-Agent': 'AgenticMail-Webhooks/1.0',
+          'X-AgenticMail-Event': delivery.event,
+          'X-AgenticMail-Delivery': delivery.id,
+          'X-AgenticMail-Signature': signPayload(row.secret, timestamp, body),
+        },
+        body,
+      });
+      const text = (await res.text().catch(() => '')).slice(0, MAX_RESPONSE_BODY_CHARS);
+      result = res.ok
+        ? { status: 'success', responseCode: res.status, responseBody: text }
+        : { status: 'failed', responseCode: res.status, responseBody: text, error: `HTTP ${res.status}` };
+    } catch (err: any) {
+      result = { status: 'failed', error: err.name === 'TimeoutError' ? `Timed out after ${REQUEST_TIMEOUT_MS / 1000}s` : err.message };
+    }
+    result.durationMs = Date.now() - started;
+
+    const retryIn = RETRY_DELAYS_MS[delivery.attempts - 1];
+    if (result.status === 'failed' && retry && retryIn !== undefined) {
+      Object.assign(delivery, result, { status: 'pending' });
+      await this.db.execute(
+        'UPDATE agent_webhook_deliveries SET attempts = ?, response_code = ?, response_body = ?, error = ?, duration_ms = ? WHERE id = ?',
+        [delivery.attempts, delivery.responseCode ?? null, delivery.responseBody ?? null, delivery.error ?? null, delivery.durationMs, delivery.id]
+      );
+      setTimeout(() => { this.attempt(delivery, retry).catch(() => {}); }, retryIn).unref?.();
+      return;
+    }
+    await this.finish(delivery, result);
+    // Test pings don't count towards switching a failing webhook off
+    if (delivery.event !== 'ping') await this.recordOutcome(delivery.webhookId, result.status === 'success');
+  }
+
+  private async finish(delivery: WebhookDelivery, result: Partial<WebhookDelivery>): Promise<void> {
+    Object.assign(delivery, result, { completedAt: new Date().toISOString() });
+    await this.db.execute(
+      'UPDATE agent_webhook_deliveries SET status = ?, attempts = ?, response_code = ?, response_body = ?, error = ?, duration_ms = ?, completed_at = ? WHERE id = ?',
+      [delivery.status, delivery.attempts, delivery.responseCode ?? null, delivery.responseBody ?? null, delivery.error ?? null, delivery.durationMs ?? null, delivery.completedAt, delivery.id]
+    );
+  }
+
+  private async recordOutcome(webhookId: string, ok: boolean): Promise<void> {
+    const now = new Date().toISOString();
+    if (ok) {
+      await this.db.execute('UPDATE agent_webhooks SET consecutive_failures = 0, last_delivery_at = ?, last_status = ? WHERE id = ?', [now, 'success', webhookId]);
+      return;
+    }
+    await this.db.execute('UPDATE agent_webhooks SET consecutive_failures = consecutive_failures + 1, last_delivery_at = ?, last_status = ? WHERE id = ?', [now, 'failed', webhookId]);
+    const row = await this.db.get<any>('SELECT consecutive_failures FROM agent_webhooks WHERE id = ?', [webhookId]);
+    if (Number(row?.consecutive_failures) >= MAX_CONSECUTIVE_FAILURES) {
+      await this.db.execute('UPDATE agent_webhooks SET enabled = ?, updated_at = ? WHERE id = ?', [0, now, webhookId]);
+      console.warn(`[webhooks] Disabled webhook ${webhookId} after ${MAX_CONSECUTIVE_FAILURES} failed deliveries in a row`);
+    }
+  }
+
+  // At most hourly, piggybacking on new deliveries
+  private prune(): void {
+    if (Date.now() - this.lastPruneAt < PRUNE_INTERVAL_MS) return;
+    this.lastPruneAt = Date.now();
+    const cutoff = new Date(Date.now() - DELIVERY_RETENTION_DAYS * 86_400_000).toISOString();
+    this.engineDb?.execute('DELETE FROM agent_webhook_deliveries WHERE created_at < ?', [cutoff])
+      .catch((err) => console.error('[webhooks] Failed to prune deliveries:', err.message));
+  }
+
+  private rowToWebhook(r: any): AgentWebhook {
+    return {
+      id: r.id,
+      orgId: r.org_id,
+      agentId: r.agent_id,
+      url: r.url,
+      description: r.description || undefined,
+      events: safeJson(r.events, []),
+      enabled: !!r.enabled,
+      consecutiveFailures: Number(r.consecutive_failures || 0),
+      lastDeliveryAt: r.last_delivery_at || undefined,
+      lastStatus: r.last_status || undefined,
+      createdBy: r.created_by || undefined,
+      createdAt: r.created_at,
+      updatedAt: r.updated_at,
+    };
+  }
+
+  private rowToDelivery(r: any): WebhookDelivery {
+    return {
+      id: r.id,
+      webhookId: r.webhook_id,
+      agentId: r.agent_id,
+      event: r.event,
+      payload: safeJson(r.payload, {}),
+      status: r.status,
+      attempts: Number(r.attempts || 0),
+      responseCode: r.response_code ?? undefined,
+      responseBody: r.response_body ?? undefined,
+      error: r.error || undefined,
+      durationMs: r.duration_ms ?? undefined,
+      createdAt: r.created_at,
+      completedAt: r.completed_at || undefined,
+    };
+  }
+}
+
+function newSecret(): string {
+  return `whsec_${randomBytes(24).toString('hex')}`;
+}
+
+async function validateTarget(url: string): Promise<string> {
+  const value = (url || '').trim();
+  if (!value) throw new Error('A URL is required');
+  let parsed: URL;
+  try { parsed = new URL(value); } catch { throw new Error('Invalid URL'); }
+  if (parsed.protocol !== 'https:' && parsed.protocol !== 'http:') throw new Error('Webhook URLs must use http or https');
+  await ssrf.validateUrl(value);
+  return value;
+}
+
+function normalizeEvents(events: string[]): WebhookEvent[] {
+  const list = Array.from(new Set((events || []).filter((e): e is WebhookEvent => (WEBHOOK_EVENTS as readonly string[]).includes(e))));
+  if (list.length === 0) throw new Error(`Choose at least one event: ${WEBHOOK_EVENTS.join(', ')}`);
+  return list;
+}
+
+function safeJson(value: any, fallback: any): any {
+  if (value && typeof value === 'object') return value;
+  try { return JSON.parse(value); } catch { return fallback; }
+}
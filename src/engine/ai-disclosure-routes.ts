@@ -0,0 +1,74 @@
+/**
+ * AI Disclosure Routes
+ * Mounted at /disclosure/* on the engine sub-app.
+ */
+
+import { Hono } from 'hono';
+import type { AIDisclosureManager, ConsentStatus } from './ai-disclosure.js';
+import type { DatabaseAdapter } from '../db/adapter.js';
+import { auditFromEngine } from './route-audit.js';
+
+export function createAIDisclosureRoutes(disclosure: AIDisclosureManager, deps: { getAdminDb?: () => DatabaseAdapter | null } = {}) {
+  const router = new Hono();
+
+  const audit = auditFromEngine(deps.getAdminDb);
+
+  router.get('/config', (c) => c.json({ config: disclosure.getConfig() }));
+
+  // { footerEnabled, consentEnabled, internalDomains, templates }
+  router.put('/config', async (c) => {
+    const body = await c.req.json().catch(() => ({}));
+    try {
+      const before = disclosure.getConfig();
+      const config = await disclosure.saveConfig(body);
+      audit(c, 'disclosure.config_update', 'ai_disclosure', {
+        footerEnabled: config.footerEnabled, consentEnabled: config.consentEnabled, templates: config.templates.map(t => t.id),
+        previous: { footerEnabled: before.footerEnabled, consentEnabled: before.consentEnabled, templates: before.templates.map(t => t.id) },
+      });
+      return c.json({ success: true, config });
+    } catch (e: any) { return c.json({ error: e.message }, 500); }
+  });
+
+  // Which template a recipient gets, rendered for an agent: ?recipient=&agentId=
+  router.get('/preview', (c) => {
+    const template = disclosure.templateFor(c.req.query('recipient') || null);
+    const agentId = c.req.query('agentId') || '';
+    return c.json({ template: template.id, footer: disclosure.render(template.footer, agentId), consent: disclosure.render(template.consent, agentId) });
+  });
+
+  // When disclosures were applied: ?orgId=&agentId=&kind=&since=&limit=&offset=
+  router.get('/log', async (c) => {
+    try {
+      return c.json(await disclosure.listLog(c.req.query('orgId') || 'default', {
+        agentId: c.req.query('agentId') || undefined,
+        kind: c.req.query('kind') || undefined,
+        since: c.req.query('since') || undefined,
+        limit: parseInt(c.req.query('limit') || '50'),
+        offset: parseInt(c.req.query('offset') || '0'),
+      }));
+    } catch (e: any) { return c.json({ error: e.message }, 500); }
+  });
+
+  router.get('/consents', async (c) => {
+    try {
+      const consents = await disclosure.listConsents(c.req.query('orgId') || 'default', (c.req.query('status') as ConsentStatus) || undefined);
+      return c.json({ consents, total: consents.length });
+    } catch (e: any) { return c.json({ error: e.message }, 500); }
+  });
+
+  // { orgId, contact, status, note } — records a contact's answer
+  router.put('/consents', async (c) => {
+    const body = await c.req.json().catch(() => ({}));
+    const orgId = body.orgId || 'default';
+    try {
+      const consent = await disclosure.recordConsent(orgId, String(body.contact || ''), body.status, {
+        by: c.req.header('X-User-Id') || 'dashboard',
+        note: body.note !== undefined ? String(body.note) : undefined,
+      });
+      audit(c, 'disclosure.consent_' + consent.status, `contact:${consent.contact}`, { jurisdiction: consent.jurisdiction, note: consent.note }, orgId);
+      return c.json({ success: true, consent });
+    } catch (e: any) { return c.json({ error: e.message }, 400); }
+  });
+
+  return router;
+}
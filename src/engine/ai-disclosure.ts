@@ -0,0 +1,337 @@
+/**
+ * AI Disclosure — Automatic disclosure footers and conversation consent
+ *
+ * When enabled, every outbound email an agent sends through a mail tool
+ * (AgenticMail, Gmail, Outlook) gets a footer saying it was written by an
+ * AI agent. The wording comes from a per-jurisdiction template, picked by
+ * the recipient's domain (".de" → EU template) with the default template
+ * as fallback, so the EU AI Act, California's BOT Act and the like can each
+ * get their own text.
+ *
+ * Conversations are tracked (logged, summarized, searchable), and some
+ * jurisdictions want the other party's consent for that. With consent
+ * capture on, the first email to a contact without a consent record also
+ * carries the template's consent notice, and the contact is recorded as
+ * "requested" until an admin (or an integration) records their answer.
+ *
+ * Every footer and consent notice applied is logged so compliance can show
+ * when each disclosure went out. Config lives in engine_settings under
+ * 'ai_disclosure'.
+ */
+
+import type { EngineDatabase } from './db-adapter.js';
+
+// ─── Types ──────────────────────────────────────────────
+
+export interface DisclosureTemplate {
+  id: string;
+  label: string;
+  /** Recipient domain suffixes that select this template: ".de", "example.co.uk" */
+  domains: string[];
+  /** Appended to outbound email. {{agentName}} and {{agentEmail}} are filled in. */
+  footer: string;
+  /** Added to the first email to a contact when consent capture is on */
+  consent: string;
+}
+
+export interface DisclosureConfig {
+  footerEnabled: boolean;
+  consentEnabled: boolean;
+  /** No footer or consent notice on mail to these domains (colleagues already know) */
+  internalDomains: string[];
+  templates: DisclosureTemplate[];
+}
+
+export type ConsentStatus = 'requested' | 'granted' | 'declined' | 'withdrawn';
+
+export const CONSENT_STATUSES: ConsentStatus[] = ['requested', 'granted', 'declined', 'withdrawn'];
+
+export interface ConversationConsent {
+  id: string;
+  orgId: string;
+  contact: string;
+  jurisdiction: string;
+  status: ConsentStatus;
+  /** Agent whose email asked for it */
+  agentId?: string;
+  requestedAt?: string;
+  decidedAt?: string;
+  decidedBy?: string;
+  note?: string;
+}
+
+export interface DisclosureLogEntry {
+  id: string;
+  orgId: string;
+  agentId: string;
+  tool: string;
+  recipient: string | null;
+  jurisdiction: string;
+  kind: 'footer' | 'consent';
+  appliedAt: string;
+}
+
+// ─── Config ─────────────────────────────────────────────
+
+const SETTINGS_KEY = 'ai_disclosure';
+
+const EU_DOMAINS = ['.eu', '.at', '.be', '.bg', '.cy', '.cz', '.de', '.dk', '.ee', '.es', '.fi', '.fr', '.gr', '.hr', '.hu', '.ie', '.it', '.lt', '.lu', '.lv', '.mt', '.nl', '.pl', '.pt', '.ro', '.se', '.si', '.sk'];
+
+export const DEFAULT_TEMPLATES: DisclosureTemplate[] = [
+  {
+    id: 'default', label: 'Default', domains: [],
+    footer: 'This message was written and sent by {{agentName}}, an AI agent. Reply to reach a human.',
+    consent: 'Conversations with {{agentName}} are logged to improve service. Reply "I consent" to continue, or "no" and a person will follow up instead.',
+  },
+  {
+    id: 'eu', label: 'European Union (AI Act)', domains: EU_DOMAINS,
+    footer: 'You are communicating with an AI system. This message was generated by {{agentName}}, an AI agent, in line with Article 50 of the EU AI Act.',
+    consent: 'To process this conversation we keep a record of it (GDPR Art. 6(1)(a)). Reply "I consent" to agree. You can withdraw consent at any time by replying "withdraw".',
+  },
+  {
+    id: 'us-ca', label: 'California (BOT Act)', domains: [],
+    footer: 'Disclosure: {{agentName}} is an automated AI agent, not a person (Cal. Bus. & Prof. Code § 17941).',
+    consent: 'This conversation may be recorded and reviewed. Reply "I consent" to continue.',
+  },
+];
+
+function cleanDomains(list: any): string[] {
+  return Array.isArray(list)
+    ? Array.from(new Set(list.map((d: any) => String(d).trim().toLowerCase().replace(/^@/, '')).filter(Boolean))) as string[]
+    : [];
+}
+
+export function normalizeDisclosureConfig(raw: any): DisclosureConfig {
+  const templates: DisclosureTemplate[] = [];
+  for (const t of Array.isArray(raw?.templates) ? raw.templates : DEFAULT_TEMPLATES) {
+    const id = String(t?.id || '').trim().toLowerCase();
+    if (!/^[a-z0-9-]{1,32}$/.test(id) || templates.some(x => x.id === id)) continue;
+    templates.push({
+      id,
+      label: String(t.label || id).slice(0, 80),
+      domains: cleanDomains(t.domains),
+      footer: String(t.footer || '').slice(0, 2000),
+      consent: String(t.consent || '').slice(0, 2000),
+    });
+  }
+  // Everything falls back to 'default', so there always is one
+  if (!templates.some(t => t.id === 'default')) templates.unshift({ ...DEFAULT_TEMPLATES[0] });
+  return {
+    footerEnabled: raw?.footerEnabled === true,
+    consentEnabled: raw?.consentEnabled === true,
+    internalDomains: cleanDomains(raw?.internalDomains),
+    templates,
+  };
+}
+
+// ─── Mail tools ─────────────────────────────────────────
+
+/** Body parameters of each outbound mail tool, and which one holds the recipients */
+const MAIL_TOOLS: Record<string, { body: string[]; to?: string }> = {
+  agenticmail_send: { body: ['text', 'html'], to: 'to' },
+  agenticmail_reply: { body: ['text'] },
+  agenticmail_forward: { body: ['text'], to: 'to' },
+  gmail_send: { body: ['body', 'html'], to: 'to' },
+  gmail_reply: { body: ['body', 'html'] },
+  gmail_forward: { body: ['body'], to: 'to' },
+  outlook_mail_send: { body: ['body'], to: 'to' },
+  outlook_mail_reply: { body: ['body'] },
+  outlook_mail_forward: { body: ['comment'], to: 'to' },
+};
+
+export function isMailTool(toolName: string): boolean {
+  return Object.prototype.hasOwnProperty.call(MAIL_TOOLS, toolName);
+}
+
+function escapeHtml(s: string): string {
+  return s.replace(/&/g, '&amp;').replace(/</g, '&lt;').replace(/>/g, '&gt;').replace(/"/g, '&quot;');
+}
+
+function appendText(body: string, blocks: string[]): string {
+  const isHtml = /<[a-z][\s\S]*>/i.test(body);
+  if (isHtml) {
+    return body + blocks.map(b => `<p style="margin-top:16px;font-size:12px;color:#6b7280">${escapeHtml(b).replace(/\n/g, '<br>')}</p>`).join('');
+  }
+  return (body ? body.replace(/\s+$/, '') + '\n\n' : '') + '-- \n' + blocks.join('\n\n');
+}
+
+function parseRecipients(v: any): string[] {
+  return String(v || '').split(/[,;]/).map(s => {
+    const m = s.match(/<([^>]+)>/);
+    return (m ? m[1] : s).trim().toLowerCase();
+  }).filter(s => s.includes('@'));
+}
+
+function domainMatches(domain: string, pattern: string): boolean {
+  return pattern.startsWith('.') ? domain.endsWith(pattern) : domain === pattern || domain.endsWith('.' + pattern);
+}
+
+// ─── Manager ────────────────────────────────────────────
+
+export class AIDisclosureManager {
+  private engineDb?: EngineDatabase;
+  private config: DisclosureConfig = normalizeDisclosureConfig({});
+
+  constructor(private deps: { agentInfo: (agentId: string) => { name: string; email?: string; orgId?: string } | null }) {}
+
+  async setDb(db: EngineDatabase): Promise<void> {
+    this.engineDb = db;
+    const row = await db.get<any>('SELECT value FROM engine_settings WHERE key = ?', [SETTINGS_KEY]).catch(() => undefined);
+    try { this.config = normalizeDisclosureConfig(row?.value ? JSON.parse(row.value) : {}); } catch { /* keep defaults */ }
+  }
+
+  private db(): EngineDatabase {
+    if (!this.engineDb) throw new Error('AI disclosure database not initialized');
+    return this.engineDb;
+  }
+
+  getConfig(): DisclosureConfig {
+    return this.config;
+  }
+
+  async saveConfig(raw: any): Promise<DisclosureConfig> {
+    const config = normalizeDisclosureConfig(raw);
+    await this.db().execute('DELETE FROM engine_settings WHERE key = ?', [SETTINGS_KEY]);
+    await this.db().execute('INSERT INTO engine_settings (key, value) VALUES (?, ?)', [SETTINGS_KEY, JSON.stringify(config)]);
+    this.config = config;
+    return config;
+  }
+
+  /** The template for a recipient: first whose domains match, else 'default' */
+  templateFor(recipient?: string | null): DisclosureTemplate {
+    const domain = recipient ? recipient.split('@').pop()!.toLowerCase() : '';
+    const match = domain && this.config.templates.find(t => t.id !== 'default' && t.domains.some(d => domainMatches(domain, d)));
+    return match || this.config.templates.find(t => t.id === 'default')!;
+  }
+
+  render(text: string, agentId: string): string {
+    const info = this.deps.agentInfo(agentId);
+    return text
+      .replace(/\{\{\s*agentName\s*\}\}/g, info?.name || 'this assistant')
+      .replace(/\{\{\s*agentEmail\s*\}\}/g, info?.email || '');
+  }
+
+  /**
+   * Runtime hook for outbound mail. Returns the parameters with the footer
+   * (and, for new contacts, the consent notice) added, or null when nothing
+   * applies. Never throws: a disclosure problem must not block the email.
+   */
+  async apply(ctx: { agentId: string; orgId: string; toolName: string; parameters: Record<string, any> }): Promise<Record<string, any> | null> {
+    const tool = MAIL_TOOLS[ctx.toolName];
+    if (!tool || (!this.config.footerEnabled && !this.config.consentEnabled)) return null;
+    try {
+      const recipients = tool.to ? parseRecipients(ctx.parameters[tool.to]) : [];
+      const external = recipients.filter(r => !this.config.internalDomains.some(d => domainMatches(r.split('@').pop()!, d)));
+      // Replies have no recipient parameter: treat them as external
+      if (tool.to && recipients.length > 0 && external.length === 0) return null;
+
+      const template = this.templateFor(external[0]);
+      const blocks: Array<{ kind: 'footer' | 'consent'; text: string }> = [];
+      if (this.config.footerEnabled && template.footer) blocks.push({ kind: 'footer', text: this.render(template.footer, ctx.agentId) });
+
+      const needConsent: string[] = [];
+      if (this.config.consentEnabled && template.consent) {
+        for (const r of external) if (!(await this.getConsent(ctx.orgId, r))) needConsent.push(r);
+        if (needConsent.length) blocks.push({ kind: 'consent', text: this.render(template.consent, ctx.agentId) });
+      }
+      if (!blocks.length) return null;
+
+      const params = { ...ctx.parameters };
+      const bodyKeys = tool.body.filter(k => typeof params[k] === 'string' && params[k]);
+      // Agents re-sending a draft that already carries the footer shouldn't get two
+      const pending = blocks.filter(b => !bodyKeys.some(k => params[k].includes(b.text)));
+      if (!pending.length) return null;
+      for (const k of bodyKeys.length ? bodyKeys : [tool.body[0]]) params[k] = appendText(params[k] || '', pending.map(b => b.text));
+
+      for (const b of pending) {
+        await this.log({ orgId: ctx.orgId, agentId: ctx.agentId, tool: ctx.toolName, recipient: external.join(', ') || null, jurisdiction: template.id, kind: b.kind });
+      }
+      if (pending.some(b => b.kind === 'consent')) {
+        for (const r of needConsent) await this.recordConsent(ctx.orgId, r, 'requested', { agentId: ctx.agentId, jurisdiction: template.id });
+      }
+      return params;
+    } catch (err: any) {
+      console.warn(`[ai-disclosure] Failed to apply disclosure for ${ctx.toolName}: ${err.message}`);
+      return null;
+    }
+  }
+
+  // ─── Disclosure Log ───────────────────────────────────
+
+  private async log(e: Omit<DisclosureLogEntry, 'id' | 'appliedAt'>): Promise<void> {
+    await this.db().execute(
+      'INSERT INTO ai_disclosure_log (id, org_id, agent_id, tool, recipient, jurisdiction, kind, applied_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)',
+      [crypto.randomUUID(), e.orgId, e.agentId, e.tool, e.recipient, e.jurisdiction, e.kind, new Date().toISOString()],
+    );
+  }
+
+  async listLog(orgId: string, opts: { agentId?: string; kind?: string; since?: string; limit?: number; offset?: number } = {}): Promise<{ entries: DisclosureLogEntry[]; total: number }> {
+    let where = 'org_id = ?';
+    const params: any[] = [orgId];
+    if (opts.agentId) { where += ' AND agent_id = ?'; params.push(opts.agentId); }
+    if (opts.kind) { where += ' AND kind = ?'; params.push(opts.kind); }
+    if (opts.since) { where += ' AND applied_at >= ?'; params.push(opts.since); }
+    const count = await this.db().get<any>(`SELECT COUNT(*) as n FROM ai_disclosure_log WHERE ${where}`, params);
+    const rows = await this.db().query<any>(
+      `SELECT * FROM ai_disclosure_log WHERE ${where} ORDER BY applied_at DESC LIMIT ? OFFSET ?`,
+      [...params, Math.min(opts.limit || 50, 500), opts.offset || 0],
+    );
+    return {
+      total: Number(count?.n || 0),
+      entries: rows.map(r => ({
+        id: r.id, orgId: r.org_id, agentId: r.agent_id, tool: r.tool, recipient: r.recipient || null,
+        jurisdiction: r.jurisdiction, kind: r.kind, appliedAt: r.applied_at instanceof Date ? r.applied_at.toISOString() : r.applied_at,
+      })),
+    };
+  }
+
+  // ─── Consent ──────────────────────────────────────────
+
+  private static toConsent(r: any): ConversationConsent {
+    const iso = (v: any) => v instanceof Date ? v.toISOString() : v || undefined;
+    return {
+      id: r.id, orgId: r.org_id, contact: r.contact, jurisdiction: r.jurisdiction, status: r.status,
+      agentId: r.agent_id || undefined, requestedAt: iso(r.requested_at), decidedAt: iso(r.decided_at),
+      decidedBy: r.decided_by || undefined, note: r.note || undefined,
+    };
+  }
+
+  async getConsent(orgId: string, contact: string): Promise<ConversationConsent | null> {
+    const row = await this.db().get<any>('SELECT * FROM conversation_consents WHERE org_id = ? AND contact = ?', [orgId, contact.trim().toLowerCase()]);
+    return row ? AIDisclosureManager.toConsent(row) : null;
+  }
+
+  async listConsents(orgId: string, status?: ConsentStatus): Promise<ConversationConsent[]> {
+    const rows = status
+      ? await this.db().query<any>('SELECT * FROM conversation_consents WHERE org_id = ? AND status = ? ORDER BY contact', [orgId, status])
+      : await this.db().query<any>('SELECT * FROM conversation_consents WHERE org_id = ? ORDER BY contact', [orgId]);
+    return rows.map(AIDisclosureManager.toConsent);
+  }
+
+  /** Creates or updates a contact's consent record */
+  async recordConsent(orgId: string, contact: string, status: ConsentStatus, opts: { agentId?: string; jurisdiction?: string; by?: string; note?: string } = {}): Promise<ConversationConsent> {
+    if (!CONSENT_STATUSES.includes(status)) throw new Error(`Unknown consent status: ${status}`);
+    const email = contact.trim().toLowerCase();
+    if (!email.includes('@')) throw new Error('Contact must be an email address');
+    const existing = await this.getConsent(orgId, email);
+    const now = new Date().toISOString();
+    const record: ConversationConsent = {
+      id: existing?.id || crypto.randomUUID(),
+      orgId,
+      contact: email,
+      jurisdiction: opts.jurisdiction || existing?.jurisdiction || this.templateFor(email).id,
+      status,
+      agentId: opts.agentId || existing?.agentId,
+      requestedAt: status === 'requested' ? now : existing?.requestedAt,
+      decidedAt: status === 'requested' ? undefined : now,
+      decidedBy: status === 'requested' ? undefined : opts.by,
+      note: opts.note !== undefined ? opts.note.slice(0, 500) : existing?.note,
+    };
+    await this.db().execute('DELETE FROM conversation_consents WHERE org_id = ? AND contact = ?', [orgId, email]);
+    await this.db().execute(
+      'INSERT INTO conversation_consents (id, org_id, contact, jurisdiction, status, agent_id, requested_at, decided_at, decided_by, note) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)',
+      [record.id, orgId, email, record.jurisdiction, status, record.agentId || null, record.requestedAt || null, record.decidedAt || null, record.decidedBy || null, record.note || null],
+    );
+    return record;
+  }
+}
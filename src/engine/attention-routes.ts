@@ -0,0 +1,30 @@
+/**
+ * Needs Attention Routes
+ * Mounted at /attention/* on the engine sub-app.
+ *
+ * Read-only. Dismissing is per user and lives in their dashboard preferences.
+ */
+
+import { Hono } from 'hono';
+import { ATTENTION_KINDS, type AttentionFeed } from './attention.js';
+import { isAdminCaller } from './caller-role.js';
+
+export function createAttentionRoutes(feed: AttentionFeed) {
+  const router = new Hono();
+
+  // ?orgId=&days= — failed sign-ins are only included for admins
+  router.get('/', async (c) => {
+    const orgId = c.req.query('orgId');
+    if (!orgId) return c.json({ error: 'orgId required' }, 400);
+    const days = Math.min(Math.max(parseInt(c.req.query('days') || '7') || 7, 1), 90);
+    const includeLogins = isAdminCaller(c);
+    try {
+      const items = await feed.list(orgId, { days, includeLogins });
+      const counts: Record<string, number> = {};
+      for (const k of ATTENTION_KINDS) counts[k] = items.filter(i => i.kind === k).length;
+      return c.json({ items, counts, days, generatedAt: new Date().toISOString() });
+    } catch (e: any) { return c.json({ error: e.message }, 500); }
+  });
+
+  return router;
+}
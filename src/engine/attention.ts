@@ -0,0 +1,159 @@
+/**
+ * Needs Attention — one prioritized feed for the dashboard home page
+ *
+ * Pulls recent items that someone should look at from the modules that
+ * already record them:
+ *   - guardrail   agents paused, killed or flagged by anomaly rules
+ *   - dlp         outbound content blocked, redacted or warned on by DLP
+ *   - login       repeated failed sign-ins for one email (admins only)
+ *   - approval    tool approvals waiting on a human
+ *
+ * Nothing is stored here. Item ids are stable (`${kind}:${sourceId}`) so the
+ * dashboard can remember which ones a user dismissed; a failed-login item's
+ * id includes the newest attempt, so new failures bring it back.
+ */
+
+import type { GuardrailEngine } from './guardrails.js';
+import type { DLPEngine } from './dlp.js';
+import type { ApprovalEngine } from './approvals.js';
+import type { AgentLifecycleManager } from './lifecycle.js';
+import type { DatabaseAdapter } from '../db/adapter.js';
+
+// ─── Types ──────────────────────────────────────────────
+
+export type AttentionKind = 'guardrail' | 'dlp' | 'login' | 'approval';
+export type AttentionPriority = 'critical' | 'high' | 'medium' | 'low';
+
+export interface AttentionItem {
+  id: string;
+  kind: AttentionKind;
+  priority: AttentionPriority;
+  title: string;
+  detail?: string;
+  agentId?: string;
+  /** Dashboard path the item opens */
+  link: string;
+  createdAt: string;
+}
+
+// ─── Config ─────────────────────────────────────────────
+
+export const ATTENTION_KINDS: AttentionKind[] = ['guardrail', 'dlp', 'login', 'approval'];
+
+const PRIORITY_RANK: Record<AttentionPriority, number> = { critical: 0, high: 1, medium: 2, low: 3 };
+
+/** Failed sign-ins for one email below this count are treated as typos */
+const FAILED_LOGIN_THRESHOLD = 3;
+const FAILED_LOGIN_HIGH = 10;
+/** Approvals expiring sooner than this jump the queue */
+const APPROVAL_URGENT_MS = 60 * 60_000;
+const MAX_ITEMS = 200;
+
+// ─── Feed ───────────────────────────────────────────────
+
+export class AttentionFeed {
+  constructor(private deps: {
+    guardrails: GuardrailEngine;
+    dlp: DLPEngine;
+    approvals: ApprovalEngine;
+    lifecycle: AgentLifecycleManager;
+    getAdminDb: () => DatabaseAdapter | null;
+  }) {}
+
+  /** Items from the last `days` days (pending approvals regardless of age), most urgent first. */
+  async list(orgId: string, opts: { days?: number; includeLogins?: boolean } = {}): Promise<AttentionItem[]> {
+    const since = new Date(Date.now() - (opts.days || 7) * 86_400_000).toISOString();
+    const kinds = ATTENTION_KINDS.filter(k => k !== 'login' || opts.includeLogins);
+    const batches = await Promise.all(kinds.map(k => this.collect(k, orgId, since).catch(() => [] as AttentionItem[])));
+    return batches.flat()
+      .sort((a, b) => PRIORITY_RANK[a.priority] - PRIORITY_RANK[b.priority] || b.createdAt.localeCompare(a.createdAt))
+      .slice(0, MAX_ITEMS);
+  }
+
+  // ─── Sources ────────────────────────────────────────
+
+  private async collect(kind: AttentionKind, orgId: string, since: string): Promise<AttentionItem[]> {
+    switch (kind) {
+      case 'guardrail':
+        // A resume is an admin undoing a pause, not something to look at
+        return this.deps.guardrails.getInterventions({ orgId, limit: 500 })
+          .filter(i => i.type !== 'resume' && i.createdAt >= since)
+          .map(i => ({
+            id: `guardrail:${i.id}`, kind, agentId: i.agentId,
+            priority: i.type === 'kill' ? 'critical' : 'high',
+            title: i.type === 'anomaly_detected' ? `Anomaly detected on ${this.agentName(i.agentId)}`
+              : `${this.agentName(i.agentId)} was ${i.type === 'kill' ? 'stopped' : 'paused'}`,
+            detail: i.reason,
+            link: '/dashboard/guardrails',
+            createdAt: i.createdAt,
+          } as AttentionItem));
+
+      case 'dlp': {
+        const verbs: Record<string, string> = { blocked: 'blocked', redacted: 'redacted', warned: 'flagged' };
+        return this.deps.dlp.getViolations({ orgId, limit: 100_000 })
+          .filter(v => v.createdAt >= since && verbs[v.actionTaken])
+          .map(v => {
+            const rule = this.deps.dlp.getRule(v.ruleId);
+            return {
+              id: `dlp:${v.id}`, kind, agentId: v.agentId,
+              priority: v.actionTaken === 'blocked' ? 'high' : v.actionTaken === 'redacted' ? 'medium' : 'low',
+              title: `DLP ${verbs[v.actionTaken]} ${v.direction} content from ${this.agentName(v.agentId)}`,
+              detail: [rule?.name, v.toolId].filter(Boolean).join(' · ') || undefined,
+              link: '/dashboard/dlp',
+              createdAt: v.createdAt,
+            } as AttentionItem;
+          });
+      }
+
+      case 'login': {
+        const adminDb = this.deps.getAdminDb();
+        if (!adminDb) return [];
+        const [{ events }, settings] = await Promise.all([
+          adminDb.queryAudit({ action: 'auth.login_failed', from: new Date(since), limit: 5000, offset: 0 }),
+          adminDb.getSettings().catch(() => null),
+        ]);
+        // Attempts on users bound to a client org carry that org; the rest belong to the home org
+        const isHome = orgId === 'default' || orgId === settings?.orgId;
+        const groups = new Map<string, typeof events>();
+        for (const e of events) {
+          if (e.orgId ? e.orgId !== orgId : !isHome) continue;
+          const email = String(e.details?.email || e.actor);
+          groups.set(email, [...(groups.get(email) || []), e]);
+        }
+        return Array.from(groups.entries())
+          .filter(([, list]) => list.length >= FAILED_LOGIN_THRESHOLD)
+          .map(([email, list]) => {
+            const latest = list.reduce((a, b) => (new Date(a.timestamp) > new Date(b.timestamp) ? a : b));
+            const ips = new Set(list.map(e => e.ip).filter(Boolean));
+            return {
+              id: `login:${email}:${latest.id}`, kind,
+              priority: list.length >= FAILED_LOGIN_HIGH ? 'high' : 'medium',
+              title: `${list.length} failed sign-ins for ${email}`,
+              detail: ips.size ? `from ${ips.size === 1 ? [...ips][0] : ips.size + ' addresses'}` : undefined,
+              link: '/dashboard/audit?action=auth.login_failed&q=' + encodeURIComponent(email),
+              createdAt: new Date(latest.timestamp).toISOString(),
+            } as AttentionItem;
+          });
+      }
+
+      case 'approval': {
+        const soon = new Date(Date.now() + APPROVAL_URGENT_MS).toISOString();
+        return this.deps.approvals.getPendingRequests()
+          .filter(r => this.deps.lifecycle.getAgent(r.agentId)?.orgId === orgId)
+          .map(r => ({
+            id: `approval:${r.id}`, kind, agentId: r.agentId,
+            priority: r.expiresAt && r.expiresAt < soon ? 'high' : 'medium',
+            title: `${r.agentName || this.agentName(r.agentId)} wants to use ${r.toolName || r.toolId}`,
+            detail: r.context || r.reason,
+            link: '/dashboard/approvals',
+            createdAt: r.createdAt,
+          } as AttentionItem));
+      }
+    }
+  }
+
+  private agentName(agentId: string): string {
+    const a = this.deps.lifecycle.getAgent(agentId);
+    return a?.config?.displayName || a?.config?.name || a?.name || agentId;
+  }
+}
@@ -0,0 +1,141 @@
+/**
+ * Audit Log Retention
+ *
+ * How long admin audit events are kept. The policy lives in the engine's
+ * engine_settings table under 'audit_retention'; the server's scheduler
+ * (server.ts) applies it once a day while it is enabled.
+ *
+ * With archiving on, expiring events are first written to S3 as gzipped
+ * JSON Lines — the same records the audit log's JSONL export produces —
+ * and nothing is deleted unless every upload succeeded. S3 credentials
+ * come from the standard AWS environment (env vars, shared config or an
+ * instance role); none are stored in the policy.
+ */
+
+import { gzipSync } from 'zlib';
+import type { DatabaseAdapter } from '../db/adapter.js';
+import { createStorageProvider } from './storage.js';
+
+/** engine_settings key the policy is stored under */
+export const AUDIT_RETENTION_KEY = 'audit_retention';
+
+export const MIN_AUDIT_RETAIN_DAYS = 30;
+export const MAX_AUDIT_RETAIN_DAYS = 3650;
+
+/** Events per archive object */
+const ARCHIVE_CHUNK = 10_000;
+const PAGE_SIZE = 500;
+
+export interface AuditArchiveTarget {
+  bucket: string;
+  region?: string;
+  /** Key prefix inside the bucket, e.g. "compliance/" */
+  prefix?: string;
+  /** S3-compatible endpoint (MinIO, R2...); AWS when empty */
+  endpoint?: string;
+}
+
+export interface AuditRetentionRun {
+  at: string;
+  cutoff: string;
+  archived: number;
+  deleted: number;
+  archiveKeys?: string[];
+  error?: string;
+}
+
+export interface AuditRetentionPolicy {
+  enabled: boolean;
+  retainDays: number;
+  archiveToS3: boolean;
+  s3?: AuditArchiveTarget;
+  updatedAt?: string;
+  updatedBy?: string;
+  lastRun?: AuditRetentionRun;
+}
+
+export const DEFAULT_AUDIT_RETENTION: AuditRetentionPolicy = { enabled: false, retainDays: 365, archiveToS3: false };
+
+/** Check and tidy a policy from the dashboard; throws with a message fit for a 400. */
+export function normalizeAuditRetention(raw: any): Pick<AuditRetentionPolicy, 'enabled' | 'retainDays' | 'archiveToS3' | 's3'> {
+  if (!raw || typeof raw !== 'object') throw new Error('Policy must be an object');
+  const retainDays = Number(raw.retainDays);
+  if (!Number.isInteger(retainDays) || retainDays < MIN_AUDIT_RETAIN_DAYS || retainDays > MAX_AUDIT_RETAIN_DAYS) {
+    throw new Error(`retainDays must be a whole number from ${MIN_AUDIT_RETAIN_DAYS} to ${MAX_AUDIT_RETAIN_DAYS}`);
+  }
+  const archiveToS3 = raw.archiveToS3 === true;
+  let s3: AuditArchiveTarget | undefined;
+  if (raw.s3 && typeof raw.s3 === 'object') {
+    const bucket = String(raw.s3.bucket || '').trim();
+    const prefix = String(raw.s3.prefix || '').trim().replace(/^\/+/, '');
+    s3 = {
+      bucket,
+      region: String(raw.s3.region || '').trim() || undefined,
+      prefix: prefix ? prefix.replace(/\/?$/, '/') : undefined,
+      endpoint: String(raw.s3.endpoint || '').trim() || undefined,
+    };
+    if (bucket && !/^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$/.test(bucket)) throw new Error(`Not a valid S3 bucket name: ${bucket}`);
+    if (s3.endpoint && !/^https?:\/\//.test(s3.endpoint)) throw new Error('The S3 endpoint must be an http(s) URL');
+  }
+  if (archiveToS3 && !s3?.bucket) throw new Error('Choose an S3 bucket to archive to');
+  return { enabled: raw.enabled === true, retainDays, archiveToS3, s3 };
+}
+
+export function auditRetentionCutoff(retainDays: number, now = new Date()): Date {
+  return new Date(now.getTime() - retainDays * 86_400_000);
+}
+
+type SettingsDb = { get: (sql: string, params?: any[]) => Promise<any>; run: (sql: string, params?: any[]) => Promise<any> };
+
+export async function loadAuditRetention(edb: SettingsDb): Promise<AuditRetentionPolicy> {
+  const row = await edb.get('SELECT value FROM engine_settings WHERE key = ?', [AUDIT_RETENTION_KEY]).catch(() => undefined);
+  try { return row?.value ? { ...DEFAULT_AUDIT_RETENTION, ...JSON.parse(row.value) } : { ...DEFAULT_AUDIT_RETENTION }; } catch { return { ...DEFAULT_AUDIT_RETENTION }; }
+}
+
+export async function saveAuditRetention(edb: SettingsDb, policy: AuditRetentionPolicy): Promise<void> {
+  await edb.run('DELETE FROM engine_settings WHERE key = ?', [AUDIT_RETENTION_KEY]);
+  await edb.run('INSERT INTO engine_settings (key, value) VALUES (?, ?)', [AUDIT_RETENTION_KEY, JSON.stringify(policy)]);
+}
+
+/** How many events a policy with `retainDays` would purge right now, and the oldest of them. */
+export async function previewAuditPurge(db: DatabaseAdapter, retainDays: number): Promise<{ cutoff: string; count: number; oldest: string | null }> {
+  const cutoff = auditRetentionCutoff(retainDays);
+  const { events, total } = await db.queryAudit({ to: new Date(cutoff.getTime() - 1), limit: 1, sort: { field: 'timestamp', dir: 'asc' } });
+  return { cutoff: cutoff.toISOString(), count: total, oldest: events[0] ? new Date(events[0].timestamp).toISOString() : null };
+}
+
+/** Archive (if configured) and then delete every event older than the policy allows. */
+export async function runAuditRetention(db: DatabaseAdapter, policy: AuditRetentionPolicy): Promise<AuditRetentionRun> {
+  const at = new Date();
+  const cutoff = auditRetentionCutoff(policy.retainDays, at);
+  const before = new Date(cutoff.getTime() - 1);
+  let archived = 0;
+  const archiveKeys: string[] = [];
+
+  if (policy.archiveToS3) {
+    if (!policy.s3?.bucket) throw new Error('No S3 bucket configured for audit archiving');
+    const s3 = createStorageProvider('s3');
+    await s3.init({ type: 's3', bucket: policy.s3.bucket, region: policy.s3.region, endpoint: policy.s3.endpoint, forcePathStyle: !!policy.s3.endpoint });
+    const base = `${policy.s3.prefix || ''}audit-log/${cutoff.toISOString().slice(0, 10)}/${at.getTime()}`;
+    let lines: string[] = [];
+    const flush = async () => {
+      if (lines.length === 0) return;
+      const key = `${base}-part-${String(archiveKeys.length + 1).padStart(4, '0')}.jsonl.gz`;
+      await s3.upload(key, gzipSync(lines.join('')), { contentType: 'application/gzip', metadata: { cutoff: cutoff.toISOString(), events: String(lines.length) } });
+      archiveKeys.push(key);
+      archived += lines.length;
+      lines = [];
+    };
+    // Nothing new is logged before the cutoff, so offset paging is stable here
+    for (let offset = 0; ; offset += PAGE_SIZE) {
+      const { events } = await db.queryAudit({ to: before, limit: PAGE_SIZE, offset, sort: { field: 'timestamp', dir: 'asc' } });
+      for (const e of events) lines.push(JSON.stringify(e) + '\n');
+      if (lines.length >= ARCHIVE_CHUNK) await flush();
+      if (events.length < PAGE_SIZE) break;
+    }
+    await flush();
+  }
+
+  const deleted = await db.purgeAuditBefore(cutoff);
+  return { at: at.toISOString(), cutoff: cutoff.toISOString(), archived, deleted, archiveKeys: archiveKeys.length ? archiveKeys : undefined };
+}
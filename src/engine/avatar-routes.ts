@@ -0,0 +1,70 @@
+/**
+ * Avatar Routes
+ * Mounted at /avatars/* on the engine sub-app.
+ *
+ * Setting or removing an avatar also updates identity.avatar in the agent's
+ * config, so every view that reads the config picks up the new image.
+ */
+
+import { Hono } from 'hono';
+import { avatarUrl, decodeDataUrl, type AvatarStore } from './avatars.js';
+import type { AgentLifecycleManager } from './lifecycle.js';
+import type { DatabaseAdapter } from '../db/adapter.js';
+import { auditFromEngine } from './route-audit.js';
+
+export function createAvatarRoutes(avatars: AvatarStore, lifecycle: AgentLifecycleManager, deps: { getAdminDb?: () => DatabaseAdapter | null } = {}) {
+  const router = new Hono();
+
+  const audit = auditFromEngine(deps.getAdminDb);
+
+  // The URL carries a version, so the image can be cached until it changes
+  router.get('/:agentId', async (c) => {
+    const avatar = await avatars.get(c.req.param('agentId'));
+    if (!avatar) return c.json({ error: 'No avatar' }, 404);
+    const etag = `"${Date.parse(avatar.updatedAt) || avatar.size}"`;
+    c.header('ETag', etag);
+    c.header('Cache-Control', 'private, max-age=86400');
+    c.header('X-Content-Type-Options', 'nosniff');
+    if (c.req.header('If-None-Match') === etag) return c.body(null, 304);
+    c.header('Content-Type', avatar.contentType);
+    return c.body(new Uint8Array(avatar.data));
+  });
+
+  // { dataUrl } for an upload or { url } to fetch a remote image
+  router.put('/:agentId', async (c) => {
+    const agentId = c.req.param('agentId');
+    const agent = lifecycle.getAgent(agentId);
+    if (!agent) return c.json({ error: 'Agent not found' }, 404);
+    const body = await c.req.json().catch(() => ({}));
+    try {
+      let avatar;
+      if (typeof body.dataUrl === 'string') {
+        const data = decodeDataUrl(body.dataUrl);
+        if (!data) return c.json({ error: 'dataUrl must be a base64 data URL' }, 400);
+        avatar = await avatars.put(agentId, data);
+      } else if (typeof body.url === 'string' && body.url.trim()) {
+        avatar = await avatars.putFromUrl(agentId, body.url.trim());
+      } else {
+        return c.json({ error: 'Provide dataUrl or url' }, 400);
+      }
+      const url = avatarUrl(agentId, avatar.updatedAt);
+      await lifecycle.updateConfig(agentId, { identity: { avatar: url } } as any, c.req.header('X-User-Id') || 'dashboard');
+      audit(c, 'agent.avatar.update', `agent:${agentId}`, { source: avatar.sourceUrl ? 'url' : 'upload', sourceUrl: avatar.sourceUrl, size: avatar.size, contentType: avatar.contentType }, agent.orgId);
+      return c.json({ avatar: url, contentType: avatar.contentType, size: avatar.size });
+    } catch (e: any) { return c.json({ error: e.message }, 400); }
+  });
+
+  router.delete('/:agentId', async (c) => {
+    const agentId = c.req.param('agentId');
+    const agent = lifecycle.getAgent(agentId);
+    if (!agent) return c.json({ error: 'Agent not found' }, 404);
+    try {
+      await avatars.remove(agentId);
+      await lifecycle.updateConfig(agentId, { identity: { avatar: null } } as any, c.req.header('X-User-Id') || 'dashboard');
+      audit(c, 'agent.avatar.remove', `agent:${agentId}`, {}, agent.orgId);
+      return c.json({ success: true });
+    } catch (e: any) { return c.json({ error: e.message }, 400); }
+  });
+
+  return router;
+}
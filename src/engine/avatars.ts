@@ -0,0 +1,145 @@
+/**
+ * Agent Avatars — profile images stored on the backend
+ *
+ * Avatars used to live inline in the agent config as data URLs, which made
+ * every agent list carry every image. Now the image is kept here and the
+ * config holds a short URL (see avatarUrl()) that the dashboard can cache.
+ *
+ * Images come from an upload (data URL) or from a remote URL. Remote images
+ * are fetched once through an SSRF guard and stored, so the dashboard never
+ * hotlinks a third-party host. Only raster formats are accepted — SVG could
+ * carry script — and the type is taken from the file's bytes, not the
+ * declared Content-Type.
+ *
+ * Legacy data URLs in old configs keep rendering; they are moved here the
+ * next time the avatar is changed.
+ */
+
+import type { EngineDatabase } from './db-adapter.js';
+import { createSsrfGuard } from '../agent-tools/security.js';
+
+// ─── Types ──────────────────────────────────────────────
+
+export interface AgentAvatar {
+  agentId: string;
+  contentType: string;
+  data: Buffer;
+  size: number;
+  sourceUrl?: string;
+  updatedAt: string;
+}
+
+// ─── Config ─────────────────────────────────────────────
+
+export const AVATAR_MAX_BYTES = 512 * 1024;
+const FETCH_TIMEOUT_MS = 10_000;
+const MAX_REDIRECTS = 3;
+
+const ssrf = createSsrfGuard();
+
+/** The URL the dashboard stores in identity.avatar. The version busts caches after a change. */
+export function avatarUrl(agentId: string, updatedAt: string): string {
+  return `/api/engine/avatars/${encodeURIComponent(agentId)}?v=${Date.parse(updatedAt) || Date.now()}`;
+}
+
+/** Identify PNG, JPEG, GIF and WebP by their magic bytes. */
+export function sniffImageType(data: Buffer): string | null {
+  if (data.length >= 8 && data.subarray(0, 8).equals(Buffer.from([0x89, 0x50, 0x4e, 0x47, 0x0d, 0x0a, 0x1a, 0x0a]))) return 'image/png';
+  if (data.length >= 3 && data[0] === 0xff && data[1] === 0xd8 && data[2] === 0xff) return 'image/jpeg';
+  if (data.length >= 6 && /^GIF8[79]a$/.test(data.subarray(0, 6).toString('latin1'))) return 'image/gif';
+  if (data.length >= 12 && data.subarray(0, 4).toString('latin1') === 'RIFF' && data.subarray(8, 12).toString('latin1') === 'WEBP') return 'image/webp';
+  return null;
+}
+
+/** Decode a base64 data URL; returns null for anything else. */
+export function decodeDataUrl(value: string): Buffer | null {
+  const m = /^data:[\w.+/-]*;base64,([A-Za-z0-9+/=\s]+)$/.exec(value || '');
+  return m ? Buffer.from(m[1], 'base64') : null;
+}
+
+// ─── Avatar Store ───────────────────────────────────────
+
+export class AvatarStore {
+  private engineDb?: EngineDatabase;
+
+  async setDb(db: EngineDatabase): Promise<void> {
+    this.engineDb = db;
+  }
+
+  private get db(): EngineDatabase {
+    if (!this.engineDb) throw new Error('Avatar database not initialized');
+    return this.engineDb;
+  }
+
+  async get(agentId: string): Promise<AgentAvatar | undefined> {
+    if (!this.engineDb) return undefined;
+    const row = await this.engineDb.get<any>('SELECT * FROM agent_avatars WHERE agent_id = ?', [agentId]);
+    if (!row) return undefined;
+    return {
+      agentId: row.agent_id,
+      contentType: row.content_type,
+      data: Buffer.from(row.data, 'base64'),
+      size: row.size,
+      sourceUrl: row.source_url || undefined,
+      updatedAt: row.updated_at instanceof Date ? row.updated_at.toISOString() : String(row.updated_at),
+    };
+  }
+
+  /** Store an image, replacing any previous one. Throws on unsupported or oversized files. */
+  async put(agentId: string, data: Buffer, sourceUrl?: string): Promise<AgentAvatar> {
+    if (data.length === 0) throw new Error('The image is empty');
+    if (data.length > AVATAR_MAX_BYTES) throw new Error(`Avatars can be at most ${Math.round(AVATAR_MAX_BYTES / 1024)} KB`);
+    const contentType = sniffImageType(data);
+    if (!contentType) throw new Error('Avatars must be PNG, JPEG, GIF or WebP images');
+    const avatar: AgentAvatar = { agentId, contentType, data, size: data.length, sourceUrl, updatedAt: new Date().toISOString() };
+    await this.db.execute('DELETE FROM agent_avatars WHERE agent_id = ?', [agentId]);
+    await this.db.execute(
+      'INSERT INTO agent_avatars (agent_id, content_type, data, size, source_url, updated_at) VALUES (?, ?, ?, ?, ?, ?)',
+      [agentId, contentType, data.toString('base64'), data.length, sourceUrl || null, avatar.updatedAt]
+    );
+    return avatar;
+  }
+
+  /** Fetch a remote image through the SSRF guard and store it. */
+  async putFromUrl(agentId: string, url: string): Promise<AgentAvatar> {
+    return this.put(agentId, await fetchImage(url), url);
+  }
+
+  async remove(agentId: string): Promise<void> {
+    if (!this.engineDb) return;
+    await this.engineDb.execute('DELETE FROM agent_avatars WHERE agent_id = ?', [agentId]);
+  }
+}
+
+// Redirects are followed by hand so every hop goes through the guard
+async function fetchImage(url: string): Promise<Buffer> {
+  let current = url;
+  for (let hop = 0; hop <= MAX_REDIRECTS; hop++) {
+    await ssrf.validateUrl(current);
+    const res = await fetch(current, { redirect: 'manual', signal: AbortSignal.timeout(FETCH_TIMEOUT_MS), headers: { Accept: 'image/*' } });
+    if (res.status >= 300 && res.status < 400 && res.headers.get('location')) {
+      current = new URL(res.headers.get('location')!, current).toString();
+      continue;
+    }
+    if (!res.ok) throw new Error(`Could not fetch the image (HTTP ${res.status})`);
+    const tooLarge = () => new Error(`Avatars can be at most ${Math.round(AVATAR_MAX_BYTES / 1024)} KB`);
+    if (Number(res.headers.get('content-length')) > AVATAR_MAX_BYTES) throw tooLarge();
+    // Content-Length can be missing or wrong, so count while reading
+    const chunks: Buffer[] = [];
+    let size = 0;
+    const reader = res.body?.getReader();
+    if (!reader) throw new Error('The image response had no body');
+    for (;;) {
+      const { done, value } = await reader.read();
+      if (done) break;
+      size += value.byteLength;
+      if (size > AVATAR_MAX_BYTES) {
+        await reader.cancel().catch(() => {});
+        throw tooLarge();
+      }
+      chunks.push(Buffer.from(value));
+    }
+    return Buffer.concat(chunks);
+  }
+  throw new Error('Too many redirects fetching the image');
+}
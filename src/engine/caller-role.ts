@@ -0,0 +1,18 @@
+/**
+ * Caller Role for Engine Routes
+ *
+ * The server proxy strips any inbound X-User-Id, X-User-Role and
+ * X-Auth-Type headers and sets its own from the verified session, so route
+ * files can trust them. API keys carry no role: they never pass an admin or
+ * owner check here, whatever headers the client sent.
+ */
+
+/** The signed-in user's role, or undefined for API keys and unauthenticated calls */
+export function callerRole(c: any): string | undefined {
+  if (c.req.header('X-Auth-Type') === 'api-key') return undefined;
+  return c.req.header('X-User-Role') || undefined;
+}
+
+export const isAdminCaller = (c: any) => ['admin', 'owner'].includes(callerRole(c) || '');
+
+export const isOwnerCaller = (c: any) => callerRole(c) === 'owner';
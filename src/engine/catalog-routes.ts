@@ -152,6 +152,8 @@ export function createCatalogRoutes(opts: {
     const profile = await c.req.json();
     const orgId = resolveOrgId(c, profile);
     profile.id = profile.id || agentId;
+    // Vault grants are managed on their own, so a save that leaves them out keeps them
+    if (!profile.vaultAccess) profile.vaultAccess = permissions.getProfile(agentId)?.vaultAccess;
     profile.updatedAt = new Date().toISOString();
     if (!profile.createdAt) profile.createdAt = profile.updatedAt;
     permissions.setProfile(agentId, profile, orgId);
@@ -173,7 +175,7 @@ export function createCatalogRoutes(opts: {
     const orgId = resolveOrgId(c, {});
     const preset = presets.find(p => p.name === presetName);
     if (!preset) return c.json({ error: 'Preset not found' }, 404);
-    const profile = { ...preset, id: agentId, createdAt: new Date().toISOString(), updatedAt: new Date().toISOString() };
+    const profile = { ...preset, id: agentId, vaultAccess: permissions.getProfile(agentId)?.vaultAccess, createdAt: new Date().toISOString(), updatedAt: new Date().toISOString() };
     permissions.setProfile(agentId, profile as any, orgId);
     // Also persist permissionProfileId into agent config
     if (lifecycle) {
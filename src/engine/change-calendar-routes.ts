@@ -0,0 +1,159 @@
+/**
+ * Change Calendar Routes
+ * Mounted at /change-calendar/* on the engine sub-app.
+ *
+ * Planned-change edits are written to the admin audit log so the board can
+ * see who moved what.
+ *
+ * GET /feed.ics is the one route reachable without a session (see the auth
+ * skip in server.ts): it is authorized by the ?token= issued from POST /feed,
+ * and only while the token's owner is still an active user.
+ */
+
+import { Hono } from 'hono';
+import { CHANGE_KINDS, type ChangeCalendar, type ChangeKind } from './change-calendar.js';
+import type { DatabaseAdapter } from '../db/adapter.js';
+import { filterByQuery } from '../lib/filter.js';
+import { auditFromEngine } from './route-audit.js';
+
+export function createChangeCalendarRoutes(calendar: ChangeCalendar, deps: { getAdminDb: () => DatabaseAdapter | null }) {
+  const router = new Hono();
+
+  const parseKinds = (raw: string | undefined): ChangeKind[] | undefined => {
+    if (!raw) return undefined;
+    const kinds = raw.split(',').map(k => k.trim()).filter(Boolean) as ChangeKind[];
+    const bad = kinds.find(k => !CHANGE_KINDS.includes(k));
+    if (bad) throw new Error(`Invalid kind: ${bad}`);
+    return kinds;
+  };
+
+  const audit = auditFromEngine(deps.getAdminDb, 'change_calendar');
+
+  // Changes overlapping ?from=&to= (?orgId=&kind=a,b&q=)
+  router.get('/', async (c) => {
+    const orgId = c.req.query('orgId');
+    if (!orgId) return c.json({ error: 'orgId required' }, 400);
+    let kinds: ChangeKind[] | undefined;
+    try { kinds = parseKinds(c.req.query('kind')); } catch (err: any) { return c.json({ error: err.message }, 400); }
+
+    const events = await calendar.list(orgId, { from: c.req.query('from'), to: c.req.query('to'), kinds });
+    const filtered = filterByQuery(events, c.req.query('q'), e => [e.title, e.description, e.resource, e.createdBy]);
+    return c.json({ events: filtered, total: filtered.length });
+  });
+
+  // Same range and filters as GET /, as an .ics download
+  router.get('/ical', async (c) => {
+    const orgId = c.req.query('orgId');
+    if (!orgId) return c.json({ error: 'orgId required' }, 400);
+    let kinds: ChangeKind[] | undefined;
+    try { kinds = parseKinds(c.req.query('kind')); } catch (err: any) { return c.json({ error: err.message }, 400); }
+
+    const events = await calendar.list(orgId, { from: c.req.query('from'), to: c.req.query('to'), kinds });
+    c.header('Content-Type', 'text/calendar; charset=utf-8');
+    c.header('Content-Disposition', `attachment; filename="change-calendar-${new Date().toISOString().split('T')[0]}.ics"`);
+    return c.body(calendar.toICal(events, 'Change Calendar'));
+  });
+
+  // ─── Subscribed Feed ────────────────────────────────
+
+  const feedUrl = (c: any, token: string) => `${new URL(c.req.url).origin}/api/engine/change-calendar/feed.ics?token=${token}`;
+
+  // The caller's feed, if any (?orgId=). The token itself is never returned again.
+  router.get('/feed', async (c) => {
+    const orgId = c.req.query('orgId');
+    const userId = c.req.header('X-User-Id');
+    if (!orgId) return c.json({ error: 'orgId required' }, 400);
+    if (!userId) return c.json({ error: 'Authentication required' }, 401);
+    return c.json({ feed: (await calendar.getFeed(orgId, userId)) || null });
+  });
+
+  // { orgId, kinds? } — issues a new token, replacing the caller's previous one
+  router.post('/feed', async (c) => {
+    const body = await c.req.json().catch(() => ({}));
+    const userId = c.req.header('X-User-Id');
+    if (!body.orgId) return c.json({ error: 'orgId required' }, 400);
+    if (!userId) return c.json({ error: 'Authentication required' }, 401);
+    try {
+      const { feed, token } = await calendar.issueFeed(body.orgId, userId, Array.isArray(body.kinds) ? body.kinds : undefined);
+      audit(c, 'feed_issue', `calendar_feed:${feed.id}`, { kinds: feed.kinds }, feed.orgId);
+      return c.json({ feed, token, url: feedUrl(c, token) }, 201);
+    } catch (err: any) {
+      return c.json({ error: err.message }, 400);
+    }
+  });
+
+  router.delete('/feed', async (c) => {
+    const orgId = c.req.query('orgId');
+    const userId = c.req.header('X-User-Id');
+    if (!orgId) return c.json({ error: 'orgId required' }, 400);
+    if (!userId) return c.json({ error: 'Authentication required' }, 401);
+    const existing = await calendar.getFeed(orgId, userId);
+    if (!existing) return c.json({ error: 'No calendar feed' }, 404);
+    await calendar.revokeFeed(orgId, userId);
+    audit(c, 'feed_revoke', `calendar_feed:${existing.id}`, undefined, orgId);
+    return c.json({ success: true });
+  });
+
+  // Polled by Outlook / Google Calendar. Unauthenticated at the server; the token is the credential.
+  router.get('/feed.ics', async (c) => {
+    const token = c.req.query('token') || '';
+    const feed = token ? await calendar.resolveFeedToken(token) : undefined;
+    if (!feed) return c.json({ error: 'Invalid or revoked calendar feed' }, 401);
+    const adminDb = deps.getAdminDb();
+    const user = adminDb ? await adminDb.getUser(feed.userId).catch(() => null) : null;
+    if (!user || user.isActive === false) return c.json({ error: 'Invalid or revoked calendar feed' }, 401);
+
+    const events = await calendar.feedEvents(feed);
+    c.header('Content-Type', 'text/calendar; charset=utf-8');
+    c.header('Cache-Control', 'private, max-age=300');
+    return c.body(calendar.toICal(events, 'AgenticMail Change Calendar'));
+  });
+
+  // { orgId, kind, title, description?, resource?, startsAt, endsAt? }
+  router.post('/planned', async (c) => {
+    const body = await c.req.json().catch(() => ({}));
+    if (!body.orgId) return c.json({ error: 'orgId required' }, 400);
+    if (!body.startsAt) return c.json({ error: 'startsAt is required' }, 400);
+    try {
+      const change = await calendar.createPlannedChange({
+        orgId: body.orgId,
+        kind: body.kind,
+        title: body.title,
+        description: body.description,
+        resource: body.resource,
+        startsAt: body.startsAt,
+        endsAt: body.endsAt || undefined,
+        createdBy: c.req.header('X-User-Id') || undefined,
+      });
+      audit(c, 'create', `planned_change:${change.id}`, { kind: change.kind, title: change.title, startsAt: change.startsAt }, change.orgId);
+      return c.json({ change }, 201);
+    } catch (err: any) {
+      return c.json({ error: err.message }, 400);
+    }
+  });
+
+  router.put('/planned/:id', async (c) => {
+    const body = await c.req.json().catch(() => ({}));
+    try {
+      const change = await calendar.updatePlannedChange(c.req.param('id'), {
+        kind: body.kind, title: body.title, description: body.description, resource: body.resource,
+        startsAt: body.startsAt, endsAt: body.endsAt, status: body.status,
+      });
+      if (!change) return c.json({ error: 'Planned change not found' }, 404);
+      audit(c, 'update', `planned_change:${change.id}`, { status: change.status, startsAt: change.startsAt, endsAt: change.endsAt }, change.orgId);
+      return c.json({ change });
+    } catch (err: any) {
+      return c.json({ error: err.message }, 400);
+    }
+  });
+
+  router.delete('/planned/:id', async (c) => {
+    const existing = await calendar.getPlannedChange(c.req.param('id'));
+    if (!existing) return c.json({ error: 'Planned change not found' }, 404);
+    await calendar.deletePlannedChange(existing.id);
+    audit(c, 'delete', `planned_change:${existing.id}`, { title: existing.title }, existing.orgId);
+    return c.json({ success: true });
+  });
+
+  return router;
+}
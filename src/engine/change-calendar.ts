@@ -0,0 +1,393 @@
+/**
+ * Change Calendar — Upcoming and recent operational changes, in one view
+ *
+ * Gives change advisory boards a single timeline to review:
+ *   - maintenance          agent/org maintenance windows (WorkforceManager)
+ *   - compliance_report    compliance reports generated or in progress (ComplianceReporter)
+ *   - task                 open agent tasks with a deadline (AgentCommunicationBus)
+ *   - rule_change          planned guardrail, DLP or policy changes
+ *   - retention_purge      planned data retention purges
+ *   - compliance_deadline  audit, attestation and access-review due dates
+ *   - other                anything else worth putting in front of the board
+ *
+ * Maintenance windows, reports and tasks are read from their own modules. The
+ * planned kinds have no system of record elsewhere, so they are stored here
+ * in planned_changes. Event ids are `${source}:${sourceId}`.
+ *
+ * Calendar clients can't send a session cookie, so the subscribable ICS feed
+ * is authorized by a per-user token in the URL. Only its SHA-256 is stored;
+ * issuing a new token replaces the old one.
+ */
+
+import { createHash, randomBytes } from 'crypto';
+import type { EngineDatabase } from './db-adapter.js';
+import type { WorkforceManager } from './workforce.js';
+import type { ComplianceReporter } from './compliance.js';
+import type { AgentCommunicationBus } from './communication.js';
+import { buildICalendar, type ICalEvent } from '../lib/ical.js';
+
+// ─── Types ──────────────────────────────────────────────
+
+export type ChangeKind = 'maintenance' | 'compliance_report' | 'task' | 'rule_change' | 'retention_purge' | 'compliance_deadline' | 'other';
+export type PlannedChangeKind = Exclude<ChangeKind, 'maintenance' | 'compliance_report' | 'task'>;
+
+export interface PlannedChange {
+  id: string;
+  orgId: string;
+  kind: PlannedChangeKind;
+  title: string;
+  description?: string;
+  /** What is being changed, e.g. "DLP rule: PII outbound" */
+  resource?: string;
+  startsAt: string;
+  endsAt?: string;
+  status: 'planned' | 'done' | 'cancelled';
+  createdBy?: string;
+  createdAt: string;
+  updatedAt: string;
+}
+
+export interface ChangeEvent {
+  id: string;
+  kind: ChangeKind;
+  source: 'maintenance' | 'compliance' | 'task' | 'planned';
+  sourceId: string;
+  orgId: string;
+  title: string;
+  description?: string;
+  resource?: string;
+  agentId?: string;
+  startsAt: string;
+  endsAt?: string;
+  status: string;
+  createdBy?: string;
+}
+
+export interface CalendarFeed {
+  id: string;
+  orgId: string;
+  userId: string;
+  /** Kinds included in the feed; empty means all */
+  kinds: ChangeKind[];
+  createdAt: string;
+  lastUsedAt?: string;
+}
+
+// ─── Config ─────────────────────────────────────────────
+
+export const CHANGE_KINDS: ChangeKind[] = ['maintenance', 'compliance_report', 'task', 'rule_change', 'retention_purge', 'compliance_deadline', 'other'];
+export const PLANNED_CHANGE_KINDS: PlannedChangeKind[] = ['rule_change', 'retention_purge', 'compliance_deadline', 'other'];
+
+export const CHANGE_KIND_LABELS: Record<ChangeKind, string> = {
+  maintenance: 'Maintenance',
+  compliance_report: 'Compliance report',
+  task: 'Task deadline',
+  rule_change: 'Rule change',
+  retention_purge: 'Retention purge',
+  compliance_deadline: 'Compliance deadline',
+  other: 'Change',
+};
+
+/** Default range when the caller gives none: a week back, ninety days ahead */
+const DEFAULT_LOOKBACK_DAYS = 7;
+const DEFAULT_LOOKAHEAD_DAYS = 90;
+const MAX_EVENTS = 1000;
+/** Subscribed feeds cover a month back and a year ahead */
+const FEED_LOOKBACK_DAYS = 30;
+const FEED_LOOKAHEAD_DAYS = 365;
+/** Tasks without a deadline don't belong on a calendar; this caps the scan */
+const MAX_TASK_SCAN = 2000;
+
+// ─── Change Calendar ────────────────────────────────────
+
+export class ChangeCalendar {
+  private engineDb?: EngineDatabase;
+
+  constructor(private deps: {
+    workforce: WorkforceManager;
+    compliance: ComplianceReporter;
+    commBus: AgentCommunicationBus;
+  }) {}
+
+  async setDb(db: EngineDatabase): Promise<void> {
+    this.engineDb = db;
+  }
+
+  /** All changes overlapping [from, to], oldest first. */
+  async list(orgId: string, opts?: { from?: string; to?: string; kinds?: ChangeKind[] }): Promise<ChangeEvent[]> {
+    const from = opts?.from || new Date(Date.now() - DEFAULT_LOOKBACK_DAYS * 86_400_000).toISOString();
+    const to = opts?.to || new Date(Date.now() + DEFAULT_LOOKAHEAD_DAYS * 86_400_000).toISOString();
+    const wants = (k: ChangeKind) => !opts?.kinds?.length || opts.kinds.includes(k);
+    const events: ChangeEvent[] = [];
+
+    if (wants('maintenance')) {
+      for (const w of await this.deps.workforce.getMaintenanceWindows(orgId, { from, to })) {
+        events.push({
+          id: `maintenance:${w.id}`, kind: 'maintenance', source: 'maintenance', sourceId: w.id,
+          orgId: w.orgId, title: w.title, description: w.notice, agentId: w.agentId,
+          resource: w.agentId ? `Agent ${w.agentId}` : 'All agents',
+          startsAt: w.startsAt, endsAt: w.endsAt, status: w.status, createdBy: w.createdBy,
+        });
+      }
+    }
+
+    if (wants('compliance_report')) {
+      for (const r of this.deps.compliance.getReports({ orgId, limit: 200 })) {
+        const at = r.completedAt || r.createdAt;
+        if (at < from || r.createdAt > to) continue;
+        events.push({
+          id: `compliance:${r.id}`, kind: 'compliance_report', source: 'compliance', sourceId: r.id,
+          orgId: r.orgId, title: r.title, resource: r.type.toUpperCase(),
+          description: r.error ? `Failed: ${r.error}` : undefined,
+          startsAt: r.createdAt, endsAt: r.completedAt, status: r.status, createdBy: r.generatedBy,
+        });
+      }
+    }
+
+    if (wants('task')) {
+      const { messages } = this.deps.commBus.getMessages({ orgId, type: 'task', limit: MAX_TASK_SCAN });
+      for (const t of messages) {
+        if (!t.deadline || t.deadline < from || t.deadline > to) continue;
+        if (t.status === 'completed' || t.status === 'failed') continue;
+        events.push({
+          id: `task:${t.id}`, kind: 'task', source: 'task', sourceId: t.id,
+          orgId: t.orgId, title: t.subject, agentId: t.toAgentId,
+          resource: `Agent ${t.toAgentId}`, description: t.content?.slice(0, 500),
+          startsAt: t.deadline, status: t.status, createdBy: t.fromAgentId,
+        });
+      }
+    }
+
+    if (PLANNED_CHANGE_KINDS.some(wants)) {
+      for (const p of await this.getPlannedChanges(orgId, { from, to })) {
+        if (!wants(p.kind)) continue;
+        events.push({
+          id: `planned:${p.id}`, kind: p.kind, source: 'planned', sourceId: p.id,
+          orgId: p.orgId, title: p.title, description: p.description, resource: p.resource,
+          startsAt: p.startsAt, endsAt: p.endsAt, status: p.status, createdBy: p.createdBy,
+        });
+      }
+    }
+
+    return events.sort((a, b) => a.startsAt.localeCompare(b.startsAt)).slice(0, MAX_EVENTS);
+  }
+
+  /** The same events as an iCalendar feed. Cancelled entries are kept so clients drop them. */
+  toICal(events: ChangeEvent[], calendarName: string): string {
+    const icalEvents: ICalEvent[] = events.map(e => ({
+      uid: `${e.id}@agenticmail-change-calendar`,
+      start: e.startsAt,
+      end: e.endsAt,
+      summary: `[${CHANGE_KIND_LABELS[e.kind]}] ${e.title}`,
+      description: [e.description, e.resource && `Affects: ${e.resource}`, e.createdBy && `Owner: ${e.createdBy}`, `Status: ${e.status}`]
+        .filter(Boolean).join('\n'),
+      categories: [CHANGE_KIND_LABELS[e.kind]],
+      status: e.status === 'cancelled' ? 'CANCELLED' : e.status === 'planned' || e.status === 'scheduled' ? 'TENTATIVE' : 'CONFIRMED',
+    }));
+    return buildICalendar(icalEvents, { name: calendarName, description: 'Scheduled maintenance, rule changes, compliance reports and retention purges' });
+  }
+
+  // ─── ICS Feed Tokens ──────────────────────────────────
+
+  async getFeed(orgId: string, userId: string): Promise<CalendarFeed | undefined> {
+    if (!this.engineDb) return undefined;
+    const row = await this.engineDb.get<any>('SELECT * FROM calendar_feeds WHERE org_id = ? AND user_id = ?', [orgId, userId]);
+    return row ? this.rowToFeed(row) : undefined;
+  }
+
+  /** Issue a feed token for the user, replacing any previous one. The plaintext is only returned here. */
+  async issueFeed(orgId: string, userId: string, kinds?: ChangeKind[]): Promise<{ feed: CalendarFeed; token: string }> {
+    if (!this.engineDb) throw new Error('Change calendar database not initialized');
+    const bad = (kinds || []).find(k => !CHANGE_KINDS.includes(k));
+    if (bad) throw new Error(`Invalid kind: ${bad}`);
+    const token = 'cal_' + randomBytes(24).toString('base64url');
+    const feed: CalendarFeed = {
+      id: crypto.randomUUID(), orgId, userId, kinds: kinds || [], createdAt: new Date().toISOString(),
+    };
+    await this.engineDb.execute('DELETE FROM calendar_feeds WHERE org_id = ? AND user_id = ?', [orgId, userId]);
+    await this.engineDb.execute(
+      'INSERT INTO calendar_feeds (id, org_id, user_id, token_hash, kinds, created_at) VALUES (?, ?, ?, ?, ?, ?)',
+      [feed.id, orgId, userId, hashFeedToken(token), feed.kinds.join(',') || null, feed.createdAt]
+    );
+    return { feed, token };
+  }
+
+  async revokeFeed(orgId: string, userId: string): Promise<boolean> {
+    if (!this.engineDb) return false;
+    const existing = await this.getFeed(orgId, userId);
+    if (!existing) return false;
+    await this.engineDb.execute('DELETE FROM calendar_feeds WHERE id = ?', [existing.id]);
+    return true;
+  }
+
+  /** Look up the feed a token belongs to and record the fetch. */
+  async resolveFeedToken(token: string): Promise<CalendarFeed | undefined> {
+    if (!this.engineDb || !token.startsWith('cal_')) return undefined;
+    const row = await this.engineDb.get<any>('SELECT * FROM calendar_feeds WHERE token_hash = ?', [hashFeedToken(token)]);
+    if (!row) return undefined;
+    const now = new Date().toISOString();
+    this.engineDb.execute('UPDATE calendar_feeds SET last_used_at = ? WHERE id = ?', [now, row.id]).catch(() => {});
+    return { ...this.rowToFeed(row), lastUsedAt: now };
+  }
+
+  /** Events for a subscribed feed: a month back, a year ahead. */
+  async feedEvents(feed: CalendarFeed): Promise<ChangeEvent[]> {
+    return this.list(feed.orgId, {
+      from: new Date(Date.now() - FEED_LOOKBACK_DAYS * 86_400_000).toISOString(),
+      to: new Date(Date.now() + FEED_LOOKAHEAD_DAYS * 86_400_000).toISOString(),
+      kinds: feed.kinds.length ? feed.kinds : undefined,
+    });
+  }
+
+  // ─── Planned Changes ──────────────────────────────────
+
+  async getPlannedChanges(orgId: string, opts?: { from?: string; to?: string }): Promise<PlannedChange[]> {
+    if (!this.engineDb) return [];
+    let sql = 'SELECT * FROM planned_changes WHERE org_id = ?';
+    const params: any[] = [orgId];
+    if (opts?.from) {
+      // Point-in-time changes have no end, so fall back to their start
+      sql += ' AND COALESCE(ends_at, starts_at) >= ?';
+      params.push(opts.from);
+    }
+    if (opts?.to) {
+      sql += ' AND starts_at <= ?';
+      params.push(opts.to);
+    }
+    sql += ' ORDER BY starts_at ASC LIMIT 500';
+    try {
+      const rows = await this.engineDb.query<any>(sql, params);
+      return rows.map((r: any) => this.rowToPlanned(r));
+    } catch {
+      return [];
+    }
+  }
+
+  async getPlannedChange(id: string): Promise<PlannedChange | undefined> {
+    if (!this.engineDb) return undefined;
+    const row = await this.engineDb.get<any>('SELECT * FROM planned_changes WHERE id = ?', [id]);
+    return row ? this.rowToPlanned(row) : undefined;
+  }
+
+  async createPlannedChange(input: {
+    orgId: string;
+    kind: PlannedChangeKind;
+    title: string;
+    description?: string;
+    resource?: string;
+    startsAt: string;
+    endsAt?: string;
+    createdBy?: string;
+  }): Promise<PlannedChange> {
+    if (!this.engineDb) throw new Error('Change calendar database not initialized');
+    if (!PLANNED_CHANGE_KINDS.includes(input.kind)) throw new Error(`kind must be one of ${PLANNED_CHANGE_KINDS.join(', ')}`);
+    if (!input.title?.trim()) throw new Error('title is required');
+    const { startsAt, endsAt } = this.validateTimes(input.startsAt, input.endsAt);
+    const now = new Date().toISOString();
+    const change: PlannedChange = {
+      id: crypto.randomUUID(),
+      orgId: input.orgId,
+      kind: input.kind,
+      title: input.title.trim().slice(0, 200),
+      description: input.description?.slice(0, 4000) || undefined,
+      resource: input.resource?.slice(0, 255) || undefined,
+      startsAt,
+      endsAt,
+      status: 'planned',
+      createdBy: input.createdBy,
+      createdAt: now,
+      updatedAt: now,
+    };
+    await this.engineDb.execute(
+      `INSERT INTO planned_changes (id, org_id, kind, title, description, resource, starts_at, ends_at, status, created_by, created_at, updated_at)
+       VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+      [
+        change.id, change.orgId, change.kind, change.title, change.description || null, change.resource || null,
+        change.startsAt, change.endsAt || null, change.status, change.createdBy || null, change.createdAt, change.updatedAt,
+      ]
+    );
+    return change;
+  }
+
+  async updatePlannedChange(id: string, updates: Partial<Pick<PlannedChange, 'kind' | 'title' | 'description' | 'resource' | 'startsAt' | 'endsAt' | 'status'>>): Promise<PlannedChange | undefined> {
+    const existing = await this.getPlannedChange(id);
+    if (!existing || !this.engineDb) return undefined;
+    if (updates.kind && !PLANNED_CHANGE_KINDS.includes(updates.kind)) throw new Error(`kind must be one of ${PLANNED_CHANGE_KINDS.join(', ')}`);
+    if (updates.status && !['planned', 'done', 'cancelled'].includes(updates.status)) throw new Error('status must be planned, done or cancelled');
+
+    const { startsAt, endsAt } = this.validateTimes(
+      updates.startsAt ?? existing.startsAt,
+      updates.endsAt === undefined ? existing.endsAt : updates.endsAt || undefined,
+    );
+    const change: PlannedChange = {
+      ...existing,
+      kind: updates.kind ?? existing.kind,
+      title: updates.title?.trim() ? updates.title.trim().slice(0, 200) : existing.title,
+      description: updates.description === undefined ? existing.description : updates.description.slice(0, 4000) || undefined,
+      resource: updates.resource === undefined ? existing.resource : updates.resource.slice(0, 255) || undefined,
+      startsAt,
+      endsAt,
+      status: updates.status ?? existing.status,
+      updatedAt: new Date().toISOString(),
+    };
+    await this.engineDb.execute(
+      `UPDATE planned_changes SET kind = ?, title = ?, description = ?, resource = ?, starts_at = ?, ends_at = ?, status = ?, updated_at = ?
+       WHERE id = ?`,
+      [change.kind, change.title, change.description || null, change.resource || null, change.startsAt, change.endsAt || null, change.status, change.updatedAt, id]
+    );
+    return change;
+  }
+
+  async deletePlannedChange(id: string): Promise<boolean> {
+    if (!this.engineDb) return false;
+    const existing = await this.getPlannedChange(id);
+    if (!existing) return false;
+    await this.engineDb.execute('DELETE FROM planned_changes WHERE id = ?', [id]);
+    return true;
+  }
+
+  // ─── Helpers ──────────────────────────────────────────
+
+  private validateTimes(startsAt: string, endsAt?: string): { startsAt: string; endsAt?: string } {
+    const start = new Date(startsAt);
+    if (isNaN(start.getTime())) throw new Error('startsAt must be a valid date');
+    if (!endsAt) return { startsAt: start.toISOString() };
+    const end = new Date(endsAt);
+    if (isNaN(end.getTime())) throw new Error('endsAt must be a valid date');
+    if (end <= start) throw new Error('endsAt must be after startsAt');
+    return { startsAt: start.toISOString(), endsAt: end.toISOString() };
+  }
+
+  private rowToFeed(r: any): CalendarFeed {
+    return {
+      id: r.id,
+      orgId: r.org_id,
+      userId: r.user_id,
+      kinds: r.kinds ? String(r.kinds).split(',').filter(Boolean) as ChangeKind[] : [],
+      createdAt: r.created_at ? new Date(r.created_at).toISOString() : r.created_at,
+      lastUsedAt: r.last_used_at ? new Date(r.last_used_at).toISOString() : undefined,
+    };
+  }
+
+  private rowToPlanned(r: any): PlannedChange {
+    const iso = (v: any) => v ? new Date(v).toISOString() : undefined;
+    return {
+      id: r.id,
+      orgId: r.org_id,
+      kind: r.kind,
+      title: r.title,
+      description: r.description || undefined,
+      resource: r.resource || undefined,
+      startsAt: iso(r.starts_at)!,
+      endsAt: iso(r.ends_at),
+      status: r.status,
+      createdBy: r.created_by || undefined,
+      createdAt: iso(r.created_at) || r.created_at,
+      updatedAt: iso(r.updated_at) || r.updated_at,
+    };
+  }
+}
+
+function hashFeedToken(token: string): string {
+  return createHash('sha256').update(token).digest('hex');
+}
@@ -85,6 +85,8 @@ interface ChatPollerConfig {
   maxBackoffMs?: number;
   /** Workforce manager for work hours enforcement */
   workforce?: any;
+  /** Called for each message handed to an agent (e.g. to fire webhooks) */
+  onMessage?: (agentId: string, message: { channel: string; from: string; subject?: string; text: string; threadId?: string }) => void;
 }
 
 interface ThreadOwnership {
@@ -749,6 +751,7 @@ export class ChatPoller {
       isDM: space.agentIds.length === 1, // heuristic: single-agent space is like a DM
       messageText: msg.text,
     };
+    this.config.onMessage?.(agent.id, { channel: 'google_chat', from: chatContext.senderEmail || chatContext.senderName, text: msg.text, threadId: chatContext.threadId || undefined });
 
     const url = `http://${agent.host}:${agent.port}/api/runtime/chat`;
 
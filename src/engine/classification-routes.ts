@@ -0,0 +1,46 @@
+/**
+ * Data Classification Routes
+ * Mounted at /classifications/* on the engine sub-app.
+ */
+
+import { Hono } from 'hono';
+import { DataClassifier, CLASSIFIED_RESOURCES, type ClassifiedResource } from './classification.js';
+import type { DatabaseAdapter } from '../db/adapter.js';
+import { CLASSIFICATION_LEVELS } from '../lib/classification.js';
+import { auditFromEngine } from './route-audit.js';
+
+export function createClassificationRoutes(classifier: DataClassifier, deps: { getAdminDb?: () => DatabaseAdapter | null } = {}) {
+  const router = new Hono();
+  const audit = auditFromEngine(deps.getAdminDb);
+
+  router.get('/', (c) => {
+    const orgId = c.req.query('orgId') || 'default';
+    const type = c.req.query('type') as ClassifiedResource | undefined;
+    if (type && !CLASSIFIED_RESOURCES.includes(type)) return c.json({ error: `Unknown resource type: ${type}` }, 400);
+    return c.json({ classifications: classifier.list(orgId, type), levels: CLASSIFICATION_LEVELS });
+  });
+
+  /** What an agent handles: the highest of its mailbox and knowledge bases */
+  router.get('/agents/:agentId', (c) => {
+    const agentId = c.req.param('agentId');
+    return c.json({ agentId, mailbox: classifier.get('mailbox', agentId), effective: classifier.effectiveLevel(agentId) });
+  });
+
+  // { orgId, level } — level null clears the label
+  router.put('/:type/:id', async (c) => {
+    const type = c.req.param('type') as ClassifiedResource;
+    const id = c.req.param('id');
+    const body = await c.req.json().catch(() => ({}));
+    const orgId = body.orgId || 'default';
+    const level = body.level || null;
+    const actor = c.req.header('X-User-Id') || 'dashboard';
+    let previous;
+    try {
+      previous = await classifier.set(orgId, type, id, level, actor);
+    } catch (e: any) { return c.json({ error: e.message }, 400); }
+    audit(c, 'classification.set', `${type}:${id}`, { from: previous, to: level }, orgId);
+    return c.json({ success: true, level });
+  });
+
+  return router;
+}
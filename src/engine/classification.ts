@@ -0,0 +1,93 @@
+/**
+ * Data Classification — Labels on knowledge bases, templates and mailboxes
+ *
+ * Each resource carries at most one level (public, internal, confidential,
+ * restricted). An agent's effective level is the highest of its own
+ * mailbox and every knowledge base it can read: an agent with a restricted
+ * knowledge base handles restricted data whatever its mailbox says. DLP
+ * rules and the egress filter key off that effective level.
+ *
+ * Labels are cached in memory so the policy checks on every tool call stay
+ * synchronous; writes go through here and update the cache.
+ */
+
+import type { EngineDatabase } from './db-adapter.js';
+import { type ClassificationLevel, isClassificationLevel, highestClassification } from '../lib/classification.js';
+
+// ─── Types ──────────────────────────────────────────────
+
+/** knowledge_base → knowledge_bases, template → custom_roles, mailbox → an agent's inbox */
+export type ClassifiedResource = 'knowledge_base' | 'template' | 'mailbox';
+
+export const CLASSIFIED_RESOURCES: ClassifiedResource[] = ['knowledge_base', 'template', 'mailbox'];
+
+export interface Classification {
+  orgId: string;
+  resourceType: ClassifiedResource;
+  resourceId: string;
+  level: ClassificationLevel;
+  setBy?: string;
+  updatedAt: string;
+}
+
+// ─── Classifier ─────────────────────────────────────────
+
+export class DataClassifier {
+  private engineDb?: EngineDatabase;
+  private labels = new Map<string, Classification>();
+
+  constructor(private deps: { kbIdsForAgent: (agentId: string) => string[] }) {}
+
+  private static keyOf(type: ClassifiedResource, id: string) { return `${type}:${id}`; }
+
+  async setDb(db: EngineDatabase): Promise<void> {
+    this.engineDb = db;
+    this.labels.clear();
+    const rows = await db.query<any>('SELECT * FROM data_classifications').catch(() => []);
+    for (const r of rows) {
+      if (!isClassificationLevel(r.level)) continue;
+      const c: Classification = {
+        orgId: r.org_id, resourceType: r.resource_type, resourceId: r.resource_id, level: r.level,
+        setBy: r.set_by || undefined, updatedAt: r.updated_at instanceof Date ? r.updated_at.toISOString() : r.updated_at,
+      };
+      this.labels.set(DataClassifier.keyOf(c.resourceType, c.resourceId), c);
+    }
+  }
+
+  list(orgId: string, type?: ClassifiedResource): Classification[] {
+    return Array.from(this.labels.values()).filter(c => c.orgId === orgId && (!type || c.resourceType === type));
+  }
+
+  get(type: ClassifiedResource, id: string): ClassificationLevel | null {
+    return this.labels.get(DataClassifier.keyOf(type, id))?.level || null;
+  }
+
+  /** Sets or (with null) clears a label; returns the previous level */
+  async set(orgId: string, type: ClassifiedResource, id: string, level: ClassificationLevel | null, setBy?: string): Promise<ClassificationLevel | null> {
+    if (!CLASSIFIED_RESOURCES.includes(type)) throw new Error(`Unknown resource type: ${type}`);
+    if (level !== null && !isClassificationLevel(level)) throw new Error(`Unknown classification: ${level}`);
+    if (!this.engineDb) throw new Error('Classification database not initialized');
+    const key = DataClassifier.keyOf(type, id);
+    const previous = this.labels.get(key)?.level || null;
+    await this.engineDb.execute('DELETE FROM data_classifications WHERE resource_type = ? AND resource_id = ?', [type, id]);
+    if (level === null) {
+      this.labels.delete(key);
+      return previous;
+    }
+    const now = new Date().toISOString();
+    await this.engineDb.execute(
+      'INSERT INTO data_classifications (org_id, resource_type, resource_id, level, set_by, updated_at) VALUES (?, ?, ?, ?, ?, ?)',
+      [orgId, type, id, level, setBy || null, now],
+    );
+    this.labels.set(key, { orgId, resourceType: type, resourceId: id, level, setBy, updatedAt: now });
+    return previous;
+  }
+
+  /** Highest of the agent's mailbox and the knowledge bases it can read */
+  effectiveLevel(agentId: string): ClassificationLevel | null {
+    return highestClassification([
+      this.get('mailbox', agentId),
+      ...this.deps.kbIdsForAgent(agentId).map(kbId => this.get('knowledge_base', kbId)),
+    ]);
+  }
+}
@@ -1,14 +1,35 @@
 /**
  * Agent-to-Agent Communication Routes
  * Mounted at /messages/* and /tasks/* on the engine sub-app.
+ *
+ * Reads are narrowed by `visibleAgentIds` when the caller is limited to
+ * their teams' agents: they only see messages one of those agents sent or
+ * received.
  */
 
 import { Hono } from 'hono';
-import type { AgentCommunicationBus } from './communication.js';
-
-export function createCommunicationRoutes(commBus: AgentCommunicationBus) {
+import { DELIVERY_EVENTS, type AgentCommunicationBus } from './communication.js';
+import type { DatabaseAdapter } from '../db/adapter.js';
+import { unknownVariables, applyMergeVariables, buildMergeContext } from '../lib/merge-vars.js';
+import { parseSort } from '../lib/sort.js';
+import { wantsCsv, csvResponse } from '../lib/csv.js';
+
+const MESSAGE_SORT_FIELDS = ['type', 'direction', 'channel', 'fromAgentId', 'toAgentId', 'subject', 'status', 'priority', 'createdAt'] as const;
+
+const MESSAGE_FORMATS = ['text', 'markdown'];
+const MERGE_MAX_RECIPIENTS = 25;
+
+export function createCommunicationRoutes(
+  commBus: AgentCommunicationBus,
+  getAdminDb?: () => DatabaseAdapter | null,
+  opts: { visibleAgentIds?: (c: any) => Promise<Set<string> | null> } = {},
+) {
   const router = new Hono();
 
+  const visibleTo = async (c: any) => opts.visibleAgentIds ? opts.visibleAgentIds(c) : null;
+  const canSee = (visible: Set<string> | null, msg: { fromAgentId: string; toAgentId: string }) =>
+    !visible || visible.has(msg.fromAgentId) || visible.has(msg.toAgentId);
+
   // ─── Messages ──────────────────────────────────────────
 
   router.post('/observe', async (c) => {
@@ -21,13 +42,50 @@ export function createCommunicationRoutes(commBus: AgentCommunicationBus) {
   router.post('/', async (c) => {
     const body = await c.req.json();
     if (!body.orgId || !body.fromAgentId || !body.toAgentId) return c.json({ error: 'orgId, fromAgentId, toAgentId required' }, 400);
+    if (body.format && !MESSAGE_FORMATS.includes(body.format)) return c.json({ error: 'format must be text or markdown' }, 400);
     const msg = await commBus.sendMessage(body);
     return c.json({ message: msg }, 201);
   });
 
+  // Mail-merge: one personalised message per recipient. dryRun returns the
+  // rendered messages without sending (recipient preview).
+  router.post('/merge', async (c) => {
+    const body = await c.req.json();
+    const toAgentIds: string[] = Array.isArray(body.toAgentIds) ? [...new Set<string>(body.toAgentIds.filter((id: any) => typeof id === 'string' && id))] : [];
+    if (!body.orgId || !body.fromAgentId || !toAgentIds.length) return c.json({ error: 'orgId, fromAgentId, toAgentIds required' }, 400);
+    if (toAgentIds.length > MERGE_MAX_RECIPIENTS) return c.json({ error: `Mail-merge is limited to ${MERGE_MAX_RECIPIENTS} recipients per batch` }, 400);
+    if (body.format && !MESSAGE_FORMATS.includes(body.format)) return c.json({ error: 'format must be text or markdown' }, 400);
+
+    const subject = String(body.subject || '');
+    const content = String(body.content || '');
+    const unknown = unknownVariables([subject, content]);
+    if (unknown.length) return c.json({ error: 'Unknown variables: ' + unknown.map(v => `{{${v}}}`).join(', '), unknownVariables: unknown }, 400);
+
+    let orgName: string | undefined;
+    try { orgName = (await getAdminDb?.()?.getSettings())?.name; } catch { /* org name is optional */ }
+    const sender = commBus.getAgentProfile(body.fromAgentId, body.orgId);
+
+    const rendered = toAgentIds.map(toAgentId => {
+      const ctx = buildMergeContext({ contact: commBus.getAgentProfile(toAgentId, body.orgId), agent: sender, orgName });
+      return { toAgentId, subject: applyMergeVariables(subject, ctx), content: applyMergeVariables(content, ctx) };
+    });
+    if (body.dryRun) return c.json({ preview: rendered });
+
+    const messages = [];
+    for (const r of rendered) {
+      messages.push(await commBus.sendMessage({
+        orgId: body.orgId, fromAgentId: body.fromAgentId, toAgentId: r.toAgentId,
+        subject: r.subject, content: r.content, priority: body.priority, format: body.format,
+        metadata: { mailMerge: true },
+      }));
+    }
+    return c.json({ messages, total: messages.length }, 201);
+  });
+
   router.post('/broadcast', async (c) => {
     const body = await c.req.json();
     if (!body.orgId || !body.fromAgentId || !body.agentIds) return c.json({ error: 'orgId, fromAgentId, agentIds required' }, 400);
+    if (body.format && !MESSAGE_FORMATS.includes(body.format)) return c.json({ error: 'format must be text or markdown' }, 400);
     const messages = await commBus.broadcast(body);
     return c.json({ messages, total: messages.length });
   });
@@ -41,23 +99,38 @@ export function createCommunicationRoutes(commBus: AgentCommunicationBus) {
     return c.json({ topology });
   });
 
-  router.get('/', (c) => {
-    const result = commBus.getMessages({
+  // ?format=csv exports every matching message rather than one page
+  router.get('/', async (c) => {
+    const visible = await visibleTo(c);
+    const filters = {
       orgId: c.req.query('orgId') || undefined,
       agentId: c.req.query('agentId') || undefined,
+      agentIds: visible ? [...visible] : undefined,
       type: c.req.query('type') as any || undefined,
       status: c.req.query('status') as any || undefined,
       direction: c.req.query('direction') as any || undefined,
       channel: c.req.query('channel') as any || undefined,
+      q: c.req.query('q') || undefined,
+      sort: c.req.query('sort') ? parseSort(c.req.query('sort'), c.req.query('dir'), MESSAGE_SORT_FIELDS, { field: 'createdAt', dir: 'desc' }) : undefined,
+    };
+    if (wantsCsv(c)) {
+      return csvResponse('messages', [
+        { header: 'id' }, { header: 'createdAt' }, { header: 'fromAgentId' }, { header: 'toAgentId' },
+        { header: 'type' }, { header: 'status' }, { header: 'priority' }, { header: 'direction' }, { header: 'channel' },
+        { header: 'subject' }, { header: 'content' }, { header: 'parentId' }, { header: 'completedAt' },
+      ], async (offset, limit) => commBus.getMessages({ ...filters, limit, offset }).messages);
+    }
+    const result = commBus.getMessages({
+      ...filters,
       limit: parseInt(c.req.query('limit') || '50'),
       offset: parseInt(c.req.query('offset') || '0'),
     });
     return c.json(result);
   });
 
-  router.get('/:id', (c) => {
+  router.get('/:id', async (c) => {
     const msg = commBus.getMessage(c.req.param('id'));
-    if (!msg) return c.json({ error: 'Message not found' }, 404);
+    if (!msg || !canSee(await visibleTo(c), msg)) return c.json({ error: 'Message not found' }, 404);
     return c.json({ message: msg });
   });
 
@@ -66,7 +139,34 @@ export function createCommunicationRoutes(commBus: AgentCommunicationBus) {
     return c.json({ success: true });
   });
 
-  router.get('/inbox/:agentId', (c) => {
+  // ─── Delivery Timeline ─────────────────────────────────
+
+  router.get('/:id/timeline', async (c) => {
+    const msg = commBus.getMessage(c.req.param('id'));
+    if (!msg || !canSee(await visibleTo(c), msg)) return c.json({ error: 'Message not found' }, 404);
+    return c.json({ events: await commBus.getDeliveryTimeline(msg.id), status: msg.status });
+  });
+
+  // Delivery reports from the send path (provider webhooks, hook pipeline)
+  router.post('/:id/delivery', async (c) => {
+    const body = await c.req.json().catch(() => ({}));
+    if (!DELIVERY_EVENTS.includes(body.event) || body.event === 'resent') return c.json({ error: 'event must be one of queued, sent, delivered, opened, bounced, failed' }, 400);
+    const evt = await commBus.recordDeliveryEvent(c.req.param('id'), body.event, { reason: body.reason, detail: body.detail });
+    if (!evt) return c.json({ error: 'Message not found' }, 404);
+    return c.json({ event: evt }, 201);
+  });
+
+  router.post('/:id/resend', async (c) => {
+    const msg = commBus.getMessage(c.req.param('id'));
+    if (!msg) return c.json({ error: 'Message not found' }, 404);
+    if (msg.status !== 'failed') return c.json({ error: 'Only bounced or failed messages can be resent' }, 409);
+    const copy = await commBus.resend(msg.id);
+    return c.json({ message: copy }, 201);
+  });
+
+  router.get('/inbox/:agentId', async (c) => {
+    const visible = await visibleTo(c);
+    if (visible && !visible.has(c.req.param('agentId'))) return c.json({ messages: [], total: 0 });
     const msgs = commBus.getInbox(c.req.param('agentId'), c.req.query('orgId') || undefined);
     return c.json({ messages: msgs, total: msgs.length });
   });
@@ -24,6 +24,9 @@
 
 import type { EngineDatabase } from './db-adapter.js';
 import type { AgentLifecycleManager } from './lifecycle.js';
+import { renderMarkdown, markdownToText } from '../lib/markdown.js';
+import { sortRows, type SortSpec } from '../lib/sort.js';
+import { filterByQuery } from '../lib/filter.js';
 
 function sj(v: string|null|undefined, fb: any = {}): any { if(!v) return fb; try { return JSON.parse(v); } catch { return fb; } }
 // ─── Types ──────────────────────────────────────────────
@@ -33,6 +36,8 @@ export type MessageStatus = 'pending' | 'delivered' | 'read' | 'completed' | 'fa
 export type MessagePriority = 'low' | 'normal' | 'high' | 'urgent';
 export type CommunicationDirection = 'internal' | 'external_outbound' | 'external_inbound' | 'escalation';
 export type CommunicationChannel = 'direct' | 'email' | 'task';
+export type MessageFormat = 'text' | 'markdown';
+export type DeliveryEventType = 'queued' | 'sent' | 'delivered' | 'opened' | 'bounced' | 'failed' | 'resent';
 
 export interface AgentMessage {
   id: string;
@@ -55,6 +60,24 @@ export interface AgentMessage {
   updatedAt: string;
 }
 
+/**
+ * One step in a message's delivery timeline. Reported by the delivery path
+ * (email provider webhooks, the hook pipeline) via POST /messages/:id/delivery,
+ * or recorded by the bus itself (opened on markRead, resent on resend).
+ */
+export interface DeliveryEvent {
+  id: string;
+  messageId: string;
+  event: DeliveryEventType;
+  reason?: string;
+  detail?: Record<string, any>;
+  createdAt: string;
+  /** true when inferred from the message record rather than a stored event */
+  inferred?: boolean;
+}
+
+export const DELIVERY_EVENTS: DeliveryEventType[] = ['queued', 'sent', 'delivered', 'opened', 'bounced', 'failed', 'resent'];
+
 // ─── Topology Types ─────────────────────────────────────
 
 export interface TopologyNode {
@@ -97,6 +120,19 @@ interface RegistryEntry {
   displayName: string;
 }
 
+// ─── Message Body Parts ─────────────────────────────────
+
+/**
+ * Markdown messages carry pre-rendered HTML and a plain-text alternative in
+ * metadata so email delivery can send multipart/alternative without
+ * re-parsing. `content` always keeps the author's markdown source.
+ */
+function withBodyParts(metadata: Record<string, any>, content: string, format?: MessageFormat): Record<string, any> {
+  const { format: _f, html: _h, text: _t, ...rest } = metadata;
+  if (format !== 'markdown') return rest;
+  return { ...rest, format: 'markdown', html: renderMarkdown(content), text: markdownToText(content) };
+}
+
 // ─── Communication Bus ──────────────────────────────────
 
 export class AgentCommunicationBus {
@@ -525,6 +561,17 @@ export class AgentCommunicationBus {
     return byName?.state;
   }
 
+  /** Name, email and role of an agent — used for mail-merge variables. */
+  getAgentProfile(agentId: string, orgId: string): { name: string; email?: string; role?: string } {
+    const agent = this.lifecycle?.getAgent(agentId)
+      || this.lifecycle?.getAgentsByOrg(orgId).find(a => a.config.name === agentId);
+    return {
+      name: agent ? (agent.config.displayName || agent.config.name) : agentId,
+      email: agent?.config?.email?.address,
+      role: (agent?.config as any)?.identity?.role,
+    };
+  }
+
   // ─── Admin-Initiated Messages ─────────────────────
   // These methods are for admin dashboard use — sending messages
   // directly to agents outside the normal AgenticMail email flow.
@@ -539,6 +586,7 @@ export class AgentCommunicationBus {
     priority?: MessagePriority;
     parentId?: string;
     metadata?: Record<string, any>;
+    format?: MessageFormat;
   }): Promise<AgentMessage> {
     const msg: AgentMessage = {
       id: crypto.randomUUID(),
@@ -548,7 +596,7 @@ export class AgentCommunicationBus {
       type: 'message',
       subject: opts.subject,
       content: opts.content,
-      metadata: opts.metadata || {},
+      metadata: withBodyParts(opts.metadata || {}, opts.content, opts.format),
       status: 'pending',
       parentId: opts.parentId,
       priority: opts.priority || 'normal',
@@ -567,6 +615,7 @@ export class AgentCommunicationBus {
     content: string;
     agentIds: string[];
     priority?: MessagePriority;
+    format?: MessageFormat;
   }): Promise<AgentMessage[]> {
     const results: AgentMessage[] = [];
     for (const toId of opts.agentIds) {
@@ -579,7 +628,7 @@ export class AgentCommunicationBus {
         type: 'broadcast',
         subject: opts.subject,
         content: opts.content,
-        metadata: {},
+        metadata: withBodyParts({}, opts.content, opts.format),
         status: 'pending',
         priority: opts.priority || 'normal',
         direction: 'internal',
@@ -683,25 +732,126 @@ export class AgentCommunicationBus {
     msg.status = 'read';
     msg.updatedAt = new Date().toISOString();
     this.updateInDb(msg);
+    await this.recordDeliveryEvent(messageId, 'opened');
+  }
+
+  // ─── Delivery Timeline ────────────────────────────
+
+  /**
+   * Record a delivery event and move the message status along with it.
+   * Bounces and failures mark the message failed and keep the reason in
+   * metadata.deliveryError for the table view.
+   */
+  async recordDeliveryEvent(messageId: string, event: DeliveryEventType, opts: { reason?: string; detail?: Record<string, any> } = {}): Promise<DeliveryEvent | null> {
+    const msg = this.messages.find(m => m.id === messageId);
+    if (!msg) return null;
+    const evt: DeliveryEvent = {
+      id: crypto.randomUUID(),
+      messageId,
+      event,
+      reason: opts.reason?.slice(0, 1000),
+      detail: opts.detail,
+      createdAt: new Date().toISOString(),
+    };
+    await this.engineDb?.execute(
+      'INSERT INTO message_delivery_events (id, message_id, event, reason, detail, created_at) VALUES (?, ?, ?, ?, ?, ?)',
+      [evt.id, messageId, event, evt.reason || null, evt.detail ? JSON.stringify(evt.detail) : null, evt.createdAt],
+    ).catch((err) => { console.error('[comm] Failed to record delivery event:', err); });
+
+    const failed = event === 'bounced' || event === 'failed';
+    const next: MessageStatus | null =
+      failed ? 'failed'
+      : event === 'delivered' && msg.status === 'pending' ? 'delivered'
+      : event === 'opened' && (msg.status === 'pending' || msg.status === 'delivered') ? 'read'
+      : null;
+    if (next) {
+      msg.status = next;
+      if (failed) msg.metadata = { ...msg.metadata, deliveryError: { event, reason: evt.reason, at: evt.createdAt } };
+      msg.updatedAt = evt.createdAt;
+      this.updateInDb(msg);
+    }
+    return evt;
+  }
+
+  /**
+   * Delivery timeline for a message: stored events, plus queued/sent (and
+   * delivered for internal traffic, which never leaves the bus) inferred from
+   * the message record so older messages still get a timeline.
+   */
+  async getDeliveryTimeline(messageId: string): Promise<DeliveryEvent[]> {
+    const msg = this.messages.find(m => m.id === messageId);
+    if (!msg) return [];
+    let stored: DeliveryEvent[] = [];
+    try {
+      const rows = await this.engineDb?.query<any>(
+        'SELECT * FROM message_delivery_events WHERE message_id = ? ORDER BY created_at ASC', [messageId],
+      ) || [];
+      stored = rows.map((r: any) => ({
+        id: r.id, messageId: r.message_id, event: r.event, reason: r.reason || undefined,
+        detail: r.detail ? sj(r.detail) : undefined, createdAt: new Date(r.created_at).toISOString(),
+      }));
+    } catch { /* table may not exist yet */ }
+
+    const has = (e: DeliveryEventType) => stored.some(s => s.event === e);
+    const inferred = (event: DeliveryEventType, at: string): DeliveryEvent => ({ id: `${messageId}:${event}`, messageId, event, createdAt: at, inferred: true });
+    const out: DeliveryEvent[] = [];
+    if (!has('queued')) out.push(inferred('queued', msg.createdAt));
+    if (!has('sent') && msg.status !== 'pending') out.push(inferred('sent', msg.createdAt));
+    if (!has('delivered') && msg.direction === 'internal' && ['delivered', 'read', 'completed'].includes(msg.status)) out.push(inferred('delivered', msg.createdAt));
+    if (!has('opened') && (msg.status === 'read' || msg.status === 'completed')) out.push(inferred('opened', msg.claimedAt || msg.updatedAt));
+    return [...out, ...stored].sort((a, b) => a.createdAt.localeCompare(b.createdAt));
+  }
+
+  /** Send a copy of a bounced/failed message. The copy links back via parentId. */
+  async resend(messageId: string): Promise<AgentMessage | null> {
+    const original = this.messages.find(m => m.id === messageId);
+    if (!original) return null;
+    const { deliveryError: _e, ...metadata } = original.metadata || {};
+    const now = new Date().toISOString();
+    const copy = await this.persistMessage({
+      ...original,
+      id: crypto.randomUUID(),
+      metadata: { ...metadata, resendOf: original.id },
+      status: 'pending',
+      parentId: original.id,
+      claimedAt: undefined,
+      completedAt: undefined,
+      createdAt: now,
+      updatedAt: now,
+    });
+    await this.recordDeliveryEvent(original.id, 'resent', { detail: { messageId: copy.id } });
+    return copy;
   }
 
   getMessages(opts?: {
     orgId?: string;
     agentId?: string;
+    /** Only messages sent or received by one of these agents */
+    agentIds?: string[];
     type?: MessageType;
     status?: MessageStatus;
     direction?: CommunicationDirection;
     channel?: CommunicationChannel;
+    /** Free-text search over subject, content and agent IDs */
+    q?: string;
+    /** Defaults to newest first */
+    sort?: SortSpec;
     limit?: number;
     offset?: number;
   }): { messages: AgentMessage[]; total: number } {
     let list = [...this.messages];
     if (opts?.orgId) list = list.filter(m => m.orgId === opts.orgId);
     if (opts?.agentId) list = list.filter(m => m.toAgentId === opts.agentId || m.fromAgentId === opts.agentId);
+    if (opts?.agentIds) {
+      const ids = new Set(opts.agentIds);
+      list = list.filter(m => ids.has(m.toAgentId) || ids.has(m.fromAgentId));
+    }
     if (opts?.type) list = list.filter(m => m.type === opts.type);
     if (opts?.status) list = list.filter(m => m.status === opts.status);
     if (opts?.direction) list = list.filter(m => m.direction === opts.direction);
     if (opts?.channel) list = list.filter(m => m.channel === opts.channel);
+    if (opts?.q) list = filterByQuery(list, opts.q, m => [m.subject, m.content, m.fromAgentId, m.toAgentId]);
+    if (opts?.sort) sortRows(list, opts.sort);
     const total = list.length;
     const offset = opts?.offset || 0;
     return { messages: list.slice(offset, offset + (opts?.limit || 50)), total };
@@ -729,6 +879,11 @@ export class AgentCommunicationBus {
     };
   }
 
+  /** Every persisted message, regardless of recipient (labelling rules, etc.). */
+  onAnyMessage(callback: (msg: AgentMessage) => void): () => void {
+    return this.onMessage('*', callback);
+  }
+
   private notifyListeners(agentId: string, msg: AgentMessage): void {
     const cbs = this.listeners.get(agentId) || [];
     for (const cb of cbs) {
@@ -743,6 +898,7 @@ export class AgentCommunicationBus {
     if (this.messages.length > 2000) this.messages = this.messages.slice(0, 2000);
 
     this.notifyListeners(msg.toAgentId, msg);
+    this.notifyListeners('*', msg);
 
     this.engineDb?.execute(
       `INSERT INTO agent_messages (id, org_id, from_agent_id, to_agent_id, type, subject, content, metadata, status, parent_id, priority, direction, channel, deadline, created_at, updated_at)
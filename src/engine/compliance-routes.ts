@@ -1,14 +1,22 @@
 /**
  * Compliance Reporting Routes
  * Mounted at /compliance/* on the engine sub-app.
+ *
+ * Reports are meant to be read in the dashboard's watermarked viewer
+ * (/reports/:id/view); views and downloads are both audited.
  */
 
 import { Hono } from 'hono';
 import type { ComplianceReporter } from './compliance.js';
+import type { DatabaseAdapter } from '../db/adapter.js';
+import { viewerStamp } from '../lib/watermark.js';
+import { auditFromEngine } from './route-audit.js';
 
-export function createComplianceRoutes(compliance: ComplianceReporter) {
+export function createComplianceRoutes(compliance: ComplianceReporter, deps: { getAdminDb?: () => DatabaseAdapter | null } = {}) {
   const router = new Hono();
 
+  const audit = auditFromEngine(deps.getAdminDb, 'compliance');
+
   router.post('/reports/soc2', async (c) => {
     try {
       const { orgId, dateRange, agentIds } = await c.req.json();
@@ -98,12 +106,27 @@ export function createComplianceRoutes(compliance: ComplianceReporter) {
     return c.json({ success: true });
   });
 
+  // The printable HTML report plus a stamp of who is viewing it, for the watermark
+  router.get('/reports/:id/view', (c) => {
+    const report = compliance.getReport(c.req.param('id'));
+    if (!report) return c.json({ error: 'Report not found' }, 404);
+    if (report.status !== 'completed') return c.json({ error: 'Report is not ready' }, 409);
+    const viewer = viewerStamp(c);
+    audit(c, 'report_view', `compliance_report:${report.id}`, { type: report.type, title: report.title, viewer: viewer.label }, report.orgId);
+    return c.json({
+      report: { id: report.id, type: report.type, title: report.title, createdAt: report.createdAt },
+      html: compliance.toHTML(report),
+      viewer,
+    });
+  });
+
   router.get('/reports/:id/download', (c) => {
     try {
       const report = compliance.getReport(c.req.param('id'));
       if (!report) return c.json({ error: 'Report not found' }, 404);
 
       const format = c.req.query('format') || report.format;
+      audit(c, 'report_download', `compliance_report:${report.id}`, { type: report.type, format }, report.orgId);
       const ts = report.createdAt?.split('T')[0] || 'report';
       const fname = `${report.type}-${ts}-${report.id.substring(0, 8)}`;
 
@@ -114,6 +137,12 @@ export function createComplianceRoutes(compliance: ComplianceReporter) {
         return c.body(csv);
       }
 
+      if (format === 'pdf') {
+        return new Response(compliance.toPDF(report), {
+          headers: { 'Content-Type': 'application/pdf', 'Content-Disposition': `attachment; filename="${fname}.pdf"` },
+        });
+      }
+
       if (format === 'html') {
         const html = compliance.toHTML(report);
         c.header('Content-Type', 'text/html; charset=utf-8');
@@ -16,6 +16,7 @@
  */
 
 import type { EngineDatabase } from './db-adapter.js';
+import { PdfDocument } from '../lib/pdf.js';
 
 function sj(v: string|null|undefined, fb: any = {}): any { if(!v) return fb; try { return JSON.parse(v); } catch { return fb; } }
 
@@ -1167,6 +1168,68 @@ export class ComplianceReporter {
     return parts.join('\n');
   }
 
+  // ─── PDF Export ────────────────────────────────────
+
+  /**
+   * Paginated PDF of the report. The tables are the same per-type sections
+   * as the CSV export, so the two downloads always agree on content.
+   */
+  toPDF(report: ComplianceReport): Buffer {
+    const d = report.data || {};
+    const doc = new PdfDocument({ title: report.title, footer: `${report.title} \u2022 Report ID ${report.id}`, landscape: true });
+    const orgDisplay = d._orgName || report.orgId || '';
+    doc.heading(report.title, 1);
+    doc.paragraph(`${this.typeLabel(report.type)}${orgDisplay ? ' \u2022 ' + orgDisplay : ''} \u2022 Generated ${new Date(report.createdAt).toLocaleString()} \u2022 by ${d._generatedByName || report.generatedBy}`, { gray: 0.4 });
+
+    const meta = Object.entries(d.reportMetadata || {}).filter(([, v]) => typeof v !== 'object');
+    if (meta.length) doc.keyValues(meta);
+    if (!report.data) return doc.paragraph('No data.').toBuffer();
+
+    const agentMap: Record<string, string> = d._agentNameMap || {};
+    const sections = this.csvSections(this.toCSV(report));
+    for (const section of sections) {
+      doc.heading(section.title, 2);
+      // Show agent names instead of bare IDs, as the HTML report does
+      const agentCols = section.headers.map((h, i) => /^agent( id)?$/i.test(h) ? i : -1).filter(i => i >= 0);
+      const rows = section.rows.map(r => r.map((v, i) => agentCols.includes(i) && agentMap[v] ? `${agentMap[v]} (${v.slice(0, 8)})` : v));
+      doc.table(section.headers, rows);
+    }
+    return doc.toBuffer();
+  }
+
+  /** Split toCSV output into one table per `=== TITLE ===` section (header row first). */
+  private csvSections(csv: string): Array<{ title: string; headers: string[]; rows: string[][] }> {
+    const sections: Array<{ title: string; headers: string[]; rows: string[][] }> = [];
+    let title = 'Details';
+    let current: { title: string; headers: string[]; rows: string[][] } | null = null;
+    for (const line of csv.split('\n')) {
+      const m = line.match(/^=== (.+) ===$/);
+      if (m) { title = m[1].toLowerCase().replace(/\b\w/g, c => c.toUpperCase()).replace(/\b(Cc\d|Gdpr|Dlp|Id)\b/g, w => w.toUpperCase()); current = null; continue; }
+      if (!line.trim()) { current = null; continue; }
+      const cells = this.parseCsvLine(line);
+      if (!current) { current = { title, headers: cells, rows: [] }; sections.push(current); continue; }
+      current.rows.push(cells);
+    }
+    return sections;
+  }
+
+  private parseCsvLine(line: string): string[] {
+    const cells: string[] = [];
+    let cell = '', quoted = false;
+    for (let i = 0; i < line.length; i++) {
+      const ch = line[i];
+      if (quoted) {
+        if (ch === '"' && line[i + 1] === '"') { cell += '"'; i++; }
+        else if (ch === '"') quoted = false;
+        else cell += ch;
+      } else if (ch === '"') quoted = true;
+      else if (ch === ',') { cells.push(cell); cell = ''; }
+      else cell += ch;
+    }
+    cells.push(cell);
+    return cells;
+  }
+
   private async resolveOrgName(orgId: string): Promise<string> {
     try {
       const org = await this.q('SELECT name FROM organizations WHERE id = ?', [orgId]);
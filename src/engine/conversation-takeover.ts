@@ -0,0 +1,161 @@
+/**
+ * Conversation Takeover — Human-in-the-loop for live chats
+ *
+ * A dashboard user can take over one WhatsApp/Telegram conversation:
+ *   - the agent is paused for that thread only (inbound messages are still
+ *     stored in messaging_history, but not dispatched to the agent)
+ *   - the human replies from the dashboard through the agent's own channel
+ *   - releasing the thread hands it back; the agent sees the human's replies
+ *     in its conversation history on the next inbound message
+ *
+ * Active takeovers are cached in memory so the messaging poller can check a
+ * thread synchronously on every inbound message.
+ */
+
+import type { EngineDatabase } from './db-adapter.js';
+
+// ─── Types ──────────────────────────────────────────────
+
+export type TakeoverStatus = 'active' | 'released';
+
+export interface ConversationTakeover {
+  id: string;
+  orgId?: string;
+  agentId: string;
+  platform: string;
+  contactId: string;
+  status: TakeoverStatus;
+  takenBy: string;
+  reason?: string;
+  replyCount: number;
+  takenAt: string;
+  releasedBy?: string;
+  releasedAt?: string;
+}
+
+// ─── Config ─────────────────────────────────────────────
+
+export const TAKEOVER_PLATFORMS = ['whatsapp', 'telegram'];
+const HISTORY_LIMIT = 50;
+
+// ─── Takeover Manager ───────────────────────────────────
+
+export class ConversationTakeoverManager {
+  private engineDb?: EngineDatabase;
+  /** `${agentId}:${platform}:${contactId}` → active takeover */
+  private active = new Map<string, ConversationTakeover>();
+
+  async setDb(db: EngineDatabase): Promise<void> {
+    this.engineDb = db;
+    try {
+      const rows = await db.query<any>("SELECT * FROM conversation_takeovers WHERE status = 'active'");
+      this.active.clear();
+      for (const r of rows) {
+        const t = this.rowToTakeover(r);
+        this.active.set(threadKey(t.agentId, t.platform, t.contactId), t);
+      }
+    } catch { /* table may not exist yet */ }
+  }
+
+  /** Is a human currently handling this thread? Called on every inbound message. */
+  isActive(agentId: string, platform: string, contactId: string): boolean {
+    return this.active.has(threadKey(agentId, platform, contactId));
+  }
+
+  getActive(agentId: string, platform: string, contactId: string): ConversationTakeover | null {
+    return this.active.get(threadKey(agentId, platform, contactId)) || null;
+  }
+
+  listActive(opts: { agentId?: string; orgId?: string } = {}): ConversationTakeover[] {
+    return [...this.active.values()]
+      .filter(t => (!opts.agentId || t.agentId === opts.agentId) && (!opts.orgId || t.orgId === opts.orgId))
+      .sort((a, b) => b.takenAt.localeCompare(a.takenAt));
+  }
+
+  async get(id: string): Promise<ConversationTakeover | null> {
+    for (const t of this.active.values()) if (t.id === id) return t;
+    if (!this.engineDb) return null;
+    const row = await this.engineDb.get<any>('SELECT * FROM conversation_takeovers WHERE id = ?', [id]);
+    return row ? this.rowToTakeover(row) : null;
+  }
+
+  /** Past and current takeovers of one thread, newest first. */
+  async history(agentId: string, platform: string, contactId: string): Promise<ConversationTakeover[]> {
+    if (!this.engineDb) return [];
+    const rows = await this.engineDb.query<any>(
+      `SELECT * FROM conversation_takeovers WHERE agent_id = ? AND platform = ? AND contact_id = ?
+       ORDER BY taken_at DESC LIMIT ${HISTORY_LIMIT}`,
+      [agentId, platform, contactId],
+    );
+    return rows.map((r: any) => this.rowToTakeover(r));
+  }
+
+  /** Pause the agent for a thread. Throws if someone already holds it. */
+  async takeOver(input: { agentId: string; platform: string; contactId: string; takenBy: string; orgId?: string; reason?: string }): Promise<ConversationTakeover> {
+    if (!this.engineDb) throw new Error('Takeover storage not initialized');
+    if (!TAKEOVER_PLATFORMS.includes(input.platform)) throw new Error(`Unsupported platform: ${input.platform}`);
+    const existing = this.getActive(input.agentId, input.platform, input.contactId);
+    if (existing) throw new Error(`Conversation is already taken over by ${existing.takenBy}`);
+
+    const takeover: ConversationTakeover = {
+      id: crypto.randomUUID(),
+      orgId: input.orgId || undefined,
+      agentId: input.agentId,
+      platform: input.platform,
+      contactId: input.contactId,
+      status: 'active',
+      takenBy: input.takenBy,
+      reason: input.reason?.slice(0, 500) || undefined,
+      replyCount: 0,
+      takenAt: new Date().toISOString(),
+    };
+    await this.engineDb.execute(
+      `INSERT INTO conversation_takeovers (id, org_id, agent_id, platform, contact_id, status, taken_by, reason, reply_count, taken_at)
+       VALUES (?, ?, ?, ?, ?, 'active', ?, ?, 0, ?)`,
+      [takeover.id, takeover.orgId || null, takeover.agentId, takeover.platform, takeover.contactId,
+       takeover.takenBy, takeover.reason || null, takeover.takenAt],
+    );
+    this.active.set(threadKey(takeover.agentId, takeover.platform, takeover.contactId), takeover);
+    return takeover;
+  }
+
+  async recordReply(id: string): Promise<void> {
+    const t = [...this.active.values()].find(a => a.id === id);
+    if (t) t.replyCount++;
+    await this.engineDb?.execute('UPDATE conversation_takeovers SET reply_count = reply_count + 1 WHERE id = ?', [id]);
+  }
+
+  /** Hand the thread back to the agent. Returns null if it wasn't active. */
+  async release(id: string, releasedBy: string): Promise<ConversationTakeover | null> {
+    const t = [...this.active.values()].find(a => a.id === id);
+    if (!t) return null;
+    const releasedAt = new Date().toISOString();
+    await this.engineDb?.execute(
+      "UPDATE conversation_takeovers SET status = 'released', released_by = ?, released_at = ? WHERE id = ?",
+      [releasedBy, releasedAt, id],
+    );
+    this.active.delete(threadKey(t.agentId, t.platform, t.contactId));
+    return { ...t, status: 'released', releasedBy, releasedAt };
+  }
+
+  private rowToTakeover(r: any): ConversationTakeover {
+    return {
+      id: r.id,
+      orgId: r.org_id || undefined,
+      agentId: r.agent_id,
+      platform: r.platform,
+      contactId: r.contact_id,
+      status: r.status,
+      takenBy: r.taken_by,
+      reason: r.reason || undefined,
+      replyCount: Number(r.reply_count) || 0,
+      takenAt: new Date(r.taken_at).toISOString(),
+      releasedBy: r.released_by || undefined,
+      releasedAt: r.released_at ? new Date(r.released_at).toISOString() : undefined,
+    };
+  }
+}
+
+function threadKey(agentId: string, platform: string, contactId: string): string {
+  return `${agentId}:${platform}:${contactId}`;
+}
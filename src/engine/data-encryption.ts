@@ -0,0 +1,181 @@
+/**
+ * Data Encryption Report — Which stored fields are encrypted at rest
+ *
+ * Each sensitive column is listed with how the engine writes it: through
+ * the SecureVault (AES-256-GCM), wrapped in a vault envelope inside a JSON
+ * config, or as plain text. For the encrypted ones the rows are checked,
+ * so data written before encryption was turned on shows up as "partial"
+ * rather than being assumed safe.
+ *
+ * Disk or database-level encryption (encrypted volumes, managed database
+ * TDE) is invisible from here and isn't reported; this covers what the
+ * application itself encrypts.
+ *
+ * The encryption policy (securityConfig.encryptionPolicy) names the data
+ * classes that must be encrypted; an unmet policy fails the security
+ * posture (see security-posture.ts).
+ */
+
+import type { EngineDatabase } from './db-adapter.js';
+import type { SecureVault } from './vault.js';
+
+// ─── Types ──────────────────────────────────────────────
+
+export type DataClass = 'messages' | 'persona' | 'memory' | 'secrets' | 'credentials';
+
+/** How the engine writes a field: vault-encrypted, a vault envelope in JSON, or plain */
+export type FieldProtection = 'vault' | 'envelope' | 'none';
+
+export type EncryptionStatus = 'encrypted' | 'partial' | 'plaintext';
+
+export interface EncryptedFieldReport {
+  dataClass: DataClass;
+  table: string;
+  column: string;
+  label: string;
+  protection: FieldProtection;
+  /** AES-256-GCM, or null when the field is stored as written */
+  algorithm: string | null;
+  status: EncryptionStatus;
+  rows: number;
+  encryptedRows: number;
+}
+
+export interface DataClassReport {
+  key: DataClass;
+  label: string;
+  description: string;
+  status: EncryptionStatus;
+  fields: EncryptedFieldReport[];
+}
+
+export interface EncryptionPolicy {
+  /** Data classes that must be encrypted at rest */
+  requiredClasses: DataClass[];
+}
+
+export interface EncryptionPolicyResult {
+  met: boolean;
+  unmet: Array<{ dataClass: DataClass; label: string; reason: string }>;
+}
+
+export interface DataEncryptionReport {
+  generatedAt: string;
+  /** False while the vault runs on the built-in development key */
+  keyConfigured: boolean;
+  classes: DataClassReport[];
+  policy: EncryptionPolicy;
+  evaluation: EncryptionPolicyResult;
+}
+
+// ─── Config ─────────────────────────────────────────────
+
+export const DATA_CLASSES: Array<{ key: DataClass; label: string; description: string }> = [
+  { key: 'messages', label: 'Message bodies', description: 'Internal agent messages and channel conversations (WhatsApp, Telegram, ...)' },
+  { key: 'persona', label: 'Persona data', description: 'Agent identity, personality and configuration' },
+  { key: 'memory', label: 'Agent memory', description: 'What agents have learned and remembered' },
+  { key: 'secrets', label: 'Vault values', description: 'API keys, passwords and other secrets stored in the vault' },
+  { key: 'credentials', label: 'Deploy credentials', description: 'Cloud and server credentials used to deploy agents' },
+];
+
+interface StoredField {
+  dataClass: DataClass;
+  table: string;
+  column: string;
+  label: string;
+  protection: FieldProtection;
+}
+
+const STORED_FIELDS: StoredField[] = [
+  { dataClass: 'messages', table: 'agent_messages', column: 'content', label: 'Internal message bodies', protection: 'none' },
+  { dataClass: 'messages', table: 'messaging_history', column: 'message_text', label: 'Channel message text', protection: 'none' },
+  { dataClass: 'persona', table: 'managed_agents', column: 'config', label: 'Agent config and identity', protection: 'none' },
+  { dataClass: 'memory', table: 'agent_memory', column: 'content', label: 'Memory entries', protection: 'none' },
+  { dataClass: 'secrets', table: 'vault_entries', column: 'encrypted_value', label: 'Vault secret values', protection: 'vault' },
+  { dataClass: 'credentials', table: 'deploy_credentials', column: 'config', label: 'Deploy credential config', protection: 'envelope' },
+];
+
+const RANK: Record<EncryptionStatus, number> = { encrypted: 0, partial: 1, plaintext: 2 };
+
+export function normalizeEncryptionPolicy(raw: any): EncryptionPolicy {
+  const known = new Set(DATA_CLASSES.map(c => c.key));
+  const classes = Array.isArray(raw?.requiredClasses) ? raw.requiredClasses.filter((c: any) => known.has(c)) : [];
+  return { requiredClasses: Array.from(new Set<DataClass>(classes)) };
+}
+
+/** A required class passes only when every field is fully encrypted with a real key */
+export function evaluateEncryptionPolicy(
+  classes: DataClassReport[], policy: EncryptionPolicy, keyConfigured: boolean,
+): EncryptionPolicyResult {
+  const unmet: EncryptionPolicyResult['unmet'] = [];
+  for (const key of policy.requiredClasses) {
+    const cls = classes.find(c => c.key === key);
+    if (!cls) continue;
+    if (cls.status === 'plaintext') {
+      unmet.push({ dataClass: key, label: cls.label, reason: 'Stored unencrypted' });
+    } else if (cls.status === 'partial') {
+      const rows = cls.fields.reduce((n, f) => n + f.rows - f.encryptedRows, 0);
+      unmet.push({ dataClass: key, label: cls.label, reason: `${rows} row${rows === 1 ? '' : 's'} not yet encrypted` });
+    } else if (!keyConfigured) {
+      unmet.push({ dataClass: key, label: cls.label, reason: 'Encrypted with the built-in development key; set AGENTICMAIL_VAULT_KEY' });
+    }
+  }
+  return { met: unmet.length === 0, unmet };
+}
+
+// ─── Report ─────────────────────────────────────────────
+
+export class DataEncryption {
+  private engineDb?: EngineDatabase;
+  private textType?: 'TEXT' | 'CHAR';
+
+  constructor(private deps: { vault: SecureVault }) {}
+
+  async setDb(db: EngineDatabase): Promise<void> {
+    this.engineDb = db;
+  }
+
+  async getReport(rawPolicy?: any): Promise<DataEncryptionReport> {
+    if (!this.engineDb) throw new Error('Data encryption database not initialized');
+    const db = this.engineDb;
+    if (!this.textType) {
+      this.textType = await db.get<any>("SELECT LENGTH(CAST('x' AS TEXT)) AS n", []).then(() => 'TEXT' as const, () => 'CHAR' as const);
+    }
+
+    const fields: EncryptedFieldReport[] = [];
+    for (const f of STORED_FIELDS) {
+      // JSON columns need a cast before LIKE on Postgres
+      const col = `CAST(${f.column} AS ${this.textType})`;
+      const marker = f.protection === 'vault' ? `%"alg":"aes-256-gcm"%` : `%"_encrypted"%`;
+      const encryptedExpr = f.protection === 'none' ? '0' : `SUM(CASE WHEN ${col} LIKE ? THEN 1 ELSE 0 END)`;
+      // A table missing on this backend shouldn't hide the rest of the report
+      const row = await db.get<any>(
+        `SELECT COUNT(*) AS c, ${encryptedExpr} AS e FROM ${f.table}`, f.protection === 'none' ? [] : [marker],
+      ).catch(() => undefined);
+      const rows = Number(row?.c || 0);
+      const encryptedRows = Number(row?.e || 0);
+      const status: EncryptionStatus = f.protection === 'none' ? 'plaintext'
+        : encryptedRows >= rows ? 'encrypted'
+        : encryptedRows === 0 ? 'plaintext' : 'partial';
+      fields.push({ ...f, algorithm: f.protection === 'none' ? null : 'AES-256-GCM', status, rows, encryptedRows });
+    }
+
+    const classes: DataClassReport[] = DATA_CLASSES.map(c => {
+      const own = fields.filter(f => f.dataClass === c.key);
+      const worst = own.reduce<EncryptionStatus>((s, f) => RANK[f.status] > RANK[s] ? f.status : s, 'encrypted');
+      // Some fields encrypted and others not is partial, not plaintext
+      const status = worst === 'plaintext' && own.some(f => f.status !== 'plaintext') ? 'partial' : worst;
+      return { ...c, status, fields: own };
+    });
+
+    const keyConfigured = this.deps.vault.isConfigured();
+    const policy = normalizeEncryptionPolicy(rawPolicy);
+    return {
+      generatedAt: new Date().toISOString(),
+      keyConfigured,
+      classes,
+      policy,
+      evaluation: evaluateEncryptionPolicy(classes, policy, keyConfigured),
+    };
+  }
+}
@@ -0,0 +1,53 @@
+/**
+ * Data Residency Routes
+ * Mounted at /residency/* on the engine sub-app.
+ */
+
+import { Hono } from 'hono';
+import type { DataResidency } from './data-residency.js';
+import type { DatabaseAdapter } from '../db/adapter.js';
+import { auditFromEngine } from './route-audit.js';
+
+export function createDataResidencyRoutes(residency: DataResidency, deps: { getAdminDb?: () => DatabaseAdapter | null } = {}) {
+  const router = new Hono();
+
+  const audit = auditFromEngine(deps.getAdminDb);
+
+  // Where each subsystem stores/processes data, plus per-agent pinning
+  router.get('/', async (c) => {
+    try {
+      return c.json(await residency.getReport(c.req.query('orgId') || 'default'));
+    } catch (e: any) { return c.json({ error: e.message }, 500); }
+  });
+
+  router.get('/config', (c) => c.json({ config: residency.getConfig() }));
+
+  // { regions, defaultRegion, enforce, declared }
+  router.put('/config', async (c) => {
+    const body = await c.req.json().catch(() => ({}));
+    try {
+      const before = residency.getConfig();
+      const config = await residency.saveConfig(body);
+      audit(c, 'residency.config_update', 'data_residency', {
+        regions: config.regions.map(r => r.id), defaultRegion: config.defaultRegion, enforce: config.enforce,
+        previous: { regions: before.regions.map(r => r.id), defaultRegion: before.defaultRegion, enforce: before.enforce },
+      });
+      return c.json({ success: true, config });
+    } catch (e: any) { return c.json({ error: e.message }, 500); }
+  });
+
+  // { region } — null unpins
+  router.put('/agents/:id', async (c) => {
+    const id = c.req.param('id');
+    const body = await c.req.json().catch(() => ({}));
+    try {
+      const agent = await residency.pinAgent(id, body.region || null, c.req.header('X-User-Id') || 'dashboard');
+      audit(c, 'residency.agent_pin', `agent:${id}`, { region: body.region || null }, agent.orgId);
+      return c.json({ success: true, dataRegion: (agent.config as any).dataRegion || null });
+    } catch (e: any) {
+      return c.json({ error: e.message }, /not found/i.test(e.message) ? 404 : 400);
+    }
+  });
+
+  return router;
+}
@@ -0,0 +1,261 @@
+/**
+ * Data Residency — Where org data is stored and processed, and region pinning
+ *
+ * The report lists each subsystem (database, file storage, the server
+ * itself, agent hosting, model inference, email) with the region it runs
+ * in. Regions are detected where the deployment gives them away (cloud
+ * region env vars, AWS-style region names in database hostnames, the S3
+ * bucket region); anything else can be declared by an admin, and is shown
+ * as unknown until it is.
+ *
+ * For multi-region deployments the config defines residency regions (e.g.
+ * "eu" → Fly fra/ams, AWS eu-central-1). Agents — and with them their
+ * mailboxes — are pinned to one at creation (the default region unless the
+ * wizard picks another). On deploy a pinned agent's cloud region is filled
+ * in from its residency region, and with `enforce` a cloud region outside
+ * it fails the deploy.
+ *
+ * Config lives in engine_settings under 'data_residency'.
+ */
+
+import type { EngineDatabase } from './db-adapter.js';
+import type { AgentLifecycleManager, ManagedAgent } from './lifecycle.js';
+import type { StorageManager } from './storage-manager.js';
+import type { DatabaseAdapter } from '../db/adapter.js';
+import { PROVIDER_REGISTRY } from '../runtime/providers.js';
+
+// ─── Types ──────────────────────────────────────────────
+
+export interface ResidencyRegion {
+  id: string;
+  label: string;
+  /** Legal jurisdiction shown to customers, e.g. "EU (GDPR)" */
+  jurisdiction?: string;
+  /** Cloud regions that count as inside it (fra, ams, eu-central-1, ...) */
+  deployRegions: string[];
+}
+
+export type ResidencySubsystem = 'database' | 'storage' | 'runtime' | 'agents' | 'models' | 'email';
+
+export interface ResidencyConfig {
+  regions: ResidencyRegion[];
+  /** Region new agents are pinned to when none is picked */
+  defaultRegion?: string;
+  /** Fail deploys that would put a pinned agent outside its region */
+  enforce: boolean;
+  /** Admin-declared locations for subsystems that can't be detected */
+  declared: Partial<Record<ResidencySubsystem, string>>;
+}
+
+export interface SubsystemLocation {
+  key: ResidencySubsystem;
+  label: string;
+  /** What runs it: postgres, s3, fly, anthropic, ... */
+  provider: string;
+  /** Region or location, null when unknown */
+  location: string | null;
+  source: 'detected' | 'declared' | 'unknown';
+  detail?: string;
+}
+
+export interface AgentResidency {
+  agentId: string;
+  name: string;
+  dataRegion: string | null;
+  target: string | null;
+  hostRegion: string | null;
+  status: 'ok' | 'outside' | 'unpinned' | 'unverified';
+}
+
+export interface ResidencyReport {
+  generatedAt: string;
+  config: ResidencyConfig;
+  multiRegion: boolean;
+  subsystems: SubsystemLocation[];
+  agents: AgentResidency[];
+}
+
+// ─── Config ─────────────────────────────────────────────
+
+const SETTINGS_KEY = 'data_residency';
+
+const SUBSYSTEMS: Array<{ key: ResidencySubsystem; label: string }> = [
+  { key: 'database', label: 'Primary database' },
+  { key: 'storage', label: 'File storage' },
+  { key: 'runtime', label: 'Application server' },
+  { key: 'agents', label: 'Agent hosting' },
+  { key: 'models', label: 'Model inference' },
+  { key: 'email', label: 'Email delivery' },
+];
+
+/** Env vars cloud platforms set with the region the process runs in */
+const RUNTIME_REGION_ENV: Array<[string, string]> = [
+  ['FLY_REGION', 'fly'],
+  ['AWS_REGION', 'aws'],
+  ['AWS_DEFAULT_REGION', 'aws'],
+  ['RAILWAY_REPLICA_REGION', 'railway'],
+  ['GOOGLE_CLOUD_REGION', 'gcp'],
+  ['REGION_NAME', 'azure'],
+];
+
+const CLOUD_REGION_RE = /\b((?:us|eu|ap|ca|sa|me|af|il|mx)-(?:gov-)?[a-z]+-\d)\b/;
+
+export function normalizeResidencyConfig(raw: any): ResidencyConfig {
+  const regions: ResidencyRegion[] = [];
+  for (const r of Array.isArray(raw?.regions) ? raw.regions : []) {
+    const id = String(r?.id || '').trim().toLowerCase();
+    if (!/^[a-z0-9-]{1,32}$/.test(id) || regions.some(x => x.id === id)) continue;
+    regions.push({
+      id,
+      label: String(r.label || id).slice(0, 80),
+      jurisdiction: r.jurisdiction ? String(r.jurisdiction).slice(0, 80) : undefined,
+      deployRegions: Array.isArray(r.deployRegions) ? r.deployRegions.map((d: any) => String(d).trim().toLowerCase()).filter(Boolean) : [],
+    });
+  }
+  const declared: ResidencyConfig['declared'] = {};
+  for (const s of SUBSYSTEMS) {
+    const v = raw?.declared?.[s.key];
+    if (typeof v === 'string' && v.trim()) declared[s.key] = v.trim().slice(0, 80);
+  }
+  return {
+    regions,
+    defaultRegion: regions.some(r => r.id === raw?.defaultRegion) ? raw.defaultRegion : undefined,
+    enforce: raw?.enforce === true,
+    declared,
+  };
+}
+
+/** AWS-style region in a hostname (Neon, Supabase, RDS, ...), or null */
+export function regionFromHost(host?: string | null): string | null {
+  return host ? host.toLowerCase().match(CLOUD_REGION_RE)?.[1] || null : null;
+}
+
+// ─── Data Residency ─────────────────────────────────────
+
+export class DataResidency {
+  private engineDb?: EngineDatabase;
+  private config: ResidencyConfig = normalizeResidencyConfig({});
+
+  constructor(private deps: {
+    lifecycle: AgentLifecycleManager;
+    storage: StorageManager;
+    getAdminDb: () => DatabaseAdapter | null;
+  }) {}
+
+  async setDb(db: EngineDatabase): Promise<void> {
+    this.engineDb = db;
+    const row = await db.get<any>('SELECT value FROM engine_settings WHERE key = ?', [SETTINGS_KEY]).catch(() => undefined);
+    try { this.config = normalizeResidencyConfig(row?.value ? JSON.parse(row.value) : {}); } catch { /* keep defaults */ }
+  }
+
+  getConfig(): ResidencyConfig {
+    return this.config;
+  }
+
+  async saveConfig(raw: any): Promise<ResidencyConfig> {
+    if (!this.engineDb) throw new Error('Data residency database not initialized');
+    const config = normalizeResidencyConfig(raw);
+    await this.engineDb.execute('DELETE FROM engine_settings WHERE key = ?', [SETTINGS_KEY]);
+    await this.engineDb.execute('INSERT INTO engine_settings (key, value) VALUES (?, ?)', [SETTINGS_KEY, JSON.stringify(config)]);
+    this.config = config;
+    return config;
+  }
+
+  getRegion(id?: string | null): ResidencyRegion | undefined {
+    return id ? this.config.regions.find(r => r.id === id) : undefined;
+  }
+
+  /** Pin an existing agent (and its mailbox); null unpins */
+  async pinAgent(agentId: string, regionId: string | null, by: string): Promise<ManagedAgent> {
+    if (regionId && !this.getRegion(regionId)) throw new Error(`Unknown residency region: ${regionId}`);
+    return this.deps.lifecycle.updateConfig(agentId, { dataRegion: regionId || undefined } as any, by);
+  }
+
+  /**
+   * Lifecycle hook. On create, pins unpinned agents to the default region.
+   * On deploy, fills in the cloud region from the residency region and,
+   * when enforcing, rejects one outside it.
+   */
+  route = async (agent: ManagedAgent, phase: 'create' | 'deploy'): Promise<void> => {
+    const config = agent.config as any;
+    if (phase === 'create') {
+      if (config.dataRegion && !this.getRegion(config.dataRegion)) throw new Error(`Unknown residency region: ${config.dataRegion}`);
+      if (!config.dataRegion && this.config.defaultRegion) config.dataRegion = this.config.defaultRegion;
+      return;
+    }
+    const region = this.getRegion(config.dataRegion);
+    const cloud = config.deployment?.config?.cloud;
+    if (!region || !region.deployRegions.length || !cloud) return;
+    if (!cloud.region) {
+      cloud.region = region.deployRegions[0];
+    } else if (this.config.enforce && !region.deployRegions.includes(String(cloud.region).toLowerCase())) {
+      throw new Error(`Deploy region "${cloud.region}" is outside the agent's data residency region (${region.label}: ${region.deployRegions.join(', ')})`);
+    }
+  };
+
+  async getReport(orgId: string): Promise<ResidencyReport> {
+    const declared = this.config.declared;
+    const located = (key: ResidencySubsystem, provider: string, detected: string | null, detail?: string): SubsystemLocation => {
+      const label = SUBSYSTEMS.find(s => s.key === key)!.label;
+      if (detected) return { key, label, provider, location: detected, source: 'detected', detail };
+      if (declared[key]) return { key, label, provider, location: declared[key]!, source: 'declared', detail };
+      return { key, label, provider, location: null, source: 'unknown', detail };
+    };
+
+    // The server's own region: what local storage and local agents inherit
+    const runtimeEnv = RUNTIME_REGION_ENV.find(([name]) => process.env[name]);
+    const serverRegion = runtimeEnv ? `${runtimeEnv[1]}:${process.env[runtimeEnv[0]]}` : null;
+
+    const adminDb = this.deps.getAdminDb();
+    let dbHost: string | null = null;
+    try { dbHost = process.env.DATABASE_URL ? new URL(process.env.DATABASE_URL).hostname : null; } catch { /* not a URL (sqlite path) */ }
+    const dbType = adminDb?.type || 'unknown';
+    const dbLocal = !dbHost || dbHost === 'localhost' || dbHost === '127.0.0.1' || dbType === 'sqlite';
+    const database = located('database', dbType, regionFromHost(dbHost) || (dbLocal ? serverRegion : null),
+      dbLocal ? 'Runs alongside the application server' : dbHost || undefined);
+
+    const storageCfg = await this.deps.storage.getStorageConfig(orgId).catch(() => null);
+    const storageType = storageCfg?.enabled ? storageCfg.storageType : 'local';
+    const storage = located('storage', storageType,
+      storageType === 's3' ? (storageCfg!.config?.region || 'us-east-1') : storageType === 'local' ? serverRegion : null,
+      storageType === 'local' ? 'Stored on the application server' : storageCfg?.config?.bucket || storageCfg?.config?.containerName);
+
+    const runtime = located('runtime', runtimeEnv?.[1] || 'self-hosted', serverRegion,
+      runtimeEnv ? `From ${runtimeEnv[0]}` : undefined);
+
+    const agents = this.deps.lifecycle.getAgentsByOrg(orgId).map((a): AgentResidency => {
+      const cfg = a.config as any;
+      const target: string | null = cfg.deployment?.target || null;
+      const cloudRegion: string | null = cfg.deployment?.config?.cloud?.region || null;
+      const hostRegion = cloudRegion || (target === 'local' ? serverRegion : null);
+      const region = this.getRegion(cfg.dataRegion);
+      const status: AgentResidency['status'] = !region ? 'unpinned'
+        : !cloudRegion || !region.deployRegions.length ? 'unverified'
+        : region.deployRegions.includes(cloudRegion.toLowerCase()) ? 'ok' : 'outside';
+      return { agentId: a.id, name: cfg.displayName || cfg.name || a.id, dataRegion: region?.id || null, target, hostRegion, status };
+    });
+    const hostRegions = Array.from(new Set(agents.map(a => a.hostRegion).filter(Boolean))) as string[];
+    const agentHosting = located('agents', Array.from(new Set(agents.map(a => a.target).filter(Boolean))).join(', ') || 'none',
+      hostRegions.length ? hostRegions.join(', ') : null,
+      agents.length ? `${agents.length} agent${agents.length === 1 ? '' : 's'}` : 'No agents');
+
+    // Inference happens wherever the provider runs it; only local providers are known
+    const providers = Array.from(new Set(agents.map(a => (this.deps.lifecycle.getAgent(a.agentId)?.config as any)?.model?.provider).filter(Boolean))) as string[];
+    const external = providers.filter(p => !PROVIDER_REGISTRY[p]?.isLocal);
+    const models = located('models', providers.map(p => PROVIDER_REGISTRY[p]?.name || p).join(', ') || 'none',
+      providers.length && !external.length ? serverRegion || 'On-premises' : null,
+      external.length ? 'External providers process prompts in their own regions; see their data processing terms' : undefined);
+
+    const settings = await adminDb?.getSettings().catch(() => null);
+    const emailProvider = settings?.orgEmailConfig?.configured ? settings.orgEmailConfig.provider : settings?.smtpHost ? 'smtp' : 'agenticmail';
+    const email = located('email', emailProvider, null, settings?.smtpHost || undefined);
+
+    return {
+      generatedAt: new Date().toISOString(),
+      config: this.config,
+      multiRegion: this.config.regions.length > 1,
+      subsystems: [database, storage, runtime, agentHosting, models, email],
+      agents,
+    };
+  }
+}
@@ -1891,6 +1891,1212 @@ CREATE INDEX idx_custom_roles_slug ON custom_roles(slug);
     `,
     nosql: async () => {},
   },
+  {
+    version: 33,
+    name: 'user_drafts',
+    sqlite: `
+CREATE TABLE IF NOT EXISTS user_drafts (
+  user_id TEXT NOT NULL,
+  draft_key TEXT NOT NULL,
+  data TEXT NOT NULL DEFAULT '{}',
+  created_at TEXT NOT NULL DEFAULT (datetime('now')),
+  updated_at TEXT NOT NULL DEFAULT (datetime('now')),
+  expires_at TEXT NOT NULL,
+  PRIMARY KEY (user_id, draft_key)
+);
+CREATE INDEX IF NOT EXISTS idx_user_drafts_expires ON user_drafts(expires_at);
+    `,
+    postgres: `
+CREATE TABLE IF NOT EXISTS user_drafts (
+  user_id TEXT NOT NULL,
+  draft_key TEXT NOT NULL,
+  data JSONB NOT NULL DEFAULT '{}',
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  expires_at TIMESTAMP NOT NULL,
+  PRIMARY KEY (user_id, draft_key)
+);
+CREATE INDEX IF NOT EXISTS idx_user_drafts_expires ON user_drafts(expires_at);
+    `,
+    mysql: `
+CREATE TABLE IF NOT EXISTS user_drafts (
+  user_id VARCHAR(255) NOT NULL,
+  draft_key VARCHAR(128) NOT NULL,
+  data JSON NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  expires_at TIMESTAMP NOT NULL,
+  PRIMARY KEY (user_id, draft_key)
+);
+CREATE INDEX idx_user_drafts_expires ON user_drafts(expires_at);
+    `,
+    nosql: async () => {},
+  },
+  {
+    version: 34,
+    name: 'message_followups',
+    sqlite: `
+CREATE TABLE IF NOT EXISTS message_followups (
+  id TEXT PRIMARY KEY,
+  user_id TEXT NOT NULL,
+  org_id TEXT NOT NULL,
+  message_id TEXT NOT NULL,
+  kind TEXT NOT NULL DEFAULT 'followup',
+  note TEXT,
+  remind_at TEXT NOT NULL,
+  status TEXT NOT NULL DEFAULT 'pending',
+  created_at TEXT NOT NULL DEFAULT (datetime('now')),
+  updated_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+CREATE INDEX IF NOT EXISTS idx_message_followups_user ON message_followups(user_id, status, remind_at);
+CREATE INDEX IF NOT EXISTS idx_message_followups_message ON message_followups(message_id);
+    `,
+    postgres: `
+CREATE TABLE IF NOT EXISTS message_followups (
+  id TEXT PRIMARY KEY,
+  user_id TEXT NOT NULL,
+  org_id TEXT NOT NULL,
+  message_id TEXT NOT NULL,
+  kind TEXT NOT NULL DEFAULT 'followup',
+  note TEXT,
+  remind_at TIMESTAMP NOT NULL,
+  status TEXT NOT NULL DEFAULT 'pending',
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_message_followups_user ON message_followups(user_id, status, remind_at);
+CREATE INDEX IF NOT EXISTS idx_message_followups_message ON message_followups(message_id);
+    `,
+    mysql: `
+CREATE TABLE IF NOT EXISTS message_followups (
+  id VARCHAR(36) PRIMARY KEY,
+  user_id VARCHAR(255) NOT NULL,
+  org_id VARCHAR(255) NOT NULL,
+  message_id VARCHAR(255) NOT NULL,
+  kind VARCHAR(16) NOT NULL DEFAULT 'followup',
+  note TEXT,
+  remind_at TIMESTAMP NOT NULL,
+  status VARCHAR(16) NOT NULL DEFAULT 'pending',
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_message_followups_user ON message_followups(user_id, status, remind_at);
+CREATE INDEX idx_message_followups_message ON message_followups(message_id);
+    `,
+    nosql: async () => {},
+  },
+  {
+    version: 35,
+    name: 'message_labels',
+    sqlite: `
+CREATE TABLE IF NOT EXISTS message_labels (
+  id TEXT PRIMARY KEY,
+  org_id TEXT NOT NULL,
+  name TEXT NOT NULL,
+  color TEXT NOT NULL DEFAULT '#6366f1',
+  rules TEXT NOT NULL DEFAULT '[]',
+  created_by TEXT,
+  created_at TEXT NOT NULL DEFAULT (datetime('now')),
+  updated_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_message_labels_org_name ON message_labels(org_id, name);
+CREATE TABLE IF NOT EXISTS message_label_assignments (
+  message_id TEXT NOT NULL,
+  label_id TEXT NOT NULL,
+  org_id TEXT NOT NULL,
+  source TEXT NOT NULL DEFAULT 'manual',
+  created_at TEXT NOT NULL DEFAULT (datetime('now')),
+  PRIMARY KEY (message_id, label_id)
+);
+CREATE INDEX IF NOT EXISTS idx_message_label_assignments_org ON message_label_assignments(org_id);
+CREATE INDEX IF NOT EXISTS idx_message_label_assignments_label ON message_label_assignments(label_id);
+    `,
+    postgres: `
+CREATE TABLE IF NOT EXISTS message_labels (
+  id TEXT PRIMARY KEY,
+  org_id TEXT NOT NULL,
+  name TEXT NOT NULL,
+  color TEXT NOT NULL DEFAULT '#6366f1',
+  rules TEXT NOT NULL DEFAULT '[]',
+  created_by TEXT,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_message_labels_org_name ON message_labels(org_id, name);
+CREATE TABLE IF NOT EXISTS message_label_assignments (
+  message_id TEXT NOT NULL,
+  label_id TEXT NOT NULL,
+  org_id TEXT NOT NULL,
+  source TEXT NOT NULL DEFAULT 'manual',
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (message_id, label_id)
+);
+CREATE INDEX IF NOT EXISTS idx_message_label_assignments_org ON message_label_assignments(org_id);
+CREATE INDEX IF NOT EXISTS idx_message_label_assignments_label ON message_label_assignments(label_id);
+    `,
+    mysql: `
+CREATE TABLE IF NOT EXISTS message_labels (
+  id VARCHAR(36) PRIMARY KEY,
+  org_id VARCHAR(255) NOT NULL,
+  name VARCHAR(64) NOT NULL,
+  color VARCHAR(16) NOT NULL DEFAULT '#6366f1',
+  rules TEXT NOT NULL,
+  created_by VARCHAR(255),
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX idx_message_labels_org_name ON message_labels(org_id, name);
+CREATE TABLE IF NOT EXISTS message_label_assignments (
+  message_id VARCHAR(255) NOT NULL,
+  label_id VARCHAR(36) NOT NULL,
+  org_id VARCHAR(255) NOT NULL,
+  source VARCHAR(16) NOT NULL DEFAULT 'manual',
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (message_id, label_id)
+);
+CREATE INDEX idx_message_label_assignments_org ON message_label_assignments(org_id);
+CREATE INDEX idx_message_label_assignments_label ON message_label_assignments(label_id);
+    `,
+    nosql: async () => {},
+  },
+  {
+    version: 36,
+    name: 'message_delivery_events',
+    sqlite: `
+CREATE TABLE IF NOT EXISTS message_delivery_events (
+  id TEXT PRIMARY KEY,
+  message_id TEXT NOT NULL,
+  event TEXT NOT NULL,
+  reason TEXT,
+  detail TEXT,
+  created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+CREATE INDEX IF NOT EXISTS idx_message_delivery_events_message ON message_delivery_events(message_id, created_at);
+    `,
+    postgres: `
+CREATE TABLE IF NOT EXISTS message_delivery_events (
+  id TEXT PRIMARY KEY,
+  message_id TEXT NOT NULL,
+  event TEXT NOT NULL,
+  reason TEXT,
+  detail TEXT,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_message_delivery_events_message ON message_delivery_events(message_id, created_at);
+    `,
+    mysql: `
+CREATE TABLE IF NOT EXISTS message_delivery_events (
+  id VARCHAR(36) PRIMARY KEY,
+  message_id VARCHAR(255) NOT NULL,
+  event VARCHAR(16) NOT NULL,
+  reason TEXT,
+  detail TEXT,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_message_delivery_events_message ON message_delivery_events(message_id, created_at);
+    `,
+    nosql: async () => {},
+  },
+  {
+    version: 37,
+    name: 'qa_reviews',
+    sqlite: `
+CREATE TABLE IF NOT EXISTS qa_reviews (
+  id TEXT PRIMARY KEY,
+  org_id TEXT NOT NULL,
+  agent_id TEXT NOT NULL,
+  message_id TEXT NOT NULL,
+  source TEXT NOT NULL DEFAULT 'sample',
+  status TEXT NOT NULL DEFAULT 'pending',
+  scores TEXT,
+  overall REAL,
+  comment TEXT,
+  reviewer_id TEXT,
+  queued_at TEXT NOT NULL DEFAULT (datetime('now')),
+  graded_at TEXT
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_qa_reviews_message ON qa_reviews(message_id);
+CREATE INDEX IF NOT EXISTS idx_qa_reviews_org_status ON qa_reviews(org_id, status, queued_at);
+CREATE INDEX IF NOT EXISTS idx_qa_reviews_agent ON qa_reviews(agent_id, graded_at);
+CREATE TABLE IF NOT EXISTS qa_settings (
+  org_id TEXT PRIMARY KEY,
+  sample_rate REAL NOT NULL DEFAULT 0.1,
+  rubric TEXT NOT NULL,
+  updated_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+    `,
+    postgres: `
+CREATE TABLE IF NOT EXISTS qa_reviews (
+  id TEXT PRIMARY KEY,
+  org_id TEXT NOT NULL,
+  agent_id TEXT NOT NULL,
+  message_id TEXT NOT NULL,
+  source TEXT NOT NULL DEFAULT 'sample',
+  status TEXT NOT NULL DEFAULT 'pending',
+  scores TEXT,
+  overall REAL,
+  comment TEXT,
+  reviewer_id TEXT,
+  queued_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  graded_at TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_qa_reviews_message ON qa_reviews(message_id);
+CREATE INDEX IF NOT EXISTS idx_qa_reviews_org_status ON qa_reviews(org_id, status, queued_at);
+CREATE INDEX IF NOT EXISTS idx_qa_reviews_agent ON qa_reviews(agent_id, graded_at);
+CREATE TABLE IF NOT EXISTS qa_settings (
+  org_id TEXT PRIMARY KEY,
+  sample_rate REAL NOT NULL DEFAULT 0.1,
+  rubric TEXT NOT NULL,
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+    `,
+    mysql: `
+CREATE TABLE IF NOT EXISTS qa_reviews (
+  id VARCHAR(36) PRIMARY KEY,
+  org_id VARCHAR(255) NOT NULL,
+  agent_id VARCHAR(255) NOT NULL,
+  message_id VARCHAR(255) NOT NULL,
+  source VARCHAR(16) NOT NULL DEFAULT 'sample',
+  status VARCHAR(16) NOT NULL DEFAULT 'pending',
+  scores TEXT,
+  overall REAL,
+  comment TEXT,
+  reviewer_id VARCHAR(255),
+  queued_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  graded_at TIMESTAMP NULL
+);
+CREATE UNIQUE INDEX idx_qa_reviews_message ON qa_reviews(message_id);
+CREATE INDEX idx_qa_reviews_org_status ON qa_reviews(org_id, status, queued_at);
+CREATE INDEX idx_qa_reviews_agent ON qa_reviews(agent_id, graded_at);
+CREATE TABLE IF NOT EXISTS qa_settings (
+  org_id VARCHAR(255) PRIMARY KEY,
+  sample_rate REAL NOT NULL DEFAULT 0.1,
+  rubric TEXT NOT NULL,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+    `,
+    nosql: async () => {},
+  },
+  {
+    version: 38,
+    name: 'conversation_takeovers',
+    sqlite: `
+CREATE TABLE IF NOT EXISTS conversation_takeovers (
+  id TEXT PRIMARY KEY,
+  org_id TEXT,
+  agent_id TEXT NOT NULL,
+  platform TEXT NOT NULL,
+  contact_id TEXT NOT NULL,
+  status TEXT NOT NULL DEFAULT 'active',
+  taken_by TEXT NOT NULL,
+  reason TEXT,
+  reply_count INTEGER NOT NULL DEFAULT 0,
+  taken_at TEXT NOT NULL DEFAULT (datetime('now')),
+  released_by TEXT,
+  released_at TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_takeovers_thread ON conversation_takeovers(agent_id, platform, contact_id, status);
+CREATE INDEX IF NOT EXISTS idx_takeovers_status ON conversation_takeovers(status, taken_at);
+    `,
+    postgres: `
+CREATE TABLE IF NOT EXISTS conversation_takeovers (
+  id TEXT PRIMARY KEY,
+  org_id TEXT,
+  agent_id TEXT NOT NULL,
+  platform TEXT NOT NULL,
+  contact_id TEXT NOT NULL,
+  status TEXT NOT NULL DEFAULT 'active',
+  taken_by TEXT NOT NULL,
+  reason TEXT,
+  reply_count INTEGER NOT NULL DEFAULT 0,
+  taken_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  released_by TEXT,
+  released_at TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_takeovers_thread ON conversation_takeovers(agent_id, platform, contact_id, status);
+CREATE INDEX IF NOT EXISTS idx_takeovers_status ON conversation_takeovers(status, taken_at);
+    `,
+    mysql: `
+CREATE TABLE IF NOT EXISTS conversation_takeovers (
+  id VARCHAR(36) PRIMARY KEY,
+  org_id VARCHAR(255),
+  agent_id VARCHAR(255) NOT NULL,
+  platform VARCHAR(32) NOT NULL,
+  contact_id VARCHAR(255) NOT NULL,
+  status VARCHAR(16) NOT NULL DEFAULT 'active',
+  taken_by VARCHAR(255) NOT NULL,
+  reason TEXT,
+  reply_count INT NOT NULL DEFAULT 0,
+  taken_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  released_by VARCHAR(255),
+  released_at TIMESTAMP NULL
+);
+CREATE INDEX idx_takeovers_thread ON conversation_takeovers(agent_id, platform, contact_id, status);
+CREATE INDEX idx_takeovers_status ON conversation_takeovers(status, taken_at);
+    `,
+    nosql: async () => {},
+  },
+  {
+    version: 39,
+    name: 'work_queue_items',
+    sqlite: `
+CREATE TABLE IF NOT EXISTS work_queue_items (
+  item_id TEXT PRIMARY KEY,
+  org_id TEXT,
+  kind TEXT NOT NULL,
+  assigned_to TEXT,
+  assigned_at TEXT,
+  resolved_by TEXT,
+  resolved_at TEXT,
+  resolution TEXT,
+  updated_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+CREATE INDEX IF NOT EXISTS idx_work_queue_assignee ON work_queue_items(org_id, assigned_to);
+    `,
+    postgres: `
+CREATE TABLE IF NOT EXISTS work_queue_items (
+  item_id TEXT PRIMARY KEY,
+  org_id TEXT,
+  kind TEXT NOT NULL,
+  assigned_to TEXT,
+  assigned_at TIMESTAMP,
+  resolved_by TEXT,
+  resolved_at TIMESTAMP,
+  resolution TEXT,
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_work_queue_assignee ON work_queue_items(org_id, assigned_to);
+    `,
+    mysql: `
+CREATE TABLE IF NOT EXISTS work_queue_items (
+  item_id VARCHAR(160) PRIMARY KEY,
+  org_id VARCHAR(255),
+  kind VARCHAR(32) NOT NULL,
+  assigned_to VARCHAR(255),
+  assigned_at TIMESTAMP NULL,
+  resolved_by VARCHAR(255),
+  resolved_at TIMESTAMP NULL,
+  resolution TEXT,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_work_queue_assignee ON work_queue_items(org_id, assigned_to);
+    `,
+    nosql: async () => {},
+  },
+  {
+    version: 40,
+    name: 'maintenance_windows',
+    sqlite: `
+CREATE TABLE IF NOT EXISTS maintenance_windows (
+  id TEXT PRIMARY KEY,
+  org_id TEXT NOT NULL,
+  agent_id TEXT,
+  title TEXT NOT NULL,
+  starts_at TEXT NOT NULL,
+  ends_at TEXT NOT NULL,
+  notice TEXT,
+  status TEXT NOT NULL DEFAULT 'scheduled',
+  created_by TEXT,
+  created_at TEXT NOT NULL DEFAULT (datetime('now')),
+  updated_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+CREATE INDEX IF NOT EXISTS idx_maintenance_org ON maintenance_windows(org_id, starts_at);
+CREATE INDEX IF NOT EXISTS idx_maintenance_status ON maintenance_windows(status);
+    `,
+    postgres: `
+CREATE TABLE IF NOT EXISTS maintenance_windows (
+  id TEXT PRIMARY KEY,
+  org_id TEXT NOT NULL,
+  agent_id TEXT,
+  title TEXT NOT NULL,
+  starts_at TIMESTAMP NOT NULL,
+  ends_at TIMESTAMP NOT NULL,
+  notice TEXT,
+  status TEXT NOT NULL DEFAULT 'scheduled',
+  created_by TEXT,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_maintenance_org ON maintenance_windows(org_id, starts_at);
+CREATE INDEX IF NOT EXISTS idx_maintenance_status ON maintenance_windows(status);
+    `,
+    mysql: `
+CREATE TABLE IF NOT EXISTS maintenance_windows (
+  id VARCHAR(36) PRIMARY KEY,
+  org_id VARCHAR(255) NOT NULL,
+  agent_id VARCHAR(255),
+  title VARCHAR(255) NOT NULL,
+  starts_at TIMESTAMP NOT NULL,
+  ends_at TIMESTAMP NOT NULL,
+  notice TEXT,
+  status VARCHAR(16) NOT NULL DEFAULT 'scheduled',
+  created_by VARCHAR(255),
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_maintenance_org ON maintenance_windows(org_id, starts_at);
+CREATE INDEX idx_maintenance_status ON maintenance_windows(status);
+    `,
+    nosql: async () => {},
+  },
+  {
+    version: 41,
+    name: 'planned_changes',
+    sqlite: `
+CREATE TABLE IF NOT EXISTS planned_changes (
+  id TEXT PRIMARY KEY,
+  org_id TEXT NOT NULL,
+  kind TEXT NOT NULL,
+  title TEXT NOT NULL,
+  description TEXT,
+  resource TEXT,
+  starts_at TEXT NOT NULL,
+  ends_at TEXT,
+  status TEXT NOT NULL DEFAULT 'planned',
+  created_by TEXT,
+  created_at TEXT NOT NULL DEFAULT (datetime('now')),
+  updated_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+CREATE INDEX IF NOT EXISTS idx_planned_changes_org ON planned_changes(org_id, starts_at);
+    `,
+    postgres: `
+CREATE TABLE IF NOT EXISTS planned_changes (
+  id TEXT PRIMARY KEY,
+  org_id TEXT NOT NULL,
+  kind TEXT NOT NULL,
+  title TEXT NOT NULL,
+  description TEXT,
+  resource TEXT,
+  starts_at TIMESTAMP NOT NULL,
+  ends_at TIMESTAMP,
+  status TEXT NOT NULL DEFAULT 'planned',
+  created_by TEXT,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_planned_changes_org ON planned_changes(org_id, starts_at);
+    `,
+    mysql: `
+CREATE TABLE IF NOT EXISTS planned_changes (
+  id VARCHAR(36) PRIMARY KEY,
+  org_id VARCHAR(255) NOT NULL,
+  kind VARCHAR(32) NOT NULL,
+  title VARCHAR(255) NOT NULL,
+  description TEXT,
+  resource VARCHAR(255),
+  starts_at TIMESTAMP NOT NULL,
+  ends_at TIMESTAMP NULL,
+  status VARCHAR(16) NOT NULL DEFAULT 'planned',
+  created_by VARCHAR(255),
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_planned_changes_org ON planned_changes(org_id, starts_at);
+    `,
+    nosql: async () => {},
+  },
+  {
+    version: 42,
+    name: 'calendar_feeds',
+    sqlite: `
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+  id TEXT PRIMARY KEY,
+  org_id TEXT NOT NULL,
+  user_id TEXT NOT NULL,
+  token_hash TEXT NOT NULL UNIQUE,
+  kinds TEXT,
+  created_at TEXT NOT NULL DEFAULT (datetime('now')),
+  last_used_at TEXT
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_calendar_feeds_user ON calendar_feeds(org_id, user_id);
+    `,
+    postgres: `
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+  id TEXT PRIMARY KEY,
+  org_id TEXT NOT NULL,
+  user_id TEXT NOT NULL,
+  token_hash TEXT NOT NULL UNIQUE,
+  kinds TEXT,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  last_used_at TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_calendar_feeds_user ON calendar_feeds(org_id, user_id);
+    `,
+    mysql: `
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+  id VARCHAR(36) PRIMARY KEY,
+  org_id VARCHAR(255) NOT NULL,
+  user_id VARCHAR(255) NOT NULL,
+  token_hash VARCHAR(64) NOT NULL UNIQUE,
+  kinds VARCHAR(255),
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  last_used_at TIMESTAMP NULL
+);
+CREATE UNIQUE INDEX idx_calendar_feeds_user ON calendar_feeds(org_id, user_id);
+    `,
+    nosql: async () => {},
+  },
+  {
+    version: 43,
+    name: 'retention_runs_and_legal_holds',
+    sqlite: `
+CREATE TABLE IF NOT EXISTS legal_holds (
+  id TEXT PRIMARY KEY,
+  org_id TEXT NOT NULL,
+  agent_id TEXT NOT NULL,
+  reason TEXT NOT NULL,
+  created_by TEXT,
+  created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_legal_holds_agent ON legal_holds(org_id, agent_id);
+
+CREATE TABLE IF NOT EXISTS retention_runs (
+  id TEXT PRIMARY KEY,
+  org_id TEXT NOT NULL,
+  retain_days INTEGER NOT NULL,
+  cutoff TEXT NOT NULL,
+  report JSON NOT NULL,
+  status TEXT NOT NULL DEFAULT 'pending',
+  result JSON,
+  error TEXT,
+  created_by TEXT,
+  approved_by TEXT,
+  created_at TEXT NOT NULL DEFAULT (datetime('now')),
+  approved_at TEXT,
+  completed_at TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_retention_runs_org ON retention_runs(org_id, created_at);
+    `,
+    postgres: `
+CREATE TABLE IF NOT EXISTS legal_holds (
+  id TEXT PRIMARY KEY,
+  org_id TEXT NOT NULL,
+  agent_id TEXT NOT NULL,
+  reason TEXT NOT NULL,
+  created_by TEXT,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_legal_holds_agent ON legal_holds(org_id, agent_id);
+
+CREATE TABLE IF NOT EXISTS retention_runs (
+  id TEXT PRIMARY KEY,
+  org_id TEXT NOT NULL,
+  retain_days INTEGER NOT NULL,
+  cutoff TIMESTAMP NOT NULL,
+  report JSONB NOT NULL,
+  status TEXT NOT NULL DEFAULT 'pending',
+  result JSONB,
+  error TEXT,
+  created_by TEXT,
+  approved_by TEXT,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  approved_at TIMESTAMP,
+  completed_at TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_retention_runs_org ON retention_runs(org_id, created_at);
+    `,
+    mysql: `
+CREATE TABLE IF NOT EXISTS legal_holds (
+  id VARCHAR(36) PRIMARY KEY,
+  org_id VARCHAR(255) NOT NULL,
+  agent_id VARCHAR(255) NOT NULL,
+  reason TEXT NOT NULL,
+  created_by VARCHAR(255),
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX idx_legal_holds_agent ON legal_holds(org_id, agent_id);
+
+CREATE TABLE IF NOT EXISTS retention_runs (
+  id VARCHAR(36) PRIMARY KEY,
+  org_id VARCHAR(255) NOT NULL,
+  retain_days INT NOT NULL,
+  cutoff TIMESTAMP NOT NULL,
+  report JSON NOT NULL,
+  status VARCHAR(20) NOT NULL DEFAULT 'pending',
+  result JSON,
+  error TEXT,
+  created_by VARCHAR(255),
+  approved_by VARCHAR(255),
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  approved_at TIMESTAMP NULL,
+  completed_at TIMESTAMP NULL
+);
+CREATE INDEX idx_retention_runs_org ON retention_runs(org_id, created_at);
+    `,
+    nosql: async () => {},
+  },
+  {
+    version: 44,
+    name: 'storage_usage_snapshots',
+    sqlite: `
+CREATE TABLE IF NOT EXISTS storage_usage_snapshots (
+  org_id TEXT NOT NULL,
+  day TEXT NOT NULL,
+  category TEXT NOT NULL,
+  bytes INTEGER NOT NULL DEFAULT 0,
+  row_count INTEGER NOT NULL DEFAULT 0,
+  PRIMARY KEY (org_id, day, category)
+);
+    `,
+    postgres: `
+CREATE TABLE IF NOT EXISTS storage_usage_snapshots (
+  org_id TEXT NOT NULL,
+  day TEXT NOT NULL,
+  category TEXT NOT NULL,
+  bytes BIGINT NOT NULL DEFAULT 0,
+  row_count INTEGER NOT NULL DEFAULT 0,
+  PRIMARY KEY (org_id, day, category)
+);
+    `,
+    mysql: `
+CREATE TABLE IF NOT EXISTS storage_usage_snapshots (
+  org_id VARCHAR(255) NOT NULL,
+  day VARCHAR(10) NOT NULL,
+  category VARCHAR(32) NOT NULL,
+  bytes BIGINT NOT NULL DEFAULT 0,
+  row_count INT NOT NULL DEFAULT 0,
+  PRIMARY KEY (org_id, day, category)
+);
+    `,
+    nosql: async () => {},
+  },
+  {
+    version: 45,
+    name: 'ediscovery_cases',
+    sqlite: `
+CREATE TABLE IF NOT EXISTS ediscovery_cases (
+  id TEXT PRIMARY KEY,
+  org_id TEXT NOT NULL,
+  name TEXT NOT NULL,
+  matter TEXT,
+  description TEXT,
+  status TEXT NOT NULL DEFAULT 'open',
+  custodians JSON NOT NULL,
+  created_by TEXT,
+  created_at TEXT NOT NULL DEFAULT (datetime('now')),
+  updated_at TEXT NOT NULL DEFAULT (datetime('now')),
+  closed_at TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_ediscovery_cases_org ON ediscovery_cases(org_id, created_at);
+
+CREATE TABLE IF NOT EXISTS ediscovery_case_items (
+  id TEXT PRIMARY KEY,
+  case_id TEXT NOT NULL,
+  kind TEXT NOT NULL,
+  title TEXT NOT NULL,
+  data JSON,
+  created_by TEXT,
+  created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+CREATE INDEX IF NOT EXISTS idx_ediscovery_items_case ON ediscovery_case_items(case_id, created_at);
+    `,
+    postgres: `
+CREATE TABLE IF NOT EXISTS ediscovery_cases (
+  id TEXT PRIMARY KEY,
+  org_id TEXT NOT NULL,
+  name TEXT NOT NULL,
+  matter TEXT,
+  description TEXT,
+  status TEXT NOT NULL DEFAULT 'open',
+  custodians JSONB NOT NULL,
+  created_by TEXT,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  closed_at TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_ediscovery_cases_org ON ediscovery_cases(org_id, created_at);
+
+CREATE TABLE IF NOT EXISTS ediscovery_case_items (
+  id TEXT PRIMARY KEY,
+  case_id TEXT NOT NULL,
+  kind TEXT NOT NULL,
+  title TEXT NOT NULL,
+  data JSONB,
+  created_by TEXT,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_ediscovery_items_case ON ediscovery_case_items(case_id, created_at);
+    `,
+    mysql: `
+CREATE TABLE IF NOT EXISTS ediscovery_cases (
+  id VARCHAR(36) PRIMARY KEY,
+  org_id VARCHAR(255) NOT NULL,
+  name TEXT NOT NULL,
+  matter VARCHAR(255),
+  description TEXT,
+  status VARCHAR(20) NOT NULL DEFAULT 'open',
+  custodians JSON NOT NULL,
+  created_by VARCHAR(255),
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  closed_at TIMESTAMP NULL
+);
+CREATE INDEX idx_ediscovery_cases_org ON ediscovery_cases(org_id, created_at);
+
+CREATE TABLE IF NOT EXISTS ediscovery_case_items (
+  id VARCHAR(36) PRIMARY KEY,
+  case_id VARCHAR(36) NOT NULL,
+  kind VARCHAR(20) NOT NULL,
+  title TEXT NOT NULL,
+  data JSON,
+  created_by VARCHAR(255),
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_ediscovery_items_case ON ediscovery_case_items(case_id, created_at);
+    `,
+    nosql: async () => {},
+  },
+  {
+    version: 46,
+    name: 'user_preferences',
+    sqlite: `
+CREATE TABLE IF NOT EXISTS user_preferences (
+  user_id TEXT NOT NULL,
+  pref_key TEXT NOT NULL,
+  value JSON NOT NULL,
+  updated_at TEXT NOT NULL DEFAULT (datetime('now')),
+  PRIMARY KEY (user_id, pref_key)
+);
+    `,
+    postgres: `
+CREATE TABLE IF NOT EXISTS user_preferences (
+  user_id TEXT NOT NULL,
+  pref_key TEXT NOT NULL,
+  value JSONB NOT NULL,
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (user_id, pref_key)
+);
+    `,
+    mysql: `
+CREATE TABLE IF NOT EXISTS user_preferences (
+  user_id VARCHAR(255) NOT NULL,
+  pref_key VARCHAR(191) NOT NULL,
+  value JSON NOT NULL,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (user_id, pref_key)
+);
+    `,
+    nosql: async () => {},
+  },
+  {
+    version: 47,
+    name: 'data_classifications',
+    sqlite: `
+CREATE TABLE IF NOT EXISTS data_classifications (
+  org_id TEXT NOT NULL,
+  resource_type TEXT NOT NULL,
+  resource_id TEXT NOT NULL,
+  level TEXT NOT NULL,
+  set_by TEXT,
+  updated_at TEXT NOT NULL DEFAULT (datetime('now')),
+  PRIMARY KEY (resource_type, resource_id)
+);
+CREATE INDEX IF NOT EXISTS idx_data_classifications_org ON data_classifications(org_id);
+ALTER TABLE dlp_rules ADD COLUMN min_classification TEXT;
+    `,
+    postgres: `
+CREATE TABLE IF NOT EXISTS data_classifications (
+  org_id TEXT NOT NULL,
+  resource_type TEXT NOT NULL,
+  resource_id TEXT NOT NULL,
+  level TEXT NOT NULL,
+  set_by TEXT,
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (resource_type, resource_id)
+);
+CREATE INDEX IF NOT EXISTS idx_data_classifications_org ON data_classifications(org_id);
+ALTER TABLE dlp_rules ADD COLUMN IF NOT EXISTS min_classification TEXT;
+    `,
+    mysql: `
+CREATE TABLE IF NOT EXISTS data_classifications (
+  org_id VARCHAR(255) NOT NULL,
+  resource_type VARCHAR(32) NOT NULL,
+  resource_id VARCHAR(191) NOT NULL,
+  level VARCHAR(32) NOT NULL,
+  set_by VARCHAR(255),
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (resource_type, resource_id),
+  INDEX idx_data_classifications_org (org_id)
+);
+ALTER TABLE dlp_rules ADD COLUMN min_classification VARCHAR(32);
+    `,
+    nosql: async () => {},
+  },
+  {
+    version: 48,
+    name: 'ai_disclosure',
+    sqlite: `
+CREATE TABLE IF NOT EXISTS ai_disclosure_log (
+  id TEXT PRIMARY KEY,
+  org_id TEXT NOT NULL,
+  agent_id TEXT NOT NULL,
+  tool TEXT NOT NULL,
+  recipient TEXT,
+  jurisdiction TEXT NOT NULL,
+  kind TEXT NOT NULL,
+  applied_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+CREATE INDEX IF NOT EXISTS idx_ai_disclosure_log_org ON ai_disclosure_log(org_id, applied_at);
+CREATE TABLE IF NOT EXISTS conversation_consents (
+  id TEXT PRIMARY KEY,
+  org_id TEXT NOT NULL,
+  contact TEXT NOT NULL,
+  jurisdiction TEXT NOT NULL,
+  status TEXT NOT NULL,
+  agent_id TEXT,
+  requested_at TEXT,
+  decided_at TEXT,
+  decided_by TEXT,
+  note TEXT,
+  UNIQUE (org_id, contact)
+);
+    `,
+    postgres: `
+CREATE TABLE IF NOT EXISTS ai_disclosure_log (
+  id TEXT PRIMARY KEY,
+  org_id TEXT NOT NULL,
+  agent_id TEXT NOT NULL,
+  tool TEXT NOT NULL,
+  recipient TEXT,
+  jurisdiction TEXT NOT NULL,
+  kind TEXT NOT NULL,
+  applied_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_ai_disclosure_log_org ON ai_disclosure_log(org_id, applied_at);
+CREATE TABLE IF NOT EXISTS conversation_consents (
+  id TEXT PRIMARY KEY,
+  org_id TEXT NOT NULL,
+  contact TEXT NOT NULL,
+  jurisdiction TEXT NOT NULL,
+  status TEXT NOT NULL,
+  agent_id TEXT,
+  requested_at TIMESTAMP,
+  decided_at TIMESTAMP,
+  decided_by TEXT,
+  note TEXT,
+  UNIQUE (org_id, contact)
+);
+    `,
+    mysql: `
+CREATE TABLE IF NOT EXISTS ai_disclosure_log (
+  id VARCHAR(64) PRIMARY KEY,
+  org_id VARCHAR(255) NOT NULL,
+  agent_id VARCHAR(255) NOT NULL,
+  tool VARCHAR(128) NOT NULL,
+  recipient TEXT,
+  jurisdiction VARCHAR(32) NOT NULL,
+  kind VARCHAR(16) NOT NULL,
+  applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  INDEX idx_ai_disclosure_log_org (org_id, applied_at)
+);
+CREATE TABLE IF NOT EXISTS conversation_consents (
+  id VARCHAR(64) PRIMARY KEY,
+  org_id VARCHAR(255) NOT NULL,
+  contact VARCHAR(191) NOT NULL,
+  jurisdiction VARCHAR(32) NOT NULL,
+  status VARCHAR(16) NOT NULL,
+  agent_id VARCHAR(255),
+  requested_at TIMESTAMP NULL,
+  decided_at TIMESTAMP NULL,
+  decided_by VARCHAR(255),
+  note TEXT,
+  UNIQUE KEY uq_conversation_consents (org_id, contact)
+);
+    `,
+    nosql: async () => {},
+  },
+  {
+    version: 49,
+    name: 'agent_teams',
+    sqlite: `
+CREATE TABLE IF NOT EXISTS agent_teams (
+  id TEXT PRIMARY KEY,
+  org_id TEXT NOT NULL,
+  name TEXT NOT NULL,
+  description TEXT,
+  color TEXT,
+  created_by TEXT,
+  created_at TEXT NOT NULL DEFAULT (datetime('now')),
+  updated_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+CREATE INDEX IF NOT EXISTS idx_agent_teams_org ON agent_teams(org_id, name);
+CREATE TABLE IF NOT EXISTS agent_team_members (
+  team_id TEXT NOT NULL,
+  agent_id TEXT NOT NULL,
+  added_at TEXT NOT NULL DEFAULT (datetime('now')),
+  PRIMARY KEY (team_id, agent_id)
+);
+CREATE INDEX IF NOT EXISTS idx_agent_team_members_agent ON agent_team_members(agent_id);
+    `,
+    postgres: `
+CREATE TABLE IF NOT EXISTS agent_teams (
+  id TEXT PRIMARY KEY,
+  org_id TEXT NOT NULL,
+  name TEXT NOT NULL,
+  description TEXT,
+  color TEXT,
+  created_by TEXT,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_agent_teams_org ON agent_teams(org_id, name);
+CREATE TABLE IF NOT EXISTS agent_team_members (
+  team_id TEXT NOT NULL,
+  agent_id TEXT NOT NULL,
+  added_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (team_id, agent_id)
+);
+CREATE INDEX IF NOT EXISTS idx_agent_team_members_agent ON agent_team_members(agent_id);
+    `,
+    mysql: `
+CREATE TABLE IF NOT EXISTS agent_teams (
+  id VARCHAR(64) PRIMARY KEY,
+  org_id VARCHAR(255) NOT NULL,
+  name VARCHAR(200) NOT NULL,
+  description TEXT,
+  color VARCHAR(16),
+  created_by VARCHAR(255),
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  INDEX idx_agent_teams_org (org_id, name)
+);
+CREATE TABLE IF NOT EXISTS agent_team_members (
+  team_id VARCHAR(64) NOT NULL,
+  agent_id VARCHAR(255) NOT NULL,
+  added_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (team_id, agent_id),
+  INDEX idx_agent_team_members_agent (agent_id)
+);
+    `,
+    nosql: async () => {},
+  },
+  {
+    version: 50,
+    name: 'agent_avatars',
+    sqlite: `
+CREATE TABLE IF NOT EXISTS agent_avatars (
+  agent_id TEXT PRIMARY KEY,
+  content_type TEXT NOT NULL,
+  data TEXT NOT NULL,
+  size INTEGER NOT NULL DEFAULT 0,
+  source_url TEXT,
+  updated_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+    `,
+    postgres: `
+CREATE TABLE IF NOT EXISTS agent_avatars (
+  agent_id TEXT PRIMARY KEY,
+  content_type TEXT NOT NULL,
+  data TEXT NOT NULL,
+  size INTEGER NOT NULL DEFAULT 0,
+  source_url TEXT,
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+    `,
+    mysql: `
+CREATE TABLE IF NOT EXISTS agent_avatars (
+  agent_id VARCHAR(255) PRIMARY KEY,
+  content_type VARCHAR(64) NOT NULL,
+  data MEDIUMTEXT NOT NULL,
+  size INT NOT NULL DEFAULT 0,
+  source_url TEXT,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+    `,
+    nosql: async () => {},
+  },
+  {
+    version: 51,
+    name: 'agent_webhooks',
+    sqlite: `
+CREATE TABLE IF NOT EXISTS agent_webhooks (
+  id TEXT PRIMARY KEY,
+  org_id TEXT NOT NULL,
+  agent_id TEXT NOT NULL,
+  url TEXT NOT NULL,
+  description TEXT,
+  events TEXT NOT NULL DEFAULT '[]',
+  secret TEXT NOT NULL,
+  enabled INTEGER NOT NULL DEFAULT 1,
+  consecutive_failures INTEGER NOT NULL DEFAULT 0,
+  last_delivery_at TEXT,
+  last_status TEXT,
+  created_by TEXT,
+  created_at TEXT NOT NULL DEFAULT (datetime('now')),
+  updated_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+CREATE INDEX IF NOT EXISTS idx_agent_webhooks_agent ON agent_webhooks(agent_id);
+CREATE TABLE IF NOT EXISTS agent_webhook_deliveries (
+  id TEXT PRIMARY KEY,
+  webhook_id TEXT NOT NULL,
+  agent_id TEXT NOT NULL,
+  event TEXT NOT NULL,
+  payload TEXT NOT NULL,
+  status TEXT NOT NULL,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  response_code INTEGER,
+  response_body TEXT,
+  error TEXT,
+  duration_ms INTEGER,
+  created_at TEXT NOT NULL DEFAULT (datetime('now')),
+  completed_at TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_agent_webhook_deliveries_webhook ON agent_webhook_deliveries(webhook_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_agent_webhook_deliveries_agent ON agent_webhook_deliveries(agent_id, created_at);
+    `,
+    postgres: `
+CREATE TABLE IF NOT EXISTS agent_webhooks (
+  id TEXT PRIMARY KEY,
+  org_id TEXT NOT NULL,
+  agent_id TEXT NOT NULL,
+  url TEXT NOT NULL,
+  description TEXT,
+  events TEXT NOT NULL DEFAULT '[]',
+  secret TEXT NOT NULL,
+  enabled BOOLEAN NOT NULL DEFAULT TRUE,
+  consecutive_failures INTEGER NOT NULL DEFAULT 0,
+  last_delivery_at TIMESTAMP,
+  last_status TEXT,
+  created_by TEXT,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_agent_webhooks_agent ON agent_webhooks(agent_id);
+CREATE TABLE IF NOT EXISTS agent_webhook_deliveries (
+  id TEXT PRIMARY KEY,
+  webhook_id TEXT NOT NULL,
+  agent_id TEXT NOT NULL,
+  event TEXT NOT NULL,
+  payload TEXT NOT NULL,
+  status TEXT NOT NULL,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  response_code INTEGER,
+  response_body TEXT,
+  error TEXT,
+  duration_ms INTEGER,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  completed_at TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_agent_webhook_deliveries_webhook ON agent_webhook_deliveries(webhook_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_agent_webhook_deliveries_agent ON agent_webhook_deliveries(agent_id, created_at);
+    `,
+    mysql: `
+CREATE TABLE IF NOT EXISTS agent_webhooks (
+  id VARCHAR(64) PRIMARY KEY,
+  org_id VARCHAR(255) NOT NULL,
+  agent_id VARCHAR(255) NOT NULL,
+  url TEXT NOT NULL,
+  description TEXT,
+  events TEXT NOT NULL,
+  secret VARCHAR(128) NOT NULL,
+  enabled BOOLEAN NOT NULL DEFAULT TRUE,
+  consecutive_failures INT NOT NULL DEFAULT 0,
+  last_delivery_at TIMESTAMP NULL,
+  last_status VARCHAR(16),
+  created_by VARCHAR(255),
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  INDEX idx_agent_webhooks_agent (agent_id)
+);
+CREATE TABLE IF NOT EXISTS agent_webhook_deliveries (
+  id VARCHAR(64) PRIMARY KEY,
+  webhook_id VARCHAR(64) NOT NULL,
+  agent_id VARCHAR(255) NOT NULL,
+  event VARCHAR(64) NOT NULL,
+  payload MEDIUMTEXT NOT NULL,
+  status VARCHAR(16) NOT NULL,
+  attempts INT NOT NULL DEFAULT 0,
+  response_code INT,
+  response_body TEXT,
+  error TEXT,
+  duration_ms INT,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  completed_at TIMESTAMP NULL,
+  INDEX idx_agent_webhook_deliveries_webhook (webhook_id, created_at),
+  INDEX idx_agent_webhook_deliveries_agent (agent_id, created_at)
+);
+    `,
+    nosql: async () => {},
+  },
+  {
+    version: 52,
+    name: 'agent_team_users',
+    sqlite: `
+CREATE TABLE IF NOT EXISTS agent_team_users (
+  team_id TEXT NOT NULL,
+  user_id TEXT NOT NULL,
+  added_at TEXT NOT NULL DEFAULT (datetime('now')),
+  PRIMARY KEY (team_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_agent_team_users_user ON agent_team_users(user_id);
+    `,
+    postgres: `
+CREATE TABLE IF NOT EXISTS agent_team_users (
+  team_id TEXT NOT NULL,
+  user_id TEXT NOT NULL,
+  added_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (team_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_agent_team_users_user ON agent_team_users(user_id);
+    `,
+    mysql: `
+CREATE TABLE IF NOT EXISTS agent_team_users (
+  team_id VARCHAR(64) NOT NULL,
+  user_id VARCHAR(255) NOT NULL,
+  added_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (team_id, user_id),
+  INDEX idx_agent_team_users_user (user_id)
+);
+    `,
+    nosql: async () => {},
+  },
+  {
+    version: 53,
+    name: 'user_sessions',
+    sqlite: `
+CREATE TABLE IF NOT EXISTS user_sessions (
+  id TEXT PRIMARY KEY,
+  user_id TEXT NOT NULL,
+  ip TEXT,
+  user_agent TEXT,
+  method TEXT,
+  created_at TEXT NOT NULL,
+  last_seen_at TEXT NOT NULL,
+  expires_at TEXT NOT NULL,
+  ended_at TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_user_sessions_user ON user_sessions(user_id, created_at);
+    `,
+    postgres: `
+CREATE TABLE IF NOT EXISTS user_sessions (
+  id TEXT PRIMARY KEY,
+  user_id TEXT NOT NULL,
+  ip TEXT,
+  user_agent TEXT,
+  method TEXT,
+  created_at TIMESTAMP NOT NULL,
+  last_seen_at TIMESTAMP NOT NULL,
+  expires_at TIMESTAMP NOT NULL,
+  ended_at TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_user_sessions_user ON user_sessions(user_id, created_at);
+    `,
+    mysql: `
+CREATE TABLE IF NOT EXISTS user_sessions (
+  id VARCHAR(64) PRIMARY KEY,
+  user_id VARCHAR(255) NOT NULL,
+  ip VARCHAR(64),
+  user_agent TEXT,
+  method VARCHAR(32),
+  created_at TIMESTAMP NOT NULL,
+  last_seen_at TIMESTAMP NOT NULL,
+  expires_at TIMESTAMP NOT NULL,
+  ended_at TIMESTAMP NULL,
+  INDEX idx_user_sessions_user (user_id, created_at)
+);
+    `,
+    nosql: async () => {},
+  },
 ];
 
 // ─── Dynamic Table Definitions ─────────────────────────
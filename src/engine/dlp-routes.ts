@@ -4,17 +4,27 @@
  */
 
 import { Hono } from 'hono';
-import { DLPEngine, DLP_RULE_PACKS } from './dlp.js';
+import { DLPEngine, DLP_RULE_PACKS, type DLPViolation } from './dlp.js';
+import type { DatabaseAdapter } from '../db/adapter.js';
+import { wantsCsv, csvResponse } from '../lib/csv.js';
+import { viewerStamp } from '../lib/watermark.js';
+import { requireCapability } from '../middleware/role-permissions.js';
+import { auditFromEngine } from './route-audit.js';
 
-export function createDlpRoutes(dlp: DLPEngine) {
+/** Blocked outbound content is held back from the recipient, i.e. quarantined */
+const isQuarantined = (v: DLPViolation) => v.actionTaken === 'blocked' && v.direction === 'outbound';
+
+export function createDlpRoutes(dlp: DLPEngine, deps: { getAdminDb?: () => DatabaseAdapter | null } = {}) {
   const router = new Hono();
+  const audit = auditFromEngine(deps.getAdminDb);
+  const canManage = requireCapability('dlp.manage');
 
   router.get('/rules', (c) => {
     const rules = dlp.getRules(c.req.query('orgId') || undefined);
     return c.json({ rules, total: rules.length });
   });
 
-  router.post('/rules', async (c) => {
+  router.post('/rules', canManage, async (c) => {
     const body = await c.req.json();
     body.id = body.id || crypto.randomUUID();
     body.createdAt = body.createdAt || new Date().toISOString();
@@ -29,7 +39,7 @@ export function createDlpRoutes(dlp: DLPEngine) {
     return c.json({ rule });
   });
 
-  router.put('/rules/:id', async (c) => {
+  router.put('/rules/:id', canManage, async (c) => {
     const id = c.req.param('id');
     const existing = dlp.getRule(id);
     if (!existing) return c.json({ error: 'Rule not found' }, 404);
@@ -39,7 +49,7 @@ export function createDlpRoutes(dlp: DLPEngine) {
     return c.json({ success: true, rule: updated });
   });
 
-  router.delete('/rules/:id', (c) => {
+  router.delete('/rules/:id', canManage, (c) => {
     dlp.removeRule(c.req.param('id'));
     return c.json({ success: true });
   });
@@ -69,7 +79,7 @@ export function createDlpRoutes(dlp: DLPEngine) {
     return c.json({ id: packId, ...pack });
   });
 
-  router.post('/rule-packs/apply', async (c) => {
+  router.post('/rule-packs/apply', canManage, async (c) => {
     const { orgId, packIds, overwrite } = await c.req.json();
     if (!orgId || !packIds?.length) return c.json({ error: 'orgId and packIds[] required' }, 400);
     try {
@@ -80,7 +90,7 @@ export function createDlpRoutes(dlp: DLPEngine) {
     }
   });
 
-  router.post('/reload', async (c) => {
+  router.post('/reload', canManage, async (c) => {
     await dlp.reloadRules();
     const rules = dlp.getRules();
     return c.json({ success: true, totalRules: rules.length });
@@ -88,14 +98,47 @@ export function createDlpRoutes(dlp: DLPEngine) {
 
   // ─── Violations ─────────────────────────────────────
 
+  // ?orgId=&agentId=&action=blocked|redacted|warned|logged&since=<ISO>
+  // ?format=csv exports every matching violation rather than the first page
   router.get('/violations', (c) => {
+    const orgId = c.req.query('orgId') || undefined;
+    const agentId = c.req.query('agentId') || undefined;
+    const actionTaken = c.req.query('action') || undefined;
+    const sinceParam = c.req.query('since');
+    if (sinceParam && isNaN(Date.parse(sinceParam))) return c.json({ error: 'Invalid "since" date' }, 400);
+    const since = sinceParam ? new Date(sinceParam).toISOString() : undefined;
+    if (wantsCsv(c)) {
+      const ruleNames = new Map(dlp.getRules(orgId).map(r => [r.id, r.name]));
+      return csvResponse('dlp-violations', [
+        { header: 'id' }, { header: 'createdAt' }, { header: 'agentId' }, { header: 'ruleId' },
+        { header: 'ruleName', value: v => ruleNames.get(v.ruleId) || '' },
+        { header: 'toolId' }, { header: 'direction' }, { header: 'actionTaken' },
+        { header: 'matchContext', value: v => isQuarantined(v) ? '[quarantined]' : v.matchContext || '' },
+      ], async (offset, limit) => dlp.getViolations({ orgId, agentId, actionTaken, since, limit, offset }));
+    }
+    // Quarantined content is only shown through /violations/:id/view, which is logged
     const violations = dlp.getViolations({
-      orgId: c.req.query('orgId') || undefined,
-      agentId: c.req.query('agentId') || undefined,
+      orgId,
+      agentId,
+      actionTaken,
+      since,
       limit: parseInt(c.req.query('limit') || '100'),
-    });
+    }).map(v => isQuarantined(v) ? { ...v, matchContext: undefined, quarantined: true } : v);
     return c.json({ violations, total: violations.length });
   });
 
+  router.get('/violations/:id/view', (c) => {
+    const violation = dlp.getViolation(c.req.param('id'));
+    if (!violation) return c.json({ error: 'Violation not found' }, 404);
+    const viewer = viewerStamp(c);
+    audit(c, isQuarantined(violation) ? 'dlp.quarantine_view' : 'dlp.violation_view', `dlp_violation:${violation.id}`,
+      { agentId: violation.agentId, ruleId: violation.ruleId, viewer: viewer.label }, violation.orgId);
+    return c.json({
+      violation: { ...violation, quarantined: isQuarantined(violation) },
+      rule: dlp.getRule(violation.ruleId) || null,
+      viewer,
+    });
+  });
+
   return router;
 }
@@ -10,6 +10,7 @@
  */
 
 import type { EngineDatabase } from './db-adapter.js';
+import { type ClassificationLevel, agentClassification, isClassificationLevel, meetsClassification } from '../lib/classification.js';
 
 // ─── Types ──────────────────────────────────────────────
 
@@ -24,6 +25,8 @@ export interface DLPRule {
   appliesTo: 'parameters' | 'results' | 'both';
   severity: 'low' | 'medium' | 'high' | 'critical';
   enabled: boolean;
+  /** Only scan agents handling data at or above this classification; unset scans every agent */
+  minClassification?: ClassificationLevel | null;
   createdAt: string;
   updatedAt: string;
 }
@@ -184,6 +187,7 @@ export class DLPEngine {
           id: r.id, orgId: r.org_id, name: r.name, description: r.description,
           patternType: r.pattern_type, pattern: r.pattern, action: r.action,
           appliesTo: r.applies_to, severity: r.severity, enabled: !!r.enabled,
+          minClassification: r.min_classification || null,
           createdAt: r.created_at, updatedAt: r.updated_at,
         });
       }
@@ -262,12 +266,13 @@ export class DLPEngine {
   // ─── Rule CRUD ──────────────────────────────────────
 
   async addRule(rule: DLPRule): Promise<void> {
+    if (!isClassificationLevel(rule.minClassification)) rule.minClassification = null;
     this.rules.set(rule.id, rule);
     this.engineDb?.execute(
-      `INSERT INTO dlp_rules (id, org_id, name, description, pattern_type, pattern, action, applies_to, severity, enabled, created_at, updated_at)
-       VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-       ON CONFLICT(id) DO UPDATE SET name=excluded.name, description=excluded.description, pattern_type=excluded.pattern_type, pattern=excluded.pattern, action=excluded.action, applies_to=excluded.applies_to, severity=excluded.severity, enabled=excluded.enabled, updated_at=excluded.updated_at`,
-      [rule.id, rule.orgId, rule.name, rule.description || null, rule.patternType, rule.pattern, rule.action, rule.appliesTo, rule.severity, rule.enabled ? 1 : 0, rule.createdAt, rule.updatedAt]
+      `INSERT INTO dlp_rules (id, org_id, name, description, pattern_type, pattern, action, applies_to, severity, enabled, min_classification, created_at, updated_at)
+       VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+       ON CONFLICT(id) DO UPDATE SET name=excluded.name, description=excluded.description, pattern_type=excluded.pattern_type, pattern=excluded.pattern, action=excluded.action, applies_to=excluded.applies_to, severity=excluded.severity, enabled=excluded.enabled, min_classification=excluded.min_classification, updated_at=excluded.updated_at`,
+      [rule.id, rule.orgId, rule.name, rule.description || null, rule.patternType, rule.pattern, rule.action, rule.appliesTo, rule.severity, rule.enabled ? 1 : 0, rule.minClassification || null, rule.createdAt, rule.updatedAt]
     ).catch((err) => { console.error('[dlp] Failed to persist rule:', err); });
   }
 
@@ -294,12 +299,12 @@ export class DLPEngine {
       return this.scanParameters(o.orgId, o.agentId, o.toolId, o.parameters || {});
     }
     const orgId = optsOrOrgId;
-    const rules = this.getApplicableRules(orgId, 'parameters');
+    const rules = this.getApplicableRules(orgId, 'parameters', agentId);
     return this.scan(orgId, agentId!, toolId!, params || {}, rules, 'outbound');
   }
 
   scanResults(orgId: string, agentId: string, toolId: string, result: any): DLPScanResult {
-    const rules = this.getApplicableRules(orgId, 'results');
+    const rules = this.getApplicableRules(orgId, 'results', agentId);
     return this.scan(orgId, agentId, toolId, typeof result === 'object' ? result : { _value: result }, rules, 'inbound');
   }
 
@@ -317,18 +322,27 @@ export class DLPEngine {
     return { matches };
   }
 
-  getViolations(opts?: { orgId?: string; agentId?: string; limit?: number }): DLPViolation[] {
+  getViolations(opts?: { orgId?: string; agentId?: string; actionTaken?: string; since?: string; limit?: number; offset?: number }): DLPViolation[] {
     let v = [...this.violations];
     if (opts?.orgId) v = v.filter(x => x.orgId === opts.orgId);
     if (opts?.agentId) v = v.filter(x => x.agentId === opts.agentId);
-    return v.slice(0, opts?.limit || 100);
+    if (opts?.actionTaken) v = v.filter(x => x.actionTaken === opts.actionTaken);
+    if (opts?.since) v = v.filter(x => x.createdAt >= opts.since!);
+    const offset = opts?.offset || 0;
+    return v.slice(offset, offset + (opts?.limit || 100));
+  }
+
+  getViolation(id: string): DLPViolation | undefined {
+    return this.violations.find(v => v.id === id);
   }
 
   // ─── Private ──────────────────────────────────────
 
-  private getApplicableRules(orgId: string, direction: 'parameters' | 'results'): DLPRule[] {
+  private getApplicableRules(orgId: string, direction: 'parameters' | 'results', agentId?: string): DLPRule[] {
+    const level = agentClassification(agentId);
     return Array.from(this.rules.values()).filter(r =>
       r.orgId === orgId && r.enabled && (r.appliesTo === 'both' || r.appliesTo === direction)
+      && meetsClassification(level, r.minClassification)
     );
   }
 
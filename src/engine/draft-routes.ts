@@ -0,0 +1,52 @@
+/**
+ * Form Draft Routes
+ * Mounted at /drafts/* on the engine sub-app. Drafts are always scoped to the
+ * authenticated user (X-User-Id injected by the enterprise server).
+ */
+
+import { Hono } from 'hono';
+import { DRAFT_KEY_RE, type DraftStore } from './drafts.js';
+
+export function createDraftRoutes(drafts: DraftStore) {
+  const router = new Hono();
+
+  router.use('*', async (c, next) => {
+    if (!c.req.header('X-User-Id')) return c.json({ error: 'Authentication required' }, 401);
+    return next();
+  });
+
+  router.get('/', async (c) => {
+    const list = await drafts.list(c.req.header('X-User-Id')!);
+    return c.json({ drafts: list });
+  });
+
+  router.get('/:key', async (c) => {
+    if (!DRAFT_KEY_RE.test(c.req.param('key'))) return c.json({ error: 'Invalid draft key' }, 400);
+    const draft = await drafts.get(c.req.header('X-User-Id')!, c.req.param('key'));
+    if (!draft) return c.json({ error: 'Draft not found' }, 404);
+    return c.json({ draft });
+  });
+
+  router.put('/:key', async (c) => {
+    const key = c.req.param('key');
+    if (!DRAFT_KEY_RE.test(key)) return c.json({ error: 'Invalid draft key' }, 400);
+    const body = await c.req.json().catch(() => null);
+    if (!body || typeof body.data !== 'object' || Array.isArray(body.data)) {
+      return c.json({ error: 'data must be a JSON object' }, 400);
+    }
+    try {
+      const draft = await drafts.save(c.req.header('X-User-Id')!, key, body.data);
+      return c.json({ draft: { key: draft.key, updatedAt: draft.updatedAt, expiresAt: draft.expiresAt } });
+    } catch (e: any) {
+      return c.json({ error: e.message }, 400);
+    }
+  });
+
+  router.delete('/:key', async (c) => {
+    if (!DRAFT_KEY_RE.test(c.req.param('key'))) return c.json({ error: 'Invalid draft key' }, 400);
+    await drafts.delete(c.req.header('X-User-Id')!, c.req.param('key'));
+    return c.json({ ok: true });
+  });
+
+  return router;
+}
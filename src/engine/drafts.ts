@@ -0,0 +1,114 @@
+/**
+ * Form Drafts — Server-side Unsaved-Draft Persistence
+ *
+ * Stores in-progress form state (agent creation wizard, message compose, …)
+ * per user so an accidental navigation, tab close or device switch doesn't
+ * lose work. Drafts expire automatically after DRAFT_TTL_DAYS of inactivity.
+ */
+
+import type { EngineDatabase } from './db-adapter.js';
+
+// ─── Types ──────────────────────────────────────────────
+
+export interface FormDraft {
+  userId: string;
+  key: string;
+  data: Record<string, any>;
+  createdAt: string;
+  updatedAt: string;
+  expiresAt: string;
+}
+
+// ─── Config ─────────────────────────────────────────────
+
+const DRAFT_TTL_DAYS = 7;
+const MAX_DRAFT_BYTES = 256 * 1024;
+const MAX_DRAFTS_PER_USER = 50;
+const SWEEP_INTERVAL_MS = 60 * 60 * 1000;
+
+export const DRAFT_KEY_RE = /^[a-z0-9][a-z0-9:_-]{0,127}$/;
+
+// ─── Draft Store ────────────────────────────────────────
+
+export class DraftStore {
+  private engineDb?: EngineDatabase;
+  private sweepTimer: ReturnType<typeof setInterval> | null = null;
+
+  async setDb(db: EngineDatabase): Promise<void> {
+    this.engineDb = db;
+    await this.purgeExpired();
+    if (!this.sweepTimer) {
+      this.sweepTimer = setInterval(() => { this.purgeExpired().catch(() => {}); }, SWEEP_INTERVAL_MS);
+      if (typeof this.sweepTimer === 'object' && 'unref' in this.sweepTimer) this.sweepTimer.unref();
+    }
+  }
+
+  async get(userId: string, key: string): Promise<FormDraft | null> {
+    if (!this.engineDb) return null;
+    const row = await this.engineDb.get<any>(
+      'SELECT * FROM user_drafts WHERE user_id = ? AND draft_key = ? AND expires_at > ?',
+      [userId, key, new Date().toISOString()],
+    );
+    return row ? this.rowToDraft(row) : null;
+  }
+
+  async list(userId: string): Promise<Array<Omit<FormDraft, 'data'>>> {
+    if (!this.engineDb) return [];
+    const rows = await this.engineDb.query<any>(
+      'SELECT user_id, draft_key, created_at, updated_at, expires_at FROM user_drafts WHERE user_id = ? AND expires_at > ? ORDER BY updated_at DESC',
+      [userId, new Date().toISOString()],
+    );
+    return rows.map((r: any) => ({ userId: r.user_id, key: r.draft_key, createdAt: String(r.created_at), updatedAt: String(r.updated_at), expiresAt: String(r.expires_at) }));
+  }
+
+  /** Create or replace a draft; each save pushes the expiry window forward. */
+  async save(userId: string, key: string, data: Record<string, any>): Promise<FormDraft> {
+    if (!this.engineDb) throw new Error('Draft storage not initialized');
+    const json = JSON.stringify(data ?? {});
+    if (json.length > MAX_DRAFT_BYTES) throw new Error(`Draft too large (max ${MAX_DRAFT_BYTES / 1024} KB)`);
+
+    const existing = await this.get(userId, key);
+    if (!existing) {
+      const others = await this.list(userId);
+      if (others.length >= MAX_DRAFTS_PER_USER) throw new Error(`Too many drafts (max ${MAX_DRAFTS_PER_USER})`);
+    }
+
+    const now = new Date();
+    const draft: FormDraft = {
+      userId,
+      key,
+      data,
+      createdAt: existing?.createdAt || now.toISOString(),
+      updatedAt: now.toISOString(),
+      expiresAt: new Date(now.getTime() + DRAFT_TTL_DAYS * 86_400_000).toISOString(),
+    };
+    // Delete + insert rather than dialect-specific upsert syntax
+    await this.engineDb.execute('DELETE FROM user_drafts WHERE user_id = ? AND draft_key = ?', [userId, key]);
+    await this.engineDb.execute(
+      'INSERT INTO user_drafts (user_id, draft_key, data, created_at, updated_at, expires_at) VALUES (?, ?, ?, ?, ?, ?)',
+      [userId, key, json, draft.createdAt, draft.updatedAt, draft.expiresAt],
+    );
+    return draft;
+  }
+
+  async delete(userId: string, key: string): Promise<void> {
+    await this.engineDb?.execute('DELETE FROM user_drafts WHERE user_id = ? AND draft_key = ?', [userId, key]);
+  }
+
+  async purgeExpired(): Promise<void> {
+    try {
+      await this.engineDb?.execute('DELETE FROM user_drafts WHERE expires_at <= ?', [new Date().toISOString()]);
+    } catch { /* table may not exist yet */ }
+  }
+
+  private rowToDraft(r: any): FormDraft {
+    return {
+      userId: r.user_id,
+      key: r.draft_key,
+      data: typeof r.data === 'string' ? JSON.parse(r.data) : (r.data || {}),
+      createdAt: String(r.created_at),
+      updatedAt: String(r.updated_at),
+      expiresAt: String(r.expires_at),
+    };
+  }
+}
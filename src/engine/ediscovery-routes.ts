@@ -0,0 +1,208 @@
+/**
+ * e-Discovery Routes
+ * Mounted at /ediscovery/* on the engine sub-app.
+ *
+ * Every route under /cases/:id checks the caller is a custodian of the case
+ * (or an owner) and answers 404 otherwise, so case names don't leak. Views,
+ * changes and exports are all written to the admin audit log.
+ */
+
+import { Hono } from 'hono';
+import type { CaseManager, EDiscoveryCase } from './ediscovery.js';
+import type { RetentionManager } from './retention.js';
+import type { MessageSearch } from './message-search.js';
+import type { DatabaseAdapter } from '../db/adapter.js';
+import { viewerStamp } from '../lib/watermark.js';
+import { auditFromEngine } from './route-audit.js';
+import { callerRole } from './caller-role.js';
+
+export function createEDiscoveryRoutes(cases: CaseManager, deps: {
+  retention: RetentionManager;
+  messageSearch: MessageSearch;
+  getAdminDb: () => DatabaseAdapter | null;
+}) {
+  const router = new Hono();
+
+  const audit = auditFromEngine(deps.getAdminDb, 'ediscovery');
+  // Holds placed from a case are recorded as retention actions, like holds placed directly
+  const auditRetention = auditFromEngine(deps.getAdminDb, 'retention');
+
+  const userOf = (c: any) => c.req.header('X-User-Id') || undefined;
+
+  /** The case, if the caller may access it */
+  const load = async (c: any): Promise<EDiscoveryCase | null> => {
+    const found = await cases.getCase(c.req.param('id'));
+    if (!found || !cases.canAccess(found, userOf(c), callerRole(c))) return null;
+    return found;
+  };
+
+  const status = (err: any) => /not found/i.test(err.message) ? 404 : 400;
+
+  // ─── Cases ────────────────────────────────────────────
+
+  router.get('/cases', async (c) => {
+    const orgId = c.req.query('orgId');
+    if (!orgId) return c.json({ error: 'orgId required' }, 400);
+    return c.json({ cases: await cases.listCases(orgId, userOf(c), callerRole(c)) });
+  });
+
+  // { orgId, name, matter?, description?, custodians? }
+  router.post('/cases', async (c) => {
+    const body = await c.req.json().catch(() => ({}));
+    if (!body.orgId) return c.json({ error: 'orgId required' }, 400);
+    try {
+      const created = await cases.createCase({ ...body, createdBy: userOf(c) });
+      audit(c, 'case_create', `ediscovery_case:${created.id}`, { name: created.name, matter: created.matter, custodians: created.custodians }, created.orgId);
+      return c.json({ case: created }, 201);
+    } catch (err: any) {
+      return c.json({ error: err.message }, 400);
+    }
+  });
+
+  // Transcript messages are left out here; fetch the item for them
+  router.get('/cases/:id', async (c) => {
+    const found = await load(c);
+    if (!found) return c.json({ error: 'Case not found' }, 404);
+    const items = (await cases.getItems(found.id)).map(i => i.kind === 'transcript' ? { ...i, data: { query: i.data.query, count: i.data.count } } : i);
+    const activeHolds = new Set((await deps.retention.getHolds(found.orgId)).map(h => h.id));
+    audit(c, 'case_view', `ediscovery_case:${found.id}`, undefined, found.orgId);
+    return c.json({
+      case: found,
+      items: items.map(i => i.kind === 'hold' ? { ...i, data: { ...i.data, active: activeHolds.has(i.data.holdId) } } : i),
+    });
+  });
+
+  // { name?, matter?, description?, custodians?, status? }
+  router.patch('/cases/:id', async (c) => {
+    const found = await load(c);
+    if (!found) return c.json({ error: 'Case not found' }, 404);
+    const body = await c.req.json().catch(() => ({}));
+    try {
+      const updated = await cases.updateCase(found.id, body);
+      const action = body.status && body.status !== found.status ? (body.status === 'closed' ? 'case_close' : 'case_reopen') : 'case_update';
+      audit(c, action, `ediscovery_case:${found.id}`, {
+        fields: Object.keys(body),
+        ...(body.custodians ? { custodiansBefore: found.custodians, custodiansAfter: updated.custodians } : {}),
+      }, found.orgId);
+      return c.json({ case: updated });
+    } catch (err: any) {
+      return c.json({ error: err.message }, status(err));
+    }
+  });
+
+  // ─── Items ────────────────────────────────────────────
+
+  router.get('/cases/:id/items/:itemId', async (c) => {
+    const found = await load(c);
+    if (!found) return c.json({ error: 'Case not found' }, 404);
+    const item = await cases.getItem(found.id, c.req.param('itemId'));
+    if (!item) return c.json({ error: 'Item not found' }, 404);
+    if (item.kind !== 'transcript') return c.json({ item });
+    const viewer = viewerStamp(c);
+    audit(c, 'transcript_view', `ediscovery_case:${found.id}`, { itemId: item.id, count: item.data.count, viewer: viewer.label }, found.orgId);
+    return c.json({ item, viewer });
+  });
+
+  // { title?, query: { q, sender?, source?, from?, to? } }
+  router.post('/cases/:id/searches', async (c) => {
+    const found = await load(c);
+    if (!found) return c.json({ error: 'Case not found' }, 404);
+    const body = await c.req.json().catch(() => ({}));
+    try {
+      const item = await cases.saveSearch(found.id, body.title, body.query || {}, userOf(c));
+      audit(c, 'search_save', `ediscovery_case:${found.id}`, { itemId: item.id, query: item.data.query }, found.orgId);
+      return c.json({ item }, 201);
+    } catch (err: any) {
+      return c.json({ error: err.message }, status(err));
+    }
+  });
+
+  // { title?, query } — freezes the current matches into the case
+  router.post('/cases/:id/transcripts', async (c) => {
+    const found = await load(c);
+    if (!found) return c.json({ error: 'Case not found' }, 404);
+    const body = await c.req.json().catch(() => ({}));
+    try {
+      const item = await cases.exportTranscript(found.id, body.title, body.query || {}, deps.messageSearch, userOf(c));
+      audit(c, 'transcript_export', `ediscovery_case:${found.id}`, { itemId: item.id, query: item.data.query, count: item.data.count }, found.orgId);
+      return c.json({ item: { ...item, data: { query: item.data.query, count: item.data.count } } }, 201);
+    } catch (err: any) {
+      return c.json({ error: err.message }, status(err));
+    }
+  });
+
+  // { agentId, reason } — places a legal hold, or links the agent's existing one
+  router.post('/cases/:id/holds', async (c) => {
+    const found = await load(c);
+    if (!found) return c.json({ error: 'Case not found' }, 404);
+    if (found.status === 'closed') return c.json({ error: 'Case is closed; reopen it to make changes' }, 400);
+    const body = await c.req.json().catch(() => ({}));
+    if (!body.agentId) return c.json({ error: 'agentId required' }, 400);
+    try {
+      let hold = (await deps.retention.getHolds(found.orgId)).find(h => h.agentId === body.agentId);
+      if (!hold) {
+        hold = await deps.retention.placeHold({
+          orgId: found.orgId, agentId: body.agentId,
+          reason: body.reason || `e-Discovery case: ${found.matter ? found.matter + ' — ' : ''}${found.name}`,
+          createdBy: userOf(c),
+        });
+        auditRetention(c, 'hold_place', `legal_hold:${hold.id}`, { agentId: hold.agentId, reason: hold.reason, caseId: found.id }, found.orgId);
+      }
+      const item = await cases.linkHold(found.id, hold, userOf(c));
+      audit(c, 'hold_link', `ediscovery_case:${found.id}`, { itemId: item.id, holdId: hold.id, agentId: hold.agentId }, found.orgId);
+      return c.json({ item, hold }, 201);
+    } catch (err: any) {
+      return c.json({ error: err.message }, status(err));
+    }
+  });
+
+  // { text }
+  router.post('/cases/:id/notes', async (c) => {
+    const found = await load(c);
+    if (!found) return c.json({ error: 'Case not found' }, 404);
+    const body = await c.req.json().catch(() => ({}));
+    try {
+      const item = await cases.addNote(found.id, body.text, userOf(c));
+      audit(c, 'note_add', `ediscovery_case:${found.id}`, { itemId: item.id }, found.orgId);
+      return c.json({ item }, 201);
+    } catch (err: any) {
+      return c.json({ error: err.message }, status(err));
+    }
+  });
+
+  // Unlinks a saved search or hold; the hold itself stays in place
+  router.delete('/cases/:id/items/:itemId', async (c) => {
+    const found = await load(c);
+    if (!found) return c.json({ error: 'Case not found' }, 404);
+    try {
+      const item = await cases.removeItem(found.id, c.req.param('itemId'));
+      audit(c, `${item.kind}_unlink`, `ediscovery_case:${found.id}`, { itemId: item.id, title: item.title }, found.orgId);
+      return c.json({ success: true });
+    } catch (err: any) {
+      return c.json({ error: err.message }, status(err));
+    }
+  });
+
+  // ─── Export Package ───────────────────────────────────
+
+  router.get('/cases/:id/export', async (c) => {
+    const found = await load(c);
+    if (!found) return c.json({ error: 'Case not found' }, 404);
+    try {
+      const activeHoldIds = new Set((await deps.retention.getHolds(found.orgId)).map(h => h.id));
+      const { name, zip } = await cases.buildExport(found.id, { search: deps.messageSearch, activeHoldIds, exportedBy: userOf(c) });
+      audit(c, 'case_export', `ediscovery_case:${found.id}`, { bytes: zip.length }, found.orgId);
+      return new Response(zip, {
+        headers: {
+          'Content-Type': 'application/zip',
+          'Content-Disposition': `attachment; filename="${name}.zip"`,
+          'Cache-Control': 'no-store',
+        },
+      });
+    } catch (err: any) {
+      return c.json({ error: err.message }, 500);
+    }
+  });
+
+  return router;
+}
@@ -0,0 +1,353 @@
+/**
+ * e-Discovery Cases — Named legal matters that bundle the evidence for them
+ *
+ * A case collects, in one place:
+ *   - saved message searches (re-runnable queries over Message Search)
+ *   - legal holds placed for the matter (links to Retention's legal_holds)
+ *   - transcripts — frozen snapshots of the messages a search matched when
+ *     they were exported, so later edits or purges can't change them
+ *   - reviewer notes, which are append-only
+ *
+ * Only the case's custodians can see or change it. Owners can open any case
+ * so a matter is never orphaned when its custodians leave; every such
+ * access is audited by the routes like any other.
+ *
+ * The export package is a ZIP of the case record, notes, holds, every
+ * transcript and a fresh run of every saved search, plus a manifest with a
+ * SHA-256 for each file so the package can be verified later.
+ */
+
+import { createHash } from 'crypto';
+import type { EngineDatabase } from './db-adapter.js';
+import type { MessageSearch, MessageSearchHit, MessageSearchQuery } from './message-search.js';
+import { createZip, type ZipEntry } from '../lib/zip.js';
+import { csvLine } from '../lib/csv.js';
+
+// ─── Types ──────────────────────────────────────────────
+
+export type CaseStatus = 'open' | 'closed';
+export type CaseItemKind = 'search' | 'hold' | 'transcript' | 'note';
+
+export interface EDiscoveryCase {
+  id: string;
+  orgId: string;
+  name: string;
+  /** External matter or docket number */
+  matter?: string;
+  description?: string;
+  status: CaseStatus;
+  /** User IDs allowed to access the case */
+  custodians: string[];
+  createdBy?: string;
+  createdAt: string;
+  updatedAt: string;
+  closedAt?: string;
+}
+
+export interface CaseItem {
+  id: string;
+  caseId: string;
+  kind: CaseItemKind;
+  title: string;
+  /**
+   * search: { query }            hold: { holdId, agentId, reason }
+   * transcript: { query, count, messages }   note: { text }
+   */
+  data: Record<string, any>;
+  createdBy?: string;
+  createdAt: string;
+}
+
+export type SavedSearchQuery = Omit<MessageSearchQuery, 'orgId' | 'limit' | 'offset'>;
+
+// ─── Config ─────────────────────────────────────────────
+
+const MAX_CUSTODIANS = 50;
+const MAX_NOTE_CHARS = 20_000;
+/** Messages frozen into one transcript; narrow the search for more */
+const MAX_TRANSCRIPT_MESSAGES = 5000;
+
+// ─── Case Manager ───────────────────────────────────────
+
+export class CaseManager {
+  private engineDb?: EngineDatabase;
+
+  async setDb(db: EngineDatabase): Promise<void> {
+    this.engineDb = db;
+  }
+
+  private get db(): EngineDatabase {
+    if (!this.engineDb) throw new Error('e-Discovery database not initialized');
+    return this.engineDb;
+  }
+
+  /** Custodians can open their cases; owners can open any. */
+  canAccess(c: EDiscoveryCase, userId?: string, role?: string): boolean {
+    if (role === 'owner') return true;
+    return !!userId && c.custodians.includes(userId);
+  }
+
+  // ─── Cases ────────────────────────────────────────────
+
+  /** Cases the user can access, newest first, with item counts per kind. */
+  async listCases(orgId: string, userId?: string, role?: string): Promise<Array<EDiscoveryCase & { counts: Partial<Record<CaseItemKind, number>> }>> {
+    if (!this.engineDb) return [];
+    const rows = await this.engineDb.query<any>('SELECT * FROM ediscovery_cases WHERE org_id = ? ORDER BY created_at DESC', [orgId]);
+    const cases = rows.map((r: any) => this.rowToCase(r)).filter(c => this.canAccess(c, userId, role));
+    if (cases.length === 0) return [];
+    const counts = await this.engineDb.query<any>(
+      `SELECT case_id, kind, COUNT(*) AS n FROM ediscovery_case_items WHERE case_id IN (${cases.map(() => '?').join(',')}) GROUP BY case_id, kind`,
+      cases.map(c => c.id)
+    );
+    return cases.map(c => ({
+      ...c,
+      counts: Object.fromEntries(counts.filter((r: any) => r.case_id === c.id).map((r: any) => [r.kind, Number(r.n)])),
+    }));
+  }
+
+  async getCase(id: string): Promise<EDiscoveryCase | undefined> {
+    if (!this.engineDb) return undefined;
+    const row = await this.engineDb.get<any>('SELECT * FROM ediscovery_cases WHERE id = ?', [id]);
+    return row ? this.rowToCase(row) : undefined;
+  }
+
+  async createCase(input: { orgId: string; name: string; matter?: string; description?: string; custodians?: string[]; createdBy?: string }): Promise<EDiscoveryCase> {
+    if (!input.name?.trim()) throw new Error('A case name is required');
+    const now = new Date().toISOString();
+    const c: EDiscoveryCase = {
+      id: crypto.randomUUID(),
+      orgId: input.orgId,
+      name: input.name.trim().slice(0, 200),
+      matter: input.matter?.trim().slice(0, 100) || undefined,
+      description: input.description?.trim().slice(0, 5000) || undefined,
+      status: 'open',
+      // Whoever opens the case is always a custodian of it
+      custodians: normalizeCustodians([...(input.custodians || []), ...(input.createdBy ? [input.createdBy] : [])]),
+      createdBy: input.createdBy,
+      createdAt: now,
+      updatedAt: now,
+    };
+    await this.db.execute(
+      'INSERT INTO ediscovery_cases (id, org_id, name, matter, description, status, custodians, created_by, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)',
+      [c.id, c.orgId, c.name, c.matter || null, c.description || null, c.status, JSON.stringify(c.custodians), c.createdBy || null, c.createdAt, c.updatedAt]
+    );
+    return c;
+  }
+
+  async updateCase(id: string, patch: { name?: string; matter?: string; description?: string; custodians?: string[]; status?: CaseStatus }): Promise<EDiscoveryCase> {
+    const existing = await this.getCase(id);
+    if (!existing) throw new Error('Case not found');
+    if (existing.status === 'closed' && patch.status !== 'open') throw new Error('Case is closed; reopen it to make changes');
+    if (patch.status && patch.status !== 'open' && patch.status !== 'closed') throw new Error('status must be open or closed');
+    const next: EDiscoveryCase = {
+      ...existing,
+      name: patch.name !== undefined ? patch.name.trim().slice(0, 200) : existing.name,
+      matter: patch.matter !== undefined ? patch.matter.trim().slice(0, 100) || undefined : existing.matter,
+      description: patch.description !== undefined ? patch.description.trim().slice(0, 5000) || undefined : existing.description,
+      custodians: patch.custodians !== undefined ? normalizeCustodians(patch.custodians) : existing.custodians,
+      status: patch.status || existing.status,
+      updatedAt: new Date().toISOString(),
+    };
+    if (!next.name) throw new Error('A case name is required');
+    if (next.custodians.length === 0) throw new Error('A case needs at least one custodian');
+    if (next.status !== existing.status) next.closedAt = next.status === 'closed' ? next.updatedAt : undefined;
+    await this.db.execute(
+      'UPDATE ediscovery_cases SET name = ?, matter = ?, description = ?, custodians = ?, status = ?, updated_at = ?, closed_at = ? WHERE id = ?',
+      [next.name, next.matter || null, next.description || null, JSON.stringify(next.custodians), next.status, next.updatedAt, next.closedAt || null, id]
+    );
+    return next;
+  }
+
+  // ─── Items ────────────────────────────────────────────
+
+  async getItems(caseId: string, kind?: CaseItemKind): Promise<CaseItem[]> {
+    if (!this.engineDb) return [];
+    const rows = kind
+      ? await this.engineDb.query<any>('SELECT * FROM ediscovery_case_items WHERE case_id = ? AND kind = ? ORDER BY created_at DESC', [caseId, kind])
+      : await this.engineDb.query<any>('SELECT * FROM ediscovery_case_items WHERE case_id = ? ORDER BY created_at DESC', [caseId]);
+    return rows.map((r: any) => this.rowToItem(r));
+  }
+
+  async getItem(caseId: string, itemId: string): Promise<CaseItem | undefined> {
+    if (!this.engineDb) return undefined;
+    const row = await this.engineDb.get<any>('SELECT * FROM ediscovery_case_items WHERE id = ? AND case_id = ?', [itemId, caseId]);
+    return row ? this.rowToItem(row) : undefined;
+  }
+
+  async saveSearch(caseId: string, title: string, query: SavedSearchQuery, createdBy?: string): Promise<CaseItem> {
+    if (!query.q?.trim()) throw new Error('A search query is required');
+    return this.addItem(caseId, 'search', title || query.q, { query: cleanQuery(query) }, createdBy);
+  }
+
+  async linkHold(caseId: string, hold: { id: string; agentId: string; reason: string }, createdBy?: string): Promise<CaseItem> {
+    const linked = (await this.getItems(caseId, 'hold')).find(i => i.data.holdId === hold.id);
+    if (linked) return linked;
+    return this.addItem(caseId, 'hold', hold.reason, { holdId: hold.id, agentId: hold.agentId, reason: hold.reason }, createdBy);
+  }
+
+  /** Freeze the messages `query` matches right now into the case. */
+  async exportTranscript(caseId: string, title: string, query: SavedSearchQuery, search: MessageSearch, createdBy?: string): Promise<CaseItem> {
+    const c = await this.requireOpen(caseId);
+    const messages = (await search.exportMatches({ ...cleanQuery(query), orgId: c.orgId })).slice(0, MAX_TRANSCRIPT_MESSAGES);
+    if (messages.length === 0) throw new Error('The search matched no messages');
+    // Oldest first reads as a transcript
+    messages.sort((a, b) => a.createdAt.localeCompare(b.createdAt));
+    return this.addItem(caseId, 'transcript', title || query.q, {
+      query: cleanQuery(query),
+      count: messages.length,
+      messages: messages.map(m => ({ id: m.id, source: m.source, sender: m.sender, recipient: m.recipient, subject: m.subject, text: m.text, createdAt: m.createdAt })),
+    }, createdBy);
+  }
+
+  async addNote(caseId: string, text: string, createdBy?: string): Promise<CaseItem> {
+    if (!text?.trim()) throw new Error('Note text is required');
+    const body = text.trim().slice(0, MAX_NOTE_CHARS);
+    return this.addItem(caseId, 'note', body.split('\n')[0].slice(0, 120), { text: body }, createdBy);
+  }
+
+  /**
+   * Unlink a saved search or hold. Unlinking a hold doesn't release it.
+   * Notes and transcripts are part of the record and can't be removed.
+   */
+  async removeItem(caseId: string, itemId: string): Promise<CaseItem> {
+    await this.requireOpen(caseId);
+    const item = await this.getItem(caseId, itemId);
+    if (!item) throw new Error('Item not found');
+    if (item.kind === 'note' || item.kind === 'transcript') throw new Error(`A ${item.kind} is part of the case record and can't be removed`);
+    await this.db.execute('DELETE FROM ediscovery_case_items WHERE id = ?', [itemId]);
+    return item;
+  }
+
+  // ─── Export Package ───────────────────────────────────
+
+  /**
+   * ZIP of everything in the case. Saved searches are re-run now; holds
+   * are annotated with whether they are still in place.
+   */
+  async buildExport(caseId: string, deps: { search: MessageSearch; activeHoldIds: Set<string>; exportedBy?: string }): Promise<{ name: string; zip: Buffer }> {
+    const c = await this.getCase(caseId);
+    if (!c) throw new Error('Case not found');
+    const items = (await this.getItems(caseId)).reverse();
+    const files: ZipEntry[] = [];
+    const slug = (s: string) => s.toLowerCase().replace(/[^a-z0-9]+/g, '-').replace(/^-|-$/g, '').slice(0, 50) || 'untitled';
+    const transcriptCsv = (messages: Array<Partial<MessageSearchHit>>) =>
+      csvLine(['id', 'createdAt', 'source', 'sender', 'recipient', 'subject', 'text'])
+      + messages.map(m => csvLine([m.id, m.createdAt, m.source, m.sender, m.recipient, m.subject || '', m.text])).join('');
+
+    files.push({ name: 'case.json', data: JSON.stringify(c, null, 2) });
+
+    const notes = items.filter(i => i.kind === 'note');
+    files.push({
+      name: 'notes.md',
+      data: `# Reviewer notes — ${c.name}\n\n` + (notes.length
+        ? notes.map(n => `## ${n.createdAt} — ${n.createdBy || 'unknown'}\n\n${n.data.text}\n`).join('\n')
+        : '_No notes._\n'),
+    });
+
+    files.push({
+      name: 'holds.csv',
+      data: csvLine(['holdId', 'agentId', 'reason', 'linkedBy', 'linkedAt', 'stillActive'])
+        + items.filter(i => i.kind === 'hold').map(i => csvLine([i.data.holdId, i.data.agentId, i.data.reason, i.createdBy, i.createdAt, deps.activeHoldIds.has(i.data.holdId) ? 'yes' : 'no'])).join(''),
+    });
+
+    const used = new Set<string>();
+    const unique = (base: string) => { let n = base, k = 2; while (used.has(n)) n = `${base}-${k++}`; used.add(n); return n; };
+
+    for (const t of items.filter(i => i.kind === 'transcript')) {
+      files.push({ name: `transcripts/${unique(slug(t.title) + '-' + t.createdAt.slice(0, 10))}.csv`, data: transcriptCsv(t.data.messages || []) });
+    }
+    const searches: Array<{ title: string; query: SavedSearchQuery; matches: number; file: string }> = [];
+    for (const s of items.filter(i => i.kind === 'search')) {
+      const matches = await deps.search.exportMatches({ ...s.data.query, orgId: c.orgId }).catch(() => [] as MessageSearchHit[]);
+      const file = `searches/${unique(slug(s.title))}.csv`;
+      files.push({ name: file, data: transcriptCsv(matches) });
+      searches.push({ title: s.title, query: s.data.query, matches: matches.length, file });
+    }
+
+    const manifest = {
+      case: { id: c.id, name: c.name, matter: c.matter, status: c.status },
+      exportedAt: new Date().toISOString(),
+      exportedBy: deps.exportedBy,
+      items: items.map(i => ({ id: i.id, kind: i.kind, title: i.title, createdBy: i.createdBy, createdAt: i.createdAt, ...(i.kind === 'transcript' ? { query: i.data.query, count: i.data.count } : {}) })),
+      searches,
+      files: files.map(f => ({ name: f.name, sha256: createHash('sha256').update(f.data).digest('hex') })),
+    };
+    files.push({ name: 'manifest.json', data: JSON.stringify(manifest, null, 2) });
+
+    return { name: `case-${slug(c.matter || c.name)}-${new Date().toISOString().slice(0, 10)}`, zip: createZip(files) };
+  }
+
+  // ─── Internals ────────────────────────────────────────
+
+  private async requireOpen(caseId: string): Promise<EDiscoveryCase> {
+    const c = await this.getCase(caseId);
+    if (!c) throw new Error('Case not found');
+    if (c.status === 'closed') throw new Error('Case is closed; reopen it to make changes');
+    return c;
+  }
+
+  private async addItem(caseId: string, kind: CaseItemKind, title: string, data: Record<string, any>, createdBy?: string): Promise<CaseItem> {
+    await this.requireOpen(caseId);
+    const item: CaseItem = {
+      id: crypto.randomUUID(),
+      caseId,
+      kind,
+      title: (title || kind).trim().slice(0, 200),
+      data,
+      createdBy,
+      createdAt: new Date().toISOString(),
+    };
+    await this.db.execute(
+      'INSERT INTO ediscovery_case_items (id, case_id, kind, title, data, created_by, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)',
+      [item.id, item.caseId, item.kind, item.title, JSON.stringify(item.data), item.createdBy || null, item.createdAt]
+    );
+    await this.db.execute('UPDATE ediscovery_cases SET updated_at = ? WHERE id = ?', [item.createdAt, caseId]);
+    return item;
+  }
+
+  private rowToCase(r: any): EDiscoveryCase {
+    return {
+      id: r.id,
+      orgId: r.org_id,
+      name: r.name,
+      matter: r.matter || undefined,
+      description: r.description || undefined,
+      status: r.status,
+      custodians: typeof r.custodians === 'string' ? JSON.parse(r.custodians) : r.custodians || [],
+      createdBy: r.created_by || undefined,
+      createdAt: toIso(r.created_at)!,
+      updatedAt: toIso(r.updated_at)!,
+      closedAt: toIso(r.closed_at),
+    };
+  }
+
+  private rowToItem(r: any): CaseItem {
+    return {
+      id: r.id,
+      caseId: r.case_id,
+      kind: r.kind,
+      title: r.title,
+      data: r.data ? (typeof r.data === 'string' ? JSON.parse(r.data) : r.data) : {},
+      createdBy: r.created_by || undefined,
+      createdAt: toIso(r.created_at)!,
+    };
+  }
+}
+
+function normalizeCustodians(ids: string[]): string[] {
+  return [...new Set(ids.filter(id => typeof id === 'string' && id.trim()).map(id => id.trim()))].slice(0, MAX_CUSTODIANS);
+}
+
+/** Keep only the fields a saved search replays */
+function cleanQuery(q: SavedSearchQuery): SavedSearchQuery {
+  const out: SavedSearchQuery = { q: String(q.q || '').trim() };
+  if (q.sender) out.sender = String(q.sender);
+  if (q.source) out.source = String(q.source);
+  if (q.from) out.from = String(q.from);
+  if (q.to) out.to = String(q.to);
+  return out;
+}
+
+function toIso(v: any): string | undefined {
+  return v ? new Date(v).toISOString() : undefined;
+}
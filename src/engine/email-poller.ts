@@ -61,6 +61,8 @@ export interface EmailPollerConfig {
   agentPorts?: Record<string, number>;
   /** Workforce manager for work hours enforcement */
   workforce?: any;
+  /** Called for each new email handed to an agent (e.g. to fire webhooks) */
+  onMessage?: (agentId: string, message: { channel: string; from: string; subject?: string; text: string; threadId?: string }) => void;
 }
 
 interface EngineDB {
@@ -562,6 +564,7 @@ export class EmailPoller {
 
     // Extract body
     const body = this.extractBody(fullMsg);
+    this.config.onMessage?.(mailbox.agentId, { channel: 'email', from: from.email, subject, text: body || fullMsg.snippet || '', threadId: fullMsg.threadId });
 
     // Dispatch to agent process
     await this.dispatchToAgent(mailbox, {
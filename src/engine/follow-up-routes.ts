@@ -0,0 +1,79 @@
+/**
+ * Message Follow-up Routes
+ * Mounted at /follow-ups/* on the engine sub-app. Follow-ups are always
+ * scoped to the authenticated user (X-User-Id injected by the enterprise server).
+ */
+
+import { Hono } from 'hono';
+import { FOLLOW_UP_KINDS, type FollowUpStore } from './follow-ups.js';
+import type { AgentCommunicationBus } from './communication.js';
+
+const MAX_REMIND_AHEAD_MS = 365 * 86_400_000;
+
+function parseRemindAt(value: unknown): string | null {
+  if (typeof value !== 'string') return null;
+  const d = new Date(value);
+  if (isNaN(d.getTime()) || d.getTime() > Date.now() + MAX_REMIND_AHEAD_MS) return null;
+  return d.toISOString();
+}
+
+export function createFollowUpRoutes(followUps: FollowUpStore, commBus: AgentCommunicationBus) {
+  const router = new Hono();
+
+  router.use('*', async (c, next) => {
+    if (!c.req.header('X-User-Id')) return c.json({ error: 'Authentication required' }, 401);
+    return next();
+  });
+
+  // ?due=true → only items whose reminder time has passed (notifications center)
+  router.get('/', async (c) => {
+    const list = await followUps.list(c.req.header('X-User-Id')!, {
+      orgId: c.req.query('orgId') || undefined,
+      dueOnly: c.req.query('due') === 'true',
+    });
+    // Attach the message so the notifications center can show subject/participants
+    const items = list.map(f => {
+      const msg = commBus.getMessage(f.messageId);
+      return { ...f, message: msg ? { id: msg.id, subject: msg.subject, fromAgentId: msg.fromAgentId, toAgentId: msg.toAgentId, createdAt: msg.createdAt } : null };
+    });
+    return c.json({ followUps: items, due: items.filter(f => f.remindAt <= new Date().toISOString()).length });
+  });
+
+  router.post('/', async (c) => {
+    const body = await c.req.json().catch(() => ({}));
+    if (!body.messageId || !body.orgId) return c.json({ error: 'messageId and orgId required' }, 400);
+    const kind = body.kind || 'followup';
+    if (!FOLLOW_UP_KINDS.includes(kind)) return c.json({ error: 'kind must be snooze or followup' }, 400);
+    const remindAt = parseRemindAt(body.remindAt);
+    if (!remindAt) return c.json({ error: 'remindAt must be a valid date within the next year' }, 400);
+    if (!commBus.getMessage(body.messageId)) return c.json({ error: 'Message not found' }, 404);
+    try {
+      const followUp = await followUps.schedule({ userId: c.req.header('X-User-Id')!, orgId: body.orgId, messageId: body.messageId, kind, remindAt, note: body.note });
+      return c.json({ followUp }, 201);
+    } catch (e: any) {
+      return c.json({ error: e.message }, 400);
+    }
+  });
+
+  router.patch('/:id', async (c) => {
+    const body = await c.req.json().catch(() => ({}));
+    const remindAt = parseRemindAt(body.remindAt);
+    if (!remindAt) return c.json({ error: 'remindAt must be a valid date within the next year' }, 400);
+    const followUp = await followUps.reschedule(c.req.header('X-User-Id')!, c.req.param('id'), remindAt);
+    if (!followUp) return c.json({ error: 'Follow-up not found' }, 404);
+    return c.json({ followUp });
+  });
+
+  router.post('/:id/done', async (c) => {
+    const ok = await followUps.complete(c.req.header('X-User-Id')!, c.req.param('id'));
+    if (!ok) return c.json({ error: 'Follow-up not found' }, 404);
+    return c.json({ ok: true });
+  });
+
+  router.delete('/:id', async (c) => {
+    await followUps.delete(c.req.header('X-User-Id')!, c.req.param('id'));
+    return c.json({ ok: true });
+  });
+
+  return router;
+}
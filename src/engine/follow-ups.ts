@@ -0,0 +1,149 @@
+/**
+ * Message Follow-ups — Snooze & Reminders
+ *
+ * Lets a dashboard user resurface a message thread at a chosen time:
+ *   - snooze:   hide the message from the inbox views until remindAt
+ *   - followup: keep it visible, but remind at remindAt
+ * Due items (remindAt <= now, still pending) feed the notifications center.
+ * Follow-ups are per user; completed ones are purged after 30 days.
+ */
+
+import type { EngineDatabase } from './db-adapter.js';
+
+// ─── Types ──────────────────────────────────────────────
+
+export type FollowUpKind = 'snooze' | 'followup';
+export type FollowUpStatus = 'pending' | 'done';
+
+export interface FollowUp {
+  id: string;
+  userId: string;
+  orgId: string;
+  messageId: string;
+  kind: FollowUpKind;
+  note?: string;
+  remindAt: string;
+  status: FollowUpStatus;
+  createdAt: string;
+  updatedAt: string;
+}
+
+// ─── Config ─────────────────────────────────────────────
+
+const MAX_PENDING_PER_USER = 500;
+const DONE_RETENTION_DAYS = 30;
+const SWEEP_INTERVAL_MS = 60 * 60 * 1000;
+
+export const FOLLOW_UP_KINDS: FollowUpKind[] = ['snooze', 'followup'];
+
+// ─── Follow-up Store ────────────────────────────────────
+
+export class FollowUpStore {
+  private engineDb?: EngineDatabase;
+  private sweepTimer: ReturnType<typeof setInterval> | null = null;
+
+  async setDb(db: EngineDatabase): Promise<void> {
+    this.engineDb = db;
+    await this.purgeDone();
+    if (!this.sweepTimer) {
+      this.sweepTimer = setInterval(() => { this.purgeDone().catch(() => {}); }, SWEEP_INTERVAL_MS);
+      if (typeof this.sweepTimer === 'object' && 'unref' in this.sweepTimer) this.sweepTimer.unref();
+    }
+  }
+
+  /** Pending follow-ups for a user, soonest first. `dueOnly` limits to remindAt <= now. */
+  async list(userId: string, opts: { orgId?: string; dueOnly?: boolean } = {}): Promise<FollowUp[]> {
+    if (!this.engineDb) return [];
+    const where = ['user_id = ?', "status = 'pending'"];
+    const params: any[] = [userId];
+    if (opts.orgId) { where.push('org_id = ?'); params.push(opts.orgId); }
+    if (opts.dueOnly) { where.push('remind_at <= ?'); params.push(new Date().toISOString()); }
+    const rows = await this.engineDb.query<any>(
+      `SELECT * FROM message_followups WHERE ${where.join(' AND ')} ORDER BY remind_at ASC`,
+      params,
+    );
+    return rows.map((r: any) => this.rowToFollowUp(r));
+  }
+
+  async get(userId: string, id: string): Promise<FollowUp | null> {
+    if (!this.engineDb) return null;
+    const row = await this.engineDb.get<any>('SELECT * FROM message_followups WHERE id = ? AND user_id = ?', [id, userId]);
+    return row ? this.rowToFollowUp(row) : null;
+  }
+
+  /**
+   * Schedule a follow-up. A message has at most one pending follow-up per
+   * user — scheduling again replaces the previous one.
+   */
+  async schedule(input: { userId: string; orgId: string; messageId: string; kind: FollowUpKind; remindAt: string; note?: string }): Promise<FollowUp> {
+    if (!this.engineDb) throw new Error('Follow-up storage not initialized');
+    const pending = await this.list(input.userId);
+    const replacing = pending.find(f => f.messageId === input.messageId);
+    if (!replacing && pending.length >= MAX_PENDING_PER_USER) throw new Error(`Too many pending follow-ups (max ${MAX_PENDING_PER_USER})`);
+
+    const now = new Date().toISOString();
+    const followUp: FollowUp = {
+      id: replacing?.id || crypto.randomUUID(),
+      userId: input.userId,
+      orgId: input.orgId,
+      messageId: input.messageId,
+      kind: input.kind,
+      note: input.note?.slice(0, 500) || undefined,
+      remindAt: new Date(input.remindAt).toISOString(),
+      status: 'pending',
+      createdAt: replacing?.createdAt || now,
+      updatedAt: now,
+    };
+    if (replacing) await this.engineDb.execute('DELETE FROM message_followups WHERE id = ?', [replacing.id]);
+    await this.engineDb.execute(
+      `INSERT INTO message_followups (id, user_id, org_id, message_id, kind, note, remind_at, status, created_at, updated_at)
+       VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+      [followUp.id, followUp.userId, followUp.orgId, followUp.messageId, followUp.kind, followUp.note || null,
+       followUp.remindAt, followUp.status, followUp.createdAt, followUp.updatedAt],
+    );
+    return followUp;
+  }
+
+  /** Push the reminder time forward (e.g. "remind me again in an hour"). */
+  async reschedule(userId: string, id: string, remindAt: string): Promise<FollowUp | null> {
+    const existing = await this.get(userId, id);
+    if (!existing || existing.status !== 'pending') return null;
+    return this.schedule({ ...existing, remindAt });
+  }
+
+  async complete(userId: string, id: string): Promise<boolean> {
+    const existing = await this.get(userId, id);
+    if (!existing) return false;
+    await this.engineDb!.execute(
+      "UPDATE message_followups SET status = 'done', updated_at = ? WHERE id = ? AND user_id = ?",
+      [new Date().toISOString(), id, userId],
+    );
+    return true;
+  }
+
+  async delete(userId: string, id: string): Promise<void> {
+    await this.engineDb?.execute('DELETE FROM message_followups WHERE id = ? AND user_id = ?', [id, userId]);
+  }
+
+  async purgeDone(): Promise<void> {
+    try {
+      const cutoff = new Date(Date.now() - DONE_RETENTION_DAYS * 86_400_000).toISOString();
+      await this.engineDb?.execute("DELETE FROM message_followups WHERE status = 'done' AND updated_at <= ?", [cutoff]);
+    } catch { /* table may not exist yet */ }
+  }
+
+  private rowToFollowUp(r: any): FollowUp {
+    return {
+      id: r.id,
+      userId: r.user_id,
+      orgId: r.org_id,
+      messageId: r.message_id,
+      kind: r.kind,
+      note: r.note || undefined,
+      remindAt: new Date(r.remind_at).toISOString(),
+      status: r.status,
+      createdAt: String(r.created_at),
+      updatedAt: String(r.updated_at),
+    };
+  }
+}
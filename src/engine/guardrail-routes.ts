@@ -5,13 +5,16 @@
 
 import { Hono } from 'hono';
 import type { GuardrailEngine } from './guardrails.js';
+import { requireCapability } from '../middleware/role-permissions.js';
 
 export function createGuardrailRoutes(guardrails: GuardrailEngine, opts?: { getWorkforceOffDuty?: (agentId: string) => boolean }) {
   const router = new Hono();
+  const canControl = requireCapability('agents.control');
+  const canManage = requireCapability('guardrails.manage');
 
   // ─── Interventions ─────────────────────────────────────
 
-  router.post('/pause/:id', async (c) => {
+  router.post('/pause/:id', canControl, async (c) => {
     const { reason } = await c.req.json().catch(() => ({ reason: 'Manual pause' }));
     const triggeredBy = c.req.header('X-User-Id') || 'admin';
     const orgId = c.req.query('orgId') || undefined;
@@ -19,7 +22,7 @@ export function createGuardrailRoutes(guardrails: GuardrailEngine, opts?: { getW
     return c.json({ success: true, intervention: record });
   });
 
-  router.post('/resume/:id', async (c) => {
+  router.post('/resume/:id', canControl, async (c) => {
     const { reason } = await c.req.json().catch(() => ({ reason: 'Manual resume' }));
     const triggeredBy = c.req.header('X-User-Id') || 'admin';
     const orgId = c.req.query('orgId') || undefined;
@@ -27,7 +30,7 @@ export function createGuardrailRoutes(guardrails: GuardrailEngine, opts?: { getW
     return c.json({ success: true, intervention: record });
   });
 
-  router.post('/kill/:id', async (c) => {
+  router.post('/kill/:id', canControl, async (c) => {
     const { reason } = await c.req.json().catch(() => ({ reason: 'Emergency kill' }));
     const triggeredBy = c.req.header('X-User-Id') || 'admin';
     const orgId = c.req.query('orgId') || undefined;
@@ -61,7 +64,7 @@ export function createGuardrailRoutes(guardrails: GuardrailEngine, opts?: { getW
     return c.json({ rules, total: rules.length });
   });
 
-  router.post('/rules', async (c) => {
+  router.post('/rules', canManage, async (c) => {
     const body = await c.req.json();
     body.id = body.id || crypto.randomUUID();
     body.createdAt = body.createdAt || new Date().toISOString();
@@ -72,14 +75,14 @@ export function createGuardrailRoutes(guardrails: GuardrailEngine, opts?: { getW
     return c.json({ success: true, rule: body }, 201);
   });
 
-  router.put('/rules/:id', async (c) => {
+  router.put('/rules/:id', canManage, async (c) => {
     const body = await c.req.json();
     const updated = await guardrails.updateGuardrailRule(c.req.param('id'), body);
     if (!updated) return c.json({ error: 'Rule not found' }, 404);
     return c.json({ success: true, rule: updated });
   });
 
-  router.delete('/rules/:id', (c) => {
+  router.delete('/rules/:id', canManage, (c) => {
     guardrails.removeGuardrailRule(c.req.param('id'));
     return c.json({ success: true });
   });
@@ -101,13 +104,14 @@ export function createGuardrailRoutes(guardrails: GuardrailEngine, opts?: { getW
 
 export function createAnomalyRoutes(guardrails: GuardrailEngine) {
   const router = new Hono();
+  const canManage = requireCapability('guardrails.manage');
 
   router.get('/', (c) => {
     const rules = guardrails.getAnomalyRules(c.req.query('orgId') || undefined);
     return c.json({ rules, total: rules.length });
   });
 
-  router.post('/', async (c) => {
+  router.post('/', canManage, async (c) => {
     const body = await c.req.json();
     body.id = body.id || crypto.randomUUID();
     body.createdAt = body.createdAt || new Date().toISOString();
@@ -116,7 +120,7 @@ export function createAnomalyRoutes(guardrails: GuardrailEngine) {
     return c.json({ success: true, rule: body }, 201);
   });
 
-  router.delete('/:id', (c) => {
+  router.delete('/:id', canManage, (c) => {
     guardrails.removeAnomalyRule(c.req.param('id'));
     return c.json({ success: true });
   });
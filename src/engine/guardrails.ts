@@ -97,6 +97,7 @@ export class GuardrailEngine {
   private checkInterval?: NodeJS.Timeout;
   private refreshInterval?: NodeJS.Timeout;
   private onboardingManager?: { isOnboarded(agentId: string): boolean };
+  private interventionListeners: ((record: InterventionRecord) => void)[] = [];
 
   /** External references for intervention actions */
   private stopAgent?: (agentId: string, by: string, reason: string) => Promise<any>;
@@ -111,6 +112,12 @@ export class GuardrailEngine {
     this.onboardingManager = om;
   }
 
+  /** Called after every intervention is recorded (pause, resume, kill, anomaly). */
+  onIntervention(listener: (record: InterventionRecord) => void): () => void {
+    this.interventionListeners.push(listener);
+    return () => { this.interventionListeners = this.interventionListeners.filter(l => l !== listener); };
+  }
+
   async setDb(db: EngineDatabase): Promise<void> {
     this.engineDb = db;
     await this.loadFromDb();
@@ -397,6 +404,9 @@ export class GuardrailEngine {
       [record.id, record.orgId, record.agentId, record.type, record.reason, record.triggeredBy, JSON.stringify(record.metadata), record.createdAt]
     ).catch((err) => { console.error('[guardrails] Failed to persist intervention:', err); });
 
+    for (const listener of this.interventionListeners) {
+      try { listener(record); } catch { /* ignore */ }
+    }
     return record;
   }
 
@@ -0,0 +1,40 @@
+/**
+ * Housekeeping Routes
+ * Mounted at /housekeeping/* on the engine sub-app.
+ */
+
+import { Hono } from 'hono';
+import type { Housekeeping, HousekeepingKind } from './housekeeping.js';
+import type { DatabaseAdapter } from '../db/adapter.js';
+import { auditFromEngine } from './route-audit.js';
+import { isAdminCaller } from './caller-role.js';
+
+export function createHousekeepingRoutes(housekeeping: Housekeeping, deps: { getAdminDb?: () => DatabaseAdapter | null } = {}) {
+  const router = new Hono();
+
+  const audit = auditFromEngine(deps.getAdminDb);
+
+  const staleDaysOf = (v: any) => (v === undefined || v === null || v === '' ? undefined : Number(v) || 0);
+
+  // ?orgId=&staleDays=
+  router.get('/', async (c) => {
+    try {
+      return c.json(await housekeeping.report(c.req.query('orgId') || 'default', { staleDays: staleDaysOf(c.req.query('staleDays')) }));
+    } catch (e: any) { return c.json({ error: e.message }, 500); }
+  });
+
+  // { orgId, kind, ids, staleDays }
+  router.post('/cleanup', async (c) => {
+    if (!isAdminCaller(c)) return c.json({ error: 'Only admins can run housekeeping cleanup' }, 403);
+    const body = await c.req.json().catch(() => ({}));
+    const orgId = body.orgId || 'default';
+    if (!Array.isArray(body.ids) || body.ids.length === 0) return c.json({ error: 'ids must be a non-empty array' }, 400);
+    try {
+      const result = await housekeeping.cleanup(orgId, body.kind as HousekeepingKind, body.ids.map(String), { staleDays: staleDaysOf(body.staleDays) });
+      audit(c, 'housekeeping.cleanup', `housekeeping:${result.kind}`, { done: result.done, skipped: result.skipped, revokedApiKeys: result.revokedApiKeys }, orgId);
+      return c.json({ success: true, ...result });
+    } catch (e: any) { return c.json({ error: e.message }, 400); }
+  });
+
+  return router;
+}
@@ -0,0 +1,222 @@
+/**
+ * Housekeeping — duplicate and orphan detection
+ *
+ * One report over things that pile up and are easy to forget:
+ *
+ *   - duplicate agents: live agents sharing an email address, or the same
+ *     name and role. The oldest in each group is the one to keep.
+ *   - orphaned API keys: unrevoked keys whose creating user no longer exists
+ *   - unused skills: installed community skills no agent in the org can use
+ *   - unreferenced secrets: vault entries never decrypted since they were
+ *     stored, once older than the grace period
+ *
+ * Cleanup acts only on items the report currently flags — ids are checked
+ * against a fresh report, so a stale page can't archive the last agent of a
+ * group or delete a secret that has since been read.
+ */
+
+import type { EngineDatabase } from './db-adapter.js';
+import type { AgentLifecycleManager } from './lifecycle.js';
+import type { PermissionEngine } from './skills.js';
+import type { SecureVault } from './vault.js';
+import type { CommunitySkillRegistry } from './community-registry.js';
+import type { DatabaseAdapter } from '../db/adapter.js';
+import { revokeAgentApiKeys } from '../middleware/api-key-agents.js';
+
+// ─── Types ──────────────────────────────────────────────
+
+export type HousekeepingKind = 'duplicate-agents' | 'orphaned-api-keys' | 'unused-skills' | 'unreferenced-secrets';
+
+export const HOUSEKEEPING_KINDS: HousekeepingKind[] = ['duplicate-agents', 'orphaned-api-keys', 'unused-skills', 'unreferenced-secrets'];
+
+export interface DuplicateGroup {
+  /** What the agents share: the email, or "name / role" */
+  key: string;
+  reason: 'email' | 'name-role';
+  /** Oldest agent in the group; never offered for cleanup */
+  keepId: string;
+  agents: Array<{ id: string; name: string; email: string; role: string; status: string; createdAt: string }>;
+}
+
+export interface HousekeepingReport {
+  generatedAt: string;
+  orgId: string;
+  staleDays: number;
+  duplicateAgents: DuplicateGroup[];
+  orphanedApiKeys: Array<{ id: string; name: string; keyPrefix: string; createdBy: string; createdAt: string; lastUsedAt: string | null }>;
+  unusedSkills: Array<{ skillId: string; name: string; version: string; enabled: boolean; installedBy: string; installedAt: string }>;
+  unreferencedSecrets: Array<{ id: string; name: string; category: string; createdBy: string; createdAt: string }>;
+}
+
+export interface CleanupResult {
+  kind: HousekeepingKind;
+  done: string[];
+  skipped: Array<{ id: string; reason: string }>;
+  /** Service-account keys revoked because the agent they were bound to was archived */
+  revokedApiKeys?: Array<{ id: string; name: string; agentId: string }>;
+}
+
+const DEFAULT_STALE_DAYS = 30;
+const iso = (d: any) => (d instanceof Date ? d.toISOString() : d ? String(d) : '');
+
+// ─── Housekeeping ───────────────────────────────────────
+
+export class Housekeeping {
+  private db: EngineDatabase | null = null;
+  private lifecycle: AgentLifecycleManager;
+  private permissions: PermissionEngine;
+  private vault: SecureVault;
+  private community: CommunitySkillRegistry;
+  private getAdminDb: () => DatabaseAdapter | null;
+
+  constructor(opts: {
+    lifecycle: AgentLifecycleManager;
+    permissions: PermissionEngine;
+    vault: SecureVault;
+    community: CommunitySkillRegistry;
+    getAdminDb: () => DatabaseAdapter | null;
+  }) {
+    this.lifecycle = opts.lifecycle;
+    this.permissions = opts.permissions;
+    this.vault = opts.vault;
+    this.community = opts.community;
+    this.getAdminDb = opts.getAdminDb;
+  }
+
+  async setDb(db: EngineDatabase): Promise<void> {
+    this.db = db;
+  }
+
+  async report(orgId: string, opts: { staleDays?: number } = {}): Promise<HousekeepingReport> {
+    const staleDays = Math.max(0, Math.min(3650, Math.floor(opts.staleDays ?? DEFAULT_STALE_DAYS)));
+    const [duplicateAgents, orphanedApiKeys, unusedSkills, unreferencedSecrets] = await Promise.all([
+      this.findDuplicateAgents(),
+      this.findOrphanedApiKeys(),
+      this.findUnusedSkills(orgId),
+      this.findUnreferencedSecrets(orgId, staleDays),
+    ]);
+    return { generatedAt: new Date().toISOString(), orgId, staleDays, duplicateAgents, orphanedApiKeys, unusedSkills, unreferencedSecrets };
+  }
+
+  /**
+   * Archive duplicate agents, revoke orphaned keys, uninstall unused skills
+   * or delete unreferenced secrets. Ids the report doesn't flag are skipped.
+   */
+  async cleanup(orgId: string, kind: HousekeepingKind, ids: string[], opts: { staleDays?: number } = {}): Promise<CleanupResult> {
+    if (!HOUSEKEEPING_KINDS.includes(kind)) throw new Error(`Unknown cleanup kind "${kind}"`);
+    const report = await this.report(orgId, opts);
+    const adminDb = this.getAdminDb();
+    const result: CleanupResult = { kind, done: [], skipped: [] };
+
+    let flagged: Set<string>;
+    switch (kind) {
+      case 'duplicate-agents':
+        flagged = new Set(report.duplicateAgents.flatMap(g => g.agents.filter(a => a.id !== g.keepId).map(a => a.id)));
+        // An agent kept in one group may be a duplicate in another — keepers always win
+        for (const g of report.duplicateAgents) flagged.delete(g.keepId);
+        break;
+      case 'orphaned-api-keys': flagged = new Set(report.orphanedApiKeys.map(k => k.id)); break;
+      case 'unused-skills': flagged = new Set(report.unusedSkills.map(s => s.skillId)); break;
+      case 'unreferenced-secrets': flagged = new Set(report.unreferencedSecrets.map(s => s.id)); break;
+    }
+
+    for (const id of new Set(ids)) {
+      if (!flagged.has(id)) { result.skipped.push({ id, reason: 'No longer flagged' }); continue; }
+      try {
+        switch (kind) {
+          case 'duplicate-agents': {
+            await adminDb!.archiveAgent(id);
+            const revoked = await revokeAgentApiKeys(adminDb!, id).catch(() => []);
+            if (revoked.length) (result.revokedApiKeys ||= []).push(...revoked.map(k => ({ ...k, agentId: id })));
+            break;
+          }
+          case 'orphaned-api-keys': await adminDb!.revokeApiKey(id); break;
+          case 'unused-skills': await this.community.uninstall(orgId, id); break;
+          case 'unreferenced-secrets': if (!(await this.vault.deleteSecret(id))) throw new Error('Secret not found'); break;
+        }
+        result.done.push(id);
+      } catch (err: any) {
+        result.skipped.push({ id, reason: err.message });
+      }
+    }
+    return result;
+  }
+
+  // ─── Detectors ────────────────────────────────────────
+
+  private async findDuplicateAgents(): Promise<DuplicateGroup[]> {
+    const adminDb = this.getAdminDb();
+    if (!adminDb) return [];
+    const agents = (await adminDb.listAgents({ limit: 10_000 }))
+      .filter(a => a.status !== 'archived' && a.status !== 'retired')
+      .sort((a, b) => iso(a.createdAt).localeCompare(iso(b.createdAt)));
+
+    const groups = new Map<string, { reason: DuplicateGroup['reason']; key: string; agents: typeof agents }>();
+    const add = (id: string, reason: DuplicateGroup['reason'], key: string, agent: (typeof agents)[number]) => {
+      const g = groups.get(id) || { reason, key, agents: [] };
+      g.agents.push(agent);
+      groups.set(id, g);
+    };
+    for (const a of agents) {
+      if (a.email) add('email:' + a.email.toLowerCase(), 'email', a.email.toLowerCase(), a);
+      if (a.name) add('name:' + a.name.trim().toLowerCase() + '\u0000' + (a.role || '').toLowerCase(), 'name-role', `${a.name} / ${a.role || 'agent'}`, a);
+    }
+
+    const seen = new Set<string>();
+    const out: DuplicateGroup[] = [];
+    for (const g of groups.values()) {
+      if (g.agents.length < 2) continue;
+      // The same agents matching on both email and name+role are one group
+      const members = g.agents.map(a => a.id).join(',');
+      if (seen.has(members)) continue;
+      seen.add(members);
+      out.push({
+        key: g.key,
+        reason: g.reason,
+        keepId: g.agents[0].id,
+        agents: g.agents.map(a => ({ id: a.id, name: a.name, email: a.email, role: a.role, status: a.status, createdAt: iso(a.createdAt) })),
+      });
+    }
+    return out;
+  }
+
+  private async findOrphanedApiKeys(): Promise<HousekeepingReport['orphanedApiKeys']> {
+    const adminDb = this.getAdminDb();
+    if (!adminDb) return [];
+    const [keys, users] = await Promise.all([adminDb.listApiKeys(), adminDb.listUsers({ limit: 10_000 })]);
+    const userIds = new Set(users.map(u => u.id));
+    return keys
+      .filter(k => !k.revoked && k.createdBy && k.createdBy !== 'system' && !userIds.has(k.createdBy))
+      .map(k => ({ id: k.id, name: k.name, keyPrefix: k.keyPrefix, createdBy: k.createdBy, createdAt: iso(k.createdAt), lastUsedAt: k.lastUsedAt ? iso(k.lastUsedAt) : null }));
+  }
+
+  private async findUnusedSkills(orgId: string): Promise<HousekeepingReport['unusedSkills']> {
+    const installed = await this.community.getInstalledWithDetails(orgId).catch(() => []);
+    if (!installed.length) return [];
+    const agents = this.lifecycle.getAgentsByOrg(orgId).filter(a => a.state !== 'destroying');
+    const canUse = (skillId: string) => agents.some(a => {
+      if (Array.isArray((a.config as any).skills) && (a.config as any).skills.includes(skillId)) return true;
+      const profile = this.permissions.getProfile(a.id);
+      if (!profile?.skills) return false;
+      return profile.skills.mode === 'allowlist' ? profile.skills.list.includes(skillId) : !profile.skills.list.includes(skillId);
+    });
+    return installed
+      .filter(i => !canUse(i.skillId))
+      .map(i => ({ skillId: i.skillId, name: i.skill?.name || i.skillId, version: i.version, enabled: i.enabled, installedBy: i.installedBy, installedAt: i.installedAt }));
+  }
+
+  private async findUnreferencedSecrets(orgId: string, staleDays: number): Promise<HousekeepingReport['unreferencedSecrets']> {
+    if (!this.db) return [];
+    const entries = await this.vault.getSecretsByOrg(orgId).catch(() => []);
+    if (!entries.length) return [];
+    const read = await this.db.query<{ vault_entry_id: string }>(
+      "SELECT DISTINCT vault_entry_id FROM vault_audit_log WHERE org_id = ? AND action = 'decrypt' AND vault_entry_id IS NOT NULL",
+      [orgId],
+    ).catch(() => []);
+    const readIds = new Set(read.map(r => r.vault_entry_id));
+    const cutoff = new Date(Date.now() - staleDays * 86_400_000).toISOString();
+    return entries
+      .filter(e => !readIds.has(e.id) && iso(e.createdAt) < cutoff)
+      .map(e => ({ id: e.id, name: e.name, category: e.category, createdBy: e.createdBy, createdAt: iso(e.createdAt) }));
+  }
+}
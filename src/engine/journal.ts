@@ -200,15 +200,25 @@ export class ActionJournal {
     return this.entries.find(e => e.id === id);
   }
 
-  getStats(orgId: string): { total: number; reversible: number; reversed: number; byType: Record<string, number> } {
+  getStats(orgId: string): { total: number; reversible: number; reversed: number; byType: Record<string, number>; daily: Array<{ day: string; actions: number; reversed: number }> } {
     const list = this.entries.filter(e => e.orgId === orgId);
     const byType: Record<string, number> = {};
     for (const e of list) { byType[e.actionType] = (byType[e.actionType] || 0) + 1; }
+    // Last 14 days, oldest first, with empty days filled in so charts keep their spacing
+    const daily = Array.from({ length: 14 }, (_, i) => ({ day: new Date(Date.now() - (13 - i) * 86_400_000).toISOString().slice(0, 10), actions: 0, reversed: 0 }));
+    const byDay = new Map(daily.map(d => [d.day, d]));
+    for (const e of list) {
+      const d = byDay.get(String(e.createdAt).slice(0, 10));
+      if (!d) continue;
+      d.actions++;
+      if (e.reversed) d.reversed++;
+    }
     return {
       total: list.length,
       reversible: list.filter(e => e.reversible).length,
       reversed: list.filter(e => e.reversed).length,
       byType,
+      daily,
     };
   }
 
@@ -255,6 +255,13 @@ export class KnowledgeBaseEngine {
     });
   }
 
+  /** IDs of the knowledge bases an agent can read, without the async hop (policy checks) */
+  listIdsForAgent(agentId: string): string[] {
+    return Array.from(this.knowledgeBases.values())
+      .filter(kb => Array.isArray(kb.agentIds) && kb.agentIds.includes(agentId))
+      .map(kb => kb.id);
+  }
+
   async search(agentId: string, query: string, opts?: {
     kbIds?: string[];
     maxResults?: number;
@@ -206,6 +206,8 @@ export class AgentLifecycleManager {
   private vault?: any;
   /** Real-time status tracker for SSE push to dashboard */
   private statusTracker?: any;
+  /** Data residency routing: pins new agents and checks deploy regions (set via setRegionRouter) */
+  private regionRouter: ((agent: ManagedAgent, phase: 'create' | 'deploy') => Promise<void>) | null = null;
 
   constructor(opts?: { db?: EngineDatabase; permissions?: PermissionEngine }) {
     this.engineDb = opts?.db;
@@ -229,6 +231,10 @@ export class AgentLifecycleManager {
     this.statusTracker = tracker;
   }
 
+  setRegionRouter(router: (agent: ManagedAgent, phase: 'create' | 'deploy') => Promise<void>): void {
+    this.regionRouter = router;
+  }
+
   /**
    * Load all agents from DB into memory
    */
@@ -356,6 +362,8 @@ export class AgentLifecycleManager {
       version: 1,
     };
 
+    if (this.regionRouter) await this.regionRouter(agent, 'create');
+
     this.agents.set(agent.id, agent);
     await this.persistAgent(agent);
     this.emitEvent(agent, 'created', { createdBy });
@@ -428,6 +436,7 @@ export class AgentLifecycleManager {
     try {
       // Resolve org-level deploy credentials if agent doesn't have its own
       await this.resolveDeployCredentials(agent);
+      if (this.regionRouter) await this.regionRouter(agent, 'deploy');
 
       // Run deployment
       this.transition(agent, 'deploying', 'Pushing configuration', 'system');
@@ -0,0 +1,59 @@
+/**
+ * Log Tail Routes
+ * Mounted at /logs/* on the engine sub-app.
+ *
+ * Admin only: engine logs can include addresses, subjects and tool input.
+ */
+
+import { Hono } from 'hono';
+import { LOG_LEVELS, type LogLevel, type LogTail } from './log-tail.js';
+import type { AgentLifecycleManager } from './lifecycle.js';
+import { isAdminCaller } from './caller-role.js';
+
+export function createLogTailRoutes(tail: LogTail, lifecycle: AgentLifecycleManager) {
+  const router = new Hono();
+
+  /** The id plus any names the engine prints for this agent */
+  const termsFor = (agentId: string) => {
+    const agent = lifecycle.getAgent(agentId);
+    const names = [agent?.config?.name, agent?.config?.displayName, agent?.name].filter((n): n is string => !!n && n.length >= 3);
+    return [agentId, ...new Set(names)];
+  };
+
+  // ?level=debug|info|warn|error&backlog= — SSE: the recent matching lines, then live ones
+  router.get('/agent/:agentId/stream', (c) => {
+    if (!isAdminCaller(c)) return c.json({ error: 'Only admins can view engine logs' }, 403);
+    const level = (LOG_LEVELS.includes(c.req.query('level') as LogLevel) ? c.req.query('level') : 'debug') as LogLevel;
+    const backlog = Math.min(Math.max(parseInt(c.req.query('backlog') || '200') || 200, 0), 1000);
+    const terms = termsFor(c.req.param('agentId'));
+
+    const stream = new ReadableStream({
+      start(controller) {
+        const encoder = new TextEncoder();
+        const send = (data: string) => {
+          try { controller.enqueue(encoder.encode(`data: ${data}\n\n`)); }
+          catch { unsubscribe(); clearInterval(heartbeat); }
+        };
+
+        send(JSON.stringify({ type: 'backlog', lines: tail.recent({ terms, level, limit: backlog }) }));
+        const unsubscribe = tail.subscribe({ terms, level }, (line) => send(JSON.stringify({ type: 'line', line })));
+        const heartbeat = setInterval(() => send(JSON.stringify({ type: 'heartbeat' })), 30_000);
+
+        c.req.raw.signal.addEventListener('abort', () => {
+          unsubscribe();
+          clearInterval(heartbeat);
+        });
+      },
+    });
+
+    return new Response(stream, {
+      headers: {
+        'Content-Type': 'text/event-stream',
+        'Cache-Control': 'no-cache',
+        'Connection': 'keep-alive',
+      },
+    });
+  });
+
+  return router;
+}
@@ -0,0 +1,93 @@
+/**
+ * Log Tail — recent engine log lines, kept in memory for the dashboard
+ *
+ * Wraps console.debug/log/info/warn/error once at startup and keeps the last
+ * MAX_LINES lines in a ring buffer, so an admin can follow what the engine
+ * is doing for one agent without a shell on the server. Lines are matched to
+ * an agent by its id or name appearing in the text, which is how the engine
+ * modules already tag their output ("[lifecycle] ... agent=<id>").
+ *
+ * Only this process's output is captured; an agent deployed to its own
+ * machine or container logs there.
+ */
+
+import { format } from 'util';
+
+// ─── Types ──────────────────────────────────────────────
+
+export type LogLevel = 'debug' | 'info' | 'warn' | 'error';
+
+export interface LogLine {
+  seq: number;
+  level: LogLevel;
+  message: string;
+  timestamp: string;
+}
+
+// ─── Config ─────────────────────────────────────────────
+
+export const LOG_LEVELS: LogLevel[] = ['debug', 'info', 'warn', 'error'];
+
+const MAX_LINES = 5000;
+const MAX_LINE_LENGTH = 4000;
+const CONSOLE_LEVELS: Array<[keyof Console, LogLevel]> = [['debug', 'debug'], ['log', 'info'], ['info', 'info'], ['warn', 'warn'], ['error', 'error']];
+
+// ─── Tail ───────────────────────────────────────────────
+
+export class LogTail {
+  private lines: LogLine[] = [];
+  private seq = 0;
+  private listeners = new Set<(line: LogLine) => void>();
+  private installed = false;
+
+  /** Start capturing console output. Safe to call more than once. */
+  install(): void {
+    if (this.installed) return;
+    this.installed = true;
+    for (const [method, level] of CONSOLE_LEVELS) {
+      const original = (console as any)[method].bind(console);
+      (console as any)[method] = (...args: any[]) => {
+        try { this.push(level, args); } catch { /* never break logging */ }
+        original(...args);
+      };
+    }
+  }
+
+  /** Lines at or above `level` that mention one of `terms`, oldest first. */
+  recent(opts: { terms?: string[]; level?: LogLevel; limit?: number } = {}): LogLine[] {
+    const matches = this.matcher(opts.terms, opts.level);
+    const out: LogLine[] = [];
+    for (let i = this.lines.length - 1; i >= 0 && out.length < (opts.limit || 200); i--) {
+      if (matches(this.lines[i])) out.push(this.lines[i]);
+    }
+    return out.reverse();
+  }
+
+  /** Live lines matching the same filter as recent(). Returns an unsubscribe function. */
+  subscribe(opts: { terms?: string[]; level?: LogLevel }, callback: (line: LogLine) => void): () => void {
+    const matches = this.matcher(opts.terms, opts.level);
+    const listener = (line: LogLine) => { if (matches(line)) callback(line); };
+    this.listeners.add(listener);
+    return () => { this.listeners.delete(listener); };
+  }
+
+  // ─── Private ────────────────────────────────────────
+
+  private push(level: LogLevel, args: any[]): void {
+    let message = format(...args);
+    if (message.length > MAX_LINE_LENGTH) message = message.slice(0, MAX_LINE_LENGTH) + '…';
+    const line: LogLine = { seq: ++this.seq, level, message, timestamp: new Date().toISOString() };
+    this.lines.push(line);
+    if (this.lines.length > MAX_LINES) this.lines.splice(0, this.lines.length - MAX_LINES);
+    for (const listener of this.listeners) {
+      try { listener(line); } catch { this.listeners.delete(listener); }
+    }
+  }
+
+  private matcher(terms?: string[], level?: LogLevel): (line: LogLine) => boolean {
+    const min = LOG_LEVELS.indexOf(level || 'debug');
+    const needles = (terms || []).filter(Boolean).map(t => t.toLowerCase());
+    return (line) => LOG_LEVELS.indexOf(line.level) >= min
+      && (needles.length === 0 || needles.some(n => line.message.toLowerCase().includes(n)));
+  }
+}
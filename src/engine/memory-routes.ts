@@ -6,10 +6,14 @@
 import { Hono } from 'hono';
 import type { AgentMemoryManager } from './agent-memory.js';
 import { MEMORY_CATEGORIES } from './agent-memory.js';
+import type { DatabaseAdapter } from '../db/adapter.js';
+import { auditFromEngine } from './route-audit.js';
 
-export function createMemoryRoutes(memoryManager: AgentMemoryManager) {
+export function createMemoryRoutes(memoryManager: AgentMemoryManager, deps: { getAdminDb?: () => DatabaseAdapter | null } = {}) {
   const router = new Hono();
 
+  const audit = auditFromEngine(deps.getAdminDb);
+
   // ─── Query ─────────────────────────────────────────────────
 
   router.get('/categories', (c) => {
@@ -23,7 +27,7 @@ export function createMemoryRoutes(memoryManager: AgentMemoryManager) {
         category: c.req.query('category') || undefined,
         importance: c.req.query('importance') || undefined,
         source: c.req.query('source') || undefined,
-        query: c.req.query('q') || undefined,
+        query: c.req.query('q') || c.req.query('search') || undefined,
         limit: parseInt(c.req.query('limit') || '100'),
       });
       return c.json({ memories, total: memories.length });
@@ -96,8 +100,15 @@ export function createMemoryRoutes(memoryManager: AgentMemoryManager) {
     }
   });
 
+  // Audited with what was removed, so a deleted (often wrongly learned) fact can be traced
   router.delete('/:id', async (c) => {
-    await memoryManager.deleteMemory(c.req.param('id'));
+    const id = c.req.param('id');
+    const memory = await memoryManager.getMemory(id);
+    await memoryManager.deleteMemory(id);
+    audit(c, 'memory.delete', `memory:${id}`, memory ? {
+      agentId: memory.agentId, title: memory.title, category: memory.category, source: memory.source,
+      content: memory.content.slice(0, 500),
+    } : {}, memory?.orgId);
     return c.json({ success: true });
   });
 
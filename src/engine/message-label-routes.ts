@@ -0,0 +1,94 @@
+/**
+ * Message Label Routes
+ * Mounted at /message-labels/* on the engine sub-app.
+ */
+
+import { Hono } from 'hono';
+import { parseLabelRules, type MessageLabelStore } from './message-labels.js';
+import type { AgentCommunicationBus } from './communication.js';
+
+const COLOR_RE = /^#[0-9a-fA-F]{6}$/;
+
+function parseName(value: unknown): string | null {
+  const name = typeof value === 'string' ? value.trim() : '';
+  return name && name.length <= 64 ? name : null;
+}
+
+export function createMessageLabelRoutes(labels: MessageLabelStore, commBus: AgentCommunicationBus) {
+  const router = new Hono();
+
+  router.get('/', async (c) => {
+    const orgId = c.req.query('orgId');
+    if (!orgId) return c.json({ error: 'orgId required' }, 400);
+    return c.json({ labels: await labels.listLabels(orgId) });
+  });
+
+  router.post('/', async (c) => {
+    const body = await c.req.json().catch(() => ({}));
+    if (!body.orgId) return c.json({ error: 'orgId required' }, 400);
+    const name = parseName(body.name);
+    if (!name) return c.json({ error: 'name is required (max 64 characters)' }, 400);
+    if (body.color && !COLOR_RE.test(body.color)) return c.json({ error: 'color must be a hex value like #6366f1' }, 400);
+    try {
+      const label = await labels.createLabel({ orgId: body.orgId, name, color: body.color, rules: parseLabelRules(body.rules), createdBy: c.req.header('X-User-Id') });
+      const applied = body.applyToExisting ? await labels.backfill(label, commBus.getMessages({ orgId: body.orgId, limit: 2000 }).messages) : 0;
+      return c.json({ label, applied }, 201);
+    } catch (e: any) {
+      return c.json({ error: e.message }, 400);
+    }
+  });
+
+  router.patch('/:id', async (c) => {
+    const body = await c.req.json().catch(() => ({}));
+    if (!body.orgId) return c.json({ error: 'orgId required' }, 400);
+    const patch: { name?: string; color?: string; rules?: any[] } = {};
+    if (body.name !== undefined) {
+      const name = parseName(body.name);
+      if (!name) return c.json({ error: 'name is required (max 64 characters)' }, 400);
+      patch.name = name;
+    }
+    if (body.color !== undefined) {
+      if (!COLOR_RE.test(body.color)) return c.json({ error: 'color must be a hex value like #6366f1' }, 400);
+      patch.color = body.color;
+    }
+    try {
+      if (body.rules !== undefined) patch.rules = parseLabelRules(body.rules);
+      const label = await labels.updateLabel(body.orgId, c.req.param('id'), patch);
+      if (!label) return c.json({ error: 'Label not found' }, 404);
+      const applied = body.applyToExisting ? await labels.backfill(label, commBus.getMessages({ orgId: body.orgId, limit: 2000 }).messages) : 0;
+      return c.json({ label, applied });
+    } catch (e: any) {
+      return c.json({ error: e.message }, 400);
+    }
+  });
+
+  router.delete('/:id', async (c) => {
+    const orgId = c.req.query('orgId');
+    if (!orgId) return c.json({ error: 'orgId required' }, 400);
+    await labels.deleteLabel(orgId, c.req.param('id'));
+    return c.json({ ok: true });
+  });
+
+  // ─── Assignments ─────────────────────────────────────
+
+  router.get('/assignments', async (c) => {
+    const orgId = c.req.query('orgId');
+    if (!orgId) return c.json({ error: 'orgId required' }, 400);
+    return c.json({ assignments: await labels.getAssignments(orgId) });
+  });
+
+  router.put('/assignments/:messageId', async (c) => {
+    const body = await c.req.json().catch(() => ({}));
+    if (!body.orgId || !Array.isArray(body.labelIds)) return c.json({ error: 'orgId and labelIds required' }, 400);
+    const msg = commBus.getMessage(c.req.param('messageId'));
+    if (!msg || msg.orgId !== body.orgId) return c.json({ error: 'Message not found' }, 404);
+    try {
+      const labelIds = await labels.setMessageLabels(body.orgId, msg.id, body.labelIds.filter((id: any) => typeof id === 'string'));
+      return c.json({ messageId: msg.id, labelIds });
+    } catch (e: any) {
+      return c.json({ error: e.message }, 400);
+    }
+  });
+
+  return router;
+}
@@ -0,0 +1,221 @@
+/**
+ * Message Labels
+ *
+ * Org-wide, user-defined labels for agent messages. A label is applied
+ * manually from the dashboard or automatically by its routing rules when a
+ * new message is recorded on the communication bus. Assignments remember
+ * where they came from (manual vs rule) so re-running rules never removes
+ * a label someone added by hand.
+ */
+
+import type { EngineDatabase } from './db-adapter.js';
+import type { AgentMessage } from './communication.js';
+
+function sj(v: any, fb: any = []): any { if (!v) return fb; if (typeof v !== 'string') return v; try { return JSON.parse(v); } catch { return fb; } }
+
+// ─── Types ──────────────────────────────────────────────
+
+export type LabelRuleField = 'subject' | 'content' | 'fromAgentId' | 'toAgentId' | 'type' | 'direction' | 'channel' | 'priority';
+export type LabelRuleOp = 'contains' | 'equals';
+export type LabelSource = 'manual' | 'rule';
+
+/** A rule matches when every condition matches (case-insensitive). */
+export interface LabelRule {
+  field: LabelRuleField;
+  op: LabelRuleOp;
+  value: string;
+}
+
+export interface MessageLabel {
+  id: string;
+  orgId: string;
+  name: string;
+  color: string;
+  rules: LabelRule[];
+  createdBy?: string;
+  createdAt: string;
+  updatedAt: string;
+}
+
+// ─── Config ─────────────────────────────────────────────
+
+const MAX_LABELS_PER_ORG = 100;
+const MAX_RULES_PER_LABEL = 10;
+const MAX_LABELS_PER_MESSAGE = 20;
+
+export const LABEL_RULE_FIELDS: LabelRuleField[] = ['subject', 'content', 'fromAgentId', 'toAgentId', 'type', 'direction', 'channel', 'priority'];
+export const LABEL_RULE_OPS: LabelRuleOp[] = ['contains', 'equals'];
+
+/** Validate and normalise label rules; throws with a user-facing message. */
+export function parseLabelRules(input: unknown): LabelRule[] {
+  if (input === undefined || input === null) return [];
+  if (!Array.isArray(input)) throw new Error('rules must be an array');
+  if (input.length > MAX_RULES_PER_LABEL) throw new Error(`A label can have at most ${MAX_RULES_PER_LABEL} rule conditions`);
+  return input.map((r: any) => {
+    if (!LABEL_RULE_FIELDS.includes(r?.field)) throw new Error(`Unknown rule field: ${r?.field}`);
+    if (!LABEL_RULE_OPS.includes(r?.op)) throw new Error(`Unknown rule operator: ${r?.op}`);
+    const value = String(r.value ?? '').trim();
+    if (!value) throw new Error('Rule value is required');
+    return { field: r.field, op: r.op, value: value.slice(0, 200) };
+  });
+}
+
+export function ruleMatches(rules: LabelRule[], msg: AgentMessage): boolean {
+  if (!rules.length) return false;
+  return rules.every(r => {
+    const actual = String((msg as any)[r.field] ?? '').toLowerCase();
+    const expected = r.value.toLowerCase();
+    return r.op === 'equals' ? actual === expected : actual.includes(expected);
+  });
+}
+
+// ─── Label Store ────────────────────────────────────────
+
+export class MessageLabelStore {
+  private engineDb?: EngineDatabase;
+
+  async setDb(db: EngineDatabase): Promise<void> {
+    this.engineDb = db;
+  }
+
+  async listLabels(orgId: string): Promise<MessageLabel[]> {
+    if (!this.engineDb) return [];
+    const rows = await this.engineDb.query<any>('SELECT * FROM message_labels WHERE org_id = ? ORDER BY name ASC', [orgId]);
+    return rows.map((r: any) => this.rowToLabel(r));
+  }
+
+  async getLabel(orgId: string, id: string): Promise<MessageLabel | null> {
+    if (!this.engineDb) return null;
+    const row = await this.engineDb.get<any>('SELECT * FROM message_labels WHERE id = ? AND org_id = ?', [id, orgId]);
+    return row ? this.rowToLabel(row) : null;
+  }
+
+  async createLabel(input: { orgId: string; name: string; color?: string; rules?: LabelRule[]; createdBy?: string }): Promise<MessageLabel> {
+    if (!this.engineDb) throw new Error('Label storage not initialized');
+    const existing = await this.listLabels(input.orgId);
+    if (existing.length >= MAX_LABELS_PER_ORG) throw new Error(`Too many labels (max ${MAX_LABELS_PER_ORG})`);
+    if (existing.some(l => l.name.toLowerCase() === input.name.toLowerCase())) throw new Error(`Label "${input.name}" already exists`);
+
+    const now = new Date().toISOString();
+    const label: MessageLabel = {
+      id: crypto.randomUUID(),
+      orgId: input.orgId,
+      name: input.name,
+      color: input.color || '#6366f1',
+      rules: input.rules || [],
+      createdBy: input.createdBy,
+      createdAt: now,
+      updatedAt: now,
+    };
+    await this.engineDb.execute(
+      `INSERT INTO message_labels (id, org_id, name, color, rules, created_by, created_at, updated_at)
+       VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+      [label.id, label.orgId, label.name, label.color, JSON.stringify(label.rules), label.createdBy || null, label.createdAt, label.updatedAt],
+    );
+    return label;
+  }
+
+  async updateLabel(orgId: string, id: string, patch: { name?: string; color?: string; rules?: LabelRule[] }): Promise<MessageLabel | null> {
+    const existing = await this.getLabel(orgId, id);
+    if (!existing) return null;
+    if (patch.name && patch.name.toLowerCase() !== existing.name.toLowerCase()) {
+      const all = await this.listLabels(orgId);
+      if (all.some(l => l.id !== id && l.name.toLowerCase() === patch.name!.toLowerCase())) throw new Error(`Label "${patch.name}" already exists`);
+    }
+    const label: MessageLabel = { ...existing, ...patch, updatedAt: new Date().toISOString() };
+    await this.engineDb!.execute(
+      'UPDATE message_labels SET name = ?, color = ?, rules = ?, updated_at = ? WHERE id = ? AND org_id = ?',
+      [label.name, label.color, JSON.stringify(label.rules), label.updatedAt, id, orgId],
+    );
+    return label;
+  }
+
+  async deleteLabel(orgId: string, id: string): Promise<void> {
+    if (!this.engineDb) return;
+    await this.engineDb.execute('DELETE FROM message_label_assignments WHERE label_id = ? AND org_id = ?', [id, orgId]);
+    await this.engineDb.execute('DELETE FROM message_labels WHERE id = ? AND org_id = ?', [id, orgId]);
+  }
+
+  // ─── Assignments ─────────────────────────────────────
+
+  /** messageId → labelIds for every labelled message in the org. */
+  async getAssignments(orgId: string): Promise<Record<string, string[]>> {
+    if (!this.engineDb) return {};
+    const rows = await this.engineDb.query<any>('SELECT message_id, label_id FROM message_label_assignments WHERE org_id = ?', [orgId]);
+    const map: Record<string, string[]> = {};
+    for (const r of rows) (map[r.message_id] ||= []).push(r.label_id);
+    return map;
+  }
+
+  /** Replace the labels on a message with the given set; new ones are recorded as manual. */
+  async setMessageLabels(orgId: string, messageId: string, labelIds: string[]): Promise<string[]> {
+    if (!this.engineDb) throw new Error('Label storage not initialized');
+    const valid = new Set((await this.listLabels(orgId)).map(l => l.id));
+    const wanted = [...new Set(labelIds)].filter(id => valid.has(id));
+    if (wanted.length > MAX_LABELS_PER_MESSAGE) throw new Error(`A message can have at most ${MAX_LABELS_PER_MESSAGE} labels`);
+
+    const current = await this.engineDb.query<any>('SELECT label_id FROM message_label_assignments WHERE message_id = ? AND org_id = ?', [messageId, orgId]);
+    const have = new Set(current.map((r: any) => r.label_id as string));
+    for (const id of have) {
+      if (!wanted.includes(id)) await this.engineDb.execute('DELETE FROM message_label_assignments WHERE message_id = ? AND label_id = ?', [messageId, id]);
+    }
+    for (const id of wanted) {
+      if (!have.has(id)) await this.assign(orgId, messageId, id, 'manual');
+    }
+    return wanted;
+  }
+
+  /** Apply every matching label rule to a newly recorded message. */
+  async applyRules(msg: AgentMessage): Promise<string[]> {
+    if (!this.engineDb) return [];
+    try {
+      const labels = await this.listLabels(msg.orgId);
+      const applied: string[] = [];
+      for (const label of labels) {
+        if (ruleMatches(label.rules, msg)) {
+          await this.assign(msg.orgId, msg.id, label.id, 'rule');
+          applied.push(label.id);
+        }
+      }
+      return applied;
+    } catch { return []; /* tables may not exist yet */ }
+  }
+
+  /** Re-run one label's rules against existing messages (after editing the rules). */
+  async backfill(label: MessageLabel, messages: AgentMessage[]): Promise<number> {
+    if (!this.engineDb || !label.rules.length) return 0;
+    const already = new Set((await this.engineDb.query<any>(
+      'SELECT message_id FROM message_label_assignments WHERE label_id = ?', [label.id],
+    )).map((r: any) => r.message_id as string));
+    let count = 0;
+    for (const msg of messages) {
+      if (msg.orgId !== label.orgId || already.has(msg.id) || !ruleMatches(label.rules, msg)) continue;
+      await this.assign(label.orgId, msg.id, label.id, 'rule');
+      count++;
+    }
+    return count;
+  }
+
+  private async assign(orgId: string, messageId: string, labelId: string, source: LabelSource): Promise<void> {
+    await this.engineDb!.execute(
+      'DELETE FROM message_label_assignments WHERE message_id = ? AND label_id = ?', [messageId, labelId],
+    );
+    await this.engineDb!.execute(
+      'INSERT INTO message_label_assignments (message_id, label_id, org_id, source, created_at) VALUES (?, ?, ?, ?, ?)',
+      [messageId, labelId, orgId, source, new Date().toISOString()],
+    );
+  }
+
+  private rowToLabel(r: any): MessageLabel {
+    return {
+      id: r.id,
+      orgId: r.org_id,
+      name: r.name,
+      color: r.color,
+      rules: sj(r.rules, []),
+      createdBy: r.created_by || undefined,
+      createdAt: String(r.created_at),
+      updatedAt: String(r.updated_at),
+    };
+  }
+}
@@ -0,0 +1,58 @@
+/**
+ * Message Search Routes
+ * Mounted at /message-search/* on the engine sub-app.
+ *
+ * Searches and exports are written to the admin audit log with the query,
+ * since reading message bodies is itself something investigators account for.
+ */
+
+import { Hono } from 'hono';
+import type { MessageSearch, MessageSearchQuery } from './message-search.js';
+import type { DatabaseAdapter } from '../db/adapter.js';
+import { wantsCsv, csvResponse, pagesOf } from '../lib/csv.js';
+import { auditFromEngine } from './route-audit.js';
+
+export function createMessageSearchRoutes(search: MessageSearch, deps: {
+  getAdminDb: () => DatabaseAdapter | null;
+  /** Agents the caller may see, or null when they aren't limited to their teams */
+  visibleAgentIds?: (c: any) => Promise<Set<string> | null>;
+}) {
+  const router = new Hono();
+
+  const log = auditFromEngine(deps.getAdminDb, 'messages');
+  const audit = (c: any, action: string, query: MessageSearchQuery, details?: Record<string, any>) =>
+    log(c, action, `org:${query.orgId}`, { q: query.q, sender: query.sender, from: query.from, to: query.to, source: query.source, ...details }, query.orgId);
+
+  // ?orgId&q&sender&from&to&source&limit&offset — ?format=csv exports every match
+  router.get('/', async (c) => {
+    const orgId = c.req.query('orgId');
+    if (!orgId) return c.json({ error: 'orgId required' }, 400);
+    const query: MessageSearchQuery = {
+      orgId,
+      q: c.req.query('q') || '',
+      sender: c.req.query('sender') || undefined,
+      from: c.req.query('from') || undefined,
+      to: c.req.query('to') || undefined,
+      source: c.req.query('source') || undefined,
+      limit: parseInt(c.req.query('limit') || '50'),
+      offset: parseInt(c.req.query('offset') || '0'),
+    };
+    const visible = deps.visibleAgentIds ? await deps.visibleAgentIds(c) : null;
+    if (visible) query.agentIds = [...visible];
+
+    if (wantsCsv(c)) {
+      const matches = await search.exportMatches(query).catch(() => []);
+      audit(c, 'search_export', query, { rows: matches.length });
+      return csvResponse('message-search', [
+        { header: 'id' }, { header: 'createdAt' }, { header: 'source' }, { header: 'agentId' },
+        { header: 'sender' }, { header: 'recipient' }, { header: 'subject' }, { header: 'text' },
+      ], pagesOf(matches));
+    }
+
+    const result = await search.search(query);
+    if (result.terms.length > 0) audit(c, 'search', query, { total: result.total });
+    return c.json(result);
+  });
+
+  return router;
+}
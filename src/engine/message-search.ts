@@ -0,0 +1,239 @@
+/**
+ * Message Search — Full-text search over message bodies for investigations
+ *
+ * Searches internal agent messages (agent_messages) and external chat
+ * history (messaging_history — WhatsApp, Telegram) in the database rather
+ * than the in-memory buffers, so it reaches every stored message. Each
+ * word or "quoted phrase" must appear somewhere in the subject or body,
+ * case-insensitively. Plain LIKE keeps it portable across SQLite, Postgres
+ * and MySQL; the document stores (MongoDB, DynamoDB) can't run it and
+ * report search as unsupported.
+ *
+ * Matches are scanned newest first up to SCAN_LIMIT, which is what the
+ * sender and month facets are counted over. Snippets carry highlight
+ * offsets rather than markup so the client decides how to render them.
+ */
+
+import type { EngineDatabase } from './db-adapter.js';
+
+// ─── Types ──────────────────────────────────────────────
+
+export interface MessageSearchQuery {
+  orgId: string;
+  q: string;
+  /** Exact sender (agent ID, contact name or contact ID) */
+  sender?: string;
+  from?: string;
+  to?: string;
+  /** 'internal' or an external platform; omit for all */
+  source?: string;
+  /** Only messages to or from these agents (a team-scoped caller); omit for all */
+  agentIds?: string[];
+  limit?: number;
+  offset?: number;
+}
+
+export interface MessageSearchHit {
+  id: string;
+  source: string;
+  agentId: string;
+  sender: string;
+  recipient: string;
+  subject?: string;
+  text: string;
+  createdAt: string;
+  snippet: string;
+  /** [start, end) offsets into snippet */
+  highlights: Array<[number, number]>;
+}
+
+export interface MessageSearchResult {
+  supported: boolean;
+  terms: string[];
+  hits: MessageSearchHit[];
+  total: number;
+  /** More matches exist than were scanned; narrow the query or dates */
+  truncated: boolean;
+  facets: {
+    senders: Array<{ value: string; count: number }>;
+    months: Array<{ value: string; count: number }>;
+    sources: Array<{ value: string; count: number }>;
+  };
+}
+
+// ─── Config ─────────────────────────────────────────────
+
+const SCAN_LIMIT = 5000;
+const MAX_TERMS = 8;
+const SNIPPET_CHARS = 180;
+
+/** Words and "quoted phrases", lowercased; single characters are dropped. */
+export function parseSearchTerms(q: string): string[] {
+  const terms: string[] = [];
+  const re = /"([^"]+)"|(\S+)/g;
+  let m: RegExpExecArray | null;
+  while ((m = re.exec(q || '')) && terms.length < MAX_TERMS) {
+    const t = (m[1] || m[2]).trim().toLowerCase();
+    if (t.length >= 2 && !terms.includes(t)) terms.push(t);
+  }
+  return terms;
+}
+
+/** `!` escapes LIKE wildcards; it needs no escaping itself in any dialect's string literals. */
+function likePattern(term: string): string {
+  return '%' + term.replace(/[!%_]/g, c => '!' + c) + '%';
+}
+
+/** A window of `text` around the first match, with every term occurrence inside it marked. */
+export function buildSnippet(text: string, terms: string[]): { snippet: string; highlights: Array<[number, number]> } {
+  const lower = text.toLowerCase();
+  const first = Math.min(...terms.map(t => { const i = lower.indexOf(t); return i < 0 ? Infinity : i; }));
+  let start = first === Infinity ? 0 : Math.max(0, first - Math.floor(SNIPPET_CHARS / 3));
+  // Start on a word boundary when we can
+  if (start > 0) { const sp = text.indexOf(' ', start); if (sp > 0 && sp - start < 20) start = sp + 1; }
+  const end = Math.min(text.length, start + SNIPPET_CHARS);
+  const prefix = start > 0 ? '…' : '';
+  const snippet = prefix + text.slice(start, end).replace(/\s+/g, ' ') + (end < text.length ? '…' : '');
+
+  const hay = snippet.toLowerCase();
+  const ranges: Array<[number, number]> = [];
+  for (const t of terms) {
+    let i = hay.indexOf(t);
+    while (i >= 0) { ranges.push([i, i + t.length]); i = hay.indexOf(t, i + t.length); }
+  }
+  ranges.sort((a, b) => a[0] - b[0]);
+  // Merge overlaps so the client never nests marks
+  const highlights: Array<[number, number]> = [];
+  for (const r of ranges) {
+    const last = highlights[highlights.length - 1];
+    if (last && r[0] <= last[1]) last[1] = Math.max(last[1], r[1]);
+    else highlights.push([r[0], r[1]]);
+  }
+  return { snippet, highlights };
+}
+
+function iso(v: any): string {
+  if (v instanceof Date) return v.toISOString();
+  const s = String(v || '');
+  // SQLite datetime('now') has no zone and is UTC
+  return /^\d{4}-\d\d-\d\d \d\d:\d\d:\d\d$/.test(s) ? s.replace(' ', 'T') + 'Z' : s;
+}
+
+function countBy(values: string[]): Array<{ value: string; count: number }> {
+  const m = new Map<string, number>();
+  for (const v of values) if (v) m.set(v, (m.get(v) || 0) + 1);
+  return [...m.entries()].map(([value, count]) => ({ value, count })).sort((a, b) => b.count - a.count);
+}
+
+// ─── Message Search ─────────────────────────────────────
+
+export class MessageSearch {
+  private engineDb?: EngineDatabase;
+
+  async setDb(db: EngineDatabase): Promise<void> {
+    this.engineDb = db;
+  }
+
+  async search(query: MessageSearchQuery): Promise<MessageSearchResult> {
+    const terms = parseSearchTerms(query.q);
+    const empty: MessageSearchResult = { supported: true, terms, hits: [], total: 0, truncated: false, facets: { senders: [], months: [], sources: [] } };
+    if (!this.engineDb) return { ...empty, supported: false };
+    if (terms.length === 0) return empty;
+
+    let matches: MessageSearchHit[];
+    try {
+      matches = await this.scan(query, terms);
+    } catch {
+      // Document-store backends don't speak SQL
+      return { ...empty, supported: false };
+    }
+
+    const truncated = matches.length >= SCAN_LIMIT;
+    const senders = countBy(matches.map(m => m.sender));
+    const filtered = query.sender ? matches.filter(m => m.sender === query.sender) : matches;
+    const offset = query.offset || 0;
+    const page = filtered.slice(offset, offset + Math.min(query.limit || 50, 500));
+
+    return {
+      supported: true,
+      terms,
+      hits: page.map(h => ({ ...h, ...buildSnippet((h.subject ? h.subject + ' — ' : '') + h.text, terms) })),
+      total: filtered.length,
+      truncated,
+      facets: {
+        senders: senders.slice(0, 20),
+        months: countBy(filtered.map(m => m.createdAt.slice(0, 7))).sort((a, b) => b.value.localeCompare(a.value)),
+        sources: countBy(filtered.map(m => m.source)),
+      },
+    };
+  }
+
+  /** Every match for an export — same filters as search, no paging or snippets. */
+  async exportMatches(query: MessageSearchQuery): Promise<MessageSearchHit[]> {
+    const terms = parseSearchTerms(query.q);
+    if (!this.engineDb || terms.length === 0) return [];
+    const matches = await this.scan(query, terms);
+    return query.sender ? matches.filter(m => m.sender === query.sender) : matches;
+  }
+
+  /** Every match across both tables, newest first, up to SCAN_LIMIT. */
+  private async scan(query: MessageSearchQuery, terms: string[]): Promise<MessageSearchHit[]> {
+    const db = this.engineDb!;
+    const dateClauses = (col: string, params: any[]) => {
+      let sql = '';
+      if (query.from) { sql += ` AND ${col} >= ?`; params.push(query.from); }
+      if (query.to) { sql += ` AND ${col} <= ?`; params.push(query.to.length === 10 ? query.to + 'T23:59:59.999Z' : query.to); }
+      return sql;
+    };
+    const termClauses = (cols: string[], params: any[]) => terms.map(t => {
+      cols.forEach(() => params.push(likePattern(t)));
+      return ' AND (' + cols.map(c => `LOWER(${c}) LIKE ? ESCAPE '!'`).join(' OR ') + ')';
+    }).join('');
+
+    const agentClause = (cols: string[], params: any[]) => {
+      if (!query.agentIds) return '';
+      const marks = query.agentIds.map(() => '?').join(', ');
+      cols.forEach(() => params.push(...query.agentIds!));
+      return ' AND (' + cols.map(c => `${c} IN (${marks})`).join(' OR ') + ')';
+    };
+
+    const hits: MessageSearchHit[] = [];
+    if (query.agentIds && query.agentIds.length === 0) return hits;
+
+    if (!query.source || query.source === 'internal') {
+      const params: any[] = [query.orgId];
+      const sql = 'SELECT id, from_agent_id, to_agent_id, subject, content, created_at FROM agent_messages WHERE org_id = ?'
+        + agentClause(['from_agent_id', 'to_agent_id'], params)
+        + termClauses(["COALESCE(subject, '')", 'content'], params)
+        + dateClauses('created_at', params)
+        + ' ORDER BY created_at DESC LIMIT ' + SCAN_LIMIT;
+      for (const r of await db.query<any>(sql, params)) {
+        hits.push({
+          id: r.id, source: 'internal', agentId: r.to_agent_id, sender: r.from_agent_id, recipient: r.to_agent_id,
+          subject: r.subject || undefined, text: r.content || '', createdAt: iso(r.created_at), snippet: '', highlights: [],
+        });
+      }
+    }
+
+    if (query.source !== 'internal') {
+      const params: any[] = [query.orgId];
+      let sql = 'SELECT id, agent_id, platform, contact_id, direction, sender_name, message_text, created_at FROM messaging_history'
+        + ' WHERE agent_id IN (SELECT id FROM managed_agents WHERE org_id = ?)';
+      if (query.source) { sql += ' AND platform = ?'; params.push(query.source); }
+      sql += agentClause(['agent_id'], params) + termClauses(['message_text'], params) + dateClauses('created_at', params) + ' ORDER BY created_at DESC LIMIT ' + SCAN_LIMIT;
+      // Older installs without messaging history still search internal messages
+      const rows = await db.query<any>(sql, params).catch(() => []);
+      for (const r of rows) {
+        const contact = r.sender_name || r.contact_id;
+        hits.push({
+          id: r.platform + ':' + r.id, source: r.platform, agentId: r.agent_id,
+          sender: r.direction === 'inbound' ? contact : r.agent_id,
+          recipient: r.direction === 'inbound' ? r.agent_id : contact,
+          text: r.message_text || '', createdAt: iso(r.created_at), snippet: '', highlights: [],
+        });
+      }
+    }
+
+    return hits.sort((a, b) => b.createdAt.localeCompare(a.createdAt)).slice(0, SCAN_LIMIT);
+  }
+}
@@ -28,6 +28,9 @@ interface MessagingPollerConfig {
   publicUrl?: string; // e.g. https://enterprise.example.com — enables webhooks
   app?: Hono; // Engine Hono app to mount webhook routes on
   engineDb?: any; // Postgres pool — for persisting cursors + message logs
+  isTakenOver?: (agentId: string, platform: string, contactId: string) => boolean; // Human takeover — hold the thread
+  getMaintenance?: (agentId: string) => { id: string; notice: string } | null; // Open maintenance window — auto-reply, don't dispatch
+  onMessage?: (agentId: string, message: { channel: string; from: string; subject?: string; text: string; threadId?: string }) => void; // Each trusted inbound message (e.g. to fire webhooks)
 }
 
 interface AgentEndpoint {
@@ -46,6 +49,7 @@ export class MessagingPoller {
   private untrustedReplySent = new Map<string, number>(); // senderId → last reply timestamp
   private rateLimitMap = new Map<string, number[]>(); // senderId → timestamps
   private telegramBots = new Map<string, { agentId: string; stop: () => void }>(); // botToken → { agentId, stop }
+  private maintenanceNotified = new Set<string>(); // windowId:platform:contact — notice already sent
 
   constructor(config: MessagingPollerConfig) {
     this.config = config;
@@ -582,6 +586,39 @@ export class MessagingPoller {
       return;
     }
 
+    this.config.onMessage?.(agent.id, { channel: ctx.source, from: ctx.senderName || ctx.senderId, text: ctx.messageText, threadId: ctx.chatId || ctx.senderId });
+
+    // A human has taken over this thread from the dashboard — keep the message, skip the agent
+    if (this.config.isTakenOver?.(agent.id, ctx.source, ctx.chatId || ctx.senderId)) {
+      console.log(`[messaging] ${ctx.source} thread ${ctx.chatId || ctx.senderId} is under human takeover — not dispatching to ${agent.displayName}`);
+      if (this.config.engineDb) {
+        storeMessage(this.config.engineDb, {
+          agentId: agent.id,
+          platform: ctx.source,
+          contactId: ctx.chatId || ctx.senderId,
+          direction: 'inbound',
+          senderName: ctx.senderName,
+          messageText: ctx.messageText,
+          messageId: ctx.messageId,
+          isGroup: ctx.isGroup,
+          metadata: { heldForHuman: true },
+        }).catch(() => {});
+      }
+      return;
+    }
+
+    // Agent is in a maintenance window — send the notice once per contact, skip the agent
+    var maintenance = this.config.getMaintenance?.(agent.id);
+    if (maintenance) {
+      var noticeKey = maintenance.id + ':' + ctx.source + ':' + (ctx.chatId || ctx.senderId);
+      console.log(`[messaging] ${agent.displayName} is in a maintenance window — not dispatching ${ctx.source} message`);
+      if (!this.maintenanceNotified.has(noticeKey)) {
+        this.maintenanceNotified.add(noticeKey);
+        this.sendDirectReply(agent, ctx, maintenance.notice).catch(() => {});
+      }
+      return;
+    }
+
     console.log(`[messaging] Dispatching ${ctx.source} message to ${agent.displayName}`);
 
     // Send typing indicator so user sees "..." while agent processes
@@ -842,25 +879,36 @@ export class MessagingPoller {
     source: string; senderId: string; chatId?: string;
   }, text: string) {
     try {
-      if (ctx.source === 'whatsapp') {
-        var { getConnection } = await import('../agent-tools/tools/messaging/whatsapp.js');
-        var conn = getConnection(agent.id);
-        if (!conn?.connected) return;
-        var jid = ctx.senderId.includes('@') ? ctx.senderId : ctx.senderId.replace(/[^0-9]/g, '') + '@s.whatsapp.net';
-        await conn.sock.sendMessage(jid, { text });
-      } else if (ctx.source === 'telegram') {
-        var tgConfig = this.config.getAgentChannelConfig(agent.id);
-        var botToken = tgConfig?.telegram?.botToken;
-        if (!botToken) return;
-        var chatId = ctx.chatId || ctx.senderId;
-        await fetch(`https://api.telegram.org/bot${botToken}/sendMessage`, {
-          method: 'POST',
-          headers: { 'Content-Type': 'application/json' },
-          body: JSON.stringify({ chat_id: chatId, text }),
-        });
-      }
+      await this.sendAsAgent(agent.id, ctx.source, ctx.chatId || ctx.senderId, text);
     } catch (err: any) {
       console.error(`[messaging] Direct reply failed for ${ctx.senderId}: ${err.message}`);
     }
   }
+
+  /**
+   * Send a message from the agent's own WhatsApp number / Telegram bot.
+   * Used for system replies and by humans replying during a takeover.
+   * Throws when the channel isn't connected.
+   */
+  async sendAsAgent(agentId: string, platform: string, contactId: string, text: string): Promise<void> {
+    if (platform === 'whatsapp') {
+      var { getConnection } = await import('../agent-tools/tools/messaging/whatsapp.js');
+      var conn = getConnection(agentId);
+      if (!conn?.connected) throw new Error('WhatsApp is not connected for this agent');
+      var jid = contactId.includes('@') ? contactId : contactId.replace(/[^0-9]/g, '') + '@s.whatsapp.net';
+      await conn.sock.sendMessage(jid, { text });
+    } else if (platform === 'telegram') {
+      var tgConfig = this.config.getAgentChannelConfig(agentId);
+      var botToken = tgConfig?.telegram?.botToken;
+      if (!botToken) throw new Error('Telegram bot is not configured for this agent');
+      var resp = await fetch(`https://api.telegram.org/bot${botToken}/sendMessage`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ chat_id: contactId, text }),
+      });
+      if (!resp.ok) throw new Error(`Telegram send failed: ${resp.status}`);
+    } else {
+      throw new Error(`Unsupported platform: ${platform}`);
+    }
+  }
 }
@@ -19,6 +19,7 @@ import {
   exchangeCodeForTokens,
 } from './oauth-connect.js';
 import type { OAuthProviderDefinition, OAuthPendingState } from './oauth-connect.js';
+import { renderPage } from '../lib/templates.js';
 
 // ─── Skill-to-Provider Mapping (auto-generated from integration catalog) ──
 
@@ -556,48 +557,12 @@ async function handleAgentEmailOAuthCallback(c: any, agentId: string, code: stri
  * opened as a popup it falls back to showing a status message.
  */
 function oauthResultPage(success: boolean, message: string): string {
-  const status = success ? 'success' : 'error';
-  return `<!DOCTYPE html>
-<html lang="en">
-<head>
-  <meta charset="utf-8" />
-  <title>OAuth ${success ? 'Connected' : 'Error'}</title>
-  <style>
-    body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-serif; display: flex; align-items: center; justify-content: center; height: 100vh; margin: 0; background: #f9fafb; }
-    .card { text-align: center; padding: 2rem; border-radius: 8px; background: #fff; box-shadow: 0 1px 3px rgba(0,0,0,0.1); max-width: 400px; }
-    .icon { font-size: 3rem; margin-bottom: 1rem; }
-    .message { color: #374151; margin-top: 0.5rem; }
-    .status-success .icon { color: #10b981; }
-    .status-error .icon { color: #ef4444; }
-  </style>
-</head>
-<body>
-  <div class="card status-${status}">
-    <div class="icon">${success ? '&#10003;' : '&#10007;'}</div>
-    <h2>${success ? 'Connected!' : 'Connection Failed'}</h2>
-    <p class="message">${escapeHtml(message)}</p>
-    <p style="color:#9ca3af;font-size:0.875rem;margin-top:1rem;">This window will close automatically.</p>
-  </div>
-  <script>
-    (function() {
-      var result = { type: 'oauth-result', status: '${status}', message: ${JSON.stringify(message)} };
-      if (window.opener) {
-        window.opener.postMessage(result, '*');
-        setTimeout(function() { window.close(); }, 1500);
-      } else if (window.parent !== window) {
-        window.parent.postMessage(result, '*');
-      }
-    })();
-  </script>
-</body>
-</html>`;
-}
-
-function escapeHtml(str: string): string {
-  return str
-    .replace(/&/g, '&amp;')
-    .replace(/</g, '&lt;')
-    .replace(/>/g, '&gt;')
-    .replace(/"/g, '&quot;')
-    .replace(/'/g, '&#039;');
+  return renderPage('oauth-result', {
+    title: `OAuth ${success ? 'Connected' : 'Error'}`,
+    status: success ? 'success' : 'error',
+    heading: success ? 'Connected!' : 'Connection Failed',
+    iconPath: success ? 'M20 6 9 17l-5-5' : 'M18 6 6 18M6 6l12 12',
+    message,
+    result: JSON.stringify({ type: 'oauth-result', status: success ? 'success' : 'error', message }),
+  });
 }
@@ -7,6 +7,7 @@
 
 import { Hono } from 'hono';
 import type { OrgIntegrationManager } from './org-integrations.js';
+import { renderPage } from '../lib/templates.js';
 
 // Providers that support OAuth connect flow
 const OAUTH_PROVIDERS: Record<string, { authUrl: string; tokenUrl: string; defaultScopes: string }> = {
@@ -22,6 +23,19 @@ const OAUTH_PROVIDERS: Record<string, { authUrl: string; tokenUrl: string; defau
   },
 };
 
+/** Popup page that reports the OAuth outcome to the Organizations page and closes. */
+function orgOAuthResultPage(result: { status: 'success' | 'error'; message?: string; email?: string; provider?: string }): string {
+  const success = result.status === 'success';
+  return renderPage('oauth-result', {
+    title: `OAuth ${success ? 'Connected' : 'Error'}`,
+    status: result.status,
+    heading: success ? 'Connected!' : 'Connection Failed',
+    iconPath: success ? 'M20 6 9 17l-5-5' : 'M18 6 6 18M6 6l12 12',
+    message: success ? `Connected ${result.email || result.provider || ''}`.trim() : result.message,
+    result: JSON.stringify({ type: 'org-oauth-result', ...result }),
+  });
+}
+
 export function createOrgIntegrationRoutes(manager: OrgIntegrationManager) {
   const router = new Hono();
 
@@ -256,18 +270,18 @@ export function createOrgIntegrationRoutes(manager: OrgIntegrationManager) {
       const error = c.req.query('error');
 
       if (error) {
-        return c.html(`<script>window.opener?.postMessage({type:'org-oauth-result',status:'error',message:'${error}'},'*');window.close();</script>`);
+        return c.html(orgOAuthResultPage({ status: 'error', message: error }));
       }
 
       if (!state || !pendingOAuthStates.has(state)) {
-        return c.html(`<script>window.opener?.postMessage({type:'org-oauth-result',status:'error',message:'Invalid state'},'*');window.close();</script>`);
+        return c.html(orgOAuthResultPage({ status: 'error', message: 'Invalid state' }));
       }
 
       const pending = pendingOAuthStates.get(state)!;
       pendingOAuthStates.delete(state);
 
       if (pending.expiresAt < Date.now()) {
-        return c.html(`<script>window.opener?.postMessage({type:'org-oauth-result',status:'error',message:'State expired'},'*');window.close();</script>`);
+        return c.html(orgOAuthResultPage({ status: 'error', message: 'State expired' }));
       }
 
       // Exchange code for tokens
@@ -289,7 +303,7 @@ export function createOrgIntegrationRoutes(manager: OrgIntegrationManager) {
 
       if (!tokenRes.ok) {
         const _err = await tokenRes.text();
-        return c.html(`<script>window.opener?.postMessage({type:'org-oauth-result',status:'error',message:'Token exchange failed: ${tokenRes.status}'},'*');window.close();</script>`);
+        return c.html(orgOAuthResultPage({ status: 'error', message: `Token exchange failed: ${tokenRes.status}` }));
       }
 
       const tokens = await tokenRes.json() as any;
@@ -356,9 +370,9 @@ export function createOrgIntegrationRoutes(manager: OrgIntegrationManager) {
       // Push credentials to all running agents in this org immediately
       await manager.pushCredentialsToOrgAgents(pending.orgId).catch(() => {});
 
-      return c.html(`<script>window.opener?.postMessage({type:'org-oauth-result',status:'success',email:'${email}',provider:'${pending.provider}'},'*');window.close();</script>`);
+      return c.html(orgOAuthResultPage({ status: 'success', email, provider: pending.provider }));
     } catch (e: any) {
-      return c.html(`<script>window.opener?.postMessage({type:'org-oauth-result',status:'error',message:'${e.message}'},'*');window.close();</script>`);
+      return c.html(orgOAuthResultPage({ status: 'error', message: e.message }));
     }
   });
 
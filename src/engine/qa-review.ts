@@ -0,0 +1,314 @@
+/**
+ * Agent Response Quality Review (QA)
+ *
+ * A sample of real agent replies observed on the communication bus is queued
+ * for human grading. Reviewers score each reply against the org's rubric
+ * (1–5 per criterion) and leave a comment; graded reviews roll up into
+ * per-agent quality trends shown on the agent scorecard and in QA reports.
+ *
+ * Sampling is per org (default 10%) and only considers agent-authored
+ * traffic (metadata.source === 'observed'); admins can also queue a specific
+ * message by hand.
+ */
+
+import type { EngineDatabase } from './db-adapter.js';
+import type { AgentMessage } from './communication.js';
+
+function sj(v: any, fb: any = {}): any { if (!v) return fb; if (typeof v !== 'string') return v; try { return JSON.parse(v); } catch { return fb; } }
+
+// ─── Types ──────────────────────────────────────────────
+
+export type QAReviewStatus = 'pending' | 'graded' | 'skipped';
+export type QAReviewSource = 'sample' | 'manual';
+
+export interface QACriterion {
+  id: string;
+  label: string;
+  description?: string;
+  weight: number;
+}
+
+export interface QASettings {
+  orgId: string;
+  sampleRate: number;
+  rubric: QACriterion[];
+}
+
+export interface QAReview {
+  id: string;
+  orgId: string;
+  agentId: string;
+  messageId: string;
+  source: QAReviewSource;
+  status: QAReviewStatus;
+  scores?: Record<string, number>;
+  /** Weighted average of the rubric scores, 1–5 */
+  overall?: number;
+  comment?: string;
+  reviewerId?: string;
+  queuedAt: string;
+  gradedAt?: string;
+}
+
+export interface QATrendPoint {
+  week: string;       // ISO date of the week's Monday
+  average: number;
+  count: number;
+}
+
+export interface QAAgentSummary {
+  agentId: string;
+  average: number | null;
+  graded: number;
+  pending: number;
+  trend: QATrendPoint[];
+  /** Change in average between the last two weeks with grades */
+  delta: number | null;
+}
+
+// ─── Config ─────────────────────────────────────────────
+
+export const DEFAULT_RUBRIC: QACriterion[] = [
+  { id: 'accuracy', label: 'Accuracy', description: 'Facts, numbers and commitments are correct', weight: 2 },
+  { id: 'helpfulness', label: 'Helpfulness', description: 'Resolves the request or moves it forward', weight: 2 },
+  { id: 'tone', label: 'Tone', description: 'Professional, on-brand, appropriate for the recipient', weight: 1 },
+  { id: 'policy', label: 'Policy compliance', description: 'Follows org policies and guardrails', weight: 1 },
+];
+
+const DEFAULT_SAMPLE_RATE = 0.1;
+const MAX_PENDING_PER_ORG = 1000;
+const MAX_CRITERIA = 10;
+const SETTINGS_TTL_MS = 60_000;
+
+// ─── QA Review Queue ────────────────────────────────────
+
+export class QAReviewQueue {
+  private engineDb?: EngineDatabase;
+  private settingsCache = new Map<string, { settings: QASettings; loadedAt: number }>();
+
+  async setDb(db: EngineDatabase): Promise<void> {
+    this.engineDb = db;
+  }
+
+  // ─── Settings ───────────────────────────────────────
+
+  async getSettings(orgId: string): Promise<QASettings> {
+    const cached = this.settingsCache.get(orgId);
+    if (cached && Date.now() - cached.loadedAt < SETTINGS_TTL_MS) return cached.settings;
+    let settings: QASettings = { orgId, sampleRate: DEFAULT_SAMPLE_RATE, rubric: DEFAULT_RUBRIC };
+    try {
+      const row = await this.engineDb?.get<any>('SELECT * FROM qa_settings WHERE org_id = ?', [orgId]);
+      if (row) settings = { orgId, sampleRate: Number(row.sample_rate), rubric: sj(row.rubric, DEFAULT_RUBRIC) };
+    } catch { /* table may not exist yet */ }
+    this.settingsCache.set(orgId, { settings, loadedAt: Date.now() });
+    return settings;
+  }
+
+  async updateSettings(orgId: string, patch: { sampleRate?: number; rubric?: QACriterion[] }): Promise<QASettings> {
+    if (!this.engineDb) throw new Error('QA storage not initialized');
+    const current = await this.getSettings(orgId);
+    const next: QASettings = {
+      orgId,
+      sampleRate: patch.sampleRate ?? current.sampleRate,
+      rubric: patch.rubric ?? current.rubric,
+    };
+    if (!(next.sampleRate >= 0 && next.sampleRate <= 1)) throw new Error('sampleRate must be between 0 and 1');
+    if (!next.rubric.length || next.rubric.length > MAX_CRITERIA) throw new Error(`Rubric needs 1–${MAX_CRITERIA} criteria`);
+    const ids = new Set<string>();
+    for (const c of next.rubric) {
+      if (!c.id || !/^[a-z0-9_-]{1,32}$/.test(c.id) || !c.label) throw new Error('Each criterion needs an id (a-z, 0-9, _ or -) and a label');
+      if (ids.has(c.id)) throw new Error(`Duplicate criterion id: ${c.id}`);
+      if (!(c.weight > 0 && c.weight <= 10)) throw new Error('Criterion weight must be between 0 and 10');
+      ids.add(c.id);
+    }
+    await this.engineDb.execute('DELETE FROM qa_settings WHERE org_id = ?', [orgId]);
+    await this.engineDb.execute(
+      'INSERT INTO qa_settings (org_id, sample_rate, rubric, updated_at) VALUES (?, ?, ?, ?)',
+      [orgId, next.sampleRate, JSON.stringify(next.rubric), new Date().toISOString()],
+    );
+    this.settingsCache.set(orgId, { settings: next, loadedAt: Date.now() });
+    return next;
+  }
+
+  // ─── Queue ──────────────────────────────────────────
+
+  /** Called for every new message; queues agent-authored replies at the org's sample rate. */
+  async maybeSample(msg: AgentMessage): Promise<QAReview | null> {
+    if (!this.engineDb || msg.type !== 'message' || msg.metadata?.source !== 'observed') return null;
+    try {
+      const { sampleRate } = await this.getSettings(msg.orgId);
+      if (sampleRate <= 0 || Math.random() >= sampleRate) return null;
+      return await this.enqueue(msg, 'sample');
+    } catch { return null; /* tables may not exist yet */ }
+  }
+
+  async enqueue(msg: AgentMessage, source: QAReviewSource): Promise<QAReview> {
+    if (!this.engineDb) throw new Error('QA storage not initialized');
+    const existing = await this.engineDb.get<any>('SELECT * FROM qa_reviews WHERE message_id = ?', [msg.id]);
+    if (existing) return this.rowToReview(existing);
+    const pending = await this.engineDb.get<any>("SELECT COUNT(*) AS n FROM qa_reviews WHERE org_id = ? AND status = 'pending'", [msg.orgId]);
+    if (Number(pending?.n || 0) >= MAX_PENDING_PER_ORG) throw new Error(`QA queue is full (max ${MAX_PENDING_PER_ORG} pending reviews)`);
+
+    const review: QAReview = {
+      id: crypto.randomUUID(),
+      orgId: msg.orgId,
+      agentId: msg.fromAgentId,
+      messageId: msg.id,
+      source,
+      status: 'pending',
+      queuedAt: new Date().toISOString(),
+    };
+    await this.engineDb.execute(
+      'INSERT INTO qa_reviews (id, org_id, agent_id, message_id, source, status, queued_at) VALUES (?, ?, ?, ?, ?, ?, ?)',
+      [review.id, review.orgId, review.agentId, review.messageId, review.source, review.status, review.queuedAt],
+    );
+    return review;
+  }
+
+  async list(opts: { orgId: string; status?: QAReviewStatus; agentId?: string; since?: string; limit?: number; offset?: number }): Promise<{ reviews: QAReview[]; total: number }> {
+    if (!this.engineDb) return { reviews: [], total: 0 };
+    const where = ['org_id = ?'];
+    const params: any[] = [opts.orgId];
+    if (opts.status) { where.push('status = ?'); params.push(opts.status); }
+    if (opts.agentId) { where.push('agent_id = ?'); params.push(opts.agentId); }
+    if (opts.since) { where.push('queued_at >= ?'); params.push(opts.since); }
+    const clause = where.join(' AND ');
+    const count = await this.engineDb.get<any>(`SELECT COUNT(*) AS n FROM qa_reviews WHERE ${clause}`, params);
+    const rows = await this.engineDb.query<any>(
+      `SELECT * FROM qa_reviews WHERE ${clause} ORDER BY queued_at ${opts.status === 'pending' ? 'ASC' : 'DESC'} LIMIT ? OFFSET ?`,
+      [...params, Math.min(opts.limit || 50, 500), opts.offset || 0],
+    );
+    return { reviews: rows.map((r: any) => this.rowToReview(r)), total: Number(count?.n || 0) };
+  }
+
+  async get(orgId: string, id: string): Promise<QAReview | null> {
+    if (!this.engineDb) return null;
+    const row = await this.engineDb.get<any>('SELECT * FROM qa_reviews WHERE id = ? AND org_id = ?', [id, orgId]);
+    return row ? this.rowToReview(row) : null;
+  }
+
+  /** Grade a review. Every rubric criterion needs an integer score 1–5. */
+  async grade(orgId: string, id: string, input: { scores: Record<string, number>; comment?: string; reviewerId?: string }): Promise<QAReview | null> {
+    const review = await this.get(orgId, id);
+    if (!review) return null;
+    const { rubric } = await this.getSettings(orgId);
+    const scores: Record<string, number> = {};
+    let weighted = 0, totalWeight = 0;
+    for (const c of rubric) {
+      const v = Number(input.scores?.[c.id]);
+      if (!Number.isInteger(v) || v < 1 || v > 5) throw new Error(`Score for "${c.label}" must be 1–5`);
+      scores[c.id] = v;
+      weighted += v * c.weight;
+      totalWeight += c.weight;
+    }
+    const graded: QAReview = {
+      ...review,
+      status: 'graded',
+      scores,
+      overall: Math.round((weighted / totalWeight) * 100) / 100,
+      comment: input.comment?.slice(0, 4000) || undefined,
+      reviewerId: input.reviewerId,
+      gradedAt: new Date().toISOString(),
+    };
+    await this.engineDb!.execute(
+      'UPDATE qa_reviews SET status = ?, scores = ?, overall = ?, comment = ?, reviewer_id = ?, graded_at = ? WHERE id = ?',
+      [graded.status, JSON.stringify(scores), graded.overall, graded.comment || null, graded.reviewerId || null, graded.gradedAt, id],
+    );
+    return graded;
+  }
+
+  async skip(orgId: string, id: string, reviewerId?: string): Promise<boolean> {
+    const review = await this.get(orgId, id);
+    if (!review || review.status !== 'pending') return false;
+    await this.engineDb!.execute(
+      "UPDATE qa_reviews SET status = 'skipped', reviewer_id = ?, graded_at = ? WHERE id = ?",
+      [reviewerId || null, new Date().toISOString(), id],
+    );
+    return true;
+  }
+
+  // ─── Trends ─────────────────────────────────────────
+
+  /** Per-agent average, weekly trend and queue depth over the last `days`. */
+  async summary(orgId: string, opts: { agentId?: string; days?: number } = {}): Promise<QAAgentSummary[]> {
+    if (!this.engineDb) return [];
+    const since = new Date(Date.now() - (opts.days || 90) * 86_400_000).toISOString();
+    const where = ['org_id = ?', "(status = 'pending' OR (status = 'graded' AND graded_at >= ?))"];
+    const params: any[] = [orgId, since];
+    if (opts.agentId) { where.push('agent_id = ?'); params.push(opts.agentId); }
+    const rows = await this.engineDb.query<any>(`SELECT agent_id, status, overall, graded_at FROM qa_reviews WHERE ${where.join(' AND ')}`, params);
+
+    const byAgent = new Map<string, { pending: number; weeks: Map<string, { sum: number; count: number }>; sum: number; count: number }>();
+    for (const r of rows) {
+      const a = byAgent.get(r.agent_id) || { pending: 0, weeks: new Map(), sum: 0, count: 0 };
+      byAgent.set(r.agent_id, a);
+      if (r.status === 'pending') { a.pending++; continue; }
+      const score = Number(r.overall);
+      if (!Number.isFinite(score)) continue;
+      const week = weekStart(new Date(r.graded_at));
+      const w = a.weeks.get(week) || { sum: 0, count: 0 };
+      w.sum += score; w.count++;
+      a.weeks.set(week, w);
+      a.sum += score; a.count++;
+    }
+
+    return [...byAgent.entries()].map(([agentId, a]) => {
+      const trend = [...a.weeks.entries()]
+        .sort(([x], [y]) => x.localeCompare(y))
+        .map(([week, w]) => ({ week, average: round2(w.sum / w.count), count: w.count }));
+      const last = trend[trend.length - 1], prev = trend[trend.length - 2];
+      return {
+        agentId,
+        average: a.count ? round2(a.sum / a.count) : null,
+        graded: a.count,
+        pending: a.pending,
+        trend,
+        delta: last && prev ? round2(last.average - prev.average) : null,
+      };
+    }).sort((x, y) => (y.average ?? -1) - (x.average ?? -1));
+  }
+
+  /** Graded reviews as CSV — one row per review, one column per rubric criterion. */
+  async exportCsv(orgId: string, opts: { agentId?: string; days?: number } = {}): Promise<string> {
+    const since = new Date(Date.now() - (opts.days || 90) * 86_400_000).toISOString();
+    const { rubric } = await this.getSettings(orgId);
+    const { reviews } = await this.list({ orgId, status: 'graded', agentId: opts.agentId, since, limit: 500 });
+    const esc = (v: unknown) => {
+      const s = String(v ?? '');
+      return /[",\n\r]/.test(s) ? `"${s.replace(/"/g, '""')}"` : s;
+    };
+    const header = ['review_id', 'agent_id', 'message_id', 'source', 'queued_at', 'graded_at', 'reviewer_id', 'overall', ...rubric.map(c => c.id), 'comment'];
+    const lines = reviews.map(r => [
+      r.id, r.agentId, r.messageId, r.source, r.queuedAt, r.gradedAt, r.reviewerId, r.overall,
+      ...rubric.map(c => r.scores?.[c.id]), r.comment,
+    ].map(esc).join(','));
+    return [header.join(','), ...lines].join('\n');
+  }
+
+  private rowToReview(r: any): QAReview {
+    return {
+      id: r.id,
+      orgId: r.org_id,
+      agentId: r.agent_id,
+      messageId: r.message_id,
+      source: r.source,
+      status: r.status,
+      scores: r.scores ? sj(r.scores) : undefined,
+      overall: r.overall != null ? Number(r.overall) : undefined,
+      comment: r.comment || undefined,
+      reviewerId: r.reviewer_id || undefined,
+      queuedAt: new Date(r.queued_at).toISOString(),
+      gradedAt: r.graded_at ? new Date(r.graded_at).toISOString() : undefined,
+    };
+  }
+}
+
+function round2(n: number): number {
+  return Math.round(n * 100) / 100;
+}
+
+/** Monday (UTC) of the week containing d, as YYYY-MM-DD. */
+function weekStart(d: Date): string {
+  const day = (d.getUTCDay() + 6) % 7;
+  return new Date(Date.UTC(d.getUTCFullYear(), d.getUTCMonth(), d.getUTCDate() - day)).toISOString().slice(0, 10);
+}
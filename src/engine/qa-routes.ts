@@ -0,0 +1,110 @@
+/**
+ * QA Review Routes
+ * Mounted at /qa/* on the engine sub-app.
+ */
+
+import { Hono } from 'hono';
+import type { QAReviewQueue, QAReviewStatus } from './qa-review.js';
+import type { AgentCommunicationBus } from './communication.js';
+
+const STATUSES: QAReviewStatus[] = ['pending', 'graded', 'skipped'];
+
+export function createQARoutes(qa: QAReviewQueue, commBus: AgentCommunicationBus) {
+  const router = new Hono();
+
+  const withMessage = (review: any) => {
+    const msg = commBus.getMessage(review.messageId);
+    return { ...review, message: msg ? { id: msg.id, subject: msg.subject, content: msg.content, toAgentId: msg.toAgentId, direction: msg.direction, channel: msg.channel, metadata: msg.metadata, createdAt: msg.createdAt } : null };
+  };
+
+  // ─── Queue ─────────────────────────────────────────────
+
+  router.get('/queue', async (c) => {
+    const orgId = c.req.query('orgId');
+    if (!orgId) return c.json({ error: 'orgId required' }, 400);
+    const status = (c.req.query('status') || 'pending') as QAReviewStatus;
+    if (!STATUSES.includes(status)) return c.json({ error: 'status must be pending, graded or skipped' }, 400);
+    const result = await qa.list({
+      orgId, status,
+      agentId: c.req.query('agentId') || undefined,
+      limit: parseInt(c.req.query('limit') || '50'),
+      offset: parseInt(c.req.query('offset') || '0'),
+    });
+    return c.json({ reviews: result.reviews.map(withMessage), total: result.total });
+  });
+
+  // Queue a specific message by hand
+  router.post('/queue', async (c) => {
+    const body = await c.req.json().catch(() => ({}));
+    const msg = body.messageId ? commBus.getMessage(body.messageId) : undefined;
+    if (!msg) return c.json({ error: 'Message not found' }, 404);
+    try {
+      const review = await qa.enqueue(msg, 'manual');
+      return c.json({ review: withMessage(review) }, 201);
+    } catch (e: any) {
+      return c.json({ error: e.message }, 400);
+    }
+  });
+
+  router.post('/queue/:id/grade', async (c) => {
+    const body = await c.req.json().catch(() => ({}));
+    if (!body.orgId || !body.scores) return c.json({ error: 'orgId and scores required' }, 400);
+    try {
+      const review = await qa.grade(body.orgId, c.req.param('id'), { scores: body.scores, comment: body.comment, reviewerId: c.req.header('X-User-Id') });
+      if (!review) return c.json({ error: 'Review not found' }, 404);
+      return c.json({ review });
+    } catch (e: any) {
+      return c.json({ error: e.message }, 400);
+    }
+  });
+
+  router.post('/queue/:id/skip', async (c) => {
+    const body = await c.req.json().catch(() => ({}));
+    if (!body.orgId) return c.json({ error: 'orgId required' }, 400);
+    const ok = await qa.skip(body.orgId, c.req.param('id'), c.req.header('X-User-Id'));
+    if (!ok) return c.json({ error: 'Review not found or already handled' }, 404);
+    return c.json({ ok: true });
+  });
+
+  // ─── Trends & Reports ──────────────────────────────────
+
+  router.get('/summary', async (c) => {
+    const orgId = c.req.query('orgId');
+    if (!orgId) return c.json({ error: 'orgId required' }, 400);
+    const agents = await qa.summary(orgId, { agentId: c.req.query('agentId') || undefined, days: parseInt(c.req.query('days') || '90') });
+    return c.json({ agents });
+  });
+
+  router.get('/export', async (c) => {
+    const orgId = c.req.query('orgId');
+    if (!orgId) return c.json({ error: 'orgId required' }, 400);
+    const csv = await qa.exportCsv(orgId, { agentId: c.req.query('agentId') || undefined, days: parseInt(c.req.query('days') || '90') });
+    c.header('Content-Type', 'text/csv; charset=utf-8');
+    c.header('Content-Disposition', `attachment; filename="qa-report-${new Date().toISOString().slice(0, 10)}.csv"`);
+    return c.body(csv);
+  });
+
+  // ─── Settings ──────────────────────────────────────────
+
+  router.get('/settings', async (c) => {
+    const orgId = c.req.query('orgId');
+    if (!orgId) return c.json({ error: 'orgId required' }, 400);
+    return c.json({ settings: await qa.getSettings(orgId) });
+  });
+
+  router.put('/settings', async (c) => {
+    const body = await c.req.json().catch(() => ({}));
+    if (!body.orgId) return c.json({ error: 'orgId required' }, 400);
+    try {
+      const settings = await qa.updateSettings(body.orgId, {
+        sampleRate: body.sampleRate !== undefined ? Number(body.sampleRate) : undefined,
+        rubric: Array.isArray(body.rubric) ? body.rubric.map((r: any) => ({ id: String(r.id || ''), label: String(r.label || '').slice(0, 64), description: r.description ? String(r.description).slice(0, 200) : undefined, weight: Number(r.weight) || 1 })) : undefined,
+      });
+      return c.json({ settings });
+    } catch (e: any) {
+      return c.json({ error: e.message }, 400);
+    }
+  });
+
+  return router;
+}
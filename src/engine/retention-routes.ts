@@ -0,0 +1,109 @@
+/**
+ * Retention Routes
+ * Mounted at /retention/* on the engine sub-app.
+ *
+ * Previews, approvals and legal hold changes are all written to the admin
+ * audit log. When no retainDays is given, the preview uses the policy saved
+ * under Settings → Data Retention. Previews take an admin, and approving or
+ * discarding one takes an admin other than the one who previewed it;
+ * placing and releasing legal holds takes the owner. API keys can do none
+ * of these.
+ */
+
+import { Hono } from 'hono';
+import type { RetentionManager } from './retention.js';
+import type { DatabaseAdapter } from '../db/adapter.js';
+import { auditFromEngine } from './route-audit.js';
+import { isAdminCaller, isOwnerCaller } from './caller-role.js';
+
+export function createRetentionRoutes(retention: RetentionManager, deps: { getAdminDb: () => DatabaseAdapter | null }) {
+  const router = new Hono();
+
+  const audit = auditFromEngine(deps.getAdminDb, 'retention');
+
+  // ─── Purge Runs ───────────────────────────────────────
+
+  router.get('/runs', async (c) => {
+    const orgId = c.req.query('orgId');
+    if (!orgId) return c.json({ error: 'orgId required' }, 400);
+    return c.json({ runs: await retention.getRuns(orgId) });
+  });
+
+  // { orgId, retainDays? } — dry run; nothing is deleted
+  router.post('/preview', async (c) => {
+    if (!isAdminCaller(c)) return c.json({ error: 'Only admins can preview a purge' }, 403);
+    const body = await c.req.json().catch(() => ({}));
+    if (!body.orgId) return c.json({ error: 'orgId required' }, 400);
+    let retainDays = body.retainDays;
+    if (retainDays === undefined || retainDays === null) {
+      const policy = await deps.getAdminDb()?.getRetentionPolicy().catch(() => null);
+      if (!policy) return c.json({ error: 'retainDays required' }, 400);
+      retainDays = policy.retainDays;
+    }
+    try {
+      const run = await retention.preview(body.orgId, Number(retainDays), c.req.header('X-User-Id') || undefined);
+      audit(c, 'preview', `retention_run:${run.id}`, { retainDays: run.retainDays, cutoff: run.cutoff, total: run.report.total }, run.orgId);
+      return c.json({ run }, 201);
+    } catch (err: any) {
+      return c.json({ error: err.message }, 400);
+    }
+  });
+
+  router.post('/runs/:id/approve', async (c) => {
+    if (!isAdminCaller(c)) return c.json({ error: 'Only admins can approve a purge' }, 403);
+    try {
+      const run = await retention.execute(c.req.param('id'), c.req.header('X-User-Id') || undefined);
+      audit(c, 'purge', `retention_run:${run.id}`, { cutoff: run.cutoff, deleted: run.result }, run.orgId);
+      return c.json({ run });
+    } catch (err: any) {
+      return c.json({ error: err.message }, err.message === 'Retention run not found' ? 404 : 400);
+    }
+  });
+
+  router.post('/runs/:id/discard', async (c) => {
+    if (!isAdminCaller(c)) return c.json({ error: 'Only admins can discard a purge report' }, 403);
+    try {
+      const run = await retention.discard(c.req.param('id'), c.req.header('X-User-Id') || undefined);
+      if (!run) return c.json({ error: 'Retention run not found' }, 404);
+      audit(c, 'discard', `retention_run:${run.id}`, undefined, run.orgId);
+      return c.json({ run });
+    } catch (err: any) {
+      return c.json({ error: err.message }, 400);
+    }
+  });
+
+  // ─── Legal Holds ──────────────────────────────────────
+
+  router.get('/holds', async (c) => {
+    const orgId = c.req.query('orgId');
+    if (!orgId) return c.json({ error: 'orgId required' }, 400);
+    return c.json({ holds: await retention.getHolds(orgId) });
+  });
+
+  // { orgId, agentId, reason }
+  router.post('/holds', async (c) => {
+    if (!isOwnerCaller(c)) return c.json({ error: 'Only the owner can place a legal hold' }, 403);
+    const body = await c.req.json().catch(() => ({}));
+    if (!body.orgId) return c.json({ error: 'orgId required' }, 400);
+    try {
+      const hold = await retention.placeHold({
+        orgId: body.orgId, agentId: body.agentId, reason: body.reason, createdBy: c.req.header('X-User-Id') || undefined,
+      });
+      audit(c, 'hold_place', `legal_hold:${hold.id}`, { agentId: hold.agentId, reason: hold.reason }, hold.orgId);
+      return c.json({ hold }, 201);
+    } catch (err: any) {
+      return c.json({ error: err.message }, 400);
+    }
+  });
+
+  router.delete('/holds/:id', async (c) => {
+    if (!isOwnerCaller(c)) return c.json({ error: 'Only the owner can release a legal hold' }, 403);
+    const existing = await retention.getHold(c.req.param('id'));
+    if (!existing) return c.json({ error: 'Legal hold not found' }, 404);
+    await retention.releaseHold(existing.id);
+    audit(c, 'hold_release', `legal_hold:${existing.id}`, { agentId: existing.agentId, reason: existing.reason }, existing.orgId);
+    return c.json({ success: true });
+  });
+
+  return router;
+}
@@ -0,0 +1,321 @@
+/**
+ * Retention — Previewed, approved purges of old agent data, and legal holds
+ *
+ * Nothing is deleted on a timer. A purge starts as a dry run: preview()
+ * counts exactly what falls past the cutoff (per data type, per agent, and
+ * what legal holds keep back) and stores that report as a pending run. An
+ * admin reviews it and approves; only then does execute() delete, using the
+ * cutoff frozen in the report, so the purge can never reach further than
+ * what was shown.
+ *
+ * A legal hold pins every record for an agent. Held agents are excluded
+ * from previews and from the delete itself, including holds placed after
+ * the preview was generated.
+ */
+
+import type { EngineDatabase } from './db-adapter.js';
+
+// ─── Types ──────────────────────────────────────────────
+
+export type RetentionDataType = 'tool_calls' | 'activity_events' | 'conversations';
+export type RetentionRunStatus = 'pending' | 'completed' | 'discarded' | 'expired' | 'failed';
+
+export interface LegalHold {
+  id: string;
+  orgId: string;
+  agentId: string;
+  reason: string;
+  createdBy?: string;
+  createdAt: string;
+}
+
+export interface RetentionReport {
+  cutoff: string;
+  retainDays: number;
+  generatedAt: string;
+  total: number;
+  types: Array<{ type: RetentionDataType; label: string; count: number; oldest?: string; newest?: string }>;
+  /** Agents whose records would be purged, most affected first */
+  agents: Array<{ agentId: string; name: string; count: number }>;
+  /** Records past the cutoff that are kept because of a legal hold */
+  holds: Array<{ agentId: string; name: string; reason: string; excluded: number }>;
+}
+
+export interface RetentionRun {
+  id: string;
+  orgId: string;
+  retainDays: number;
+  cutoff: string;
+  report: RetentionReport;
+  status: RetentionRunStatus;
+  /** Rows actually deleted, per type */
+  result?: Partial<Record<RetentionDataType, number>>;
+  error?: string;
+  createdBy?: string;
+  approvedBy?: string;
+  createdAt: string;
+  approvedAt?: string;
+  completedAt?: string;
+}
+
+// ─── Config ─────────────────────────────────────────────
+
+const TARGETS: Array<{ type: RetentionDataType; label: string; orgScoped: boolean }> = [
+  { type: 'tool_calls', label: 'Tool calls', orgScoped: true },
+  { type: 'activity_events', label: 'Activity events', orgScoped: true },
+  // conversations carry no org_id; scope through the owning agent
+  { type: 'conversations', label: 'Conversation messages', orgScoped: false },
+];
+
+/** A report older than this must be regenerated before it can be approved */
+const REPORT_TTL_MS = 24 * 60 * 60 * 1000;
+const MIN_RETAIN_DAYS = 1;
+const MAX_RETAIN_DAYS = 3650;
+
+// ─── Retention Manager ──────────────────────────────────
+
+export class RetentionManager {
+  private engineDb?: EngineDatabase;
+
+  async setDb(db: EngineDatabase): Promise<void> {
+    this.engineDb = db;
+  }
+
+  private get db(): EngineDatabase {
+    if (!this.engineDb) throw new Error('Retention database not initialized');
+    return this.engineDb;
+  }
+
+  // ─── Legal Holds ──────────────────────────────────────
+
+  async getHolds(orgId: string): Promise<LegalHold[]> {
+    if (!this.engineDb) return [];
+    const rows = await this.engineDb.query<any>('SELECT * FROM legal_holds WHERE org_id = ? ORDER BY created_at DESC', [orgId]);
+    return rows.map((r: any) => this.rowToHold(r));
+  }
+
+  async getHold(id: string): Promise<LegalHold | undefined> {
+    if (!this.engineDb) return undefined;
+    const row = await this.engineDb.get<any>('SELECT * FROM legal_holds WHERE id = ?', [id]);
+    return row ? this.rowToHold(row) : undefined;
+  }
+
+  /** Whether the agent's data is frozen. Anything that deletes agent data should check this. */
+  async isHeld(orgId: string, agentId: string): Promise<boolean> {
+    if (!this.engineDb) return false;
+    const row = await this.engineDb.get<any>('SELECT id FROM legal_holds WHERE org_id = ? AND agent_id = ?', [orgId, agentId]);
+    return !!row;
+  }
+
+  async placeHold(input: { orgId: string; agentId: string; reason: string; createdBy?: string }): Promise<LegalHold> {
+    if (!input.agentId) throw new Error('agentId is required');
+    if (!input.reason?.trim()) throw new Error('A reason is required for a legal hold');
+    if (await this.isHeld(input.orgId, input.agentId)) throw new Error('This agent is already on legal hold');
+    const hold: LegalHold = {
+      id: crypto.randomUUID(),
+      orgId: input.orgId,
+      agentId: input.agentId,
+      reason: input.reason.trim().slice(0, 1000),
+      createdBy: input.createdBy,
+      createdAt: new Date().toISOString(),
+    };
+    await this.db.execute(
+      'INSERT INTO legal_holds (id, org_id, agent_id, reason, created_by, created_at) VALUES (?, ?, ?, ?, ?, ?)',
+      [hold.id, hold.orgId, hold.agentId, hold.reason, hold.createdBy || null, hold.createdAt]
+    );
+    return hold;
+  }
+
+  async releaseHold(id: string): Promise<void> {
+    await this.db.execute('DELETE FROM legal_holds WHERE id = ?', [id]);
+  }
+
+  // ─── Purge Runs ───────────────────────────────────────
+
+  async getRuns(orgId: string, limit = 20): Promise<RetentionRun[]> {
+    if (!this.engineDb) return [];
+    const rows = await this.engineDb.query<any>(
+      'SELECT * FROM retention_runs WHERE org_id = ? ORDER BY created_at DESC LIMIT ?', [orgId, limit]
+    );
+    return rows.map((r: any) => this.rowToRun(r));
+  }
+
+  async getRun(id: string): Promise<RetentionRun | undefined> {
+    if (!this.engineDb) return undefined;
+    const row = await this.engineDb.get<any>('SELECT * FROM retention_runs WHERE id = ?', [id]);
+    return row ? this.rowToRun(row) : undefined;
+  }
+
+  /**
+   * Dry run: count what a purge keeping `retainDays` would delete and save
+   * the report as a pending run. Any earlier pending run for the org is
+   * discarded, so only the latest report can be approved.
+   */
+  async preview(orgId: string, retainDays: number, createdBy?: string): Promise<RetentionRun> {
+    if (!Number.isInteger(retainDays) || retainDays < MIN_RETAIN_DAYS || retainDays > MAX_RETAIN_DAYS) {
+      throw new Error(`retainDays must be a whole number between ${MIN_RETAIN_DAYS} and ${MAX_RETAIN_DAYS}`);
+    }
+    const now = new Date();
+    const cutoff = new Date(now.getTime() - retainDays * 86_400_000).toISOString();
+    const holds = await this.getHolds(orgId);
+    const heldIds = holds.map(h => h.agentId);
+    const names = await this.agentNames(orgId);
+
+    const report: RetentionReport = { cutoff, retainDays, generatedAt: now.toISOString(), total: 0, types: [], agents: [], holds: [] };
+    const perAgent = new Map<string, number>();
+    const excluded = new Map<string, number>();
+
+    for (const t of TARGETS) {
+      const purge = this.scope(t, orgId, cutoff, heldIds, 'exclude');
+      const row = await this.db.get<any>(
+        `SELECT COUNT(*) AS c, MIN(created_at) AS oldest, MAX(created_at) AS newest FROM ${t.type} WHERE ${purge.where}`, purge.params
+      );
+      const count = Number(row?.c || 0);
+      report.types.push({ type: t.type, label: t.label, count, oldest: toIso(row?.oldest), newest: toIso(row?.newest) });
+      report.total += count;
+
+      if (count > 0) {
+        const byAgent = await this.db.query<any>(
+          `SELECT agent_id, COUNT(*) AS c FROM ${t.type} WHERE ${purge.where} GROUP BY agent_id`, purge.params
+        );
+        for (const r of byAgent) perAgent.set(r.agent_id, (perAgent.get(r.agent_id) || 0) + Number(r.c));
+      }
+
+      if (heldIds.length) {
+        const held = this.scope(t, orgId, cutoff, heldIds, 'only');
+        const byAgent = await this.db.query<any>(
+          `SELECT agent_id, COUNT(*) AS c FROM ${t.type} WHERE ${held.where} GROUP BY agent_id`, held.params
+        );
+        for (const r of byAgent) excluded.set(r.agent_id, (excluded.get(r.agent_id) || 0) + Number(r.c));
+      }
+    }
+
+    report.agents = [...perAgent.entries()]
+      .map(([agentId, count]) => ({ agentId, name: names.get(agentId) || agentId, count }))
+      .sort((a, b) => b.count - a.count);
+    report.holds = holds.map(h => ({ agentId: h.agentId, name: names.get(h.agentId) || h.agentId, reason: h.reason, excluded: excluded.get(h.agentId) || 0 }));
+
+    await this.db.execute(
+      "UPDATE retention_runs SET status = 'discarded' WHERE org_id = ? AND status = 'pending'", [orgId]
+    );
+    const run: RetentionRun = {
+      id: crypto.randomUUID(), orgId, retainDays, cutoff, report, status: 'pending', createdBy, createdAt: report.generatedAt,
+    };
+    await this.db.execute(
+      'INSERT INTO retention_runs (id, org_id, retain_days, cutoff, report, status, created_by, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)',
+      [run.id, orgId, retainDays, cutoff, JSON.stringify(report), 'pending', createdBy || null, run.createdAt]
+    );
+    return run;
+  }
+
+  /** Approve a pending run and delete what its report covered. */
+  async execute(id: string, approvedBy?: string): Promise<RetentionRun> {
+    const run = await this.getRun(id);
+    if (!run) throw new Error('Retention run not found');
+    if (run.status !== 'pending') throw new Error(`This run is ${run.status} and can no longer be approved`);
+    // Approval is the second pair of eyes on the dry-run report
+    if (!approvedBy || approvedBy === run.createdBy) {
+      throw new Error('A different admin than the one who generated the preview must approve the purge');
+    }
+    if (Date.now() - new Date(run.createdAt).getTime() > REPORT_TTL_MS) {
+      await this.db.execute("UPDATE retention_runs SET status = 'expired' WHERE id = ?", [id]);
+      throw new Error('This report is more than 24 hours old. Generate a new preview before purging.');
+    }
+
+    const approvedAt = new Date().toISOString();
+    await this.db.execute(
+      'UPDATE retention_runs SET approved_by = ?, approved_at = ? WHERE id = ?', [approvedBy || null, approvedAt, id]
+    );
+
+    // Holds placed since the preview still win
+    const heldIds = [...new Set([...run.report.holds.map(h => h.agentId), ...(await this.getHolds(run.orgId)).map(h => h.agentId)])];
+    const result: Partial<Record<RetentionDataType, number>> = {};
+    try {
+      for (const t of TARGETS) {
+        const purge = this.scope(t, run.orgId, run.cutoff, heldIds, 'exclude');
+        const row = await this.db.get<any>(`SELECT COUNT(*) AS c FROM ${t.type} WHERE ${purge.where}`, purge.params);
+        await this.db.execute(`DELETE FROM ${t.type} WHERE ${purge.where}`, purge.params);
+        result[t.type] = Number(row?.c || 0);
+      }
+    } catch (err: any) {
+      await this.db.execute(
+        "UPDATE retention_runs SET status = 'failed', result = ?, error = ?, completed_at = ? WHERE id = ?",
+        [JSON.stringify(result), err.message, new Date().toISOString(), id]
+      );
+      throw err;
+    }
+
+    const completedAt = new Date().toISOString();
+    await this.db.execute(
+      "UPDATE retention_runs SET status = 'completed', result = ?, completed_at = ? WHERE id = ?",
+      [JSON.stringify(result), completedAt, id]
+    );
+    return { ...run, status: 'completed', result, approvedBy, approvedAt, completedAt };
+  }
+
+  async discard(id: string, discardedBy?: string): Promise<RetentionRun | undefined> {
+    const run = await this.getRun(id);
+    if (!run) return undefined;
+    if (run.status !== 'pending') throw new Error(`This run is ${run.status} and can no longer be discarded`);
+    if (!discardedBy || discardedBy === run.createdBy) {
+      throw new Error('A different admin than the one who generated the preview must discard it');
+    }
+    await this.db.execute("UPDATE retention_runs SET status = 'discarded' WHERE id = ?", [id]);
+    return { ...run, status: 'discarded' };
+  }
+
+  // ─── Helpers ──────────────────────────────────────────
+
+  /** WHERE clause for one data type past the cutoff, excluding or limited to held agents */
+  private scope(t: typeof TARGETS[number], orgId: string, cutoff: string, heldIds: string[], holds: 'exclude' | 'only'): { where: string; params: any[] } {
+    let where = t.orgScoped
+      ? 'org_id = ? AND created_at < ?'
+      : 'agent_id IN (SELECT id FROM managed_agents WHERE org_id = ?) AND created_at < ?';
+    const params: any[] = [orgId, cutoff];
+    if (heldIds.length) {
+      where += ` AND agent_id ${holds === 'exclude' ? 'NOT IN' : 'IN'} (${heldIds.map(() => '?').join(', ')})`;
+      params.push(...heldIds);
+    } else if (holds === 'only') {
+      where += ' AND 1 = 0';
+    }
+    return { where, params };
+  }
+
+  private async agentNames(orgId: string): Promise<Map<string, string>> {
+    const rows = await this.db.query<any>('SELECT id, display_name, name FROM managed_agents WHERE org_id = ?', [orgId]).catch(() => []);
+    return new Map(rows.map((r: any) => [r.id, r.display_name || r.name || r.id]));
+  }
+
+  private rowToHold(r: any): LegalHold {
+    return {
+      id: r.id,
+      orgId: r.org_id,
+      agentId: r.agent_id,
+      reason: r.reason,
+      createdBy: r.created_by || undefined,
+      createdAt: toIso(r.created_at)!,
+    };
+  }
+
+  private rowToRun(r: any): RetentionRun {
+    return {
+      id: r.id,
+      orgId: r.org_id,
+      retainDays: Number(r.retain_days),
+      cutoff: toIso(r.cutoff)!,
+      report: typeof r.report === 'string' ? JSON.parse(r.report) : r.report,
+      status: r.status,
+      result: r.result ? (typeof r.result === 'string' ? JSON.parse(r.result) : r.result) : undefined,
+      error: r.error || undefined,
+      createdBy: r.created_by || undefined,
+      approvedBy: r.approved_by || undefined,
+      createdAt: toIso(r.created_at)!,
+      approvedAt: toIso(r.approved_at),
+      completedAt: toIso(r.completed_at),
+    };
+  }
+}
+
+function toIso(v: any): string | undefined {
+  return v ? new Date(v).toISOString() : undefined;
+}
@@ -0,0 +1,30 @@
+/**
+ * Audit Logging for Engine Routes
+ *
+ * The engine sub-app has no session of its own; server.ts forwards the
+ * caller as X-User-Id when it proxies a dashboard request. Route files
+ * record admin actions through the admin database with the helper below,
+ * so every engine event names its actor and client IP the same way.
+ */
+
+import type { DatabaseAdapter } from '../db/adapter.js';
+
+export type EngineAudit = (c: any, action: string, resource: string, details?: Record<string, any>, orgId?: string) => void;
+
+/**
+ * An audit logger for a route file. With a prefix, actions are recorded as
+ * `<prefix>.<action>`. Logging never fails the request.
+ */
+export function auditFromEngine(getAdminDb: (() => DatabaseAdapter | null) | undefined, prefix?: string): EngineAudit {
+  return (c, action, resource, details, orgId) => {
+    getAdminDb?.()?.logEvent({
+      actor: c.req.header('X-User-Id') || 'dashboard',
+      actorType: 'user',
+      action: prefix ? `${prefix}.${action}` : action,
+      resource,
+      details,
+      ip: c.req.header('x-forwarded-for')?.split(',')[0]?.trim() || c.req.header('x-real-ip'),
+      orgId,
+    }).catch(() => {});
+  };
+}
@@ -24,6 +24,30 @@
  *   - vault-routes.ts        → /vault/*
  *   - storage-routes.ts      → /storage/*
  *   - policy-import-routes.ts→ /policies/import/*
+ *   - draft-routes.ts         → /drafts/*
+ *   - follow-up-routes.ts     → /follow-ups/*
+ *   - message-label-routes.ts → /message-labels/*
+ *   - qa-routes.ts            → /qa/*
+ *   - takeover-routes.ts      → /takeovers/*
+ *   - work-queue-routes.ts    → /work-queue/*
+ *   - change-calendar-routes.ts → /change-calendar/*
+ *   - retention-routes.ts     → /retention/*
+ *   - message-search-routes.ts → /message-search/*
+ *   - ediscovery-routes.ts    → /ediscovery/*
+ *   - security-posture-routes.ts → /security/*
+ *   - user-preferences-routes.ts → /preferences/*
+ *   - classification-routes.ts → /classifications/*
+ *   - data-residency-routes.ts → /residency/*
+ *   - ai-disclosure-routes.ts → /disclosure/*
+ *   - agent-offboarding-routes.ts → /agent-lifecycle/*
+ *   - housekeeping-routes.ts → /housekeeping/*
+ *   - trust-center-routes.ts → /trust/*
+ *   - attention-routes.ts → /attention/*
+ *   - log-tail-routes.ts → /logs/*
+ *   - transcript-routes.ts → /transcripts/*
+ *   - team-routes.ts → /teams/*
+ *   - avatar-routes.ts → /avatars/*
+ *   - agent-webhook-routes.ts → /agent-webhooks/*
  */
 
 import { Hono } from 'hono';
@@ -52,6 +76,26 @@ import {
 import { createDlpRoutes } from './dlp-routes.js';
 import { createGuardrailRoutes, createAnomalyRoutes } from './guardrail-routes.js';
 import { createJournalRoutes } from './journal-routes.js';
+import { DraftStore } from './drafts.js';
+import { createDraftRoutes } from './draft-routes.js';
+import { FollowUpStore } from './follow-ups.js';
+import { createFollowUpRoutes } from './follow-up-routes.js';
+import { MessageLabelStore } from './message-labels.js';
+import { createMessageLabelRoutes } from './message-label-routes.js';
+import { QAReviewQueue } from './qa-review.js';
+import { createQARoutes } from './qa-routes.js';
+import { ConversationTakeoverManager } from './conversation-takeover.js';
+import { createTakeoverRoutes } from './takeover-routes.js';
+import { WorkQueue } from './work-queue.js';
+import { createWorkQueueRoutes } from './work-queue-routes.js';
+import { ChangeCalendar } from './change-calendar.js';
+import { createChangeCalendarRoutes } from './change-calendar-routes.js';
+import { RetentionManager } from './retention.js';
+import { createRetentionRoutes } from './retention-routes.js';
+import { MessageSearch } from './message-search.js';
+import { createMessageSearchRoutes } from './message-search-routes.js';
+import { CaseManager } from './ediscovery.js';
+import { createEDiscoveryRoutes } from './ediscovery-routes.js';
 import { createCommunicationRoutes, createTaskRoutes } from './communication-routes.js';
 import { createComplianceRoutes } from './compliance-routes.js';
 import { createCatalogRoutes } from './catalog-routes.js';
@@ -81,6 +125,37 @@ import { StorageManager } from './storage-manager.js';
 import { PolicyImporter } from './policy-import.js';
 import { createVaultRoutes } from './vault-routes.js';
 import { createStorageRoutes } from './storage-routes.js';
+import { StorageUsage } from './storage-usage.js';
+import { DataEncryption } from './data-encryption.js';
+import { createSecurityPostureRoutes } from './security-posture-routes.js';
+import { UserPreferences } from './user-preferences.js';
+import { createUserPreferenceRoutes } from './user-preferences-routes.js';
+import { DataClassifier } from './classification.js';
+import { createClassificationRoutes } from './classification-routes.js';
+import { setAgentClassificationResolver } from '../lib/classification.js';
+import { DataResidency } from './data-residency.js';
+import { createDataResidencyRoutes } from './data-residency-routes.js';
+import { AIDisclosureManager } from './ai-disclosure.js';
+import { createAIDisclosureRoutes } from './ai-disclosure-routes.js';
+import { AgentOffboarding } from './agent-offboarding.js';
+import { createAgentOffboardingRoutes } from './agent-offboarding-routes.js';
+import { Housekeeping } from './housekeeping.js';
+import { createHousekeepingRoutes } from './housekeeping-routes.js';
+import { TrustCenter } from './trust-center.js';
+import { createTrustCenterRoutes } from './trust-center-routes.js';
+import { AttentionFeed } from './attention.js';
+import { createAttentionRoutes } from './attention-routes.js';
+import { LogTail } from './log-tail.js';
+import { createLogTailRoutes } from './log-tail-routes.js';
+import { TranscriptReader } from './transcripts.js';
+import { createTranscriptRoutes } from './transcript-routes.js';
+import { TeamManager } from './teams.js';
+import { callerRole } from './caller-role.js';
+import { createTeamRoutes } from './team-routes.js';
+import { AvatarStore } from './avatars.js';
+import { createAvatarRoutes } from './avatar-routes.js';
+import { AgentWebhookManager, type WebhookEvent } from './agent-webhooks.js';
+import { createAgentWebhookRoutes } from './agent-webhook-routes.js';
 import { createPolicyImportRoutes } from './policy-import-routes.js';
 import { createOAuthConnectRoutes } from './oauth-connect-routes.js';
 import { OrgIntegrationManager } from './org-integrations.js';
@@ -92,9 +167,12 @@ import { MessagingPoller } from './messaging-poller.js';
 import { TaskQueueManager } from './task-queue.js';
 import { createTaskQueueRoutes } from './task-queue-routes.js';
 import type { DatabaseAdapter } from '../db/adapter.js';
+import { handleAppError } from '../middleware/index.js';
 
 const engine = new Hono<AppEnv>();
 let _engineApp: Hono<AppEnv> = engine;
+// Same JSON error shape (with request ID, no internals) as the main app
+engine.onError(handleAppError);
 
 // Forward declarations (set later via setEngineDb)
 let _engineDb: import('./db-adapter.js').EngineDatabase | null = null;
@@ -124,9 +202,24 @@ const guardrails = new GuardrailEngine({
   stopAgent: async (agentId, by, reason) => { await lifecycle.stop(agentId, by, reason); },
 });
 const journal = new ActionJournal();
+const drafts = new DraftStore();
+const followUps = new FollowUpStore();
+const messageLabels = new MessageLabelStore();
+const qaReviews = new QAReviewQueue();
+const takeovers = new ConversationTakeoverManager();
+const workQueue = new WorkQueue({ approvals, qaReviews, takeovers, lifecycle });
+// Routing rules label new messages as they are recorded; a sample of agent replies goes to QA
+commBus.onAnyMessage((msg) => {
+  messageLabels.applyRules(msg).catch(() => {});
+  qaReviews.maybeSample(msg).catch(() => {});
+});
 const compliance = new ComplianceReporter();
 const communityRegistry = new CommunitySkillRegistry({ permissions: permissionEngine });
 const workforce = new WorkforceManager({ lifecycle, guardrails });
+const changeCalendar = new ChangeCalendar({ workforce, compliance, commBus });
+const retention = new RetentionManager();
+const messageSearch = new MessageSearch();
+const ediscovery = new CaseManager();
 const policyEngine = new OrgPolicyEngine();
 const memoryManager = new AgentMemoryManager();
 const onboarding = new OnboardingManager({ policyEngine, memoryManager });
@@ -134,6 +227,12 @@ const vault = new SecureVault();
 const orgIntegrations = new OrgIntegrationManager();
 orgIntegrations.setVault(vault);
 const storageManager = new StorageManager({ vault });
+const dataEncryption = new DataEncryption({ vault });
+const userPreferences = new UserPreferences();
+const classifier = new DataClassifier({ kbIdsForAgent: (agentId) => knowledgeBase.listIdsForAgent(agentId) });
+// DLP and the egress filter look agents up through this
+setAgentClassificationResolver((agentId) => classifier.effectiveLevel(agentId));
+const storageUsage = new StorageUsage({ getAdminDb: () => _adminDb });
 const policyImporter = new PolicyImporter({ policyEngine, storageManager });
 const knowledgeContribution = new KnowledgeContributionManager({ memoryCallback: async (agentId: string) => memoryManager.queryMemories({ agentId }) });
 
@@ -178,6 +277,18 @@ lifecycle.onEvent((event) => {
 // Wire status tracker into lifecycle so health checks push to SSE
 lifecycle.setStatusTracker(agentStatus);
 
+// Pins new agents to a residency region and keeps deploys inside it
+const dataResidency = new DataResidency({ lifecycle, storage: storageManager, getAdminDb: () => _adminDb });
+lifecycle.setRegionRouter(dataResidency.route);
+
+// Disclosure footers and consent notices on outbound agent email (applied in the runtime hooks)
+const aiDisclosure = new AIDisclosureManager({
+  agentInfo: (agentId) => {
+    const a = lifecycle.getAgent(agentId);
+    return a ? { name: a.config?.identity?.name || a.config?.displayName || a.name || 'AI agent', email: a.config?.email?.address, orgId: a.orgId } : null;
+  },
+});
+
 // Wire lifecycle into communication bus for agent email registry
 commBus.setLifecycle(lifecycle);
 
@@ -239,18 +350,60 @@ engine.use('*', async (c, next) => {
   await next();
 });
 
+/**
+ * Agents the caller may see, or null when they aren't limited to their teams
+ * (teams is set up below). The role comes from the proxy-set headers only.
+ */
+function visibleToCaller(c: any): Promise<Set<string> | null> {
+  return teams.visibleAgentIds({ id: c.req.header('X-User-Id'), role: callerRole(c) });
+}
+
+// Members and viewers in a team can't open other agents' detail routes
+// (config, usage, logs, conversations...)
+const requireVisibleAgent = async (c: any, next: any) => {
+  const visible = await visibleToCaller(c);
+  if (visible && !visible.has(c.req.param('agentId'))) return c.json({ error: 'Agent not found' }, 404);
+  return next();
+};
+engine.use('/agents/:agentId', requireVisibleAgent);
+engine.use('/agents/:agentId/*', requireVisibleAgent);
+engine.use('/transcripts/agent/:agentId', requireVisibleAgent);
+engine.use('/logs/agent/:agentId/*', requireVisibleAgent);
+engine.use('/agent-webhooks/agents/:agentId', requireVisibleAgent);
+engine.use('/agent-webhooks/agents/:agentId/*', requireVisibleAgent);
+
 // ─── Mount Sub-Apps ─────────────────────────────────────
 
-engine.route('/dlp', createDlpRoutes(dlp));
+engine.route('/dlp', createDlpRoutes(dlp, { getAdminDb: () => _adminDb }));
 engine.route('/guardrails', createGuardrailRoutes(guardrails, {
   getWorkforceOffDuty: (agentId) => workforce.isOffDuty(agentId),
 }));
 engine.route('/anomaly-rules', createAnomalyRoutes(guardrails));
 engine.route('/journal', createJournalRoutes(journal));
-engine.route('/messages', createCommunicationRoutes(commBus));
+engine.route('/drafts', createDraftRoutes(drafts));
+engine.route('/follow-ups', createFollowUpRoutes(followUps, commBus));
+engine.route('/message-labels', createMessageLabelRoutes(messageLabels, commBus));
+engine.route('/qa', createQARoutes(qaReviews, commBus));
+engine.route('/takeovers', createTakeoverRoutes(takeovers, {
+  getMessagingPoller: () => _messagingPoller,
+  getAdminDb: () => _adminDb,
+  getEngineDb: () => _engineDb,
+}));
+engine.route('/work-queue', createWorkQueueRoutes(workQueue, { getAdminDb: () => _adminDb }));
+engine.route('/messages', createCommunicationRoutes(commBus, () => _adminDb, {
+  // Members and viewers in a team only see their teams' traffic
+  visibleAgentIds: visibleToCaller,
+}));
 engine.route('/tasks', createTaskRoutes(commBus));
 engine.route('/task-pipeline', createTaskQueueRoutes(taskQueue));
-engine.route('/compliance', createComplianceRoutes(compliance));
+engine.route('/compliance', createComplianceRoutes(compliance, { getAdminDb: () => _adminDb }));
+engine.route('/change-calendar', createChangeCalendarRoutes(changeCalendar, { getAdminDb: () => _adminDb }));
+engine.route('/retention', createRetentionRoutes(retention, { getAdminDb: () => _adminDb }));
+engine.route('/message-search', createMessageSearchRoutes(messageSearch, {
+  getAdminDb: () => _adminDb,
+  visibleAgentIds: visibleToCaller,
+}));
+engine.route('/ediscovery', createEDiscoveryRoutes(ediscovery, { retention, messageSearch, getAdminDb: () => _adminDb }));
 
 engine.route('/', createCatalogRoutes({
   skills: BUILTIN_SKILLS,
@@ -515,11 +668,16 @@ engine.get('/cluster/stream', (c) => {
 engine.route('/community', createCommunityRoutes(communityRegistry));
 engine.route('/workforce', createWorkforceRoutes(workforce, { lifecycle }));
 engine.route('/policies', createPolicyRoutes(policyEngine));
-engine.route('/memory', createMemoryRoutes(memoryManager));
+engine.route('/memory', createMemoryRoutes(memoryManager, { getAdminDb: () => _adminDb }));
 engine.route('/memory-transfer', createMemoryTransferRoutes(memoryManager, _engineDb));
 engine.route('/onboarding', createOnboardingRoutes(onboarding));
-engine.route('/vault', createVaultRoutes(vault, dlp));
-engine.route('/storage', createStorageRoutes(storageManager));
+engine.route('/vault', createVaultRoutes(vault, dlp, { permissions: permissionEngine, lifecycle, getAdminDb: () => _adminDb }));
+engine.route('/security', createSecurityPostureRoutes(dataEncryption, { getAdminDb: () => _adminDb }));
+engine.route('/preferences', createUserPreferenceRoutes(userPreferences));
+engine.route('/classifications', createClassificationRoutes(classifier, { getAdminDb: () => _adminDb }));
+engine.route('/residency', createDataResidencyRoutes(dataResidency, { getAdminDb: () => _adminDb }));
+engine.route('/disclosure', createAIDisclosureRoutes(aiDisclosure, { getAdminDb: () => _adminDb }));
+engine.route('/storage', createStorageRoutes(storageManager, storageUsage, { retention, getAdminDb: () => _adminDb }));
 engine.route('/policies', createPolicyImportRoutes(policyImporter));
 engine.route('/knowledge-contribution', createKnowledgeContributionRoutes(knowledgeContribution, { lifecycle }));
 engine.route('/knowledge-import', createKnowledgeImportRoutes(knowledgeImport));
@@ -532,6 +690,66 @@ import { DatabaseConnectionManager, createDatabaseAccessRoutes } from '../databa
 const databaseManager = new DatabaseConnectionManager({ vault });
 engine.route('/database', createDatabaseAccessRoutes(databaseManager, lifecycle));
 
+// Agent identity lifecycle + offboarding
+const agentOffboarding = new AgentOffboarding({ lifecycle, vault, storage: storageManager, databaseManager, getAdminDb: () => _adminDb });
+engine.route('/agent-lifecycle', createAgentOffboardingRoutes(agentOffboarding, { getAdminDb: () => _adminDb }));
+
+// Duplicate and orphan detection
+const housekeeping = new Housekeeping({ lifecycle, permissions: permissionEngine, vault, community: communityRegistry, getAdminDb: () => _adminDb });
+engine.route('/housekeeping', createHousekeepingRoutes(housekeeping, { getAdminDb: () => _adminDb }));
+
+// Customer-facing Trust page
+const trustCenter = new TrustCenter({ encryption: dataEncryption, dlp, retention, residency: dataResidency, compliance, getAdminDb: () => _adminDb });
+engine.route('/trust', createTrustCenterRoutes(trustCenter, { getAdminDb: () => _adminDb }));
+
+// Dashboard "Needs attention" feed
+const attentionFeed = new AttentionFeed({ guardrails, dlp, approvals, lifecycle, getAdminDb: () => _adminDb });
+engine.route('/attention', createAttentionRoutes(attentionFeed));
+
+// Per-agent engine log tail for the dashboard
+const logTail = new LogTail();
+logTail.install();
+engine.route('/logs', createLogTailRoutes(logTail, lifecycle));
+
+// Agent conversation transcripts for debugging
+const transcripts = new TranscriptReader();
+engine.route('/transcripts', createTranscriptRoutes(transcripts, { getAdminDb: () => _adminDb, visibleAgentIds: visibleToCaller }));
+
+// Agent teams / departments
+const teams = new TeamManager();
+engine.route('/teams', createTeamRoutes(teams, { getAdminDb: () => _adminDb }));
+lifecycle.onEvent((event) => {
+  if (event.type === 'destroyed') teams.removeAgent(event.agentId).catch(() => {});
+});
+
+// Agent avatars, stored on the backend instead of inline in the config
+const avatars = new AvatarStore();
+engine.route('/avatars', createAvatarRoutes(avatars, lifecycle, { getAdminDb: () => _adminDb }));
+lifecycle.onEvent((event) => {
+  if (event.type === 'destroyed') avatars.remove(event.agentId).catch(() => {});
+});
+
+// Per-agent webhooks for inbound messages, errors and interventions
+const agentWebhooks = new AgentWebhookManager();
+engine.route('/agent-webhooks', createAgentWebhookRoutes(agentWebhooks, lifecycle, { getAdminDb: () => _adminDb, visibleAgentIds: visibleToCaller }));
+function emitAgentWebhook(agentId: string, event: WebhookEvent, data: Record<string, any>): void {
+  const agent = lifecycle.getAgent(agentId);
+  if (!agent) return;
+  agentWebhooks.emit({ id: agent.id, orgId: agent.orgId, name: (agent.config as any)?.displayName || agent.name }, event, data)
+    .catch((err) => console.error(`[webhooks] Failed to emit ${event} for ${agentId}:`, err.message));
+}
+lifecycle.onEvent((event) => {
+  if (event.type === 'destroyed') agentWebhooks.removeAgent(event.agentId).catch(() => {});
+  else if (event.type === 'error' || (event.type as string) === 'crashed') emitAgentWebhook(event.agentId, 'agent.error', { type: event.type, ...event.data });
+});
+guardrails.onIntervention((record) => {
+  emitAgentWebhook(record.agentId, 'agent.intervention', { interventionId: record.id, type: record.type, reason: record.reason, triggeredBy: record.triggeredBy, metadata: record.metadata });
+});
+// Inbound messages from the pollers; the text is cut down to a preview
+const onPolledMessage = (agentId: string, message: { channel: string; from: string; subject?: string; text: string; threadId?: string }) => {
+  emitAgentWebhook(agentId, 'message.received', { ...message, text: (message.text || '').slice(0, 1000) });
+};
+
 // ─── Hierarchy / Management API ─────────────────────────
 engine.get('/hierarchy/org-chart', async (c) => {
   if (!hierarchyManager) return c.json({ error: 'Hierarchy not initialized' }, 503);
@@ -974,22 +1192,45 @@ export async function setEngineDb(
     commBus.setDb(db),
     guardrails.setDb(db),
     journal.setDb(db),
+    drafts.setDb(db),
+    followUps.setDb(db),
+    messageLabels.setDb(db),
+    qaReviews.setDb(db),
+    takeovers.setDb(db),
+    workQueue.setDb(db),
     compliance.setDb(db),
     communityRegistry.setDb(db),
     knowledgeContribution.setDb(db),
     (async () => { knowledgeImport.setDb((db as any)?.db || db); knowledgeImport.setKnowledgeEngine(knowledgeBase); await knowledgeImport.loadJobs(); })(),
     workforce.setDb(db),
+    changeCalendar.setDb(db),
+    retention.setDb(db),
+    messageSearch.setDb(db),
+    ediscovery.setDb(db),
     policyEngine.setDb(db),
     (async () => { cluster.setDb(db); await cluster.loadFromDb(); })(),
     memoryManager.setDb(db),
     onboarding.setDb(db),
     vault.setDb(db),
     agentStatus.setDb(db),
+    transcripts.setDb(db),
+    teams.setDb(db),
+    avatars.setDb(db),
+    agentWebhooks.setDb(db),
     (async () => { orgIntegrations.setDb(db); orgIntegrations.setLifecycle(lifecycle); (globalThis as any).__orgIntegrations = orgIntegrations; })(),
     storageManager.setDb(db),
+    storageUsage.setDb(db),
+    dataEncryption.setDb(db),
+    userPreferences.setDb(db),
+    classifier.setDb(db),
+    dataResidency.setDb(db),
+    aiDisclosure.setDb(db),
     policyImporter.setDb(db),
     (async () => { (taskQueue as any).db = (db as any)?.db || db; await taskQueue.init(); })(),
     databaseManager.setDb(db),
+    agentOffboarding.setDb(db),
+    housekeeping.setDb(db),
+    trustCenter.setDb(db),
   ]);
   // Initialize hierarchy manager + start background task monitor
   hierarchyManager = new AgentHierarchyManager(db);
@@ -1171,6 +1412,7 @@ async function startChatPoller(engineDb: any): Promise<void> {
     agents: agentEndpoints,
     intervalMs: 30_000,
     workforce,
+    onMessage: onPolledMessage,
   });
 
   await _chatPoller.start();
@@ -1190,6 +1432,7 @@ async function startEmailPoller(engineDb: any): Promise<void> {
     lifecycle,
     intervalMs: 30_000,
     workforce,
+    onMessage: onPolledMessage,
   });
 
   await _emailPoller.start();
@@ -1249,6 +1492,9 @@ async function startMessagingPoller(engineDb: any): Promise<void> {
     publicUrl,
     app: (_engineApp || undefined) as any,
     engineDb,
+    isTakenOver: (agentId, platform, contactId) => takeovers.isActive(agentId, platform, contactId),
+    getMaintenance: (agentId) => workforce.getActiveMaintenance(agentId),
+    onMessage: onPolledMessage,
     lifecycle: { getAgent: (id: string) => lifecycle.getAgent(id) },
     getCapability: (key: string) => !!capabilities[key],
     getAgentChannelConfig: (agentId: string) => {
@@ -1290,4 +1536,4 @@ export function getRuntime(): any | null {
 }
 
 export { engine as engineRoutes };
-export { permissionEngine, configGen, deployer, approvals, lifecycle, knowledgeBase, tenants, activity, dlp, commBus, guardrails, journal, compliance, communityRegistry, workforce, policyEngine, memoryManager, onboarding, vault, storageManager, policyImporter, knowledgeContribution, skillUpdater, agentStatus, hierarchyManager, databaseManager, orgIntegrations, cluster };
+export { permissionEngine, configGen, deployer, approvals, lifecycle, knowledgeBase, tenants, activity, dlp, commBus, guardrails, journal, compliance, communityRegistry, workforce, policyEngine, memoryManager, onboarding, vault, storageManager, policyImporter, knowledgeContribution, skillUpdater, agentStatus, hierarchyManager, databaseManager, orgIntegrations, cluster, retention, aiDisclosure, trustCenter };
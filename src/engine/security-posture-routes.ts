@@ -0,0 +1,36 @@
+/**
+ * Security Posture Routes
+ * Mounted at /security/* on the engine sub-app.
+ *
+ * The encryption policy itself is saved with the rest of securityConfig
+ * through the admin /settings/security route; these only report.
+ */
+
+import { Hono } from 'hono';
+import type { DataEncryption } from './data-encryption.js';
+import type { DatabaseAdapter } from '../db/adapter.js';
+import { computeSecurityPosture } from '../security/posture.js';
+
+export function createSecurityPostureRoutes(encryption: DataEncryption, deps: { getAdminDb: () => DatabaseAdapter | null }) {
+  const router = new Hono();
+
+  const securityConfig = async () => ((await deps.getAdminDb()?.getSettings().catch(() => null)) as any)?.securityConfig || {};
+
+  // Which stored fields are encrypted, and whether the policy is met
+  router.get('/encryption', async (c) => {
+    try {
+      const cfg = await securityConfig();
+      return c.json(await encryption.getReport(cfg.encryptionPolicy));
+    } catch (e: any) { return c.json({ error: e.message }, 500); }
+  });
+
+  router.get('/posture', async (c) => {
+    try {
+      const cfg = await securityConfig();
+      const report = await encryption.getReport(cfg.encryptionPolicy).catch(() => null);
+      return c.json({ posture: computeSecurityPosture(cfg, report) });
+    } catch (e: any) { return c.json({ error: e.message }, 500); }
+  });
+
+  return router;
+}
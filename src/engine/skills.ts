@@ -193,6 +193,12 @@ export interface AgentPermissionProfile {
     autoCleanup: boolean;
   };
 
+  // Vault secrets this agent may read, granted on the agent's Credentials tab.
+  // Secrets the agent owns (metadata.agentId or an "agent:<id>:" name) need no grant.
+  vaultAccess?: {
+    secretIds: string[];
+  };
+
   createdAt: string;
   updatedAt: string;
 }
@@ -653,6 +659,11 @@ export class PermissionEngine {
     return this.profiles.get(agentId);
   }
 
+  /** Whether a vault secret has been granted to this agent. */
+  canReadSecret(agentId: string, secretId: string): boolean {
+    return !!this.profiles.get(agentId)?.vaultAccess?.secretIds?.includes(secretId);
+  }
+
   /**
    * Core permission check: Can this agent use this tool right now?
    * Returns { allowed, reason, requiresApproval }
@@ -6,6 +6,7 @@
  * Caches active storage providers per org.
  */
 
+import { gzipSync } from 'zlib';
 import type { EngineDatabase } from './db-adapter.js';
 import type { SecureVault } from './vault.js';
 import { createStorageProvider, type StorageConfig, type StorageProvider } from './storage.js';
@@ -38,6 +39,24 @@ export interface StorageObjectRecord {
   createdAt: string;
 }
 
+export interface AttachmentFilter {
+  agentId?: string;
+  /** Matches created_by */
+  sender?: string;
+  minSize?: number;
+  olderThanDays?: number;
+  includeArchived?: boolean;
+  sort?: 'size' | 'age';
+  limit?: number;
+  offset?: number;
+}
+
+export interface AttachmentRecord extends StorageObjectRecord {
+  /** The agent the file belongs to, when related to one */
+  agentId?: string;
+  archived: boolean;
+}
+
 // ─── Storage Manager ────────────────────────────────────
 
 export class StorageManager {
@@ -238,6 +257,74 @@ export class StorageManager {
     }));
   }
 
+  // ─── Attachment Cleanup ──────────────────────────────
+
+  /** Stored objects, largest or oldest first, for the attachment browser. */
+  async browseAttachments(orgId: string, filter: AttachmentFilter = {}): Promise<{ attachments: AttachmentRecord[]; total: number; totalBytes: number }> {
+    if (!this.engineDb) return { attachments: [], total: 0, totalBytes: 0 };
+    let where = 'org_id = ?';
+    const params: any[] = [orgId];
+    if (filter.agentId) { where += " AND related_type = 'agent' AND related_id = ?"; params.push(filter.agentId); }
+    if (filter.sender) { where += ' AND created_by = ?'; params.push(filter.sender); }
+    if (filter.minSize) { where += ' AND size >= ?'; params.push(filter.minSize); }
+    if (filter.olderThanDays) { where += ' AND created_at < ?'; params.push(new Date(Date.now() - filter.olderThanDays * 86_400_000).toISOString()); }
+    if (!filter.includeArchived) { where += " AND storage_key NOT LIKE '%/archive/%'"; }
+
+    const totals = await this.engineDb.get<any>(`SELECT COUNT(*) AS c, SUM(size) AS b FROM storage_objects WHERE ${where}`, params);
+    const order = filter.sort === 'age' ? 'created_at ASC' : 'size DESC, created_at ASC';
+    const rows = await this.engineDb.query<any>(
+      `SELECT * FROM storage_objects WHERE ${where} ORDER BY ${order} LIMIT ? OFFSET ?`,
+      [...params, Math.min(filter.limit || 50, 500), filter.offset || 0]
+    );
+    return {
+      attachments: rows.map((r: any) => {
+        const rec = this.rowToObject(r);
+        return { ...rec, agentId: rec.relatedType === 'agent' ? rec.relatedId : undefined, archived: !!rec.metadata.archived };
+      }),
+      total: Number(totals?.c || 0),
+      totalBytes: Number(totals?.b || 0),
+    };
+  }
+
+  async getObjects(orgId: string, ids: string[]): Promise<StorageObjectRecord[]> {
+    if (!this.engineDb || ids.length === 0) return [];
+    const rows = await this.engineDb.query<any>(
+      `SELECT * FROM storage_objects WHERE org_id = ? AND id IN (${ids.map(() => '?').join(', ')})`, [orgId, ...ids]
+    );
+    return rows.map((r: any) => this.rowToObject(r));
+  }
+
+  /**
+   * Gzips an object into the org's archive/ prefix and drops the original.
+   * The record keeps its id; the original key and size go into metadata.archived.
+   * Returns the bytes reclaimed.
+   */
+  async archiveDocument(orgId: string, obj: StorageObjectRecord): Promise<number> {
+    if (obj.metadata.archived) return 0;
+    const provider = await this.getProvider(orgId);
+    const packed = gzipSync(await provider.download(obj.storageKey));
+    const key = `${orgId}/archive/${obj.storageKey.split('/').pop()}.gz`;
+    await provider.upload(key, packed, { contentType: 'application/gzip' });
+    await provider.delete(obj.storageKey);
+    const metadata = { ...obj.metadata, archived: { at: new Date().toISOString(), originalKey: obj.storageKey, originalSize: obj.size, contentType: obj.contentType } };
+    if (this.engineDb) {
+      await this.engineDb.execute(
+        'UPDATE storage_objects SET storage_key = ?, size = ?, content_type = ?, metadata = ? WHERE id = ?',
+        [key, packed.length, 'application/gzip', JSON.stringify(metadata), obj.id]
+      );
+    }
+    return Math.max(0, obj.size - packed.length);
+  }
+
+  private rowToObject(r: any): StorageObjectRecord {
+    return {
+      id: r.id, orgId: r.org_id, storageKey: r.storage_key, originalName: r.original_name,
+      contentType: r.content_type, size: Number(r.size), relatedType: r.related_type || undefined,
+      relatedId: r.related_id || undefined, metadata: typeof r.metadata === 'string' ? JSON.parse(r.metadata || '{}') : (r.metadata || {}),
+      createdBy: r.created_by, createdAt: r.created_at,
+    };
+  }
+
   // ─── Health Check ────────────────────────────────────
 
   async healthCheck(orgId: string): Promise<{ healthy: boolean; type: string; error?: string }> {
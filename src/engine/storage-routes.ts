@@ -1,13 +1,92 @@
 /**
  * Cloud Storage Routes
  * Mounted at /storage/* on the engine sub-app.
+ *
+ * Bulk attachment cleanup skips anything belonging to an agent on legal
+ * hold and writes each batch to the admin audit log.
  */
 
 import { Hono } from 'hono';
 import type { StorageManager } from './storage-manager.js';
+import type { StorageUsage } from './storage-usage.js';
+import type { RetentionManager } from './retention.js';
+import type { DatabaseAdapter } from '../db/adapter.js';
+import { auditFromEngine } from './route-audit.js';
 
-export function createStorageRoutes(storage: StorageManager) {
+export function createStorageRoutes(storage: StorageManager, usage: StorageUsage, deps: { retention: RetentionManager; getAdminDb: () => DatabaseAdapter | null }) {
   const router = new Hono();
+  const audit = auditFromEngine(deps.getAdminDb);
+
+  const heldAgentIds = async (orgId: string) => new Set((await deps.retention.getHolds(orgId)).map(h => h.agentId));
+
+  // Bytes and rows by category, per agent, with a 30-day trend
+  router.get('/usage', async (c) => {
+    try {
+      const orgId = c.req.query('orgId') || '';
+      if (!orgId) return c.json({ error: 'orgId required' }, 400);
+      return c.json(await usage.getUsage(orgId));
+    } catch (e: any) { return c.json({ error: e.message }, 500); }
+  });
+
+  // Large/old attachments across agents. Filters: agentId, sender, minSize (bytes), olderThanDays, includeArchived, sort=size|age
+  router.get('/attachments', async (c) => {
+    try {
+      const orgId = c.req.query('orgId') || '';
+      if (!orgId) return c.json({ error: 'orgId required' }, 400);
+      const q = (k: string) => c.req.query(k) || undefined;
+      const result = await storage.browseAttachments(orgId, {
+        agentId: q('agentId'),
+        sender: q('sender'),
+        minSize: q('minSize') ? Number(q('minSize')) : undefined,
+        olderThanDays: q('olderThanDays') ? Number(q('olderThanDays')) : undefined,
+        includeArchived: q('includeArchived') === 'true',
+        sort: q('sort') === 'age' ? 'age' : 'size',
+        limit: parseInt(q('limit') || '50'),
+        offset: parseInt(q('offset') || '0'),
+      });
+      const held = await heldAgentIds(orgId);
+      return c.json({
+        ...result,
+        attachments: result.attachments.map(a => ({ ...a, held: !!(a.agentId && held.has(a.agentId)) || held.has(a.createdBy) })),
+      });
+    } catch (e: any) { return c.json({ error: e.message }, 500); }
+  });
+
+  // { orgId, ids, action: 'delete' | 'archive' } — held attachments are skipped, not failed
+  router.post('/attachments/bulk', async (c) => {
+    try {
+      const body = await c.req.json().catch(() => ({}));
+      if (!body.orgId) return c.json({ error: 'orgId required' }, 400);
+      if (body.action !== 'delete' && body.action !== 'archive') return c.json({ error: "action must be 'delete' or 'archive'" }, 400);
+      const ids: string[] = Array.isArray(body.ids) ? body.ids.slice(0, 500) : [];
+      if (ids.length === 0) return c.json({ error: 'ids required' }, 400);
+
+      const held = await heldAgentIds(body.orgId);
+      const objects = await storage.getObjects(body.orgId, ids);
+      const done: string[] = [];
+      const skipped: Array<{ id: string; reason: string }> = ids.filter(id => !objects.some(o => o.id === id)).map(id => ({ id, reason: 'not found' }));
+      let reclaimedBytes = 0;
+      for (const obj of objects) {
+        const agentId = obj.relatedType === 'agent' ? obj.relatedId : undefined;
+        if ((agentId && held.has(agentId)) || held.has(obj.createdBy)) { skipped.push({ id: obj.id, reason: 'legal hold' }); continue; }
+        try {
+          if (body.action === 'delete') {
+            if (!await storage.deleteDocument(body.orgId, obj.storageKey)) { skipped.push({ id: obj.id, reason: 'delete failed' }); continue; }
+            reclaimedBytes += obj.size;
+          } else {
+            reclaimedBytes += await storage.archiveDocument(body.orgId, obj);
+          }
+          done.push(obj.id);
+        } catch (e: any) {
+          skipped.push({ id: obj.id, reason: e.message });
+        }
+      }
+
+      audit(c, `storage.attachments_${body.action}`, `org:${body.orgId}`,
+        { count: done.length, reclaimedBytes, skipped: skipped.length, ids: done }, body.orgId);
+      return c.json({ done, skipped, reclaimedBytes });
+    } catch (e: any) { return c.json({ error: e.message }, 500); }
+  });
 
   // Configure storage provider for an org
   router.post('/configure', async (c) => {
@@ -0,0 +1,188 @@
+/**
+ * Storage Usage — How much each kind of data takes up, per org and agent
+ *
+ * Sizes are measured from the database rather than the disk, so they are
+ * the payload bytes each category holds (message bodies, attachment sizes,
+ * journal payloads, knowledge documents), not including indexes or
+ * overhead. Audit events live in the admin database and are counted only.
+ *
+ * Every read of the usage records a daily snapshot per category, which is
+ * what the trend line is drawn from. There is no background job, so days
+ * nobody looked at the page have no point.
+ */
+
+import type { EngineDatabase } from './db-adapter.js';
+import type { DatabaseAdapter } from '../db/adapter.js';
+
+// ─── Types ──────────────────────────────────────────────
+
+export type StorageCategory = 'messages' | 'attachments' | 'journal' | 'knowledge' | 'activity' | 'audit';
+
+export interface StorageCategoryUsage {
+  key: StorageCategory;
+  label: string;
+  /** Null when the size can't be measured (audit) */
+  bytes: number | null;
+  rows: number;
+  /** Dashboard page with the cleanup tools for this category */
+  cleanupPage?: string;
+}
+
+export interface StorageAgentUsage {
+  agentId: string;
+  name: string;
+  total: number;
+  bytes: Partial<Record<StorageCategory, number>>;
+}
+
+export interface StorageUsageReport {
+  orgId: string;
+  generatedAt: string;
+  totalBytes: number;
+  categories: StorageCategoryUsage[];
+  agents: StorageAgentUsage[];
+  trend: Array<{ day: string; total: number; bytes: Partial<Record<StorageCategory, number>> }>;
+}
+
+// ─── Config ─────────────────────────────────────────────
+
+/** One query per source. `agent` is the column that attributes a row to an agent, if any. */
+interface UsageSource {
+  category: StorageCategory;
+  table: string;
+  bytes: string;
+  /** Restricts to the org; `?` is bound to the orgId */
+  scope: string;
+  agent?: string;
+}
+
+const ORG_AGENTS = 'agent_id IN (SELECT id FROM managed_agents WHERE org_id = ?)';
+
+/** JSON columns need a cast before LENGTH(); MySQL spells the text type CHAR */
+function usageSources(textType: 'TEXT' | 'CHAR'): UsageSource[] {
+  const T = (col: string) => `COALESCE(LENGTH(CAST(${col} AS ${textType})), 0)`;
+  return [
+    // Internal messages count against the recipient's inbox
+    { category: 'messages', table: 'agent_messages', bytes: `${T('subject')} + ${T('content')} + ${T('metadata')}`, scope: 'org_id = ?', agent: 'to_agent_id' },
+    { category: 'messages', table: 'messaging_history', bytes: `${T('message_text')} + ${T('metadata')}`, scope: ORG_AGENTS, agent: 'agent_id' },
+    { category: 'attachments', table: 'storage_objects', bytes: 'size', scope: 'org_id = ?', agent: "CASE WHEN related_type = 'agent' THEN related_id END" },
+    { category: 'journal', table: 'action_journal', bytes: `${T('forward_data')} + ${T('reverse_data')}`, scope: 'org_id = ?', agent: 'agent_id' },
+    { category: 'knowledge', table: 'kb_documents', bytes: 'size', scope: 'knowledge_base_id IN (SELECT id FROM knowledge_bases WHERE org_id = ?)' },
+    { category: 'activity', table: 'tool_calls', bytes: `${T('parameters')} + ${T('result')}`, scope: 'org_id = ?', agent: 'agent_id' },
+    { category: 'activity', table: 'activity_events', bytes: T('data'), scope: 'org_id = ?', agent: 'agent_id' },
+    { category: 'activity', table: 'conversations', bytes: `${T('content')} + ${T('tool_calls')}`, scope: ORG_AGENTS, agent: 'agent_id' },
+  ];
+}
+
+export const STORAGE_CATEGORIES: Array<{ key: StorageCategory; label: string; cleanupPage?: string }> = [
+  { key: 'messages', label: 'Messages', cleanupPage: 'messages' },
+  { key: 'attachments', label: 'Attachments', cleanupPage: 'attachments' },
+  { key: 'journal', label: 'Journal', cleanupPage: 'journal' },
+  { key: 'knowledge', label: 'Knowledge bases', cleanupPage: 'knowledge' },
+  { key: 'activity', label: 'Activity & conversations', cleanupPage: 'settings' },
+  { key: 'audit', label: 'Audit log', cleanupPage: 'audit' },
+];
+
+const TREND_DAYS = 30;
+
+// ─── Storage Usage ──────────────────────────────────────
+
+export class StorageUsage {
+  private engineDb?: EngineDatabase;
+  private textType?: 'TEXT' | 'CHAR';
+
+  constructor(private deps: { getAdminDb: () => DatabaseAdapter | null }) {}
+
+  async setDb(db: EngineDatabase): Promise<void> {
+    this.engineDb = db;
+  }
+
+  async getUsage(orgId: string): Promise<StorageUsageReport> {
+    if (!this.engineDb) throw new Error('Storage usage database not initialized');
+    const db = this.engineDb;
+    const totals = new Map<StorageCategory, { bytes: number; rows: number }>();
+    const perAgent = new Map<string, Partial<Record<StorageCategory, number>>>();
+    if (!this.textType) {
+      this.textType = await db.get<any>("SELECT LENGTH(CAST('x' AS TEXT)) AS n", []).then(() => 'TEXT' as const, () => 'CHAR' as const);
+    }
+
+    for (const src of usageSources(this.textType)) {
+      // A table missing on this backend shouldn't hide the rest of the report
+      const row = await db.get<any>(
+        `SELECT COUNT(*) AS c, SUM(${src.bytes}) AS b FROM ${src.table} WHERE ${src.scope}`, [orgId]
+      ).catch(() => undefined);
+      const t = totals.get(src.category) || { bytes: 0, rows: 0 };
+      t.bytes += Number(row?.b || 0);
+      t.rows += Number(row?.c || 0);
+      totals.set(src.category, t);
+
+      if (!src.agent || !row?.c) continue;
+      const rows = await db.query<any>(
+        `SELECT ${src.agent} AS agent_id, SUM(${src.bytes}) AS b FROM ${src.table} WHERE ${src.scope} GROUP BY ${src.agent}`, [orgId]
+      ).catch(() => []);
+      for (const r of rows) {
+        if (!r.agent_id) continue;
+        const a = perAgent.get(r.agent_id) || {};
+        a[src.category] = (a[src.category] || 0) + Number(r.b || 0);
+        perAgent.set(r.agent_id, a);
+      }
+    }
+
+    const adminDb = this.deps.getAdminDb();
+    const auditRows = adminDb ? (await adminDb.queryAudit({ orgId, limit: 1 }).catch(() => null))?.total || 0 : 0;
+
+    const categories: StorageCategoryUsage[] = STORAGE_CATEGORIES.map(c => c.key === 'audit'
+      ? { ...c, bytes: null, rows: auditRows }
+      : { ...c, bytes: totals.get(c.key)?.bytes || 0, rows: totals.get(c.key)?.rows || 0 });
+
+    const names = new Map<string, string>(
+      (await db.query<any>('SELECT id, display_name, name FROM managed_agents WHERE org_id = ?', [orgId]).catch(() => []))
+        .map((r: any) => [r.id, r.display_name || r.name || r.id])
+    );
+    const agents: StorageAgentUsage[] = [...perAgent.entries()]
+      .map(([agentId, bytes]) => ({ agentId, name: names.get(agentId) || agentId, bytes, total: Object.values(bytes).reduce((s, n) => s + (n || 0), 0) }))
+      .sort((a, b) => b.total - a.total);
+
+    await this.recordSnapshot(orgId, categories);
+
+    return {
+      orgId,
+      generatedAt: new Date().toISOString(),
+      totalBytes: categories.reduce((s, c) => s + (c.bytes || 0), 0),
+      categories,
+      agents,
+      trend: await this.getTrend(orgId),
+    };
+  }
+
+  private async recordSnapshot(orgId: string, categories: StorageCategoryUsage[]): Promise<void> {
+    const db = this.engineDb!;
+    const day = new Date().toISOString().slice(0, 10);
+    try {
+      await db.execute('DELETE FROM storage_usage_snapshots WHERE org_id = ? AND day = ?', [orgId, day]);
+      for (const c of categories) {
+        if (c.bytes === null) continue;
+        await db.execute(
+          'INSERT INTO storage_usage_snapshots (org_id, day, category, bytes, row_count) VALUES (?, ?, ?, ?, ?)',
+          [orgId, day, c.key, c.bytes, c.rows]
+        );
+      }
+    } catch { /* the trend is best-effort */ }
+  }
+
+  private async getTrend(orgId: string): Promise<StorageUsageReport['trend']> {
+    const since = new Date(Date.now() - TREND_DAYS * 86_400_000).toISOString().slice(0, 10);
+    const rows = await this.engineDb!.query<any>(
+      'SELECT day, category, bytes FROM storage_usage_snapshots WHERE org_id = ? AND day >= ? ORDER BY day', [orgId, since]
+    ).catch(() => []);
+    const byDay = new Map<string, Partial<Record<StorageCategory, number>>>();
+    for (const r of rows) {
+      const d = byDay.get(r.day) || {};
+      d[r.category as StorageCategory] = Number(r.bytes);
+      byDay.set(r.day, d);
+    }
+    return [...byDay.entries()].map(([day, bytes]) => ({
+      day, bytes, total: Object.values(bytes).reduce((s, n) => s + (n || 0), 0),
+    }));
+  }
+}
@@ -0,0 +1,106 @@
+/**
+ * Conversation Takeover Routes
+ * Mounted at /takeovers/* on the engine sub-app.
+ *
+ * Every takeover, human reply and hand-back is written to the admin audit log.
+ */
+
+import { Hono } from 'hono';
+import type { ConversationTakeoverManager } from './conversation-takeover.js';
+import type { MessagingPoller } from './messaging-poller.js';
+import type { DatabaseAdapter } from '../db/adapter.js';
+import { storeMessage } from './messaging-history.js';
+import { auditFromEngine } from './route-audit.js';
+
+const MAX_REPLY_LENGTH = 4000;
+
+export function createTakeoverRoutes(takeovers: ConversationTakeoverManager, deps: {
+  getMessagingPoller: () => MessagingPoller | null;
+  getAdminDb: () => DatabaseAdapter | null;
+  getEngineDb: () => any;
+}) {
+  const router = new Hono();
+
+  const log = auditFromEngine(deps.getAdminDb, 'conversation');
+  const audit = (c: any, action: string, t: { id: string; agentId: string; platform: string; contactId: string; orgId?: string }, details: Record<string, any> = {}) =>
+    log(c, action, `agent:${t.agentId}/${t.platform}:${t.contactId}`, { takeoverId: t.id, ...details }, t.orgId);
+
+  // Active takeovers (?agentId=&orgId=)
+  router.get('/', (c) => {
+    return c.json({
+      takeovers: takeovers.listActive({ agentId: c.req.query('agentId') || undefined, orgId: c.req.query('orgId') || undefined }),
+    });
+  });
+
+  // State of one thread: the active takeover (if any) plus past ones
+  router.get('/thread', async (c) => {
+    const agentId = c.req.query('agentId'), platform = c.req.query('platform'), contactId = c.req.query('contactId');
+    if (!agentId || !platform || !contactId) return c.json({ error: 'agentId, platform and contactId required' }, 400);
+    return c.json({
+      active: takeovers.getActive(agentId, platform, contactId),
+      history: await takeovers.history(agentId, platform, contactId),
+    });
+  });
+
+  router.post('/', async (c) => {
+    const body = await c.req.json().catch(() => ({}));
+    if (!body.agentId || !body.platform || !body.contactId) return c.json({ error: 'agentId, platform and contactId required' }, 400);
+    const userId = c.req.header('X-User-Id');
+    if (!userId) return c.json({ error: 'X-User-Id header required' }, 401);
+    try {
+      const takeover = await takeovers.takeOver({
+        agentId: body.agentId, platform: body.platform, contactId: String(body.contactId),
+        orgId: body.orgId, reason: body.reason, takenBy: userId,
+      });
+      audit(c, 'takeover', takeover, { reason: takeover.reason });
+      return c.json({ takeover }, 201);
+    } catch (e: any) {
+      return c.json({ error: e.message }, e.message.startsWith('Conversation is already') ? 409 : 400);
+    }
+  });
+
+  // Reply to the contact as the agent
+  router.post('/:id/reply', async (c) => {
+    const body = await c.req.json().catch(() => ({}));
+    const text = String(body.text || '').trim();
+    if (!text) return c.json({ error: 'text required' }, 400);
+    if (text.length > MAX_REPLY_LENGTH) return c.json({ error: `Reply is too long (max ${MAX_REPLY_LENGTH} characters)` }, 400);
+
+    const takeover = await takeovers.get(c.req.param('id'));
+    if (!takeover || takeover.status !== 'active') return c.json({ error: 'No active takeover' }, 404);
+    const poller = deps.getMessagingPoller();
+    if (!poller) return c.json({ error: 'Messaging channels are not running' }, 503);
+
+    try {
+      await poller.sendAsAgent(takeover.agentId, takeover.platform, takeover.contactId, text);
+    } catch (e: any) {
+      return c.json({ error: e.message }, 502);
+    }
+
+    const userId = c.req.header('X-User-Id') || 'dashboard';
+    const engineDb = deps.getEngineDb();
+    if (engineDb) {
+      await storeMessage(engineDb, {
+        agentId: takeover.agentId,
+        platform: takeover.platform,
+        contactId: takeover.contactId,
+        direction: 'outbound',
+        messageText: text,
+        metadata: { humanTakeover: true, takeoverId: takeover.id, sentBy: userId },
+      });
+    }
+    await takeovers.recordReply(takeover.id);
+    audit(c, 'human_reply', takeover, { length: text.length });
+    return c.json({ ok: true, sentAt: new Date().toISOString() });
+  });
+
+  // Hand control back to the agent
+  router.post('/:id/release', async (c) => {
+    const released = await takeovers.release(c.req.param('id'), c.req.header('X-User-Id') || 'dashboard');
+    if (!released) return c.json({ error: 'No active takeover' }, 404);
+    audit(c, 'release', released, { replies: released.replyCount, heldFor: Date.now() - new Date(released.takenAt).getTime() });
+    return c.json({ takeover: released });
+  });
+
+  return router;
+}
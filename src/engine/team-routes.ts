@@ -0,0 +1,116 @@
+/**
+ * Team Routes
+ * Mounted at /teams/* on the engine sub-app.
+ *
+ * Anyone can list teams; creating, editing and changing members is for admins.
+ * Adding dashboard users to a team limits what members and viewers see (see
+ * teams.ts), so that is admin-only too.
+ */
+
+import { Hono } from 'hono';
+import type { TeamManager } from './teams.js';
+import type { DatabaseAdapter } from '../db/adapter.js';
+import { auditFromEngine } from './route-audit.js';
+import { isAdminCaller } from './caller-role.js';
+
+export function createTeamRoutes(teams: TeamManager, deps: { getAdminDb?: () => DatabaseAdapter | null } = {}) {
+  const router = new Hono();
+
+  const audit = auditFromEngine(deps.getAdminDb);
+
+  // ?orgId=
+  router.get('/', async (c) => {
+    try {
+      return c.json({ teams: await teams.list(c.req.query('orgId') || 'default') });
+    } catch (e: any) { return c.json({ error: e.message }, 500); }
+  });
+
+  router.get('/agent/:agentId', async (c) => {
+    try {
+      return c.json({ teams: await teams.teamsForAgent(c.req.param('agentId')) });
+    } catch (e: any) { return c.json({ error: e.message }, 500); }
+  });
+
+  router.get('/user/:userId', async (c) => {
+    try {
+      return c.json({ teams: await teams.teamsForUser(c.req.param('userId')) });
+    } catch (e: any) { return c.json({ error: e.message }, 500); }
+  });
+
+  router.get('/:id', async (c) => {
+    const team = await teams.get(c.req.param('id'));
+    if (!team) return c.json({ error: 'Team not found' }, 404);
+    return c.json({ team });
+  });
+
+  // { orgId, name, description?, color?, agentIds?, userIds? }
+  router.post('/', async (c) => {
+    if (!isAdminCaller(c)) return c.json({ error: 'Only admins can create teams' }, 403);
+    const body = await c.req.json().catch(() => ({}));
+    try {
+      const team = await teams.create({ ...body, orgId: body.orgId || 'default', createdBy: c.req.header('X-User-Id') });
+      audit(c, 'team.create', `team:${team.id}`, { name: team.name, agentIds: team.agentIds, userIds: team.userIds }, team.orgId);
+      return c.json({ team }, 201);
+    } catch (e: any) { return c.json({ error: e.message }, 400); }
+  });
+
+  // { name?, description?, color? }
+  router.patch('/:id', async (c) => {
+    if (!isAdminCaller(c)) return c.json({ error: 'Only admins can edit teams' }, 403);
+    const body = await c.req.json().catch(() => ({}));
+    try {
+      const team = await teams.update(c.req.param('id'), body);
+      audit(c, 'team.update', `team:${team.id}`, { fields: Object.keys(body), name: team.name }, team.orgId);
+      return c.json({ team });
+    } catch (e: any) { return c.json({ error: e.message }, e.message === 'Team not found' ? 404 : 400); }
+  });
+
+  // { agentIds } — replaces the member list
+  router.put('/:id/members', async (c) => {
+    if (!isAdminCaller(c)) return c.json({ error: 'Only admins can change team members' }, 403);
+    const existing = await teams.get(c.req.param('id'));
+    if (!existing) return c.json({ error: 'Team not found' }, 404);
+    const body = await c.req.json().catch(() => ({}));
+    if (!Array.isArray(body.agentIds)) return c.json({ error: 'agentIds must be an array' }, 400);
+    try {
+      const agentIds = await teams.setMembers(existing.id, body.agentIds);
+      audit(c, 'team.members', `team:${existing.id}`, {
+        name: existing.name,
+        added: agentIds.filter(a => !existing.agentIds.includes(a)),
+        removed: existing.agentIds.filter(a => !agentIds.includes(a)),
+      }, existing.orgId);
+      return c.json({ team: { ...existing, agentIds } });
+    } catch (e: any) { return c.json({ error: e.message }, 400); }
+  });
+
+  // { userIds } — replaces the users scoped to this team
+  router.put('/:id/users', async (c) => {
+    if (!isAdminCaller(c)) return c.json({ error: 'Only admins can change team users' }, 403);
+    const existing = await teams.get(c.req.param('id'));
+    if (!existing) return c.json({ error: 'Team not found' }, 404);
+    const body = await c.req.json().catch(() => ({}));
+    if (!Array.isArray(body.userIds)) return c.json({ error: 'userIds must be an array' }, 400);
+    try {
+      const userIds = await teams.setUsers(existing.id, body.userIds);
+      audit(c, 'team.users', `team:${existing.id}`, {
+        name: existing.name,
+        added: userIds.filter(u => !existing.userIds.includes(u)),
+        removed: existing.userIds.filter(u => !userIds.includes(u)),
+      }, existing.orgId);
+      return c.json({ team: { ...existing, userIds } });
+    } catch (e: any) { return c.json({ error: e.message }, 400); }
+  });
+
+  router.delete('/:id', async (c) => {
+    if (!isAdminCaller(c)) return c.json({ error: 'Only admins can delete teams' }, 403);
+    const existing = await teams.get(c.req.param('id'));
+    if (!existing) return c.json({ error: 'Team not found' }, 404);
+    try {
+      await teams.delete(existing.id);
+      audit(c, 'team.delete', `team:${existing.id}`, { name: existing.name, agentIds: existing.agentIds, userIds: existing.userIds }, existing.orgId);
+      return c.json({ success: true });
+    } catch (e: any) { return c.json({ error: e.message }, 500); }
+  });
+
+  return router;
+}
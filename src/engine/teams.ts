@@ -0,0 +1,253 @@
+/**
+ * Agent Teams — named groups of agents, such as departments or pods
+ *
+ * A team is just a label over a set of agents: it doesn't change how any
+ * agent runs or what it may do. An agent can belong to several teams. The
+ * dashboard uses teams to filter the Agents list and to roll up activity
+ * and cost for a group (see the admin /teams/:id/report endpoint).
+ *
+ * Dashboard users can be added to teams too. A member or viewer who is in
+ * at least one team only sees those teams' agents, and the messages they
+ * sent or received, in list endpoints. Users in no team, admins and owners
+ * see everything.
+ *
+ * Members that no longer exist are dropped when an agent is deleted via
+ * removeAgent(); stale ids in old rows are harmless and filtered on read.
+ */
+
+import type { EngineDatabase } from './db-adapter.js';
+
+// ─── Types ──────────────────────────────────────────────
+
+export interface AgentTeam {
+  id: string;
+  orgId: string;
+  name: string;
+  description?: string;
+  /** Hex color for the team's badge */
+  color?: string;
+  agentIds: string[];
+  /** Dashboard users scoped to this team's agents */
+  userIds: string[];
+  createdBy?: string;
+  createdAt: string;
+  updatedAt: string;
+}
+
+// ─── Config ─────────────────────────────────────────────
+
+const MAX_NAME_CHARS = 100;
+const MAX_DESCRIPTION_CHARS = 2000;
+const MAX_MEMBERS = 500;
+const MAX_USERS = 500;
+
+/** Roles whose view is narrowed to their teams; admins and owners see everything */
+const SCOPED_ROLES = ['member', 'viewer'];
+
+/**
+ * Agents a user may see, or null when they aren't limited. `run` executes a
+ * query against the engine database, so the admin routes can share this.
+ */
+export async function teamVisibleAgentIds(
+  run: (sql: string, params: any[]) => Promise<any[]>,
+  user: { id?: string; role?: string },
+): Promise<Set<string> | null> {
+  if (!user.id || !SCOPED_ROLES.includes(user.role || '')) return null;
+  try {
+    const teams = await run('SELECT team_id FROM agent_team_users WHERE user_id = ?', [user.id]);
+    if (teams.length === 0) return null;
+    const rows = await run(
+      'SELECT DISTINCT m.agent_id FROM agent_team_members m JOIN agent_team_users u ON u.team_id = m.team_id WHERE u.user_id = ?',
+      [user.id],
+    );
+    return new Set(rows.map((r: any) => r.agent_id as string));
+  } catch {
+    // Can't tell which teams they're in — show nothing rather than everything
+    return new Set();
+  }
+}
+
+// ─── Team Manager ───────────────────────────────────────
+
+export class TeamManager {
+  private engineDb?: EngineDatabase;
+
+  async setDb(db: EngineDatabase): Promise<void> {
+    this.engineDb = db;
+  }
+
+  private get db(): EngineDatabase {
+    if (!this.engineDb) throw new Error('Teams database not initialized');
+    return this.engineDb;
+  }
+
+  /** An org's teams by name, with their members. */
+  async list(orgId: string): Promise<AgentTeam[]> {
+    if (!this.engineDb) return [];
+    const rows = await this.engineDb.query<any>('SELECT * FROM agent_teams WHERE org_id = ? ORDER BY name', [orgId]);
+    if (rows.length === 0) return [];
+    const placeholders = rows.map(() => '?').join(',');
+    const ids = rows.map((r: any) => r.id);
+    const members = await this.engineDb.query<any>(`SELECT team_id, agent_id FROM agent_team_members WHERE team_id IN (${placeholders}) ORDER BY added_at`, ids);
+    const users = await this.engineDb.query<any>(`SELECT team_id, user_id FROM agent_team_users WHERE team_id IN (${placeholders}) ORDER BY added_at`, ids).catch(() => []);
+    return rows.map((r: any) => this.rowToTeam(
+      r,
+      members.filter((m: any) => m.team_id === r.id).map((m: any) => m.agent_id),
+      users.filter((u: any) => u.team_id === r.id).map((u: any) => u.user_id),
+    ));
+  }
+
+  async get(id: string): Promise<AgentTeam | undefined> {
+    if (!this.engineDb) return undefined;
+    const row = await this.engineDb.get<any>('SELECT * FROM agent_teams WHERE id = ?', [id]);
+    if (!row) return undefined;
+    const members = await this.engineDb.query<any>('SELECT agent_id FROM agent_team_members WHERE team_id = ? ORDER BY added_at', [id]);
+    const users = await this.engineDb.query<any>('SELECT user_id FROM agent_team_users WHERE team_id = ? ORDER BY added_at', [id]).catch(() => []);
+    return this.rowToTeam(row, members.map((m: any) => m.agent_id), users.map((u: any) => u.user_id));
+  }
+
+  /** Teams an agent belongs to. */
+  async teamsForAgent(agentId: string): Promise<Array<Pick<AgentTeam, 'id' | 'name' | 'color'>>> {
+    if (!this.engineDb) return [];
+    const rows = await this.engineDb.query<any>(
+      'SELECT t.id, t.name, t.color FROM agent_teams t JOIN agent_team_members m ON m.team_id = t.id WHERE m.agent_id = ? ORDER BY t.name',
+      [agentId],
+    );
+    return rows.map((r: any) => ({ id: r.id, name: r.name, color: r.color || undefined }));
+  }
+
+  /** Teams a dashboard user belongs to. */
+  async teamsForUser(userId: string): Promise<Array<Pick<AgentTeam, 'id' | 'name' | 'color'>>> {
+    if (!this.engineDb) return [];
+    const rows = await this.engineDb.query<any>(
+      'SELECT t.id, t.name, t.color FROM agent_teams t JOIN agent_team_users u ON u.team_id = t.id WHERE u.user_id = ? ORDER BY t.name',
+      [userId],
+    );
+    return rows.map((r: any) => ({ id: r.id, name: r.name, color: r.color || undefined }));
+  }
+
+  /** Agents the user may see, or null when they aren't limited to their teams. */
+  async visibleAgentIds(user: { id?: string; role?: string }): Promise<Set<string> | null> {
+    // Without a database the lookup throws, so scoped users see nothing
+    return teamVisibleAgentIds((sql, params) => this.db.query<any>(sql, params), user);
+  }
+
+  async create(input: { orgId: string; name: string; description?: string; color?: string; agentIds?: string[]; userIds?: string[]; createdBy?: string }): Promise<AgentTeam> {
+    const name = input.name?.trim().slice(0, MAX_NAME_CHARS);
+    if (!name) throw new Error('A team name is required');
+    if ((await this.list(input.orgId)).some(t => t.name.toLowerCase() === name.toLowerCase())) throw new Error(`A team named "${name}" already exists`);
+    const now = new Date().toISOString();
+    const team: AgentTeam = {
+      id: crypto.randomUUID(),
+      orgId: input.orgId,
+      name,
+      description: input.description?.trim().slice(0, MAX_DESCRIPTION_CHARS) || undefined,
+      color: normalizeColor(input.color),
+      agentIds: [],
+      userIds: [],
+      createdBy: input.createdBy,
+      createdAt: now,
+      updatedAt: now,
+    };
+    await this.db.execute(
+      'INSERT INTO agent_teams (id, org_id, name, description, color, created_by, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)',
+      [team.id, team.orgId, team.name, team.description || null, team.color || null, team.createdBy || null, team.createdAt, team.updatedAt]
+    );
+    if (input.agentIds?.length) team.agentIds = await this.setMembers(team.id, input.agentIds);
+    if (input.userIds?.length) team.userIds = await this.setUsers(team.id, input.userIds);
+    return team;
+  }
+
+  async update(id: string, patch: { name?: string; description?: string; color?: string }): Promise<AgentTeam> {
+    const existing = await this.get(id);
+    if (!existing) throw new Error('Team not found');
+    const next: AgentTeam = {
+      ...existing,
+      name: patch.name !== undefined ? patch.name.trim().slice(0, MAX_NAME_CHARS) : existing.name,
+      description: patch.description !== undefined ? patch.description.trim().slice(0, MAX_DESCRIPTION_CHARS) || undefined : existing.description,
+      color: patch.color !== undefined ? normalizeColor(patch.color) : existing.color,
+      updatedAt: new Date().toISOString(),
+    };
+    if (!next.name) throw new Error('A team name is required');
+    if (next.name.toLowerCase() !== existing.name.toLowerCase()
+      && (await this.list(existing.orgId)).some(t => t.id !== id && t.name.toLowerCase() === next.name.toLowerCase())) {
+      throw new Error(`A team named "${next.name}" already exists`);
+    }
+    await this.db.execute(
+      'UPDATE agent_teams SET name = ?, description = ?, color = ?, updated_at = ? WHERE id = ?',
+      [next.name, next.description || null, next.color || null, next.updatedAt, id]
+    );
+    return next;
+  }
+
+  async delete(id: string): Promise<void> {
+    await this.db.execute('DELETE FROM agent_team_members WHERE team_id = ?', [id]);
+    await this.db.execute('DELETE FROM agent_team_users WHERE team_id = ?', [id]);
+    await this.db.execute('DELETE FROM agent_teams WHERE id = ?', [id]);
+  }
+
+  /** Replace a team's members. Returns the de-duplicated list stored. */
+  async setMembers(id: string, agentIds: string[]): Promise<string[]> {
+    const ids = Array.from(new Set((agentIds || []).filter(a => typeof a === 'string' && a.trim()).map(a => a.trim())));
+    if (ids.length > MAX_MEMBERS) throw new Error(`A team can have at most ${MAX_MEMBERS} agents`);
+    const current = (await this.db.query<any>('SELECT agent_id FROM agent_team_members WHERE team_id = ?', [id])).map((r: any) => r.agent_id as string);
+    const now = new Date().toISOString();
+    for (const agentId of current.filter(a => !ids.includes(a))) {
+      await this.db.execute('DELETE FROM agent_team_members WHERE team_id = ? AND agent_id = ?', [id, agentId]);
+    }
+    for (const agentId of ids.filter(a => !current.includes(a))) {
+      await this.db.execute('INSERT INTO agent_team_members (team_id, agent_id, added_at) VALUES (?, ?, ?)', [id, agentId, now]);
+    }
+    await this.db.execute('UPDATE agent_teams SET updated_at = ? WHERE id = ?', [now, id]);
+    return ids;
+  }
+
+  /** Replace a team's dashboard users. Returns the de-duplicated list stored. */
+  async setUsers(id: string, userIds: string[]): Promise<string[]> {
+    const ids = Array.from(new Set((userIds || []).filter(u => typeof u === 'string' && u.trim()).map(u => u.trim())));
+    if (ids.length > MAX_USERS) throw new Error(`A team can have at most ${MAX_USERS} users`);
+    const current = (await this.db.query<any>('SELECT user_id FROM agent_team_users WHERE team_id = ?', [id])).map((r: any) => r.user_id as string);
+    const now = new Date().toISOString();
+    for (const userId of current.filter(u => !ids.includes(u))) {
+      await this.db.execute('DELETE FROM agent_team_users WHERE team_id = ? AND user_id = ?', [id, userId]);
+    }
+    for (const userId of ids.filter(u => !current.includes(u))) {
+      await this.db.execute('INSERT INTO agent_team_users (team_id, user_id, added_at) VALUES (?, ?, ?)', [id, userId, now]);
+    }
+    await this.db.execute('UPDATE agent_teams SET updated_at = ? WHERE id = ?', [now, id]);
+    return ids;
+  }
+
+  /** Drop a deleted agent from every team. */
+  async removeAgent(agentId: string): Promise<void> {
+    if (!this.engineDb) return;
+    await this.engineDb.execute('DELETE FROM agent_team_members WHERE agent_id = ?', [agentId]);
+  }
+
+  /** Drop a deleted dashboard user from every team. */
+  async removeUser(userId: string): Promise<void> {
+    if (!this.engineDb) return;
+    await this.engineDb.execute('DELETE FROM agent_team_users WHERE user_id = ?', [userId]);
+  }
+
+  // ─── Private ────────────────────────────────────────
+
+  private rowToTeam(r: any, agentIds: string[], userIds: string[]): AgentTeam {
+    return {
+      id: r.id,
+      orgId: r.org_id,
+      name: r.name,
+      description: r.description || undefined,
+      color: r.color || undefined,
+      agentIds,
+      userIds,
+      createdBy: r.created_by || undefined,
+      createdAt: r.created_at,
+      updatedAt: r.updated_at,
+    };
+  }
+}
+
+function normalizeColor(color?: string): string | undefined {
+  return color && /^#[0-9a-f]{6}$/i.test(color.trim()) ? color.trim().toLowerCase() : undefined;
+}
@@ -0,0 +1,64 @@
+/**
+ * Transcript Routes
+ * Mounted at /transcripts/* on the engine sub-app.
+ */
+
+import { Hono } from 'hono';
+import { transcriptToMarkdown, type TranscriptReader } from './transcripts.js';
+import type { DatabaseAdapter } from '../db/adapter.js';
+import { auditFromEngine } from './route-audit.js';
+
+export function createTranscriptRoutes(reader: TranscriptReader, deps: {
+  getAdminDb?: () => DatabaseAdapter | null;
+  /** Agents the caller may see, or null when they aren't limited to their teams */
+  visibleAgentIds?: (c: any) => Promise<Set<string> | null>;
+} = {}) {
+  const router = new Hono();
+
+  /** The transcript, unless the caller's teams don't include its agent */
+  const visibleTranscript = async (c: any) => {
+    const transcript = await reader.get(c.req.param('sessionId'));
+    if (!transcript) return null;
+    const visible = deps.visibleAgentIds ? await deps.visibleAgentIds(c) : null;
+    return visible && !visible.has(transcript.agentId) ? null : transcript;
+  };
+
+  const audit = auditFromEngine(deps.getAdminDb);
+
+  // ?limit=&offset=
+  router.get('/agent/:agentId', async (c) => {
+    try {
+      return c.json(await reader.list(c.req.param('agentId'), {
+        limit: parseInt(c.req.query('limit') || '25') || 25,
+        offset: parseInt(c.req.query('offset') || '0') || 0,
+      }));
+    } catch (e: any) { return c.json({ error: e.message }, 500); }
+  });
+
+  router.get('/:sessionId', async (c) => {
+    try {
+      const transcript = await visibleTranscript(c);
+      if (!transcript) return c.json({ error: 'Conversation not found' }, 404);
+      return c.json({ transcript });
+    } catch (e: any) { return c.json({ error: e.message }, 500); }
+  });
+
+  // ?format=json|md
+  router.get('/:sessionId/export', async (c) => {
+    try {
+      const transcript = await visibleTranscript(c);
+      if (!transcript) return c.json({ error: 'Conversation not found' }, 404);
+      const format = c.req.query('format') === 'md' ? 'md' : 'json';
+      audit(c, 'transcript.export', `agent:${transcript.agentId}`, { sessionId: transcript.sessionId, format, turns: transcript.turns.length });
+      const fname = `conversation-${transcript.sessionId.slice(0, 12)}.${format}`;
+      c.header('Content-Disposition', `attachment; filename="${fname}"`);
+      if (format === 'md') {
+        c.header('Content-Type', 'text/markdown; charset=utf-8');
+        return c.body(transcriptToMarkdown(transcript));
+      }
+      return c.json(transcript);
+    } catch (e: any) { return c.json({ error: e.message }, 500); }
+  });
+
+  return router;
+}
@@ -0,0 +1,218 @@
+/**
+ * Transcripts — read-only view of an agent's conversations
+ *
+ * Reads the runtime's agent_sessions / agent_session_messages tables and
+ * turns them into something a person can follow when debugging an agent:
+ * one entry per turn, with each tool call paired to its result inline
+ * instead of split across the assistant and user messages the model sees.
+ *
+ * Nothing here writes; sessions are created and pruned by the runtime's
+ * SessionManager.
+ */
+
+import type { EngineDatabase } from './db-adapter.js';
+
+// ─── Types ──────────────────────────────────────────────
+
+export interface ConversationSummary {
+  sessionId: string;
+  agentId: string;
+  status: string;
+  turnCount: number;
+  tokenCount: number;
+  messageCount: number;
+  parentSessionId?: string;
+  /** First user message, whitespace-collapsed */
+  preview: string | null;
+  createdAt: string;
+  updatedAt: string;
+}
+
+export interface TranscriptToolCall {
+  id: string;
+  name: string;
+  input: Record<string, any>;
+  /** Missing when the session ended before the tool returned */
+  result?: string;
+  isError?: boolean;
+}
+
+export interface TranscriptTurn {
+  role: 'user' | 'assistant' | 'system';
+  text: string;
+  thinking?: string;
+  toolCalls: TranscriptToolCall[];
+  timestamp: string;
+}
+
+export interface Transcript extends ConversationSummary {
+  turns: TranscriptTurn[];
+}
+
+// ─── Config ─────────────────────────────────────────────
+
+const PREVIEW_CHARS = 140;
+/** Tool output kept per call; the rest is cut with a note */
+const MAX_RESULT_CHARS = 20_000;
+
+// ─── Reader ─────────────────────────────────────────────
+
+export class TranscriptReader {
+  private db?: EngineDatabase;
+
+  async setDb(db: EngineDatabase): Promise<void> {
+    this.db = db;
+  }
+
+  /** An agent's conversations, most recently active first. */
+  async list(agentId: string, opts: { limit?: number; offset?: number } = {}): Promise<{ conversations: ConversationSummary[]; total: number }> {
+    if (!this.db) return { conversations: [], total: 0 };
+    const limit = Math.min(Math.max(opts.limit || 25, 1), 100);
+    const offset = Math.max(opts.offset || 0, 0);
+    const [rows, count] = await Promise.all([
+      this.db.query<any>(
+        `SELECT s.*, (SELECT COUNT(*) FROM agent_session_messages m WHERE m.session_id = s.id) AS message_count
+         FROM agent_sessions s WHERE s.agent_id = ? ORDER BY s.updated_at DESC LIMIT ? OFFSET ?`,
+        [agentId, limit, offset],
+      ),
+      this.db.get<any>('SELECT COUNT(*) AS n FROM agent_sessions WHERE agent_id = ?', [agentId]),
+    ]);
+    const conversations = await Promise.all(rows.map(async (r: any) => {
+      const first = await this.db!.get<any>(
+        "SELECT content FROM agent_session_messages WHERE session_id = ? AND role = 'user' ORDER BY created_at LIMIT 1",
+        [r.id],
+      ).catch(() => undefined);
+      return this.rowToSummary(r, first ? previewOf(first.content) : null);
+    }));
+    return { conversations, total: Number(count?.n || 0) };
+  }
+
+  /** The full transcript, or null if the session doesn't exist. */
+  async get(sessionId: string): Promise<Transcript | null> {
+    if (!this.db) return null;
+    const row = await this.db.get<any>('SELECT * FROM agent_sessions WHERE id = ?', [sessionId]);
+    if (!row) return null;
+    const messages = await this.db.query<any>(
+      'SELECT * FROM agent_session_messages WHERE session_id = ? ORDER BY created_at ASC',
+      [sessionId],
+    );
+
+    const turns: TranscriptTurn[] = [];
+    const pending = new Map<string, TranscriptToolCall>();
+    let preview: string | null = null;
+
+    for (const m of messages) {
+      const content = parseJson(m.content);
+      const blocks: any[] = Array.isArray(content) ? content : [];
+      const results = [
+        ...blocks.filter(b => b?.type === 'tool_result'),
+        ...(asArray(parseJson(m.tool_results))),
+      ];
+      // Tool results go back onto the call that asked for them
+      for (const r of results) {
+        const call = pending.get(r.tool_use_id);
+        if (!call) continue;
+        call.result = clip(resultText(r.content));
+        if (r.is_error) call.isError = true;
+        pending.delete(r.tool_use_id);
+      }
+
+      const text = typeof content === 'string' ? content : blocks.filter(b => b?.type === 'text').map(b => b.text).join('\n');
+      const thinking = blocks.filter(b => b?.type === 'thinking').map(b => b.thinking).join('\n');
+      const calls = [...blocks.filter(b => b?.type === 'tool_use'), ...asArray(parseJson(m.tool_calls))];
+      const seen = new Set<string>();
+      const toolCalls: TranscriptToolCall[] = [];
+      for (const tc of calls) {
+        if (!tc?.id || seen.has(tc.id)) continue;
+        seen.add(tc.id);
+        const call: TranscriptToolCall = { id: tc.id, name: tc.name, input: tc.input || {} };
+        pending.set(tc.id, call);
+        toolCalls.push(call);
+      }
+
+      // A user message that only carries tool results isn't a turn of its own
+      if (!text.trim() && !thinking && toolCalls.length === 0) continue;
+      if (m.role === 'user' && preview === null && text.trim()) preview = previewOf(text);
+      turns.push({ role: m.role, text, thinking: thinking || undefined, toolCalls, timestamp: toIso(m.created_at) });
+    }
+
+    return { ...this.rowToSummary({ ...row, message_count: messages.length }, preview), turns };
+  }
+
+  // ─── Private ────────────────────────────────────────
+
+  private rowToSummary(r: any, preview: string | null): ConversationSummary {
+    return {
+      sessionId: r.id,
+      agentId: r.agent_id,
+      status: r.status,
+      turnCount: Number(r.turn_count || 0),
+      tokenCount: Number(r.token_count || 0),
+      messageCount: Number(r.message_count || 0),
+      parentSessionId: r.parent_session_id || undefined,
+      preview,
+      createdAt: toIso(r.created_at),
+      updatedAt: toIso(r.updated_at),
+    };
+  }
+}
+
+// ─── Export ─────────────────────────────────────────────
+
+/** Markdown rendering of a transcript for sharing in a ticket or chat. */
+export function transcriptToMarkdown(t: Transcript): string {
+  const out = [
+    `# Conversation ${t.sessionId}`,
+    '',
+    `- Agent: ${t.agentId}`,
+    `- Status: ${t.status}`,
+    `- Started: ${t.createdAt}`,
+    `- Last activity: ${t.updatedAt}`,
+    `- Turns: ${t.turnCount} · Tokens: ${t.tokenCount}`,
+    '',
+  ];
+  for (const turn of t.turns) {
+    out.push(`## ${turn.role.charAt(0).toUpperCase() + turn.role.slice(1)} — ${turn.timestamp}`, '');
+    if (turn.thinking) out.push('> ' + turn.thinking.replace(/\n/g, '\n> '), '');
+    if (turn.text.trim()) out.push(turn.text, '');
+    for (const call of turn.toolCalls) {
+      out.push(`**Tool call: \`${call.name}\`**${call.isError ? ' (error)' : ''}`, '', '```json', JSON.stringify(call.input, null, 2), '```', '');
+      out.push(call.result === undefined ? '_No result recorded_' : '```\n' + call.result + '\n```', '');
+    }
+  }
+  return out.join('\n');
+}
+
+// ─── Helpers ────────────────────────────────────────────
+
+function parseJson(v: any): any {
+  if (typeof v !== 'string') return v;
+  try { return JSON.parse(v); } catch { return v; }
+}
+
+function asArray(v: any): any[] {
+  return Array.isArray(v) ? v : [];
+}
+
+function resultText(content: any): string {
+  if (typeof content === 'string') return content;
+  if (Array.isArray(content)) return content.map((b: any) => (typeof b === 'string' ? b : b?.text ?? (b?.type === 'image' ? '[image]' : ''))).join('\n');
+  return content == null ? '' : JSON.stringify(content);
+}
+
+function previewOf(raw: any): string | null {
+  const content = parseJson(raw);
+  const text = (typeof content === 'string' ? content : asArray(content).map((b: any) => b?.type === 'text' ? b.text : '').join(' '))
+    .replace(/\s+/g, ' ').trim();
+  return text ? text.slice(0, PREVIEW_CHARS) : null;
+}
+
+function clip(s: string): string {
+  return s.length > MAX_RESULT_CHARS ? s.slice(0, MAX_RESULT_CHARS) + `\n… (${s.length - MAX_RESULT_CHARS} more characters)` : s;
+}
+
+function toIso(v: any): string {
+  if (v == null) return '';
+  const d = new Date(typeof v === 'string' && /^\d+$/.test(v) ? Number(v) : v);
+  return isNaN(d.getTime()) ? String(v) : d.toISOString();
+}
@@ -0,0 +1,81 @@
+/**
+ * Trust Center Routes
+ * Mounted at /trust/* on the engine sub-app.
+ *
+ * The published page itself is served without auth by server.ts at
+ * /trust/<token>; these routes manage and preview it.
+ */
+
+import { Hono } from 'hono';
+import { renderTrustHtml, renderTrustPdf, TRUST_SECTIONS, type TrustCenter } from './trust-center.js';
+import type { DatabaseAdapter } from '../db/adapter.js';
+import { auditFromEngine } from './route-audit.js';
+import { isAdminCaller } from './caller-role.js';
+
+export function createTrustCenterRoutes(trust: TrustCenter, deps: { getAdminDb?: () => DatabaseAdapter | null } = {}) {
+  const router = new Hono();
+
+  const audit = auditFromEngine(deps.getAdminDb);
+
+  // Settings plus the page as it would be published right now
+  router.get('/', async (c) => {
+    const orgId = c.req.query('orgId') || 'default';
+    try {
+      const [settings, page] = await Promise.all([trust.getSettings(orgId), trust.build(orgId)]);
+      return c.json({ settings, page, sections: TRUST_SECTIONS, publicPath: settings.token ? `/trust/${settings.token}` : null });
+    } catch (e: any) { return c.json({ error: e.message }, 500); }
+  });
+
+  // ?orgId=&format=html|pdf
+  router.get('/export', async (c) => {
+    const orgId = c.req.query('orgId') || 'default';
+    try {
+      const page = await trust.build(orgId);
+      const pdf = c.req.query('format') === 'pdf';
+      audit(c, 'trust_center.export', `trust_center:${orgId}`, { format: pdf ? 'pdf' : 'html' }, orgId);
+      return new Response(pdf ? renderTrustPdf(page).toBuffer() : renderTrustHtml(page), {
+        headers: {
+          'Content-Type': pdf ? 'application/pdf' : 'text/html; charset=utf-8',
+          'Content-Disposition': `attachment; filename="trust-center-${page.generatedAt.slice(0, 10)}.${pdf ? 'pdf' : 'html'}"`,
+          'Cache-Control': 'no-store',
+        },
+      });
+    } catch (e: any) { return c.json({ error: e.message }, 500); }
+  });
+
+  // { orgId, intro, contactEmail, hiddenSections }
+  router.put('/settings', async (c) => {
+    if (!isAdminCaller(c)) return c.json({ error: 'Only admins can edit the Trust page' }, 403);
+    const body = await c.req.json().catch(() => ({}));
+    const orgId = body.orgId || 'default';
+    try {
+      const settings = await trust.saveSettings(orgId, body);
+      audit(c, 'trust_center.update', `trust_center:${orgId}`, { hiddenSections: settings.hiddenSections, contactEmail: settings.contactEmail }, orgId);
+      return c.json({ success: true, settings });
+    } catch (e: any) { return c.json({ error: e.message }, 400); }
+  });
+
+  router.post('/publish', async (c) => {
+    if (!isAdminCaller(c)) return c.json({ error: 'Only admins can publish the Trust page' }, 403);
+    const body = await c.req.json().catch(() => ({}));
+    const orgId = body.orgId || 'default';
+    try {
+      const settings = await trust.publish(orgId, c.req.header('X-User-Id') || 'dashboard');
+      audit(c, 'trust_center.publish', `trust_center:${orgId}`, {}, orgId);
+      return c.json({ success: true, settings, publicPath: `/trust/${settings.token}` });
+    } catch (e: any) { return c.json({ error: e.message }, 400); }
+  });
+
+  router.post('/unpublish', async (c) => {
+    if (!isAdminCaller(c)) return c.json({ error: 'Only admins can unpublish the Trust page' }, 403);
+    const body = await c.req.json().catch(() => ({}));
+    const orgId = body.orgId || 'default';
+    try {
+      const settings = await trust.unpublish(orgId);
+      audit(c, 'trust_center.unpublish', `trust_center:${orgId}`, {}, orgId);
+      return c.json({ success: true, settings });
+    } catch (e: any) { return c.json({ error: e.message }, 400); }
+  });
+
+  return router;
+}
@@ -0,0 +1,336 @@
+/**
+ * Trust Center — a customer-facing summary of the org's controls
+ *
+ * Assembles a Trust page from live configuration: encryption at rest and
+ * in transit, DLP rules, access control, threat protection, audit and
+ * retention, data residency, and which compliance reports can be produced.
+ * Nothing is typed in by hand apart from an optional intro and contact
+ * address, so the page can't drift from what is actually switched on.
+ *
+ * The page is shown only in aggregate — rule counts, not patterns; report
+ * types and dates, not contents — because it is meant for customers'
+ * security reviewers. It can be downloaded as HTML or PDF, or published
+ * at /trust/<token>, an unauthenticated link that always renders the
+ * current state. Unpublishing or republishing invalidates the old link.
+ *
+ * Per-org settings live in engine_settings under 'trust_center:<orgId>'.
+ */
+
+import { randomBytes } from 'crypto';
+import type { EngineDatabase } from './db-adapter.js';
+import type { DataEncryption } from './data-encryption.js';
+import type { DLPEngine } from './dlp.js';
+import type { RetentionManager } from './retention.js';
+import type { DataResidency } from './data-residency.js';
+import type { ComplianceReporter } from './compliance.js';
+import type { DatabaseAdapter } from '../db/adapter.js';
+import { mergeSecurityConfig } from '../security/config.js';
+import { computeSecurityPosture } from '../security/posture.js';
+import { getTransportEncryptionConfig } from '../middleware/index.js';
+import { escapeHtml } from '../lib/templates.js';
+import { loadAuditRetention } from './audit-retention.js';
+import { PdfDocument } from '../lib/pdf.js';
+
+// ─── Types ──────────────────────────────────────────────
+
+export type TrustSectionId = 'encryption' | 'dlp' | 'access' | 'threats' | 'audit' | 'residency' | 'compliance';
+
+export const TRUST_SECTIONS: Array<{ id: TrustSectionId; title: string }> = [
+  { id: 'encryption', title: 'Encryption' },
+  { id: 'dlp', title: 'Data loss prevention' },
+  { id: 'access', title: 'Access control' },
+  { id: 'threats', title: 'Threat protection' },
+  { id: 'audit', title: 'Audit logging & retention' },
+  { id: 'residency', title: 'Data residency' },
+  { id: 'compliance', title: 'Compliance reporting' },
+];
+
+/** on = control in place, partial = in place with gaps, off = not in place, info = a fact, not a control */
+export type TrustStatus = 'on' | 'partial' | 'off' | 'info';
+
+export interface TrustItem {
+  label: string;
+  status: TrustStatus;
+  detail?: string;
+}
+
+export interface TrustSection {
+  id: TrustSectionId;
+  title: string;
+  items: TrustItem[];
+}
+
+export interface TrustSettings {
+  intro: string;
+  contactEmail: string;
+  /** Sections left off the page */
+  hiddenSections: TrustSectionId[];
+  /** Set while published; the public link is /trust/<token> */
+  token: string | null;
+  publishedAt: string | null;
+  publishedBy: string | null;
+}
+
+export interface TrustPage {
+  orgId: string;
+  companyName: string;
+  intro: string;
+  contactEmail: string;
+  generatedAt: string;
+  sections: TrustSection[];
+}
+
+const DEFAULT_SETTINGS: TrustSettings = { intro: '', contactEmail: '', hiddenSections: [], token: null, publishedAt: null, publishedBy: null };
+const settingsKey = (orgId: string) => `trust_center:${orgId}`;
+
+const REPORT_TYPES: Array<{ type: string; label: string }> = [
+  { type: 'soc2', label: 'SOC 2 summary' },
+  { type: 'gdpr', label: 'GDPR data subject export' },
+  { type: 'audit', label: 'Audit trail' },
+  { type: 'incident', label: 'Incident report' },
+  { type: 'access-review', label: 'Access review' },
+];
+
+const plural = (n: number, word: string) => `${n} ${word}${n === 1 ? '' : 's'}`;
+
+export function normalizeTrustSettings(raw: any, current: TrustSettings = DEFAULT_SETTINGS): TrustSettings {
+  const intro = raw?.intro !== undefined ? String(raw.intro || '').trim().slice(0, 2000) : current.intro;
+  const contactEmail = raw?.contactEmail !== undefined ? String(raw.contactEmail || '').trim().slice(0, 200) : current.contactEmail;
+  if (contactEmail && !/^[^\s@]+@[^\s@]+\.[^\s@]+$/.test(contactEmail)) throw new Error('contactEmail must be an email address');
+  const ids = TRUST_SECTIONS.map(s => s.id);
+  const hiddenSections = raw?.hiddenSections !== undefined
+    ? (Array.isArray(raw.hiddenSections) ? raw.hiddenSections : []).filter((id: any) => ids.includes(id))
+    : current.hiddenSections;
+  return { ...current, intro, contactEmail, hiddenSections };
+}
+
+// ─── Trust Center ───────────────────────────────────────
+
+export class TrustCenter {
+  private db: EngineDatabase | null = null;
+
+  constructor(private deps: {
+    encryption: DataEncryption;
+    dlp: DLPEngine;
+    retention: RetentionManager;
+    residency: DataResidency;
+    compliance: ComplianceReporter;
+    getAdminDb: () => DatabaseAdapter | null;
+  }) {}
+
+  async setDb(db: EngineDatabase): Promise<void> {
+    this.db = db;
+  }
+
+  async getSettings(orgId: string): Promise<TrustSettings> {
+    const row = await this.db?.get<any>('SELECT value FROM engine_settings WHERE key = ?', [settingsKey(orgId)]).catch(() => undefined);
+    try { return row?.value ? { ...DEFAULT_SETTINGS, ...JSON.parse(row.value) } : { ...DEFAULT_SETTINGS }; } catch { return { ...DEFAULT_SETTINGS }; }
+  }
+
+  async saveSettings(orgId: string, raw: any): Promise<TrustSettings> {
+    return this.write(orgId, normalizeTrustSettings(raw, await this.getSettings(orgId)));
+  }
+
+  /** Publish under a fresh token; any earlier link stops working */
+  async publish(orgId: string, by: string): Promise<TrustSettings> {
+    const current = await this.getSettings(orgId);
+    return this.write(orgId, { ...current, token: randomBytes(16).toString('hex'), publishedAt: new Date().toISOString(), publishedBy: by });
+  }
+
+  async unpublish(orgId: string): Promise<TrustSettings> {
+    const current = await this.getSettings(orgId);
+    return this.write(orgId, { ...current, token: null, publishedAt: null, publishedBy: null });
+  }
+
+  /** The org a public token belongs to, or null when it isn't (or is no longer) published */
+  async findByToken(token: string): Promise<string | null> {
+    if (!this.db || !/^[a-f0-9]{32}$/.test(token)) return null;
+    const rows = await this.db.query<any>("SELECT key, value FROM engine_settings WHERE key LIKE 'trust_center:%'").catch(() => []);
+    for (const r of rows) {
+      try { if (JSON.parse(r.value).token === token) return String(r.key).slice('trust_center:'.length); } catch { /* skip malformed */ }
+    }
+    return null;
+  }
+
+  async build(orgId: string): Promise<TrustPage> {
+    const adminDb = this.deps.getAdminDb();
+    const companySettings: any = await adminDb?.getSettings().catch(() => null);
+    const saved: any = companySettings?.securityConfig || {};
+    const [settings, report, users, runs, residency, auditRetention] = await Promise.all([
+      this.getSettings(orgId),
+      this.deps.encryption.getReport(saved.encryptionPolicy).catch(() => null),
+      adminDb ? adminDb.listUsers({ limit: 10_000 }).catch(() => []) : Promise.resolve([]),
+      this.deps.retention.getRuns(orgId, 50).catch(() => []),
+      this.deps.residency.getReport(orgId).catch(() => null),
+      this.db ? loadAuditRetention(this.db) : Promise.resolve(null),
+    ]);
+    const security = mergeSecurityConfig(saved);
+    const posture = computeSecurityPosture(saved, report);
+    const check = (id: string) => posture.checks.find(c => c.id === id);
+
+    const sections: TrustSection[] = [];
+
+    // Encryption
+    const encryption: TrustItem[] = [];
+    if (report) {
+      for (const cls of report.classes) {
+        const algo = cls.fields.find(f => f.algorithm)?.algorithm || 'AES-256-GCM';
+        encryption.push({
+          label: `${cls.label} at rest`,
+          status: cls.status === 'encrypted' ? 'on' : cls.status === 'partial' ? 'partial' : 'off',
+          detail: cls.status === 'encrypted' ? algo : cls.status === 'partial' ? `${algo} for part of this data` : 'Stored unencrypted',
+        });
+      }
+      encryption.push({ label: 'Dedicated encryption key', status: report.keyConfigured ? 'on' : 'off', detail: report.keyConfigured ? 'Vault master key supplied by the operator' : undefined });
+    }
+    const baseUrl = process.env.ENTERPRISE_URL || '';
+    encryption.push({ label: 'HTTPS for the dashboard and API', status: baseUrl.startsWith('https://') ? 'on' : baseUrl ? 'off' : 'info', detail: baseUrl ? undefined : 'Terminated by the hosting platform' });
+    const transport = getTransportEncryptionConfig();
+    encryption.push({
+      label: 'Payload encryption for sensitive API calls',
+      status: transport.enabled ? 'on' : 'off',
+      detail: transport.enabled ? (transport.encryptAll ? 'All API calls' : plural(Object.values(transport.enabledGroups || {}).filter(Boolean).length, 'endpoint group')) + ', AES-256 with HMAC' : undefined,
+    });
+    sections.push({ id: 'encryption', title: 'Encryption', items: encryption });
+
+    // DLP
+    const rules = this.deps.dlp.getRules(orgId).filter(r => r.enabled);
+    const byAction = (a: string) => rules.filter(r => r.action === a).length;
+    const since = new Date(Date.now() - 30 * 86_400_000).toISOString();
+    const recent = this.deps.dlp.getViolations({ orgId, limit: 100_000 }).filter(v => v.createdAt >= since);
+    sections.push({ id: 'dlp', title: 'Data loss prevention', items: [
+      { label: 'Outbound content scanning', status: rules.length ? 'on' : 'off', detail: rules.length ? `${plural(rules.length, 'active rule')}: ${byAction('block')} block, ${byAction('redact')} redact, ${byAction('warn') + byAction('log')} monitor` : 'No active rules' },
+      { label: 'PII detection', status: rules.some(r => r.patternType === 'pii_type') ? 'on' : 'off' },
+      { label: 'Secrets in agent output', status: security.secretScanning.enabled ? 'on' : 'off', detail: security.secretScanning.enabled ? `${security.secretScanning.patterns} patterns` : undefined },
+      { label: 'Detections in the last 30 days', status: 'info', detail: `${recent.length} (${recent.filter(v => v.actionTaken === 'blocked').length} blocked)` },
+    ] });
+
+    // Access control
+    const sso = companySettings?.ssoConfig || {};
+    const ipAccess = companySettings?.firewallConfig?.ipAccess;
+    const active = users.filter(u => u.isActive !== false);
+    const mfa = active.filter(u => u.totpEnabled).length;
+    sections.push({ id: 'access', title: 'Access control', items: [
+      { label: 'Single sign-on', status: sso.saml || sso.oidc ? 'on' : 'off', detail: [sso.saml && 'SAML 2.0', sso.oidc && 'OpenID Connect'].filter(Boolean).join(', ') || undefined },
+      { label: 'Two-factor authentication', status: !active.length ? 'info' : mfa === active.length ? 'on' : mfa ? 'partial' : 'off', detail: active.length ? `${mfa} of ${plural(active.length, 'user')}` : undefined },
+      { label: 'Role-based access', status: 'on', detail: 'Owner, admin, member and viewer roles, with per-page permissions' },
+      { label: 'Brute-force lockout', status: security.bruteForce.enabled ? 'on' : 'off', detail: security.bruteForce.enabled ? `${security.bruteForce.maxLoginAttempts} attempts, ${security.bruteForce.lockoutDurationMinutes} min lockout` : undefined },
+      { label: 'IP access restrictions', status: ipAccess?.enabled ? 'on' : 'off', detail: ipAccess?.enabled ? (ipAccess.mode === 'blocklist' ? 'Blocklist' : 'Allowlist') : undefined },
+    ] });
+
+    // Threat protection
+    const fromCheck = (id: string, label: string): TrustItem => {
+      const c = check(id);
+      return { label, status: !c ? 'off' : c.passed ? 'on' : c.detail === 'Monitor only' ? 'partial' : 'off', detail: c?.detail };
+    };
+    sections.push({ id: 'threats', title: 'Threat protection', items: [
+      fromCheck('prompt_injection', 'Prompt injection protection'),
+      fromCheck('sql_injection', 'SQL injection blocking'),
+      fromCheck('output_filtering', 'Output filtering'),
+      fromCheck('input_validation', 'Input validation'),
+      fromCheck('content_security', 'Content Security Policy'),
+      { label: 'Security posture score', status: posture.status === 'pass' ? 'on' : posture.status === 'warn' ? 'partial' : 'off', detail: `${posture.score} / 100` },
+    ] });
+
+    // Audit & retention
+    const lastPurge = runs.find(r => r.status === 'completed');
+    const retainDays = lastPurge?.retainDays;
+    sections.push({ id: 'audit', title: 'Audit logging & retention', items: [
+      { label: 'Administrative audit log', status: 'on', detail: 'Every change made through the dashboard or API is recorded with actor, time and source IP' },
+      { label: 'Agent action journal', status: 'on', detail: 'External actions taken by agents are journaled, with rollback where possible' },
+      { label: 'Audit log retention', status: auditRetention?.enabled ? 'on' : 'info', detail: auditRetention?.enabled ? `Kept ${auditRetention.retainDays} days${auditRetention.archiveToS3 ? ', then archived to S3' : ''}` : 'Kept indefinitely' },
+      { label: 'Security event logging', status: security.auditSecurity.enabled ? 'on' : 'off', detail: security.auditSecurity.enabled ? `Kept ${security.auditSecurity.retentionDays} days` : undefined },
+      { label: 'Data retention purges', status: lastPurge ? 'on' : 'off', detail: lastPurge ? `Data older than ${retainDays} days; last run ${String(lastPurge.completedAt || lastPurge.createdAt).slice(0, 10)}` : 'No purge run yet' },
+      { label: 'Legal hold', status: 'info', detail: 'Supported; held agents are excluded from purges' },
+    ] });
+
+    // Residency
+    if (residency) {
+      const cfg = residency.config;
+      sections.push({ id: 'residency', title: 'Data residency', items: [
+        ...residency.subsystems.filter(s => s.location).map((s): TrustItem => ({ label: s.label, status: 'info', detail: `${s.location} (${s.provider})` })),
+        { label: 'Region pinning', status: cfg.regions.length ? (cfg.enforce ? 'on' : 'partial') : 'off',
+          detail: cfg.regions.length ? cfg.regions.map(r => r.jurisdiction || r.label).join(', ') + (cfg.enforce ? ', enforced at deploy' : ', not enforced') : undefined },
+      ] });
+    }
+
+    // Compliance
+    const completed = this.deps.compliance.getReports({ orgId, limit: 1000 }).filter(r => r.status === 'completed');
+    sections.push({ id: 'compliance', title: 'Compliance reporting', items: REPORT_TYPES.map(t => {
+      const last = completed.filter(r => r.type === t.type).sort((a, b) => String(b.createdAt).localeCompare(String(a.createdAt)))[0];
+      return { label: t.label, status: last ? 'on' : 'info', detail: last ? `Last produced ${String(last.completedAt || last.createdAt).slice(0, 10)}` : 'Available on request' };
+    }) });
+
+    return {
+      orgId,
+      companyName: companySettings?.name || 'AgenticMail',
+      intro: settings.intro,
+      contactEmail: settings.contactEmail,
+      generatedAt: new Date().toISOString(),
+      sections: sections.filter(s => !settings.hiddenSections.includes(s.id) && s.items.length),
+    };
+  }
+
+  private async write(orgId: string, settings: TrustSettings): Promise<TrustSettings> {
+    if (!this.db) throw new Error('Trust center database not initialized');
+    await this.db.execute('DELETE FROM engine_settings WHERE key = ?', [settingsKey(orgId)]);
+    await this.db.execute('INSERT INTO engine_settings (key, value) VALUES (?, ?)', [settingsKey(orgId), JSON.stringify(settings)]);
+    return settings;
+  }
+}
+
+// ─── Rendering ──────────────────────────────────────────
+
+const STATUS_TEXT: Record<TrustStatus, string> = { on: 'In place', partial: 'Partial', off: 'Not enabled', info: '' };
+
+const STYLE = `
+*{box-sizing:border-box}
+body{margin:0;font:15px/1.6 -apple-system,BlinkMacSystemFont,"Segoe UI",Roboto,sans-serif;color:#1f2937;background:#f9fafb}
+main{max-width:860px;margin:0 auto;padding:40px 24px}
+h1{font-size:26px;margin:0 0 4px}
+h2{font-size:17px;margin:32px 0 10px}
+.muted{color:#6b7280;font-size:13px}
+.intro{margin:16px 0 0;white-space:pre-line}
+ul{list-style:none;margin:0;padding:0;background:#fff;border:1px solid #e5e7eb;border-radius:8px}
+li{display:flex;gap:12px;align-items:baseline;padding:10px 16px;border-top:1px solid #f3f4f6}
+li:first-child{border-top:0}
+li .label{flex:1;font-weight:500}
+li .detail{flex:1.4;color:#4b5563;font-size:13px}
+.pill{font-size:11px;font-weight:600;border-radius:999px;padding:2px 8px;white-space:nowrap;min-width:82px;text-align:center}
+.on{background:#dcfce7;color:#166534}.partial{background:#fef3c7;color:#92400e}.off{background:#f3f4f6;color:#6b7280}.info{background:transparent}
+footer{margin-top:40px}
+@media print{body{background:#fff}}
+`;
+
+/** Self-contained HTML: inline CSS, no scripts or external assets */
+export function renderTrustHtml(page: TrustPage): string {
+  const sections = page.sections.map(s => `<h2>${escapeHtml(s.title)}</h2><ul>`
+    + s.items.map(i => `<li><span class="label">${escapeHtml(i.label)}</span><span class="detail">${escapeHtml(i.detail || '')}</span><span class="pill ${i.status}">${STATUS_TEXT[i.status]}</span></li>`).join('')
+    + '</ul>').join('\n');
+  return `<!DOCTYPE html>
+<html lang="en"><head><meta charset="utf-8"><meta name="viewport" content="width=device-width,initial-scale=1">
+<meta name="robots" content="noindex">
+<title>${escapeHtml(page.companyName)} — Trust Center</title>
+<style>${STYLE}</style></head>
+<body><main>
+<h1>${escapeHtml(page.companyName)} Trust Center</h1>
+<div class="muted">Security and privacy controls for our AI agents, generated from live configuration on ${escapeHtml(page.generatedAt.slice(0, 10))}.</div>
+${page.intro ? `<p class="intro">${escapeHtml(page.intro)}</p>` : ''}
+${sections}
+<footer class="muted">${page.contactEmail ? `Security questions: <a href="mailto:${escapeHtml(page.contactEmail)}">${escapeHtml(page.contactEmail)}</a>. ` : ''}Generated ${escapeHtml(page.generatedAt.replace('T', ' ').slice(0, 16))} UTC.</footer>
+</main></body></html>
+`;
+}
+
+export function renderTrustPdf(page: TrustPage): PdfDocument {
+  const doc = new PdfDocument({ title: `${page.companyName} Trust Center`, author: page.companyName });
+  doc.heading(`${page.companyName} Trust Center`, 1);
+  doc.paragraph(`Security and privacy controls, generated from live configuration on ${page.generatedAt.slice(0, 10)}.`, { gray: 0.4 });
+  if (page.intro) doc.paragraph(page.intro);
+  for (const s of page.sections) {
+    doc.heading(s.title, 2);
+    doc.table(['Control', 'Details', 'Status'], s.items.map(i => [i.label, i.detail || '', STATUS_TEXT[i.status]]), { weights: [160, 260, 80] });
+  }
+  if (page.contactEmail) doc.paragraph(`Security questions: ${page.contactEmail}`, { gray: 0.4 });
+  return doc;
+}
@@ -0,0 +1,43 @@
+/**
+ * User Preferences Routes
+ * Mounted at /preferences/* on the engine sub-app.
+ *
+ * Always scoped to the caller (X-User-Id); there is no way to read or
+ * change another user's preferences.
+ */
+
+import { Hono } from 'hono';
+import type { UserPreferences } from './user-preferences.js';
+
+export function createUserPreferenceRoutes(prefs: UserPreferences) {
+  const router = new Hono();
+
+  router.use('*', async (c, next) => {
+    if (!c.req.header('X-User-Id')) return c.json({ error: 'Sign in to use preferences' }, 401);
+    await next();
+  });
+
+  router.get('/', async (c) => {
+    try {
+      return c.json({ preferences: await prefs.getAll(c.req.header('X-User-Id')!) });
+    } catch (e: any) { return c.json({ error: e.message }, 500); }
+  });
+
+  // { value }
+  router.put('/:key{.+}', async (c) => {
+    const body = await c.req.json().catch(() => ({}));
+    try {
+      await prefs.set(c.req.header('X-User-Id')!, c.req.param('key'), body.value);
+      return c.json({ success: true });
+    } catch (e: any) { return c.json({ error: e.message }, 400); }
+  });
+
+  router.delete('/:key{.+}', async (c) => {
+    try {
+      await prefs.remove(c.req.header('X-User-Id')!, c.req.param('key'));
+      return c.json({ success: true });
+    } catch (e: any) { return c.json({ error: e.message }, 500); }
+  });
+
+  return router;
+}
@@ -0,0 +1,52 @@
+/**
+ * User Preferences — Per-user dashboard settings that follow the user
+ *
+ * A small key/value store: hidden table columns, dashboard card order and
+ * the like, keyed by the signed-in user so they apply on any browser.
+ * Values are arbitrary JSON, capped in size; the dashboard owns their
+ * shape.
+ */
+
+import type { EngineDatabase } from './db-adapter.js';
+
+/** Keys are dotted/slashed identifiers like "columns.audit" */
+const KEY_PATTERN = /^[a-zA-Z0-9][\w.:\/-]{0,190}$/;
+
+/** Largest JSON value accepted per key */
+export const MAX_PREFERENCE_BYTES = 32 * 1024;
+
+export class UserPreferences {
+  private engineDb?: EngineDatabase;
+
+  async setDb(db: EngineDatabase): Promise<void> {
+    this.engineDb = db;
+  }
+
+  private db(): EngineDatabase {
+    if (!this.engineDb) throw new Error('Preferences database not initialized');
+    return this.engineDb;
+  }
+
+  async getAll(userId: string): Promise<Record<string, any>> {
+    const rows = await this.db().query<any>('SELECT pref_key, value FROM user_preferences WHERE user_id = ?', [userId]);
+    const out: Record<string, any> = {};
+    for (const r of rows) {
+      try { out[r.pref_key] = typeof r.value === 'string' ? JSON.parse(r.value) : r.value; } catch { /* skip corrupt rows */ }
+    }
+    return out;
+  }
+
+  async set(userId: string, key: string, value: any): Promise<void> {
+    if (!KEY_PATTERN.test(key)) throw new Error('Invalid preference key');
+    if (value === undefined) throw new Error('value required');
+    const json = JSON.stringify(value);
+    if (json.length > MAX_PREFERENCE_BYTES) throw new Error(`Preference too large (max ${MAX_PREFERENCE_BYTES / 1024} KB)`);
+    const db = this.db();
+    await db.execute('DELETE FROM user_preferences WHERE user_id = ? AND pref_key = ?', [userId, key]);
+    await db.execute('INSERT INTO user_preferences (user_id, pref_key, value, updated_at) VALUES (?, ?, ?, ?)', [userId, key, json, new Date().toISOString()]);
+  }
+
+  async remove(userId: string, key: string): Promise<void> {
+    await this.db().execute('DELETE FROM user_preferences WHERE user_id = ? AND pref_key = ?', [userId, key]);
+  }
+}
@@ -4,16 +4,39 @@
  */
 
 import { Hono } from 'hono';
-import type { SecureVault } from './vault.js';
+import type { SecureVault, VaultEntry } from './vault.js';
 import type { DLPEngine } from './dlp.js';
+import type { PermissionEngine } from './skills.js';
+import type { AgentLifecycleManager } from './lifecycle.js';
+import type { DatabaseAdapter } from '../db/adapter.js';
+import { parseSort, sortRows } from '../lib/sort.js';
+import { filterByQuery } from '../lib/filter.js';
+import { requireCapability } from '../middleware/role-permissions.js';
+import { auditFromEngine } from './route-audit.js';
+
+const SECRET_SORT_FIELDS = ['name', 'category', 'createdBy', 'createdAt', 'rotatedAt'] as const;
+
+/** Secrets created for the agent itself; it can always read these. */
+function ownedBy(entry: VaultEntry, agentId: string): boolean {
+  return entry.metadata?.agentId === agentId || entry.name.startsWith(`agent:${agentId}:`);
+}
 
-export function createVaultRoutes(vault: SecureVault, _dlp?: DLPEngine) {
+export function createVaultRoutes(vault: SecureVault, _dlp?: DLPEngine, deps: {
+  permissions?: PermissionEngine;
+  lifecycle?: AgentLifecycleManager;
+  getAdminDb?: () => DatabaseAdapter | null;
+} = {}) {
   const router = new Hono();
+  const audit = auditFromEngine(deps.getAdminDb);
+
+  const canView = requireCapability('vault.view');
+  const canReveal = requireCapability('vault.reveal');
+  const canManage = requireCapability('vault.manage');
 
   // ─── Secrets CRUD ────────────────────────────────────
 
   // POST /secrets — Store a new secret
-  router.post('/secrets', async (c) => {
+  router.post('/secrets', canManage, async (c) => {
     try {
       const body = await c.req.json();
       if (!body.orgId || !body.name || !body.value) {
@@ -31,20 +54,21 @@ export function createVaultRoutes(vault: SecureVault, _dlp?: DLPEngine) {
   });
 
   // GET /secrets — List secrets for org (metadata only, no decrypted values)
-  router.get('/secrets', async (c) => {
+  router.get('/secrets', canView, async (c) => {
     try {
       const orgId = c.req.query('orgId') || '';
       if (!orgId) return c.json({ error: 'orgId required' }, 400);
       const category = c.req.query('category') || undefined;
-      const entries = await vault.getSecretsByOrg(orgId, category);
+      const entries = filterByQuery(await vault.getSecretsByOrg(orgId, category), c.req.query('q'), e => [e.name, e.category, e.createdBy]);
+      sortRows(entries, parseSort(c.req.query('sort'), c.req.query('dir'), SECRET_SORT_FIELDS, { field: 'name', dir: 'asc' }));
       // Strip encrypted values from response
       const safe = entries.map(e => ({ ...e, encryptedValue: '[encrypted]' }));
       return c.json({ secrets: safe, total: safe.length });
     } catch (e: any) { return c.json({ error: e.message }, 500); }
   });
 
-  // GET /secrets/:id — Get a secret (decrypted, admin only)
-  router.get('/secrets/:id', async (c) => {
+  // GET /secrets/:id — Get a secret (decrypted)
+  router.get('/secrets/:id', canReveal, async (c) => {
     try {
       const result = await vault.getSecret(c.req.param('id'));
       if (!result) return c.json({ error: 'Secret not found' }, 404);
@@ -55,7 +79,7 @@ export function createVaultRoutes(vault: SecureVault, _dlp?: DLPEngine) {
   });
 
   // DELETE /secrets/:id — Delete a secret
-  router.delete('/secrets/:id', async (c) => {
+  router.delete('/secrets/:id', canManage, async (c) => {
     try {
       const deleted = await vault.deleteSecret(c.req.param('id'));
       if (!deleted) return c.json({ error: 'Secret not found' }, 404);
@@ -66,7 +90,7 @@ export function createVaultRoutes(vault: SecureVault, _dlp?: DLPEngine) {
   // ─── Rotation ────────────────────────────────────────
 
   // POST /secrets/:id/rotate — Rotate a specific secret
-  router.post('/secrets/:id/rotate', async (c) => {
+  router.post('/secrets/:id/rotate', canManage, async (c) => {
     try {
       const rotated = await vault.rotateSecret(c.req.param('id'));
       if (!rotated) return c.json({ error: 'Secret not found' }, 404);
@@ -77,7 +101,7 @@ export function createVaultRoutes(vault: SecureVault, _dlp?: DLPEngine) {
   });
 
   // POST /rotate-all — Rotate all secrets for an org
-  router.post('/rotate-all', async (c) => {
+  router.post('/rotate-all', canManage, async (c) => {
     try {
       const body = await c.req.json();
       if (!body.orgId) return c.json({ error: 'orgId required' }, 400);
@@ -91,7 +115,7 @@ export function createVaultRoutes(vault: SecureVault, _dlp?: DLPEngine) {
   // ─── Migration ───────────────────────────────────────
 
   // POST /migrate-credentials — Migrate plaintext deploy_credentials to vault
-  router.post('/migrate-credentials', async (c) => {
+  router.post('/migrate-credentials', canManage, async (c) => {
     try {
       const actor = c.req.header('X-User-Id') || 'admin';
       const result = await vault.migrateDeployCredentials();
@@ -103,7 +127,7 @@ export function createVaultRoutes(vault: SecureVault, _dlp?: DLPEngine) {
   // ─── Audit Log ───────────────────────────────────────
 
   // GET /audit-log — View vault access audit trail
-  router.get('/audit-log', async (c) => {
+  router.get('/audit-log', canView, async (c) => {
     try {
       const orgId = c.req.query('orgId') || '';
       if (!orgId) return c.json({ error: 'orgId required' }, 400);
@@ -119,6 +143,84 @@ export function createVaultRoutes(vault: SecureVault, _dlp?: DLPEngine) {
     } catch (e: any) { return c.json({ error: e.message }, 500); }
   });
 
+  // ─── Agent Access ────────────────────────────────────
+
+  // GET /agents/:agentId/access?orgId= — every org secret (metadata only) with whether this agent may read it
+  router.get('/agents/:agentId/access', canView, async (c) => {
+    try {
+      const agentId = c.req.param('agentId');
+      const orgId = c.req.query('orgId') || deps.lifecycle?.getAgent(agentId)?.orgId || '';
+      if (!orgId) return c.json({ error: 'orgId required' }, 400);
+      const profile = deps.permissions?.getProfile(agentId);
+      const granted = new Set(profile?.vaultAccess?.secretIds || []);
+      const reads = await vault.getAuditLog(orgId, { action: 'decrypt', actor: `agent:${agentId}`, limit: 1000 });
+      const lastRead = new Map<string, string>();
+      for (const r of reads.entries) {
+        if (r.vaultEntryId && !lastRead.has(r.vaultEntryId)) lastRead.set(r.vaultEntryId, r.createdAt);
+      }
+      const entries = await vault.getSecretsByOrg(orgId);
+      const secrets = entries.map(e => ({
+        id: e.id, name: e.name, category: e.category, createdBy: e.createdBy, createdAt: e.createdAt, rotatedAt: e.rotatedAt,
+        access: ownedBy(e, agentId) ? 'owned' : granted.has(e.id) ? 'granted' : 'none',
+        lastReadAt: lastRead.get(e.id) || null,
+      }));
+      sortRows(secrets, parseSort(c.req.query('sort'), c.req.query('dir'), SECRET_SORT_FIELDS, { field: 'name', dir: 'asc' }));
+      return c.json({ secrets, hasProfile: !!profile, grantedCount: secrets.filter(s => s.access === 'granted').length });
+    } catch (e: any) { return c.json({ error: e.message }, 500); }
+  });
+
+  // PUT /agents/:agentId/access — { orgId, secretIds } replaces the grants (admin only)
+  router.put('/agents/:agentId/access', canManage, async (c) => {
+    if (!deps.permissions) return c.json({ error: 'Permissions are not available' }, 501);
+    try {
+      const agentId = c.req.param('agentId');
+      const body = await c.req.json().catch(() => ({}));
+      const orgId = body.orgId || deps.lifecycle?.getAgent(agentId)?.orgId || '';
+      if (!orgId) return c.json({ error: 'orgId required' }, 400);
+      if (!Array.isArray(body.secretIds)) return c.json({ error: 'secretIds must be an array' }, 400);
+      const profile = deps.permissions.getProfile(agentId);
+      if (!profile) return c.json({ error: 'Assign a permission profile to this agent first' }, 409);
+
+      // Only this org's secrets, and never the agent's own (those need no grant)
+      const orgEntries = new Map((await vault.getSecretsByOrg(orgId)).map(e => [e.id, e]));
+      const unknown = body.secretIds.filter((id: string) => !orgEntries.has(id));
+      if (unknown.length) return c.json({ error: `Unknown secret${unknown.length === 1 ? '' : 's'}: ${unknown.join(', ')}` }, 400);
+      const next: string[] = Array.from(new Set<string>(body.secretIds)).filter(id => !ownedBy(orgEntries.get(id)!, agentId));
+      const prev = profile.vaultAccess?.secretIds || [];
+      const added = next.filter(id => !prev.includes(id));
+      const removed = prev.filter(id => !next.includes(id));
+
+      deps.permissions.setProfile(agentId, { ...profile, vaultAccess: { secretIds: next }, updatedAt: new Date().toISOString() }, orgId);
+
+      const actor = c.req.header('X-User-Id') || 'admin';
+      for (const id of added) await vault.auditLog(orgId, 'grant', actor, id, { agentId });
+      for (const id of removed) await vault.auditLog(orgId, 'revoke', actor, id, { agentId });
+      audit(c, 'agent.vault_access', `agent:${agentId}`, {
+        granted: added.map(id => orgEntries.get(id)?.name || id),
+        revoked: removed.map(id => orgEntries.get(id)?.name || id),
+      }, orgId);
+      return c.json({ success: true, secretIds: next, added: added.length, removed: removed.length });
+    } catch (e: any) { return c.json({ error: e.message }, 500); }
+  });
+
+  // GET /agents/:agentId/secrets/:id — read a secret on the agent's behalf; only granted or owned secrets
+  router.get('/agents/:agentId/secrets/:id', async (c) => {
+    try {
+      const agentId = c.req.param('agentId');
+      const result = await vault.getSecret(c.req.param('id'));
+      if (!result) return c.json({ error: 'Secret not found' }, 404);
+      const agent = deps.lifecycle?.getAgent(agentId);
+      const allowed = agent?.orgId === result.entry.orgId
+        && (ownedBy(result.entry, agentId) || !!deps.permissions?.canReadSecret(agentId, result.entry.id));
+      if (!allowed) {
+        await vault.auditLog(result.entry.orgId, 'decrypt_denied', `agent:${agentId}`, result.entry.id);
+        return c.json({ error: 'This agent has not been granted access to the secret' }, 403);
+      }
+      await vault.auditLog(result.entry.orgId, 'decrypt', `agent:${agentId}`, result.entry.id);
+      return c.json({ entry: { ...result.entry, encryptedValue: undefined }, value: result.decrypted });
+    } catch (e: any) { return c.json({ error: e.message }, 500); }
+  });
+
   // ─── Status ──────────────────────────────────────────
 
   // GET /status — Vault health
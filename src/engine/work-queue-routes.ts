@@ -0,0 +1,64 @@
+/**
+ * Work Queue Routes
+ * Mounted at /work-queue/* on the engine sub-app.
+ *
+ * Bulk actions are written to the admin audit log, one event per item.
+ */
+
+import { Hono } from 'hono';
+import { WORK_ITEM_KINDS, type WorkQueue, type WorkItemKind, type WorkQueueAction } from './work-queue.js';
+import type { DatabaseAdapter } from '../db/adapter.js';
+import { filterByQuery } from '../lib/filter.js';
+import { auditFromEngine } from './route-audit.js';
+
+const ACTIONS: WorkQueueAction[] = ['assign', 'unassign', 'resolve', 'approve', 'deny'];
+const MAX_BULK = 200;
+
+export function createWorkQueueRoutes(queue: WorkQueue, deps: { getAdminDb: () => DatabaseAdapter | null }) {
+  const router = new Hono();
+  const audit = auditFromEngine(deps.getAdminDb);
+
+  // Open items (?orgId=&kind=&assignee=me|unassigned|<userId>&q=)
+  router.get('/', async (c) => {
+    const orgId = c.req.query('orgId');
+    if (!orgId) return c.json({ error: 'orgId required' }, 400);
+    const kind = c.req.query('kind') as WorkItemKind | undefined;
+    if (kind && !WORK_ITEM_KINDS.includes(kind)) return c.json({ error: `Invalid kind: ${kind}` }, 400);
+    let assignee = c.req.query('assignee') || undefined;
+    if (assignee === 'me') assignee = c.req.header('X-User-Id') || undefined;
+
+    const items = await queue.list(orgId, { kind, assignee });
+    const filtered = filterByQuery(items, c.req.query('q'), i => [i.title, i.detail, i.agentId, i.assignedTo]);
+    return c.json({ items: filtered, total: filtered.length, now: new Date().toISOString() });
+  });
+
+  router.get('/counts', async (c) => {
+    const orgId = c.req.query('orgId');
+    if (!orgId) return c.json({ error: 'orgId required' }, 400);
+    return c.json({ counts: await queue.counts(orgId) });
+  });
+
+  // { orgId, ids: string[], action, assignee?, note? }
+  router.post('/bulk', async (c) => {
+    const body = await c.req.json().catch(() => ({}));
+    const userId = c.req.header('X-User-Id');
+    if (!userId) return c.json({ error: 'X-User-Id header required' }, 401);
+    if (!body.orgId) return c.json({ error: 'orgId required' }, 400);
+    if (!ACTIONS.includes(body.action)) return c.json({ error: `action must be one of ${ACTIONS.join(', ')}` }, 400);
+    const ids: string[] = Array.isArray(body.ids) ? body.ids.map(String) : [];
+    if (ids.length === 0) return c.json({ error: 'ids required' }, 400);
+    if (ids.length > MAX_BULK) return c.json({ error: `At most ${MAX_BULK} items per request` }, 400);
+
+    const note = body.note ? String(body.note).slice(0, 1000) : undefined;
+    const results = await queue.apply(body.orgId, ids, body.action, { userId, assignee: body.assignee || undefined, note });
+
+    for (const r of results) {
+      if (r.ok) audit(c, `work_queue.${body.action}`, `work_item:${r.id}`, { assignee: body.assignee, note }, body.orgId);
+    }
+
+    const failed = results.filter(r => !r.ok).length;
+    return c.json({ results, succeeded: results.length - failed, failed });
+  });
+
+  return router;
+}
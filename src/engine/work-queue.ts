@@ -0,0 +1,307 @@
+/**
+ * Work Queue — Everything waiting on a human, in one place
+ *
+ * Aggregates items from the modules that pause for people:
+ *   - approval      pending tool approvals (ApprovalEngine)
+ *   - escalation    agent escalations with no manager agent above them
+ *   - conversation  WhatsApp/Telegram threads a human has taken over
+ *   - quarantine    outbound content blocked by DLP, awaiting review
+ *   - qa            agent replies queued for QA grading
+ *
+ * The source modules stay the system of record. This module only adds what
+ * they lack: an assignee per item, an SLA due time, and (for DLP blocks,
+ * which have no review state of their own) whether someone has cleared it.
+ * Item ids are `${kind}:${sourceId}`.
+ */
+
+import type { EngineDatabase } from './db-adapter.js';
+import type { ApprovalEngine } from './approvals.js';
+import type { QAReviewQueue } from './qa-review.js';
+import type { ConversationTakeoverManager } from './conversation-takeover.js';
+import type { AgentLifecycleManager } from './lifecycle.js';
+
+// ─── Types ──────────────────────────────────────────────
+
+export type WorkItemKind = 'approval' | 'escalation' | 'conversation' | 'quarantine' | 'qa';
+
+export interface WorkItem {
+  id: string;
+  kind: WorkItemKind;
+  sourceId: string;
+  orgId?: string;
+  agentId?: string;
+  title: string;
+  detail?: string;
+  createdAt: string;
+  /** When the item breaches its SLA */
+  dueAt: string;
+  assignedTo?: string;
+  assignedAt?: string;
+}
+
+export type WorkQueueAction = 'assign' | 'unassign' | 'resolve' | 'approve' | 'deny';
+
+export interface WorkQueueResult {
+  id: string;
+  ok: boolean;
+  error?: string;
+}
+
+// ─── Config ─────────────────────────────────────────────
+
+export const WORK_ITEM_KINDS: WorkItemKind[] = ['approval', 'escalation', 'conversation', 'quarantine', 'qa'];
+
+/** Minutes from creation until an item is overdue. Approvals use their own expiry. */
+export const WORK_QUEUE_SLA_MINUTES: Record<WorkItemKind, number> = {
+  approval: 60,
+  escalation: 4 * 60,
+  conversation: 60,
+  quarantine: 24 * 60,
+  qa: 72 * 60,
+};
+
+/** DLP blocks older than this drop out of the queue on their own */
+const QUARANTINE_WINDOW_DAYS = 14;
+const MAX_ITEMS_PER_KIND = 500;
+
+// ─── Work Queue ─────────────────────────────────────────
+
+export class WorkQueue {
+  private engineDb?: EngineDatabase;
+
+  constructor(private deps: {
+    approvals: ApprovalEngine;
+    qaReviews: QAReviewQueue;
+    takeovers: ConversationTakeoverManager;
+    lifecycle: AgentLifecycleManager;
+  }) {}
+
+  async setDb(db: EngineDatabase): Promise<void> {
+    this.engineDb = db;
+  }
+
+  /** Open items for an org, most urgent first. */
+  async list(orgId: string, opts: { kind?: WorkItemKind; assignee?: string } = {}): Promise<WorkItem[]> {
+    const kinds = opts.kind ? [opts.kind] : WORK_ITEM_KINDS;
+    const batches = await Promise.all(kinds.map(k => this.collect(k, orgId).catch(() => [] as WorkItem[])));
+    let items = batches.flat();
+
+    const state = await this.loadState(items.map(i => i.id));
+    items = items.filter(i => !state.get(i.id)?.resolved_at);
+    for (const item of items) {
+      const s = state.get(item.id);
+      if (s?.assigned_to) {
+        item.assignedTo = s.assigned_to;
+        item.assignedAt = s.assigned_at ? new Date(s.assigned_at).toISOString() : undefined;
+      }
+    }
+
+    if (opts.assignee === 'unassigned') items = items.filter(i => !i.assignedTo);
+    else if (opts.assignee) items = items.filter(i => i.assignedTo === opts.assignee);
+    return items.sort((a, b) => a.dueAt.localeCompare(b.dueAt));
+  }
+
+  /** Open item counts per kind, plus how many are past due. */
+  async counts(orgId: string): Promise<Record<WorkItemKind | 'total' | 'overdue', number>> {
+    const items = await this.list(orgId);
+    const now = new Date().toISOString();
+    const out: any = { total: items.length, overdue: items.filter(i => i.dueAt < now).length };
+    for (const k of WORK_ITEM_KINDS) out[k] = items.filter(i => i.kind === k).length;
+    return out;
+  }
+
+  /**
+   * Apply one action to many items. Each item succeeds or fails on its own;
+   * approve/deny only apply to approvals.
+   */
+  async apply(orgId: string, ids: string[], action: WorkQueueAction, opts: { userId: string; assignee?: string; note?: string }): Promise<WorkQueueResult[]> {
+    const results: WorkQueueResult[] = [];
+    for (const id of ids) {
+      const [kind, ...rest] = id.split(':');
+      const sourceId = rest.join(':');
+      if (!WORK_ITEM_KINDS.includes(kind as WorkItemKind) || !sourceId) {
+        results.push({ id, ok: false, error: 'Unknown item' });
+        continue;
+      }
+      try {
+        await this.applyOne(orgId, kind as WorkItemKind, sourceId, action, opts);
+        results.push({ id, ok: true });
+      } catch (e: any) {
+        results.push({ id, ok: false, error: e.message });
+      }
+    }
+    return results;
+  }
+
+  // ─── Actions ────────────────────────────────────────
+
+  private async applyOne(orgId: string, kind: WorkItemKind, sourceId: string, action: WorkQueueAction, opts: { userId: string; assignee?: string; note?: string }): Promise<void> {
+    const id = `${kind}:${sourceId}`;
+    switch (action) {
+      case 'assign':
+        return this.upsertState(id, orgId, kind, { assigned_to: opts.assignee || opts.userId, assigned_at: new Date().toISOString() });
+      case 'unassign':
+        return this.upsertState(id, orgId, kind, { assigned_to: null, assigned_at: null });
+      case 'approve':
+      case 'deny': {
+        if (kind !== 'approval') throw new Error(`Only approvals can be ${action === 'approve' ? 'approved' : 'denied'}`);
+        const decided = await this.deps.approvals.decide(sourceId, { by: opts.userId, action, reason: opts.note });
+        if (!decided) throw new Error('Approval is no longer pending');
+        return;
+      }
+      case 'resolve':
+        return this.resolve(orgId, kind, sourceId, opts);
+      default:
+        throw new Error(`Unknown action: ${action}`);
+    }
+  }
+
+  /** Close an item in its source module. */
+  private async resolve(orgId: string, kind: WorkItemKind, sourceId: string, opts: { userId: string; note?: string }): Promise<void> {
+    const now = new Date().toISOString();
+    switch (kind) {
+      case 'approval':
+        throw new Error('Approvals must be approved or denied');
+      case 'escalation': {
+        const row = await this.engineDb?.get<any>("SELECT id FROM agent_escalations WHERE id = ? AND status = 'pending'", [sourceId]);
+        if (!row) throw new Error('Escalation is no longer pending');
+        await this.engineDb!.execute(
+          "UPDATE agent_escalations SET status = 'resolved', resolution = ?, resolved_at = ?, updated_at = ? WHERE id = ?",
+          [opts.note || `Resolved by ${opts.userId}`, now, now, sourceId],
+        );
+        break;
+      }
+      case 'conversation': {
+        const released = await this.deps.takeovers.release(sourceId, opts.userId);
+        if (!released) throw new Error('Conversation is no longer taken over');
+        break;
+      }
+      case 'qa': {
+        const skipped = await this.deps.qaReviews.skip(orgId, sourceId, opts.userId);
+        if (!skipped) throw new Error('Review is no longer pending');
+        break;
+      }
+      case 'quarantine':
+        break;
+    }
+    await this.upsertState(`${kind}:${sourceId}`, orgId, kind, { resolved_by: opts.userId, resolved_at: now, resolution: opts.note || null });
+  }
+
+  // ─── Sources ────────────────────────────────────────
+
+  private async collect(kind: WorkItemKind, orgId: string): Promise<WorkItem[]> {
+    switch (kind) {
+      case 'approval':
+        return this.deps.approvals.getPendingRequests()
+          .filter(r => this.orgOf(r.agentId) === orgId)
+          .map(r => ({
+            id: `approval:${r.id}`, kind, sourceId: r.id, orgId, agentId: r.agentId,
+            title: `${r.agentName || r.agentId} wants to use ${r.toolName || r.toolId}`,
+            detail: r.context || r.reason,
+            createdAt: r.createdAt,
+            dueAt: r.expiresAt || dueFrom(kind, r.createdAt),
+          }));
+
+      case 'escalation': {
+        if (!this.engineDb) return [];
+        const rows = await this.engineDb.query<any>(
+          `SELECT * FROM agent_escalations WHERE status = 'pending' AND to_agent_id IS NULL ORDER BY created_at ASC LIMIT ${MAX_ITEMS_PER_KIND}`,
+        );
+        return rows
+          .filter((r: any) => this.orgOf(r.from_agent_id) === orgId)
+          .map((r: any) => {
+            const createdAt = new Date(r.created_at).toISOString();
+            return {
+              id: `escalation:${r.id}`, kind, sourceId: r.id, orgId, agentId: r.from_agent_id,
+              title: r.subject, detail: r.context ? String(r.context).slice(0, 300) : undefined,
+              createdAt, dueAt: dueFrom(kind, createdAt),
+            };
+          });
+      }
+
+      case 'conversation':
+        return this.deps.takeovers.listActive({ orgId }).map(t => ({
+          id: `conversation:${t.id}`, kind, sourceId: t.id, orgId, agentId: t.agentId,
+          title: `${t.platform} conversation with ${t.contactId}`,
+          detail: t.reason,
+          createdAt: t.takenAt, dueAt: dueFrom(kind, t.takenAt),
+          // Whoever took the thread over is working it
+          assignedTo: t.takenBy, assignedAt: t.takenAt,
+        }));
+
+      case 'quarantine': {
+        if (!this.engineDb) return [];
+        const since = new Date(Date.now() - QUARANTINE_WINDOW_DAYS * 86_400_000).toISOString();
+        const rows = await this.engineDb.query<any>(
+          `SELECT * FROM dlp_violations WHERE org_id = ? AND action_taken = 'blocked' AND direction = 'outbound' AND created_at >= ?
+           ORDER BY created_at DESC LIMIT ${MAX_ITEMS_PER_KIND}`,
+          [orgId, since],
+        );
+        return rows.map((r: any) => {
+          const createdAt = new Date(r.created_at).toISOString();
+          return {
+            id: `quarantine:${r.id}`, kind, sourceId: r.id, orgId, agentId: r.agent_id,
+            title: `Blocked ${r.tool_id || 'outbound'} content (rule ${r.rule_id})`,
+            detail: r.match_context || undefined,
+            createdAt, dueAt: dueFrom(kind, createdAt),
+          };
+        });
+      }
+
+      case 'qa': {
+        const { reviews } = await this.deps.qaReviews.list({ orgId, status: 'pending', limit: MAX_ITEMS_PER_KIND });
+        return reviews.map(r => ({
+          id: `qa:${r.id}`, kind, sourceId: r.id, orgId, agentId: r.agentId,
+          title: `Grade reply ${r.messageId}`,
+          detail: r.source === 'manual' ? 'Queued by hand' : 'Sampled for review',
+          createdAt: r.queuedAt, dueAt: dueFrom(kind, r.queuedAt),
+        }));
+      }
+    }
+  }
+
+  private orgOf(agentId: string): string | undefined {
+    return this.deps.lifecycle.getAgent(agentId)?.orgId;
+  }
+
+  // ─── State ──────────────────────────────────────────
+
+  private async loadState(ids: string[]): Promise<Map<string, any>> {
+    const map = new Map<string, any>();
+    if (!this.engineDb || ids.length === 0) return map;
+    try {
+      // Chunk to stay well under parameter limits
+      for (let i = 0; i < ids.length; i += 200) {
+        const chunk = ids.slice(i, i + 200);
+        const rows = await this.engineDb.query<any>(
+          `SELECT * FROM work_queue_items WHERE item_id IN (${chunk.map(() => '?').join(', ')})`,
+          chunk,
+        );
+        for (const r of rows) map.set(r.item_id, r);
+      }
+    } catch { /* table may not exist yet */ }
+    return map;
+  }
+
+  private async upsertState(itemId: string, orgId: string, kind: WorkItemKind, patch: Record<string, string | null>): Promise<void> {
+    if (!this.engineDb) throw new Error('Work queue storage not initialized');
+    const now = new Date().toISOString();
+    const existing = await this.engineDb.get<any>('SELECT item_id FROM work_queue_items WHERE item_id = ?', [itemId]);
+    const cols = Object.keys(patch);
+    if (existing) {
+      await this.engineDb.execute(
+        `UPDATE work_queue_items SET ${cols.map(c => `${c} = ?`).join(', ')}, updated_at = ? WHERE item_id = ?`,
+        [...cols.map(c => patch[c]), now, itemId],
+      );
+    } else {
+      await this.engineDb.execute(
+        `INSERT INTO work_queue_items (item_id, org_id, kind, ${cols.join(', ')}, updated_at) VALUES (?, ?, ?, ${cols.map(() => '?').join(', ')}, ?)`,
+        [itemId, orgId, kind, ...cols.map(c => patch[c]), now],
+      );
+    }
+  }
+}
+
+function dueFrom(kind: WorkItemKind, createdAt: string): string {
+  return new Date(new Date(createdAt).getTime() + WORK_QUEUE_SLA_MINUTES[kind] * 60_000).toISOString();
+}
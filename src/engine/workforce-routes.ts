@@ -35,7 +35,7 @@ export function createWorkforceRoutes(workforce: WorkforceManager, opts?: { life
     try {
       const agentId = c.req.param('agentId');
       const schedule = await workforce.getSchedule(agentId);
-      return c.json({ schedule: schedule || null });
+      return c.json({ schedule: schedule || null, nextWindow: workforce.getNextActiveWindow(agentId) });
     } catch (err: any) {
       return c.json({ error: err.message }, 500);
     }
@@ -204,6 +204,7 @@ export function createWorkforceRoutes(workforce: WorkforceManager, opts?: { life
         clockedIn: !isOffDuty,
         clockStatus: latestClock,
         schedule: schedule || null,
+        nextWindow: workforce.getNextActiveWindow(agentId),
         queuedTasks,
         isOffDuty,
       });
@@ -306,6 +307,70 @@ export function createWorkforceRoutes(workforce: WorkforceManager, opts?: { life
     }
   });
 
+  // ─── Maintenance Windows ────────────────────────────────
+
+  /** Windows overlapping ?from=&to= (default: from now), optionally for one ?agentId= */
+  router.get('/maintenance', async (c) => {
+    try {
+      const orgId = resolveOrgId(c);
+      const windows = await workforce.getMaintenanceWindows(orgId, {
+        from: c.req.query('from') || new Date().toISOString(),
+        to: c.req.query('to') || undefined,
+        agentId: c.req.query('agentId') || undefined,
+      });
+      return c.json({ windows, total: windows.length });
+    } catch (err: any) {
+      return c.json({ error: err.message }, 500);
+    }
+  });
+
+  /** Schedule a window — omit agentId for an org-wide window */
+  router.post('/maintenance', async (c) => {
+    try {
+      const body = await c.req.json();
+      if (!body.startsAt || !body.endsAt) {
+        return c.json({ error: 'startsAt and endsAt are required' }, 400);
+      }
+      const window = await workforce.createMaintenanceWindow({
+        orgId: resolveOrgId(c, body),
+        agentId: body.agentId || undefined,
+        title: body.title,
+        startsAt: body.startsAt,
+        endsAt: body.endsAt,
+        notice: body.notice,
+        createdBy: c.req.header('X-User-Id') || undefined,
+      });
+      return c.json({ window }, 201);
+    } catch (err: any) {
+      return c.json({ error: err.message }, 400);
+    }
+  });
+
+  /** Edit or reschedule an upcoming or open window */
+  router.put('/maintenance/:id', async (c) => {
+    try {
+      const body = await c.req.json();
+      const window = await workforce.updateMaintenanceWindow(c.req.param('id'), {
+        title: body.title, startsAt: body.startsAt, endsAt: body.endsAt, notice: body.notice,
+      });
+      if (!window) return c.json({ error: 'Maintenance window not found or already finished' }, 404);
+      return c.json({ window });
+    } catch (err: any) {
+      return c.json({ error: err.message }, 400);
+    }
+  });
+
+  /** Cancel a window; an open one ends immediately and its agents resume */
+  router.delete('/maintenance/:id', async (c) => {
+    try {
+      const window = await workforce.cancelMaintenanceWindow(c.req.param('id'), c.req.header('X-User-Id') || 'dashboard');
+      if (!window) return c.json({ error: 'Maintenance window not found or already finished' }, 404);
+      return c.json({ success: true });
+    } catch (err: any) {
+      return c.json({ error: err.message }, 500);
+    }
+  });
+
   // ─── Budget Overview ────────────────────────────────────
 
   /** Extended budget overview, requires lifecycle manager to be configured */
@@ -6,6 +6,8 @@
  * - Automated clock-in/out at scheduled times
  * - Off-hours enforcement via guardrails pipeline
  * - Task queue for work continuity between sessions
+ * - Maintenance windows (per-agent or org-wide) that pause agents and
+ *   auto-reply with a notice until the window closes
  * - Automated counter resets (daily/weekly/monthly/annual)
  */
 
@@ -40,6 +42,15 @@ export interface WorkSchedule {
       end?: string;
       reason?: string;
     }[];
+    /**
+     * Auto-reply sent while off duty (Google mailboxes). On unless
+     * enabled is false; {nextStart} and {hours} in the text are filled in.
+     */
+    outOfOffice?: {
+      enabled: boolean;
+      subject?: string;
+      message?: string;
+    };
   };
   enforceClockIn: boolean;
   enforceClockOut: boolean;
@@ -82,6 +93,20 @@ export interface QueuedTask {
   updatedAt: string;
 }
 
+export interface MaintenanceWindow {
+  id: string;
+  orgId: string;
+  agentId?: string;                    // Omitted for an org-wide window
+  title: string;
+  startsAt: string;
+  endsAt: string;
+  notice: string;                      // Auto-reply sent while the window is open
+  status: 'scheduled' | 'active' | 'completed' | 'cancelled';
+  createdBy?: string;
+  createdAt: string;
+  updatedAt: string;
+}
+
 export interface WorkforceStatus {
   agents: {
     id: string;
@@ -90,17 +115,23 @@ export interface WorkforceStatus {
     schedule?: WorkSchedule;
     nextEvent?: { type: string; at: string };
     queuedTasks: number;
+    maintenance?: { id: string; title: string; endsAt: string };
   }[];
   totalClocked: number;
   totalOff: number;
   totalUnscheduled: number;
 }
 
+export const DEFAULT_MAINTENANCE_NOTICE =
+  "I'm currently unavailable due to scheduled maintenance. I'll pick up your message as soon as I'm back online.";
+
 // ─── Workforce Manager ──────────────────────────────────
 
 export class WorkforceManager {
   private schedules = new Map<string, WorkSchedule>();
   private clockStatus = new Map<string, 'clocked_in' | 'clocked_out'>();
+  /** Scheduled and active maintenance windows; past ones are only in the DB */
+  private maintenance = new Map<string, MaintenanceWindow>();
   private engineDb?: EngineDatabase;
   private lifecycle?: AgentLifecycleManager;
   private guardrails?: GuardrailEngine;
@@ -164,6 +195,13 @@ export class WorkforceManager {
     } catch {
       // Table may not exist yet if migrations haven't run
     }
+
+    try {
+      const rows = await this.engineDb.query<any>("SELECT * FROM maintenance_windows WHERE status IN ('scheduled', 'active')");
+      for (const r of rows) this.maintenance.set(r.id, this.rowToMaintenance(r));
+    } catch {
+      // Table may not exist yet if migrations haven't run
+    }
   }
 
   // ─── Schedule CRUD ────────────────────────────────────
@@ -206,6 +244,13 @@ export class WorkforceManager {
     }
 
     this.emitEvent('schedule_set', { agentId: schedule.agentId, schedule });
+
+    // Pick up out-of-office changes while the agent is already off duty
+    if (schedule.enabled && this.clockStatus.get(schedule.agentId) === 'clocked_out') {
+      this.setGmailVacation(schedule.agentId, true, schedule).catch(e =>
+        console.warn(`[workforce] ${schedule.agentId}: failed to update vacation responder: ${e.message}`)
+      );
+    }
   }
 
   /**
@@ -230,6 +275,48 @@ export class WorkforceManager {
     return this.schedules.get(agentId);
   }
 
+  /**
+   * The agent's current or next active window under its schedule, grace
+   * period included. `start` is `from` when the agent is already active;
+   * `end` is null when the window runs past the week looked ahead.
+   * Null with no enabled schedule or no working time in the next week.
+   */
+  getNextActiveWindow(agentId: string, from = new Date()): { activeNow: boolean; start: string; end: string | null; timezone: string } | null {
+    const schedule = this.schedules.get(agentId);
+    if (!schedule?.enabled) return null;
+
+    const MINUTE = 60_000;
+    const STEP = 15 * MINUTE;
+    const now = Math.floor(from.getTime() / MINUTE) * MINUTE;
+    const horizon = now + 8 * 24 * 60 * MINUTE;
+    // Same conversion as toTimezone(), with one formatter for the whole scan
+    const local = new Intl.DateTimeFormat('en-US', { timeZone: schedule.timezone, year: 'numeric', month: 'numeric', day: 'numeric', hour: 'numeric', minute: 'numeric', second: 'numeric' });
+    const working = (t: number) => this.isWithinWorkingHours(schedule, new Date(local.format(new Date(t))));
+
+    // First minute at or after t where working() is `target`: coarse steps, then back-fill to the minute
+    const nextFlip = (t: number, target: boolean): number | null => {
+      for (let s = t; s <= horizon; s += STEP) {
+        if (working(s) !== target) continue;
+        for (let m = Math.max(t, s - STEP + MINUTE); m < s; m += MINUTE) {
+          if (working(m) === target) return m;
+        }
+        return s;
+      }
+      return null;
+    };
+
+    const activeNow = working(now);
+    const start = activeNow ? now : nextFlip(now, true);
+    if (start === null) return null;
+    const end = nextFlip(start, false);
+    return {
+      activeNow,
+      start: new Date(activeNow ? from.getTime() : start).toISOString(),
+      end: end === null ? null : new Date(end).toISOString(),
+      timezone: schedule.timezone,
+    };
+  }
+
   /**
    * Get all work schedules for an organization.
    */
@@ -314,6 +401,7 @@ export class WorkforceManager {
    * Queried by the guardrails status endpoint.
    */
   isOffDuty(agentId: string): boolean {
+    if (this.getActiveMaintenance(agentId)) return true;
     if (!this.schedules.has(agentId)) return false;
     return this.clockStatus.get(agentId) === 'clocked_out';
   }
@@ -325,6 +413,10 @@ export class WorkforceManager {
    */
   shouldBeWorking(agentId: string): { onDuty: boolean; schedule: WorkSchedule | null; reason: string } {
     const schedule = this.schedules.get(agentId);
+    const window = this.getActiveMaintenance(agentId);
+    if (window) {
+      return { onDuty: false, schedule: schedule || null, reason: `Maintenance window "${window.title}" until ${window.endsAt}` };
+    }
     if (!schedule || !schedule.enabled) {
       return { onDuty: true, schedule: null, reason: 'No schedule defined — always on' };
     }
@@ -523,9 +615,15 @@ export class WorkforceManager {
     // Reset counters as needed
     this.checkAndResetCounters(now);
 
+    await this.processMaintenanceWindows(now).catch(err =>
+      console.error('[workforce] Maintenance window error:', err)
+    );
+
     // Process each enabled schedule
     for (const schedule of this.schedules.values()) {
       if (!schedule.enabled) continue;
+      // Maintenance owns the agent's state until its window closes
+      if (this.getActiveMaintenance(schedule.agentId)) continue;
 
       try {
         const localNow = this.toTimezone(now, schedule.timezone);
@@ -651,6 +749,240 @@ export class WorkforceManager {
     }
   }
 
+  // ─── Maintenance Windows ─────────────────────────────
+
+  /**
+   * Maintenance windows for an org that overlap [from, to], soonest first.
+   * Cancelled windows are left out.
+   */
+  async getMaintenanceWindows(orgId: string, opts?: { from?: string; to?: string; agentId?: string }): Promise<MaintenanceWindow[]> {
+    if (!this.engineDb) {
+      return [...this.maintenance.values()].filter(w => w.orgId === orgId).sort((a, b) => a.startsAt.localeCompare(b.startsAt));
+    }
+    let sql = "SELECT * FROM maintenance_windows WHERE org_id = ? AND status != 'cancelled'";
+    const params: any[] = [orgId];
+    if (opts?.from) {
+      sql += ' AND ends_at >= ?';
+      params.push(opts.from);
+    }
+    if (opts?.to) {
+      sql += ' AND starts_at <= ?';
+      params.push(opts.to);
+    }
+    if (opts?.agentId) {
+      // An agent is also covered by org-wide windows
+      sql += ' AND (agent_id = ? OR agent_id IS NULL)';
+      params.push(opts.agentId);
+    }
+    sql += ' ORDER BY starts_at ASC LIMIT 500';
+    try {
+      const rows = await this.engineDb.query<any>(sql, params);
+      return rows.map((r: any) => this.maintenance.get(r.id) || this.rowToMaintenance(r));
+    } catch {
+      return [];
+    }
+  }
+
+  getMaintenanceWindow(id: string): MaintenanceWindow | undefined {
+    return this.maintenance.get(id);
+  }
+
+  /**
+   * The open maintenance window covering an agent, if any.
+   * Agent-specific windows win over org-wide ones.
+   */
+  getActiveMaintenance(agentId: string): MaintenanceWindow | null {
+    let orgWide: MaintenanceWindow | null = null;
+    for (const w of this.maintenance.values()) {
+      if (w.status !== 'active') continue;
+      if (w.agentId === agentId) return w;
+      if (!w.agentId && !orgWide && this.lifecycle?.getAgent(agentId)?.orgId === w.orgId) orgWide = w;
+    }
+    return orgWide;
+  }
+
+  async createMaintenanceWindow(input: {
+    orgId: string;
+    agentId?: string;
+    title: string;
+    startsAt: string;
+    endsAt: string;
+    notice?: string;
+    createdBy?: string;
+  }): Promise<MaintenanceWindow> {
+    const { startsAt, endsAt } = this.validateWindowTimes(input.startsAt, input.endsAt);
+    const now = new Date().toISOString();
+    const window: MaintenanceWindow = {
+      id: crypto.randomUUID(),
+      orgId: input.orgId,
+      agentId: input.agentId || undefined,
+      title: (input.title || 'Scheduled maintenance').slice(0, 200),
+      startsAt,
+      endsAt,
+      notice: (input.notice || DEFAULT_MAINTENANCE_NOTICE).slice(0, 2000),
+      status: 'scheduled',
+      createdBy: input.createdBy,
+      createdAt: now,
+      updatedAt: now,
+    };
+    this.maintenance.set(window.id, window);
+
+    if (this.engineDb) {
+      await this.engineDb.execute(
+        `INSERT INTO maintenance_windows (id, org_id, agent_id, title, starts_at, ends_at, notice, status, created_by, created_at, updated_at)
+         VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+        [
+          window.id, window.orgId, window.agentId || null, window.title,
+          window.startsAt, window.endsAt, window.notice, window.status,
+          window.createdBy || null, window.createdAt, window.updatedAt,
+        ]
+      );
+    }
+
+    this.emitEvent('maintenance_scheduled', { window });
+    // A window that starts now takes effect immediately rather than on the next tick
+    await this.processMaintenanceWindows(new Date());
+    return window;
+  }
+
+  /**
+   * Reschedule or edit an upcoming or open window.
+   * Completed and cancelled windows can't be changed.
+   */
+  async updateMaintenanceWindow(id: string, patch: Partial<Pick<MaintenanceWindow, 'title' | 'startsAt' | 'endsAt' | 'notice'>>): Promise<MaintenanceWindow | null> {
+    const window = this.maintenance.get(id);
+    if (!window) return null;
+
+    const times = this.validateWindowTimes(patch.startsAt || window.startsAt, patch.endsAt || window.endsAt);
+    if (window.status === 'active' && times.startsAt !== window.startsAt) {
+      throw new Error('Cannot move the start of a window that is already open');
+    }
+    Object.assign(window, {
+      title: patch.title !== undefined ? patch.title.slice(0, 200) : window.title,
+      notice: patch.notice !== undefined ? (patch.notice || DEFAULT_MAINTENANCE_NOTICE).slice(0, 2000) : window.notice,
+      startsAt: times.startsAt,
+      endsAt: times.endsAt,
+      updatedAt: new Date().toISOString(),
+    });
+
+    if (this.engineDb) {
+      await this.engineDb.execute(
+        'UPDATE maintenance_windows SET title = ?, notice = ?, starts_at = ?, ends_at = ?, updated_at = ? WHERE id = ?',
+        [window.title, window.notice, window.startsAt, window.endsAt, window.updatedAt, id]
+      );
+    }
+    await this.processMaintenanceWindows(new Date());
+    return window;
+  }
+
+  /**
+   * Cancel a window. An open window is closed first, so its agents resume.
+   */
+  async cancelMaintenanceWindow(id: string, cancelledBy: string): Promise<MaintenanceWindow | null> {
+    const window = this.maintenance.get(id);
+    if (!window) return null;
+    if (window.status === 'active') await this.endMaintenance(window, cancelledBy);
+    await this.setMaintenanceStatus(window, 'cancelled');
+    this.maintenance.delete(id);
+    this.emitEvent('maintenance_cancelled', { windowId: id, cancelledBy });
+    return window;
+  }
+
+  /**
+   * Open windows whose start has passed and close those whose end has passed.
+   * Called every scheduler tick.
+   */
+  private async processMaintenanceWindows(now: Date): Promise<void> {
+    const nowIso = now.toISOString();
+    for (const window of [...this.maintenance.values()]) {
+      if (window.status === 'scheduled' && window.endsAt <= nowIso) {
+        // Missed entirely (server was down) — nothing to pause or resume
+        await this.setMaintenanceStatus(window, 'completed');
+        this.maintenance.delete(window.id);
+      } else if (window.status === 'scheduled' && window.startsAt <= nowIso) {
+        await this.startMaintenance(window);
+      } else if (window.status === 'active' && window.endsAt <= nowIso) {
+        await this.endMaintenance(window, 'workforce-scheduler');
+        await this.setMaintenanceStatus(window, 'completed');
+        this.maintenance.delete(window.id);
+      }
+    }
+  }
+
+  private maintenanceAgents(window: MaintenanceWindow): string[] {
+    if (window.agentId) return [window.agentId];
+    return this.lifecycle?.getAgentsByOrg(window.orgId).map(a => a.id) || [];
+  }
+
+  private async startMaintenance(window: MaintenanceWindow): Promise<void> {
+    await this.setMaintenanceStatus(window, 'active');
+    const reason = `Maintenance window: ${window.title}`;
+
+    for (const agentId of this.maintenanceAgents(window)) {
+      await this.recordClockEvent(agentId, window.orgId, 'auto_pause', 'maintenance-window', window.startsAt, reason);
+      if (this.guardrails) {
+        try {
+          await this.guardrails.pauseAgent(agentId, reason, 'workforce-scheduler');
+        } catch { /* best effort */ }
+      }
+      await this.setGmailVacation(agentId, true, this.schedules.get(agentId), window.notice).catch(e =>
+        console.warn(`[workforce] ${agentId}: failed to enable maintenance notice: ${e.message}`)
+      );
+    }
+
+    console.log(`[workforce] Maintenance window "${window.title}" started (${window.agentId || 'org-wide'})`);
+    this.emitEvent('maintenance_started', { windowId: window.id, agentId: window.agentId, orgId: window.orgId });
+  }
+
+  /**
+   * Hand each agent back to its work schedule: resume if it should be working
+   * now, otherwise leave it paused with the regular out-of-office reply.
+   */
+  private async endMaintenance(window: MaintenanceWindow, endedBy: string): Promise<void> {
+    window.status = 'completed';
+    for (const agentId of this.maintenanceAgents(window)) {
+      // Still covered by another open window
+      if (this.getActiveMaintenance(agentId)) continue;
+
+      const { onDuty, schedule } = this.shouldBeWorking(agentId);
+      if (onDuty) {
+        await this.recordClockEvent(agentId, window.orgId, 'auto_wake', endedBy, window.endsAt, `Maintenance window ended: ${window.title}`);
+        if (schedule) this.clockStatus.set(agentId, 'clocked_in');
+        if (this.guardrails) {
+          try {
+            await this.guardrails.resumeAgent(agentId, `Maintenance window ended: ${window.title}`, 'workforce-scheduler');
+          } catch { /* agent may not be paused */ }
+        }
+      } else if (schedule) {
+        this.clockStatus.set(agentId, 'clocked_out');
+      }
+      await this.setGmailVacation(agentId, !onDuty, schedule || undefined).catch(e =>
+        console.warn(`[workforce] ${agentId}: failed to reset auto-reply after maintenance: ${e.message}`)
+      );
+    }
+
+    console.log(`[workforce] Maintenance window "${window.title}" ended (${window.agentId || 'org-wide'})`);
+    this.emitEvent('maintenance_ended', { windowId: window.id, agentId: window.agentId, orgId: window.orgId, endedBy });
+  }
+
+  private async setMaintenanceStatus(window: MaintenanceWindow, status: MaintenanceWindow['status']): Promise<void> {
+    window.status = status;
+    window.updatedAt = new Date().toISOString();
+    if (this.engineDb) {
+      await this.engineDb.execute(
+        'UPDATE maintenance_windows SET status = ?, updated_at = ? WHERE id = ?',
+        [status, window.updatedAt, window.id]
+      ).catch((err) => { console.error('[workforce] Failed to persist maintenance window:', err); });
+    }
+  }
+
+  private validateWindowTimes(startsAt: string, endsAt: string): { startsAt: string; endsAt: string } {
+    const start = new Date(startsAt), end = new Date(endsAt);
+    if (isNaN(start.getTime()) || isNaN(end.getTime())) throw new Error('startsAt and endsAt must be valid dates');
+    if (end <= start) throw new Error('endsAt must be after startsAt');
+    return { startsAt: start.toISOString(), endsAt: end.toISOString() };
+  }
+
   // ─── Working Hours Logic ─────────────────────────────
 
   /**
@@ -844,6 +1176,10 @@ export class WorkforceManager {
         schedule,
         nextEvent,
         queuedTasks,
+        maintenance: (() => {
+          const w = this.getActiveMaintenance(schedule.agentId);
+          return w ? { id: w.id, title: w.title, endsAt: w.endsAt } : undefined;
+        })(),
       });
 
       if (status === 'clocked_in') totalClocked++;
@@ -982,7 +1318,7 @@ export class WorkforceManager {
    * Manager emails still get through to the agent via the poller bypass,
    * but they'll also receive the auto-reply from Gmail (acceptable trade-off).
    */
-  private async setGmailVacation(agentId: string, enable: boolean, schedule?: WorkSchedule): Promise<void> {
+  private async setGmailVacation(agentId: string, enable: boolean, schedule?: WorkSchedule, notice?: string): Promise<void> {
     // Get agent's email config for OAuth token
     const agent = this.lifecycle?.getAgent(agentId);
     if (!agent) return;
@@ -1009,13 +1345,29 @@ export class WorkforceManager {
 
     // Build vacation settings
     let body: Record<string, any>;
-    if (enable) {
+    if (enable && notice) {
+      // Maintenance window — use its notice verbatim
+      body = {
+        enableAutoReply: true,
+        responseSubject: `Temporarily unavailable - ${agentName}`,
+        responseBodyPlainText: `${notice}\n\nBest regards,\n${agentName}`,
+        restrictToContacts: false,
+        restrictToDomain: false,
+      };
+    } else if (enable && schedule?.config.outOfOffice?.enabled === false) {
+      // Off duty, but the schedule asks for no auto-reply
+      body = { enableAutoReply: false };
+    } else if (enable) {
       // Calculate next start time from schedule
       const tz = schedule?.timezone || 'UTC';
       const nextStart = this.getNextWorkStart(schedule);
+      const ooo = schedule?.config.outOfOffice;
+      const fill = (text: string) => text
+        .replace(/\{nextStart\}/g, nextStart || 'soon')
+        .replace(/\{hours\}/g, schedule ? `${this.formatScheduleHours(schedule)} (${tz})` : '');
 
-      const responseSubject = `Out of Office - ${agentName}`;
-      const responseBody = [
+      const responseSubject = ooo?.subject?.trim() ? fill(ooo.subject.trim()) : `Out of Office - ${agentName}`;
+      const responseBody = ooo?.message?.trim() ? `${fill(ooo.message.trim())}\n\nBest regards,\n${agentName}` : [
         `Hi,\n`,
         `Thank you for your email. I'm currently outside of my working hours and will respond when I'm back.`,
         schedule ? `\nMy regular working hours are ${this.formatScheduleHours(schedule)} (${tz}).` : '',
@@ -1136,6 +1488,25 @@ export class WorkforceManager {
 
   // ─── Row Mappers ─────────────────────────────────────
 
+  /**
+   * Map a database row to a MaintenanceWindow.
+   */
+  private rowToMaintenance(r: any): MaintenanceWindow {
+    return {
+      id: r.id,
+      orgId: r.org_id,
+      agentId: r.agent_id || undefined,
+      title: r.title,
+      startsAt: new Date(r.starts_at).toISOString(),
+      endsAt: new Date(r.ends_at).toISOString(),
+      notice: r.notice || DEFAULT_MAINTENANCE_NOTICE,
+      status: r.status,
+      createdBy: r.created_by || undefined,
+      createdAt: r.created_at,
+      updatedAt: r.updated_at,
+    };
+  }
+
   /**
    * Map a database row to a QueuedTask.
    */
@@ -0,0 +1,119 @@
+/**
+ * Org Branding — white-label name, logo, and colour
+ *
+ * Settings store `name`, `logoUrl`, `primaryColor` and the uploaded
+ * `branding` assets. They are read once and cached here so every dashboard
+ * page load and server-rendered template can apply them without a DB
+ * round-trip. The cache is dropped whenever settings are saved (configBus
+ * 'settings' event), so changes show up on the next request.
+ */
+
+import type { DatabaseAdapter } from '../db/adapter.js';
+
+export interface Branding {
+  companyName: string;
+  logo?: string;
+  favicon?: string;
+  primaryColor: string;
+  /** Remaining uploaded assets (login_logo, login_bg, icon192, ...) */
+  assets: Record<string, string>;
+}
+
+export interface BrandPalette {
+  color: string;
+  hover: string;
+  soft: string;
+  text: string;
+}
+
+export const DEFAULT_BRANDING: Branding = {
+  companyName: 'AgenticMail',
+  primaryColor: '#6366f1',
+  assets: {},
+};
+
+/** Settings keys that affect branding — a save touching any of them invalidates the cache. */
+export const BRANDING_SETTINGS_KEYS = ['name', 'logoUrl', 'primaryColor', 'branding'];
+
+const HEX_RE = /^#[0-9a-fA-F]{6}$/;
+
+let _db: DatabaseAdapter | null = null;
+let _cached: Branding | null = null;
+let _loading: Promise<Branding> | null = null;
+
+// ─── Loading ─────────────────────────────────────────────
+
+export function setBrandingDb(db: DatabaseAdapter): void {
+  _db = db;
+  invalidateBranding();
+}
+
+export function invalidateBranding(): void {
+  _cached = null;
+  _loading = null;
+}
+
+/** Cached branding; the first call after a settings change re-reads the DB. */
+export async function getBranding(): Promise<Branding> {
+  if (_cached) return _cached;
+  if (!_db) return DEFAULT_BRANDING;
+  if (!_loading) {
+    const pending = _db.getSettings()
+      .then((settings: any) => {
+        const b = resolveBranding(settings);
+        if (_loading === pending) _cached = b;
+        return b;
+      })
+      .catch(() => DEFAULT_BRANDING)
+      .finally(() => { if (_loading === pending) _loading = null; });
+    _loading = pending;
+  }
+  return _loading;
+}
+
+/** Last loaded branding, or the defaults if nothing has been loaded yet. */
+export function getBrandingSync(): Branding {
+  return _cached || DEFAULT_BRANDING;
+}
+
+function resolveBranding(settings: any): Branding {
+  const { logo, favicon, ...assets } = (settings?.branding || {}) as Record<string, string>;
+  return {
+    companyName: settings?.name || DEFAULT_BRANDING.companyName,
+    logo: logo || settings?.logoUrl || undefined,
+    favicon: favicon || undefined,
+    primaryColor: HEX_RE.test(settings?.primaryColor || '') ? settings.primaryColor : DEFAULT_BRANDING.primaryColor,
+    assets,
+  };
+}
+
+// ─── Rendering ───────────────────────────────────────────
+
+/** Same derivation as applyBrandColor() in dashboard/components/utils.js. */
+export function brandPalette(hex: string): BrandPalette {
+  if (!HEX_RE.test(hex)) hex = DEFAULT_BRANDING.primaryColor;
+  const r = parseInt(hex.slice(1, 3), 16), g = parseInt(hex.slice(3, 5), 16), b = parseInt(hex.slice(5, 7), 16);
+  const lighten = (v: number) => Math.min(255, v + Math.round((255 - v) * 0.35));
+  return {
+    color: hex,
+    hover: `rgb(${Math.round(r * 0.85)},${Math.round(g * 0.85)},${Math.round(b * 0.85)})`,
+    soft: `rgba(${r},${g},${b},0.15)`,
+    text: `rgb(${lighten(r)},${lighten(g)},${lighten(b)})`,
+  };
+}
+
+/** <head> markup for the dashboard: brand CSS variables and favicon. */
+export function brandingHead(b: Branding): string {
+  const p = brandPalette(b.primaryColor);
+  let head = `<style id="em-branding">:root{--brand-color:${p.color};--brand-hover:${p.hover};--brand-soft:${p.soft};--brand-text:${p.text}}</style>`;
+  if (b.favicon) head += `<link rel="icon" href="${b.favicon.replace(/&/g, '&amp;').replace(/"/g, '&quot;').replace(/</g, '&lt;')}"/>`;
+  return head;
+}
+
+/** Client-side view injected as window.__EM_BRANDING__ (legacy flat shape). */
+export function brandingForClient(b: Branding): Record<string, string> {
+  const out: Record<string, string> = { ...b.assets, companyName: b.companyName, primaryColor: b.primaryColor };
+  if (b.logo) out.logo = b.logo;
+  if (b.favicon) out.favicon = b.favicon;
+  return out;
+}
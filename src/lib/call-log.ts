@@ -0,0 +1,86 @@
+/**
+ * Dashboard Call Log
+ *
+ * In-memory ledger mapping each user-initiated dashboard action (a click or
+ * form submit, identified by the X-Action-Id header the dashboard sends) to
+ * the backend API calls it produced. Used by owners to debug "the UI said
+ * saved but nothing changed" reports.
+ *
+ * Bounded ring buffer — this is a debugging aid, not an audit trail.
+ */
+
+// ─── Types ───────────────────────────────────────────────
+
+export interface CallLogEntry {
+  actionId: string;
+  actionLabel: string;
+  page?: string;
+  userId?: string;
+  method: string;
+  path: string;
+  status: number;
+  durationMs: number;
+  requestId?: string;
+  traceId?: string;
+  error?: string;
+  timestamp: string;
+}
+
+export interface CallLogAction {
+  actionId: string;
+  label: string;
+  page?: string;
+  userId?: string;
+  startedAt: string;
+  failed: number;
+  calls: Array<Omit<CallLogEntry, 'actionId' | 'actionLabel' | 'page' | 'userId'>>;
+}
+
+// ─── Store ───────────────────────────────────────────────
+
+const MAX_ENTRIES = 5_000;
+
+const _entries: CallLogEntry[] = [];
+
+export function recordCall(entry: CallLogEntry): void {
+  _entries.push(entry);
+  if (_entries.length > MAX_ENTRIES) _entries.splice(0, _entries.length - MAX_ENTRIES);
+}
+
+/**
+ * Calls grouped by action, newest action first.
+ */
+export function listActions(opts: { userId?: string; search?: string; failedOnly?: boolean; limit?: number } = {}): CallLogAction[] {
+  const limit = Math.min(Math.max(opts.limit || 100, 1), 500);
+  const q = opts.search?.toLowerCase();
+  const byId = new Map<string, CallLogAction>();
+
+  for (let i = _entries.length - 1; i >= 0; i--) {
+    const e = _entries[i];
+    if (opts.userId && e.userId !== opts.userId) continue;
+    let action = byId.get(e.actionId);
+    if (!action) {
+      if (byId.size >= limit * 2) break; // enough candidates to fill the page after filtering
+      action = { actionId: e.actionId, label: e.actionLabel, page: e.page, userId: e.userId, startedAt: e.timestamp, failed: 0, calls: [] };
+      byId.set(e.actionId, action);
+    }
+    action.calls.unshift({ method: e.method, path: e.path, status: e.status, durationMs: e.durationMs, requestId: e.requestId, traceId: e.traceId, error: e.error, timestamp: e.timestamp });
+    action.startedAt = e.timestamp;
+    if (e.status >= 400) action.failed++;
+  }
+
+  let actions = [...byId.values()];
+  if (opts.failedOnly) actions = actions.filter(a => a.failed > 0);
+  if (q) {
+    actions = actions.filter(a =>
+      a.label.toLowerCase().includes(q) ||
+      (a.page || '').toLowerCase().includes(q) ||
+      a.calls.some(c => c.path.toLowerCase().includes(q) || (c.error || '').toLowerCase().includes(q)),
+    );
+  }
+  return actions.slice(0, limit);
+}
+
+export function clearCallLog(): void {
+  _entries.length = 0;
+}
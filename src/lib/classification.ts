@@ -0,0 +1,51 @@
+/**
+ * Data Classification Levels
+ *
+ * The four labels that knowledge bases, role templates and mailboxes can
+ * carry, in increasing order of sensitivity. Policies (DLP rules, the
+ * egress filter) take a minimum level and apply to anything at or above it.
+ *
+ * Policy code outside the engine asks for an agent's level through the
+ * resolver the engine registers at startup; with no engine in the process
+ * (a standalone agent) every agent reads as unclassified and the
+ * classification-keyed parts of those policies don't apply.
+ */
+
+export const CLASSIFICATION_LEVELS = ['public', 'internal', 'confidential', 'restricted'] as const;
+
+export type ClassificationLevel = typeof CLASSIFICATION_LEVELS[number];
+
+export function isClassificationLevel(v: any): v is ClassificationLevel {
+  return CLASSIFICATION_LEVELS.includes(v);
+}
+
+/** -1 for unclassified, then 0 (public) to 3 (restricted) */
+export function classificationRank(level?: ClassificationLevel | null): number {
+  return level ? CLASSIFICATION_LEVELS.indexOf(level) : -1;
+}
+
+/** True when `level` is set and at least `min`. No minimum means everything qualifies. */
+export function meetsClassification(level: ClassificationLevel | null | undefined, min?: ClassificationLevel | null): boolean {
+  if (!min) return true;
+  return classificationRank(level) >= classificationRank(min);
+}
+
+export function highestClassification(levels: Array<ClassificationLevel | null | undefined>): ClassificationLevel | null {
+  let best: ClassificationLevel | null = null;
+  for (const l of levels) if (classificationRank(l) > classificationRank(best)) best = l!;
+  return best;
+}
+
+// ─── Agent Resolver ─────────────────────────────────────
+
+let _resolveAgent: ((agentId: string) => ClassificationLevel | null) | null = null;
+
+export function setAgentClassificationResolver(fn: (agentId: string) => ClassificationLevel | null): void {
+  _resolveAgent = fn;
+}
+
+/** The highest classification of the data an agent handles, or null if unknown */
+export function agentClassification(agentId?: string): ClassificationLevel | null {
+  if (!agentId || !_resolveAgent) return null;
+  try { return _resolveAgent(agentId); } catch { return null; }
+}
@@ -0,0 +1,167 @@
+/**
+ * Dashboard Plugins
+ *
+ * Lets self-hosters add pages to the dashboard without forking it. Each
+ * plugin is a directory under DASHBOARD_PLUGINS_DIR (default
+ * ~/.agenticmail/dashboard-plugins) holding a plugin.json manifest and the
+ * ES modules it serves:
+ *
+ *   acme-reports/
+ *     plugin.json   { "id": "acme-reports", "name": "Acme Reports", "version": "1.2.0",
+ *                     "entry": "index.js",
+ *                     "pages": [{ "id": "weekly", "label": "Weekly Report",
+ *                                 "section": "Operations", "icon": "chart", "minRole": "admin" }] }
+ *     index.js      export default function register(api) {
+ *                     api.registerPage({ id: 'weekly', component: WeeklyReport });
+ *                   }
+ *
+ * The manifest decides who sees a page (minRole, filtered here on the
+ * server); the entry module supplies the component through the registration
+ * API in dashboard/components/plugins.js, which also hands it h(), the API
+ * client and the shared components. Files are served from
+ * /dashboard/plugins/<id>/ and the pages live at /dashboard/plugins/<id>/<page>.
+ *
+ * Plugins are read once at startup; an owner can rescan without a restart.
+ */
+
+import { existsSync, readdirSync, readFileSync, statSync } from 'fs';
+import { homedir } from 'os';
+import { join, resolve, sep } from 'path';
+
+export type PluginRole = 'viewer' | 'member' | 'admin' | 'owner';
+
+export interface DashboardPluginPage {
+  id: string;
+  label: string;
+  /** Sidebar section to list the page under; a new section is added if none matches (default: "Plugins") */
+  section: string;
+  /** Name of a dashboard icon (icons.js); defaults to a generic one */
+  icon?: string;
+  /** Lowest role that sees the page (default: member) */
+  minRole: PluginRole;
+}
+
+export interface DashboardPlugin {
+  id: string;
+  name: string;
+  version: string;
+  description?: string;
+  /** Entry module, relative to the plugin directory */
+  entry: string;
+  pages: DashboardPluginPage[];
+  dir: string;
+}
+
+const ID_RE = /^[a-z0-9-]{1,40}$/;
+const ROLE_RANK: Record<PluginRole, number> = { viewer: 0, member: 1, admin: 2, owner: 3 };
+const MAX_PAGES_PER_PLUGIN = 20;
+
+let _plugins: DashboardPlugin[] | null = null;
+let _errors: Array<{ dir: string; error: string }> = [];
+
+export function pluginsDir(): string {
+  return process.env.DASHBOARD_PLUGINS_DIR || join(homedir(), '.agenticmail', 'dashboard-plugins');
+}
+
+function parseManifest(dir: string): DashboardPlugin {
+  const manifestPath = join(dir, 'plugin.json');
+  if (!existsSync(manifestPath)) throw new Error('missing plugin.json');
+  const m = JSON.parse(readFileSync(manifestPath, 'utf-8'));
+  if (!ID_RE.test(m.id || '')) throw new Error('id must be 1-40 lowercase letters, digits or dashes');
+  const entry = String(m.entry || 'index.js');
+  if (!entry.endsWith('.js') || !resolvePath(dir, entry)) throw new Error(`entry "${entry}" must be a .js file inside the plugin directory`);
+  if (!existsSync(join(dir, entry))) throw new Error(`entry "${entry}" not found`);
+  if (!Array.isArray(m.pages) || m.pages.length === 0) throw new Error('pages must be a non-empty array');
+  if (m.pages.length > MAX_PAGES_PER_PLUGIN) throw new Error(`at most ${MAX_PAGES_PER_PLUGIN} pages per plugin`);
+
+  const seen = new Set<string>();
+  const pages = m.pages.map((p: any): DashboardPluginPage => {
+    if (!ID_RE.test(p?.id || '')) throw new Error(`page id "${p?.id}" must be 1-40 lowercase letters, digits or dashes`);
+    if (seen.has(p.id)) throw new Error(`duplicate page id "${p.id}"`);
+    seen.add(p.id);
+    if (p.minRole !== undefined && !(p.minRole in ROLE_RANK)) throw new Error(`page "${p.id}": minRole must be viewer, member, admin or owner`);
+    return {
+      id: p.id,
+      label: String(p.label || p.id).slice(0, 60),
+      section: String(p.section || 'Plugins').slice(0, 40),
+      icon: typeof p.icon === 'string' ? p.icon : undefined,
+      minRole: p.minRole || 'member',
+    };
+  });
+
+  return {
+    id: m.id,
+    name: String(m.name || m.id),
+    version: String(m.version || '0.0.0'),
+    description: m.description ? String(m.description) : undefined,
+    entry,
+    pages,
+    dir,
+  };
+}
+
+/** Rescan the plugins directory. Broken plugins are skipped and reported. */
+export function reloadPlugins(): { plugins: DashboardPlugin[]; errors: Array<{ dir: string; error: string }> } {
+  const root = pluginsDir();
+  const plugins: DashboardPlugin[] = [];
+  const errors: Array<{ dir: string; error: string }> = [];
+  if (existsSync(root)) {
+    for (const name of readdirSync(root).sort()) {
+      const dir = join(root, name);
+      try {
+        if (!statSync(dir).isDirectory()) continue;
+        const plugin = parseManifest(dir);
+        if (plugins.some(p => p.id === plugin.id)) throw new Error(`duplicate plugin id "${plugin.id}"`);
+        plugins.push(plugin);
+      } catch (err: any) {
+        errors.push({ dir: name, error: err.message });
+        console.warn(`[plugins] Skipping dashboard plugin "${name}": ${err.message}`);
+      }
+    }
+  }
+  if (plugins.length) console.log(`[plugins] Loaded ${plugins.length} dashboard plugin(s): ${plugins.map(p => p.id).join(', ')}`);
+  _plugins = plugins;
+  _errors = errors;
+  return { plugins, errors };
+}
+
+export function listPlugins(): DashboardPlugin[] {
+  return _plugins ?? reloadPlugins().plugins;
+}
+
+export function pluginErrors(): Array<{ dir: string; error: string }> {
+  if (!_plugins) reloadPlugins();
+  return _errors;
+}
+
+/**
+ * Plugins as the dashboard sees them: only the pages `role` may open, and
+ * no plugin at all when none of its pages are visible. API keys see all.
+ */
+export function pluginsForRole(role: string | undefined, apiKey = false) {
+  const rank = ROLE_RANK[role as PluginRole] ?? -1;
+  return listPlugins()
+    .map(p => ({
+      id: p.id,
+      name: p.name,
+      version: p.version,
+      description: p.description,
+      entry: `/dashboard/plugins/${p.id}/${p.entry}?v=${encodeURIComponent(p.version)}`,
+      pages: p.pages.filter(page => apiKey || rank >= ROLE_RANK[page.minRole]),
+    }))
+    .filter(p => p.pages.length > 0);
+}
+
+function resolvePath(dir: string, relPath: string): string | null {
+  const root = resolve(dir);
+  const full = resolve(root, relPath);
+  return full.startsWith(root + sep) ? full : null;
+}
+
+/** Absolute path of a file a plugin serves, or null if it isn't one. */
+export function resolvePluginFile(id: string, relPath: string): string | null {
+  const plugin = listPlugins().find(p => p.id === id);
+  if (!plugin) return null;
+  const full = resolvePath(plugin.dir, relPath);
+  return full && existsSync(full) && statSync(full).isFile() ? full : null;
+}
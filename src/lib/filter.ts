@@ -0,0 +1,28 @@
+/**
+ * List Filtering
+ *
+ * Text search for the dashboard list endpoints (?q=). Every whitespace-
+ * separated term must appear, case-insensitively, in at least one of the
+ * row's searchable fields — "ops bot" matches an agent named "Ops Bot" as
+ * well as one named "bot" with an ops@ address.
+ */
+
+const MAX_QUERY_LENGTH = 200;
+
+/** Normalised search terms from ?q=, or [] when there's nothing to search for. */
+export function parseQuery(q: string | undefined | null): string[] {
+  if (!q) return [];
+  return q.slice(0, MAX_QUERY_LENGTH).toLowerCase().split(/\s+/).filter(Boolean);
+}
+
+export function matchesQuery(terms: string[], values: unknown[]): boolean {
+  if (terms.length === 0) return true;
+  const haystack = values.filter(v => v !== null && v !== undefined).map(v => String(v).toLowerCase());
+  return terms.every(t => haystack.some(v => v.includes(t)));
+}
+
+/** Filter rows by ?q= over the fields `fields(row)` returns. */
+export function filterByQuery<T>(rows: T[], q: string | undefined | null, fields: (row: T) => unknown[]): T[] {
+  const terms = parseQuery(q);
+  return terms.length ? rows.filter(r => matchesQuery(terms, fields(r))) : rows;
+}
@@ -0,0 +1,61 @@
+/**
+ * Flash Messages
+ *
+ * One-shot notices that survive a redirect ("Signed in with SSO", "Email
+ * connection failed: …"). A handler queues a message before redirecting; the
+ * dashboard layout reads the em_flash cookie on its next load, shows each
+ * message as a toast and clears the cookie.
+ *
+ * The cookie is readable by the dashboard (not httpOnly) and only ever holds
+ * short display text — never put tokens or secrets in a flash message.
+ */
+
+import type { Context } from 'hono';
+import { getCookie, setCookie } from 'hono/cookie';
+
+// ─── Types ───────────────────────────────────────────────
+
+export type FlashType = 'success' | 'error' | 'warning' | 'info';
+
+export interface FlashMessage {
+  type: FlashType;
+  message: string;
+}
+
+// ─── Config ──────────────────────────────────────────────
+
+export const FLASH_COOKIE = 'em_flash';
+const MAX_MESSAGES = 5;
+const MAX_LENGTH = 300;
+/** Long enough to cover the redirect, short enough not to resurface later */
+const FLASH_TTL_SECONDS = 120;
+
+// ─── API ─────────────────────────────────────────────────
+
+export function readFlash(c: Context): FlashMessage[] {
+  const raw = getCookie(c, FLASH_COOKIE);
+  if (!raw) return [];
+  try {
+    const parsed = JSON.parse(raw);
+    return Array.isArray(parsed) ? parsed.filter(m => m && typeof m.message === 'string') : [];
+  } catch {
+    return [];
+  }
+}
+
+/** Queue a message for the next page the user lands on. */
+export function flash(c: Context, type: FlashType, message: string): void {
+  const messages = [...readFlash(c), { type, message: message.slice(0, MAX_LENGTH) }].slice(-MAX_MESSAGES);
+  setCookie(c, FLASH_COOKIE, JSON.stringify(messages), {
+    httpOnly: false,
+    secure: process.env.NODE_ENV === 'production' || process.env.SECURE_COOKIES === '1',
+    sameSite: 'Lax',
+    path: '/',
+    maxAge: FLASH_TTL_SECONDS,
+  });
+}
+
+export function redirectWithFlash(c: Context, url: string, type: FlashType, message: string) {
+  flash(c, type, message);
+  return c.redirect(url);
+}
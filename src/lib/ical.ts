@@ -0,0 +1,91 @@
+/**
+ * iCalendar (RFC 5545) Export
+ *
+ * Minimal VCALENDAR writer for feeds that calendar clients import or
+ * subscribe to. Handles the parts clients are strict about: CRLF line
+ * endings, text escaping, 75-octet line folding and UTC timestamps.
+ */
+
+export interface ICalEvent {
+  /** Stable across exports so re-imports update rather than duplicate */
+  uid: string;
+  start: string | Date;
+  /** Omit for a point-in-time event */
+  end?: string | Date;
+  summary: string;
+  description?: string;
+  location?: string;
+  categories?: string[];
+  status?: 'TENTATIVE' | 'CONFIRMED' | 'CANCELLED';
+  url?: string;
+  updatedAt?: string | Date;
+}
+
+export interface ICalOptions {
+  /** Shown by clients as the calendar name */
+  name: string;
+  description?: string;
+  prodId?: string;
+}
+
+const PRODID = '-//AgenticMail//Enterprise//EN';
+
+/** 20261016T093000Z */
+export function formatICalDate(value: string | Date): string {
+  return new Date(value).toISOString().replace(/[-:]/g, '').replace(/\.\d{3}/, '');
+}
+
+export function escapeICalText(text: string): string {
+  return text.replace(/\\/g, '\\\\').replace(/;/g, '\\;').replace(/,/g, '\\,').replace(/\r?\n/g, '\\n');
+}
+
+/** Fold to 75 octets per line; continuation lines start with a space. */
+function foldLine(line: string): string {
+  const bytes = Buffer.from(line, 'utf8');
+  if (bytes.length <= 75) return line;
+  const parts: string[] = [];
+  let current = '';
+  let size = 0;
+  for (const ch of line) {
+    const len = Buffer.byteLength(ch, 'utf8');
+    const limit = parts.length === 0 ? 75 : 74;
+    if (size + len > limit) {
+      parts.push(current);
+      current = '';
+      size = 0;
+    }
+    current += ch;
+    size += len;
+  }
+  parts.push(current);
+  return parts.join('\r\n ');
+}
+
+export function buildICalendar(events: ICalEvent[], opts: ICalOptions): string {
+  const stamp = formatICalDate(new Date());
+  const lines: string[] = [
+    'BEGIN:VCALENDAR',
+    'VERSION:2.0',
+    `PRODID:${opts.prodId || PRODID}`,
+    'CALSCALE:GREGORIAN',
+    'METHOD:PUBLISH',
+    `X-WR-CALNAME:${escapeICalText(opts.name)}`,
+  ];
+  if (opts.description) lines.push(`X-WR-CALDESC:${escapeICalText(opts.description)}`);
+
+  for (const ev of events) {
+    lines.push('BEGIN:VEVENT', `UID:${ev.uid}`, `DTSTAMP:${stamp}`, `DTSTART:${formatICalDate(ev.start)}`);
+    if (ev.end) lines.push(`DTEND:${formatICalDate(ev.end)}`);
+    lines.push(`SUMMARY:${escapeICalText(ev.summary)}`);
+    if (ev.description) lines.push(`DESCRIPTION:${escapeICalText(ev.description)}`);
+    if (ev.location) lines.push(`LOCATION:${escapeICalText(ev.location)}`);
+    if (ev.categories?.length) lines.push(`CATEGORIES:${ev.categories.map(escapeICalText).join(',')}`);
+    if (ev.status) lines.push(`STATUS:${ev.status}`);
+    if (ev.url) lines.push(`URL:${ev.url}`);
+    if (ev.updatedAt) lines.push(`LAST-MODIFIED:${formatICalDate(ev.updatedAt)}`);
+    lines.push('END:VEVENT');
+  }
+
+  lines.push('END:VCALENDAR');
+  return lines.map(foldLine).join('\r\n') + '\r\n';
+}
@@ -0,0 +1,87 @@
+/**
+ * Minimal Markdown Rendering
+ *
+ * Converts the small markdown subset the dashboard composer produces
+ * (headings, bold/italic, inline + fenced code, links, lists, quotes) into
+ * HTML for email delivery, plus a plain-text alternative part.
+ *
+ * Input is HTML-escaped before any markup is added, so the output never
+ * contains tags the author typed. Links are restricted to http(s)/mailto.
+ * Keep in sync with dashboard/components/markdown.js.
+ */
+
+import { escapeHtml } from './templates.js';
+
+const SAFE_URL_RE = /^(https?:|mailto:)/i;
+
+function inline(text: string): string {
+  return text
+    .replace(/`([^`]+)`/g, '<code>$1</code>')
+    .replace(/\*\*(.+?)\*\*/g, '<strong>$1</strong>')
+    .replace(/(^|[^*])\*([^*\s][^*]*?)\*/g, '$1<em>$2</em>')
+    .replace(/~~(.+?)~~/g, '<del>$1</del>')
+    .replace(/\[([^\]]+)\]\(([^)\s]+)\)/g, (m, label: string, url: string) => {
+      // url is already escaped; &amp; etc. are fine inside href
+      return SAFE_URL_RE.test(url) ? `<a href="${url}" target="_blank" rel="noopener noreferrer">${label}</a>` : m;
+    });
+}
+
+/** Render markdown to sanitized HTML. */
+export function renderMarkdown(md: string): string {
+  const lines = escapeHtml(md || '').replace(/\r\n?/g, '\n').split('\n');
+  const out: string[] = [];
+  let para: string[] = [];
+  let list: { tag: 'ul' | 'ol'; items: string[] } | null = null;
+  let code: string[] | null = null;
+
+  const flushPara = () => { if (para.length) { out.push(`<p>${inline(para.join('<br>'))}</p>`); para = []; } };
+  const flushList = () => { if (list) { out.push(`<${list.tag}>${list.items.map(i => `<li>${inline(i)}</li>`).join('')}</${list.tag}>`); list = null; } };
+
+  for (const line of lines) {
+    if (code) {
+      if (/^```/.test(line)) { out.push(`<pre><code>${code.join('\n')}</code></pre>`); code = null; }
+      else code.push(line);
+      continue;
+    }
+    if (/^```/.test(line)) { flushPara(); flushList(); code = []; continue; }
+
+    const heading = line.match(/^(#{1,3})\s+(.+)$/);
+    const bullet = line.match(/^\s*[-*]\s+(.+)$/);
+    const numbered = line.match(/^\s*\d+[.)]\s+(.+)$/);
+    const quote = line.match(/^&gt;\s?(.*)$/);
+
+    if (heading) { flushPara(); flushList(); out.push(`<h${heading[1].length}>${inline(heading[2])}</h${heading[1].length}>`); }
+    else if (bullet || numbered) {
+      flushPara();
+      const tag = bullet ? 'ul' : 'ol';
+      if (list && list.tag !== tag) flushList();
+      if (!list) list = { tag, items: [] };
+      list.items.push((bullet || numbered)![1]);
+    }
+    else if (quote) { flushPara(); flushList(); out.push(`<blockquote>${inline(quote[1])}</blockquote>`); }
+    else if (/^(-{3,}|\*{3,})$/.test(line.trim())) { flushPara(); flushList(); out.push('<hr>'); }
+    else if (!line.trim()) { flushPara(); flushList(); }
+    else { flushList(); para.push(line); }
+  }
+  if (code) out.push(`<pre><code>${code.join('\n')}</code></pre>`);
+  flushPara();
+  flushList();
+  return out.join('\n');
+}
+
+/** Plain-text alternative: strips markdown syntax, keeps link targets. */
+export function markdownToText(md: string): string {
+  return (md || '')
+    .replace(/\r\n?/g, '\n')
+    .replace(/^```.*$/gm, '')
+    .replace(/^#{1,3}\s+/gm, '')
+    .replace(/^\s*[-*]\s+/gm, '• ')
+    .replace(/^>\s?/gm, '')
+    .replace(/\[([^\]]+)\]\(([^)\s]+)\)/g, '$1 ($2)')
+    .replace(/\*\*(.+?)\*\*/g, '$1')
+    .replace(/(^|[^*])\*([^*\s][^*]*?)\*/g, '$1$2')
+    .replace(/~~(.+?)~~/g, '$1')
+    .replace(/`([^`]+)`/g, '$1')
+    .replace(/\n{3,}/g, '\n\n')
+    .trim();
+}
@@ -0,0 +1,60 @@
+/**
+ * Mail-Merge Variables
+ *
+ * Resolves {{namespace.field}} placeholders in message subjects and bodies
+ * against per-recipient data. Unknown variables are reported up front so a
+ * batch is never sent with literal "{{contact.nmae}}" in it.
+ *
+ * Keep VARIABLES in sync with dashboard/components/merge-vars.js.
+ */
+
+export const MERGE_VARIABLES = [
+  'contact.name', 'contact.email', 'contact.role', 'contact.firstName',
+  'agent.name', 'agent.email', 'agent.role',
+  'org.name',
+  'date',
+] as const;
+
+export type MergeContext = Record<string, string | undefined>;
+
+const VAR_RE = /\{\{\s*([\w.]+)\s*\}\}/g;
+
+/** Distinct variable names referenced in the given texts. */
+export function extractVariables(...texts: string[]): string[] {
+  const found = new Set<string>();
+  for (const text of texts) {
+    for (const m of (text || '').matchAll(VAR_RE)) found.add(m[1]);
+  }
+  return [...found];
+}
+
+/** Variables in the texts that aren't in the known list. */
+export function unknownVariables(texts: string[], known: readonly string[] = MERGE_VARIABLES): string[] {
+  const allowed = new Set<string>(known);
+  return extractVariables(...texts).filter(v => !allowed.has(v));
+}
+
+/** Replace placeholders; missing values render as an empty string. */
+export function applyMergeVariables(text: string, ctx: MergeContext): string {
+  return (text || '').replace(VAR_RE, (_m, key: string) => ctx[key] ?? '');
+}
+
+/** Build a merge context from the sender/recipient records. */
+export function buildMergeContext(opts: {
+  contact: { name?: string; email?: string; role?: string };
+  agent: { name?: string; email?: string; role?: string };
+  orgName?: string;
+}): MergeContext {
+  const name = opts.contact.name || '';
+  return {
+    'contact.name': name,
+    'contact.firstName': name.split(/\s+/)[0] || '',
+    'contact.email': opts.contact.email,
+    'contact.role': opts.contact.role,
+    'agent.name': opts.agent.name,
+    'agent.email': opts.agent.email,
+    'agent.role': opts.agent.role,
+    'org.name': opts.orgName,
+    'date': new Date().toLocaleDateString('en-US', { year: 'numeric', month: 'long', day: 'numeric' }),
+  };
+}
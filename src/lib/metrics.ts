@@ -0,0 +1,195 @@
+/**
+ * Prometheus Metrics
+ *
+ * Dependency-free counters, gauges and histograms rendered in the Prometheus
+ * text exposition format (v0.0.4) at GET /metrics.
+ *
+ * Focus is the health of the API as the dashboard sees it: request latency and
+ * error rate per route, plus latency, errors, retries and circuit-breaker state
+ * for the backends those requests depend on (database, engine).
+ */
+
+// ─── Types ───────────────────────────────────────────────
+
+type Labels = Record<string, string | number>;
+
+interface Metric {
+  name: string;
+  help: string;
+  type: 'counter' | 'gauge' | 'histogram';
+  render(): string[];
+}
+
+function labelKey(labels: Labels): string {
+  return Object.keys(labels).sort().map(k => `${k}="${escapeLabel(String(labels[k]))}"`).join(',');
+}
+
+function escapeLabel(v: string): string {
+  return v.replace(/\\/g, '\\\\').replace(/\n/g, '\\n').replace(/"/g, '\\"');
+}
+
+function fmt(name: string, key: string, value: number): string {
+  return `${name}${key ? `{${key}}` : ''} ${Number.isFinite(value) ? value : value > 0 ? '+Inf' : '-Inf'}`;
+}
+
+// ─── Registry ────────────────────────────────────────────
+
+const _registry = new Map<string, Metric>();
+const _collectors: Array<() => void> = [];
+
+function register<T extends Metric>(metric: T): T {
+  const existing = _registry.get(metric.name);
+  if (existing) return existing as T;
+  _registry.set(metric.name, metric);
+  return metric;
+}
+
+/** Register a callback that refreshes gauges right before each scrape. */
+export function onCollect(fn: () => void): void {
+  _collectors.push(fn);
+}
+
+/** Render every registered metric in Prometheus text format. */
+export function renderMetrics(): string {
+  for (const fn of _collectors) {
+    try { fn(); } catch { /* a broken collector must not break the scrape */ }
+  }
+  const lines: string[] = [];
+  for (const m of _registry.values()) {
+    lines.push(`# HELP ${m.name} ${m.help}`, `# TYPE ${m.name} ${m.type}`, ...m.render());
+  }
+  return lines.join('\n') + '\n';
+}
+
+// ─── Metric Types ────────────────────────────────────────
+
+export class Counter implements Metric {
+  readonly type = 'counter' as const;
+  private values = new Map<string, number>();
+  constructor(readonly name: string, readonly help: string) {}
+
+  inc(labels: Labels = {}, by = 1): void {
+    const key = labelKey(labels);
+    this.values.set(key, (this.values.get(key) || 0) + by);
+  }
+
+  render(): string[] {
+    return [...this.values].map(([k, v]) => fmt(this.name, k, v));
+  }
+}
+
+export class Gauge implements Metric {
+  readonly type = 'gauge' as const;
+  private values = new Map<string, number>();
+  constructor(readonly name: string, readonly help: string) {}
+
+  set(labels: Labels, value: number): void {
+    this.values.set(labelKey(labels), value);
+  }
+
+  render(): string[] {
+    return [...this.values].map(([k, v]) => fmt(this.name, k, v));
+  }
+}
+
+export const DEFAULT_BUCKETS = [0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10];
+
+export class Histogram implements Metric {
+  readonly type = 'histogram' as const;
+  private series = new Map<string, { counts: number[]; sum: number; count: number }>();
+  constructor(readonly name: string, readonly help: string, private readonly buckets: number[] = DEFAULT_BUCKETS) {}
+
+  observe(labels: Labels, value: number): void {
+    const key = labelKey(labels);
+    let s = this.series.get(key);
+    if (!s) {
+      s = { counts: new Array(this.buckets.length).fill(0), sum: 0, count: 0 };
+      this.series.set(key, s);
+    }
+    for (let i = 0; i < this.buckets.length; i++) {
+      if (value <= this.buckets[i]) s.counts[i]++;
+    }
+    s.sum += value;
+    s.count++;
+  }
+
+  /** Start a timer; call the returned function to observe elapsed seconds. */
+  startTimer(labels: Labels = {}): (extra?: Labels) => number {
+    const start = performance.now();
+    return (extra?: Labels) => {
+      const seconds = (performance.now() - start) / 1000;
+      this.observe({ ...labels, ...extra }, seconds);
+      return seconds;
+    };
+  }
+
+  render(): string[] {
+    const out: string[] = [];
+    for (const [key, s] of this.series) {
+      const prefix = key ? key + ',' : '';
+      this.buckets.forEach((b, i) => out.push(fmt(`${this.name}_bucket`, `${prefix}le="${b}"`, s.counts[i])));
+      out.push(fmt(`${this.name}_bucket`, `${prefix}le="+Inf"`, s.count));
+      out.push(fmt(`${this.name}_sum`, key, s.sum));
+      out.push(fmt(`${this.name}_count`, key, s.count));
+    }
+    return out;
+  }
+}
+
+export function counter(name: string, help: string): Counter {
+  return register(new Counter(name, help));
+}
+
+export function gauge(name: string, help: string): Gauge {
+  return register(new Gauge(name, help));
+}
+
+export function histogram(name: string, help: string, buckets?: number[]): Histogram {
+  return register(new Histogram(name, help, buckets));
+}
+
+// ─── Built-in Metrics ────────────────────────────────────
+
+export const httpRequestsTotal = counter(
+  'enterprise_http_requests_total',
+  'HTTP requests handled, by method, route pattern and status code.',
+);
+
+export const httpRequestDuration = histogram(
+  'enterprise_http_request_duration_seconds',
+  'HTTP request latency by method and route pattern.',
+);
+
+export const backendCallDuration = histogram(
+  'enterprise_backend_call_duration_seconds',
+  'Latency of calls to backend dependencies, by backend and outcome (success|error|rejected).',
+);
+
+export const backendErrorsTotal = counter(
+  'enterprise_backend_errors_total',
+  'Failed backend calls, including calls rejected by an open circuit breaker.',
+);
+
+export const backendRetriesTotal = counter(
+  'enterprise_backend_retries_total',
+  'Retry attempts made against backend dependencies.',
+);
+
+export const circuitBreakerState = gauge(
+  'enterprise_circuit_breaker_state',
+  'Circuit breaker state: 0 = closed, 1 = half-open, 2 = open.',
+);
+
+export const coalescedRequestsTotal = counter(
+  'enterprise_http_coalesced_requests_total',
+  'API reads answered from an identical in-flight request instead of running the handler again.',
+);
+
+export const dashboardRenderDuration = histogram(
+  'enterprise_dashboard_render_duration_seconds',
+  'Dashboard page render time reported by the browser, by page.',
+  [0.25, 0.5, 1, 2, 3, 5, 8, 13, 20],
+);
+
+const processUptime = gauge('enterprise_process_uptime_seconds', 'Seconds since the process started.');
+onCollect(() => processUptime.set({}, Math.round(process.uptime())));
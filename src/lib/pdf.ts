@@ -0,0 +1,285 @@
+/**
+ * PDF Export
+ *
+ * Minimal dependency-free PDF writer for the report downloads (compliance
+ * reports, audit log). It lays out headings, paragraphs, key/value blocks
+ * and tables on A4 pages using the built-in Helvetica fonts, so nothing
+ * is embedded and files stay small. Tables wrap cell text, repeat their
+ * header row on each page, and every page gets a "Page n of N" footer.
+ *
+ * Text is encoded as WinAnsi: Latin-1 plus the usual typographic
+ * punctuation. Anything outside that renders as '?'.
+ */
+
+export interface PdfOptions {
+  title: string;
+  /** Printed in the page footer; defaults to the title */
+  footer?: string;
+  landscape?: boolean;
+  author?: string;
+}
+
+export interface PdfTableOptions {
+  /** Relative column weights; derived from the content when omitted */
+  weights?: number[];
+  /** Lines a cell may wrap to before it is cut with an ellipsis */
+  maxLines?: number;
+  fontSize?: number;
+}
+
+// Helvetica advance widths (1/1000 em) for ASCII 32–126, from the standard AFM
+const HELVETICA_WIDTHS = [
+  278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+  556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+  1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+  667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+  333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+  556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+];
+
+/** Unicode punctuation that WinAnsi has a slot for */
+const WIN_ANSI: Record<string, number> = {
+  '€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, '‰': 0x89, '‹': 0x8b,
+  '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99, '›': 0x9b,
+};
+
+const SUBSTITUTES: Record<string, string> = { '→': '->', '←': '<-', '✓': 'v', '✗': 'x', '≥': '>=', '≤': '<=', '\t': '    ' };
+
+function toWinAnsi(text: string): string {
+  let out = '';
+  for (const ch of text) {
+    const code = ch.codePointAt(0)!;
+    if (SUBSTITUTES[ch]) out += SUBSTITUTES[ch];
+    else if ((code >= 32 && code <= 126) || (code >= 160 && code <= 255)) out += ch;
+    else if (WIN_ANSI[ch]) out += String.fromCharCode(WIN_ANSI[ch]);
+    else if (code === 10 || code === 13) out += ' ';
+    else out += '?';
+  }
+  return out;
+}
+
+function charWidth(ch: string, bold: boolean): number {
+  const c = ch.charCodeAt(0);
+  const w = c >= 32 && c <= 126 ? HELVETICA_WIDTHS[c - 32] : 556;
+  // Helvetica-Bold runs about 5% wider; close enough for wrapping
+  return bold ? w * 1.05 : w;
+}
+
+function escapePdf(s: string): string {
+  return s.replace(/\\/g, '\\\\').replace(/\(/g, '\\(').replace(/\)/g, '\\)');
+}
+
+const MARGIN = 40;
+const FOOTER_SPACE = 28;
+
+export class PdfDocument {
+  private pages: string[][] = [];
+  private y = 0;
+  private readonly width: number;
+  private readonly height: number;
+
+  constructor(private opts: PdfOptions) {
+    this.width = opts.landscape ? 842 : 595;
+    this.height = opts.landscape ? 595 : 842;
+    this.newPage();
+  }
+
+  /** Width of already-encoded text in points */
+  measure(text: string, size: number, bold = false): number {
+    let w = 0;
+    for (const ch of text) w += charWidth(ch, bold);
+    return (w * size) / 1000;
+  }
+
+  heading(text: string, level: 1 | 2 | 3 = 2): this {
+    const size = level === 1 ? 18 : level === 2 ? 13 : 11;
+    this.ensure(size * 2.5);
+    this.y -= level === 1 ? 0 : size * 0.8;
+    for (const line of this.wrap(toWinAnsi(text), this.contentWidth, size, true)) {
+      this.text(MARGIN, this.y - size, line, size, true);
+      this.y -= size * 1.3;
+    }
+    if (level <= 2) {
+      this.op(`0.75 G 0.6 w ${MARGIN} ${this.y - 2} m ${this.width - MARGIN} ${this.y - 2} l S`);
+      this.y -= 8;
+    } else this.y -= 4;
+    return this;
+  }
+
+  paragraph(text: string, opts: { size?: number; gray?: number; bold?: boolean } = {}): this {
+    const size = opts.size || 9.5;
+    for (const line of this.wrap(toWinAnsi(text), this.contentWidth, size, !!opts.bold)) {
+      this.ensure(size * 1.4);
+      this.text(MARGIN, this.y - size, line, size, !!opts.bold, opts.gray);
+      this.y -= size * 1.4;
+    }
+    this.y -= 4;
+    return this;
+  }
+
+  keyValues(pairs: Array<[string, unknown]>): this {
+    const size = 9;
+    const keyWidth = Math.min(180, Math.max(...pairs.map(([k]) => this.measure(toWinAnsi(k), size, true)), 60) + 12);
+    for (const [k, v] of pairs) {
+      const lines = this.wrap(toWinAnsi(String(v ?? '')), this.contentWidth - keyWidth, size, false);
+      this.ensure(lines.length * size * 1.35);
+      this.text(MARGIN, this.y - size, toWinAnsi(k), size, true, 0.35);
+      lines.forEach((line, i) => this.text(MARGIN + keyWidth, this.y - size - i * size * 1.35, line, size));
+      this.y -= Math.max(1, lines.length) * size * 1.35 + 2;
+    }
+    this.y -= 6;
+    return this;
+  }
+
+  table(headers: string[], rows: unknown[][], opts: PdfTableOptions = {}): this {
+    const size = opts.fontSize || 8;
+    const pad = 4;
+    const lineH = size * 1.25;
+    const maxLines = opts.maxLines || 6;
+    const head = headers.map(toWinAnsi);
+    const body = rows.map(r => headers.map((_, i) => toWinAnsi(r[i] === null || r[i] === undefined ? '' : String(r[i]))));
+
+    // Column widths: proportional to a capped natural width so one long column can't starve the rest
+    const weights = opts.weights || head.map((h, i) => {
+      const natural = Math.max(this.measure(h, size, true), ...body.slice(0, 200).map(r => this.measure(r[i], size)));
+      return Math.min(Math.max(natural, 30), 260);
+    });
+    const total = weights.reduce((s, w) => s + w, 0) || 1;
+    const widths = weights.map(w => (w / total) * this.contentWidth);
+
+    const drawHeader = () => {
+      const lines = head.map((h, i) => this.wrap(h, widths[i] - pad * 2, size, true).slice(0, 2));
+      const rowH = Math.max(...lines.map(l => l.length)) * lineH + pad * 2;
+      this.op(`0.92 g ${MARGIN} ${this.y - rowH} ${this.contentWidth} ${rowH} re f`);
+      let x = MARGIN;
+      lines.forEach((cell, i) => {
+        cell.forEach((line, j) => this.text(x + pad, this.y - pad - size - j * lineH + 1, line, size, true, 0.2));
+        x += widths[i];
+      });
+      this.y -= rowH;
+    };
+
+    this.ensure(lineH * 3 + pad * 4);
+    drawHeader();
+    for (const row of body) {
+      const lines = row.map((cell, i) => {
+        const wrapped = this.wrap(cell, widths[i] - pad * 2, size, false);
+        if (wrapped.length <= maxLines) return wrapped;
+        const cut = wrapped.slice(0, maxLines);
+        cut[maxLines - 1] = cut[maxLines - 1].replace(/.{0,2}$/, '') + String.fromCharCode(0x85);
+        return cut;
+      });
+      const rowH = Math.max(1, ...lines.map(l => l.length)) * lineH + pad * 2;
+      if (this.y - rowH < MARGIN + FOOTER_SPACE) { this.newPage(); drawHeader(); }
+      let x = MARGIN;
+      lines.forEach((cell, i) => {
+        cell.forEach((line, j) => this.text(x + pad, this.y - pad - size - j * lineH + 1, line, size));
+        x += widths[i];
+      });
+      this.y -= rowH;
+      this.op(`0.85 G 0.4 w ${MARGIN} ${this.y} m ${this.width - MARGIN} ${this.y} l S`);
+    }
+    if (body.length === 0) this.paragraph('No records.', { size, gray: 0.45 });
+    this.y -= 10;
+    return this;
+  }
+
+  toBuffer(): Buffer {
+    const footer = toWinAnsi(this.opts.footer || this.opts.title);
+    const n = this.pages.length;
+    const streams = this.pages.map((ops, i) => {
+      const label = `Page ${i + 1} of ${n}`;
+      return [
+        ...ops,
+        `BT /F1 7.5 Tf 0.5 g ${MARGIN} ${MARGIN - 14} Td (${escapePdf(footer)}) Tj ET`,
+        `BT /F1 7.5 Tf 0.5 g ${this.width - MARGIN - this.measure(label, 7.5)} ${MARGIN - 14} Td (${label}) Tj ET`,
+      ].join('\n');
+    });
+
+    // 1 catalog, 2 page tree, 3–4 fonts, 5 info, then a page + content pair per page
+    const objects: string[] = [];
+    const kids = streams.map((_, i) => `${6 + i * 2} 0 R`).join(' ');
+    objects.push('<< /Type /Catalog /Pages 2 0 R >>');
+    objects.push(`<< /Type /Pages /Kids [${kids}] /Count ${n} >>`);
+    objects.push('<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>');
+    objects.push('<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>');
+    const date = new Date().toISOString().replace(/[-:T]/g, '').slice(0, 14);
+    objects.push(`<< /Title (${escapePdf(toWinAnsi(this.opts.title))}) /Author (${escapePdf(toWinAnsi(this.opts.author || 'AgenticMail Enterprise'))}) /Producer (AgenticMail Enterprise) /CreationDate (D:${date}Z) >>`);
+    streams.forEach((content, i) => {
+      objects.push(`<< /Type /Page /Parent 2 0 R /MediaBox [0 0 ${this.width} ${this.height}] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents ${7 + i * 2} 0 R >>`);
+      objects.push(`<< /Length ${Buffer.byteLength(content, 'latin1')} >>\nstream\n${content}\nendstream`);
+    });
+
+    let out = '%PDF-1.4\n%\xe2\xe3\xcf\xd3\n';
+    const offsets: number[] = [];
+    objects.forEach((body, i) => {
+      offsets.push(Buffer.byteLength(out, 'latin1'));
+      out += `${i + 1} 0 obj\n${body}\nendobj\n`;
+    });
+    const xref = Buffer.byteLength(out, 'latin1');
+    out += `xref\n0 ${objects.length + 1}\n0000000000 65535 f \n`;
+    out += offsets.map(o => String(o).padStart(10, '0') + ' 00000 n \n').join('');
+    out += `trailer\n<< /Size ${objects.length + 1} /Root 1 0 R /Info 5 0 R >>\nstartxref\n${xref}\n%%EOF\n`;
+    return Buffer.from(out, 'latin1');
+  }
+
+  // ─── Layout ───────────────────────────────────────────
+
+  private get contentWidth(): number {
+    return this.width - MARGIN * 2;
+  }
+
+  private get page(): string[] {
+    return this.pages[this.pages.length - 1];
+  }
+
+  private newPage(): void {
+    this.pages.push([]);
+    this.y = this.height - MARGIN;
+  }
+
+  /** Start a new page unless `needed` points still fit above the footer */
+  private ensure(needed: number): void {
+    if (this.y - needed < MARGIN + FOOTER_SPACE) this.newPage();
+  }
+
+  private op(s: string): void {
+    this.page.push(s);
+  }
+
+  private text(x: number, y: number, s: string, size: number, bold = false, gray = 0.1): void {
+    this.op(`BT /${bold ? 'F2' : 'F1'} ${size} Tf ${gray} g ${x.toFixed(2)} ${y.toFixed(2)} Td (${escapePdf(s)}) Tj ET`);
+  }
+
+  /** Greedy word wrap; words longer than a line are broken mid-word. */
+  private wrap(text: string, maxWidth: number, size: number, bold: boolean): string[] {
+    if (!text) return [''];
+    const lines: string[] = [];
+    let line = '';
+    for (const word of text.split(' ')) {
+      const candidate = line ? line + ' ' + word : word;
+      if (this.measure(candidate, size, bold) <= maxWidth) { line = candidate; continue; }
+      if (line) lines.push(line);
+      line = '';
+      let chunk = '';
+      for (const ch of word) {
+        if (this.measure(chunk + ch, size, bold) > maxWidth && chunk) { lines.push(chunk); chunk = ''; }
+        chunk += ch;
+      }
+      line = chunk;
+    }
+    lines.push(line);
+    return lines;
+  }
+}
+
+/** Response headers for a PDF download named `<name>-<date>.pdf`. */
+export function pdfResponse(name: string, doc: PdfDocument): Response {
+  return new Response(doc.toBuffer(), {
+    headers: {
+      'Content-Type': 'application/pdf',
+      'Content-Disposition': `attachment; filename="${name}-${new Date().toISOString().slice(0, 10)}.pdf"`,
+      'Cache-Control': 'no-store',
+    },
+  });
+}
@@ -0,0 +1,280 @@
+/**
+ * Dashboard Performance Budgets
+ *
+ * In-memory SLO tracker for dashboard pages. The dashboard reports how long
+ * each page took to render (navigation until its initial API calls settled);
+ * the performance middleware records every backend call tagged with the
+ * X-Dashboard-Page header. Together they answer "which pages are slow, and
+ * which backend call is making them slow".
+ *
+ * A page whose p95 render time stays above its budget for SUSTAIN_CHECKS
+ * consecutive checks raises an alert, surfaced in the notifications bell for
+ * admins. The alert resolves itself once the p95 drops back under budget.
+ *
+ *   DASHBOARD_RENDER_BUDGET_MS   Default budget for every page (default: 3000)
+ *   DASHBOARD_RENDER_BUDGETS     Per-page overrides: "messages=5000,agents/detail=4000"
+ *
+ * Bounded ring buffers that reset on restart — a tuning aid, not an audit trail.
+ */
+
+import { randomBytes } from 'node:crypto';
+import { dashboardRenderDuration } from './metrics.js';
+
+// ─── Types ───────────────────────────────────────────────
+
+interface RenderSample {
+  page: string;
+  durationMs: number;
+  userId?: string;
+  at: number;
+}
+
+interface CallSample {
+  page: string;
+  method: string;
+  route: string;
+  status: number;
+  durationMs: number;
+  at: number;
+}
+
+export interface SlowCall {
+  method: string;
+  route: string;
+  count: number;
+  errors: number;
+  p95Ms: number;
+  maxMs: number;
+}
+
+export interface PagePerformance {
+  page: string;
+  samples: number;
+  p50Ms: number;
+  p95Ms: number;
+  maxMs: number;
+  budgetMs: number;
+  overBudget: boolean;
+  /** When the current run of over-budget checks started */
+  breachingSince?: string;
+  slowestCalls: SlowCall[];
+}
+
+export interface PerformanceAlert {
+  id: string;
+  page: string;
+  p95Ms: number;
+  budgetMs: number;
+  breachingSince: string;
+  raisedAt: string;
+  resolvedAt?: string;
+  acknowledgedBy?: string;
+}
+
+// ─── Config ──────────────────────────────────────────────
+
+const MAX_RENDERS = 10_000;
+const MAX_CALLS = 20_000;
+const MAX_ALERTS = 200;
+const MAX_PAGES = 200;
+const WINDOW_MS = 15 * 60_000;
+const CHECK_INTERVAL_MS = 60_000;
+/** Consecutive over-budget checks before an alert is raised (5 min at the default interval) */
+const SUSTAIN_CHECKS = 5;
+/** Fewer samples than this in the window can't breach a budget */
+const MIN_SAMPLES = 5;
+const SLOWEST_CALLS = 5;
+
+const PAGE_RE = /^[a-z0-9-]{1,40}(\/[a-z0-9-]{1,40}){0,2}$/;
+
+function parseBudgets(raw?: string): Record<string, number> {
+  const budgets: Record<string, number> = {};
+  for (const pair of (raw || '').split(',')) {
+    const idx = pair.indexOf('=');
+    if (idx <= 0) continue;
+    const ms = parseInt(pair.slice(idx + 1).trim(), 10);
+    if (ms > 0) budgets[pair.slice(0, idx).trim()] = ms;
+  }
+  return budgets;
+}
+
+const _defaultBudgetMs = parseInt(process.env.DASHBOARD_RENDER_BUDGET_MS || '', 10) || 3000;
+const _budgets = parseBudgets(process.env.DASHBOARD_RENDER_BUDGETS);
+
+export function budgetFor(page: string): number {
+  return _budgets[page] || _defaultBudgetMs;
+}
+
+/** The default budget and every per-page override. */
+export function listBudgets(): { defaultMs: number; pages: Record<string, number> } {
+  return { defaultMs: _defaultBudgetMs, pages: { ..._budgets } };
+}
+
+/** True for page IDs the dashboard actually sends ("messages", "agents/detail", "plugins/acme/weekly"). */
+export function isValidPage(page: string | undefined | null): page is string {
+  return !!page && PAGE_RE.test(page);
+}
+
+// ─── Store ───────────────────────────────────────────────
+
+const _renders: RenderSample[] = [];
+const _calls: CallSample[] = [];
+const _alerts: PerformanceAlert[] = [];
+/** page → start of the current run of over-budget checks */
+const _breaching = new Map<string, { since: number; checks: number }>();
+const _pages = new Set<string>();
+let _timer: ReturnType<typeof setInterval> | null = null;
+
+function trackPage(page: string): boolean {
+  if (_pages.has(page)) return true;
+  if (_pages.size >= MAX_PAGES) return false;
+  _pages.add(page);
+  return true;
+}
+
+function ensureTimer(): void {
+  if (_timer) return;
+  _timer = setInterval(() => checkBudgets(), CHECK_INTERVAL_MS);
+  if (typeof _timer === 'object' && 'unref' in _timer) _timer.unref();
+}
+
+export function recordRender(page: string, durationMs: number, userId?: string): void {
+  if (!isValidPage(page) || !(durationMs >= 0) || !trackPage(page)) return;
+  _renders.push({ page, durationMs: Math.round(durationMs), userId, at: Date.now() });
+  if (_renders.length > MAX_RENDERS) _renders.splice(0, _renders.length - MAX_RENDERS);
+  dashboardRenderDuration.observe({ page }, durationMs / 1000);
+  ensureTimer();
+}
+
+export function recordPageCall(sample: Omit<CallSample, 'at'>): void {
+  if (!isValidPage(sample.page) || !trackPage(sample.page)) return;
+  _calls.push({ ...sample, at: Date.now() });
+  if (_calls.length > MAX_CALLS) _calls.splice(0, _calls.length - MAX_CALLS);
+}
+
+// ─── Analysis ────────────────────────────────────────────
+
+function percentile(sorted: number[], p: number): number {
+  if (sorted.length === 0) return 0;
+  return sorted[Math.min(sorted.length - 1, Math.max(0, Math.ceil(p * sorted.length) - 1))];
+}
+
+function rendersByPage(since: number): Map<string, number[]> {
+  const byPage = new Map<string, number[]>();
+  for (let i = _renders.length - 1; i >= 0 && _renders[i].at >= since; i--) {
+    const r = _renders[i];
+    const list = byPage.get(r.page);
+    if (list) list.push(r.durationMs); else byPage.set(r.page, [r.durationMs]);
+  }
+  for (const list of byPage.values()) list.sort((a, b) => a - b);
+  return byPage;
+}
+
+function slowestCalls(page: string, since: number): SlowCall[] {
+  const byRoute = new Map<string, { method: string; route: string; durations: number[]; errors: number }>();
+  for (let i = _calls.length - 1; i >= 0 && _calls[i].at >= since; i--) {
+    const c = _calls[i];
+    if (c.page !== page) continue;
+    const key = c.method + ' ' + c.route;
+    let entry = byRoute.get(key);
+    if (!entry) { entry = { method: c.method, route: c.route, durations: [], errors: 0 }; byRoute.set(key, entry); }
+    entry.durations.push(c.durationMs);
+    if (c.status >= 500) entry.errors++;
+  }
+  return [...byRoute.values()]
+    .map(e => {
+      const sorted = e.durations.sort((a, b) => a - b);
+      return { method: e.method, route: e.route, count: sorted.length, errors: e.errors, p95Ms: percentile(sorted, 0.95), maxMs: sorted[sorted.length - 1] };
+    })
+    .sort((a, b) => b.p95Ms - a.p95Ms)
+    .slice(0, SLOWEST_CALLS);
+}
+
+/**
+ * Per-page render times over the last `windowMs`, worst offenders first
+ * (ranked by how far their p95 is over, or under, budget).
+ */
+export function summarizePerformance(windowMs = WINDOW_MS): PagePerformance[] {
+  const since = Date.now() - windowMs;
+  const pages: PagePerformance[] = [];
+  for (const [page, sorted] of rendersByPage(since)) {
+    const budgetMs = budgetFor(page);
+    const p95Ms = percentile(sorted, 0.95);
+    const breach = _breaching.get(page);
+    pages.push({
+      page,
+      samples: sorted.length,
+      p50Ms: percentile(sorted, 0.5),
+      p95Ms,
+      maxMs: sorted[sorted.length - 1],
+      budgetMs,
+      overBudget: sorted.length >= MIN_SAMPLES && p95Ms > budgetMs,
+      breachingSince: breach ? new Date(breach.since).toISOString() : undefined,
+      slowestCalls: slowestCalls(page, since),
+    });
+  }
+  return pages.sort((a, b) => b.p95Ms / b.budgetMs - a.p95Ms / a.budgetMs);
+}
+
+// ─── Alerts ──────────────────────────────────────────────
+
+function openAlert(page: string): PerformanceAlert | undefined {
+  return _alerts.find(a => a.page === page && !a.resolvedAt);
+}
+
+/**
+ * Compare each page's p95 against its budget. Runs every CHECK_INTERVAL_MS
+ * once samples arrive; exported so the admin API can force a check.
+ */
+export function checkBudgets(now = Date.now()): void {
+  const byPage = rendersByPage(now - WINDOW_MS);
+  for (const page of _pages) {
+    const sorted = byPage.get(page) || [];
+    const budgetMs = budgetFor(page);
+    const p95Ms = percentile(sorted, 0.95);
+    const existing = openAlert(page);
+
+    if (sorted.length < MIN_SAMPLES || p95Ms <= budgetMs) {
+      _breaching.delete(page);
+      if (existing && sorted.length >= MIN_SAMPLES) existing.resolvedAt = new Date(now).toISOString();
+      continue;
+    }
+
+    const breach = _breaching.get(page) || { since: now, checks: 0 };
+    breach.checks++;
+    _breaching.set(page, breach);
+    if (existing) { existing.p95Ms = p95Ms; continue; }
+    if (breach.checks < SUSTAIN_CHECKS) continue;
+
+    _alerts.push({
+      id: randomBytes(8).toString('hex'),
+      page, p95Ms, budgetMs,
+      breachingSince: new Date(breach.since).toISOString(),
+      raisedAt: new Date(now).toISOString(),
+    });
+    if (_alerts.length > MAX_ALERTS) _alerts.splice(0, _alerts.length - MAX_ALERTS);
+    console.warn(`[performance] Dashboard page "${page}" p95 render time ${p95Ms}ms has exceeded its ${budgetMs}ms budget since ${new Date(breach.since).toISOString()}`);
+  }
+}
+
+/** Alerts, newest first. `open` limits to unresolved, unacknowledged ones. */
+export function listAlerts(opts: { open?: boolean } = {}): PerformanceAlert[] {
+  const alerts = opts.open ? _alerts.filter(a => !a.resolvedAt && !a.acknowledgedBy) : _alerts;
+  return [...alerts].reverse();
+}
+
+/** Hide an alert from the notifications bell; it still resolves on its own. */
+export function acknowledgeAlert(id: string, by: string): PerformanceAlert | null {
+  const alert = _alerts.find(a => a.id === id);
+  if (!alert) return null;
+  alert.acknowledgedBy = by;
+  return alert;
+}
+
+export function clearPerformance(): void {
+  _renders.length = 0;
+  _calls.length = 0;
+  _alerts.length = 0;
+  _breaching.clear();
+  _pages.clear();
+}
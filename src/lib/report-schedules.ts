@@ -0,0 +1,170 @@
+/**
+ * Scheduled Reports
+ *
+ * PDF renders of selected dashboard pages (dashboard, costs, compliance)
+ * emailed on a schedule to stakeholders who never sign in. Each recipient
+ * picks their own pages and gets one PDF with just those. Schedules are
+ * org-wide and live in the engine's engine_settings table under
+ * 'report_schedules'; the server's scheduler (server.ts) fetches the page
+ * data as the schedule's owner, so a report never shows more than its
+ * owner could see in the dashboard.
+ *
+ * Times are UTC. A schedule is due once per slot: a run that was missed
+ * while the server was down is sent on the next tick, but never twice.
+ */
+
+import { PdfDocument } from './pdf.js';
+
+export type ReportPageId = 'dashboard' | 'costs' | 'compliance';
+export type ReportFrequency = 'daily' | 'weekly' | 'monthly';
+
+export const REPORT_PAGE_IDS: ReportPageId[] = ['dashboard', 'costs', 'compliance'];
+
+/** engine_settings key the schedule list is stored under */
+export const REPORT_SETTINGS_KEY = 'report_schedules';
+
+export interface ReportRecipient {
+  email: string;
+  pages: ReportPageId[];
+}
+
+export interface ReportSchedule {
+  id: string;
+  name: string;
+  enabled: boolean;
+  frequency: ReportFrequency;
+  /** Hour of day, 0–23 UTC */
+  hour: number;
+  /** 0 = Sunday; weekly schedules only */
+  weekday?: number;
+  /** 1–28; monthly schedules only */
+  dayOfMonth?: number;
+  /** Organization the pages are scoped to; empty for all */
+  orgId?: string;
+  recipients: ReportRecipient[];
+  /** User the page data is fetched as */
+  owner: string;
+  createdAt: string;
+  lastRunAt?: string;
+  lastStatus?: 'sent' | 'partial' | 'failed';
+  lastError?: string;
+}
+
+export interface ReportSection {
+  title: string;
+  headers: string[];
+  rows: unknown[][];
+  /** Set when the owner couldn't read this section; rendered as a note */
+  error?: string;
+}
+
+export interface ReportPage {
+  id: ReportPageId;
+  title: string;
+  summary?: Array<[string, unknown]>;
+  sections: ReportSection[];
+}
+
+const MAX_SCHEDULES = 20;
+const MAX_RECIPIENTS = 50;
+const EMAIL_RE = /^[^\s@<>]+@[^\s@<>]+\.[^\s@<>]+$/;
+
+/**
+ * Validate an edited list. Run state (owner, lastRunAt, …) is kept from
+ * `existing` by id; new schedules are owned by `userId`.
+ */
+export function normalizeSchedules(raw: any, existing: ReportSchedule[], userId: string): ReportSchedule[] {
+  if (!Array.isArray(raw)) throw new Error('schedules must be an array');
+  if (raw.length > MAX_SCHEDULES) throw new Error(`At most ${MAX_SCHEDULES} report schedules`);
+  const prev = new Map(existing.map(s => [s.id, s]));
+  const ids = new Set<string>();
+  return raw.map((s: any, i: number): ReportSchedule => {
+    const name = String(s?.name || '').trim().slice(0, 80);
+    if (!name) throw new Error(`Schedule ${i + 1}: name is required`);
+    const frequency = (s.frequency || 'weekly') as ReportFrequency;
+    if (!['daily', 'weekly', 'monthly'].includes(frequency)) throw new Error(`"${name}": frequency must be daily, weekly or monthly`);
+    const hour = Number(s.hour ?? 8);
+    if (!Number.isInteger(hour) || hour < 0 || hour > 23) throw new Error(`"${name}": hour must be 0-23`);
+    const weekday = Number(s.weekday ?? 1);
+    if (frequency === 'weekly' && (!Number.isInteger(weekday) || weekday < 0 || weekday > 6)) throw new Error(`"${name}": weekday must be 0-6`);
+    const dayOfMonth = Number(s.dayOfMonth ?? 1);
+    if (frequency === 'monthly' && (!Number.isInteger(dayOfMonth) || dayOfMonth < 1 || dayOfMonth > 28)) throw new Error(`"${name}": day of month must be 1-28`);
+
+    if (!Array.isArray(s.recipients) || s.recipients.length === 0) throw new Error(`"${name}": add at least one recipient`);
+    if (s.recipients.length > MAX_RECIPIENTS) throw new Error(`"${name}": at most ${MAX_RECIPIENTS} recipients`);
+    const recipients = s.recipients.map((r: any): ReportRecipient => {
+      const email = String(r?.email || '').trim().toLowerCase();
+      if (!EMAIL_RE.test(email)) throw new Error(`"${name}": "${email}" is not an email address`);
+      const pages = REPORT_PAGE_IDS.filter(p => Array.isArray(r.pages) && r.pages.includes(p));
+      if (!pages.length) throw new Error(`"${name}": pick at least one page for ${email}`);
+      return { email, pages };
+    });
+
+    let id = String(s.id || '').replace(/[^a-z0-9-]/gi, '').slice(0, 40) || name.toLowerCase().replace(/[^a-z0-9]+/g, '-').replace(/^-|-$/g, '').slice(0, 40) || `report-${i + 1}`;
+    for (let n = 2; ids.has(id); n++) id = `${id.replace(/-\d+$/, '')}-${n}`;
+    ids.add(id);
+    const before = prev.get(id);
+    return {
+      id, name,
+      enabled: s.enabled !== false,
+      frequency, hour,
+      weekday: frequency === 'weekly' ? weekday : undefined,
+      dayOfMonth: frequency === 'monthly' ? dayOfMonth : undefined,
+      orgId: s.orgId ? String(s.orgId).slice(0, 100) : undefined,
+      recipients,
+      owner: before?.owner || userId,
+      createdAt: before?.createdAt || new Date().toISOString(),
+      lastRunAt: before?.lastRunAt,
+      lastStatus: before?.lastStatus,
+      lastError: before?.lastError,
+    };
+  });
+}
+
+/** The latest scheduled time at or before `now`. */
+export function lastSlot(s: ReportSchedule, now: Date): Date {
+  const slot = new Date(Date.UTC(now.getUTCFullYear(), now.getUTCMonth(), now.getUTCDate(), s.hour));
+  if (s.frequency === 'monthly') {
+    slot.setUTCDate(s.dayOfMonth || 1);
+    if (slot > now) slot.setUTCMonth(slot.getUTCMonth() - 1);
+    return slot;
+  }
+  if (slot > now) slot.setUTCDate(slot.getUTCDate() - 1);
+  if (s.frequency === 'weekly') {
+    while (slot.getUTCDay() !== (s.weekday ?? 1)) slot.setUTCDate(slot.getUTCDate() - 1);
+  }
+  return slot;
+}
+
+/** The next scheduled time after `now`, for display. */
+export function nextSlot(s: ReportSchedule, now: Date): Date {
+  const next = new Date(lastSlot(s, now));
+  if (s.frequency === 'monthly') next.setUTCMonth(next.getUTCMonth() + 1);
+  else next.setUTCDate(next.getUTCDate() + (s.frequency === 'weekly' ? 7 : 1));
+  return next;
+}
+
+/** True when a slot has passed since the schedule was created or last ran. */
+export function isDue(s: ReportSchedule, now: Date): boolean {
+  if (!s.enabled) return false;
+  const since = new Date(s.lastRunAt || s.createdAt);
+  return lastSlot(s, now) > since;
+}
+
+/** One PDF with the given pages, in the order they're passed. */
+export function renderReportPdf(pages: ReportPage[], meta: { companyName: string; scheduleName: string; generatedAt: Date }): Buffer {
+  const title = `${meta.companyName} • ${meta.scheduleName}`;
+  const doc = new PdfDocument({ title, footer: `${title} • ${meta.generatedAt.toISOString().slice(0, 10)}`, landscape: true });
+  doc.heading(meta.scheduleName, 1);
+  doc.paragraph(`${meta.companyName} • Generated ${meta.generatedAt.toUTCString()} • ${pages.map(p => p.title).join(', ')}`, { gray: 0.4 });
+  for (const page of pages) {
+    doc.heading(page.title, 1);
+    if (page.summary?.length) doc.keyValues(page.summary);
+    for (const section of page.sections) {
+      doc.heading(section.title, 2);
+      if (section.error) doc.paragraph(`Not included: ${section.error}`, { gray: 0.45 });
+      else doc.table(section.headers, section.rows);
+    }
+  }
+  return doc.toBuffer();
+}
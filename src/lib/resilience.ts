@@ -5,6 +5,8 @@
  * rate limiting, and graceful degradation patterns.
  */
 
+import { backendCallDuration, backendErrorsTotal, backendRetriesTotal, circuitBreakerState, onCollect } from './metrics.js';
+
 // ─── Retry with Exponential Backoff ──────────────────────
 
 export interface RetryOptions {
@@ -14,6 +16,8 @@ export interface RetryOptions {
   backoffMultiplier: number;
   retryableErrors?: (err: Error) => boolean;
   onRetry?: (attempt: number, err: Error, delayMs: number) => void;
+  /** Backend name — when set, retries are counted in enterprise_backend_retries_total */
+  name?: string;
 }
 
 const DEFAULT_RETRY: RetryOptions = {
@@ -45,6 +49,7 @@ export async function withRetry<T>(
         config.maxDelayMs,
       );
 
+      if (config.name) backendRetriesTotal.inc({ backend: config.name });
       config.onRetry?.(attempt, err, delay);
       await sleep(delay);
     }
@@ -62,8 +67,15 @@ export interface CircuitBreakerOptions {
   recoveryTimeMs: number;      // Time before half-open
   successThreshold: number;    // Successes in half-open to close
   timeout?: number;            // Per-call timeout in ms
+  name?: string;               // Backend name — enables Prometheus metrics
 }
 
+const CIRCUIT_STATE_VALUE: Record<CircuitState, number> = { closed: 0, 'half-open': 1, open: 2 };
+const _namedBreakers = new Map<string, CircuitBreaker>();
+onCollect(() => {
+  for (const [name, cb] of _namedBreakers) circuitBreakerState.set({ breaker: name }, CIRCUIT_STATE_VALUE[cb.getState()]);
+});
+
 export class CircuitBreaker {
   private state: CircuitState = 'closed';
   private failures = 0;
@@ -77,30 +89,43 @@ export class CircuitBreaker {
       recoveryTimeMs: opts.recoveryTimeMs ?? 30_000,
       successThreshold: opts.successThreshold ?? 2,
       timeout: opts.timeout,
+      name: opts.name,
     };
+    if (opts.name) _namedBreakers.set(opts.name, this);
   }
 
   async execute<T>(fn: () => Promise<T>): Promise<T> {
+    const backend = this.opts.name;
     if (this.state === 'open') {
       if (Date.now() - this.lastFailureTime >= this.opts.recoveryTimeMs) {
         this.state = 'half-open';
         this.successes = 0;
       } else {
+        if (backend) {
+          backendCallDuration.observe({ backend, outcome: 'rejected' }, 0);
+          backendErrorsTotal.inc({ backend, reason: 'circuit_open' });
+        }
         throw new CircuitOpenError(
           `Circuit breaker is open. Retry after ${this.opts.recoveryTimeMs}ms`,
         );
       }
     }
 
+    const done = backend ? backendCallDuration.startTimer({ backend }) : null;
     try {
       const result = this.opts.timeout
         ? await withTimeout(fn(), this.opts.timeout)
         : await fn();
 
       this.onSuccess();
+      done?.({ outcome: 'success' });
       return result;
     } catch (err) {
       this.onFailure();
+      if (backend) {
+        done?.({ outcome: 'error' });
+        backendErrorsTotal.inc({ backend, reason: 'error' });
+      }
       throw err;
     }
   }
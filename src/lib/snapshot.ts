@@ -0,0 +1,139 @@
+/**
+ * Dashboard Snapshot
+ *
+ * Renders a read-only copy of the dashboard as static HTML: one file per
+ * page plus an index, every file self-contained (inline CSS, no scripts, no
+ * external assets) so the archive opens from disk, attaches to a board
+ * report, or stands in for the dashboard while the backend is down for
+ * maintenance. The caller gathers the data — see the snapshot route in
+ * server.ts, which crawls the list APIs as the requesting user — and this
+ * module only lays it out and zips it with a manifest of SHA-256 hashes.
+ */
+
+import { createHash } from 'crypto';
+import { createZip, type ZipEntry } from './zip.js';
+import { escapeHtml } from './templates.js';
+import type { CsvColumn } from './csv.js';
+
+export interface SnapshotSection {
+  title: string;
+  columns: CsvColumn[];
+  rows: any[];
+  /** Rows that exist on the server; more than rows.length when truncated */
+  total?: number;
+  /** Set when the source couldn't be read; rendered in place of the table */
+  error?: string;
+}
+
+export interface SnapshotPage {
+  /** File name inside the archive, e.g. "agents.html" */
+  file: string;
+  title: string;
+  description?: string;
+  sections: SnapshotSection[];
+}
+
+export interface SnapshotMeta {
+  companyName: string;
+  generatedAt: Date;
+  generatedBy?: string;
+}
+
+const STYLE = `
+*{box-sizing:border-box}
+body{margin:0;font:14px/1.5 -apple-system,BlinkMacSystemFont,"Segoe UI",Roboto,sans-serif;color:#1f2937;background:#f9fafb}
+.layout{display:flex;min-height:100vh}
+nav{width:220px;flex-shrink:0;background:#111827;color:#e5e7eb;padding:20px 0}
+nav .brand{font-weight:700;padding:0 20px 16px;font-size:15px}
+nav a{display:block;padding:6px 20px;color:#d1d5db;text-decoration:none}
+nav a.active,nav a:hover{background:#1f2937;color:#fff}
+main{flex:1;padding:24px 32px;min-width:0}
+h1{font-size:20px;margin:0 0 4px}
+h2{font-size:15px;margin:24px 0 8px}
+.muted{color:#6b7280;font-size:12px}
+.banner{background:#fef3c7;border:1px solid #fcd34d;border-radius:6px;padding:8px 12px;margin:0 0 20px;font-size:12px}
+.cards{display:flex;flex-wrap:wrap;gap:12px;margin-top:16px}
+.card{background:#fff;border:1px solid #e5e7eb;border-radius:8px;padding:14px 18px;min-width:180px;text-decoration:none;color:inherit}
+.card strong{display:block;font-size:20px}
+table{width:100%;border-collapse:collapse;background:#fff;border:1px solid #e5e7eb;font-size:12px}
+th,td{text-align:left;padding:6px 10px;border-bottom:1px solid #f3f4f6;vertical-align:top;word-break:break-word}
+th{background:#f3f4f6;font-weight:600}
+.error{color:#b91c1c}
+@media print{nav{display:none}.banner{border-color:#999}}
+`;
+
+function cellText(v: unknown): string {
+  if (v === null || v === undefined) return '';
+  if (v instanceof Date) return v.toISOString();
+  if (typeof v === 'object') return JSON.stringify(v);
+  return String(v);
+}
+
+function renderSection(s: SnapshotSection): string {
+  const heading = `<h2>${escapeHtml(s.title)}</h2>`;
+  if (s.error) return heading + `<p class="error">Not captured: ${escapeHtml(s.error)}</p>`;
+  if (s.rows.length === 0) return heading + '<p class="muted">Nothing to show.</p>';
+  const head = s.columns.map(col => `<th>${escapeHtml(col.header)}</th>`).join('');
+  const body = s.rows.map(row =>
+    '<tr>' + s.columns.map(col => `<td>${escapeHtml(cellText(col.value ? col.value(row) : row[col.header]))}</td>`).join('') + '</tr>'
+  ).join('\n');
+  const truncated = s.total !== undefined && s.total > s.rows.length
+    ? `<p class="muted">Showing the latest ${s.rows.length} of ${s.total}.</p>` : '';
+  return heading + `<table><thead><tr>${head}</tr></thead><tbody>\n${body}\n</tbody></table>` + truncated;
+}
+
+function renderDocument(title: string, content: string, pages: SnapshotPage[], active: string, meta: SnapshotMeta): string {
+  const stamp = meta.generatedAt.toISOString().replace('T', ' ').slice(0, 19) + ' UTC';
+  const links = [{ file: 'index.html', title: 'Overview' }, ...pages]
+    .map(p => `<a href="${escapeHtml(p.file)}"${p.file === active ? ' class="active"' : ''}>${escapeHtml(p.title)}</a>`).join('');
+  return `<!DOCTYPE html>
+<html lang="en"><head><meta charset="utf-8"><meta name="viewport" content="width=device-width,initial-scale=1">
+<title>${escapeHtml(title)} — ${escapeHtml(meta.companyName)} snapshot ${escapeHtml(stamp)}</title>
+<style>${STYLE}</style></head>
+<body><div class="layout">
+<nav aria-label="Snapshot pages"><div class="brand">${escapeHtml(meta.companyName)}</div>${links}</nav>
+<main>
+<div class="banner">Read-only snapshot taken ${escapeHtml(stamp)}${meta.generatedBy ? ' by ' + escapeHtml(meta.generatedBy) : ''}. Data may have changed since.</div>
+${content}
+</main></div></body></html>
+`;
+}
+
+/** Render the pages and zip them with an index and manifest. */
+export function buildSnapshot(pages: SnapshotPage[], meta: SnapshotMeta): { name: string; zip: Buffer } {
+  const files: ZipEntry[] = [];
+
+  const cards = pages.map(p => {
+    const rows = p.sections.reduce((n, s) => n + (s.error ? 0 : s.total ?? s.rows.length), 0);
+    const failed = p.sections.some(s => s.error);
+    return `<a class="card" href="${escapeHtml(p.file)}"><span class="muted">${escapeHtml(p.title)}</span><strong>${rows}</strong>`
+      + (failed ? '<span class="error">Partially captured</span>' : '') + '</a>';
+  }).join('');
+  files.push({
+    name: 'index.html',
+    data: renderDocument('Overview', `<h1>Dashboard snapshot</h1><p class="muted">${pages.length} pages captured.</p><div class="cards">${cards}</div>`, pages, 'index.html', meta),
+    modified: meta.generatedAt,
+  });
+
+  for (const p of pages) {
+    const content = `<h1>${escapeHtml(p.title)}</h1>`
+      + (p.description ? `<p class="muted">${escapeHtml(p.description)}</p>` : '')
+      + p.sections.map(renderSection).join('\n');
+    files.push({ name: p.file, data: renderDocument(p.title, content, pages, p.file, meta), modified: meta.generatedAt });
+  }
+
+  const manifest = {
+    generatedAt: meta.generatedAt.toISOString(),
+    generatedBy: meta.generatedBy,
+    pages: pages.map(p => ({
+      file: p.file,
+      title: p.title,
+      sections: p.sections.map(s => ({ title: s.title, rows: s.rows.length, total: s.total, ...(s.error ? { error: s.error } : {}) })),
+    })),
+    files: files.map(f => ({ name: f.name, sha256: createHash('sha256').update(f.data).digest('hex') })),
+  };
+  files.push({ name: 'manifest.json', data: JSON.stringify(manifest, null, 2), modified: meta.generatedAt });
+
+  const stamp = meta.generatedAt.toISOString().slice(0, 16).replace(/[:T]/g, '-');
+  return { name: `dashboard-snapshot-${stamp}`, zip: createZip(files) };
+}
@@ -0,0 +1,51 @@
+/**
+ * Table Sorting
+ *
+ * Shared parsing and comparison for the sortable dashboard tables. Tables send
+ * ?sort=<column>&dir=asc|desc; each endpoint whitelists the columns it can
+ * sort by, so an unknown column falls back to the default order instead of
+ * reaching a query.
+ */
+
+export type SortDir = 'asc' | 'desc';
+
+export interface SortSpec<F extends string = string> {
+  field: F;
+  dir: SortDir;
+}
+
+/** Read ?sort and ?dir, falling back when the column isn't in `allowed`. */
+export function parseSort<F extends string>(
+  sort: string | undefined,
+  dir: string | undefined,
+  allowed: readonly F[],
+  fallback: SortSpec<F>,
+): SortSpec<F> {
+  if (!sort || !(allowed as readonly string[]).includes(sort)) return fallback;
+  return { field: sort as F, dir: dir === 'asc' ? 'asc' : dir === 'desc' ? 'desc' : fallback.dir };
+}
+
+/**
+ * Compare two cell values: numbers and dates numerically, everything else as
+ * case-insensitive natural-order text. Empty values always sort last.
+ */
+export function compareValues(a: unknown, b: unknown, dir: SortDir = 'asc'): number {
+  const aEmpty = a === null || a === undefined || a === '';
+  const bEmpty = b === null || b === undefined || b === '';
+  if (aEmpty || bEmpty) return aEmpty === bEmpty ? 0 : aEmpty ? 1 : -1;
+  let cmp: number;
+  if (a instanceof Date || b instanceof Date) cmp = new Date(a as any).getTime() - new Date(b as any).getTime();
+  else if (typeof a === 'number' && typeof b === 'number') cmp = a - b;
+  else if (typeof a === 'boolean' && typeof b === 'boolean') cmp = Number(a) - Number(b);
+  else cmp = String(a).localeCompare(String(b), undefined, { sensitivity: 'base', numeric: true });
+  return dir === 'desc' ? -cmp : cmp;
+}
+
+/**
+ * Sort rows in place by one column. `get` maps a column name to the row's
+ * value when the field isn't a plain property (e.g. createdAt vs created_at).
+ */
+export function sortRows<T>(rows: T[], spec: SortSpec, get?: (row: T, field: string) => unknown): T[] {
+  const read = get || ((row: any, field: string) => row?.[field]);
+  return rows.sort((x, y) => compareValues(read(x, spec.field), read(y, spec.field), spec.dir));
+}
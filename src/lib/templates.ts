@@ -0,0 +1,124 @@
+/**
+ * Server-rendered HTML Templates
+ *
+ * Standalone pages the server renders itself (firewall/geo block pages, SSO
+ * errors, OAuth popup results) live as .html files in src/templates/ and are
+ * copied to dist/templates/ at build time. Each page is rendered into the
+ * shared layout.html.
+ *
+ * Syntax:
+ *   {{name}}        value, HTML-escaped
+ *   {{{name}}}      value, raw (trusted markup only)
+ *   {{json name}}   value as a JSON literal, safe inside <script>
+ *
+ * Templates are read once and cached; unknown placeholders render empty.
+ * The layout picks up the org's cached branding (name, logo, primary colour).
+ */
+
+import { readFileSync } from 'node:fs';
+import { join, dirname } from 'node:path';
+import { fileURLToPath } from 'node:url';
+import { getBrandingSync, brandPalette } from './branding.js';
+
+export type TemplateName = 'firewall-blocked' | 'geo-blocked' | 'sso-error' | 'oauth-result' | 'error';
+
+export type TemplateData = Record<string, string | number | boolean | null | undefined>;
+
+// ─── Helpers ─────────────────────────────────────────────
+
+export function escapeHtml(value: unknown): string {
+  return String(value ?? '')
+    .replace(/&/g, '&amp;')
+    .replace(/</g, '&lt;')
+    .replace(/>/g, '&gt;')
+    .replace(/"/g, '&quot;')
+    .replace(/'/g, '&#039;');
+}
+
+/** JSON literal that cannot close a <script> element or open an HTML comment. */
+function jsonForScript(value: unknown): string {
+  return JSON.stringify(value ?? null)
+    .replace(/</g, '\\u003c')
+    .replace(/>/g, '\\u003e')
+    .replace(/\u2028/g, '\\u2028')
+    .replace(/\u2029/g, '\\u2029');
+}
+
+const FUNCS: Record<string, (value: unknown) => string> = {
+  json: jsonForScript,
+};
+
+// ─── Loading ─────────────────────────────────────────────
+
+const _cache = new Map<string, string>();
+
+function templateDirs(): string[] {
+  const dir = dirname(fileURLToPath(import.meta.url));
+  return [
+    join(dir, 'templates'),                 // dist/ (bundled)
+    join(dir, '..', 'templates'),           // src/lib/ (tsx / dev)
+    join(process.cwd(), 'node_modules', '@agenticmail', 'enterprise', 'dist', 'templates'),
+  ];
+}
+
+function loadTemplate(name: string): string {
+  const cached = _cache.get(name);
+  if (cached !== undefined) return cached;
+  for (const dir of templateDirs()) {
+    try {
+      const source = readFileSync(join(dir, name + '.html'), 'utf-8');
+      _cache.set(name, source);
+      return source;
+    } catch { /* try next location */ }
+  }
+  throw new Error(`Template not found: ${name}.html`);
+}
+
+// ─── Rendering ───────────────────────────────────────────
+
+const PLACEHOLDER_RE = /\{\{\{\s*(\w+)\s*\}\}\}|\{\{\s*(?:(\w+)\s+)?(\w+)\s*\}\}/g;
+
+function interpolate(source: string, data: TemplateData): string {
+  return source.replace(PLACEHOLDER_RE, (_m, raw: string | undefined, fn: string | undefined, key: string | undefined) => {
+    if (raw) return String(data[raw] ?? '');
+    const value = data[key!];
+    if (fn) {
+      const helper = FUNCS[fn];
+      if (!helper) throw new Error(`Unknown template function: ${fn}`);
+      return helper(value);
+    }
+    return escapeHtml(value);
+  });
+}
+
+/**
+ * Render a page template inside the shared layout. `title` sets the
+ * document title; every other key is available to the page.
+ */
+export function renderPage(name: TemplateName, data: TemplateData & { title: string }): string {
+  try {
+    const content = interpolate(loadTemplate(name), data);
+    const brand = getBrandingSync();
+    const palette = brandPalette(brand.primaryColor);
+    return interpolate(loadTemplate('layout'), {
+      title: data.title,
+      content,
+      orgName: brand.companyName,
+      logo: brand.logo || '/dashboard/assets/logo.png',
+      brandColor: palette.color,
+      brandHover: palette.hover,
+    });
+  } catch (err: any) {
+    // Templates missing from the install — never fail the request over it
+    console.error('[templates]', err.message);
+    return `<!DOCTYPE html><html><head><meta charset="utf-8"><title>${escapeHtml(data.title)}</title></head>`
+      + `<body><h1>${escapeHtml(data.title)}</h1>${data.message ? `<p>${escapeHtml(data.message)}</p>` : ''}</body></html>`;
+  }
+}
+
+/** Read every template up front so a broken install is reported at startup. */
+export function preloadTemplates(): void {
+  for (const name of ['layout', 'firewall-blocked', 'geo-blocked', 'sso-error', 'oauth-result', 'error']) {
+    try { loadTemplate(name); } catch (err: any) { console.warn('[templates]', err.message); }
+  }
+}
@@ -0,0 +1,51 @@
+/**
+ * TOTP (RFC 6238) Verification
+ *
+ * Shared by sign-in 2FA and by step-up checks on sensitive admin actions.
+ * Secrets are base32; codes are 6 digits on a 30-second step, and one step
+ * of clock drift is accepted either way.
+ */
+
+export function base32Decode(input: string): Uint8Array {
+  const alphabet = 'ABCDEFGHIJKLMNOPQRSTUVWXYZ234567';
+  const cleaned = input.replace(/[=\s]/g, '').toUpperCase();
+  const bytes: number[] = [];
+  let bits = 0;
+  let value = 0;
+  for (const char of cleaned) {
+    const idx = alphabet.indexOf(char);
+    if (idx === -1) continue;
+    value = (value << 5) | idx;
+    bits += 5;
+    if (bits >= 8) {
+      bytes.push((value >>> (bits - 8)) & 0xff);
+      bits -= 8;
+    }
+  }
+  return new Uint8Array(bytes);
+}
+
+export async function generateTotp(secret: string, timeStep = 30, digits = 6, offsetSteps = 0): Promise<string> {
+  const keyBytes = base32Decode(secret);
+  const time = Math.floor(Date.now() / 1000 / timeStep) + offsetSteps;
+  const timeBytes = new Uint8Array(8);
+  let t = time;
+  for (let i = 7; i >= 0; i--) {
+    timeBytes[i] = t & 0xff;
+    t = Math.floor(t / 256);
+  }
+  const key = await crypto.subtle.importKey('raw', keyBytes.buffer as ArrayBuffer, { name: 'HMAC', hash: 'SHA-1' }, false, ['sign']);
+  const sig = new Uint8Array(await crypto.subtle.sign('HMAC', key, timeBytes));
+  const offset = sig[sig.length - 1] & 0x0f;
+  const code = ((sig[offset] & 0x7f) << 24 | sig[offset + 1] << 16 | sig[offset + 2] << 8 | sig[offset + 3]) % (10 ** digits);
+  return String(code).padStart(digits, '0');
+}
+
+export async function verifyTotp(secret: string, token: string): Promise<boolean> {
+  // Allow 1 step drift in either direction
+  for (const offset of [0, -1, 1]) {
+    const expected = await generateTotp(secret, 30, 6, offset);
+    if (expected === token) return true;
+  }
+  return false;
+}
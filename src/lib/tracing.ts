@@ -0,0 +1,322 @@
+/**
+ * Distributed Tracing (OpenTelemetry-compatible)
+ *
+ * Dependency-free W3C Trace Context propagation + OTLP/HTTP (JSON) span export.
+ * Spans are buffered in memory and flushed in batches to the collector configured
+ * via the standard OTEL_* environment variables:
+ *
+ *   OTEL_EXPORTER_OTLP_ENDPOINT          Collector base URL (e.g. http://otel-collector:4318)
+ *   OTEL_EXPORTER_OTLP_TRACES_ENDPOINT   Full traces URL override (…/v1/traces)
+ *   OTEL_EXPORTER_OTLP_HEADERS           Extra headers: "api-key=abc,x-tenant=acme"
+ *   OTEL_SERVICE_NAME                    Service name (default: agenticmail-enterprise)
+ *   OTEL_TRACES_SAMPLER_ARG              Sampling ratio 0..1 (default: 1)
+ *   OTEL_SDK_DISABLED=true               Hard off switch
+ *
+ * Tracing is a no-op unless an OTLP endpoint is configured.
+ */
+
+import { AsyncLocalStorage } from 'node:async_hooks';
+import { randomBytes } from 'node:crypto';
+
+// ─── Types ───────────────────────────────────────────────
+
+export type SpanKind = 'internal' | 'server' | 'client';
+export type AttributeValue = string | number | boolean;
+
+export interface SpanContext {
+  traceId: string;   // 32 hex chars
+  spanId: string;    // 16 hex chars
+  sampled: boolean;
+}
+
+interface SpanEvent {
+  name: string;
+  timeUnixNano: string;
+  attributes: Record<string, AttributeValue>;
+}
+
+// OTLP enum values
+const KIND_MAP: Record<SpanKind, number> = { internal: 1, server: 2, client: 3 };
+const STATUS_OK = 1;
+const STATUS_ERROR = 2;
+
+// ─── Config ──────────────────────────────────────────────
+
+const MAX_BUFFER = 2048;
+const BATCH_SIZE = 512;
+const FLUSH_INTERVAL_MS = 5_000;
+
+function resolveEndpoint(): string | null {
+  if (process.env.OTEL_SDK_DISABLED === 'true') return null;
+  const traces = process.env.OTEL_EXPORTER_OTLP_TRACES_ENDPOINT;
+  if (traces) return traces;
+  const base = process.env.OTEL_EXPORTER_OTLP_ENDPOINT;
+  if (base) return base.replace(/\/+$/, '') + '/v1/traces';
+  return null;
+}
+
+function parseHeaders(raw?: string): Record<string, string> {
+  const headers: Record<string, string> = {};
+  for (const pair of (raw || '').split(',')) {
+    const idx = pair.indexOf('=');
+    if (idx <= 0) continue;
+    headers[decodeURIComponent(pair.slice(0, idx).trim())] = decodeURIComponent(pair.slice(idx + 1).trim());
+  }
+  return headers;
+}
+
+const _endpoint = resolveEndpoint();
+const _headers = parseHeaders(process.env.OTEL_EXPORTER_OTLP_HEADERS);
+const _serviceName = process.env.OTEL_SERVICE_NAME || 'agenticmail-enterprise';
+const _ratio = Math.min(1, Math.max(0, parseFloat(process.env.OTEL_TRACES_SAMPLER_ARG || '1') || 0));
+let _serviceVersion = 'unknown';
+
+/** True when an OTLP endpoint is configured. */
+export function tracingEnabled(): boolean {
+  return _endpoint !== null;
+}
+
+/** Set the service.version resource attribute (called once from server.ts). */
+export function setServiceVersion(version: string): void {
+  _serviceVersion = version;
+}
+
+// ─── Context ─────────────────────────────────────────────
+
+const _context = new AsyncLocalStorage<Span>();
+
+/** The span active in the current async context, if any. */
+export function currentSpan(): Span | undefined {
+  return _context.getStore();
+}
+
+/** Run fn with span as the active span (children created inside inherit it). */
+export function withSpan<T>(span: Span, fn: () => T): T {
+  return _context.run(span, fn);
+}
+
+// ─── W3C Trace Context ───────────────────────────────────
+
+const TRACEPARENT_RE = /^00-([0-9a-f]{32})-([0-9a-f]{16})-([0-9a-f]{2})$/;
+
+export function parseTraceparent(header?: string | null): SpanContext | null {
+  if (!header) return null;
+  const m = TRACEPARENT_RE.exec(header.trim().toLowerCase());
+  if (!m || /^0+$/.test(m[1]) || /^0+$/.test(m[2])) return null;
+  return { traceId: m[1], spanId: m[2], sampled: (parseInt(m[3], 16) & 1) === 1 };
+}
+
+export function formatTraceparent(ctx: SpanContext): string {
+  return `00-${ctx.traceId}-${ctx.spanId}-${ctx.sampled ? '01' : '00'}`;
+}
+
+/**
+ * Deterministic TraceIdRatio sampling — every service (and every span of a trace)
+ * reaches the same decision for the same trace ID.
+ */
+function shouldSample(traceId: string): boolean {
+  if (_ratio >= 1) return true;
+  if (_ratio <= 0) return false;
+  const bucket = parseInt(traceId.slice(-8), 16) / 0xffffffff;
+  return bucket < _ratio;
+}
+
+// ─── Span ────────────────────────────────────────────────
+
+function nowNano(): string {
+  return (BigInt(Date.now()) * 1_000_000n + BigInt(Math.floor((performance.now() % 1) * 1_000_000))).toString();
+}
+
+export class Span {
+  readonly traceId: string;
+  readonly spanId: string;
+  readonly parentSpanId?: string;
+  readonly sampled: boolean;
+  private name: string;
+  private readonly kind: SpanKind;
+  private readonly startTimeUnixNano: string;
+  private endTimeUnixNano?: string;
+  private readonly attributes: Record<string, AttributeValue> = {};
+  private readonly events: SpanEvent[] = [];
+  private status: { code: number; message?: string } = { code: 0 };
+
+  constructor(name: string, kind: SpanKind, parent?: SpanContext | null) {
+    this.name = name;
+    this.kind = kind;
+    this.traceId = parent?.traceId || randomBytes(16).toString('hex');
+    this.spanId = randomBytes(8).toString('hex');
+    this.parentSpanId = parent?.spanId;
+    this.sampled = tracingEnabled() && shouldSample(this.traceId);
+    this.startTimeUnixNano = nowNano();
+  }
+
+  context(): SpanContext {
+    return { traceId: this.traceId, spanId: this.spanId, sampled: this.sampled };
+  }
+
+  traceparent(): string {
+    return formatTraceparent(this.context());
+  }
+
+  updateName(name: string): this {
+    this.name = name;
+    return this;
+  }
+
+  setAttribute(key: string, value: AttributeValue | undefined | null): this {
+    if (value !== undefined && value !== null && value !== '') this.attributes[key] = value;
+    return this;
+  }
+
+  setAttributes(attrs: Record<string, AttributeValue | undefined | null>): this {
+    for (const [k, v] of Object.entries(attrs)) this.setAttribute(k, v);
+    return this;
+  }
+
+  addEvent(name: string, attributes: Record<string, AttributeValue> = {}): this {
+    this.events.push({ name, timeUnixNano: nowNano(), attributes });
+    return this;
+  }
+
+  recordException(err: any): this {
+    this.addEvent('exception', {
+      'exception.type': err?.name || 'Error',
+      'exception.message': String(err?.message || err),
+    });
+    return this.setError(err?.message);
+  }
+
+  setError(message?: string): this {
+    this.status = { code: STATUS_ERROR, message };
+    return this;
+  }
+
+  setOk(): this {
+    if (this.status.code !== STATUS_ERROR) this.status = { code: STATUS_OK };
+    return this;
+  }
+
+  end(): void {
+    if (this.endTimeUnixNano) return;
+    this.endTimeUnixNano = nowNano();
+    if (this.sampled) enqueue(this);
+  }
+
+  /** @internal OTLP JSON representation */
+  toOtlp(): Record<string, unknown> {
+    return {
+      traceId: this.traceId,
+      spanId: this.spanId,
+      ...(this.parentSpanId ? { parentSpanId: this.parentSpanId } : {}),
+      name: this.name,
+      kind: KIND_MAP[this.kind],
+      startTimeUnixNano: this.startTimeUnixNano,
+      endTimeUnixNano: this.endTimeUnixNano,
+      attributes: toOtlpAttributes(this.attributes),
+      events: this.events.map(e => ({ name: e.name, timeUnixNano: e.timeUnixNano, attributes: toOtlpAttributes(e.attributes) })),
+      status: this.status,
+    };
+  }
+}
+
+/**
+ * Start a span. Parent defaults to the active span in the current async context;
+ * pass `parent: null` to force a new root.
+ */
+export function startSpan(name: string, opts: { kind?: SpanKind; parent?: SpanContext | null; attributes?: Record<string, AttributeValue | undefined> } = {}): Span {
+  const parent = opts.parent === undefined ? currentSpan()?.context() : opts.parent;
+  const span = new Span(name, opts.kind || 'internal', parent);
+  if (opts.attributes) span.setAttributes(opts.attributes);
+  return span;
+}
+
+/**
+ * Run an async function inside a child span; errors are recorded and rethrown.
+ */
+export async function traced<T>(name: string, fn: (span: Span) => Promise<T>, attributes?: Record<string, AttributeValue | undefined>): Promise<T> {
+  if (!tracingEnabled()) return fn(NOOP_SPAN);
+  const span = startSpan(name, { attributes });
+  try {
+    const result = await withSpan(span, () => fn(span));
+    span.setOk();
+    return result;
+  } catch (err) {
+    span.recordException(err);
+    throw err;
+  } finally {
+    span.end();
+  }
+}
+
+const NOOP_SPAN = new Span('noop', 'internal', { traceId: '0'.repeat(32), spanId: '0'.repeat(16), sampled: false });
+
+// ─── Exporter ────────────────────────────────────────────
+
+let _buffer: Span[] = [];
+let _timer: ReturnType<typeof setInterval> | null = null;
+let _flushing = false;
+let _dropped = 0;
+
+function toOtlpAttributes(attrs: Record<string, AttributeValue>): Array<{ key: string; value: Record<string, unknown> }> {
+  return Object.entries(attrs).map(([key, v]) => ({
+    key,
+    value: typeof v === 'number'
+      ? (Number.isInteger(v) ? { intValue: String(v) } : { doubleValue: v })
+      : typeof v === 'boolean' ? { boolValue: v } : { stringValue: String(v) },
+  }));
+}
+
+function enqueue(span: Span): void {
+  if (_buffer.length >= MAX_BUFFER) { _dropped++; return; }
+  _buffer.push(span);
+  if (!_timer) {
+    _timer = setInterval(() => { flushSpans().catch(() => {}); }, FLUSH_INTERVAL_MS);
+    if (typeof _timer === 'object' && 'unref' in _timer) _timer.unref();
+  }
+  if (_buffer.length >= BATCH_SIZE) flushSpans().catch(() => {});
+}
+
+/** Export all buffered spans now. Safe to call on shutdown. */
+export async function flushSpans(): Promise<void> {
+  if (!_endpoint || _flushing || _buffer.length === 0) return;
+  _flushing = true;
+  try {
+    while (_buffer.length > 0) {
+      const batch = _buffer.splice(0, BATCH_SIZE);
+      const body = {
+        resourceSpans: [{
+          resource: {
+            attributes: toOtlpAttributes({
+              'service.name': _serviceName,
+              'service.version': _serviceVersion,
+              'telemetry.sdk.name': 'agenticmail-enterprise',
+              'telemetry.sdk.language': 'nodejs',
+            }),
+          },
+          scopeSpans: [{ scope: { name: '@agenticmail/enterprise' }, spans: batch.map(s => s.toOtlp()) }],
+        }],
+      };
+      const ctrl = new AbortController();
+      const timer = setTimeout(() => ctrl.abort(), 10_000);
+      try {
+        const res = await fetch(_endpoint, {
+          method: 'POST',
+          headers: { 'Content-Type': 'application/json', ..._headers },
+          body: JSON.stringify(body),
+          signal: ctrl.signal,
+        });
+        if (!res.ok) console.warn(`[tracing] OTLP export failed: HTTP ${res.status}`);
+      } finally {
+        clearTimeout(timer);
+      }
+    }
+    if (_dropped > 0) {
+      console.warn(`[tracing] Dropped ${_dropped} spans (buffer full)`);
+      _dropped = 0;
+    }
+  } catch (e: any) {
+    console.warn(`[tracing] OTLP export error: ${e.message}`);
+  } finally {
+    _flushing = false;
+  }
+}
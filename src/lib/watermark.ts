@@ -0,0 +1,30 @@
+/**
+ * Viewer Watermarks
+ *
+ * Sensitive content (compliance reports, quarantined DLP matches, case
+ * transcripts) is shown in the dashboard's secure viewer rather than
+ * downloaded raw. The route that serves it returns a stamp identifying
+ * who is looking, which the viewer tiles across the content, and writes
+ * the same identity to the audit log, so a screenshot can be traced back
+ * to an access record.
+ */
+
+export interface ViewerStamp {
+  userId?: string;
+  email?: string;
+  ip?: string;
+  requestId?: string;
+  viewedAt: string;
+  /** One line for the watermark: "jane@corp.com · 2026-10-16 14:02 UTC · 10.0.0.4" */
+  label: string;
+}
+
+export function viewerStamp(c: any): ViewerStamp {
+  const userId = c.req.header('X-User-Id') || undefined;
+  const email = c.req.header('X-User-Email') || undefined;
+  const ip = c.req.header('x-forwarded-for')?.split(',')[0]?.trim() || c.req.header('x-real-ip') || undefined;
+  const requestId = c.req.header('X-Request-Id') || undefined;
+  const viewedAt = new Date().toISOString();
+  const label = [email || userId || 'unknown viewer', viewedAt.slice(0, 16).replace('T', ' ') + ' UTC', ip].filter(Boolean).join(' · ');
+  return { userId, email, ip, requestId, viewedAt, label };
+}
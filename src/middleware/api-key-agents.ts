@@ -0,0 +1,92 @@
+/**
+ * AgenticMail Enterprise — Agent-Bound API Keys
+ *
+ * A service-account key can be bound to one agent, for that agent's
+ * external integrations. A bound key only gets through on requests about
+ * its agent: routes addressed to it (/api/agents/:id,
+ * /api/engine/agents/:id/...), or list routes that narrow their results to
+ * the agentId query parameter when that names it. Its scopes still apply
+ * on top of that. When the agent is archived, deleted or retired, its
+ * bound keys are revoked.
+ *
+ * Bindings live in the engine's engine_settings table under
+ * 'api_key_agents' ({ keyId: agentId }) and are cached here the same way
+ * as the per-key IP allowlists.
+ */
+
+import type { DatabaseAdapter } from '../db/adapter.js';
+
+export type ApiKeyAgentBindings = Record<string, string>;
+
+// ─── Cache ───────────────────────────────────────────────
+
+const CACHE_TTL_MS = 15_000;
+
+let _bindings: ApiKeyAgentBindings = {};
+let _loadedAt = 0;
+let _source: (() => Promise<ApiKeyAgentBindings | null>) | null = null;
+
+/** Where the saved bindings come from (called once by the admin routes). */
+export function setApiKeyAgentSource(source: () => Promise<ApiKeyAgentBindings | null>): void {
+  _source = source;
+  _loadedAt = 0;
+}
+
+/** Force a reload after a binding is saved. */
+export async function invalidateApiKeyAgentBindings(): Promise<void> {
+  _loadedAt = 0;
+  await getApiKeyAgentBindings();
+}
+
+export async function getApiKeyAgentBindings(): Promise<ApiKeyAgentBindings> {
+  if (!_source || Date.now() - _loadedAt < CACHE_TTL_MS) return _bindings;
+  try {
+    _bindings = (await _source()) || {};
+  } catch {
+    // Keep the last good bindings
+  }
+  _loadedAt = Date.now();
+  return _bindings;
+}
+
+/** The agent a key is bound to, if any */
+export async function apiKeyAgentFor(keyId: string): Promise<string | undefined> {
+  return (await getApiKeyAgentBindings())[keyId];
+}
+
+/** Routes (below /api) whose next path segment is the agent id */
+const AGENT_ROUTE_PREFIXES = [
+  '/agents/', '/engine/agents/', '/engine/bridge/agents/', '/engine/agent-lifecycle/', '/engine/avatars/',
+  '/engine/agent-webhooks/agents/', '/engine/transcripts/agent/', '/engine/logs/agent/',
+];
+
+/** Read-only list routes that return only the agentId query parameter's agent */
+const AGENT_FILTERED_ROUTES = new Set([
+  '/engine/activity/events', '/engine/activity/tool-calls', '/engine/activity/trend', '/engine/activity/stream',
+  '/engine/journal', '/engine/messages', '/engine/messages/stream', '/engine/dlp/violations',
+  '/engine/guardrails/interventions', '/engine/takeovers', '/engine/qa/queue', '/engine/qa/summary', '/engine/qa/export',
+]);
+
+/** True if the request is addressed to the agent, or is a list filtered down to it. */
+export function requestIsForAgent(agentId: string, method: string, path: string, queryAgentId?: string | null): boolean {
+  const p = path.replace(/^\/api(?=\/)/, '').replace(/\/+$/, '');
+  if (AGENT_ROUTE_PREFIXES.some(prefix => p.startsWith(prefix) && p.slice(prefix.length).split('/')[0] === agentId)) return true;
+  return (method === 'GET' || method === 'HEAD') && queryAgentId === agentId && AGENT_FILTERED_ROUTES.has(p);
+}
+
+/**
+ * Revoke every live key bound to an agent; returns the keys revoked so the
+ * caller can audit them. Bindings are left in place for the record.
+ */
+export async function revokeAgentApiKeys(db: DatabaseAdapter, agentId: string): Promise<Array<{ id: string; name: string }>> {
+  await invalidateApiKeyAgentBindings();
+  const ids = Object.entries(_bindings).filter(([, bound]) => bound === agentId).map(([keyId]) => keyId);
+  const revoked: Array<{ id: string; name: string }> = [];
+  for (const id of ids) {
+    const key = await db.getApiKey(id).catch(() => null);
+    if (!key || key.revoked) continue;
+    await db.revokeApiKey(id);
+    revoked.push({ id, name: key.name });
+  }
+  return revoked;
+}
@@ -0,0 +1,70 @@
+/**
+ * AgenticMail Enterprise — Per-Key IP Allowlists
+ *
+ * An API key can be limited to a list of IPv4 addresses and CIDR ranges,
+ * so a leaked key is useless from outside the corporate network. Keys
+ * without a list work from anywhere, as before. The client IP is the one
+ * the firewall resolves, so trusted-proxy settings apply here too.
+ *
+ * Lists live in the engine's engine_settings table under
+ * 'api_key_ip_allowlists' ({ keyId: ['10.0.0.0/8', ...] }) and are cached
+ * here the same way role-permissions caches the permission matrix.
+ */
+
+import { compileIpMatcher, isValidIpOrCidr } from '../lib/cidr.js';
+
+export type ApiKeyIpAllowlists = Record<string, string[]>;
+
+/** Most entries a key's list may have; past this a firewall rule is the better tool */
+export const MAX_API_KEY_IP_ENTRIES = 50;
+
+/** Trim, drop blanks and duplicates, and reject anything that isn't an IPv4 address or CIDR. */
+export function normalizeIpAllowlist(raw: unknown): string[] {
+  if (raw === undefined || raw === null) return [];
+  if (!Array.isArray(raw)) throw new Error('ipAllowlist must be an array of IPv4 addresses or CIDR ranges');
+  const entries = [...new Set(raw.map(e => String(e).trim()).filter(Boolean))];
+  const bad = entries.find(e => !isValidIpOrCidr(e));
+  if (bad) throw new Error(`Not an IPv4 address or CIDR range: ${bad}`);
+  if (entries.length > MAX_API_KEY_IP_ENTRIES) throw new Error(`ipAllowlist can have at most ${MAX_API_KEY_IP_ENTRIES} entries`);
+  return entries;
+}
+
+// ─── Cache ───────────────────────────────────────────────
+
+const CACHE_TTL_MS = 15_000;
+
+let _lists: ApiKeyIpAllowlists = {};
+let _matchers = new Map<string, (ip: string) => boolean>();
+let _loadedAt = 0;
+let _source: (() => Promise<ApiKeyIpAllowlists | null>) | null = null;
+
+/** Where the saved lists come from (called once by the admin routes). */
+export function setApiKeyIpSource(source: () => Promise<ApiKeyIpAllowlists | null>): void {
+  _source = source;
+  _loadedAt = 0;
+}
+
+/** Force a reload after a list is saved. */
+export async function invalidateApiKeyIpAllowlists(): Promise<void> {
+  _loadedAt = 0;
+  await getApiKeyIpAllowlists();
+}
+
+export async function getApiKeyIpAllowlists(): Promise<ApiKeyIpAllowlists> {
+  if (!_source || Date.now() - _loadedAt < CACHE_TTL_MS) return _lists;
+  try {
+    _lists = (await _source()) || {};
+    _matchers = new Map(Object.entries(_lists).filter(([, list]) => list.length > 0).map(([id, list]) => [id, compileIpMatcher(list)]));
+  } catch {
+    // Keep the last good lists
+  }
+  _loadedAt = Date.now();
+  return _lists;
+}
+
+/** True if the key has no list or the IP is on it. */
+export async function apiKeyIpAllowed(keyId: string, ip: string): Promise<boolean> {
+  await getApiKeyIpAllowlists();
+  const matcher = _matchers.get(keyId);
+  return !matcher || matcher(ip);
+}
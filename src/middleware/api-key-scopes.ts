@@ -0,0 +1,68 @@
+/**
+ * AgenticMail Enterprise — API Key Scopes
+ *
+ * A key's scopes decide which requests it may make.
+ *
+ *   read   any GET request
+ *   write  any request (includes read)
+ *   admin  also routes that need the admin or owner role
+ *
+ * Resource scopes narrow that to one area of the API: 'agents:read',
+ * 'agents:write', 'audit:read'... They never open admin-only routes; only
+ * the 'admin' scope does. '*' (keys created through the API without
+ * scopes) grants everything.
+ */
+
+export type ApiKeyAccess = 'read' | 'write';
+
+export const API_KEY_SCOPES = ['read', 'write', 'admin'] as const;
+export type ApiKeyScope = typeof API_KEY_SCOPES[number];
+
+export interface ApiKeyResource {
+  id: string;
+  label: string;
+  /** Access levels that make sense for the area; audit and the like are read-only */
+  access: ApiKeyAccess[];
+  /** Request paths below /api that belong to the area */
+  prefixes: string[];
+}
+
+export const API_KEY_RESOURCES: ApiKeyResource[] = [
+  { id: 'agents', label: 'Agents', access: ['read', 'write'], prefixes: ['/agents', '/engine/agents', '/engine/bridge/agents', '/engine/agent-lifecycle', '/engine/avatars', '/engine/agent-webhooks', '/engine/workforce'] },
+  { id: 'messages', label: 'Messages', access: ['read', 'write'], prefixes: ['/engine/messages', '/engine/message-search', '/engine/message-labels', '/engine/drafts', '/engine/follow-ups', '/engine/tasks'] },
+  { id: 'knowledge', label: 'Knowledge & memory', access: ['read', 'write'], prefixes: ['/engine/knowledge-bases', '/engine/knowledge-contribution', '/engine/knowledge-import', '/engine/memory'] },
+  { id: 'audit', label: 'Audit log', access: ['read'], prefixes: ['/audit', '/engine/activity'] },
+  { id: 'compliance', label: 'Compliance', access: ['read', 'write'], prefixes: ['/engine/compliance', '/engine/ediscovery', '/engine/retention', '/engine/dlp'] },
+  { id: 'users', label: 'Users', access: ['read', 'write'], prefixes: ['/users'] },
+  { id: 'settings', label: 'Settings', access: ['read', 'write'], prefixes: ['/settings'] },
+];
+
+export function isValidApiKeyScope(scope: unknown): boolean {
+  if (typeof scope !== 'string') return false;
+  if (scope === '*' || (API_KEY_SCOPES as readonly string[]).includes(scope)) return true;
+  const [resource, access] = scope.split(':');
+  return !!API_KEY_RESOURCES.find(r => r.id === resource)?.access.includes(access as ApiKeyAccess);
+}
+
+export function apiKeyHasScope(scopes: string[] | undefined, scope: ApiKeyScope): boolean {
+  if (!scopes) return false;
+  if (scopes.includes('*') || scopes.includes('admin')) return true;
+  return scopes.includes(scope) || (scope === 'read' && scopes.includes('write'));
+}
+
+/** The resource area a request path (with or without the /api prefix) falls in, if any */
+export function apiKeyResourceFor(path: string): ApiKeyResource | undefined {
+  const p = path.replace(/^\/api(?=\/)/, '');
+  return API_KEY_RESOURCES.find(r => r.prefixes.some(prefix => p === prefix || p.startsWith(prefix + '/')));
+}
+
+/** Whether a key may make a request, ignoring admin-only routes (see requireRole) */
+export function apiKeyAccess(scopes: string[], method: string, path: string): { ok: true } | { ok: false; required: string } {
+  const access: ApiKeyAccess = ['GET', 'HEAD', 'OPTIONS'].includes(method) ? 'read' : 'write';
+  if (apiKeyHasScope(scopes, access)) return { ok: true };
+  const resource = apiKeyResourceFor(path);
+  if (resource && (scopes.includes(`${resource.id}:${access}`) || (access === 'read' && scopes.includes(`${resource.id}:write`)))) {
+    return { ok: true };
+  }
+  return { ok: false, required: resource ? `${resource.id}:${access}` : access };
+}
@@ -0,0 +1,49 @@
+/**
+ * AgenticMail Enterprise — Dashboard Call Log
+ *
+ * Records every API call tagged with an X-Action-Id header (sent by the
+ * dashboard for a few seconds after each click/submit) into the call-log
+ * ledger (see lib/call-log.ts). Untagged requests pass straight through.
+ */
+
+import type { MiddlewareHandler } from 'hono';
+import { recordCall } from '../lib/call-log.js';
+
+const ACTION_ID_RE = /^[0-9a-f]{8,32}$/;
+
+export function callLogMiddleware(): MiddlewareHandler {
+  return async (c, next) => {
+    const actionId = c.req.header('x-action-id');
+    if (!actionId || !ACTION_ID_RE.test(actionId)) return next();
+
+    const start = Date.now();
+    await next();
+
+    let label = 'action';
+    try { label = decodeURIComponent(c.req.header('x-action-label') || 'action').slice(0, 80); } catch { /* malformed label */ }
+
+    // Capture the error message the UI would have shown
+    let error: string | undefined;
+    if (c.res.status >= 400 && (c.res.headers.get('Content-Type') || '').includes('application/json')) {
+      try {
+        const body: any = await c.res.clone().json();
+        if (typeof body?.error === 'string') error = body.error.slice(0, 300);
+      } catch { /* non-JSON or encrypted body */ }
+    }
+
+    recordCall({
+      actionId,
+      actionLabel: label,
+      page: c.req.header('x-dashboard-page') || undefined,
+      userId: c.get('userId' as any) || undefined,
+      method: c.req.method,
+      path: c.req.path,
+      status: c.res.status,
+      durationMs: Date.now() - start,
+      requestId: c.get('requestId' as any) || undefined,
+      traceId: c.res.headers.get('X-Trace-Id') || undefined,
+      error,
+      timestamp: new Date(start).toISOString(),
+    });
+  };
+}
@@ -0,0 +1,93 @@
+/**
+ * AgenticMail Enterprise — Request Coalescing
+ *
+ * Singleflight for API reads: concurrent identical GETs from the same caller
+ * share one backend execution. An agent detail page and a second tab open on
+ * the same agent fire the same handful of reads at once; only the first
+ * runs, the rest get a copy of its response.
+ *
+ * Mounted after authentication, so every request is authenticated and
+ * checked (API key IP allowlists, agent bindings, scopes, org scoping) on
+ * its own before it can share anything. The key is the identity auth
+ * resolved (user or API key, role, bound org, impersonator) plus the
+ * client IP, path and query; requests auth let through anonymously are
+ * never shared.
+ *
+ * Only requests already in flight are shared — nothing is cached once the
+ * leader's response completes. Streaming responses (SSE, downloads) aren't
+ * buffered; followers of such a request simply run on their own.
+ */
+
+import type { MiddlewareHandler } from 'hono';
+import { createHash } from 'node:crypto';
+import { coalescedRequestsTotal } from '../lib/metrics.js';
+import { clientIpOf } from './firewall.js';
+
+interface SharedResponse {
+  status: number;
+  headers: [string, string][];
+  body: ArrayBuffer;
+}
+
+/** Larger bodies aren't shared — the leader's response is passed through untouched */
+const MAX_SHARED_BYTES = 5 * 1024 * 1024;
+const UNSHAREABLE_TYPES = ['text/event-stream', 'application/octet-stream', 'application/zip', 'application/pdf'];
+
+const _inflight = new Map<string, Promise<SharedResponse | null>>();
+
+/** Who auth decided the caller is, or null when the route skipped auth */
+function identityOf(c: any): string | null {
+  const userId = c.get('userId');
+  if (!userId) return null;
+  const identity = c.get('authType') === 'api-key'
+    ? ['key', c.get('apiKeyId')]
+    : ['user', userId, c.get('userRole') || '', c.get('userOrgId') || '', c.get('clientOrgId') || '', c.get('impersonatedBy') || ''];
+  identity.push(clientIpOf(c));
+  return createHash('sha256').update(identity.join('\n')).digest('hex').slice(0, 32);
+}
+
+function shareable(res: Response): boolean {
+  const type = (res.headers.get('Content-Type') || '').toLowerCase();
+  if (UNSHAREABLE_TYPES.some(t => type.startsWith(t))) return false;
+  if (res.headers.has('Content-Disposition')) return false;
+  const length = parseInt(res.headers.get('Content-Length') || '0', 10);
+  return !(length > MAX_SHARED_BYTES);
+}
+
+export function requestCoalescing(): MiddlewareHandler {
+  return async (c, next) => {
+    if (c.req.method !== 'GET' || (c.req.header('accept') || '').includes('text/event-stream')) return next();
+    const identity = identityOf(c);
+    if (!identity) return next();
+
+    const url = new URL(c.req.url);
+    const key = identity + ' ' + url.pathname + url.search + ' ' + (c.req.header('x-transport-encryption') || '');
+
+    const pending = _inflight.get(key);
+    if (pending) {
+      const shared = await pending;
+      if (!shared) return next();
+      coalescedRequestsTotal.inc({});
+      const headers = new Headers(shared.headers);
+      headers.set('X-Coalesced', '1');
+      return new Response(shared.body.slice(0), { status: shared.status, headers });
+    }
+
+    let settle!: (shared: SharedResponse | null) => void;
+    _inflight.set(key, new Promise(resolve => { settle = resolve; }));
+    try {
+      await next();
+      if (!shareable(c.res)) { settle(null); return; }
+      const body = await c.res.arrayBuffer();
+      // Cookies and request IDs belong to the leader's request alone
+      const headers = [...c.res.headers.entries()].filter(([name]) => name !== 'set-cookie' && name !== 'x-request-id');
+      c.res = new Response(body, { status: c.res.status, headers: c.res.headers });
+      settle(body.byteLength > MAX_SHARED_BYTES ? null : { status: c.res.status, headers, body });
+    } catch (err) {
+      settle(null);
+      throw err;
+    } finally {
+      _inflight.delete(key);
+    }
+  };
+}
@@ -6,11 +6,16 @@
  * with wildcard host patterns (e.g., "*.example.com").
  *
  * Now supports hot-reload via centralized network config.
+ *
+ * Agents handling classified data (see lib/classification.ts) can be held
+ * to a tighter allowlist: at or above classifiedMinLevel only
+ * classifiedAllowedHosts are reachable, whatever the general mode says.
  */
 
 import type { FirewallConfig } from '../db/adapter.js';
 import { hostMatchesPattern } from '../lib/cidr.js';
 import { onNetworkConfigChange, getNetworkConfigSync } from './network-config.js';
+import { agentClassification, meetsClassification } from '../lib/classification.js';
 
 export interface EgressFilter {
   /** Throws if the outbound URL is blocked by policy. */
@@ -25,7 +30,7 @@ onNetworkConfigChange((config) => {
   _currentConfig = config.egress;
 });
 
-function _validate(url: string, config?: FirewallConfig['egress']): void {
+function _validate(url: string, config?: FirewallConfig['egress'], agentId?: string): void {
   if (!config?.enabled) return;
 
   let parsed: URL;
@@ -42,6 +47,21 @@ function _validate(url: string, config?: FirewallConfig['egress']): void {
 
   const mode = config.mode || 'blocklist';
 
+  // Classified agents: only the classified allowlist, checked before the general rules
+  if (config.classifiedMinLevel && agentId) {
+    const level = agentClassification(agentId);
+    if (level && meetsClassification(level, config.classifiedMinLevel)) {
+      const allowed = (config.classifiedAllowedHosts || []).some((pattern) =>
+        hostMatchesPattern(host, pattern),
+      );
+      if (!allowed) {
+        throw new Error(
+          `Outbound request to ${host}:${port} blocked by egress policy (agent handles ${level} data; host not in classified allowlist)`,
+        );
+      }
+    }
+  }
+
   // Host filtering
   if (mode === 'allowlist') {
     const allowed = (config.allowedHosts || []).some((pattern) =>
@@ -98,7 +118,7 @@ export function createEgressFilter(
  * Global egress filter that reads from the centralized network config.
  * Use this in agent tools — always up to date with dashboard settings.
  */
-export function validateEgress(url: string): void {
+export function validateEgress(url: string, agentId?: string): void {
   const config = _currentConfig || getNetworkConfigSync().egress;
-  _validate(url, config);
+  _validate(url, config, agentId);
 }
@@ -7,6 +7,7 @@
 
 import type { MiddlewareHandler } from 'hono';
 import { getNetworkConfig } from './network-config.js';
+import { renderPage } from '../lib/templates.js';
 
 const COUNTRY_HEADERS = [
   'cf-ipcountry',         // Cloudflare (proxy mode)
@@ -26,6 +27,11 @@ const GEO_CACHE_TTL = 3600_000; // 1 hour
 const GEO_CACHE_MAX = 10_000;
 const GEO_LOOKUP_TIMEOUT = 3_000; // 3s — don't block requests for slow lookups
 
+/** Loopback and private-range addresses, which have no public location */
+export function isLocalIp(ip: string): boolean {
+  return ip === 'unknown' || ip === '127.0.0.1' || ip === '::1' || ip.startsWith('192.168.') || ip.startsWith('10.') || ip === 'localhost';
+}
+
 async function lookupCountry(ip: string): Promise<string | null> {
   // Check cache
   const cached = GEO_CACHE.get(ip);
@@ -34,9 +40,7 @@ async function lookupCountry(ip: string): Promise<string | null> {
   }
 
   // Skip private/local IPs
-  if (ip === 'unknown' || ip === '127.0.0.1' || ip === '::1' || ip.startsWith('192.168.') || ip.startsWith('10.') || ip === 'localhost') {
-    return null;
-  }
+  if (isLocalIp(ip)) return null;
 
   try {
     const controller = new AbortController();
@@ -66,35 +70,54 @@ async function lookupCountry(ip: string): Promise<string | null> {
   }
 }
 
-// ─── Block Page HTML ─────────────────────────────────────
-
-const GEO_BLOCK_PAGE = `<!DOCTYPE html>
-<html lang="en">
-<head>
-<meta charset="utf-8">
-<meta name="viewport" content="width=device-width,initial-scale=1">
-<title>Access Restricted</title>
-<style>
-  *{margin:0;padding:0;box-sizing:border-box}
-  body{min-height:100vh;display:flex;align-items:center;justify-content:center;font-family:-apple-system,BlinkMacSystemFont,'Segoe UI',Roboto,sans-serif;background:#0f1117;color:#e1e4e8}
-  .container{text-align:center;max-width:480px;padding:40px 24px}
-  .icon{width:64px;height:64px;margin:0 auto 24px;border-radius:16px;background:rgba(255,107,107,0.1);display:flex;align-items:center;justify-content:center}
-  .icon svg{width:32px;height:32px;stroke:#ff6b6b;fill:none;stroke-width:2;stroke-linecap:round;stroke-linejoin:round}
-  h1{font-size:24px;font-weight:700;margin-bottom:12px;color:#fff}
-  p{font-size:15px;line-height:1.6;color:#8b949e;margin-bottom:8px}
-  .subtle{font-size:13px;color:#484f58;margin-top:24px}
-</style>
-</head>
-<body>
-<div class="container">
-  <div class="icon"><svg viewBox="0 0 24 24"><circle cx="12" cy="12" r="10"/><path d="M2 12h20"/><path d="M12 2a15.3 15.3 0 0 1 4 10 15.3 15.3 0 0 1-4 10 15.3 15.3 0 0 1-4-10 15.3 15.3 0 0 1 4-10z"/><line x1="4.93" y1="4.93" x2="19.07" y2="19.07"/></svg></div>
-  <h1>Access Restricted</h1>
-  <p>This service is not available in your region. Access has been restricted by the administrator.</p>
-  <p>If you believe this is an error, please contact the site owner.</p>
-  <div class="subtle">Error 403</div>
-</div>
-</body>
-</html>`;
+// ─── Location Lookup (audit log detail) ─────────────────
+
+export interface IpLocation {
+  ip: string;
+  /** Loopback or private range; nothing to look up */
+  local?: boolean;
+  country?: string;
+  countryCode?: string;
+  region?: string;
+  city?: string;
+  isp?: string;
+}
+
+const LOCATION_CACHE = new Map<string, { location: IpLocation; ts: number }>();
+
+/**
+ * Country, region, city and ISP for an IP, from the same lookup service
+ * and with the same cache limits as the country check. Null when the
+ * lookup fails; audit views show the bare IP then.
+ */
+export async function lookupIpLocation(ip: string): Promise<IpLocation | null> {
+  if (isLocalIp(ip)) return { ip, local: true };
+  const cached = LOCATION_CACHE.get(ip);
+  if (cached && Date.now() - cached.ts < GEO_CACHE_TTL) return cached.location;
+
+  try {
+    const controller = new AbortController();
+    const timer = setTimeout(() => controller.abort(), GEO_LOOKUP_TIMEOUT);
+    const resp = await fetch(`http://ip-api.com/json/${encodeURIComponent(ip)}?fields=status,country,countryCode,regionName,city,isp`, {
+      signal: controller.signal,
+    });
+    clearTimeout(timer);
+
+    if (!resp.ok) return null;
+    const data = await resp.json() as { status: string; country?: string; countryCode?: string; regionName?: string; city?: string; isp?: string };
+    if (data.status !== 'success') return null;
+
+    const location: IpLocation = { ip, country: data.country, countryCode: data.countryCode, region: data.regionName, city: data.city, isp: data.isp };
+    if (LOCATION_CACHE.size >= GEO_CACHE_MAX) {
+      const oldest = LOCATION_CACHE.keys().next().value;
+      if (oldest) LOCATION_CACHE.delete(oldest);
+    }
+    LOCATION_CACHE.set(ip, { location, ts: Date.now() });
+    return location;
+  } catch {
+    return null;
+  }
+}
 
 /**
  * Geo-IP restriction middleware.
@@ -148,7 +171,7 @@ export function geoIpRestriction(): MiddlewareHandler {
       }
 
       // Serve a proper HTML block page for browsers
-      return c.html(GEO_BLOCK_PAGE, 403);
+      return c.html(renderPage('geo-blocked', { title: 'Access Restricted' }), 403);
     }
 
     return next();
@@ -8,6 +8,7 @@
 import type { Context, Next, MiddlewareHandler } from 'hono';
 import { KeyedRateLimiter, requestId } from '../lib/resilience.js';
 import type { DatabaseAdapter } from '../db/adapter.js';
+import { apiKeyHasScope } from './api-key-scopes.js';
 
 // ─── Request ID ──────────────────────────────────────────
 
@@ -152,9 +153,14 @@ export function rateLimiter(config: RateLimitConfig): MiddlewareHandler {
 // ─── Security Headers (DB-backed) ────────────────────────
 
 import { getNetworkConfig } from './network-config.js';
+import { generateNonce } from '../security/csp.js';
 
 export function securityHeaders(): MiddlewareHandler {
   return async (c: Context, next: Next) => {
+    // Per-request nonce for the inline <script> blocks of server-rendered pages
+    const nonce = generateNonce();
+    c.set('cspNonce' as any, nonce);
+
     await next();
 
     // Read security header config from DB (cached 15s)
@@ -166,10 +172,24 @@ export function securityHeaders(): MiddlewareHandler {
       c.header('X-Content-Type-Options', 'nosniff');
     }
 
-    // X-Frame-Options
+    // Framing — X-Frame-Options can't express an origin allowlist, so embeddable
+    // pages drop it and rely on CSP frame-ancestors; everything else stays locked.
     const xfo = sh?.xFrameOptions || 'DENY';
-    if (xfo !== 'ALLOW') {
-      c.header('X-Frame-Options', xfo);
+    const embedAncestors = embeddableAncestors(c, sh?.embedding);
+    const frameAncestors = embedAncestors || (xfo === 'ALLOW' ? null : xfo === 'DENY' ? "'none'" : "'self'");
+    if (!embedAncestors && xfo !== 'ALLOW') c.header('X-Frame-Options', xfo);
+
+    // Content-Security-Policy — HTML documents get the full policy (scripts only
+    // from our origin or carrying this request's nonce); everything else just
+    // the framing directive. A policy set by the handler itself is left alone.
+    const isHtml = (c.res.headers.get('Content-Type') || '').startsWith('text/html');
+    if (embedAncestors || !c.res.headers.has('Content-Security-Policy')) {
+      if (isHtml && sh?.contentSecurityPolicy !== false) {
+        const backends = (netConfig.network?.failover?.enabled && netConfig.network.failover.backends) || [];
+        c.header('Content-Security-Policy', documentPolicy(nonce, frameAncestors, backends));
+      } else if (frameAncestors) {
+        c.header('Content-Security-Policy', `frame-ancestors ${frameAncestors}`);
+      }
     }
 
     // XSS Protection (legacy — CSP is better, but configurable)
@@ -190,7 +210,67 @@ export function securityHeaders(): MiddlewareHandler {
   };
 }
 
+/** CSP for HTML documents. Inline styles stay allowed — the dashboard sets them everywhere. */
+function documentPolicy(nonce: string, frameAncestors: string | null, backends: string[]): string {
+  const origins = backends.map(b => { try { return new URL(b).origin; } catch { return ''; } }).filter(Boolean);
+  return [
+    "default-src 'self'",
+    `script-src 'self' 'nonce-${nonce}'`,
+    "style-src 'self' 'unsafe-inline'",
+    "img-src 'self' data: blob: https:",
+    "font-src 'self' data: https:",
+    ["connect-src 'self'", ...origins].join(' '),
+    "media-src 'self' data: blob:",
+    "frame-src 'self' blob:",
+    "worker-src 'self' blob:",
+    "object-src 'none'",
+    "base-uri 'self'",
+    "form-action 'self'",
+    ...(frameAncestors ? [`frame-ancestors ${frameAncestors}`] : []),
+  ].join('; ');
+}
+
+/**
+ * Stamp the request's CSP nonce onto every bare <script> tag. Only for HTML we
+ * author (the dashboard shell, docs pages) — never for markup containing user
+ * input, which must not get to run scripts at all.
+ */
+export function nonceScripts(html: string, nonce: string | undefined): string {
+  if (!nonce) return html;
+  return html.replace(/<script(?=[\s>])(?![^>]*\bnonce=)/g, `<script nonce="${nonce}"`);
+}
+
+/** Dashboard pages that may never be framed, regardless of configuration. */
+const NEVER_EMBEDDABLE = new Set(['settings', 'users', 'vault', 'roles', 'database-access', 'organizations', 'login']);
+
+/**
+ * Returns the frame-ancestors source list when this response is an embeddable
+ * read-only dashboard page, or null if the default framing policy applies.
+ */
+function embeddableAncestors(c: Context, embedding?: { enabled?: boolean; frameAncestors?: string[]; routes?: string[] }): string | null {
+  if (!embedding?.enabled || !embedding.frameAncestors?.length || !embedding.routes?.length) return null;
+  if (c.req.method !== 'GET' && c.req.method !== 'HEAD') return null;
+  if (!(c.res.headers.get('Content-Type') || '').startsWith('text/html')) return null;
+  const m = /^\/dashboard\/([a-z0-9-]+)\/?$/.exec(c.req.path);
+  if (!m || NEVER_EMBEDDABLE.has(m[1]) || !embedding.routes.includes(m[1])) return null;
+  return ["'self'", ...embedding.frameAncestors].join(' ');
+}
+
+/** Validates a frame-ancestors origin: https:// origin (optionally *.wildcard), no path. */
+export function isValidFrameAncestor(origin: string): boolean {
+  return /^https:\/\/(\*\.)?[a-z0-9.-]+(:\d{1,5})?$/i.test(origin) || /^http:\/\/(localhost|127\.0\.0\.1)(:\d{1,5})?$/.test(origin);
+}
+
 // ─── Error Handler ───────────────────────────────────────
+//
+// Hono hands errors thrown by a handler to app.onError rather than letting
+// them reach the middleware above, so the same response is produced from
+// both places: errorHandler() catches what middleware throws, and
+// handleAppError is registered as onError for what handlers throw.
+//
+// Browsers navigating to a page get the branded error template; API
+// clients get JSON. Neither ever includes a stack trace — the full error
+// is logged with the request ID the user sees.
 
 export interface ApiError {
   error: string;
@@ -199,33 +279,96 @@ export interface ApiError {
   requestId?: string;
 }
 
+const ERROR_COPY: Record<number, { title: string; message: string }> = {
+  400: { title: 'Bad Request', message: 'The request could not be understood.' },
+  401: { title: 'Sign-in Required', message: 'Please sign in to continue.' },
+  403: { title: 'Access Denied', message: 'You do not have permission to view this page.' },
+  404: { title: 'Page Not Found', message: 'The page you were looking for does not exist or has moved.' },
+  405: { title: 'Method Not Allowed', message: 'This address does not accept that kind of request.' },
+  500: { title: 'Something Went Wrong', message: 'An unexpected error occurred on our side. It has been logged; please try again in a moment.' },
+};
+
+/** A browser navigation (HTML page wanted) rather than an API call */
+function wantsHtmlPage(c: Context): boolean {
+  if (c.req.method !== 'GET' && c.req.method !== 'HEAD') return false;
+  if (/^\/(api|auth\/api)\//.test(c.req.path)) return false;
+  return (c.req.header('Accept') || '').includes('text/html');
+}
+
+/** Error response for `status`; `message` is shown as-is, so only pass user-safe text. */
+export async function errorResponse(c: Context, status: number, message?: string, extra?: Record<string, unknown>): Promise<Response> {
+  const reqId = c.get('requestId' as any) || c.req.header('X-Request-Id');
+  const copy = ERROR_COPY[status] || ERROR_COPY[status >= 500 ? 500 : 400];
+  if (wantsHtmlPage(c)) {
+    const { renderPage } = await import('../lib/templates.js');
+    return c.html(renderPage('error', { title: copy.title, message: message || copy.message, status, requestId: reqId || '-' }), status as any);
+  }
+  return c.json({ error: message || copy.title, ...extra, requestId: reqId } as ApiError, status as any);
+}
+
+/** app.onError handler — logs the error and answers without internals. */
+export async function handleAppError(err: any, c: Context): Promise<Response> {
+  // An HTTPException built with its own Response keeps it
+  if (err?.res instanceof Response && typeof err.getResponse === 'function') return err.getResponse();
+  const status = Number(err?.status || err?.statusCode) || 500;
+  const reqId = c.get('requestId' as any);
+  if (status >= 500) {
+    console.error(`[${new Date().toISOString()}] ERROR req=${reqId} ${c.req.method} ${c.req.path}`, err);
+    return errorResponse(c, status >= 600 ? 500 : status);
+  }
+  return errorResponse(c, status, err.message, {
+    code: err.code,
+    ...(status === 400 && err.details ? { details: err.details } : {}),
+  });
+}
+
 export function errorHandler(): MiddlewareHandler {
   return async (c: Context, next: Next) => {
     try {
       await next();
     } catch (err: any) {
-      const reqId = c.get('requestId' as any);
-      const status = err.status || err.statusCode || 500;
-      const message = status >= 500 ? 'Internal server error' : err.message;
+      return handleAppError(err, c);
+    }
+  };
+}
 
-      // Log full error for 5xx
-      if (status >= 500) {
-        console.error(`[${new Date().toISOString()}] ERROR req=${reqId}`, err);
-      }
+// ─── Not Found / Method Not Allowed ──────────────────────
 
-      const body: ApiError = {
-        error: message,
-        code: err.code,
-        requestId: reqId,
-      };
+type RouteInfo = { method: string; path: string };
 
-      // Include validation details for 400s
-      if (status === 400 && err.details) {
-        body.details = err.details;
-      }
+/** Hono route pattern → RegExp: `:id`, `:id?`, `:id{regex}` and `*` */
+function routePattern(path: string): RegExp {
+  const src = path.split('/').filter(Boolean).map(seg => {
+    if (seg === '*') return '(?:/.*)?';
+    const m = /^:\w+(?:\{(.+)\})?(\?)?$/.exec(seg);
+    if (m) return m[2] ? `(?:/${m[1] ? `(?:${m[1]})` : '[^/]+'})?` : `/${m[1] ? `(?:${m[1]})` : '[^/]+'}`;
+    return '/' + seg.replace(/[.+?^${}()|[\]\\]/g, '\\$&').replace(/\*/g, '[^/]*');
+  }).join('');
+  return new RegExp('^' + (src || '/') + '/?$');
+}
 
-      return c.json(body, status);
+/**
+ * notFound handler that answers 405 (with an Allow header) when the path
+ * exists under another method, and 404 otherwise. `routes` is app.routes;
+ * it's read on each miss so routes added later are included.
+ */
+export function notFoundHandler(getRoutes: () => RouteInfo[]) {
+  const patterns = new Map<string, RegExp>();
+  return async (c: Context): Promise<Response> => {
+    const allowed = new Set<string>();
+    for (const r of getRoutes()) {
+      // Middleware registers as ALL and matches everything
+      if (r.method === 'ALL' || r.method === c.req.method) continue;
+      let re = patterns.get(r.path);
+      if (!re) { try { re = routePattern(r.path); } catch { continue; } patterns.set(r.path, re); }
+      if (re.test(c.req.path)) allowed.add(r.method);
     }
+    if (allowed.size > 0) {
+      if (allowed.has('GET')) allowed.add('HEAD');
+      c.header('Allow', [...allowed].sort().join(', '));
+      return errorResponse(c, 405);
+    }
+    return errorResponse(c, 404, undefined, { error: 'Not found', path: c.req.path });
   };
 }
 
@@ -326,15 +469,19 @@ export function auditLogger(db: DatabaseAdapter): MiddlewareHandler {
 
       const userEmail = c.get('userEmail' as any) || undefined;
       const userRole = c.get('userRole' as any) || undefined;
+      // Calls made with an API key are recorded under the key, so each key
+      // has its own trail; the user it acts as goes in the details
+      const apiKeyId = c.get('authType' as any) === 'api-key' ? c.get('apiKeyId' as any) : undefined;
 
       await db.logEvent({
-        actor: userId,
+        actor: apiKeyId ? `api_key:${apiKeyId}` : userId,
         actorType: 'user',
         action,
         resource: path,
         details: {
           ...(userEmail ? { email: userEmail } : {}),
           ...(userRole ? { role: userRole } : {}),
+          ...(apiKeyId ? { actingAs: userId } : {}),
           method,
         },
         ip: c.req.header('x-forwarded-for')?.split(',')[0]?.trim() || c.req.header('x-real-ip'),
@@ -361,8 +508,12 @@ export function requireRole(minRole: Role): MiddlewareHandler {
   return async (c: Context, next: Next) => {
     const userRole = c.get('userRole' as any) as Role | undefined;
 
-    // API keys bypass role check (scopes handle auth)
+    // API keys bypass the role check; admin routes need the admin scope
+    // instead; resource scopes such as users:write never satisfy it
     if (c.get('authType' as any) === 'api-key') {
+      if (ROLE_HIERARCHY[minRole] >= ROLE_HIERARCHY.admin && !apiKeyHasScope(c.get('apiKeyScopes' as any), 'admin')) {
+        return c.json({ error: 'API key is missing the admin scope', required: 'admin' }, 403);
+      }
       return next();
     }
 
@@ -380,11 +531,20 @@ export function requireRole(minRole: Role): MiddlewareHandler {
 
 // ─── Re-exports ──────────────────────────────────────────
 
-export { ipAccessControl, invalidateFirewallCache } from './firewall.js';
+export { ipAccessControl, invalidateFirewallCache, clientIpOf } from './firewall.js';
+export { requireCapability, hasCapability, capabilitiesFor, CAPABILITIES } from './role-permissions.js';
+export { API_KEY_SCOPES, API_KEY_RESOURCES, apiKeyHasScope, apiKeyAccess, isValidApiKeyScope } from './api-key-scopes.js';
+export { apiKeyIpAllowed, normalizeIpAllowlist, setApiKeyIpSource, invalidateApiKeyIpAllowlists, getApiKeyIpAllowlists } from './api-key-ip.js';
+export { apiKeyAgentFor, requestIsForAgent, revokeAgentApiKeys, setApiKeyAgentSource, invalidateApiKeyAgentBindings, getApiKeyAgentBindings } from './api-key-agents.js';
 export { createEgressFilter, validateEgress, type EgressFilter } from './egress-filter.js';
 export { setNetworkDb, invalidateNetworkConfig, getNetworkConfig, getNetworkConfigSync, onNetworkConfigChange } from './network-config.js';
 export { dnsRebindingProtection } from './dns-rebinding.js';
 export { requestBodyLimit, requestTimeout } from './request-limits.js';
-export { geoIpRestriction } from './geo-ip.js';
+export { geoIpRestriction, lookupIpLocation, isLocalIp, type IpLocation } from './geo-ip.js';
 export { initProxyConfig } from './proxy-config.js';
 export { transportEncryptionMiddleware, setTransportEncryptionConfig, getConfig as getTransportEncryptionConfig, encryptPayload as encryptTransportPayload, decryptPayload as decryptTransportPayload, resetKeys as resetTransportKeys, loadConfig as loadTransportEncryptionConfig, setSettingsDb as setTransportEncryptionSettingsDb } from './transport-encryption.js';
+export { tracingMiddleware } from './tracing.js';
+export { metricsMiddleware } from './metrics.js';
+export { callLogMiddleware } from './call-log.js';
+export { performanceMiddleware } from './performance.js';
+export { requestCoalescing } from './coalesce.js';
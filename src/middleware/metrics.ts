@@ -0,0 +1,27 @@
+/**
+ * AgenticMail Enterprise — Request Metrics
+ *
+ * Records request count and latency per route pattern for the Prometheus
+ * endpoint (see lib/metrics.ts). Route patterns (/api/agents/:id), not raw
+ * paths, keep label cardinality bounded.
+ */
+
+import type { MiddlewareHandler } from 'hono';
+import { httpRequestsTotal, httpRequestDuration } from '../lib/metrics.js';
+
+export function metricsMiddleware(): MiddlewareHandler {
+  return async (c, next) => {
+    const start = performance.now();
+    let status = 500;
+    try {
+      await next();
+      status = c.res.status;
+    } finally {
+      const routePath = c.req.routePath;
+      const route = routePath && routePath !== '*' && routePath !== '/*' ? routePath : 'unmatched';
+      const method = c.req.method;
+      httpRequestsTotal.inc({ method, route, status });
+      httpRequestDuration.observe({ method, route }, (performance.now() - start) / 1000);
+    }
+  };
+}
@@ -0,0 +1,31 @@
+/**
+ * AgenticMail Enterprise — Dashboard Performance
+ *
+ * Records the latency of every API call tagged with an X-Dashboard-Page
+ * header (sent by the dashboard on every call) so the performance page can
+ * show which backend calls sit behind a slow page (see lib/performance.ts).
+ * Route patterns, not raw paths, keep the breakdown readable.
+ */
+
+import type { MiddlewareHandler } from 'hono';
+import { recordPageCall } from '../lib/performance.js';
+
+export function performanceMiddleware(): MiddlewareHandler {
+  return async (c, next) => {
+    const page = c.req.header('x-dashboard-page');
+    // The render report itself isn't part of any page's load
+    if (!page || c.req.path === '/api/admin/performance/renders') return next();
+
+    const start = performance.now();
+    await next();
+
+    const routePath = c.req.routePath;
+    recordPageCall({
+      page,
+      method: c.req.method,
+      route: routePath && routePath !== '*' && routePath !== '/*' ? routePath : 'unmatched',
+      status: c.res.status,
+      durationMs: Math.round(performance.now() - start),
+    });
+  };
+}
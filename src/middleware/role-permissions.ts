@@ -0,0 +1,125 @@
+/**
+ * AgenticMail Enterprise — Role Permission Matrix
+ *
+ * Finer-grained than requireRole: each capability ("view vault", "export
+ * audit log"...) lists the roles allowed to use it. Owners can do
+ * everything and can't be restricted, so a bad matrix can't lock the
+ * organization out. API keys bypass the matrix like they bypass
+ * requireRole — their scopes handle authorization.
+ *
+ * The matrix is stored by the admin routes and cached here, the same way
+ * network-config caches firewall settings.
+ */
+
+import type { Context, MiddlewareHandler, Next } from 'hono';
+
+// ─── Types ───────────────────────────────────────────────
+
+export type EditableRole = 'admin' | 'member' | 'viewer';
+export type RoleMatrix = Record<string, EditableRole[]>;
+
+export interface Capability {
+  id: string;
+  label: string;
+  group: string;
+  description: string;
+  /** Roles granted the capability until an owner changes the matrix */
+  defaultRoles: EditableRole[];
+}
+
+export const EDITABLE_ROLES: EditableRole[] = ['admin', 'member', 'viewer'];
+
+export const CAPABILITIES: Capability[] = [
+  { id: 'vault.view', label: 'View vault', group: 'Vault', description: 'See secret names, metadata, agent access and the vault audit log. Values are never shown.', defaultRoles: ['admin', 'member', 'viewer'] },
+  { id: 'vault.reveal', label: 'Reveal secret values', group: 'Vault', description: 'Decrypt and copy a secret\'s value. Every reveal is written to the vault audit log.', defaultRoles: ['admin'] },
+  { id: 'vault.manage', label: 'Manage vault', group: 'Vault', description: 'Add, rotate and delete secrets, and grant or revoke agent access.', defaultRoles: ['admin'] },
+  { id: 'guardrails.manage', label: 'Manage guardrails', group: 'Guardrails', description: 'Create, edit and delete guardrail and anomaly rules.', defaultRoles: ['admin', 'member'] },
+  { id: 'agents.control', label: 'Pause and stop agents', group: 'Guardrails', description: 'Pause, resume or kill a running agent.', defaultRoles: ['admin', 'member'] },
+  { id: 'dlp.manage', label: 'Manage DLP rules', group: 'DLP', description: 'Create, edit and delete DLP rules and apply rule packs.', defaultRoles: ['admin'] },
+  { id: 'audit.view', label: 'View audit log', group: 'Audit', description: 'Read the organization audit log.', defaultRoles: ['admin'] },
+  { id: 'audit.export', label: 'Export audit log', group: 'Audit', description: 'Download the audit log as CSV or PDF.', defaultRoles: ['admin'] },
+];
+
+const CAPABILITY_IDS = new Set(CAPABILITIES.map(cap => cap.id));
+
+export const DEFAULT_ROLE_MATRIX: RoleMatrix = Object.fromEntries(CAPABILITIES.map(cap => [cap.id, [...cap.defaultRoles]]));
+
+/** Fill in defaults for missing capabilities and drop unknown ones and roles. */
+export function normalizeRoleMatrix(raw: any): RoleMatrix {
+  if (raw !== undefined && (typeof raw !== 'object' || raw === null || Array.isArray(raw))) {
+    throw new Error('matrix must be an object of capability → roles');
+  }
+  const matrix: RoleMatrix = {};
+  for (const cap of CAPABILITIES) {
+    const roles = raw?.[cap.id];
+    if (roles === undefined) { matrix[cap.id] = [...cap.defaultRoles]; continue; }
+    if (!Array.isArray(roles)) throw new Error(`${cap.id}: roles must be an array`);
+    matrix[cap.id] = EDITABLE_ROLES.filter(r => roles.includes(r));
+  }
+  return matrix;
+}
+
+// ─── Cache ───────────────────────────────────────────────
+
+const CACHE_TTL_MS = 15_000;
+
+let _matrix: RoleMatrix = DEFAULT_ROLE_MATRIX;
+let _loadedAt = 0;
+let _source: (() => Promise<RoleMatrix | null>) | null = null;
+
+/** Where the saved matrix comes from (called once by the admin routes). */
+export function setRolePermissionsSource(source: () => Promise<RoleMatrix | null>): void {
+  _source = source;
+  _loadedAt = 0;
+}
+
+/** Force a reload after the matrix is saved. */
+export async function invalidateRolePermissions(): Promise<void> {
+  _loadedAt = 0;
+  await getRolePermissions();
+}
+
+export async function getRolePermissions(): Promise<RoleMatrix> {
+  if (!_source || Date.now() - _loadedAt < CACHE_TTL_MS) return _matrix;
+  try {
+    const saved = await _source();
+    _matrix = saved ? normalizeRoleMatrix(saved) : DEFAULT_ROLE_MATRIX;
+  } catch {
+    // Keep the last good matrix
+  }
+  _loadedAt = Date.now();
+  return _matrix;
+}
+
+// ─── Checks ──────────────────────────────────────────────
+
+export function roleCan(matrix: RoleMatrix, role: string | undefined, capability: string): boolean {
+  if (role === 'owner') return true;
+  return !!role && (matrix[capability] || []).includes(role as EditableRole);
+}
+
+/** Capabilities the role has, for the dashboard to hide what it can't use. */
+export async function capabilitiesFor(role: string | undefined): Promise<string[]> {
+  const matrix = await getRolePermissions();
+  return CAPABILITIES.map(cap => cap.id).filter(id => roleCan(matrix, role, id));
+}
+
+/** True if the request's user may use the capability (API keys always may). */
+export async function hasCapability(c: Context, capability: string): Promise<boolean> {
+  if (c.get('authType' as any) === 'api-key') return true;
+  return roleCan(await getRolePermissions(), c.get('userRole' as any), capability);
+}
+
+export function requireCapability(capability: string): MiddlewareHandler {
+  if (!CAPABILITY_IDS.has(capability)) throw new Error(`Unknown capability: ${capability}`);
+  return async (c: Context, next: Next) => {
+    if (!(await hasCapability(c, capability))) {
+      return c.json({
+        error: 'Insufficient permissions',
+        required: capability,
+        current: c.get('userRole' as any) || 'none',
+      }, 403);
+    }
+    return next();
+  };
+}
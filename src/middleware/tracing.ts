@@ -0,0 +1,53 @@
+/**
+ * AgenticMail Enterprise — Request Tracing
+ *
+ * Opens a SERVER span for every HTTP request, continuing the caller's trace
+ * when a W3C `traceparent` header is present (the dashboard sends one per
+ * page navigation, so all API calls behind a slow page share one trace).
+ *
+ * No-op unless OTEL_EXPORTER_OTLP_ENDPOINT is configured (see lib/tracing.ts).
+ */
+
+import type { MiddlewareHandler } from 'hono';
+import { tracingEnabled, startSpan, withSpan, parseTraceparent } from '../lib/tracing.js';
+
+export function tracingMiddleware(): MiddlewareHandler {
+  return async (c, next) => {
+    if (!tracingEnabled()) return next();
+
+    const parent = parseTraceparent(c.req.header('traceparent'));
+    const span = startSpan(`${c.req.method} ${c.req.path}`, {
+      kind: 'server',
+      parent,
+      attributes: {
+        'http.request.method': c.req.method,
+        'url.path': c.req.path,
+        'url.scheme': c.req.url.startsWith('https://') || c.req.header('x-forwarded-proto') === 'https' ? 'https' : 'http',
+        'user_agent.original': c.req.header('user-agent'),
+        'enterprise.request_id': c.get('requestId' as any),
+        'enterprise.page': c.req.header('x-dashboard-page'),
+      },
+    });
+    c.set('traceSpan' as any, span);
+    c.header('X-Trace-Id', span.traceId);
+
+    try {
+      await withSpan(span, () => next());
+    } catch (err) {
+      span.recordException(err);
+      throw err;
+    } finally {
+      // Matched route pattern gives low-cardinality span names (/api/agents/:id)
+      const route = c.req.routePath;
+      if (route && route !== '*' && route !== '/*') {
+        span.updateName(`${c.req.method} ${route}`);
+        span.setAttribute('http.route', route);
+      }
+      const status = c.res?.status || 500;
+      span.setAttribute('http.response.status_code', status);
+      span.setAttribute('enduser.id', c.get('userId' as any));
+      if (status >= 500) span.setError(`HTTP ${status}`); else span.setOk();
+      span.end();
+    }
+  };
+}
@@ -419,7 +419,7 @@ export async function runAgentLoop(
           inputTokens: usageInput,
           outputTokens: usageOutput,
           costUsd,
-        });
+        }, sessionId);
         cumulativeUsage.inputTokens += usageInput;
         cumulativeUsage.outputTokens += usageOutput;
         cumulativeUsage.costUsd += costUsd;
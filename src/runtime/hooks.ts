@@ -66,6 +66,22 @@ var COMMUNICATION_TOOLS = new Set([
   'agent_message_send', 'agent_message_broadcast',
 ]);
 
+// ─── AI Disclosure ───────────────────────────────────────
+
+/**
+ * Adds the AI-disclosure footer / consent notice to outbound mail. Runs on
+ * every call, after the (possibly cached) permission result, since the
+ * footer depends on this call's recipients.
+ */
+async function withDisclosure(ctx: ToolCallContext, result: HookResult, parameters: Record<string, any>): Promise<HookResult> {
+  if (!result.allowed) return result;
+  try {
+    var { aiDisclosure } = await import('../engine/routes.js');
+    var disclosed = await aiDisclosure.apply({ agentId: ctx.agentId, orgId: ctx.orgId, toolName: ctx.toolName, parameters });
+    return disclosed ? { ...result, modifiedParameters: disclosed } : result;
+  } catch { return result; }
+}
+
 // ─── Create Runtime Hooks ────────────────────────────────
 
 export function createRuntimeHooks(deps: HookDependencies): RuntimeHooks {
@@ -269,7 +285,7 @@ export function createRuntimeHooks(deps: HookDependencies): RuntimeHooks {
     },
 
     // ─── Record LLM Usage ──────────────────────────
-    async recordLLMUsage(agentId, orgId, usage): Promise<void> {
+    async recordLLMUsage(agentId, orgId, usage, sessionId): Promise<void> {
       try {
         var { lifecycle } = await import('../engine/routes.js');
         console.log(`[hooks] recordLLMUsage: agent=${agentId}, input=${usage.inputTokens}, output=${usage.outputTokens}`);
@@ -286,6 +302,7 @@ export function createRuntimeHooks(deps: HookDependencies): RuntimeHooks {
         await activity.record({
           agentId,
           orgId,
+          sessionId: sessionId || undefined,
           type: 'llm_call',
           data: {
             inputTokens: usage.inputTokens,
@@ -332,7 +349,7 @@ export function createRuntimeHooks(deps: HookDependencies): RuntimeHooks {
         // Check permission cache
         var cacheKey = `${ctx.agentId}:${ctx.toolName}`;
         var cached = getCachedPermission(cacheKey);
-        if (cached) return cached;
+        if (cached) return withDisclosure(ctx, cached, ctx.parameters);
 
         // Permission check
         var { permissionEngine } = await import('../engine/routes.js');
@@ -403,7 +420,7 @@ export function createRuntimeHooks(deps: HookDependencies): RuntimeHooks {
         }
 
         setCachedPermission(cacheKey, result);
-        return result;
+        return withDisclosure(ctx, result, result.modifiedParameters || ctx.parameters);
 
       } catch (err: any) {
         var allowed = failMode === 'open';
@@ -211,8 +211,8 @@ export interface RuntimeHooks {
   beforeLLMCall(messages: AgentMessage[], agentId: string, sessionId: string): Promise<AgentMessage[]>;
   /** Check budget before making an LLM call (can block the call) */
   checkBudget(agentId: string, orgId: string, estimatedTokens: number): Promise<BudgetCheckResult>;
-  /** Record LLM usage after each call */
-  recordLLMUsage(agentId: string, orgId: string, usage: { inputTokens: number; outputTokens: number; costUsd: number }): Promise<void>;
+  /** Record LLM usage after each call; `sessionId` ties it to the conversation */
+  recordLLMUsage(agentId: string, orgId: string, usage: { inputTokens: number; outputTokens: number; costUsd: number }, sessionId?: string): Promise<void>;
   /** Look up model pricing from settings */
   getModelPricing(provider: string, modelId: string): Promise<{ inputCostPerMillion: number; outputCostPerMillion: number } | null>;
   /** Check permissions, DLP, guardrails before tool execution */
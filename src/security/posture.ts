@@ -0,0 +1,75 @@
+/**
+ * Security Posture Score
+ *
+ * Scores the effective security configuration (defaults merged with the
+ * saved settings) out of 100. Most checks only cost points, but critical
+ * ones fail the posture outright whatever the score: currently an unmet
+ * encryption-at-rest policy.
+ */
+
+import type { SecurityConfig } from '../db/adapter.js';
+import type { DataEncryptionReport } from '../engine/data-encryption.js';
+import { mergeSecurityConfig } from './config.js';
+
+export interface PostureCheck {
+  id: string;
+  label: string;
+  passed: boolean;
+  weight: number;
+  /** A failed critical check fails the whole posture */
+  critical?: boolean;
+  detail?: string;
+}
+
+export interface SecurityPosture {
+  score: number;
+  status: 'pass' | 'warn' | 'fail';
+  checks: PostureCheck[];
+  generatedAt: string;
+}
+
+/** Scores at or above this pass when no critical check failed */
+export const POSTURE_PASS_SCORE = 80;
+
+export function computeSecurityPosture(saved: Partial<SecurityConfig> | undefined, encryption: DataEncryptionReport | null): SecurityPosture {
+  const cfg = mergeSecurityConfig(saved || {});
+  const checks: PostureCheck[] = [
+    { id: 'prompt_injection', label: 'Prompt injection protection', weight: 15,
+      passed: cfg.promptInjection.enabled && cfg.promptInjection.mode !== 'monitor',
+      detail: !cfg.promptInjection.enabled ? 'Disabled' : cfg.promptInjection.mode === 'monitor' ? 'Monitor only' : undefined },
+    { id: 'sql_injection', label: 'SQL injection blocking', weight: 10,
+      passed: cfg.sqlInjection.enabled && cfg.sqlInjection.mode === 'block',
+      detail: !cfg.sqlInjection.enabled ? 'Disabled' : cfg.sqlInjection.mode !== 'block' ? 'Monitor only' : undefined },
+    { id: 'input_validation', label: 'Input validation', weight: 10, passed: cfg.inputValidation.enabled },
+    { id: 'output_filtering', label: 'Output filtering', weight: 10,
+      passed: cfg.outputFiltering.enabled && cfg.outputFiltering.mode !== 'monitor',
+      detail: !cfg.outputFiltering.enabled ? 'Disabled' : cfg.outputFiltering.mode === 'monitor' ? 'Monitor only' : undefined },
+    { id: 'brute_force', label: 'Brute-force lockout', weight: 15, passed: cfg.bruteForce.enabled },
+    { id: 'secret_scanning', label: 'Secret scanning', weight: 10, passed: cfg.secretScanning.enabled },
+    { id: 'content_security', label: 'Content Security Policy', weight: 5, passed: cfg.contentSecurity.enabled },
+    { id: 'audit_security', label: 'Security audit logging', weight: 10, passed: cfg.auditSecurity.enabled },
+    { id: 'port_security', label: 'Open port monitoring', weight: 5, passed: cfg.portSecurity.enabled },
+  ];
+
+  if (encryption) {
+    checks.push({
+      id: 'vault_key', label: 'Vault master key configured', weight: 10, passed: encryption.keyConfigured,
+      detail: encryption.keyConfigured ? undefined : 'Using the built-in development key; set AGENTICMAIL_VAULT_KEY',
+    });
+    // Without a policy there's nothing to fail; it shows as an unscored pass
+    const required = encryption.policy.requiredClasses.length;
+    checks.push({
+      id: 'encryption_policy', label: 'Encryption at rest policy', weight: required ? 10 : 0, critical: required > 0,
+      passed: encryption.evaluation.met,
+      detail: !required ? 'No data classes required'
+        : encryption.evaluation.met ? `${required} required data class${required === 1 ? '' : 'es'} encrypted`
+        : encryption.evaluation.unmet.map(u => `${u.label}: ${u.reason}`).join('; '),
+    });
+  }
+
+  const total = checks.reduce((n, c) => n + c.weight, 0);
+  const earned = checks.reduce((n, c) => n + (c.passed ? c.weight : 0), 0);
+  const score = total ? Math.round((earned / total) * 100) : 100;
+  const status = checks.some(c => c.critical && !c.passed) ? 'fail' : score >= POSTURE_PASS_SCORE ? 'pass' : 'warn';
+  return { score, status, checks, generatedAt: new Date().toISOString() };
+}
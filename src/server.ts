@@ -41,22 +41,48 @@ import type { DatabaseAdapter } from './db/adapter.js';
 import { createDbProxy, type DbProxy } from './db/proxy.js';
 import { createAdminRoutes } from './admin/routes.js';
 import { createAuthRoutes } from './auth/routes.js';
+import { PasswordResetService } from './auth/password-reset.js';
+import { SessionTracker } from './auth/sessions.js';
 import {
   requestIdMiddleware,
   requestLogger,
   rateLimiter,
   securityHeaders,
+  nonceScripts,
   requireHttps,
   errorHandler,
+  handleAppError,
+  notFoundHandler,
   auditLogger,
+  requireRole,
+  apiKeyAccess,
+  apiKeyIpAllowed,
+  apiKeyAgentFor,
+  requestIsForAgent,
+  clientIpOf,
 } from './middleware/index.js';
 import { ipAccessControl } from './middleware/firewall.js';
-import { setNetworkDb, invalidateNetworkConfig } from './middleware/network-config.js';
+import { setNetworkDb, invalidateNetworkConfig, getNetworkConfigSync } from './middleware/network-config.js';
 import { initProxyConfig } from './middleware/proxy-config.js';
 import { dnsRebindingProtection } from './middleware/dns-rebinding.js';
 import { requestBodyLimit } from './middleware/request-limits.js';
 import { geoIpRestriction } from './middleware/geo-ip.js';
 import { HealthMonitor, CircuitBreaker } from './lib/resilience.js';
+import { tracingMiddleware } from './middleware/tracing.js';
+import { setServiceVersion, startSpan, withSpan, flushSpans } from './lib/tracing.js';
+import { metricsMiddleware } from './middleware/metrics.js';
+import { callLogMiddleware } from './middleware/call-log.js';
+import { performanceMiddleware } from './middleware/performance.js';
+import { requestCoalescing } from './middleware/coalesce.js';
+import { renderMetrics, backendCallDuration, backendErrorsTotal } from './lib/metrics.js';
+import { preloadTemplates, escapeHtml } from './lib/templates.js';
+import { buildSnapshot, type SnapshotPage, type SnapshotSection } from './lib/snapshot.js';
+import { loadAuditRetention, saveAuditRetention, runAuditRetention, auditRetentionCutoff, type AuditRetentionRun } from './engine/audit-retention.js';
+import { normalizeSchedules, isDue, nextSlot, renderReportPdf, REPORT_PAGE_IDS, REPORT_SETTINGS_KEY, type ReportSchedule, type ReportPage, type ReportPageId, type ReportSection } from './lib/report-schedules.js';
+import type { CsvColumn } from './lib/csv.js';
+import { resolvePluginFile } from './lib/dashboard-plugins.js';
+import { setBrandingDb, invalidateBranding, getBranding, brandingHead, brandingForClient, BRANDING_SETTINGS_KEYS, DEFAULT_BRANDING } from './lib/branding.js';
+import { configBus } from './engine/config-bus.js';
 
 export interface ServerConfig {
   port: number;
@@ -85,6 +111,8 @@ export interface ServerInstance {
 
 export function createServer(config: ServerConfig): ServerInstance {
   const app = new Hono<AppEnv>();
+  setServiceVersion(ENTERPRISE_VERSION);
+  preloadTemplates();
 
   // Wrap DB in a transparent proxy for hot-swap support during onboarding
   const dbProxy = createDbProxy(config.db) as DbProxy;
@@ -97,9 +125,17 @@ export function createServer(config: ServerConfig): ServerInstance {
   invalidateNetworkConfig().catch(() => {});
   initProxyConfig();
 
+  // Org branding is cached; saving any branding field drops the cache
+  setBrandingDb(config.db);
+  getBranding().catch(() => {});
+  configBus.on('settings', ({ keys }: { keys: string[] }) => {
+    if (keys.some(k => BRANDING_SETTINGS_KEYS.includes(k))) invalidateBranding();
+  });
+
   // ─── DB Circuit Breaker ──────────────────────────────
 
   const dbBreaker = new CircuitBreaker({
+    name: 'database',
     failureThreshold: 5,
     recoveryTimeMs: 30_000,
     timeout: 10_000,
@@ -126,6 +162,18 @@ export function createServer(config: ServerConfig): ServerInstance {
   // Request ID (first — everything references it)
   app.use('*', requestIdMiddleware());
 
+  // Distributed tracing (no-op unless OTEL_EXPORTER_OTLP_ENDPOINT is set)
+  app.use('*', tracingMiddleware());
+
+  // Prometheus request metrics (outside the error handler so 5xx are counted)
+  app.use('*', metricsMiddleware());
+
+  // Dashboard call log — ties API calls to the user action that triggered them
+  app.use('/api/*', callLogMiddleware());
+
+  // Dashboard performance budgets — backend latency per dashboard page
+  app.use('/api/*', performanceMiddleware());
+
   // Error handler (wraps everything below)
   app.use('*', errorHandler());
 
@@ -173,15 +221,15 @@ export function createServer(config: ServerConfig): ServerInstance {
       return '';
     },
     credentials: true,
-    allowHeaders: ['Content-Type', 'Authorization', 'X-API-Key', 'X-Request-Id', 'X-CSRF-Token'],
-    exposeHeaders: ['X-Request-Id', 'X-RateLimit-Limit', 'X-RateLimit-Remaining', 'Retry-After', 'X-Transport-Encrypted'],
+    allowHeaders: ['Content-Type', 'Authorization', 'X-API-Key', 'X-Request-Id', 'X-CSRF-Token', 'traceparent', 'X-Dashboard-Page', 'X-Action-Id', 'X-Action-Label'],
+    exposeHeaders: ['X-Request-Id', 'X-Trace-Id', 'X-RateLimit-Limit', 'X-RateLimit-Remaining', 'Retry-After', 'X-Transport-Encrypted'],
   }));
 
   // Rate limiting
   app.use('*', rateLimiter({
     limit: config.rateLimit ?? 300,
     windowSec: 60,
-    skipPaths: ['/health', '/ready', '/dashboard', '/api/engine/agent-status'],
+    skipPaths: ['/health', '/ready', '/metrics', '/dashboard', '/api/engine/agent-status'],
   }));
 
   // Request logging
@@ -197,6 +245,15 @@ export function createServer(config: ServerConfig): ServerInstance {
     uptime: process.uptime(),
   }));
 
+  // Prometheus scrape endpoint — set METRICS_TOKEN to require a bearer token
+  app.get('/metrics', (c) => {
+    const token = process.env.METRICS_TOKEN;
+    if (token && c.req.header('Authorization') !== `Bearer ${token}`) {
+      return c.json({ error: 'Unauthorized' }, 401);
+    }
+    return c.body(renderMetrics(), 200, { 'Content-Type': 'text/plain; version=0.0.4; charset=utf-8', 'Cache-Control': 'no-store' });
+  });
+
   app.get('/ready', async (c) => {
     const dbHealthy = healthMonitor.isHealthy();
     const status = dbHealthy ? 200 : 503;
@@ -221,7 +278,10 @@ export function createServer(config: ServerConfig): ServerInstance {
 
   // ─── Auth Routes (public) ───────────────────────────
 
+  const passwordReset = new PasswordResetService(config.db, config.jwtSecret);
+  const sessions = new SessionTracker(config.db);
   const authRoutes = createAuthRoutes(config.db, config.jwtSecret, {
+    passwordReset,
     onBootstrap: () => { _setupComplete = true; },
     onDbConfigure: (newAdapter) => {
       const old = dbProxy.__swap(newAdapter);
@@ -261,14 +321,31 @@ export function createServer(config: ServerConfig): ServerInstance {
       return next();
     }
 
+    // Skip auth for the subscribed change-calendar feed — calendar clients can't
+    // send cookies, so the route checks its own per-user token instead
+    if (c.req.path.endsWith('/engine/change-calendar/feed.ics') && c.req.method === 'GET') {
+      return next();
+    }
+
     // Check API key first
     const apiKeyHeader = c.req.header('X-API-Key');
     if (apiKeyHeader) {
       const key = await dbBreaker.execute(() => config.db.validateApiKey(apiKeyHeader));
       if (!key) return c.json({ error: 'Invalid API key' }, 401);
+      if (!(await apiKeyIpAllowed(key.id, clientIpOf(c)))) {
+        return c.json({ error: 'This API key cannot be used from your IP address', code: 'API_KEY_IP_BLOCKED' }, 403);
+      }
+      const access = apiKeyAccess(key.scopes, c.req.method, c.req.path);
+      if (!access.ok) return c.json({ error: `API key is missing the ${access.required} scope`, required: access.required }, 403);
+      const boundAgentId = await apiKeyAgentFor(key.id);
+      if (boundAgentId && !requestIsForAgent(boundAgentId, c.req.method, c.req.path, c.req.query('agentId'))) {
+        return c.json({ error: 'This API key is bound to one agent and can only be used for requests about it', code: 'API_KEY_AGENT_BOUND', agentId: boundAgentId }, 403);
+      }
       c.set('userId', key.createdBy);
       c.set('authType', 'api-key');
+      c.set('apiKeyId', key.id);
       c.set('apiKeyScopes', key.scopes);
+      if (boundAgentId) c.set('apiKeyAgentId', boundAgentId);
       return next();
     }
 
@@ -286,6 +363,10 @@ export function createServer(config: ServerConfig): ServerInstance {
       const { jwtVerify } = await import('jose');
       const secret = new TextEncoder().encode(config.jwtSecret);
       const { payload } = await jwtVerify(jwt, secret);
+      // The role in the token is stale once its session is ended (e.g. by a role change)
+      if (typeof payload.sid === 'string' && !(await sessions.isLive(payload.sid))) {
+        return c.json({ error: 'Session has ended' }, 401);
+      }
       c.set('userId', payload.sub as string);
       c.set('userRole', (payload.role as string) || '');
       c.set('userEmail', (payload.email as string) || '');
@@ -335,6 +416,10 @@ export function createServer(config: ServerConfig): ServerInstance {
     return next();
   });
 
+  // Concurrent identical API reads from one caller share a single execution
+  // (after auth and org scoping, so every request is still checked on its own)
+  api.use('*', requestCoalescing());
+
   // Audit logging on all API mutations
   api.use('*', auditLogger(config.db));
 
@@ -355,8 +440,579 @@ export function createServer(config: ServerConfig): ServerInstance {
     loadConfig().catch(() => {});
   }).catch(() => {});
 
+  // ─── Internal Sub-Requests ───────────────────────────
+  // GET another API route in-process with the caller's credentials, so auth,
+  // RBAC and org scoping apply exactly as if the browser had called it.
+  const subRequestHeaders = (c: any): Headers => {
+    const headers = new Headers(c.req.raw.headers);
+    headers.delete('content-length');
+    headers.delete('content-type');
+    headers.delete('accept-encoding'); // bodies are re-serialized by the caller
+    headers.delete('x-transport-encryption');
+    headers.delete('x-action-id');
+    return headers;
+  };
+  const internalGet = async (c: any, headers: Headers, path: string): Promise<{ status: number; body: unknown }> => {
+    const res = await app.fetch(new Request(new URL(c.req.url).origin + '/api' + path, { method: 'GET', headers }), c.env);
+    const text = await res.text();
+    let parsed: unknown = text;
+    try { parsed = JSON.parse(text); } catch { /* non-JSON body */ }
+    return { status: res.status, body: parsed };
+  };
+
+  // ─── Batch Reads ─────────────────────────────────────
+  // Coalesces N single-resource GETs (e.g. one per table row) into one round
+  // trip. Each sub-request runs through the full middleware stack with the
+  // caller's credentials, so auth, RBAC and org scoping apply unchanged.
+  const BATCH_MAX_REQUESTS = 25;
+  const BATCH_CONCURRENCY = 6;
+  api.post('/batch', async (c) => {
+    const body = await c.req.json().catch(() => null);
+    const requests = body?.requests;
+    if (!Array.isArray(requests) || requests.length === 0) {
+      return c.json({ error: 'requests must be a non-empty array' }, 400);
+    }
+    if (requests.length > BATCH_MAX_REQUESTS) {
+      return c.json({ error: `At most ${BATCH_MAX_REQUESTS} requests per batch` }, 400);
+    }
+    for (const r of requests) {
+      if (typeof r?.path !== 'string' || !r.path.startsWith('/') || r.path.startsWith('//') || /^\/batch\b/.test(r.path)) {
+        return c.json({ error: 'Each request needs a path starting with "/" (GET only, no nested batches)' }, 400);
+      }
+    }
+
+    const headers = subRequestHeaders(c);
+    const responses: Array<{ id: string; status: number; body: unknown }> = new Array(requests.length);
+    let next = 0;
+    const worker = async () => {
+      while (next < requests.length) {
+        const i = next++;
+        const id = requests[i].id != null ? String(requests[i].id) : String(i);
+        try {
+          responses[i] = { id, ...await internalGet(c, headers, requests[i].path) };
+        } catch (err: any) {
+          responses[i] = { id, status: 500, body: { error: err.message } };
+        }
+      }
+    };
+    await Promise.all(Array.from({ length: Math.min(BATCH_CONCURRENCY, requests.length) }, worker));
+    return c.json({ responses });
+  });
+
+  // ─── Global Search ───────────────────────────────────
+  // Backs the dashboard's cmd-K overlay. Queries each list API concurrently
+  // as the caller; a group the caller can't read (401/403) is left out
+  // rather than reported, and one slow or failing source doesn't sink the rest.
+  const SEARCH_LIMIT = 5;
+  const SEARCH_SOURCES: Array<{
+    key: string;
+    label: string;
+    path: (q: string, orgId: string) => string | null;
+    results: (body: any) => Array<{ id: string; title: string; subtitle?: string; page: string; query?: string }>;
+  }> = [
+    {
+      key: 'agents', label: 'Agents',
+      path: (q) => `/agents/table?q=${q}&pageSize=${SEARCH_LIMIT}`,
+      results: (b) => (b.rows || []).map((a: any) => ({ id: a.id, title: a.name, subtitle: [a.email, a.role].filter(Boolean).join(' · '), page: 'agents' })),
+    },
+    {
+      key: 'users', label: 'Users',
+      path: (q) => `/users?q=${q}&limit=${SEARCH_LIMIT}`,
+      results: (b) => (b.users || []).map((u: any) => ({ id: u.id, title: u.name || u.email, subtitle: [u.email, u.role].filter(Boolean).join(' · '), page: 'users', query: 'q=' + encodeURIComponent(u.email) })),
+    },
+    {
+      key: 'secrets', label: 'Secrets',
+      path: (q, orgId) => orgId ? `/engine/vault/secrets?orgId=${encodeURIComponent(orgId)}&q=${q}` : null,
+      results: (b) => (b.secrets || []).slice(0, SEARCH_LIMIT).map((s: any) => ({ id: s.id, title: s.name, subtitle: s.category, page: 'vault', query: 'q=' + encodeURIComponent(s.name) })),
+    },
+    {
+      key: 'audit', label: 'Audit Log',
+      path: (q, orgId) => `/audit/rows?resource=${q}&pageSize=${SEARCH_LIMIT}` + (orgId ? `&orgId=${encodeURIComponent(orgId)}` : ''),
+      results: (b) => (b.rows || []).map((e: any) => ({
+        id: e.id, title: e.action, subtitle: [e.resource, e.actor, e.timestamp && String(e.timestamp).slice(0, 16).replace('T', ' ')].filter(Boolean).join(' · '),
+        page: 'audit', query: 'q=' + encodeURIComponent(e.resource || e.action),
+      })),
+    },
+  ];
+
+  api.get('/search', async (c) => {
+    const q = (c.req.query('q') || '').trim();
+    if (q.length < 2) return c.json({ query: q, groups: [] });
+    if (q.length > 200) return c.json({ error: 'Query too long' }, 400);
+    const orgId = c.req.query('orgId') || (c as any).get('enforcedOrgId') || '';
+    const headers = subRequestHeaders(c);
+    const encoded = encodeURIComponent(q);
+
+    const groups = await Promise.all(SEARCH_SOURCES.map(async (src) => {
+      const path = src.path(encoded, orgId);
+      if (!path) return null;
+      try {
+        const res = await internalGet(c, headers, path);
+        if (res.status === 401 || res.status === 403) return null;
+        if (res.status >= 400) return { key: src.key, label: src.label, results: [], error: (res.body as any)?.error || `HTTP ${res.status}` };
+        return { key: src.key, label: src.label, results: src.results(res.body) };
+      } catch (err: any) {
+        return { key: src.key, label: src.label, results: [], error: err.message };
+      }
+    }));
+    return c.json({ query: q, groups: groups.filter(Boolean) });
+  });
+
+  // ─── Dashboard Snapshot ──────────────────────────────
+  // Crawls the read-only pages' APIs as the caller and returns a zip of
+  // static HTML (lib/snapshot.ts) for board reports, audits, or reading
+  // while the backend is down. Sections the caller can't read are recorded
+  // as not captured rather than failing the export.
+  const SNAPSHOT_ROWS = 200;
+  const SNAPSHOT_PAGES: Array<{
+    file: string;
+    title: string;
+    description: string;
+    sections: Array<{ title: string; path: (orgId: string) => string; rows: (body: any) => any[]; columns: CsvColumn[] }>;
+  }> = [
+    {
+      file: 'agents.html', title: 'Agents', description: 'Every agent and its status.',
+      sections: [{
+        title: 'Agents', path: () => `/agents?limit=${SNAPSHOT_ROWS}`, rows: (b) => b.agents || [],
+        columns: [{ header: 'name' }, { header: 'email' }, { header: 'role' }, { header: 'status' }, { header: 'createdAt' }],
+      }],
+    },
+    {
+      file: 'users.html', title: 'Users', description: 'Dashboard users and how they sign in.',
+      sections: [{
+        title: 'Users', path: () => `/users?limit=${SNAPSHOT_ROWS}`, rows: (b) => b.users || [],
+        columns: [
+          { header: 'name' }, { header: 'email' }, { header: 'role' },
+          { header: 'status', value: u => u.isActive === false ? 'deactivated' : 'active' },
+          { header: '2FA', value: u => u.totpEnabled ? 'yes' : 'no' }, { header: 'lastLoginAt' },
+        ],
+      }],
+    },
+    {
+      file: 'activity.html', title: 'Activity', description: `The latest ${SNAPSHOT_ROWS} agent events and tool calls.`,
+      sections: [
+        {
+          title: 'Events', path: (orgId) => `/engine/activity/events?limit=${SNAPSHOT_ROWS}` + (orgId ? `&orgId=${encodeURIComponent(orgId)}` : ''), rows: (b) => b.events || [],
+          columns: [{ header: 'timestamp' }, { header: 'agentId' }, { header: 'type' }, { header: 'data' }],
+        },
+        {
+          title: 'Tool calls', path: (orgId) => `/engine/activity/tool-calls?limit=${SNAPSHOT_ROWS}` + (orgId ? `&orgId=${encodeURIComponent(orgId)}` : ''), rows: (b) => b.toolCalls || [],
+          columns: [
+            { header: 'startedAt', value: t => t.timing?.startedAt }, { header: 'agentId' }, { header: 'toolName' },
+            { header: 'result', value: t => t.result ? (t.result.success ? 'success' : 'failed: ' + (t.result.error || '')) : 'pending' },
+            { header: 'durationMs', value: t => t.timing?.durationMs },
+          ],
+        },
+      ],
+    },
+    {
+      file: 'approvals.html', title: 'Approvals', description: 'Requests waiting on a human and recent decisions.',
+      sections: [
+        {
+          title: 'Pending', path: () => '/engine/approvals/pending', rows: (b) => b.requests || [],
+          columns: [{ header: 'createdAt' }, { header: 'agentName' }, { header: 'toolName' }, { header: 'riskLevel' }, { header: 'reason' }, { header: 'expiresAt' }],
+        },
+        {
+          title: 'History', path: () => `/engine/approvals/history?limit=${SNAPSHOT_ROWS}`, rows: (b) => b.requests || [],
+          columns: [
+            { header: 'createdAt' }, { header: 'agentName' }, { header: 'toolName' }, { header: 'status' },
+            { header: 'decidedBy', value: r => r.decision?.by }, { header: 'reason' },
+          ],
+        },
+      ],
+    },
+    {
+      file: 'journal.html', title: 'Action Journal', description: 'Side-effecting actions agents have taken.',
+      sections: [{
+        title: 'Entries', path: (orgId) => `/engine/journal?limit=${SNAPSHOT_ROWS}` + (orgId ? `&orgId=${encodeURIComponent(orgId)}` : ''), rows: (b) => b.entries || [],
+        columns: [
+          { header: 'createdAt' }, { header: 'agentId' }, { header: 'toolName' }, { header: 'actionType' },
+          { header: 'reversible', value: e => e.reversible ? 'yes' : 'no' }, { header: 'reversed', value: e => e.reversed ? 'yes' : 'no' },
+        ],
+      }],
+    },
+    {
+      file: 'audit.html', title: 'Audit Log', description: `The latest ${SNAPSHOT_ROWS} audit events.`,
+      sections: [{
+        title: 'Events', path: (orgId) => `/audit/rows?pageSize=${SNAPSHOT_ROWS}` + (orgId ? `&orgId=${encodeURIComponent(orgId)}` : ''), rows: (b) => b.rows || [],
+        columns: [{ header: 'timestamp' }, { header: 'actor' }, { header: 'action' }, { header: 'resource' }, { header: 'ip' }],
+      }],
+    },
+  ];
+
+  api.get('/admin/snapshot', requireRole('admin'), async (c) => {
+    const orgId = c.req.query('orgId') || (c as any).get('enforcedOrgId') || '';
+    const headers = subRequestHeaders(c);
+    const generatedBy = c.get('userEmail' as any) || c.get('userId' as any) || undefined;
+
+    const pages: SnapshotPage[] = await Promise.all(SNAPSHOT_PAGES.map(async (page) => ({
+      file: page.file,
+      title: page.title,
+      description: page.description,
+      sections: await Promise.all(page.sections.map(async (s): Promise<SnapshotSection> => {
+        try {
+          const res = await internalGet(c, headers, s.path(orgId));
+          if (res.status >= 400) return { title: s.title, columns: s.columns, rows: [], error: (res.body as any)?.error || `HTTP ${res.status}` };
+          const body = res.body as any;
+          return { title: s.title, columns: s.columns, rows: s.rows(body).slice(0, SNAPSHOT_ROWS), total: typeof body?.total === 'number' ? body.total : undefined };
+        } catch (err: any) {
+          return { title: s.title, columns: s.columns, rows: [], error: err.message };
+        }
+      })),
+    })));
+
+    const branding = await getBranding();
+    const { name, zip } = buildSnapshot(pages, { companyName: branding.companyName, generatedAt: new Date(), generatedBy });
+    config.db.logEvent({
+      actor: c.get('userId' as any) || 'unknown',
+      actorType: 'user',
+      action: 'dashboard.snapshot_export',
+      resource: 'dashboard',
+      details: { bytes: zip.length, pages: pages.map(p => p.file), notCaptured: pages.flatMap(p => p.sections.filter(s => s.error).map(s => `${p.title}/${s.title}`)) },
+      ip: c.req.header('x-forwarded-for')?.split(',')[0]?.trim() || c.req.header('x-real-ip'),
+      orgId: orgId || undefined,
+    }).catch(() => {});
+    return new Response(zip, {
+      headers: {
+        'Content-Type': 'application/zip',
+        'Content-Disposition': `attachment; filename="${name}.zip"`,
+        'Cache-Control': 'no-store',
+      },
+    });
+  });
+
+  // ─── Dashboard Live Stream ───────────────────────────
+  // SSE feed for the home dashboard. Polls the stat and recent-activity APIs
+  // as the caller (so org scoping and RBAC apply as usual) and pushes a
+  // `stats` or `activity` event only when the payload changed. An `end`
+  // event means the session can no longer read them; the client stops there
+  // instead of reconnecting.
+  const STREAM_STATS_MS = 15_000;
+  const STREAM_ACTIVITY_MS = 5_000;
+  const STREAM_HEARTBEAT_MS = 30_000;
+
+  api.get('/dashboard/stream', (c) => {
+    const clientOrgId = c.req.query('clientOrgId') || '';
+    const orgId = clientOrgId || c.req.query('orgId') || (c as any).get('enforcedOrgId') || '';
+    const headers = subRequestHeaders(c);
+    headers.delete('accept');
+    const sources = [
+      { event: 'stats', everyMs: STREAM_STATS_MS, path: '/stats' + (clientOrgId ? `?clientOrgId=${encodeURIComponent(clientOrgId)}` : '') },
+      { event: 'activity', everyMs: STREAM_ACTIVITY_MS, path: '/engine/activity/events?limit=10' + (orgId ? `&orgId=${encodeURIComponent(orgId)}` : '') },
+    ];
+
+    let stop = () => {};
+    const stream = new ReadableStream({
+      start(controller) {
+        const encoder = new TextEncoder();
+        const timers: ReturnType<typeof setInterval>[] = [];
+        const last: Record<string, string> = {};
+        let closed = false;
+        const close = () => {
+          if (closed) return;
+          closed = true;
+          timers.forEach(clearInterval);
+          try { controller.close(); } catch { /* already closed */ }
+        };
+        const write = (chunk: string) => {
+          if (closed) return;
+          try { controller.enqueue(encoder.encode(chunk)); } catch { close(); }
+        };
+
+        const poll = async (src: typeof sources[number]) => {
+          try {
+            const res = await internalGet(c, headers, src.path);
+            if (res.status === 401 || res.status === 403) { write(`event: end\ndata: ${JSON.stringify({ reason: `HTTP ${res.status}` })}\n\n`); close(); return; }
+            if (res.status >= 400) return;
+            const data = JSON.stringify(res.body);
+            if (data === last[src.event]) return;
+            last[src.event] = data;
+            write(`event: ${src.event}\ndata: ${data}\n\n`);
+          } catch { /* transient; try again next tick */ }
+        };
+
+        for (const src of sources) {
+          poll(src);
+          timers.push(setInterval(() => poll(src), src.everyMs));
+        }
+        timers.push(setInterval(() => write(': heartbeat\n\n'), STREAM_HEARTBEAT_MS));
+        stop = close;
+        c.req.raw.signal.addEventListener('abort', close);
+      },
+      cancel() { stop(); },
+    });
+
+    return new Response(stream, {
+      headers: { 'Content-Type': 'text/event-stream', 'Cache-Control': 'no-cache', 'Connection': 'keep-alive' },
+    });
+  });
+
+  // ─── Scheduled Reports ───────────────────────────────
+  // Emails PDF renders of the dashboard, costs and compliance pages to
+  // stakeholders who never sign in (lib/report-schedules.ts). Page data is
+  // fetched in-process as the schedule's owner with a short-lived token, so
+  // RBAC and org scoping apply as if they had opened the pages themselves;
+  // a section they can't read is noted in the PDF rather than failing the run.
+  const REPORT_CHECK_MS = 5 * 60_000;
+  const REPORT_ROWS = 100;
+  type ReportGet = (path: string) => Promise<any>;
+
+  const reportDb = () => config.db.getEngineDB?.() || null;
+  const loadReportSchedules = async (): Promise<ReportSchedule[]> => {
+    const row = await reportDb()?.get('SELECT value FROM engine_settings WHERE key = ?', [REPORT_SETTINGS_KEY]).catch(() => undefined);
+    try { return row?.value ? JSON.parse(row.value) : []; } catch { return []; }
+  };
+  const saveReportSchedules = async (schedules: ReportSchedule[]) => {
+    const edb = reportDb();
+    if (!edb) throw new Error('Scheduled reports need a SQL database');
+    await edb.run('DELETE FROM engine_settings WHERE key = ?', [REPORT_SETTINGS_KEY]);
+    await edb.run('INSERT INTO engine_settings (key, value) VALUES (?, ?)', [REPORT_SETTINGS_KEY, JSON.stringify(schedules)]);
+  };
+
+  /** GET helper that calls the API as `userId`; throws with the API's error on 4xx/5xx. */
+  const reportFetcher = async (userId: string): Promise<ReportGet> => {
+    const user = await config.db.getUser(userId);
+    if (!user || user.isActive === false) throw new Error('The schedule owner no longer has an active account');
+    const { SignJWT } = await import('jose');
+    const token = await new SignJWT({ sub: user.id, email: user.email, role: user.role, ...(user.clientOrgId ? { clientOrgId: user.clientOrgId } : {}) })
+      .setProtectedHeader({ alg: 'HS256' })
+      .setIssuedAt()
+      .setExpirationTime('5m')
+      .sign(new TextEncoder().encode(config.jwtSecret));
+    return async (path) => {
+      const res = await app.fetch(new Request('http://localhost/api' + path, { headers: { Authorization: `Bearer ${token}`, Host: 'localhost' } }), { remoteAddress: '127.0.0.1' } as any);
+      const body: any = await res.json().catch(() => ({}));
+      if (res.status >= 400) throw new Error(body?.error || `HTTP ${res.status}`);
+      return body;
+    };
+  };
+
+  const reportSection = async (title: string, headers: string[], rows: () => Promise<unknown[][]>): Promise<ReportSection> => {
+    try { return { title, headers, rows: (await rows()).slice(0, REPORT_ROWS) }; } catch (err: any) { return { title, headers, rows: [], error: err.message }; }
+  };
+  const usd = (n: unknown) => '$' + (Number(n) || 0).toFixed(2);
+  const orgParam = (orgId: string, sep: '?' | '&') => orgId ? `${sep}orgId=${encodeURIComponent(orgId)}` : '';
+  const agentNames = (agents: any[]) => new Map(agents.map(a => [a.id, a.config?.displayName || a.config?.name || a.name || a.id]));
+
+  const REPORT_PAGES: Record<ReportPageId, (get: ReportGet, orgId: string) => Promise<ReportPage>> = {
+    dashboard: async (get, orgId) => {
+      const [stats, pending, agents] = await Promise.all([
+        get('/stats').catch(() => null),
+        get('/engine/approvals/pending').catch(() => null),
+        get('/engine/agents' + orgParam(orgId, '?')).then(b => b.agents || [], () => null),
+      ]);
+      const names = agentNames(agents || []);
+      const summary: Array<[string, unknown]> = [];
+      if (stats) summary.push(['Agents', stats.totalAgents], ['Active agents', stats.activeAgents], ['Users', stats.totalUsers], ['Audit events', stats.totalAuditEvents]);
+      if (pending) summary.push(['Pending approvals', (pending.requests || []).length]);
+      return {
+        id: 'dashboard', title: 'Dashboard', summary,
+        sections: await Promise.all([
+          reportSection('Agents', ['Agent', 'State', 'Tool calls today', 'Cost this month'], async () => {
+            if (!agents) throw new Error('agents are not visible to the schedule owner');
+            return agents.map((a: any) => [names.get(a.id), a.state, a.usage?.toolCallsToday ?? 0, usd(a.usage?.costThisMonth)]);
+          }),
+          reportSection('Recent activity', ['Time', 'Agent', 'Event'], async () =>
+            ((await get('/engine/activity/events?limit=25' + orgParam(orgId, '&'))).events || []).map((e: any) => [e.timestamp, names.get(e.agentId) || e.agentId, e.type])),
+        ]),
+      };
+    },
+    costs: async (get, orgId) => {
+      const agents: any[] | null = await get('/engine/agents' + orgParam(orgId, '?')).then(b => b.agents || [], () => null);
+      const total = (key: string) => (agents || []).reduce((s, a) => s + (Number(a.usage?.[key]) || 0), 0);
+      const names = agentNames(agents || []);
+      return {
+        id: 'costs', title: 'Costs',
+        summary: agents ? [['Today', usd(total('costToday'))], ['This week', usd(total('costThisWeek'))], ['This month', usd(total('costThisMonth'))], ['This year', usd(total('costThisYear'))]] : undefined,
+        sections: await Promise.all([
+          reportSection('Cost by agent', ['Agent', 'Today', 'This month', 'Monthly budget', 'Tokens this month'], async () => {
+            if (!agents) throw new Error('agents are not visible to the schedule owner');
+            return agents.slice().sort((a, b) => (b.usage?.costThisMonth || 0) - (a.usage?.costThisMonth || 0)).map(a => [
+              names.get(a.id), usd(a.usage?.costToday), usd(a.usage?.costThisMonth),
+              a.usage?.costBudgetMonthly ? usd(a.usage.costBudgetMonthly) : 'Unlimited', (a.usage?.tokensThisMonth || 0).toLocaleString(),
+            ]);
+          }),
+          reportSection('Last 30 days', ['Day', 'Messages', 'Tool calls', 'Errors', 'LLM cost'], async () =>
+            ((await get('/engine/activity/trend?days=30' + orgParam(orgId, '&'))).trend || []).map((d: any) => [d.day, d.messages, d.toolCalls, d.errors, usd(d.costUsd)])),
+        ]),
+      };
+    },
+    compliance: async (get, orgId) => ({
+      id: 'compliance', title: 'Compliance',
+      sections: await Promise.all([
+        reportSection('Compliance reports', ['Generated', 'Title', 'Type', 'Status'], async () =>
+          ((await get('/engine/compliance/reports?limit=25' + orgParam(orgId, '&'))).reports || []).map((r: any) => [r.createdAt, r.title, r.type, r.status])),
+        reportSection('DLP violations', ['Time', 'Agent', 'Tool', 'Direction', 'Action'], async () =>
+          ((await get('/engine/dlp/violations?limit=50' + orgParam(orgId, '&'))).violations || []).map((v: any) => [v.createdAt, v.agentId, v.toolId, v.direction, v.actionTaken])),
+      ]),
+    }),
+  };
+
+  /** Build the pages any recipient asked for, once each. */
+  const buildReportPages = async (s: ReportSchedule, pages: ReportPageId[]): Promise<Map<ReportPageId, ReportPage>> => {
+    const get = await reportFetcher(s.owner);
+    return new Map(await Promise.all(pages.map(async (p) => [p, await REPORT_PAGES[p](get, s.orgId || '')] as const)));
+  };
+
+  const runReportSchedule = async (s: ReportSchedule): Promise<{ sent: string[]; failed: Array<{ email: string; error: string }> }> => {
+    const settings: any = await config.db.getSettings();
+    if (!settings?.smtpHost || !settings?.smtpUser) throw new Error('Set up an SMTP relay in Settings → Email & Domain to send reports');
+    const pages = await buildReportPages(s, REPORT_PAGE_IDS.filter(p => s.recipients.some(r => r.pages.includes(p))));
+    const branding = await getBranding();
+    const generatedAt = new Date();
+    const nodemailer = await import('nodemailer');
+    const port = settings.smtpPort || 587;
+    const transport = nodemailer.createTransport({ host: settings.smtpHost, port, secure: port === 465, auth: { user: settings.smtpUser, pass: settings.smtpPass } });
+    const filename = `${s.id}-${generatedAt.toISOString().slice(0, 10)}.pdf`;
+    const pdfs = new Map<string, Buffer>();
+    const result = { sent: [] as string[], failed: [] as Array<{ email: string; error: string }> };
+    try {
+      for (const r of s.recipients) {
+        const key = r.pages.join(',');
+        if (!pdfs.has(key)) pdfs.set(key, renderReportPdf(r.pages.map(p => pages.get(p)!), { companyName: branding.companyName, scheduleName: s.name, generatedAt }));
+        try {
+          await transport.sendMail({
+            from: process.env.REPORTS_FROM || `"${branding.companyName.replace(/"/g, '')}" <${settings.smtpUser}>`,
+            to: r.email,
+            subject: `${s.name} — ${generatedAt.toISOString().slice(0, 10)}`,
+            text: `Attached is the ${s.frequency} "${s.name}" report from ${branding.companyName}: ${r.pages.map(p => pages.get(p)!.title).join(', ')}.\n\nYou're receiving this because an administrator added ${r.email} to the schedule.`,
+            attachments: [{ filename, content: pdfs.get(key)!, contentType: 'application/pdf' }],
+          });
+          result.sent.push(r.email);
+        } catch (err: any) {
+          result.failed.push({ email: r.email, error: err.message });
+        }
+      }
+    } finally {
+      transport.close();
+    }
+    return result;
+  };
+
+  /** Run a schedule and store the outcome on it; `actor` is set for manual sends. */
+  const runAndRecordReport = async (s: ReportSchedule, actor?: { userId: string; ip?: string }) => {
+    let outcome: Awaited<ReturnType<typeof runReportSchedule>> | null = null;
+    let error: string | undefined;
+    try { outcome = await runReportSchedule(s); } catch (err: any) { error = err.message; }
+    const status: ReportSchedule['lastStatus'] = !outcome ? 'failed' : outcome.failed.length === 0 ? 'sent' : outcome.sent.length ? 'partial' : 'failed';
+    if (!error && outcome?.failed.length) error = outcome.failed.map(f => `${f.email}: ${f.error}`).join('; ');
+    const latest = await loadReportSchedules();
+    const target = latest.find(x => x.id === s.id);
+    if (target) {
+      Object.assign(target, { lastRunAt: new Date().toISOString(), lastStatus: status, lastError: error });
+      await saveReportSchedules(latest).catch(() => {});
+    }
+    config.db.logEvent({
+      actor: actor?.userId || 'scheduler',
+      actorType: actor ? 'user' : 'system',
+      action: 'report_schedule.send',
+      resource: `report_schedule:${s.id}`,
+      details: { name: s.name, status, sent: outcome?.sent || [], failed: outcome?.failed || [], error },
+      ip: actor?.ip,
+      orgId: s.orgId || undefined,
+    }).catch(() => {});
+    return { status, sent: outcome?.sent || [], failed: outcome?.failed || [], error };
+  };
+
+  let reportTickRunning = false;
+  const reportTick = async () => {
+    if (reportTickRunning || !reportDb()) return;
+    reportTickRunning = true;
+    try {
+      const now = new Date();
+      for (const s of (await loadReportSchedules()).filter(x => isDue(x, now))) {
+        await runAndRecordReport(s).catch((err) => console.warn(`[reports] ${s.id}: ${err.message}`));
+      }
+    } finally {
+      reportTickRunning = false;
+    }
+  };
+
+  // Audit retention runs at most once a day while enabled; a failed run is retried after a few hours
+  const AUDIT_RETENTION_CHECK_MS = 60 * 60_000;
+  let auditRetentionRunning = false;
+  const auditRetentionTick = async () => {
+    const edb = reportDb();
+    if (auditRetentionRunning || !edb) return;
+    auditRetentionRunning = true;
+    try {
+      const policy = await loadAuditRetention(edb);
+      if (!policy.enabled) return;
+      const waitMs = policy.lastRun?.error ? 6 * 3_600_000 : 23 * 3_600_000;
+      if (policy.lastRun && Date.now() - Date.parse(policy.lastRun.at) < waitMs) return;
+      let run: AuditRetentionRun;
+      try {
+        run = await runAuditRetention(config.db, policy);
+      } catch (err: any) {
+        run = { at: new Date().toISOString(), cutoff: auditRetentionCutoff(policy.retainDays).toISOString(), archived: 0, deleted: 0, error: err.message };
+        console.warn(`[audit-retention] ${err.message}`);
+      }
+      // Re-read so a policy edit made during the run isn't lost
+      await saveAuditRetention(edb, { ...(await loadAuditRetention(edb)), lastRun: run });
+      config.db.logEvent({
+        actor: 'scheduler',
+        actorType: 'system',
+        action: run.error ? 'audit.retention_failed' : 'audit.retention_purged',
+        resource: 'audit_retention',
+        details: { ...run, retainDays: policy.retainDays, archiveToS3: policy.archiveToS3 },
+      }).catch(() => {});
+    } finally {
+      auditRetentionRunning = false;
+    }
+  };
+
+  const withNextRun = (list: ReportSchedule[]) => list.map(s => ({ ...s, nextRunAt: s.enabled ? nextSlot(s, new Date()).toISOString() : null }));
+
+  api.get('/admin/report-schedules', requireRole('admin'), async (c) => {
+    const settings: any = await config.db.getSettings().catch(() => null);
+    return c.json({ schedules: withNextRun(await loadReportSchedules()), pages: REPORT_PAGE_IDS, smtpConfigured: !!(settings?.smtpHost && settings?.smtpUser), available: !!reportDb() });
+  });
+
+  // Replaces the whole list: { schedules: [...] }
+  api.put('/admin/report-schedules', requireRole('admin'), async (c) => {
+    if (!reportDb()) return c.json({ error: 'Scheduled reports need a SQL database' }, 501);
+    const body = await c.req.json().catch(() => ({}));
+    const before = await loadReportSchedules();
+    let schedules: ReportSchedule[];
+    try { schedules = normalizeSchedules(body.schedules, before, c.get('userId' as any)); } catch (err: any) { return c.json({ error: err.message }, 400); }
+    await saveReportSchedules(schedules);
+    config.db.logEvent({
+      actor: c.get('userId' as any) || 'unknown',
+      actorType: 'user',
+      action: 'report_schedule.update',
+      resource: 'report_schedules',
+      details: { schedules: schedules.map(s => ({ name: s.name, frequency: s.frequency, recipients: s.recipients.map(r => r.email) })), previous: before.map(s => s.name) },
+      ip: c.req.header('x-forwarded-for')?.split(',')[0]?.trim() || c.req.header('x-real-ip'),
+    }).catch(() => {});
+    return c.json({ success: true, schedules: withNextRun(schedules) });
+  });
+
+  // Sends now, outside the schedule
+  api.post('/admin/report-schedules/:id/send', requireRole('admin'), async (c) => {
+    const s = (await loadReportSchedules()).find(x => x.id === c.req.param('id'));
+    if (!s) return c.json({ error: 'Schedule not found' }, 404);
+    const result = await runAndRecordReport(s, { userId: c.get('userId' as any), ip: c.req.header('x-forwarded-for')?.split(',')[0]?.trim() || c.req.header('x-real-ip') });
+    return c.json(result, result.status === 'failed' ? 502 : 200);
+  });
+
+  // The PDF a recipient would get (?pages=dashboard,costs), without sending it
+  api.get('/admin/report-schedules/:id/preview', requireRole('admin'), async (c) => {
+    const s = (await loadReportSchedules()).find(x => x.id === c.req.param('id'));
+    if (!s) return c.json({ error: 'Schedule not found' }, 404);
+    const wanted = (c.req.query('pages') || '').split(',').filter((p): p is ReportPageId => REPORT_PAGE_IDS.includes(p as ReportPageId));
+    const ids = wanted.length ? wanted : REPORT_PAGE_IDS.filter(p => s.recipients.some(r => r.pages.includes(p)));
+    try {
+      const pages = await buildReportPages(s, ids);
+      const branding = await getBranding();
+      const pdf = renderReportPdf(ids.map(p => pages.get(p)!), { companyName: branding.companyName, scheduleName: s.name, generatedAt: new Date() });
+      return new Response(pdf, { headers: { 'Content-Type': 'application/pdf', 'Content-Disposition': `attachment; filename="${s.id}-preview.pdf"`, 'Cache-Control': 'no-store' } });
+    } catch (err: any) {
+      return c.json({ error: err.message }, 502);
+    }
+  });
+
   // Admin routes
-  const adminRoutes = createAdminRoutes(config.db);
+  const adminRoutes = createAdminRoutes(config.db, { passwordReset });
   api.route('/', adminRoutes);
 
   // Engine routes (skills, permissions, deployment, approvals, lifecycle, KB, etc.)
@@ -473,6 +1129,8 @@ export function createServer(config: ServerConfig): ServerInstance {
       const originalUrl = new URL(c.req.url);
       const subPath = (c.req.path.replace(/^\/api\/engine/, '') || '/') + originalUrl.search;
       const headers = new Headers(c.req.raw.headers);
+      // Engine routes trust these, so only values from the verified session may reach them
+      for (const name of ['X-User-Id', 'X-User-Role', 'X-User-Email', 'X-Auth-Type']) headers.delete(name);
       const userId = c.get('userId');
       const userRole = c.get('userRole');
       const userEmail = c.get('userEmail');
@@ -497,12 +1155,35 @@ export function createServer(config: ServerConfig): ServerInstance {
           }
         }
       }
+      // Child span for the in-process engine dispatch; propagate context so
+      // engine-side spans join the same trace
+      const dispatchSpan = startSpan('engine.dispatch', { attributes: { 'url.path': subPath.split('?')[0] } });
+      headers.set('traceparent', dispatchSpan.traceparent());
       const subReq = new Request(new URL(subPath, 'http://localhost'), {
         method: c.req.method,
         headers,
         body: subBody,
       });
-      return engineRoutes.fetch(subReq);
+      const engineTimer = backendCallDuration.startTimer({ backend: 'engine' });
+      try {
+        const res = await withSpan(dispatchSpan, () => engineRoutes.fetch(subReq));
+        dispatchSpan.setAttribute('http.response.status_code', res.status);
+        if (res.status >= 500) {
+          dispatchSpan.setError(`HTTP ${res.status}`);
+          backendErrorsTotal.inc({ backend: 'engine', reason: 'http_5xx' });
+        } else {
+          dispatchSpan.setOk();
+        }
+        engineTimer({ outcome: res.status >= 500 ? 'error' : 'success' });
+        return res;
+      } catch (err) {
+        dispatchSpan.recordException(err);
+        engineTimer({ outcome: 'error' });
+        backendErrorsTotal.inc({ backend: 'engine', reason: 'error' });
+        throw err;
+      } finally {
+        dispatchSpan.end();
+      }
     } catch (e: any) {
       console.error('[engine] Error:', e.message);
       return c.json({ error: 'Engine module not available', detail: e.message }, 501);
@@ -538,20 +1219,28 @@ export function createServer(config: ServerConfig): ServerInstance {
     // Cache-bust all JS imports with current version
     html = html.replace(/\.js\?v=\d+/g, `.js?v=${ENTERPRISE_VERSION}`);
 
+    // Theme preference (em_theme cookie) — stamp data-theme before first paint.
+    // 'system' (or no cookie) resolves prefers-color-scheme inline instead.
+    const { getCookie } = await import('hono/cookie');
+    const themePref = getCookie(c, 'em_theme');
+    if (themePref === 'light' || themePref === 'dark') {
+      html = html.replace('<html lang="en">', `<html lang="en" data-theme="${themePref}">`);
+    } else {
+      html = html.replace('</head>', `<script>if(window.matchMedia&&matchMedia('(prefers-color-scheme: light)').matches)document.documentElement.setAttribute('data-theme','light');</script></head>`);
+    }
+
     if (!_setupComplete) {
       const injection = `<script>window.__EM_SETUP_STATE__=${JSON.stringify({ needsBootstrap: true })};</script>`;
       html = html.replace('</head>', injection + '</head>');
     }
 
-    // Inject branding config
+    // Inject branding — CSS variables + favicon server-side so there's no flash of default colours
     try {
-      const settings0 = await config.db.getSettings();
-      const branding = settings0?.branding || {};
-      if (settings0?.name && !branding.companyName) branding.companyName = settings0.name;
-      if (Object.keys(branding).length > 0) {
-        const brandScript = `<script>window.__EM_BRANDING__=${JSON.stringify(branding)};</script>`;
-        html = html.replace('</head>', brandScript + '</head>');
-      }
+      const branding = await getBranding();
+      const title = branding.companyName === DEFAULT_BRANDING.companyName ? 'AgenticMail Enterprise' : branding.companyName;
+      html = html.replace(/<title>[^<]*<\/title>/, `<title>${escapeHtml(title)}</title>`);
+      const brandScript = `<script>window.__EM_BRANDING__=${JSON.stringify(brandingForClient(branding)).replace(/</g, '\\u003c')};</script>`;
+      html = html.replace('</head>', brandingHead(branding) + brandScript + '</head>');
     } catch { /* non-blocking */ }
 
     // Inject domain verification status (informational, does not block)
@@ -569,7 +1258,21 @@ export function createServer(config: ServerConfig): ServerInstance {
       }
     } catch { /* non-blocking */ }
 
-    return c.html(html);
+    // Backend failover list — the dashboard health-checks these and fails over
+    const failover = getNetworkConfigSync().network?.failover;
+    if (failover?.enabled && (failover.backends?.length || 0) > 1) {
+      const backends = { urls: failover.backends, healthCheckIntervalSec: failover.healthCheckIntervalSec };
+      html = html.replace('</head>', `<script>window.__EM_BACKENDS__=${JSON.stringify(backends).replace(/</g, '\\u003c')};</script></head>`);
+    }
+
+    // Embeddable pages — lets the SPA confine navigation when loaded in a frame
+    const embedding = getNetworkConfigSync().network?.securityHeaders?.embedding;
+    if (embedding?.enabled && embedding.routes?.length) {
+      html = html.replace('</head>', `<script>window.__EM_EMBED_ROUTES__=${JSON.stringify(embedding.routes)};</script></head>`);
+    }
+
+    // Every inline script above is ours; the CSP only lets nonce'd ones run
+    return c.html(nonceScripts(html, c.get('cspNonce')));
   }
 
   // Serve branding assets from ~/.agenticmail/branding/
@@ -598,9 +1301,10 @@ export function createServer(config: ServerConfig): ServerInstance {
     let filePath = join(dir, 'dashboard', 'docs', file);
     if (!existsSync(filePath)) filePath = join(dir, 'dashboard', 'docs', file + '.html');
     if (existsSync(filePath)) {
+      const isCss = file.endsWith('.css');
       const content = readFileSync(filePath, 'utf-8');
-      const ct = file.endsWith('.css') ? 'text/css; charset=utf-8' : 'text/html; charset=utf-8';
-      return new Response(content, { status: 200, headers: { 'Content-Type': ct } });
+      const ct = isCss ? 'text/css; charset=utf-8' : 'text/html; charset=utf-8';
+      return new Response(isCss ? content : nonceScripts(content, c.get('cspNonce' as any)), { status: 200, headers: { 'Content-Type': ct } });
     }
     return c.json({ error: 'Documentation page not found' }, 404);
   });
@@ -648,8 +1352,33 @@ export function createServer(config: ServerConfig): ServerInstance {
     for (const [k, v] of SHARED_FILES) { if (now > v.expires) SHARED_FILES.delete(k); }
   }, 600_000);
 
+  // Published Trust page (no auth — the token is the auth; unpublishing revokes it)
+  app.get('/trust/:token', async (c) => {
+    try {
+      const { trustCenter } = await import('./engine/routes.js');
+      const { renderTrustHtml } = await import('./engine/trust-center.js');
+      const orgId = await trustCenter.findByToken(c.req.param('token'));
+      if (!orgId) return c.text('Not found', 404);
+      return new Response(renderTrustHtml(await trustCenter.build(orgId)), {
+        headers: { 'Content-Type': 'text/html; charset=utf-8', 'Cache-Control': 'public, max-age=300', 'X-Robots-Tag': 'noindex' },
+      });
+    } catch { return c.text('Trust page unavailable', 503); }
+  });
+
   // Serve dashboard JS modules and static assets (components/*.js, pages/*.js, app.js, assets/*)
   const STATIC_MIME: Record<string, string> = { '.js': 'application/javascript; charset=utf-8', '.png': 'image/png', '.jpg': 'image/jpeg', '.jpeg': 'image/jpeg', '.svg': 'image/svg+xml', '.ico': 'image/x-icon', '.gif': 'image/gif', '.webp': 'image/webp', '.css': 'text/css; charset=utf-8' };
+
+  // Dashboard plugin files (lib/dashboard-plugins.ts). Paths without a known
+  // extension are plugin pages, served by the SPA like any other page.
+  app.get('/dashboard/plugins/:id/*', (c) => {
+    const relPath = c.req.path.replace(/^\/dashboard\/plugins\/[^/]+\//, '');
+    const mime = STATIC_MIME[relPath.substring(relPath.lastIndexOf('.'))];
+    if (!mime) return serveDashboard(c);
+    const filePath = resolvePluginFile(c.req.param('id'), relPath);
+    if (!filePath) return c.json({ error: 'Not found', path: c.req.path }, 404);
+    return new Response(readFileSync(filePath), { status: 200, headers: { 'Content-Type': mime, 'Cache-Control': 'no-cache' } });
+  });
+
   app.get('/dashboard/*', (c) => {
     const reqPath = c.req.path.replace('/dashboard/', '');
     const ext = reqPath.substring(reqPath.lastIndexOf('.'));
@@ -681,11 +1410,10 @@ export function createServer(config: ServerConfig): ServerInstance {
     return serveDashboard(c);
   });
 
-  // ─── 404 Handler ─────────────────────────────────────
+  // ─── 404 / 405 / 500 Handlers ────────────────────────
 
-  app.notFound((c) => {
-    return c.json({ error: 'Not found', path: c.req.path }, 404);
-  });
+  app.notFound(notFoundHandler(() => app.routes));
+  app.onError(handleAppError);
 
   // ─── Server Start ────────────────────────────────────
 
@@ -706,6 +1434,12 @@ export function createServer(config: ServerConfig): ServerInstance {
             // Start health monitoring
             healthMonitor.start();
 
+            // Check report schedules; the first check catches runs missed while down
+            setTimeout(() => { reportTick().catch(() => {}); }, 30_000).unref();
+            setInterval(() => { reportTick().catch(() => {}); }, REPORT_CHECK_MS).unref();
+            setTimeout(() => { auditRetentionTick().catch(() => {}); }, 60_000).unref();
+            setInterval(() => { auditRetentionTick().catch(() => {}); }, AUDIT_RETENTION_CHECK_MS).unref();
+
             // Load saved provider API keys from DB (decrypt via vault, pass via runtime config)
             config.db.getSettings().then(async (settings: any) => {
               const { SecureVault } = await import('./engine/vault.js');
@@ -854,7 +1588,7 @@ export function createServer(config: ServerConfig): ServerInstance {
               console.log('\n⏳ Shutting down gracefully...');
               healthMonitor.stop();
               server.close(() => {
-                config.db.disconnect().then(() => {
+                flushSpans().catch(() => {}).then(() => config.db.disconnect()).then(() => {
                   console.log('✅ Shutdown complete');
                   process.exit(0);
                 });
@@ -8,7 +8,11 @@ export type AppEnv = {
     userRole: string;
     userEmail: string;
     authType: string;
+    /** The API key a request was made with; audit events are recorded under it */
+    apiKeyId: string;
     apiKeyScopes: string[];
+    /** The agent a service-account API key is bound to */
+    apiKeyAgentId: string;
     requestId: string;
     userOrgId: string;
     clientOrgId: string;